@@ -0,0 +1,63 @@
+"""Reference chat agent on Orbit's /v1/chat: one call per turn, memory handled server-side.
+
+Run interactively, or pass messages with ``--say`` for a scripted end-to-end check:
+
+    python -m examples.agent.agent --entity-id alice --session-id demo
+    python -m examples.agent.agent --say "I prefer aisle seats" --say "Book me a flight"
+"""
+
+from __future__ import annotations
+
+import argparse
+import os
+import uuid
+from pathlib import Path
+
+from examples._env import load_env_file
+from orbit import MemoryEngine
+
+
+def run_turn(engine: MemoryEngine, session_id: str, entity_id: str, message: str) -> str:
+    result = engine.chat(session_id, message, entity_id=entity_id)
+    used = ", ".join(memory.memory_id for memory in result.memories) or "none"
+    print(f"you> {message}")
+    print(f"agent> {result.reply}")
+    print(f"  (turn {result.turn_count}, memories used: {used})")
+    return result.reply
+
+
+def main() -> None:
+    load_env_file(start=Path(__file__).resolve().parent)
+    parser = argparse.ArgumentParser(description="Chat with an Orbit-backed agent.")
+    parser.add_argument("--entity-id", default="demo-user")
+    parser.add_argument("--session-id", default=f"agent-{uuid.uuid4().hex[:8]}")
+    parser.add_argument("--say", action="append", default=[], help="Send a message and exit.")
+    parser.add_argument("--clear", action="store_true", help="Clear the session when done.")
+    args = parser.parse_args()
+
+    token = os.getenv("ORBIT_JWT_TOKEN", "")
+    if not token:
+        raise SystemExit("Set ORBIT_JWT_TOKEN in .env (or process env) to a valid Orbit token.")
+    engine = MemoryEngine(
+        api_key=token,
+        base_url=os.getenv("ORBIT_API_BASE_URL", "http://localhost:8000"),
+    )
+    try:
+        if args.say:
+            for message in args.say:
+                run_turn(engine, args.session_id, args.entity_id, message)
+        else:
+            print(f"Session {args.session_id}; an empty line exits.")
+            while message := input("you> ").strip():
+                result = engine.chat(args.session_id, message, entity_id=args.entity_id)
+                print(f"agent> {result.reply}")
+        history = engine.chat_history(args.session_id)
+        print(f"Session {args.session_id} has {len(history.data)} turns.")
+        if args.clear:
+            engine.clear_chat(args.session_id)
+    finally:
+        engine.close()
+
+
+if __name__ == "__main__":
+    main()
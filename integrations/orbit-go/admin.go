@@ -0,0 +1,262 @@
+package orbitmemory
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// The admin calls manage tenant configuration and need one of the server's operator tokens.
+
+// Namespace is a tenant's settings from /v1/admin/namespaces/{account_key}. ExpiresAt is
+// set on sandbox namespaces, which are wiped once it passes.
+type Namespace struct {
+	AccountKey  string     `json:"account_key"`
+	DisplayName string     `json:"display_name"`
+	Description string     `json:"description"`
+	Pipeline    []string   `json:"pipeline"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// NamespaceParams replaces a namespace's settings. A nil Pipeline falls back to the
+// server's ORBIT_PIPELINE_STAGES, and a non-zero TTLSeconds makes the namespace a sandbox.
+type NamespaceParams struct {
+	DisplayName string   `json:"display_name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Pipeline    []string `json:"pipeline,omitempty"`
+	TTLSeconds  int      `json:"ttl_seconds,omitempty"`
+}
+
+// EventTypeDefinition is one entry in an event type registry.
+type EventTypeDefinition struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Sampling    *EventTypeSampling `json:"sampling,omitempty"`
+}
+
+// EventTypeSampling keeps a high-volume event type out of storage: Mode "sample" stores
+// each event with probability Rate, "aggregate" stores one summary memory per entity every
+// WindowSeconds, and "counter" stores the first of identical events and counts the repeats.
+// A nil Rate or zero WindowSeconds uses the server default.
+type EventTypeSampling struct {
+	Mode          string   `json:"mode"`
+	Rate          *float64 `json:"rate,omitempty"`
+	WindowSeconds int      `json:"window_seconds,omitempty"`
+}
+
+// EventTypeRegistryParams replaces a tenant's event types. With Enforce set, ingest
+// rejects any event type not in the list.
+type EventTypeRegistryParams struct {
+	EventTypes []EventTypeDefinition `json:"event_types"`
+	Enforce    bool                  `json:"enforce"`
+}
+
+// EventTypeRegistry is the /v1/admin/tenants/{account_key}/event-types response.
+type EventTypeRegistry struct {
+	AccountKey string                `json:"account_key"`
+	EventTypes []EventTypeDefinition `json:"event_types"`
+	Enforce    bool                  `json:"enforce"`
+	UpdatedAt  time.Time             `json:"updated_at"`
+}
+
+// RetentionPolicyParams sets how many days a tenant's memories are kept; EventTypes
+// overrides Days for memories with that intent.
+type RetentionPolicyParams struct {
+	Days       int            `json:"days"`
+	EventTypes map[string]int `json:"event_types,omitempty"`
+}
+
+// RetentionPolicy is the /v1/admin/tenants/{account_key}/retention response.
+type RetentionPolicy struct {
+	AccountKey string         `json:"account_key"`
+	Days       int            `json:"days"`
+	EventTypes map[string]int `json:"event_types"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+// WritePolicyParams limits what agents may store in a tenant. A nil AllowedEventTypes
+// allows any event type and a nil MaxImportance any self-assigned importance.
+// ReviewCategories are held for approval through /v1/review instead of stored.
+type WritePolicyParams struct {
+	AllowedEventTypes []string `json:"allowed_event_types,omitempty"`
+	MaxImportance     *float64 `json:"max_importance,omitempty"`
+	BannedCategories  []string `json:"banned_categories,omitempty"`
+	ReviewCategories  []string `json:"review_categories,omitempty"`
+}
+
+// WritePolicy is the /v1/admin/tenants/{account_key}/write-policy response.
+type WritePolicy struct {
+	AccountKey        string    `json:"account_key"`
+	AllowedEventTypes []string  `json:"allowed_event_types"`
+	MaxImportance     *float64  `json:"max_importance"`
+	BannedCategories  []string  `json:"banned_categories"`
+	ReviewCategories  []string  `json:"review_categories"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// PipelineWebhookParams configures the tenant's ingest transformation webhook.
+// FailurePolicy is "continue", "drop", or "reject".
+type PipelineWebhookParams struct {
+	URL           string `json:"url"`
+	Secret        string `json:"secret,omitempty"`
+	TimeoutMs     int    `json:"timeout_ms,omitempty"`
+	FailurePolicy string `json:"failure_policy,omitempty"`
+}
+
+// PipelineWebhook is a configured transformation webhook; the secret is never returned.
+type PipelineWebhook struct {
+	URL           string    `json:"url"`
+	HasSecret     bool      `json:"has_secret"`
+	TimeoutMs     int       `json:"timeout_ms"`
+	FailurePolicy string    `json:"failure_policy"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// APIKeyParams issues a tenant API key.
+type APIKeyParams struct {
+	Name           string   `json:"name"`
+	Scopes         []string `json:"scopes,omitempty"`
+	AllowedOrigins []string `json:"allowed_origins,omitempty"`
+}
+
+// IssuedAPIKey is a newly issued key; Key is the secret and is only returned once.
+type IssuedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// IsNotFound reports whether err is a 404 from the API, e.g. for a deleted resource.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// Namespace returns a tenant's settings.
+func (c *Client) Namespace(ctx context.Context, accountKey string) (Namespace, error) {
+	var out Namespace
+	err := c.do(ctx, http.MethodGet, namespacePath(accountKey), nil, &out)
+	return out, err
+}
+
+// SetNamespace creates or replaces a tenant's settings.
+func (c *Client) SetNamespace(ctx context.Context, accountKey string, params NamespaceParams) (Namespace, error) {
+	var out Namespace
+	err := c.do(ctx, http.MethodPut, namespacePath(accountKey), params, &out)
+	return out, err
+}
+
+// DeleteNamespace removes a tenant's settings. Its memories and keys are kept.
+func (c *Client) DeleteNamespace(ctx context.Context, accountKey string) error {
+	return c.do(ctx, http.MethodDelete, namespacePath(accountKey), nil, nil)
+}
+
+// EventTypeRegistry returns a tenant's event type registry.
+func (c *Client) EventTypeRegistry(ctx context.Context, accountKey string) (EventTypeRegistry, error) {
+	var out EventTypeRegistry
+	err := c.do(ctx, http.MethodGet, tenantPath(accountKey, "/event-types"), nil, &out)
+	return out, err
+}
+
+// SetEventTypeRegistry replaces a tenant's event type registry.
+func (c *Client) SetEventTypeRegistry(ctx context.Context, accountKey string, params EventTypeRegistryParams) (EventTypeRegistry, error) {
+	if params.EventTypes == nil {
+		params.EventTypes = []EventTypeDefinition{}
+	}
+	var out EventTypeRegistry
+	err := c.do(ctx, http.MethodPut, tenantPath(accountKey, "/event-types"), params, &out)
+	return out, err
+}
+
+// DeleteEventTypeRegistry removes a tenant's registry so any event type is accepted.
+func (c *Client) DeleteEventTypeRegistry(ctx context.Context, accountKey string) error {
+	return c.do(ctx, http.MethodDelete, tenantPath(accountKey, "/event-types"), nil, nil)
+}
+
+// RetentionPolicy returns a tenant's retention policy.
+func (c *Client) RetentionPolicy(ctx context.Context, accountKey string) (RetentionPolicy, error) {
+	var out RetentionPolicy
+	err := c.do(ctx, http.MethodGet, tenantPath(accountKey, "/retention"), nil, &out)
+	return out, err
+}
+
+// SetRetentionPolicy creates or replaces a tenant's retention policy.
+func (c *Client) SetRetentionPolicy(ctx context.Context, accountKey string, params RetentionPolicyParams) (RetentionPolicy, error) {
+	var out RetentionPolicy
+	err := c.do(ctx, http.MethodPut, tenantPath(accountKey, "/retention"), params, &out)
+	return out, err
+}
+
+// DeleteRetentionPolicy removes a tenant's retention policy so memories are kept indefinitely.
+func (c *Client) DeleteRetentionPolicy(ctx context.Context, accountKey string) error {
+	return c.do(ctx, http.MethodDelete, tenantPath(accountKey, "/retention"), nil, nil)
+}
+
+// WritePolicy returns a tenant's write policy.
+func (c *Client) WritePolicy(ctx context.Context, accountKey string) (WritePolicy, error) {
+	var out WritePolicy
+	err := c.do(ctx, http.MethodGet, tenantPath(accountKey, "/write-policy"), nil, &out)
+	return out, err
+}
+
+// SetWritePolicy creates or replaces a tenant's write policy.
+func (c *Client) SetWritePolicy(ctx context.Context, accountKey string, params WritePolicyParams) (WritePolicy, error) {
+	var out WritePolicy
+	err := c.do(ctx, http.MethodPut, tenantPath(accountKey, "/write-policy"), params, &out)
+	return out, err
+}
+
+// DeleteWritePolicy removes a tenant's write policy so agents may store anything.
+func (c *Client) DeleteWritePolicy(ctx context.Context, accountKey string) error {
+	return c.do(ctx, http.MethodDelete, tenantPath(accountKey, "/write-policy"), nil, nil)
+}
+
+// PipelineWebhook returns a tenant's transformation webhook.
+func (c *Client) PipelineWebhook(ctx context.Context, accountKey string) (PipelineWebhook, error) {
+	var out PipelineWebhook
+	err := c.do(ctx, http.MethodGet, tenantPath(accountKey, "/pipeline-webhook"), nil, &out)
+	return out, err
+}
+
+// SetPipelineWebhook creates or replaces a tenant's transformation webhook.
+func (c *Client) SetPipelineWebhook(ctx context.Context, accountKey string, params PipelineWebhookParams) (PipelineWebhook, error) {
+	var out PipelineWebhook
+	err := c.do(ctx, http.MethodPut, tenantPath(accountKey, "/pipeline-webhook"), params, &out)
+	return out, err
+}
+
+// DeletePipelineWebhook removes a tenant's transformation webhook.
+func (c *Client) DeletePipelineWebhook(ctx context.Context, accountKey string) error {
+	return c.do(ctx, http.MethodDelete, tenantPath(accountKey, "/pipeline-webhook"), nil, nil)
+}
+
+// IssueAPIKey issues a key for a tenant.
+func (c *Client) IssueAPIKey(ctx context.Context, accountKey string, params APIKeyParams) (IssuedAPIKey, error) {
+	var out IssuedAPIKey
+	err := c.do(ctx, http.MethodPost, tenantPath(accountKey, "/keys"), params, &out)
+	return out, err
+}
+
+// TenantAPIKey returns one of a tenant's keys without its secret.
+func (c *Client) TenantAPIKey(ctx context.Context, accountKey, keyID string) (APIKey, error) {
+	var out APIKey
+	err := c.do(ctx, http.MethodGet, tenantPath(accountKey, "/keys/"+url.PathEscape(keyID)), nil, &out)
+	return out, err
+}
+
+// RevokeTenantAPIKey revokes one of a tenant's keys.
+func (c *Client) RevokeTenantAPIKey(ctx context.Context, accountKey, keyID string) error {
+	path := tenantPath(accountKey, "/keys/"+url.PathEscape(keyID)+"/revoke")
+	return c.do(ctx, http.MethodPost, path, nil, nil)
+}
+
+func namespacePath(accountKey string) string {
+	return "/v1/admin/namespaces/" + url.PathEscape(accountKey)
+}
+
+func tenantPath(accountKey, suffix string) string {
+	return "/v1/admin/tenants/" + url.PathEscape(accountKey) + suffix
+}
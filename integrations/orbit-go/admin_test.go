@@ -0,0 +1,65 @@
+package orbitmemory
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminNamespaceAndRegistryCalls(t *testing.T) {
+	var requests []string
+	var registry EventTypeRegistryParams
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath())
+		switch r.URL.EscapedPath() {
+		case "/v1/admin/namespaces/acme%2Feu":
+			if r.Method == http.MethodDelete {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"account_key": "acme/eu",
+				"pipeline":    []string{"pii", "extraction", "embedding", "indexing"},
+			})
+		case "/v1/admin/tenants/acme%2Feu/event-types":
+			_ = json.NewDecoder(r.Body).Decode(&registry)
+			_ = json.NewEncoder(w).Encode(map[string]any{"account_key": "acme/eu", "enforce": true})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, Token: "admin"})
+	ctx := context.Background()
+	namespace, err := client.SetNamespace(ctx, "acme/eu", NamespaceParams{
+		Pipeline: []string{"pii", "extraction", "embedding", "indexing"},
+	})
+	if err != nil || len(namespace.Pipeline) != 4 {
+		t.Fatalf("unexpected namespace: %+v, %v", namespace, err)
+	}
+	if _, err := client.SetEventTypeRegistry(ctx, "acme/eu", EventTypeRegistryParams{Enforce: true}); err != nil {
+		t.Fatal(err)
+	}
+	if registry.EventTypes == nil || len(registry.EventTypes) != 0 || !registry.Enforce {
+		t.Fatalf("unexpected registry body: %+v", registry)
+	}
+	if err := client.DeleteNamespace(ctx, "acme/eu"); !IsNotFound(err) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	want := []string{
+		"PUT /v1/admin/namespaces/acme%2Feu",
+		"PUT /v1/admin/tenants/acme%2Feu/event-types",
+		"DELETE /v1/admin/namespaces/acme%2Feu",
+	}
+	if len(requests) != len(want) {
+		t.Fatalf("unexpected requests: %v", requests)
+	}
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Fatalf("request %d = %q, want %q", i, requests[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Package boltcache keeps recent Orbit retrieval results in a BoltDB file for
+// orbitmemory.WithRetrievalCache, so agents retain basic memory while Orbit is unreachable.
+package boltcache
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	orbitmemory "github.com/Intina47/orbit/integrations/orbit-go"
+)
+
+// DefaultMaxEntries is used when Options.MaxEntries is not positive.
+const DefaultMaxEntries = 1000
+
+var (
+	entriesBucket = []byte("retrievals")
+	// orderBucket indexes entries by store time (big-endian nanoseconds, then key) so the
+	// oldest can be evicted without decoding every entry.
+	orderBucket = []byte("retrievals_by_time")
+)
+
+// Options configures Open.
+type Options struct {
+	// MaxEntries caps how many results are kept; storing more evicts the oldest.
+	MaxEntries int
+	// Timeout bounds waiting for another process's lock on the file (default 1s).
+	Timeout time.Duration
+}
+
+// Cache is an orbitmemory.RetrievalCache backed by one BoltDB file. It is safe for
+// concurrent use; BoltDB allows one process to open the file at a time.
+type Cache struct {
+	db         *bbolt.DB
+	maxEntries int
+}
+
+var _ orbitmemory.RetrievalCache = (*Cache)(nil)
+
+// Open opens or creates the cache file at path.
+func Open(path string, opts Options) (*Cache, error) {
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = DefaultMaxEntries
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = time.Second
+	}
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: opts.Timeout})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range [][]byte{entriesBucket, orderBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &Cache{db: db, maxEntries: opts.MaxEntries}, nil
+}
+
+// Close releases the file.
+func (c *Cache) Close() error {
+	return c.db.Close()
+}
+
+// Load returns the entry stored under key.
+func (c *Cache) Load(_ context.Context, key string) (orbitmemory.CachedRetrieval, bool, error) {
+	var entry orbitmemory.CachedRetrieval
+	found := false
+	err := c.db.View(func(tx *bbolt.Tx) error {
+		raw := tx.Bucket(entriesBucket).Get([]byte(key))
+		if raw == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(raw, &entry)
+	})
+	return entry, found && err == nil, err
+}
+
+// Store saves entry under key, replacing any earlier result, and evicts the oldest entries
+// beyond MaxEntries.
+func (c *Cache) Store(_ context.Context, key string, entry orbitmemory.CachedRetrieval) error {
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		entries := tx.Bucket(entriesBucket)
+		order := tx.Bucket(orderBucket)
+		if previous := entries.Get([]byte(key)); previous != nil {
+			var old orbitmemory.CachedRetrieval
+			if json.Unmarshal(previous, &old) == nil {
+				if err := order.Delete(orderKey(old.StoredAt, key)); err != nil {
+					return err
+				}
+			}
+		}
+		if err := entries.Put([]byte(key), encoded); err != nil {
+			return err
+		}
+		if err := order.Put(orderKey(entry.StoredAt, key), []byte(key)); err != nil {
+			return err
+		}
+		return c.evict(entries, order)
+	})
+}
+
+func (c *Cache) evict(entries, order *bbolt.Bucket) error {
+	cursor := order.Cursor()
+	count := 0
+	for k, _ := cursor.First(); k != nil; k, _ = cursor.Next() {
+		count++
+	}
+	for ; count > c.maxEntries; count-- {
+		k, v := cursor.First()
+		if err := entries.Delete(v); err != nil {
+			return err
+		}
+		if err := order.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func orderKey(storedAt time.Time, key string) []byte {
+	buf := make([]byte, 8, 8+len(key))
+	binary.BigEndian.PutUint64(buf, uint64(storedAt.UnixNano()))
+	return append(buf, key...)
+}
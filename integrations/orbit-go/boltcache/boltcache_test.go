@@ -0,0 +1,100 @@
+package boltcache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	orbitmemory "github.com/Intina47/orbit/integrations/orbit-go"
+)
+
+func entry(content string, storedAt time.Time) orbitmemory.CachedRetrieval {
+	return orbitmemory.CachedRetrieval{
+		Memories: []orbitmemory.Memory{{MemoryID: content, Content: content}},
+		StoredAt: storedAt,
+	}
+}
+
+func TestCacheStoresLoadsAndEvictsOldest(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cache.db")
+	cache, err := Open(path, Options{MaxEntries: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	if _, found, err := cache.Load(ctx, "a"); found || err != nil {
+		t.Fatalf("empty cache Load = %v, %v", found, err)
+	}
+	for i, key := range []string{"a", "b"} {
+		if err := cache.Store(ctx, key, entry(key, start.Add(time.Duration(i)*time.Minute))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Replacing "a" makes it the newest, so "b" is evicted when "c" arrives.
+	if err := cache.Store(ctx, "a", entry("a2", start.Add(2*time.Minute))); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Store(ctx, "c", entry("c", start.Add(3*time.Minute))); err != nil {
+		t.Fatal(err)
+	}
+	if _, found, _ := cache.Load(ctx, "b"); found {
+		t.Fatal("oldest entry was not evicted")
+	}
+	got, found, err := cache.Load(ctx, "a")
+	if err != nil || !found || got.Memories[0].Content != "a2" || !got.StoredAt.Equal(start.Add(2*time.Minute)) {
+		t.Fatalf("Load(a) = %+v, %v, %v", got, found, err)
+	}
+	if err := cache.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := Open(path, Options{MaxEntries: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	if _, found, _ := reopened.Load(ctx, "c"); !found {
+		t.Fatal("entries did not survive reopening the file")
+	}
+}
+
+func TestClientSkipsEntriesOlderThanMaxAge(t *testing.T) {
+	ctx := context.Background()
+	cache, err := Open(filepath.Join(t.TempDir(), "cache.db"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+	up := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"memories":[{"memory_id":"m1","content":"Alice prefers Go"}]}`))
+	}))
+	defer server.Close()
+
+	params := orbitmemory.RetrieveParams{Query: "language"}
+	fresh := orbitmemory.NewClient(orbitmemory.Config{BaseURL: server.URL},
+		orbitmemory.WithRetrievalCache(cache, time.Hour))
+	if _, err := fresh.Retrieve(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+	up = false
+	memories, err := fresh.Retrieve(ctx, params)
+	if err != nil || len(memories) != 1 || !memories[0].Stale {
+		t.Fatalf("fresh entry: memories = %+v, err = %v", memories, err)
+	}
+
+	expired := orbitmemory.NewClient(orbitmemory.Config{BaseURL: server.URL},
+		orbitmemory.WithRetrievalCache(cache, time.Nanosecond))
+	time.Sleep(time.Millisecond)
+	if _, err := expired.Retrieve(ctx, params); err == nil {
+		t.Fatal("an entry older than maxAge was served")
+	}
+}
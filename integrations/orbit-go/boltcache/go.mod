@@ -0,0 +1,12 @@
+module github.com/Intina47/orbit/integrations/orbit-go/boltcache
+
+go 1.22
+
+require (
+	github.com/Intina47/orbit/integrations/orbit-go v0.0.0
+	go.etcd.io/bbolt v1.3.11
+)
+
+require golang.org/x/sys v0.4.0 // indirect
+
+replace github.com/Intina47/orbit/integrations/orbit-go => ../
@@ -0,0 +1,162 @@
+package orbitmemory
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// MaxIngestBatchSize is the most events POST /v1/ingest/batch accepts per call.
+const MaxIngestBatchSize = 100
+
+const defaultIngestConcurrency = 4
+
+// IngestAllOption configures IngestAll.
+type IngestAllOption func(*ingestAllConfig)
+
+type ingestAllConfig struct {
+	concurrency int
+	batchSize   int
+	onProgress  func(IngestProgress)
+}
+
+// WithConcurrency sets how many batches are in flight at once (default 4).
+func WithConcurrency(n int) IngestAllOption {
+	return func(cfg *ingestAllConfig) {
+		if n > 0 {
+			cfg.concurrency = n
+		}
+	}
+}
+
+// WithBatchSize sets how many items each batch request carries, up to MaxIngestBatchSize
+// (the default).
+func WithBatchSize(n int) IngestAllOption {
+	return func(cfg *ingestAllConfig) {
+		if n > 0 {
+			cfg.batchSize = min(n, MaxIngestBatchSize)
+		}
+	}
+}
+
+// WithProgress registers fn to be called after every batch. Calls are serialized.
+func WithProgress(fn func(IngestProgress)) IngestAllOption {
+	return func(cfg *ingestAllConfig) {
+		cfg.onProgress = fn
+	}
+}
+
+// IngestProgress counts the items IngestAll has finished so far.
+type IngestProgress struct {
+	Succeeded int
+	Failed    int
+	Total     int
+}
+
+// IngestItemError is the failure of items[Index]. Items sent in the same batch share Err.
+type IngestItemError struct {
+	Index int
+	Err   error
+}
+
+func (e IngestItemError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+func (e IngestItemError) Unwrap() error {
+	return e.Err
+}
+
+// IngestAllError lists every item IngestAll could not ingest, ordered by index.
+type IngestAllError struct {
+	Errors []IngestItemError
+	Total  int
+}
+
+func (e *IngestAllError) Error() string {
+	return fmt.Sprintf("orbit: %d of %d items failed; first: %v", len(e.Errors), e.Total, e.Errors[0])
+}
+
+// IngestAll ingests items through POST /v1/ingest/batch with bounded parallelism. Results
+// line up with items; a failed item's result is the zero value and its error is listed in
+// the returned *IngestAllError. Items not yet sent when ctx is cancelled fail with ctx.Err(),
+// and a batch holding an item that fails validation is not sent.
+func (c *Client) IngestAll(ctx context.Context, items []IngestParams, opts ...IngestAllOption) ([]IngestResult, error) {
+	cfg := ingestAllConfig{concurrency: defaultIngestConcurrency, batchSize: MaxIngestBatchSize}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	results := make([]IngestResult, len(items))
+	var (
+		mu        sync.Mutex
+		failures  []IngestItemError
+		succeeded int
+	)
+	finish := func(start, end int, out []IngestResult, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			for index := start; index < end; index++ {
+				failures = append(failures, IngestItemError{Index: index, Err: err})
+			}
+		} else {
+			copy(results[start:end], out)
+			succeeded += end - start
+		}
+		if cfg.onProgress != nil {
+			cfg.onProgress(IngestProgress{Succeeded: succeeded, Failed: len(failures), Total: len(items)})
+		}
+	}
+
+	starts := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < cfg.concurrency; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range starts {
+				end := min(start+cfg.batchSize, len(items))
+				out, err := c.ingestBatch(ctx, items[start:end])
+				finish(start, end, out, err)
+			}
+		}()
+	}
+dispatch:
+	for start := 0; start < len(items); start += cfg.batchSize {
+		select {
+		case starts <- start:
+		case <-ctx.Done():
+			finish(start, len(items), nil, ctx.Err())
+			break dispatch
+		}
+	}
+	close(starts)
+	wg.Wait()
+
+	if len(failures) == 0 {
+		return results, nil
+	}
+	sort.Slice(failures, func(i, j int) bool { return failures[i].Index < failures[j].Index })
+	return results, &IngestAllError{Errors: failures, Total: len(items)}
+}
+
+func (c *Client) ingestBatch(ctx context.Context, items []IngestParams) ([]IngestResult, error) {
+	for _, item := range items {
+		if err := c.validateIngest(item); err != nil {
+			return nil, err
+		}
+	}
+	var out struct {
+		Items []IngestResult `json:"items"`
+	}
+	payload := map[string]any{"events": items}
+	if err := c.do(ctx, http.MethodPost, "/v1/ingest/batch", payload, &out); err != nil {
+		return nil, err
+	}
+	if len(out.Items) != len(items) {
+		return nil, fmt.Errorf("orbit: batch returned %d results for %d events", len(out.Items), len(items))
+	}
+	return out.Items, nil
+}
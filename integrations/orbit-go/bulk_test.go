@@ -0,0 +1,99 @@
+package orbitmemory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestIngestAllBatchesConcurrentlyAndCollectsItemErrors(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/ingest/batch" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := maxInFlight.Load()
+			if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		if current == 2 {
+			close(release)
+		}
+		<-release
+		var body struct {
+			Events []IngestParams `json:"events"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if len(body.Events) > 3 {
+			t.Errorf("batch too large: %d", len(body.Events))
+		}
+		items := make([]map[string]any, 0, len(body.Events))
+		for _, event := range body.Events {
+			if event.Content == "event 4" {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				_, _ = w.Write([]byte(`{"detail":"rejected"}`))
+				return
+			}
+			items = append(items, map[string]any{"memory_id": "m-" + event.Content, "stored": true})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"items": items})
+	}))
+	defer server.Close()
+
+	items := make([]IngestParams, 10)
+	for index := range items {
+		items[index] = IngestParams{Content: fmt.Sprintf("event %d", index), EntityID: "alice"}
+	}
+	var progress []IngestProgress
+	client := NewClient(Config{BaseURL: server.URL, Token: "orbit_pk_test"})
+	results, err := client.IngestAll(
+		context.Background(),
+		items,
+		WithConcurrency(2),
+		WithBatchSize(3),
+		WithProgress(func(p IngestProgress) { progress = append(progress, p) }),
+	)
+
+	var allErr *IngestAllError
+	if !errors.As(err, &allErr) || allErr.Total != 10 || len(allErr.Errors) != 3 {
+		t.Fatalf("expected 3 failed items, got %v", err)
+	}
+	for offset, itemErr := range allErr.Errors {
+		var apiErr *APIError
+		if itemErr.Index != 3+offset || !errors.As(itemErr, &apiErr) || apiErr.StatusCode != http.StatusUnprocessableEntity {
+			t.Fatalf("unexpected item error: %+v", itemErr)
+		}
+	}
+	if results[0].MemoryID != "m-event 0" || results[9].MemoryID != "m-event 9" || results[4].MemoryID != "" {
+		t.Fatalf("results not aligned with items: %+v", results)
+	}
+	if maxInFlight.Load() != 2 {
+		t.Fatalf("expected 2 batches in flight, saw %d", maxInFlight.Load())
+	}
+	last := progress[len(progress)-1]
+	if len(progress) != 4 || last != (IngestProgress{Succeeded: 7, Failed: 3, Total: 10}) {
+		t.Fatalf("unexpected progress: %+v", progress)
+	}
+}
+
+func TestIngestAllFailsUnsentItemsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client := NewClient(Config{BaseURL: "http://127.0.0.1:1"})
+	_, err := client.IngestAll(ctx, make([]IngestParams, 5))
+	var allErr *IngestAllError
+	if !errors.As(err, &allErr) || len(allErr.Errors) != 5 || !errors.Is(allErr.Errors[0], context.Canceled) {
+		t.Fatalf("expected every item to fail with context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package orbitmemory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting Orbit while the circuit breaker is open.
+var ErrCircuitOpen = errors.New("orbit: circuit breaker open")
+
+// CircuitState is the state of a client's circuit breaker.
+type CircuitState string
+
+// Circuit breaker states. A half-open circuit lets one trial request through; its
+// outcome closes the circuit or reopens it for another Cooldown.
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitOpen     CircuitState = "open"
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// CircuitBreakerConfig configures WithCircuitBreaker.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is how many consecutive failed calls open the circuit (default 5).
+	// Transport errors and the statuses MaxRetries would retry count as failures, after
+	// retries, as do exceeded context deadlines; other 4xx responses and cancelled contexts
+	// do not.
+	FailureThreshold int
+	// Cooldown is how long the circuit stays open before a trial call (default 30s).
+	Cooldown time.Duration
+	// Fallback, when set, runs instead of each fast-failed call and its result becomes the
+	// call's error. Returning nil makes the call succeed with an empty result, so Retrieve
+	// returns no memories and the agent turn carries on without them.
+	Fallback func(ctx context.Context, method, path string) error
+}
+
+// WithCircuitBreaker makes the client stop calling Orbit after FailureThreshold consecutive
+// failures, failing fast with ErrCircuitOpen (or Fallback's result) until Cooldown passes.
+func WithCircuitBreaker(cfg CircuitBreakerConfig) Option {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 30 * time.Second
+	}
+	return func(c *Client) {
+		c.breaker = &circuitBreaker{cfg: cfg, state: CircuitClosed, now: time.Now}
+	}
+}
+
+// CircuitState reports the circuit breaker's state; it is always CircuitClosed without
+// WithCircuitBreaker.
+func (c *Client) CircuitState() CircuitState {
+	if c.breaker == nil {
+		return CircuitClosed
+	}
+	return c.breaker.current()
+}
+
+type circuitBreaker struct {
+	cfg CircuitBreakerConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	trialOut bool
+}
+
+func (b *circuitBreaker) current() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.cfg.Cooldown {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// allow reports whether a call may go out, moving an expired open circuit to half-open.
+func (b *circuitBreaker) allow() (bool, CircuitState) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.cfg.Cooldown {
+		b.state = CircuitHalfOpen
+	}
+	switch b.state {
+	case CircuitOpen:
+		return false, b.state
+	case CircuitHalfOpen:
+		if b.trialOut {
+			return false, b.state
+		}
+		b.trialOut = true
+	}
+	return true, b.state
+}
+
+// record applies a call's outcome and returns the new state when it changed.
+func (b *circuitBreaker) record(outcome callOutcome) (CircuitState, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	previous := b.state
+	if b.state == CircuitHalfOpen {
+		b.trialOut = false
+	}
+	switch outcome {
+	case outcomeSuccess:
+		b.failures = 0
+		b.state = CircuitClosed
+	case outcomeFailure:
+		b.failures++
+		if b.state == CircuitHalfOpen || b.failures >= b.cfg.FailureThreshold {
+			b.state = CircuitOpen
+			b.openedAt = b.now()
+		}
+	}
+	return b.state, b.state != previous
+}
+
+type callOutcome int
+
+const (
+	outcomeSuccess callOutcome = iota
+	outcomeFailure
+	// outcomeIgnored is a call cancelled by its caller, which says nothing about Orbit.
+	// Deadlines still count as failures: a slow deployment is what the breaker guards against.
+	outcomeIgnored
+)
+
+func classifyOutcome(ctx context.Context, status int, err error) callOutcome {
+	switch {
+	case err == nil:
+		return outcomeSuccess
+	case errors.Is(ctx.Err(), context.Canceled):
+		return outcomeIgnored
+	case errors.Is(err, context.DeadlineExceeded), retryable(status, err):
+		return outcomeFailure
+	case status != 0:
+		return outcomeSuccess
+	}
+	return outcomeIgnored
+}
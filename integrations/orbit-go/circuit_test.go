@@ -0,0 +1,98 @@
+package orbitmemory
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerTripsFailsFastAndRecovers(t *testing.T) {
+	var healthy atomic.Bool
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		switch {
+		case r.URL.Path == "/v1/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case healthy.Load():
+			_, _ = w.Write([]byte(`{"memories": [{"memory_id": "m1"}]}`))
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL}, WithCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold: 2,
+		Cooldown:         time.Hour,
+	}))
+	now := time.Now()
+	client.breaker.now = func() time.Time { return now }
+	ctx := context.Background()
+	params := RetrieveParams{Query: "q"}
+
+	// Client errors say nothing about Orbit's health and do not count.
+	_ = client.do(ctx, http.MethodGet, "/v1/missing", nil, nil)
+	for range 2 {
+		if _, err := client.Retrieve(ctx, params); errors.Is(err, ErrCircuitOpen) {
+			t.Fatal("circuit opened early")
+		}
+	}
+	if client.CircuitState() != CircuitOpen {
+		t.Fatalf("expected open circuit, got %s", client.CircuitState())
+	}
+	if _, err := client.Retrieve(ctx, params); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("open circuit still called Orbit: %d calls", calls.Load())
+	}
+
+	// A failed trial reopens the circuit; a successful one closes it.
+	now = now.Add(time.Hour)
+	if client.CircuitState() != CircuitHalfOpen {
+		t.Fatalf("expected half-open circuit, got %s", client.CircuitState())
+	}
+	_, _ = client.Retrieve(ctx, params)
+	if client.CircuitState() != CircuitOpen {
+		t.Fatalf("failed trial left circuit %s", client.CircuitState())
+	}
+	now = now.Add(time.Hour)
+	healthy.Store(true)
+	if memories, err := client.Retrieve(ctx, params); err != nil || len(memories) != 1 {
+		t.Fatalf("trial call failed: %v", err)
+	}
+	if client.CircuitState() != CircuitClosed {
+		t.Fatalf("expected closed circuit, got %s", client.CircuitState())
+	}
+}
+
+func TestCircuitBreakerFallbackReplacesFastFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	var fellBack []string
+	client := NewClient(Config{BaseURL: server.URL}, WithCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold: 1,
+		Fallback: func(_ context.Context, method, path string) error {
+			fellBack = append(fellBack, method+" "+path)
+			return nil
+		},
+	}))
+	if _, err := client.Ingest(context.Background(), IngestParams{Content: "x"}); err == nil {
+		t.Fatal("expected the first failure to surface")
+	}
+	memories, err := client.Retrieve(context.Background(), RetrieveParams{Query: "q"})
+	if err != nil || memories != nil {
+		t.Fatalf("expected an empty fallback result, got %v, %v", memories, err)
+	}
+	if len(fellBack) != 1 || fellBack[0] != "GET /v1/retrieve?query=q" {
+		t.Fatalf("unexpected fallback calls: %v", fellBack)
+	}
+}
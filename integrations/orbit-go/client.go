@@ -0,0 +1,489 @@
+// Package orbitmemory is a small Orbit REST client shared by the Go agent-framework adapters.
+package orbitmemory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBaseURL is used when Config.BaseURL is empty.
+const DefaultBaseURL = "http://127.0.0.1:8000"
+
+// Config configures a Client. Token is an Orbit JWT or an orbit_pk_ API key; when
+// SigningKeyID and SigningSecret are set, requests are HMAC-signed instead.
+// EventTypes lists custom event types Ingest accepts besides the built-in Event* constants.
+// MaxRetries (default 0) retries transport errors, 408, 425, 429 and 5xx responses, waiting
+// RetryBackoff (default 500ms) doubled per attempt, or the response's Retry-After.
+// Transport tunes the client's own transport and is ignored when HTTPClient is set.
+// Endpoints, when set, replaces BaseURL with several deployments, typically one per region:
+// a call that fails on one with a transport error, 408 or 5xx moves on to the next healthy one
+// under Routing (default RoutingFailover), and a down endpoint is probed again after
+// EndpointCooldown (default 30s).
+type Config struct {
+	BaseURL          string
+	Token            string
+	SigningKeyID     string
+	SigningSecret    string
+	HTTPClient       *http.Client
+	Transport        TransportConfig
+	EventTypes       []EventType
+	MaxRetries       int
+	RetryBackoff     time.Duration
+	Endpoints        []Endpoint
+	Routing          Routing
+	EndpointCooldown time.Duration
+}
+
+// Client calls the Orbit REST API.
+type Client struct {
+	baseURL       string
+	token         string
+	signingKeyID  string
+	signingSecret string
+	httpClient    *http.Client
+	eventTypes    []EventType
+	maxRetries    int
+	retryBackoff  time.Duration
+	logger        *slog.Logger
+	logLevels     LogLevels
+	breaker       *circuitBreaker
+	endpoints     *endpointPool
+
+	responseHook       func(context.Context, ResponseMetadata)
+	deprecationsLogged sync.Map
+
+	retrievalCache       RetrievalCache
+	retrievalCacheMaxAge time.Duration
+}
+
+// Option configures optional Client behaviour in NewClient.
+type Option func(*Client)
+
+// Memory is one retrieved memory.
+type Memory struct {
+	MemoryID             string         `json:"memory_id"`
+	Content              string         `json:"content"`
+	RankPosition         int            `json:"rank_position"`
+	RankScore            float64        `json:"rank_score"`
+	ImportanceScore      float64        `json:"importance_score"`
+	Timestamp            time.Time      `json:"timestamp"`
+	Metadata             map[string]any `json:"metadata"`
+	RelevanceExplanation string         `json:"relevance_explanation"`
+	// Version is the content version, set by UpdateMemory; send it back as its version.
+	Version int `json:"version,omitempty"`
+	// Stale is set on memories WithRetrievalCache served because Orbit was unreachable.
+	Stale bool `json:"-"`
+}
+
+// RetrieveParams mirrors the GET /v1/retrieve query parameters.
+type RetrieveParams struct {
+	Query    string
+	EntityID string
+	Limit    int
+	// MaxLatencyMs caps server-side retrieval time; optional stages are skipped
+	// once it is spent and partial results are returned.
+	MaxLatencyMs int
+	// Mode is "vector" (default) or "graph"; graph mode also returns memories
+	// connected to the vector hits through shared entities, up to GraphHops away.
+	Mode      RetrievalMode
+	GraphHops int
+	// TopicID restricts retrieval to one of the entity's topics; requires EntityID.
+	TopicID string
+	// MinScore drops memories ranked below it. Fallback ("empty", "recent",
+	// "attributes", or "webhook") chooses what happens when nothing is left.
+	MinScore float64
+	Fallback Fallback
+	// Consistency "strong" makes the retrieve see every memory ingested before
+	// it; the default "eventual" may miss ones still being indexed.
+	Consistency Consistency
+	// Profile applies a preset under the options set here. Options left zero take the
+	// namespace's retrieval defaults, if its admin set any.
+	Profile Profile
+	// Boost multiplies the scores of memories that match the caller's current context.
+	Boost *Boost
+	// MMR picks results by maximal marginal relevance, so near-duplicates of a result already
+	// picked fall behind. MMRLambda weighs relevance against diversity, from 0 to 1 where 1 is
+	// the plain ranking; zero keeps the server's default of 0.5.
+	MMR       bool
+	MMRLambda float64
+}
+
+// Boost maps tags, event types, and categories to score multipliers between 0 and 10. A
+// memory matching several keys is multiplied by each; a multiplier below 1 demotes.
+type Boost struct {
+	Tags      map[string]float64 `json:"tags,omitempty"`
+	EventType map[string]float64 `json:"event_type,omitempty"`
+	Category  map[string]float64 `json:"category,omitempty"`
+}
+
+// IngestParams mirrors the POST /v1/ingest body.
+type IngestParams struct {
+	Content   string         `json:"content"`
+	EventType EventType      `json:"event_type,omitempty"`
+	EntityID  string         `json:"entity_id,omitempty"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+	// Sensitivity is "public", "internal", or "confidential"; keys without the
+	// matching memory:<label> scope cannot retrieve the memory.
+	Sensitivity Sensitivity `json:"sensitivity,omitempty"`
+	// OnOversize overrides the server's ORBIT_ON_OVERSIZE for content over its size limit.
+	OnOversize OversizeAction `json:"on_oversize,omitempty"`
+	// OccurredAt is when the event happened, for backdated imports; nil uses the server
+	// clock. The server rejects times too far ahead of its clock or past its backfill window.
+	OccurredAt *time.Time `json:"occurred_at,omitempty"`
+	// IdempotencyKey is sent as the Idempotency-Key header, so retrying an ingest whose
+	// response was lost returns the original result instead of storing it twice.
+	IdempotencyKey string `json:"-"`
+}
+
+// IngestResult is the POST /v1/ingest response.
+type IngestResult struct {
+	MemoryID        string  `json:"memory_id"`
+	Stored          bool    `json:"stored"`
+	ImportanceScore float64 `json:"importance_score"`
+	DecisionReason  string  `json:"decision_reason"`
+	// OversizeAction is "summarize" or "chunk" when the content was over the size limit;
+	// chunking stores one memory per chunk, listed in ChunkMemoryIDs.
+	OversizeAction string   `json:"oversize_action"`
+	ChunkMemoryIDs []string `json:"chunk_memory_ids"`
+}
+
+// APIError is returned for non-2xx responses. RequestID is the response's X-Request-ID,
+// which identifies the call in Orbit's logs.
+type APIError struct {
+	StatusCode int
+	Body       string
+	RequestID  string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("orbit: HTTP %d: %s", e.StatusCode, e.Body)
+}
+
+// NewClient builds a Client, defaulting the base URL and an HTTP/2-capable client with a
+// 15s timeout.
+func NewClient(cfg Config, opts ...Option) *Client {
+	endpoints := resolveEndpoints(cfg)
+	routing := cfg.Routing
+	if routing == "" {
+		routing = RoutingFailover
+	}
+	cooldown := cfg.EndpointCooldown
+	if cooldown <= 0 {
+		cooldown = 30 * time.Second
+	}
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 15 * time.Second, Transport: newTransport(cfg.Transport)}
+	}
+	retryBackoff := cfg.RetryBackoff
+	if retryBackoff <= 0 {
+		retryBackoff = 500 * time.Millisecond
+	}
+	c := &Client{
+		baseURL:       endpoints[0].BaseURL,
+		token:         cfg.Token,
+		signingKeyID:  cfg.SigningKeyID,
+		signingSecret: cfg.SigningSecret,
+		httpClient:    httpClient,
+		eventTypes:    append(append([]EventType{}, knownEventTypes...), cfg.EventTypes...),
+		maxRetries:    max(cfg.MaxRetries, 0),
+		retryBackoff:  retryBackoff,
+		logLevels:     defaultLogLevels,
+		endpoints:     newEndpointPool(endpoints, routing, cooldown),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Retrieve returns memories ranked for params.Query.
+func (c *Client) Retrieve(ctx context.Context, params RetrieveParams) ([]Memory, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	query.Set("query", params.Query)
+	if params.EntityID != "" {
+		query.Set("entity_id", params.EntityID)
+	}
+	if params.Limit > 0 {
+		query.Set("limit", strconv.Itoa(params.Limit))
+	}
+	if params.MaxLatencyMs > 0 {
+		query.Set("max_latency_ms", strconv.Itoa(params.MaxLatencyMs))
+	}
+	if params.Mode != "" {
+		query.Set("mode", string(params.Mode))
+	}
+	if params.GraphHops > 0 {
+		query.Set("graph_hops", strconv.Itoa(params.GraphHops))
+	}
+	if params.TopicID != "" {
+		query.Set("topic_id", params.TopicID)
+	}
+	if params.MinScore > 0 {
+		query.Set("min_score", strconv.FormatFloat(params.MinScore, 'f', -1, 64))
+	}
+	if params.Fallback != "" {
+		query.Set("fallback", string(params.Fallback))
+	}
+	if params.Consistency != "" {
+		query.Set("consistency", string(params.Consistency))
+	}
+	if params.Profile != "" {
+		query.Set("profile", string(params.Profile))
+	}
+	if params.MMR {
+		query.Set("mmr", "true")
+	}
+	if params.MMRLambda > 0 {
+		query.Set("mmr_lambda", strconv.FormatFloat(params.MMRLambda, 'f', -1, 64))
+	}
+	if params.Boost != nil {
+		boost, err := json.Marshal(params.Boost)
+		if err != nil {
+			return nil, err
+		}
+		query.Set("boost", string(boost))
+	}
+	var out struct {
+		Memories []Memory `json:"memories"`
+	}
+	path := "/v1/retrieve?" + query.Encode()
+	if err := c.do(ctx, http.MethodGet, path, nil, &out); err != nil {
+		if c.retrievalCache != nil {
+			if memories, ok := c.staleRetrieval(ctx, path, err); ok {
+				return memories, nil
+			}
+		}
+		return nil, err
+	}
+	if c.retrievalCache != nil {
+		c.storeRetrieval(ctx, path, out.Memories)
+	}
+	return out.Memories, nil
+}
+
+// Ingest stores one event.
+func (c *Client) Ingest(ctx context.Context, params IngestParams) (IngestResult, error) {
+	var out IngestResult
+	if err := c.validateIngest(params); err != nil {
+		return out, err
+	}
+	var header http.Header
+	if params.IdempotencyKey != "" {
+		header = http.Header{"Idempotency-Key": {params.IdempotencyKey}}
+	}
+	err := c.doWithHeader(ctx, http.MethodPost, "/v1/ingest", header, params, &out)
+	return out, err
+}
+
+// Do calls any Orbit endpoint, for ones the client has no typed method for yet. path is
+// relative to BaseURL and may carry a query string. in, unless nil, is sent as the JSON body,
+// and a JSON response is decoded into out unless out is nil. Auth, signing, retries, the
+// circuit breaker, logging, and *APIError mapping apply as they do to typed methods.
+func (c *Client) Do(ctx context.Context, method, path string, in, out any) error {
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("orbit: path %q must start with /", path)
+	}
+	return c.do(ctx, strings.ToUpper(method), path, in, out)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, payload, out any) error {
+	return c.doWithHeader(ctx, method, path, nil, payload, out)
+}
+
+// apiRequest is one call as sent on every attempt.
+type apiRequest struct {
+	method  string
+	path    string
+	header  http.Header
+	body    []byte
+	hasBody bool
+}
+
+// doWithHeader is do with extra request headers, such as If-Match or Idempotency-Key.
+func (c *Client) doWithHeader(ctx context.Context, method, path string, header http.Header, payload, out any) error {
+	var encoded []byte
+	if payload != nil {
+		var err error
+		encoded, err = json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+	}
+	if c.breaker != nil {
+		allowed, state := c.breaker.allow()
+		if !allowed {
+			c.logEvent(ctx, c.logLevels.Circuit, "orbit circuit open, failing fast",
+				"method", method, "path", path, "state", state)
+			if c.breaker.cfg.Fallback != nil {
+				return c.breaker.cfg.Fallback(ctx, method, path)
+			}
+			return ErrCircuitOpen
+		}
+	}
+	started := time.Now()
+	c.logEvent(ctx, c.logLevels.Request, "orbit request started", "method", method, "path", path)
+	call := apiRequest{method: method, path: path, header: header, body: encoded, hasBody: payload != nil}
+	attempts, status, err := c.doWithRetries(ctx, call, out)
+	if c.breaker != nil {
+		if state, changed := c.breaker.record(classifyOutcome(ctx, status, err)); changed {
+			c.logEvent(ctx, c.logLevels.Circuit, "orbit circuit state changed", "state", state)
+		}
+	}
+	level := c.logLevels.Request
+	args := []any{"method", method, "path", path, "status", status, "attempts", attempts,
+		"duration", time.Since(started)}
+	if err != nil {
+		level = c.logLevels.Failure
+		args = append(args, "error", err)
+	}
+	c.logEvent(ctx, level, "orbit request finished", args...)
+	return err
+}
+
+// doWithRetries sends the request up to MaxRetries+1 times, backing off between
+// retryable failures and honouring Retry-After on 429s. A call that fails on one endpoint
+// moves to another healthy one straight away, without spending a retry.
+func (c *Client) doWithRetries(ctx context.Context, call apiRequest, out any) (int, int, error) {
+	method, path := call.method, call.path
+	// Endpoints that failed since the last backoff.
+	failed := map[int]bool{}
+	sent := 0
+	for attempt := 0; ; {
+		c.probeEndpoints(ctx)
+		index := c.endpoints.pick(failed)
+		endpoint := c.endpoints.endpoint(index)
+		started := time.Now()
+		status, retryAfter, err := c.send(ctx, endpoint, call, out)
+		sent++
+		switch {
+		case endpointFailure(ctx, status, err):
+			c.endpoints.recordFailure(index)
+			failed[index] = true
+			if c.endpoints.hasAlternative(failed) {
+				c.logEvent(ctx, c.logLevels.Retry, "orbit endpoint failing over",
+					"method", method, "path", path, "endpoint", endpoint.BaseURL, "error", err)
+				continue
+			}
+		case status != 0:
+			c.endpoints.recordSuccess(index, time.Since(started))
+		}
+		if err == nil || attempt >= c.maxRetries || ctx.Err() != nil || !retryable(status, err) {
+			return sent, status, err
+		}
+		clear(failed)
+		delay := c.retryBackoff << attempt
+		if retryAfter > 0 {
+			delay = retryAfter
+		}
+		if status == http.StatusTooManyRequests {
+			c.logEvent(ctx, c.logLevels.RateLimit, "orbit rate limited, waiting",
+				"method", method, "path", path, "attempt", attempt+1, "delay", delay)
+		} else {
+			c.logEvent(ctx, c.logLevels.Retry, "orbit request retrying",
+				"method", method, "path", path, "attempt", attempt+1, "delay", delay, "error", err)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return sent, status, ctx.Err()
+		case <-timer.C:
+		}
+		attempt++
+	}
+}
+
+// send makes one HTTP attempt and returns the response status (0 when none was received)
+// and its Retry-After delay.
+func (c *Client) send(ctx context.Context, endpoint Endpoint, call apiRequest, out any) (int, time.Duration, error) {
+	var body io.Reader
+	if call.hasBody {
+		body = bytes.NewReader(call.body)
+	}
+	req, err := http.NewRequestWithContext(ctx, call.method, endpoint.BaseURL+call.path, body)
+	if err != nil {
+		return 0, 0, err
+	}
+	for name, values := range call.header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	setTraceHeaders(ctx, req.Header)
+	switch {
+	case c.signingKeyID != "" && c.signingSecret != "":
+		if err := c.signRequest(req, call.body); err != nil {
+			return 0, 0, err
+		}
+	case endpoint.Token != "":
+		req.Header.Set("Authorization", "Bearer "+endpoint.Token)
+	case c.token != "":
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if call.hasBody {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	c.recordResponse(ctx, call, resp)
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, 0, err
+	}
+	if resp.StatusCode >= 400 {
+		return resp.StatusCode, retryAfter(resp.Header), &APIError{
+			StatusCode: resp.StatusCode,
+			Body:       strings.TrimSpace(string(respBody)),
+			RequestID:  resp.Header.Get(RequestIDHeader),
+		}
+	}
+	if len(respBody) == 0 || out == nil {
+		return resp.StatusCode, 0, nil
+	}
+	return resp.StatusCode, 0, json.Unmarshal(respBody, out)
+}
+
+func retryable(status int, err error) bool {
+	if status == 0 {
+		var urlErr *url.Error
+		return errors.As(err, &urlErr)
+	}
+	switch status {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func retryAfter(header http.Header) time.Duration {
+	raw := strings.TrimSpace(header.Get("Retry-After"))
+	if raw == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(raw, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if at, err := http.ParseTime(raw); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
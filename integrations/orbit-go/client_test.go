@@ -0,0 +1,251 @@
+package orbitmemory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRetrieveAndRememberTurns(t *testing.T) {
+	var ingested []IngestParams
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer orbit_pk_test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/retrieve":
+			if r.URL.Query().Get("entity_id") != "alice" || r.URL.Query().Get("limit") != "3" {
+				t.Errorf("unexpected query: %s", r.URL.RawQuery)
+			}
+			if boost := r.URL.Query().Get("boost"); boost != `{"tags":{"project-x":1.5}}` {
+				t.Errorf("unexpected boost: %s", boost)
+			}
+			if r.URL.Query().Get("mmr") != "true" || r.URL.Query().Get("mmr_lambda") != "0.7" {
+				t.Errorf("unexpected mmr: %s", r.URL.RawQuery)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"memories": []map[string]any{{"memory_id": "m1", "content": "Alice prefers Go"}},
+			})
+		case "/v1/ingest":
+			var params IngestParams
+			_ = json.NewDecoder(r.Body).Decode(&params)
+			ingested = append(ingested, params)
+			_ = json.NewEncoder(w).Encode(map[string]any{"memory_id": "m2", "stored": true})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL + "/", Token: "orbit_pk_test"})
+	memories, err := client.Retrieve(context.Background(), RetrieveParams{
+		Query: "language", EntityID: "alice", Limit: 3,
+		Boost: &Boost{Tags: map[string]float64{"project-x": 1.5}},
+		MMR:   true, MMRLambda: 0.7,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(memories) != 1 || memories[0].Content != "Alice prefers Go" {
+		t.Fatalf("unexpected memories: %+v", memories)
+	}
+	if got := FormatMemories(memories); got != "Relevant memories about this user (from Orbit):\n- Alice prefers Go" {
+		t.Fatalf("unexpected block: %q", got)
+	}
+
+	err = client.RememberTurns(context.Background(), "alice", "eino", []Turn{
+		{Role: "system", Content: "ignored"},
+		{Role: "user", Content: "Which language?"},
+		{Role: "assistant", Content: "Go."},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ingested) != 2 || ingested[0].EventType != UserEventType || ingested[1].EventType != AssistantEventType {
+		t.Fatalf("unexpected ingests: %+v", ingested)
+	}
+	if ingested[0].Metadata["source"] != "eino" {
+		t.Fatalf("missing source metadata: %+v", ingested[0].Metadata)
+	}
+
+	_, err = NewClient(Config{BaseURL: server.URL}).Retrieve(context.Background(), RetrieveParams{Query: "x"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 APIError, got %v", err)
+	}
+}
+
+func TestInvalidEnumsAreRejectedBeforeSending(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_ = json.NewEncoder(w).Encode(map[string]any{"memory_id": "m1", "stored": true})
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, EventTypes: []EventType{"support_ticket"}})
+	ctx := context.Background()
+	_, err := client.Ingest(ctx, IngestParams{Content: "x", EventType: "user_preferrence"})
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) || invalid.Field != "event_type" {
+		t.Fatalf("expected event_type ValidationError, got %v", err)
+	}
+	if _, err = client.Retrieve(ctx, RetrieveParams{Query: "x", Consistency: "strict"}); !errors.As(err, &invalid) {
+		t.Fatalf("expected consistency ValidationError, got %v", err)
+	}
+	if _, err = client.Retrieve(ctx, RetrieveParams{Query: "x", Profile: "quick"}); !errors.As(err, &invalid) || invalid.Field != "profile" {
+		t.Fatalf("expected profile ValidationError, got %v", err)
+	}
+	if requests != 0 {
+		t.Fatalf("invalid params reached the server %d times", requests)
+	}
+	if _, err = client.Ingest(ctx, IngestParams{Content: "x", EventType: "support_ticket"}); err != nil {
+		t.Fatalf("configured custom event type rejected: %v", err)
+	}
+	if _, err = client.Ingest(ctx, IngestParams{Content: "x", EventType: EventPreferenceStated}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTraceHeadersAreSentFromContext(t *testing.T) {
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(TraceparentHeader) != traceparent || r.Header.Get(TracestateHeader) != "vendor=1" {
+			t.Errorf("unexpected trace headers: %v", r.Header)
+		}
+		w.Header().Set(RequestIDHeader, r.Header.Get(RequestIDHeader))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx := WithTraceContext(WithRequestID(context.Background(), "req-42"), traceparent, "vendor=1")
+	_, err := NewClient(Config{BaseURL: server.URL}).Retrieve(ctx, RetrieveParams{Query: "q"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.RequestID != "req-42" {
+		t.Fatalf("expected request id on APIError, got %v", err)
+	}
+}
+
+func TestRetriesAndLogsRateLimitWaits(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0.01")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if calls == 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"memory_id": "m1", "stored": true})
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := NewClient(
+		Config{BaseURL: server.URL, MaxRetries: 2, RetryBackoff: time.Millisecond},
+		WithLogger(logger),
+	)
+	ctx := WithRequestID(context.Background(), "req-7")
+	if _, err := client.Ingest(ctx, IngestParams{Content: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	var events []map[string]any
+	for _, line := range bytes.Split(bytes.TrimSpace(logs.Bytes()), []byte("\n")) {
+		var event map[string]any
+		if err := json.Unmarshal(line, &event); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, event)
+	}
+	var messages []string
+	for _, event := range events {
+		messages = append(messages, event["msg"].(string))
+	}
+	want := []string{
+		"orbit request started",
+		"orbit rate limited, waiting",
+		"orbit request retrying",
+		"orbit request finished",
+	}
+	if !reflect.DeepEqual(messages, want) {
+		t.Fatalf("unexpected events: %v", messages)
+	}
+	if events[1]["level"] != "WARN" || events[1]["delay"] != float64(10*time.Millisecond) {
+		t.Fatalf("unexpected rate-limit event: %v", events[1])
+	}
+	if last := events[3]; last["attempts"] != float64(3) || last["request_id"] != "req-7" {
+		t.Fatalf("unexpected finish event: %v", last)
+	}
+
+	calls = 1
+	client = NewClient(Config{BaseURL: server.URL}, WithLogger(logger))
+	if _, err := client.Ingest(ctx, IngestParams{Content: "hello"}); err == nil {
+		t.Fatal("expected a 503 without retries")
+	}
+}
+
+func TestDoCallsRawEndpointsWithRetriesAndErrors(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("Authorization") != "Bearer orbit_pk_test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/experimental/echo":
+			if calls == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			var in map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&in)
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"method": r.Method, "mode": r.URL.Query().Get("mode"), "echo": in["value"],
+			})
+		default:
+			w.Header().Set(RequestIDHeader, "req-404")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"detail":"Not Found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL: server.URL, Token: "orbit_pk_test", MaxRetries: 1, RetryBackoff: time.Millisecond,
+	})
+	ctx := context.Background()
+	var out struct {
+		Method string `json:"method"`
+		Mode   string `json:"mode"`
+		Echo   string `json:"echo"`
+	}
+	err := client.Do(ctx, "post", "/v1/experimental/echo?mode=fast", map[string]any{"value": "hi"}, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 || out.Method != http.MethodPost || out.Mode != "fast" || out.Echo != "hi" {
+		t.Fatalf("calls = %d, out = %+v", calls, out)
+	}
+
+	err = client.Do(ctx, http.MethodGet, "/v1/missing", nil, nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.RequestID != "req-404" {
+		t.Fatalf("Do on a missing endpoint = %v", err)
+	}
+	if err := client.Do(ctx, http.MethodGet, "v1/status", nil, nil); err == nil {
+		t.Fatal("expected an error for a relative path")
+	}
+}
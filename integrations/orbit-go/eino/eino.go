@@ -0,0 +1,99 @@
+// Package eino exposes Orbit as a CloudWeGo Eino retriever and conversation memory.
+package eino
+
+import (
+	"context"
+
+	"github.com/cloudwego/eino/components/retriever"
+	"github.com/cloudwego/eino/schema"
+
+	orbitmemory "github.com/Intina47/orbit/integrations/orbit-go"
+)
+
+// Retriever implements retriever.Retriever for a single Orbit entity.
+type Retriever struct {
+	Client   *orbitmemory.Client
+	EntityID string
+	TopK     int
+}
+
+var _ retriever.Retriever = (*Retriever)(nil)
+
+// NewRetriever returns a Retriever scoped to entityID with TopK 5.
+func NewRetriever(client *orbitmemory.Client, entityID string) *Retriever {
+	return &Retriever{Client: client, EntityID: entityID, TopK: 5}
+}
+
+// Retrieve honours retriever.WithTopK; other common options are ignored.
+func (r *Retriever) Retrieve(ctx context.Context, query string, opts ...retriever.Option) ([]*schema.Document, error) {
+	topK := r.TopK
+	options := retriever.GetCommonOptions(&retriever.Options{TopK: &topK}, opts...)
+	if options.TopK != nil {
+		topK = *options.TopK
+	}
+	memories, err := r.Client.Retrieve(ctx, orbitmemory.RetrieveParams{
+		Query:    query,
+		EntityID: r.EntityID,
+		Limit:    topK,
+	})
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]*schema.Document, 0, len(memories))
+	for _, memory := range memories {
+		metadata := map[string]any{
+			"rank_score":       memory.RankScore,
+			"importance_score": memory.ImportanceScore,
+		}
+		for key, value := range memory.Metadata {
+			metadata[key] = value
+		}
+		doc := &schema.Document{ID: memory.MemoryID, Content: memory.Content, MetaData: metadata}
+		docs = append(docs, doc.WithScore(memory.RankScore))
+	}
+	return docs, nil
+}
+
+// GetType reports the component implementation name to Eino callbacks.
+func (r *Retriever) GetType() string {
+	return "Orbit"
+}
+
+// Memory loads and saves conversation context for one entity.
+type Memory struct {
+	Retriever *Retriever
+}
+
+// NewMemory wraps NewRetriever for chat-template use.
+func NewMemory(client *orbitmemory.Client, entityID string) *Memory {
+	return &Memory{Retriever: NewRetriever(client, entityID)}
+}
+
+// Load returns retrieved memories as a leading system message, ready to prepend to a prompt.
+func (m *Memory) Load(ctx context.Context, query string) ([]*schema.Message, error) {
+	memories, err := m.Retriever.Client.Retrieve(ctx, orbitmemory.RetrieveParams{
+		Query:    query,
+		EntityID: m.Retriever.EntityID,
+		Limit:    m.Retriever.TopK,
+	})
+	if err != nil {
+		return nil, err
+	}
+	block := orbitmemory.FormatMemories(memories)
+	if block == "" {
+		return nil, nil
+	}
+	return []*schema.Message{schema.SystemMessage(block)}, nil
+}
+
+// Save ingests the user and assistant messages of a completed turn.
+func (m *Memory) Save(ctx context.Context, messages ...*schema.Message) error {
+	turns := make([]orbitmemory.Turn, 0, len(messages))
+	for _, message := range messages {
+		if message == nil {
+			continue
+		}
+		turns = append(turns, orbitmemory.Turn{Role: string(message.Role), Content: message.Content})
+	}
+	return m.Retriever.Client.RememberTurns(ctx, m.Retriever.EntityID, "eino", turns)
+}
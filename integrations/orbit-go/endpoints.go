@@ -0,0 +1,264 @@
+package orbitmemory
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// healthCheckTimeout bounds the GET /v1/health probe sent to a down endpoint.
+const healthCheckTimeout = 2 * time.Second
+
+// latencySmoothing is the weight of the newest sample in each endpoint's latency average.
+const latencySmoothing = 0.3
+
+// Endpoint is one Orbit deployment the client may send requests to. Token, when set,
+// replaces Config.Token for requests to it.
+type Endpoint struct {
+	BaseURL string
+	Token   string
+	Region  string
+}
+
+// Routing chooses which healthy endpoint takes the next request.
+type Routing string
+
+const (
+	// RoutingFailover sends requests to the first healthy endpoint in configured order.
+	RoutingFailover Routing = "failover"
+	// RoutingLatency sends requests to the healthy endpoint with the lowest smoothed latency.
+	RoutingLatency Routing = "latency"
+)
+
+// EndpointHealth is one endpoint's state as reported by Client.EndpointHealth. Latency is
+// zero until the endpoint has answered once.
+type EndpointHealth struct {
+	BaseURL             string
+	Region              string
+	Healthy             bool
+	Latency             time.Duration
+	ConsecutiveFailures int
+}
+
+// EndpointHealth reports each configured endpoint's state, in configured order.
+func (c *Client) EndpointHealth() []EndpointHealth {
+	return c.endpoints.health()
+}
+
+type endpointState struct {
+	endpoint            Endpoint
+	latency             time.Duration
+	measured            bool
+	downUntil           time.Time
+	down                bool
+	consecutiveFailures int
+}
+
+// endpointPool keeps the health and latency of a client's endpoints. A transport error,
+// 408 or 5xx marks an endpoint down for cooldown; after that a GET /v1/health probe
+// decides whether it rejoins the pool.
+type endpointPool struct {
+	routing  Routing
+	cooldown time.Duration
+	now      func() time.Time
+
+	mu     sync.Mutex
+	states []*endpointState
+}
+
+func newEndpointPool(endpoints []Endpoint, routing Routing, cooldown time.Duration) *endpointPool {
+	pool := &endpointPool{routing: routing, cooldown: cooldown, now: time.Now}
+	for _, endpoint := range endpoints {
+		pool.states = append(pool.states, &endpointState{endpoint: endpoint})
+	}
+	return pool
+}
+
+// resolveEndpoints normalizes cfg.Endpoints, or returns cfg.BaseURL alone when none are set.
+func resolveEndpoints(cfg Config) []Endpoint {
+	endpoints := make([]Endpoint, 0, max(len(cfg.Endpoints), 1))
+	for _, endpoint := range cfg.Endpoints {
+		endpoint.BaseURL = strings.TrimRight(strings.TrimSpace(endpoint.BaseURL), "/")
+		if endpoint.BaseURL != "" {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	if len(endpoints) > 0 {
+		return endpoints
+	}
+	baseURL := strings.TrimRight(strings.TrimSpace(cfg.BaseURL), "/")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return append(endpoints, Endpoint{BaseURL: baseURL})
+}
+
+func (p *endpointPool) endpoint(index int) Endpoint {
+	return p.states[index].endpoint
+}
+
+// pick returns the endpoint for the next attempt, avoiding exclude when possible. Down
+// endpoints are used only when no healthy one is left, soonest-recovering first, so a call
+// is never refused without being tried.
+func (p *endpointPool) pick(exclude map[int]bool) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	candidates := make([]int, 0, len(p.states))
+	for index := range p.states {
+		if !exclude[index] {
+			candidates = append(candidates, index)
+		}
+	}
+	if len(candidates) == 0 {
+		for index := range p.states {
+			candidates = append(candidates, index)
+		}
+	}
+	best := -1
+	for _, index := range candidates {
+		if best < 0 || p.preferred(p.states[index], p.states[best]) {
+			best = index
+		}
+	}
+	return best
+}
+
+// preferred orders endpoints for pick: healthy before down, and down ones soonest-recovering
+// first. Healthy ones keep configured order, or with RoutingLatency go fastest first, with
+// unmeasured ones ahead so every endpoint gets a latency sample.
+func (p *endpointPool) preferred(a, b *endpointState) bool {
+	switch {
+	case a.down != b.down:
+		return !a.down
+	case a.down:
+		return a.downUntil.Before(b.downUntil)
+	case p.routing != RoutingLatency:
+		return false
+	case a.measured != b.measured:
+		return !a.measured
+	}
+	return a.latency < b.latency
+}
+
+// hasAlternative reports whether a healthy endpoint outside exclude can take a failed-over
+// call.
+func (p *endpointPool) hasAlternative(exclude map[int]bool) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for index, state := range p.states {
+		if !state.down && !exclude[index] {
+			return true
+		}
+	}
+	return false
+}
+
+// claimProbes returns down endpoints whose cooldown has passed, each claimed by one caller
+// only. A lone endpoint is never probed: every call goes to it anyway.
+func (p *endpointPool) claimProbes() []int {
+	if len(p.states) < 2 {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	var due []int
+	for index, state := range p.states {
+		if state.down && !state.downUntil.After(now) {
+			state.downUntil = now.Add(p.cooldown)
+			due = append(due, index)
+		}
+	}
+	return due
+}
+
+func (p *endpointPool) recordSuccess(index int, latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	state := p.states[index]
+	if state.measured {
+		state.latency += time.Duration(latencySmoothing * float64(latency-state.latency))
+	} else {
+		state.latency, state.measured = latency, true
+	}
+	state.down, state.downUntil = false, time.Time{}
+	state.consecutiveFailures = 0
+}
+
+func (p *endpointPool) recordFailure(index int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	state := p.states[index]
+	state.consecutiveFailures++
+	state.down, state.downUntil = true, p.now().Add(p.cooldown)
+}
+
+func (p *endpointPool) health() []EndpointHealth {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]EndpointHealth, 0, len(p.states))
+	for _, state := range p.states {
+		out = append(out, EndpointHealth{
+			BaseURL:             state.endpoint.BaseURL,
+			Region:              state.endpoint.Region,
+			Healthy:             !state.down,
+			Latency:             state.latency,
+			ConsecutiveFailures: state.consecutiveFailures,
+		})
+	}
+	return out
+}
+
+// endpointFailure reports whether an attempt's outcome says the endpoint itself is
+// unavailable, as opposed to the call being refused or cancelled by the caller.
+func endpointFailure(ctx context.Context, status int, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if status == 0 {
+		var urlErr *url.Error
+		return errors.As(err, &urlErr)
+	}
+	switch status {
+	case http.StatusRequestTimeout, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// probeEndpoints sends GET /v1/health to each down endpoint whose cooldown has passed.
+func (c *Client) probeEndpoints(ctx context.Context) {
+	for _, index := range c.endpoints.claimProbes() {
+		probeCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		started := time.Now()
+		status, err := c.probe(probeCtx, c.endpoints.endpoint(index))
+		cancel()
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil || endpointFailure(ctx, status, nil):
+			c.endpoints.recordFailure(index)
+		default:
+			c.endpoints.recordSuccess(index, time.Since(started))
+		}
+	}
+}
+
+func (c *Client) probe(ctx context.Context, endpoint Endpoint) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.BaseURL+"/v1/health", nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,111 @@
+package orbitmemory
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientFailsOverBetweenEndpointsAndProbesRecovery(t *testing.T) {
+	var primaryUp atomic.Bool
+	var mu sync.Mutex
+	var seen []string
+	handler := func(region string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			seen = append(seen, region+" "+r.URL.Path+" "+r.Header.Get("Authorization"))
+			mu.Unlock()
+			if region == "us" && !primaryUp.Load() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(`{"memories": [{"memory_id": "` + region + `"}]}`))
+		}
+	}
+	us := httptest.NewServer(handler("us"))
+	defer us.Close()
+	eu := httptest.NewServer(handler("eu"))
+	defer eu.Close()
+
+	client := NewClient(Config{
+		Token: "orbit_pk_default",
+		Endpoints: []Endpoint{
+			{BaseURL: us.URL, Region: "us"},
+			{BaseURL: eu.URL + "/", Token: "orbit_pk_eu", Region: "eu"},
+		},
+		EndpointCooldown: time.Minute,
+	})
+	now := time.Now()
+	client.endpoints.now = func() time.Time { return now }
+	ctx := context.Background()
+	retrieveFrom := func() string {
+		t.Helper()
+		memories, err := client.Retrieve(ctx, RetrieveParams{Query: "q"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return memories[0].MemoryID
+	}
+
+	// MaxRetries is 0, so only failover can reach the second endpoint.
+	if got := retrieveFrom(); got != "eu" {
+		t.Fatalf("expected failover to eu, got %s", got)
+	}
+	if got := retrieveFrom(); got != "eu" {
+		t.Fatalf("down endpoint was retried before its cooldown: %s", got)
+	}
+	if health := client.EndpointHealth(); health[0].Healthy || !health[1].Healthy ||
+		health[0].ConsecutiveFailures != 1 || health[1].BaseURL != eu.URL {
+		t.Fatalf("unexpected health: %+v", health)
+	}
+
+	primaryUp.Store(true)
+	now = now.Add(time.Minute)
+	if got := retrieveFrom(); got != "us" {
+		t.Fatalf("expected recovered us endpoint, got %s", got)
+	}
+	if health := client.EndpointHealth(); !health[0].Healthy || health[0].Latency <= 0 {
+		t.Fatalf("unexpected health after recovery: %+v", health)
+	}
+	want := []string{
+		"us /v1/retrieve Bearer orbit_pk_default",
+		"eu /v1/retrieve Bearer orbit_pk_eu",
+		"eu /v1/retrieve Bearer orbit_pk_eu",
+		"us /v1/health ",
+		"us /v1/retrieve Bearer orbit_pk_default",
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) != len(want) {
+		t.Fatalf("unexpected requests: %q", seen)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Fatalf("request %d: got %q, want %q", i, seen[i], want[i])
+		}
+	}
+}
+
+func TestEndpointPoolLatencyRoutingPrefersFastestHealthyEndpoint(t *testing.T) {
+	pool := newEndpointPool([]Endpoint{{BaseURL: "a"}, {BaseURL: "b"}, {BaseURL: "c"}},
+		RoutingLatency, time.Minute)
+	// Unmeasured endpoints go first so each gets a latency sample.
+	for _, latency := range []time.Duration{30, 10, 20} {
+		index := pool.pick(nil)
+		pool.recordSuccess(index, latency*time.Millisecond)
+	}
+	if got := pool.pick(nil); got != 1 {
+		t.Fatalf("expected fastest endpoint 1, got %d", got)
+	}
+	pool.recordFailure(1)
+	if got := pool.pick(nil); got != 2 {
+		t.Fatalf("expected next fastest healthy endpoint 2, got %d", got)
+	}
+	if got := pool.pick(map[int]bool{0: true, 2: true}); got != 1 {
+		t.Fatalf("expected the down endpoint when nothing else is left, got %d", got)
+	}
+}
@@ -0,0 +1,138 @@
+package orbitmemory
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EventType classifies an ingested memory and drives its ranking prior.
+type EventType string
+
+// Event types Orbit ranks and personalizes on. Others must be listed in Config.EventTypes.
+const (
+	EventUserQuestion      EventType = "user_question"
+	EventUserAttempt       EventType = "user_attempt"
+	EventUserFact          EventType = "user_fact"
+	EventUserProfile       EventType = "user_profile"
+	EventPreferenceStated  EventType = "preference_stated"
+	EventLearningProgress  EventType = "learning_progress"
+	EventAssessmentResult  EventType = "assessment_result"
+	EventAssistantResponse EventType = "assistant_response"
+	EventAssistantMessage  EventType = "assistant_message"
+)
+
+var knownEventTypes = []EventType{
+	EventUserQuestion,
+	EventUserAttempt,
+	EventUserFact,
+	EventUserProfile,
+	EventPreferenceStated,
+	EventLearningProgress,
+	EventAssessmentResult,
+	EventAssistantResponse,
+	EventAssistantMessage,
+}
+
+// RetrievalMode selects how GET /v1/retrieve gathers candidates.
+type RetrievalMode string
+
+const (
+	ModeVector RetrievalMode = "vector"
+	ModeGraph  RetrievalMode = "graph"
+)
+
+// Consistency selects whether a retrieve waits for pending writes to be indexed.
+type Consistency string
+
+const (
+	ConsistencyEventual Consistency = "eventual"
+	ConsistencyStrong   Consistency = "strong"
+)
+
+// Profile is a named preset of retrieval options; options set on the request win.
+type Profile string
+
+const (
+	ProfileBalanced Profile = "balanced"
+	ProfileFast     Profile = "fast"
+	// ProfileThorough retrieves in graph mode two hops out and includes linked memories.
+	ProfileThorough Profile = "thorough"
+)
+
+// Fallback chooses what a retrieve returns when nothing scores above MinScore.
+type Fallback string
+
+const (
+	FallbackEmpty      Fallback = "empty"
+	FallbackRecent     Fallback = "recent"
+	FallbackAttributes Fallback = "attributes"
+	FallbackWebhook    Fallback = "webhook"
+)
+
+// Sensitivity labels a memory, least to most restricted.
+type Sensitivity string
+
+const (
+	SensitivityPublic       Sensitivity = "public"
+	SensitivityInternal     Sensitivity = "internal"
+	SensitivityConfidential Sensitivity = "confidential"
+)
+
+// OversizeAction chooses what ingest does with content over the server's size limit.
+type OversizeAction string
+
+const (
+	OversizeReject    OversizeAction = "reject"
+	OversizeSummarize OversizeAction = "summarize"
+	OversizeChunk     OversizeAction = "chunk"
+)
+
+// ValidationError is returned, before any request is sent, for a parameter outside its
+// allowed values.
+type ValidationError struct {
+	Field   string
+	Value   string
+	Allowed []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("orbit: invalid %s %q (allowed: %s)", e.Field, e.Value, strings.Join(e.Allowed, ", "))
+}
+
+// checkEnum accepts value when it is empty (server default) or one of allowed.
+func checkEnum[T ~string](field string, value T, allowed ...T) error {
+	if value == "" {
+		return nil
+	}
+	names := make([]string, len(allowed))
+	for index, candidate := range allowed {
+		if value == candidate {
+			return nil
+		}
+		names[index] = string(candidate)
+	}
+	return &ValidationError{Field: field, Value: string(value), Allowed: names}
+}
+
+func (p RetrieveParams) validate() error {
+	if err := checkEnum("mode", p.Mode, ModeVector, ModeGraph); err != nil {
+		return err
+	}
+	if err := checkEnum("consistency", p.Consistency, ConsistencyEventual, ConsistencyStrong); err != nil {
+		return err
+	}
+	if err := checkEnum("profile", p.Profile, ProfileBalanced, ProfileFast, ProfileThorough); err != nil {
+		return err
+	}
+	return checkEnum("fallback", p.Fallback, FallbackEmpty, FallbackRecent, FallbackAttributes, FallbackWebhook)
+}
+
+func (c *Client) validateIngest(p IngestParams) error {
+	if err := checkEnum("event_type", p.EventType, c.eventTypes...); err != nil {
+		return err
+	}
+	if err := checkEnum("sensitivity", p.Sensitivity, SensitivityPublic, SensitivityInternal, SensitivityConfidential); err != nil {
+		return err
+	}
+	return checkEnum("on_oversize", p.OnOversize, OversizeReject, OversizeSummarize, OversizeChunk)
+}
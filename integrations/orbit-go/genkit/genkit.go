@@ -0,0 +1,137 @@
+// Package genkit exposes Orbit as a Firebase Genkit retriever and conversation memory.
+package genkit
+
+import (
+	"context"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+
+	orbitmemory "github.com/Intina47/orbit/integrations/orbit-go"
+)
+
+// Provider is the Genkit provider name used for registered retrievers.
+const Provider = "orbit"
+
+// RetrieverOptions can be passed as ai.RetrieverRequest.Options.
+type RetrieverOptions struct {
+	EntityID string `json:"entity_id,omitempty"`
+	Limit    int    `json:"limit,omitempty"`
+}
+
+// Memory adapts an Orbit client to Genkit.
+type Memory struct {
+	Client       *orbitmemory.Client
+	DefaultLimit int
+}
+
+// New returns a Memory with a default retrieval limit of 5.
+func New(client *orbitmemory.Client) *Memory {
+	return &Memory{Client: client, DefaultLimit: 5}
+}
+
+// DefineRetriever registers the retriever as "orbit/<name>".
+func (m *Memory) DefineRetriever(g *genkit.Genkit, name string) ai.Retriever {
+	return genkit.DefineRetriever(g, Provider, name, m.Retrieve)
+}
+
+// Retrieve implements the Genkit retriever function signature.
+func (m *Memory) Retrieve(ctx context.Context, req *ai.RetrieverRequest) (*ai.RetrieverResponse, error) {
+	opts := retrieverOptions(req.Options)
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = m.DefaultLimit
+	}
+	memories, err := m.Client.Retrieve(ctx, orbitmemory.RetrieveParams{
+		Query:    documentText(req.Query),
+		EntityID: opts.EntityID,
+		Limit:    limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]*ai.Document, 0, len(memories))
+	for _, memory := range memories {
+		metadata := map[string]any{
+			"memory_id":        memory.MemoryID,
+			"rank_score":       memory.RankScore,
+			"importance_score": memory.ImportanceScore,
+		}
+		for key, value := range memory.Metadata {
+			metadata[key] = value
+		}
+		docs = append(docs, ai.DocumentFromText(memory.Content, metadata))
+	}
+	return &ai.RetrieverResponse{Documents: docs}, nil
+}
+
+// Remember ingests the user and model turns of a Genkit conversation for entityID.
+func (m *Memory) Remember(ctx context.Context, entityID string, messages []*ai.Message) error {
+	turns := make([]orbitmemory.Turn, 0, len(messages))
+	for _, message := range messages {
+		if message == nil {
+			continue
+		}
+		turns = append(turns, orbitmemory.Turn{
+			Role:    string(message.Role),
+			Content: partsText(message.Content),
+		})
+	}
+	return m.Client.RememberTurns(ctx, entityID, "genkit", turns)
+}
+
+// SystemMessage returns retrieved memories as a system message, or nil when there are none.
+func (m *Memory) SystemMessage(ctx context.Context, entityID, query string) (*ai.Message, error) {
+	memories, err := m.Client.Retrieve(ctx, orbitmemory.RetrieveParams{
+		Query:    query,
+		EntityID: entityID,
+		Limit:    m.DefaultLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	block := orbitmemory.FormatMemories(memories)
+	if block == "" {
+		return nil, nil
+	}
+	return ai.NewSystemTextMessage(block), nil
+}
+
+func retrieverOptions(raw any) RetrieverOptions {
+	switch value := raw.(type) {
+	case RetrieverOptions:
+		return value
+	case *RetrieverOptions:
+		if value != nil {
+			return *value
+		}
+	case map[string]any:
+		opts := RetrieverOptions{}
+		if entityID, ok := value["entity_id"].(string); ok {
+			opts.EntityID = entityID
+		}
+		if limit, ok := value["limit"].(float64); ok {
+			opts.Limit = int(limit)
+		}
+		return opts
+	}
+	return RetrieverOptions{}
+}
+
+func documentText(doc *ai.Document) string {
+	if doc == nil {
+		return ""
+	}
+	return partsText(doc.Content)
+}
+
+func partsText(parts []*ai.Part) string {
+	texts := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part != nil && part.IsText() {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.TrimSpace(strings.Join(texts, " "))
+}
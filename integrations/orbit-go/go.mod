@@ -0,0 +1,3 @@
+module github.com/Intina47/orbit/integrations/orbit-go
+
+go 1.22
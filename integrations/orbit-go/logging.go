@@ -0,0 +1,65 @@
+package orbitmemory
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogLevels sets the level each kind of client event is logged at.
+type LogLevels struct {
+	// Request covers "orbit request started" and successful "orbit request finished".
+	Request slog.Level
+	// Failure is used for "orbit request finished" when the call returned an error.
+	Failure slog.Level
+	// Retry covers "orbit request retrying" after a transport error or retryable status,
+	// and "orbit endpoint failing over" when a call moves to another of Config.Endpoints.
+	Retry slog.Level
+	// RateLimit covers "orbit rate limited, waiting" before retrying a 429.
+	RateLimit slog.Level
+	// Circuit covers WithCircuitBreaker's "orbit circuit state changed" and
+	// "orbit circuit open, failing fast".
+	Circuit slog.Level
+	// Cache covers WithRetrievalCache's "orbit retrieval cache hit" and
+	// "orbit retrieval cache miss" when Orbit was unreachable.
+	Cache slog.Level
+	// Deprecation covers "orbit endpoint deprecated", logged once per endpoint whose
+	// responses carry a Deprecation or Sunset header.
+	Deprecation slog.Level
+}
+
+var defaultLogLevels = LogLevels{
+	Request:     slog.LevelDebug,
+	Failure:     slog.LevelWarn,
+	Retry:       slog.LevelInfo,
+	RateLimit:   slog.LevelWarn,
+	Circuit:     slog.LevelWarn,
+	Cache:       slog.LevelInfo,
+	Deprecation: slog.LevelWarn,
+}
+
+// WithLogger makes the client log structured events to logger: request start and finish
+// (method, path, status, attempts, duration, error), retries, rate-limit waits, circuit
+// breaker changes, retrieval cache hits, and deprecated endpoints. Events carry the context's request id, if
+// WithRequestID set one.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithLogLevels overrides the levels WithLogger's events are logged at.
+func WithLogLevels(levels LogLevels) Option {
+	return func(c *Client) {
+		c.logLevels = levels
+	}
+}
+
+func (c *Client) logEvent(ctx context.Context, level slog.Level, msg string, args ...any) {
+	if c.logger == nil || !c.logger.Enabled(ctx, level) {
+		return
+	}
+	if requestID := traceFrom(ctx).requestID; requestID != "" {
+		args = append(args, "request_id", requestID)
+	}
+	c.logger.Log(ctx, level, msg, args...)
+}
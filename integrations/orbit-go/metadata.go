@@ -0,0 +1,101 @@
+package orbitmemory
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ResponseMetadata is what an Orbit response's headers say beyond its body.
+type ResponseMetadata struct {
+	Method     string
+	Path       string
+	StatusCode int
+	// RequestID is the response's X-Request-ID.
+	RequestID string
+	RateLimit RateLimit
+	// Deprecation is the raw Deprecation header (RFC 9745), set when the endpoint or a
+	// parameter the call used is deprecated; Sunset is when it stops working, if announced.
+	Deprecation string
+	Sunset      time.Time
+}
+
+// RateLimit is the X-RateLimit-* quota state Orbit reports with each response. Limit is -1
+// when the response carried no rate-limit headers.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+	// RetryAfter is the Retry-After delay on 429 and 503 responses.
+	RetryAfter time.Duration
+}
+
+// Deprecated reports whether the response flagged the call as deprecated.
+func (m ResponseMetadata) Deprecated() bool {
+	return m.Deprecation != "" || !m.Sunset.IsZero()
+}
+
+// WithResponseHook makes the client call hook with the metadata of every response it
+// receives, including ones it then retries, so callers can throttle on RateLimit.Remaining
+// or alert on Deprecated before the endpoint is removed.
+func WithResponseHook(hook func(context.Context, ResponseMetadata)) Option {
+	return func(c *Client) {
+		c.responseHook = hook
+	}
+}
+
+type metadataKey struct{}
+
+// WithResponseMetadata returns a context whose Orbit calls store the metadata of their final
+// response in *dst, for reading it alongside a typed result:
+//
+//	var meta orbitmemory.ResponseMetadata
+//	memories, err := client.Retrieve(orbitmemory.WithResponseMetadata(ctx, &meta), params)
+func WithResponseMetadata(ctx context.Context, dst *ResponseMetadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, dst)
+}
+
+func (c *Client) recordResponse(ctx context.Context, call apiRequest, resp *http.Response) {
+	meta := ResponseMetadata{
+		Method:      call.method,
+		Path:        call.path,
+		StatusCode:  resp.StatusCode,
+		RequestID:   resp.Header.Get(RequestIDHeader),
+		RateLimit:   rateLimitFrom(resp.Header),
+		Deprecation: strings.TrimSpace(resp.Header.Get("Deprecation")),
+	}
+	if sunset, err := http.ParseTime(resp.Header.Get("Sunset")); err == nil {
+		meta.Sunset = sunset
+	}
+	if dst, ok := ctx.Value(metadataKey{}).(*ResponseMetadata); ok && dst != nil {
+		*dst = meta
+	}
+	if c.responseHook != nil {
+		c.responseHook(ctx, meta)
+	}
+	if meta.Deprecated() {
+		// Log each deprecated endpoint once rather than on every call.
+		endpoint, _, _ := strings.Cut(call.path, "?")
+		if _, logged := c.deprecationsLogged.LoadOrStore(call.method+" "+endpoint, true); !logged {
+			c.logEvent(ctx, c.logLevels.Deprecation, "orbit endpoint deprecated",
+				"method", call.method, "path", endpoint, "deprecation", meta.Deprecation,
+				"sunset", meta.Sunset)
+		}
+	}
+}
+
+func rateLimitFrom(header http.Header) RateLimit {
+	limit := RateLimit{Limit: -1, RetryAfter: retryAfter(header)}
+	parsed, err := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return limit
+	}
+	limit.Limit = parsed
+	limit.Remaining, _ = strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		limit.Reset = time.Unix(reset, 0).UTC()
+	}
+	return limit
+}
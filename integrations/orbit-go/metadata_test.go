@@ -0,0 +1,74 @@
+package orbitmemory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResponseMetadataReachesHookAndContext(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set(RequestIDHeader, "req-"+r.URL.Path)
+		w.Header().Set("X-RateLimit-Limit", "100")
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		w.Header().Set("X-RateLimit-Reset", "1792022400")
+		if r.URL.Path == "/v1/ingest" && calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if r.URL.Path == "/v1/retrieve" {
+			w.Header().Set("Deprecation", "@1790000000")
+			w.Header().Set("Sunset", "Wed, 31 Mar 2027 00:00:00 GMT")
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"memory_id": "m1", "memories": []any{}})
+	}))
+	defer server.Close()
+
+	var hooked []ResponseMetadata
+	var logs bytes.Buffer
+	client := NewClient(
+		Config{BaseURL: server.URL, MaxRetries: 1, RetryBackoff: time.Millisecond},
+		WithResponseHook(func(_ context.Context, meta ResponseMetadata) { hooked = append(hooked, meta) }),
+		WithLogger(slog.New(slog.NewJSONHandler(&logs, nil))),
+	)
+
+	var meta ResponseMetadata
+	ctx := WithResponseMetadata(context.Background(), &meta)
+	if _, err := client.Ingest(ctx, IngestParams{Content: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(hooked) != 2 || hooked[0].StatusCode != http.StatusTooManyRequests || hooked[1].StatusCode != 200 {
+		t.Fatalf("hooked = %+v, want the 429 and the retried 200", hooked)
+	}
+	want := RateLimit{Limit: 100, Remaining: 42, Reset: time.Unix(1792022400, 0).UTC()}
+	if meta.StatusCode != 200 || meta.RequestID != "req-/v1/ingest" || meta.RateLimit != want {
+		t.Fatalf("meta = %+v", meta)
+	}
+	if meta.Deprecated() {
+		t.Fatal("ingest was not deprecated")
+	}
+
+	for range 2 {
+		if _, err := client.Retrieve(ctx, RetrieveParams{Query: "tea"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !meta.Deprecated() || meta.Deprecation != "@1790000000" || meta.Path != "/v1/retrieve?query=tea" {
+		t.Fatalf("meta = %+v", meta)
+	}
+	if !meta.Sunset.Equal(time.Date(2027, 3, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("sunset = %v", meta.Sunset)
+	}
+	if count := strings.Count(logs.String(), "orbit endpoint deprecated"); count != 1 {
+		t.Fatalf("deprecation logged %d times, want once:\n%s", count, logs.String())
+	}
+}
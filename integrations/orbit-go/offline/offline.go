@@ -0,0 +1,632 @@
+// Package offline is an offline-first mode for the Orbit Go client, for desktop and CLI
+// assistants with intermittent connectivity. Writes land in a local store immediately and
+// are synced to Orbit in the background; retrieval falls back to the local store while the
+// server is unreachable.
+package offline
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	orbitmemory "github.com/Intina47/orbit/integrations/orbit-go"
+)
+
+// PendingMetadataKey is set to true in the Metadata of retrieved memories that exist only
+// locally so far.
+const PendingMetadataKey = "offline_pending"
+
+const defaultSyncInterval = 30 * time.Second
+
+// Conflict is a memory edited locally that also changed on the server since the local copy
+// was last synced.
+type Conflict struct {
+	Local           Entry
+	ServerContent   string
+	ServerVersion   int
+	ServerUpdatedAt time.Time
+}
+
+// Resolver returns the content to keep for a conflict. Returning ServerContent discards the
+// local edit; anything else is written to the server as the new version.
+type Resolver func(Conflict) string
+
+// ServerWins keeps the server's content.
+func ServerWins(c Conflict) string { return c.ServerContent }
+
+// LocalWins overwrites the server with the local content.
+func LocalWins(c Conflict) string { return c.Local.Content }
+
+// LastWriteWins keeps whichever side changed last. It is the default Resolver.
+func LastWriteWins(c Conflict) string {
+	if c.Local.UpdatedAt.After(c.ServerUpdatedAt) {
+		return c.Local.Content
+	}
+	return c.ServerContent
+}
+
+// Options configures Open.
+type Options struct {
+	// Store persists local state; FileStore{Path: ...} keeps it in one JSON file.
+	Store Store
+	// SyncInterval is how often Start syncs (default 30s). Local writes also wake it.
+	SyncInterval time.Duration
+	// Resolve settles conflicts (default LastWriteWins).
+	Resolve Resolver
+	// PullChanges applies the account's change feed to the local store on every sync, so
+	// offline retrieval also sees memories written from elsewhere.
+	PullChanges bool
+	// OnSync is called after every background sync, for logging or status indicators.
+	OnSync func(SyncResult, error)
+}
+
+// SyncResult counts what one Sync did.
+type SyncResult struct {
+	Created   int
+	Updated   int
+	Pulled    int
+	Conflicts int
+	Rejected  int
+	// Dropped counts local entries removed because their memory was deleted on the server.
+	Dropped int
+}
+
+// Client is an offline-first wrapper around an orbitmemory.Client. It is safe for
+// concurrent use.
+type Client struct {
+	remote *orbitmemory.Client
+	opts   Options
+
+	mu    sync.Mutex
+	state State
+
+	syncMu sync.Mutex
+	wake   chan struct{}
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// Open loads the local store. Call Start to sync in the background, or Sync directly.
+func Open(remote *orbitmemory.Client, opts Options) (*Client, error) {
+	if opts.Store == nil {
+		return nil, errors.New("offline: Options.Store is required")
+	}
+	if opts.SyncInterval <= 0 {
+		opts.SyncInterval = defaultSyncInterval
+	}
+	if opts.Resolve == nil {
+		opts.Resolve = LastWriteWins
+	}
+	state, err := opts.Store.Load()
+	if err != nil {
+		return nil, err
+	}
+	return &Client{remote: remote, opts: opts, state: state, wake: make(chan struct{}, 1)}, nil
+}
+
+// Remember stores a memory locally and queues it for the next sync. It never contacts
+// Orbit, so it succeeds offline.
+func (c *Client) Remember(params orbitmemory.IngestParams) (Entry, error) {
+	if strings.TrimSpace(params.Content) == "" {
+		return Entry{}, errors.New("offline: content cannot be empty")
+	}
+	now := time.Now().UTC()
+	entry := Entry{
+		LocalID:   newLocalID(),
+		Content:   params.Content,
+		EventType: params.EventType,
+		EntityID:  params.EntityID,
+		Metadata:  params.Metadata,
+		Dirty:     true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	c.mu.Lock()
+	c.state.Entries = append(c.state.Entries, entry)
+	err := c.saveLocked()
+	c.mu.Unlock()
+	c.notify()
+	return entry, err
+}
+
+// Update replaces an entry's content locally and queues the change for the next sync. A
+// rejected entry that never reached the server is retried as a new write with a fresh
+// idempotency key, since Orbit would replay the rejection for the old one.
+func (c *Client) Update(localID, content string) (Entry, error) {
+	if strings.TrimSpace(content) == "" {
+		return Entry{}, errors.New("offline: content cannot be empty")
+	}
+	c.mu.Lock()
+	entry := c.findLocked(localID)
+	if entry == nil {
+		c.mu.Unlock()
+		return Entry{}, errors.New("offline: unknown local id " + localID)
+	}
+	if entry.Rejected && entry.MemoryID == "" {
+		entry.IdempotencyKey = newLocalID()
+	}
+	entry.Content = content
+	entry.Dirty = true
+	entry.Rejected = false
+	entry.UpdatedAt = time.Now().UTC()
+	updated := *entry
+	err := c.saveLocked()
+	c.mu.Unlock()
+	c.notify()
+	return updated, err
+}
+
+// Entries returns a copy of every local entry.
+func (c *Client) Entries() []Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Entry(nil), c.state.Entries...)
+}
+
+// Pending reports how many local writes are waiting to be synced.
+func (c *Client) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pending := 0
+	for _, entry := range c.state.Entries {
+		if entry.Dirty {
+			pending++
+		}
+	}
+	return pending
+}
+
+// Retrieve asks Orbit and adds matching local writes it does not know about yet, marked
+// with PendingMetadataKey. While Orbit is unreachable it searches the local store by
+// keyword instead and marks the results Stale.
+func (c *Client) Retrieve(ctx context.Context, params orbitmemory.RetrieveParams) ([]orbitmemory.Memory, error) {
+	memories, err := c.remote.Retrieve(ctx, params)
+	if err != nil {
+		if !unreachable(ctx, err) {
+			return nil, err
+		}
+		local := c.search(params, false)
+		for i := range local {
+			local[i].Stale = true
+		}
+		return local, nil
+	}
+	c.mu.Lock()
+	for i := range memories {
+		for _, entry := range c.state.Entries {
+			if entry.Dirty && entry.MemoryID == memories[i].MemoryID {
+				memories[i].Content = entry.Content
+			}
+		}
+	}
+	c.mu.Unlock()
+	pending := c.search(params, true)
+	if params.Limit > 0 {
+		pending = pending[:min(len(pending), max(params.Limit-len(memories), 0))]
+	}
+	return append(memories, pending...), nil
+}
+
+// Sync pulls the change feed (with PullChanges) and pushes local writes. It stops at the
+// first error that means Orbit is unreachable, keeping the remaining writes queued.
+func (c *Client) Sync(ctx context.Context) (SyncResult, error) {
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+	var result SyncResult
+	if c.opts.PullChanges {
+		if err := c.pull(ctx, &result); err != nil {
+			return result, err
+		}
+	}
+	for _, entry := range c.dirtyEntries() {
+		if err := c.push(ctx, entry, &result); err != nil {
+			c.mu.Lock()
+			if current := c.findLocked(entry.LocalID); current != nil {
+				current.SyncError = err.Error()
+			}
+			saveErr := c.saveLocked()
+			c.mu.Unlock()
+			return result, errors.Join(err, saveErr)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return result, c.saveLocked()
+}
+
+// Start syncs every SyncInterval, and soon after local writes, until Close.
+func (c *Client) Start(ctx context.Context) {
+	c.mu.Lock()
+	if c.stop != nil {
+		c.mu.Unlock()
+		return
+	}
+	c.stop, c.done = make(chan struct{}), make(chan struct{})
+	stop, done := c.stop, c.done
+	c.mu.Unlock()
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(c.opts.SyncInterval)
+		defer ticker.Stop()
+		for {
+			result, err := c.Sync(ctx)
+			if c.opts.OnSync != nil {
+				c.opts.OnSync(result, err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			case <-ticker.C:
+			case <-c.wake:
+			}
+		}
+	}()
+}
+
+// Close stops the background sync started by Start and waits for it to finish. Unsynced
+// writes stay in the store for the next Open.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	stop, done := c.stop, c.done
+	c.stop = nil
+	c.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+	return nil
+}
+
+func (c *Client) pull(ctx context.Context, result *SyncResult) error {
+	c.mu.Lock()
+	cursor := c.state.ChangeCursor
+	c.mu.Unlock()
+	it := c.remote.ListChanges(ctx, orbitmemory.ListParams{PageSize: 500, Cursor: cursor})
+	for it.Next() {
+		change := it.Change()
+		content, _ := change.Memory["content"].(string)
+		c.mu.Lock()
+		if c.applyChangeLocked(change, content) {
+			result.Pulled++
+		}
+		c.mu.Unlock()
+	}
+	c.mu.Lock()
+	if it.Cursor() != "" {
+		c.state.ChangeCursor = it.Cursor()
+	}
+	err := c.saveLocked()
+	c.mu.Unlock()
+	return errors.Join(it.Err(), err)
+}
+
+// applyChangeLocked mirrors one server change locally. Entries with unsynced local edits
+// are left alone; push detects and resolves the conflict.
+func (c *Client) applyChangeLocked(change orbitmemory.MemoryChange, content string) bool {
+	index := -1
+	for i, entry := range c.state.Entries {
+		if entry.MemoryID == change.MemoryID {
+			index = i
+			break
+		}
+	}
+	switch {
+	case change.Memory == nil || change.Operation == "deleted":
+		if index < 0 || c.state.Entries[index].Dirty {
+			return false
+		}
+		c.state.Entries = append(c.state.Entries[:index], c.state.Entries[index+1:]...)
+		return true
+	case content == "":
+		return false
+	case index >= 0:
+		entry := &c.state.Entries[index]
+		if entry.Dirty || entry.BaseContent == content {
+			return false
+		}
+		// The feed carries no version; the next local edit looks it up.
+		entry.Content, entry.BaseContent, entry.Version = content, content, 0
+		entry.SyncedAt = time.Now().UTC()
+		return true
+	}
+	c.state.Entries = append(c.state.Entries, Entry{
+		LocalID:     "orbit:" + change.MemoryID,
+		MemoryID:    change.MemoryID,
+		Content:     content,
+		BaseContent: content,
+		CreatedAt:   change.OccurredAt,
+		UpdatedAt:   change.OccurredAt,
+		SyncedAt:    time.Now().UTC(),
+	})
+	return true
+}
+
+func (c *Client) push(ctx context.Context, entry Entry, result *SyncResult) error {
+	if entry.MemoryID == "" {
+		return c.pushCreate(ctx, entry, result)
+	}
+	version, content := entry.Version, entry.Content
+	if version > 0 {
+		memory, err := c.remote.UpdateMemory(ctx, entry.MemoryID, content, version)
+		if err == nil {
+			result.Updated++
+			c.settle(entry, content, memory.Version)
+			return nil
+		}
+		if !orbitmemory.IsPreconditionFailed(err) {
+			return c.pushFailed(entry, err, result)
+		}
+	}
+	history, err := c.remote.MemoryVersions(ctx, entry.MemoryID)
+	if err != nil {
+		return c.pushFailed(entry, err, result)
+	}
+	current, ok := history.Current()
+	if history.DeletedAt != nil || !ok {
+		c.drop(entry.LocalID)
+		result.Dropped++
+		return nil
+	}
+	if current.Content != entry.BaseContent {
+		result.Conflicts++
+		content = c.opts.Resolve(Conflict{
+			Local:           entry,
+			ServerContent:   current.Content,
+			ServerVersion:   current.Version,
+			ServerUpdatedAt: current.RecordedAt,
+		})
+		if content == current.Content {
+			c.settle(entry, content, current.Version)
+			return nil
+		}
+	}
+	memory, err := c.remote.UpdateMemory(ctx, entry.MemoryID, content, current.Version)
+	if err != nil {
+		if orbitmemory.IsPreconditionFailed(err) {
+			// Changed again meanwhile; the next sync resolves it against the newer version.
+			return nil
+		}
+		return c.pushFailed(entry, err, result)
+	}
+	result.Updated++
+	c.settle(entry, content, memory.Version)
+	return nil
+}
+
+func (c *Client) pushCreate(ctx context.Context, entry Entry, result *SyncResult) error {
+	created, err := c.remote.Ingest(ctx, orbitmemory.IngestParams{
+		Content:        entry.Content,
+		EventType:      entry.EventType,
+		EntityID:       entry.EntityID,
+		Metadata:       entry.Metadata,
+		IdempotencyKey: entry.idempotencyKey(),
+	})
+	if err != nil {
+		return c.pushFailed(entry, err, result)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	current := c.findLocked(entry.LocalID)
+	if current == nil {
+		return nil
+	}
+	if !created.Stored {
+		current.Rejected, current.Dirty = true, false
+		current.SyncError = "not stored: " + created.DecisionReason
+		result.Rejected++
+		return c.saveLocked()
+	}
+	result.Created++
+	current.MemoryID = created.MemoryID
+	current.BaseContent, current.Version = entry.Content, 0
+	current.Dirty = current.Content != entry.Content
+	current.SyncError = ""
+	current.SyncedAt = time.Now().UTC()
+	return c.saveLocked()
+}
+
+// pushFailed returns err when Orbit is unreachable, so the sync stops and retries later.
+// Other failures would repeat forever, so the entry is marked rejected and skipped.
+func (c *Client) pushFailed(entry Entry, err error, result *SyncResult) error {
+	if orbitmemory.IsNotFound(err) && entry.MemoryID != "" {
+		c.drop(entry.LocalID)
+		result.Dropped++
+		return nil
+	}
+	var apiErr *orbitmemory.APIError
+	if !errors.As(err, &apiErr) || retryableStatus(apiErr.StatusCode) {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if current := c.findLocked(entry.LocalID); current != nil {
+		current.Rejected, current.Dirty = true, false
+		current.SyncError = err.Error()
+	}
+	result.Rejected++
+	return c.saveLocked()
+}
+
+// settle records that content is on the server at version; a local edit made while the
+// request was in flight stays dirty.
+func (c *Client) settle(entry Entry, content string, version int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	current := c.findLocked(entry.LocalID)
+	if current == nil {
+		return
+	}
+	if current.Content == entry.Content {
+		current.Content = content
+		current.Dirty = false
+	}
+	current.BaseContent, current.Version = content, version
+	current.SyncError = ""
+	current.SyncedAt = time.Now().UTC()
+	_ = c.saveLocked()
+}
+
+func (c *Client) drop(localID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, entry := range c.state.Entries {
+		if entry.LocalID == localID {
+			c.state.Entries = append(c.state.Entries[:i], c.state.Entries[i+1:]...)
+			break
+		}
+	}
+	_ = c.saveLocked()
+}
+
+func (c *Client) dirtyEntries() []Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var dirty []Entry
+	for _, entry := range c.state.Entries {
+		if entry.Dirty {
+			dirty = append(dirty, entry)
+		}
+	}
+	sort.SliceStable(dirty, func(i, j int) bool { return dirty[i].UpdatedAt.Before(dirty[j].UpdatedAt) })
+	return dirty
+}
+
+// search ranks local entries by how many query words they contain. unsyncedOnly limits it
+// to writes Orbit has not stored yet.
+func (c *Client) search(params orbitmemory.RetrieveParams, unsyncedOnly bool) []orbitmemory.Memory {
+	terms := words(params.Query)
+	type match struct {
+		entry Entry
+		score int
+	}
+	var matches []match
+	c.mu.Lock()
+	for _, entry := range c.state.Entries {
+		if unsyncedOnly && entry.MemoryID != "" {
+			continue
+		}
+		if params.EntityID != "" && entry.EntityID != params.EntityID {
+			continue
+		}
+		score := 0
+		content := words(entry.Content)
+		for term := range terms {
+			if content[term] {
+				score++
+			}
+		}
+		if score > 0 {
+			matches = append(matches, match{entry: entry, score: score})
+		}
+	}
+	c.mu.Unlock()
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].entry.UpdatedAt.After(matches[j].entry.UpdatedAt)
+	})
+	if params.Limit > 0 && len(matches) > params.Limit {
+		matches = matches[:params.Limit]
+	}
+	memories := make([]orbitmemory.Memory, 0, len(matches))
+	for position, m := range matches {
+		memoryID := m.entry.MemoryID
+		metadata := map[string]any{}
+		for key, value := range m.entry.Metadata {
+			metadata[key] = value
+		}
+		if memoryID == "" {
+			memoryID = m.entry.LocalID
+			metadata[PendingMetadataKey] = true
+		}
+		memories = append(memories, orbitmemory.Memory{
+			MemoryID:     memoryID,
+			Content:      m.entry.Content,
+			RankPosition: position + 1,
+			RankScore:    float64(m.score) / float64(max(len(terms), 1)),
+			Timestamp:    m.entry.UpdatedAt,
+			Metadata:     metadata,
+		})
+	}
+	return memories
+}
+
+func (c *Client) findLocked(localID string) *Entry {
+	for i := range c.state.Entries {
+		if c.state.Entries[i].LocalID == localID {
+			return &c.state.Entries[i]
+		}
+	}
+	return nil
+}
+
+func (c *Client) saveLocked() error {
+	return c.opts.Store.Save(c.state)
+}
+
+func (c *Client) notify() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// unreachable reports whether err means Orbit could not answer, as opposed to rejecting
+// the request.
+func unreachable(ctx context.Context, err error) bool {
+	if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+		return false
+	}
+	var validationErr *orbitmemory.ValidationError
+	if errors.As(err, &validationErr) {
+		return false
+	}
+	var apiErr *orbitmemory.APIError
+	if errors.As(err, &apiErr) {
+		return retryableStatus(apiErr.StatusCode)
+	}
+	return true
+}
+
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests:
+		return true
+	}
+	return status >= http.StatusInternalServerError
+}
+
+func words(text string) map[string]bool {
+	set := map[string]bool{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(word) > 2 {
+			set[word] = true
+		}
+	}
+	return set
+}
+
+func (e Entry) idempotencyKey() string {
+	if e.IdempotencyKey != "" {
+		return e.IdempotencyKey
+	}
+	return e.LocalID
+}
+
+func newLocalID() string {
+	buf := make([]byte, 12)
+	_, _ = rand.Read(buf)
+	return "local_" + hex.EncodeToString(buf)
+}
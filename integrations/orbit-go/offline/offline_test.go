@@ -0,0 +1,241 @@
+package offline
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	orbitmemory "github.com/Intina47/orbit/integrations/orbit-go"
+)
+
+type fakeMemory struct {
+	content   string
+	version   int
+	updatedAt time.Time
+}
+
+// fakeOrbit implements the endpoints the offline client uses.
+type fakeOrbit struct {
+	mu          sync.Mutex
+	down        bool
+	memories    map[string]*fakeMemory
+	idempotency map[string]string
+	changes     []orbitmemory.MemoryChange
+	// reject makes ingests containing it come back not stored; sent holds each idempotency
+	// key's content, so reusing a key for other content is a 409 as in Orbit.
+	reject string
+	sent   map[string]string
+}
+
+func (f *fakeOrbit) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.down {
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	switch {
+	case r.URL.Path == "/v1/ingest":
+		var params orbitmemory.IngestParams
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		key := r.Header.Get("Idempotency-Key")
+		if sent, seen := f.sent[key]; seen && sent != params.Content {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		if f.sent == nil {
+			f.sent = map[string]string{}
+		}
+		f.sent[key] = params.Content
+		if f.reject != "" && strings.Contains(params.Content, f.reject) {
+			_ = json.NewEncoder(w).Encode(map[string]any{"stored": false, "decision_reason": "low value"})
+			return
+		}
+		id, seen := f.idempotency[key]
+		if !seen {
+			id = "m" + strconv.Itoa(len(f.memories)+1)
+			f.memories[id] = &fakeMemory{content: params.Content, version: 1, updatedAt: time.Now()}
+			f.idempotency[key] = id
+			f.record(id, "created")
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"memory_id": id, "stored": true})
+	case r.URL.Path == "/v1/retrieve":
+		var out []map[string]any
+		for id, memory := range f.memories {
+			if strings.Contains(memory.content, r.URL.Query().Get("query")) {
+				out = append(out, map[string]any{"memory_id": id, "content": memory.content})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"memories": out})
+	case r.URL.Path == "/v1/changes":
+		start, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": f.changes[start:], "cursor": strconv.Itoa(len(f.changes)), "has_more": false,
+		})
+	case strings.HasSuffix(r.URL.Path, "/versions"):
+		id := strings.Split(r.URL.Path, "/")[3]
+		memory := f.memories[id]
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"memory_id":       id,
+			"current_version": memory.version,
+			"versions": []map[string]any{{
+				"version": memory.version, "content": memory.content, "recorded_at": memory.updatedAt,
+			}},
+		})
+	case r.Method == http.MethodPatch:
+		id := strings.Split(r.URL.Path, "/")[3]
+		memory := f.memories[id]
+		if r.Header.Get("If-Match") != strconv.Quote(strconv.Itoa(memory.version)) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.edit(id, body["content"])
+		_ = json.NewEncoder(w).Encode(map[string]any{"memory_id": id, "version": memory.version})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeOrbit) edit(id, content string) {
+	memory := f.memories[id]
+	memory.content = content
+	memory.version++
+	memory.updatedAt = time.Now()
+	f.record(id, "updated")
+}
+
+func (f *fakeOrbit) record(id, operation string) {
+	f.changes = append(f.changes, orbitmemory.MemoryChange{
+		Sequence:  len(f.changes) + 1,
+		MemoryID:  id,
+		Operation: operation,
+		Memory:    map[string]any{"content": f.memories[id].content},
+	})
+}
+
+func (f *fakeOrbit) setDown(down bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.down = down
+}
+
+func TestOfflineClientWritesLocallyAndSyncsWithConflictResolution(t *testing.T) {
+	orbit := &fakeOrbit{memories: map[string]*fakeMemory{}, idempotency: map[string]string{}}
+	server := httptest.NewServer(orbit)
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "orbit.json")
+	remote := orbitmemory.NewClient(orbitmemory.Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	client, err := Open(remote, Options{Store: FileStore{Path: path}, PullChanges: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	orbit.setDown(true)
+	entry, err := client.Remember(orbitmemory.IngestParams{Content: "Alice prefers tea", EntityID: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	memories, err := client.Retrieve(ctx, orbitmemory.RetrieveParams{Query: "tea", EntityID: "alice"})
+	if err != nil || len(memories) != 1 || !memories[0].Stale || memories[0].MemoryID != entry.LocalID {
+		t.Fatalf("expected the local write while offline, got %+v, %v", memories, err)
+	}
+	if _, err := client.Sync(ctx); err == nil || client.Pending() != 1 {
+		t.Fatalf("expected an offline sync to keep the write queued: %v", err)
+	}
+
+	// Reopening from the file keeps the queue; the sync pushes it exactly once.
+	client, err = Open(remote, Options{Store: FileStore{Path: path}, PullChanges: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	orbit.setDown(false)
+	result, err := client.Sync(ctx)
+	if err != nil || result.Created != 1 || client.Pending() != 0 {
+		t.Fatalf("unexpected sync: %+v, %v", result, err)
+	}
+	if _, err := client.Sync(ctx); err != nil || len(orbit.memories) != 1 {
+		t.Fatalf("memory was pushed twice: %v", orbit.memories)
+	}
+
+	// Edited on both sides: the resolver decides, and the server keeps the result.
+	orbit.mu.Lock()
+	orbit.edit("m1", "Alice prefers green tea")
+	orbit.mu.Unlock()
+	if _, err := client.Update(entry.LocalID, "Alice prefers coffee now"); err != nil {
+		t.Fatal(err)
+	}
+	result, err = client.Sync(ctx)
+	if err != nil || result.Conflicts != 1 || result.Updated != 1 {
+		t.Fatalf("unexpected conflict sync: %+v, %v", result, err)
+	}
+	if got := orbit.memories["m1"]; got.content != "Alice prefers coffee now" || got.version != 3 {
+		t.Fatalf("unexpected server memory: %+v", got)
+	}
+
+	// A server-side edit to a clean entry is pulled without a conflict, and the next
+	// local edit applies on top of it.
+	orbit.mu.Lock()
+	orbit.edit("m1", "Alice prefers espresso")
+	orbit.mu.Unlock()
+	if result, err = client.Sync(ctx); err != nil || result.Pulled != 1 {
+		t.Fatalf("unexpected pull: %+v, %v", result, err)
+	}
+	if got := client.Entries()[0].Content; got != "Alice prefers espresso" {
+		t.Fatalf("pull did not update the local copy: %q", got)
+	}
+	if _, err := client.Update(entry.LocalID, "Alice prefers decaf espresso"); err != nil {
+		t.Fatal(err)
+	}
+	client.opts.Resolve = ServerWins
+	if result, err = client.Sync(ctx); err != nil || result.Conflicts != 0 || result.Updated != 1 {
+		t.Fatalf("unexpected sync after pull: %+v, %v", result, err)
+	}
+	if got := orbit.memories["m1"].content; got != "Alice prefers decaf espresso" {
+		t.Fatalf("unexpected server content: %q", got)
+	}
+}
+
+func TestOfflineClientRetriesAnEditedRejectionUnderANewIdempotencyKey(t *testing.T) {
+	orbit := &fakeOrbit{memories: map[string]*fakeMemory{}, idempotency: map[string]string{}, reject: "ok"}
+	server := httptest.NewServer(orbit)
+	defer server.Close()
+	remote := orbitmemory.NewClient(orbitmemory.Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	client, err := Open(remote, Options{Store: FileStore{Path: filepath.Join(t.TempDir(), "orbit.json")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := client.Remember(orbitmemory.IngestParams{Content: "ok", EntityID: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result, err := client.Sync(ctx); err != nil || result.Rejected != 1 {
+		t.Fatalf("expected the write to be rejected: %+v, %v", result, err)
+	}
+
+	edited, err := client.Update(entry.LocalID, "Alice is allergic to peanuts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edited.IdempotencyKey == "" || edited.IdempotencyKey == entry.LocalID {
+		t.Fatalf("expected a fresh idempotency key, got %q", edited.IdempotencyKey)
+	}
+	result, err := client.Sync(ctx)
+	if err != nil || result.Created != 1 || result.Rejected != 0 {
+		t.Fatalf("expected the edited entry to be stored: %+v, %v", result, err)
+	}
+	if got := client.Entries()[0]; got.MemoryID == "" || got.Rejected || got.SyncError != "" {
+		t.Fatalf("unexpected entry after retry: %+v", got)
+	}
+}
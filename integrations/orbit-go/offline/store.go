@@ -0,0 +1,95 @@
+package offline
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	orbitmemory "github.com/Intina47/orbit/integrations/orbit-go"
+)
+
+// Entry is one memory in the local store: written here first, then synced to Orbit.
+type Entry struct {
+	// LocalID identifies the entry locally and is the ingest's idempotency key until the
+	// entry is rejected and edited; IdempotencyKey then replaces it for the new content.
+	LocalID        string `json:"local_id"`
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	// MemoryID is Orbit's id, empty until the entry's first sync.
+	MemoryID  string                `json:"memory_id,omitempty"`
+	Content   string                `json:"content"`
+	EventType orbitmemory.EventType `json:"event_type,omitempty"`
+	EntityID  string                `json:"entity_id,omitempty"`
+	Metadata  map[string]any        `json:"metadata,omitempty"`
+	// Version and BaseContent are the server version and content the local content was
+	// last reconciled with; a conflict is a server change to a memory edited locally.
+	Version     int    `json:"version,omitempty"`
+	BaseContent string `json:"base_content,omitempty"`
+	// Dirty marks a local write not yet on the server.
+	Dirty bool `json:"dirty,omitempty"`
+	// Rejected is set when Orbit's decision engine declined to store the memory; it stays
+	// local-only and is not retried until Update edits it.
+	Rejected  bool      `json:"rejected,omitempty"`
+	SyncError string    `json:"sync_error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	SyncedAt  time.Time `json:"synced_at,omitempty"`
+}
+
+// State is everything the offline client persists.
+type State struct {
+	Entries []Entry `json:"entries"`
+	// ChangeCursor is the change feed position already applied locally.
+	ChangeCursor string `json:"change_cursor,omitempty"`
+}
+
+// Store persists State. Save is called after every local write and sync step.
+type Store interface {
+	Load() (State, error)
+	Save(State) error
+}
+
+// FileStore keeps State as one JSON file, replaced atomically on every save.
+type FileStore struct {
+	Path string
+}
+
+// Load reads the file; a missing file is an empty state.
+func (s FileStore) Load() (State, error) {
+	var state State
+	raw, err := os.ReadFile(s.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	return state, json.Unmarshal(raw, &state)
+}
+
+// Save writes state to a temporary file next to Path and renames it into place.
+func (s FileStore) Save(state State) error {
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
@@ -0,0 +1,183 @@
+package orbitmemory
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ListParams configures a list call. PageSize is sent as the limit query parameter (the
+// server default applies when it is zero); Cursor resumes from an earlier Cursor() value.
+type ListParams struct {
+	PageSize int
+	Cursor   string
+}
+
+// MemoryChange is one entry of the GET /v1/changes feed. Memory is nil for deletes.
+type MemoryChange struct {
+	Sequence   int            `json:"sequence"`
+	MemoryID   string         `json:"memory_id"`
+	Operation  string         `json:"operation"`
+	OccurredAt time.Time      `json:"occurred_at"`
+	Memory     map[string]any `json:"memory"`
+}
+
+// APIKey is one GET /v1/dashboard/keys entry. The secret itself is never listed.
+type APIKey struct {
+	KeyID          string     `json:"key_id"`
+	Name           string     `json:"name"`
+	KeyPrefix      string     `json:"key_prefix"`
+	Scopes         []string   `json:"scopes"`
+	AllowedOrigins []string   `json:"allowed_origins"`
+	Status         string     `json:"status"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
+	LastUsedSource string     `json:"last_used_source"`
+	RevokedAt      *time.Time `json:"revoked_at"`
+}
+
+// pager walks a {data, cursor, has_more} endpoint one page at a time.
+type pager[T any] struct {
+	ctx    context.Context
+	client *Client
+	path   string
+	query  url.Values
+	page   []T
+	index  int
+	cursor string
+	done   bool
+	err    error
+}
+
+func newPager[T any](ctx context.Context, c *Client, path string, params ListParams) *pager[T] {
+	query := url.Values{}
+	if params.PageSize > 0 {
+		query.Set("limit", strconv.Itoa(params.PageSize))
+	}
+	return &pager[T]{ctx: ctx, client: c, path: path, query: query, index: -1, cursor: params.Cursor}
+}
+
+func (p *pager[T]) next() bool {
+	if p.err != nil {
+		return false
+	}
+	p.index++
+	for p.index >= len(p.page) {
+		if p.done {
+			return false
+		}
+		if !p.fetch() {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *pager[T]) fetch() bool {
+	if p.cursor != "" {
+		p.query.Set("cursor", p.cursor)
+	}
+	var out struct {
+		Data    []T    `json:"data"`
+		Cursor  string `json:"cursor"`
+		HasMore bool   `json:"has_more"`
+	}
+	if err := p.client.do(p.ctx, http.MethodGet, p.path+"?"+p.query.Encode(), nil, &out); err != nil {
+		p.err = err
+		return false
+	}
+	p.page, p.index = out.Data, 0
+	if out.Cursor != "" {
+		p.cursor = out.Cursor
+	}
+	// A page without a new cursor cannot lead anywhere, whatever has_more says.
+	p.done = !out.HasMore || out.Cursor == ""
+	return true
+}
+
+func (p *pager[T]) current() T {
+	if p.index < 0 || p.index >= len(p.page) {
+		var zero T
+		return zero
+	}
+	return p.page[p.index]
+}
+
+// MemoryIterator pages through ListMemories results:
+//
+//	it := client.ListMemories(ctx, orbitmemory.ListParams{PageSize: 100})
+//	for it.Next() {
+//		fmt.Println(it.Memory().Content)
+//	}
+//	if err := it.Err(); err != nil { ... }
+type MemoryIterator struct {
+	p *pager[Memory]
+}
+
+// Next advances to the next memory, fetching the next page when the current one is used up.
+// It returns false when the list is exhausted or a request fails; check Err afterwards.
+func (it *MemoryIterator) Next() bool { return it.p.next() }
+
+// Memory returns the memory Next advanced to.
+func (it *MemoryIterator) Memory() Memory { return it.p.current() }
+
+// Err returns the error that stopped iteration, or nil if the list was exhausted.
+func (it *MemoryIterator) Err() error { return it.p.err }
+
+// Cursor returns the cursor of the last page fetched; pass it as ListParams.Cursor to
+// continue after that page later.
+func (it *MemoryIterator) Cursor() string { return it.p.cursor }
+
+// ListMemories lists stored memories via GET /v1/memories. No request is made until the
+// first call to Next.
+func (c *Client) ListMemories(ctx context.Context, params ListParams) *MemoryIterator {
+	return &MemoryIterator{p: newPager[Memory](ctx, c, "/v1/memories", params)}
+}
+
+// ChangeIterator pages through ListChanges results. See MemoryIterator.
+type ChangeIterator struct {
+	p *pager[MemoryChange]
+}
+
+// Next advances to the next change. See MemoryIterator.Next.
+func (it *ChangeIterator) Next() bool { return it.p.next() }
+
+// Change returns the change Next advanced to.
+func (it *ChangeIterator) Change() MemoryChange { return it.p.current() }
+
+// Err returns the error that stopped iteration, or nil if the feed was drained.
+func (it *ChangeIterator) Err() error { return it.p.err }
+
+// Cursor returns the feed position after the last page fetched. Once Next returns false
+// with a nil Err, pass it to a later ListChanges call to receive only newer changes.
+func (it *ChangeIterator) Cursor() string { return it.p.cursor }
+
+// ListChanges reads the change feed via GET /v1/changes, oldest change first.
+func (c *Client) ListChanges(ctx context.Context, params ListParams) *ChangeIterator {
+	return &ChangeIterator{p: newPager[MemoryChange](ctx, c, "/v1/changes", params)}
+}
+
+// APIKeyIterator pages through ListAPIKeys results. See MemoryIterator.
+type APIKeyIterator struct {
+	p *pager[APIKey]
+}
+
+// Next advances to the next key. See MemoryIterator.Next.
+func (it *APIKeyIterator) Next() bool { return it.p.next() }
+
+// APIKey returns the key Next advanced to.
+func (it *APIKeyIterator) APIKey() APIKey { return it.p.current() }
+
+// Err returns the error that stopped iteration, or nil if the list was exhausted.
+func (it *APIKeyIterator) Err() error { return it.p.err }
+
+// Cursor returns the cursor of the last page fetched.
+func (it *APIKeyIterator) Cursor() string { return it.p.cursor }
+
+// ListAPIKeys lists the account's API keys via GET /v1/dashboard/keys; the token needs the
+// keys:read (or read) scope.
+func (c *Client) ListAPIKeys(ctx context.Context, params ListParams) *APIKeyIterator {
+	return &APIKeyIterator{p: newPager[APIKey](ctx, c, "/v1/dashboard/keys", params)}
+}
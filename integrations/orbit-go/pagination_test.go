@@ -0,0 +1,77 @@
+package orbitmemory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestListMemoriesFetchesEveryPage(t *testing.T) {
+	pages := map[string]map[string]any{
+		"": {
+			"data":     []map[string]any{{"memory_id": "m1"}, {"memory_id": "m2"}},
+			"cursor":   "c1",
+			"has_more": true,
+		},
+		"c1": {"data": []map[string]any{}, "cursor": "c2", "has_more": true},
+		"c2": {"data": []map[string]any{{"memory_id": "m3"}}, "cursor": "c3", "has_more": false},
+	}
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/v1/memories" || r.URL.Query().Get("limit") != "2" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		_ = json.NewEncoder(w).Encode(pages[r.URL.Query().Get("cursor")])
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+	it := client.ListMemories(context.Background(), ListParams{PageSize: 2})
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Memory().MemoryID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 3 || ids[0] != "m1" || ids[2] != "m3" || requests != 3 {
+		t.Fatalf("unexpected iteration: ids=%v requests=%d", ids, requests)
+	}
+	if it.Next() || it.Cursor() != "c3" {
+		t.Fatalf("iterator should stay exhausted at cursor c3, got %q", it.Cursor())
+	}
+}
+
+func TestListChangesSurfacesPageErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("cursor") == "7" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data":     []map[string]any{{"sequence": 7, "memory_id": "m1", "operation": "delete"}},
+			"cursor":   "7",
+			"has_more": true,
+		})
+	}))
+	defer server.Close()
+
+	it := NewClient(Config{BaseURL: server.URL}).ListChanges(context.Background(), ListParams{})
+	if !it.Next() || it.Change().Sequence != 7 || it.Change().Memory != nil {
+		t.Fatalf("unexpected first change: %+v", it.Change())
+	}
+	if it.Next() {
+		t.Fatal("expected the failing page to stop iteration")
+	}
+	var apiErr *APIError
+	if !errors.As(it.Err(), &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected a 429 APIError, got %v", it.Err())
+	}
+	if it.Cursor() != "7" {
+		t.Fatalf("cursor should point after the last good page, got %q", it.Cursor())
+	}
+}
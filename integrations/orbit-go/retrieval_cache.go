@@ -0,0 +1,83 @@
+package orbitmemory
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// CachedRetrieval is one Retrieve result kept by a RetrievalCache.
+type CachedRetrieval struct {
+	Memories []Memory  `json:"memories"`
+	StoredAt time.Time `json:"stored_at"`
+}
+
+// RetrievalCache persists recent Retrieve results for WithRetrievalCache. Keys are opaque
+// hashes of the credential and the full retrieve query. The boltcache module provides a
+// BoltDB file implementation.
+type RetrievalCache interface {
+	Load(ctx context.Context, key string) (CachedRetrieval, bool, error)
+	Store(ctx context.Context, key string, entry CachedRetrieval) error
+}
+
+// WithRetrievalCache stores every successful Retrieve in cache and, when Orbit is
+// unreachable (transport errors, timeouts, 408, 425, 429 and 5xx after retries, or
+// ErrCircuitOpen), answers from it instead of failing. Memories served from the cache have
+// Stale set. Entries older than maxAge are not served; 0 serves entries of any age. Cache
+// errors never fail a call: a failed store is skipped and a failed load returns the
+// original error.
+func WithRetrievalCache(cache RetrievalCache, maxAge time.Duration) Option {
+	return func(c *Client) {
+		c.retrievalCache = cache
+		c.retrievalCacheMaxAge = maxAge
+	}
+}
+
+func (c *Client) retrievalCacheKey(path string) string {
+	credential := c.token
+	if c.signingKeyID != "" {
+		credential = c.signingKeyID
+	}
+	sum := sha256.Sum256([]byte(c.baseURL + "\n" + credential + "\n" + path))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *Client) storeRetrieval(ctx context.Context, path string, memories []Memory) {
+	entry := CachedRetrieval{Memories: memories, StoredAt: time.Now().UTC()}
+	if err := c.retrievalCache.Store(ctx, c.retrievalCacheKey(path), entry); err != nil {
+		c.logEvent(ctx, c.logLevels.Failure, "orbit retrieval cache store failed", "error", err)
+	}
+}
+
+// staleRetrieval returns the cached result for path when err means Orbit was unreachable.
+func (c *Client) staleRetrieval(ctx context.Context, path string, err error) ([]Memory, bool) {
+	status := 0
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		status = apiErr.StatusCode
+	}
+	unreachable := errors.Is(err, ErrCircuitOpen) || classifyOutcome(ctx, status, err) == outcomeFailure
+	if !unreachable {
+		return nil, false
+	}
+	entry, ok, loadErr := c.retrievalCache.Load(context.WithoutCancel(ctx), c.retrievalCacheKey(path))
+	if loadErr != nil {
+		c.logEvent(ctx, c.logLevels.Failure, "orbit retrieval cache load failed", "error", loadErr)
+		return nil, false
+	}
+	age := time.Since(entry.StoredAt)
+	if !ok || (c.retrievalCacheMaxAge > 0 && age > c.retrievalCacheMaxAge) {
+		c.logEvent(ctx, c.logLevels.Cache, "orbit retrieval cache miss", "path", path, "error", err)
+		return nil, false
+	}
+	memories := make([]Memory, len(entry.Memories))
+	for i, memory := range entry.Memories {
+		memory.Stale = true
+		memories[i] = memory
+	}
+	c.logEvent(ctx, c.logLevels.Cache, "orbit retrieval cache hit",
+		"path", path, "age", age, "memories", len(memories), "error", err)
+	return memories, true
+}
@@ -0,0 +1,74 @@
+package orbitmemory
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type mapCache map[string]CachedRetrieval
+
+func (m mapCache) Load(_ context.Context, key string) (CachedRetrieval, bool, error) {
+	entry, ok := m[key]
+	return entry, ok, nil
+}
+
+func (m mapCache) Store(_ context.Context, key string, entry CachedRetrieval) error {
+	m[key] = entry
+	return nil
+}
+
+func TestRetrievalCacheServesStaleResultsWhileOrbitIsDown(t *testing.T) {
+	var down atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Query().Get("query") == "bad":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		case down.Load():
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			_, _ = w.Write([]byte(`{"memories": [{"memory_id": "m1", "content": "Alice prefers Go"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	cache := mapCache{}
+	client := NewClient(Config{BaseURL: server.URL, Token: "a"}, WithRetrievalCache(cache, time.Hour))
+	ctx := context.Background()
+	params := RetrieveParams{Query: "language", EntityID: "alice"}
+	if memories, err := client.Retrieve(ctx, params); err != nil || memories[0].Stale {
+		t.Fatalf("unexpected live result: %+v, %v", memories, err)
+	}
+
+	down.Store(true)
+	memories, err := client.Retrieve(ctx, params)
+	if err != nil || len(memories) != 1 || !memories[0].Stale || memories[0].Content != "Alice prefers Go" {
+		t.Fatalf("expected a stale cached result, got %+v, %v", memories, err)
+	}
+	// Other queries, other credentials, and client errors are never answered from the cache.
+	if _, err := client.Retrieve(ctx, RetrieveParams{Query: "other"}); err == nil {
+		t.Fatal("expected an error for an uncached query")
+	}
+	other := NewClient(Config{BaseURL: server.URL, Token: "b"}, WithRetrievalCache(cache, time.Hour))
+	if _, err := other.Retrieve(ctx, params); err == nil {
+		t.Fatal("another credential was served a cached result")
+	}
+	down.Store(false)
+	if _, err := client.Retrieve(ctx, RetrieveParams{Query: "bad"}); err == nil {
+		t.Fatal("expected the 422 to surface")
+	}
+
+	down.Store(true)
+	for key, entry := range cache {
+		entry.StoredAt = entry.StoredAt.Add(-2 * time.Hour)
+		cache[key] = entry
+	}
+	var apiErr *APIError
+	if _, err := client.Retrieve(ctx, params); !errors.As(err, &apiErr) {
+		t.Fatalf("expired entry was served: %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package orbitmemory
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// SavedQueryParams fills a saved query's template and scopes the run. Params must supply
+// exactly the template's placeholders. A zero Limit keeps the saved limit.
+type SavedQueryParams struct {
+	Params    map[string]string `json:"params,omitempty"`
+	EntityID  string            `json:"entity_id,omitempty"`
+	SessionID string            `json:"session_id,omitempty"`
+	AgentID   string            `json:"agent_id,omitempty"`
+	Limit     int               `json:"limit,omitempty"`
+	Boost     *Boost            `json:"boost,omitempty"`
+}
+
+// RunSavedQuery retrieves with the query saved under name at /v1/saved-queries, so its
+// template, filters, and profile can be tuned server-side without changing callers.
+func (c *Client) RunSavedQuery(ctx context.Context, name string, params SavedQueryParams) ([]Memory, error) {
+	var out struct {
+		Memories []Memory `json:"memories"`
+	}
+	path := "/v1/saved-queries/" + url.PathEscape(name) + "/run"
+	if err := c.do(ctx, http.MethodPost, path, params, &out); err != nil {
+		return nil, err
+	}
+	return out.Memories, nil
+}
@@ -0,0 +1,44 @@
+package orbitmemory
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRunSavedQuerySendsParams(t *testing.T) {
+	var path string
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.Method + " " + r.URL.EscapedPath()
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"memories": []map[string]any{{"memory_id": "m1", "content": "Prefers dark mode"}},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+	memories, err := client.RunSavedQuery(context.Background(), "ui/prefs", SavedQueryParams{
+		Params:   map[string]string{"product": "dashboard"},
+		EntityID: "alice",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "POST /v1/saved-queries/ui%2Fprefs/run" {
+		t.Fatalf("path = %q", path)
+	}
+	params, _ := body["params"].(map[string]any)
+	if params["product"] != "dashboard" || body["entity_id"] != "alice" {
+		t.Fatalf("body = %v", body)
+	}
+	if _, ok := body["limit"]; ok {
+		t.Fatalf("zero limit was sent: %v", body)
+	}
+	if len(memories) != 1 || memories[0].MemoryID != "m1" {
+		t.Fatalf("memories = %+v", memories)
+	}
+}
@@ -0,0 +1,84 @@
+package orbitmemory
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Request-signing headers; see src/orbit/signing.py for the canonical format.
+const (
+	SignatureScheme = "ORBIT-HMAC-SHA256"
+	TimestampHeader = "X-Orbit-Timestamp"
+	NonceHeader     = "X-Orbit-Nonce"
+)
+
+// CanonicalRequest builds the string that is HMAC-signed for a request.
+func CanonicalRequest(method, path, rawQuery, timestamp, nonce string, body []byte) string {
+	if path == "" {
+		path = "/"
+	}
+	sum := sha256.Sum256(body)
+	return strings.Join([]string{
+		strings.ToUpper(method),
+		path,
+		canonicalQuery(rawQuery),
+		timestamp,
+		nonce,
+		hex.EncodeToString(sum[:]),
+	}, "\n")
+}
+
+// ComputeSignature returns the lowercase hex HMAC-SHA256 of canonical.
+func ComputeSignature(secret, canonical string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(canonical))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (c *Client) signRequest(req *http.Request, body []byte) error {
+	nonceBytes := make([]byte, 16)
+	if _, err := rand.Read(nonceBytes); err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce := base64.RawURLEncoding.EncodeToString(nonceBytes)
+	canonical := CanonicalRequest(req.Method, req.URL.Path, req.URL.RawQuery, timestamp, nonce, body)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(NonceHeader, nonce)
+	req.Header.Set("Authorization", SignatureScheme+" KeyId="+c.signingKeyID+
+		", Signature="+ComputeSignature(c.signingSecret, canonical))
+	return nil
+}
+
+func canonicalQuery(rawQuery string) string {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return rawQuery
+	}
+	pairs := make([][2]string, 0, len(values))
+	for key, items := range values {
+		for _, item := range items {
+			pairs = append(pairs, [2]string{key, item})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+	encoded := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		encoded = append(encoded, url.QueryEscape(pair[0])+"="+url.QueryEscape(pair[1]))
+	}
+	return strings.Join(encoded, "&")
+}
@@ -0,0 +1,48 @@
+package orbitmemory
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalRequestMatchesPythonSigner(t *testing.T) {
+	canonical := CanonicalRequest("get", "/v1/retrieve", "query=a+b&limit=5&entity_id=x%2Fy", "1", "n", nil)
+	want := "GET\n/v1/retrieve\nentity_id=x%2Fy&limit=5&query=a+b\n1\nn\n" +
+		"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	if canonical != want {
+		t.Fatalf("canonical mismatch:\n%q\n%q", canonical, want)
+	}
+	if got := ComputeSignature("secret", canonical); got != "f82ea6b817e68d8b1bf45f5e1f5706ecb192febcd7c049f3014617d2b3aef50a" {
+		t.Fatalf("unexpected signature %s", got)
+	}
+}
+
+func TestSignedClientOmitsBearerToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		header := r.Header.Get("Authorization")
+		if !strings.HasPrefix(header, SignatureScheme+" KeyId=backend-1, Signature=") {
+			t.Errorf("unexpected Authorization header %q", header)
+		}
+		canonical := CanonicalRequest(r.Method, r.URL.Path, r.URL.RawQuery,
+			r.Header.Get(TimestampHeader), r.Header.Get(NonceHeader), body)
+		if !strings.HasSuffix(header, ComputeSignature("signing-secret-0123456789", canonical)) {
+			t.Errorf("signature does not verify")
+		}
+		_, _ = w.Write([]byte(`{"memory_id":"m1","stored":true}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL:       server.URL,
+		SigningKeyID:  "backend-1",
+		SigningSecret: "signing-secret-0123456789",
+	})
+	if _, err := client.Ingest(context.Background(), IngestParams{Content: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,58 @@
+package orbitmemory
+
+import (
+	"context"
+	"net/http"
+)
+
+// Trace headers sent with every request made under a context carrying them, so Orbit's
+// work shows up in the caller's distributed trace.
+const (
+	RequestIDHeader   = "X-Request-ID"
+	TraceparentHeader = "traceparent"
+	TracestateHeader  = "tracestate"
+)
+
+type traceKey struct{}
+
+type traceValues struct {
+	requestID   string
+	traceparent string
+	tracestate  string
+}
+
+// WithRequestID returns a context whose Orbit requests send requestID as X-Request-ID.
+// The API echoes it back and logs it, and APIError.RequestID reports it on failures.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	values := traceFrom(ctx)
+	values.requestID = requestID
+	return context.WithValue(ctx, traceKey{}, values)
+}
+
+// WithTraceContext returns a context whose Orbit requests carry a W3C traceparent and,
+// optionally, tracestate. Pass the values an OpenTelemetry propagator injects for the
+// current span to join Orbit calls to an existing trace.
+func WithTraceContext(ctx context.Context, traceparent, tracestate string) context.Context {
+	values := traceFrom(ctx)
+	values.traceparent = traceparent
+	values.tracestate = tracestate
+	return context.WithValue(ctx, traceKey{}, values)
+}
+
+func traceFrom(ctx context.Context) traceValues {
+	values, _ := ctx.Value(traceKey{}).(traceValues)
+	return values
+}
+
+func setTraceHeaders(ctx context.Context, header http.Header) {
+	values := traceFrom(ctx)
+	if values.requestID != "" {
+		header.Set(RequestIDHeader, values.requestID)
+	}
+	if values.traceparent != "" {
+		header.Set(TraceparentHeader, values.traceparent)
+		if values.tracestate != "" {
+			header.Set(TracestateHeader, values.tracestate)
+		}
+	}
+}
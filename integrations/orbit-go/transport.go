@@ -0,0 +1,71 @@
+package orbitmemory
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// TransportConfig tunes the HTTP transport NewClient builds when Config.HTTPClient is nil.
+// Zero fields keep net/http's defaults. The transport always attempts HTTP/2 over TLS, even
+// with a custom TLSClientConfig, so concurrent calls share one connection per host.
+type TransportConfig struct {
+	// MaxIdleConnsPerHost is how many idle HTTP/1.1 connections are kept per host (default 2).
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost caps dialing, active, and idle connections per host; 0 is unlimited.
+	MaxConnsPerHost       int
+	IdleConnTimeout       time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+	// TLSClientConfig sets custom root CAs or client certificates for mTLS.
+	TLSClientConfig *tls.Config
+	// DisableHTTP2 pins connections to HTTP/1.1, e.g. behind a proxy that mishandles HTTP/2.
+	DisableHTTP2 bool
+}
+
+func newTransport(cfg TransportConfig) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Setting TLSClientConfig turns off net/http's automatic HTTP/2 unless this is set.
+	transport.ForceAttemptHTTP2 = !cfg.DisableHTTP2
+	if cfg.DisableHTTP2 {
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	if cfg.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	}
+	if cfg.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = cfg.MaxConnsPerHost
+	}
+	if cfg.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = cfg.IdleConnTimeout
+	}
+	if cfg.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = cfg.TLSHandshakeTimeout
+	}
+	if cfg.ResponseHeaderTimeout > 0 {
+		transport.ResponseHeaderTimeout = cfg.ResponseHeaderTimeout
+	}
+	if cfg.TLSClientConfig != nil {
+		transport.TLSClientConfig = cfg.TLSClientConfig.Clone()
+	}
+	return transport
+}
+
+// Ping checks that Orbit is reachable and accepts the client's credentials, so a wrong
+// BaseURL, token, or signing key fails at startup instead of on the first real call. It
+// calls GET /v1/health, then GET /v1/status; a key without the read scope still passes,
+// since Orbit authenticated it before refusing the call.
+func (c *Client) Ping(ctx context.Context) error {
+	if err := c.do(ctx, http.MethodGet, "/v1/health", nil, nil); err != nil {
+		return fmt.Errorf("orbit: ping %s: %w", c.baseURL, err)
+	}
+	err := c.do(ctx, http.MethodGet, "/v1/status", nil, nil)
+	var apiErr *APIError
+	if err == nil || errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusForbidden {
+		return nil
+	}
+	return fmt.Errorf("orbit: ping %s: %w", c.baseURL, err)
+}
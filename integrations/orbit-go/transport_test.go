@@ -0,0 +1,61 @@
+package orbitmemory
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientNegotiatesHTTP2AndPings(t *testing.T) {
+	var protos []int
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		protos = append(protos, r.ProtoMajor)
+		if r.URL.Path == "/v1/health" {
+			_, _ = w.Write([]byte(`{"status":"ok"}`))
+			return
+		}
+		switch r.Header.Get("Authorization") {
+		case "Bearer orbit_pk_good":
+			_, _ = w.Write([]byte(`{}`))
+		case "Bearer orbit_pk_write_only":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+	tlsConfig := server.Client().Transport.(*http.Transport).TLSClientConfig
+
+	newClient := func(token string, transport TransportConfig) *Client {
+		transport.TLSClientConfig = tlsConfig
+		return NewClient(Config{BaseURL: server.URL, Token: token, Transport: transport})
+	}
+	ctx := context.Background()
+	if err := newClient("orbit_pk_good", TransportConfig{MaxConnsPerHost: 4}).Ping(ctx); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+	if protos[0] != 2 || protos[1] != 2 {
+		t.Fatalf("protocols = %v, want HTTP/2", protos)
+	}
+	if err := newClient("orbit_pk_write_only", TransportConfig{}).Ping(ctx); err != nil {
+		t.Fatalf("Ping with a write-only key: %v", err)
+	}
+
+	err := newClient("orbit_pk_bad", TransportConfig{}).Ping(ctx)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Ping with a bad key = %v, want 401", err)
+	}
+
+	protos = nil
+	if err := newClient("orbit_pk_good", TransportConfig{DisableHTTP2: true}).Ping(ctx); err != nil {
+		t.Fatalf("Ping over HTTP/1.1: %v", err)
+	}
+	if protos[0] != 1 {
+		t.Fatalf("protocols = %v, want HTTP/1.1 with DisableHTTP2", protos)
+	}
+}
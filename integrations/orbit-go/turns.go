@@ -0,0 +1,61 @@
+package orbitmemory
+
+import (
+	"context"
+	"strings"
+)
+
+// Event types written for conversation turns, matching the Python SDK and chat proxy.
+const (
+	UserEventType      = EventUserQuestion
+	AssistantEventType = EventAssistantResponse
+)
+
+// Turn is one conversation message to remember.
+type Turn struct {
+	Role    string
+	Content string
+}
+
+// RememberTurns ingests user and assistant turns for entityID; other roles are skipped.
+func (c *Client) RememberTurns(ctx context.Context, entityID, source string, turns []Turn) error {
+	for _, turn := range turns {
+		content := strings.TrimSpace(turn.Content)
+		if content == "" {
+			continue
+		}
+		var eventType EventType
+		switch turn.Role {
+		case "user":
+			eventType = UserEventType
+		case "assistant", "model":
+			eventType = AssistantEventType
+		default:
+			continue
+		}
+		_, err := c.Ingest(ctx, IngestParams{
+			Content:   content,
+			EventType: eventType,
+			EntityID:  entityID,
+			Metadata:  map[string]any{"source": source},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FormatMemories renders memories as a system-prompt block, or "" when there are none.
+func FormatMemories(memories []Memory) string {
+	if len(memories) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Relevant memories about this user (from Orbit):")
+	for _, memory := range memories {
+		b.WriteString("\n- ")
+		b.WriteString(memory.Content)
+	}
+	return b.String()
+}
@@ -0,0 +1,69 @@
+package orbitmemory
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// MemoryVersion is one entry of a memory's content history.
+type MemoryVersion struct {
+	Version      int       `json:"version"`
+	Operation    string    `json:"operation"`
+	Content      string    `json:"content"`
+	RecordedAt   time.Time `json:"recorded_at"`
+	RevertedFrom *int      `json:"reverted_from"`
+}
+
+// MemoryHistory is the GET /v1/memories/{id}/versions response, oldest version first.
+type MemoryHistory struct {
+	MemoryID       string          `json:"memory_id"`
+	CurrentVersion int             `json:"current_version"`
+	Versions       []MemoryVersion `json:"versions"`
+	DeletedAt      *time.Time      `json:"deleted_at"`
+	SupersededBy   string          `json:"superseded_by"`
+}
+
+// Current returns the newest version, or false when the history is empty.
+func (h MemoryHistory) Current() (MemoryVersion, bool) {
+	for i := len(h.Versions) - 1; i >= 0; i-- {
+		if h.Versions[i].Version == h.CurrentVersion {
+			return h.Versions[i], true
+		}
+	}
+	return MemoryVersion{}, false
+}
+
+// UpdateMemory replaces a memory's content. A positive version is sent as If-Match, so the
+// update fails with a 412 (see IsPreconditionFailed) when the memory changed since then.
+func (c *Client) UpdateMemory(ctx context.Context, memoryID, content string, version int) (Memory, error) {
+	var out Memory
+	header := http.Header{}
+	if version > 0 {
+		header.Set("If-Match", strconv.Quote(strconv.Itoa(version)))
+	}
+	payload := map[string]string{"content": content}
+	err := c.doWithHeader(ctx, http.MethodPatch, memoryPath(memoryID, ""), header, payload, &out)
+	return out, err
+}
+
+// MemoryVersions returns a memory's content history.
+func (c *Client) MemoryVersions(ctx context.Context, memoryID string) (MemoryHistory, error) {
+	var out MemoryHistory
+	err := c.do(ctx, http.MethodGet, memoryPath(memoryID, "/versions"), nil, &out)
+	return out, err
+}
+
+// IsPreconditionFailed reports whether err is a 412 from the API: the If-Match version
+// was no longer current.
+func IsPreconditionFailed(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusPreconditionFailed
+}
+
+func memoryPath(memoryID, suffix string) string {
+	return "/v1/memories/" + url.PathEscape(memoryID) + suffix
+}
@@ -0,0 +1,55 @@
+terraform {
+  required_providers {
+    orbit = {
+      source = "intina47/orbit"
+    }
+  }
+}
+
+provider "orbit" {}
+
+resource "orbit_namespace" "acme" {
+  account_key  = "acme"
+  display_name = "ACME Corp"
+  pipeline     = ["pii", "extraction", "embedding", "indexing"]
+}
+
+resource "orbit_event_type_registry" "acme" {
+  account_key = orbit_namespace.acme.account_key
+  event_types = [
+    { name = "user_question" },
+    { name = "preference_stated", description = "Explicit user preferences" },
+    { name = "support_ticket" },
+  ]
+}
+
+resource "orbit_retention_policy" "acme" {
+  account_key = orbit_namespace.acme.account_key
+  days        = 365
+  event_types = {
+    user_question = 30
+  }
+}
+
+resource "orbit_pipeline_webhook" "acme" {
+  account_key    = orbit_namespace.acme.account_key
+  url            = "https://hooks.acme.example/orbit"
+  secret         = var.webhook_secret
+  failure_policy = "reject"
+}
+
+resource "orbit_api_key" "backend" {
+  account_key = orbit_namespace.acme.account_key
+  name        = "backend"
+  scopes      = ["read", "write"]
+}
+
+variable "webhook_secret" {
+  type      = string
+  sensitive = true
+}
+
+output "backend_api_key" {
+  value     = orbit_api_key.backend.key
+  sensitive = true
+}
@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	orbitmemory "github.com/Intina47/orbit/integrations/orbit-go"
+)
+
+var (
+	_ resource.ResourceWithConfigure   = (*apiKeyResource)(nil)
+	_ resource.ResourceWithImportState = (*apiKeyResource)(nil)
+)
+
+// NewAPIKeyResource returns the orbit_api_key resource.
+func NewAPIKeyResource() resource.Resource {
+	return &apiKeyResource{}
+}
+
+type apiKeyResource struct {
+	client *orbitmemory.Client
+}
+
+type apiKeyModel struct {
+	ID             types.String `tfsdk:"id"`
+	AccountKey     types.String `tfsdk:"account_key"`
+	Name           types.String `tfsdk:"name"`
+	Scopes         types.List   `tfsdk:"scopes"`
+	AllowedOrigins types.List   `tfsdk:"allowed_origins"`
+	KeyPrefix      types.String `tfsdk:"key_prefix"`
+	Key            types.String `tfsdk:"key"`
+}
+
+func (r *apiKeyResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_api_key"
+}
+
+func (r *apiKeyResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	keep := []planmodifier.String{stringplanmodifier.UseStateForUnknown()}
+	replace := []planmodifier.String{stringplanmodifier.RequiresReplace()}
+	resp.Schema = schema.Schema{
+		Description: "A tenant API key. Keys are immutable, so any change issues a new key and revokes the old one.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:      true,
+				Description:   "The key ID.",
+				PlanModifiers: keep,
+			},
+			"account_key":     schema.StringAttribute{Required: true, PlanModifiers: replace},
+			"name":            schema.StringAttribute{Required: true, PlanModifiers: replace},
+			"scopes":          replaceableList("Scopes granted to the key. Unset uses the server default."),
+			"allowed_origins": replaceableList("Browser origins allowed to use the key."),
+			"key_prefix":      schema.StringAttribute{Computed: true, PlanModifiers: keep},
+			"key": schema.StringAttribute{
+				Computed:      true,
+				Sensitive:     true,
+				Description:   "The secret, only known for keys created by Terraform.",
+				PlanModifiers: keep,
+			},
+		},
+	}
+}
+
+func replaceableList(description string) schema.ListAttribute {
+	return schema.ListAttribute{
+		Optional:      true,
+		ElementType:   types.StringType,
+		Description:   description,
+		PlanModifiers: []planmodifier.List{listplanmodifier.RequiresReplace()},
+	}
+}
+
+func (r *apiKeyResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.client = configureClient(req, resp)
+}
+
+func (r *apiKeyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan apiKeyModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	params := orbitmemory.APIKeyParams{
+		Name:           plan.Name.ValueString(),
+		Scopes:         listStrings(ctx, plan.Scopes, &resp.Diagnostics),
+		AllowedOrigins: listStrings(ctx, plan.AllowedOrigins, &resp.Diagnostics),
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	issued, err := r.client.IssueAPIKey(ctx, plan.AccountKey.ValueString(), params)
+	if err != nil {
+		apiError(&resp.Diagnostics, "issue API key", err)
+		return
+	}
+	plan.ID = types.StringValue(issued.KeyID)
+	plan.KeyPrefix = types.StringValue(issued.KeyPrefix)
+	plan.Key = types.StringValue(issued.Key)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *apiKeyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state apiKeyModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	key, err := r.client.TenantAPIKey(ctx, state.AccountKey.ValueString(), state.ID.ValueString())
+	if orbitmemory.IsNotFound(err) || (err == nil && key.Status == "revoked") {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		apiError(&resp.Diagnostics, "read API key", err)
+		return
+	}
+	state.Name = types.StringValue(key.Name)
+	state.KeyPrefix = types.StringValue(key.KeyPrefix)
+	state.Scopes = stringList(ctx, key.Scopes, state.Scopes, &resp.Diagnostics)
+	state.AllowedOrigins = stringList(ctx, key.AllowedOrigins, state.AllowedOrigins, &resp.Diagnostics)
+	if state.Key.IsUnknown() {
+		state.Key = types.StringNull()
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+}
+
+// Update is never called with a changed key because every argument forces replacement.
+func (r *apiKeyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan apiKeyModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *apiKeyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state apiKeyModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	err := r.client.RevokeTenantAPIKey(ctx, state.AccountKey.ValueString(), state.ID.ValueString())
+	if err != nil && !orbitmemory.IsNotFound(err) {
+		apiError(&resp.Diagnostics, "revoke API key", err)
+	}
+}
+
+// ImportState takes an "account_key/key_id" ID. Imported keys have no secret in state.
+func (r *apiKeyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	idx := strings.LastIndex(req.ID, "/")
+	if idx <= 0 || idx == len(req.ID)-1 {
+		resp.Diagnostics.AddError("Invalid import ID", "Expected account_key/key_id, got "+req.ID+".")
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("account_key"), req.ID[:idx])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID[idx+1:])...)
+}
@@ -0,0 +1,169 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	orbitmemory "github.com/Intina47/orbit/integrations/orbit-go"
+)
+
+var (
+	_ resource.ResourceWithConfigure   = (*eventTypeRegistryResource)(nil)
+	_ resource.ResourceWithImportState = (*eventTypeRegistryResource)(nil)
+)
+
+// NewEventTypeRegistryResource returns the orbit_event_type_registry resource.
+func NewEventTypeRegistryResource() resource.Resource {
+	return &eventTypeRegistryResource{}
+}
+
+type eventTypeRegistryResource struct {
+	client *orbitmemory.Client
+}
+
+type eventTypeRegistryModel struct {
+	ID         types.String     `tfsdk:"id"`
+	AccountKey types.String     `tfsdk:"account_key"`
+	Enforce    types.Bool       `tfsdk:"enforce"`
+	EventTypes []eventTypeModel `tfsdk:"event_types"`
+}
+
+type eventTypeModel struct {
+	Name        types.String `tfsdk:"name"`
+	Description types.String `tfsdk:"description"`
+}
+
+func (r *eventTypeRegistryResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_event_type_registry"
+}
+
+func (r *eventTypeRegistryResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "The event types a tenant may ingest. Deleting it lets the tenant ingest any event type.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"account_key": schema.StringAttribute{
+				Required:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"enforce": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+				Description: "Reject ingest of unregistered event types. When false the registry is documentation only.",
+			},
+			"event_types": schema.ListNestedAttribute{
+				Required: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name":        schema.StringAttribute{Required: true},
+						"description": schema.StringAttribute{Optional: true},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *eventTypeRegistryResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.client = configureClient(req, resp)
+}
+
+func (r *eventTypeRegistryResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan eventTypeRegistryModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	r.apply(ctx, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *eventTypeRegistryResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state eventTypeRegistryModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	registry, err := r.client.EventTypeRegistry(ctx, state.AccountKey.ValueString())
+	if orbitmemory.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		apiError(&resp.Diagnostics, "read event type registry", err)
+		return
+	}
+	state.ID = types.StringValue(registry.AccountKey)
+	state.Enforce = types.BoolValue(registry.Enforce)
+	state.EventTypes = make([]eventTypeModel, 0, len(registry.EventTypes))
+	for _, eventType := range registry.EventTypes {
+		state.EventTypes = append(state.EventTypes, eventTypeModel{
+			Name:        types.StringValue(eventType.Name),
+			Description: optionalString(eventType.Description),
+		})
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+}
+
+func (r *eventTypeRegistryResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan eventTypeRegistryModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	r.apply(ctx, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *eventTypeRegistryResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state eventTypeRegistryModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	err := r.client.DeleteEventTypeRegistry(ctx, state.AccountKey.ValueString())
+	if err != nil && !orbitmemory.IsNotFound(err) {
+		apiError(&resp.Diagnostics, "delete event type registry", err)
+	}
+}
+
+func (r *eventTypeRegistryResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("account_key"), req, resp)
+}
+
+func (r *eventTypeRegistryResource) apply(ctx context.Context, plan *eventTypeRegistryModel, diags *diag.Diagnostics) {
+	params := orbitmemory.EventTypeRegistryParams{
+		EventTypes: make([]orbitmemory.EventTypeDefinition, 0, len(plan.EventTypes)),
+		Enforce:    plan.Enforce.ValueBool(),
+	}
+	for _, eventType := range plan.EventTypes {
+		params.EventTypes = append(params.EventTypes, orbitmemory.EventTypeDefinition{
+			Name:        eventType.Name.ValueString(),
+			Description: eventType.Description.ValueString(),
+		})
+	}
+	registry, err := r.client.SetEventTypeRegistry(ctx, plan.AccountKey.ValueString(), params)
+	if err != nil {
+		apiError(diags, "save event type registry", err)
+		return
+	}
+	plan.ID = types.StringValue(registry.AccountKey)
+}
@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	orbitmemory "github.com/Intina47/orbit/integrations/orbit-go"
+)
+
+var (
+	_ resource.ResourceWithConfigure   = (*namespaceResource)(nil)
+	_ resource.ResourceWithImportState = (*namespaceResource)(nil)
+)
+
+// NewNamespaceResource returns the orbit_namespace resource.
+func NewNamespaceResource() resource.Resource {
+	return &namespaceResource{}
+}
+
+type namespaceResource struct {
+	client *orbitmemory.Client
+}
+
+type namespaceModel struct {
+	ID          types.String `tfsdk:"id"`
+	AccountKey  types.String `tfsdk:"account_key"`
+	DisplayName types.String `tfsdk:"display_name"`
+	Description types.String `tfsdk:"description"`
+	Pipeline    types.List   `tfsdk:"pipeline"`
+}
+
+func (r *namespaceResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_namespace"
+}
+
+func (r *namespaceResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Settings for one tenant (account key). Deleting it keeps the tenant's memories and keys.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"account_key": schema.StringAttribute{
+				Required:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"display_name": schema.StringAttribute{Optional: true},
+			"description":  schema.StringAttribute{Optional: true},
+			"pipeline": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Ingest stage order, e.g. [\"pii\", \"extraction\", \"embedding\", \"indexing\"]. Unset uses the server default.",
+			},
+		},
+	}
+}
+
+func (r *namespaceResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.client = configureClient(req, resp)
+}
+
+func (r *namespaceResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan namespaceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	r.apply(ctx, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *namespaceResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state namespaceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	namespace, err := r.client.Namespace(ctx, state.AccountKey.ValueString())
+	if orbitmemory.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		apiError(&resp.Diagnostics, "read namespace", err)
+		return
+	}
+	state.ID = types.StringValue(namespace.AccountKey)
+	state.DisplayName = optionalString(namespace.DisplayName)
+	state.Description = optionalString(namespace.Description)
+	state.Pipeline = stringList(ctx, namespace.Pipeline, state.Pipeline, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+}
+
+func (r *namespaceResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan namespaceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	r.apply(ctx, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *namespaceResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state namespaceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	err := r.client.DeleteNamespace(ctx, state.AccountKey.ValueString())
+	if err != nil && !orbitmemory.IsNotFound(err) {
+		apiError(&resp.Diagnostics, "delete namespace", err)
+	}
+}
+
+func (r *namespaceResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("account_key"), req, resp)
+}
+
+func (r *namespaceResource) apply(ctx context.Context, plan *namespaceModel, diags *diag.Diagnostics) {
+	params := orbitmemory.NamespaceParams{
+		DisplayName: plan.DisplayName.ValueString(),
+		Description: plan.Description.ValueString(),
+		Pipeline:    listStrings(ctx, plan.Pipeline, diags),
+	}
+	if diags.HasError() {
+		return
+	}
+	namespace, err := r.client.SetNamespace(ctx, plan.AccountKey.ValueString(), params)
+	if err != nil {
+		apiError(diags, "save namespace", err)
+		return
+	}
+	plan.ID = types.StringValue(namespace.AccountKey)
+}
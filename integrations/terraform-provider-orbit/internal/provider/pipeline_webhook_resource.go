@@ -0,0 +1,162 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	orbitmemory "github.com/Intina47/orbit/integrations/orbit-go"
+)
+
+var (
+	_ resource.ResourceWithConfigure   = (*pipelineWebhookResource)(nil)
+	_ resource.ResourceWithImportState = (*pipelineWebhookResource)(nil)
+)
+
+// NewPipelineWebhookResource returns the orbit_pipeline_webhook resource.
+func NewPipelineWebhookResource() resource.Resource {
+	return &pipelineWebhookResource{}
+}
+
+type pipelineWebhookResource struct {
+	client *orbitmemory.Client
+}
+
+type pipelineWebhookModel struct {
+	ID            types.String `tfsdk:"id"`
+	AccountKey    types.String `tfsdk:"account_key"`
+	URL           types.String `tfsdk:"url"`
+	Secret        types.String `tfsdk:"secret"`
+	TimeoutMs     types.Int64  `tfsdk:"timeout_ms"`
+	FailurePolicy types.String `tfsdk:"failure_policy"`
+}
+
+func (r *pipelineWebhookResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_pipeline_webhook"
+}
+
+func (r *pipelineWebhookResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "The tenant's ingest transformation webhook.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"account_key": schema.StringAttribute{
+				Required:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"url": schema.StringAttribute{Required: true},
+			"secret": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "HMAC signing secret. The API never returns it, so drift is not detected.",
+			},
+			"timeout_ms": schema.Int64Attribute{
+				Optional: true,
+				Computed: true,
+				Default:  int64default.StaticInt64(2000),
+			},
+			"failure_policy": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("continue"),
+				Description: "What ingest does when the webhook fails: continue, drop, or reject.",
+			},
+		},
+	}
+}
+
+func (r *pipelineWebhookResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.client = configureClient(req, resp)
+}
+
+func (r *pipelineWebhookResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan pipelineWebhookModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	r.apply(ctx, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *pipelineWebhookResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state pipelineWebhookModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	webhook, err := r.client.PipelineWebhook(ctx, state.AccountKey.ValueString())
+	if orbitmemory.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		apiError(&resp.Diagnostics, "read pipeline webhook", err)
+		return
+	}
+	state.ID = state.AccountKey
+	state.URL = types.StringValue(webhook.URL)
+	state.TimeoutMs = types.Int64Value(int64(webhook.TimeoutMs))
+	state.FailurePolicy = types.StringValue(webhook.FailurePolicy)
+	if !webhook.HasSecret {
+		state.Secret = types.StringNull()
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+}
+
+func (r *pipelineWebhookResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan pipelineWebhookModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	r.apply(ctx, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *pipelineWebhookResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state pipelineWebhookModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	err := r.client.DeletePipelineWebhook(ctx, state.AccountKey.ValueString())
+	if err != nil && !orbitmemory.IsNotFound(err) {
+		apiError(&resp.Diagnostics, "delete pipeline webhook", err)
+	}
+}
+
+func (r *pipelineWebhookResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("account_key"), req, resp)
+}
+
+func (r *pipelineWebhookResource) apply(ctx context.Context, plan *pipelineWebhookModel, diags *diag.Diagnostics) {
+	params := orbitmemory.PipelineWebhookParams{
+		URL:           plan.URL.ValueString(),
+		Secret:        plan.Secret.ValueString(),
+		TimeoutMs:     int(plan.TimeoutMs.ValueInt64()),
+		FailurePolicy: plan.FailurePolicy.ValueString(),
+	}
+	if _, err := r.client.SetPipelineWebhook(ctx, plan.AccountKey.ValueString(), params); err != nil {
+		apiError(diags, "save pipeline webhook", err)
+		return
+	}
+	plan.ID = plan.AccountKey
+}
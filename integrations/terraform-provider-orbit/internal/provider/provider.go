@@ -0,0 +1,150 @@
+// Package provider implements the Orbit Terraform provider on top of the admin API.
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	orbitmemory "github.com/Intina47/orbit/integrations/orbit-go"
+)
+
+var _ provider.Provider = (*orbitProvider)(nil)
+
+type orbitProvider struct {
+	version string
+}
+
+type providerModel struct {
+	Endpoint types.String `tfsdk:"endpoint"`
+	Token    types.String `tfsdk:"token"`
+}
+
+// New returns a provider factory for providerserver.Serve.
+func New(version string) func() provider.Provider {
+	return func() provider.Provider {
+		return &orbitProvider{version: version}
+	}
+}
+
+func (p *orbitProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "orbit"
+	resp.Version = p.version
+}
+
+func (p *orbitProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages Orbit namespaces, API keys, event type registries, retention policies, and pipeline webhooks.",
+		Attributes: map[string]schema.Attribute{
+			"endpoint": schema.StringAttribute{
+				Optional:    true,
+				Description: "Orbit API base URL. Defaults to ORBIT_API_URL, then http://127.0.0.1:8000.",
+			},
+			"token": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "An operator token from the server's ORBIT_OPERATOR_TOKENS. Defaults to ORBIT_ADMIN_TOKEN.",
+			},
+		},
+	}
+}
+
+func (p *orbitProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var config providerModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	token := stringOr(config.Token, os.Getenv("ORBIT_ADMIN_TOKEN"))
+	if token == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("token"),
+			"Missing Orbit admin token",
+			"Set token or ORBIT_ADMIN_TOKEN to an operator token from the server's ORBIT_OPERATOR_TOKENS.",
+		)
+		return
+	}
+	client := orbitmemory.NewClient(orbitmemory.Config{
+		BaseURL: stringOr(config.Endpoint, os.Getenv("ORBIT_API_URL")),
+		Token:   token,
+	})
+	resp.ResourceData = client
+	resp.DataSourceData = client
+}
+
+func (p *orbitProvider) Resources(context.Context) []func() resource.Resource {
+	return []func() resource.Resource{
+		NewNamespaceResource,
+		NewAPIKeyResource,
+		NewEventTypeRegistryResource,
+		NewRetentionPolicyResource,
+		NewPipelineWebhookResource,
+	}
+}
+
+func (p *orbitProvider) DataSources(context.Context) []func() datasource.DataSource {
+	return nil
+}
+
+// configureClient pulls the provider's client out of a resource's ConfigureRequest. It
+// returns nil before the provider is configured, e.g. during validation.
+func configureClient(req resource.ConfigureRequest, resp *resource.ConfigureResponse) *orbitmemory.Client {
+	if req.ProviderData == nil {
+		return nil
+	}
+	client, ok := req.ProviderData.(*orbitmemory.Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected provider data",
+			fmt.Sprintf("Expected *orbitmemory.Client, got %T.", req.ProviderData),
+		)
+		return nil
+	}
+	return client
+}
+
+func stringOr(value types.String, fallback string) string {
+	if value.IsNull() || value.IsUnknown() || value.ValueString() == "" {
+		return fallback
+	}
+	return value.ValueString()
+}
+
+// optionalString maps the API's empty string to null so unset attributes show no diff.
+func optionalString(value string) types.String {
+	if value == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(value)
+}
+
+func listStrings(ctx context.Context, value types.List, diags *diag.Diagnostics) []string {
+	if value.IsNull() || value.IsUnknown() {
+		return nil
+	}
+	var out []string
+	diags.Append(value.ElementsAs(ctx, &out, false)...)
+	return out
+}
+
+// stringList keeps a null list null when the API returns an empty one.
+func stringList(ctx context.Context, values []string, current types.List, diags *diag.Diagnostics) types.List {
+	if len(values) == 0 && current.IsNull() {
+		return current
+	}
+	list, listDiags := types.ListValueFrom(ctx, types.StringType, values)
+	diags.Append(listDiags...)
+	return list
+}
+
+func apiError(diags *diag.Diagnostics, action string, err error) {
+	diags.AddError(fmt.Sprintf("Unable to %s", action), err.Error())
+}
@@ -0,0 +1,270 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	fwprovider "github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	orbitmemory "github.com/Intina47/orbit/integrations/orbit-go"
+)
+
+func requireNoDiags(t *testing.T, diags diag.Diagnostics) {
+	t.Helper()
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+}
+
+func resourceSchema(t *testing.T, r resource.Resource) schema.Schema {
+	t.Helper()
+	var resp resource.SchemaResponse
+	r.Schema(context.Background(), resource.SchemaRequest{}, &resp)
+	requireNoDiags(t, resp.Diagnostics)
+	return resp.Schema
+}
+
+func configured(t *testing.T, r resource.Resource, client *orbitmemory.Client) {
+	t.Helper()
+	var resp resource.ConfigureResponse
+	r.(resource.ResourceWithConfigure).Configure(context.Background(), resource.ConfigureRequest{ProviderData: client}, &resp)
+	requireNoDiags(t, resp.Diagnostics)
+}
+
+func emptyState(s schema.Schema) tfsdk.State {
+	return tfsdk.State{Schema: s, Raw: tftypes.NewValue(s.Type().TerraformType(context.Background()), nil)}
+}
+
+func planFor(t *testing.T, s schema.Schema, model any) tfsdk.Plan {
+	t.Helper()
+	plan := tfsdk.Plan{Schema: s, Raw: tftypes.NewValue(s.Type().TerraformType(context.Background()), nil)}
+	requireNoDiags(t, plan.Set(context.Background(), model))
+	return plan
+}
+
+func TestProviderAndResourceSchemasAreValid(t *testing.T) {
+	ctx := context.Background()
+	p := New("test")()
+	var providerSchema fwprovider.SchemaResponse
+	p.Schema(ctx, fwprovider.SchemaRequest{}, &providerSchema)
+	requireNoDiags(t, providerSchema.Diagnostics)
+	if !providerSchema.Schema.Attributes["token"].IsSensitive() {
+		t.Fatal("provider token is not sensitive")
+	}
+
+	seen := map[string]bool{}
+	for _, factory := range p.Resources(ctx) {
+		r := factory()
+		var meta resource.MetadataResponse
+		r.Metadata(ctx, resource.MetadataRequest{ProviderTypeName: "orbit"}, &meta)
+		if !strings.HasPrefix(meta.TypeName, "orbit_") || seen[meta.TypeName] {
+			t.Fatalf("bad or duplicate resource type name %q", meta.TypeName)
+		}
+		seen[meta.TypeName] = true
+		s := resourceSchema(t, r)
+		requireNoDiags(t, s.ValidateImplementation(ctx))
+		if _, ok := s.Attributes["id"]; !ok {
+			t.Fatalf("%s has no id attribute", meta.TypeName)
+		}
+	}
+	if !seen["orbit_namespace"] || !seen["orbit_api_key"] {
+		t.Fatalf("resources = %v", seen)
+	}
+	key := resourceSchema(t, NewAPIKeyResource()).Attributes["key"]
+	if !key.IsSensitive() || !key.IsComputed() {
+		t.Fatal("orbit_api_key.key must be computed and sensitive")
+	}
+}
+
+// fakeAdminAPI keeps namespaces and keys in memory behind the admin routes the provider uses.
+type fakeAdminAPI struct {
+	mu         sync.Mutex
+	namespaces map[string]orbitmemory.Namespace
+	keys       map[string]orbitmemory.APIKey
+}
+
+func newFakeAdminAPI(t *testing.T) (*fakeAdminAPI, *orbitmemory.Client) {
+	t.Helper()
+	api := &fakeAdminAPI{namespaces: map[string]orbitmemory.Namespace{}, keys: map[string]orbitmemory.APIKey{}}
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	return api, orbitmemory.NewClient(orbitmemory.Config{BaseURL: server.URL, Token: "operator"})
+}
+
+func (f *fakeAdminAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	reply := func(v any) { _ = json.NewEncoder(w).Encode(v) }
+	if name, ok := strings.CutPrefix(r.URL.Path, "/v1/admin/namespaces/"); ok {
+		switch r.Method {
+		case http.MethodPut:
+			var params orbitmemory.NamespaceParams
+			_ = json.NewDecoder(r.Body).Decode(&params)
+			ns := orbitmemory.Namespace{
+				AccountKey: name, DisplayName: params.DisplayName,
+				Description: params.Description, Pipeline: params.Pipeline,
+			}
+			f.namespaces[name] = ns
+			reply(ns)
+			return
+		case http.MethodGet:
+			if ns, found := f.namespaces[name]; found {
+				reply(ns)
+				return
+			}
+		case http.MethodDelete:
+			if _, found := f.namespaces[name]; found {
+				delete(f.namespaces, name)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		http.Error(w, `{"detail":"not found"}`, http.StatusNotFound)
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/admin/tenants/"), "/")
+	switch {
+	case len(parts) == 2 && parts[1] == "keys" && r.Method == http.MethodPost:
+		var params orbitmemory.APIKeyParams
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		key := orbitmemory.APIKey{
+			KeyID: "key_1", Name: params.Name, KeyPrefix: "orbit_pk_1",
+			Scopes: params.Scopes, Status: "active",
+		}
+		f.keys[key.KeyID] = key
+		reply(orbitmemory.IssuedAPIKey{APIKey: key, Key: "orbit_pk_1_secret"})
+	case len(parts) == 3 && r.Method == http.MethodGet:
+		if key, found := f.keys[parts[2]]; found {
+			reply(key)
+			return
+		}
+		http.Error(w, `{"detail":"not found"}`, http.StatusNotFound)
+	case len(parts) == 4 && parts[3] == "revoke" && r.Method == http.MethodPost:
+		key := f.keys[parts[2]]
+		key.Status = "revoked"
+		f.keys[parts[2]] = key
+		reply(key)
+	default:
+		http.Error(w, `{"detail":"not found"}`, http.StatusNotFound)
+	}
+}
+
+func TestNamespaceResourceCRUD(t *testing.T) {
+	ctx := context.Background()
+	api, client := newFakeAdminAPI(t)
+	r := NewNamespaceResource()
+	configured(t, r, client)
+	s := resourceSchema(t, r)
+
+	pipeline, diags := types.ListValueFrom(ctx, types.StringType, []string{"pii", "embedding"})
+	requireNoDiags(t, diags)
+	model := namespaceModel{
+		ID:          types.StringUnknown(),
+		AccountKey:  types.StringValue("acme"),
+		DisplayName: types.StringValue("Acme"),
+		Description: types.StringNull(),
+		Pipeline:    pipeline,
+	}
+	created := resource.CreateResponse{State: emptyState(s)}
+	r.Create(ctx, resource.CreateRequest{Plan: planFor(t, s, model)}, &created)
+	requireNoDiags(t, created.Diagnostics)
+	var state namespaceModel
+	requireNoDiags(t, created.State.Get(ctx, &state))
+	if state.ID.ValueString() != "acme" || api.namespaces["acme"].DisplayName != "Acme" {
+		t.Fatalf("after create: state = %+v, server = %+v", state, api.namespaces)
+	}
+
+	model.ID = state.ID
+	model.DisplayName = types.StringValue("Acme Corp")
+	updated := resource.UpdateResponse{State: created.State}
+	r.Update(ctx, resource.UpdateRequest{Plan: planFor(t, s, model), State: created.State}, &updated)
+	requireNoDiags(t, updated.Diagnostics)
+	if got := api.namespaces["acme"].DisplayName; got != "Acme Corp" {
+		t.Fatalf("update sent display_name %q", got)
+	}
+
+	read := resource.ReadResponse{State: updated.State}
+	r.Read(ctx, resource.ReadRequest{State: updated.State}, &read)
+	requireNoDiags(t, read.Diagnostics)
+	requireNoDiags(t, read.State.Get(ctx, &state))
+	var stages []string
+	requireNoDiags(t, state.Pipeline.ElementsAs(ctx, &stages, false))
+	if state.DisplayName.ValueString() != "Acme Corp" || !state.Description.IsNull() || len(stages) != 2 {
+		t.Fatalf("after read: %+v", state)
+	}
+
+	deleted := resource.DeleteResponse{State: read.State}
+	r.Delete(ctx, resource.DeleteRequest{State: read.State}, &deleted)
+	requireNoDiags(t, deleted.Diagnostics)
+	if _, found := api.namespaces["acme"]; found {
+		t.Fatal("namespace was not deleted")
+	}
+
+	gone := resource.ReadResponse{State: read.State}
+	r.Read(ctx, resource.ReadRequest{State: read.State}, &gone)
+	requireNoDiags(t, gone.Diagnostics)
+	if !gone.State.Raw.IsNull() {
+		t.Fatal("a deleted namespace was kept in state")
+	}
+}
+
+func TestAPIKeyResourceCreateReadRevoke(t *testing.T) {
+	ctx := context.Background()
+	api, client := newFakeAdminAPI(t)
+	r := NewAPIKeyResource()
+	configured(t, r, client)
+	s := resourceSchema(t, r)
+
+	scopes, diags := types.ListValueFrom(ctx, types.StringType, []string{"read"})
+	requireNoDiags(t, diags)
+	model := apiKeyModel{
+		ID:             types.StringUnknown(),
+		AccountKey:     types.StringValue("acme"),
+		Name:           types.StringValue("ci"),
+		Scopes:         scopes,
+		AllowedOrigins: types.ListNull(types.StringType),
+		KeyPrefix:      types.StringUnknown(),
+		Key:            types.StringUnknown(),
+	}
+	created := resource.CreateResponse{State: emptyState(s)}
+	r.Create(ctx, resource.CreateRequest{Plan: planFor(t, s, model)}, &created)
+	requireNoDiags(t, created.Diagnostics)
+	var state apiKeyModel
+	requireNoDiags(t, created.State.Get(ctx, &state))
+	if state.ID.ValueString() != "key_1" || state.Key.ValueString() != "orbit_pk_1_secret" {
+		t.Fatalf("after create: %+v", state)
+	}
+
+	read := resource.ReadResponse{State: created.State}
+	r.Read(ctx, resource.ReadRequest{State: created.State}, &read)
+	requireNoDiags(t, read.Diagnostics)
+	requireNoDiags(t, read.State.Get(ctx, &state))
+	if state.Key.ValueString() != "orbit_pk_1_secret" || state.KeyPrefix.ValueString() != "orbit_pk_1" {
+		t.Fatalf("read lost the secret or prefix: %+v", state)
+	}
+
+	deleted := resource.DeleteResponse{State: read.State}
+	r.Delete(ctx, resource.DeleteRequest{State: read.State}, &deleted)
+	requireNoDiags(t, deleted.Diagnostics)
+	if api.keys["key_1"].Status != "revoked" {
+		t.Fatal("key was not revoked")
+	}
+
+	gone := resource.ReadResponse{State: read.State}
+	r.Read(ctx, resource.ReadRequest{State: read.State}, &gone)
+	requireNoDiags(t, gone.Diagnostics)
+	if !gone.State.Raw.IsNull() {
+		t.Fatal("a revoked key was kept in state")
+	}
+}
@@ -0,0 +1,161 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	orbitmemory "github.com/Intina47/orbit/integrations/orbit-go"
+)
+
+var (
+	_ resource.ResourceWithConfigure   = (*retentionPolicyResource)(nil)
+	_ resource.ResourceWithImportState = (*retentionPolicyResource)(nil)
+)
+
+// NewRetentionPolicyResource returns the orbit_retention_policy resource.
+func NewRetentionPolicyResource() resource.Resource {
+	return &retentionPolicyResource{}
+}
+
+type retentionPolicyResource struct {
+	client *orbitmemory.Client
+}
+
+type retentionPolicyModel struct {
+	ID         types.String `tfsdk:"id"`
+	AccountKey types.String `tfsdk:"account_key"`
+	Days       types.Int64  `tfsdk:"days"`
+	EventTypes types.Map    `tfsdk:"event_types"`
+}
+
+func (r *retentionPolicyResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_retention_policy"
+}
+
+func (r *retentionPolicyResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "How long a tenant's memories are kept. `orbit retention` deletes memories past their limit.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"account_key": schema.StringAttribute{
+				Required:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"days": schema.Int64Attribute{
+				Required:    true,
+				Description: "Days to keep memories of any event type not listed in event_types.",
+			},
+			"event_types": schema.MapAttribute{
+				Optional:    true,
+				ElementType: types.Int64Type,
+				Description: "Per event type overrides of days.",
+			},
+		},
+	}
+}
+
+func (r *retentionPolicyResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.client = configureClient(req, resp)
+}
+
+func (r *retentionPolicyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan retentionPolicyModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	r.apply(ctx, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *retentionPolicyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state retentionPolicyModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	policy, err := r.client.RetentionPolicy(ctx, state.AccountKey.ValueString())
+	if orbitmemory.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		apiError(&resp.Diagnostics, "read retention policy", err)
+		return
+	}
+	state.ID = types.StringValue(policy.AccountKey)
+	state.Days = types.Int64Value(int64(policy.Days))
+	if len(policy.EventTypes) > 0 || !state.EventTypes.IsNull() {
+		overrides := make(map[string]int64, len(policy.EventTypes))
+		for name, days := range policy.EventTypes {
+			overrides[name] = int64(days)
+		}
+		eventTypes, diags := types.MapValueFrom(ctx, types.Int64Type, overrides)
+		resp.Diagnostics.Append(diags...)
+		state.EventTypes = eventTypes
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+}
+
+func (r *retentionPolicyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan retentionPolicyModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	r.apply(ctx, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *retentionPolicyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state retentionPolicyModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	err := r.client.DeleteRetentionPolicy(ctx, state.AccountKey.ValueString())
+	if err != nil && !orbitmemory.IsNotFound(err) {
+		apiError(&resp.Diagnostics, "delete retention policy", err)
+	}
+}
+
+func (r *retentionPolicyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("account_key"), req, resp)
+}
+
+func (r *retentionPolicyResource) apply(ctx context.Context, plan *retentionPolicyModel, diags *diag.Diagnostics) {
+	params := orbitmemory.RetentionPolicyParams{Days: int(plan.Days.ValueInt64())}
+	if !plan.EventTypes.IsNull() && !plan.EventTypes.IsUnknown() {
+		var overrides map[string]int64
+		diags.Append(plan.EventTypes.ElementsAs(ctx, &overrides, false)...)
+		if diags.HasError() {
+			return
+		}
+		params.EventTypes = make(map[string]int, len(overrides))
+		for name, days := range overrides {
+			params.EventTypes[name] = int(days)
+		}
+	}
+	policy, err := r.client.SetRetentionPolicy(ctx, plan.AccountKey.ValueString(), params)
+	if err != nil {
+		apiError(diags, "save retention policy", err)
+		return
+	}
+	plan.ID = types.StringValue(policy.AccountKey)
+}
@@ -0,0 +1,29 @@
+// Command terraform-provider-orbit manages Orbit tenant configuration from Terraform.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+
+	"github.com/Intina47/orbit/integrations/terraform-provider-orbit/internal/provider"
+)
+
+// version is set by release builds with -ldflags "-X main.version=...".
+var version = "dev"
+
+func main() {
+	var debug bool
+	flag.BoolVar(&debug, "debug", false, "Run with support for debuggers such as delve.")
+	flag.Parse()
+
+	err := providerserver.Serve(context.Background(), provider.New(version), providerserver.ServeOpts{
+		Address: "registry.terraform.io/intina47/orbit",
+		Debug:   debug,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
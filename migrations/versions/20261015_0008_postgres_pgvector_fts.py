@@ -0,0 +1,71 @@
+"""add pgvector, jsonb metadata and full-text search columns on postgres
+
+Revision ID: 20261015_0008
+Revises: 20260225_0007
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import os
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0008"
+down_revision = "20260225_0007"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    if bind.dialect.name != "postgresql":
+        return
+    inspector = sa.inspect(bind)
+    if "memories" not in set(inspector.get_table_names()):
+        return
+
+    embedding_dim = int(os.getenv("MDE_EMBEDDING_DIM", "384"))
+    text_search_config = (
+        os.getenv("MDE_POSTGRES_TEXT_SEARCH_CONFIG", "english").strip().lower()
+    )
+    if not text_search_config.replace("_", "").isalpha():
+        msg = "MDE_POSTGRES_TEXT_SEARCH_CONFIG must be a text search configuration name"
+        raise ValueError(msg)
+
+    op.execute("CREATE EXTENSION IF NOT EXISTS vector")
+    op.execute(
+        "ALTER TABLE memories ADD COLUMN IF NOT EXISTS "
+        f"embedding_vector vector({embedding_dim})"
+    )
+    op.execute("ALTER TABLE memories ADD COLUMN IF NOT EXISTS metadata_jsonb JSONB")
+    op.execute(
+        "ALTER TABLE memories ADD COLUMN IF NOT EXISTS content_tsv tsvector "
+        f"GENERATED ALWAYS AS (to_tsvector('{text_search_config}', "
+        "coalesce(summary, '') || ' ' || coalesce(content, ''))) STORED"
+    )
+    op.execute(
+        "CREATE INDEX IF NOT EXISTS ix_memories_embedding_vector_hnsw "
+        "ON memories USING hnsw (embedding_vector vector_cosine_ops)"
+    )
+    op.execute(
+        "CREATE INDEX IF NOT EXISTS ix_memories_content_tsv "
+        "ON memories USING gin (content_tsv)"
+    )
+    op.execute(
+        "CREATE INDEX IF NOT EXISTS ix_memories_metadata_jsonb "
+        "ON memories USING gin (metadata_jsonb)"
+    )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    if bind.dialect.name != "postgresql":
+        return
+    op.execute("DROP INDEX IF EXISTS ix_memories_metadata_jsonb")
+    op.execute("DROP INDEX IF EXISTS ix_memories_content_tsv")
+    op.execute("DROP INDEX IF EXISTS ix_memories_embedding_vector_hnsw")
+    op.execute("ALTER TABLE memories DROP COLUMN IF EXISTS content_tsv")
+    op.execute("ALTER TABLE memories DROP COLUMN IF EXISTS metadata_jsonb")
+    op.execute("ALTER TABLE memories DROP COLUMN IF EXISTS embedding_vector")
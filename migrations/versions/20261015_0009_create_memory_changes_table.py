@@ -0,0 +1,55 @@
+"""create memory change feed table
+
+Revision ID: 20261015_0009
+Revises: 20261015_0008
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0009"
+down_revision = "20261015_0008"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    existing_tables = set(inspector.get_table_names())
+    if "api_memory_changes" in existing_tables:
+        return
+
+    op.create_table(
+        "api_memory_changes",
+        sa.Column("id", sa.Integer(), autoincrement=True, nullable=False),
+        sa.Column("account_key", sa.String(length=128), nullable=False),
+        sa.Column("memory_id", sa.String(length=64), nullable=False),
+        sa.Column("operation", sa.String(length=16), nullable=False),
+        sa.Column("payload_json", sa.Text(), nullable=False),
+        sa.Column("created_at", sa.DateTime(timezone=True), nullable=False),
+        sa.PrimaryKeyConstraint("id"),
+    )
+    op.create_index(
+        "ix_api_memory_changes_account_id",
+        "api_memory_changes",
+        ["account_key", "id"],
+    )
+    op.create_index(
+        "ix_api_memory_changes_memory_id",
+        "api_memory_changes",
+        ["memory_id"],
+    )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    existing_tables = set(inspector.get_table_names())
+    if "api_memory_changes" in existing_tables:
+        op.drop_index("ix_api_memory_changes_memory_id", table_name="api_memory_changes")
+        op.drop_index("ix_api_memory_changes_account_id", table_name="api_memory_changes")
+        op.drop_table("api_memory_changes")
@@ -0,0 +1,43 @@
+"""add per-origin restrictions to api keys
+
+Revision ID: 20261015_0010
+Revises: 20261015_0009
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0010"
+down_revision = "20261015_0009"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    columns = {column["name"] for column in inspector.get_columns("api_keys")}
+
+    if "allowed_origins_json" not in columns:
+        op.add_column(
+            "api_keys",
+            sa.Column(
+                "allowed_origins_json",
+                sa.Text(),
+                nullable=False,
+                server_default="[]",
+            ),
+        )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    columns = {column["name"] for column in inspector.get_columns("api_keys")}
+
+    with op.batch_alter_table("api_keys") as batch_op:
+        if "allowed_origins_json" in columns:
+            batch_op.drop_column("allowed_origins_json")
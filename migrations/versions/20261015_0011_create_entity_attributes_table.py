@@ -0,0 +1,48 @@
+"""create structured entity attribute table
+
+Revision ID: 20261015_0011
+Revises: 20261015_0010
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0011"
+down_revision = "20261015_0010"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    existing_tables = set(inspector.get_table_names())
+    if "api_entity_attributes" in existing_tables:
+        return
+
+    op.create_table(
+        "api_entity_attributes",
+        sa.Column("id", sa.Integer(), autoincrement=True, nullable=False),
+        sa.Column("account_key", sa.String(length=128), nullable=False),
+        sa.Column("entity_id", sa.String(length=255), nullable=False),
+        sa.Column("attributes_json", sa.Text(), nullable=False),
+        sa.Column("sources_json", sa.Text(), nullable=False),
+        sa.Column("updated_at", sa.DateTime(timezone=True), nullable=False),
+        sa.PrimaryKeyConstraint("id"),
+        sa.UniqueConstraint(
+            "account_key",
+            "entity_id",
+            name="uq_api_entity_attributes_account_entity",
+        ),
+    )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    existing_tables = set(inspector.get_table_names())
+    if "api_entity_attributes" in existing_tables:
+        op.drop_table("api_entity_attributes")
@@ -0,0 +1,53 @@
+"""create ingestion anomaly alert table
+
+Revision ID: 20261015_0012
+Revises: 20261015_0011
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0012"
+down_revision = "20261015_0011"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    existing_tables = set(inspector.get_table_names())
+    if "api_ingestion_anomalies" in existing_tables:
+        return
+
+    op.create_table(
+        "api_ingestion_anomalies",
+        sa.Column("id", sa.Integer(), autoincrement=True, nullable=False),
+        sa.Column("account_key", sa.String(length=128), nullable=False),
+        sa.Column("key_id", sa.String(length=64), nullable=False),
+        sa.Column("kind", sa.String(length=32), nullable=False),
+        sa.Column("detail_json", sa.Text(), nullable=False),
+        sa.Column("webhook_status", sa.String(length=16), nullable=False),
+        sa.Column("detected_at", sa.DateTime(timezone=True), nullable=False),
+        sa.PrimaryKeyConstraint("id"),
+    )
+    op.create_index(
+        "ix_api_ingestion_anomalies_account_id",
+        "api_ingestion_anomalies",
+        ["account_key", "id"],
+    )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    existing_tables = set(inspector.get_table_names())
+    if "api_ingestion_anomalies" in existing_tables:
+        op.drop_index(
+            "ix_api_ingestion_anomalies_account_id",
+            table_name="api_ingestion_anomalies",
+        )
+        op.drop_table("api_ingestion_anomalies")
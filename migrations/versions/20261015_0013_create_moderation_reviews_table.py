@@ -0,0 +1,61 @@
+"""create moderation review queue table
+
+Revision ID: 20261015_0013
+Revises: 20261015_0012
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0013"
+down_revision = "20261015_0012"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    existing_tables = set(inspector.get_table_names())
+    if "api_moderation_reviews" in existing_tables:
+        return
+
+    op.create_table(
+        "api_moderation_reviews",
+        sa.Column("id", sa.Integer(), autoincrement=True, nullable=False),
+        sa.Column("account_key", sa.String(length=128), nullable=False),
+        sa.Column("entity_id", sa.String(length=255), nullable=True),
+        sa.Column("action", sa.String(length=16), nullable=False),
+        sa.Column("categories_json", sa.Text(), nullable=False),
+        sa.Column("provider", sa.String(length=32), nullable=False),
+        sa.Column("status", sa.String(length=16), nullable=False),
+        sa.Column("content", sa.Text(), nullable=False),
+        sa.Column("request_json", sa.Text(), nullable=False),
+        sa.Column("memory_id", sa.String(length=64), nullable=True),
+        sa.Column("appeal_reason", sa.Text(), nullable=True),
+        sa.Column("resolution_note", sa.Text(), nullable=True),
+        sa.Column("resolved_by", sa.String(length=255), nullable=True),
+        sa.Column("created_at", sa.DateTime(timezone=True), nullable=False),
+        sa.Column("updated_at", sa.DateTime(timezone=True), nullable=False),
+        sa.PrimaryKeyConstraint("id"),
+    )
+    op.create_index(
+        "ix_api_moderation_reviews_account_status",
+        "api_moderation_reviews",
+        ["account_key", "status"],
+    )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    existing_tables = set(inspector.get_table_names())
+    if "api_moderation_reviews" in existing_tables:
+        op.drop_index(
+            "ix_api_moderation_reviews_account_status",
+            table_name="api_moderation_reviews",
+        )
+        op.drop_table("api_moderation_reviews")
@@ -0,0 +1,91 @@
+"""create memory share and entity group tables
+
+Revision ID: 20261015_0014
+Revises: 20261015_0013
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0014"
+down_revision = "20261015_0013"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    existing_tables = set(inspector.get_table_names())
+
+    if "api_memory_shares" not in existing_tables:
+        op.create_table(
+            "api_memory_shares",
+            sa.Column("id", sa.Integer(), autoincrement=True, nullable=False),
+            sa.Column("account_key", sa.String(length=128), nullable=False),
+            sa.Column("source_entity_id", sa.String(length=255), nullable=False),
+            sa.Column("target_type", sa.String(length=16), nullable=False),
+            sa.Column("target_id", sa.String(length=255), nullable=False),
+            sa.Column("memory_ids_json", sa.Text(), nullable=False),
+            sa.Column("tags_json", sa.Text(), nullable=False),
+            sa.Column("created_at", sa.DateTime(timezone=True), nullable=False),
+            sa.Column("revoked_at", sa.DateTime(timezone=True), nullable=True),
+            sa.PrimaryKeyConstraint("id"),
+        )
+        op.create_index(
+            "ix_api_memory_shares_account_target",
+            "api_memory_shares",
+            ["account_key", "target_id"],
+        )
+        op.create_index(
+            "ix_api_memory_shares_account_source",
+            "api_memory_shares",
+            ["account_key", "source_entity_id"],
+        )
+
+    if "api_entity_group_members" not in existing_tables:
+        op.create_table(
+            "api_entity_group_members",
+            sa.Column("id", sa.Integer(), autoincrement=True, nullable=False),
+            sa.Column("account_key", sa.String(length=128), nullable=False),
+            sa.Column("group_id", sa.String(length=255), nullable=False),
+            sa.Column("entity_id", sa.String(length=255), nullable=False),
+            sa.Column("created_at", sa.DateTime(timezone=True), nullable=False),
+            sa.PrimaryKeyConstraint("id"),
+            sa.UniqueConstraint(
+                "account_key",
+                "group_id",
+                "entity_id",
+                name="uq_api_entity_group_members_account_group_entity",
+            ),
+        )
+        op.create_index(
+            "ix_api_entity_group_members_account_entity",
+            "api_entity_group_members",
+            ["account_key", "entity_id"],
+        )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    existing_tables = set(inspector.get_table_names())
+    if "api_entity_group_members" in existing_tables:
+        op.drop_index(
+            "ix_api_entity_group_members_account_entity",
+            table_name="api_entity_group_members",
+        )
+        op.drop_table("api_entity_group_members")
+    if "api_memory_shares" in existing_tables:
+        op.drop_index(
+            "ix_api_memory_shares_account_source",
+            table_name="api_memory_shares",
+        )
+        op.drop_index(
+            "ix_api_memory_shares_account_target",
+            table_name="api_memory_shares",
+        )
+        op.drop_table("api_memory_shares")
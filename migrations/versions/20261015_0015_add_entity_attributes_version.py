@@ -0,0 +1,41 @@
+"""add version counter to entity attributes for optimistic concurrency
+
+Revision ID: 20261015_0015
+Revises: 20261015_0014
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0015"
+down_revision = "20261015_0014"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_entity_attributes" not in set(inspector.get_table_names()):
+        return
+    columns = {column["name"] for column in inspector.get_columns("api_entity_attributes")}
+    if "version" not in columns:
+        op.add_column(
+            "api_entity_attributes",
+            sa.Column("version", sa.Integer(), nullable=False, server_default="0"),
+        )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_entity_attributes" not in set(inspector.get_table_names()):
+        return
+    columns = {column["name"] for column in inspector.get_columns("api_entity_attributes")}
+    if "version" not in columns:
+        return
+    with op.batch_alter_table("api_entity_attributes") as batch_op:
+        batch_op.drop_column("version")
@@ -0,0 +1,48 @@
+"""create anonymized retrieval query log table
+
+Revision ID: 20261015_0016
+Revises: 20261015_0015
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0016"
+down_revision = "20261015_0015"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_query_log" in set(inspector.get_table_names()):
+        return
+    op.create_table(
+        "api_query_log",
+        sa.Column("id", sa.Integer(), autoincrement=True, nullable=False),
+        sa.Column("account_key", sa.String(length=128), nullable=False),
+        sa.Column("query_hash", sa.String(length=64), nullable=False),
+        sa.Column("query_text", sa.String(length=255), nullable=False),
+        sa.Column("result_count", sa.Integer(), nullable=False),
+        sa.Column("latency_ms", sa.Float(), nullable=False),
+        sa.Column("created_at", sa.DateTime(timezone=True), nullable=False),
+        sa.PrimaryKeyConstraint("id"),
+    )
+    op.create_index(
+        "ix_api_query_log_account_created",
+        "api_query_log",
+        ["account_key", "created_at"],
+    )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_query_log" not in set(inspector.get_table_names()):
+        return
+    op.drop_index("ix_api_query_log_account_created", table_name="api_query_log")
+    op.drop_table("api_query_log")
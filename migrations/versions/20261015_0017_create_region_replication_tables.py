@@ -0,0 +1,51 @@
+"""create tenant residency and replication cursor tables
+
+Revision ID: 20261015_0017
+Revises: 20261015_0016
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0017"
+down_revision = "20261015_0016"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    existing_tables = set(inspector.get_table_names())
+    if "api_tenant_residency" not in existing_tables:
+        op.create_table(
+            "api_tenant_residency",
+            sa.Column("account_key", sa.String(length=128), nullable=False),
+            sa.Column("home_region", sa.String(length=32), nullable=False),
+            sa.Column("replica_regions_json", sa.Text(), nullable=False),
+            sa.Column("pinned", sa.Boolean(), nullable=False),
+            sa.Column("updated_at", sa.DateTime(timezone=True), nullable=False),
+            sa.PrimaryKeyConstraint("account_key"),
+        )
+    if "api_replication_cursors" not in existing_tables:
+        op.create_table(
+            "api_replication_cursors",
+            sa.Column("account_key", sa.String(length=128), nullable=False),
+            sa.Column("source_region", sa.String(length=32), nullable=False),
+            sa.Column("cursor", sa.String(length=64), nullable=True),
+            sa.Column("synced_at", sa.DateTime(timezone=True), nullable=True),
+            sa.PrimaryKeyConstraint("account_key"),
+        )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    existing_tables = set(inspector.get_table_names())
+    if "api_replication_cursors" in existing_tables:
+        op.drop_table("api_replication_cursors")
+    if "api_tenant_residency" in existing_tables:
+        op.drop_table("api_tenant_residency")
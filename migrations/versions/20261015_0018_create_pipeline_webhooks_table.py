@@ -0,0 +1,40 @@
+"""create pipeline webhooks table
+
+Revision ID: 20261015_0018
+Revises: 20261015_0017
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0018"
+down_revision = "20261015_0017"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_pipeline_webhooks" in set(inspector.get_table_names()):
+        return
+    op.create_table(
+        "api_pipeline_webhooks",
+        sa.Column("account_key", sa.String(length=128), nullable=False),
+        sa.Column("url", sa.Text(), nullable=False),
+        sa.Column("secret", sa.String(length=256), nullable=True),
+        sa.Column("timeout_ms", sa.Integer(), nullable=False),
+        sa.Column("failure_policy", sa.String(length=16), nullable=False),
+        sa.Column("updated_at", sa.DateTime(timezone=True), nullable=False),
+        sa.PrimaryKeyConstraint("account_key"),
+    )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_pipeline_webhooks" in set(inspector.get_table_names()):
+        op.drop_table("api_pipeline_webhooks")
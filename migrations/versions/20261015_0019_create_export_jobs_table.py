@@ -0,0 +1,54 @@
+"""create export jobs table
+
+Revision ID: 20261015_0019
+Revises: 20261015_0018
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0019"
+down_revision = "20261015_0018"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_export_jobs" in set(inspector.get_table_names()):
+        return
+    op.create_table(
+        "api_export_jobs",
+        sa.Column("id", sa.String(length=64), nullable=False),
+        sa.Column("account_key", sa.String(length=128), nullable=False),
+        sa.Column("cron", sa.String(length=128), nullable=False),
+        sa.Column("destination", sa.Text(), nullable=False),
+        sa.Column("format", sa.String(length=16), nullable=False),
+        sa.Column("enabled", sa.Boolean(), nullable=False),
+        sa.Column("cursor", sa.Integer(), nullable=False),
+        sa.Column("next_run_at", sa.DateTime(timezone=True), nullable=True),
+        sa.Column("last_run_at", sa.DateTime(timezone=True), nullable=True),
+        sa.Column("last_status", sa.String(length=16), nullable=True),
+        sa.Column("last_error", sa.Text(), nullable=True),
+        sa.Column("last_object_key", sa.Text(), nullable=True),
+        sa.Column("rows_exported", sa.Integer(), nullable=False),
+        sa.Column("created_at", sa.DateTime(timezone=True), nullable=False),
+        sa.PrimaryKeyConstraint("id"),
+    )
+    op.create_index("ix_api_export_jobs_account_key", "api_export_jobs", ["account_key"])
+    op.create_index(
+        "ix_api_export_jobs_enabled_next_run",
+        "api_export_jobs",
+        ["enabled", "next_run_at"],
+    )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_export_jobs" in set(inspector.get_table_names()):
+        op.drop_table("api_export_jobs")
@@ -0,0 +1,60 @@
+"""create namespace, event type registry, and retention policy tables
+
+Revision ID: 20261015_0020
+Revises: 20261015_0019
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0020"
+down_revision = "20261015_0019"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    tables = set(inspector.get_table_names())
+    if "api_namespaces" not in tables:
+        op.create_table(
+            "api_namespaces",
+            sa.Column("account_key", sa.String(length=128), nullable=False),
+            sa.Column("display_name", sa.String(length=128), nullable=True),
+            sa.Column("description", sa.Text(), nullable=True),
+            sa.Column("pipeline_json", sa.Text(), nullable=True),
+            sa.Column("created_at", sa.DateTime(timezone=True), nullable=False),
+            sa.Column("updated_at", sa.DateTime(timezone=True), nullable=False),
+            sa.PrimaryKeyConstraint("account_key"),
+        )
+    if "api_event_type_registries" not in tables:
+        op.create_table(
+            "api_event_type_registries",
+            sa.Column("account_key", sa.String(length=128), nullable=False),
+            sa.Column("event_types_json", sa.Text(), nullable=False),
+            sa.Column("enforce", sa.Boolean(), nullable=False),
+            sa.Column("updated_at", sa.DateTime(timezone=True), nullable=False),
+            sa.PrimaryKeyConstraint("account_key"),
+        )
+    if "api_retention_policies" not in tables:
+        op.create_table(
+            "api_retention_policies",
+            sa.Column("account_key", sa.String(length=128), nullable=False),
+            sa.Column("days", sa.Integer(), nullable=False),
+            sa.Column("event_types_json", sa.Text(), nullable=False),
+            sa.Column("updated_at", sa.DateTime(timezone=True), nullable=False),
+            sa.PrimaryKeyConstraint("account_key"),
+        )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    tables = set(inspector.get_table_names())
+    for table in ("api_retention_policies", "api_event_type_registries", "api_namespaces"):
+        if table in tables:
+            op.drop_table(table)
@@ -0,0 +1,51 @@
+"""create index deployments table
+
+Revision ID: 20261015_0021
+Revises: 20261015_0020
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0021"
+down_revision = "20261015_0020"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_index_deployments" in set(inspector.get_table_names()):
+        return
+    op.create_table(
+        "api_index_deployments",
+        sa.Column("id", sa.String(length=64), nullable=False),
+        sa.Column("status", sa.String(length=16), nullable=False),
+        sa.Column("embedding_provider", sa.String(length=64), nullable=True),
+        sa.Column("chunk_chars", sa.Integer(), nullable=True),
+        sa.Column("quantization", sa.String(length=16), nullable=False),
+        sa.Column("mirror_percent", sa.Float(), nullable=False),
+        sa.Column("mirrored_queries", sa.Integer(), nullable=False),
+        sa.Column("overlap_sum", sa.Float(), nullable=False),
+        sa.Column("top_result_matches", sa.Integer(), nullable=False),
+        sa.Column("production_latency_ms_sum", sa.Float(), nullable=False),
+        sa.Column("candidate_latency_ms_sum", sa.Float(), nullable=False),
+        sa.Column("error", sa.Text(), nullable=True),
+        sa.Column("created_at", sa.DateTime(timezone=True), nullable=False),
+        sa.Column("ready_at", sa.DateTime(timezone=True), nullable=True),
+        sa.Column("promoted_at", sa.DateTime(timezone=True), nullable=True),
+        sa.Column("retired_at", sa.DateTime(timezone=True), nullable=True),
+        sa.PrimaryKeyConstraint("id"),
+    )
+    op.create_index("ix_api_index_deployments_status", "api_index_deployments", ["status"])
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_index_deployments" in set(inspector.get_table_names()):
+        op.drop_table("api_index_deployments")
@@ -0,0 +1,53 @@
+"""create memory links table
+
+Revision ID: 20261015_0022
+Revises: 20261015_0021
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0022"
+down_revision = "20261015_0021"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_memory_links" in set(inspector.get_table_names()):
+        return
+    op.create_table(
+        "api_memory_links",
+        sa.Column("id", sa.Integer(), autoincrement=True, nullable=False),
+        sa.Column("account_key", sa.String(length=128), nullable=False),
+        sa.Column("source_memory_id", sa.String(length=64), nullable=False),
+        sa.Column("target_memory_id", sa.String(length=64), nullable=False),
+        sa.Column("link_type", sa.String(length=32), nullable=False),
+        sa.Column("created_at", sa.DateTime(timezone=True), nullable=False),
+        sa.PrimaryKeyConstraint("id"),
+        sa.UniqueConstraint(
+            "account_key",
+            "source_memory_id",
+            "target_memory_id",
+            "link_type",
+            name="uq_api_memory_links_account_source_target_type",
+        ),
+    )
+    op.create_index(
+        "ix_api_memory_links_account_target",
+        "api_memory_links",
+        ["account_key", "target_memory_id"],
+    )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_memory_links" in set(inspector.get_table_names()):
+        op.drop_index("ix_api_memory_links_account_target", table_name="api_memory_links")
+        op.drop_table("api_memory_links")
@@ -0,0 +1,49 @@
+"""create chat turns table
+
+Revision ID: 20261015_0023
+Revises: 20261015_0022
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0023"
+down_revision = "20261015_0022"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_chat_turns" in set(inspector.get_table_names()):
+        return
+    op.create_table(
+        "api_chat_turns",
+        sa.Column("id", sa.Integer(), autoincrement=True, nullable=False),
+        sa.Column("account_key", sa.String(length=128), nullable=False),
+        sa.Column("session_id", sa.String(length=128), nullable=False),
+        sa.Column("entity_id", sa.String(length=255), nullable=False),
+        sa.Column("role", sa.String(length=16), nullable=False),
+        sa.Column("content", sa.Text(), nullable=False),
+        sa.Column("memory_id", sa.String(length=64), nullable=True),
+        sa.Column("retrieved_ids_json", sa.Text(), nullable=False),
+        sa.Column("created_at", sa.DateTime(timezone=True), nullable=False),
+        sa.PrimaryKeyConstraint("id"),
+    )
+    op.create_index(
+        "ix_api_chat_turns_account_session",
+        "api_chat_turns",
+        ["account_key", "session_id", "id"],
+    )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_chat_turns" in set(inspector.get_table_names()):
+        op.drop_index("ix_api_chat_turns_account_session", table_name="api_chat_turns")
+        op.drop_table("api_chat_turns")
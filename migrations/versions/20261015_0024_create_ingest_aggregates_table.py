@@ -0,0 +1,48 @@
+"""create ingest aggregates table
+
+Revision ID: 20261015_0024
+Revises: 20261015_0023
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0024"
+down_revision = "20261015_0023"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_ingest_aggregates" in set(inspector.get_table_names()):
+        return
+    op.create_table(
+        "api_ingest_aggregates",
+        sa.Column("id", sa.Integer(), autoincrement=True, nullable=False),
+        sa.Column("account_key", sa.String(length=128), nullable=False),
+        sa.Column("entity_id", sa.String(length=255), nullable=False),
+        sa.Column("event_type", sa.String(length=64), nullable=False),
+        sa.Column("event_count", sa.Integer(), nullable=False),
+        sa.Column("latest_content", sa.Text(), nullable=False),
+        sa.Column("window_start", sa.DateTime(timezone=True), nullable=False),
+        sa.Column("last_seen_at", sa.DateTime(timezone=True), nullable=False),
+        sa.PrimaryKeyConstraint("id"),
+        sa.UniqueConstraint(
+            "account_key",
+            "entity_id",
+            "event_type",
+            name="uq_api_ingest_aggregates_account_entity_type",
+        ),
+    )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_ingest_aggregates" in set(inspector.get_table_names()):
+        op.drop_table("api_ingest_aggregates")
@@ -0,0 +1,56 @@
+"""create memory counters table
+
+Revision ID: 20261015_0025
+Revises: 20261015_0024
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0025"
+down_revision = "20261015_0024"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_memory_counters" in set(inspector.get_table_names()):
+        return
+    op.create_table(
+        "api_memory_counters",
+        sa.Column("id", sa.Integer(), autoincrement=True, nullable=False),
+        sa.Column("account_key", sa.String(length=128), nullable=False),
+        sa.Column("entity_id", sa.String(length=255), nullable=False),
+        sa.Column("event_type", sa.String(length=64), nullable=False),
+        sa.Column("content_hash", sa.String(length=64), nullable=False),
+        sa.Column("memory_id", sa.String(length=64), nullable=False),
+        sa.Column("occurrences", sa.Integer(), nullable=False),
+        sa.Column("first_seen_at", sa.DateTime(timezone=True), nullable=False),
+        sa.Column("last_seen_at", sa.DateTime(timezone=True), nullable=False),
+        sa.PrimaryKeyConstraint("id"),
+        sa.UniqueConstraint(
+            "account_key",
+            "entity_id",
+            "event_type",
+            "content_hash",
+            name="uq_api_memory_counters_account_entity_type_hash",
+        ),
+    )
+    op.create_index(
+        "ix_api_memory_counters_account_memory",
+        "api_memory_counters",
+        ["account_key", "memory_id"],
+    )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_memory_counters" in set(inspector.get_table_names()):
+        op.drop_index("ix_api_memory_counters_account_memory", table_name="api_memory_counters")
+        op.drop_table("api_memory_counters")
@@ -0,0 +1,46 @@
+"""create goals table
+
+Revision ID: 20261015_0026
+Revises: 20261015_0025
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0026"
+down_revision = "20261015_0025"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_goals" in set(inspector.get_table_names()):
+        return
+    op.create_table(
+        "api_goals",
+        sa.Column("id", sa.String(length=64), nullable=False),
+        sa.Column("account_key", sa.String(length=128), nullable=False),
+        sa.Column("entity_id", sa.String(length=255), nullable=False),
+        sa.Column("memory_id", sa.String(length=64), nullable=False),
+        sa.Column("title", sa.Text(), nullable=False),
+        sa.Column("status", sa.String(length=16), nullable=False),
+        sa.Column("due_date", sa.Date(), nullable=True),
+        sa.Column("progress_json", sa.Text(), nullable=False),
+        sa.Column("created_at", sa.DateTime(timezone=True), nullable=False),
+        sa.Column("updated_at", sa.DateTime(timezone=True), nullable=False),
+        sa.PrimaryKeyConstraint("id"),
+    )
+    op.create_index("ix_api_goals_account_entity", "api_goals", ["account_key", "entity_id"])
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_goals" in set(inspector.get_table_names()):
+        op.drop_index("ix_api_goals_account_entity", table_name="api_goals")
+        op.drop_table("api_goals")
@@ -0,0 +1,37 @@
+"""add last_retrieved_at column to memories for access reinforcement
+
+Revision ID: 20261015_0027
+Revises: 20261015_0026
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0027"
+down_revision = "20261015_0026"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    columns = {column["name"] for column in inspector.get_columns("memories")}
+    if "last_retrieved_at" not in columns:
+        op.add_column(
+            "memories",
+            sa.Column("last_retrieved_at", sa.DateTime(timezone=True), nullable=True),
+        )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    columns = {column["name"] for column in inspector.get_columns("memories")}
+    if "last_retrieved_at" not in columns:
+        return
+    with op.batch_alter_table("memories") as batch_op:
+        batch_op.drop_column("last_retrieved_at")
@@ -0,0 +1,43 @@
+"""create resurfaced memories table
+
+Revision ID: 20261015_0028
+Revises: 20261015_0027
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0028"
+down_revision = "20261015_0027"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_resurfaced_memories" in set(inspector.get_table_names()):
+        return
+    op.create_table(
+        "api_resurfaced_memories",
+        sa.Column("id", sa.Integer(), autoincrement=True, nullable=False),
+        sa.Column("account_key", sa.String(length=128), nullable=False),
+        sa.Column("memory_id", sa.String(length=64), nullable=False),
+        sa.Column("surfaced_at", sa.DateTime(timezone=True), nullable=False),
+        sa.PrimaryKeyConstraint("id"),
+        sa.UniqueConstraint(
+            "account_key",
+            "memory_id",
+            name="uq_api_resurfaced_memories_account_memory",
+        ),
+    )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_resurfaced_memories" in set(inspector.get_table_names()):
+        op.drop_table("api_resurfaced_memories")
@@ -0,0 +1,53 @@
+"""create digests table
+
+Revision ID: 20261015_0029
+Revises: 20261015_0028
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0029"
+down_revision = "20261015_0028"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_digests" in set(inspector.get_table_names()):
+        return
+    op.create_table(
+        "api_digests",
+        sa.Column("id", sa.String(length=64), nullable=False),
+        sa.Column("account_key", sa.String(length=128), nullable=False),
+        sa.Column("entity_id", sa.String(length=255), nullable=True),
+        sa.Column("period", sa.String(length=16), nullable=False),
+        sa.Column("window_start", sa.DateTime(timezone=True), nullable=False),
+        sa.Column("window_end", sa.DateTime(timezone=True), nullable=False),
+        sa.Column("memory_count", sa.Integer(), nullable=False),
+        sa.Column("categories_json", sa.Text(), nullable=False),
+        sa.Column("summary", sa.Text(), nullable=False),
+        sa.Column("memory_ids_json", sa.Text(), nullable=False),
+        sa.Column("model", sa.String(length=128), nullable=True),
+        sa.Column("delivered", sa.Boolean(), nullable=False),
+        sa.Column("created_at", sa.DateTime(timezone=True), nullable=False),
+        sa.PrimaryKeyConstraint("id"),
+    )
+    op.create_index(
+        "ix_api_digests_account_created",
+        "api_digests",
+        ["account_key", "created_at"],
+    )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_digests" in set(inspector.get_table_names()):
+        op.drop_index("ix_api_digests_account_created", table_name="api_digests")
+        op.drop_table("api_digests")
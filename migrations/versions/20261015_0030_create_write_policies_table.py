@@ -0,0 +1,39 @@
+"""create write policies table
+
+Revision ID: 20261015_0030
+Revises: 20261015_0029
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0030"
+down_revision = "20261015_0029"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_write_policies" in set(inspector.get_table_names()):
+        return
+    op.create_table(
+        "api_write_policies",
+        sa.Column("account_key", sa.String(length=128), nullable=False),
+        sa.Column("allowed_event_types_json", sa.Text(), nullable=True),
+        sa.Column("max_importance", sa.Float(), nullable=True),
+        sa.Column("banned_categories_json", sa.Text(), nullable=False),
+        sa.Column("updated_at", sa.DateTime(timezone=True), nullable=False),
+        sa.PrimaryKeyConstraint("account_key"),
+    )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_write_policies" in set(inspector.get_table_names()):
+        op.drop_table("api_write_policies")
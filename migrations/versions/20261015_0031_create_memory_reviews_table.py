@@ -0,0 +1,68 @@
+"""create memory reviews table and add review categories to write policies
+
+Revision ID: 20261015_0031
+Revises: 20261015_0030
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0031"
+down_revision = "20261015_0030"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    columns = {column["name"] for column in inspector.get_columns("api_write_policies")}
+    if "review_categories_json" not in columns:
+        op.add_column(
+            "api_write_policies",
+            sa.Column(
+                "review_categories_json",
+                sa.Text(),
+                nullable=False,
+                server_default="[]",
+            ),
+        )
+    if "api_memory_reviews" in set(inspector.get_table_names()):
+        return
+    op.create_table(
+        "api_memory_reviews",
+        sa.Column("id", sa.String(length=64), nullable=False),
+        sa.Column("account_key", sa.String(length=128), nullable=False),
+        sa.Column("entity_id", sa.String(length=255), nullable=True),
+        sa.Column("agent_id", sa.String(length=128), nullable=True),
+        sa.Column("category", sa.String(length=64), nullable=False),
+        sa.Column("content", sa.Text(), nullable=False),
+        sa.Column("request_json", sa.Text(), nullable=False),
+        sa.Column("status", sa.String(length=16), nullable=False),
+        sa.Column("memory_id", sa.String(length=64), nullable=True),
+        sa.Column("note", sa.Text(), nullable=True),
+        sa.Column("resolved_by", sa.String(length=255), nullable=True),
+        sa.Column("created_at", sa.DateTime(timezone=True), nullable=False),
+        sa.Column("updated_at", sa.DateTime(timezone=True), nullable=False),
+        sa.PrimaryKeyConstraint("id"),
+    )
+    op.create_index(
+        "ix_api_memory_reviews_account_status",
+        "api_memory_reviews",
+        ["account_key", "status"],
+    )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_memory_reviews" in set(inspector.get_table_names()):
+        op.drop_index("ix_api_memory_reviews_account_status", table_name="api_memory_reviews")
+        op.drop_table("api_memory_reviews")
+    columns = {column["name"] for column in inspector.get_columns("api_write_policies")}
+    if "review_categories_json" in columns:
+        with op.batch_alter_table("api_write_policies") as batch_op:
+            batch_op.drop_column("review_categories_json")
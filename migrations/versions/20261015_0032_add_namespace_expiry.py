@@ -0,0 +1,36 @@
+"""add expiry to namespaces for sandboxes
+
+Revision ID: 20261015_0032
+Revises: 20261015_0031
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0032"
+down_revision = "20261015_0031"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    columns = {column["name"] for column in inspector.get_columns("api_namespaces")}
+    if "expires_at" not in columns:
+        op.add_column(
+            "api_namespaces",
+            sa.Column("expires_at", sa.DateTime(timezone=True), nullable=True),
+        )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    columns = {column["name"] for column in inspector.get_columns("api_namespaces")}
+    if "expires_at" in columns:
+        with op.batch_alter_table("api_namespaces") as batch_op:
+            batch_op.drop_column("expires_at")
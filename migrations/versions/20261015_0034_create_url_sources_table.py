@@ -0,0 +1,53 @@
+"""create url sources table
+
+Revision ID: 20261015_0034
+Revises: 20261015_0033
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0034"
+down_revision = "20261015_0033"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_url_sources" in set(inspector.get_table_names()):
+        return
+    op.create_table(
+        "api_url_sources",
+        sa.Column("id", sa.String(length=64), nullable=False),
+        sa.Column("account_key", sa.String(length=128), nullable=False),
+        sa.Column("url", sa.String(length=2048), nullable=False),
+        sa.Column("entity_id", sa.String(length=255), nullable=False),
+        sa.Column("interval_hours", sa.Float(), nullable=False),
+        sa.Column("enabled", sa.Boolean(), nullable=False),
+        sa.Column("content_hash", sa.String(length=64), nullable=True),
+        sa.Column("memory_ids_json", sa.Text(), nullable=False),
+        sa.Column("last_crawled_at", sa.DateTime(timezone=True), nullable=True),
+        sa.Column("next_crawl_at", sa.DateTime(timezone=True), nullable=True),
+        sa.Column("last_status", sa.String(length=16), nullable=True),
+        sa.Column("last_error", sa.Text(), nullable=True),
+        sa.Column("created_at", sa.DateTime(timezone=True), nullable=False),
+        sa.PrimaryKeyConstraint("id"),
+    )
+    op.create_index("ix_api_url_sources_account_key", "api_url_sources", ["account_key"])
+    op.create_index(
+        "ix_api_url_sources_enabled_next_crawl",
+        "api_url_sources",
+        ["enabled", "next_crawl_at"],
+    )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_url_sources" in set(inspector.get_table_names()):
+        op.drop_table("api_url_sources")
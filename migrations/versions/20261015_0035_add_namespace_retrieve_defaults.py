@@ -0,0 +1,37 @@
+"""add default retrieval options to namespaces
+
+Revision ID: 20261015_0035
+Revises: 20261015_0034
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0035"
+down_revision = "20261015_0034"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    columns = {column["name"] for column in inspector.get_columns("api_namespaces")}
+    if "retrieve_defaults_json" not in columns:
+        op.add_column(
+            "api_namespaces",
+            sa.Column("retrieve_defaults_json", sa.Text(), nullable=True),
+        )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    columns = {column["name"] for column in inspector.get_columns("api_namespaces")}
+    if "retrieve_defaults_json" not in columns:
+        return
+    with op.batch_alter_table("api_namespaces") as batch_op:
+        batch_op.drop_column("retrieve_defaults_json")
@@ -0,0 +1,44 @@
+"""create memory versions table
+
+Revision ID: 20261015_0037
+Revises: 20261015_0036
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0037"
+down_revision = "20261015_0036"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_memory_versions" in set(inspector.get_table_names()):
+        return
+    op.create_table(
+        "api_memory_versions",
+        sa.Column("id", sa.Integer(), autoincrement=True, nullable=False),
+        sa.Column("account_key", sa.String(length=128), nullable=False),
+        sa.Column("memory_id", sa.String(length=64), nullable=False),
+        sa.Column("version", sa.Integer(), nullable=False),
+        sa.Column("updated_at", sa.DateTime(timezone=True), nullable=False),
+        sa.PrimaryKeyConstraint("id"),
+        sa.UniqueConstraint(
+            "account_key",
+            "memory_id",
+            name="uq_api_memory_versions_account_memory",
+        ),
+    )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    if "api_memory_versions" in set(inspector.get_table_names()):
+        op.drop_table("api_memory_versions")
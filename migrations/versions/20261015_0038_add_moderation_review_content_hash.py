@@ -0,0 +1,37 @@
+"""add content_sha256 to moderation reviews so withheld payloads are never stored
+
+Revision ID: 20261015_0038
+Revises: 20261015_0037
+Create Date: 2026-10-15
+"""
+
+from __future__ import annotations
+
+import sqlalchemy as sa
+from alembic import op
+
+revision = "20261015_0038"
+down_revision = "20261015_0037"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    columns = {column["name"] for column in inspector.get_columns("api_moderation_reviews")}
+    if "content_sha256" not in columns:
+        op.add_column(
+            "api_moderation_reviews",
+            sa.Column("content_sha256", sa.String(length=64), nullable=True),
+        )
+
+
+def downgrade() -> None:
+    bind = op.get_bind()
+    inspector = sa.inspect(bind)
+    columns = {column["name"] for column in inspector.get_columns("api_moderation_reviews")}
+    if "content_sha256" not in columns:
+        return
+    with op.batch_alter_table("api_moderation_reviews") as batch_op:
+        batch_op.drop_column("content_sha256")
@@ -0,0 +1,68 @@
+"""The engine's current time.
+
+Everything that ages, decays, expires, or schedules reads :func:`now` instead of the wall
+clock. The process runs on one installed :class:`Clock`: :class:`SystemClock` by default, or a
+:class:`FixedClock` that tests and test-mode servers (``ORBIT_TEST_CLOCK``) move by hand.
+"""
+
+from __future__ import annotations
+
+from datetime import UTC, datetime, timedelta
+from typing import Protocol
+
+
+class Clock(Protocol):
+    def now(self) -> datetime:
+        """Return the current time as an aware UTC datetime."""
+
+
+class SystemClock:
+    def now(self) -> datetime:
+        return datetime.now(UTC)
+
+
+class FixedClock:
+    """A clock that only moves when told to."""
+
+    def __init__(self, at: datetime) -> None:
+        self._at = _as_utc(at)
+
+    def now(self) -> datetime:
+        return self._at
+
+    def set(self, at: datetime) -> None:
+        self._at = _as_utc(at)
+
+    def advance(self, delta: timedelta) -> None:
+        self._at += delta
+
+
+_current: Clock = SystemClock()
+
+
+def now() -> datetime:
+    """The installed clock's time."""
+    return _current.now()
+
+
+def current() -> Clock:
+    return _current
+
+
+def install(clock: Clock | None) -> None:
+    """Make ``clock`` the process clock; ``None`` restores the system clock."""
+    global _current
+    _current = clock if clock is not None else SystemClock()
+
+
+def pin(value: datetime | None) -> None:
+    """Freeze :func:`now` at ``value`` (naive values are UTC); ``None`` unpins it."""
+    install(FixedClock(value) if value is not None else None)
+
+
+def pinned() -> bool:
+    return isinstance(_current, FixedClock)
+
+
+def _as_utc(value: datetime) -> datetime:
+    return value.replace(tzinfo=UTC) if value.tzinfo is None else value.astimezone(UTC)
@@ -13,6 +13,8 @@ class EngineConfig(BaseModel):
     embedding_dim: int = 384
     sqlite_path: str = "memory.db"
     database_url: str | None = None
+    storage_backend: str = "auto"
+    postgres_text_search_config: str = "english"
     max_content_chars: int = 4000
     assistant_max_content_chars: int = 900
     store_raw_embedding: bool = False
@@ -59,6 +61,15 @@ class EngineConfig(BaseModel):
     def validate_database_url(cls, value: str | None) -> str | None:
         return normalize_database_url(value)
 
+    @field_validator("storage_backend")
+    @classmethod
+    def validate_storage_backend(cls, value: str) -> str:
+        normalized = value.strip().lower()
+        if normalized not in {"auto", "sqlite", "sqlalchemy", "postgres"}:
+            msg = "storage_backend must be one of: auto, sqlite, sqlalchemy, postgres"
+            raise ValueError(msg)
+        return normalized
+
     @field_validator("max_content_chars", "assistant_max_content_chars")
     @classmethod
     def validate_content_limits(cls, value: int) -> int:
@@ -134,6 +145,10 @@ class EngineConfig(BaseModel):
             embedding_dim=int(os.getenv("MDE_EMBEDDING_DIM", "384")),
             sqlite_path=os.getenv("MDE_SQLITE_PATH", "memory.db"),
             database_url=database_url if database_url else None,
+            storage_backend=os.getenv("MDE_STORAGE_BACKEND", "auto"),
+            postgres_text_search_config=os.getenv(
+                "MDE_POSTGRES_TEXT_SEARCH_CONFIG", "english"
+            ),
             max_content_chars=int(os.getenv("MDE_MAX_CONTENT_CHARS", "4000")),
             assistant_max_content_chars=int(
                 os.getenv("MDE_ASSISTANT_MAX_CONTENT_CHARS", "900")
@@ -1,7 +1,6 @@
 from __future__ import annotations
 
-from datetime import UTC, datetime
-
+from decision_engine import clock
 from decision_engine.config import EngineConfig
 from decision_engine.decay_learner import DecayLearner
 from decision_engine.importance_model import ImportanceModel
@@ -104,7 +103,7 @@ class DecisionEngine:
         query_embedding = self.encoder.encode_query(query)
         pool_size = candidate_pool_size or max(top_k * 2, 20)
         candidates = self.storage.search_candidates(query_embedding, top_k=pool_size)
-        now = datetime.now(UTC)
+        now = clock.now()
         ranked = self.ranker.rank(query_embedding, candidates, now=now)
         selected = ranked[:top_k]
         for item in selected:
@@ -113,7 +112,7 @@ class DecisionEngine:
         return selected
 
     def record_feedback(self, feedback: OutcomeFeedback) -> dict[str, float | None]:
-        now = datetime.now(UTC)
+        now = clock.now()
         query_embedding = self.encoder.encode_query(feedback.query)
         ranked_memories = self.storage.fetch_by_ids(feedback.ranked_memory_ids)
         helpful_ids = set(feedback.helpful_memory_ids)
@@ -139,7 +138,7 @@ class DecisionEngine:
             importance_loss = self.importance_model.train_batch(embeddings, outcomes)
 
         for memory in ranked_memories:
-            age_days = max((now - memory.created_at).total_seconds() / 86400.0, 0.0)
+            age_days = max((now - memory.event_time).total_seconds() / 86400.0, 0.0)
             was_helpful = memory.memory_id in helpful_ids
             self.decay_learner.record_outcome(
                 memory.semantic_key, age_days, was_helpful
@@ -163,7 +162,7 @@ class DecisionEngine:
 
     def estimate_memory_relevance(self, memory: MemoryRecord) -> float:
         age_days = max(
-            (datetime.now(UTC) - memory.created_at).total_seconds() / 86400.0, 0.0
+            (clock.now() - memory.event_time).total_seconds() / 86400.0, 0.0
         )
         return self.decay_learner.predict_relevance(
             memory.semantic_key, age_days, memory.latest_importance
@@ -1,12 +1,14 @@
 from __future__ import annotations
 
-from datetime import UTC, datetime
+from datetime import datetime
 from enum import Enum
 from typing import Any
 from uuid import uuid4
 
 from pydantic import BaseModel, Field, field_validator
 
+from decision_engine import clock
+
 
 class StorageTier(str, Enum):
     PERSISTENT = "persistent"
@@ -16,7 +18,7 @@ class StorageTier(str, Enum):
 
 class RawEvent(BaseModel):
     event_id: str = Field(default_factory=lambda: str(uuid4()))
-    timestamp: datetime = Field(default_factory=lambda: datetime.now(UTC))
+    timestamp: datetime = Field(default_factory=clock.now)
     content: str
     context: dict[str, Any] = Field(default_factory=dict)
 
@@ -68,7 +70,10 @@ class MemoryRecord(BaseModel):
     semantic_key: str
     created_at: datetime
     updated_at: datetime
+    # When the event happened, for backdated ingests; created_at is when Orbit stored it.
+    occurred_at: datetime | None = None
     retrieval_count: int = 0
+    last_retrieved_at: datetime | None = None
     avg_outcome_signal: float = 0.0
     storage_tier: StorageTier
     latest_importance: float
@@ -76,6 +81,11 @@ class MemoryRecord(BaseModel):
     original_count: int = 1
     decay_half_life_days: float | None = None
 
+    @property
+    def event_time(self) -> datetime:
+        """What recency and time ranges read: ``occurred_at``, else ``created_at``."""
+        return self.occurred_at or self.created_at
+
 
 class RetrievedMemory(BaseModel):
     memory: MemoryRecord
@@ -20,6 +20,12 @@ class RetrievalRanker:
     """Learned retrieval ranker with similarity fallback before warm-up."""
 
     _FEATURE_DIM = 8
+    # Memory strength decays at this rate per day since the last reinforcement. Each
+    # retrieval restarts the clock and slows later decay; memories never recalled decay
+    # faster once the grace period has passed.
+    _STRENGTH_DECAY_RATE = 0.03
+    _NEVER_RECALLED_GRACE_DAYS = 7.0
+    _NEVER_RECALLED_DECAY_FACTOR = 1.5
     _INTENT_PRIORS = {
         "preference_stated": 1.28,
         "learning_progress": 1.22,
@@ -133,14 +139,13 @@ class RetrievalRanker:
             raw_embedding,
             fallback=semantic_similarity,
         )
-        age_days = max((now - memory.created_at).total_seconds() / 86400.0, 0.0)
         summary_words = self._word_count(memory.summary)
         content_words = self._word_count(memory.content)
         return np.array(
             [
                 semantic_similarity,
                 raw_similarity,
-                math.exp(-0.03 * age_days),
+                self._memory_strength(memory, now),
                 self._clamp01(math.log1p(memory.retrieval_count) / 4.0),
                 (memory.avg_outcome_signal + 1.0) / 2.0,
                 self._clamp01(memory.latest_importance),
@@ -150,10 +155,27 @@ class RetrievalRanker:
             dtype=np.float32,
         )
 
+    @classmethod
+    def _memory_strength(cls, memory: MemoryRecord, now: datetime) -> float:
+        """Retention in [0, 1]: reinforced by each retrieval, fading without them."""
+        age_days = max((now - memory.event_time).total_seconds() / 86400.0, 0.0)
+        if memory.retrieval_count <= 0:
+            grace_days = min(age_days, cls._NEVER_RECALLED_GRACE_DAYS)
+            return math.exp(
+                -cls._STRENGTH_DECAY_RATE * grace_days
+                - cls._STRENGTH_DECAY_RATE
+                * cls._NEVER_RECALLED_DECAY_FACTOR
+                * (age_days - grace_days)
+            )
+        reinforced_at = memory.last_retrieved_at or memory.event_time
+        idle_days = max((now - reinforced_at).total_seconds() / 86400.0, 0.0)
+        stability = 1.0 + math.log1p(memory.retrieval_count)
+        return math.exp(-cls._STRENGTH_DECAY_RATE * idle_days / stability)
+
     def _fallback_score(self, features: NDArray[np.float32]) -> float:
         semantic_signal = (float(features[0]) + 1.0) / 2.0
         raw_signal = (float(features[1]) + 1.0) / 2.0
-        recency_signal = float(features[2])
+        strength_signal = float(features[2])
         retrieval_signal = float(features[3])
         outcome_signal = float(features[4])
         importance_signal = float(features[5])
@@ -163,7 +185,7 @@ class RetrievalRanker:
         base_score = (
             0.41 * semantic_signal
             + 0.09 * raw_signal
-            + 0.05 * recency_signal
+            + 0.05 * strength_signal
             + 0.05 * retrieval_signal
             + 0.09 * outcome_signal
             + 0.31 * importance_signal
@@ -152,17 +152,57 @@ class SemanticEncoder:
         self._semantic_provider = semantic_provider
 
     def encode_event(self, event: RawEvent) -> EncodedEvent:
-        understanding = self._semantic_provider.understand(event)
-        raw_embedding = self._embedding_provider.embed(event.content)
+        return self.encode_understood(event, self.understand(event))
+
+    def understand(self, event: RawEvent) -> SemanticUnderstanding:
+        return self._semantic_provider.understand(event)
+
+    def encode_understood(
+        self, event: RawEvent, understanding: SemanticUnderstanding
+    ) -> EncodedEvent:
+        """Embed an event whose semantic understanding was extracted separately."""
         semantic_text = self._build_semantic_text(event, understanding)
-        semantic_embedding = self._embedding_provider.embed(semantic_text)
-        semantic_key = self._semantic_key(understanding)
+        # Providers that batch (such as Ollama) embed both texts in one request.
+        embed_many = getattr(self._embedding_provider, "embed_many", None)
+        if callable(embed_many):
+            raw_embedding, semantic_embedding = embed_many([event.content, semantic_text])
+        else:
+            raw_embedding = self._embedding_provider.embed(event.content)
+            semantic_embedding = self._embedding_provider.embed(semantic_text)
+        return self._encoded(event, understanding, raw_embedding, semantic_embedding)
+
+    def encode_understood_many(
+        self, items: list[tuple[RawEvent, SemanticUnderstanding]]
+    ) -> list[EncodedEvent]:
+        """``encode_understood`` for many events, in one ``embed_many`` call when supported."""
+        texts = [
+            text
+            for event, understanding in items
+            for text in (event.content, self._build_semantic_text(event, understanding))
+        ]
+        embed_many = getattr(self._embedding_provider, "embed_many", None)
+        if callable(embed_many):
+            vectors = list(embed_many(texts))
+        else:
+            vectors = [self._embedding_provider.embed(text) for text in texts]
+        return [
+            self._encoded(event, understanding, vectors[2 * index], vectors[2 * index + 1])
+            for index, (event, understanding) in enumerate(items)
+        ]
+
+    def _encoded(
+        self,
+        event: RawEvent,
+        understanding: SemanticUnderstanding,
+        raw_embedding: FloatArray,
+        semantic_embedding: FloatArray,
+    ) -> EncodedEvent:
         return EncodedEvent(
             event=event,
             raw_embedding=raw_embedding.tolist(),
             semantic_embedding=semantic_embedding.tolist(),
             understanding=understanding,
-            semantic_key=semantic_key,
+            semantic_key=self._semantic_key(understanding),
         )
 
     def encode_query(self, query: str) -> FloatArray:
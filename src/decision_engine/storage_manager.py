@@ -4,13 +4,14 @@ import json
 import re
 import sqlite3
 import threading
-from datetime import UTC, datetime
+from datetime import datetime
 from pathlib import Path
 from uuid import uuid4
 
 import numpy as np
 from numpy.typing import NDArray
 
+from decision_engine import clock
 from decision_engine.math_utils import cosine_similarity
 from decision_engine.models import (
     EncodedEvent,
@@ -79,12 +80,16 @@ class SQLiteStorageManager:
                     storage_tier TEXT NOT NULL,
                     latest_importance REAL NOT NULL,
                     is_compressed INTEGER NOT NULL DEFAULT 0,
-                    original_count INTEGER NOT NULL DEFAULT 1
+                    original_count INTEGER NOT NULL DEFAULT 1,
+                    last_retrieved_at TEXT,
+                    occurred_at TEXT
                 )
                 """)
             self._ensure_column("is_compressed", "INTEGER NOT NULL DEFAULT 0")
             self._ensure_column("original_count", "INTEGER NOT NULL DEFAULT 1")
             self._ensure_column("account_key", "TEXT NOT NULL DEFAULT 'default'")
+            self._ensure_column("last_retrieved_at", "TEXT")
+            self._ensure_column("occurred_at", "TEXT")
             self._connection.execute(
                 "UPDATE memories SET account_key = 'default' "
                 "WHERE account_key IS NULL OR account_key = ''"
@@ -106,10 +111,11 @@ class SQLiteStorageManager:
         encoded_event: EncodedEvent,
         decision: StorageDecision,
         account_key: str = "default",
+        memory_id: str | None = None,
     ) -> MemoryRecord:
         normalized_account_key = self._normalize_account_key(account_key)
-        memory_id = str(uuid4())
-        now = datetime.now(UTC)
+        memory_id = memory_id or str(uuid4())
+        now = clock.now()
         intent = encoded_event.understanding.intent
         content = self._truncate_content(encoded_event.event.content, intent=intent)
         raw_embedding = (
@@ -129,6 +135,7 @@ class SQLiteStorageManager:
             semantic_key=encoded_event.semantic_key,
             created_at=now,
             updated_at=now,
+            occurred_at=encoded_event.event.timestamp,
             retrieval_count=0,
             avg_outcome_signal=0.0,
             storage_tier=decision.tier,
@@ -144,8 +151,8 @@ class SQLiteStorageManager:
                     relationships_json, raw_embedding_json, semantic_embedding_json,
                     semantic_key, created_at, updated_at, retrieval_count,
                     avg_outcome_signal, outcome_count, storage_tier, latest_importance,
-                    is_compressed, original_count
-                ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+                    is_compressed, original_count, last_retrieved_at, occurred_at
+                ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
                 """,
                 (
                     record.account_key,
@@ -168,6 +175,12 @@ class SQLiteStorageManager:
                     record.latest_importance,
                     1 if record.is_compressed else 0,
                     record.original_count,
+                    (
+                        record.last_retrieved_at.isoformat()
+                        if record.last_retrieved_at is not None
+                        else None
+                    ),
+                    record.occurred_at.isoformat() if record.occurred_at is not None else None,
                 ),
             )
             self._connection.commit()
@@ -316,16 +329,18 @@ class SQLiteStorageManager:
         return [memory for memory, _ in scored[:top_k]]
 
     def update_retrieval(self, memory_id: str, account_key: str | None = None) -> None:
+        now = clock.now().isoformat()
         with self._lock:
             if account_key is None:
                 self._connection.execute(
                     """
                     UPDATE memories
                     SET retrieval_count = retrieval_count + 1,
+                        last_retrieved_at = ?,
                         updated_at = ?
                     WHERE memory_id = ?
                     """,
-                    (datetime.now(UTC).isoformat(), memory_id),
+                    (now, now, memory_id),
                 )
             else:
                 normalized_account_key = self._normalize_account_key(account_key)
@@ -333,10 +348,11 @@ class SQLiteStorageManager:
                     """
                     UPDATE memories
                     SET retrieval_count = retrieval_count + 1,
+                        last_retrieved_at = ?,
                         updated_at = ?
                     WHERE account_key = ? AND memory_id = ?
                     """,
-                    (datetime.now(UTC).isoformat(), normalized_account_key, memory_id),
+                    (now, now, normalized_account_key, memory_id),
                 )
             self._connection.commit()
 
@@ -373,7 +389,7 @@ class SQLiteStorageManager:
                     SET avg_outcome_signal = ?, outcome_count = ?, updated_at = ?
                     WHERE memory_id = ?
                     """,
-                    (new_avg, new_count, datetime.now(UTC).isoformat(), memory_id),
+                    (new_avg, new_count, clock.now().isoformat(), memory_id),
                 )
             else:
                 self._connection.execute(
@@ -385,17 +401,85 @@ class SQLiteStorageManager:
                     (
                         new_avg,
                         new_count,
-                        datetime.now(UTC).isoformat(),
+                        clock.now().isoformat(),
                         normalized_account_key,
                         memory_id,
                     ),
                 )
             self._connection.commit()
 
+    def optimize(self) -> list[str]:
+        with self._lock:
+            self._connection.commit()
+            self._connection.execute("VACUUM")
+            self._connection.execute("ANALYZE")
+        return ["vacuum", "analyze"]
+
     def close(self) -> None:
         with self._lock:
             self._connection.close()
 
+    def update_content(
+        self,
+        memory_id: str,
+        *,
+        content: str,
+        summary: str,
+        raw_embedding: list[float],
+        semantic_embedding: list[float],
+        semantic_key: str,
+        account_key: str | None = None,
+    ) -> MemoryRecord | None:
+        existing = self.fetch_by_ids([memory_id], account_key=account_key)
+        if not existing:
+            return None
+        with self._lock:
+            self._connection.execute(
+                """
+                UPDATE memories
+                SET content = ?, summary = ?, raw_embedding_json = ?,
+                    semantic_embedding_json = ?, semantic_key = ?, updated_at = ?
+                WHERE account_key = ? AND memory_id = ?
+                """,
+                (
+                    self._truncate_content(content, intent=existing[0].intent),
+                    summary,
+                    self._dumps_vector(raw_embedding if self._store_raw_embedding else []),
+                    self._dumps_vector(semantic_embedding),
+                    semantic_key,
+                    clock.now().isoformat(),
+                    existing[0].account_key,
+                    memory_id,
+                ),
+            )
+            self._connection.commit()
+        return self.fetch_by_ids([memory_id], account_key=existing[0].account_key)[0]
+
+    def update_relationships(
+        self,
+        memory_id: str,
+        relationships: list[str],
+        account_key: str | None = None,
+    ) -> MemoryRecord | None:
+        existing = self.fetch_by_ids([memory_id], account_key=account_key)
+        if not existing:
+            return None
+        with self._lock:
+            self._connection.execute(
+                """
+                UPDATE memories SET relationships_json = ?, updated_at = ?
+                WHERE account_key = ? AND memory_id = ?
+                """,
+                (
+                    self._dumps_compact(relationships),
+                    clock.now().isoformat(),
+                    existing[0].account_key,
+                    memory_id,
+                ),
+            )
+            self._connection.commit()
+        return self.fetch_by_ids([memory_id], account_key=existing[0].account_key)[0]
+
     def delete_memories(
         self,
         memory_ids: list[str],
@@ -505,7 +589,15 @@ class SQLiteStorageManager:
             semantic_key=str(row["semantic_key"]),
             created_at=datetime.fromisoformat(str(row["created_at"])),
             updated_at=datetime.fromisoformat(str(row["updated_at"])),
+            occurred_at=(
+                datetime.fromisoformat(str(row["occurred_at"])) if row["occurred_at"] else None
+            ),
             retrieval_count=int(row["retrieval_count"]),
+            last_retrieved_at=(
+                datetime.fromisoformat(str(row["last_retrieved_at"]))
+                if row["last_retrieved_at"]
+                else None
+            ),
             avg_outcome_signal=float(row["avg_outcome_signal"]),
             storage_tier=StorageTier(str(row["storage_tier"])),
             latest_importance=float(row["latest_importance"]),
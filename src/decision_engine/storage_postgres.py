@@ -0,0 +1,250 @@
+from __future__ import annotations
+
+import json
+import re
+from collections.abc import Sequence
+
+import numpy as np
+from numpy.typing import NDArray
+from sqlalchemy import text
+from sqlalchemy.orm import Session
+
+from decision_engine.models import MemoryRecord
+from decision_engine.storage_sqlalchemy import SQLAlchemyStorageManager
+
+_TEXT_SEARCH_CONFIG_PATTERN = re.compile(r"^[a-z_]+$")
+
+
+class PostgresStorageManager(SQLAlchemyStorageManager):
+    """PostgreSQL storage with pgvector similarity, JSONB metadata, and full-text search."""
+
+    def __init__(
+        self,
+        database_url: str,
+        *,
+        embedding_dim: int,
+        text_search_config: str = "english",
+        max_content_chars: int = 4000,
+        assistant_max_content_chars: int = 900,
+        store_raw_embedding: bool = False,
+        write_retry_attempts: int = 5,
+    ) -> None:
+        if not database_url.startswith("postgresql"):
+            msg = "PostgresStorageManager requires a postgresql:// database URL"
+            raise ValueError(msg)
+        if embedding_dim <= 0:
+            msg = "embedding_dim must be > 0"
+            raise ValueError(msg)
+        self._embedding_dim = embedding_dim
+        self._text_search_config = normalize_text_search_config(text_search_config)
+        super().__init__(
+            database_url,
+            max_content_chars=max_content_chars,
+            assistant_max_content_chars=assistant_max_content_chars,
+            store_raw_embedding=store_raw_embedding,
+            write_retry_attempts=write_retry_attempts,
+        )
+        self._require_postgres_schema()
+        self._backfill_postgres_columns()
+
+    def search_candidates(
+        self,
+        query_embedding: NDArray[np.float32],
+        top_k: int,
+        account_key: str | None = None,
+    ) -> list[MemoryRecord]:
+        if top_k <= 0:
+            return []
+        if int(query_embedding.shape[0]) != self._embedding_dim:
+            return super().search_candidates(
+                query_embedding, top_k=top_k, account_key=account_key
+            )
+        params: dict[str, object] = {
+            "embedding": to_vector_literal(query_embedding.tolist()),
+            "top_k": top_k,
+        }
+        where = "embedding_vector IS NOT NULL"
+        if account_key is not None:
+            where += " AND account_key = :account_key"
+            params["account_key"] = self._normalize_account_key(account_key)
+        with self._session_factory() as session:
+            rows = session.execute(
+                text(
+                    "SELECT memory_id FROM memories "
+                    f"WHERE {where} "
+                    "ORDER BY embedding_vector <=> CAST(:embedding AS vector) "
+                    "LIMIT :top_k"
+                ),
+                params,
+            ).all()
+        memory_ids = [str(row[0]) for row in rows]
+        return self._fetch_in_order(memory_ids, account_key=account_key)
+
+    def keyword_search(
+        self,
+        query_text: str,
+        top_k: int,
+        account_key: str | None = None,
+    ) -> list[MemoryRecord]:
+        """Rank memories by Postgres full-text relevance for keyword-heavy queries."""
+        normalized_query = query_text.strip()
+        if not normalized_query or top_k <= 0:
+            return []
+        params: dict[str, object] = {"query": normalized_query, "top_k": top_k}
+        where = f"content_tsv @@ plainto_tsquery('{self._text_search_config}', :query)"
+        if account_key is not None:
+            where += " AND account_key = :account_key"
+            params["account_key"] = self._normalize_account_key(account_key)
+        with self._session_factory() as session:
+            rows = session.execute(
+                text(
+                    "SELECT memory_id FROM memories "
+                    f"WHERE {where} "
+                    "ORDER BY ts_rank_cd(content_tsv, "
+                    f"plainto_tsquery('{self._text_search_config}', :query)) DESC "
+                    "LIMIT :top_k"
+                ),
+                params,
+            ).all()
+        memory_ids = [str(row[0]) for row in rows]
+        return self._fetch_in_order(memory_ids, account_key=account_key)
+
+    def _write_derived_columns(self, session: Session, record: MemoryRecord) -> None:
+        session.execute(
+            text(
+                "UPDATE memories SET "
+                "embedding_vector = CAST(:embedding AS vector), "
+                "metadata_jsonb = CAST(:metadata AS JSONB) "
+                "WHERE memory_id = :memory_id"
+            ),
+            {
+                "embedding": self._vector_or_none(record.semantic_embedding),
+                "metadata": build_metadata_document(
+                    entities=record.entities,
+                    relationships=record.relationships,
+                    intent=record.intent,
+                ),
+                "memory_id": record.memory_id,
+            },
+        )
+
+    def _fetch_in_order(
+        self,
+        memory_ids: list[str],
+        account_key: str | None,
+    ) -> list[MemoryRecord]:
+        if not memory_ids:
+            return []
+        by_id = {
+            record.memory_id: record
+            for record in self.fetch_by_ids(memory_ids, account_key=account_key)
+        }
+        return [by_id[memory_id] for memory_id in memory_ids if memory_id in by_id]
+
+    def _vector_or_none(self, values: Sequence[float]) -> str | None:
+        if len(values) != self._embedding_dim:
+            return None
+        return to_vector_literal(values)
+
+    def optimize(self) -> list[str]:
+        # Rebuild the HNSW graph so vectors of deleted rows stop being visited at query time.
+        with self._engine.connect().execution_options(isolation_level="AUTOCOMMIT") as conn:
+            conn.execute(
+                text("REINDEX INDEX CONCURRENTLY ix_memories_embedding_vector_hnsw")
+            )
+        return ["reindex_hnsw", *super().optimize()]
+
+    def _require_postgres_schema(self) -> None:
+        """Fail fast unless migration 20261015_0008 added the Postgres columns.
+
+        The schema is owned by alembic; this only checks that it has been applied with a
+        vector width matching ``embedding_dim``.
+        """
+        with self._engine.connect() as conn:
+            columns = {
+                str(row[0]): int(row[1])
+                for row in conn.execute(
+                    text(
+                        "SELECT attname, atttypmod FROM pg_attribute "
+                        "WHERE attrelid = 'memories'::regclass AND NOT attisdropped"
+                    )
+                )
+            }
+        missing = [
+            column
+            for column in ("embedding_vector", "metadata_jsonb", "content_tsv")
+            if column not in columns
+        ]
+        if missing:
+            msg = (
+                f"memories is missing {', '.join(missing)}; run `orbit migrate` "
+                "(alembic upgrade head) with MDE_EMBEDDING_DIM set"
+            )
+            raise RuntimeError(msg)
+        if columns["embedding_vector"] != self._embedding_dim:
+            msg = (
+                f"memories.embedding_vector has {columns['embedding_vector']} dimensions "
+                f"but embedding_dim is {self._embedding_dim}"
+            )
+            raise RuntimeError(msg)
+
+    def _backfill_postgres_columns(self, batch_size: int = 500) -> None:
+        while True:
+            with self._session_factory() as session:
+                rows = session.execute(
+                    text(
+                        "SELECT memory_id FROM memories "
+                        "WHERE metadata_jsonb IS NULL LIMIT :limit"
+                    ),
+                    {"limit": batch_size},
+                ).all()
+            memory_ids = [str(row[0]) for row in rows]
+            if not memory_ids:
+                return
+            records = self.fetch_by_ids(memory_ids)
+
+            def _backfill(
+                session: Session,
+                pending: list[MemoryRecord] = records,
+            ) -> None:
+                for record in pending:
+                    self._write_derived_columns(session, record)
+
+            self._execute_write(_backfill)
+            if len(memory_ids) < batch_size:
+                return
+
+
+def to_vector_literal(values: Sequence[float]) -> str:
+    """Format an embedding as a pgvector text literal."""
+    return "[" + ",".join(f"{float(value):.8g}" for value in values) + "]"
+
+
+def build_metadata_document(
+    *,
+    entities: Sequence[str],
+    relationships: Sequence[str],
+    intent: str,
+) -> str:
+    """Build the JSONB metadata payload; prefixed relationship tags become keys."""
+    tags: dict[str, list[str]] = {}
+    for relationship in relationships:
+        prefix, separator, value = relationship.partition(":")
+        if not separator or not prefix.strip() or not value.strip():
+            continue
+        tags.setdefault(prefix.strip(), []).append(value.strip())
+    document = {
+        "intent": intent,
+        "entities": list(entities),
+        "relationships": list(relationships),
+        "tags": tags,
+    }
+    return json.dumps(document, ensure_ascii=True, separators=(",", ":"))
+
+
+def normalize_text_search_config(value: str) -> str:
+    normalized = value.strip().lower()
+    if not _TEXT_SEARCH_CONFIG_PATTERN.fullmatch(normalized):
+        msg = "text_search_config must be a Postgres text search configuration name"
+        raise ValueError(msg)
+    return normalized
@@ -16,8 +16,13 @@ class StorageManagerProtocol(Protocol):
         encoded_event: EncodedEvent,
         decision: StorageDecision,
         account_key: str = "default",
+        memory_id: str | None = None,
     ) -> MemoryRecord:
-        """Persist an encoded event and return the stored memory record."""
+        """Persist an encoded event and return the stored memory record.
+
+        ``memory_id`` keeps an id assigned elsewhere (replication); a new one is generated
+        when it is omitted.
+        """
 
     def count_memories(self, account_key: str | None = None) -> int:
         """Return total persisted memories."""
@@ -71,6 +76,27 @@ class StorageManagerProtocol(Protocol):
     ) -> None:
         """Update aggregated outcome signal for a memory."""
 
+    def update_content(
+        self,
+        memory_id: str,
+        *,
+        content: str,
+        summary: str,
+        raw_embedding: list[float],
+        semantic_embedding: list[float],
+        semantic_key: str,
+        account_key: str | None = None,
+    ) -> MemoryRecord | None:
+        """Replace a memory's content and embeddings in place; ``None`` if it does not exist."""
+
+    def update_relationships(
+        self,
+        memory_id: str,
+        relationships: list[str],
+        account_key: str | None = None,
+    ) -> MemoryRecord | None:
+        """Replace a memory's relationships; ``None`` if it does not exist."""
+
     def delete_memories(
         self,
         memory_ids: list[str],
@@ -78,5 +104,8 @@ class StorageManagerProtocol(Protocol):
     ) -> None:
         """Delete memories by ID."""
 
+    def optimize(self) -> list[str]:
+        """Reclaim space and refresh planner statistics; returns the maintenance steps run."""
+
     def close(self) -> None:
         """Release storage resources."""
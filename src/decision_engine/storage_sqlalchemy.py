@@ -16,6 +16,7 @@ from sqlalchemy.engine import Engine
 from sqlalchemy.exc import OperationalError
 from sqlalchemy.orm import Session, sessionmaker
 
+from decision_engine import clock
 from decision_engine.math_utils import cosine_similarity
 from decision_engine.models import EncodedEvent, MemoryRecord, StorageDecision, StorageTier
 from decision_engine.vector_codec import decode_vector, encode_vector
@@ -60,16 +61,32 @@ class SQLAlchemyStorageManager:
             autocommit=False,
             future=True,
         )
+        self._write_journals: list[Callable[[Session, str, MemoryRecord], None]] = []
+
+    @property
+    def database_url(self) -> str:
+        return self._database_url
+
+    def add_write_journal(
+        self,
+        journal: Callable[[Session, str, MemoryRecord], None],
+    ) -> None:
+        """Run ``journal`` inside every memory write with ("created" | "updated" | "deleted").
+
+        Rows the journal adds to the session commit or roll back together with the write.
+        """
+        self._write_journals.append(journal)
 
     def store(
         self,
         encoded_event: EncodedEvent,
         decision: StorageDecision,
         account_key: str = "default",
+        memory_id: str | None = None,
     ) -> MemoryRecord:
         normalized_account_key = self._normalize_account_key(account_key)
-        memory_id = str(uuid4())
-        now = datetime.now(UTC)
+        memory_id = memory_id or str(uuid4())
+        now = clock.now()
         intent = encoded_event.understanding.intent
         content = self._truncate_content(encoded_event.event.content, intent=intent)
         raw_embedding = (
@@ -89,6 +106,7 @@ class SQLAlchemyStorageManager:
             semantic_key=encoded_event.semantic_key,
             created_at=now,
             updated_at=now,
+            occurred_at=encoded_event.event.timestamp,
             retrieval_count=0,
             avg_outcome_signal=0.0,
             storage_tier=decision.tier,
@@ -109,6 +127,7 @@ class SQLAlchemyStorageManager:
             "semantic_embedding_json": self._dumps_vector(record.semantic_embedding),
             "semantic_key": record.semantic_key,
             "retrieval_count": record.retrieval_count,
+            "last_retrieved_at": record.last_retrieved_at,
             "avg_outcome_signal": record.avg_outcome_signal,
             "outcome_count": 0,
             "storage_tier": record.storage_tier.value,
@@ -117,10 +136,14 @@ class SQLAlchemyStorageManager:
             "original_count": record.original_count,
             "created_at": record.created_at,
             "updated_at": record.updated_at,
+            "occurred_at": record.occurred_at,
         }
 
         def _insert(session: Session) -> None:
             session.add(MemoryRow(**row_payload))
+            session.flush()
+            self._write_derived_columns(session, record)
+            self._journal(session, "created", record)
 
         self._execute_write(_insert)
         return record
@@ -230,10 +253,12 @@ class SQLAlchemyStorageManager:
             if account_key is not None:
                 normalized_account_key = self._normalize_account_key(account_key)
                 stmt = stmt.where(MemoryRow.account_key == normalized_account_key)
+            now = clock.now()
             session.execute(
                 stmt.values(
                     retrieval_count=MemoryRow.retrieval_count + 1,
-                    updated_at=datetime.now(UTC),
+                    last_retrieved_at=now,
+                    updated_at=now,
                 )
             )
 
@@ -259,10 +284,71 @@ class SQLAlchemyStorageManager:
             new_avg = ((avg * count) + outcome_signal) / new_count
             row.avg_outcome_signal = new_avg
             row.outcome_count = new_count
-            row.updated_at = datetime.now(UTC)
+            row.updated_at = clock.now()
 
         self._execute_write(_update)
 
+    def update_content(
+        self,
+        memory_id: str,
+        *,
+        content: str,
+        summary: str,
+        raw_embedding: list[float],
+        semantic_embedding: list[float],
+        semantic_key: str,
+        account_key: str | None = None,
+    ) -> MemoryRecord | None:
+        def _update(session: Session) -> MemoryRecord | None:
+            stmt = select(MemoryRow).where(MemoryRow.memory_id == memory_id)
+            if account_key is not None:
+                normalized_account_key = self._normalize_account_key(account_key)
+                stmt = stmt.where(MemoryRow.account_key == normalized_account_key)
+            row = session.execute(stmt).scalar_one_or_none()
+            if row is None:
+                return None
+            row.content = self._truncate_content(content, intent=row.intent)
+            row.summary = summary
+            row.raw_embedding_json = self._dumps_vector(
+                raw_embedding if self._store_raw_embedding else []
+            )
+            row.semantic_embedding_json = self._dumps_vector(semantic_embedding)
+            row.semantic_key = semantic_key
+            row.updated_at = clock.now()
+            session.flush()
+            record = self._row_to_memory(row)
+            # Derived dialect columns (e.g. pgvector) must follow the new embedding.
+            self._write_derived_columns(session, record)
+            self._journal(session, "updated", record)
+            return record
+
+        return self._execute_write(_update)
+
+    def update_relationships(
+        self,
+        memory_id: str,
+        relationships: list[str],
+        account_key: str | None = None,
+    ) -> MemoryRecord | None:
+        def _update(session: Session) -> MemoryRecord | None:
+            stmt = select(MemoryRow).where(MemoryRow.memory_id == memory_id)
+            if account_key is not None:
+                normalized_account_key = self._normalize_account_key(account_key)
+                stmt = stmt.where(MemoryRow.account_key == normalized_account_key)
+            row = session.execute(stmt).scalar_one_or_none()
+            if row is None:
+                return None
+            row.relationships_json = self._dumps_compact(relationships)
+            row.updated_at = clock.now()
+            session.flush()
+            record = self._row_to_memory(row)
+            # Derived dialect columns (e.g. Postgres metadata_jsonb) must follow the new tags.
+            self._write_derived_columns(session, record)
+            self._journal(session, "updated", record)
+            return record
+
+        return self._execute_write(_update)
+
     def delete_memories(
         self,
         memory_ids: list[str],
@@ -272,14 +358,31 @@ class SQLAlchemyStorageManager:
             return
 
         def _delete(session: Session) -> None:
-            stmt = delete(MemoryRow).where(MemoryRow.memory_id.in_(memory_ids))
+            conditions = [MemoryRow.memory_id.in_(memory_ids)]
             if account_key is not None:
                 normalized_account_key = self._normalize_account_key(account_key)
-                stmt = stmt.where(MemoryRow.account_key == normalized_account_key)
-            session.execute(stmt)
+                conditions.append(MemoryRow.account_key == normalized_account_key)
+            if self._write_journals:
+                for row in session.scalars(select(MemoryRow).where(*conditions)).all():
+                    self._journal(session, "deleted", self._row_to_memory(row))
+            session.execute(delete(MemoryRow).where(*conditions))
 
         self._execute_write(_delete)
 
+    def optimize(self) -> list[str]:
+        dialect = self._engine.dialect.name
+        if dialect == "sqlite":
+            steps = {"vacuum": "VACUUM", "analyze": "ANALYZE"}
+        elif dialect == "postgresql":
+            steps = {"vacuum_analyze": "VACUUM (ANALYZE)"}
+        else:
+            return []
+        # VACUUM cannot run inside a transaction block.
+        with self._engine.connect().execution_options(isolation_level="AUTOCOMMIT") as conn:
+            for statement in steps.values():
+                conn.execute(text(statement))
+        return list(steps)
+
     def close(self) -> None:
         self._engine.dispose()
 
@@ -323,7 +426,15 @@ class SQLAlchemyStorageManager:
             semantic_key=str(row.semantic_key),
             created_at=_to_utc_datetime(row.created_at),
             updated_at=_to_utc_datetime(row.updated_at),
+            occurred_at=(
+                _to_utc_datetime(row.occurred_at) if row.occurred_at is not None else None
+            ),
             retrieval_count=int(row.retrieval_count),
+            last_retrieved_at=(
+                _to_utc_datetime(row.last_retrieved_at)
+                if row.last_retrieved_at is not None
+                else None
+            ),
             avg_outcome_signal=float(row.avg_outcome_signal),
             storage_tier=StorageTier(str(row.storage_tier)),
             latest_importance=float(row.latest_importance),
@@ -331,6 +442,13 @@ class SQLAlchemyStorageManager:
             original_count=int(row.original_count),
         )
 
+    def _write_derived_columns(self, session: Session, record: MemoryRecord) -> None:
+        """Write dialect-specific columns derived from ``record`` on every insert and update."""
+
+    def _journal(self, session: Session, operation: str, record: MemoryRecord) -> None:
+        for journal in self._write_journals:
+            journal(session, operation, record)
+
     def _execute_write(self, operation: Callable[[Session], T]) -> T:
         for attempt in range(self._write_retry_attempts):
             with self._session_factory() as session:
@@ -2,7 +2,17 @@ from __future__ import annotations
 
 import os
 
+from pydantic import Field, field_validator
+
 from decision_engine.config import EngineConfig as CoreEngineConfig
+from memory_engine.pipeline_mode import (
+    normalize_pipeline_mode,
+    parse_namespace_pipeline_modes,
+)
+from memory_engine.storage.quantization import (
+    normalize_quantization_mode,
+    parse_namespace_quantization,
+)
 
 
 class EngineConfig(CoreEngineConfig):
@@ -17,6 +27,49 @@ class EngineConfig(CoreEngineConfig):
     flash_pipeline_workers: int = 1
     flash_pipeline_queue_size: int = 256
     flash_pipeline_maintenance_interval: int = 50
+    # Vector index encoding: none (float32), int8, pq, or binary; namespaces are account keys.
+    vector_quantization: str = "none"
+    vector_quantization_namespaces: dict[str, str] = Field(default_factory=dict)
+    vector_rescore_factor: int = 4
+    vector_pq_train_size: int = 1024
+    # Stage 1/2 pipeline: standard (configured providers) or heuristic (no model calls).
+    pipeline_mode: str = "standard"
+    pipeline_mode_namespaces: dict[str, str] = Field(default_factory=dict)
+    # Salts the heuristic pipeline's hashed-term embedder; 0 leaves it unsalted.
+    embedding_seed: int = 0
+
+    @field_validator("vector_quantization")
+    @classmethod
+    def validate_vector_quantization(cls, value: str) -> str:
+        return normalize_quantization_mode(value)
+
+    @field_validator("vector_quantization_namespaces")
+    @classmethod
+    def validate_vector_quantization_namespaces(cls, value: dict[str, str]) -> dict[str, str]:
+        return {
+            namespace.strip(): normalize_quantization_mode(mode)
+            for namespace, mode in value.items()
+        }
+
+    @field_validator("pipeline_mode")
+    @classmethod
+    def validate_pipeline_mode(cls, value: str) -> str:
+        return normalize_pipeline_mode(value)
+
+    @field_validator("pipeline_mode_namespaces")
+    @classmethod
+    def validate_pipeline_mode_namespaces(cls, value: dict[str, str]) -> dict[str, str]:
+        return {
+            namespace.strip(): normalize_pipeline_mode(mode) for namespace, mode in value.items()
+        }
+
+    @field_validator("vector_rescore_factor", "vector_pq_train_size")
+    @classmethod
+    def validate_positive_vector_tunables(cls, value: int) -> int:
+        if value <= 0:
+            msg = "vector quantization tunables must be positive"
+            raise ValueError(msg)
+        return value
 
     @classmethod
     def from_env(cls) -> EngineConfig:
@@ -25,6 +78,8 @@ class EngineConfig(CoreEngineConfig):
             embedding_dim=base.embedding_dim,
             sqlite_path=base.sqlite_path,
             database_url=base.database_url,
+            storage_backend=base.storage_backend,
+            postgres_text_search_config=base.postgres_text_search_config,
             max_content_chars=base.max_content_chars,
             assistant_max_content_chars=base.assistant_max_content_chars,
             store_raw_embedding=base.store_raw_embedding,
@@ -62,4 +117,15 @@ class EngineConfig(CoreEngineConfig):
             flash_pipeline_maintenance_interval=int(
                 os.getenv("MDE_FLASH_PIPELINE_MAINTENANCE_INTERVAL", "50")
             ),
+            vector_quantization=os.getenv("MDE_VECTOR_QUANTIZATION", "none"),
+            vector_quantization_namespaces=parse_namespace_quantization(
+                os.getenv("MDE_VECTOR_QUANTIZATION_NAMESPACES", "")
+            ),
+            vector_rescore_factor=int(os.getenv("MDE_VECTOR_RESCORE_FACTOR", "4")),
+            vector_pq_train_size=int(os.getenv("MDE_VECTOR_PQ_TRAIN_SIZE", "1024")),
+            pipeline_mode=os.getenv("MDE_PIPELINE_MODE", "standard"),
+            pipeline_mode_namespaces=parse_namespace_pipeline_modes(
+                os.getenv("MDE_PIPELINE_MODE_NAMESPACES", "")
+            ),
+            embedding_seed=int(os.getenv("MDE_EMBEDDING_SEED", "0")),
         )
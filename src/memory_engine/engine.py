@@ -5,9 +5,12 @@ import os
 import queue
 import threading
 import time
-from datetime import UTC, datetime
+from collections.abc import Callable, Sequence
+from datetime import datetime
 from pathlib import Path
+from typing import Any
 
+from decision_engine import clock
 from decision_engine.decay_learner import DecayLearner
 from decision_engine.importance_model import ImportanceModel
 from decision_engine.models import (
@@ -21,6 +24,7 @@ from decision_engine.models import (
 )
 from decision_engine.retrieval_ranker import RetrievalRanker
 from decision_engine.storage_manager import SQLiteStorageManager
+from decision_engine.storage_postgres import PostgresStorageManager
 from decision_engine.storage_protocol import StorageManagerProtocol
 from decision_engine.storage_sqlalchemy import SQLAlchemyStorageManager
 from memory_engine.config import EngineConfig
@@ -34,13 +38,20 @@ from memory_engine.personalization import (
     InferredMemoryCandidate,
 )
 from memory_engine.personalization.decay_policy import compute_inferred_decay_plan
+from memory_engine.providers.heuristic import (
+    HashedTermEmbeddingProvider,
+    HeuristicSemanticProvider,
+)
 from memory_engine.stage1_input.embedding import build_embedding_provider
 from memory_engine.stage1_input.extractors import build_semantic_provider
-from memory_engine.stage1_input.processor import InputProcessor
+from memory_engine.stage1_input.processor import ExtractedEvent, InputProcessor
 from memory_engine.stage2_decision.compression import CompressionPlanner
 from memory_engine.stage2_decision.decay import DecayPolicyAssigner
 from memory_engine.stage2_decision.logic import DecisionLogic
-from memory_engine.stage2_decision.scoring import LearnedRelevanceScorer
+from memory_engine.stage2_decision.scoring import (
+    LearnedRelevanceScorer,
+    TfidfImportanceScorer,
+)
 from memory_engine.stage3_learning.loop import LearningLoop
 from memory_engine.stage3_learning.weight_updater import WeightUpdater
 from memory_engine.storage.retrieval import RetrievalService
@@ -78,6 +89,13 @@ class DecisionEngine:
             provider_name=os.getenv("MDE_SEMANTIC_PROVIDER"),
         )
         self.input_processor = InputProcessor(embedding_provider, semantic_provider)
+        self.heuristic_input_processor = InputProcessor(
+            HashedTermEmbeddingProvider(
+                self.config.embedding_dim,
+                seed=self.config.embedding_seed,
+            ),
+            HeuristicSemanticProvider(),
+        )
 
         self.importance_model = ImportanceModel(
             embedding_dim=self.config.embedding_dim,
@@ -90,7 +108,20 @@ class DecisionEngine:
             training_batch_size=self.config.ranker_training_batch_size,
         )
         self.storage: StorageManagerProtocol
-        if self.config.database_url:
+        backend = self.config.storage_backend
+        if backend == "postgres":
+            if not self.config.database_url:
+                msg = "storage_backend=postgres requires database_url"
+                raise ValueError(msg)
+            self.storage = PostgresStorageManager(
+                self.config.database_url,
+                embedding_dim=self.config.embedding_dim,
+                text_search_config=self.config.postgres_text_search_config,
+                max_content_chars=self.config.max_content_chars,
+                assistant_max_content_chars=self.config.assistant_max_content_chars,
+                store_raw_embedding=self.config.store_raw_embedding,
+            )
+        elif self.config.database_url and backend != "sqlite":
             self.storage = SQLAlchemyStorageManager(
                 self.config.database_url,
                 max_content_chars=self.config.max_content_chars,
@@ -116,6 +147,10 @@ class DecisionEngine:
         self.vector_store = VectorStore(
             embedding_dim=self.config.embedding_dim,
             index_path=vector_index_path,
+            quantization=self.config.vector_quantization,
+            namespace_quantization=self.config.vector_quantization_namespaces,
+            rescore_factor=self.config.vector_rescore_factor,
+            pq_train_size=self.config.vector_pq_train_size,
         )
 
         self.compression_planner = CompressionPlanner(
@@ -130,10 +165,14 @@ class DecisionEngine:
             persistent_threshold=self.config.persistent_confidence_prior,
             ephemeral_threshold=self.config.ephemeral_confidence_prior,
         )
+        # Heuristic namespaces each keep their own TF-IDF document frequencies.
+        self._heuristic_decision_logic: dict[str, DecisionLogic] = {}
+        self._heuristic_lock = threading.Lock()
         self.retrieval_service = RetrievalService(
             storage=self.storage,
             ranker=self.ranker,
             encoder=self.input_processor.encoder,
+            encoder_for=lambda account_key: self.processor_for(account_key).encoder,
             vector_store=self.vector_store,
             assistant_response_max_share=self.config.assistant_response_max_share,
         )
@@ -170,12 +209,49 @@ class DecisionEngine:
         self._flash_workers: list[threading.Thread] = []
         self._flash_lock = threading.RLock()
         self._flash_ops = 0
+        # Sequence numbers of queued flash tasks that have not finished yet.
+        self._flash_pending: set[int] = set()
+        self._flash_sequence = 0
+        self._flash_settled = threading.Condition()
+        self._mutation_listeners: list[Callable[[str, MemoryRecord], None]] = []
         if self._flash_async_enabled:
             self._start_flash_workers()
 
-    def process_input(self, event: Event) -> ProcessedEvent:
+    def process_input(
+        self,
+        event: Event,
+        account_key: str | None = None,
+    ) -> ProcessedEvent:
+        self._metrics["events_received"] += 1
+        return self.processor_for(account_key).process(event)
+
+    def extract_input(
+        self,
+        event: Event,
+        account_key: str | None = None,
+        *,
+        use_provider: bool = True,
+    ) -> ExtractedEvent:
+        """First half of ``process_input``: semantic extraction without embedding."""
+        return self.processor_for(account_key).extract(event, use_provider=use_provider)
+
+    def embed_input(
+        self,
+        extracted: ExtractedEvent,
+        account_key: str | None = None,
+    ) -> ProcessedEvent:
+        """Second half of ``process_input``."""
         self._metrics["events_received"] += 1
-        return self.input_processor.process(event)
+        return self.processor_for(account_key).embed(extracted)
+
+    def embed_inputs(
+        self,
+        extracted: list[ExtractedEvent],
+        account_key: str | None = None,
+    ) -> list[ProcessedEvent]:
+        """``embed_input`` for a batch; bulk imports use it to batch embedding calls."""
+        self._metrics["events_received"] += len(extracted)
+        return self.processor_for(account_key).embed_many(extracted)
 
     def make_storage_decision(
         self,
@@ -183,14 +259,31 @@ class DecisionEngine:
         account_key: str | None = None,
     ) -> StorageDecision:
         snapshot = self._memory_snapshot(processed, account_key=account_key)
-        return self.decision_logic.decide(processed, snapshot)
+        return self._decision_logic_for(account_key).decide(processed, snapshot)
+
+    def provider_health(self) -> dict[str, dict[str, str]]:
+        return self.input_processor.provider_health()
+
+    def pipeline_mode_for(self, account_key: str | None = None) -> str:
+        """Return ``standard`` or ``heuristic`` for the account's namespace."""
+        namespace = self._normalize_account_key(account_key)
+        return self.config.pipeline_mode_namespaces.get(namespace, self.config.pipeline_mode)
+
+    def processor_for(self, account_key: str | None = None) -> InputProcessor:
+        """Stage 1 processor for the account; queries must be encoded with the same one."""
+        if self.pipeline_mode_for(account_key) == "heuristic":
+            return self.heuristic_input_processor
+        return self.input_processor
 
     def store_memory(
         self,
         processed: ProcessedEvent,
         decision: StorageDecision,
         account_key: str | None = None,
+        *,
+        consolidate: bool = True,
     ) -> MemoryRecord | None:
+        """Store ``processed``; ``consolidate=False`` leaves the flash pipeline to the caller."""
         if not decision.store:
             self._metrics["events_discarded"] += 1
             return None
@@ -204,15 +297,43 @@ class DecisionEngine:
         )
         self._register_stored_memory(stored)
         self._metrics["events_stored"] += 1
-        self._run_flash_pipeline(
-            processed=processed,
-            stored=stored,
-            should_compress=decision.should_compress,
-            account_key=account_key,
-        )
+        if consolidate:
+            self._run_flash_pipeline(
+                processed=processed,
+                stored=stored,
+                should_compress=decision.should_compress,
+                account_key=account_key,
+            )
         self._schedule_metrics_flush()
         return stored
 
+    def consolidate(
+        self,
+        stored: Sequence[tuple[ProcessedEvent, MemoryRecord, bool]],
+        account_key: str | None = None,
+    ) -> None:
+        """Run the flash pipeline deferred by ``store_memory(consolidate=False)``.
+
+        ``stored`` holds ``(processed, memory, should_compress)`` per write. Personalization
+        observes every memory, but compression runs once per entity and event type, on the
+        latest write, rather than after each one.
+        """
+        latest: dict[tuple[str, str], ProcessedEvent] = {}
+        with self._flash_lock:
+            self._run_personalization_lifecycle(account_key=account_key)
+            for processed, memory, should_compress in stored:
+                inferred = self.personalization.observe_memory(
+                    memory,
+                    account_key=account_key,
+                    source_text=processed.description,
+                )
+                self._store_inferred_candidates(inferred, account_key=account_key)
+                if should_compress:
+                    latest[(processed.entity_id, processed.event_type)] = processed
+            for processed in latest.values():
+                self._maybe_compress_cluster(processed, account_key=account_key)
+            self._run_flash_maintenance(account_key=account_key)
+
     def retrieve(
         self,
         query: str,
@@ -256,7 +377,9 @@ class DecisionEngine:
             helpful_memory_ids=helpful_memory_ids,
             outcome_signal=outcome_signal,
         )
-        query_embedding = self.input_processor.encoder.encode_query(query).tolist()
+        query_embedding = (
+            self.processor_for(account_key).encoder.encode_query(query).tolist()
+        )
         self._metrics["feedback_events"] += 1
         result = self.learning_loop.record_feedback(
             feedback,
@@ -304,6 +427,182 @@ class DecisionEngine:
         scope_key = self._entity_scope_key(account_key=account_key, entity_id=entity_id)
         return sorted(self._entity_memory_ids.get(scope_key, set()))
 
+    def delete_memories(
+        self,
+        memory_ids: list[str],
+        account_key: str | None = None,
+    ) -> list[MemoryRecord]:
+        """Remove memories from storage and the vector index; returns those that existed."""
+        return self._delete_memories(memory_ids, account_key=account_key)
+
+    def update_memory(
+        self,
+        memory_id: str,
+        content: str,
+        account_key: str | None = None,
+    ) -> MemoryRecord:
+        """Replace a memory's content in place and re-encode it.
+
+        The id, entities, relationships, tier, and feedback statistics are kept.
+        """
+        existing = self.storage.fetch_by_ids([memory_id], account_key=account_key)
+        if not existing:
+            msg = f"memory not found: {memory_id}"
+            raise KeyError(msg)
+        current = existing[0]
+        processed = self.processor_for(current.account_key).process(
+            Event(
+                entity_id=current.entities[0] if current.entities else "",
+                event_type=current.intent,
+                description=content,
+                metadata={
+                    "intent": current.intent,
+                    "entities": current.entities[1:],
+                    "relationships": current.relationships,
+                },
+            )
+        )
+        encoded = self.input_processor.to_encoded_event(processed)
+        updated = self.storage.update_content(
+            memory_id,
+            content=encoded.event.content,
+            summary=encoded.understanding.summary,
+            raw_embedding=encoded.raw_embedding,
+            semantic_embedding=encoded.semantic_embedding,
+            semantic_key=encoded.semantic_key,
+            account_key=current.account_key,
+        )
+        if updated is None:
+            msg = f"memory not found: {memory_id}"
+            raise KeyError(msg)
+        self.vector_store.remove_many([memory_id])
+        self.vector_store.add(
+            memory_id,
+            updated.semantic_embedding,
+            namespace=self._normalize_account_key(updated.account_key),
+        )
+        self._notify_mutation("updated", updated)
+        return updated
+
+    def update_relationships(
+        self,
+        memory_id: str,
+        relationships: list[str],
+        account_key: str | None = None,
+    ) -> MemoryRecord:
+        """Replace a memory's relationships; its content and embeddings are untouched."""
+        updated = self.storage.update_relationships(
+            memory_id,
+            relationships,
+            account_key=account_key,
+        )
+        if updated is None:
+            msg = f"memory not found: {memory_id}"
+            raise KeyError(msg)
+        self._notify_mutation("updated", updated)
+        return updated
+
+    def import_memory(
+        self,
+        memory_id: str,
+        *,
+        content: str,
+        intent: str,
+        entities: list[str],
+        relationships: list[str],
+        storage_tier: str,
+        account_key: str | None = None,
+    ) -> MemoryRecord:
+        """Store a memory created in another region under its original id.
+
+        The content is re-encoded locally, but the storage decision and flash pipeline are
+        skipped: compressed and inferred memories arrive as their own replicated changes.
+        """
+        processed = self.processor_for(account_key).process(
+            Event(
+                entity_id=entities[0] if entities else "",
+                event_type=intent,
+                description=content,
+                metadata={
+                    "intent": intent,
+                    "entities": entities[1:],
+                    "relationships": relationships,
+                },
+            )
+        )
+        encoded = self.input_processor.to_encoded_event(processed)
+        stored = self.storage.store(
+            encoded,
+            CoreStorageDecision(
+                should_store=True,
+                tier=self._tier_from_string(storage_tier),
+                confidence=1.0,
+                rationale="replicated",
+                trace={},
+            ),
+            account_key=self._normalize_account_key(account_key),
+            memory_id=memory_id,
+        )
+        self.vector_store.add(
+            stored.memory_id,
+            stored.semantic_embedding,
+            namespace=self._normalize_account_key(stored.account_key),
+        )
+        self._register_stored_memory(stored)
+        self._notify_mutation("created", stored)
+        return stored
+
+    def add_mutation_listener(
+        self,
+        listener: Callable[[str, MemoryRecord], None],
+    ) -> None:
+        """Register a callback invoked with ("created" | "updated" | "deleted", memory)."""
+        self._mutation_listeners.append(listener)
+
+    def optimize(
+        self,
+        on_step: Callable[[str], None] | None = None,
+    ) -> dict[str, dict[str, Any]]:
+        """Compact the vector index against storage, persist it, then run storage maintenance.
+
+        ``on_step`` is called with each step name before the step starts.
+        """
+        report: dict[str, dict[str, Any]] = {}
+
+        if on_step is not None:
+            on_step("vector_compaction")
+        # Snapshot indexed ids before reading storage so memories written mid-run are kept.
+        indexed_ids = self.vector_store.memory_ids()
+        records = self.storage.list_memories()
+        live_ids = {record.memory_id for record in records}
+        tombstoned = sorted(indexed_ids - live_ids)
+        if tombstoned:
+            self.vector_store.remove_many(tombstoned)
+        missing = [record for record in records if record.memory_id not in indexed_ids]
+        for record in missing:
+            self.vector_store.add(
+                record.memory_id,
+                record.semantic_embedding,
+                namespace=self._normalize_account_key(record.account_key),
+            )
+        stale_rows = self.vector_store.compact()
+        report["vector_compaction"] = {
+            "live_vectors": len(live_ids),
+            "tombstones_removed": len(tombstoned) + stale_rows,
+            "missing_added": len(missing),
+        }
+
+        if on_step is not None:
+            on_step("vector_index_save")
+        if self._persist_vector_index:
+            self.vector_store.save()
+        report["vector_index_save"] = {"persisted": self._persist_vector_index}
+
+        if on_step is not None:
+            on_step("storage_maintenance")
+        report["storage_maintenance"] = {"statements": self.storage.optimize()}
+        return report
+
     def close(self) -> None:
         self._stop_flash_workers()
         self._write_metrics()
@@ -378,11 +677,12 @@ class DecisionEngine:
                 task_name, args = task_queue.get(timeout=0.2)
             except queue.Empty:
                 continue
+            sequence = args[0] if task_name == "ingest" else None
             try:
                 if task_name == "stop":
                     break
                 if task_name == "ingest":
-                    self._run_flash_pipeline_sync(*args)
+                    self._run_flash_pipeline_sync(*args[1:])  # type: ignore[arg-type]
             except Exception as exc:  # pragma: no cover - safety net
                 self._metrics["flash_pipeline_failures"] = (
                     self._metrics.get("flash_pipeline_failures", 0.0) + 1.0
@@ -392,8 +692,28 @@ class DecisionEngine:
                     error=str(exc),
                 )
             finally:
+                if isinstance(sequence, int):
+                    self._mark_flash_task_done(sequence)
                 task_queue.task_done()
 
+    def _mark_flash_task_done(self, sequence: int) -> None:
+        with self._flash_settled:
+            self._flash_pending.discard(sequence)
+            self._flash_settled.notify_all()
+
+    def wait_for_flash_pipeline(self, timeout_seconds: float) -> bool:
+        """Block until flash work queued before this call has finished.
+
+        Returns ``False`` if it is still running after ``timeout_seconds``. Always ``True`` in
+        sync mode, where the pipeline finishes before ``store_memory`` returns.
+        """
+        with self._flash_settled:
+            target = self._flash_sequence
+            return self._flash_settled.wait_for(
+                lambda: not any(sequence <= target for sequence in self._flash_pending),
+                timeout=timeout_seconds,
+            )
+
     def _run_flash_pipeline(
         self,
         *,
@@ -412,14 +732,19 @@ class DecisionEngine:
             return
         if self._flash_queue is None:
             return
+        with self._flash_settled:
+            self._flash_sequence += 1
+            sequence = self._flash_sequence
+            self._flash_pending.add(sequence)
         try:
             self._flash_queue.put_nowait(
-                ("ingest", (processed, stored, should_compress, account_key))
+                ("ingest", (sequence, processed, stored, should_compress, account_key))
             )
             self._metrics["flash_pipeline_enqueued"] = (
                 self._metrics.get("flash_pipeline_enqueued", 0.0) + 1.0
             )
         except queue.Full:
+            self._mark_flash_task_done(sequence)
             self._metrics["flash_pipeline_dropped"] = (
                 self._metrics.get("flash_pipeline_dropped", 0.0) + 1.0
             )
@@ -491,7 +816,12 @@ class DecisionEngine:
             core_decision,
             account_key=normalized_account_key,
         )
-        self.vector_store.add(stored.memory_id, stored.semantic_embedding)
+        self.vector_store.add(
+            stored.memory_id,
+            stored.semantic_embedding,
+            namespace=normalized_account_key,
+        )
+        self._notify_mutation("created", stored)
         return stored
 
     def _maybe_compress_cluster(
@@ -525,7 +855,7 @@ class DecisionEngine:
                 "compressed_original_count": plan.original_count,
             },
         )
-        compressed_processed = self.input_processor.process(compressed_event)
+        compressed_processed = self.processor_for(account_key).process(compressed_event)
         try:
             confidence_seed = float(processed.context.get("importance", 0.8))
         except (TypeError, ValueError):
@@ -577,7 +907,7 @@ class DecisionEngine:
             total_memories=self._total_memories,
             entity_reference_count=entity_reference_count,
             similar_recent_count=similar_recent_count,
-            generated_at=datetime.now(UTC),
+            generated_at=clock.now(),
             metadata={"event_type": processed.event_type},
         )
 
@@ -624,13 +954,13 @@ class DecisionEngine:
         metadata.setdefault("entities", [candidate.entity_id])
         metadata.setdefault("inferred", True)
         event = Event(
-            timestamp=datetime.now(UTC),
+            timestamp=clock.now(),
             entity_id=candidate.entity_id,
             event_type=candidate.event_type,
             description=candidate.content,
             metadata=metadata,
         )
-        processed = self.input_processor.process(event)
+        processed = self.processor_for(account_key).process(event)
         decay_plan = compute_inferred_decay_plan(candidate)
         inferred_decision = StorageDecision(
             store=True,
@@ -678,11 +1008,16 @@ class DecisionEngine:
         self.vector_store.remove_many(existing_ids)
         for memory in existing:
             self._unregister_stored_memory(memory)
+            self._notify_mutation("deleted", memory)
         self.personalization.notify_memories_deleted(existing)
         return existing
 
+    def _notify_mutation(self, operation: str, memory: MemoryRecord) -> None:
+        for listener in self._mutation_listeners:
+            listener(operation, memory)
+
     def _run_personalization_lifecycle(self, account_key: str | None = None) -> None:
-        now = datetime.now(UTC)
+        now = clock.now()
         if (
             self._lifecycle_scan_interval_seconds > 0
             and self._last_lifecycle_scan_at is not None
@@ -710,7 +1045,7 @@ class DecisionEngine:
 
     def _write_metrics(self) -> None:
         payload = {
-            "generated_at": datetime.now(UTC).isoformat(),
+            "generated_at": clock.now().isoformat(),
             "metrics": self._metrics,
             "storage_ratio": self._safe_ratio(
                 self._metrics["events_stored"], self._metrics["events_received"]
@@ -748,7 +1083,11 @@ class DecisionEngine:
 
     def _warm_cache_from_storage(self) -> None:
         for record in self.storage.list_memories():
-            self.vector_store.add(record.memory_id, record.semantic_embedding)
+            self.vector_store.add(
+                record.memory_id,
+                record.semantic_embedding,
+                namespace=self._normalize_account_key(record.account_key),
+            )
             self._register_stored_memory(record)
 
     def _register_stored_memory(self, memory: MemoryRecord) -> None:
@@ -816,6 +1155,23 @@ class DecisionEngine:
         self._recent_key_timestamps[key] = filtered
         return len(filtered)
 
+    def _decision_logic_for(self, account_key: str | None) -> DecisionLogic:
+        if self.pipeline_mode_for(account_key) != "heuristic":
+            return self.decision_logic
+        namespace = self._normalize_account_key(account_key)
+        with self._heuristic_lock:
+            logic = self._heuristic_decision_logic.get(namespace)
+            if logic is None:
+                logic = DecisionLogic(
+                    scorer=TfidfImportanceScorer(),
+                    decay_assigner=DecayPolicyAssigner(self.decay_learner),
+                    compression_planner=self.compression_planner,
+                    persistent_threshold=self.config.persistent_confidence_prior,
+                    ephemeral_threshold=self.config.ephemeral_confidence_prior,
+                )
+                self._heuristic_decision_logic[namespace] = logic
+        return logic
+
     @staticmethod
     def _normalize_account_key(account_key: str | None) -> str:
         if account_key is None:
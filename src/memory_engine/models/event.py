@@ -5,11 +5,13 @@ from typing import Any
 
 from pydantic import BaseModel, Field, field_validator
 
+from decision_engine import clock
+
 
 class Event(BaseModel):
     """External event shape accepted by the stage-based API."""
 
-    timestamp: int | datetime = Field(default_factory=lambda: datetime.now(UTC))
+    timestamp: int | datetime = Field(default_factory=clock.now)
     entity_id: str
     event_type: str
     description: str
@@ -3,12 +3,13 @@ from __future__ import annotations
 import re
 import threading
 from dataclasses import dataclass, field
-from datetime import UTC, datetime, timedelta
+from datetime import datetime, timedelta
 from typing import Any
 
 import numpy as np
 from numpy.typing import NDArray
 
+from decision_engine import clock
 from decision_engine.math_utils import cosine_similarity
 from decision_engine.models import MemoryRecord
 from decision_engine.storage_protocol import StorageManagerProtocol
@@ -334,7 +335,7 @@ class AdaptivePersonalizationEngine:
         account_key: str | None = None,
     ) -> InferredMemoryCandidate | None:
         since_iso = (
-            datetime.now(UTC) - timedelta(days=self._window_days)
+            clock.now() - timedelta(days=self._window_days)
         ).isoformat()
         history = self._storage.fetch_by_entity_and_intent(
             entity_id=entity_id,
@@ -829,7 +830,7 @@ class AdaptivePersonalizationEngine:
         intents: set[str],
         account_key: str | None = None,
     ) -> list[MemoryRecord]:
-        since = datetime.now(UTC) - timedelta(days=self._window_days)
+        since = clock.now() - timedelta(days=self._window_days)
         filtered: list[MemoryRecord] = []
         for memory in self._storage.list_memories(account_key=account_key):
             if entity_id not in memory.entities:
@@ -851,7 +852,7 @@ class AdaptivePersonalizationEngine:
         topic_summary: str,
         account_key: str | None = None,
     ) -> _SignatureReservation | None:
-        now = datetime.now(UTC)
+        now = clock.now()
         refresh_window = timedelta(days=self._inferred_refresh_days)
         signature = self._signature(
             entity_id=entity_id,
@@ -910,7 +911,7 @@ class AdaptivePersonalizationEngine:
     ) -> list[str]:
         if not self._enabled:
             return []
-        cutoff = datetime.now(UTC) - timedelta(days=self._inferred_ttl_days)
+        cutoff = clock.now() - timedelta(days=self._inferred_ttl_days)
         expired: list[str] = []
         for memory in self._storage.list_memories(account_key=account_key):
             if not self._is_inferred_memory(memory):
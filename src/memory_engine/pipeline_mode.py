@@ -0,0 +1,28 @@
+from __future__ import annotations
+
+# ``standard`` uses the configured embedding and semantic providers and the learned importance
+# model. ``heuristic`` swaps in the deterministic providers of
+# ``memory_engine.providers.heuristic`` and TF-IDF importance scoring, and never calls a model.
+PIPELINE_MODES = ("standard", "heuristic")
+
+
+def normalize_pipeline_mode(value: str) -> str:
+    normalized = value.strip().lower() or "standard"
+    if normalized not in PIPELINE_MODES:
+        msg = f"pipeline mode must be one of: {', '.join(PIPELINE_MODES)}"
+        raise ValueError(msg)
+    return normalized
+
+
+def parse_namespace_pipeline_modes(value: str) -> dict[str, str]:
+    """Parse ``acme=heuristic,bigco=standard`` into a namespace -> mode mapping."""
+    modes: dict[str, str] = {}
+    for item in value.split(","):
+        if not item.strip():
+            continue
+        namespace, separator, mode = item.partition("=")
+        if not separator or not namespace.strip():
+            msg = f"invalid pipeline mode entry: {item.strip()!r}"
+            raise ValueError(msg)
+        modes[namespace.strip()] = normalize_pipeline_mode(mode)
+    return modes
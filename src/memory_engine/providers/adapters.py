@@ -4,6 +4,10 @@ from __future__ import annotations
 
 import json
 import os
+import threading
+import time
+from collections.abc import Callable
+from dataclasses import dataclass
 from importlib import import_module
 from types import ModuleType
 from typing import Any
@@ -14,6 +18,7 @@ from numpy.typing import NDArray
 from decision_engine.math_utils import to_unit_vector
 from decision_engine.models import RawEvent, SemanticUnderstanding
 from decision_engine.semantic_encoding import EmbeddingProvider, SemanticProvider
+from orbit.secret_sources import get_secret
 
 FloatArray = NDArray[np.float32]
 
@@ -101,7 +106,7 @@ class AnthropicSemanticProvider(
             msg = "anthropic.Anthropic client class is unavailable."
             raise RuntimeError(msg)
         self._client: Any = client_cls(
-            api_key=api_key or os.getenv("ANTHROPIC_API_KEY")
+            api_key=api_key or get_secret("ANTHROPIC_API_KEY")
         )
         self._model = model or os.getenv(
             "MDE_ANTHROPIC_MODEL", "claude-3-5-sonnet-latest"
@@ -147,7 +152,7 @@ class AnthropicEmbeddingProvider(
         if client_cls is None:
             msg = "anthropic.Anthropic client class is unavailable."
             raise RuntimeError(msg)
-        resolved_key = api_key or os.getenv("ANTHROPIC_API_KEY")
+        resolved_key = api_key or get_secret("ANTHROPIC_API_KEY")
         if not resolved_key:
             msg = "ANTHROPIC_API_KEY is required for Anthropic embedding provider."
             raise RuntimeError(msg)
@@ -199,7 +204,7 @@ class GeminiSemanticProvider(
         if client_cls is None:
             msg = "google.genai.Client class is unavailable."
             raise RuntimeError(msg)
-        self._client: Any = client_cls(api_key=api_key or os.getenv("GEMINI_API_KEY"))
+        self._client: Any = client_cls(api_key=api_key or get_secret("GEMINI_API_KEY"))
         self._model = model or os.getenv(
             "MDE_GEMINI_SEMANTIC_MODEL", "gemini-2.5-flash"
         )
@@ -233,7 +238,7 @@ class GeminiEmbeddingProvider(
         if client_cls is None:
             msg = "google.genai.Client class is unavailable."
             raise RuntimeError(msg)
-        self._client: Any = client_cls(api_key=api_key or os.getenv("GEMINI_API_KEY"))
+        self._client: Any = client_cls(api_key=api_key or get_secret("GEMINI_API_KEY"))
         self._model = model or os.getenv(
             "MDE_GEMINI_EMBEDDING_MODEL", "text-embedding-004"
         )
@@ -261,41 +266,231 @@ class GeminiEmbeddingProvider(
         raise RuntimeError(msg)
 
 
+_OLLAMA_HEALTH_TTL_SECONDS = 30.0
+_ollama_semaphores: dict[str, threading.BoundedSemaphore] = {}
+_ollama_semaphores_lock = threading.Lock()
+
+
+def _env_flag(name: str, default: bool) -> bool:
+    value = os.getenv(name)
+    if value is None:
+        return default
+    return value.strip().lower() in {"true", "1", "yes", "on"}
+
+
+def _ollama_client(host: str | None) -> tuple[Any, str]:
+    if ollama_module is None:
+        msg = "ollama package is not installed. Install optional dependency 'ollama'."
+        raise RuntimeError(msg)
+    client_cls = getattr(ollama_module, "Client", None)
+    if client_cls is None:
+        msg = "ollama.Client class is unavailable."
+        raise RuntimeError(msg)
+    resolved_host = host or os.getenv("MDE_OLLAMA_HOST") or "http://localhost:11434"
+    api_key = get_secret("MDE_OLLAMA_API_KEY") or get_secret("OLLAMA_API_KEY")
+    client_kwargs: dict[str, Any] = {"host": resolved_host}
+    if api_key:
+        client_kwargs["headers"] = {"Authorization": f"Bearer {api_key}"}
+    return client_cls(**client_kwargs), resolved_host
+
+
+def _ollama_semaphore(host: str, max_concurrency: int | None) -> threading.BoundedSemaphore:
+    """One limit per Ollama host, shared by its embedding and semantic providers."""
+    with _ollama_semaphores_lock:
+        semaphore = _ollama_semaphores.get(host)
+        if semaphore is None:
+            limit = max_concurrency or int(os.getenv("MDE_OLLAMA_MAX_CONCURRENCY", "4"))
+            semaphore = threading.BoundedSemaphore(max(1, limit))
+            _ollama_semaphores[host] = semaphore
+        return semaphore
+
+
+def _response_field(payload: Any, key: str) -> Any:
+    # The ollama client returns dicts before 0.4 and subscriptable models after.
+    if isinstance(payload, dict):
+        return payload.get(key)
+    return getattr(payload, key, None)
+
+
+def _ollama_has_model(client: Any, model: str) -> bool:
+    listed = _response_field(client.list(), "models") or []
+    names = {
+        str(_response_field(item, "model") or _response_field(item, "name") or "")
+        for item in listed
+    }
+    if ":" in model:
+        return model in names
+    return any(name == model or name.split(":", 1)[0] == model for name in names)
+
+
+class _OllamaModel:
+    """Health and concurrency plumbing shared by the Ollama providers."""
+
+    def __init__(
+        self,
+        model: str,
+        host: str | None,
+        max_concurrency: int | None,
+        health_check: bool | None,
+    ) -> None:
+        self.client, self.host = _ollama_client(host)
+        self.model = model
+        self.semaphore = _ollama_semaphore(self.host, max_concurrency)
+        self._health: dict[str, str] | None = None
+        self._health_checked_at = 0.0
+        self._health_lock = threading.Lock()
+        if health_check is None:
+            health_check = _env_flag("MDE_OLLAMA_HEALTH_CHECK", True)
+        if health_check:
+            status = self.check_health(force=True)
+            if status["status"] != "ok":
+                msg = f"Ollama model {model!r} is not ready at {self.host}: {status['detail']}"
+                raise RuntimeError(msg)
+
+    def check_health(self, force: bool = False) -> dict[str, str]:
+        """Report whether the host answers and has the model pulled; cached for 30 seconds."""
+        with self._health_lock:
+            now = time.monotonic()
+            if (
+                not force
+                and self._health is not None
+                and now - self._health_checked_at < _OLLAMA_HEALTH_TTL_SECONDS
+            ):
+                return self._health
+            status = {"status": "ok", "provider": "ollama", "model": self.model}
+            try:
+                with self.semaphore:
+                    available = _ollama_has_model(self.client, self.model)
+            except Exception as exc:  # pylint: disable=broad-exception-caught
+                status.update(status="error", detail=f"host unreachable: {exc}")
+            else:
+                if not available:
+                    status.update(
+                        status="error",
+                        detail=f"model not pulled; run `ollama pull {self.model}`",
+                    )
+            self._health, self._health_checked_at = status, now
+            return status
+
+
+@dataclass
+class _PendingEmbedding:
+    text: str
+    taken: bool = False
+    done: bool = False
+    vector: FloatArray | None = None
+    error: BaseException | None = None
+
+
+class _EmbeddingBatcher:
+    """Coalesce concurrent ``embed`` calls into one request.
+
+    The first waiting caller leads: it holds the batch open for ``window_seconds`` (or until
+    ``max_size`` texts are queued), sends it, and hands every caller its own vector. Callers
+    left over from a full batch elect the next leader.
+    """
+
+    def __init__(
+        self,
+        send: Callable[[list[str]], list[FloatArray]],
+        max_size: int,
+        window_seconds: float,
+    ) -> None:
+        self._send = send
+        self._max_size = max(1, max_size)
+        self._window_seconds = max(0.0, window_seconds)
+        self._pending: list[_PendingEmbedding] = []
+        self._leading = False
+        self._condition = threading.Condition()
+
+    def submit(self, text: str) -> FloatArray:
+        item = _PendingEmbedding(text)
+        with self._condition:
+            self._pending.append(item)
+            self._condition.notify_all()
+        while True:
+            with self._condition:
+                while not item.done and (item.taken or self._leading):
+                    self._condition.wait()
+                if item.done:
+                    break
+                self._leading = True
+                deadline = time.monotonic() + self._window_seconds
+                while len(self._pending) < self._max_size:
+                    remaining = deadline - time.monotonic()
+                    if remaining <= 0:
+                        break
+                    self._condition.wait(remaining)
+                batch = self._pending[: self._max_size]
+                del self._pending[: self._max_size]
+                for pending in batch:
+                    pending.taken = True
+                self._leading = False
+                self._condition.notify_all()
+            self._flush(batch)
+        if item.error is not None:
+            raise item.error
+        return item.vector if item.vector is not None else np.zeros(0, dtype=np.float32)
+
+    def _flush(self, batch: list[_PendingEmbedding]) -> None:
+        try:
+            vectors = self._send([pending.text for pending in batch])
+            if len(vectors) != len(batch):
+                msg = f"Ollama returned {len(vectors)} embeddings for {len(batch)} inputs."
+                raise RuntimeError(msg)
+        except Exception as exc:  # pylint: disable=broad-exception-caught
+            with self._condition:
+                for pending in batch:
+                    pending.error, pending.done = exc, True
+                self._condition.notify_all()
+            return
+        with self._condition:
+            for pending, vector in zip(batch, vectors, strict=True):
+                pending.vector, pending.done = vector, True
+            self._condition.notify_all()
+
+
 class OllamaSemanticProvider(
     SemanticProvider
 ):  # pragma: no cover - optional dependency
-    """Ollama semantic understanding adapter."""
+    """Ollama semantic understanding adapter.
 
-    def __init__(self, model: str | None = None, host: str | None = None) -> None:
-        if ollama_module is None:
-            msg = (
-                "ollama package is not installed. Install optional dependency 'ollama'."
-            )
-            raise RuntimeError(msg)
-        client_cls = getattr(ollama_module, "Client", None)
-        if client_cls is None:
-            msg = "ollama.Client class is unavailable."
-            raise RuntimeError(msg)
-        resolved_host = host or os.getenv("MDE_OLLAMA_HOST")
-        api_key = os.getenv("MDE_OLLAMA_API_KEY") or os.getenv("OLLAMA_API_KEY")
-        client_kwargs: dict[str, Any] = {"host": resolved_host}
-        if api_key:
-            client_kwargs["headers"] = {"Authorization": f"Bearer {api_key}"}
-        self._client: Any = client_cls(**client_kwargs)
-        self._model = model or os.getenv("MDE_OLLAMA_SEMANTIC_MODEL", "llama3.1")
+    The model is checked at startup (``MDE_OLLAMA_HEALTH_CHECK``) and requests share the
+    host's ``MDE_OLLAMA_MAX_CONCURRENCY`` limit with the embedding provider.
+    """
+
+    def __init__(
+        self,
+        model: str | None = None,
+        host: str | None = None,
+        max_concurrency: int | None = None,
+        health_check: bool | None = None,
+    ) -> None:
+        self._ollama = _OllamaModel(
+            model=model or os.getenv("MDE_OLLAMA_SEMANTIC_MODEL", "llama3.1"),
+            host=host,
+            max_concurrency=max_concurrency,
+            health_check=health_check,
+        )
+        self._client: Any = self._ollama.client
+        self._model = self._ollama.model
+
+    def check_health(self) -> dict[str, str]:
+        return self._ollama.check_health()
 
     def understand(self, event: RawEvent) -> SemanticUnderstanding:
         system_prompt, user_prompt = _semantic_prompts(event)
-        response = self._client.chat(
-            model=self._model,
-            messages=[
-                {"role": "system", "content": system_prompt},
-                {"role": "user", "content": user_prompt},
-            ],
-            format="json",
-        )
-        message = response.get("message", {})
-        output_text = str(message.get("content", ""))
+        with self._ollama.semaphore:
+            response = self._client.chat(
+                model=self._model,
+                messages=[
+                    {"role": "system", "content": system_prompt},
+                    {"role": "user", "content": user_prompt},
+                ],
+                format="json",
+            )
+        message = _response_field(response, "message") or {}
+        output_text = str(_response_field(message, "content") or "")
         payload = _parse_json_object(output_text)
         return _semantic_from_payload(payload, event.content)
 
@@ -303,49 +498,79 @@ class OllamaSemanticProvider(
 class OllamaEmbeddingProvider(
     EmbeddingProvider
 ):  # pragma: no cover - optional dependency
-    """Ollama embedding adapter."""
+    """Ollama embedding adapter.
+
+    Concurrent ``embed`` calls are batched into single ``/api/embed`` requests of up to
+    ``MDE_OLLAMA_BATCH_SIZE`` texts, gathered for at most ``MDE_OLLAMA_BATCH_WINDOW_MS``.
+    """
 
     def __init__(
         self,
         model: str | None = None,
         host: str | None = None,
         dimensions: int | None = None,
+        max_concurrency: int | None = None,
+        batch_size: int | None = None,
+        batch_window_ms: float | None = None,
+        health_check: bool | None = None,
     ) -> None:
-        if ollama_module is None:
-            msg = (
-                "ollama package is not installed. Install optional dependency 'ollama'."
-            )
-            raise RuntimeError(msg)
-        client_cls = getattr(ollama_module, "Client", None)
-        if client_cls is None:
-            msg = "ollama.Client class is unavailable."
-            raise RuntimeError(msg)
-        resolved_host = host or os.getenv("MDE_OLLAMA_HOST")
-        api_key = os.getenv("MDE_OLLAMA_API_KEY") or os.getenv("OLLAMA_API_KEY")
-        client_kwargs: dict[str, Any] = {"host": resolved_host}
-        if api_key:
-            client_kwargs["headers"] = {"Authorization": f"Bearer {api_key}"}
-        self._client: Any = client_cls(**client_kwargs)
-        self._model = model or os.getenv(
-            "MDE_OLLAMA_EMBEDDING_MODEL", "nomic-embed-text"
+        self._ollama = _OllamaModel(
+            model=model or os.getenv("MDE_OLLAMA_EMBEDDING_MODEL", "nomic-embed-text"),
+            host=host,
+            max_concurrency=max_concurrency,
+            health_check=health_check,
         )
+        self._client: Any = self._ollama.client
+        self._model = self._ollama.model
         self._dimensions = dimensions
+        self._batch_size = max(
+            1, batch_size or int(os.getenv("MDE_OLLAMA_BATCH_SIZE", "32"))
+        )
+        window_ms = (
+            batch_window_ms
+            if batch_window_ms is not None
+            else float(os.getenv("MDE_OLLAMA_BATCH_WINDOW_MS", "5"))
+        )
+        self._batcher = _EmbeddingBatcher(
+            self._embed_batch, max_size=self._batch_size, window_seconds=window_ms / 1000.0
+        )
 
-    def embed(self, text: str) -> FloatArray:
-        if hasattr(self._client, "embeddings"):
-            response = self._client.embeddings(model=self._model, prompt=text)
-            values = response.get("embedding")
-        elif hasattr(self._client, "embed"):
-            response = self._client.embed(model=self._model, input=text)
-            embeddings = response.get("embeddings", [])
-            values = embeddings[0] if embeddings else None
-        else:
-            msg = "No embedding method available on Ollama client."
-            raise RuntimeError(msg)
+    def check_health(self) -> dict[str, str]:
+        return self._ollama.check_health()
 
-        if values is None:
+    def embed(self, text: str) -> FloatArray:
+        return self._batcher.submit(text)
+
+    def embed_many(self, texts: list[str]) -> list[FloatArray]:
+        vectors: list[FloatArray] = []
+        for start in range(0, len(texts), self._batch_size):
+            vectors.extend(self._embed_batch(texts[start : start + self._batch_size]))
+        return vectors
+
+    def _embed_batch(self, texts: list[str]) -> list[FloatArray]:
+        with self._ollama.semaphore:
+            if hasattr(self._client, "embed"):
+                response = self._client.embed(model=self._model, input=texts)
+                rows = list(_response_field(response, "embeddings") or [])
+            elif hasattr(self._client, "embeddings"):
+                # Clients older than 0.3 only embed one prompt per request.
+                rows = [
+                    _response_field(
+                        self._client.embeddings(model=self._model, prompt=text), "embedding"
+                    )
+                    for text in texts
+                ]
+            else:
+                msg = "No embedding method available on Ollama client."
+                raise RuntimeError(msg)
+        if len(rows) != len(texts) or any(values is None for values in rows):
             msg = "Unable to parse Ollama embedding response."
             raise RuntimeError(msg)
-        vector = np.asarray(values, dtype=np.float32)
-        vector = _coerce_embedding_dimensions(vector, self._dimensions)
-        return to_unit_vector(vector)
+        return [
+            to_unit_vector(
+                _coerce_embedding_dimensions(
+                    np.asarray(values, dtype=np.float32), self._dimensions
+                )
+            )
+            for values in rows
+        ]
@@ -0,0 +1,134 @@
+"""Language-model-free providers for the ``heuristic`` pipeline mode.
+
+Everything here is deterministic and in-process: no network calls, no model weights, and the
+same input always yields the same output.
+"""
+
+from __future__ import annotations
+
+import hashlib
+import math
+import re
+from collections import Counter
+
+import numpy as np
+
+from decision_engine.math_utils import to_unit_vector
+from decision_engine.models import RawEvent, SemanticUnderstanding
+from decision_engine.semantic_encoding import FloatArray
+
+_TOKEN = re.compile(r"[a-z0-9][a-z0-9_'-]*")
+_STOPWORDS = frozenset(
+    {
+        "a", "about", "after", "all", "also", "am", "an", "and", "any", "are", "as", "at", "be",
+        "because", "been", "but", "by", "can", "could", "did", "do", "does", "for", "from",
+        "had", "has", "have", "he", "her", "here", "him", "his", "how", "i", "if", "in", "into",
+        "is", "it", "its", "just", "me", "more", "my", "no", "not", "now", "of", "on", "or",
+        "our", "out", "she", "should", "so", "some", "than", "that", "the", "their", "them",
+        "then", "there", "these", "they", "this", "to", "too", "up", "us", "very", "was", "we",
+        "were", "what", "when", "where", "which", "who", "why", "will", "with", "would", "you",
+        "your",
+    }
+)  # fmt: skip
+
+# Entity patterns, most specific first so an email is not also read as a mention.
+_EMAIL = re.compile(r"\b[\w.+-]+@[\w-]+(?:\.[\w-]+)+\b")
+_URL = re.compile(r"\bhttps?://[^\s<>()\"']+")
+_MENTION = re.compile(r"(?<![\w@])@[A-Za-z0-9_][A-Za-z0-9_.-]*")
+_HASHTAG = re.compile(r"(?<![\w#])#[A-Za-z][A-Za-z0-9_-]*")
+_ISO_DATE = re.compile(r"\b\d{4}-\d{2}-\d{2}\b")
+_ACRONYM = re.compile(r"\b[A-Z][A-Z0-9]{1,9}\b")
+_PROPER_RUN = re.compile(r"\b[A-Z][a-z0-9]+(?:[ -][A-Z][a-z0-9]+)*\b")
+_MAX_ENTITIES = 12
+
+
+def terms(text: str) -> list[str]:
+    """Lowercased content words of ``text`` with stopwords removed."""
+    return [token for token in _TOKEN.findall(text.lower()) if token not in _STOPWORDS]
+
+
+class HashedTermEmbeddingProvider:
+    """Bag of words and bigrams projected into ``embedding_dim`` by feature hashing.
+
+    Term counts are damped with ``1 + log(tf)`` and each feature hashes to a signed bucket, so
+    texts that share vocabulary land close together. Synonyms and paraphrases do not. A
+    non-zero ``seed`` salts the hash, giving a different but equally stable projection.
+    """
+
+    _BIGRAM_WEIGHT = 0.5
+
+    def __init__(self, embedding_dim: int, *, seed: int = 0) -> None:
+        self._embedding_dim = embedding_dim
+        # blake2b's default salt is all zeros, so seed 0 matches the unsalted hash.
+        self._salt = (seed % (1 << 128)).to_bytes(16, "big")
+
+    def embed(self, text: str) -> FloatArray:
+        vector = np.zeros(self._embedding_dim, dtype=np.float32)
+        words = terms(text)
+        features: Counter[str] = Counter(words)
+        for left, right in zip(words, words[1:], strict=False):
+            features[f"{left} {right}"] += 1
+        for feature, count in features.items():
+            digest = hashlib.blake2b(
+                feature.encode("utf-8"),
+                digest_size=8,
+                salt=self._salt,
+            ).digest()
+            bucket = int.from_bytes(digest[:4], "big") % self._embedding_dim
+            sign = 1.0 if digest[4] & 1 else -1.0
+            weight = 1.0 + math.log(count)
+            if " " in feature:
+                weight *= self._BIGRAM_WEIGHT
+            vector[bucket] += sign * weight
+        return to_unit_vector(vector)
+
+
+class HeuristicSemanticProvider:
+    """Pattern-based entity extraction in place of an LLM.
+
+    Entities are the caller's entities followed by emails, URLs, @mentions, #tags, ISO dates,
+    acronyms, and runs of capitalized words (a single capitalized word counts only when it does
+    not start a sentence). Intent and relationships come from the event context unchanged.
+    """
+
+    def understand(self, event: RawEvent) -> SemanticUnderstanding:
+        entities = [str(item) for item in event.context.get("entities", []) if str(item)]
+        entities.extend(extract_entities(event.content))
+        return SemanticUnderstanding(
+            summary=str(event.context.get("summary", event.content)),
+            entities=list(dict.fromkeys(entities)),
+            relationships=[str(item) for item in event.context.get("relationships", [])],
+            intent=str(event.context.get("intent", "unknown")),
+        )
+
+
+def extract_entities(text: str) -> list[str]:
+    """Entity strings found in ``text``, first occurrence first, at most twelve."""
+    found: list[str] = []
+    remaining = text
+    for pattern in (_EMAIL, _URL, _MENTION, _HASHTAG, _ISO_DATE):
+        found.extend(match.rstrip(".,;:") for match in pattern.findall(remaining))
+        remaining = pattern.sub(" ", remaining)
+    found.extend(_ACRONYM.findall(remaining))
+    for match in _PROPER_RUN.finditer(remaining):
+        words = match.group(0).split(" ")
+        leading = 0
+        while leading < len(words) and words[leading].lower() in _STOPWORDS:
+            leading += 1
+        phrase = " ".join(words[leading:])
+        if not phrase:
+            continue
+        # A lone capitalized word opening a sentence is usually just capitalization.
+        if " " not in phrase and "-" not in phrase and not leading:
+            if _starts_sentence(remaining, match.start()):
+                continue
+        found.append(phrase)
+    return list(dict.fromkeys(found))[:_MAX_ENTITIES]
+
+
+def _starts_sentence(text: str, index: int) -> bool:
+    window = text[max(0, index - 64) : index]
+    stripped = window.rstrip(" \t")
+    if not stripped:
+        return index <= len(window)
+    return stripped[-1] in ".!?:\n"
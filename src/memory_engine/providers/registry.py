@@ -20,6 +20,7 @@ from memory_engine.providers.adapters import (
     OllamaEmbeddingProvider,
     OllamaSemanticProvider,
 )
+from orbit.secret_sources import get_secret
 
 EmbeddingProviderName = str
 SemanticProviderName = str
@@ -58,6 +59,24 @@ class _FallbackEmbeddingProvider(EmbeddingProvider):
             self._primary_unavailable = True
             return self._fallback.embed(text)
 
+    def embed_many(self, texts: list[str]):
+        embed_many = getattr(self._primary, "embed_many", None)
+        if self._primary_unavailable or not callable(embed_many):
+            return [self.embed(text) for text in texts]
+        try:
+            return embed_many(texts)
+        except Exception:
+            self._primary_unavailable = True
+            return [self._fallback.embed(text) for text in texts]
+
+    def check_health(self) -> dict[str, str]:
+        check = getattr(self._primary, "check_health", None)
+        status = dict(check()) if callable(check) else {"status": "ok"}
+        if self._primary_unavailable:
+            status["status"] = "fallback"
+            status["detail"] = "primary provider failed; serving deterministic embeddings"
+        return status
+
 
 def build_embedding_provider(
     embedding_dim: int,
@@ -78,19 +97,19 @@ def build_embedding_provider(
         )
         factory = lambda: OpenAIEmbeddingProvider(
             model=model,
-            api_key=os.getenv("OPENAI_API_KEY"),
+            api_key=get_secret("OPENAI_API_KEY"),
             dimensions=embedding_dim,
         )
     elif resolved == "anthropic":
         factory = lambda: AnthropicEmbeddingProvider(
             model=os.getenv("MDE_ANTHROPIC_EMBED_MODEL"),
-            api_key=os.getenv("ANTHROPIC_API_KEY"),
+            api_key=get_secret("ANTHROPIC_API_KEY"),
             dimensions=embedding_dim,
         )
     elif resolved == "gemini":
         factory = lambda: GeminiEmbeddingProvider(
             model=os.getenv("MDE_GEMINI_EMBEDDING_MODEL"),
-            api_key=os.getenv("GEMINI_API_KEY"),
+            api_key=get_secret("GEMINI_API_KEY"),
             dimensions=embedding_dim,
         )
     elif resolved == "ollama":
@@ -126,17 +145,17 @@ def build_semantic_provider(
     if resolved == "openai":
         return OpenAISemanticProvider(
             model=os.getenv("MDE_OPENAI_SEMANTIC_MODEL", "gpt-4o-mini"),
-            api_key=os.getenv("OPENAI_API_KEY"),
+            api_key=get_secret("OPENAI_API_KEY"),
         )
     if resolved == "anthropic":
         return AnthropicSemanticProvider(
             model=os.getenv("MDE_ANTHROPIC_MODEL"),
-            api_key=os.getenv("ANTHROPIC_API_KEY"),
+            api_key=get_secret("ANTHROPIC_API_KEY"),
         )
     if resolved == "gemini":
         return GeminiSemanticProvider(
             model=os.getenv("MDE_GEMINI_SEMANTIC_MODEL"),
-            api_key=os.getenv("GEMINI_API_KEY"),
+            api_key=get_secret("GEMINI_API_KEY"),
         )
     if resolved == "ollama":
         return OllamaSemanticProvider(
@@ -153,7 +172,7 @@ def _resolve_embedding_provider_name(provider_name: str | None) -> str:
     configured = os.getenv("MDE_EMBEDDING_PROVIDER")
     if configured:
         return _normalize(configured, _EMBEDDING_ALIASES)
-    use_openai = os.getenv("OPENAI_API_KEY") is not None and os.getenv(
+    use_openai = get_secret("OPENAI_API_KEY") is not None and os.getenv(
         "USE_LOCAL_EMBEDDINGS", "true"
     ).lower() in {"false", "0", "no"}
     if use_openai:
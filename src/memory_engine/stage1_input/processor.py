@@ -1,9 +1,11 @@
 from __future__ import annotations
 
 import re
+from dataclasses import dataclass
 
 from decision_engine.models import EncodedEvent, RawEvent, SemanticUnderstanding
 from decision_engine.semantic_encoding import (
+    ContextSemanticProvider,
     EmbeddingProvider,
     SemanticEncoder,
     SemanticProvider,
@@ -12,6 +14,15 @@ from memory_engine.models.event import Event
 from memory_engine.models.processed_event import ProcessedEvent
 
 
+@dataclass(frozen=True)
+class ExtractedEvent:
+    """An event after semantic extraction and before embedding."""
+
+    event: Event
+    raw_event: RawEvent
+    understanding: SemanticUnderstanding
+
+
 class InputProcessor:
     """Stage 1 input processor: schema validation + semantic encoding."""
 
@@ -19,10 +30,47 @@ class InputProcessor:
         self, embedding_provider: EmbeddingProvider, semantic_provider: SemanticProvider
     ) -> None:
         self.encoder = SemanticEncoder(embedding_provider, semantic_provider)
+        self._providers = {"embedding": embedding_provider, "semantic": semantic_provider}
+
+    def provider_health(self) -> dict[str, dict[str, str]]:
+        """Health of the providers that can report it (e.g. Ollama), keyed by role."""
+        health: dict[str, dict[str, str]] = {}
+        for role, provider in self._providers.items():
+            check = getattr(provider, "check_health", None)
+            if callable(check):
+                health[role] = check()
+        return health
 
     def process(self, event: Event) -> ProcessedEvent:
+        return self.embed(self.extract(event))
+
+    def extract(self, event: Event, *, use_provider: bool = True) -> ExtractedEvent:
+        """Semantic understanding only; ``use_provider=False`` reads it from the event context."""
         raw_event = self.to_raw_event(event)
-        encoded = self.encoder.encode_event(raw_event)
+        understanding = (
+            self.encoder.understand(raw_event)
+            if use_provider
+            else ContextSemanticProvider().understand(raw_event)
+        )
+        return ExtractedEvent(event=event, raw_event=raw_event, understanding=understanding)
+
+    def embed(self, extracted: ExtractedEvent) -> ProcessedEvent:
+        encoded = self.encoder.encode_understood(extracted.raw_event, extracted.understanding)
+        return self._processed(extracted, encoded)
+
+    def embed_many(self, extracted: list[ExtractedEvent]) -> list[ProcessedEvent]:
+        """``embed`` for a batch, with every embedding requested together."""
+        encoded = self.encoder.encode_understood_many(
+            [(item.raw_event, item.understanding) for item in extracted]
+        )
+        return [
+            self._processed(item, result)
+            for item, result in zip(extracted, encoded, strict=True)
+        ]
+
+    @staticmethod
+    def _processed(extracted: ExtractedEvent, encoded: EncodedEvent) -> ProcessedEvent:
+        event, raw_event = extracted.event, extracted.raw_event
         entity_references = list(
             dict.fromkeys([event.entity_id] + encoded.understanding.entities)
         )
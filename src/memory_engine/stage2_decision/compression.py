@@ -1,8 +1,9 @@
 from __future__ import annotations
 
 from dataclasses import dataclass
-from datetime import UTC, datetime, timedelta
+from datetime import datetime, timedelta
 
+from decision_engine import clock
 from decision_engine.models import MemoryRecord
 from memory_engine.models.processed_event import ProcessedEvent
 
@@ -52,5 +53,5 @@ class CompressionPlanner:
         )
 
     def since_iso(self, now: datetime | None = None) -> str:
-        reference = now or datetime.now(UTC)
+        reference = now or clock.now()
         return (reference - timedelta(days=self._window_days)).isoformat()
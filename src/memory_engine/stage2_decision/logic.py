@@ -5,15 +5,15 @@ from memory_engine.models.processed_event import ProcessedEvent
 from memory_engine.models.storage_decision import StorageDecision
 from memory_engine.stage2_decision.compression import CompressionPlanner
 from memory_engine.stage2_decision.decay import DecayPolicyAssigner
-from memory_engine.stage2_decision.scoring import LearnedRelevanceScorer
+from memory_engine.stage2_decision.scoring import RelevanceScorer
 
 
 class DecisionLogic:
-    """Stage 2 decision logic with learned scoring and decay assignment."""
+    """Stage 2 decision logic with pluggable scoring and decay assignment."""
 
     def __init__(
         self,
-        scorer: LearnedRelevanceScorer,
+        scorer: RelevanceScorer,
         decay_assigner: DecayPolicyAssigner,
         compression_planner: CompressionPlanner,
         persistent_threshold: float,
@@ -1,12 +1,16 @@
 from __future__ import annotations
 
 import math
+import threading
+from collections import Counter
 from dataclasses import dataclass
-from datetime import UTC, datetime
+from typing import Protocol
 
+from decision_engine import clock
 from decision_engine.importance_model import ImportanceModel
 from memory_engine.models.memory_state import MemorySnapshot
 from memory_engine.models.processed_event import ProcessedEvent
+from memory_engine.providers.heuristic import terms
 
 ALPHA = 0.4
 BETA = 0.3
@@ -34,6 +38,11 @@ class ScoreResult:
     trace: dict[str, float]
 
 
+class RelevanceScorer(Protocol):
+    def score(self, processed: ProcessedEvent, snapshot: MemorySnapshot) -> ScoreResult:
+        """Return the storage confidence for a processed event."""
+
+
 class LearnedRelevanceScorer:
     """Learned scorer with deterministic bootstrap prior for cold-start stability."""
 
@@ -43,7 +52,7 @@ class LearnedRelevanceScorer:
     def score(self, processed: ProcessedEvent, snapshot: MemorySnapshot) -> ScoreResult:
         model_confidence = self._importance_model.predict(processed.semantic_embedding)
         recency_days = max(
-            (datetime.now(UTC) - processed.timestamp).total_seconds() / 86400.0,
+            (clock.now() - processed.timestamp).total_seconds() / 86400.0,
             0.0,
         )
         prior_confidence = bootstrap_relevance_score(
@@ -64,3 +73,68 @@ class LearnedRelevanceScorer:
                 "entity_reference_count": float(snapshot.entity_reference_count),
             },
         )
+
+
+class TfidfImportanceScorer:
+    """Model-free scorer for the heuristic pipeline: rare, content-rich events score high.
+
+    Distinctiveness is the mean normalized IDF of the event's strongest TF-IDF terms against
+    every event this scorer has seen, so boilerplate that recurs across the namespace sinks
+    while new facts rise. Density saturates with the number of distinct content words, which
+    keeps short acknowledgements out. Document frequencies live in memory and restart empty.
+    """
+
+    _TOP_TERMS = 8
+    _DENSITY_SCALE = 8.0
+
+    def __init__(self) -> None:
+        self._document_frequency: Counter[str] = Counter()
+        self._documents = 0
+        self._lock = threading.Lock()
+
+    def score(self, processed: ProcessedEvent, snapshot: MemorySnapshot) -> ScoreResult:
+        counts = Counter(terms(processed.description))
+        with self._lock:
+            documents = self._documents
+            max_idf = math.log(1.0 + documents) + 1.0
+            weighted = sorted(
+                (
+                    (1.0 + math.log(count)) * self._idf(term, documents),
+                    self._idf(term, documents) / max_idf,
+                )
+                for term, count in counts.items()
+            )
+            self._document_frequency.update(counts.keys())
+            self._documents += 1
+        strongest = weighted[-self._TOP_TERMS :]
+        distinctiveness = (
+            sum(normalized for _, normalized in strongest) / len(strongest) if strongest else 0.0
+        )
+        density = 1.0 - math.exp(-len(counts) / self._DENSITY_SCALE)
+        recency_days = max(
+            (clock.now() - processed.timestamp).total_seconds() / 86400.0,
+            0.0,
+        )
+        prior_confidence = bootstrap_relevance_score(
+            recency_days=recency_days,
+            frequency_count=snapshot.similar_recent_count,
+            entity_ref_count=snapshot.entity_reference_count,
+        )
+        confidence = min(
+            max((0.7 * distinctiveness * density) + (0.3 * prior_confidence), 0.0), 1.0
+        )
+        return ScoreResult(
+            confidence=confidence,
+            trace={
+                "distinctiveness": distinctiveness,
+                "density": density,
+                "prior_confidence": prior_confidence,
+                "documents_seen": float(documents),
+                "recency_days": recency_days,
+                "similar_recent_count": float(snapshot.similar_recent_count),
+                "entity_reference_count": float(snapshot.entity_reference_count),
+            },
+        )
+
+    def _idf(self, term: str, documents: int) -> float:
+        return math.log((1.0 + documents) / (1.0 + self._document_frequency[term])) + 1.0
@@ -1,9 +1,11 @@
 from __future__ import annotations
 
-from datetime import UTC, datetime
+from datetime import datetime
 
 from pydantic import BaseModel, Field
 
+from decision_engine import clock
+
 
 class FeedbackSignal(BaseModel):
     memory_id: str
@@ -11,4 +13,4 @@ class FeedbackSignal(BaseModel):
     memory_age_days: float
     outcome: str
     outcome_signal: float
-    observed_at: datetime = Field(default_factory=lambda: datetime.now(UTC))
+    observed_at: datetime = Field(default_factory=clock.now)
@@ -1,9 +1,8 @@
 from __future__ import annotations
 
-from datetime import UTC, datetime
-
 import numpy as np
 
+from decision_engine import clock
 from decision_engine.models import OutcomeFeedback
 from decision_engine.retrieval_ranker import RetrievalRanker
 from decision_engine.storage_protocol import StorageManagerProtocol
@@ -31,7 +30,7 @@ class LearningLoop:
         query_embedding: list[float],
         account_key: str | None = None,
     ) -> dict[str, float | None]:
-        now = datetime.now(UTC)
+        now = clock.now()
         memories = self._storage.fetch_by_ids(
             feedback.ranked_memory_ids,
             account_key=account_key,
@@ -39,7 +38,7 @@ class LearningLoop:
         helpful_ids = set(feedback.helpful_memory_ids)
         losses: list[float] = []
         for memory in memories:
-            age_days = max((now - memory.created_at).total_seconds() / 86400.0, 0.0)
+            age_days = max((now - memory.event_time).total_seconds() / 86400.0, 0.0)
             signal = (
                 feedback.outcome_signal
                 if memory.memory_id in helpful_ids
@@ -40,6 +40,9 @@ class MemoryRow(Base):
     semantic_embedding_json: Mapped[str] = mapped_column(Text, nullable=False)
     semantic_key: Mapped[str] = mapped_column(String(64), nullable=False, index=True)
     retrieval_count: Mapped[int] = mapped_column(Integer, nullable=False, default=0)
+    last_retrieved_at: Mapped[datetime | None] = mapped_column(
+        DateTime(timezone=True), nullable=True
+    )
     avg_outcome_signal: Mapped[float] = mapped_column(
         Float, nullable=False, default=0.0
     )
@@ -54,6 +57,9 @@ class MemoryRow(Base):
     updated_at: Mapped[datetime] = mapped_column(
         DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC)
     )
+    occurred_at: Mapped[datetime | None] = mapped_column(
+        DateTime(timezone=True), nullable=True
+    )
 
 
 class ApiAccountUsageRow(Base):
@@ -112,6 +118,7 @@ class ApiKeyRow(Base):
     secret_hash: Mapped[str] = mapped_column(String(64), nullable=False)
     hash_iterations: Mapped[int] = mapped_column(Integer, nullable=False)
     scopes_json: Mapped[str] = mapped_column(Text, nullable=False, default="[]")
+    allowed_origins_json: Mapped[str] = mapped_column(Text, nullable=False, default="[]")
     status: Mapped[str] = mapped_column(String(16), nullable=False, default="active")
     created_at: Mapped[datetime] = mapped_column(
         DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC)
@@ -196,6 +203,515 @@ class ApiPilotProRequestRow(Base):
     )
 
 
+class ApiMemoryChangeRow(Base):
+    __tablename__ = "api_memory_changes"
+    __table_args__ = (
+        Index("ix_api_memory_changes_account_id", "account_key", "id"),
+    )
+
+    id: Mapped[int] = mapped_column(Integer, primary_key=True, autoincrement=True)
+    account_key: Mapped[str] = mapped_column(String(128), nullable=False)
+    memory_id: Mapped[str] = mapped_column(String(64), nullable=False, index=True)
+    operation: Mapped[str] = mapped_column(String(16), nullable=False)
+    payload_json: Mapped[str] = mapped_column(Text, nullable=False, default="{}")
+    created_at: Mapped[datetime] = mapped_column(
+        DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC)
+    )
+
+
+class ApiMemoryVersionRow(Base):
+    """A memory's edit version, advanced by a conditional UPDATE so edits cannot interleave."""
+
+    __tablename__ = "api_memory_versions"
+    __table_args__ = (
+        UniqueConstraint(
+            "account_key",
+            "memory_id",
+            name="uq_api_memory_versions_account_memory",
+        ),
+    )
+
+    id: Mapped[int] = mapped_column(Integer, primary_key=True, autoincrement=True)
+    account_key: Mapped[str] = mapped_column(String(128), nullable=False)
+    memory_id: Mapped[str] = mapped_column(String(64), nullable=False)
+    version: Mapped[int] = mapped_column(Integer, nullable=False)
+    updated_at: Mapped[datetime] = mapped_column(
+        DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC)
+    )
+
+
+class ApiEntityAttributeRow(Base):
+    __tablename__ = "api_entity_attributes"
+    __table_args__ = (
+        UniqueConstraint(
+            "account_key",
+            "entity_id",
+            name="uq_api_entity_attributes_account_entity",
+        ),
+    )
+
+    id: Mapped[int] = mapped_column(Integer, primary_key=True, autoincrement=True)
+    account_key: Mapped[str] = mapped_column(String(128), nullable=False)
+    entity_id: Mapped[str] = mapped_column(String(255), nullable=False)
+    attributes_json: Mapped[str] = mapped_column(Text, nullable=False, default="{}")
+    sources_json: Mapped[str] = mapped_column(Text, nullable=False, default="{}")
+    version: Mapped[int] = mapped_column(Integer, nullable=False, default=0)
+    updated_at: Mapped[datetime] = mapped_column(
+        DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC)
+    )
+
+
+class ApiIngestionAnomalyRow(Base):
+    __tablename__ = "api_ingestion_anomalies"
+    __table_args__ = (
+        Index("ix_api_ingestion_anomalies_account_id", "account_key", "id"),
+    )
+
+    id: Mapped[int] = mapped_column(Integer, primary_key=True, autoincrement=True)
+    account_key: Mapped[str] = mapped_column(String(128), nullable=False)
+    key_id: Mapped[str] = mapped_column(String(64), nullable=False)
+    kind: Mapped[str] = mapped_column(String(32), nullable=False)
+    detail_json: Mapped[str] = mapped_column(Text, nullable=False, default="{}")
+    webhook_status: Mapped[str] = mapped_column(String(16), nullable=False, default="skipped")
+    detected_at: Mapped[datetime] = mapped_column(
+        DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC)
+    )
+
+
+class ApiModerationReviewRow(Base):
+    __tablename__ = "api_moderation_reviews"
+    __table_args__ = (
+        Index("ix_api_moderation_reviews_account_status", "account_key", "status"),
+    )
+
+    id: Mapped[int] = mapped_column(Integer, primary_key=True, autoincrement=True)
+    account_key: Mapped[str] = mapped_column(String(128), nullable=False)
+    entity_id: Mapped[str | None] = mapped_column(String(255), nullable=True)
+    action: Mapped[str] = mapped_column(String(16), nullable=False)
+    categories_json: Mapped[str] = mapped_column(Text, nullable=False, default="[]")
+    provider: Mapped[str] = mapped_column(String(32), nullable=False)
+    status: Mapped[str] = mapped_column(String(16), nullable=False, default="pending")
+    content: Mapped[str] = mapped_column(Text, nullable=False)
+    # Set instead of content and request_json for withheld categories such as csam.
+    content_sha256: Mapped[str | None] = mapped_column(String(64), nullable=True)
+    request_json: Mapped[str] = mapped_column(Text, nullable=False, default="{}")
+    memory_id: Mapped[str | None] = mapped_column(String(64), nullable=True)
+    appeal_reason: Mapped[str | None] = mapped_column(Text, nullable=True)
+    resolution_note: Mapped[str | None] = mapped_column(Text, nullable=True)
+    resolved_by: Mapped[str | None] = mapped_column(String(255), nullable=True)
+    created_at: Mapped[datetime] = mapped_column(
+        DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC)
+    )
+    updated_at: Mapped[datetime] = mapped_column(
+        DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC)
+    )
+
+
+class ApiPipelineWebhookRow(Base):
+    __tablename__ = "api_pipeline_webhooks"
+
+    account_key: Mapped[str] = mapped_column(String(128), primary_key=True)
+    url: Mapped[str] = mapped_column(Text, nullable=False)
+    secret: Mapped[str | None] = mapped_column(String(256), nullable=True)
+    timeout_ms: Mapped[int] = mapped_column(Integer, nullable=False, default=2000)
+    failure_policy: Mapped[str] = mapped_column(String(16), nullable=False, default="continue")
+    updated_at: Mapped[datetime] = mapped_column(
+        DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC)
+    )
+
+
+class ApiNamespaceRow(Base):
+    __tablename__ = "api_namespaces"
+
+    account_key: Mapped[str] = mapped_column(String(128), primary_key=True)
+    display_name: Mapped[str | None] = mapped_column(String(128), nullable=True)
+    description: Mapped[str | None] = mapped_column(Text, nullable=True)
+    pipeline_json: Mapped[str | None] = mapped_column(Text, nullable=True)
+    retrieve_defaults_json: Mapped[str | None] = mapped_column(Text, nullable=True)
+    # Sandbox namespaces are wiped by ``orbit expire`` once this passes.
+    expires_at: Mapped[datetime | None] = mapped_column(DateTime(timezone=True), nullable=True)
+    created_at: Mapped[datetime] = mapped_column(
+        DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC)
+    )
+    updated_at: Mapped[datetime] = mapped_column(
+        DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC)
+    )
+
+
+class ApiEventTypeRegistryRow(Base):
+    __tablename__ = "api_event_type_registries"
+
+    account_key: Mapped[str] = mapped_column(String(128), primary_key=True)
+    event_types_json: Mapped[str] = mapped_column(Text, nullable=False, default="[]")
+    enforce: Mapped[bool] = mapped_column(Boolean, nullable=False, default=True)
+    updated_at: Mapped[datetime] = mapped_column(
+        DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC)
+    )
+
+
+class ApiIngestAggregateRow(Base):
+    """Events of an ``aggregate``-sampled type counted for one entity in the current window."""
+
+    __tablename__ = "api_ingest_aggregates"
+    __table_args__ = (
+        UniqueConstraint(
+            "account_key",
+            "entity_id",
+            "event_type",
+            name="uq_api_ingest_aggregates_account_entity_type",
+        ),
+    )
+
+    id: Mapped[int] = mapped_column(Integer, primary_key=True, autoincrement=True)
+    account_key: Mapped[str] = mapped_column(String(128), nullable=False)
+    entity_id: Mapped[str] = mapped_column(String(255), nullable=False)
+    event_type: Mapped[str] = mapped_column(String(64), nullable=False)
+    event_count: Mapped[int] = mapped_column(Integer, nullable=False, default=0)
+    latest_content: Mapped[str] = mapped_column(Text, nullable=False, default="")
+    window_start: Mapped[datetime] = mapped_column(DateTime(timezone=True), nullable=False)
+    last_seen_at: Mapped[datetime] = mapped_column(DateTime(timezone=True), nullable=False)
+
+
+class ApiMemoryCounterRow(Base):
+    """Repeats of a ``counter``-sampled observation, counted on the memory that stored it."""
+
+    __tablename__ = "api_memory_counters"
+    __table_args__ = (
+        UniqueConstraint(
+            "account_key",
+            "entity_id",
+            "event_type",
+            "content_hash",
+            name="uq_api_memory_counters_account_entity_type_hash",
+        ),
+        Index("ix_api_memory_counters_account_memory", "account_key", "memory_id"),
+    )
+
+    id: Mapped[int] = mapped_column(Integer, primary_key=True, autoincrement=True)
+    account_key: Mapped[str] = mapped_column(String(128), nullable=False)
+    entity_id: Mapped[str] = mapped_column(String(255), nullable=False)
+    event_type: Mapped[str] = mapped_column(String(64), nullable=False)
+    content_hash: Mapped[str] = mapped_column(String(64), nullable=False)
+    memory_id: Mapped[str] = mapped_column(String(64), nullable=False)
+    occurrences: Mapped[int] = mapped_column(Integer, nullable=False, default=1)
+    first_seen_at: Mapped[datetime] = mapped_column(DateTime(timezone=True), nullable=False)
+    last_seen_at: Mapped[datetime] = mapped_column(DateTime(timezone=True), nullable=False)
+
+
+class ApiGoalRow(Base):
+    """A goal an entity committed to in conversation, with progress from later memories."""
+
+    __tablename__ = "api_goals"
+    __table_args__ = (Index("ix_api_goals_account_entity", "account_key", "entity_id"),)
+
+    id: Mapped[str] = mapped_column(String(64), primary_key=True)
+    account_key: Mapped[str] = mapped_column(String(128), nullable=False)
+    entity_id: Mapped[str] = mapped_column(String(255), nullable=False)
+    memory_id: Mapped[str] = mapped_column(String(64), nullable=False)
+    title: Mapped[str] = mapped_column(Text, nullable=False)
+    status: Mapped[str] = mapped_column(String(16), nullable=False, default="open")
+    due_date: Mapped[date | None] = mapped_column(Date, nullable=True)
+    progress_json: Mapped[str] = mapped_column(Text, nullable=False, default="[]")
+    created_at: Mapped[datetime] = mapped_column(DateTime(timezone=True), nullable=False)
+    updated_at: Mapped[datetime] = mapped_column(DateTime(timezone=True), nullable=False)
+
+
+class ApiResurfacedMemoryRow(Base):
+    """When ``orbit resurface`` last delivered a memory, so it is not sent again too soon."""
+
+    __tablename__ = "api_resurfaced_memories"
+    __table_args__ = (
+        UniqueConstraint(
+            "account_key",
+            "memory_id",
+            name="uq_api_resurfaced_memories_account_memory",
+        ),
+    )
+
+    id: Mapped[int] = mapped_column(Integer, primary_key=True, autoincrement=True)
+    account_key: Mapped[str] = mapped_column(String(128), nullable=False)
+    memory_id: Mapped[str] = mapped_column(String(64), nullable=False)
+    surfaced_at: Mapped[datetime] = mapped_column(DateTime(timezone=True), nullable=False)
+
+
+class ApiDigestRow(Base):
+    """A generated summary of the memories stored in one period, kept for lookup by ID."""
+
+    __tablename__ = "api_digests"
+    __table_args__ = (Index("ix_api_digests_account_created", "account_key", "created_at"),)
+
+    id: Mapped[str] = mapped_column(String(64), primary_key=True)
+    account_key: Mapped[str] = mapped_column(String(128), nullable=False)
+    entity_id: Mapped[str | None] = mapped_column(String(255), nullable=True)
+    period: Mapped[str] = mapped_column(String(16), nullable=False)
+    window_start: Mapped[datetime] = mapped_column(DateTime(timezone=True), nullable=False)
+    window_end: Mapped[datetime] = mapped_column(DateTime(timezone=True), nullable=False)
+    memory_count: Mapped[int] = mapped_column(Integer, nullable=False, default=0)
+    categories_json: Mapped[str] = mapped_column(Text, nullable=False, default="{}")
+    summary: Mapped[str] = mapped_column(Text, nullable=False)
+    memory_ids_json: Mapped[str] = mapped_column(Text, nullable=False, default="[]")
+    model: Mapped[str | None] = mapped_column(String(128), nullable=True)
+    delivered: Mapped[bool] = mapped_column(Boolean, nullable=False, default=False)
+    created_at: Mapped[datetime] = mapped_column(DateTime(timezone=True), nullable=False)
+
+
+class ApiWritePolicyRow(Base):
+    """What agents may store in a namespace; ingest rejects writes that break it."""
+
+    __tablename__ = "api_write_policies"
+
+    account_key: Mapped[str] = mapped_column(String(128), primary_key=True)
+    allowed_event_types_json: Mapped[str | None] = mapped_column(Text, nullable=True)
+    max_importance: Mapped[float | None] = mapped_column(Float, nullable=True)
+    banned_categories_json: Mapped[str] = mapped_column(Text, nullable=False, default="[]")
+    review_categories_json: Mapped[str] = mapped_column(Text, nullable=False, default="[]")
+    updated_at: Mapped[datetime] = mapped_column(
+        DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC)
+    )
+
+
+class ApiMemoryReviewRow(Base):
+    """A write held for approval by the write policy, with the request to replay on approval."""
+
+    __tablename__ = "api_memory_reviews"
+    __table_args__ = (Index("ix_api_memory_reviews_account_status", "account_key", "status"),)
+
+    id: Mapped[str] = mapped_column(String(64), primary_key=True)
+    account_key: Mapped[str] = mapped_column(String(128), nullable=False)
+    entity_id: Mapped[str | None] = mapped_column(String(255), nullable=True)
+    agent_id: Mapped[str | None] = mapped_column(String(128), nullable=True)
+    category: Mapped[str] = mapped_column(String(64), nullable=False)
+    content: Mapped[str] = mapped_column(Text, nullable=False)
+    request_json: Mapped[str] = mapped_column(Text, nullable=False)
+    status: Mapped[str] = mapped_column(String(16), nullable=False, default="pending")
+    memory_id: Mapped[str | None] = mapped_column(String(64), nullable=True)
+    note: Mapped[str | None] = mapped_column(Text, nullable=True)
+    resolved_by: Mapped[str | None] = mapped_column(String(255), nullable=True)
+    created_at: Mapped[datetime] = mapped_column(DateTime(timezone=True), nullable=False)
+    updated_at: Mapped[datetime] = mapped_column(DateTime(timezone=True), nullable=False)
+
+
+class ApiRetentionPolicyRow(Base):
+    __tablename__ = "api_retention_policies"
+
+    account_key: Mapped[str] = mapped_column(String(128), primary_key=True)
+    days: Mapped[int] = mapped_column(Integer, nullable=False)
+    event_types_json: Mapped[str] = mapped_column(Text, nullable=False, default="{}")
+    updated_at: Mapped[datetime] = mapped_column(
+        DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC)
+    )
+
+
+class ApiExportJobRow(Base):
+    __tablename__ = "api_export_jobs"
+    __table_args__ = (Index("ix_api_export_jobs_enabled_next_run", "enabled", "next_run_at"),)
+
+    id: Mapped[str] = mapped_column(String(64), primary_key=True)
+    account_key: Mapped[str] = mapped_column(String(128), nullable=False, index=True)
+    cron: Mapped[str] = mapped_column(String(128), nullable=False)
+    destination: Mapped[str] = mapped_column(Text, nullable=False)
+    format: Mapped[str] = mapped_column(String(16), nullable=False, default="jsonl")
+    enabled: Mapped[bool] = mapped_column(Boolean, nullable=False, default=True)
+    cursor: Mapped[int] = mapped_column(Integer, nullable=False, default=0)
+    next_run_at: Mapped[datetime | None] = mapped_column(DateTime(timezone=True), nullable=True)
+    last_run_at: Mapped[datetime | None] = mapped_column(DateTime(timezone=True), nullable=True)
+    last_status: Mapped[str | None] = mapped_column(String(16), nullable=True)
+    last_error: Mapped[str | None] = mapped_column(Text, nullable=True)
+    last_object_key: Mapped[str | None] = mapped_column(Text, nullable=True)
+    rows_exported: Mapped[int] = mapped_column(Integer, nullable=False, default=0)
+    created_at: Mapped[datetime] = mapped_column(
+        DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC)
+    )
+
+
+class ApiUrlSourceRow(Base):
+    __tablename__ = "api_url_sources"
+    __table_args__ = (Index("ix_api_url_sources_enabled_next_crawl", "enabled", "next_crawl_at"),)
+
+    id: Mapped[str] = mapped_column(String(64), primary_key=True)
+    account_key: Mapped[str] = mapped_column(String(128), nullable=False, index=True)
+    url: Mapped[str] = mapped_column(String(2048), nullable=False)
+    entity_id: Mapped[str] = mapped_column(String(255), nullable=False)
+    interval_hours: Mapped[float] = mapped_column(Float, nullable=False)
+    enabled: Mapped[bool] = mapped_column(Boolean, nullable=False, default=True)
+    # SHA-256 of the last crawl's normalized text; an unchanged page is not re-ingested.
+    content_hash: Mapped[str | None] = mapped_column(String(64), nullable=True)
+    memory_ids_json: Mapped[str] = mapped_column(Text, nullable=False, default="[]")
+    last_crawled_at: Mapped[datetime | None] = mapped_column(DateTime(timezone=True), nullable=True)
+    next_crawl_at: Mapped[datetime | None] = mapped_column(DateTime(timezone=True), nullable=True)
+    last_status: Mapped[str | None] = mapped_column(String(16), nullable=True)
+    last_error: Mapped[str | None] = mapped_column(Text, nullable=True)
+    created_at: Mapped[datetime] = mapped_column(
+        DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC)
+    )
+
+
+class ApiSavedQueryRow(Base):
+    __tablename__ = "api_saved_queries"
+    __table_args__ = (
+        UniqueConstraint("account_key", "name", name="uq_api_saved_queries_account_name"),
+    )
+
+    id: Mapped[int] = mapped_column(Integer, primary_key=True, autoincrement=True)
+    account_key: Mapped[str] = mapped_column(String(128), nullable=False)
+    name: Mapped[str] = mapped_column(String(64), nullable=False)
+    query_template: Mapped[str] = mapped_column(Text, nullable=False)
+    description: Mapped[str | None] = mapped_column(Text, nullable=True)
+    options_json: Mapped[str] = mapped_column(Text, nullable=False, default="{}")
+    created_at: Mapped[datetime] = mapped_column(
+        DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC)
+    )
+    updated_at: Mapped[datetime] = mapped_column(
+        DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC)
+    )
+
+
+class ApiMemoryShareRow(Base):
+    __tablename__ = "api_memory_shares"
+    __table_args__ = (
+        Index("ix_api_memory_shares_account_target", "account_key", "target_id"),
+        Index("ix_api_memory_shares_account_source", "account_key", "source_entity_id"),
+    )
+
+    id: Mapped[int] = mapped_column(Integer, primary_key=True, autoincrement=True)
+    account_key: Mapped[str] = mapped_column(String(128), nullable=False)
+    source_entity_id: Mapped[str] = mapped_column(String(255), nullable=False)
+    target_type: Mapped[str] = mapped_column(String(16), nullable=False)
+    target_id: Mapped[str] = mapped_column(String(255), nullable=False)
+    memory_ids_json: Mapped[str] = mapped_column(Text, nullable=False, default="[]")
+    tags_json: Mapped[str] = mapped_column(Text, nullable=False, default="[]")
+    created_at: Mapped[datetime] = mapped_column(
+        DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC)
+    )
+    revoked_at: Mapped[datetime | None] = mapped_column(DateTime(timezone=True), nullable=True)
+
+
+class ApiEntityGroupMemberRow(Base):
+    __tablename__ = "api_entity_group_members"
+    __table_args__ = (
+        UniqueConstraint(
+            "account_key",
+            "group_id",
+            "entity_id",
+            name="uq_api_entity_group_members_account_group_entity",
+        ),
+        Index("ix_api_entity_group_members_account_entity", "account_key", "entity_id"),
+    )
+
+    id: Mapped[int] = mapped_column(Integer, primary_key=True, autoincrement=True)
+    account_key: Mapped[str] = mapped_column(String(128), nullable=False)
+    group_id: Mapped[str] = mapped_column(String(255), nullable=False)
+    entity_id: Mapped[str] = mapped_column(String(255), nullable=False)
+    created_at: Mapped[datetime] = mapped_column(
+        DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC)
+    )
+
+
+class ApiMemoryLinkRow(Base):
+    __tablename__ = "api_memory_links"
+    __table_args__ = (
+        UniqueConstraint(
+            "account_key",
+            "source_memory_id",
+            "target_memory_id",
+            "link_type",
+            name="uq_api_memory_links_account_source_target_type",
+        ),
+        Index("ix_api_memory_links_account_target", "account_key", "target_memory_id"),
+    )
+
+    id: Mapped[int] = mapped_column(Integer, primary_key=True, autoincrement=True)
+    account_key: Mapped[str] = mapped_column(String(128), nullable=False)
+    source_memory_id: Mapped[str] = mapped_column(String(64), nullable=False)
+    target_memory_id: Mapped[str] = mapped_column(String(64), nullable=False)
+    link_type: Mapped[str] = mapped_column(String(32), nullable=False)
+    created_at: Mapped[datetime] = mapped_column(
+        DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC)
+    )
+
+
+class ApiChatTurnRow(Base):
+    __tablename__ = "api_chat_turns"
+    __table_args__ = (
+        Index("ix_api_chat_turns_account_session", "account_key", "session_id", "id"),
+    )
+
+    id: Mapped[int] = mapped_column(Integer, primary_key=True, autoincrement=True)
+    account_key: Mapped[str] = mapped_column(String(128), nullable=False)
+    session_id: Mapped[str] = mapped_column(String(128), nullable=False)
+    entity_id: Mapped[str] = mapped_column(String(255), nullable=False)
+    role: Mapped[str] = mapped_column(String(16), nullable=False)
+    content: Mapped[str] = mapped_column(Text, nullable=False)
+    # The long-term memory ingested from this turn, if it was stored.
+    memory_id: Mapped[str | None] = mapped_column(String(64), nullable=True)
+    # Memories retrieved to produce an assistant turn.
+    retrieved_ids_json: Mapped[str] = mapped_column(Text, nullable=False, default="[]")
+    created_at: Mapped[datetime] = mapped_column(
+        DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC)
+    )
+
+
+class ApiQueryLogRow(Base):
+    __tablename__ = "api_query_log"
+    __table_args__ = (
+        Index("ix_api_query_log_account_created", "account_key", "created_at"),
+    )
+
+    id: Mapped[int] = mapped_column(Integer, primary_key=True, autoincrement=True)
+    account_key: Mapped[str] = mapped_column(String(128), nullable=False)
+    query_hash: Mapped[str] = mapped_column(String(64), nullable=False)
+    query_text: Mapped[str] = mapped_column(String(255), nullable=False)
+    result_count: Mapped[int] = mapped_column(Integer, nullable=False, default=0)
+    latency_ms: Mapped[float] = mapped_column(Float, nullable=False, default=0.0)
+    created_at: Mapped[datetime] = mapped_column(
+        DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC)
+    )
+
+
+class ApiTenantResidencyRow(Base):
+    __tablename__ = "api_tenant_residency"
+
+    account_key: Mapped[str] = mapped_column(String(128), primary_key=True)
+    home_region: Mapped[str] = mapped_column(String(32), nullable=False)
+    replica_regions_json: Mapped[str] = mapped_column(Text, nullable=False, default="[]")
+    pinned: Mapped[bool] = mapped_column(Boolean, nullable=False, default=False)
+    updated_at: Mapped[datetime] = mapped_column(
+        DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC)
+    )
+
+
+class ApiReplicationCursorRow(Base):
+    __tablename__ = "api_replication_cursors"
+
+    account_key: Mapped[str] = mapped_column(String(128), primary_key=True)
+    source_region: Mapped[str] = mapped_column(String(32), nullable=False)
+    cursor: Mapped[str | None] = mapped_column(String(64), nullable=True)
+    synced_at: Mapped[datetime | None] = mapped_column(DateTime(timezone=True), nullable=True)
+
+
+class ApiIndexDeploymentRow(Base):
+    __tablename__ = "api_index_deployments"
+
+    id: Mapped[str] = mapped_column(String(64), primary_key=True)
+    status: Mapped[str] = mapped_column(String(16), nullable=False, index=True)
+    embedding_provider: Mapped[str | None] = mapped_column(String(64), nullable=True)
+    chunk_chars: Mapped[int | None] = mapped_column(Integer, nullable=True)
+    quantization: Mapped[str] = mapped_column(String(16), nullable=False, default="none")
+    mirror_percent: Mapped[float] = mapped_column(Float, nullable=False, default=0.0)
+    mirrored_queries: Mapped[int] = mapped_column(Integer, nullable=False, default=0)
+    overlap_sum: Mapped[float] = mapped_column(Float, nullable=False, default=0.0)
+    top_result_matches: Mapped[int] = mapped_column(Integer, nullable=False, default=0)
+    production_latency_ms_sum: Mapped[float] = mapped_column(Float, nullable=False, default=0.0)
+    candidate_latency_ms_sum: Mapped[float] = mapped_column(Float, nullable=False, default=0.0)
+    error: Mapped[str | None] = mapped_column(Text, nullable=True)
+    created_at: Mapped[datetime] = mapped_column(
+        DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC)
+    )
+    ready_at: Mapped[datetime | None] = mapped_column(DateTime(timezone=True), nullable=True)
+    promoted_at: Mapped[datetime | None] = mapped_column(DateTime(timezone=True), nullable=True)
+    retired_at: Mapped[datetime | None] = mapped_column(DateTime(timezone=True), nullable=True)
+
+
 def initialize_database(database_url: str) -> sessionmaker[Session]:
     connect_args = (
         {"check_same_thread": False} if database_url.startswith("sqlite") else {}
@@ -0,0 +1,309 @@
+"""Compressed vector encodings for the embedded vector store.
+
+Float32 vectors cost ``4 * dim`` bytes each. The encodings here trade a little recall for memory:
+
+- ``int8``: scalar quantization, one signed byte per dimension plus a per-vector scale (~4x).
+- ``pq``: product quantization, one byte per pair of dimensions, scored against per-subspace
+  codebooks trained with k-means (~8x).
+- ``binary``: one sign bit per dimension, searched by Hamming distance. The best
+  ``rescore_factor * top_k`` candidates are rescored from int8 codes kept alongside (~3.5x).
+
+Vectors are unit length, so every score approximates cosine similarity. Retrieval re-ranks the
+candidates it gets back against the full-precision embeddings kept in storage, so the
+approximation only affects which memories make the candidate pool.
+"""
+
+from __future__ import annotations
+
+from typing import Any
+
+import numpy as np
+
+QUANTIZATION_MODES = ("none", "int8", "pq", "binary")
+
+_PQ_SUBVECTOR_DIM = 2
+_PQ_CENTROIDS = 256
+_PQ_KMEANS_ITERATIONS = 12
+_PQ_MAX_TRAINING_ROWS = 16_384
+_POPCOUNT = np.array([bin(value).count("1") for value in range(256)], dtype=np.uint8)
+
+
+def normalize_quantization_mode(value: str) -> str:
+    normalized = value.strip().lower() or "none"
+    if normalized not in QUANTIZATION_MODES:
+        msg = f"vector quantization must be one of: {', '.join(QUANTIZATION_MODES)}"
+        raise ValueError(msg)
+    return normalized
+
+
+def parse_namespace_quantization(value: str) -> dict[str, str]:
+    """Parse ``acme=int8,bigco=pq`` into a namespace -> mode mapping."""
+    modes: dict[str, str] = {}
+    for item in value.split(","):
+        if not item.strip():
+            continue
+        namespace, separator, mode = item.partition("=")
+        if not separator or not namespace.strip():
+            msg = f"invalid vector quantization entry: {item.strip()!r}"
+            raise ValueError(msg)
+        modes[namespace.strip()] = normalize_quantization_mode(mode)
+    return modes
+
+
+class QuantizedSegment:
+    """Vectors of one namespace stored in a compressed encoding.
+
+    Rows live in growable arrays; removal moves the last row into the freed slot so the arrays
+    stay dense. Product quantization needs ``train_size`` vectors to fit its codebooks. Until
+    then the segment stores int8 codes, and it re-encodes every row once trained.
+    """
+
+    def __init__(
+        self,
+        mode: str,
+        embedding_dim: int,
+        *,
+        rescore_factor: int = 4,
+        train_size: int = 1024,
+    ) -> None:
+        if mode not in QUANTIZATION_MODES or mode == "none":
+            msg = f"unsupported quantized segment mode: {mode}"
+            raise ValueError(msg)
+        self.mode = mode
+        self._dim = embedding_dim
+        self._rescore_factor = max(1, rescore_factor)
+        self._train_size = max(_PQ_CENTROIDS, train_size)
+        self._ids: list[str] = []
+        self._row_of: dict[str, int] = {}
+        self._int8 = np.zeros((0, embedding_dim), dtype=np.int8)
+        self._scales = np.zeros(0, dtype=np.float32)
+        self._bits = np.zeros((0, (embedding_dim + 7) // 8), dtype=np.uint8)
+        self._subspaces = -(-embedding_dim // _PQ_SUBVECTOR_DIM)
+        self._codebooks: np.ndarray[Any, np.dtype[np.float32]] | None = None
+        self._pq_codes = np.zeros((0, self._subspaces), dtype=np.uint8)
+
+    def __len__(self) -> int:
+        return len(self._ids)
+
+    def __contains__(self, memory_id: object) -> bool:
+        return memory_id in self._row_of
+
+    @property
+    def trained(self) -> bool:
+        return self.mode != "pq" or self._codebooks is not None
+
+    def memory_ids(self) -> list[str]:
+        return list(self._ids)
+
+    def nbytes(self) -> int:
+        rows = len(self._ids)
+        if self.mode == "pq" and self._codebooks is not None:
+            return rows * self._subspaces + self._codebooks.nbytes
+        encoded = rows * (self._dim + 4)
+        if self.mode == "binary":
+            encoded += rows * self._bits.shape[1]
+        return encoded
+
+    def add(self, memory_id: str, unit_vector: np.ndarray[Any, np.dtype[np.float32]]) -> None:
+        self.remove([memory_id])
+        row = len(self._ids)
+        self._grow(row + 1)
+        self._ids.append(memory_id)
+        self._row_of[memory_id] = row
+        self._encode_row(row, unit_vector)
+        if len(self._ids) >= self._train_size:
+            self.train()
+
+    def remove(self, memory_ids: list[str]) -> None:
+        for memory_id in memory_ids:
+            row = self._row_of.pop(memory_id, None)
+            if row is None:
+                continue
+            last = len(self._ids) - 1
+            if row != last:
+                moved = self._ids[last]
+                self._ids[row] = moved
+                self._row_of[moved] = row
+                for array in self._arrays():
+                    array[row] = array[last]
+            self._ids.pop()
+
+    def train(self) -> None:
+        """Fit product-quantization codebooks on the staged rows and re-encode them."""
+        if self.mode != "pq" or self._codebooks is not None or len(self._ids) < _PQ_CENTROIDS:
+            return
+        rows = len(self._ids)
+        vectors = self._int8[:rows].astype(np.float32) * self._scales[:rows, None]
+        padded = self._pad(vectors)
+        rng = np.random.default_rng(0)
+        sample = padded
+        if padded.shape[0] > _PQ_MAX_TRAINING_ROWS:
+            sample = padded[rng.choice(padded.shape[0], _PQ_MAX_TRAINING_ROWS, replace=False)]
+        codebooks = np.zeros(
+            (self._subspaces, _PQ_CENTROIDS, _PQ_SUBVECTOR_DIM),
+            dtype=np.float32,
+        )
+        for subspace in range(self._subspaces):
+            codebooks[subspace] = _kmeans(
+                sample[:, subspace],
+                _PQ_CENTROIDS,
+                rng=rng,
+                iterations=_PQ_KMEANS_ITERATIONS,
+            )
+        self._codebooks = codebooks
+        self._pq_codes = self._pq_encode(padded, codebooks)
+        # Trained rows no longer need their int8 staging codes.
+        self._int8 = np.zeros((0, self._dim), dtype=np.int8)
+        self._scales = np.zeros(0, dtype=np.float32)
+
+    def search(
+        self,
+        query: np.ndarray[Any, np.dtype[np.float32]],
+        top_k: int,
+    ) -> list[tuple[str, float]]:
+        rows = len(self._ids)
+        if rows == 0 or top_k <= 0:
+            return []
+        if self.mode == "binary":
+            query_bits = np.packbits(query > 0)
+            distances = _POPCOUNT[np.bitwise_xor(self._bits[:rows], query_bits)].sum(
+                axis=1, dtype=np.int32
+            )
+            pool = _top_indices(-distances.astype(np.float32), top_k * self._rescore_factor)
+            rescored = (self._int8[pool].astype(np.float32) @ query) * self._scales[pool]
+            order = np.argsort(rescored)[::-1][:top_k]
+            return [(self._ids[int(pool[index])], float(rescored[index])) for index in order]
+        if self.mode == "pq" and self._codebooks is not None:
+            query_parts = self._pad(query[None, :])[0]
+            tables = np.einsum("mks,ms->mk", self._codebooks, query_parts)
+            scores = tables[np.arange(self._subspaces), self._pq_codes[:rows]].sum(axis=1)
+        else:
+            scores = (self._int8[:rows].astype(np.float32) @ query) * self._scales[:rows]
+        top = _top_indices(scores, top_k)
+        return [(self._ids[int(row)], float(scores[int(row)])) for row in top]
+
+    def export(self) -> dict[str, np.ndarray[Any, Any]]:
+        rows = len(self._ids)
+        state: dict[str, np.ndarray[Any, Any]] = {
+            "ids": np.asarray(self._ids, dtype=np.str_),
+        }
+        if self.mode == "pq" and self._codebooks is not None:
+            state["codebooks"] = self._codebooks
+            state["pq_codes"] = self._pq_codes[:rows]
+            return state
+        state["int8"] = self._int8[:rows]
+        state["scales"] = self._scales[:rows]
+        if self.mode == "binary":
+            state["bits"] = self._bits[:rows]
+        return state
+
+    def restore(self, state: dict[str, np.ndarray[Any, Any]]) -> None:
+        self._ids = [str(value) for value in state["ids"].tolist()]
+        self._row_of = {memory_id: row for row, memory_id in enumerate(self._ids)}
+        if "codebooks" in state:
+            self._codebooks = np.asarray(state["codebooks"], dtype=np.float32)
+            self._pq_codes = np.asarray(state["pq_codes"], dtype=np.uint8)
+            self._int8 = np.zeros((0, self._dim), dtype=np.int8)
+            self._scales = np.zeros(0, dtype=np.float32)
+            return
+        self._int8 = np.asarray(state["int8"], dtype=np.int8)
+        self._scales = np.asarray(state["scales"], dtype=np.float32)
+        if "bits" in state:
+            self._bits = np.asarray(state["bits"], dtype=np.uint8)
+
+    def _arrays(self) -> list[np.ndarray[Any, Any]]:
+        if self.mode == "pq" and self._codebooks is not None:
+            return [self._pq_codes]
+        arrays: list[np.ndarray[Any, Any]] = [self._int8, self._scales]
+        if self.mode == "binary":
+            arrays.append(self._bits)
+        return arrays
+
+    def _grow(self, rows: int) -> None:
+        capacity = self._arrays()[0].shape[0]
+        if rows <= capacity:
+            return
+        capacity = max(rows, capacity * 2, 64)
+        if self.mode == "pq" and self._codebooks is not None:
+            self._pq_codes = _resized(self._pq_codes, capacity)
+            return
+        self._int8 = _resized(self._int8, capacity)
+        self._scales = _resized(self._scales, capacity)
+        if self.mode == "binary":
+            self._bits = _resized(self._bits, capacity)
+
+    def _encode_row(self, row: int, vector: np.ndarray[Any, np.dtype[np.float32]]) -> None:
+        if self.mode == "pq" and self._codebooks is not None:
+            self._pq_codes[row] = self._pq_encode(self._pad(vector[None, :]), self._codebooks)[0]
+            return
+        peak = float(np.max(np.abs(vector))) if vector.size else 0.0
+        scale = peak / 127.0 if peak > 0.0 else 1.0
+        self._int8[row] = np.clip(np.rint(vector / scale), -127, 127).astype(np.int8)
+        self._scales[row] = scale
+        if self.mode == "binary":
+            self._bits[row] = np.packbits(vector > 0)
+
+    def _pad(
+        self,
+        vectors: np.ndarray[Any, np.dtype[np.float32]],
+    ) -> np.ndarray[Any, np.dtype[np.float32]]:
+        width = self._subspaces * _PQ_SUBVECTOR_DIM
+        padded = np.zeros((vectors.shape[0], width), dtype=np.float32)
+        padded[:, : self._dim] = vectors
+        return padded.reshape(vectors.shape[0], self._subspaces, _PQ_SUBVECTOR_DIM)
+
+    def _pq_encode(
+        self,
+        padded: np.ndarray[Any, np.dtype[np.float32]],
+        codebooks: np.ndarray[Any, np.dtype[np.float32]],
+    ) -> np.ndarray[Any, np.dtype[np.uint8]]:
+        codes = np.zeros((padded.shape[0], self._subspaces), dtype=np.uint8)
+        for subspace in range(self._subspaces):
+            codes[:, subspace] = _nearest(padded[:, subspace], codebooks[subspace])
+        return codes
+
+def _kmeans(
+    points: np.ndarray[Any, np.dtype[np.float32]],
+    clusters: int,
+    *,
+    rng: np.random.Generator,
+    iterations: int,
+) -> np.ndarray[Any, np.dtype[np.float32]]:
+    centroids = points[rng.choice(points.shape[0], size=clusters, replace=False)].copy()
+    for _ in range(iterations):
+        assignment = _nearest(points, centroids)
+        counts = np.bincount(assignment, minlength=clusters)
+        occupied = counts > 0
+        for axis in range(points.shape[1]):
+            sums = np.bincount(assignment, weights=points[:, axis], minlength=clusters)
+            centroids[occupied, axis] = sums[occupied] / counts[occupied]
+    return centroids
+
+
+def _nearest(
+    points: np.ndarray[Any, np.dtype[np.float32]],
+    centroids: np.ndarray[Any, np.dtype[np.float32]],
+) -> np.ndarray[Any, np.dtype[np.intp]]:
+    distances = (
+        np.sum(points * points, axis=1, keepdims=True)
+        - 2.0 * points @ centroids.T
+        + np.sum(centroids * centroids, axis=1)
+    )
+    return np.argmin(distances, axis=1)
+
+
+def _top_indices(
+    scores: np.ndarray[Any, np.dtype[np.float32]],
+    top_k: int,
+) -> np.ndarray[Any, np.dtype[np.intp]]:
+    count = min(top_k, scores.shape[0])
+    if count == scores.shape[0]:
+        return np.argsort(scores)[::-1]
+    partial = np.argpartition(-scores, count - 1)[:count]
+    return partial[np.argsort(scores[partial])[::-1]]
+
+
+def _resized(array: np.ndarray[Any, Any], rows: int) -> np.ndarray[Any, Any]:
+    resized = np.zeros((rows, *array.shape[1:]), dtype=array.dtype)
+    resized[: array.shape[0]] = array
+    return resized
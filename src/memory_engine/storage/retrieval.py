@@ -1,9 +1,10 @@
 from __future__ import annotations
 
-from datetime import UTC, datetime
+from collections.abc import Callable
 
 import numpy as np
 
+from decision_engine import clock
 from decision_engine.models import MemoryRecord, RetrievedMemory
 from decision_engine.retrieval_ranker import RetrievalRanker
 from decision_engine.semantic_encoding import SemanticEncoder
@@ -21,10 +22,13 @@ class RetrievalService:
         encoder: SemanticEncoder,
         vector_store: VectorStore | None = None,
         assistant_response_max_share: float = 0.25,
+        encoder_for: Callable[[str | None], SemanticEncoder] | None = None,
     ) -> None:
         self._storage = storage
         self._ranker = ranker
         self._encoder = encoder
+        # Picks the account's encoder when namespaces run different pipeline modes.
+        self._encoder_for = encoder_for
         self._vector_store = vector_store
         self._assistant_response_max_share = max(
             0.0, min(assistant_response_max_share, 1.0)
@@ -37,7 +41,10 @@ class RetrievalService:
         candidate_pool_size: int | None = None,
         account_key: str | None = None,
     ) -> list[RetrievedMemory]:
-        query_embedding = self._encoder.encode_query(query)
+        encoder = (
+            self._encoder_for(account_key) if self._encoder_for is not None else self._encoder
+        )
+        query_embedding = encoder.encode_query(query)
         pool_size = candidate_pool_size or max(80, top_k * 12)
 
         if self._vector_store is not None:
@@ -68,7 +75,7 @@ class RetrievalService:
         ranked = self._ranker.rank(
             np.asarray(query_embedding, dtype=np.float32),
             candidates,
-            now=datetime.now(UTC),
+            now=clock.now(),
         )
         selected = self._select_with_intent_caps(ranked, top_k=top_k)
         for item in selected:
@@ -8,6 +8,7 @@ from typing import Any
 import numpy as np
 
 from decision_engine.math_utils import to_unit_vector
+from memory_engine.storage.quantization import QuantizedSegment, normalize_quantization_mode
 
 try:
     import faiss
@@ -22,15 +23,37 @@ class VectorHit:
 
 
 class VectorStore:
-    """Optional FAISS vector store with a numpy fallback backend."""
+    """Optional FAISS vector store with a numpy fallback backend.
 
-    def __init__(self, embedding_dim: int, index_path: str = "faiss_index.idx") -> None:
+    Namespaces configured for quantization keep their vectors in a ``QuantizedSegment`` instead
+    of the float32 index; searches cover both and merge by score.
+    """
+
+    def __init__(
+        self,
+        embedding_dim: int,
+        index_path: str = "faiss_index.idx",
+        *,
+        quantization: str = "none",
+        namespace_quantization: dict[str, str] | None = None,
+        rescore_factor: int = 4,
+        pq_train_size: int = 1024,
+    ) -> None:
         self._embedding_dim = embedding_dim
         self._index_path = Path(index_path)
         self._use_faiss = faiss is not None
         self._lock = threading.RLock()
         self._memory_ids: list[str] = []
         self._vectors: dict[str, np.ndarray[Any, np.dtype[np.float32]]] = {}
+        self._quantization = normalize_quantization_mode(quantization)
+        self._namespace_quantization = {
+            namespace: normalize_quantization_mode(mode)
+            for namespace, mode in (namespace_quantization or {}).items()
+        }
+        self._rescore_factor = rescore_factor
+        self._pq_train_size = pq_train_size
+        self._segments: dict[str, QuantizedSegment] = {}
+        self._namespace_of: dict[str, str] = {}
         self._cache_dirty = True
         self._cached_ids: list[str] = []
         self._cached_matrix: np.ndarray[Any, np.dtype[np.float32]] | None = None
@@ -44,9 +67,27 @@ class VectorStore:
     def backend(self) -> str:
         return "faiss" if self._use_faiss else "numpy"
 
-    def add(self, memory_id: str, vector: list[float]) -> None:
+    def quantization_for(self, namespace: str | None) -> str:
+        if namespace is None:
+            return self._quantization
+        return self._namespace_quantization.get(namespace, self._quantization)
+
+    def add(self, memory_id: str, vector: list[float], namespace: str | None = None) -> None:
         with self._lock:
             embedding = to_unit_vector(np.asarray(vector, dtype=np.float32))
+            namespace_key = namespace or ""
+            previous = self._namespace_of.get(memory_id)
+            if previous is not None and previous in self._segments:
+                self._segments[previous].remove([memory_id])
+            self._namespace_of[memory_id] = namespace_key
+            mode = self.quantization_for(namespace)
+            if mode != "none":
+                if self._vectors.pop(memory_id, None) is not None:
+                    self._cache_dirty = True
+                    if self._use_faiss:  # pragma: no cover - optional dependency path
+                        self._rebuild_faiss_index()
+                self._segment(namespace_key, mode).add(memory_id, embedding)
+                return
             self._vectors[memory_id] = embedding
             self._cache_dirty = True
             if self._use_faiss:  # pragma: no cover - optional dependency path
@@ -57,10 +98,62 @@ class VectorStore:
         with self._lock:
             for memory_id in memory_ids:
                 self._vectors.pop(memory_id, None)
+                namespace_key = self._namespace_of.pop(memory_id, None)
+                if namespace_key is not None and namespace_key in self._segments:
+                    self._segments[namespace_key].remove([memory_id])
             self._cache_dirty = True
             if self._use_faiss:  # pragma: no cover - optional dependency path
                 self._rebuild_faiss_index()
 
+    def memory_ids(self) -> set[str]:
+        with self._lock:
+            ids = set(self._vectors)
+            for segment in self._segments.values():
+                ids.update(segment.memory_ids())
+            return ids
+
+    def namespace_stats(self) -> list[dict[str, Any]]:
+        """Vector count and encoded bytes per namespace, next to the float32 equivalent."""
+        with self._lock:
+            float_counts: dict[str, int] = {}
+            for memory_id in self._vectors:
+                namespace_key = self._namespace_of.get(memory_id, "")
+                float_counts[namespace_key] = float_counts.get(namespace_key, 0) + 1
+            float_bytes = self._embedding_dim * 4
+            stats = [
+                {
+                    "namespace": namespace_key,
+                    "quantization": "none",
+                    "vectors": count,
+                    "bytes": count * float_bytes,
+                    "float32_bytes": count * float_bytes,
+                }
+                for namespace_key, count in float_counts.items()
+            ]
+            stats.extend(
+                {
+                    "namespace": namespace_key,
+                    "quantization": segment.mode if segment.trained else "pq_untrained",
+                    "vectors": len(segment),
+                    "bytes": segment.nbytes(),
+                    "float32_bytes": len(segment) * float_bytes,
+                }
+                for namespace_key, segment in self._segments.items()
+                if len(segment)
+            )
+            return sorted(stats, key=lambda item: (item["namespace"], item["quantization"]))
+
+    def compact(self) -> int:
+        """Rebuild the index from the live vectors; returns how many stale rows were dropped."""
+        with self._lock:
+            dropped = 0
+            if self._use_faiss:  # pragma: no cover - optional dependency path
+                indexed_rows = len(self._memory_ids)
+                self._rebuild_faiss_index()
+                dropped = indexed_rows - len(self._memory_ids)
+            self._cache_dirty = True
+            return max(0, dropped)
+
     def search(
         self,
         query_vector: list[float] | np.ndarray[Any, np.dtype[np.float32]],
@@ -70,73 +163,145 @@ class VectorStore:
             if top_k <= 0:
                 return []
             query = to_unit_vector(np.asarray(query_vector, dtype=np.float32))
-            if (
-                self._use_faiss and self._memory_ids
-            ):  # pragma: no cover - optional dependency path
-                distances, indices = self._index.search(
-                    np.asarray([query], dtype=np.float32), top_k
-                )
-                hits: list[VectorHit] = []
-                for score, idx in zip(distances[0], indices[0], strict=False):
-                    if idx < 0 or idx >= len(self._memory_ids):
-                        continue
-                    hits.append(
-                        VectorHit(memory_id=self._memory_ids[idx], score=float(score))
-                    )
+            hits = self._search_float(query, top_k)
+            if not self._segments:
                 return hits
-
-            ids, matrix = self._numpy_cache()
-            if matrix.size == 0:
-                return []
-            scores = matrix @ query
-            candidate_count = min(top_k, scores.shape[0])
-            if candidate_count <= 0:
-                return []
-            if candidate_count == scores.shape[0]:
-                top_indices = np.argsort(scores)[::-1]
-            else:
-                partial = np.argpartition(-scores, candidate_count - 1)[:candidate_count]
-                top_indices = partial[np.argsort(scores[partial])[::-1]]
-            return [
-                VectorHit(memory_id=ids[int(index)], score=float(scores[int(index)]))
-                for index in top_indices
-            ]
+            for segment in self._segments.values():
+                hits.extend(
+                    VectorHit(memory_id=memory_id, score=score)
+                    for memory_id, score in segment.search(query, top_k)
+                )
+            hits.sort(key=lambda hit: hit.score, reverse=True)
+            return hits[:top_k]
 
     def save(self) -> None:
         with self._lock:
+            self._save_segments()
             if self._use_faiss:  # pragma: no cover - optional dependency path
                 faiss.write_index(self._index, str(self._index_path))
                 ids_path = self._index_path.with_suffix(".ids.npy")
-                np.save(str(ids_path), np.asarray(self._memory_ids, dtype=object))
+                np.save(str(ids_path), np.asarray(self._memory_ids, dtype=np.str_))
                 return
             np.savez(
                 str(self._index_path.with_suffix(".npz")),
-                memory_ids=np.asarray(list(self._vectors.keys()), dtype=object),
+                memory_ids=np.asarray(list(self._vectors.keys()), dtype=np.str_),
                 vectors=np.asarray(list(self._vectors.values()), dtype=np.float32),
             )
 
     def load(self) -> None:
         with self._lock:
+            self._load_segments()
             if (
                 self._use_faiss and self._index_path.exists()
             ):  # pragma: no cover - optional dependency path
                 self._index = faiss.read_index(str(self._index_path))
                 ids_path = self._index_path.with_suffix(".ids.npy")
                 if ids_path.exists():
-                    loaded_ids = np.load(str(ids_path), allow_pickle=True)
+                    loaded_ids = np.load(str(ids_path), allow_pickle=False)
                     self._memory_ids = [str(value) for value in loaded_ids.tolist()]
                 return
             npz_path = self._index_path.with_suffix(".npz")
             if not npz_path.exists():
                 return
-            data = np.load(str(npz_path), allow_pickle=True)
-            ids = [str(value) for value in data["memory_ids"].tolist()]
-            vectors = np.asarray(data["vectors"], dtype=np.float32)
+            with np.load(str(npz_path), allow_pickle=False) as data:
+                ids = [str(value) for value in data["memory_ids"].tolist()]
+                vectors = np.asarray(data["vectors"], dtype=np.float32)
             self._vectors = {
                 memory_id: vectors[idx] for idx, memory_id in enumerate(ids)
             }
             self._cache_dirty = True
 
+    def _search_float(
+        self,
+        query: np.ndarray[Any, np.dtype[np.float32]],
+        top_k: int,
+    ) -> list[VectorHit]:
+        if (
+            self._use_faiss and self._memory_ids
+        ):  # pragma: no cover - optional dependency path
+            distances, indices = self._index.search(
+                np.asarray([query], dtype=np.float32), top_k
+            )
+            hits: list[VectorHit] = []
+            for score, idx in zip(distances[0], indices[0], strict=False):
+                if idx < 0 or idx >= len(self._memory_ids):
+                    continue
+                hits.append(
+                    VectorHit(memory_id=self._memory_ids[idx], score=float(score))
+                )
+            return hits
+
+        ids, matrix = self._numpy_cache()
+        if matrix.size == 0:
+            return []
+        scores = matrix @ query
+        candidate_count = min(top_k, scores.shape[0])
+        if candidate_count <= 0:
+            return []
+        if candidate_count == scores.shape[0]:
+            top_indices = np.argsort(scores)[::-1]
+        else:
+            partial = np.argpartition(-scores, candidate_count - 1)[:candidate_count]
+            top_indices = partial[np.argsort(scores[partial])[::-1]]
+        return [
+            VectorHit(memory_id=ids[int(index)], score=float(scores[int(index)]))
+            for index in top_indices
+        ]
+
+    def _save_segments(self) -> None:
+        path = self._index_path.with_suffix(".quantized.npz")
+        segments = [(key, segment) for key, segment in self._segments.items() if len(segment)]
+        if not segments:
+            path.unlink(missing_ok=True)
+            return
+        arrays: dict[str, np.ndarray[Any, Any]] = {}
+        for position, (_, segment) in enumerate(segments):
+            for name, array in segment.export().items():
+                arrays[f"{position}_{name}"] = array
+        np.savez(
+            str(path),
+            namespaces=np.asarray([key for key, _ in segments], dtype=np.str_),
+            modes=np.asarray([segment.mode for _, segment in segments], dtype=np.str_),
+            **arrays,
+        )
+
+    def _load_segments(self) -> None:
+        path = self._index_path.with_suffix(".quantized.npz")
+        if not path.exists():
+            return
+        # Ids, namespaces and modes are fixed-width strings, so nothing here is unpickled.
+        with np.load(str(path), allow_pickle=False) as data:
+            modes = [str(value) for value in data["modes"].tolist()]
+            namespaces = [str(value) for value in data["namespaces"].tolist()]
+            states = [
+                {
+                    name.removeprefix(f"{position}_"): data[name]
+                    for name in data.files
+                    if name.startswith(f"{position}_")
+                }
+                for position in range(len(namespaces))
+            ]
+        for namespace_key, mode, state in zip(namespaces, modes, states, strict=True):
+            # A namespace whose configured mode changed is re-encoded from storage instead.
+            if self.quantization_for(namespace_key or None) != mode:
+                continue
+            segment = self._segment(namespace_key, mode)
+            segment.restore(state)
+            for memory_id in segment.memory_ids():
+                self._namespace_of[memory_id] = namespace_key
+
+    def _segment(self, namespace_key: str, mode: str) -> QuantizedSegment:
+        segment = self._segments.get(namespace_key)
+        if segment is None:
+            segment = QuantizedSegment(
+                mode,
+                self._embedding_dim,
+                rescore_factor=self._rescore_factor,
+                train_size=self._pq_train_size,
+            )
+            self._segments[namespace_key] = segment
+        return segment
+
     def _rebuild_faiss_index(self) -> None:
         if not self._use_faiss:  # pragma: no cover - optional dependency path
             return
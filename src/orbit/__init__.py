@@ -2,11 +2,13 @@
 
 from orbit.async_client import AsyncMemoryEngine
 from orbit.client import MemoryEngine
-from orbit.config import Config
+from orbit.config import Config, Endpoint
+from orbit.endpoints import EndpointHealth
 from orbit.exceptions import (
     OrbitAuthError,
     OrbitError,
     OrbitNotFoundError,
+    OrbitPreconditionFailedError,
     OrbitRateLimitError,
     OrbitServerError,
     OrbitTimeoutError,
@@ -14,30 +16,41 @@ from orbit.exceptions import (
 )
 from orbit.models import (
     FeedbackResponse,
+    IngestAttachment,
     IngestResponse,
     Memory,
     RetrieveResponse,
     StatusResponse,
     TimeRange,
 )
+from orbit.shadow_read import AsyncShadowReadEngine, ShadowDiff, ShadowReadEngine
+from orbit.tracing import trace_context
 from orbit.version import __version__
 
 __all__ = [
     "AsyncMemoryEngine",
+    "AsyncShadowReadEngine",
     "Config",
+    "Endpoint",
+    "EndpointHealth",
     "FeedbackResponse",
+    "IngestAttachment",
     "IngestResponse",
     "Memory",
     "MemoryEngine",
     "OrbitAuthError",
     "OrbitError",
     "OrbitNotFoundError",
+    "OrbitPreconditionFailedError",
     "OrbitRateLimitError",
     "OrbitServerError",
     "OrbitTimeoutError",
     "OrbitValidationError",
     "RetrieveResponse",
+    "ShadowDiff",
+    "ShadowReadEngine",
     "StatusResponse",
     "TimeRange",
     "__version__",
+    "trace_context",
 ]
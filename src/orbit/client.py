@@ -2,29 +2,100 @@
 
 from __future__ import annotations
 
-from collections.abc import Sequence
+from collections.abc import Iterable, Iterator, Sequence
+from datetime import datetime
 from typing import Any
+from urllib.parse import quote
 
 import httpx
 
 from orbit.config import Config
+from orbit.endpoints import EndpointHealth
 from orbit.http import OrbitHttpClient
 from orbit.logger import configure_logging, get_logger
 from orbit.models import (
+    AskRequest,
+    AskResponse,
+    BatchRetrieveRequest,
+    BatchRetrieveResponse,
+    BrowserTokenRequest,
+    BrowserTokenResponse,
+    CaptureRequest,
+    ChangeFeedResponse,
+    ChatHistoryResponse,
+    ChatRequest,
+    ChatResponse,
+    Digest,
+    DigestRequest,
+    EntityAttributesPatchRequest,
+    EntityAttributesResponse,
+    EntityConflictsResponse,
+    EntityGoalsResponse,
+    EntityGroupRequest,
+    EntityGroupResponse,
+    EntityTopicsResponse,
+    FanoutRetrieveRequest,
+    FanoutRetrieveResponse,
     FeedbackBatchRequest,
     FeedbackBatchResponse,
     FeedbackRequest,
     FeedbackResponse,
+    ImportRequest,
+    ImportResponse,
     IngestBatchRequest,
+    IngestAttachment,
     IngestBatchResponse,
     IngestRequest,
     IngestResponse,
+    IngestStreamAck,
+    Memory,
+    MemoryDiffResponse,
+    MemoryLink,
+    MemoryLinkListResponse,
+    MemoryLinkRequest,
+    MemoryRevertRequest,
+    MemoryReview,
+    MemoryReviewDecision,
+    MemoryReviewListResponse,
+    MemoryShare,
+    MemoryShareListResponse,
+    MemoryShareRequest,
+    MemoryUpdateRequest,
+    MemoryVersionListResponse,
+    ModerationAppealRequest,
+    ModerationReview,
+    ModerationReviewListResponse,
+    PipelineWebhook,
+    PipelineWebhookRequest,
+    ProcedureRequest,
+    ProcedureSearchResponse,
+    ProcedureStep,
+    QueryAnalyticsResponse,
+    RecallRequest,
+    RecallResponse,
+    ReflectRequest,
+    ReflectResponse,
+    ResurfaceResponse,
+    RetrieveBoost,
+    RetrieveDefaults,
+    RetrieveNamespace,
     RetrieveRequest,
     RetrieveResponse,
+    SavedQuery,
+    SavedQueryListResponse,
+    SavedQueryRequest,
+    SavedQueryRunRequest,
+    SessionEndResponse,
+    SessionMemoryItem,
+    SessionMemoryRequest,
+    SimilarMemoriesResponse,
     StatusResponse,
     TimeRange,
+    TrajectoryStep,
+    VectorSearchRequest,
 )
 from orbit.telemetry import TelemetryClient
+from orbit.tracing import TraceContextProvider
 
 
 class MemoryEngine:
@@ -39,6 +110,7 @@ class MemoryEngine:
         timeout_seconds: float | None = None,
         max_retries: int | None = None,
         transport: httpx.BaseTransport | None = None,
+        trace_context_provider: TraceContextProvider | None = None,
     ) -> None:
         resolved = _resolve_config(
             api_key=api_key,
@@ -51,7 +123,20 @@ class MemoryEngine:
         self.config = resolved
         self._log = get_logger("orbit.client")
         self._telemetry = TelemetryClient(enabled=self.config.enable_telemetry)
-        self._http = OrbitHttpClient(config=resolved, transport=transport)
+        self._http = OrbitHttpClient(
+            config=resolved,
+            transport=transport,
+            trace_context_provider=trace_context_provider,
+        )
+
+    @property
+    def last_request_id(self) -> str | None:
+        """``X-Request-ID`` of the latest response, for matching calls to server logs."""
+        return self._http.last_request_id
+
+    def endpoint_health(self) -> list[EndpointHealth]:
+        """Health and smoothed latency of each configured endpoint, in priority order."""
+        return self._http.endpoint_health()
 
     def ingest(
         self,
@@ -59,15 +144,31 @@ class MemoryEngine:
         event_type: str | None = None,
         metadata: dict[str, Any] | None = None,
         entity_id: str | None = None,
+        attachment: IngestAttachment | dict[str, Any] | None = None,
+        sensitivity: str | None = None,
+        on_oversize: str | None = None,
+        agent_id: str | None = None,
+        agent_scope: str | None = None,
+        occurred_at: datetime | None = None,
     ) -> IngestResponse:
         request = IngestRequest(
             content=content,
             event_type=event_type,
             metadata=metadata,
             entity_id=entity_id,
+            attachment=(
+                IngestAttachment.model_validate(attachment)
+                if attachment is not None
+                else None
+            ),
+            sensitivity=sensitivity,
+            on_oversize=on_oversize,
+            agent_id=agent_id,
+            agent_scope=agent_scope,
+            occurred_at=occurred_at,
         )
         payload = self._http.post(
-            "/v1/ingest", json_body=request.model_dump(exclude_none=True)
+            "/v1/ingest", json_body=request.model_dump(mode="json", exclude_none=True)
         )
         response = IngestResponse.model_validate(payload)
         self._telemetry.track("ingest")
@@ -76,25 +177,94 @@ class MemoryEngine:
         )
         return response
 
+    def capture(
+        self,
+        url: str,
+        title: str | None = None,
+        selection: str | None = None,
+        favicon_url: str | None = None,
+        note: str | None = None,
+        tags: list[str] | None = None,
+        entity_id: str | None = None,
+    ) -> IngestResponse:
+        request = CaptureRequest(
+            url=url,
+            title=title,
+            selection=selection,
+            favicon_url=favicon_url,
+            note=note,
+            tags=tags or [],
+            entity_id=entity_id,
+        )
+        payload = self._http.post(
+            "/v1/capture",
+            json_body=request.model_dump(exclude_none=True),
+        )
+        response = IngestResponse.model_validate(payload)
+        self._telemetry.track("capture")
+        return response
+
     def retrieve(
         self,
         query: str,
-        limit: int = 10,
+        limit: int | None = None,
         entity_id: str | None = None,
         event_type: str | None = None,
         time_range: TimeRange | None = None,
+        max_latency_ms: int | None = None,
+        session_id: str | None = None,
+        mode: str = "vector",
+        graph_hops: int = 1,
+        topic_id: str | None = None,
+        min_score: float | None = None,
+        fallback: str | None = None,
+        consistency: str = "eventual",
+        hedge: bool | None = None,
+        include_embeddings: bool = False,
+        include_linked: bool = False,
+        summarize: bool = False,
+        fast: bool = False,
+        sentiment: str | None = None,
+        emotion: str | None = None,
+        category: str | None = None,
+        agent_id: str | None = None,
+        from_agent: str | None = None,
+        profile: str | None = None,
+        boost: RetrieveBoost | dict[str, Any] | None = None,
+        mmr: bool = False,
+        mmr_lambda: float | None = None,
     ) -> RetrieveResponse:
+        # ``limit`` is only sent when given, so the namespace's default limit applies.
         request = RetrieveRequest(
             query=query,
-            limit=limit,
+            limit=limit if limit is not None else 10,
             entity_id=entity_id,
             event_type=event_type,
             time_range=time_range,
+            max_latency_ms=max_latency_ms,
+            session_id=session_id,
+            mode=mode,
+            graph_hops=graph_hops,
+            topic_id=topic_id,
+            min_score=min_score,
+            fallback=fallback,
+            consistency=consistency,
+            include_linked=include_linked,
+            summarize=summarize,
+            fast=fast,
+            sentiment=sentiment,
+            emotion=emotion,
+            category=category,
+            agent_id=agent_id,
+            from_agent=from_agent,
+            profile=profile,
+            boost=boost,
+            mmr=mmr,
+            mmr_lambda=mmr_lambda if mmr_lambda is not None else 0.5,
         )
-        params: dict[str, Any] = {
-            "query": request.query,
-            "limit": request.limit,
-        }
+        params: dict[str, Any] = {"query": request.query}
+        if limit is not None:
+            params["limit"] = request.limit
         if request.entity_id:
             params["entity_id"] = request.entity_id
         if request.event_type:
@@ -102,11 +272,475 @@ class MemoryEngine:
         if request.time_range:
             params["start_time"] = request.time_range.start.isoformat()
             params["end_time"] = request.time_range.end.isoformat()
-        payload = self._http.get("/v1/retrieve", params=params)
+        if request.max_latency_ms is not None:
+            params["max_latency_ms"] = request.max_latency_ms
+        if request.session_id:
+            params["session_id"] = request.session_id
+        if request.mode != "vector":
+            params["mode"] = request.mode
+            params["graph_hops"] = request.graph_hops
+        if request.topic_id:
+            params["topic_id"] = request.topic_id
+        if request.min_score is not None:
+            params["min_score"] = request.min_score
+        if request.fallback:
+            params["fallback"] = request.fallback
+        if request.consistency != "eventual":
+            params["consistency"] = request.consistency
+        if request.include_linked:
+            params["include_linked"] = "true"
+        if request.summarize:
+            params["summarize"] = "true"
+        if request.fast:
+            params["fast"] = "true"
+        if request.sentiment:
+            params["sentiment"] = request.sentiment
+        if request.emotion:
+            params["emotion"] = request.emotion
+        if request.category:
+            params["category"] = request.category
+        if request.agent_id:
+            params["agent_id"] = request.agent_id
+        if request.from_agent:
+            params["from_agent"] = request.from_agent
+        if request.profile:
+            params["profile"] = request.profile
+        if request.boost:
+            params["boost"] = request.boost.model_dump_json(exclude_defaults=True)
+        if request.mmr:
+            params["mmr"] = "true"
+        if mmr_lambda is not None:
+            params["mmr_lambda"] = request.mmr_lambda
+        if include_embeddings:
+            params["fields"] = "embedding"
+        payload = self._http.hedged_get(
+            "/v1/retrieve",
+            params=params,
+            hedge=self.config.hedge_retrievals if hedge is None else hedge,
+        )
         response = RetrieveResponse.model_validate(payload)
         self._telemetry.track("retrieve", {"result_count": len(response.memories)})
         return response
 
+    def remember_in_session(
+        self,
+        session_id: str,
+        content: str,
+        event_type: str | None = None,
+        entity_id: str | None = None,
+        metadata: dict[str, Any] | None = None,
+        importance: float = 0.0,
+    ) -> SessionMemoryItem:
+        request = SessionMemoryRequest(
+            content=content,
+            event_type=event_type,
+            entity_id=entity_id,
+            metadata=metadata or {},
+            importance=importance,
+        )
+        payload = self._http.post(
+            f"/v1/sessions/{quote(session_id, safe='')}/memories",
+            json_body=request.model_dump(exclude_none=True),
+        )
+        response = SessionMemoryItem.model_validate(payload)
+        self._telemetry.track("remember_in_session")
+        return response
+
+    def end_session(self, session_id: str) -> SessionEndResponse:
+        payload = self._http.post(f"/v1/sessions/{quote(session_id, safe='')}/end")
+        response = SessionEndResponse.model_validate(payload)
+        self._telemetry.track(
+            "end_session",
+            {"promoted": len(response.promoted_memory_ids)},
+        )
+        return response
+
+    def entity_attributes(self, entity_id: str) -> EntityAttributesResponse:
+        payload = self._http.get(f"/v1/entities/{quote(entity_id, safe='')}/attributes")
+        response = EntityAttributesResponse.model_validate(payload)
+        self._telemetry.track("entity_attributes")
+        return response
+
+    def entity_topics(self, entity_id: str, refresh: bool = False) -> EntityTopicsResponse:
+        payload = self._http.get(
+            f"/v1/entities/{quote(entity_id, safe='')}/topics",
+            params={"refresh": "true"} if refresh else None,
+        )
+        response = EntityTopicsResponse.model_validate(payload)
+        self._telemetry.track("entity_topics", {"topic_count": len(response.topics)})
+        return response
+
+    def entity_conflicts(self, entity_id: str) -> EntityConflictsResponse:
+        payload = self._http.get(f"/v1/entities/{quote(entity_id, safe='')}/conflicts")
+        response = EntityConflictsResponse.model_validate(payload)
+        self._telemetry.track("entity_conflicts", {"conflict_count": len(response.conflicts)})
+        return response
+
+    def entity_goals(self, entity_id: str, status: str | None = None) -> EntityGoalsResponse:
+        payload = self._http.get(
+            f"/v1/entities/{quote(entity_id, safe='')}/goals",
+            params={"status": status} if status else None,
+        )
+        response = EntityGoalsResponse.model_validate(payload)
+        self._telemetry.track("entity_goals", {"goal_count": len(response.goals)})
+        return response
+
+    def share_memories(
+        self,
+        entity_id: str,
+        *,
+        target_entity_id: str | None = None,
+        target_group_id: str | None = None,
+        memory_ids: Sequence[str] | None = None,
+        tags: Sequence[str] | None = None,
+    ) -> MemoryShare:
+        request = MemoryShareRequest(
+            target_entity_id=target_entity_id,
+            target_group_id=target_group_id,
+            memory_ids=list(memory_ids or []),
+            tags=list(tags or []),
+        )
+        payload = self._http.post(
+            f"/v1/entities/{quote(entity_id, safe='')}/shares",
+            json_body=request.model_dump(exclude_none=True),
+        )
+        response = MemoryShare.model_validate(payload)
+        self._telemetry.track("share_memories", {"target_type": response.target_type})
+        return response
+
+    def memory_shares(
+        self,
+        entity_id: str,
+        direction: str = "outgoing",
+        include_revoked: bool = False,
+    ) -> MemoryShareListResponse:
+        params: dict[str, Any] = {"direction": direction}
+        if include_revoked:
+            params["include_revoked"] = "true"
+        payload = self._http.get(
+            f"/v1/entities/{quote(entity_id, safe='')}/shares",
+            params=params,
+        )
+        response = MemoryShareListResponse.model_validate(payload)
+        self._telemetry.track("memory_shares", {"count": len(response.data)})
+        return response
+
+    def revoke_memory_share(self, share_id: int) -> MemoryShare:
+        payload = self._http.post(f"/v1/shares/{share_id}/revoke")
+        response = MemoryShare.model_validate(payload)
+        self._telemetry.track("revoke_memory_share")
+        return response
+
+    def set_entity_group(self, group_id: str, entity_ids: Sequence[str]) -> EntityGroupResponse:
+        request = EntityGroupRequest(entity_ids=list(entity_ids))
+        payload = self._http.request(
+            "PUT",
+            f"/v1/groups/{quote(group_id, safe='')}/members",
+            json_body=request.model_dump(),
+        )
+        response = EntityGroupResponse.model_validate(payload)
+        self._telemetry.track("set_entity_group", {"members": len(response.entity_ids)})
+        return response
+
+    def update_entity_attributes(
+        self,
+        entity_id: str,
+        attributes: dict[str, Any],
+        version: int | None = None,
+    ) -> EntityAttributesResponse:
+        request = EntityAttributesPatchRequest(attributes=attributes)
+        payload = self._http.request(
+            "PATCH",
+            f"/v1/entities/{quote(entity_id, safe='')}/attributes",
+            json_body=request.model_dump(),
+            headers=_if_match(version),
+        )
+        response = EntityAttributesResponse.model_validate(payload)
+        self._telemetry.track("update_entity_attributes", {"changed": len(attributes)})
+        return response
+
+    def record_procedure(
+        self,
+        task: str,
+        steps: Sequence[ProcedureStep | dict[str, Any]],
+        error_pattern: str | None = None,
+        outcome: str | None = None,
+        entity_id: str | None = None,
+        metadata: dict[str, Any] | None = None,
+    ) -> IngestResponse:
+        request = ProcedureRequest(
+            task=task,
+            steps=[ProcedureStep.model_validate(item) for item in steps],
+            error_pattern=error_pattern,
+            outcome=outcome,
+            entity_id=entity_id,
+            metadata=metadata or {},
+        )
+        payload = self._http.post(
+            "/v1/procedures",
+            json_body=request.model_dump(exclude_none=True),
+        )
+        response = IngestResponse.model_validate(payload)
+        self._telemetry.track("record_procedure", {"steps": len(request.steps)})
+        return response
+
+    def search_procedures(
+        self,
+        task: str,
+        limit: int = 5,
+        entity_id: str | None = None,
+    ) -> ProcedureSearchResponse:
+        params: dict[str, Any] = {"task": task, "limit": limit}
+        if entity_id:
+            params["entity_id"] = entity_id
+        payload = self._http.get("/v1/procedures/search", params=params)
+        response = ProcedureSearchResponse.model_validate(payload)
+        self._telemetry.track("search_procedures", {"result_count": len(response.data)})
+        return response
+
+    def reflect(
+        self,
+        goal: str,
+        steps: Sequence[TrajectoryStep | dict[str, Any]],
+        outcome: str,
+        outcome_detail: str | None = None,
+        entity_id: str | None = None,
+        metadata: dict[str, Any] | None = None,
+    ) -> ReflectResponse:
+        request = ReflectRequest(
+            goal=goal,
+            steps=[TrajectoryStep.model_validate(item) for item in steps],
+            outcome=outcome,
+            outcome_detail=outcome_detail,
+            entity_id=entity_id,
+            metadata=metadata or {},
+        )
+        payload = self._http.post(
+            "/v1/reflect",
+            json_body=request.model_dump(exclude_none=True),
+        )
+        response = ReflectResponse.model_validate(payload)
+        self._telemetry.track("reflect", {"lessons": len(response.lessons)})
+        return response
+
+    def recall(
+        self,
+        query: str,
+        entity_id: str,
+        limit: int = 10,
+        session_id: str | None = None,
+        session_items: int = 5,
+        max_latency_ms: int | None = None,
+    ) -> RecallResponse:
+        request = RecallRequest(
+            query=query,
+            entity_id=entity_id,
+            limit=limit,
+            session_id=session_id,
+            session_items=session_items,
+            max_latency_ms=max_latency_ms,
+        )
+        payload = self._http.post(
+            "/v1/recall",
+            json_body=request.model_dump(exclude_none=True),
+        )
+        response = RecallResponse.model_validate(payload)
+        self._telemetry.track("recall", {"result_count": len(response.memories)})
+        return response
+
+    def resurface(self, entity_id: str | None = None, limit: int = 10) -> ResurfaceResponse:
+        params: dict[str, Any] = {"limit": limit}
+        if entity_id:
+            params["entity_id"] = entity_id
+        payload = self._http.get("/v1/resurface", params=params)
+        response = ResurfaceResponse.model_validate(payload)
+        self._telemetry.track("resurface", {"result_count": len(response.data)})
+        return response
+
+    def create_digest(
+        self,
+        period: str = "daily",
+        entity_id: str | None = None,
+        deliver: bool = False,
+    ) -> Digest:
+        request = DigestRequest(period=period, entity_id=entity_id, deliver=deliver)
+        payload = self._http.post(
+            "/v1/digests",
+            json_body=request.model_dump(exclude_none=True),
+        )
+        response = Digest.model_validate(payload)
+        self._telemetry.track("create_digest", {"memory_count": response.memory_count})
+        return response
+
+    def digest(self, digest_id: str) -> Digest:
+        payload = self._http.get(f"/v1/digests/{quote(digest_id, safe='')}")
+        return Digest.model_validate(payload)
+
+    def ask(
+        self,
+        question: str,
+        limit: int = 5,
+        entity_id: str | None = None,
+        event_type: str | None = None,
+        time_range: TimeRange | None = None,
+    ) -> AskResponse:
+        request = AskRequest(
+            question=question,
+            limit=limit,
+            entity_id=entity_id,
+            event_type=event_type,
+            time_range=time_range,
+        )
+        payload = self._http.post(
+            "/v1/ask",
+            json_body=request.model_dump(mode="json", exclude_none=True),
+        )
+        response = AskResponse.model_validate(payload)
+        self._telemetry.track(
+            "ask",
+            {"answered": response.answer is not None, "citations": len(response.citations)},
+        )
+        return response
+
+    def chat(
+        self,
+        session_id: str,
+        message: str,
+        entity_id: str | None = None,
+        memory_limit: int = 5,
+    ) -> ChatResponse:
+        request = ChatRequest(
+            session_id=session_id,
+            message=message,
+            entity_id=entity_id,
+            memory_limit=memory_limit,
+        )
+        payload = self._http.post(
+            "/v1/chat",
+            json_body=request.model_dump(exclude_none=True),
+        )
+        response = ChatResponse.model_validate(payload)
+        self._telemetry.track("chat", {"memories": len(response.memories)})
+        return response
+
+    def chat_history(self, session_id: str) -> ChatHistoryResponse:
+        payload = self._http.get(f"/v1/chat/{quote(session_id, safe='')}")
+        response = ChatHistoryResponse.model_validate(payload)
+        self._telemetry.track("chat_history", {"turns": len(response.data)})
+        return response
+
+    def clear_chat(self, session_id: str) -> ChatHistoryResponse:
+        payload = self._http.request("DELETE", f"/v1/chat/{quote(session_id, safe='')}")
+        response = ChatHistoryResponse.model_validate(payload)
+        self._telemetry.track("clear_chat", {"turns": len(response.data)})
+        return response
+
+    def retrieve_fanout(
+        self,
+        query: str,
+        namespaces: Sequence[RetrieveNamespace | dict[str, Any]],
+        limit: int = 10,
+        time_range: TimeRange | None = None,
+        max_latency_ms: int | None = None,
+    ) -> FanoutRetrieveResponse:
+        request = FanoutRetrieveRequest(
+            query=query,
+            limit=limit,
+            namespaces=[RetrieveNamespace.model_validate(item) for item in namespaces],
+            time_range=time_range,
+            max_latency_ms=max_latency_ms,
+        )
+        payload = self._http.post(
+            "/v1/retrieve/fanout",
+            json_body=request.model_dump(mode="json", exclude_none=True),
+        )
+        response = FanoutRetrieveResponse.model_validate(payload)
+        self._telemetry.track(
+            "retrieve_fanout",
+            {"namespaces": len(request.namespaces), "result_count": len(response.memories)},
+        )
+        return response
+
+    def retrieve_batch(
+        self,
+        queries: Sequence[str],
+        limit: int | None = None,
+        entity_id: str | None = None,
+        event_type: str | None = None,
+        time_range: TimeRange | None = None,
+        max_latency_ms: int | None = None,
+        mode: str = "vector",
+        graph_hops: int = 1,
+        min_score: float | None = None,
+        fallback: str | None = None,
+        consistency: str = "eventual",
+        profile: str | None = None,
+        boost: RetrieveBoost | dict[str, Any] | None = None,
+        mmr: bool = False,
+        mmr_lambda: float | None = None,
+    ) -> BatchRetrieveResponse:
+        request = BatchRetrieveRequest(
+            queries=list(queries),
+            limit=limit if limit is not None else 10,
+            entity_id=entity_id,
+            event_type=event_type,
+            time_range=time_range,
+            max_latency_ms=max_latency_ms,
+            mode=mode,
+            graph_hops=graph_hops,
+            min_score=min_score,
+            fallback=fallback,
+            consistency=consistency,
+            profile=profile,
+            boost=boost,
+            mmr=mmr,
+            mmr_lambda=mmr_lambda if mmr_lambda is not None else 0.5,
+        )
+        # Options left at their defaults are not sent, so the namespace's defaults apply.
+        body = request.model_dump(mode="json", exclude_none=True, exclude_defaults=True)
+        if limit is not None:
+            body["limit"] = request.limit
+        if mmr_lambda is not None:
+            body["mmr_lambda"] = request.mmr_lambda
+        payload = self._http.post("/v1/retrieve/batch", json_body=body)
+        response = BatchRetrieveResponse.model_validate(payload)
+        self._telemetry.track(
+            "retrieve_batch",
+            {
+                "queries": len(request.queries),
+                "result_count": sum(len(item.memories) for item in response.results),
+            },
+        )
+        return response
+
+    def search_vector(
+        self,
+        vector: Sequence[float],
+        limit: int = 10,
+        entity_id: str | None = None,
+        event_type: str | None = None,
+        time_range: TimeRange | None = None,
+        min_score: float | None = None,
+        include_embeddings: bool = False,
+    ) -> RetrieveResponse:
+        request = VectorSearchRequest(
+            vector=[float(item) for item in vector],
+            limit=limit,
+            entity_id=entity_id,
+            event_type=event_type,
+            time_range=time_range,
+            min_score=min_score,
+        )
+        payload = self._http.request(
+            "POST",
+            "/v1/search/vector",
+            params={"fields": "embedding"} if include_embeddings else None,
+            json_body=request.model_dump(mode="json", exclude_none=True),
+        )
+        response = RetrieveResponse.model_validate(payload)
+        self._telemetry.track("search_vector", {"result_count": len(response.memories)})
+        return response
+
     def feedback(
         self,
         memory_id: str,
@@ -125,6 +759,312 @@ class MemoryEngine:
         self._telemetry.track("feedback", {"helpful": helpful})
         return response
 
+    def browser_token(
+        self,
+        entity_id: str | None = None,
+        scopes: list[str] | None = None,
+        allowed_origins: list[str] | None = None,
+        ttl_seconds: int = 900,
+    ) -> BrowserTokenResponse:
+        request = BrowserTokenRequest(
+            entity_id=entity_id,
+            scopes=scopes or ["read"],
+            allowed_origins=allowed_origins or [],
+            ttl_seconds=ttl_seconds,
+        )
+        payload = self._http.post(
+            "/v1/tokens/browser",
+            json_body=request.model_dump(exclude_none=True),
+        )
+        response = BrowserTokenResponse.model_validate(payload)
+        self._telemetry.track("browser_token")
+        return response
+
+    def changes(
+        self,
+        cursor: str | None = None,
+        limit: int = 100,
+    ) -> ChangeFeedResponse:
+        params: dict[str, Any] = {"limit": limit}
+        if cursor:
+            params["cursor"] = cursor
+        payload = self._http.get("/v1/changes", params=params)
+        response = ChangeFeedResponse.model_validate(payload)
+        self._telemetry.track("changes", {"count": len(response.data)})
+        return response
+
+    def query_analytics(self, days: int = 7, limit: int = 20) -> QueryAnalyticsResponse:
+        payload = self._http.get(
+            "/v1/analytics/queries",
+            params={"days": days, "limit": limit},
+        )
+        response = QueryAnalyticsResponse.model_validate(payload)
+        self._telemetry.track("query_analytics", {"days": days})
+        return response
+
+    def update_memory(
+        self,
+        memory_id: str,
+        content: str,
+        version: int | None = None,
+    ) -> Memory:
+        """Pass the ``version`` you last read to fail with a precondition error on conflicts."""
+        request = MemoryUpdateRequest(content=content)
+        payload = self._http.request(
+            "PATCH",
+            f"/v1/memories/{quote(memory_id, safe='')}",
+            json_body=request.model_dump(),
+            headers=_if_match(version),
+        )
+        response = Memory.model_validate(payload)
+        self._telemetry.track("update_memory")
+        return response
+
+    def memory_versions(self, memory_id: str) -> MemoryVersionListResponse:
+        payload = self._http.get(f"/v1/memories/{quote(memory_id, safe='')}/versions")
+        response = MemoryVersionListResponse.model_validate(payload)
+        self._telemetry.track("memory_versions", {"count": len(response.versions)})
+        return response
+
+    def memory_diff(
+        self,
+        memory_id: str,
+        from_version: int | None = None,
+        to_version: int | None = None,
+    ) -> MemoryDiffResponse:
+        params: dict[str, Any] = {}
+        if from_version is not None:
+            params["from_version"] = from_version
+        if to_version is not None:
+            params["to_version"] = to_version
+        payload = self._http.get(
+            f"/v1/memories/{quote(memory_id, safe='')}/diff",
+            params=params or None,
+        )
+        response = MemoryDiffResponse.model_validate(payload)
+        self._telemetry.track("memory_diff")
+        return response
+
+    def similar_memories(
+        self,
+        memory_id: str,
+        limit: int = 10,
+        min_score: float | None = None,
+        include_embeddings: bool = False,
+    ) -> SimilarMemoriesResponse:
+        params: dict[str, Any] = {"limit": limit}
+        if min_score is not None:
+            params["min_score"] = min_score
+        if include_embeddings:
+            params["fields"] = "embedding"
+        payload = self._http.get(
+            f"/v1/memories/{quote(memory_id, safe='')}/similar",
+            params=params,
+        )
+        response = SimilarMemoriesResponse.model_validate(payload)
+        self._telemetry.track("similar_memories", {"result_count": len(response.memories)})
+        return response
+
+    def link_memories(
+        self,
+        memory_id: str,
+        target_memory_id: str,
+        link_type: str,
+    ) -> MemoryLink:
+        request = MemoryLinkRequest(target_memory_id=target_memory_id, link_type=link_type)
+        payload = self._http.post(
+            f"/v1/memories/{quote(memory_id, safe='')}/links",
+            json_body=request.model_dump(),
+        )
+        response = MemoryLink.model_validate(payload)
+        self._telemetry.track("link_memories", {"link_type": response.link_type})
+        return response
+
+    def memory_links(self, memory_id: str) -> MemoryLinkListResponse:
+        payload = self._http.get(f"/v1/memories/{quote(memory_id, safe='')}/links")
+        response = MemoryLinkListResponse.model_validate(payload)
+        self._telemetry.track("memory_links", {"count": len(response.data)})
+        return response
+
+    def delete_memory_link(self, memory_id: str, link_id: int) -> MemoryLink:
+        payload = self._http.request(
+            "DELETE",
+            f"/v1/memories/{quote(memory_id, safe='')}/links/{link_id}",
+        )
+        response = MemoryLink.model_validate(payload)
+        self._telemetry.track("delete_memory_link")
+        return response
+
+    def revert_memory(
+        self,
+        memory_id: str,
+        version: int,
+        expected_version: int | None = None,
+    ) -> Memory:
+        request = MemoryRevertRequest(version=version)
+        payload = self._http.request(
+            "POST",
+            f"/v1/memories/{quote(memory_id, safe='')}/revert",
+            json_body=request.model_dump(),
+            headers=_if_match(expected_version),
+        )
+        response = Memory.model_validate(payload)
+        self._telemetry.track("revert_memory", {"version": version})
+        return response
+
+    def moderation_reviews(
+        self,
+        status: str | None = None,
+        limit: int = 50,
+    ) -> ModerationReviewListResponse:
+        params: dict[str, Any] = {"limit": limit}
+        if status:
+            params["status"] = status
+        payload = self._http.get("/v1/moderation/reviews", params=params)
+        response = ModerationReviewListResponse.model_validate(payload)
+        self._telemetry.track("moderation_reviews", {"count": len(response.data)})
+        return response
+
+    def appeal_moderation_review(self, review_id: int, reason: str) -> ModerationReview:
+        request = ModerationAppealRequest(reason=reason)
+        payload = self._http.post(
+            f"/v1/moderation/reviews/{review_id}/appeal",
+            json_body=request.model_dump(),
+        )
+        response = ModerationReview.model_validate(payload)
+        self._telemetry.track("appeal_moderation_review")
+        return response
+
+    def memory_reviews(
+        self,
+        status: str | None = None,
+        limit: int = 50,
+    ) -> MemoryReviewListResponse:
+        params: dict[str, Any] = {"limit": limit}
+        if status:
+            params["status"] = status
+        payload = self._http.get("/v1/review", params=params)
+        response = MemoryReviewListResponse.model_validate(payload)
+        self._telemetry.track("memory_reviews", {"count": len(response.data)})
+        return response
+
+    def approve_memory_review(self, review_id: str, note: str | None = None) -> MemoryReview:
+        request = MemoryReviewDecision(note=note)
+        payload = self._http.post(
+            f"/v1/review/{review_id}/approve",
+            json_body=request.model_dump(exclude_none=True),
+        )
+        response = MemoryReview.model_validate(payload)
+        self._telemetry.track("approve_memory_review")
+        return response
+
+    def reject_memory_review(self, review_id: str, note: str | None = None) -> MemoryReview:
+        request = MemoryReviewDecision(note=note)
+        payload = self._http.post(
+            f"/v1/review/{review_id}/reject",
+            json_body=request.model_dump(exclude_none=True),
+        )
+        response = MemoryReview.model_validate(payload)
+        self._telemetry.track("reject_memory_review")
+        return response
+
+    def pipeline_webhook(self) -> PipelineWebhook:
+        payload = self._http.get("/v1/pipeline/webhook")
+        response = PipelineWebhook.model_validate(payload)
+        self._telemetry.track("pipeline_webhook")
+        return response
+
+    def set_pipeline_webhook(
+        self,
+        url: str,
+        *,
+        secret: str | None = None,
+        timeout_ms: int = 2000,
+        failure_policy: str = "continue",
+    ) -> PipelineWebhook:
+        request = PipelineWebhookRequest(
+            url=url,
+            secret=secret,
+            timeout_ms=timeout_ms,
+            failure_policy=failure_policy,
+        )
+        payload = self._http.request(
+            "PUT",
+            "/v1/pipeline/webhook",
+            json_body=request.model_dump(),
+        )
+        response = PipelineWebhook.model_validate(payload)
+        self._telemetry.track("set_pipeline_webhook", {"failure_policy": failure_policy})
+        return response
+
+    def delete_pipeline_webhook(self) -> PipelineWebhook:
+        payload = self._http.request("DELETE", "/v1/pipeline/webhook")
+        response = PipelineWebhook.model_validate(payload)
+        self._telemetry.track("delete_pipeline_webhook")
+        return response
+
+    def saved_queries(self) -> SavedQueryListResponse:
+        payload = self._http.get("/v1/saved-queries")
+        response = SavedQueryListResponse.model_validate(payload)
+        self._telemetry.track("saved_queries")
+        return response
+
+    def set_saved_query(
+        self,
+        name: str,
+        query_template: str,
+        *,
+        description: str | None = None,
+        options: RetrieveDefaults | dict[str, Any] | None = None,
+    ) -> SavedQuery:
+        request = SavedQueryRequest(
+            query_template=query_template,
+            description=description,
+            options=RetrieveDefaults.model_validate(options or {}),
+        )
+        payload = self._http.request(
+            "PUT",
+            f"/v1/saved-queries/{quote(name, safe='')}",
+            json_body=request.model_dump(exclude_none=True),
+        )
+        response = SavedQuery.model_validate(payload)
+        self._telemetry.track("set_saved_query")
+        return response
+
+    def delete_saved_query(self, name: str) -> SavedQuery:
+        payload = self._http.request("DELETE", f"/v1/saved-queries/{quote(name, safe='')}")
+        response = SavedQuery.model_validate(payload)
+        self._telemetry.track("delete_saved_query")
+        return response
+
+    def run_saved_query(
+        self,
+        name: str,
+        params: dict[str, str] | None = None,
+        *,
+        entity_id: str | None = None,
+        session_id: str | None = None,
+        agent_id: str | None = None,
+        limit: int | None = None,
+        boost: RetrieveBoost | dict[str, Any] | None = None,
+    ) -> RetrieveResponse:
+        """Retrieve with a saved query, filling its template's placeholders from ``params``."""
+        request = SavedQueryRunRequest(
+            params=params or {},
+            entity_id=entity_id,
+            session_id=session_id,
+            agent_id=agent_id,
+            limit=limit,
+            boost=boost,
+        )
+        payload = self._http.post(
+            f"/v1/saved-queries/{quote(name, safe='')}/run",
+            json_body=request.model_dump(exclude_none=True, exclude_defaults=True),
+        )
+        response = RetrieveResponse.model_validate(payload)
+        self._telemetry.track("run_saved_query", {"result_count": len(response.memories)})
+        return response
+
     def status(self) -> StatusResponse:
         payload = self._http.get("/v1/status")
         response = StatusResponse.model_validate(payload)
@@ -145,6 +1085,43 @@ class MemoryEngine:
         self._telemetry.track("ingest_batch", {"count": len(response.items)})
         return response.items
 
+    def import_events(
+        self,
+        events: Sequence[IngestRequest | dict[str, Any]],
+        mode: str = "standard",
+    ) -> ImportResponse:
+        """Bulk-load history with an ``import`` key; ``mode="backfill"`` is the fast path."""
+        request = ImportRequest(events=[IngestRequest.model_validate(item) for item in events])
+        payload = self._http.request(
+            "POST",
+            "/v1/import",
+            params={"mode": mode},
+            json_body=request.model_dump(mode="json", exclude_none=True),
+        )
+        response = ImportResponse.model_validate(payload)
+        self._telemetry.track("import", {"count": len(response.items), "mode": mode})
+        return response
+
+    def ingest_stream(
+        self, events: Iterable[IngestRequest | dict[str, Any]]
+    ) -> Iterator[IngestStreamAck]:
+        """Send ``events`` as one NDJSON stream and yield each line's ack as it arrives.
+
+        A failed line does not stop the stream; a rate-limited one ends it.
+        """
+        body = b"".join(
+            IngestRequest.model_validate(item).model_dump_json(exclude_none=True).encode()
+            + b"\n"
+            for item in events
+        )
+        accepted = failed = 0
+        for payload in self._http.stream_lines("/v1/ingest/stream", body):
+            ack = IngestStreamAck.model_validate(payload)
+            accepted += int(ack.ok)
+            failed += int(not ack.ok)
+            yield ack
+        self._telemetry.track("ingest_stream", {"accepted": accepted, "failed": failed})
+
     def feedback_batch(
         self, feedback: Sequence[FeedbackRequest | dict[str, Any]]
     ) -> list[FeedbackResponse]:
@@ -186,3 +1163,7 @@ def _resolve_config(
     if max_retries is not None:
         resolved.max_retries = max_retries
     return resolved
+
+
+def _if_match(version: int | None) -> dict[str, str] | None:
+    return {"If-Match": f'"{version}"'} if version is not None else None
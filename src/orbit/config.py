@@ -3,16 +3,33 @@
 from __future__ import annotations
 
 import os
+from typing import Literal
 
 from pydantic import BaseModel, Field, field_validator
 
+from orbit.secret_sources import get_secret
 from orbit.version import __version__
 
 
+class Endpoint(BaseModel):
+    """One Orbit deployment a client may send requests to."""
+
+    base_url: str
+    api_key: str | None = None
+    region: str | None = None
+
+    @field_validator("base_url")
+    @classmethod
+    def validate_base_url(cls, value: str) -> str:
+        return _normalize_base_url(value)
+
+
 class Config(BaseModel):
     """Runtime configuration for sync and async SDK clients."""
 
     api_key: str | None = None
+    signing_key_id: str | None = None
+    signing_secret: str | None = None
     base_url: str = "https://orbit-api-ic4qh4dzga-uc.a.run.app"
     timeout_seconds: float = 30.0
     max_retries: int = 3
@@ -20,15 +37,17 @@ class Config(BaseModel):
     log_level: str = "info"
     user_agent: str = Field(default_factory=lambda: f"orbit-python/{__version__}")
     enable_telemetry: bool = True
+    propagate_trace_context: bool = True
+    endpoints: list[Endpoint] = Field(default_factory=list)
+    routing: Literal["failover", "latency"] = "failover"
+    endpoint_cooldown_seconds: float = 30.0
+    hedge_retrievals: bool = False
+    hedge_delay_ms: float | None = None
 
     @field_validator("base_url")
     @classmethod
     def validate_base_url(cls, value: str) -> str:
-        stripped = value.strip().rstrip("/")
-        if not stripped:
-            msg = "base_url cannot be empty"
-            raise ValueError(msg)
-        return stripped
+        return _normalize_base_url(value)
 
     @field_validator("timeout_seconds")
     @classmethod
@@ -54,10 +73,36 @@ class Config(BaseModel):
             raise ValueError(msg)
         return value
 
+    @field_validator("endpoint_cooldown_seconds")
+    @classmethod
+    def validate_endpoint_cooldown_seconds(cls, value: float) -> float:
+        if value <= 0:
+            msg = "endpoint_cooldown_seconds must be > 0"
+            raise ValueError(msg)
+        return value
+
+    @field_validator("hedge_delay_ms")
+    @classmethod
+    def validate_hedge_delay_ms(cls, value: float | None) -> float | None:
+        if value is not None and value < 0:
+            msg = "hedge_delay_ms must be >= 0"
+            raise ValueError(msg)
+        return value
+
+    @property
+    def uses_request_signing(self) -> bool:
+        return bool(self.signing_key_id and self.signing_secret)
+
+    def resolved_endpoints(self) -> list[Endpoint]:
+        """Configured endpoints in priority order; ``base_url`` alone when none are set."""
+        return list(self.endpoints) or [Endpoint(base_url=self.base_url)]
+
     @classmethod
     def from_env(cls) -> Config:
         return cls(
-            api_key=os.getenv("ORBIT_API_KEY"),
+            api_key=get_secret("ORBIT_API_KEY"),
+            signing_key_id=os.getenv("ORBIT_SIGNING_KEY_ID"),
+            signing_secret=get_secret("ORBIT_SIGNING_SECRET"),
             base_url=os.getenv("ORBIT_BASE_URL", "https://orbit-api-ic4qh4dzga-uc.a.run.app"),
             timeout_seconds=_env_float("ORBIT_TIMEOUT", 30.0),
             max_retries=_env_int("ORBIT_MAX_RETRIES", 3),
@@ -65,9 +110,42 @@ class Config(BaseModel):
             log_level=os.getenv("ORBIT_LOG_LEVEL", "info"),
             user_agent=os.getenv("ORBIT_USER_AGENT", f"orbit-python/{__version__}"),
             enable_telemetry=_env_bool("ORBIT_ENABLE_TELEMETRY", True),
+            propagate_trace_context=_env_bool("ORBIT_PROPAGATE_TRACE_CONTEXT", True),
+            endpoints=_env_endpoints(),
+            routing=os.getenv("ORBIT_ROUTING", "failover"),
+            endpoint_cooldown_seconds=_env_float("ORBIT_ENDPOINT_COOLDOWN", 30.0),
+            hedge_retrievals=_env_bool("ORBIT_HEDGE_RETRIEVALS", False),
+            hedge_delay_ms=_env_optional_float("ORBIT_HEDGE_DELAY_MS"),
         )
 
 
+def _normalize_base_url(value: str) -> str:
+    stripped = value.strip().rstrip("/")
+    if not stripped:
+        msg = "base_url cannot be empty"
+        raise ValueError(msg)
+    return stripped
+
+
+def _env_endpoints() -> list[Endpoint]:
+    """``ORBIT_ENDPOINTS`` base URLs, keyed by the matching ``ORBIT_API_KEYS`` entry.
+
+    Both are comma-separated; an endpoint without a key of its own uses ``ORBIT_API_KEY``.
+    """
+    urls = _env_list(os.getenv("ORBIT_ENDPOINTS"))
+    keys = _env_list(get_secret("ORBIT_API_KEYS"))
+    return [
+        Endpoint(base_url=url, api_key=keys[index] if index < len(keys) else None)
+        for index, url in enumerate(urls)
+    ]
+
+
+def _env_list(raw: str | None) -> list[str]:
+    if raw is None:
+        return []
+    return [item.strip() for item in raw.split(",") if item.strip()]
+
+
 def _env_int(name: str, default: int) -> int:
     raw = os.getenv(name)
     if raw is None:
@@ -88,6 +166,16 @@ def _env_float(name: str, default: float) -> float:
         return default
 
 
+def _env_optional_float(name: str) -> float | None:
+    raw = os.getenv(name)
+    if raw is None:
+        return None
+    try:
+        return float(raw)
+    except ValueError:
+        return None
+
+
 def _env_bool(name: str, default: bool) -> bool:
     raw = os.getenv(name)
     if raw is None:
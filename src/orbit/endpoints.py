@@ -0,0 +1,141 @@
+"""Endpoint pooling for clients configured with several Orbit base URLs.
+
+Each endpoint (a base URL with an optional API key of its own) is either healthy or down.
+A transport error, timeout, 408 or 5xx marks it down for ``endpoint_cooldown_seconds``;
+after that a ``GET /v1/health`` probe decides whether it rejoins the pool. Requests go to the
+first healthy endpoint in configured order (``routing="failover"``) or to the healthy one
+with the lowest observed latency (``routing="latency"``).
+"""
+
+from __future__ import annotations
+
+import threading
+import time
+from collections.abc import Callable, Collection
+from dataclasses import dataclass
+
+from orbit.config import Config, Endpoint
+
+HEALTH_PATH = "/v1/health"
+HEALTH_CHECK_TIMEOUT_SECONDS = 2.0
+_ENDPOINT_FAILURE_STATUS_CODES = frozenset({408, 500, 502, 503, 504})
+# Weight of the newest sample in each endpoint's latency average.
+_LATENCY_SMOOTHING = 0.3
+
+
+@dataclass(frozen=True)
+class EndpointHealth:
+    base_url: str
+    region: str | None
+    healthy: bool
+    latency_ms: float | None
+    consecutive_failures: int
+
+
+@dataclass
+class _EndpointState:
+    index: int
+    endpoint: Endpoint
+    latency_ms: float | None = None
+    down_until: float | None = None
+    consecutive_failures: int = 0
+
+
+def is_endpoint_failure(status_code: int) -> bool:
+    return status_code in _ENDPOINT_FAILURE_STATUS_CODES
+
+
+class EndpointPool:
+    """Thread-safe health and latency bookkeeping for a client's endpoints."""
+
+    def __init__(self, config: Config, *, clock: Callable[[], float] = time.monotonic) -> None:
+        self._routing = config.routing
+        self._cooldown = config.endpoint_cooldown_seconds
+        self._clock = clock
+        self._lock = threading.Lock()
+        self._states = [
+            _EndpointState(index=index, endpoint=endpoint)
+            for index, endpoint in enumerate(config.resolved_endpoints())
+        ]
+
+    @property
+    def size(self) -> int:
+        return len(self._states)
+
+    def select(self, exclude: Collection[int] = ()) -> int:
+        """Index of the endpoint for the next attempt, avoiding ``exclude`` when possible.
+
+        Down endpoints are used only when no healthy one is left, soonest-recovering first,
+        so a request is never refused without being tried.
+        """
+        with self._lock:
+            candidates = [state for state in self._states if state.index not in exclude]
+            candidates = candidates or self._states
+            healthy = [state for state in candidates if state.down_until is None]
+            if not healthy:
+                return min(candidates, key=lambda state: state.down_until or 0.0).index
+            if self._routing == "latency":
+                # Unmeasured endpoints go first so every endpoint gets a latency sample.
+                return min(
+                    healthy,
+                    key=lambda state: (state.latency_ms is not None, state.latency_ms or 0.0),
+                ).index
+            return healthy[0].index
+
+    def has_alternative(self, exclude: Collection[int]) -> bool:
+        """Whether a healthy endpoint outside ``exclude`` can take a failed-over request."""
+        with self._lock:
+            return any(
+                state.down_until is None and state.index not in exclude for state in self._states
+            )
+
+    def claim_probes(self) -> list[int]:
+        """Down endpoints whose cooldown has passed; each is claimed by one caller only.
+
+        A lone endpoint is never probed: every request goes to it anyway.
+        """
+        if len(self._states) < 2:
+            return []
+        with self._lock:
+            now = self._clock()
+            due = [
+                state
+                for state in self._states
+                if state.down_until is not None and state.down_until <= now
+            ]
+            for state in due:
+                state.down_until = now + self._cooldown
+            return [state.index for state in due]
+
+    def record_success(self, index: int, latency_seconds: float) -> None:
+        with self._lock:
+            state = self._states[index]
+            latency_ms = latency_seconds * 1000.0
+            if state.latency_ms is None:
+                state.latency_ms = latency_ms
+            else:
+                state.latency_ms += _LATENCY_SMOOTHING * (latency_ms - state.latency_ms)
+            state.down_until = None
+            state.consecutive_failures = 0
+
+    def record_failure(self, index: int) -> None:
+        with self._lock:
+            state = self._states[index]
+            state.consecutive_failures += 1
+            state.down_until = self._clock() + self._cooldown
+
+    def endpoint(self, index: int) -> Endpoint:
+        return self._states[index].endpoint
+
+    def health(self) -> list[EndpointHealth]:
+        with self._lock:
+            return [
+                EndpointHealth(
+                    base_url=state.endpoint.base_url,
+                    region=state.endpoint.region,
+                    healthy=state.down_until is None,
+                    latency_ms=round(state.latency_ms, 3) if state.latency_ms is not None else None,
+                    consecutive_failures=state.consecutive_failures,
+                )
+                for state in self._states
+            ]
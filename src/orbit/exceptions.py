@@ -2,9 +2,16 @@
 
 from __future__ import annotations
 
+from typing import Any
+
 
 class OrbitError(Exception):
-    """Base SDK exception."""
+    """Base SDK exception.
+
+    ``request_id`` is the ``X-Request-ID`` of the failed response, when the API returned one.
+    """
+
+    request_id: str | None = None
 
 
 class OrbitAuthError(OrbitError):
@@ -12,7 +19,15 @@ class OrbitAuthError(OrbitError):
 
 
 class OrbitValidationError(OrbitError):
-    """Request validation failed."""
+    """Request validation failed.
+
+    ``errors`` holds the API's per-field entries (``field``, ``location``, ``constraint``,
+    ``message`` and optional ``limit``) when the response listed them.
+    """
+
+    def __init__(self, message: str, errors: list[dict[str, Any]] | None = None) -> None:
+        super().__init__(message)
+        self.errors = errors or []
 
 
 class OrbitRateLimitError(OrbitError):
@@ -27,6 +42,10 @@ class OrbitNotFoundError(OrbitError):
     """Requested resource was not found."""
 
 
+class OrbitPreconditionFailedError(OrbitError):
+    """The resource changed since the version sent in ``If-Match``."""
+
+
 class OrbitServerError(OrbitError):
     """Server-side failure."""
 
@@ -0,0 +1,118 @@
+"""Hedged requests: a duplicate is sent when the first is slower than usual.
+
+The hedge goes out after ``hedge_delay_ms`` or, by default, after the p95 latency of recent
+calls to the same path; whichever response arrives first successfully is returned. Until
+``MIN_LATENCY_SAMPLES`` calls have completed there is no p95 and requests are not hedged.
+"""
+
+from __future__ import annotations
+
+import asyncio
+import contextvars
+import math
+import threading
+import time
+from collections import deque
+from collections.abc import Awaitable, Callable
+from concurrent.futures import FIRST_COMPLETED, Executor, Future, wait
+from concurrent.futures import TimeoutError as FutureTimeoutError
+from typing import TypeVar
+
+from orbit.config import Config
+
+T = TypeVar("T")
+
+MIN_LATENCY_SAMPLES = 20
+_LATENCY_WINDOW = 200
+
+
+class LatencyTracker:
+    """Rolling window of call latencies, in seconds."""
+
+    def __init__(self, window: int = _LATENCY_WINDOW) -> None:
+        self._samples: deque[float] = deque(maxlen=window)
+        self._lock = threading.Lock()
+
+    def record(self, seconds: float) -> None:
+        with self._lock:
+            self._samples.append(seconds)
+
+    def percentile(self, quantile: float) -> float | None:
+        with self._lock:
+            if len(self._samples) < MIN_LATENCY_SAMPLES:
+                return None
+            ordered = sorted(self._samples)
+        return ordered[min(len(ordered) - 1, math.ceil(quantile * len(ordered)) - 1)]
+
+
+def hedge_delay_seconds(config: Config, latencies: LatencyTracker) -> float | None:
+    if config.hedge_delay_ms is not None:
+        return config.hedge_delay_ms / 1000.0
+    return latencies.percentile(0.95)
+
+
+def hedged_call(
+    call: Callable[[], T], delay_seconds: float, executor: Executor, latencies: LatencyTracker
+) -> T:
+    """Run ``call``, and a duplicate if it has not finished after ``delay_seconds``.
+
+    A request that fails before the hedge is sent raises as usual. Once both are in flight the
+    first success wins and the other is left to finish in the background.
+    """
+    first = _submit(executor, call, latencies)
+    try:
+        return first.result(timeout=delay_seconds)
+    except FutureTimeoutError:
+        pass
+    pending: set[Future[T]] = {first, _submit(executor, call, latencies)}
+    failures: list[BaseException] = []
+    while pending:
+        done, pending = wait(pending, return_when=FIRST_COMPLETED)
+        for future in done:
+            if (error := future.exception()) is None:
+                return future.result()
+            failures.append(error)
+    raise failures[-1]
+
+
+async def hedged_acall(
+    call: Callable[[], Awaitable[T]], delay_seconds: float, latencies: LatencyTracker
+) -> T:
+    """Async ``hedged_call``; the slower request is cancelled once one succeeds."""
+    pending: set[asyncio.Future[T]] = {asyncio.ensure_future(timed_acall(call, latencies))}
+    failures: list[BaseException] = []
+    try:
+        done, _ = await asyncio.wait(pending, timeout=delay_seconds)
+        if done:
+            return done.pop().result()
+        pending.add(asyncio.ensure_future(timed_acall(call, latencies)))
+        while pending:
+            done, pending = await asyncio.wait(pending, return_when=asyncio.FIRST_COMPLETED)
+            for task in done:
+                if (error := task.exception()) is None:
+                    return task.result()
+                failures.append(error)
+    finally:
+        for task in pending:
+            task.cancel()
+    raise failures[-1]
+
+
+def timed_call(call: Callable[[], T], latencies: LatencyTracker) -> T:
+    started = time.monotonic()
+    result = call()
+    latencies.record(time.monotonic() - started)
+    return result
+
+
+async def timed_acall(call: Callable[[], Awaitable[T]], latencies: LatencyTracker) -> T:
+    started = time.monotonic()
+    result = await call()
+    latencies.record(time.monotonic() - started)
+    return result
+
+
+def _submit(executor: Executor, call: Callable[[], T], latencies: LatencyTracker) -> Future[T]:
+    # Each request runs in a copy of the caller's context so trace_context(...) still applies.
+    context = contextvars.copy_context()
+    return executor.submit(context.run, timed_call, call, latencies)
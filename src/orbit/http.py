@@ -3,50 +3,99 @@
 from __future__ import annotations
 
 import asyncio
+import json
 import time
+from collections.abc import AsyncIterator, Iterator
+from concurrent.futures import ThreadPoolExecutor
+from functools import partial
 from typing import Any
 
 import httpx
 
-from orbit.config import Config
+from orbit.config import Config, Endpoint
+from orbit.endpoints import (
+    HEALTH_CHECK_TIMEOUT_SECONDS,
+    HEALTH_PATH,
+    EndpointHealth,
+    EndpointPool,
+    is_endpoint_failure,
+)
 from orbit.exceptions import (
     OrbitAuthError,
     OrbitError,
     OrbitNotFoundError,
+    OrbitPreconditionFailedError,
     OrbitRateLimitError,
     OrbitServerError,
     OrbitTimeoutError,
     OrbitValidationError,
 )
+from orbit.hedging import (
+    LatencyTracker,
+    hedge_delay_seconds,
+    hedged_acall,
+    hedged_call,
+    timed_acall,
+    timed_call,
+)
+from orbit.signing import HmacAuth
+from orbit.tracing import REQUEST_ID_HEADER, TraceContextProvider, trace_headers
 
 _RETRYABLE_STATUS_CODES = {408, 425, 429, 500, 502, 503, 504}
+_HEDGE_MAX_WORKERS = 32
+NDJSON_CONTENT_TYPE = "application/x-ndjson"
 
 
 class OrbitHttpClient:
     """Synchronous HTTP client for Orbit API."""
 
     def __init__(
-        self, config: Config, transport: httpx.BaseTransport | None = None
+        self,
+        config: Config,
+        transport: httpx.BaseTransport | None = None,
+        *,
+        trace_context_provider: TraceContextProvider | None = None,
     ) -> None:
         self._config = config
-        if not self._config.api_key:
+        self._trace_context_provider = trace_context_provider
+        self.last_request_id: str | None = None
+        if not _has_credentials(self._config):
             msg = "Missing API key. Set ORBIT_API_KEY or pass api_key to MemoryEngine."
             raise OrbitAuthError(msg)
-        self._client = httpx.Client(
-            base_url=self._config.base_url,
-            timeout=self._config.timeout_seconds,
-            headers={
-                "Authorization": f"Bearer {self._config.api_key}",
-                "User-Agent": self._config.user_agent,
-            },
-            transport=transport,
-        )
+        self._endpoints = EndpointPool(self._config)
+        self._clients = [
+            httpx.Client(
+                base_url=endpoint.base_url,
+                timeout=self._config.timeout_seconds,
+                headers=_default_headers(self._config, endpoint),
+                auth=_request_auth(self._config),
+                transport=transport,
+            )
+            for endpoint in self._config.resolved_endpoints()
+        ]
+        self._latencies: dict[str, LatencyTracker] = {}
+        self._hedge_executor: ThreadPoolExecutor | None = None
 
     def get(
         self, path: str, params: dict[str, Any] | None = None
     ) -> dict[str, Any] | list[Any]:
         return self.request("GET", path, params=params)
 
+    def hedged_get(
+        self, path: str, params: dict[str, Any] | None = None, *, hedge: bool
+    ) -> dict[str, Any] | list[Any]:
+        """GET that tracks the path's latency and, when ``hedge`` is set, hedges slow calls."""
+        latencies = self._latencies.setdefault(path, LatencyTracker())
+        call = partial(self.request, "GET", path, params=params)
+        delay = hedge_delay_seconds(self._config, latencies) if hedge else None
+        if delay is None:
+            return timed_call(call, latencies)
+        if self._hedge_executor is None:
+            self._hedge_executor = ThreadPoolExecutor(
+                max_workers=_HEDGE_MAX_WORKERS, thread_name_prefix="orbit-hedge"
+            )
+        return hedged_call(call, delay, self._hedge_executor, latencies)
+
     def post(
         self, path: str, json_body: dict[str, Any] | None = None
     ) -> dict[str, Any] | list[Any]:
@@ -58,43 +107,105 @@ class OrbitHttpClient:
         path: str,
         params: dict[str, Any] | None = None,
         json_body: dict[str, Any] | None = None,
+        headers: dict[str, str] | None = None,
     ) -> dict[str, Any] | list[Any]:
-        for attempt in range(self._config.max_retries + 1):
+        headers = {**self._trace_headers(), **(headers or {})}
+        # Endpoints that failed since the last backoff; failing over to another healthy
+        # endpoint is immediate and does not spend the retry budget.
+        failed: set[int] = set()
+        attempt = 0
+        while True:
+            self._probe_endpoints()
+            index = self._endpoints.select(exclude=failed)
+            started = time.monotonic()
             try:
-                response = self._client.request(
+                response = self._clients[index].request(
                     method,
                     path,
                     params=params,
                     json=json_body,
+                    headers=headers,
                 )
-            except httpx.TimeoutException as exc:
-                if attempt >= self._config.max_retries:
-                    msg = "Orbit request timed out"
-                    raise OrbitTimeoutError(msg) from exc
-                self._sleep(attempt)
-                continue
             except httpx.HTTPError as exc:
+                self._endpoints.record_failure(index)
+                failed.add(index)
+                if self._endpoints.has_alternative(failed):
+                    continue
                 if attempt >= self._config.max_retries:
-                    msg = f"HTTP transport error: {exc!s}"
-                    raise OrbitServerError(msg) from exc
+                    raise _transport_error(exc) from exc
                 self._sleep(attempt)
+                attempt += 1
+                failed.clear()
                 continue
 
+            if is_endpoint_failure(response.status_code):
+                self._endpoints.record_failure(index)
+                failed.add(index)
+                if self._endpoints.has_alternative(failed):
+                    continue
+            else:
+                self._endpoints.record_success(index, time.monotonic() - started)
+
             if (
                 response.status_code in _RETRYABLE_STATUS_CODES
                 and attempt < self._config.max_retries
             ):
                 self._sleep(attempt, retry_after=_retry_after_seconds(response))
+                attempt += 1
+                failed.clear()
                 continue
 
+            self.last_request_id = response.headers.get(REQUEST_ID_HEADER)
             _raise_for_status(response)
             return _parse_payload(response)
 
-        msg = "Orbit request failed after retries"
-        raise OrbitServerError(msg)
+    def stream_lines(
+        self, path: str, content: bytes, *, content_type: str = NDJSON_CONTENT_TYPE
+    ) -> Iterator[dict[str, Any]]:
+        """POST ``content`` once, without retries, and yield each JSON line of the response."""
+        headers = {**self._trace_headers(), "Content-Type": content_type}
+        self._probe_endpoints()
+        index = self._endpoints.select()
+        try:
+            with self._clients[index].stream(
+                "POST", path, content=content, headers=headers
+            ) as response:
+                self.last_request_id = response.headers.get(REQUEST_ID_HEADER)
+                if response.status_code >= 400:
+                    response.read()
+                    _raise_for_status(response)
+                for line in response.iter_lines():
+                    if line.strip():
+                        yield json.loads(line)
+        except httpx.HTTPError as exc:
+            self._endpoints.record_failure(index)
+            raise _transport_error(exc) from exc
+
+    def endpoint_health(self) -> list[EndpointHealth]:
+        return self._endpoints.health()
 
     def close(self) -> None:
-        self._client.close()
+        if self._hedge_executor is not None:
+            self._hedge_executor.shutdown(wait=False, cancel_futures=True)
+        for client in self._clients:
+            client.close()
+
+    def _probe_endpoints(self) -> None:
+        for index in self._endpoints.claim_probes():
+            started = time.monotonic()
+            try:
+                response = self._clients[index].get(
+                    HEALTH_PATH, timeout=HEALTH_CHECK_TIMEOUT_SECONDS
+                )
+            except httpx.HTTPError:
+                self._endpoints.record_failure(index)
+                continue
+            _record_probe(self._endpoints, index, response, started)
+
+    def _trace_headers(self) -> dict[str, str]:
+        if not self._config.propagate_trace_context:
+            return {}
+        return trace_headers(self._trace_context_provider)
 
     def _sleep(self, attempt: int, retry_after: float | None = None) -> None:
         delay = _compute_backoff(
@@ -109,27 +220,47 @@ class AsyncOrbitHttpClient:
     """Asynchronous HTTP client for Orbit API."""
 
     def __init__(
-        self, config: Config, transport: httpx.AsyncBaseTransport | None = None
+        self,
+        config: Config,
+        transport: httpx.AsyncBaseTransport | None = None,
+        *,
+        trace_context_provider: TraceContextProvider | None = None,
     ) -> None:
         self._config = config
-        if not self._config.api_key:
+        self._trace_context_provider = trace_context_provider
+        self.last_request_id: str | None = None
+        if not _has_credentials(self._config):
             msg = "Missing API key. Set ORBIT_API_KEY or pass api_key to AsyncMemoryEngine."
             raise OrbitAuthError(msg)
-        self._client = httpx.AsyncClient(
-            base_url=self._config.base_url,
-            timeout=self._config.timeout_seconds,
-            headers={
-                "Authorization": f"Bearer {self._config.api_key}",
-                "User-Agent": self._config.user_agent,
-            },
-            transport=transport,
-        )
+        self._endpoints = EndpointPool(self._config)
+        self._clients = [
+            httpx.AsyncClient(
+                base_url=endpoint.base_url,
+                timeout=self._config.timeout_seconds,
+                headers=_default_headers(self._config, endpoint),
+                auth=_request_auth(self._config),
+                transport=transport,
+            )
+            for endpoint in self._config.resolved_endpoints()
+        ]
+        self._latencies: dict[str, LatencyTracker] = {}
 
     async def get(
         self, path: str, params: dict[str, Any] | None = None
     ) -> dict[str, Any] | list[Any]:
         return await self.request("GET", path, params=params)
 
+    async def hedged_get(
+        self, path: str, params: dict[str, Any] | None = None, *, hedge: bool
+    ) -> dict[str, Any] | list[Any]:
+        """GET that tracks the path's latency and, when ``hedge`` is set, hedges slow calls."""
+        latencies = self._latencies.setdefault(path, LatencyTracker())
+        call = partial(self.request, "GET", path, params=params)
+        delay = hedge_delay_seconds(self._config, latencies) if hedge else None
+        if delay is None:
+            return await timed_acall(call, latencies)
+        return await hedged_acall(call, delay, latencies)
+
     async def post(
         self, path: str, json_body: dict[str, Any] | None = None
     ) -> dict[str, Any] | list[Any]:
@@ -141,43 +272,103 @@ class AsyncOrbitHttpClient:
         path: str,
         params: dict[str, Any] | None = None,
         json_body: dict[str, Any] | None = None,
+        headers: dict[str, str] | None = None,
     ) -> dict[str, Any] | list[Any]:
-        for attempt in range(self._config.max_retries + 1):
+        headers = {**self._trace_headers(), **(headers or {})}
+        # Endpoints that failed since the last backoff; failing over to another healthy
+        # endpoint is immediate and does not spend the retry budget.
+        failed: set[int] = set()
+        attempt = 0
+        while True:
+            await self._probe_endpoints()
+            index = self._endpoints.select(exclude=failed)
+            started = time.monotonic()
             try:
-                response = await self._client.request(
+                response = await self._clients[index].request(
                     method,
                     path,
                     params=params,
                     json=json_body,
+                    headers=headers,
                 )
-            except httpx.TimeoutException as exc:
-                if attempt >= self._config.max_retries:
-                    msg = "Orbit request timed out"
-                    raise OrbitTimeoutError(msg) from exc
-                await self._sleep(attempt)
-                continue
             except httpx.HTTPError as exc:
+                self._endpoints.record_failure(index)
+                failed.add(index)
+                if self._endpoints.has_alternative(failed):
+                    continue
                 if attempt >= self._config.max_retries:
-                    msg = f"HTTP transport error: {exc!s}"
-                    raise OrbitServerError(msg) from exc
+                    raise _transport_error(exc) from exc
                 await self._sleep(attempt)
+                attempt += 1
+                failed.clear()
                 continue
 
+            if is_endpoint_failure(response.status_code):
+                self._endpoints.record_failure(index)
+                failed.add(index)
+                if self._endpoints.has_alternative(failed):
+                    continue
+            else:
+                self._endpoints.record_success(index, time.monotonic() - started)
+
             if (
                 response.status_code in _RETRYABLE_STATUS_CODES
                 and attempt < self._config.max_retries
             ):
                 await self._sleep(attempt, retry_after=_retry_after_seconds(response))
+                attempt += 1
+                failed.clear()
                 continue
 
+            self.last_request_id = response.headers.get(REQUEST_ID_HEADER)
             _raise_for_status(response)
             return _parse_payload(response)
 
-        msg = "Orbit request failed after retries"
-        raise OrbitServerError(msg)
+    async def stream_lines(
+        self, path: str, content: bytes, *, content_type: str = NDJSON_CONTENT_TYPE
+    ) -> AsyncIterator[dict[str, Any]]:
+        """POST ``content`` once, without retries, and yield each JSON line of the response."""
+        headers = {**self._trace_headers(), "Content-Type": content_type}
+        await self._probe_endpoints()
+        index = self._endpoints.select()
+        try:
+            async with self._clients[index].stream(
+                "POST", path, content=content, headers=headers
+            ) as response:
+                self.last_request_id = response.headers.get(REQUEST_ID_HEADER)
+                if response.status_code >= 400:
+                    await response.aread()
+                    _raise_for_status(response)
+                async for line in response.aiter_lines():
+                    if line.strip():
+                        yield json.loads(line)
+        except httpx.HTTPError as exc:
+            self._endpoints.record_failure(index)
+            raise _transport_error(exc) from exc
+
+    def endpoint_health(self) -> list[EndpointHealth]:
+        return self._endpoints.health()
 
     async def aclose(self) -> None:
-        await self._client.aclose()
+        for client in self._clients:
+            await client.aclose()
+
+    async def _probe_endpoints(self) -> None:
+        for index in self._endpoints.claim_probes():
+            started = time.monotonic()
+            try:
+                response = await self._clients[index].get(
+                    HEALTH_PATH, timeout=HEALTH_CHECK_TIMEOUT_SECONDS
+                )
+            except httpx.HTTPError:
+                self._endpoints.record_failure(index)
+                continue
+            _record_probe(self._endpoints, index, response, started)
+
+    def _trace_headers(self) -> dict[str, str]:
+        if not self._config.propagate_trace_context:
+            return {}
+        return trace_headers(self._trace_context_provider)
 
     async def _sleep(self, attempt: int, retry_after: float | None = None) -> None:
         delay = _compute_backoff(
@@ -188,6 +379,42 @@ class AsyncOrbitHttpClient:
         await asyncio.sleep(delay)
 
 
+def _has_credentials(config: Config) -> bool:
+    if config.uses_request_signing:
+        return True
+    return all(endpoint.api_key or config.api_key for endpoint in config.resolved_endpoints())
+
+
+def _default_headers(config: Config, endpoint: Endpoint) -> dict[str, str]:
+    headers = {"User-Agent": config.user_agent}
+    if not config.uses_request_signing:
+        headers["Authorization"] = f"Bearer {endpoint.api_key or config.api_key}"
+    return headers
+
+
+def _request_auth(config: Config) -> HmacAuth | None:
+    if config.uses_request_signing and config.signing_key_id and config.signing_secret:
+        return HmacAuth(config.signing_key_id, config.signing_secret)
+    return None
+
+
+def _record_probe(
+    endpoints: EndpointPool, index: int, response: httpx.Response, started: float
+) -> None:
+    if is_endpoint_failure(response.status_code):
+        endpoints.record_failure(index)
+    else:
+        endpoints.record_success(index, time.monotonic() - started)
+
+
+def _transport_error(exc: httpx.HTTPError) -> OrbitError:
+    if isinstance(exc, httpx.TimeoutException):
+        msg = "Orbit request timed out"
+        return OrbitTimeoutError(msg)
+    msg = f"HTTP transport error: {exc!s}"
+    return OrbitServerError(msg)
+
+
 def _compute_backoff(
     attempt: int, backoff_factor: float, retry_after: float | None
 ) -> float:
@@ -209,19 +436,27 @@ def _retry_after_seconds(response: httpx.Response) -> float | None:
 def _raise_for_status(response: httpx.Response) -> None:
     if response.status_code < 400:
         return
+    error = _status_error(response)
+    error.request_id = response.headers.get(REQUEST_ID_HEADER)
+    raise error
+
+
+def _status_error(response: httpx.Response) -> OrbitError:
     message = _error_message(response)
     code = response.status_code
     if code in {401, 403}:
-        raise OrbitAuthError(message)
-    if code == 400:
-        raise OrbitValidationError(message)
+        return OrbitAuthError(message)
+    if code in {400, 422}:
+        return OrbitValidationError(message, errors=_field_errors(response))
     if code == 404:
-        raise OrbitNotFoundError(message)
+        return OrbitNotFoundError(message)
+    if code == 412:
+        return OrbitPreconditionFailedError(message)
     if code == 429:
-        raise OrbitRateLimitError(message, retry_after=_retry_after_seconds(response))
+        return OrbitRateLimitError(message, retry_after=_retry_after_seconds(response))
     if code >= 500:
-        raise OrbitServerError(message)
-    raise OrbitError(message)
+        return OrbitServerError(message)
+    return OrbitError(message)
 
 
 def _error_message(response: httpx.Response) -> str:
@@ -241,6 +476,15 @@ def _error_message(response: httpx.Response) -> str:
     return text if text else f"Orbit API error ({response.status_code})"
 
 
+def _field_errors(response: httpx.Response) -> list[dict[str, Any]]:
+    payload = _parse_payload(response)
+    detail = payload.get("detail") if isinstance(payload, dict) else None
+    errors = detail.get("errors") if isinstance(detail, dict) else None
+    if not isinstance(errors, list):
+        return []
+    return [item for item in errors if isinstance(item, dict)]
+
+
 def _parse_payload(response: httpx.Response) -> dict[str, Any] | list[Any]:
     if not response.content:
         return {}
@@ -2,16 +2,95 @@
 
 from __future__ import annotations
 
-from datetime import datetime
+import base64
+import binascii
+import math
+from datetime import date, datetime
 from typing import Any
 
-from pydantic import BaseModel, ConfigDict, Field, field_validator
+from pydantic import BaseModel, ConfigDict, Field, field_validator, model_validator
+
+# Memory sensitivity labels, least to most restricted.
+SENSITIVITY_LEVELS = ("public", "internal", "confidential")
+# What retrieval does when nothing clears the ranking threshold.
+ZERO_RESULT_FALLBACKS = ("empty", "recent", "attributes", "webhook")
+# Retrieval read guarantees: "strong" waits for pending indexing of earlier writes.
+CONSISTENCY_LEVELS = ("eventual", "strong")
+# How POST /v1/import writes: like a batch ingest, or as a bulk backfill that batches
+# embeddings and defers consolidation to the end of the request.
+IMPORT_MODES = ("standard", "backfill")
+# What ingest does with content over the size limit.
+OVERSIZE_ACTIONS = ("reject", "summarize", "chunk")
+# What the ingest pipeline's webhook stage does when the tenant's endpoint fails.
+WEBHOOK_FAILURE_POLICIES = ("continue", "drop", "reject")
+# Typed links between memories, read as "source <link_type> target".
+MEMORY_LINK_TYPES = ("supports", "contradicts", "elaborates", "caused_by")
+# Labels the ingest pipeline's sentiment stage attaches to memories.
+MEMORY_SENTIMENTS = ("positive", "neutral", "negative")
+MEMORY_EMOTIONS = ("frustration", "anger", "sadness", "anxiety", "confusion", "joy", "gratitude")
+# Who else can retrieve a memory an agent wrote: every agent, or only the writer (and the
+# agents ORBIT_AGENT_VISIBILITY lets read its private memories).
+AGENT_SCOPES = ("shared", "private")
+# Lifecycle of writes held for approval by a namespace's write policy.
+MEMORY_REVIEW_STATUSES = ("pending", "approved", "rejected")
+# Lifecycle of goals tracked from conversation.
+GOAL_STATUSES = ("open", "in_progress", "done")
+# Windows a digest of new memories can cover, in days.
+DIGEST_PERIODS = {"daily": 1, "weekly": 7}
+# File formats for scheduled change-log exports.
+EXPORT_FORMATS = ("jsonl", "parquet")
+# Named presets of retrieval options; options a request sets itself override its profile's.
+RETRIEVAL_PROFILES: dict[str, dict[str, Any]] = {
+    "balanced": {},
+    "fast": {"fast": True},
+    "thorough": {"mode": "graph", "graph_hops": 2, "include_linked": True},
+}
+# Largest multiplier a retrieval boost may apply.
+MAX_BOOST = 10.0
 
 
 class OrbitModel(BaseModel):
     model_config = ConfigDict(extra="forbid")
 
 
+def _normalize_fallback(value: str | None) -> str | None:
+    if value is None:
+        return None
+    normalized = value.strip().lower()
+    if normalized not in ZERO_RESULT_FALLBACKS:
+        msg = f"fallback must be one of: {', '.join(ZERO_RESULT_FALLBACKS)}"
+        raise ValueError(msg)
+    return normalized
+
+
+def _normalize_agent_id(value: str | None) -> str | None:
+    if value is None:
+        return None
+    normalized = value.strip()
+    if len(normalized) > 128:
+        msg = "agent_id cannot exceed 128 characters"
+        raise ValueError(msg)
+    return normalized or None
+
+
+def normalize_oversize_action(value: str | None) -> str | None:
+    if value is None:
+        return None
+    normalized = value.strip().lower()
+    if normalized not in OVERSIZE_ACTIONS:
+        msg = f"on_oversize must be one of: {', '.join(OVERSIZE_ACTIONS)}"
+        raise ValueError(msg)
+    return normalized
+
+
+def _normalize_consistency(value: str) -> str:
+    normalized = value.strip().lower()
+    if normalized not in CONSISTENCY_LEVELS:
+        msg = f"consistency must be one of: {', '.join(CONSISTENCY_LEVELS)}"
+        raise ValueError(msg)
+    return normalized
+
+
 class TimeRange(OrbitModel):
     start: datetime
     end: datetime
@@ -26,11 +105,54 @@ class TimeRange(OrbitModel):
         return value
 
 
+class IngestAttachment(OrbitModel):
+    content_base64: str
+    content_type: str = "application/octet-stream"
+    filename: str | None = None
+
+    @field_validator("content_base64")
+    @classmethod
+    def validate_content_base64(cls, value: str) -> str:
+        stripped = value.strip()
+        if not stripped:
+            msg = "attachment content cannot be empty"
+            raise ValueError(msg)
+        try:
+            base64.b64decode(stripped, validate=True)
+        except (binascii.Error, ValueError) as exc:
+            msg = "attachment content_base64 must be valid base64"
+            raise ValueError(msg) from exc
+        return stripped
+
+    @field_validator("content_type")
+    @classmethod
+    def validate_content_type(cls, value: str) -> str:
+        normalized = value.strip().lower()
+        if "/" not in normalized:
+            msg = "attachment content_type must be a MIME type"
+            raise ValueError(msg)
+        return normalized
+
+    def decoded(self) -> bytes:
+        return base64.b64decode(self.content_base64, validate=True)
+
+
 class IngestRequest(OrbitModel):
     content: str
     event_type: str | None = None
     metadata: dict[str, Any] | None = None
     entity_id: str | None = None
+    attachment: IngestAttachment | None = None
+    sensitivity: str | None = None
+    # Overrides ORBIT_ON_OVERSIZE for content over ORBIT_MAX_INGEST_CONTENT_CHARS.
+    on_oversize: str | None = None
+    # The agent writing the memory, and whether other agents can retrieve it (AGENT_SCOPES;
+    # ORBIT_DEFAULT_AGENT_SCOPE when omitted). A private memory needs an agent_id.
+    agent_id: str | None = None
+    agent_scope: str | None = None
+    # When the event happened, for backdated imports; naive values are UTC. Recency and
+    # time_range read it instead of the ingest time. Defaults to the server clock.
+    occurred_at: datetime | None = None
 
     @field_validator("content")
     @classmethod
@@ -41,6 +163,83 @@ class IngestRequest(OrbitModel):
             raise ValueError(msg)
         return stripped
 
+    @field_validator("on_oversize")
+    @classmethod
+    def validate_on_oversize(cls, value: str | None) -> str | None:
+        return normalize_oversize_action(value)
+
+    @field_validator("sensitivity")
+    @classmethod
+    def validate_sensitivity(cls, value: str | None) -> str | None:
+        if value is None:
+            return None
+        normalized = value.strip().lower()
+        if normalized not in SENSITIVITY_LEVELS:
+            msg = f"sensitivity must be one of: {', '.join(SENSITIVITY_LEVELS)}"
+            raise ValueError(msg)
+        return normalized
+
+    @field_validator("agent_id")
+    @classmethod
+    def validate_agent_id(cls, value: str | None) -> str | None:
+        return _normalize_agent_id(value)
+
+    @field_validator("agent_scope")
+    @classmethod
+    def validate_agent_scope(cls, value: str | None) -> str | None:
+        if value is None:
+            return None
+        normalized = value.strip().lower()
+        if normalized not in AGENT_SCOPES:
+            msg = f"agent_scope must be one of: {', '.join(AGENT_SCOPES)}"
+            raise ValueError(msg)
+        return normalized
+
+    @model_validator(mode="after")
+    def validate_private_agent_scope(self) -> IngestRequest:
+        if self.agent_scope == "private" and self.agent_id is None:
+            msg = "agent_scope=private requires agent_id"
+            raise ValueError(msg)
+        return self
+
+
+class CaptureRequest(OrbitModel):
+    url: str
+    title: str | None = None
+    selection: str | None = None
+    favicon_url: str | None = None
+    note: str | None = None
+    tags: list[str] = Field(default_factory=list)
+    entity_id: str | None = None
+
+    @field_validator("url")
+    @classmethod
+    def validate_url(cls, value: str) -> str:
+        stripped = value.strip()
+        if not stripped.lower().startswith(("http://", "https://")):
+            msg = "url must be an http(s) URL"
+            raise ValueError(msg)
+        if len(stripped) > 2048:
+            msg = "url cannot exceed 2048 characters"
+            raise ValueError(msg)
+        return stripped
+
+    @field_validator("tags")
+    @classmethod
+    def validate_tags(cls, value: list[str]) -> list[str]:
+        normalized = [item.strip() for item in value if item.strip()]
+        return list(dict.fromkeys(normalized))
+
+
+class DocumentRevision(OrbitModel):
+    # The chunk_group of the earlier upload this one revised; its chunks keep it.
+    document_id: str
+    similarity: float
+    unchanged: int
+    updated: int
+    added: int
+    removed: int
+
 
 class IngestResponse(OrbitModel):
     memory_id: str
@@ -49,6 +248,15 @@ class IngestResponse(OrbitModel):
     decision_reason: str
     encoded_at: datetime
     latency_ms: float
+    # Set when oversized content was summarized or chunked; chunking stores one memory per
+    # chunk and memory_id is the first of chunk_memory_ids.
+    oversize_action: str | None = None
+    chunk_memory_ids: list[str] = Field(default_factory=list)
+    # Set when the write policy held the memory for approval; nothing is stored until the
+    # review is approved.
+    review_id: str | None = None
+    # Set when a chunked document revised an earlier upload instead of being stored again.
+    revision: DocumentRevision | None = None
 
 
 class Memory(OrbitModel):
@@ -57,9 +265,22 @@ class Memory(OrbitModel):
     rank_position: int
     rank_score: float
     importance_score: float
+    # When the memory's event happened (its occurred_at); created_at is when it was stored.
     timestamp: datetime
     metadata: dict[str, Any] = Field(default_factory=dict)
     relevance_explanation: str
+    created_at: datetime | None = None
+    # Content version, set by update and revert responses; send it back as ``If-Match``.
+    version: int | None = None
+    # Stored semantic embedding, returned only for ``fields=embedding``.
+    embedding: list[float] | None = None
+
+
+class RetrieveSummary(OrbitModel):
+    text: str
+    # Memory IDs cited in ``text``, in order of first citation.
+    citations: list[str]
+    model: str
 
 
 class RetrieveResponse(OrbitModel):
@@ -67,6 +288,15 @@ class RetrieveResponse(OrbitModel):
     total_candidates: int
     query_execution_time_ms: float
     applied_filters: dict[str, Any] = Field(default_factory=dict)
+    degraded: bool = False
+    skipped_stages: list[str] = Field(default_factory=list)
+    # Set when nothing matched and a zero-result fallback ran.
+    fallback: str | None = None
+    attributes: dict[str, Any] | None = None
+    # Set for summarize=true; ``memories`` is then empty.
+    summary: RetrieveSummary | None = None
+    # True when a fast=true retrieval was answered from the result cache.
+    cached: bool = False
 
 
 class FeedbackRequest(OrbitModel):
@@ -186,12 +416,68 @@ class TenantMetricsResponse(OrbitModel):
     pilot_pro_requested_at: datetime | None = None
 
 
+class RetrieveBoost(OrbitModel):
+    """Score multipliers for memories that match the caller's current context.
+
+    Keys are tags (``metadata.tags`` at ingest), event types, and categories. A memory that
+    matches several keys is multiplied by each of them; below 1 demotes instead.
+    """
+
+    tags: dict[str, float] = Field(default_factory=dict, max_length=50)
+    event_type: dict[str, float] = Field(default_factory=dict, max_length=50)
+    category: dict[str, float] = Field(default_factory=dict, max_length=50)
+
+    @field_validator("tags", "event_type", "category")
+    @classmethod
+    def validate_multipliers(cls, value: dict[str, float], info: Any) -> dict[str, float]:
+        normalized: dict[str, float] = {}
+        for key, multiplier in value.items():
+            name = key.strip().lower() if info.field_name == "category" else key.strip()
+            if not name:
+                msg = f"boost {info.field_name} keys cannot be empty"
+                raise ValueError(msg)
+            if not 0.0 <= multiplier <= MAX_BOOST:
+                msg = f"boost {info.field_name}[{name}] must be between 0 and {MAX_BOOST:g}"
+                raise ValueError(msg)
+            normalized[name] = multiplier
+        return normalized
+
+
 class RetrieveRequest(OrbitModel):
     query: str
     limit: int = 10
     entity_id: str | None = None
     event_type: str | None = None
     time_range: TimeRange | None = None
+    max_latency_ms: int | None = None
+    session_id: str | None = None
+    mode: str = "vector"
+    graph_hops: int = 1
+    topic_id: str | None = None
+    min_score: float | None = None
+    fallback: str | None = None
+    consistency: str = "eventual"
+    include_linked: bool = False
+    summarize: bool = False
+    # Low-latency path for voice agents: base ranking only, served from cache when possible.
+    fast: bool = False
+    # Labels from the sentiment pipeline stage; memories without them never match.
+    sentiment: str | None = None
+    emotion: str | None = None
+    # Category from the categorization stage; the service checks it against the taxonomy.
+    category: str | None = None
+    # The agent asking, which sees its own private memories (and those ORBIT_AGENT_VISIBILITY
+    # grants it), and an optional filter to memories written by one agent.
+    agent_id: str | None = None
+    from_agent: str | None = None
+    # A RETRIEVAL_PROFILES preset applied under the request's own options.
+    profile: str | None = None
+    # Per-request score multipliers, e.g. for the project or screen the user is on.
+    boost: RetrieveBoost | None = None
+    # Maximal marginal relevance: each result is picked for relevance minus its similarity to
+    # the results already picked. ``mmr_lambda`` weighs the two; 1 is the plain ranking.
+    mmr: bool = False
+    mmr_lambda: float = 0.5
 
     @field_validator("query")
     @classmethod
@@ -202,6 +488,17 @@ class RetrieveRequest(OrbitModel):
             raise ValueError(msg)
         return stripped
 
+    @field_validator("profile")
+    @classmethod
+    def validate_profile(cls, value: str | None) -> str | None:
+        if value is None:
+            return None
+        normalized = value.strip().lower()
+        if normalized not in RETRIEVAL_PROFILES:
+            msg = f"profile must be one of: {', '.join(RETRIEVAL_PROFILES)}"
+            raise ValueError(msg)
+        return normalized
+
     @field_validator("limit")
     @classmethod
     def validate_limit(cls, value: int) -> int:
@@ -210,118 +507,1717 @@ class RetrieveRequest(OrbitModel):
             raise ValueError(msg)
         return value
 
+    @field_validator("max_latency_ms")
+    @classmethod
+    def validate_max_latency_ms(cls, value: int | None) -> int | None:
+        if value is not None and not 1 <= value <= 60_000:
+            msg = "max_latency_ms must be between 1 and 60000"
+            raise ValueError(msg)
+        return value
 
-class IngestBatchRequest(OrbitModel):
-    events: list[IngestRequest] = Field(min_length=1, max_length=100)
+    @field_validator("mode")
+    @classmethod
+    def validate_mode(cls, value: str) -> str:
+        normalized = value.strip().lower()
+        if normalized not in {"vector", "graph"}:
+            msg = "mode must be one of: vector, graph"
+            raise ValueError(msg)
+        return normalized
 
+    @field_validator("graph_hops")
+    @classmethod
+    def validate_graph_hops(cls, value: int) -> int:
+        if not 1 <= value <= 2:
+            msg = "graph_hops must be 1 or 2"
+            raise ValueError(msg)
+        return value
 
-class IngestBatchResponse(OrbitModel):
-    items: list[IngestResponse]
+    @field_validator("min_score")
+    @classmethod
+    def validate_min_score(cls, value: float | None) -> float | None:
+        if value is not None and value < 0.0:
+            msg = "min_score must be >= 0"
+            raise ValueError(msg)
+        return value
 
+    @field_validator("mmr_lambda")
+    @classmethod
+    def validate_mmr_lambda(cls, value: float) -> float:
+        if not 0.0 <= value <= 1.0:
+            msg = "mmr_lambda must be between 0 and 1"
+            raise ValueError(msg)
+        return value
 
-class FeedbackBatchRequest(OrbitModel):
-    feedback: list[FeedbackRequest] = Field(min_length=1, max_length=100)
+    @field_validator("fallback")
+    @classmethod
+    def validate_fallback(cls, value: str | None) -> str | None:
+        return _normalize_fallback(value)
 
+    @field_validator("consistency")
+    @classmethod
+    def validate_consistency(cls, value: str) -> str:
+        return _normalize_consistency(value)
 
-class FeedbackBatchResponse(OrbitModel):
-    items: list[FeedbackResponse]
+    @field_validator("sentiment")
+    @classmethod
+    def validate_sentiment(cls, value: str | None) -> str | None:
+        if value is None:
+            return None
+        normalized = value.strip().lower()
+        if normalized not in MEMORY_SENTIMENTS:
+            msg = f"sentiment must be one of: {', '.join(MEMORY_SENTIMENTS)}"
+            raise ValueError(msg)
+        return normalized
+
+    @field_validator("emotion")
+    @classmethod
+    def validate_emotion(cls, value: str | None) -> str | None:
+        if value is None:
+            return None
+        normalized = value.strip().lower()
+        if normalized not in MEMORY_EMOTIONS:
+            msg = f"emotion must be one of: {', '.join(MEMORY_EMOTIONS)}"
+            raise ValueError(msg)
+        return normalized
 
+    @field_validator("category")
+    @classmethod
+    def validate_category(cls, value: str | None) -> str | None:
+        if value is None:
+            return None
+        normalized = value.strip().lower()
+        return normalized or None
 
-class AuthValidationResponse(OrbitModel):
-    valid: bool
-    scopes: list[str] = Field(default_factory=list)
+    @field_validator("agent_id", "from_agent")
+    @classmethod
+    def validate_agent_ids(cls, value: str | None) -> str | None:
+        return _normalize_agent_id(value)
+
+    @model_validator(mode="after")
+    def validate_fast(self) -> RetrieveRequest:
+        if self.fast and (
+            self.mode == "graph"
+            or self.summarize
+            or self.include_linked
+            or self.consistency == "strong"
+        ):
+            msg = (
+                "fast cannot be combined with mode=graph, summarize, include_linked "
+                "or consistency=strong"
+            )
+            raise ValueError(msg)
+        return self
 
 
-class ApiKeyCreateRequest(OrbitModel):
+class RetrieveDefaults(OrbitModel):
+    """Retrieval options a namespace applies to requests that leave them out."""
+
+    limit: int | None = None
+    min_score: float | None = None
+    profile: str | None = None
+    event_type: str | None = None
+    category: str | None = None
+    sentiment: str | None = None
+    emotion: str | None = None
+    from_agent: str | None = None
+    mmr: bool | None = None
+    mmr_lambda: float | None = None
+
+    @model_validator(mode="after")
+    def validate_options(self) -> RetrieveDefaults:
+        # Checked and normalized exactly as a request carrying the same options would be.
+        options = self.model_dump(exclude_none=True)
+        checked = RetrieveRequest(query="defaults", **options)
+        for name in options:
+            setattr(self, name, getattr(checked, name))
+        return self
+
+
+class RetrieveNamespace(OrbitModel):
+    """One scope of a fan-out retrieval, e.g. a user's memory or an org knowledge base."""
+
     name: str
-    scopes: list[str] = Field(default_factory=list)
+    entity_id: str | None = None
+    event_type: str | None = None
+    weight: float = 1.0
+    limit: int | None = None
+    fallback: str | None = None
 
     @field_validator("name")
     @classmethod
     def validate_name(cls, value: str) -> str:
-        normalized = value.strip()
-        if not normalized:
-            msg = "name cannot be empty"
+        stripped = value.strip()
+        if not stripped:
+            msg = "namespace name cannot be empty"
             raise ValueError(msg)
-        if len(normalized) > 128:
-            msg = "name cannot exceed 128 characters"
+        return stripped
+
+    @field_validator("weight")
+    @classmethod
+    def validate_weight(cls, value: float) -> float:
+        if not 0.0 < value <= 10.0:
+            msg = "weight must be greater than 0 and at most 10"
             raise ValueError(msg)
-        return normalized
+        return value
 
-    @field_validator("scopes")
+    @field_validator("fallback")
     @classmethod
-    def validate_scopes(cls, value: list[str]) -> list[str]:
-        normalized = [item.strip() for item in value if item.strip()]
-        return list(dict.fromkeys(normalized))
+    def validate_fallback(cls, value: str | None) -> str | None:
+        return _normalize_fallback(value)
 
+    @field_validator("limit")
+    @classmethod
+    def validate_limit(cls, value: int | None) -> int | None:
+        if value is not None and not 1 <= value <= 100:
+            msg = "limit must be between 1 and 100"
+            raise ValueError(msg)
+        return value
 
-class ApiKeySummary(OrbitModel):
-    key_id: str
-    name: str
-    key_prefix: str
-    scopes: list[str] = Field(default_factory=list)
-    status: str
-    created_at: datetime
-    last_used_at: datetime | None = None
-    last_used_source: str | None = None
-    revoked_at: datetime | None = None
 
+class FanoutRetrieveRequest(OrbitModel):
+    query: str
+    limit: int = 10
+    namespaces: list[RetrieveNamespace] = Field(min_length=1, max_length=10)
+    time_range: TimeRange | None = None
+    max_latency_ms: int | None = None
 
-class ApiKeyIssueResponse(ApiKeySummary):
-    key: str
+    @field_validator("query")
+    @classmethod
+    def validate_query(cls, value: str) -> str:
+        stripped = value.strip()
+        if not stripped:
+            msg = "query cannot be empty"
+            raise ValueError(msg)
+        return stripped
 
+    @field_validator("limit")
+    @classmethod
+    def validate_limit(cls, value: int) -> int:
+        if not 1 <= value <= 100:
+            msg = "limit must be between 1 and 100"
+            raise ValueError(msg)
+        return value
 
-class ApiKeyListResponse(OrbitModel):
-    data: list[ApiKeySummary]
-    cursor: str | None = None
-    has_more: bool = False
+    @field_validator("max_latency_ms")
+    @classmethod
+    def validate_max_latency_ms(cls, value: int | None) -> int | None:
+        if value is not None and not 1 <= value <= 60_000:
+            msg = "max_latency_ms must be between 1 and 60000"
+            raise ValueError(msg)
+        return value
 
+    @field_validator("namespaces")
+    @classmethod
+    def validate_namespaces(cls, value: list[RetrieveNamespace]) -> list[RetrieveNamespace]:
+        names = [item.name for item in value]
+        if len(set(names)) != len(names):
+            msg = "namespace names must be unique"
+            raise ValueError(msg)
+        return value
 
-class ApiKeyRevokeResponse(OrbitModel):
-    key_id: str
-    revoked: bool
-    revoked_at: datetime | None = None
 
+class NamespaceRetrieveSummary(OrbitModel):
+    name: str
+    weight: float
+    returned: int
+    total_candidates: int
+    query_execution_time_ms: float
+    degraded: bool = False
+    fallback: str | None = None
+    attributes: dict[str, Any] | None = None
 
-class ApiKeyRotateRequest(OrbitModel):
-    name: str | None = None
-    scopes: list[str] | None = None
 
-    @field_validator("name")
+class FanoutRetrieveResponse(OrbitModel):
+    memories: list[Memory]
+    namespaces: list[NamespaceRetrieveSummary]
+    query_execution_time_ms: float
+    degraded: bool = False
+
+
+class BatchRetrieveRequest(OrbitModel):
+    """Independent queries that share one set of retrieval options."""
+
+    queries: list[str] = Field(min_length=1, max_length=100)
+    limit: int = 10
+    entity_id: str | None = None
+    event_type: str | None = None
+    time_range: TimeRange | None = None
+    max_latency_ms: int | None = None
+    mode: str = "vector"
+    graph_hops: int = 1
+    min_score: float | None = None
+    fallback: str | None = None
+    consistency: str = "eventual"
+    profile: str | None = None
+    boost: RetrieveBoost | None = None
+    mmr: bool = False
+    mmr_lambda: float = 0.5
+
+    @field_validator("profile")
     @classmethod
-    def validate_optional_name(cls, value: str | None) -> str | None:
-        if value is None:
-            return None
-        normalized = value.strip()
-        if not normalized:
-            msg = "name cannot be empty when provided"
+    def validate_profile(cls, value: str | None) -> str | None:
+        return RetrieveRequest.validate_profile(value)
+
+    @field_validator("queries")
+    @classmethod
+    def validate_queries(cls, value: list[str]) -> list[str]:
+        stripped = [item.strip() for item in value]
+        if not all(stripped):
+            msg = "queries cannot be empty"
             raise ValueError(msg)
-        if len(normalized) > 128:
-            msg = "name cannot exceed 128 characters"
+        return stripped
+
+    @field_validator("limit")
+    @classmethod
+    def validate_limit(cls, value: int) -> int:
+        if not 1 <= value <= 100:
+            msg = "limit must be between 1 and 100"
+            raise ValueError(msg)
+        return value
+
+    @field_validator("max_latency_ms")
+    @classmethod
+    def validate_max_latency_ms(cls, value: int | None) -> int | None:
+        if value is not None and not 1 <= value <= 60_000:
+            msg = "max_latency_ms must be between 1 and 60000"
+            raise ValueError(msg)
+        return value
+
+    @field_validator("mode")
+    @classmethod
+    def validate_mode(cls, value: str) -> str:
+        normalized = value.strip().lower()
+        if normalized not in {"vector", "graph"}:
+            msg = "mode must be one of: vector, graph"
             raise ValueError(msg)
         return normalized
 
-    @field_validator("scopes")
+    @field_validator("graph_hops")
     @classmethod
-    def validate_optional_scopes(cls, value: list[str] | None) -> list[str] | None:
-        if value is None:
-            return None
-        normalized = [item.strip() for item in value if item.strip()]
-        return list(dict.fromkeys(normalized))
+    def validate_graph_hops(cls, value: int) -> int:
+        if not 1 <= value <= 2:
+            msg = "graph_hops must be 1 or 2"
+            raise ValueError(msg)
+        return value
 
+    @field_validator("min_score")
+    @classmethod
+    def validate_min_score(cls, value: float | None) -> float | None:
+        if value is not None and value < 0.0:
+            msg = "min_score must be >= 0"
+            raise ValueError(msg)
+        return value
 
-class ApiKeyRotateResponse(OrbitModel):
-    revoked_key_id: str
-    new_key: ApiKeyIssueResponse
+    @field_validator("mmr_lambda")
+    @classmethod
+    def validate_mmr_lambda(cls, value: float) -> float:
+        if not 0.0 <= value <= 1.0:
+            msg = "mmr_lambda must be between 0 and 1"
+            raise ValueError(msg)
+        return value
 
+    @field_validator("fallback")
+    @classmethod
+    def validate_fallback(cls, value: str | None) -> str | None:
+        return _normalize_fallback(value)
 
-class PilotProRequestResponse(OrbitModel):
-    request: PilotProRequest
-    created: bool
-    email_sent: bool
+    @field_validator("consistency")
+    @classmethod
+    def validate_consistency(cls, value: str) -> str:
+        return _normalize_consistency(value)
 
 
-class PaginatedMemoriesResponse(OrbitModel):
-    data: list[Memory]
-    cursor: str | None = None
-    has_more: bool
+class BatchRetrieveResponse(OrbitModel):
+    # One result per query, in request order.
+    results: list[RetrieveResponse]
+    query_execution_time_ms: float
+    degraded: bool = False
+
+
+class VectorSearchRequest(OrbitModel):
+    """Nearest memories to an embedding the caller computed with Orbit's embedding model."""
+
+    vector: list[float] = Field(min_length=1, max_length=8192)
+    limit: int = 10
+    entity_id: str | None = None
+    event_type: str | None = None
+    time_range: TimeRange | None = None
+    min_score: float | None = None
+    # The calling agent; private memories of other agents are left out, as in retrieval.
+    agent_id: str | None = None
+
+    @field_validator("vector")
+    @classmethod
+    def validate_vector(cls, value: list[float]) -> list[float]:
+        if not all(math.isfinite(item) for item in value):
+            msg = "vector values must be finite"
+            raise ValueError(msg)
+        if not any(value):
+            msg = "vector cannot be all zeros"
+            raise ValueError(msg)
+        return value
+
+    @field_validator("limit")
+    @classmethod
+    def validate_limit(cls, value: int) -> int:
+        if not 1 <= value <= 100:
+            msg = "limit must be between 1 and 100"
+            raise ValueError(msg)
+        return value
+
+    @field_validator("min_score")
+    @classmethod
+    def validate_min_score(cls, value: float | None) -> float | None:
+        if value is not None and value < 0.0:
+            msg = "min_score must be >= 0"
+            raise ValueError(msg)
+        return value
+
+    @field_validator("agent_id")
+    @classmethod
+    def validate_agent_id(cls, value: str | None) -> str | None:
+        return _normalize_agent_id(value)
+
+
+class SimilarMemoriesResponse(OrbitModel):
+    memory_id: str
+    # Nearest neighbors, most similar first; ``rank_score`` is the cosine similarity.
+    memories: list[Memory]
+    query_execution_time_ms: float
+
+
+class IngestBatchRequest(OrbitModel):
+    events: list[IngestRequest] = Field(min_length=1, max_length=100)
+
+
+class IngestBatchResponse(OrbitModel):
+    items: list[IngestResponse]
+
+
+class IngestStreamAck(OrbitModel):
+    """One line of the POST /v1/ingest/stream response, acknowledging one request line."""
+
+    # 1-based line number in the request body; blank lines get no ack.
+    line: int
+    ok: bool
+    # The status the line would have had as its own POST /v1/ingest.
+    status_code: int
+    result: IngestResponse | None = None
+    error: str | None = None
+
+
+class ImportRequest(OrbitModel):
+    # Up to ORBIT_MAX_IMPORT_ITEMS; set occurred_at on each to keep historical times.
+    events: list[IngestRequest] = Field(min_length=1)
+
+
+class ImportResponse(OrbitModel):
+    mode: str
+    items: list[IngestResponse]
+    imported: int
+    # Events not stored: duplicates, discarded by the storage decision, sampled, or held.
+    skipped: int
+    duration_ms: float
+    events_per_second: float
+
+
+class FeedbackBatchRequest(OrbitModel):
+    feedback: list[FeedbackRequest] = Field(min_length=1, max_length=100)
+
+
+class FeedbackBatchResponse(OrbitModel):
+    items: list[FeedbackResponse]
+
+
+class AuthValidationResponse(OrbitModel):
+    valid: bool
+    scopes: list[str] = Field(default_factory=list)
+
+
+class ApiKeyCreateRequest(OrbitModel):
+    name: str
+    scopes: list[str] = Field(default_factory=list)
+    allowed_origins: list[str] = Field(default_factory=list)
+
+    @field_validator("name")
+    @classmethod
+    def validate_name(cls, value: str) -> str:
+        normalized = value.strip()
+        if not normalized:
+            msg = "name cannot be empty"
+            raise ValueError(msg)
+        if len(normalized) > 128:
+            msg = "name cannot exceed 128 characters"
+            raise ValueError(msg)
+        return normalized
+
+    @field_validator("scopes", "allowed_origins")
+    @classmethod
+    def validate_scopes(cls, value: list[str]) -> list[str]:
+        normalized = [item.strip() for item in value if item.strip()]
+        return list(dict.fromkeys(normalized))
+
+
+class ApiKeySummary(OrbitModel):
+    key_id: str
+    name: str
+    key_prefix: str
+    scopes: list[str] = Field(default_factory=list)
+    allowed_origins: list[str] = Field(default_factory=list)
+    status: str
+    created_at: datetime
+    last_used_at: datetime | None = None
+    last_used_source: str | None = None
+    revoked_at: datetime | None = None
+
+
+class ApiKeyIssueResponse(ApiKeySummary):
+    key: str
+
+
+class ApiKeyListResponse(OrbitModel):
+    data: list[ApiKeySummary]
+    cursor: str | None = None
+    has_more: bool = False
+
+
+class ApiKeyRevokeResponse(OrbitModel):
+    key_id: str
+    revoked: bool
+    revoked_at: datetime | None = None
+
+
+class ApiKeyRotateRequest(OrbitModel):
+    name: str | None = None
+    scopes: list[str] | None = None
+    allowed_origins: list[str] | None = None
+
+    @field_validator("name")
+    @classmethod
+    def validate_optional_name(cls, value: str | None) -> str | None:
+        if value is None:
+            return None
+        normalized = value.strip()
+        if not normalized:
+            msg = "name cannot be empty when provided"
+            raise ValueError(msg)
+        if len(normalized) > 128:
+            msg = "name cannot exceed 128 characters"
+            raise ValueError(msg)
+        return normalized
+
+    @field_validator("scopes", "allowed_origins")
+    @classmethod
+    def validate_optional_scopes(cls, value: list[str] | None) -> list[str] | None:
+        if value is None:
+            return None
+        normalized = [item.strip() for item in value if item.strip()]
+        return list(dict.fromkeys(normalized))
+
+
+class ApiKeyRotateResponse(OrbitModel):
+    revoked_key_id: str
+    new_key: ApiKeyIssueResponse
+
+
+class BrowserTokenRequest(OrbitModel):
+    entity_id: str | None = None
+    scopes: list[str] = Field(default_factory=lambda: ["read"])
+    allowed_origins: list[str] = Field(default_factory=list)
+    ttl_seconds: int = 900
+
+    @field_validator("scopes", "allowed_origins")
+    @classmethod
+    def validate_lists(cls, value: list[str]) -> list[str]:
+        normalized = [item.strip() for item in value if item.strip()]
+        return list(dict.fromkeys(normalized))
+
+    @field_validator("ttl_seconds")
+    @classmethod
+    def validate_ttl_seconds(cls, value: int) -> int:
+        if value < 1:
+            msg = "ttl_seconds must be >= 1"
+            raise ValueError(msg)
+        return value
+
+
+class BrowserTokenResponse(OrbitModel):
+    token: str
+    token_type: str = "bearer"
+    expires_at: datetime
+    scopes: list[str]
+    entity_id: str | None = None
+    allowed_origins: list[str] = Field(default_factory=list)
+
+
+class PilotProRequestResponse(OrbitModel):
+    request: PilotProRequest
+    created: bool
+    email_sent: bool
+
+
+class PaginatedMemoriesResponse(OrbitModel):
+    data: list[Memory]
+    cursor: str | None = None
+    has_more: bool
+
+
+class HookMemory(OrbitModel):
+    """Flat memory shape for no-code platforms (Zapier, Make)."""
+
+    id: str
+    content: str
+    entity_id: str | None = None
+    event_type: str | None = None
+    created_at: datetime
+    importance_score: float
+
+
+class HookIngestResponse(HookMemory):
+    stored: bool
+    decision_reason: str
+
+
+class MemoryChange(OrbitModel):
+    sequence: int
+    memory_id: str
+    operation: str
+    occurred_at: datetime
+    memory: dict[str, Any] | None = None
+
+
+class MemoryUpdateRequest(OrbitModel):
+    content: str
+
+    @field_validator("content")
+    @classmethod
+    def validate_content(cls, value: str) -> str:
+        stripped = value.strip()
+        if not stripped:
+            msg = "content cannot be empty"
+            raise ValueError(msg)
+        return stripped
+
+
+class MemoryRevertRequest(OrbitModel):
+    version: int = Field(ge=1)
+
+
+class MemoryVersion(OrbitModel):
+    version: int
+    operation: str
+    content: str
+    summary: str | None = None
+    recorded_at: datetime
+    reverted_from: int | None = None
+
+
+class MemoryVersionListResponse(OrbitModel):
+    memory_id: str
+    current_version: int
+    versions: list[MemoryVersion]
+    deleted_at: datetime | None = None
+    superseded_by: str | None = None
+
+
+class MemoryDiffResponse(OrbitModel):
+    memory_id: str
+    from_version: int
+    to_version: int
+    diff: str
+
+
+class ChangeFeedResponse(OrbitModel):
+    data: list[MemoryChange]
+    cursor: str | None = None
+    has_more: bool = False
+
+
+class QueryStat(OrbitModel):
+    query: str
+    count: int
+    zero_result_count: int
+    last_seen_at: datetime
+
+
+class QueryDailyStats(OrbitModel):
+    day: date
+    queries: int
+    zero_result_queries: int
+    latency_p50_ms: float
+    latency_p95_ms: float
+    latency_p99_ms: float
+
+
+class QueryAnalyticsResponse(OrbitModel):
+    days: int
+    total_queries: int
+    zero_result_rate: float
+    top_queries: list[QueryStat]
+    zero_result_queries: list[QueryStat]
+    daily: list[QueryDailyStats]
+
+
+class AdminTenant(OrbitModel):
+    account_key: str
+    plan: str
+    memory_count: int
+    active_api_keys: int
+    events_this_month: int
+    queries_this_month: int
+    last_activity_at: datetime | None = None
+
+
+class AdminTenantListResponse(OrbitModel):
+    data: list[AdminTenant]
+
+
+class AdminEntity(OrbitModel):
+    entity_id: str
+    memory_count: int
+    last_memory_at: datetime
+
+
+class AdminEntityListResponse(OrbitModel):
+    account_key: str
+    data: list[AdminEntity]
+
+
+class AdminAnomaly(OrbitModel):
+    id: int
+    account_key: str
+    key_id: str
+    kind: str
+    detail: dict[str, Any]
+    webhook_status: str
+    detected_at: datetime
+
+
+class AdminAnomalyListResponse(OrbitModel):
+    data: list[AdminAnomaly]
+
+
+class TenantResidencyRequest(OrbitModel):
+    home_region: str = Field(min_length=1, max_length=32)
+    replica_regions: list[str] = Field(default_factory=list, max_length=16)
+    pinned: bool = False
+
+    @field_validator("home_region")
+    @classmethod
+    def normalize_home_region(cls, value: str) -> str:
+        return value.strip().lower()
+
+    @field_validator("replica_regions")
+    @classmethod
+    def normalize_replica_regions(cls, value: list[str]) -> list[str]:
+        return list(dict.fromkeys(item.strip().lower() for item in value if item.strip()))
+
+
+class TenantResidency(OrbitModel):
+    account_key: str
+    home_region: str
+    replica_regions: list[str]
+    pinned: bool
+    updated_at: datetime
+    propagated_to: list[str] = Field(default_factory=list)
+
+
+class ReplicatedApiKey(OrbitModel):
+    key_id: str
+    name: str
+    key_prefix: str
+    secret_salt: str
+    secret_hash: str
+    hash_iterations: int
+    scopes: list[str]
+    allowed_origins: list[str]
+    status: str
+    created_at: datetime
+    revoked_at: datetime | None = None
+
+
+class ReplicationBatch(OrbitModel):
+    account_key: str
+    region: str
+    changes: list[MemoryChange]
+    api_keys: list[ReplicatedApiKey]
+    cursor: str | None = None
+    has_more: bool = False
+
+
+class OptimizeStep(OrbitModel):
+    name: str
+    status: str
+    duration_ms: float | None = None
+    detail: dict[str, Any] = Field(default_factory=dict)
+
+
+class OptimizeJob(OrbitModel):
+    job_id: str
+    status: str
+    trigger: str
+    progress: float = Field(ge=0.0, le=1.0)
+    current_step: str | None = None
+    steps: list[OptimizeStep]
+    error: str | None = None
+    created_at: datetime
+    started_at: datetime | None = None
+    finished_at: datetime | None = None
+
+
+class OptimizeJobListResponse(OrbitModel):
+    jobs: list[OptimizeJob]
+
+
+class IndexDeploymentRequest(OrbitModel):
+    embedding_provider: str | None = Field(default=None, min_length=1, max_length=64)
+    chunk_chars: int | None = Field(default=None, ge=200, le=20_000)
+    quantization: str = "none"
+    mirror_percent: float = Field(default=0.0, ge=0.0, le=100.0)
+
+
+class IndexMirrorRequest(OrbitModel):
+    mirror_percent: float = Field(ge=0.0, le=100.0)
+
+
+class IndexComparison(OrbitModel):
+    mirrored_queries: int = 0
+    mean_overlap: float | None = None
+    top_result_agreement: float | None = None
+    production_latency_ms: float | None = None
+    candidate_latency_ms: float | None = None
+
+
+class IndexDeployment(OrbitModel):
+    deployment_id: str
+    status: str
+    embedding_provider: str | None = None
+    chunk_chars: int | None = None
+    quantization: str
+    mirror_percent: float
+    built: bool
+    indexed_memories: int
+    comparison: IndexComparison
+    error: str | None = None
+    created_at: datetime
+    ready_at: datetime | None = None
+    promoted_at: datetime | None = None
+    retired_at: datetime | None = None
+
+
+class IndexDeploymentListResponse(OrbitModel):
+    serving: str
+    data: list[IndexDeployment]
+
+
+class ModerationReview(OrbitModel):
+    id: int
+    account_key: str
+    entity_id: str | None = None
+    action: str
+    categories: list[str]
+    provider: str
+    status: str
+    content: str
+    content_sha256: str | None = None
+    memory_id: str | None = None
+    appeal_reason: str | None = None
+    resolution_note: str | None = None
+    created_at: datetime
+    updated_at: datetime
+
+
+class ModerationReviewListResponse(OrbitModel):
+    data: list[ModerationReview]
+
+
+class ModerationAppealRequest(OrbitModel):
+    reason: str = Field(min_length=1, max_length=2000)
+
+
+class PipelineWebhookRequest(OrbitModel):
+    url: str = Field(min_length=1, max_length=2048)
+    secret: str | None = Field(default=None, max_length=256)
+    timeout_ms: int = Field(default=2000, ge=100, le=10_000)
+    failure_policy: str = "continue"
+
+    @field_validator("url")
+    @classmethod
+    def validate_url(cls, value: str) -> str:
+        normalized = value.strip()
+        if not normalized.startswith(("https://", "http://")):
+            msg = "url must be an http(s) URL"
+            raise ValueError(msg)
+        return normalized
+
+    @field_validator("failure_policy")
+    @classmethod
+    def validate_failure_policy(cls, value: str) -> str:
+        normalized = value.strip().lower()
+        if normalized not in WEBHOOK_FAILURE_POLICIES:
+            msg = f"failure_policy must be one of: {', '.join(WEBHOOK_FAILURE_POLICIES)}"
+            raise ValueError(msg)
+        return normalized
+
+
+class PipelineWebhook(OrbitModel):
+    url: str
+    has_secret: bool
+    timeout_ms: int
+    failure_policy: str
+    updated_at: datetime
+
+
+class NamespaceRequest(OrbitModel):
+    display_name: str | None = Field(default=None, max_length=128)
+    description: str | None = Field(default=None, max_length=1024)
+    # Overrides ORBIT_PIPELINE_STAGES / ORBIT_PIPELINE_NAMESPACES for this namespace.
+    pipeline: list[str] | None = Field(default=None, max_length=32)
+    # Makes the namespace a sandbox: usage is not counted against quota and everything in it
+    # is wiped this many seconds after the request.
+    ttl_seconds: int | None = Field(default=None, ge=60, le=2_592_000)
+    # Used by every retrieval in the namespace for the options the request leaves out.
+    retrieve_defaults: RetrieveDefaults | None = None
+
+
+class Namespace(OrbitModel):
+    account_key: str
+    display_name: str | None = None
+    description: str | None = None
+    pipeline: list[str] | None = None
+    retrieve_defaults: RetrieveDefaults | None = None
+    # Set on sandbox namespaces.
+    expires_at: datetime | None = None
+    created_at: datetime
+    updated_at: datetime
+
+
+class NamespaceListResponse(OrbitModel):
+    data: list[Namespace]
+
+
+class PinnedClock(OrbitModel):
+    """The engine clock of a server in test mode."""
+
+    now: datetime
+
+
+class EventTypeSampling(OrbitModel):
+    """How a high-volume event type is stored instead of one memory per event."""
+
+    # ``sample`` stores each event with probability ``rate``; ``aggregate`` counts the events
+    # for each entity and stores one summary memory per ``window_seconds``; ``counter`` stores
+    # the first of identical events and counts the repeats on that memory.
+    mode: str = "sample"
+    rate: float = Field(default=1.0, ge=0.0, le=1.0)
+    window_seconds: int = Field(default=3600, ge=60, le=604_800)
+
+    @field_validator("mode")
+    @classmethod
+    def validate_mode(cls, value: str) -> str:
+        normalized = value.strip().lower()
+        if normalized not in {"sample", "aggregate", "counter"}:
+            msg = "sampling mode must be sample, aggregate or counter"
+            raise ValueError(msg)
+        return normalized
+
+
+class EventTypeDefinition(OrbitModel):
+    name: str = Field(min_length=1, max_length=64)
+    description: str | None = Field(default=None, max_length=512)
+    # Applies whether or not the registry is enforced.
+    sampling: EventTypeSampling | None = None
+
+    @field_validator("name")
+    @classmethod
+    def normalize_name(cls, value: str) -> str:
+        normalized = value.strip()
+        if not normalized:
+            msg = "event type name cannot be empty"
+            raise ValueError(msg)
+        return normalized
+
+
+class EventTypeRegistryRequest(OrbitModel):
+    event_types: list[EventTypeDefinition] = Field(default_factory=list, max_length=256)
+    # When false the registry is documentation only and ingest accepts any event type.
+    enforce: bool = True
+
+    @field_validator("event_types")
+    @classmethod
+    def validate_unique_names(cls, value: list[EventTypeDefinition]) -> list[EventTypeDefinition]:
+        names = [item.name for item in value]
+        if len(set(names)) != len(names):
+            msg = "event type names must be unique"
+            raise ValueError(msg)
+        return value
+
+
+class EventTypeRegistry(OrbitModel):
+    account_key: str
+    event_types: list[EventTypeDefinition]
+    enforce: bool
+    updated_at: datetime
+
+
+class WritePolicyRequest(OrbitModel):
+    # Event types agents may ingest; any type when omitted.
+    allowed_event_types: list[str] | None = Field(default=None, max_length=256)
+    # Ceiling on importance an agent assigns itself (``metadata.importance`` on ingest,
+    # ``importance`` on session memories).
+    max_importance: float | None = Field(default=None, ge=0.0, le=1.0)
+    # Categories (see the categorization stage) that may not be stored, and categories held
+    # for approval through /v1/review before they are stored.
+    banned_categories: list[str] = Field(default_factory=list, max_length=64)
+    review_categories: list[str] = Field(default_factory=list, max_length=64)
+
+    @field_validator("allowed_event_types")
+    @classmethod
+    def validate_allowed_event_types(cls, value: list[str] | None) -> list[str] | None:
+        if value is None:
+            return None
+        return list(dict.fromkeys(item.strip() for item in value if item.strip()))
+
+    @field_validator("banned_categories", "review_categories")
+    @classmethod
+    def validate_categories(cls, value: list[str]) -> list[str]:
+        return list(dict.fromkeys(item.strip().lower() for item in value if item.strip()))
+
+
+class WritePolicy(OrbitModel):
+    account_key: str
+    allowed_event_types: list[str] | None = None
+    max_importance: float | None = None
+    banned_categories: list[str] = Field(default_factory=list)
+    review_categories: list[str] = Field(default_factory=list)
+    updated_at: datetime
+
+
+class MemoryReview(OrbitModel):
+    review_id: str
+    entity_id: str | None = None
+    agent_id: str | None = None
+    category: str
+    content: str
+    status: str
+    # The stored memory, once approved.
+    memory_id: str | None = None
+    note: str | None = None
+    resolved_by: str | None = None
+    created_at: datetime
+    updated_at: datetime
+
+
+class MemoryReviewListResponse(OrbitModel):
+    data: list[MemoryReview]
+
+
+class MemoryReviewDecision(OrbitModel):
+    note: str | None = Field(default=None, max_length=2000)
+
+
+class RetentionPolicyRequest(OrbitModel):
+    days: int = Field(ge=1, le=36_500)
+    # Per-event-type overrides, matched against each memory's intent.
+    event_types: dict[str, int] = Field(default_factory=dict)
+
+    @field_validator("event_types")
+    @classmethod
+    def validate_event_type_days(cls, value: dict[str, int]) -> dict[str, int]:
+        normalized = {name.strip(): days for name, days in value.items() if name.strip()}
+        if any(days < 1 or days > 36_500 for days in normalized.values()):
+            msg = "event type retention must be between 1 and 36500 days"
+            raise ValueError(msg)
+        return normalized
+
+
+class RetentionPolicy(OrbitModel):
+    account_key: str
+    days: int
+    event_types: dict[str, int] = Field(default_factory=dict)
+    updated_at: datetime
+
+
+class ExportJobRequest(OrbitModel):
+    cron: str = Field(min_length=1, max_length=128)
+    destination: str = Field(min_length=1, max_length=1024)
+    format: str = "jsonl"
+    enabled: bool = True
+
+    @field_validator("format")
+    @classmethod
+    def validate_format(cls, value: str) -> str:
+        normalized = value.strip().lower()
+        if normalized not in EXPORT_FORMATS:
+            msg = f"format must be one of: {', '.join(EXPORT_FORMATS)}"
+            raise ValueError(msg)
+        return normalized
+
+
+class ExportJob(OrbitModel):
+    export_id: str
+    account_key: str
+    cron: str
+    destination: str
+    format: str
+    enabled: bool
+    # Sequence of the last change written; the next run starts after it.
+    cursor: int
+    next_run_at: datetime | None = None
+    last_run_at: datetime | None = None
+    last_status: str | None = None
+    last_error: str | None = None
+    last_object_key: str | None = None
+    rows_exported: int = 0
+    created_at: datetime
+
+
+class ExportJobListResponse(OrbitModel):
+    data: list[ExportJob]
+
+
+class UrlSourceRequest(OrbitModel):
+    url: str
+    entity_id: str | None = None
+    # Hours between re-crawls; ORBIT_URL_RECRAWL_HOURS when omitted.
+    interval_hours: float | None = Field(default=None, gt=0, le=24 * 365)
+    enabled: bool = True
+
+    @field_validator("url")
+    @classmethod
+    def validate_url(cls, value: str) -> str:
+        stripped = value.strip()
+        if not stripped.lower().startswith(("http://", "https://")):
+            msg = "url must be an http(s) URL"
+            raise ValueError(msg)
+        if len(stripped) > 2048:
+            msg = "url cannot exceed 2048 characters"
+            raise ValueError(msg)
+        return stripped
+
+
+class UrlSource(OrbitModel):
+    source_id: str
+    url: str
+    entity_id: str
+    interval_hours: float
+    enabled: bool
+    # SHA-256 of the last crawled text; a re-crawl with the same hash stores nothing.
+    content_hash: str | None = None
+    memory_ids: list[str] = Field(default_factory=list)
+    last_crawled_at: datetime | None = None
+    next_crawl_at: datetime | None = None
+    # "unchanged", "updated", or "failed"; a failed crawl keeps the earlier memories.
+    last_status: str | None = None
+    last_error: str | None = None
+    # True once the last successful crawl is older than ORBIT_URL_FRESHNESS_HOURS.
+    stale: bool = False
+    created_at: datetime
+
+
+class UrlSourceListResponse(OrbitModel):
+    data: list[UrlSource]
+
+
+class SavedQueryRequest(OrbitModel):
+    # Retrieval text with ``{name}`` placeholders filled in at run time; ``{{ }}`` are literal.
+    query_template: str = Field(min_length=1, max_length=2000)
+    description: str | None = Field(default=None, max_length=1024)
+    # Applied as if the caller had sent them, so they take precedence over namespace defaults.
+    options: RetrieveDefaults = Field(default_factory=RetrieveDefaults)
+
+
+class SavedQuery(OrbitModel):
+    name: str
+    query_template: str
+    # Placeholder names in the template; a run must supply exactly these.
+    parameters: list[str] = Field(default_factory=list)
+    description: str | None = None
+    options: RetrieveDefaults = Field(default_factory=RetrieveDefaults)
+    created_at: datetime
+    updated_at: datetime
+
+
+class SavedQueryListResponse(OrbitModel):
+    data: list[SavedQuery]
+
+
+class SavedQueryRunRequest(OrbitModel):
+    params: dict[str, str] = Field(default_factory=dict)
+    entity_id: str | None = None
+    session_id: str | None = None
+    agent_id: str | None = None
+    # Overrides the saved limit for this run only.
+    limit: int | None = Field(default=None, ge=1, le=100)
+    boost: RetrieveBoost | None = None
+
+
+class ModerationResolveRequest(OrbitModel):
+    decision: str
+    note: str | None = Field(default=None, max_length=2000)
+
+    @field_validator("decision")
+    @classmethod
+    def validate_decision(cls, value: str) -> str:
+        normalized = value.strip().lower()
+        if normalized not in {"approve", "reject"}:
+            msg = "decision must be one of: approve, reject"
+            raise ValueError(msg)
+        return normalized
+
+
+class VectorIndexNamespace(OrbitModel):
+    namespace: str
+    quantization: str
+    vectors: int
+    bytes: int
+    float32_bytes: int
+
+
+class AdminMetricsResponse(OrbitModel):
+    generated_at: datetime
+    uptime_seconds: float
+    requests: dict[str, float]
+    flash_pipeline: dict[str, float]
+    http_responses: dict[str, float]
+    vector_index: list[VectorIndexNamespace] = Field(default_factory=list)
+
+
+class SessionMemoryRequest(OrbitModel):
+    content: str
+    event_type: str | None = None
+    entity_id: str | None = None
+    metadata: dict[str, Any] = Field(default_factory=dict)
+    importance: float = 0.0
+
+    @field_validator("content")
+    @classmethod
+    def validate_content(cls, value: str) -> str:
+        stripped = value.strip()
+        if not stripped:
+            msg = "content cannot be empty"
+            raise ValueError(msg)
+        return stripped
+
+    @field_validator("importance")
+    @classmethod
+    def validate_importance(cls, value: float) -> float:
+        if not 0.0 <= value <= 1.0:
+            msg = "importance must be between 0 and 1"
+            raise ValueError(msg)
+        return value
+
+
+class SessionMemoryItem(OrbitModel):
+    item_id: str
+    session_id: str
+    content: str
+    event_type: str
+    entity_id: str
+    importance: float
+    recall_count: int
+    created_at: datetime
+    metadata: dict[str, Any] = Field(default_factory=dict)
+
+
+class SessionMemoryListResponse(OrbitModel):
+    session_id: str
+    data: list[SessionMemoryItem]
+
+
+class SessionEndResponse(OrbitModel):
+    session_id: str
+    promoted_memory_ids: list[str]
+    discarded_count: int
+    # Items the ingest pipeline refused (validation, moderation, write policy); never retried.
+    rejected_count: int = 0
+
+
+class ProcedureStep(OrbitModel):
+    action: str
+    tool: str | None = None
+    arguments: dict[str, Any] = Field(default_factory=dict)
+    result: str | None = None
+
+    @field_validator("action")
+    @classmethod
+    def validate_action(cls, value: str) -> str:
+        stripped = value.strip()
+        if not stripped:
+            msg = "action cannot be empty"
+            raise ValueError(msg)
+        return stripped
+
+
+class ProcedureRequest(OrbitModel):
+    task: str
+    steps: list[ProcedureStep] = Field(min_length=1, max_length=50)
+    error_pattern: str | None = None
+    outcome: str | None = None
+    entity_id: str | None = None
+    metadata: dict[str, Any] = Field(default_factory=dict)
+
+    @field_validator("task")
+    @classmethod
+    def validate_task(cls, value: str) -> str:
+        stripped = value.strip()
+        if not stripped:
+            msg = "task cannot be empty"
+            raise ValueError(msg)
+        return stripped
+
+
+class Procedure(OrbitModel):
+    memory_id: str
+    task: str
+    kind: str
+    steps: list[ProcedureStep]
+    error_pattern: str | None = None
+    outcome: str | None = None
+    entity_id: str | None = None
+    score: float
+    created_at: datetime
+
+
+class ProcedureSearchResponse(OrbitModel):
+    data: list[Procedure]
+    query_execution_time_ms: float
+
+
+class TrajectoryStep(OrbitModel):
+    action: str
+    tool: str | None = None
+    arguments: dict[str, Any] = Field(default_factory=dict)
+    result: str | None = None
+    error: str | None = None
+
+    @field_validator("action")
+    @classmethod
+    def validate_action(cls, value: str) -> str:
+        stripped = value.strip()
+        if not stripped:
+            msg = "action cannot be empty"
+            raise ValueError(msg)
+        return stripped
+
+    @field_validator("error")
+    @classmethod
+    def validate_error(cls, value: str | None) -> str | None:
+        if value is None:
+            return None
+        return value.strip() or None
+
+
+class ReflectRequest(OrbitModel):
+    goal: str
+    steps: list[TrajectoryStep] = Field(min_length=1, max_length=100)
+    outcome: str
+    outcome_detail: str | None = None
+    entity_id: str | None = None
+    metadata: dict[str, Any] = Field(default_factory=dict)
+
+    @field_validator("goal")
+    @classmethod
+    def validate_goal(cls, value: str) -> str:
+        stripped = value.strip()
+        if not stripped:
+            msg = "goal cannot be empty"
+            raise ValueError(msg)
+        return stripped
+
+    @field_validator("outcome")
+    @classmethod
+    def validate_outcome(cls, value: str) -> str:
+        normalized = value.strip().lower()
+        if normalized not in {"success", "failure", "partial"}:
+            msg = "outcome must be one of: success, failure, partial"
+            raise ValueError(msg)
+        return normalized
+
+
+class ReflectLesson(OrbitModel):
+    memory_id: str
+    kind: str
+    content: str
+    stored: bool
+
+
+class ReflectResponse(OrbitModel):
+    trajectory_id: str
+    lessons: list[ReflectLesson]
+
+
+class EntityAttributesPatchRequest(OrbitModel):
+    """JSON merge patch: keys set to ``null`` are removed, everything else is replaced."""
+
+    attributes: dict[str, Any] = Field(min_length=1)
+
+    @field_validator("attributes")
+    @classmethod
+    def validate_attributes(cls, value: dict[str, Any]) -> dict[str, Any]:
+        for key in value:
+            if not 1 <= len(key.strip()) <= 64 or key != key.strip():
+                msg = "attribute names must be 1-64 characters without surrounding spaces"
+                raise ValueError(msg)
+        return value
+
+
+class EntityAttributesResponse(OrbitModel):
+    entity_id: str
+    attributes: dict[str, Any]
+    sources: dict[str, str]
+    updated_at: datetime | None = None
+    version: int = 0
+
+
+class Topic(OrbitModel):
+    topic_id: str
+    label: str
+    summary: str
+    keywords: list[str]
+    memory_count: int
+    memory_ids: list[str]
+
+
+class EntityTopicsResponse(OrbitModel):
+    entity_id: str
+    topics: list[Topic]
+    algorithm: str
+    computed_at: datetime
+
+
+class GoalProgress(OrbitModel):
+    memory_id: str
+    status: str
+    note: str
+    recorded_at: datetime
+
+
+class Goal(OrbitModel):
+    goal_id: str
+    title: str
+    status: str
+    due_date: date | None = None
+    # Past its due date and not done.
+    overdue: bool = False
+    # The memory that stated the goal; ``progress`` lists later memories that reported on it.
+    memory_id: str
+    progress: list[GoalProgress] = Field(default_factory=list)
+    created_at: datetime
+    updated_at: datetime
+
+
+class ConflictingFact(OrbitModel):
+    memory_id: str
+    statement: str
+    polarity: str | None = None
+    status: str | None = None
+    created_at: datetime
+    # The ingested memory the fact was inferred from, when it still exists.
+    source_memory_id: str | None = None
+    source_content: str | None = None
+
+
+class MemoryConflict(OrbitModel):
+    subject: str
+    fact_key: str
+    fact_type: str | None = None
+    clarification_required: bool
+    facts: list[ConflictingFact]
+    detected_at: datetime
+
+
+class ResurfacedMemory(OrbitModel):
+    memory: Memory
+    # When the memory was last retrieved, or stored if it never was.
+    last_accessed_at: datetime
+    idle_days: int
+    # Ready-made opener, e.g. "Last month you mentioned: I want to learn Rust".
+    prompt: str
+
+
+class ResurfaceResponse(OrbitModel):
+    data: list[ResurfacedMemory]
+
+
+class DigestRequest(OrbitModel):
+    period: str = "daily"
+    # Only this entity's memories; the whole namespace when omitted.
+    entity_id: str | None = Field(default=None, min_length=1, max_length=255)
+    # Also post the digest to ORBIT_DIGEST_WEBHOOK_URL.
+    deliver: bool = False
+
+    @field_validator("period")
+    @classmethod
+    def validate_period(cls, value: str) -> str:
+        normalized = value.strip().lower()
+        if normalized not in DIGEST_PERIODS:
+            msg = f"period must be one of: {', '.join(DIGEST_PERIODS)}"
+            raise ValueError(msg)
+        return normalized
+
+
+class Digest(OrbitModel):
+    digest_id: str
+    period: str
+    entity_id: str | None = None
+    window_start: datetime
+    window_end: datetime
+    memory_count: int
+    # New memories per category, e.g. {"preference": 3, "goal": 1}.
+    categories: dict[str, int] = Field(default_factory=dict)
+    summary: str
+    # Memories the summary is built from, most important first.
+    memory_ids: list[str] = Field(default_factory=list)
+    # Set when the summary was written by the summarization model.
+    model: str | None = None
+    delivered: bool = False
+    created_at: datetime
+
+
+class EntityConflictsResponse(OrbitModel):
+    entity_id: str
+    conflicts: list[MemoryConflict]
+
+
+class EntityGoalsResponse(OrbitModel):
+    entity_id: str
+    goals: list[Goal]
+
+
+class MemoryShareRequest(OrbitModel):
+    """Share selected memories, or every memory carrying one of ``tags``, with one target."""
+
+    target_entity_id: str | None = None
+    target_group_id: str | None = None
+    memory_ids: list[str] = Field(default_factory=list, max_length=500)
+    tags: list[str] = Field(default_factory=list, max_length=50)
+
+    @field_validator("memory_ids", "tags")
+    @classmethod
+    def validate_items(cls, value: list[str]) -> list[str]:
+        normalized = [item.strip() for item in value if item.strip()]
+        return list(dict.fromkeys(normalized))
+
+    @model_validator(mode="after")
+    def validate_share(self) -> MemoryShareRequest:
+        if (self.target_entity_id is None) == (self.target_group_id is None):
+            msg = "exactly one of target_entity_id or target_group_id is required"
+            raise ValueError(msg)
+        if not self.memory_ids and not self.tags:
+            msg = "memory_ids or tags is required"
+            raise ValueError(msg)
+        return self
+
+
+class MemoryShare(OrbitModel):
+    share_id: int
+    source_entity_id: str
+    target_type: str
+    target_id: str
+    memory_ids: list[str]
+    tags: list[str]
+    created_at: datetime
+    revoked_at: datetime | None = None
+
+
+class MemoryShareListResponse(OrbitModel):
+    data: list[MemoryShare]
+
+
+class MemoryLinkRequest(OrbitModel):
+    target_memory_id: str
+    link_type: str
+
+    @field_validator("target_memory_id")
+    @classmethod
+    def validate_target_memory_id(cls, value: str) -> str:
+        stripped = value.strip()
+        if not stripped:
+            msg = "target_memory_id cannot be empty"
+            raise ValueError(msg)
+        return stripped
+
+    @field_validator("link_type")
+    @classmethod
+    def validate_link_type(cls, value: str) -> str:
+        normalized = value.strip().lower().replace("-", "_")
+        if normalized not in MEMORY_LINK_TYPES:
+            msg = f"link_type must be one of: {', '.join(MEMORY_LINK_TYPES)}"
+            raise ValueError(msg)
+        return normalized
+
+
+class MemoryLink(OrbitModel):
+    link_id: int
+    source_memory_id: str
+    target_memory_id: str
+    link_type: str
+    created_at: datetime
+
+
+class MemoryLinkListResponse(OrbitModel):
+    data: list[MemoryLink]
+
+
+class EntityGroupRequest(OrbitModel):
+    entity_ids: list[str] = Field(max_length=100)
+
+    @field_validator("entity_ids")
+    @classmethod
+    def validate_entity_ids(cls, value: list[str]) -> list[str]:
+        normalized = [item.strip() for item in value if item.strip()]
+        return list(dict.fromkeys(normalized))
+
+
+class EntityGroupResponse(OrbitModel):
+    group_id: str
+    entity_ids: list[str]
+
+
+class RecallRequest(OrbitModel):
+    query: str
+    entity_id: str
+    limit: int = 10
+    session_id: str | None = None
+    session_items: int = 5
+    max_latency_ms: int | None = None
+
+    @field_validator("query", "entity_id")
+    @classmethod
+    def validate_required_text(cls, value: str) -> str:
+        stripped = value.strip()
+        if not stripped:
+            msg = "query and entity_id cannot be empty"
+            raise ValueError(msg)
+        return stripped
+
+    @field_validator("limit")
+    @classmethod
+    def validate_limit(cls, value: int) -> int:
+        if not 1 <= value <= 100:
+            msg = "limit must be between 1 and 100"
+            raise ValueError(msg)
+        return value
+
+    @field_validator("session_items")
+    @classmethod
+    def validate_session_items(cls, value: int) -> int:
+        if not 0 <= value <= 50:
+            msg = "session_items must be between 0 and 50"
+            raise ValueError(msg)
+        return value
+
+    @field_validator("max_latency_ms")
+    @classmethod
+    def validate_max_latency_ms(cls, value: int | None) -> int | None:
+        if value is not None and not 1 <= value <= 60_000:
+            msg = "max_latency_ms must be between 1 and 60000"
+            raise ValueError(msg)
+        return value
+
+
+class SessionSummary(OrbitModel):
+    session_id: str
+    item_count: int
+    started_at: datetime | None = None
+    last_activity_at: datetime | None = None
+    summary: str
+    recent: list[SessionMemoryItem]
+
+
+class RecallResponse(OrbitModel):
+    entity_id: str
+    profile: EntityAttributesResponse
+    memories: list[Memory]
+    session: SessionSummary | None = None
+    query_execution_time_ms: float
+    degraded: bool = False
+
+
+class AskRequest(OrbitModel):
+    question: str
+    limit: int = 5
+    entity_id: str | None = None
+    event_type: str | None = None
+    time_range: TimeRange | None = None
+
+    @field_validator("question")
+    @classmethod
+    def validate_question(cls, value: str) -> str:
+        stripped = value.strip()
+        if not stripped:
+            msg = "question cannot be empty"
+            raise ValueError(msg)
+        return stripped
+
+    @field_validator("limit")
+    @classmethod
+    def validate_limit(cls, value: int) -> int:
+        if not 1 <= value <= 20:
+            msg = "limit must be between 1 and 20"
+            raise ValueError(msg)
+        return value
+
+
+class AskResponse(OrbitModel):
+    question: str
+    # None when no memory matched, or the memories do not answer the question.
+    answer: str | None
+    citations: list[str]
+    confidence: float
+    # The cited memories, in citation order.
+    memories: list[Memory]
+    model: str
+    query_execution_time_ms: float
+
+
+class ChatRequest(OrbitModel):
+    session_id: str
+    message: str
+    # Defaults to the session's entity, then ORBIT_DEFAULT_ENTITY_ID.
+    entity_id: str | None = None
+    memory_limit: int = 5
+
+    @field_validator("session_id", "message")
+    @classmethod
+    def validate_required_text(cls, value: str) -> str:
+        stripped = value.strip()
+        if not stripped:
+            msg = "session_id and message cannot be empty"
+            raise ValueError(msg)
+        return stripped
+
+    @field_validator("memory_limit")
+    @classmethod
+    def validate_memory_limit(cls, value: int) -> int:
+        if not 0 <= value <= 20:
+            msg = "memory_limit must be between 0 and 20"
+            raise ValueError(msg)
+        return value
+
+
+class ChatTurn(OrbitModel):
+    role: str
+    content: str
+    # The long-term memory ingested from the turn; None when it was not stored.
+    memory_id: str | None = None
+    # For assistant turns, the memories retrieved to produce the reply.
+    retrieved_memory_ids: list[str] = Field(default_factory=list)
+    created_at: datetime
+
+
+class ChatResponse(OrbitModel):
+    session_id: str
+    entity_id: str
+    reply: str
+    # Memories retrieved for the message and given to the model.
+    memories: list[Memory]
+    ingested_memory_ids: list[str]
+    model: str
+    turn_count: int
+
+
+class ChatHistoryResponse(OrbitModel):
+    session_id: str
+    data: list[ChatTurn]
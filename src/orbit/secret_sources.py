@@ -0,0 +1,159 @@
+"""Resolve secrets from env vars, ``*_FILE`` paths, mounted secret dirs, and Vault.
+
+``get_secret("ORBIT_JWT_SECRET")`` checks, in order:
+
+1. ``ORBIT_JWT_SECRET`` in the environment
+2. the file named by ``ORBIT_JWT_SECRET_FILE`` (Docker/Compose secrets convention)
+3. ``$ORBIT_SECRETS_DIR/ORBIT_JWT_SECRET`` or ``$ORBIT_SECRETS_DIR/orbit-jwt-secret``
+   (a Kubernetes Secret mounted as a volume)
+
+A resolved value of the form ``vault:<path>#<field>`` is then read from HashiCorp Vault
+(KV v1 or v2) using ``VAULT_ADDR`` and ``VAULT_TOKEN``/``VAULT_TOKEN_FILE``, or Kubernetes
+auth when ``VAULT_K8S_ROLE`` is set.
+"""
+
+from __future__ import annotations
+
+import os
+from pathlib import Path
+from threading import RLock
+from typing import Any
+
+import httpx
+
+VAULT_PREFIX = "vault:"
+_K8S_SERVICE_ACCOUNT_TOKEN = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+_cache_lock = RLock()
+_vault_values: dict[str, str] = {}
+_vault_token: str | None = None
+
+
+class SecretResolutionError(RuntimeError):
+    """Raised when a configured secret source cannot be read."""
+
+
+def get_secret(name: str, default: str | None = None) -> str | None:
+    value = _raw_secret(name)
+    if value is None:
+        return default
+    if value.startswith(VAULT_PREFIX):
+        return read_vault_secret(value[len(VAULT_PREFIX) :])
+    return value
+
+
+def clear_secret_cache() -> None:
+    """Forget cached Vault values and tokens (e.g. before a config reload)."""
+    global _vault_token
+    with _cache_lock:
+        _vault_values.clear()
+        _vault_token = None
+
+
+def read_vault_secret(reference: str) -> str:
+    path, separator, field = reference.strip().partition("#")
+    path = path.strip("/")
+    if not separator or not path or not field:
+        msg = f"vault reference must look like vault:<path>#<field>, got {reference!r}"
+        raise SecretResolutionError(msg)
+    with _cache_lock:
+        cached = _vault_values.get(reference)
+        if cached is not None:
+            return cached
+        payload = _vault_request("GET", f"/v1/{path}")
+        data = payload.get("data") if isinstance(payload, dict) else None
+        if isinstance(data, dict) and isinstance(data.get("data"), dict):
+            data = data["data"]
+        value = data.get(field) if isinstance(data, dict) else None
+        if value is None:
+            msg = f"vault secret {path} has no field {field!r}"
+            raise SecretResolutionError(msg)
+        _vault_values[reference] = str(value)
+        return str(value)
+
+
+def _raw_secret(name: str) -> str | None:
+    direct = os.getenv(name)
+    if direct is not None and direct.strip():
+        return direct.strip()
+    file_path = os.getenv(f"{name}_FILE")
+    if file_path is not None and file_path.strip():
+        return _read_file(Path(file_path.strip()), name=name)
+    secrets_dir = os.getenv("ORBIT_SECRETS_DIR")
+    if secrets_dir is not None and secrets_dir.strip():
+        base = Path(secrets_dir.strip())
+        for candidate in (name, name.lower().replace("_", "-")):
+            path = base / candidate
+            if path.is_file():
+                return _read_file(path, name=name)
+    return None
+
+
+def _read_file(path: Path, *, name: str) -> str | None:
+    try:
+        value = path.read_text(encoding="utf-8").strip()
+    except OSError as exc:
+        msg = f"cannot read secret {name} from {path}: {exc}"
+        raise SecretResolutionError(msg) from exc
+    return value or None
+
+
+def _vault_request(
+    method: str,
+    path: str,
+    *,
+    json_body: dict[str, Any] | None = None,
+    authenticated: bool = True,
+) -> Any:
+    address = (os.getenv("VAULT_ADDR") or "").strip().rstrip("/")
+    if not address:
+        msg = "VAULT_ADDR must be set to resolve vault: secrets"
+        raise SecretResolutionError(msg)
+    headers: dict[str, str] = {}
+    if authenticated:
+        headers["X-Vault-Token"] = _vault_client_token()
+    namespace = (os.getenv("VAULT_NAMESPACE") or "").strip()
+    if namespace:
+        headers["X-Vault-Namespace"] = namespace
+    try:
+        response = httpx.request(
+            method,
+            f"{address}{path}",
+            headers=headers,
+            json=json_body,
+            timeout=10.0,
+        )
+    except httpx.HTTPError as exc:
+        msg = f"vault request failed: {exc}"
+        raise SecretResolutionError(msg) from exc
+    if response.status_code >= 400:
+        msg = f"vault returned {response.status_code} for {path}"
+        raise SecretResolutionError(msg)
+    return response.json()
+
+
+def _vault_client_token() -> str:
+    global _vault_token
+    if _vault_token is not None:
+        return _vault_token
+    token = _raw_secret("VAULT_TOKEN")
+    if token is None:
+        role = (os.getenv("VAULT_K8S_ROLE") or "").strip()
+        if not role:
+            msg = "set VAULT_TOKEN, VAULT_TOKEN_FILE, or VAULT_K8S_ROLE to read vault: secrets"
+            raise SecretResolutionError(msg)
+        jwt_path = Path(os.getenv("VAULT_K8S_TOKEN_PATH", _K8S_SERVICE_ACCOUNT_TOKEN))
+        service_account_jwt = _read_file(jwt_path, name="VAULT_K8S_TOKEN_PATH") or ""
+        mount = (os.getenv("VAULT_K8S_AUTH_PATH") or "kubernetes").strip("/")
+        payload = _vault_request(
+            "POST",
+            f"/v1/auth/{mount}/login",
+            json_body={"role": role, "jwt": service_account_jwt},
+            authenticated=False,
+        )
+        token = payload.get("auth", {}).get("client_token") if isinstance(payload, dict) else None
+        if not token:
+            msg = f"vault kubernetes login for role {role} returned no token"
+            raise SecretResolutionError(msg)
+    _vault_token = str(token)
+    return _vault_token
@@ -0,0 +1,404 @@
+"""Shadow-read comparison between two memory backends.
+
+``ShadowReadEngine`` wraps the engine an application already uses. Every ``retrieve`` returns
+the primary's response unchanged; a sampled copy of the call goes to the shadow in the
+background, and the two result lists are compared there, so the shadow's latency and failures
+never reach the caller. The shadow can be a second Orbit deployment, the same deployment with
+different retrieve parameters (``shadow_params``), or any other provider wrapped in an object
+whose ``retrieve(query, **kwargs)`` returns a ``RetrieveResponse``.
+
+Results are matched on normalized content by default, because memory ids differ between
+deployments; pass ``match_on="memory_id"`` when both sides share ids (a replica, say).
+"""
+
+from __future__ import annotations
+
+import asyncio
+import random
+import threading
+from collections.abc import Awaitable, Callable
+from concurrent.futures import ThreadPoolExecutor
+from dataclasses import dataclass, field
+from time import perf_counter
+from typing import Any, Protocol
+
+from orbit.logger import get_logger
+from orbit.models import RetrieveResponse
+
+MATCH_MODES = ("content", "memory_id")
+
+
+class Retriever(Protocol):
+    def retrieve(self, query: str, **kwargs: Any) -> RetrieveResponse: ...
+
+
+class AsyncRetriever(Protocol):
+    def retrieve(self, query: str, **kwargs: Any) -> Awaitable[RetrieveResponse]: ...
+
+
+@dataclass(frozen=True)
+class RankChange:
+    key: str
+    primary_position: int
+    shadow_position: int
+
+
+@dataclass(frozen=True)
+class ShadowDiff:
+    """How the shadow's answer to one query differed from the primary's."""
+
+    query: str
+    overlap: float
+    top_result_match: bool
+    only_in_primary: list[str] = field(default_factory=list)
+    only_in_shadow: list[str] = field(default_factory=list)
+    rank_changes: list[RankChange] = field(default_factory=list)
+    primary_latency_ms: float = 0.0
+    shadow_latency_ms: float | None = None
+    error: str | None = None
+
+    @property
+    def identical(self) -> bool:
+        return (
+            self.error is None
+            and not self.only_in_primary
+            and not self.only_in_shadow
+            and not self.rank_changes
+        )
+
+
+@dataclass(frozen=True)
+class ShadowReadReport:
+    """Totals over every comparison since the wrapper was created."""
+
+    compared: int
+    identical: int
+    failed: int
+    dropped: int
+    mean_overlap: float | None
+    top_result_agreement: float | None
+    primary_latency_ms: float | None
+    shadow_latency_ms: float | None
+
+
+def compare_results(
+    query: str,
+    primary: RetrieveResponse,
+    shadow: RetrieveResponse,
+    *,
+    match_on: str = "content",
+    primary_latency_ms: float = 0.0,
+    shadow_latency_ms: float | None = None,
+) -> ShadowDiff:
+    """Diff two responses to ``query``; keys are normalized content or memory ids."""
+    primary_keys = _result_keys(primary, match_on)
+    shadow_keys = _result_keys(shadow, match_on)
+    shadow_positions = {key: position for position, key in enumerate(shadow_keys, start=1)}
+    shared = set(primary_keys) & set(shadow_keys)
+    size = max(len(primary_keys), len(shadow_keys))
+    return ShadowDiff(
+        query=query,
+        overlap=len(shared) / size if size else 1.0,
+        top_result_match=primary_keys[:1] == shadow_keys[:1],
+        only_in_primary=[key for key in primary_keys if key not in shared],
+        only_in_shadow=[key for key in shadow_keys if key not in shared],
+        rank_changes=[
+            RankChange(key=key, primary_position=position, shadow_position=shadow_positions[key])
+            for position, key in enumerate(primary_keys, start=1)
+            if key in shared and shadow_positions[key] != position
+        ],
+        primary_latency_ms=primary_latency_ms,
+        shadow_latency_ms=shadow_latency_ms,
+    )
+
+
+def _result_keys(response: RetrieveResponse, match_on: str) -> list[str]:
+    keys: list[str] = []
+    for memory in response.memories:
+        if match_on == "memory_id":
+            key = memory.memory_id
+        else:
+            key = " ".join(memory.content.lower().split())
+        if key not in keys:
+            keys.append(key)
+    return keys
+
+
+class _ShadowReadBase:
+    def __init__(
+        self,
+        *,
+        sample_rate: float,
+        match_on: str,
+        shadow_params: dict[str, Any] | None,
+        on_diff: Callable[[ShadowDiff], None] | None,
+        max_pending: int,
+    ) -> None:
+        if not 0.0 <= sample_rate <= 1.0:
+            msg = "sample_rate must be between 0 and 1"
+            raise ValueError(msg)
+        if match_on not in MATCH_MODES:
+            msg = f"match_on must be one of: {', '.join(MATCH_MODES)}"
+            raise ValueError(msg)
+        self._sample_rate = sample_rate
+        self._match_on = match_on
+        self._shadow_params = dict(shadow_params or {})
+        self._on_diff = on_diff
+        self._max_pending = max(1, max_pending)
+        self._log = get_logger("orbit.shadow_read")
+        # Also signalled whenever a comparison finishes, for ``flush``.
+        self._lock = threading.Condition()
+        self._pending = 0
+        self._compared = 0
+        self._identical = 0
+        self._failed = 0
+        self._dropped = 0
+        self._overlap_sum = 0.0
+        self._top_matches = 0
+        self._primary_latency_sum = 0.0
+        self._shadow_latency_sum = 0.0
+
+    def report(self) -> ShadowReadReport:
+        with self._lock:
+            compared = self._compared
+            return ShadowReadReport(
+                compared=compared,
+                identical=self._identical,
+                failed=self._failed,
+                dropped=self._dropped,
+                mean_overlap=round(self._overlap_sum / compared, 4) if compared else None,
+                top_result_agreement=round(self._top_matches / compared, 4) if compared else None,
+                primary_latency_ms=(
+                    round(self._primary_latency_sum / compared, 3) if compared else None
+                ),
+                shadow_latency_ms=(
+                    round(self._shadow_latency_sum / compared, 3) if compared else None
+                ),
+            )
+
+    def _reserve(self) -> bool:
+        """Decide whether to shadow this call; over ``max_pending`` it is dropped."""
+        if self._sample_rate <= 0.0 or random.random() >= self._sample_rate:
+            return False
+        with self._lock:
+            if self._pending >= self._max_pending:
+                self._dropped += 1
+                return False
+            self._pending += 1
+        return True
+
+    def _shadow_kwargs(self, kwargs: dict[str, Any]) -> dict[str, Any]:
+        return {**kwargs, **self._shadow_params}
+
+    def _record(self, diff: ShadowDiff) -> None:
+        with self._lock:
+            if diff.error is not None:
+                self._failed += 1
+            else:
+                self._compared += 1
+                self._identical += int(diff.identical)
+                self._overlap_sum += diff.overlap
+                self._top_matches += int(diff.top_result_match)
+                self._primary_latency_sum += diff.primary_latency_ms
+                self._shadow_latency_sum += diff.shadow_latency_ms or 0.0
+        if diff.error is not None:
+            self._log.warning("shadow_read_failed", error=diff.error)
+        elif not diff.identical:
+            self._log.debug(
+                "shadow_read_diff",
+                overlap=diff.overlap,
+                only_in_primary=len(diff.only_in_primary),
+                only_in_shadow=len(diff.only_in_shadow),
+                rank_changes=len(diff.rank_changes),
+            )
+        if self._on_diff is not None:
+            try:
+                self._on_diff(diff)
+            except Exception as exc:
+                self._log.warning("shadow_read_callback_failed", error=str(exc))
+        with self._lock:
+            self._pending -= 1
+            self._lock.notify_all()
+
+    def _diff(
+        self,
+        query: str,
+        primary: RetrieveResponse,
+        shadow: RetrieveResponse,
+        *,
+        primary_latency_ms: float,
+        shadow_latency_ms: float,
+    ) -> ShadowDiff:
+        return compare_results(
+            query,
+            primary,
+            shadow,
+            match_on=self._match_on,
+            primary_latency_ms=primary_latency_ms,
+            shadow_latency_ms=shadow_latency_ms,
+        )
+
+
+class ShadowReadEngine(_ShadowReadBase):
+    """Serve reads from ``primary`` while comparing a sample of them against ``shadow``.
+
+    Every other attribute (``ingest``, ``feedback``, ...) is the primary's, so writes are not
+    mirrored; load the shadow with the same data before comparing.
+    """
+
+    def __init__(
+        self,
+        primary: Any,
+        shadow: Retriever,
+        *,
+        sample_rate: float = 1.0,
+        match_on: str = "content",
+        shadow_params: dict[str, Any] | None = None,
+        on_diff: Callable[[ShadowDiff], None] | None = None,
+        max_pending: int = 100,
+    ) -> None:
+        super().__init__(
+            sample_rate=sample_rate,
+            match_on=match_on,
+            shadow_params=shadow_params,
+            on_diff=on_diff,
+            max_pending=max_pending,
+        )
+        self._primary = primary
+        self._shadow = shadow
+        self._executor = ThreadPoolExecutor(max_workers=1, thread_name_prefix="orbit-shadow")
+
+    def __getattr__(self, name: str) -> Any:
+        return getattr(self._primary, name)
+
+    def retrieve(self, query: str, **kwargs: Any) -> RetrieveResponse:
+        start = perf_counter()
+        response: RetrieveResponse = self._primary.retrieve(query, **kwargs)
+        primary_latency_ms = (perf_counter() - start) * 1000.0
+        if self._reserve():
+            self._executor.submit(self._compare, query, kwargs, response, primary_latency_ms)
+        return response
+
+    def flush(self, timeout: float | None = None) -> bool:
+        """Wait for queued comparisons; ``False`` if some were still running at ``timeout``."""
+        with self._lock:
+            return self._lock.wait_for(lambda: self._pending == 0, timeout=timeout)
+
+    def close(self) -> None:
+        """Finish queued comparisons. The wrapped engines stay open."""
+        self._executor.shutdown(wait=True)
+
+    def __enter__(self) -> ShadowReadEngine:
+        return self
+
+    def __exit__(self, exc_type: object, exc: object, tb: object) -> None:
+        self.close()
+
+    def _compare(
+        self,
+        query: str,
+        kwargs: dict[str, Any],
+        primary: RetrieveResponse,
+        primary_latency_ms: float,
+    ) -> None:
+        start = perf_counter()
+        try:
+            shadow = self._shadow.retrieve(query, **self._shadow_kwargs(kwargs))
+            diff = self._diff(
+                query,
+                primary,
+                shadow,
+                primary_latency_ms=primary_latency_ms,
+                shadow_latency_ms=(perf_counter() - start) * 1000.0,
+            )
+        except Exception as exc:
+            diff = ShadowDiff(
+                query=query,
+                overlap=0.0,
+                top_result_match=False,
+                primary_latency_ms=primary_latency_ms,
+                error=str(exc) or type(exc).__name__,
+            )
+        self._record(diff)
+
+
+class AsyncShadowReadEngine(_ShadowReadBase):
+    """``ShadowReadEngine`` for ``AsyncMemoryEngine``; comparisons run as event loop tasks."""
+
+    def __init__(
+        self,
+        primary: Any,
+        shadow: AsyncRetriever,
+        *,
+        sample_rate: float = 1.0,
+        match_on: str = "content",
+        shadow_params: dict[str, Any] | None = None,
+        on_diff: Callable[[ShadowDiff], None] | None = None,
+        max_pending: int = 100,
+    ) -> None:
+        super().__init__(
+            sample_rate=sample_rate,
+            match_on=match_on,
+            shadow_params=shadow_params,
+            on_diff=on_diff,
+            max_pending=max_pending,
+        )
+        self._primary = primary
+        self._shadow = shadow
+        self._tasks: set[asyncio.Task[None]] = set()
+
+    def __getattr__(self, name: str) -> Any:
+        return getattr(self._primary, name)
+
+    async def retrieve(self, query: str, **kwargs: Any) -> RetrieveResponse:
+        start = perf_counter()
+        response: RetrieveResponse = await self._primary.retrieve(query, **kwargs)
+        primary_latency_ms = (perf_counter() - start) * 1000.0
+        if self._reserve():
+            task = asyncio.create_task(
+                self._compare(query, kwargs, response, primary_latency_ms)
+            )
+            self._tasks.add(task)
+            task.add_done_callback(self._tasks.discard)
+        return response
+
+    async def flush(self) -> None:
+        if self._tasks:
+            await asyncio.gather(*self._tasks, return_exceptions=True)
+
+    async def aclose(self) -> None:
+        """Finish pending comparisons. The wrapped engines stay open."""
+        await self.flush()
+
+    async def __aenter__(self) -> AsyncShadowReadEngine:
+        return self
+
+    async def __aexit__(self, exc_type: object, exc: object, tb: object) -> None:
+        await self.aclose()
+
+    async def _compare(
+        self,
+        query: str,
+        kwargs: dict[str, Any],
+        primary: RetrieveResponse,
+        primary_latency_ms: float,
+    ) -> None:
+        start = perf_counter()
+        try:
+            shadow = await self._shadow.retrieve(query, **self._shadow_kwargs(kwargs))
+            diff = self._diff(
+                query,
+                primary,
+                shadow,
+                primary_latency_ms=primary_latency_ms,
+                shadow_latency_ms=(perf_counter() - start) * 1000.0,
+            )
+        except Exception as exc:
+            diff = ShadowDiff(
+                query=query,
+                overlap=0.0,
+                top_result_match=False,
+                primary_latency_ms=primary_latency_ms,
+                error=str(exc) or type(exc).__name__,
+            )
+        self._record(diff)
@@ -0,0 +1,105 @@
+"""HMAC-SHA256 request signing shared by the SDK and the API server.
+
+A signed request carries::
+
+    Authorization: ORBIT-HMAC-SHA256 KeyId=<key_id>, Signature=<hex>
+    X-Orbit-Timestamp: <unix seconds>
+    X-Orbit-Nonce: <random, single use>
+
+The signature is ``HMAC-SHA256(secret, canonical_request(...))`` in lowercase hex.
+"""
+
+from __future__ import annotations
+
+import hashlib
+import hmac
+import secrets
+import time
+from collections.abc import Generator
+from urllib.parse import parse_qsl, quote_plus
+
+import httpx
+
+SIGNATURE_SCHEME = "ORBIT-HMAC-SHA256"
+TIMESTAMP_HEADER = "X-Orbit-Timestamp"
+NONCE_HEADER = "X-Orbit-Nonce"
+
+
+def canonical_query(query: str) -> str:
+    pairs = sorted(parse_qsl(query, keep_blank_values=True))
+    return "&".join(f"{quote_plus(key)}={quote_plus(value)}" for key, value in pairs)
+
+
+def canonical_request(
+    *,
+    method: str,
+    path: str,
+    query: str,
+    timestamp: str,
+    nonce: str,
+    body: bytes,
+) -> str:
+    return "\n".join(
+        [
+            method.upper(),
+            path or "/",
+            canonical_query(query),
+            timestamp,
+            nonce,
+            hashlib.sha256(body).hexdigest(),
+        ]
+    )
+
+
+def compute_signature(secret: str, canonical: str) -> str:
+    return hmac.new(secret.encode("utf-8"), canonical.encode("utf-8"), hashlib.sha256).hexdigest()
+
+
+def authorization_header(key_id: str, signature: str) -> str:
+    return f"{SIGNATURE_SCHEME} KeyId={key_id}, Signature={signature}"
+
+
+def parse_authorization(header: str) -> tuple[str, str] | None:
+    """Return ``(key_id, signature)`` from a signed Authorization header, else None."""
+    scheme, _, params = header.strip().partition(" ")
+    if scheme != SIGNATURE_SCHEME:
+        return None
+    values: dict[str, str] = {}
+    for item in params.split(","):
+        name, separator, value = item.strip().partition("=")
+        if separator:
+            values[name.strip()] = value.strip()
+    key_id = values.get("KeyId", "")
+    signature = values.get("Signature", "")
+    if not key_id or not signature:
+        return None
+    return key_id, signature
+
+
+class HmacAuth(httpx.Auth):
+    """httpx auth flow that signs every attempt with a fresh timestamp and nonce."""
+
+    requires_request_body = True
+
+    def __init__(self, key_id: str, secret: str) -> None:
+        self._key_id = key_id
+        self._secret = secret
+
+    def auth_flow(self, request: httpx.Request) -> Generator[httpx.Request, httpx.Response, None]:
+        timestamp = str(int(time.time()))
+        nonce = secrets.token_urlsafe(16)
+        canonical = canonical_request(
+            method=request.method,
+            path=request.url.path,
+            query=request.url.query.decode("ascii"),
+            timestamp=timestamp,
+            nonce=nonce,
+            body=request.content,
+        )
+        request.headers[TIMESTAMP_HEADER] = timestamp
+        request.headers[NONCE_HEADER] = nonce
+        request.headers["Authorization"] = authorization_header(
+            self._key_id,
+            compute_signature(self._secret, canonical),
+        )
+        yield request
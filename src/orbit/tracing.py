@@ -0,0 +1,87 @@
+"""Trace-context headers for Orbit SDK requests.
+
+Requests carry ``X-Request-ID`` and W3C ``traceparent``/``tracestate`` headers so Orbit's
+work shows up inside the caller's distributed trace. Values come from, in increasing
+priority: the active OpenTelemetry span (when ``opentelemetry`` is installed), the client's
+``trace_context_provider`` callable, and a surrounding ``trace_context(...)`` block.
+"""
+
+# pylint: disable=import-outside-toplevel
+
+from __future__ import annotations
+
+from collections.abc import Callable, Iterator, Mapping
+from contextlib import contextmanager
+from contextvars import ContextVar
+
+REQUEST_ID_HEADER = "X-Request-ID"
+TRACEPARENT_HEADER = "traceparent"
+TRACESTATE_HEADER = "tracestate"
+
+TraceContextProvider = Callable[[], Mapping[str, str]]
+
+_current: ContextVar[dict[str, str] | None] = ContextVar("orbit_sdk_trace_headers", default=None)
+
+
+@contextmanager
+def trace_context(
+    *,
+    request_id: str | None = None,
+    traceparent: str | None = None,
+    tracestate: str | None = None,
+) -> Iterator[None]:
+    """Send these trace headers on every Orbit request made inside the block.
+
+    Works for sync and async clients alike; nested blocks override only the values they set.
+    """
+    headers = dict(_current.get() or {})
+    for name, value in (
+        (REQUEST_ID_HEADER, request_id),
+        (TRACEPARENT_HEADER, traceparent),
+        (TRACESTATE_HEADER, tracestate),
+    ):
+        if value:
+            headers[name] = value
+    token = _current.set(headers)
+    try:
+        yield
+    finally:
+        _current.reset(token)
+
+
+def trace_headers(
+    provider: TraceContextProvider | None = None,
+    *,
+    use_opentelemetry: bool = True,
+) -> dict[str, str]:
+    """Resolve the trace headers for a request made from the current context."""
+    headers: dict[str, str] = {}
+    if use_opentelemetry:
+        headers.update(_opentelemetry_headers())
+    if provider is not None:
+        headers.update({_canonical(name): value for name, value in provider().items() if value})
+    headers.update(_current.get() or {})
+    if TRACEPARENT_HEADER not in headers:
+        headers.pop(TRACESTATE_HEADER, None)
+    return headers
+
+
+def _canonical(name: str) -> str:
+    lowered = name.lower()
+    if lowered == REQUEST_ID_HEADER.lower():
+        return REQUEST_ID_HEADER
+    return lowered
+
+
+def _opentelemetry_headers() -> dict[str, str]:
+    try:  # pragma: no cover - optional runtime dependency
+        from opentelemetry.propagate import inject
+    except ImportError:
+        return {}
+    carrier: dict[str, str] = {}
+    inject(carrier)
+    return {
+        name: carrier[name]
+        for name in (TRACEPARENT_HEADER, TRACESTATE_HEADER)
+        if carrier.get(name)
+    }
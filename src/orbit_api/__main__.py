@@ -5,10 +5,8 @@ from __future__ import annotations
 import os
 
 import uvicorn
-from alembic import command
-from alembic.config import Config as AlembicConfig
 
-from decision_engine.database_url import normalize_database_url
+from orbit_api import migrations
 from orbit_api.app import create_app
 
 
@@ -27,11 +25,7 @@ def _run_migrations_if_enabled() -> None:
     enabled = os.getenv("ORBIT_AUTO_MIGRATE", "true").strip().lower()
     if enabled not in {"1", "true", "yes", "on"}:
         return
-    alembic_cfg = AlembicConfig("alembic.ini")
-    database_url = normalize_database_url(os.getenv("MDE_DATABASE_URL"))
-    if database_url:
-        alembic_cfg.set_main_option("sqlalchemy.url", database_url)
-    command.upgrade(alembic_cfg, "head")
+    migrations.upgrade("head")
 
 
 if __name__ == "__main__":
@@ -0,0 +1,186 @@
+"""Per-key detection of unusual ingestion patterns.
+
+Three signals are tracked in memory for each (account, API key) pair: a minute's volume far
+above the key's recent per-minute baseline, content written in a script the key has not sent
+before, and the same payload repeated many times in a short window. Each signal fires at most
+once per cooldown so a misbehaving integration produces one alert, not thousands. State is
+bounded: expired cooldowns are dropped and the least recently seen keys are forgotten.
+"""
+
+from __future__ import annotations
+
+import hashlib
+import unicodedata
+from collections import Counter, OrderedDict, deque
+from dataclasses import dataclass, field
+from datetime import UTC, datetime, timedelta
+from threading import Lock
+from typing import Any
+
+_BASELINE_MINUTES = 60
+_MIN_BASELINE_MINUTES = 10
+_SCRIPT_SAMPLE_CHARS = 500
+_MAX_TRACKED_PAYLOADS = 10_000
+# Least recently seen (account, key) pairs are forgotten past this many.
+_MAX_TRACKED_KEYS = 10_000
+
+
+@dataclass(frozen=True)
+class AnomalyThresholds:
+    spike_multiplier: float = 5.0
+    spike_min_events_per_minute: int = 60
+    repeat_threshold: int = 20
+    repeat_window_seconds: int = 600
+    language_warmup_events: int = 50
+    cooldown_seconds: int = 900
+
+
+@dataclass(frozen=True)
+class IngestionAnomaly:
+    kind: str
+    account_key: str
+    key_id: str
+    detail: dict[str, Any]
+    detected_at: datetime
+
+
+@dataclass
+class _KeyState:
+    first_seen: datetime
+    total_events: int = 0
+    minute_counts: dict[int, int] = field(default_factory=dict)
+    scripts: set[str] = field(default_factory=set)
+    payloads: dict[str, deque[datetime]] = field(default_factory=dict)
+    last_alert: dict[str, datetime] = field(default_factory=dict)
+
+
+class IngestionAnomalyDetector:
+    def __init__(self, thresholds: AnomalyThresholds | None = None) -> None:
+        self._thresholds = thresholds or AnomalyThresholds()
+        self._states: OrderedDict[tuple[str, str], _KeyState] = OrderedDict()
+        self._lock = Lock()
+
+    def observe(
+        self,
+        *,
+        account_key: str,
+        key_id: str,
+        contents: list[str],
+        now: datetime | None = None,
+    ) -> list[IngestionAnomaly]:
+        """Record one ingest call's events and return any anomalies it triggered."""
+        current = now or datetime.now(UTC)
+        thresholds = self._thresholds
+        anomalies: list[IngestionAnomaly] = []
+
+        def emit(state: _KeyState, kind: str, detail: dict[str, Any], *, cooldown_key: str) -> None:
+            last = state.last_alert.get(cooldown_key)
+            if last is not None and (current - last).total_seconds() < thresholds.cooldown_seconds:
+                return
+            state.last_alert[cooldown_key] = current
+            anomalies.append(
+                IngestionAnomaly(
+                    kind=kind,
+                    account_key=account_key,
+                    key_id=key_id,
+                    detail=detail,
+                    detected_at=current,
+                )
+            )
+
+        with self._lock:
+            state = self._states.setdefault((account_key, key_id), _KeyState(first_seen=current))
+            self._states.move_to_end((account_key, key_id))
+            while len(self._states) > _MAX_TRACKED_KEYS:
+                self._states.popitem(last=False)
+            # An alert older than the cooldown no longer suppresses anything.
+            state.last_alert = {
+                key: alerted_at
+                for key, alerted_at in state.last_alert.items()
+                if (current - alerted_at).total_seconds() < thresholds.cooldown_seconds
+            }
+            minute = int(current.timestamp() // 60)
+            state.minute_counts[minute] = state.minute_counts.get(minute, 0) + len(contents)
+            oldest_minute = minute - _BASELINE_MINUTES
+            for stale in [item for item in state.minute_counts if item < oldest_minute]:
+                del state.minute_counts[stale]
+            this_minute = state.minute_counts[minute]
+            baseline_minutes = min(
+                _BASELINE_MINUTES,
+                int((current - state.first_seen).total_seconds() // 60),
+            )
+            if baseline_minutes >= _MIN_BASELINE_MINUTES:
+                previous = sum(
+                    count for item, count in state.minute_counts.items() if item < minute
+                )
+                baseline = previous / baseline_minutes
+                if (
+                    this_minute >= thresholds.spike_min_events_per_minute
+                    and this_minute >= thresholds.spike_multiplier * max(baseline, 1.0)
+                ):
+                    emit(
+                        state,
+                        "volume_spike",
+                        {
+                            "events_this_minute": this_minute,
+                            "baseline_per_minute": round(baseline, 2),
+                        },
+                        cooldown_key="volume_spike",
+                    )
+
+            window_start = current - timedelta(seconds=thresholds.repeat_window_seconds)
+            for content in contents:
+                script = dominant_script(content)
+                if script is not None and script not in state.scripts:
+                    if state.total_events >= thresholds.language_warmup_events:
+                        emit(
+                            state,
+                            "new_language",
+                            {"script": script, "known_scripts": sorted(state.scripts)},
+                            cooldown_key=f"new_language:{script}",
+                        )
+                    state.scripts.add(script)
+                state.total_events += 1
+
+                digest = hashlib.sha256(content.strip().encode("utf-8")).hexdigest()
+                seen = state.payloads.setdefault(digest, deque())
+                seen.append(current)
+                while seen and seen[0] < window_start:
+                    seen.popleft()
+                if len(seen) >= thresholds.repeat_threshold:
+                    emit(
+                        state,
+                        "repeated_payload",
+                        {
+                            "payload_sha256": digest,
+                            "occurrences": len(seen),
+                            "window_seconds": thresholds.repeat_window_seconds,
+                        },
+                        cooldown_key=f"repeated_payload:{digest}",
+                    )
+            if len(state.payloads) > _MAX_TRACKED_PAYLOADS:
+                state.payloads = {
+                    key: times
+                    for key, times in state.payloads.items()
+                    if times and times[-1] >= window_start
+                }
+        return anomalies
+
+
+def dominant_script(text: str) -> str | None:
+    """Writing system of most letters in the text, e.g. ``latin`` or ``cyrillic``."""
+    scripts: Counter[str] = Counter()
+    for char in text[:_SCRIPT_SAMPLE_CHARS]:
+        if not char.isalpha():
+            continue
+        name = unicodedata.name(char, "")
+        if not name:
+            continue
+        script = name.split(" ", 1)[0].lower()
+        if script == "cjk":
+            script = "han"
+        scripts[script] += 1
+    if not scripts:
+        return None
+    script, count = scripts.most_common(1)[0]
+    return script if count * 2 >= sum(scripts.values()) else None
@@ -1,10 +1,15 @@
 """FastAPI application for Orbit REST API."""
 
+import hmac
+import json
 from collections.abc import AsyncIterator, Callable
 from contextlib import asynccontextmanager
 from datetime import UTC, datetime
-from typing import Annotated, cast
+from pathlib import Path
+from typing import Annotated, Any, cast
+from urllib.parse import parse_qsl
 
+import httpx
 from fastapi import (
     Depends,
     FastAPI,
@@ -15,56 +20,216 @@ from fastapi import (
     Response,
     status,
 )
+from fastapi.exceptions import RequestValidationError
 from fastapi.middleware.cors import CORSMiddleware
 from fastapi.security import HTTPAuthorizationCredentials, HTTPBearer
 from slowapi import Limiter, _rate_limit_exceeded_handler
 from slowapi.errors import RateLimitExceeded
 from slowapi.middleware import SlowAPIMiddleware
 from slowapi.util import get_remote_address
-from starlette.responses import JSONResponse, PlainTextResponse
+from starlette.background import BackgroundTask
+from starlette.concurrency import run_in_threadpool
+from starlette.responses import (
+    HTMLResponse,
+    JSONResponse,
+    PlainTextResponse,
+    StreamingResponse,
+)
+from pydantic import ValidationError
 from starlette.types import ExceptionHandler
 
 from memory_engine.config import EngineConfig
 from orbit.logger import configure_logging, get_logger
 from orbit.models import (
+    IMPORT_MODES,
+    AdminAnomalyListResponse,
+    AdminEntityListResponse,
+    AdminMetricsResponse,
+    AdminTenantListResponse,
     ApiKeyCreateRequest,
     ApiKeyIssueResponse,
     ApiKeyListResponse,
     ApiKeyRevokeResponse,
     ApiKeyRotateRequest,
     ApiKeyRotateResponse,
+    ApiKeySummary,
+    AskRequest,
+    AskResponse,
     AuthValidationResponse,
+    BatchRetrieveRequest,
+    BatchRetrieveResponse,
+    BrowserTokenRequest,
+    BrowserTokenResponse,
+    CaptureRequest,
+    ChangeFeedResponse,
+    ChatHistoryResponse,
+    ChatRequest,
+    ChatResponse,
+    Digest,
+    DigestRequest,
+    EntityAttributesPatchRequest,
+    EntityAttributesResponse,
+    EntityConflictsResponse,
+    EntityGoalsResponse,
+    EntityGroupRequest,
+    EntityGroupResponse,
+    EntityTopicsResponse,
+    EventTypeRegistry,
+    EventTypeRegistryRequest,
+    ExportJob,
+    ExportJobListResponse,
+    ExportJobRequest,
+    FanoutRetrieveRequest,
+    FanoutRetrieveResponse,
     FeedbackBatchRequest,
     FeedbackBatchResponse,
     FeedbackRequest,
     FeedbackResponse,
+    HookIngestResponse,
+    HookMemory,
+    ImportRequest,
+    ImportResponse,
+    IndexDeployment,
+    IndexDeploymentListResponse,
+    IndexDeploymentRequest,
+    IndexMirrorRequest,
     IngestBatchRequest,
     IngestBatchResponse,
     IngestRequest,
     IngestResponse,
+    IngestStreamAck,
+    Memory,
+    MemoryDiffResponse,
+    MemoryLink,
+    MemoryLinkListResponse,
+    MemoryLinkRequest,
     MemoryQualityResponse,
+    MemoryRevertRequest,
+    MemoryReview,
+    MemoryReviewDecision,
+    MemoryReviewListResponse,
+    MemoryShare,
+    MemoryShareListResponse,
+    MemoryShareRequest,
+    MemoryUpdateRequest,
+    MemoryVersionListResponse,
+    ModerationAppealRequest,
+    ModerationResolveRequest,
+    ModerationReview,
+    ModerationReviewListResponse,
+    Namespace,
+    NamespaceListResponse,
+    NamespaceRequest,
+    OptimizeJob,
+    OptimizeJobListResponse,
     PaginatedMemoriesResponse,
     PilotProRequestResponse,
+    PinnedClock,
+    PipelineWebhook,
+    PipelineWebhookRequest,
+    ProcedureRequest,
+    ProcedureSearchResponse,
+    QueryAnalyticsResponse,
+    RecallRequest,
+    RecallResponse,
+    ReflectRequest,
+    ReflectResponse,
+    ReplicationBatch,
+    ResurfaceResponse,
+    RetentionPolicy,
+    RetentionPolicyRequest,
+    RetrieveBoost,
     RetrieveRequest,
     RetrieveResponse,
+    SavedQuery,
+    SavedQueryListResponse,
+    SavedQueryRequest,
+    SavedQueryRunRequest,
+    SessionEndResponse,
+    SessionMemoryItem,
+    SessionMemoryListResponse,
+    SessionMemoryRequest,
+    SimilarMemoriesResponse,
     StatusResponse,
     TenantMetricsResponse,
+    TenantResidency,
+    TenantResidencyRequest,
     TimeRange,
+    UrlSource,
+    UrlSourceListResponse,
+    UrlSourceRequest,
+    VectorSearchRequest,
+    WritePolicy,
+    WritePolicyRequest,
 )
-from orbit_api.auth import AuthContext, require_auth_context
+from orbit.signing import NONCE_HEADER, TIMESTAMP_HEADER, parse_authorization
+from orbit_api.auth import AuthContext, require_auth_context, require_operator_context
+from orbit_api.chat_proxy import ChatMemoryProxy, UpstreamError
 from orbit_api.config import ApiConfig
+from orbit_api.config_reload import ConfigReloader
+from orbit_api.email_connector import (
+    EmailIngestor,
+    extract_sendgrid_email,
+    extract_ses_email,
+    is_sns_subscribe_url,
+)
+from orbit_api.ndjson import LineTooLongError, iter_lines
+from orbit_api.regions import (
+    FORWARDED_FROM_HEADER,
+    READ_METHODS,
+    REGION_HEADER,
+    REPLICATION_NONCE_HEADER,
+    REPLICATION_SIGNATURE_HEADER,
+    REPLICATION_TIMESTAMP_HEADER,
+    verify_replication_signature,
+)
 from orbit_api.service import (
+    BROWSER_TOKEN_AUTH_TYPE,
     AccountMappingError,
     ApiKeyAuthenticationError,
     IdempotencyConflictError,
+    IndexDeploymentConflictError,
     OrbitApiService,
     PlanQuotaExceededError,
+    PreconditionFailedError,
     RateLimitExceededError,
     RateLimitSnapshot,
 )
+from orbit_api.slack import SlackIntegration, verify_signature
+from orbit_api.synthesis import SummarizationError
 from orbit_api.telemetry import configure_telemetry
+from orbit_api.tracing import (
+    REQUEST_ID_HEADER,
+    TRACEPARENT_HEADER,
+    TRACESTATE_HEADER,
+    TraceContext,
+    bind_trace_context,
+    outbound_headers,
+)
+from orbit_api.validation import (
+    VALIDATION_ERROR_CODE,
+    FieldError,
+    FieldValidationError,
+    field_errors_from_pydantic,
+    validation_error_content,
+)
+from orbit_api.versioning import (
+    VERSION_HEADER,
+    ApiVersionMiddleware,
+    Deprecation,
+    request_path,
+)
 
 _security = HTTPBearer(auto_error=False)
+_ADMIN_DASHBOARD_HTML = Path(__file__).parent / "static" / "admin.html"
+_HOP_BY_HOP_HEADERS = frozenset(
+    {"connection", "content-length", "host", "keep-alive", "transfer-encoding", "upgrade"}
+)
+_TRACE_HEADERS = frozenset({REQUEST_ID_HEADER.lower(), TRACEPARENT_HEADER, TRACESTATE_HEADER})
+_REGION_FORWARD_TIMEOUT_SECONDS = 30.0
+_BROWSER_TOKEN_PATHS = frozenset({"/v1/retrieve", "/v1/feedback", "/v1/auth/validate"})
+# GET /v1/retrieve query parameters named differently from the RetrieveRequest field they set.
+_RETRIEVE_QUERY_FIELDS = {"start_time": "time_range", "end_time": "time_range"}
 
 
 def create_app(
@@ -75,9 +240,25 @@ def create_app(
     configure_logging()
     log = get_logger("orbit.api")
 
+    config_reloader = (
+        ConfigReloader(
+            config,
+            config.config_file,
+            source=ApiConfig.from_environment if api_config is None else None,
+        )
+        if config.config_file
+        else None
+    )
+
     @asynccontextmanager
     async def lifespan(app_instance: FastAPI) -> AsyncIterator[None]:
+        if config_reloader is not None:
+            config_reloader.install_signal_handler()
+            config_reloader.start_watching(config.config_watch_seconds)
+        _service_from_app(app_instance).start_session_sweeper()
         yield
+        if config_reloader is not None:
+            config_reloader.stop()
         service = _service_from_app(app_instance)
         service.close()
 
@@ -87,18 +268,32 @@ def create_app(
         description="Memory infrastructure API for developer applications.",
         lifespan=lifespan,
     )
+    app.state.config_reloader = config_reloader
     app.state.orbit_service = OrbitApiService(
         api_config=config,
         engine_config=engine_config,
     )
 
-    if config.cors_allow_origins:
+    deprecations: list[Deprecation] = []
+    if config.api_v1_deprecated_at is not None:
+        deprecations.append(
+            Deprecation(
+                path="/v1",
+                deprecated_at=config.api_v1_deprecated_at,
+                sunset=config.api_v1_sunset,
+                successor="/v2",
+            )
+        )
+    app.add_middleware(ApiVersionMiddleware, routes=app.router.routes, deprecations=deprecations)
+
+    if config.cors_allow_origins or config.cors_allow_origin_regex:
         allow_origins = (
             ["*"] if any(item == "*" for item in config.cors_allow_origins) else config.cors_allow_origins
         )
         app.add_middleware(
             CORSMiddleware,
             allow_origins=allow_origins,
+            allow_origin_regex=config.cors_allow_origin_regex,
             allow_credentials=False,
             allow_methods=["*"],
             allow_headers=["*"],
@@ -109,6 +304,14 @@ def create_app(
                 "Retry-After",
                 "X-Idempotency-Replayed",
                 "X-Orbit-Error-Code",
+                "ETag",
+                VERSION_HEADER,
+                "Deprecation",
+                "Sunset",
+                "Link",
+                REGION_HEADER,
+                REQUEST_ID_HEADER,
+                TRACEPARENT_HEADER,
             ],
         )
 
@@ -147,6 +350,44 @@ def create_app(
     )
     app.add_middleware(SlowAPIMiddleware)
 
+    def region_forwarded_handler(request: Request, exc: _RegionForwarded) -> Response:
+        return exc.response
+
+    app.add_exception_handler(
+        _RegionForwarded,
+        cast(ExceptionHandler, region_forwarded_handler),
+    )
+
+    def validation_error_handler(request: Request, exc: Exception) -> JSONResponse:
+        if isinstance(exc, FieldValidationError):
+            errors = exc.errors
+        elif isinstance(exc, RequestValidationError):
+            errors = field_errors_from_pydantic(exc.errors())
+        else:
+            # A request model built inside the endpoint, e.g. RetrieveRequest from query
+            # parameters, rejected its input.
+            errors = field_errors_from_pydantic(
+                cast(ValidationError, exc).errors(include_url=False),
+                location="query" if request.method in {"GET", "DELETE"} else "body",
+            )
+        log.info(
+            "request_validation_failed",
+            fields=[error.field for error in errors],
+            path=str(request.url.path),
+        )
+        response = JSONResponse(
+            status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+            content=validation_error_content(errors),
+        )
+        response.headers["X-Orbit-Error-Code"] = VALIDATION_ERROR_CODE
+        return response
+
+    for validation_exception in (RequestValidationError, ValidationError, FieldValidationError):
+        app.add_exception_handler(
+            validation_exception,
+            cast(ExceptionHandler, validation_error_handler),
+        )
+
     configure_telemetry(app, config)
 
     @app.middleware("http")
@@ -158,23 +399,60 @@ def create_app(
             service.record_http_response(500)
             raise
         service.record_http_response(response.status_code)
+        if config.region is not None and REGION_HEADER not in response.headers:
+            response.headers[REGION_HEADER] = config.region
         if (
-            request.url.path.startswith("/v1/dashboard")
+            request.url.path.startswith(("/v1/dashboard", "/v2/dashboard"))
             and response.status_code in (status.HTTP_401_UNAUTHORIZED, status.HTTP_403_FORBIDDEN)
         ):
             service.record_dashboard_auth_failure()
         return response
 
+    @app.middleware("http")
+    async def propagate_trace_context(request: Request, call_next: Callable) -> Response:
+        trace_context = TraceContext.from_headers(request.headers)
+        with bind_trace_context(trace_context):
+            response = await call_next(request)
+        response.headers.update(trace_context.as_headers())
+        return response
+
     limit = limiter.limit
 
     def get_service() -> OrbitApiService:
         return _service_from_app(app)
 
+    async def read_signed_body(request: Request) -> bytes:
+        if parse_authorization(request.headers.get("authorization", "")) is None:
+            return b""
+        return await request.body()
+
     def get_auth_context(
         request: Request,
         credentials: Annotated[HTTPAuthorizationCredentials | None, Depends(_security)],
         service: Annotated[OrbitApiService, Depends(get_service)],
+        signed_body: Annotated[bytes, Depends(read_signed_body)],
     ) -> AuthContext:
+        signed = parse_authorization(request.headers.get("authorization", ""))
+        if signed is not None:
+            key_id, signature = signed
+            try:
+                context = service.authenticate_signed_request(
+                    key_id=key_id,
+                    signature=signature,
+                    method=request.method,
+                    path=request_path(request),
+                    query=request.url.query,
+                    timestamp=request.headers.get(TIMESTAMP_HEADER, ""),
+                    nonce=request.headers.get(NONCE_HEADER, ""),
+                    body=signed_body,
+                )
+            except ApiKeyAuthenticationError as exc:
+                raise HTTPException(
+                    status_code=status.HTTP_401_UNAUTHORIZED,
+                    detail="Invalid request signature.",
+                ) from exc
+            request.state.auth_context = context
+            return context
         if credentials is not None:
             bearer_token = credentials.credentials.strip()
             if bearer_token.startswith("orbit_pk_"):
@@ -182,6 +460,7 @@ def create_app(
                     context = service.authenticate_api_key(
                         bearer_token,
                         source=f"{request.method} {request.url.path}",
+                        origin=request.headers.get("origin"),
                     )
                 except ApiKeyAuthenticationError as exc:
                     raise HTTPException(
@@ -191,6 +470,8 @@ def create_app(
                 request.state.auth_context = context
                 return context
         context = require_auth_context(credentials=credentials, config=service.config)
+        if _is_browser_token(context):
+            _enforce_browser_token(context, request)
         try:
             context = service.resolve_account_context(context)
         except AccountMappingError as exc:
@@ -201,6 +482,104 @@ def create_app(
         request.state.auth_context = context
         return context
 
+    def get_hook_auth_context(
+        request: Request,
+        credentials: Annotated[HTTPAuthorizationCredentials | None, Depends(_security)],
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        signed_body: Annotated[bytes, Depends(read_signed_body)],
+        api_key: Annotated[str | None, Query()] = None,
+    ) -> AuthContext:
+        if credentials is None and api_key and config.allow_query_api_key:
+            try:
+                context = service.authenticate_api_key(
+                    api_key.strip(),
+                    source=f"{request.method} {request.url.path}",
+                    origin=request.headers.get("origin"),
+                )
+            except ApiKeyAuthenticationError as exc:
+                raise HTTPException(
+                    status_code=status.HTTP_401_UNAUTHORIZED,
+                    detail="Invalid API key.",
+                ) from exc
+            request.state.auth_context = context
+            return context
+        return get_auth_context(request, credentials, service, signed_body)
+
+    async def _route_to_home_region(
+        request: Request,
+        auth: AuthContext,
+        service: OrbitApiService,
+    ) -> AuthContext:
+        """Serve the request here, or answer it from the tenant's home region."""
+        # Strong reads need the home region's writes, so they are routed like writes.
+        method = "POST" if request.query_params.get("consistency") == "strong" else request.method
+        route = service.region_route(auth.subject, method=method)
+        if route.action == "local":
+            return auth
+        home_region = route.region or ""
+        base_url = config.region_peers.get(home_region)
+        if route.action == "reject" or base_url is None or FORWARDED_FROM_HEADER in request.headers:
+            raise HTTPException(
+                status_code=status.HTTP_421_MISDIRECTED_REQUEST,
+                detail={
+                    "message": f"Account data is served from region {home_region}.",
+                    "error_code": "region_misdirected",
+                },
+                headers={REGION_HEADER: home_region, "X-Orbit-Error-Code": "region_misdirected"},
+            )
+        log.info(
+            "region_forward",
+            account=auth.subject,
+            region=home_region,
+            path=str(request.url.path),
+        )
+        raise _RegionForwarded(
+            await _forward_to_region(
+                request,
+                base_url,
+                home_region=home_region,
+                local_region=config.region or "",
+            )
+        )
+
+    async def get_regional_auth_context(
+        request: Request,
+        auth: Annotated[AuthContext, Depends(get_auth_context)],
+        service: Annotated[OrbitApiService, Depends(get_service)],
+    ) -> AuthContext:
+        return await _route_to_home_region(request, auth, service)
+
+    async def get_regional_hook_auth_context(
+        request: Request,
+        auth: Annotated[AuthContext, Depends(get_hook_auth_context)],
+        service: Annotated[OrbitApiService, Depends(get_service)],
+    ) -> AuthContext:
+        return await _route_to_home_region(request, auth, service)
+
+    async def require_replication_peer(
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+    ) -> None:
+        if not config.replication_secret:
+            raise HTTPException(status_code=status.HTTP_404_NOT_FOUND, detail="Not found.")
+        nonce = request.headers.get(REPLICATION_NONCE_HEADER, "")
+        valid = verify_replication_signature(
+            config.replication_secret,
+            signature=request.headers.get(REPLICATION_SIGNATURE_HEADER, ""),
+            method=request.method,
+            path=request.url.path,
+            query=request.url.query,
+            timestamp=request.headers.get(REPLICATION_TIMESTAMP_HEADER, ""),
+            nonce=nonce,
+            body=await request.body(),
+        )
+        # Checked only after the signature, so unsigned requests cannot fill the nonce table.
+        if not valid or not service.claim_replication_nonce(nonce):
+            raise HTTPException(
+                status_code=status.HTTP_401_UNAUTHORIZED,
+                detail="Invalid replication signature.",
+            )
+
     def _require_any_scope(auth: AuthContext, allowed_scopes: tuple[str, ...]) -> AuthContext:
         if "admin" in auth.scopes or "*" in auth.scopes:
             return auth
@@ -213,23 +592,43 @@ def create_app(
         )
 
     def require_read_scope(
-        auth: Annotated[AuthContext, Depends(get_auth_context)],
+        auth: Annotated[AuthContext, Depends(get_regional_auth_context)],
     ) -> AuthContext:
         return _require_any_scope(auth, ("read", "memory:read"))
 
     def require_write_scope(
-        auth: Annotated[AuthContext, Depends(get_auth_context)],
+        auth: Annotated[AuthContext, Depends(get_regional_auth_context)],
     ) -> AuthContext:
         return _require_any_scope(auth, ("write", "memory:write"))
 
     def require_feedback_scope(
-        auth: Annotated[AuthContext, Depends(get_auth_context)],
+        auth: Annotated[AuthContext, Depends(get_regional_auth_context)],
     ) -> AuthContext:
         return _require_any_scope(
             auth,
             ("feedback", "memory:feedback", "write", "memory:write"),
         )
 
+    def require_import_scope(
+        auth: Annotated[AuthContext, Depends(get_regional_auth_context)],
+    ) -> AuthContext:
+        return _require_any_scope(auth, ("import", "memory:import"))
+
+    def require_review_scope(
+        auth: Annotated[AuthContext, Depends(get_regional_auth_context)],
+    ) -> AuthContext:
+        return _require_any_scope(auth, ("review", "memory:review"))
+
+    def require_hook_read_scope(
+        auth: Annotated[AuthContext, Depends(get_regional_hook_auth_context)],
+    ) -> AuthContext:
+        return _require_any_scope(auth, ("read", "memory:read"))
+
+    def require_hook_write_scope(
+        auth: Annotated[AuthContext, Depends(get_regional_hook_auth_context)],
+    ) -> AuthContext:
+        return _require_any_scope(auth, ("write", "memory:write"))
+
     def require_keys_read_scope(
         auth: Annotated[AuthContext, Depends(get_auth_context)],
     ) -> AuthContext:
@@ -240,6 +639,28 @@ def create_app(
     ) -> AuthContext:
         return _require_any_scope(auth, ("keys:write", "write"))
 
+    def require_operator(
+        credentials: Annotated[HTTPAuthorizationCredentials | None, Depends(_security)],
+    ) -> AuthContext:
+        if not config.admin_dashboard_enabled or not config.operator_tokens:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail="Admin dashboard is disabled.",
+            )
+        return require_operator_context(credentials=credentials, config=config)
+
+    def require_token_mint_scope(
+        auth: Annotated[AuthContext, Depends(get_auth_context)],
+    ) -> AuthContext:
+        return _require_any_scope(auth, ("tokens:write", "keys:write", "write"))
+
+    def _include_embeddings(auth: AuthContext, fields: str | None) -> bool:
+        # Embeddings can be used to approximate content, so they need their own scope.
+        if fields is None:
+            return False
+        _require_any_scope(auth, ("memory:embeddings",))
+        return True
+
     @app.post(
         "/v1/ingest",
         response_model=IngestResponse,
@@ -254,19 +675,18 @@ def create_app(
         auth: Annotated[AuthContext, Depends(require_write_scope)],
         idempotency_key: Annotated[str | None, Header(alias="Idempotency-Key")] = None,
     ) -> IngestResponse:
-        if len(payload.content) > config.max_ingest_content_chars:
-            raise HTTPException(
-                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
-                detail=(
-                    f"content exceeds ORBIT_MAX_INGEST_CONTENT_CHARS="
-                    f"{config.max_ingest_content_chars}"
-                ),
-            )
+        _check_ingest_fields(
+            config,
+            payload.content,
+            payload.event_type,
+            on_oversize=payload.on_oversize or config.on_oversize,
+        )
         try:
             result, snapshot, replayed = service.ingest_with_quota(
                 account_key=auth.subject,
                 request=payload,
                 idempotency_key=idempotency_key,
+                key_id=_api_key_id(auth),
             )
         except ValueError as exc:
             raise HTTPException(
@@ -291,63 +711,90 @@ def create_app(
         )
         return result
 
-    @app.get("/v1/retrieve", response_model=RetrieveResponse)
+    @app.post("/v1/ingest/stream", response_class=StreamingResponse)
     @limit(config.per_minute_limit)
-    def retrieve_endpoint(
+    async def ingest_stream_endpoint(
         request: Request,
-        response: Response,
         service: Annotated[OrbitApiService, Depends(get_service)],
-        auth: Annotated[AuthContext, Depends(require_read_scope)],
-        query: Annotated[str, Query(min_length=1, max_length=config.max_query_chars)],
-        limit_count: Annotated[int, Query(alias="limit", ge=1, le=100)] = 10,
-        entity_id: str | None = None,
-        event_type: str | None = None,
-        start_time: datetime | None = None,
-        end_time: datetime | None = None,
-    ) -> RetrieveResponse:
-        snapshot = _consume_or_raise(
-            service.consume_query_quota,
-            account_key=auth.subject,
-            amount=1,
-        )
-        retrieve_request = RetrieveRequest(
-            query=query,
-            limit=limit_count,
-            entity_id=entity_id,
-            event_type=event_type,
-            time_range=_build_time_range(start_time, end_time),
-        )
-        result = service.retrieve(retrieve_request, account_key=auth.subject)
-        _apply_rate_headers(response, snapshot)
-        log.info(
-            "retrieve",
-            account=auth.subject,
-            returned=len(result.memories),
-            path=str(request.url.path),
-        )
-        return result
+        auth: Annotated[AuthContext, Depends(require_write_scope)],
+    ) -> StreamingResponse:
+        content_type = request.headers.get("content-type", "").split(";")[0].strip().lower()
+        if content_type != "application/x-ndjson":
+            raise HTTPException(
+                status_code=status.HTTP_415_UNSUPPORTED_MEDIA_TYPE,
+                detail="Content-Type must be application/x-ndjson.",
+            )
+        key_id = _api_key_id(auth)
 
-    @app.post("/v1/feedback", response_model=FeedbackResponse)
+        async def acks() -> AsyncIterator[bytes]:
+            accepted = failed = 0
+            try:
+                async for number, line in iter_lines(
+                    request.stream(), max_line_bytes=config.max_stream_line_bytes
+                ):
+                    ack = await run_in_threadpool(
+                        _ingest_stream_line,
+                        service,
+                        config,
+                        line,
+                        number=number,
+                        account_key=auth.subject,
+                        key_id=key_id,
+                    )
+                    accepted += int(ack.ok)
+                    failed += int(not ack.ok)
+                    yield ack.model_dump_json(exclude_none=True).encode("utf-8") + b"\n"
+                    if ack.status_code == status.HTTP_429_TOO_MANY_REQUESTS:
+                        # Every later line would fail the same way.
+                        break
+            except LineTooLongError as exc:
+                failed += 1
+                ack = IngestStreamAck(
+                    line=exc.line,
+                    ok=False,
+                    status_code=status.HTTP_413_REQUEST_ENTITY_TOO_LARGE,
+                    error=str(exc),
+                )
+                yield ack.model_dump_json(exclude_none=True).encode("utf-8") + b"\n"
+            log.info(
+                "ingest_stream",
+                account=auth.subject,
+                accepted=accepted,
+                failed=failed,
+                path=str(request.url.path),
+            )
+
+        return StreamingResponse(acks(), media_type="application/x-ndjson")
+
+    @app.post(
+        "/v1/capture",
+        response_model=IngestResponse,
+        status_code=status.HTTP_201_CREATED,
+    )
     @limit(config.per_minute_limit)
-    def feedback_endpoint(
-        payload: FeedbackRequest,
+    def capture_endpoint(
+        payload: CaptureRequest,
         request: Request,
         response: Response,
         service: Annotated[OrbitApiService, Depends(get_service)],
-        auth: Annotated[AuthContext, Depends(require_feedback_scope)],
+        auth: Annotated[AuthContext, Depends(require_write_scope)],
         idempotency_key: Annotated[str | None, Header(alias="Idempotency-Key")] = None,
-    ) -> FeedbackResponse:
+    ) -> IngestResponse:
+        ingest_request = service.capture_to_ingest(payload)
+        _check_ingest_fields(
+            config,
+            ingest_request.content,
+            None,
+            label="capture",
+            on_oversize=config.on_oversize,
+        )
         try:
-            result, snapshot, replayed = service.feedback_with_quota(
+            result, snapshot, replayed = service.ingest_with_quota(
                 account_key=auth.subject,
-                request=payload,
+                request=ingest_request,
                 idempotency_key=idempotency_key,
+                key_id=_api_key_id(auth),
             )
-        except KeyError as exc:
-            raise HTTPException(
-                status_code=status.HTTP_404_NOT_FOUND,
-                detail=str(exc),
-            ) from exc
         except ValueError as exc:
             raise HTTPException(
                 status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
@@ -363,375 +810,3905 @@ def create_app(
         _apply_rate_headers(response, snapshot)
         response.headers["X-Idempotency-Replayed"] = "true" if replayed else "false"
         log.info(
-            "feedback",
+            "capture",
             account=auth.subject,
             memory_id=result.memory_id,
+            origin=request.headers.get("origin"),
             path=str(request.url.path),
         )
         return result
 
-    @app.post("/v1/ingest/batch", response_model=IngestBatchResponse)
+    @app.post(
+        "/v1/sources/url",
+        response_model=UrlSource,
+        status_code=status.HTTP_201_CREATED,
+    )
     @limit(config.per_minute_limit)
-    def ingest_batch_endpoint(
-        payload: IngestBatchRequest,
+    def create_url_source_endpoint(
+        payload: UrlSourceRequest,
         request: Request,
-        response: Response,
         service: Annotated[OrbitApiService, Depends(get_service)],
         auth: Annotated[AuthContext, Depends(require_write_scope)],
-        idempotency_key: Annotated[str | None, Header(alias="Idempotency-Key")] = None,
-    ) -> IngestBatchResponse:
-        if not payload.events:
-            raise HTTPException(
-                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
-                detail="events batch cannot be empty",
-            )
-        if len(payload.events) > config.max_batch_items:
-            raise HTTPException(
-                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
-                detail=f"events batch exceeds ORBIT_MAX_BATCH_ITEMS={config.max_batch_items}",
-            )
-        if any(
-            len(item.content) > config.max_ingest_content_chars
-            for item in payload.events
-        ):
-            raise HTTPException(
-                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
-                detail=(
-                    f"one or more events exceed ORBIT_MAX_INGEST_CONTENT_CHARS="
-                    f"{config.max_ingest_content_chars}"
-                ),
-            )
+    ) -> UrlSource:
+        result = service.create_url_source(payload, account_key=auth.subject)
+        log.info(
+            "url_source_created",
+            account=auth.subject,
+            source_id=result.source_id,
+            status=result.last_status,
+            memories=len(result.memory_ids),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get("/v1/sources/url", response_model=UrlSourceListResponse)
+    @limit(config.per_minute_limit)
+    def url_sources_endpoint(
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+    ) -> UrlSourceListResponse:
+        return service.list_url_sources(account_key=auth.subject)
+
+    @app.post("/v1/sources/url/{source_id}/crawl", response_model=UrlSource)
+    @limit(config.per_minute_limit)
+    def crawl_url_source_endpoint(
+        source_id: str,
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_write_scope)],
+    ) -> UrlSource:
         try:
-            items, snapshot, replayed = service.ingest_batch_with_quota(
-                account_key=auth.subject,
-                events=payload.events,
-                idempotency_key=idempotency_key,
-            )
-        except ValueError as exc:
-            raise HTTPException(
-                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
-                detail=str(exc),
-            ) from exc
-        except IdempotencyConflictError as exc:
+            result = service.crawl_url_source(source_id, account_key=auth.subject)
+        except KeyError as exc:
             raise HTTPException(
-                status_code=status.HTTP_409_CONFLICT,
-                detail=str(exc),
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc.args[0]) if exc.args else "URL source not found.",
             ) from exc
-        except RateLimitExceededError as exc:
-            raise _rate_limit_exception(exc) from exc
-        _apply_rate_headers(response, snapshot)
-        response.headers["X-Idempotency-Replayed"] = "true" if replayed else "false"
         log.info(
-            "ingest_batch",
+            "url_source_crawled",
             account=auth.subject,
-            count=len(items),
+            source_id=source_id,
+            status=result.last_status,
             path=str(request.url.path),
         )
-        return IngestBatchResponse(items=items)
+        return result
 
-    @app.post("/v1/feedback/batch", response_model=FeedbackBatchResponse)
+    @app.delete("/v1/sources/url/{source_id}", response_model=UrlSource)
     @limit(config.per_minute_limit)
-    def feedback_batch_endpoint(
-        payload: FeedbackBatchRequest,
+    def delete_url_source_endpoint(
+        source_id: str,
         request: Request,
-        response: Response,
         service: Annotated[OrbitApiService, Depends(get_service)],
-        auth: Annotated[AuthContext, Depends(require_feedback_scope)],
-        idempotency_key: Annotated[str | None, Header(alias="Idempotency-Key")] = None,
-    ) -> FeedbackBatchResponse:
-        if not payload.feedback:
-            raise HTTPException(
-                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
-                detail="feedback batch cannot be empty",
-            )
-        if len(payload.feedback) > config.max_batch_items:
-            raise HTTPException(
-                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
-                detail=(
-                    f"feedback batch exceeds ORBIT_MAX_BATCH_ITEMS="
-                    f"{config.max_batch_items}"
-                ),
-            )
+        auth: Annotated[AuthContext, Depends(require_write_scope)],
+    ) -> UrlSource:
         try:
-            items, snapshot, replayed = service.feedback_batch_with_quota(
-                account_key=auth.subject,
-                feedback=payload.feedback,
-                idempotency_key=idempotency_key,
-            )
+            result = service.delete_url_source(source_id, account_key=auth.subject)
         except KeyError as exc:
             raise HTTPException(
                 status_code=status.HTTP_404_NOT_FOUND,
-                detail=str(exc),
+                detail=str(exc.args[0]) if exc.args else "URL source not found.",
             ) from exc
+        log.info(
+            "url_source_deleted",
+            account=auth.subject,
+            source_id=source_id,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.put("/v1/saved-queries/{name}", response_model=SavedQuery)
+    @limit(config.per_minute_limit)
+    def set_saved_query_endpoint(
+        name: str,
+        payload: SavedQueryRequest,
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_write_scope)],
+    ) -> SavedQuery:
+        try:
+            result = service.set_saved_query(name, payload, account_key=auth.subject)
         except ValueError as exc:
             raise HTTPException(
                 status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
                 detail=str(exc),
             ) from exc
-        except IdempotencyConflictError as exc:
-            raise HTTPException(
-                status_code=status.HTTP_409_CONFLICT,
-                detail=str(exc),
-            ) from exc
+        log.info(
+            "saved_query_set",
+            account=auth.subject,
+            name=name,
+            parameters=result.parameters,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get("/v1/saved-queries", response_model=SavedQueryListResponse)
+    @limit(config.per_minute_limit)
+    def saved_queries_endpoint(
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+    ) -> SavedQueryListResponse:
+        return service.list_saved_queries(account_key=auth.subject)
+
+    @app.get("/v1/saved-queries/{name}", response_model=SavedQuery)
+    @limit(config.per_minute_limit)
+    def saved_query_endpoint(
+        name: str,
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+    ) -> SavedQuery:
+        try:
+            return service.saved_query(name, account_key=auth.subject)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc.args[0]) if exc.args else "Saved query not found.",
+            ) from exc
+
+    @app.delete("/v1/saved-queries/{name}", response_model=SavedQuery)
+    @limit(config.per_minute_limit)
+    def delete_saved_query_endpoint(
+        name: str,
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_write_scope)],
+    ) -> SavedQuery:
+        try:
+            result = service.delete_saved_query(name, account_key=auth.subject)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc.args[0]) if exc.args else "Saved query not found.",
+            ) from exc
+        log.info(
+            "saved_query_deleted",
+            account=auth.subject,
+            name=name,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post("/v1/saved-queries/{name}/run", response_model=RetrieveResponse)
+    @limit(config.per_minute_limit)
+    def run_saved_query_endpoint(
+        name: str,
+        payload: SavedQueryRunRequest,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+    ) -> RetrieveResponse:
+        snapshot = _consume_or_raise(
+            service.consume_query_quota,
+            account_key=auth.subject,
+            amount=1,
+        )
+        payload = payload.model_copy(update={"agent_id": _acting_agent(auth, payload.agent_id)})
+        try:
+            result = service.run_saved_query(
+                name,
+                payload,
+                account_key=auth.subject,
+                max_sensitivity=_sensitivity_clearance(auth),
+            )
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc.args[0]) if exc.args else "Saved query not found.",
+            ) from exc
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        _apply_rate_headers(response, snapshot)
+        log.info(
+            "saved_query_run",
+            account=auth.subject,
+            name=name,
+            returned=len(result.memories),
+            degraded=result.degraded,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post(
+        "/v1/hooks/ingest",
+        response_model=HookIngestResponse,
+        status_code=status.HTTP_201_CREATED,
+    )
+    @limit(config.per_minute_limit)
+    def hook_ingest_endpoint(
+        payload: dict[str, Any],
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_hook_write_scope)],
+        idempotency_key: Annotated[str | None, Header(alias="Idempotency-Key")] = None,
+    ) -> HookIngestResponse:
+        try:
+            ingest_request = service.hook_to_ingest(payload)
+            _check_ingest_fields(
+                config,
+                ingest_request.content,
+                ingest_request.event_type,
+                on_oversize=config.on_oversize,
+            )
+            result, snapshot, replayed = service.ingest_with_quota(
+                account_key=auth.subject,
+                request=ingest_request,
+                idempotency_key=idempotency_key,
+                key_id=_api_key_id(auth),
+            )
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        except IdempotencyConflictError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_409_CONFLICT,
+                detail=str(exc),
+            ) from exc
         except RateLimitExceededError as exc:
             raise _rate_limit_exception(exc) from exc
         _apply_rate_headers(response, snapshot)
         response.headers["X-Idempotency-Replayed"] = "true" if replayed else "false"
         log.info(
-            "feedback_batch",
+            "hook_ingest",
             account=auth.subject,
-            count=len(items),
+            memory_id=result.memory_id,
             path=str(request.url.path),
         )
-        return FeedbackBatchResponse(items=items)
+        return HookIngestResponse(
+            id=result.memory_id,
+            content=ingest_request.content,
+            entity_id=ingest_request.entity_id,
+            event_type=ingest_request.event_type,
+            created_at=result.encoded_at,
+            importance_score=result.importance_score,
+            stored=result.stored,
+            decision_reason=result.decision_reason,
+        )
 
-    @app.get("/v1/status", response_model=StatusResponse)
-    def status_endpoint(
+    @app.get("/v1/hooks/memories", response_model=list[HookMemory])
+    @limit(config.per_minute_limit)
+    def hook_list_memories_endpoint(
+        request: Request,
+        response: Response,
         service: Annotated[OrbitApiService, Depends(get_service)],
-        auth: Annotated[AuthContext, Depends(require_read_scope)],
-    ) -> StatusResponse:
-        return service.status(auth.subject)
+        auth: Annotated[AuthContext, Depends(require_hook_read_scope)],
+        limit_count: Annotated[int, Query(alias="limit", ge=1, le=100)] = 50,
+        entity_id: str | None = None,
+        event_type: str | None = None,
+    ) -> list[HookMemory]:
+        snapshot = _consume_or_raise(
+            service.consume_query_quota,
+            account_key=auth.subject,
+            amount=1,
+        )
+        result = service.list_hook_memories(
+            account_key=auth.subject,
+            limit=limit_count,
+            entity_id=entity_id,
+            event_type=event_type,
+            max_sensitivity=_sensitivity_clearance(auth),
+            agent_id=_acting_agent(auth, None),
+        )
+        _apply_rate_headers(response, snapshot)
+        log.info(
+            "hook_list_memories",
+            account=auth.subject,
+            count=len(result),
+            path=str(request.url.path),
+        )
+        return result
 
-    @app.get(
-        "/v1/dashboard/memory-quality",
-        response_model=MemoryQualityResponse,
-    )
-    def dashboard_memory_quality_endpoint(
+    @app.get("/v1/hooks/search", response_model=list[HookMemory])
+    @limit(config.per_minute_limit)
+    def hook_search_endpoint(
+        request: Request,
+        response: Response,
         service: Annotated[OrbitApiService, Depends(get_service)],
-        auth: Annotated[AuthContext, Depends(require_keys_read_scope)],
-    ) -> MemoryQualityResponse:
-        return service.memory_quality(auth.subject)
+        auth: Annotated[AuthContext, Depends(require_hook_read_scope)],
+        query: Annotated[str, Query(min_length=1, max_length=config.max_query_chars)],
+        limit_count: Annotated[int, Query(alias="limit", ge=1, le=100)] = 10,
+        entity_id: str | None = None,
+    ) -> list[HookMemory]:
+        snapshot = _consume_or_raise(
+            service.consume_query_quota,
+            account_key=auth.subject,
+            amount=1,
+        )
+        result = service.search_hook_memories(
+            account_key=auth.subject,
+            query=query,
+            limit=limit_count,
+            entity_id=entity_id,
+            max_sensitivity=_sensitivity_clearance(auth),
+        )
+        _apply_rate_headers(response, snapshot)
+        log.info(
+            "hook_search",
+            account=auth.subject,
+            returned=len(result),
+            path=str(request.url.path),
+        )
+        return result
 
-    @app.get("/v1/health")
-    def health_endpoint(
+    @app.get("/v1/retrieve", response_model=RetrieveResponse)
+    @limit(config.per_minute_limit)
+    def retrieve_endpoint(
+        request: Request,
+        response: Response,
         service: Annotated[OrbitApiService, Depends(get_service)],
-    ) -> dict[str, str]:
-        return service.health()
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+        query: Annotated[str, Query(min_length=1, max_length=config.max_query_chars)],
+        limit_count: Annotated[int, Query(alias="limit", ge=1, le=100)] = 10,
+        entity_id: str | None = None,
+        event_type: str | None = None,
+        start_time: datetime | None = None,
+        end_time: datetime | None = None,
+        max_latency_ms: Annotated[int | None, Query(ge=1, le=60_000)] = None,
+        session_id: Annotated[str | None, Query(min_length=1, max_length=128)] = None,
+        mode: Annotated[str, Query(pattern="^(vector|graph)$")] = "vector",
+        graph_hops: Annotated[int, Query(ge=1, le=2)] = 1,
+        topic_id: Annotated[str | None, Query(min_length=1, max_length=64)] = None,
+        min_score: Annotated[float | None, Query(ge=0.0)] = None,
+        fallback: Annotated[
+            str | None,
+            Query(pattern="^(empty|recent|attributes|webhook)$"),
+        ] = None,
+        consistency: Annotated[str, Query(pattern="^(eventual|strong)$")] = "eventual",
+        fields: Annotated[str | None, Query(pattern="^embedding$")] = None,
+        include_linked: bool = False,
+        summarize: bool = False,
+        fast: bool = False,
+        sentiment: str | None = None,
+        emotion: str | None = None,
+        category: str | None = None,
+        agent_id: str | None = None,
+        from_agent: str | None = None,
+        profile: str | None = None,
+        # JSON, e.g. {"tags": {"project-x": 1.5}}, since query strings cannot nest.
+        boost: Annotated[str | None, Query(max_length=8192)] = None,
+        mmr: bool = False,
+        mmr_lambda: Annotated[float, Query(ge=0.0, le=1.0)] = 0.5,
+    ) -> RetrieveResponse:
+        include_embeddings = _include_embeddings(auth, fields)
+        pinned_entity_id = auth.claims.get("entity_id") if _is_browser_token(auth) else None
+        if pinned_entity_id:
+            if entity_id and entity_id != pinned_entity_id:
+                raise HTTPException(
+                    status_code=status.HTTP_403_FORBIDDEN,
+                    detail="Browser token is restricted to a different entity_id.",
+                )
+            entity_id = str(pinned_entity_id)
+        agent_id = _acting_agent(auth, agent_id)
+        retrieve_request = RetrieveRequest(
+            query=query,
+            limit=limit_count,
+            entity_id=entity_id,
+            event_type=event_type,
+            time_range=_build_time_range(start_time, end_time),
+            max_latency_ms=max_latency_ms,
+            session_id=session_id,
+            mode=mode,
+            graph_hops=graph_hops,
+            topic_id=topic_id,
+            min_score=min_score,
+            fallback=fallback,
+            consistency=consistency,
+            include_linked=include_linked,
+            summarize=summarize,
+            fast=fast,
+            sentiment=sentiment,
+            emotion=emotion,
+            category=category,
+            agent_id=agent_id,
+            from_agent=from_agent,
+            profile=profile,
+            boost=RetrieveBoost.model_validate_json(boost) if boost else None,
+            mmr=mmr,
+            mmr_lambda=mmr_lambda,
+        )
+        # Only parameters the client sent count as set, so the rest take the namespace's
+        # retrieval defaults.
+        sent = {_RETRIEVE_QUERY_FIELDS.get(name, name) for name in request.query_params}
+        retrieve_request = RetrieveRequest.model_validate(
+            retrieve_request.model_dump(include={"query", "entity_id", "agent_id", *sent})
+        )
+        snapshot = _consume_or_raise(
+            service.consume_query_quota,
+            account_key=auth.subject,
+            amount=1,
+        )
+        try:
+            result = service.retrieve(
+                retrieve_request,
+                account_key=auth.subject,
+                max_sensitivity=_sensitivity_clearance(auth),
+            )
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        except SummarizationError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_502_BAD_GATEWAY,
+                detail=str(exc),
+            ) from exc
+        if include_embeddings:
+            service.attach_embeddings(result.memories, account_key=auth.subject)
+        _apply_rate_headers(response, snapshot)
+        log.info(
+            "retrieve",
+            account=auth.subject,
+            returned=len(result.memories),
+            degraded=result.degraded,
+            fallback=result.fallback,
+            summarized=result.summary is not None,
+            cached=result.cached,
+            path=str(request.url.path),
+        )
+        return result
 
-    @app.get("/v1/metrics", response_class=PlainTextResponse)
-    def metrics_endpoint(
+    @app.post(
+        "/v1/sessions/{session_id}/memories",
+        response_model=SessionMemoryItem,
+        status_code=status.HTTP_201_CREATED,
+    )
+    @limit(config.per_minute_limit)
+    def session_remember_endpoint(
+        session_id: str,
+        payload: SessionMemoryRequest,
+        request: Request,
         service: Annotated[OrbitApiService, Depends(get_service)],
-    ) -> str:
-        return service.metrics_text()
+        auth: Annotated[AuthContext, Depends(require_write_scope)],
+    ) -> SessionMemoryItem:
+        _check_ingest_fields(config, payload.content, payload.event_type)
+        try:
+            result = service.remember_in_session(
+                session_id,
+                payload,
+                account_key=auth.subject,
+            )
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "session_remember",
+            account=auth.subject,
+            session_id=result.session_id,
+            path=str(request.url.path),
+        )
+        return result
 
-    @app.get("/v1/tenant-metrics", response_model=TenantMetricsResponse)
-    def tenant_metrics_endpoint(
+    @app.get("/v1/sessions/{session_id}/memories", response_model=SessionMemoryListResponse)
+    @limit(config.per_minute_limit)
+    def session_memories_endpoint(
+        session_id: str,
+        request: Request,
         service: Annotated[OrbitApiService, Depends(get_service)],
         auth: Annotated[AuthContext, Depends(require_read_scope)],
-    ) -> TenantMetricsResponse:
-        return service.tenant_metrics(auth.subject)
+    ) -> SessionMemoryListResponse:
+        try:
+            result = service.session_memories(session_id, account_key=auth.subject)
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "session_memories",
+            account=auth.subject,
+            count=len(result.data),
+            path=str(request.url.path),
+        )
+        return result
 
-    @app.post("/v1/auth/validate", response_model=AuthValidationResponse)
-    def validate_endpoint(
+    @app.post("/v1/sessions/{session_id}/end", response_model=SessionEndResponse)
+    @limit(config.per_minute_limit)
+    def session_end_endpoint(
+        session_id: str,
+        request: Request,
         service: Annotated[OrbitApiService, Depends(get_service)],
-        auth: Annotated[AuthContext, Depends(get_auth_context)],
-    ) -> AuthValidationResponse:
-        return service.validate_token(auth)
+        auth: Annotated[AuthContext, Depends(require_write_scope)],
+    ) -> SessionEndResponse:
+        try:
+            result = service.end_session(session_id, account_key=auth.subject)
+        except RateLimitExceededError as exc:
+            raise _rate_limit_exception(exc) from exc
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "session_end",
+            account=auth.subject,
+            session_id=result.session_id,
+            promoted=len(result.promoted_memory_ids),
+            discarded=result.discarded_count,
+            rejected=result.rejected_count,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get("/v1/resurface", response_model=ResurfaceResponse)
+    @limit(config.per_minute_limit)
+    def resurface_endpoint(
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+        limit_count: Annotated[int, Query(alias="limit", ge=1, le=100)] = 10,
+        entity_id: str | None = None,
+    ) -> ResurfaceResponse:
+        snapshot = _consume_or_raise(
+            service.consume_query_quota,
+            account_key=auth.subject,
+            amount=1,
+        )
+        try:
+            result = service.resurface(
+                entity_id=entity_id,
+                limit=limit_count,
+                max_sensitivity=_sensitivity_clearance(auth),
+                account_key=auth.subject,
+            )
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        _apply_rate_headers(response, snapshot)
+        log.info(
+            "resurface",
+            account=auth.subject,
+            returned=len(result.data),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post("/v1/digests", response_model=Digest, status_code=status.HTTP_201_CREATED)
+    @limit(config.per_minute_limit)
+    def create_digest_endpoint(
+        payload: DigestRequest,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+    ) -> Digest:
+        snapshot = _consume_or_raise(
+            service.consume_query_quota,
+            account_key=auth.subject,
+            amount=1,
+        )
+        try:
+            result = service.create_digest(
+                payload,
+                account_key=auth.subject,
+                max_sensitivity=_sensitivity_clearance(auth),
+            )
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        except SummarizationError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_502_BAD_GATEWAY,
+                detail=str(exc),
+            ) from exc
+        _apply_rate_headers(response, snapshot)
+        log.info(
+            "digest_created",
+            account=auth.subject,
+            digest_id=result.digest_id,
+            period=result.period,
+            memories=result.memory_count,
+            delivered=result.delivered,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get("/v1/digests/{digest_id}", response_model=Digest)
+    @limit(config.per_minute_limit)
+    def digest_endpoint(
+        digest_id: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+    ) -> Digest:
+        try:
+            result = service.digest(digest_id, account_key=auth.subject)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc.args[0]) if exc.args else "Digest not found.",
+            ) from exc
+        return result
+
+    @app.post("/v1/recall", response_model=RecallResponse)
+    @limit(config.per_minute_limit)
+    def recall_endpoint(
+        payload: RecallRequest,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+    ) -> RecallResponse:
+        if len(payload.query) > config.max_query_chars:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=f"query must be at most {config.max_query_chars} characters",
+            )
+        snapshot = _consume_or_raise(
+            service.consume_query_quota,
+            account_key=auth.subject,
+            amount=1,
+        )
+        try:
+            result = service.recall(
+                payload,
+                account_key=auth.subject,
+                max_sensitivity=_sensitivity_clearance(auth),
+            )
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        _apply_rate_headers(response, snapshot)
+        log.info(
+            "recall",
+            account=auth.subject,
+            returned=len(result.memories),
+            attributes=len(result.profile.attributes),
+            session_items=result.session.item_count if result.session else 0,
+            degraded=result.degraded,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post("/v1/ask", response_model=AskResponse)
+    @limit(config.per_minute_limit)
+    def ask_endpoint(
+        payload: AskRequest,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+    ) -> AskResponse:
+        if len(payload.question) > config.max_query_chars:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=f"question must be at most {config.max_query_chars} characters",
+            )
+        snapshot = _consume_or_raise(
+            service.consume_query_quota,
+            account_key=auth.subject,
+            amount=1,
+        )
+        try:
+            result = service.ask(
+                payload,
+                account_key=auth.subject,
+                max_sensitivity=_sensitivity_clearance(auth),
+            )
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        except SummarizationError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_502_BAD_GATEWAY,
+                detail=str(exc),
+            ) from exc
+        _apply_rate_headers(response, snapshot)
+        log.info(
+            "ask",
+            account=auth.subject,
+            answered=result.answer is not None,
+            citations=len(result.citations),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post("/v1/chat", response_model=ChatResponse)
+    @limit(config.per_minute_limit)
+    def chat_endpoint(
+        payload: ChatRequest,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_write_scope)],
+    ) -> ChatResponse:
+        if len(payload.message) > config.max_query_chars:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=f"message must be at most {config.max_query_chars} characters",
+            )
+        snapshot = _consume_or_raise(
+            service.consume_query_quota,
+            account_key=auth.subject,
+            amount=1,
+        )
+        try:
+            result = service.chat(
+                payload,
+                account_key=auth.subject,
+                max_sensitivity=_sensitivity_clearance(auth),
+            )
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        except SummarizationError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_502_BAD_GATEWAY,
+                detail=str(exc),
+            ) from exc
+        _apply_rate_headers(response, snapshot)
+        log.info(
+            "chat",
+            account=auth.subject,
+            session_id=result.session_id,
+            memories=len(result.memories),
+            ingested=len(result.ingested_memory_ids),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get("/v1/chat/{session_id}", response_model=ChatHistoryResponse)
+    @limit(config.per_minute_limit)
+    def chat_history_endpoint(
+        session_id: str,
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+    ) -> ChatHistoryResponse:
+        try:
+            result = service.chat_history(session_id, account_key=auth.subject)
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "chat_history",
+            account=auth.subject,
+            session_id=result.session_id,
+            turns=len(result.data),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.delete("/v1/chat/{session_id}", response_model=ChatHistoryResponse)
+    @limit(config.per_minute_limit)
+    def clear_chat_endpoint(
+        session_id: str,
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_write_scope)],
+    ) -> ChatHistoryResponse:
+        try:
+            result = service.clear_chat(session_id, account_key=auth.subject)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc.args[0]) if exc.args else "Chat session not found.",
+            ) from exc
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "clear_chat",
+            account=auth.subject,
+            session_id=result.session_id,
+            turns=len(result.data),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post("/v1/retrieve/fanout", response_model=FanoutRetrieveResponse)
+    @limit(config.per_minute_limit)
+    def retrieve_fanout_endpoint(
+        payload: FanoutRetrieveRequest,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+    ) -> FanoutRetrieveResponse:
+        if len(payload.query) > config.max_query_chars:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=f"query must be at most {config.max_query_chars} characters",
+            )
+        snapshot = _consume_or_raise(
+            service.consume_query_quota,
+            account_key=auth.subject,
+            amount=len(payload.namespaces),
+        )
+        result = service.retrieve_fanout(
+            payload,
+            account_key=auth.subject,
+            max_sensitivity=_sensitivity_clearance(auth),
+        )
+        _apply_rate_headers(response, snapshot)
+        log.info(
+            "retrieve_fanout",
+            account=auth.subject,
+            namespaces=len(payload.namespaces),
+            returned=len(result.memories),
+            degraded=result.degraded,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post("/v1/retrieve/batch", response_model=BatchRetrieveResponse)
+    @limit(config.per_minute_limit)
+    def retrieve_batch_endpoint(
+        payload: BatchRetrieveRequest,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+    ) -> BatchRetrieveResponse:
+        if len(payload.queries) > config.max_retrieve_batch_queries:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=(
+                    f"queries batch exceeds ORBIT_MAX_RETRIEVE_BATCH_QUERIES="
+                    f"{config.max_retrieve_batch_queries}"
+                ),
+            )
+        if any(len(query) > config.max_query_chars for query in payload.queries):
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=f"query must be at most {config.max_query_chars} characters",
+            )
+        snapshot = _consume_or_raise(
+            service.consume_query_quota,
+            account_key=auth.subject,
+            amount=len(payload.queries),
+        )
+        result = service.retrieve_batch(
+            payload,
+            account_key=auth.subject,
+            max_sensitivity=_sensitivity_clearance(auth),
+        )
+        _apply_rate_headers(response, snapshot)
+        log.info(
+            "retrieve_batch",
+            account=auth.subject,
+            queries=len(payload.queries),
+            returned=sum(len(item.memories) for item in result.results),
+            degraded=result.degraded,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post("/v1/search/vector", response_model=RetrieveResponse)
+    @limit(config.per_minute_limit)
+    def search_vector_endpoint(
+        payload: VectorSearchRequest,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+        fields: Annotated[str | None, Query(pattern="^embedding$")] = None,
+    ) -> RetrieveResponse:
+        include_embeddings = _include_embeddings(auth, fields)
+        snapshot = _consume_or_raise(
+            service.consume_query_quota,
+            account_key=auth.subject,
+            amount=1,
+        )
+        payload = payload.model_copy(update={"agent_id": _acting_agent(auth, payload.agent_id)})
+        try:
+            result = service.search_vector(
+                payload,
+                account_key=auth.subject,
+                max_sensitivity=_sensitivity_clearance(auth),
+            )
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        if include_embeddings:
+            service.attach_embeddings(result.memories, account_key=auth.subject)
+        _apply_rate_headers(response, snapshot)
+        log.info(
+            "search_vector",
+            account=auth.subject,
+            dimensions=len(payload.vector),
+            returned=len(result.memories),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get("/v1/analytics/queries", response_model=QueryAnalyticsResponse)
+    @limit(config.per_minute_limit)
+    def query_analytics_endpoint(
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+        days: Annotated[int, Query(ge=1, le=90)] = 7,
+        limit_count: Annotated[int, Query(alias="limit", ge=1, le=100)] = 20,
+    ) -> QueryAnalyticsResponse:
+        result = service.query_analytics(
+            account_key=auth.subject,
+            days=days,
+            limit=limit_count,
+        )
+        log.info(
+            "query_analytics",
+            account=auth.subject,
+            days=days,
+            total_queries=result.total_queries,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get(
+        "/v1/entities/{entity_id}/attributes",
+        response_model=EntityAttributesResponse,
+    )
+    @limit(config.per_minute_limit)
+    def entity_attributes_endpoint(
+        entity_id: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+    ) -> EntityAttributesResponse:
+        try:
+            result = service.entity_attributes(entity_id, account_key=auth.subject)
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        response.headers["ETag"] = _etag(result.version)
+        log.info(
+            "entity_attributes",
+            account=auth.subject,
+            attributes=len(result.attributes),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.patch(
+        "/v1/entities/{entity_id}/attributes",
+        response_model=EntityAttributesResponse,
+    )
+    @limit(config.per_minute_limit)
+    def entity_attributes_patch_endpoint(
+        entity_id: str,
+        payload: EntityAttributesPatchRequest,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_write_scope)],
+        if_match: Annotated[str | None, Header(alias="If-Match")] = None,
+    ) -> EntityAttributesResponse:
+        try:
+            result = service.patch_entity_attributes(
+                entity_id,
+                payload,
+                account_key=auth.subject,
+                expected_version=_if_match_version(if_match),
+            )
+        except PreconditionFailedError as exc:
+            raise _precondition_failed_exception(exc) from exc
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        response.headers["ETag"] = _etag(result.version)
+        log.info(
+            "entity_attributes_patch",
+            account=auth.subject,
+            changed=len(payload.attributes),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get(
+        "/v1/entities/{entity_id}/topics",
+        response_model=EntityTopicsResponse,
+    )
+    @limit(config.per_minute_limit)
+    def entity_topics_endpoint(
+        entity_id: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+        refresh: bool = False,
+        agent_id: str | None = None,
+    ) -> EntityTopicsResponse:
+        snapshot = _consume_or_raise(
+            service.consume_query_quota,
+            account_key=auth.subject,
+            amount=1,
+        )
+        try:
+            result = service.entity_topics(
+                entity_id,
+                refresh=refresh,
+                account_key=auth.subject,
+                max_sensitivity=_sensitivity_clearance(auth),
+                agent_id=_acting_agent(auth, agent_id),
+            )
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        _apply_rate_headers(response, snapshot)
+        log.info(
+            "entity_topics",
+            account=auth.subject,
+            topics=len(result.topics),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get(
+        "/v1/entities/{entity_id}/conflicts",
+        response_model=EntityConflictsResponse,
+    )
+    @limit(config.per_minute_limit)
+    def entity_conflicts_endpoint(
+        entity_id: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+        agent_id: str | None = None,
+    ) -> EntityConflictsResponse:
+        try:
+            result = service.entity_conflicts(
+                entity_id,
+                account_key=auth.subject,
+                max_sensitivity=_sensitivity_clearance(auth),
+                agent_id=_acting_agent(auth, agent_id),
+            )
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "entity_conflicts",
+            account=auth.subject,
+            conflicts=len(result.conflicts),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get(
+        "/v1/entities/{entity_id}/goals",
+        response_model=EntityGoalsResponse,
+    )
+    @limit(config.per_minute_limit)
+    def entity_goals_endpoint(
+        entity_id: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+        goal_status: Annotated[str | None, Query(alias="status")] = None,
+    ) -> EntityGoalsResponse:
+        try:
+            result = service.entity_goals(entity_id, status=goal_status, account_key=auth.subject)
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "entity_goals",
+            account=auth.subject,
+            goals=len(result.goals),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post(
+        "/v1/entities/{entity_id}/shares",
+        response_model=MemoryShare,
+        status_code=status.HTTP_201_CREATED,
+    )
+    @limit(config.per_minute_limit)
+    def share_memories_endpoint(
+        entity_id: str,
+        payload: MemoryShareRequest,
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_write_scope)],
+    ) -> MemoryShare:
+        try:
+            result = service.share_memories(entity_id, payload, account_key=auth.subject)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "share_memories",
+            account=auth.subject,
+            share_id=result.share_id,
+            target_type=result.target_type,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get(
+        "/v1/entities/{entity_id}/shares",
+        response_model=MemoryShareListResponse,
+    )
+    @limit(config.per_minute_limit)
+    def memory_shares_endpoint(
+        entity_id: str,
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+        direction: Annotated[str, Query(pattern="^(outgoing|incoming)$")] = "outgoing",
+        include_revoked: bool = False,
+    ) -> MemoryShareListResponse:
+        try:
+            result = service.memory_shares(
+                entity_id,
+                direction=direction,
+                include_revoked=include_revoked,
+                account_key=auth.subject,
+            )
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "memory_shares",
+            account=auth.subject,
+            direction=direction,
+            count=len(result.data),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post("/v1/shares/{share_id}/revoke", response_model=MemoryShare)
+    @limit(config.per_minute_limit)
+    def revoke_memory_share_endpoint(
+        share_id: int,
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_write_scope)],
+    ) -> MemoryShare:
+        try:
+            result = service.revoke_memory_share(share_id, account_key=auth.subject)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "revoke_memory_share",
+            account=auth.subject,
+            share_id=share_id,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.put("/v1/groups/{group_id}/members", response_model=EntityGroupResponse)
+    @limit(config.per_minute_limit)
+    def set_entity_group_endpoint(
+        group_id: str,
+        payload: EntityGroupRequest,
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_write_scope)],
+    ) -> EntityGroupResponse:
+        try:
+            result = service.set_entity_group(group_id, payload, account_key=auth.subject)
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "set_entity_group",
+            account=auth.subject,
+            group_id=result.group_id,
+            members=len(result.entity_ids),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get("/v1/groups/{group_id}", response_model=EntityGroupResponse)
+    @limit(config.per_minute_limit)
+    def entity_group_endpoint(
+        group_id: str,
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+    ) -> EntityGroupResponse:
+        try:
+            result = service.entity_group(group_id, account_key=auth.subject)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "entity_group",
+            account=auth.subject,
+            group_id=result.group_id,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post(
+        "/v1/procedures",
+        response_model=IngestResponse,
+        status_code=status.HTTP_201_CREATED,
+    )
+    @limit(config.per_minute_limit)
+    def procedure_ingest_endpoint(
+        payload: ProcedureRequest,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_write_scope)],
+        idempotency_key: Annotated[str | None, Header(alias="Idempotency-Key")] = None,
+    ) -> IngestResponse:
+        ingest_request = service.procedure_to_ingest(payload)
+        _check_ingest_fields(
+            config,
+            ingest_request.content,
+            None,
+            label="procedure",
+            on_oversize=config.on_oversize,
+        )
+        try:
+            result, snapshot, replayed = service.ingest_with_quota(
+                account_key=auth.subject,
+                request=ingest_request,
+                idempotency_key=idempotency_key,
+                key_id=_api_key_id(auth),
+            )
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        except IdempotencyConflictError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_409_CONFLICT,
+                detail=str(exc),
+            ) from exc
+        except RateLimitExceededError as exc:
+            raise _rate_limit_exception(exc) from exc
+        _apply_rate_headers(response, snapshot)
+        response.headers["X-Idempotency-Replayed"] = "true" if replayed else "false"
+        log.info(
+            "procedure_ingest",
+            account=auth.subject,
+            memory_id=result.memory_id,
+            steps=len(payload.steps),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get("/v1/procedures/search", response_model=ProcedureSearchResponse)
+    @limit(config.per_minute_limit)
+    def procedure_search_endpoint(
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+        task: Annotated[str, Query(min_length=1, max_length=config.max_query_chars)],
+        limit_count: Annotated[int, Query(alias="limit", ge=1, le=50)] = 5,
+        entity_id: str | None = None,
+    ) -> ProcedureSearchResponse:
+        snapshot = _consume_or_raise(
+            service.consume_query_quota,
+            account_key=auth.subject,
+            amount=1,
+        )
+        result = service.search_procedures(
+            task,
+            limit=limit_count,
+            entity_id=entity_id,
+            account_key=auth.subject,
+        )
+        _apply_rate_headers(response, snapshot)
+        log.info(
+            "procedure_search",
+            account=auth.subject,
+            returned=len(result.data),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post(
+        "/v1/reflect",
+        response_model=ReflectResponse,
+        status_code=status.HTTP_201_CREATED,
+    )
+    @limit(config.per_minute_limit)
+    def reflect_endpoint(
+        payload: ReflectRequest,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_write_scope)],
+        idempotency_key: Annotated[str | None, Header(alias="Idempotency-Key")] = None,
+    ) -> ReflectResponse:
+        try:
+            result, snapshot, replayed = service.reflect_with_quota(
+                account_key=auth.subject,
+                request=payload,
+                idempotency_key=idempotency_key,
+            )
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        except IdempotencyConflictError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_409_CONFLICT,
+                detail=str(exc),
+            ) from exc
+        except RateLimitExceededError as exc:
+            raise _rate_limit_exception(exc) from exc
+        _apply_rate_headers(response, snapshot)
+        response.headers["X-Idempotency-Replayed"] = "true" if replayed else "false"
+        log.info(
+            "reflect",
+            account=auth.subject,
+            trajectory_id=result.trajectory_id,
+            lessons=len(result.lessons),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post(
+        "/v1/tokens/browser",
+        response_model=BrowserTokenResponse,
+        status_code=status.HTTP_201_CREATED,
+    )
+    @limit(config.per_minute_limit)
+    def browser_token_endpoint(
+        payload: BrowserTokenRequest,
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_token_mint_scope)],
+    ) -> BrowserTokenResponse:
+        if _is_browser_token(auth):
+            raise HTTPException(
+                status_code=status.HTTP_403_FORBIDDEN,
+                detail="Browser tokens cannot mint other tokens.",
+            )
+        try:
+            result = service.issue_browser_token(auth=auth, request=payload)
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "browser_token_issued",
+            account=auth.subject,
+            entity_id=result.entity_id,
+            scopes=result.scopes,
+            expires_at=result.expires_at.isoformat(),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post("/v1/feedback", response_model=FeedbackResponse)
+    @limit(config.per_minute_limit)
+    def feedback_endpoint(
+        payload: FeedbackRequest,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_feedback_scope)],
+        idempotency_key: Annotated[str | None, Header(alias="Idempotency-Key")] = None,
+    ) -> FeedbackResponse:
+        try:
+            result, snapshot, replayed = service.feedback_with_quota(
+                account_key=auth.subject,
+                request=payload,
+                idempotency_key=idempotency_key,
+            )
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        except IdempotencyConflictError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_409_CONFLICT,
+                detail=str(exc),
+            ) from exc
+        except RateLimitExceededError as exc:
+            raise _rate_limit_exception(exc) from exc
+        _apply_rate_headers(response, snapshot)
+        response.headers["X-Idempotency-Replayed"] = "true" if replayed else "false"
+        log.info(
+            "feedback",
+            account=auth.subject,
+            memory_id=result.memory_id,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post("/v1/ingest/batch", response_model=IngestBatchResponse)
+    @limit(config.per_minute_limit)
+    def ingest_batch_endpoint(
+        payload: IngestBatchRequest,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_write_scope)],
+        idempotency_key: Annotated[str | None, Header(alias="Idempotency-Key")] = None,
+    ) -> IngestBatchResponse:
+        if not payload.events:
+            raise FieldValidationError(
+                [FieldError("events", "min_items", "events batch cannot be empty", limit=1)]
+            )
+        if len(payload.events) > config.max_batch_items:
+            raise FieldValidationError(
+                [
+                    FieldError(
+                        "events",
+                        "max_items",
+                        f"events batch exceeds ORBIT_MAX_BATCH_ITEMS={config.max_batch_items}",
+                        limit=config.max_batch_items,
+                    )
+                ]
+            )
+        field_errors = [
+            error
+            for index, item in enumerate(payload.events)
+            for error in _ingest_field_errors(
+                config,
+                item.content,
+                item.event_type,
+                prefix=f"events.{index}.",
+                on_oversize=item.on_oversize or config.on_oversize,
+            )
+        ]
+        if field_errors:
+            raise FieldValidationError(field_errors)
+        try:
+            items, snapshot, replayed = service.ingest_batch_with_quota(
+                account_key=auth.subject,
+                events=payload.events,
+                idempotency_key=idempotency_key,
+                key_id=_api_key_id(auth),
+            )
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        except IdempotencyConflictError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_409_CONFLICT,
+                detail=str(exc),
+            ) from exc
+        except RateLimitExceededError as exc:
+            raise _rate_limit_exception(exc) from exc
+        _apply_rate_headers(response, snapshot)
+        response.headers["X-Idempotency-Replayed"] = "true" if replayed else "false"
+        log.info(
+            "ingest_batch",
+            account=auth.subject,
+            count=len(items),
+            path=str(request.url.path),
+        )
+        return IngestBatchResponse(items=items)
+
+    # Backfills skip the per-minute limit: they load history in bulk, and the event quota
+    # still applies.
+    @app.post("/v1/import", response_model=ImportResponse)
+    @limit(config.per_minute_limit, exempt_when=_is_backfill_import)
+    def import_endpoint(
+        payload: ImportRequest,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_import_scope)],
+        mode: Annotated[str, Query()] = "standard",
+        idempotency_key: Annotated[str | None, Header(alias="Idempotency-Key")] = None,
+    ) -> ImportResponse:
+        field_errors: list[FieldError] = []
+        if mode not in IMPORT_MODES:
+            field_errors.append(
+                FieldError(
+                    "mode",
+                    "enum",
+                    f"mode must be one of: {', '.join(IMPORT_MODES)}",
+                    location="query",
+                    limit=list(IMPORT_MODES),
+                )
+            )
+        if len(payload.events) > config.max_import_items:
+            field_errors.append(
+                FieldError(
+                    "events",
+                    "max_items",
+                    f"import exceeds ORBIT_MAX_IMPORT_ITEMS={config.max_import_items}",
+                    limit=config.max_import_items,
+                )
+            )
+        field_errors.extend(
+            error
+            for index, item in enumerate(payload.events)
+            for error in _ingest_field_errors(
+                config,
+                item.content,
+                item.event_type,
+                prefix=f"events.{index}.",
+                on_oversize=item.on_oversize or config.on_oversize,
+            )
+        )
+        if field_errors:
+            raise FieldValidationError(field_errors)
+        try:
+            result, snapshot, replayed = service.import_with_quota(
+                account_key=auth.subject,
+                events=payload.events,
+                mode=mode,
+                idempotency_key=idempotency_key,
+                key_id=_api_key_id(auth),
+            )
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        except IdempotencyConflictError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_409_CONFLICT,
+                detail=str(exc),
+            ) from exc
+        except RateLimitExceededError as exc:
+            raise _rate_limit_exception(exc) from exc
+        _apply_rate_headers(response, snapshot)
+        response.headers["X-Idempotency-Replayed"] = "true" if replayed else "false"
+        log.info(
+            "import",
+            account=auth.subject,
+            mode=mode,
+            count=len(result.items),
+            imported=result.imported,
+            events_per_second=result.events_per_second,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post("/v1/feedback/batch", response_model=FeedbackBatchResponse)
+    @limit(config.per_minute_limit)
+    def feedback_batch_endpoint(
+        payload: FeedbackBatchRequest,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_feedback_scope)],
+        idempotency_key: Annotated[str | None, Header(alias="Idempotency-Key")] = None,
+    ) -> FeedbackBatchResponse:
+        if not payload.feedback:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail="feedback batch cannot be empty",
+            )
+        if len(payload.feedback) > config.max_batch_items:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=(
+                    f"feedback batch exceeds ORBIT_MAX_BATCH_ITEMS="
+                    f"{config.max_batch_items}"
+                ),
+            )
+        try:
+            items, snapshot, replayed = service.feedback_batch_with_quota(
+                account_key=auth.subject,
+                feedback=payload.feedback,
+                idempotency_key=idempotency_key,
+            )
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        except IdempotencyConflictError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_409_CONFLICT,
+                detail=str(exc),
+            ) from exc
+        except RateLimitExceededError as exc:
+            raise _rate_limit_exception(exc) from exc
+        _apply_rate_headers(response, snapshot)
+        response.headers["X-Idempotency-Replayed"] = "true" if replayed else "false"
+        log.info(
+            "feedback_batch",
+            account=auth.subject,
+            count=len(items),
+            path=str(request.url.path),
+        )
+        return FeedbackBatchResponse(items=items)
+
+    @app.get("/v1/status", response_model=StatusResponse)
+    def status_endpoint(
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+    ) -> StatusResponse:
+        return service.status(auth.subject)
+
+    @app.get(
+        "/v1/dashboard/memory-quality",
+        response_model=MemoryQualityResponse,
+    )
+    def dashboard_memory_quality_endpoint(
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_keys_read_scope)],
+    ) -> MemoryQualityResponse:
+        return service.memory_quality(auth.subject)
+
+    @app.get("/v1/health")
+    def health_endpoint(
+        service: Annotated[OrbitApiService, Depends(get_service)],
+    ) -> dict[str, str]:
+        return service.health()
+
+    @app.get("/v1/metrics", response_class=PlainTextResponse)
+    def metrics_endpoint(
+        service: Annotated[OrbitApiService, Depends(get_service)],
+    ) -> str:
+        return service.metrics_text()
+
+    @app.get("/v1/tenant-metrics", response_model=TenantMetricsResponse)
+    def tenant_metrics_endpoint(
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+    ) -> TenantMetricsResponse:
+        return service.tenant_metrics(auth.subject)
+
+    @app.post("/v1/auth/validate", response_model=AuthValidationResponse)
+    def validate_endpoint(
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(get_auth_context)],
+    ) -> AuthValidationResponse:
+        return service.validate_token(auth)
+
+    @app.post(
+        "/v1/dashboard/pilot-pro/request",
+        response_model=PilotProRequestResponse,
+    )
+    @limit(config.dashboard_key_per_minute_limit)
+    def request_pilot_pro_endpoint(
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_keys_write_scope)],
+    ) -> PilotProRequestResponse:
+        response.headers["Cache-Control"] = "no-store"
+        result = service.request_pilot_pro(
+            account_key=auth.subject,
+            actor_subject=_actor_subject(auth),
+            actor_email=_actor_email(auth),
+            actor_name=_actor_name(auth),
+        )
+        log.info(
+            "request_pilot_pro",
+            account=auth.subject,
+            created=result.created,
+            email_sent=result.email_sent,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post(
+        "/v1/dashboard/keys",
+        response_model=ApiKeyIssueResponse,
+        status_code=status.HTTP_201_CREATED,
+    )
+    @limit(config.dashboard_key_per_minute_limit)
+    def issue_api_key_endpoint(
+        payload: ApiKeyCreateRequest,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_keys_write_scope)],
+    ) -> ApiKeyIssueResponse:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            result = service.issue_api_key(
+                account_key=auth.subject,
+                name=payload.name,
+                scopes=payload.scopes,
+                allowed_origins=payload.allowed_origins,
+                actor_subject=_actor_subject(auth),
+            )
+        except PlanQuotaExceededError as exc:
+            raise _plan_quota_exception(exc) from exc
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "issue_api_key",
+            account=auth.subject,
+            key_id=result.key_id,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get("/v1/dashboard/keys", response_model=ApiKeyListResponse)
+    @limit(config.dashboard_key_per_minute_limit)
+    def list_api_keys_endpoint(
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_keys_read_scope)],
+        limit_count: Annotated[int, Query(alias="limit", ge=1, le=100)] = 50,
+        cursor: str | None = None,
+    ) -> ApiKeyListResponse:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            result = service.list_api_keys(
+                account_key=auth.subject,
+                limit=limit_count,
+                cursor=cursor,
+            )
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "list_api_keys",
+            account=auth.subject,
+            count=len(result.data),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post("/v1/dashboard/keys/{key_id}/revoke", response_model=ApiKeyRevokeResponse)
+    @limit(config.dashboard_key_per_minute_limit)
+    def revoke_api_key_endpoint(
+        key_id: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_keys_write_scope)],
+    ) -> ApiKeyRevokeResponse:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            result = service.revoke_api_key(
+                account_key=auth.subject,
+                key_id=key_id,
+                actor_subject=_actor_subject(auth),
+            )
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "revoke_api_key",
+            account=auth.subject,
+            key_id=result.key_id,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post(
+        "/v1/dashboard/keys/{key_id}/rotate",
+        response_model=ApiKeyRotateResponse,
+        status_code=status.HTTP_201_CREATED,
+    )
+    @limit(config.dashboard_key_per_minute_limit)
+    def rotate_api_key_endpoint(
+        key_id: str,
+        payload: ApiKeyRotateRequest,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_keys_write_scope)],
+    ) -> ApiKeyRotateResponse:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            result = service.rotate_api_key(
+                account_key=auth.subject,
+                key_id=key_id,
+                name=payload.name,
+                scopes=payload.scopes,
+                allowed_origins=payload.allowed_origins,
+                actor_subject=_actor_subject(auth),
+            )
+        except KeyError as exc:
+            service.record_dashboard_key_rotation_failure()
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+        except ValueError as exc:
+            service.record_dashboard_key_rotation_failure()
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        except Exception:
+            service.record_dashboard_key_rotation_failure()
+            raise
+        log.info(
+            "rotate_api_key",
+            account=auth.subject,
+            revoked_key_id=result.revoked_key_id,
+            new_key_id=result.new_key.key_id,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get("/v1/memories", response_model=PaginatedMemoriesResponse)
+    @limit(config.per_minute_limit)
+    def list_memories_endpoint(
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+        limit_count: Annotated[int, Query(alias="limit", ge=1, le=100)] = 100,
+        cursor: str | None = None,
+        fields: Annotated[str | None, Query(pattern="^embedding$")] = None,
+        agent_id: str | None = None,
+    ) -> PaginatedMemoriesResponse:
+        include_embeddings = _include_embeddings(auth, fields)
+        snapshot = _consume_or_raise(
+            service.consume_query_quota,
+            account_key=auth.subject,
+            amount=1,
+        )
+        result = service.list_memories(
+            limit=limit_count,
+            cursor=cursor,
+            account_key=auth.subject,
+            max_sensitivity=_sensitivity_clearance(auth),
+            agent_id=_acting_agent(auth, agent_id),
+        )
+        if include_embeddings:
+            service.attach_embeddings(result.data, account_key=auth.subject)
+        _apply_rate_headers(response, snapshot)
+        log.info(
+            "list_memories",
+            account=auth.subject,
+            count=len(result.data),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get("/v1/review", response_model=MemoryReviewListResponse)
+    @limit(config.per_minute_limit)
+    def memory_reviews_endpoint(
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_review_scope)],
+        review_status: Annotated[
+            str | None,
+            Query(alias="status", pattern="^(pending|approved|rejected)$"),
+        ] = None,
+        limit_count: Annotated[int, Query(alias="limit", ge=1, le=200)] = 50,
+    ) -> MemoryReviewListResponse:
+        result = service.memory_reviews(
+            account_key=auth.subject,
+            status=review_status,
+            limit=limit_count,
+        )
+        log.info(
+            "memory_reviews",
+            account=auth.subject,
+            count=len(result.data),
+            path=str(request.url.path),
+        )
+        return result
+
+    def _resolve_memory_review(
+        service: OrbitApiService,
+        auth: AuthContext,
+        review_id: str,
+        decision: str,
+        payload: MemoryReviewDecision | None,
+        path: str,
+    ) -> MemoryReview:
+        try:
+            result = service.resolve_memory_review(
+                review_id,
+                decision,
+                payload or MemoryReviewDecision(),
+                account_key=auth.subject,
+                resolved_by=_actor_subject(auth),
+            )
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_409_CONFLICT,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "resolve_memory_review",
+            account=auth.subject,
+            review_id=review_id,
+            decision=decision,
+            path=path,
+        )
+        return result
+
+    @app.post("/v1/review/{review_id}/approve", response_model=MemoryReview)
+    @limit(config.per_minute_limit)
+    def approve_memory_review_endpoint(
+        review_id: str,
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_review_scope)],
+        payload: MemoryReviewDecision | None = None,
+    ) -> MemoryReview:
+        return _resolve_memory_review(
+            service, auth, review_id, "approve", payload, str(request.url.path)
+        )
+
+    @app.post("/v1/review/{review_id}/reject", response_model=MemoryReview)
+    @limit(config.per_minute_limit)
+    def reject_memory_review_endpoint(
+        review_id: str,
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_review_scope)],
+        payload: MemoryReviewDecision | None = None,
+    ) -> MemoryReview:
+        return _resolve_memory_review(
+            service, auth, review_id, "reject", payload, str(request.url.path)
+        )
+
+    @app.get("/v1/moderation/reviews", response_model=ModerationReviewListResponse)
+    @limit(config.per_minute_limit)
+    def moderation_reviews_endpoint(
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+        review_status: Annotated[
+            str | None,
+            Query(alias="status", pattern="^(pending|appealed|approved|rejected)$"),
+        ] = None,
+        limit_count: Annotated[int, Query(alias="limit", ge=1, le=200)] = 50,
+    ) -> ModerationReviewListResponse:
+        result = service.moderation_reviews(
+            account_key=auth.subject,
+            status=review_status,
+            limit=limit_count,
+        )
+        log.info(
+            "moderation_reviews",
+            account=auth.subject,
+            count=len(result.data),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post(
+        "/v1/moderation/reviews/{review_id}/appeal",
+        response_model=ModerationReview,
+    )
+    @limit(config.per_minute_limit)
+    def moderation_appeal_endpoint(
+        review_id: int,
+        payload: ModerationAppealRequest,
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_write_scope)],
+    ) -> ModerationReview:
+        try:
+            result = service.appeal_moderation_review(
+                review_id,
+                payload,
+                account_key=auth.subject,
+            )
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_409_CONFLICT,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "moderation_appeal",
+            account=auth.subject,
+            review_id=review_id,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get("/v1/pipeline/webhook", response_model=PipelineWebhook)
+    @limit(config.per_minute_limit)
+    def pipeline_webhook_endpoint(
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+    ) -> PipelineWebhook:
+        try:
+            return service.pipeline_webhook(auth.subject)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+
+    @app.put("/v1/pipeline/webhook", response_model=PipelineWebhook)
+    @limit(config.per_minute_limit)
+    def set_pipeline_webhook_endpoint(
+        payload: PipelineWebhookRequest,
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_write_scope)],
+    ) -> PipelineWebhook:
+        try:
+            result = service.set_pipeline_webhook(auth.subject, payload)
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "set_pipeline_webhook",
+            account=auth.subject,
+            failure_policy=result.failure_policy,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.delete("/v1/pipeline/webhook", response_model=PipelineWebhook)
+    @limit(config.per_minute_limit)
+    def delete_pipeline_webhook_endpoint(
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_write_scope)],
+    ) -> PipelineWebhook:
+        try:
+            result = service.delete_pipeline_webhook(auth.subject)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+        log.info("delete_pipeline_webhook", account=auth.subject, path=str(request.url.path))
+        return result
+
+    @app.get("/v1/changes", response_model=ChangeFeedResponse)
+    @limit(config.per_minute_limit)
+    def list_changes_endpoint(
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+        limit_count: Annotated[int, Query(alias="limit", ge=1, le=1000)] = 100,
+        cursor: str | None = None,
+        agent_id: str | None = None,
+    ) -> ChangeFeedResponse:
+        snapshot = _consume_or_raise(
+            service.consume_query_quota,
+            account_key=auth.subject,
+            amount=1,
+        )
+        result = service.list_changes(
+            account_key=auth.subject,
+            cursor=cursor,
+            limit=limit_count,
+            max_sensitivity=_sensitivity_clearance(auth),
+            agent_id=_acting_agent(auth, agent_id),
+        )
+        _apply_rate_headers(response, snapshot)
+        log.info(
+            "list_changes",
+            account=auth.subject,
+            count=len(result.data),
+            cursor=result.cursor,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get("/v1/memories/{memory_id}/attachment")
+    @limit(config.per_minute_limit)
+    def memory_attachment_endpoint(
+        memory_id: str,
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+        agent_id: str | None = None,
+    ) -> Response:
+        snapshot = _consume_or_raise(
+            service.consume_query_quota,
+            account_key=auth.subject,
+            amount=1,
+        )
+        try:
+            data, content_type, filename = service.memory_attachment(
+                memory_id,
+                account_key=auth.subject,
+                max_sensitivity=_sensitivity_clearance(auth),
+                agent_id=_acting_agent(auth, agent_id),
+            )
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail="Attachment not found.",
+            ) from exc
+        response = Response(content=data, media_type=content_type)
+        if filename:
+            response.headers["Content-Disposition"] = (
+                f'attachment; filename="{filename.replace(chr(34), "")}"'
+            )
+        _apply_rate_headers(response, snapshot)
+        log.info(
+            "memory_attachment",
+            account=auth.subject,
+            memory_id=memory_id,
+            size_bytes=len(data),
+            path=str(request.url.path),
+        )
+        return response
+
+    @app.patch("/v1/memories/{memory_id}", response_model=Memory)
+    @limit(config.per_minute_limit)
+    def memory_update_endpoint(
+        memory_id: str,
+        payload: MemoryUpdateRequest,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_write_scope)],
+        if_match: Annotated[str | None, Header(alias="If-Match")] = None,
+    ) -> Memory:
+        _check_ingest_fields(config, payload.content, None)
+        try:
+            result = service.update_memory(
+                memory_id,
+                payload,
+                account_key=auth.subject,
+                expected_version=_if_match_version(if_match),
+            )
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail="Memory not found.",
+            ) from exc
+        except PreconditionFailedError as exc:
+            raise _precondition_failed_exception(exc) from exc
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        response.headers["ETag"] = _etag(result.version or 1)
+        log.info(
+            "memory_update",
+            account=auth.subject,
+            memory_id=memory_id,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get(
+        "/v1/memories/{memory_id}/versions",
+        response_model=MemoryVersionListResponse,
+    )
+    @limit(config.per_minute_limit)
+    def memory_versions_endpoint(
+        memory_id: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+        agent_id: str | None = None,
+    ) -> MemoryVersionListResponse:
+        try:
+            result = service.memory_versions(
+                memory_id,
+                account_key=auth.subject,
+                max_sensitivity=_sensitivity_clearance(auth),
+                agent_id=_acting_agent(auth, agent_id),
+            )
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail="Memory not found.",
+            ) from exc
+        response.headers["ETag"] = _etag(result.current_version)
+        log.info(
+            "memory_versions",
+            account=auth.subject,
+            memory_id=memory_id,
+            count=len(result.versions),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get("/v1/memories/{memory_id}/diff", response_model=MemoryDiffResponse)
+    @limit(config.per_minute_limit)
+    def memory_diff_endpoint(
+        memory_id: str,
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+        from_version: Annotated[int | None, Query(ge=1)] = None,
+        to_version: Annotated[int | None, Query(ge=1)] = None,
+        agent_id: str | None = None,
+    ) -> MemoryDiffResponse:
+        try:
+            result = service.memory_diff(
+                memory_id,
+                from_version=from_version,
+                to_version=to_version,
+                account_key=auth.subject,
+                max_sensitivity=_sensitivity_clearance(auth),
+                agent_id=_acting_agent(auth, agent_id),
+            )
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc.args[0]) if exc.args else "Memory not found.",
+            ) from exc
+        log.info(
+            "memory_diff",
+            account=auth.subject,
+            memory_id=memory_id,
+            from_version=result.from_version,
+            to_version=result.to_version,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get("/v1/memories/{memory_id}/similar", response_model=SimilarMemoriesResponse)
+    @limit(config.per_minute_limit)
+    def similar_memories_endpoint(
+        memory_id: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+        limit_count: Annotated[int, Query(alias="limit", ge=1, le=100)] = 10,
+        min_score: Annotated[float | None, Query(ge=0.0, le=1.0)] = None,
+        fields: Annotated[str | None, Query(pattern="^embedding$")] = None,
+        agent_id: str | None = None,
+    ) -> SimilarMemoriesResponse:
+        include_embeddings = _include_embeddings(auth, fields)
+        snapshot = _consume_or_raise(
+            service.consume_query_quota,
+            account_key=auth.subject,
+            amount=1,
+        )
+        try:
+            result = service.similar_memories(
+                memory_id,
+                limit=limit_count,
+                min_score=min_score,
+                account_key=auth.subject,
+                max_sensitivity=_sensitivity_clearance(auth),
+                agent_id=_acting_agent(auth, agent_id),
+            )
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc.args[0]) if exc.args else "Memory not found.",
+            ) from exc
+        if include_embeddings:
+            service.attach_embeddings(result.memories, account_key=auth.subject)
+        _apply_rate_headers(response, snapshot)
+        log.info(
+            "similar_memories",
+            account=auth.subject,
+            memory_id=memory_id,
+            returned=len(result.memories),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post(
+        "/v1/memories/{memory_id}/links",
+        response_model=MemoryLink,
+        status_code=status.HTTP_201_CREATED,
+    )
+    @limit(config.per_minute_limit)
+    def link_memories_endpoint(
+        memory_id: str,
+        payload: MemoryLinkRequest,
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_write_scope)],
+    ) -> MemoryLink:
+        try:
+            result = service.link_memories(memory_id, payload, account_key=auth.subject)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc.args[0]) if exc.args else "Memory not found.",
+            ) from exc
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "link_memories",
+            account=auth.subject,
+            link_id=result.link_id,
+            link_type=result.link_type,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get("/v1/memories/{memory_id}/links", response_model=MemoryLinkListResponse)
+    @limit(config.per_minute_limit)
+    def memory_links_endpoint(
+        memory_id: str,
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_read_scope)],
+    ) -> MemoryLinkListResponse:
+        try:
+            result = service.memory_links(memory_id, account_key=auth.subject)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc.args[0]) if exc.args else "Memory not found.",
+            ) from exc
+        log.info(
+            "memory_links",
+            account=auth.subject,
+            memory_id=memory_id,
+            count=len(result.data),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.delete("/v1/memories/{memory_id}/links/{link_id}", response_model=MemoryLink)
+    @limit(config.per_minute_limit)
+    def delete_memory_link_endpoint(
+        memory_id: str,
+        link_id: int,
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_write_scope)],
+    ) -> MemoryLink:
+        try:
+            result = service.delete_memory_link(memory_id, link_id, account_key=auth.subject)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc.args[0]) if exc.args else "Memory link not found.",
+            ) from exc
+        log.info(
+            "delete_memory_link",
+            account=auth.subject,
+            link_id=link_id,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post("/v1/memories/{memory_id}/revert", response_model=Memory)
+    @limit(config.per_minute_limit)
+    def memory_revert_endpoint(
+        memory_id: str,
+        payload: MemoryRevertRequest,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_write_scope)],
+        if_match: Annotated[str | None, Header(alias="If-Match")] = None,
+    ) -> Memory:
+        try:
+            result = service.revert_memory(
+                memory_id,
+                payload.version,
+                account_key=auth.subject,
+                expected_version=_if_match_version(if_match),
+            )
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc.args[0]) if exc.args else "Memory not found.",
+            ) from exc
+        except PreconditionFailedError as exc:
+            raise _precondition_failed_exception(exc) from exc
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        response.headers["ETag"] = _etag(result.version or 1)
+        log.info(
+            "memory_revert",
+            account=auth.subject,
+            memory_id=memory_id,
+            version=payload.version,
+            path=str(request.url.path),
+        )
+        return result
+
+    slack = SlackIntegration.from_config(app.state.orbit_service, config)
+
+    async def verified_slack_request(request: Request) -> tuple[SlackIntegration, bytes]:
+        if slack is None or config.slack_signing_secret is None:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail="Slack integration is not configured.",
+            )
+        body = await request.body()
+        if not verify_signature(
+            config.slack_signing_secret,
+            timestamp=request.headers.get("X-Slack-Request-Timestamp"),
+            body=body,
+            signature=request.headers.get("X-Slack-Signature"),
+        ):
+            raise HTTPException(
+                status_code=status.HTTP_401_UNAUTHORIZED,
+                detail="Invalid Slack signature.",
+            )
+        return slack, body
+
+    @app.post("/v1/integrations/slack/events")
+    @limit(config.per_minute_limit)
+    async def slack_events_endpoint(request: Request) -> dict[str, Any]:
+        integration, body = await verified_slack_request(request)
+        try:
+            payload = json.loads(body)
+        except json.JSONDecodeError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_400_BAD_REQUEST,
+                detail="Slack payload is not valid JSON.",
+            ) from exc
+        try:
+            result = await run_in_threadpool(integration.handle_event, payload)
+        except RateLimitExceededError as exc:
+            raise _rate_limit_exception(exc) from exc
+        log.info(
+            "slack_event",
+            account=integration.account_key,
+            event_id=payload.get("event_id"),
+            ingested=result.get("ingested", False),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post("/v1/integrations/slack/commands")
+    @limit(config.per_minute_limit)
+    async def slack_commands_endpoint(request: Request) -> dict[str, Any]:
+        integration, body = await verified_slack_request(request)
+        form = dict(parse_qsl(body.decode("utf-8")))
+        result = await run_in_threadpool(integration.handle_command, form)
+        log.info(
+            "slack_command",
+            account=integration.account_key,
+            command=form.get("command"),
+            path=str(request.url.path),
+        )
+        return result
+
+    email_ingestor = (
+        EmailIngestor(
+            app.state.orbit_service,
+            account_key=config.email_account_key,
+            max_content_chars=config.max_ingest_content_chars,
+        )
+        if config.email_webhook_token and config.email_account_key
+        else None
+    )
+
+    @app.post("/v1/integrations/email/inbound")
+    @limit(config.per_minute_limit)
+    async def email_inbound_endpoint(
+        request: Request,
+        token: str | None = None,
+        token_header: Annotated[str | None, Header(alias="X-Orbit-Webhook-Token")] = None,
+    ) -> dict[str, Any]:
+        if email_ingestor is None or config.email_webhook_token is None:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail="Email integration is not configured.",
+            )
+        supplied = token_header or token or ""
+        if not hmac.compare_digest(supplied, config.email_webhook_token):
+            raise HTTPException(
+                status_code=status.HTTP_401_UNAUTHORIZED,
+                detail="Invalid webhook token.",
+            )
+        body = await request.body()
+        content_type = request.headers.get("content-type", "")
+        raw = extract_sendgrid_email(body, content_type)
+        if raw is None:
+            try:
+                payload = json.loads(body)
+            except json.JSONDecodeError as exc:
+                raise HTTPException(
+                    status_code=status.HTTP_400_BAD_REQUEST,
+                    detail="Unsupported inbound email payload.",
+                ) from exc
+            if (
+                isinstance(payload, dict)
+                and payload.get("Type") == "SubscriptionConfirmation"
+            ):
+                subscribe_url = str(payload.get("SubscribeURL", ""))
+                if not is_sns_subscribe_url(subscribe_url):
+                    raise HTTPException(
+                        status_code=status.HTTP_400_BAD_REQUEST,
+                        detail="Invalid SNS SubscribeURL.",
+                    )
+                async with httpx.AsyncClient(timeout=10.0) as client:
+                    await client.get(subscribe_url)
+                log.info("email_sns_subscription_confirmed", path=str(request.url.path))
+                return {"ok": True, "ingested": False}
+            raw = extract_ses_email(payload) if isinstance(payload, dict) else None
+        if raw is None:
+            raise HTTPException(
+                status_code=status.HTTP_400_BAD_REQUEST,
+                detail="Unsupported inbound email payload.",
+            )
+        try:
+            result = await run_in_threadpool(email_ingestor.ingest_raw, raw)
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        except RateLimitExceededError as exc:
+            raise _rate_limit_exception(exc) from exc
+        log.info(
+            "email_inbound",
+            account=email_ingestor.account_key,
+            memory_id=result.memory_id if result is not None else None,
+            path=str(request.url.path),
+        )
+        return {
+            "ok": True,
+            "ingested": result is not None and result.stored,
+            "memory_id": result.memory_id if result is not None else None,
+        }
+
+    chat_proxy = (
+        ChatMemoryProxy(
+            app.state.orbit_service,
+            upstream_base_url=config.chat_proxy_upstream_url,
+            upstream_api_key=config.chat_proxy_upstream_api_key,
+            memory_limit=config.chat_proxy_memory_limit,
+        )
+        if config.chat_proxy_upstream_url
+        else None
+    )
+
+    @app.post("/v1/chat/completions", response_model=None)
+    @limit(config.per_minute_limit)
+    async def chat_completions_endpoint(
+        request: Request,
+        auth: Annotated[AuthContext, Depends(require_write_scope)],
+        entity_header: Annotated[str | None, Header(alias="X-Orbit-Entity-Id")] = None,
+    ) -> Response:
+        if chat_proxy is None:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail="Chat proxy is not configured.",
+            )
+        try:
+            body = json.loads(await request.body())
+        except json.JSONDecodeError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_400_BAD_REQUEST,
+                detail="Request body must be JSON.",
+            ) from exc
+        if not isinstance(body, dict):
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail="Request body must be a JSON object.",
+            )
+        user_field = body.get("user")
+        entity_id = (
+            entity_header
+            or (user_field if isinstance(user_field, str) and user_field.strip() else None)
+            or config.default_entity_id
+        ).strip()
+        try:
+            augmented, user_message = await run_in_threadpool(
+                chat_proxy.augment,
+                body,
+                account_key=auth.subject,
+                entity_id=entity_id,
+                max_sensitivity=_sensitivity_clearance(auth),
+            )
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        except RateLimitExceededError as exc:
+            raise _rate_limit_exception(exc) from exc
+        log.info(
+            "chat_completions",
+            account=auth.subject,
+            entity_id=entity_id,
+            stream=bool(body.get("stream")),
+            path=str(request.url.path),
+        )
+        if body.get("stream"):
+            return StreamingResponse(
+                chat_proxy.stream(
+                    augmented,
+                    user_message=user_message,
+                    account_key=auth.subject,
+                    entity_id=entity_id,
+                ),
+                media_type="text/event-stream",
+            )
+        try:
+            payload = await chat_proxy.complete(
+                augmented,
+                user_message=user_message,
+                account_key=auth.subject,
+                entity_id=entity_id,
+            )
+        except UpstreamError as exc:
+            return JSONResponse(status_code=exc.status_code, content=exc.body)
+        return JSONResponse(content=payload)
+
+    @app.get("/admin", response_class=HTMLResponse, include_in_schema=False)
+    def admin_dashboard_page() -> HTMLResponse:
+        if not config.admin_dashboard_enabled:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail="Admin dashboard is disabled.",
+            )
+        return HTMLResponse(
+            _ADMIN_DASHBOARD_HTML.read_text(encoding="utf-8"),
+            headers={"Cache-Control": "no-store"},
+        )
+
+    @app.get("/v1/admin/tenants", response_model=AdminTenantListResponse)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_tenants_endpoint(
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> AdminTenantListResponse:
+        response.headers["Cache-Control"] = "no-store"
+        result = service.admin_tenants()
+        log.info(
+            "admin_tenants",
+            actor=_actor_subject(auth),
+            count=len(result.data),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get(
+        "/v1/admin/tenants/{account_key}/entities",
+        response_model=AdminEntityListResponse,
+    )
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_entities_endpoint(
+        account_key: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> AdminEntityListResponse:
+        response.headers["Cache-Control"] = "no-store"
+        result = service.admin_entities(account_key)
+        log.info(
+            "admin_entities",
+            actor=_actor_subject(auth),
+            account=result.account_key,
+            count=len(result.data),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get(
+        "/v1/admin/tenants/{account_key}/memories",
+        response_model=PaginatedMemoriesResponse,
+    )
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_memories_endpoint(
+        account_key: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+        limit_count: Annotated[int, Query(alias="limit", ge=1, le=100)] = 50,
+        cursor: str | None = None,
+        entity_id: str | None = None,
+    ) -> PaginatedMemoriesResponse:
+        response.headers["Cache-Control"] = "no-store"
+        result = service.list_memories(
+            limit=limit_count,
+            cursor=cursor,
+            account_key=account_key,
+            entity_id=entity_id,
+            all_agents=True,
+        )
+        log.info(
+            "admin_memories",
+            actor=_actor_subject(auth),
+            account=account_key,
+            count=len(result.data),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get(
+        "/v1/admin/tenants/{account_key}/retrieve",
+        response_model=RetrieveResponse,
+    )
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_retrieve_endpoint(
+        account_key: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+        query: Annotated[str, Query(min_length=1, max_length=config.max_query_chars)],
+        limit_count: Annotated[int, Query(alias="limit", ge=1, le=100)] = 10,
+        entity_id: str | None = None,
+    ) -> RetrieveResponse:
+        # Operator test queries do not count against the tenant's query quota.
+        response.headers["Cache-Control"] = "no-store"
+        result = service.retrieve(
+            RetrieveRequest(query=query, limit=limit_count, entity_id=entity_id),
+            account_key=account_key,
+        )
+        log.info(
+            "admin_retrieve",
+            actor=_actor_subject(auth),
+            account=account_key,
+            returned=len(result.memories),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get("/v1/admin/tenants/{account_key}/keys", response_model=ApiKeyListResponse)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_list_api_keys_endpoint(
+        account_key: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+        limit_count: Annotated[int, Query(alias="limit", ge=1, le=100)] = 50,
+        cursor: str | None = None,
+    ) -> ApiKeyListResponse:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            result = service.list_api_keys(
+                account_key=account_key,
+                limit=limit_count,
+                cursor=cursor,
+            )
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "admin_list_api_keys",
+            actor=_actor_subject(auth),
+            account=account_key,
+            count=len(result.data),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post(
+        "/v1/admin/tenants/{account_key}/keys",
+        response_model=ApiKeyIssueResponse,
+        status_code=status.HTTP_201_CREATED,
+    )
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_issue_api_key_endpoint(
+        account_key: str,
+        payload: ApiKeyCreateRequest,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> ApiKeyIssueResponse:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            result = service.issue_api_key(
+                account_key=account_key,
+                name=payload.name,
+                scopes=payload.scopes,
+                allowed_origins=payload.allowed_origins,
+                actor_subject=_actor_subject(auth),
+                actor_type="admin",
+            )
+        except PlanQuotaExceededError as exc:
+            raise _plan_quota_exception(exc) from exc
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "admin_issue_api_key",
+            actor=_actor_subject(auth),
+            account=account_key,
+            key_id=result.key_id,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get(
+        "/v1/admin/tenants/{account_key}/keys/{key_id}",
+        response_model=ApiKeySummary,
+    )
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_api_key_endpoint(
+        account_key: str,
+        key_id: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> ApiKeySummary:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            return service.api_key(account_key=account_key, key_id=key_id)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+
+    @app.post(
+        "/v1/admin/tenants/{account_key}/keys/{key_id}/revoke",
+        response_model=ApiKeyRevokeResponse,
+    )
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_revoke_api_key_endpoint(
+        account_key: str,
+        key_id: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> ApiKeyRevokeResponse:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            result = service.revoke_api_key(
+                account_key=account_key,
+                key_id=key_id,
+                actor_subject=_actor_subject(auth),
+                actor_type="admin",
+            )
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "admin_revoke_api_key",
+            actor=_actor_subject(auth),
+            account=account_key,
+            key_id=result.key_id,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get("/v1/admin/metrics", response_model=AdminMetricsResponse)
+    def admin_metrics_endpoint(
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> AdminMetricsResponse:
+        return service.admin_metrics()
+
+    @app.get("/v1/admin/anomalies", response_model=AdminAnomalyListResponse)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_anomalies_endpoint(
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+        account_key: str | None = None,
+        kind: Annotated[
+            str | None,
+            Query(pattern="^(volume_spike|new_language|repeated_payload)$"),
+        ] = None,
+        limit_count: Annotated[int, Query(alias="limit", ge=1, le=500)] = 50,
+    ) -> AdminAnomalyListResponse:
+        response.headers["Cache-Control"] = "no-store"
+        result = service.admin_anomalies(account_key=account_key, kind=kind, limit=limit_count)
+        log.info(
+            "admin_anomalies",
+            actor=_actor_subject(auth),
+            count=len(result.data),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get(
+        "/v1/admin/tenants/{account_key}/residency",
+        response_model=TenantResidency,
+    )
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_tenant_residency_endpoint(
+        account_key: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> TenantResidency:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            return service.tenant_residency(account_key)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+
+    @app.put(
+        "/v1/admin/tenants/{account_key}/residency",
+        response_model=TenantResidency,
+    )
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_set_tenant_residency_endpoint(
+        account_key: str,
+        request: Request,
+        response: Response,
+        payload: TenantResidencyRequest,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> TenantResidency:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            result = service.set_tenant_residency(account_key, payload)
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "admin_tenant_residency_set",
+            actor=_actor_subject(auth),
+            account=result.account_key,
+            home_region=result.home_region,
+            replica_regions=result.replica_regions,
+            pinned=result.pinned,
+            propagated_to=result.propagated_to,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post(
+        "/v1/admin/tenants/{account_key}/exports",
+        response_model=ExportJob,
+        status_code=status.HTTP_201_CREATED,
+    )
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_create_export_endpoint(
+        account_key: str,
+        request: Request,
+        response: Response,
+        payload: ExportJobRequest,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> ExportJob:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            result = service.create_export_job(account_key, payload)
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "admin_export_created",
+            actor=_actor_subject(auth),
+            account=result.account_key,
+            export_id=result.export_id,
+            cron=result.cron,
+            format=result.format,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get(
+        "/v1/admin/tenants/{account_key}/exports",
+        response_model=ExportJobListResponse,
+    )
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_export_jobs_endpoint(
+        account_key: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> ExportJobListResponse:
+        response.headers["Cache-Control"] = "no-store"
+        return service.list_export_jobs(account_key)
+
+    @app.post("/v1/admin/exports/{export_id}/run", response_model=ExportJob)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_run_export_endpoint(
+        export_id: str,
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> ExportJob:
+        try:
+            result = service.run_export_job(export_id)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "admin_export_run",
+            actor=_actor_subject(auth),
+            export_id=export_id,
+            status=result.last_status,
+            rows=result.rows_exported,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.delete("/v1/admin/exports/{export_id}", response_model=ExportJob)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_delete_export_endpoint(
+        export_id: str,
+        request: Request,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> ExportJob:
+        try:
+            result = service.delete_export_job(export_id)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "admin_export_deleted",
+            actor=_actor_subject(auth),
+            export_id=export_id,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.put("/v1/admin/clock", response_model=PinnedClock)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_set_clock_endpoint(
+        payload: PinnedClock,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> PinnedClock:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            result = service.set_clock(payload)
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_409_CONFLICT,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "admin_clock_set",
+            actor=_actor_subject(auth),
+            now=result.now.isoformat(),
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get("/v1/admin/namespaces", response_model=NamespaceListResponse)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_namespaces_endpoint(
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> NamespaceListResponse:
+        response.headers["Cache-Control"] = "no-store"
+        return service.list_namespaces()
+
+    @app.get("/v1/admin/namespaces/{account_key}", response_model=Namespace)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_namespace_endpoint(
+        account_key: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> Namespace:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            return service.namespace(account_key)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+
+    @app.put("/v1/admin/namespaces/{account_key}", response_model=Namespace)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_set_namespace_endpoint(
+        account_key: str,
+        payload: NamespaceRequest,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> Namespace:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            result = service.set_namespace(account_key, payload)
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "admin_namespace_set",
+            actor=_actor_subject(auth),
+            account=account_key,
+            pipeline=result.pipeline,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.delete("/v1/admin/namespaces/{account_key}", response_model=Namespace)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_delete_namespace_endpoint(
+        account_key: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> Namespace:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            result = service.delete_namespace(account_key)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "admin_namespace_deleted",
+            actor=_actor_subject(auth),
+            account=account_key,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get("/v1/admin/tenants/{account_key}/event-types", response_model=EventTypeRegistry)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_event_types_endpoint(
+        account_key: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> EventTypeRegistry:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            return service.event_type_registry(account_key)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+
+    @app.put("/v1/admin/tenants/{account_key}/event-types", response_model=EventTypeRegistry)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_set_event_types_endpoint(
+        account_key: str,
+        payload: EventTypeRegistryRequest,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> EventTypeRegistry:
+        response.headers["Cache-Control"] = "no-store"
+        result = service.set_event_type_registry(account_key, payload)
+        log.info(
+            "admin_event_types_set",
+            actor=_actor_subject(auth),
+            account=account_key,
+            event_types=len(result.event_types),
+            enforce=result.enforce,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.delete("/v1/admin/tenants/{account_key}/event-types", response_model=EventTypeRegistry)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_delete_event_types_endpoint(
+        account_key: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> EventTypeRegistry:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            result = service.delete_event_type_registry(account_key)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "admin_event_types_deleted",
+            actor=_actor_subject(auth),
+            account=account_key,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get("/v1/admin/tenants/{account_key}/write-policy", response_model=WritePolicy)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_write_policy_endpoint(
+        account_key: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> WritePolicy:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            return service.write_policy(account_key)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+
+    @app.put("/v1/admin/tenants/{account_key}/write-policy", response_model=WritePolicy)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_set_write_policy_endpoint(
+        account_key: str,
+        payload: WritePolicyRequest,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> WritePolicy:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            result = service.set_write_policy(account_key, payload)
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "admin_write_policy_set",
+            actor=_actor_subject(auth),
+            account=account_key,
+            allowed_event_types=(
+                len(result.allowed_event_types) if result.allowed_event_types is not None else None
+            ),
+            max_importance=result.max_importance,
+            banned_categories=result.banned_categories,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.delete("/v1/admin/tenants/{account_key}/write-policy", response_model=WritePolicy)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_delete_write_policy_endpoint(
+        account_key: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> WritePolicy:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            result = service.delete_write_policy(account_key)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "admin_write_policy_deleted",
+            actor=_actor_subject(auth),
+            account=account_key,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get("/v1/admin/tenants/{account_key}/retention", response_model=RetentionPolicy)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_retention_endpoint(
+        account_key: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> RetentionPolicy:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            return service.retention_policy(account_key)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+
+    @app.put("/v1/admin/tenants/{account_key}/retention", response_model=RetentionPolicy)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_set_retention_endpoint(
+        account_key: str,
+        payload: RetentionPolicyRequest,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> RetentionPolicy:
+        response.headers["Cache-Control"] = "no-store"
+        result = service.set_retention_policy(account_key, payload)
+        log.info(
+            "admin_retention_set",
+            actor=_actor_subject(auth),
+            account=account_key,
+            days=result.days,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.delete("/v1/admin/tenants/{account_key}/retention", response_model=RetentionPolicy)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_delete_retention_endpoint(
+        account_key: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> RetentionPolicy:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            result = service.delete_retention_policy(account_key)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "admin_retention_deleted",
+            actor=_actor_subject(auth),
+            account=account_key,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get("/v1/admin/tenants/{account_key}/pipeline-webhook", response_model=PipelineWebhook)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_pipeline_webhook_endpoint(
+        account_key: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> PipelineWebhook:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            return service.pipeline_webhook(account_key)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+
+    @app.put("/v1/admin/tenants/{account_key}/pipeline-webhook", response_model=PipelineWebhook)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_set_pipeline_webhook_endpoint(
+        account_key: str,
+        payload: PipelineWebhookRequest,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> PipelineWebhook:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            result = service.set_pipeline_webhook(account_key, payload)
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "admin_pipeline_webhook_set",
+            actor=_actor_subject(auth),
+            account=account_key,
+            failure_policy=result.failure_policy,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.delete("/v1/admin/tenants/{account_key}/pipeline-webhook", response_model=PipelineWebhook)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_delete_pipeline_webhook_endpoint(
+        account_key: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> PipelineWebhook:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            result = service.delete_pipeline_webhook(account_key)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+        log.info(
+            "admin_pipeline_webhook_deleted",
+            actor=_actor_subject(auth),
+            account=account_key,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.post(
+        "/v1/admin/optimize",
+        response_model=OptimizeJob,
+        status_code=status.HTTP_202_ACCEPTED,
+    )
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_optimize_endpoint(
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> OptimizeJob:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            result = service.start_optimize_job(trigger="manual")
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_409_CONFLICT,
+                detail=str(exc),
+            ) from exc
+        response.headers["Location"] = f"/v1/admin/optimize/{result.job_id}"
+        log.info(
+            "admin_optimize_started",
+            actor=_actor_subject(auth),
+            job_id=result.job_id,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get("/v1/admin/optimize", response_model=OptimizeJobListResponse)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_optimize_jobs_endpoint(
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> OptimizeJobListResponse:
+        response.headers["Cache-Control"] = "no-store"
+        return service.list_optimize_jobs()
+
+    @app.get("/v1/admin/optimize/{job_id}", response_model=OptimizeJob)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_optimize_job_endpoint(
+        job_id: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> OptimizeJob:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            return service.optimize_job(job_id)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
 
     @app.post(
-        "/v1/dashboard/pilot-pro/request",
-        response_model=PilotProRequestResponse,
+        "/v1/admin/index-deployments",
+        response_model=IndexDeployment,
+        status_code=status.HTTP_202_ACCEPTED,
     )
     @limit(config.dashboard_key_per_minute_limit)
-    def request_pilot_pro_endpoint(
+    def admin_index_deployment_create_endpoint(
+        payload: IndexDeploymentRequest,
         request: Request,
         response: Response,
         service: Annotated[OrbitApiService, Depends(get_service)],
-        auth: Annotated[AuthContext, Depends(require_keys_write_scope)],
-    ) -> PilotProRequestResponse:
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> IndexDeployment:
         response.headers["Cache-Control"] = "no-store"
-        result = service.request_pilot_pro(
-            account_key=auth.subject,
-            actor_subject=_actor_subject(auth),
-            actor_email=_actor_email(auth),
-            actor_name=_actor_name(auth),
-        )
+        try:
+            result = service.start_index_deployment(payload)
+        except IndexDeploymentConflictError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_409_CONFLICT,
+                detail=str(exc),
+            ) from exc
+        except ValueError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                detail=str(exc),
+            ) from exc
+        response.headers["Location"] = f"/v1/admin/index-deployments/{result.deployment_id}"
         log.info(
-            "request_pilot_pro",
-            account=auth.subject,
-            created=result.created,
-            email_sent=result.email_sent,
+            "admin_index_deployment_started",
+            actor=_actor_subject(auth),
+            deployment_id=result.deployment_id,
             path=str(request.url.path),
         )
         return result
 
-    @app.post(
-        "/v1/dashboard/keys",
-        response_model=ApiKeyIssueResponse,
-        status_code=status.HTTP_201_CREATED,
+    @app.get("/v1/admin/index-deployments", response_model=IndexDeploymentListResponse)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_index_deployments_endpoint(
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> IndexDeploymentListResponse:
+        response.headers["Cache-Control"] = "no-store"
+        return service.list_index_deployments()
+
+    @app.get("/v1/admin/index-deployments/{deployment_id}", response_model=IndexDeployment)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_index_deployment_endpoint(
+        deployment_id: str,
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> IndexDeployment:
+        response.headers["Cache-Control"] = "no-store"
+        try:
+            return service.index_deployment(deployment_id)
+        except KeyError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+
+    @app.put(
+        "/v1/admin/index-deployments/{deployment_id}/mirror",
+        response_model=IndexDeployment,
     )
     @limit(config.dashboard_key_per_minute_limit)
-    def issue_api_key_endpoint(
-        payload: ApiKeyCreateRequest,
+    def admin_index_deployment_mirror_endpoint(
+        deployment_id: str,
+        payload: IndexMirrorRequest,
         request: Request,
         response: Response,
         service: Annotated[OrbitApiService, Depends(get_service)],
-        auth: Annotated[AuthContext, Depends(require_keys_write_scope)],
-    ) -> ApiKeyIssueResponse:
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> IndexDeployment:
         response.headers["Cache-Control"] = "no-store"
         try:
-            result = service.issue_api_key(
-                account_key=auth.subject,
-                name=payload.name,
-                scopes=payload.scopes,
-                actor_subject=_actor_subject(auth),
-            )
-        except PlanQuotaExceededError as exc:
-            raise _plan_quota_exception(exc) from exc
-        except ValueError as exc:
+            result = service.set_index_mirror(deployment_id, payload.mirror_percent)
+        except KeyError as exc:
             raise HTTPException(
-                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+        except IndexDeploymentConflictError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_409_CONFLICT,
                 detail=str(exc),
             ) from exc
         log.info(
-            "issue_api_key",
-            account=auth.subject,
-            key_id=result.key_id,
+            "admin_index_deployment_mirror_set",
+            actor=_actor_subject(auth),
+            deployment_id=deployment_id,
+            mirror_percent=payload.mirror_percent,
             path=str(request.url.path),
         )
         return result
 
-    @app.get("/v1/dashboard/keys", response_model=ApiKeyListResponse)
+    @app.post(
+        "/v1/admin/index-deployments/{deployment_id}/promote",
+        response_model=IndexDeployment,
+    )
     @limit(config.dashboard_key_per_minute_limit)
-    def list_api_keys_endpoint(
+    def admin_index_deployment_promote_endpoint(
+        deployment_id: str,
         request: Request,
         response: Response,
         service: Annotated[OrbitApiService, Depends(get_service)],
-        auth: Annotated[AuthContext, Depends(require_keys_read_scope)],
-        limit_count: Annotated[int, Query(alias="limit", ge=1, le=100)] = 50,
-        cursor: str | None = None,
-    ) -> ApiKeyListResponse:
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> IndexDeployment:
         response.headers["Cache-Control"] = "no-store"
         try:
-            result = service.list_api_keys(
-                account_key=auth.subject,
-                limit=limit_count,
-                cursor=cursor,
-            )
-        except ValueError as exc:
+            result = service.promote_index_deployment(deployment_id)
+        except KeyError as exc:
             raise HTTPException(
-                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=str(exc),
+            ) from exc
+        except IndexDeploymentConflictError as exc:
+            raise HTTPException(
+                status_code=status.HTTP_409_CONFLICT,
                 detail=str(exc),
             ) from exc
         log.info(
-            "list_api_keys",
-            account=auth.subject,
-            count=len(result.data),
+            "admin_index_deployment_promoted",
+            actor=_actor_subject(auth),
+            deployment_id=deployment_id,
             path=str(request.url.path),
         )
         return result
 
-    @app.post("/v1/dashboard/keys/{key_id}/revoke", response_model=ApiKeyRevokeResponse)
+    @app.delete(
+        "/v1/admin/index-deployments/{deployment_id}",
+        response_model=IndexDeployment,
+    )
     @limit(config.dashboard_key_per_minute_limit)
-    def revoke_api_key_endpoint(
-        key_id: str,
+    def admin_index_deployment_retire_endpoint(
+        deployment_id: str,
         request: Request,
         response: Response,
         service: Annotated[OrbitApiService, Depends(get_service)],
-        auth: Annotated[AuthContext, Depends(require_keys_write_scope)],
-    ) -> ApiKeyRevokeResponse:
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> IndexDeployment:
         response.headers["Cache-Control"] = "no-store"
         try:
-            result = service.revoke_api_key(
-                account_key=auth.subject,
-                key_id=key_id,
-                actor_subject=_actor_subject(auth),
-            )
+            result = service.retire_index_deployment(deployment_id)
         except KeyError as exc:
             raise HTTPException(
                 status_code=status.HTTP_404_NOT_FOUND,
                 detail=str(exc),
             ) from exc
+        log.info(
+            "admin_index_deployment_retired",
+            actor=_actor_subject(auth),
+            deployment_id=deployment_id,
+            status=result.status,
+            path=str(request.url.path),
+        )
+        return result
+
+    @app.get(
+        "/v1/internal/replication/{account_key}/changes",
+        response_model=ReplicationBatch,
+        include_in_schema=False,
+        dependencies=[Depends(require_replication_peer)],
+    )
+    def replication_changes_endpoint(
+        account_key: str,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        cursor: str | None = None,
+    ) -> ReplicationBatch:
+        return service.replication_batch(account_key, cursor=cursor)
+
+    @app.put(
+        "/v1/internal/replication/residency/{account_key}",
+        response_model=TenantResidency,
+        include_in_schema=False,
+        dependencies=[Depends(require_replication_peer)],
+    )
+    def replication_residency_endpoint(
+        account_key: str,
+        payload: TenantResidencyRequest,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+    ) -> TenantResidency:
+        try:
+            return service.set_tenant_residency(account_key, payload, propagate=False)
         except ValueError as exc:
             raise HTTPException(
                 status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
                 detail=str(exc),
             ) from exc
+
+    @app.get("/v1/admin/moderation/reviews", response_model=ModerationReviewListResponse)
+    @limit(config.dashboard_key_per_minute_limit)
+    def admin_moderation_reviews_endpoint(
+        request: Request,
+        response: Response,
+        service: Annotated[OrbitApiService, Depends(get_service)],
+        auth: Annotated[AuthContext, Depends(require_operator)],
+        account_key: str | None = None,
+        review_status: Annotated[
+            str | None,
+            Query(alias="status", pattern="^(pending|appealed|approved|rejected)$"),
+        ] = None,
+        limit_count: Annotated[int, Query(alias="limit", ge=1, le=500)] = 50,
+    ) -> ModerationReviewListResponse:
+        response.headers["Cache-Control"] = "no-store"
+        result = service.moderation_reviews(
+            account_key=account_key,
+            status=review_status,
+            limit=limit_count,
+        )
         log.info(
-            "revoke_api_key",
-            account=auth.subject,
-            key_id=result.key_id,
+            "admin_moderation_reviews",
+            actor=_actor_subject(auth),
+            count=len(result.data),
             path=str(request.url.path),
         )
         return result
 
     @app.post(
-        "/v1/dashboard/keys/{key_id}/rotate",
-        response_model=ApiKeyRotateResponse,
-        status_code=status.HTTP_201_CREATED,
+        "/v1/admin/moderation/reviews/{review_id}/resolve",
+        response_model=ModerationReview,
     )
     @limit(config.dashboard_key_per_minute_limit)
-    def rotate_api_key_endpoint(
-        key_id: str,
-        payload: ApiKeyRotateRequest,
+    def admin_resolve_moderation_endpoint(
+        review_id: int,
+        payload: ModerationResolveRequest,
         request: Request,
         response: Response,
         service: Annotated[OrbitApiService, Depends(get_service)],
-        auth: Annotated[AuthContext, Depends(require_keys_write_scope)],
-    ) -> ApiKeyRotateResponse:
+        auth: Annotated[AuthContext, Depends(require_operator)],
+    ) -> ModerationReview:
         response.headers["Cache-Control"] = "no-store"
         try:
-            result = service.rotate_api_key(
-                account_key=auth.subject,
-                key_id=key_id,
-                name=payload.name,
-                scopes=payload.scopes,
-                actor_subject=_actor_subject(auth),
+            result = service.resolve_moderation_review(
+                review_id,
+                payload,
+                resolved_by=_actor_subject(auth),
             )
         except KeyError as exc:
-            service.record_dashboard_key_rotation_failure()
             raise HTTPException(
                 status_code=status.HTTP_404_NOT_FOUND,
                 detail=str(exc),
             ) from exc
         except ValueError as exc:
-            service.record_dashboard_key_rotation_failure()
             raise HTTPException(
-                status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+                status_code=status.HTTP_409_CONFLICT,
                 detail=str(exc),
             ) from exc
-        except Exception:
-            service.record_dashboard_key_rotation_failure()
-            raise
         log.info(
-            "rotate_api_key",
-            account=auth.subject,
-            revoked_key_id=result.revoked_key_id,
-            new_key_id=result.new_key.key_id,
+            "admin_resolve_moderation",
+            actor=_actor_subject(auth),
+            account=result.account_key,
+            review_id=review_id,
+            decision=payload.decision,
             path=str(request.url.path),
         )
         return result
 
-    @app.get("/v1/memories", response_model=PaginatedMemoriesResponse)
-    @limit(config.per_minute_limit)
-    def list_memories_endpoint(
-        request: Request,
-        response: Response,
-        service: Annotated[OrbitApiService, Depends(get_service)],
-        auth: Annotated[AuthContext, Depends(require_read_scope)],
-        limit_count: Annotated[int, Query(alias="limit", ge=1, le=100)] = 100,
-        cursor: str | None = None,
-    ) -> PaginatedMemoriesResponse:
-        snapshot = _consume_or_raise(
-            service.consume_query_quota,
-            account_key=auth.subject,
-            amount=1,
-        )
-        result = service.list_memories(
-            limit=limit_count,
-            cursor=cursor,
-            account_key=auth.subject,
+    return app
+
+
+def _is_backfill_import(request: Request) -> bool:
+    return request.query_params.get("mode") == "backfill"
+
+
+def _is_browser_token(auth: AuthContext) -> bool:
+    return auth.claims.get("auth_type") == BROWSER_TOKEN_AUTH_TYPE
+
+
+def _enforce_browser_token(auth: AuthContext, request: Request) -> None:
+    if request.url.path not in _BROWSER_TOKEN_PATHS:
+        raise HTTPException(
+            status_code=status.HTTP_403_FORBIDDEN,
+            detail="Browser tokens can only call retrieval and feedback endpoints.",
         )
-        _apply_rate_headers(response, snapshot)
-        log.info(
-            "list_memories",
-            account=auth.subject,
-            count=len(result.data),
-            path=str(request.url.path),
+    allowed_origins = auth.claims.get("allowed_origins") or []
+    origin = (request.headers.get("origin") or "").strip().lower()
+    if allowed_origins and origin not in allowed_origins:
+        raise HTTPException(
+            status_code=status.HTTP_403_FORBIDDEN,
+            detail="Browser token is not allowed from this origin.",
         )
-        return result
 
-    return app
+
+def _api_key_id(auth: AuthContext) -> str | None:
+    raw = auth.claims.get("key_id")
+    return str(raw) if raw else None
+
+
+def _sensitivity_clearance(auth: AuthContext) -> str:
+    """Most restricted memory label the caller may read; plain ``read`` sees ``public`` only."""
+    scopes = set(auth.scopes)
+    if scopes & {"admin", "memory:confidential"}:
+        return "confidential"
+    if "memory:internal" in scopes:
+        return "internal"
+    return "public"
+
+
+def _acting_agent(auth: AuthContext, requested: str | None) -> str | None:
+    """Agent a read acts as, bound to an ``agent:<id>`` key scope or a JWT ``agent_id`` claim.
+
+    A credential bound to an agent cannot ask as another one, and omitting ``agent_id`` acts as
+    the bound agent. Unbound credentials act as whichever agent they name.
+    """
+    bound = {scope.removeprefix("agent:") for scope in auth.scopes if scope.startswith("agent:")}
+    claimed = auth.claims.get("agent_id")
+    if claimed:
+        bound.add(str(claimed))
+    if not bound:
+        return requested
+    if requested is None:
+        return next(iter(bound)) if len(bound) == 1 else None
+    if requested not in bound:
+        raise HTTPException(
+            status_code=status.HTTP_403_FORBIDDEN,
+            detail="Credential is restricted to a different agent_id.",
+        )
+    return requested
 
 
 def _actor_subject(auth: AuthContext) -> str:
@@ -756,6 +4733,75 @@ def _actor_name(auth: AuthContext) -> str | None:
     return normalized or None
 
 
+class _RegionForwarded(Exception):
+    """Carries the home region's answer to a request this region forwarded."""
+
+    def __init__(self, response: Response) -> None:
+        super().__init__("request forwarded to home region")
+        self.response = response
+
+
+async def _forward_to_region(
+    request: Request,
+    base_url: str,
+    *,
+    home_region: str,
+    local_region: str,
+) -> Response:
+    headers = {
+        key: value
+        for key, value in request.headers.items()
+        if key.lower() not in _HOP_BY_HOP_HEADERS and key.lower() not in _TRACE_HEADERS
+    }
+    headers.update(outbound_headers())
+    headers[FORWARDED_FROM_HEADER] = local_region
+    if "content-length" in request.headers:
+        # Keeps the streamed upload from being re-sent chunked.
+        headers["content-length"] = request.headers["content-length"]
+    url = f"{base_url}{request_path(request)}"
+    if request.url.query:
+        url = f"{url}?{request.url.query}"
+    # Both bodies are streamed so large imports and exports are never held in memory here.
+    client = httpx.AsyncClient(timeout=_REGION_FORWARD_TIMEOUT_SECONDS)
+    try:
+        upstream = await client.send(
+            client.build_request(
+                request.method,
+                url,
+                content=None if request.method in READ_METHODS else request.stream(),
+                headers=headers,
+            ),
+            stream=True,
+        )
+    except httpx.HTTPError as exc:
+        await client.aclose()
+        raise HTTPException(
+            status_code=status.HTTP_502_BAD_GATEWAY,
+            detail={
+                "message": f"Home region {home_region} is unreachable.",
+                "error_code": "region_unreachable",
+            },
+            headers={REGION_HEADER: home_region, "X-Orbit-Error-Code": "region_unreachable"},
+        ) from exc
+
+    async def _close() -> None:
+        await upstream.aclose()
+        await client.aclose()
+
+    response_headers = {
+        key: value
+        for key, value in upstream.headers.items()
+        if key.lower() not in _HOP_BY_HOP_HEADERS
+    }
+    response_headers[REGION_HEADER] = home_region
+    return StreamingResponse(
+        upstream.aiter_raw(),
+        status_code=upstream.status_code,
+        headers=response_headers,
+        background=BackgroundTask(_close),
+    )
+
+
 def _service_from_app(app: FastAPI) -> OrbitApiService:
     service = getattr(app.state, "orbit_service", None)
     if not isinstance(service, OrbitApiService):
@@ -764,6 +4810,111 @@ def _service_from_app(app: FastAPI) -> OrbitApiService:
     return service
 
 
+def _ingest_field_errors(
+    config: ApiConfig,
+    content: str,
+    event_type: str | None,
+    *,
+    prefix: str = "",
+    label: str = "content",
+    on_oversize: str = "reject",
+) -> list[FieldError]:
+    errors: list[FieldError] = []
+    # Other actions shrink oversized content in the service instead.
+    if on_oversize == "reject" and len(content) > config.max_ingest_content_chars:
+        errors.append(
+            FieldError(
+                f"{prefix}content",
+                "max_length",
+                f"{label} exceeds ORBIT_MAX_INGEST_CONTENT_CHARS="
+                f"{config.max_ingest_content_chars}",
+                limit=config.max_ingest_content_chars,
+            )
+        )
+    allowed = config.allowed_event_types
+    if allowed and event_type is not None and event_type not in allowed:
+        errors.append(
+            FieldError(
+                f"{prefix}event_type",
+                "enum",
+                f"unknown event type {event_type!r}; allowed: {', '.join(allowed)}",
+                limit=list(allowed),
+            )
+        )
+    return errors
+
+
+def _check_ingest_fields(
+    config: ApiConfig,
+    content: str,
+    event_type: str | None,
+    *,
+    label: str = "content",
+    on_oversize: str = "reject",
+) -> None:
+    errors = _ingest_field_errors(
+        config,
+        content,
+        event_type,
+        label=label,
+        on_oversize=on_oversize,
+    )
+    if errors:
+        raise FieldValidationError(errors)
+
+
+def _ingest_stream_line(
+    service: OrbitApiService,
+    config: ApiConfig,
+    line: bytes,
+    *,
+    number: int,
+    account_key: str,
+    key_id: str | None,
+) -> IngestStreamAck:
+    """Ingest one NDJSON line as ``POST /v1/ingest`` would, reporting failure in the ack."""
+    try:
+        payload = IngestRequest.model_validate_json(line)
+    except ValidationError as exc:
+        errors = field_errors_from_pydantic(exc.errors(include_url=False))
+    else:
+        errors = _ingest_field_errors(
+            config,
+            payload.content,
+            payload.event_type,
+            on_oversize=payload.on_oversize or config.on_oversize,
+        )
+    if errors:
+        return IngestStreamAck(
+            line=number,
+            ok=False,
+            status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+            error="; ".join(f"{error.field}: {error.message}" for error in errors),
+        )
+    try:
+        result, _, _ = service.ingest_with_quota(
+            account_key=account_key,
+            request=payload,
+            idempotency_key=None,
+            key_id=key_id,
+        )
+    except ValueError as exc:
+        return IngestStreamAck(
+            line=number,
+            ok=False,
+            status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
+            error=str(exc),
+        )
+    except RateLimitExceededError as exc:
+        return IngestStreamAck(
+            line=number,
+            ok=False,
+            status_code=status.HTTP_429_TOO_MANY_REQUESTS,
+            error=exc.detail,
+        )
+    return IngestStreamAck(line=number, ok=True, status_code=status.HTTP_201_CREATED, result=result)
+
+
 def _build_time_range(
     start_time: datetime | None,
     end_time: datetime | None,
@@ -772,6 +4923,10 @@ def _build_time_range(
         return None
     resolved_start = start_time or datetime.fromtimestamp(0, tz=UTC)
     resolved_end = end_time or datetime.now(UTC)
+    if resolved_end < resolved_start:
+        raise FieldValidationError(
+            [FieldError("end_time", "invalid", "end_time must be >= start_time", location="query")]
+        )
     return TimeRange(start=resolved_start, end=resolved_end)
 
 
@@ -816,3 +4971,28 @@ def _plan_quota_exception(exc: PlanQuotaExceededError) -> HTTPException:
         },
         headers={"X-Orbit-Error-Code": exc.error_code},
     )
+
+
+def _etag(version: int) -> str:
+    return f'"{version}"'
+
+
+def _if_match_version(if_match: str | None) -> int | None:
+    """Version named by an ``If-Match`` header; ``None`` when absent or ``*``."""
+    if if_match is None or if_match.strip() == "*":
+        return None
+    tag = if_match.strip().removeprefix("W/").strip('"')
+    if not tag.isdigit():
+        raise HTTPException(
+            status_code=status.HTTP_412_PRECONDITION_FAILED,
+            detail="If-Match must be an ETag returned by this API.",
+        )
+    return int(tag)
+
+
+def _precondition_failed_exception(exc: PreconditionFailedError) -> HTTPException:
+    return HTTPException(
+        status_code=status.HTTP_412_PRECONDITION_FAILED,
+        detail=str(exc),
+        headers={"ETag": _etag(exc.current_version)},
+    )
@@ -2,6 +2,7 @@
 
 from __future__ import annotations
 
+import hmac
 from dataclasses import dataclass
 from typing import Any
 
@@ -82,6 +83,39 @@ def require_auth_context(
     )
 
 
+def require_operator_context(
+    credentials: HTTPAuthorizationCredentials | None,
+    config: ApiConfig,
+) -> AuthContext:
+    """Accept only a bearer token listed in ``config.operator_tokens``.
+
+    Operator routes act across tenants, so tenant JWTs and API keys are refused here even when
+    they carry the ``admin`` scope.
+    """
+    token = credentials.credentials.strip() if credentials is not None else ""
+    if not token:
+        raise HTTPException(
+            status_code=status.HTTP_401_UNAUTHORIZED,
+            detail="Missing bearer token.",
+        )
+    operator = None
+    for name, secret in config.operator_tokens.items():
+        if hmac.compare_digest(token.encode("utf-8"), secret.encode("utf-8")):
+            operator = name
+    if operator is None:
+        raise HTTPException(
+            status_code=status.HTTP_403_FORBIDDEN,
+            detail="An operator token is required.",
+        )
+    subject = f"operator:{operator}"
+    return AuthContext(
+        subject=subject,
+        scopes=["admin"],
+        token=token,
+        claims={"sub": subject, "auth_subject": subject, "auth_type": "operator"},
+    )
+
+
 def _parse_scopes(payload: dict[str, Any]) -> list[str]:
     claim = payload.get("scopes")
     if isinstance(claim, list):
@@ -0,0 +1,384 @@
+"""Consistent backups of metadata DB, vector index, and blob store with restore."""
+
+from __future__ import annotations
+
+import base64
+import json
+import shutil
+from dataclasses import asdict, dataclass, field
+from datetime import UTC, date, datetime
+from pathlib import Path
+from typing import Any
+
+from sqlalchemy import Date, DateTime, LargeBinary, Table, create_engine, inspect, select
+from sqlalchemy.engine import Connection, Engine
+
+from memory_engine.storage.db import Base
+from orbit_api.blob_store import BlobStore, LocalBlobStore
+
+MANIFEST_FILENAME = "manifest.json"
+BLOB_INDEX_FILENAME = "blobs.jsonl"
+
+
+@dataclass
+class BackupManifest:
+    backup_id: str
+    kind: str
+    created_at: datetime
+    parent_id: str | None = None
+    since: datetime | None = None
+    tables: dict[str, int] = field(default_factory=dict)
+    blobs: int = 0
+    vector_index: bool = False
+
+    def to_json(self) -> dict[str, Any]:
+        payload = asdict(self)
+        payload["created_at"] = self.created_at.isoformat()
+        payload["since"] = self.since.isoformat() if self.since else None
+        return payload
+
+    @classmethod
+    def from_json(cls, payload: dict[str, Any]) -> BackupManifest:
+        return cls(
+            backup_id=str(payload["backup_id"]),
+            kind=str(payload["kind"]),
+            created_at=_parse_datetime(str(payload["created_at"])),
+            parent_id=payload.get("parent_id"),
+            since=_parse_datetime(payload["since"]) if payload.get("since") else None,
+            tables={str(k): int(v) for k, v in dict(payload.get("tables", {})).items()},
+            blobs=int(payload.get("blobs", 0)),
+            vector_index=bool(payload.get("vector_index", False)),
+        )
+
+
+def list_backups(root: str | Path) -> list[BackupManifest]:
+    root_path = Path(root)
+    if not root_path.exists():
+        return []
+    manifests: list[BackupManifest] = []
+    for manifest_path in root_path.glob(f"*/{MANIFEST_FILENAME}"):
+        payload = json.loads(manifest_path.read_text(encoding="utf-8"))
+        manifests.append(BackupManifest.from_json(payload))
+    manifests.sort(key=lambda item: item.created_at)
+    return manifests
+
+
+def create_backup(
+    root: str | Path,
+    *,
+    database_url: str,
+    vector_index_path: str | Path | None = None,
+    blob_store: BlobStore | None = None,
+    incremental: bool = False,
+    now: datetime | None = None,
+) -> BackupManifest:
+    """Snapshot all Orbit tables in one read transaction plus the index file and attachments.
+
+    Attachments are read through ``blob_store``, whatever its backend, for every blob key the
+    snapshot's memories reference. Incremental backups carry rows/blobs changed since the newest
+    existing backup and the full primary-key set of each table so deletions survive a restore.
+    """
+    root_path = Path(root)
+    created_at = now or datetime.now(UTC)
+    parent: BackupManifest | None = None
+    if incremental:
+        existing = list_backups(root_path)
+        if not existing:
+            msg = "incremental backup requires an existing full backup"
+            raise ValueError(msg)
+        parent = existing[-1]
+    since = parent.created_at if parent is not None else None
+    manifest = BackupManifest(
+        backup_id=created_at.strftime("%Y%m%dT%H%M%S%fZ"),
+        kind="incremental" if parent is not None else "full",
+        created_at=created_at,
+        parent_id=parent.backup_id if parent is not None else None,
+        since=since,
+    )
+    target = root_path / manifest.backup_id
+    if target.exists():
+        msg = f"backup already exists: {target}"
+        raise ValueError(msg)
+    (target / "tables").mkdir(parents=True)
+
+    engine = _engine(database_url)
+    try:
+        with _snapshot_connection(engine) as conn:
+            existing_tables = set(inspect(conn).get_table_names())
+            for table in Base.metadata.sorted_tables:
+                if table.name not in existing_tables:
+                    continue
+                manifest.tables[table.name] = _export_table(
+                    conn, table, target / "tables", since=since
+                )
+    finally:
+        engine.dispose()
+
+    if vector_index_path is not None and Path(vector_index_path).exists():
+        shutil.copy2(vector_index_path, target / "vector.idx")
+        manifest.vector_index = True
+    if blob_store is not None:
+        manifest.blobs = _copy_blobs(blob_store, target)
+
+    (target / MANIFEST_FILENAME).write_text(
+        json.dumps(manifest.to_json(), indent=2, sort_keys=True),
+        encoding="utf-8",
+    )
+    return manifest
+
+
+def restore_backup(
+    root: str | Path,
+    *,
+    database_url: str,
+    backup_id: str | None = None,
+    to_timestamp: datetime | None = None,
+    vector_index_path: str | Path | None = None,
+    blob_store: BlobStore | None = None,
+) -> BackupManifest:
+    """Restore the chosen backup (or newest one taken at/before ``to_timestamp``)."""
+    root_path = Path(root)
+    manifests = list_backups(root_path)
+    target = _select_backup(manifests, backup_id=backup_id, to_timestamp=to_timestamp)
+    chain = _backup_chain(manifests, target)
+
+    engine = _engine(database_url)
+    try:
+        Base.metadata.create_all(engine)
+        with engine.begin() as conn:
+            tables = list(Base.metadata.sorted_tables)
+            for table in reversed(tables):
+                conn.execute(table.delete())
+            for manifest in chain:
+                layer = root_path / manifest.backup_id / "tables"
+                for table in tables:
+                    _import_table(conn, table, layer)
+            final_layer = root_path / target.backup_id / "tables"
+            for table in tables:
+                _prune_deleted_rows(conn, table, final_layer)
+    finally:
+        engine.dispose()
+
+    if vector_index_path is not None:
+        index_source = root_path / target.backup_id / "vector.idx"
+        if index_source.exists():
+            Path(vector_index_path).parent.mkdir(parents=True, exist_ok=True)
+            shutil.copy2(index_source, vector_index_path)
+    if blob_store is not None:
+        for manifest in chain:
+            _restore_blobs(root_path / manifest.backup_id, blob_store)
+    return target
+
+
+def _select_backup(
+    manifests: list[BackupManifest],
+    *,
+    backup_id: str | None,
+    to_timestamp: datetime | None,
+) -> BackupManifest:
+    if not manifests:
+        msg = "no backups found"
+        raise ValueError(msg)
+    if backup_id is not None:
+        for manifest in manifests:
+            if manifest.backup_id == backup_id:
+                return manifest
+        msg = f"backup not found: {backup_id}"
+        raise ValueError(msg)
+    if to_timestamp is not None:
+        cutoff = _as_utc(to_timestamp)
+        eligible = [item for item in manifests if item.created_at <= cutoff]
+        if not eligible:
+            msg = f"no backup exists at or before {cutoff.isoformat()}"
+            raise ValueError(msg)
+        return eligible[-1]
+    return manifests[-1]
+
+
+def _backup_chain(
+    manifests: list[BackupManifest],
+    target: BackupManifest,
+) -> list[BackupManifest]:
+    by_id = {item.backup_id: item for item in manifests}
+    chain = [target]
+    while chain[0].parent_id is not None:
+        parent = by_id.get(chain[0].parent_id)
+        if parent is None:
+            msg = f"backup chain broken: missing parent {chain[0].parent_id}"
+            raise ValueError(msg)
+        chain.insert(0, parent)
+    return chain
+
+
+def _engine(database_url: str) -> Engine:
+    connect_args = {"check_same_thread": False} if database_url.startswith("sqlite") else {}
+    return create_engine(database_url, future=True, connect_args=connect_args)
+
+
+def _snapshot_connection(engine: Engine) -> Connection:
+    if engine.dialect.name == "postgresql":
+        return engine.connect().execution_options(isolation_level="REPEATABLE READ")
+    return engine.connect()
+
+
+def _change_column(table: Table) -> str | None:
+    for name in ("updated_at", "created_at"):
+        if name in table.c:
+            return name
+    return None
+
+
+def _export_table(
+    conn: Connection,
+    table: Table,
+    directory: Path,
+    *,
+    since: datetime | None,
+) -> int:
+    stmt = select(table)
+    change_column = _change_column(table)
+    if since is not None and change_column is not None:
+        stmt = stmt.where(table.c[change_column] >= since)
+    count = 0
+    with (directory / f"{table.name}.jsonl").open("w", encoding="utf-8") as handle:
+        for row in conn.execute(stmt).mappings():
+            handle.write(json.dumps(_encode_row(dict(row)), sort_keys=True) + "\n")
+            count += 1
+    primary_keys = [column.name for column in table.primary_key.columns]
+    with (directory / f"{table.name}.keys.jsonl").open("w", encoding="utf-8") as handle:
+        for row in conn.execute(select(*[table.c[name] for name in primary_keys])):
+            handle.write(json.dumps(list(row)) + "\n")
+    return count
+
+
+def _import_table(conn: Connection, table: Table, directory: Path) -> None:
+    path = directory / f"{table.name}.jsonl"
+    if not path.exists():
+        return
+    primary_keys = [column for column in table.primary_key.columns]
+    with path.open(encoding="utf-8") as handle:
+        for line in handle:
+            if not line.strip():
+                continue
+            row = _decode_row(table, json.loads(line))
+            condition = [column == row[column.name] for column in primary_keys]
+            conn.execute(table.delete().where(*condition))
+            conn.execute(table.insert().values(**row))
+
+
+def _prune_deleted_rows(conn: Connection, table: Table, directory: Path) -> None:
+    path = directory / f"{table.name}.keys.jsonl"
+    if not path.exists():
+        return
+    keep = {
+        tuple(json.loads(line))
+        for line in path.read_text(encoding="utf-8").splitlines()
+        if line.strip()
+    }
+    primary_keys = [column for column in table.primary_key.columns]
+    for row in conn.execute(select(*primary_keys)).all():
+        if tuple(row) in keep:
+            continue
+        condition = [
+            column == value for column, value in zip(primary_keys, row, strict=True)
+        ]
+        conn.execute(table.delete().where(*condition))
+
+
+def _copy_blobs(blob_store: BlobStore, target: Path) -> int:
+    """Copy the attachments of the memories in ``target``'s memories layer out of the store.
+
+    An incremental layer only holds memories written since its parent, and blobs are immutable
+    once stored, so this copies exactly the blobs that are new since the parent backup.
+    """
+    archive = LocalBlobStore(target / "blobs")
+    copied = 0
+    with (target / BLOB_INDEX_FILENAME).open("w", encoding="utf-8") as handle:
+        for key, content_type in _attachment_keys(target / "tables" / "memories.jsonl"):
+            try:
+                data = blob_store.get(key)
+            except KeyError:
+                # Deleted between the table snapshot and this read; its memory is going too.
+                continue
+            archive.put(key, data, content_type=content_type)
+            handle.write(json.dumps({"key": key, "content_type": content_type}) + "\n")
+            copied += 1
+    return copied
+
+
+def _restore_blobs(layer: Path, blob_store: BlobStore) -> None:
+    index = layer / BLOB_INDEX_FILENAME
+    if not index.exists():
+        return
+    archive = LocalBlobStore(layer / "blobs")
+    for line in index.read_text(encoding="utf-8").splitlines():
+        if not line.strip():
+            continue
+        entry = json.loads(line)
+        key = str(entry["key"])
+        blob_store.put(key, archive.get(key), content_type=str(entry["content_type"]))
+
+
+def _attachment_keys(path: Path) -> list[tuple[str, str]]:
+    if not path.exists():
+        return []
+    keys: list[tuple[str, str]] = []
+    with path.open(encoding="utf-8") as handle:
+        for line in handle:
+            if not line.strip():
+                continue
+            relationships = json.loads(json.loads(line).get("relationships_json") or "[]")
+            values = {
+                prefix: str(item)[len(prefix) :]
+                for item in relationships
+                for prefix in ("blob_key:", "blob_content_type:")
+                if str(item).startswith(prefix)
+            }
+            if "blob_key:" in values:
+                keys.append(
+                    (
+                        values["blob_key:"],
+                        values.get("blob_content_type:", "application/octet-stream"),
+                    )
+                )
+    return keys
+
+
+def _encode_row(row: dict[str, Any]) -> dict[str, Any]:
+    encoded: dict[str, Any] = {}
+    for key, value in row.items():
+        if isinstance(value, datetime):
+            encoded[key] = _as_utc(value).isoformat()
+        elif isinstance(value, date):
+            encoded[key] = value.isoformat()
+        elif isinstance(value, bytes):
+            encoded[key] = base64.b64encode(value).decode("ascii")
+        else:
+            encoded[key] = value
+    return encoded
+
+
+def _decode_row(table: Table, payload: dict[str, Any]) -> dict[str, Any]:
+    decoded: dict[str, Any] = {}
+    for column in table.columns:
+        if column.name not in payload:
+            continue
+        value = payload[column.name]
+        if value is not None and isinstance(column.type, DateTime):
+            value = _parse_datetime(str(value))
+        elif value is not None and isinstance(column.type, Date):
+            value = date.fromisoformat(str(value))
+        elif value is not None and isinstance(column.type, LargeBinary):
+            value = base64.b64decode(str(value))
+        decoded[column.name] = value
+    return decoded
+
+
+def _parse_datetime(value: str) -> datetime:
+    return _as_utc(datetime.fromisoformat(value))
+
+
+def _as_utc(value: datetime) -> datetime:
+    if value.tzinfo is None:
+        return value.replace(tzinfo=UTC)
+    return value.astimezone(UTC)
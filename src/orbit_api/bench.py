@@ -0,0 +1,339 @@
+"""Synthetic load generation behind ``orbit bench``.
+
+A run has two phases. ``seed`` ingests ``memories_per_entity`` synthetic memories for each of
+``entities`` entities so retrieval has realistic data to search. ``mixed`` then issues
+retrieve and ingest calls in ``read_ratio`` proportion from ``concurrency`` workers until
+``operations`` calls (or ``duration_seconds``) are done. Each operation reports throughput and
+nearest-rank latency percentiles.
+
+Corpus generation is seeded, so two runs with the same settings send the same requests.
+"""
+
+from __future__ import annotations
+
+import itertools
+import random
+import threading
+from collections import Counter
+from collections.abc import Callable
+from concurrent.futures import ThreadPoolExecutor
+from dataclasses import asdict, dataclass, field
+from time import perf_counter
+from typing import TYPE_CHECKING, Any, Protocol
+
+import httpx
+
+from orbit.models import IngestRequest, RetrieveRequest
+from orbit_api.query_analytics import percentile
+
+if TYPE_CHECKING:
+    from orbit_api.service import OrbitApiService
+
+_NAMES = ("Alice", "Bruno", "Chen", "Dana", "Emeka", "Farah", "Goran", "Hana", "Ivan", "Jia")
+_TOPICS = ("billing", "onboarding", "the mobile app", "data exports", "SSO", "the API")
+_TOOLS = ("Python", "Postgres", "Kubernetes", "React", "Terraform", "Excel")
+_CITIES = ("Lisbon", "Nairobi", "Osaka", "Toronto", "Berlin", "Austin")
+_ROLES = ("data engineer", "support lead", "product manager", "student", "designer")
+_PREFERENCES = ("short answers", "code examples", "step-by-step guides", "diagrams")
+_MEMORY_TEMPLATES = (
+    ("user_preference", "{name} prefers {preference} when working on {topic}."),
+    ("user_question", "{name} asked how to configure {topic} with {tool}."),
+    ("user_fact", "{name} lives in {city} and works as a {role}."),
+    ("user_question", "{name} reported that {tool} times out during {topic}."),
+    ("assistant_response", "Explained to {name} how {tool} handles {topic}."),
+)
+_QUERY_TEMPLATES = (
+    "What does {name} prefer?",
+    "How should I explain {topic} to {name}?",
+    "Where does {name} live?",
+    "What problems has {name} had with {tool}?",
+)
+
+
+@dataclass(frozen=True)
+class BenchConfig:
+    entities: int = 100
+    memories_per_entity: int = 10
+    operations: int = 1000
+    duration_seconds: float | None = None
+    concurrency: int = 8
+    read_ratio: float = 0.8
+    limit: int = 5
+    seed: int = 0
+
+    def __post_init__(self) -> None:
+        if self.entities <= 0 or self.concurrency <= 0 or self.limit <= 0:
+            msg = "entities, concurrency and limit must be positive"
+            raise ValueError(msg)
+        if self.memories_per_entity < 0 or self.operations < 0:
+            msg = "memories per entity and operations cannot be negative"
+            raise ValueError(msg)
+        if not 0.0 <= self.read_ratio <= 1.0:
+            msg = "read ratio must be between 0 and 1"
+            raise ValueError(msg)
+        if self.duration_seconds is not None and self.duration_seconds <= 0:
+            msg = "duration must be positive"
+            raise ValueError(msg)
+
+
+class BenchTarget(Protocol):
+    def ingest(self, *, entity_id: str, content: str, event_type: str) -> None: ...
+
+    def retrieve(self, *, entity_id: str, query: str, limit: int) -> None: ...
+
+
+class ServiceTarget:
+    """Drive an in-process ``OrbitApiService``: measures the engine and database only."""
+
+    def __init__(
+        self,
+        service: OrbitApiService,
+        *,
+        account_key: str = "bench",
+        fast: bool = False,
+    ) -> None:
+        self._service = service
+        self._account_key = account_key
+        self._fast = fast
+
+    def ingest(self, *, entity_id: str, content: str, event_type: str) -> None:
+        self._service.ingest(
+            IngestRequest(content=content, event_type=event_type, entity_id=entity_id),
+            account_key=self._account_key,
+        )
+
+    def retrieve(self, *, entity_id: str, query: str, limit: int) -> None:
+        self._service.retrieve(
+            RetrieveRequest(query=query, entity_id=entity_id, limit=limit, fast=self._fast),
+            account_key=self._account_key,
+        )
+
+
+class HttpTarget:
+    """Drive a running deployment over HTTP, including auth, rate limits, and the network."""
+
+    def __init__(
+        self,
+        base_url: str,
+        *,
+        token: str,
+        timeout_seconds: float = 30.0,
+        fast: bool = False,
+    ) -> None:
+        self._client = httpx.Client(
+            base_url=base_url.rstrip("/"),
+            headers={"Authorization": f"Bearer {token}"},
+            timeout=timeout_seconds,
+        )
+        self._fast = fast
+
+    def ingest(self, *, entity_id: str, content: str, event_type: str) -> None:
+        response = self._client.post(
+            "/v1/ingest",
+            json={"content": content, "event_type": event_type, "entity_id": entity_id},
+        )
+        response.raise_for_status()
+
+    def retrieve(self, *, entity_id: str, query: str, limit: int) -> None:
+        params: dict[str, Any] = {"query": query, "entity_id": entity_id, "limit": limit}
+        if self._fast:
+            params["fast"] = "true"
+        response = self._client.get("/v1/retrieve", params=params)
+        response.raise_for_status()
+
+    def close(self) -> None:
+        self._client.close()
+
+
+@dataclass
+class OperationStats:
+    phase: str
+    operation: str
+    latencies_ms: list[float] = field(default_factory=list)
+    errors: Counter[str] = field(default_factory=Counter)
+    _lock: threading.Lock = field(default_factory=threading.Lock, repr=False)
+
+    def record(self, latency_ms: float) -> None:
+        with self._lock:
+            self.latencies_ms.append(latency_ms)
+
+    def record_error(self, error_type: str) -> None:
+        with self._lock:
+            self.errors[error_type] += 1
+
+    def summary(self, elapsed_seconds: float) -> dict[str, Any]:
+        count = len(self.latencies_ms)
+        return {
+            "phase": self.phase,
+            "operation": self.operation,
+            "count": count,
+            "errors": sum(self.errors.values()),
+            "error_types": dict(self.errors),
+            "throughput_per_second": round(count / elapsed_seconds, 2)
+            if elapsed_seconds > 0
+            else 0.0,
+            "p50_ms": round(percentile(self.latencies_ms, 0.50), 2),
+            "p90_ms": round(percentile(self.latencies_ms, 0.90), 2),
+            "p95_ms": round(percentile(self.latencies_ms, 0.95), 2),
+            "p99_ms": round(percentile(self.latencies_ms, 0.99), 2),
+            "max_ms": round(max(self.latencies_ms, default=0.0), 2),
+        }
+
+
+@dataclass
+class BenchReport:
+    config: BenchConfig
+    elapsed_seconds: dict[str, float] = field(default_factory=dict)
+    operations: list[dict[str, Any]] = field(default_factory=list)
+
+    def as_dict(self) -> dict[str, Any]:
+        return {
+            "config": asdict(self.config),
+            "elapsed_seconds": self.elapsed_seconds,
+            "operations": self.operations,
+        }
+
+    def format_table(self) -> str:
+        header = (
+            f"{'phase':<6} {'operation':<9} {'count':>7} {'errors':>6} {'ops/s':>9} "
+            f"{'p50 ms':>8} {'p90 ms':>8} {'p95 ms':>8} {'p99 ms':>8} {'max ms':>8}"
+        )
+        lines = [header]
+        for item in self.operations:
+            lines.append(
+                f"{item['phase']:<6} {item['operation']:<9} {item['count']:>7} "
+                f"{item['errors']:>6} {item['throughput_per_second']:>9.1f} "
+                f"{item['p50_ms']:>8.1f} {item['p90_ms']:>8.1f} {item['p95_ms']:>8.1f} "
+                f"{item['p99_ms']:>8.1f} {item['max_ms']:>8.1f}"
+            )
+        return "\n".join(lines)
+
+
+class SyntheticCorpus:
+    """Deterministic entities, memories, and queries for a given seed."""
+
+    def __init__(self, *, entities: int, seed: int) -> None:
+        self.entity_ids = [f"bench-user-{index:05d}" for index in range(entities)]
+        rng = random.Random(seed)
+        self._profiles = {
+            entity_id: {
+                "name": f"{rng.choice(_NAMES)} {index}",
+                "topic": rng.choice(_TOPICS),
+                "tool": rng.choice(_TOOLS),
+                "city": rng.choice(_CITIES),
+                "role": rng.choice(_ROLES),
+                "preference": rng.choice(_PREFERENCES),
+            }
+            for index, entity_id in enumerate(self.entity_ids)
+        }
+
+    def memory(self, rng: random.Random, entity_id: str | None = None) -> tuple[str, str, str]:
+        """Return ``(entity_id, content, event_type)``."""
+        chosen = entity_id or rng.choice(self.entity_ids)
+        fields = {**self._profiles[chosen], "topic": rng.choice(_TOPICS)}
+        event_type, template = rng.choice(_MEMORY_TEMPLATES)
+        return chosen, template.format(**fields), event_type
+
+    def query(self, rng: random.Random) -> tuple[str, str]:
+        """Return ``(entity_id, query)``."""
+        entity_id = rng.choice(self.entity_ids)
+        return entity_id, rng.choice(_QUERY_TEMPLATES).format(**self._profiles[entity_id])
+
+
+def run_bench(target: BenchTarget, config: BenchConfig) -> BenchReport:
+    corpus = SyntheticCorpus(entities=config.entities, seed=config.seed)
+    report = BenchReport(config=config)
+    seed_ingest = OperationStats("seed", "ingest")
+    mixed_retrieve = OperationStats("mixed", "retrieve")
+    mixed_ingest = OperationStats("mixed", "ingest")
+
+    def seed_operation(rng: random.Random, index: int) -> None:
+        # Round-robin over entities so every entity gets the same number of memories.
+        entity_id = corpus.entity_ids[index % len(corpus.entity_ids)]
+        _, content, event_type = corpus.memory(rng, entity_id)
+        _timed(
+            seed_ingest,
+            lambda: target.ingest(entity_id=entity_id, content=content, event_type=event_type),
+        )
+
+    def mixed_operation(rng: random.Random, _: int) -> None:
+        if rng.random() < config.read_ratio:
+            entity_id, query = corpus.query(rng)
+            _timed(
+                mixed_retrieve,
+                lambda: target.retrieve(entity_id=entity_id, query=query, limit=config.limit),
+            )
+            return
+        entity_id, content, event_type = corpus.memory(rng)
+        _timed(
+            mixed_ingest,
+            lambda: target.ingest(entity_id=entity_id, content=content, event_type=event_type),
+        )
+
+    report.elapsed_seconds["seed"] = _run_phase(
+        "seed",
+        config,
+        budget=config.entities * config.memories_per_entity,
+        duration_seconds=None,
+        operation=seed_operation,
+    )
+    report.elapsed_seconds["mixed"] = _run_phase(
+        "mixed",
+        config,
+        budget=None if config.duration_seconds is not None else config.operations,
+        duration_seconds=config.duration_seconds,
+        operation=mixed_operation,
+    )
+    for stats in (seed_ingest, mixed_retrieve, mixed_ingest):
+        if stats.latencies_ms or stats.errors:
+            report.operations.append(stats.summary(report.elapsed_seconds[stats.phase]))
+    return report
+
+
+def _run_phase(
+    phase: str,
+    config: BenchConfig,
+    *,
+    budget: int | None,
+    duration_seconds: float | None,
+    operation: Callable[[random.Random, int], None],
+) -> float:
+    if budget == 0:
+        return 0.0
+    next_index = itertools.count()
+    index_lock = threading.Lock()
+    started = perf_counter()
+    deadline = started + duration_seconds if duration_seconds is not None else None
+
+    def worker() -> None:
+        while True:
+            with index_lock:
+                index = next(next_index)
+            if budget is not None and index >= budget:
+                return
+            if deadline is not None and perf_counter() >= deadline:
+                return
+            # Seeding per operation keeps requests identical across runs whatever the
+            # interleaving of workers.
+            operation(random.Random(f"{config.seed}:{phase}:{index}"), index)
+
+    with ThreadPoolExecutor(
+        max_workers=config.concurrency,
+        thread_name_prefix="orbit-bench",
+    ) as executor:
+        for future in [executor.submit(worker) for _ in range(config.concurrency)]:
+            future.result()
+    return perf_counter() - started
+
+
+def _timed(stats: OperationStats, call: Callable[[], None]) -> None:
+    started = perf_counter()
+    try:
+        call()
+    except httpx.HTTPStatusError as exc:
+        stats.record_error(f"http_{exc.response.status_code}")
+        return
+    except Exception as exc:  # pylint: disable=broad-exception-caught
+        stats.record_error(type(exc).__name__)
+        return
+    stats.record((perf_counter() - started) * 1000.0)
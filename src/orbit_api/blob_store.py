@@ -0,0 +1,237 @@
+"""Object storage for large memory attachments (documents, images, audio)."""
+
+from __future__ import annotations
+
+import hashlib
+import re
+from dataclasses import dataclass
+from importlib import import_module
+from pathlib import Path
+from types import ModuleType
+from typing import Any, Protocol
+
+_KEY_SEGMENT_PATTERN = re.compile(r"[^A-Za-z0-9._-]+")
+
+
+def _optional_import(module_name: str) -> ModuleType | None:
+    try:  # pragma: no cover - optional dependency
+        return import_module(module_name)
+    except ImportError:  # pragma: no cover - optional dependency
+        return None
+
+
+boto3_module: ModuleType | None = _optional_import("boto3")
+gcs_module: ModuleType | None = _optional_import("google.cloud.storage")
+
+
+@dataclass(frozen=True)
+class BlobObject:
+    key: str
+    content_type: str
+    size_bytes: int
+    sha256: str
+
+
+class BlobStore(Protocol):
+    backend: str
+
+    def put(self, key: str, data: bytes, *, content_type: str) -> BlobObject: ...
+
+    def get(self, key: str) -> bytes: ...
+
+    def delete(self, key: str) -> None: ...
+
+
+class LocalBlobStore:
+    """Stores blobs on local disk; the default when no object store is configured."""
+
+    backend = "local"
+
+    def __init__(self, root: str | Path) -> None:
+        self._root = Path(root)
+
+    def put(self, key: str, data: bytes, *, content_type: str) -> BlobObject:
+        path = self._path_for(key)
+        path.parent.mkdir(parents=True, exist_ok=True)
+        tmp_path = path.with_suffix(path.suffix + ".tmp")
+        tmp_path.write_bytes(data)
+        tmp_path.replace(path)
+        return _blob_object(key, data, content_type)
+
+    def get(self, key: str) -> bytes:
+        path = self._path_for(key)
+        if not path.exists():
+            msg = f"blob not found: {key}"
+            raise KeyError(msg)
+        return path.read_bytes()
+
+    def delete(self, key: str) -> None:
+        path = self._path_for(key)
+        if path.exists():
+            path.unlink()
+
+    def _path_for(self, key: str) -> Path:
+        resolved_root = self._root.resolve()
+        path = (resolved_root / key).resolve()
+        if resolved_root not in path.parents:
+            msg = "blob key escapes storage root"
+            raise ValueError(msg)
+        return path
+
+
+class S3BlobStore:
+    """Stores blobs in any S3-compatible object store (AWS S3, MinIO, R2, GCS interop)."""
+
+    backend = "s3"
+
+    def __init__(
+        self,
+        bucket: str,
+        *,
+        prefix: str = "",
+        endpoint_url: str | None = None,
+        region: str | None = None,
+        client: Any | None = None,
+    ) -> None:
+        if not bucket.strip():
+            msg = "bucket cannot be empty"
+            raise ValueError(msg)
+        self._bucket = bucket.strip()
+        self._prefix = prefix.strip().strip("/")
+        if client is None:
+            if boto3_module is None:
+                msg = (
+                    "S3 blob storage requires boto3. "
+                    "Install with: pip install orbit-memory[s3]"
+                )
+                raise RuntimeError(msg)
+            client = boto3_module.client(
+                "s3",
+                endpoint_url=endpoint_url,
+                region_name=region,
+            )
+        self._client = client
+
+    def put(self, key: str, data: bytes, *, content_type: str) -> BlobObject:
+        self._client.put_object(
+            Bucket=self._bucket,
+            Key=self._object_key(key),
+            Body=data,
+            ContentType=content_type,
+        )
+        return _blob_object(key, data, content_type)
+
+    def get(self, key: str) -> bytes:
+        try:
+            response = self._client.get_object(
+                Bucket=self._bucket,
+                Key=self._object_key(key),
+            )
+        except Exception as exc:
+            if _is_missing_object_error(exc):
+                msg = f"blob not found: {key}"
+                raise KeyError(msg) from exc
+            raise
+        return bytes(response["Body"].read())
+
+    def delete(self, key: str) -> None:
+        self._client.delete_object(Bucket=self._bucket, Key=self._object_key(key))
+
+    def _object_key(self, key: str) -> str:
+        return f"{self._prefix}/{key}" if self._prefix else key
+
+
+class GcsBlobStore:
+    """Stores blobs in Google Cloud Storage using application default credentials."""
+
+    backend = "gcs"
+
+    def __init__(self, bucket: str, *, prefix: str = "", client: Any | None = None) -> None:
+        if client is None:
+            if gcs_module is None:
+                msg = (
+                    "GCS blob storage requires google-cloud-storage. "
+                    "Install with: pip install orbit-memory[gcs]"
+                )
+                raise RuntimeError(msg)
+            client = gcs_module.Client()
+        self._bucket = client.bucket(bucket)
+        self._prefix = prefix.strip().strip("/")
+
+    def put(self, key: str, data: bytes, *, content_type: str) -> BlobObject:
+        blob = self._bucket.blob(self._object_key(key))
+        blob.upload_from_string(data, content_type=content_type)
+        return _blob_object(key, data, content_type)
+
+    def get(self, key: str) -> bytes:
+        blob = self._bucket.blob(self._object_key(key))
+        if not blob.exists():
+            msg = f"blob not found: {key}"
+            raise KeyError(msg)
+        return bytes(blob.download_as_bytes())
+
+    def delete(self, key: str) -> None:
+        self._bucket.blob(self._object_key(key)).delete()
+
+    def _object_key(self, key: str) -> str:
+        return f"{self._prefix}/{key}" if self._prefix else key
+
+
+def build_blob_store(
+    backend: str,
+    *,
+    local_path: str,
+    s3_bucket: str | None = None,
+    s3_prefix: str = "",
+    s3_endpoint_url: str | None = None,
+    s3_region: str | None = None,
+) -> BlobStore:
+    normalized = backend.strip().lower()
+    if normalized == "s3":
+        if not s3_bucket:
+            msg = "ORBIT_BLOB_S3_BUCKET is required when ORBIT_BLOB_STORE_BACKEND=s3"
+            raise ValueError(msg)
+        return S3BlobStore(
+            s3_bucket,
+            prefix=s3_prefix,
+            endpoint_url=s3_endpoint_url,
+            region=s3_region,
+        )
+    return LocalBlobStore(local_path)
+
+
+def blob_key(account_key: str, memory_ref: str, filename: str | None = None) -> str:
+    """Build a tenant-scoped object key that is safe for disk and S3."""
+    segments = [_safe_segment(account_key), _safe_segment(memory_ref)]
+    if filename:
+        segments.append(_safe_segment(filename))
+    else:
+        segments.append("blob")
+    return "/".join(segments)
+
+
+def blob_key_belongs_to(key: str, account_key: str) -> bool:
+    """Whether ``key`` sits under ``account_key``'s segment, as ``blob_key`` builds it."""
+    return key.startswith(f"{_safe_segment(account_key)}/")
+
+
+def _safe_segment(value: str) -> str:
+    cleaned = _KEY_SEGMENT_PATTERN.sub("_", value.strip()).strip("._")
+    return cleaned[:128] or "_"
+
+
+def _blob_object(key: str, data: bytes, content_type: str) -> BlobObject:
+    return BlobObject(
+        key=key,
+        content_type=content_type,
+        size_bytes=len(data),
+        sha256=hashlib.sha256(data).hexdigest(),
+    )
+
+
+def _is_missing_object_error(exc: Exception) -> bool:
+    response = getattr(exc, "response", None)
+    if not isinstance(response, dict):
+        return False
+    code = str(response.get("Error", {}).get("Code", ""))
+    return code in {"NoSuchKey", "404", "NotFound"}
@@ -0,0 +1,71 @@
+"""Sort memories into a configurable category taxonomy at ingest.
+
+A taxonomy maps each category to cue phrases. A memory goes into the category whose cues
+match its content most often; ties go to the category listed first, and content with no cue
+at all is ``other``. The category is stored as a ``category:<name>`` relationship, which
+retrieval filters on and weights by.
+
+``ORBIT_MEMORY_CATEGORIES`` replaces the default taxonomy, e.g.
+``goal=want to|plan to|goal,constraint=allergic|must not|budget``.
+"""
+
+from __future__ import annotations
+
+import re
+from collections.abc import Mapping, Sequence
+from functools import lru_cache
+
+UNCATEGORIZED = "other"
+DEFAULT_TAXONOMY: dict[str, tuple[str, ...]] = {
+    "preference": tuple(
+        "prefer|prefers|preferred|like|likes|love|loves|favorite|favourite|enjoy|enjoys|"
+        "dislike|dislikes|hate|hates|rather".split("|")
+    ),
+    "biographical": tuple(
+        "lives in|live in|born|works as|work as|works at|work at|my name|years old|married|"
+        "moved to|grew up|studied|speaks|from".split("|")
+    ),
+    "goal": tuple(
+        "want to|wants to|plan to|plans to|planning|goal|hope to|trying to|aim to|"
+        "would like to|target|learn".split("|")
+    ),
+    "constraint": tuple(
+        "allergic|allergy|cannot|can't|must not|never|only|budget|deadline|avoid|vegetarian|"
+        "vegan|not allowed|limit".split("|")
+    ),
+    "event": tuple(
+        "yesterday|today|tomorrow|last week|meeting|booked|visited|attended|called|happened|"
+        "on monday|on friday|scheduled|went".split("|")
+    ),
+}
+
+
+def parse_taxonomy(value: str) -> dict[str, tuple[str, ...]]:
+    """Parse ``goal=want to|plan to,constraint=allergic|budget`` into category -> cues."""
+    taxonomy: dict[str, tuple[str, ...]] = {}
+    for item in value.split(","):
+        if not item.strip():
+            continue
+        name, separator, cues = item.partition("=")
+        phrases = tuple(cue.strip().lower() for cue in cues.split("|") if cue.strip())
+        if not separator or not name.strip() or not phrases:
+            msg = f"invalid memory category entry: {item.strip()!r}"
+            raise ValueError(msg)
+        taxonomy[name.strip().lower()] = phrases
+    return taxonomy
+
+
+def categorize(text: str, taxonomy: Mapping[str, Sequence[str]]) -> str:
+    """Return the category of ``text`` under ``taxonomy``."""
+    lowered = text.lower()
+    best, best_hits = UNCATEGORIZED, 0
+    for category, cues in taxonomy.items():
+        hits = sum(len(_cue_pattern(cue).findall(lowered)) for cue in cues)
+        if hits > best_hits:
+            best, best_hits = category, hits
+    return best
+
+
+@lru_cache(maxsize=1024)
+def _cue_pattern(cue: str) -> re.Pattern[str]:
+    return re.compile(rf"(?<!\w){re.escape(cue)}(?!\w)")
@@ -0,0 +1,238 @@
+"""OpenAI-compatible chat completions proxy that adds Orbit memory to every call."""
+
+from __future__ import annotations
+
+import copy
+import json
+from collections.abc import AsyncIterator
+from typing import Any
+
+import httpx
+from starlette.concurrency import run_in_threadpool
+
+from orbit.logger import get_logger
+from orbit.models import IngestRequest, RetrieveRequest
+from orbit_api.service import OrbitApiService, RateLimitExceededError
+from orbit_api.tracing import outbound_headers
+
+USER_EVENT_TYPE = "user_question"
+ASSISTANT_EVENT_TYPE = "assistant_response"
+MEMORY_PREAMBLE = "Relevant memories about this user (from Orbit):"
+
+
+class UpstreamError(RuntimeError):
+    """Raised when the upstream completion endpoint fails or returns non-2xx."""
+
+    def __init__(self, status_code: int, body: Any) -> None:
+        super().__init__(f"upstream returned {status_code}")
+        self.status_code = status_code
+        self.body = body
+
+
+class ChatMemoryProxy:
+    """Retrieve → inject into the system prompt → forward → ingest the new turn."""
+
+    def __init__(
+        self,
+        service: OrbitApiService,
+        *,
+        upstream_base_url: str,
+        upstream_api_key: str | None = None,
+        memory_limit: int = 5,
+        timeout_seconds: float = 60.0,
+        transport: httpx.AsyncBaseTransport | None = None,
+    ) -> None:
+        self._service = service
+        self._upstream_base_url = upstream_base_url.rstrip("/")
+        self._upstream_api_key = upstream_api_key
+        self._memory_limit = memory_limit
+        self._timeout_seconds = timeout_seconds
+        self._transport = transport
+        self._log = get_logger("orbit.api.chat_proxy")
+
+    def augment(
+        self,
+        body: dict[str, Any],
+        *,
+        account_key: str,
+        entity_id: str,
+        max_sensitivity: str | None = None,
+    ) -> tuple[dict[str, Any], str | None]:
+        """Return ``(augmented_body, last_user_message)``; the input is not mutated."""
+        augmented = copy.deepcopy(body)
+        messages = augmented.get("messages")
+        if not isinstance(messages, list) or not messages:
+            msg = "messages must be a non-empty list"
+            raise ValueError(msg)
+        user_message = last_user_message(messages)
+        if user_message is None:
+            return augmented, None
+        self._service.consume_query_quota(account_key=account_key, amount=1)
+        result = self._service.retrieve(
+            RetrieveRequest(query=user_message, limit=self._memory_limit, entity_id=entity_id),
+            account_key=account_key,
+            max_sensitivity=max_sensitivity,
+        )
+        if result.memories:
+            memory_block = "\n".join(
+                [MEMORY_PREAMBLE, *[f"- {memory.content}" for memory in result.memories]]
+            )
+            first = messages[0]
+            if (
+                isinstance(first, dict)
+                and first.get("role") == "system"
+                and isinstance(first.get("content"), str)
+            ):
+                first["content"] = f"{first['content']}\n\n{memory_block}"
+            else:
+                messages.insert(0, {"role": "system", "content": memory_block})
+        return augmented, user_message
+
+    async def complete(
+        self,
+        augmented: dict[str, Any],
+        *,
+        user_message: str | None,
+        account_key: str,
+        entity_id: str,
+    ) -> Any:
+        """Forward an already-augmented non-streaming request and ingest the turn."""
+        body = {**augmented, "stream": False}
+        async with self._client() as client:
+            try:
+                response = await client.post("/chat/completions", json=body)
+            except httpx.HTTPError as exc:
+                raise UpstreamError(502, {"error": {"message": str(exc)}}) from exc
+        payload = _json_or_text(response)
+        if response.status_code >= 400:
+            raise UpstreamError(response.status_code, payload)
+        await run_in_threadpool(
+            self.record_turn,
+            account_key=account_key,
+            entity_id=entity_id,
+            user_message=user_message,
+            assistant_message=_completion_text(payload),
+        )
+        return payload
+
+    async def stream(
+        self,
+        augmented: dict[str, Any],
+        *,
+        user_message: str | None,
+        account_key: str,
+        entity_id: str,
+    ) -> AsyncIterator[bytes]:
+        """Relay upstream SSE chunks; upstream failures become a final ``error`` event."""
+        body = {**augmented, "stream": True}
+        parts: list[str] = []
+        try:
+            async with self._client() as client:
+                async with client.stream("POST", "/chat/completions", json=body) as response:
+                    if response.status_code >= 400:
+                        raw = (await response.aread()).decode("utf-8", "replace")
+                        yield _error_event(f"upstream returned {response.status_code}: {raw}")
+                        return
+                    async for line in response.aiter_lines():
+                        parts.append(_delta_text(line))
+                        yield f"{line}\n".encode()
+        except httpx.HTTPError as exc:
+            yield _error_event(str(exc))
+            return
+        await run_in_threadpool(
+            self.record_turn,
+            account_key=account_key,
+            entity_id=entity_id,
+            user_message=user_message,
+            assistant_message="".join(parts),
+        )
+
+    def record_turn(
+        self,
+        *,
+        account_key: str,
+        entity_id: str,
+        user_message: str | None,
+        assistant_message: str | None,
+    ) -> None:
+        turns = [
+            (USER_EVENT_TYPE, user_message),
+            (ASSISTANT_EVENT_TYPE, assistant_message),
+        ]
+        for event_type, content in turns:
+            if not content or not content.strip():
+                continue
+            try:
+                self._service.ingest_with_quota(
+                    account_key=account_key,
+                    request=IngestRequest(
+                        content=content,
+                        event_type=event_type,
+                        entity_id=entity_id,
+                        metadata={"source": "chat_proxy"},
+                    ),
+                    idempotency_key=None,
+                )
+            except RateLimitExceededError:
+                self._log.warning("chat_proxy_ingest_rate_limited", account=account_key)
+                return
+
+    def _client(self) -> httpx.AsyncClient:
+        headers = {"Content-Type": "application/json", **outbound_headers()}
+        if self._upstream_api_key:
+            headers["Authorization"] = f"Bearer {self._upstream_api_key}"
+        return httpx.AsyncClient(
+            base_url=self._upstream_base_url,
+            headers=headers,
+            timeout=self._timeout_seconds,
+            transport=self._transport,
+        )
+
+
+def last_user_message(messages: list[Any]) -> str | None:
+    for message in reversed(messages):
+        if not isinstance(message, dict) or message.get("role") != "user":
+            continue
+        content = message.get("content")
+        if isinstance(content, str):
+            return content.strip() or None
+        if isinstance(content, list):
+            text = " ".join(
+                str(part.get("text", ""))
+                for part in content
+                if isinstance(part, dict) and part.get("type") == "text"
+            ).strip()
+            return text or None
+    return None
+
+
+def _completion_text(payload: Any) -> str | None:
+    try:
+        content = payload["choices"][0]["message"]["content"]
+    except (KeyError, IndexError, TypeError):
+        return None
+    return content if isinstance(content, str) else None
+
+
+def _delta_text(line: str) -> str:
+    if not line.startswith("data:"):
+        return ""
+    data = line[len("data:") :].strip()
+    if not data or data == "[DONE]":
+        return ""
+    try:
+        content = json.loads(data)["choices"][0]["delta"].get("content")
+    except (json.JSONDecodeError, KeyError, IndexError, TypeError, AttributeError):
+        return ""
+    return content if isinstance(content, str) else ""
+
+
+def _error_event(message: str) -> bytes:
+    return f"data: {json.dumps({'error': {'message': message}})}\n\n".encode()
+
+
+def _json_or_text(response: httpx.Response) -> Any:
+    try:
+        return response.json()
+    except ValueError:
+        return response.text
@@ -0,0 +1,863 @@
+"""Operator CLI for self-hosted Orbit deployments."""
+
+from __future__ import annotations
+
+import argparse
+import json
+import os
+import time
+from collections.abc import Sequence
+from datetime import datetime
+from pathlib import Path
+from typing import TYPE_CHECKING
+
+from decision_engine.database_url import normalize_database_url
+from orbit.models import SENSITIVITY_LEVELS
+from orbit.secret_sources import get_secret
+from orbit_api import backup, migrations
+from orbit_api.blob_store import BlobStore, build_blob_store
+
+if TYPE_CHECKING:
+    from orbit_api.stream_connector import MessageSource
+    from orbit_api.warehouse_sync import WarehouseSink
+
+
+class _SecretDefault(str):
+    """Argparse default naming a secret; ``main`` resolves it only once parsing succeeds."""
+
+
+def build_parser() -> argparse.ArgumentParser:
+    parser = argparse.ArgumentParser(prog="orbit", description="Orbit operator commands.")
+    subcommands = parser.add_subparsers(dest="command", required=True)
+
+    migrate = subcommands.add_parser("migrate", help="Apply database schema migrations.")
+    migrate.add_argument(
+        "--revision",
+        default="head",
+        help="Target revision (default: head).",
+    )
+    migrate.add_argument(
+        "--downgrade",
+        action="store_true",
+        help="Downgrade to --revision instead of upgrading.",
+    )
+    migrate.add_argument(
+        "--current",
+        action="store_true",
+        help="Print the current revision and exit.",
+    )
+    migrate.add_argument(
+        "--sql",
+        action="store_true",
+        help="Emit SQL instead of executing it (offline mode).",
+    )
+    migrate.add_argument(
+        "--database-url",
+        default=None,
+        help="Override MDE_DATABASE_URL for this run.",
+    )
+    migrate.set_defaults(handler=_run_migrate)
+
+    backup_parser = subcommands.add_parser(
+        "backup",
+        help="Create, list, or restore backups (DB + vector index + blobs).",
+    )
+    backup_commands = backup_parser.add_subparsers(dest="backup_command", required=True)
+    backup_create = backup_commands.add_parser("create", help="Create a backup.")
+    backup_create.add_argument(
+        "--incremental",
+        action="store_true",
+        help="Only capture changes since the newest existing backup.",
+    )
+    backup_create.set_defaults(handler=_run_backup_create)
+    backup_list = backup_commands.add_parser("list", help="List available backups.")
+    backup_list.set_defaults(handler=_run_backup_list)
+    backup_restore = backup_commands.add_parser(
+        "restore",
+        help="Restore a backup, replacing current data.",
+    )
+    backup_restore.add_argument("--backup-id", default=None)
+    backup_restore.add_argument(
+        "--to-timestamp",
+        default=None,
+        help="Restore the newest backup taken at or before this ISO-8601 time.",
+    )
+    backup_restore.add_argument(
+        "--yes",
+        action="store_true",
+        help="Confirm that current data will be replaced.",
+    )
+    backup_restore.set_defaults(handler=_run_backup_restore)
+    for command_parser in (backup_create, backup_list, backup_restore):
+        command_parser.add_argument(
+            "--dir",
+            default=os.getenv("ORBIT_BACKUP_DIR", "backups"),
+            help="Backup root directory (default: ORBIT_BACKUP_DIR or ./backups).",
+        )
+        command_parser.add_argument("--database-url", default=None)
+
+    replicate = subcommands.add_parser(
+        "replicate",
+        help="Pull changes for tenants this region replicates from their home regions.",
+    )
+    replicate.add_argument(
+        "--interval",
+        type=float,
+        default=float(os.getenv("ORBIT_REPLICATION_INTERVAL_SECONDS", "10")),
+        help="Seconds between sync rounds (default: 10).",
+    )
+    replicate.add_argument("--once", action="store_true", help="Sync once and exit.")
+    replicate.set_defaults(handler=_run_replicate)
+
+    optimize = subcommands.add_parser(
+        "optimize",
+        help="Compact the vector index and vacuum-analyze storage tables.",
+    )
+    optimize.add_argument(
+        "--interval",
+        type=float,
+        default=float(os.getenv("ORBIT_OPTIMIZE_INTERVAL_HOURS", "24")),
+        help="Hours between runs (default: 24).",
+    )
+    optimize.add_argument("--once", action="store_true", help="Run once and exit.")
+    optimize.set_defaults(handler=_run_optimize)
+
+    export = subcommands.add_parser(
+        "export",
+        help="Run scheduled tenant exports to object storage as they come due.",
+    )
+    export.add_argument(
+        "--interval",
+        type=float,
+        default=float(os.getenv("ORBIT_EXPORT_POLL_SECONDS", "60")),
+        help="Seconds between checks for due exports (default: 60).",
+    )
+    export.add_argument("--once", action="store_true", help="Run due exports once and exit.")
+    export.set_defaults(handler=_run_export)
+
+    crawl = subcommands.add_parser(
+        "crawl",
+        help="Re-crawl URL sources as they come due and revise their changed chunks.",
+    )
+    crawl.add_argument(
+        "--interval",
+        type=float,
+        default=float(os.getenv("ORBIT_CRAWL_POLL_SECONDS", "300")),
+        help="Seconds between checks for due URL sources (default: 300).",
+    )
+    crawl.add_argument("--once", action="store_true", help="Run due crawls once and exit.")
+    crawl.set_defaults(handler=_run_crawl)
+
+    retention = subcommands.add_parser(
+        "retention",
+        help="Delete memories older than their tenant's retention policy.",
+    )
+    retention.add_argument(
+        "--interval",
+        type=float,
+        default=float(os.getenv("ORBIT_RETENTION_INTERVAL_HOURS", "24")),
+        help="Hours between runs (default: 24).",
+    )
+    retention.add_argument("--once", action="store_true", help="Run once and exit.")
+    retention.set_defaults(handler=_run_retention)
+
+    resurface = subcommands.add_parser(
+        "resurface",
+        help="Send important memories nobody has retrieved lately to the resurface webhook.",
+    )
+    resurface.add_argument(
+        "--interval",
+        type=float,
+        default=float(os.getenv("ORBIT_RESURFACE_INTERVAL_HOURS", "24")),
+        help="Hours between runs (default: 24).",
+    )
+    resurface.add_argument("--once", action="store_true", help="Run once and exit.")
+    resurface.set_defaults(handler=_run_resurface)
+
+    expire = subcommands.add_parser(
+        "expire",
+        help="Wipe sandbox namespaces whose TTL has run out.",
+    )
+    expire.add_argument(
+        "--interval",
+        type=float,
+        default=float(os.getenv("ORBIT_EXPIRE_INTERVAL_SECONDS", "60")),
+        help="Seconds between runs (default: 60).",
+    )
+    expire.add_argument("--once", action="store_true", help="Run once and exit.")
+    expire.set_defaults(handler=_run_expire)
+
+    operator = subcommands.add_parser(
+        "operator",
+        help="Reconcile OrbitCluster, OrbitNamespace, and OrbitAPIKey resources on Kubernetes.",
+    )
+    operator.add_argument(
+        "--namespace",
+        default=os.getenv("ORBIT_OPERATOR_NAMESPACE") or None,
+        help="Only watch this Kubernetes namespace (default: all namespaces).",
+    )
+    operator.add_argument(
+        "--interval",
+        type=float,
+        default=float(os.getenv("ORBIT_OPERATOR_INTERVAL_SECONDS", "30")),
+        help="Seconds between reconcile passes (default: 30).",
+    )
+    operator.add_argument("--once", action="store_true", help="Reconcile once and exit.")
+    operator.set_defaults(handler=_run_operator)
+
+    sync_parser = subcommands.add_parser(
+        "sync",
+        help="Stream a tenant's memory changes into BigQuery or Snowflake tables.",
+    )
+    sync_commands = sync_parser.add_subparsers(dest="sync_command", required=True)
+    sync_bigquery = sync_commands.add_parser("bigquery", help="Sync into a BigQuery dataset.")
+    sync_bigquery.add_argument(
+        "--dataset",
+        default=os.getenv("ORBIT_BIGQUERY_DATASET"),
+        help="Target as project.dataset (default: ORBIT_BIGQUERY_DATASET).",
+    )
+    sync_bigquery.add_argument("--location", default=os.getenv("ORBIT_BIGQUERY_LOCATION"))
+    sync_bigquery.set_defaults(handler=_run_sync_bigquery)
+    sync_snowflake = sync_commands.add_parser("snowflake", help="Sync into a Snowflake schema.")
+    sync_snowflake.add_argument("--account", default=os.getenv("ORBIT_SNOWFLAKE_ACCOUNT"))
+    sync_snowflake.add_argument("--user", default=os.getenv("ORBIT_SNOWFLAKE_USER"))
+    sync_snowflake.add_argument(
+        "--password",
+        default=_SecretDefault("ORBIT_SNOWFLAKE_PASSWORD"),
+        help="Password (default: ORBIT_SNOWFLAKE_PASSWORD; prefer the env var).",
+    )
+    sync_snowflake.add_argument("--database", default=os.getenv("ORBIT_SNOWFLAKE_DATABASE"))
+    sync_snowflake.add_argument(
+        "--schema",
+        default=os.getenv("ORBIT_SNOWFLAKE_SCHEMA", "PUBLIC"),
+    )
+    sync_snowflake.add_argument("--warehouse", default=os.getenv("ORBIT_SNOWFLAKE_WAREHOUSE"))
+    sync_snowflake.add_argument("--role", default=os.getenv("ORBIT_SNOWFLAKE_ROLE"))
+    sync_snowflake.set_defaults(handler=_run_sync_snowflake)
+    for command_parser in (sync_bigquery, sync_snowflake):
+        command_parser.add_argument(
+            "--account-key",
+            default=os.getenv("ORBIT_SYNC_ACCOUNT_KEY", "default"),
+            help="Orbit account whose memories are synced (default: ORBIT_SYNC_ACCOUNT_KEY).",
+        )
+        command_parser.add_argument(
+            "--backfill",
+            action="store_true",
+            help="Copy every stored memory before syncing new changes.",
+        )
+        command_parser.add_argument(
+            "--batch-size",
+            type=int,
+            default=int(os.getenv("ORBIT_SYNC_BATCH_SIZE", "500")),
+            help="Changes written per batch (default: 500).",
+        )
+        command_parser.add_argument(
+            "--interval",
+            type=float,
+            default=float(os.getenv("ORBIT_SYNC_INTERVAL_SECONDS", "60")),
+            help="Seconds between sync rounds (default: 60).",
+        )
+        command_parser.add_argument("--once", action="store_true", help="Sync once and exit.")
+
+    bench = subcommands.add_parser(
+        "bench",
+        help="Load-test ingest and retrieve with synthetic entities and memories.",
+    )
+    bench.add_argument(
+        "--url",
+        default=None,
+        help="Benchmark a running deployment at this base URL (default: in-process service).",
+    )
+    bench.add_argument(
+        "--token",
+        default=_SecretDefault("ORBIT_API_KEY"),
+        help="API key or JWT for --url (default: ORBIT_API_KEY).",
+    )
+    bench.add_argument(
+        "--account-key",
+        default="bench",
+        help="Account that owns in-process benchmark memories (default: bench).",
+    )
+    bench.add_argument("--entities", type=int, default=100)
+    bench.add_argument(
+        "--memories-per-entity",
+        type=int,
+        default=10,
+        help="Memories ingested per entity before the mixed phase (default: 10).",
+    )
+    bench.add_argument(
+        "--operations",
+        type=int,
+        default=1000,
+        help="Calls in the mixed phase (default: 1000).",
+    )
+    bench.add_argument(
+        "--duration",
+        type=float,
+        default=None,
+        help="Run the mixed phase for this many seconds instead of --operations.",
+    )
+    bench.add_argument("--concurrency", type=int, default=8)
+    bench.add_argument(
+        "--read-ratio",
+        type=float,
+        default=0.8,
+        help="Share of mixed-phase calls that are retrieves (default: 0.8).",
+    )
+    bench.add_argument("--limit", type=int, default=5, help="Retrieve limit (default: 5).")
+    bench.add_argument(
+        "--fast",
+        action="store_true",
+        help="Send retrieves with fast=true, the low-latency path for voice agents.",
+    )
+    bench.add_argument("--seed", type=int, default=0)
+    bench.add_argument("--json", action="store_true", help="Print the report as JSON.")
+    bench.set_defaults(handler=_run_bench)
+
+    connect_parser = subcommands.add_parser(
+        "connect",
+        help="Ingest events directly from a Kafka topic or NATS subject.",
+    )
+    connect_commands = connect_parser.add_subparsers(dest="connect_command", required=True)
+    connect_kafka = connect_commands.add_parser("kafka", help="Consume a Kafka topic.")
+    connect_kafka.add_argument("--brokers", required=True)
+    connect_kafka.add_argument("--topic", required=True)
+    connect_kafka.add_argument("--group-id", default="orbit-connector")
+    connect_kafka.set_defaults(handler=_run_connect_kafka)
+    connect_nats = connect_commands.add_parser("nats", help="Subscribe to a NATS subject.")
+    connect_nats.add_argument("--servers", required=True)
+    connect_nats.add_argument("--subject", required=True)
+    connect_nats.add_argument("--queue-group", default="orbit-connector")
+    connect_nats.set_defaults(handler=_run_connect_nats)
+    connect_discord = connect_commands.add_parser(
+        "discord",
+        help="Run a Discord bot that ingests channel messages and answers /recall.",
+    )
+    connect_discord.add_argument(
+        "--token",
+        default=_SecretDefault("ORBIT_DISCORD_BOT_TOKEN"),
+        help="Bot token (default: ORBIT_DISCORD_BOT_TOKEN).",
+    )
+    connect_discord.add_argument(
+        "--channel-ids",
+        default=os.getenv("ORBIT_DISCORD_CHANNEL_IDS", ""),
+        help="Comma-separated channel IDs to ingest (default: ORBIT_DISCORD_CHANNEL_IDS).",
+    )
+    connect_discord.add_argument(
+        "--guild-ids",
+        default=os.getenv("ORBIT_DISCORD_GUILD_IDS", ""),
+        help="Register /recall on these guilds only (faster than global sync).",
+    )
+    connect_discord.add_argument(
+        "--account-key",
+        default=os.getenv("ORBIT_DISCORD_ACCOUNT_KEY", "default"),
+        help="Orbit account that owns the ingested memories.",
+    )
+    connect_discord.add_argument(
+        "--recall-across-users",
+        action="store_true",
+        help="Let /recall search every user's memories, not just the caller's.",
+    )
+    connect_discord.add_argument(
+        "--recall-max-sensitivity",
+        choices=SENSITIVITY_LEVELS,
+        default=os.getenv("ORBIT_DISCORD_RECALL_MAX_SENSITIVITY", "public"),
+        help="Most restricted memory label /recall may post (default: public).",
+    )
+    connect_discord.set_defaults(handler=_run_connect_discord)
+    connect_imap = connect_commands.add_parser(
+        "imap",
+        help="Poll an IMAP mailbox and ingest unseen messages under the sender entity.",
+    )
+    connect_imap.add_argument("--host", default=os.getenv("ORBIT_IMAP_HOST"))
+    connect_imap.add_argument("--port", type=int, default=int(os.getenv("ORBIT_IMAP_PORT", "993")))
+    connect_imap.add_argument("--username", default=os.getenv("ORBIT_IMAP_USERNAME"))
+    connect_imap.add_argument(
+        "--password",
+        default=_SecretDefault("ORBIT_IMAP_PASSWORD"),
+        help="Mailbox password (default: ORBIT_IMAP_PASSWORD; prefer the env var).",
+    )
+    connect_imap.add_argument("--mailbox", default=os.getenv("ORBIT_IMAP_MAILBOX", "INBOX"))
+    connect_imap.add_argument("--no-ssl", action="store_true", help="Use plain IMAP (port 143).")
+    connect_imap.add_argument(
+        "--account-key",
+        default=os.getenv("ORBIT_EMAIL_ACCOUNT_KEY", "default"),
+        help="Orbit account that owns the ingested memories.",
+    )
+    connect_imap.add_argument(
+        "--interval",
+        type=float,
+        default=60.0,
+        help="Seconds between polls (default: 60).",
+    )
+    connect_imap.add_argument("--once", action="store_true", help="Poll once and exit.")
+    connect_imap.set_defaults(handler=_run_connect_imap)
+    for command_parser in (connect_kafka, connect_nats):
+        command_parser.add_argument(
+            "--mapping",
+            default=os.getenv("ORBIT_CONNECTOR_MAPPING"),
+            help="Schema mapping as JSON or @path (default: ORBIT_CONNECTOR_MAPPING).",
+        )
+        command_parser.add_argument(
+            "--max-messages",
+            type=int,
+            default=None,
+            help="Stop after this many messages (default: run until interrupted).",
+        )
+    return parser
+
+
+def main(argv: Sequence[str] | None = None) -> int:
+    parser = build_parser()
+    args = parser.parse_args(argv)
+    # Resolved after parsing so ``--help`` and commands that never use a secret do not read
+    # secret files or contact Vault.
+    for name, value in vars(args).items():
+        if isinstance(value, _SecretDefault):
+            setattr(args, name, get_secret(str(value)))
+    try:
+        args.handler(args)
+    except ValueError as exc:
+        parser.error(str(exc))
+    return 0
+
+
+def _run_migrate(args: argparse.Namespace) -> None:
+    if args.current:
+        migrations.current(database_url=args.database_url)
+        return
+    if args.downgrade:
+        if args.revision == "head":
+            msg = "--downgrade requires an explicit --revision"
+            raise ValueError(msg)
+        migrations.downgrade(args.revision, database_url=args.database_url, sql=args.sql)
+        return
+    migrations.upgrade(args.revision, database_url=args.database_url, sql=args.sql)
+
+
+def _run_backup_create(args: argparse.Namespace) -> None:
+    manifest = backup.create_backup(
+        args.dir,
+        database_url=_database_url(args.database_url),
+        vector_index_path=_vector_index_path(),
+        blob_store=_blob_store(),
+        incremental=args.incremental,
+    )
+    rows = sum(manifest.tables.values())
+    print(f"{manifest.backup_id} {manifest.kind} rows={rows} blobs={manifest.blobs}")
+
+
+def _run_backup_list(args: argparse.Namespace) -> None:
+    for manifest in backup.list_backups(args.dir):
+        parent = manifest.parent_id or "-"
+        print(
+            f"{manifest.backup_id} {manifest.kind} "
+            f"created_at={manifest.created_at.isoformat()} parent={parent}"
+        )
+
+
+def _run_backup_restore(args: argparse.Namespace) -> None:
+    if not args.yes:
+        msg = "restore replaces all current data; re-run with --yes to confirm"
+        raise ValueError(msg)
+    to_timestamp = (
+        datetime.fromisoformat(args.to_timestamp) if args.to_timestamp else None
+    )
+    manifest = backup.restore_backup(
+        args.dir,
+        database_url=_database_url(args.database_url),
+        backup_id=args.backup_id,
+        to_timestamp=to_timestamp,
+        vector_index_path=_vector_index_path(),
+        blob_store=_blob_store(),
+    )
+    print(f"restored {manifest.backup_id} ({manifest.kind})")
+
+
+def _run_replicate(args: argparse.Namespace) -> None:
+    from orbit_api.service import OrbitApiService
+
+    service = OrbitApiService()
+    try:
+        if service.config.region is None or not service.config.region_peers:
+            msg = "ORBIT_REGION and ORBIT_REGION_PEERS are required for replication"
+            raise ValueError(msg)
+        while True:
+            applied = service.replicate_once()
+            print(f"tenants={len(applied)} applied={sum(applied.values())}")
+            if args.once:
+                return
+            try:
+                time.sleep(args.interval)
+            except KeyboardInterrupt:
+                return
+    finally:
+        service.close()
+
+
+def _run_optimize(args: argparse.Namespace) -> None:
+    from orbit_api.service import OrbitApiService
+
+    service = OrbitApiService()
+    try:
+        while True:
+            job = service.run_optimize(trigger="scheduled")
+            for step in job.steps:
+                print(f"{step.name} {step.status} {step.duration_ms or 0.0:.1f}ms {step.detail}")
+            if job.status == "failed":
+                msg = job.error or "optimize failed"
+                raise RuntimeError(msg)
+            if args.once:
+                return
+            try:
+                time.sleep(args.interval * 3600)
+            except KeyboardInterrupt:
+                return
+    finally:
+        service.close()
+
+
+def _run_export(args: argparse.Namespace) -> None:
+    from orbit_api.service import OrbitApiService
+
+    service = OrbitApiService()
+    try:
+        while True:
+            for job in service.run_due_exports():
+                detail = job.last_object_key if job.last_status == "succeeded" else job.last_error
+                print(f"{job.export_id} {job.last_status} rows={job.rows_exported} {detail or '-'}")
+            if args.once:
+                return
+            try:
+                time.sleep(args.interval)
+            except KeyboardInterrupt:
+                return
+    finally:
+        service.close()
+
+
+def _run_crawl(args: argparse.Namespace) -> None:
+    from orbit_api.service import OrbitApiService
+
+    service = OrbitApiService()
+    try:
+        while True:
+            for source in service.run_due_crawls():
+                detail = source.last_error if source.last_status == "failed" else source.url
+                print(f"{source.source_id} {source.last_status} {detail}")
+            if args.once:
+                return
+            try:
+                time.sleep(args.interval)
+            except KeyboardInterrupt:
+                return
+    finally:
+        service.close()
+
+
+def _run_retention(args: argparse.Namespace) -> None:
+    from orbit_api.service import OrbitApiService
+
+    service = OrbitApiService()
+    try:
+        while True:
+            deleted = service.apply_retention_policies()
+            for account_key, count in sorted(deleted.items()):
+                print(f"{account_key} deleted={count}")
+            print(f"tenants={len(deleted)} deleted={sum(deleted.values())}")
+            if args.once:
+                return
+            try:
+                time.sleep(args.interval * 3600)
+            except KeyboardInterrupt:
+                return
+    finally:
+        service.close()
+
+
+def _run_resurface(args: argparse.Namespace) -> None:
+    from orbit_api.service import OrbitApiService
+
+    service = OrbitApiService()
+    try:
+        if not service.config.resurface_webhook_url:
+            msg = "ORBIT_RESURFACE_WEBHOOK_URL must be set for orbit resurface"
+            raise RuntimeError(msg)
+        while True:
+            delivered = service.run_resurfacing()
+            for account_key, count in sorted(delivered.items()):
+                print(f"{account_key} resurfaced={count}")
+            print(f"tenants={len(delivered)} resurfaced={sum(delivered.values())}")
+            if args.once:
+                return
+            try:
+                time.sleep(args.interval * 3600)
+            except KeyboardInterrupt:
+                return
+    finally:
+        service.close()
+
+
+def _run_expire(args: argparse.Namespace) -> None:
+    from orbit_api.service import OrbitApiService
+
+    service = OrbitApiService()
+    try:
+        while True:
+            deleted = service.expire_namespaces()
+            for account_key, count in sorted(deleted.items()):
+                print(f"{account_key} wiped memories={count}")
+            print(f"namespaces={len(deleted)} deleted={sum(deleted.values())}")
+            if args.once:
+                return
+            try:
+                time.sleep(args.interval)
+            except KeyboardInterrupt:
+                return
+    finally:
+        service.close()
+
+
+def _run_operator(args: argparse.Namespace) -> None:
+    from orbit_api.kube_operator import KubernetesClient, OrbitOperator
+
+    operator = OrbitOperator(KubernetesClient(namespace=args.namespace))
+    while True:
+        counts = operator.reconcile_once()
+        print(" ".join(f"{state}={count}" for state, count in counts.items()))
+        if args.once:
+            return
+        try:
+            time.sleep(args.interval)
+        except KeyboardInterrupt:
+            return
+
+
+def _run_sync_bigquery(args: argparse.Namespace) -> None:
+    from orbit_api.warehouse_sync import BigQuerySink
+
+    if not args.dataset:
+        msg = "--dataset or ORBIT_BIGQUERY_DATASET is required"
+        raise ValueError(msg)
+    _run_warehouse_sync(args, BigQuerySink(args.dataset, location=args.location))
+
+
+def _run_sync_snowflake(args: argparse.Namespace) -> None:
+    from orbit_api.warehouse_sync import SnowflakeSink
+
+    if not args.account or not args.user or not args.password or not args.database:
+        msg = (
+            "--account, --user, --password and --database "
+            "(or ORBIT_SNOWFLAKE_* env vars) are required"
+        )
+        raise ValueError(msg)
+    connect_kwargs = {
+        "account": args.account,
+        "user": args.user,
+        "password": args.password,
+        "database": args.database,
+        "schema": args.schema,
+        "warehouse": args.warehouse,
+        "role": args.role,
+    }
+    sink = SnowflakeSink.connect(
+        **{key: value for key, value in connect_kwargs.items() if value is not None}
+    )
+    _run_warehouse_sync(args, sink)
+
+
+def _run_warehouse_sync(args: argparse.Namespace, sink: WarehouseSink) -> None:
+    from orbit_api.service import OrbitApiService
+    from orbit_api.warehouse_sync import WarehouseSync
+
+    service = OrbitApiService()
+    try:
+        sync = WarehouseSync(
+            service,
+            sink,
+            account_key=args.account_key,
+            batch_size=args.batch_size,
+        )
+        backfill = args.backfill
+        while True:
+            stats = sync.backfill() if backfill else sync.sync_once()
+            backfill = False
+            print(f"memories={stats.memories} changes={stats.changes} cursor={stats.cursor}")
+            if args.once:
+                return
+            try:
+                time.sleep(args.interval)
+            except KeyboardInterrupt:
+                return
+    finally:
+        service.close()
+
+
+def _run_bench(args: argparse.Namespace) -> None:
+    from orbit_api.bench import BenchConfig, HttpTarget, ServiceTarget, run_bench
+
+    config = BenchConfig(
+        entities=args.entities,
+        memories_per_entity=args.memories_per_entity,
+        operations=args.operations,
+        duration_seconds=args.duration,
+        concurrency=args.concurrency,
+        read_ratio=args.read_ratio,
+        limit=args.limit,
+        seed=args.seed,
+    )
+    if args.url:
+        if not args.token:
+            msg = "--token or ORBIT_API_KEY is required with --url"
+            raise ValueError(msg)
+        http_target = HttpTarget(args.url, token=args.token, fast=args.fast)
+        try:
+            report = run_bench(http_target, config)
+        finally:
+            http_target.close()
+    else:
+        from orbit_api.service import OrbitApiService
+
+        service = OrbitApiService()
+        try:
+            report = run_bench(
+                ServiceTarget(service, account_key=args.account_key, fast=args.fast),
+                config,
+            )
+        finally:
+            service.close()
+    if args.json:
+        print(json.dumps(report.as_dict(), indent=2))
+        return
+    print(report.format_table())
+
+
+def _run_connect_kafka(args: argparse.Namespace) -> None:
+    from orbit_api.stream_connector import KafkaSource
+
+    source = KafkaSource(brokers=args.brokers, topic=args.topic, group_id=args.group_id)
+    _run_connector(args, source)
+
+
+def _run_connect_nats(args: argparse.Namespace) -> None:
+    from orbit_api.stream_connector import NatsSource
+
+    source = NatsSource(
+        servers=args.servers,
+        subject=args.subject,
+        queue_group=args.queue_group,
+    )
+    _run_connector(args, source)
+
+
+def _run_connect_discord(args: argparse.Namespace) -> None:
+    from orbit_api.discord_bot import DiscordMemoryBridge, build_client
+    from orbit_api.service import OrbitApiService
+
+    if not args.token:
+        msg = "--token or ORBIT_DISCORD_BOT_TOKEN is required"
+        raise ValueError(msg)
+    channel_ids = _csv(args.channel_ids)
+    if not channel_ids:
+        msg = "--channel-ids or ORBIT_DISCORD_CHANNEL_IDS is required"
+        raise ValueError(msg)
+    service = OrbitApiService()
+    try:
+        bridge = DiscordMemoryBridge(
+            service,
+            account_key=args.account_key,
+            channel_ids=channel_ids,
+            recall_across_users=args.recall_across_users,
+            recall_max_sensitivity=args.recall_max_sensitivity,
+        )
+        build_client(bridge, guild_ids=_csv(args.guild_ids)).run(args.token)
+    finally:
+        service.close()
+
+
+def _run_connect_imap(args: argparse.Namespace) -> None:
+    from orbit_api.email_connector import EmailIngestor, ImapPoller
+    from orbit_api.service import OrbitApiService
+
+    if not args.host or not args.username or not args.password:
+        msg = "--host, --username and --password (or ORBIT_IMAP_* env vars) are required"
+        raise ValueError(msg)
+    service = OrbitApiService()
+    try:
+        poller = ImapPoller(
+            EmailIngestor(
+                service,
+                account_key=args.account_key,
+                max_content_chars=service.config.max_ingest_content_chars,
+            ),
+            host=args.host,
+            username=args.username,
+            password=args.password,
+            mailbox=args.mailbox,
+            port=args.port,
+            use_ssl=not args.no_ssl,
+        )
+        if args.once:
+            print(f"ingested={poller.poll_once()}")
+            return
+        try:
+            poller.run(interval_seconds=args.interval)
+        except KeyboardInterrupt:
+            pass
+    finally:
+        service.close()
+
+
+def _run_connector(args: argparse.Namespace, source: MessageSource) -> None:
+    from orbit_api.service import OrbitApiService
+    from orbit_api.stream_connector import SchemaMapping, StreamConnector
+
+    mapping = SchemaMapping()
+    if args.mapping:
+        raw = args.mapping
+        if raw.startswith("@"):
+            raw = Path(raw[1:]).read_text(encoding="utf-8")
+        mapping = SchemaMapping.from_json(raw)
+    service = OrbitApiService()
+    try:
+        connector = StreamConnector(service, source, mapping)
+        try:
+            stats = connector.run(max_messages=args.max_messages)
+        except KeyboardInterrupt:
+            stats = connector.stats
+    finally:
+        service.close()
+    print(
+        f"ingested={stats.ingested} replayed={stats.replayed} rejected={stats.rejected} "
+        f"source_errors={stats.source_errors}"
+    )
+
+
+def _csv(raw: str) -> list[str]:
+    return [item.strip() for item in raw.split(",") if item.strip()]
+
+
+def _database_url(override: str | None) -> str:
+    resolved = normalize_database_url(override or get_secret("MDE_DATABASE_URL"))
+    if resolved:
+        return resolved
+    return f"sqlite:///{os.getenv('MDE_SQLITE_PATH', 'memory.db')}"
+
+
+def _vector_index_path() -> Path:
+    return Path(os.getenv("MDE_SQLITE_PATH", "memory.db")).with_suffix(".idx")
+
+
+def _blob_store() -> BlobStore:
+    return build_blob_store(
+        os.getenv("ORBIT_BLOB_STORE_BACKEND", "local"),
+        local_path=os.getenv("ORBIT_BLOB_STORE_PATH", "blobs"),
+        s3_bucket=os.getenv("ORBIT_BLOB_S3_BUCKET") or None,
+        s3_prefix=os.getenv("ORBIT_BLOB_S3_PREFIX", "orbit"),
+        s3_endpoint_url=os.getenv("ORBIT_BLOB_S3_ENDPOINT_URL") or None,
+        s3_region=os.getenv("ORBIT_BLOB_S3_REGION") or None,
+    )
+
+
+if __name__ == "__main__":
+    raise SystemExit(main())
@@ -0,0 +1,183 @@
+"""Hot reload of non-structural ApiConfig settings from the server config file."""
+
+from __future__ import annotations
+
+import signal
+from collections.abc import Callable
+from pathlib import Path
+from threading import Event, RLock, Thread
+from typing import Any
+
+from orbit.logger import get_logger
+from orbit.secret_sources import clear_secret_cache
+from orbit_api.config import ApiConfig
+
+# Settings read per request through the shared ApiConfig instance. Everything else
+# (database, storage, CORS, rate-limit decorators, integrations) is wired at startup
+# and only changes on restart.
+RELOADABLE_FIELDS = frozenset(
+    {
+        "default_entity_id",
+        "default_event_type",
+        "free_events_per_day",
+        "free_queries_per_day",
+        "free_events_per_month",
+        "free_queries_per_month",
+        "free_api_keys",
+        "free_retention_days",
+        "pilot_pro_events_per_month",
+        "pilot_pro_queries_per_month",
+        "pilot_pro_api_keys",
+        "pilot_pro_retention_days",
+        "usage_warning_threshold_percent",
+        "usage_critical_threshold_percent",
+        "max_ingest_content_chars",
+        "on_oversize",
+        "oversize_summary_chars",
+        "max_batch_items",
+        "allowed_event_types",
+        "max_retrieve_batch_queries",
+        "strong_consistency_timeout_ms",
+        "max_entity_attributes",
+        "topic_refresh_seconds",
+        "topic_min_cluster_size",
+        "zero_result_fallback",
+        "query_analytics_enabled",
+        "query_analytics_retention_days",
+        "moderation_policy",
+        "pipeline_stages",
+        "pipeline_namespaces",
+        "dedup_window_days",
+        "export_max_rows",
+        "change_feed_settle_seconds",
+        "default_sensitivity",
+        "max_attachment_bytes",
+        "uptime_percent",
+        "metadata_summary_window",
+        "dashboard_auto_provision_accounts",
+        "pilot_pro_resend_api_key",
+        "pilot_pro_request_admin_email",
+        "pilot_pro_request_from_email",
+        "pilot_pro_email_timeout_seconds",
+        "allow_query_api_key",
+        "admin_dashboard_enabled",
+        "operator_tokens",
+        "working_memory_ttl_seconds",
+        "working_memory_promotion_min_importance",
+        "browser_token_max_ttl_seconds",
+        "request_signing_keys",
+        "request_signing_max_skew_seconds",
+        "jwt_secret",
+        "jwt_algorithm",
+        "jwt_issuer",
+        "jwt_audience",
+        "jwt_required_scope",
+    }
+)
+
+
+class ConfigReloader:
+    """Re-read ``config.config_file`` and apply reloadable changes in place."""
+
+    def __init__(
+        self,
+        config: ApiConfig,
+        path: str | Path,
+        *,
+        source: Callable[[], ApiConfig] | None = None,
+    ) -> None:
+        self._config = config
+        self._path = Path(path)
+        self._source = source
+        self._lock = RLock()
+        self._stop = Event()
+        self._thread: Thread | None = None
+        self._last_mtime = self._mtime()
+        self._log = get_logger("orbit.api.config_reload")
+
+    def reload(self) -> dict[str, list[str]]:
+        """Apply changed reloadable fields; returns ``{"applied": [...], "restart_required": [...]}``.
+
+        An invalid file leaves the running config untouched.
+        """
+        with self._lock:
+            clear_secret_cache()
+            try:
+                candidate = ApiConfig.from_file(self._path, base=self._baseline())
+            except (ValueError, RuntimeError) as exc:
+                self._log.error("config_reload_failed", path=str(self._path), error=str(exc))
+                return {"applied": [], "restart_required": []}
+            applied: list[str] = []
+            restart_required: list[str] = []
+            for name in type(candidate).model_fields:
+                new_value = getattr(candidate, name)
+                if getattr(self._config, name) == new_value:
+                    continue
+                if name in RELOADABLE_FIELDS:
+                    setattr(self._config, name, new_value)
+                    applied.append(name)
+                else:
+                    restart_required.append(name)
+            self._log.info(
+                "config_reloaded",
+                path=str(self._path),
+                applied=applied,
+                restart_required=restart_required,
+            )
+            return {"applied": applied, "restart_required": restart_required}
+
+    def install_signal_handler(self) -> bool:
+        """Reload on SIGHUP. Returns False where signals are unavailable."""
+        sighup = getattr(signal, "SIGHUP", None)
+        if sighup is None:
+            return False
+        try:
+            signal.signal(sighup, self._handle_signal)
+        except ValueError:
+            # signal.signal only works from the main thread (e.g. not under some test runners).
+            return False
+        return True
+
+    def start_watching(self, interval_seconds: float) -> None:
+        if interval_seconds <= 0 or self._thread is not None:
+            return
+        self._stop.clear()
+        self._thread = Thread(
+            target=self._watch,
+            args=(interval_seconds,),
+            name="orbit-config-watch",
+            daemon=True,
+        )
+        self._thread.start()
+
+    def stop(self) -> None:
+        self._stop.set()
+        if self._thread is not None:
+            self._thread.join(timeout=5)
+            self._thread = None
+
+    def _baseline(self) -> ApiConfig:
+        # Rebuilding from the source re-reads env vars, ``*_FILE`` paths and Vault, so rotated
+        # secrets apply. A config built in code has no source; values not set in the file keep
+        # whatever the process started with.
+        if self._source is not None:
+            return self._source()
+        return self._config.model_copy()
+
+    def _handle_signal(self, signum: int, frame: Any) -> None:
+        del signum, frame
+        self.reload()
+
+    def _watch(self, interval_seconds: float) -> None:
+        while not self._stop.wait(interval_seconds):
+            mtime = self._mtime()
+            if mtime is None or mtime == self._last_mtime:
+                continue
+            self._last_mtime = mtime
+            self.reload()
+
+    def _mtime(self) -> float | None:
+        try:
+            return self._path.stat().st_mtime
+        except OSError:
+            return None
@@ -0,0 +1,170 @@
+"""Discord bot that ingests guild channel messages and answers `/recall`."""
+
+from __future__ import annotations
+
+import asyncio
+from dataclasses import dataclass
+from importlib import import_module
+from types import ModuleType
+from typing import Any
+
+from orbit.logger import get_logger
+from orbit.models import IngestRequest, RetrieveRequest
+from orbit_api.service import OrbitApiService, RateLimitExceededError
+
+DISCORD_EVENT_TYPE = "discord_message"
+DISCORD_MESSAGE_LIMIT = 2000
+
+
+def _optional_import(module_name: str) -> ModuleType | None:
+    try:  # pragma: no cover - optional dependency
+        return import_module(module_name)
+    except ImportError:  # pragma: no cover - optional dependency
+        return None
+
+
+discord_module: ModuleType | None = _optional_import("discord")
+
+
+@dataclass(frozen=True)
+class DiscordMessage:
+    message_id: str
+    guild_id: str | None
+    channel_id: str
+    author_id: str
+    author_name: str
+    content: str
+    is_bot: bool = False
+
+
+class DiscordMemoryBridge:
+    """Library-agnostic core of the bot: one Orbit entity per Discord user."""
+
+    def __init__(
+        self,
+        service: OrbitApiService,
+        *,
+        account_key: str,
+        channel_ids: list[str],
+        recall_across_users: bool = False,
+        recall_limit: int = 5,
+        recall_max_sensitivity: str = "public",
+    ) -> None:
+        self._service = service
+        self._account_key = account_key
+        self._channel_ids = set(channel_ids)
+        self._recall_across_users = recall_across_users
+        self._recall_limit = recall_limit
+        # Replies land in a Discord channel, so restricted memories stay out by default.
+        self._recall_max_sensitivity = recall_max_sensitivity
+        self._log = get_logger("orbit.api.discord")
+
+    @staticmethod
+    def entity_for(author_id: str) -> str:
+        return f"discord:{author_id}"
+
+    def message_to_ingest(self, message: DiscordMessage) -> IngestRequest | None:
+        if message.is_bot or message.channel_id not in self._channel_ids:
+            return None
+        content = message.content.strip()
+        if not content:
+            return None
+        metadata: dict[str, Any] = {
+            "source": "discord",
+            "discord_channel_id": message.channel_id,
+            "discord_author": message.author_name,
+        }
+        if message.guild_id:
+            metadata["discord_guild_id"] = message.guild_id
+        return IngestRequest(
+            content=content,
+            entity_id=self.entity_for(message.author_id),
+            event_type=DISCORD_EVENT_TYPE,
+            metadata=metadata,
+        )
+
+    def handle_message(self, message: DiscordMessage) -> bool:
+        request = self.message_to_ingest(message)
+        if request is None:
+            return False
+        try:
+            result, _snapshot, _replayed = self._service.ingest_with_quota(
+                account_key=self._account_key,
+                request=request,
+                idempotency_key=f"discord:{message.message_id}",
+            )
+        except RateLimitExceededError as exc:
+            self._log.warning(
+                "discord_ingest_rate_limited",
+                account=self._account_key,
+                retry_after_seconds=exc.retry_after_seconds,
+            )
+            return False
+        return result.stored
+
+    def recall(self, query: str, *, user_id: str) -> str:
+        query = query.strip()
+        if not query:
+            return "Usage: `/recall <question>`"
+        try:
+            self._service.consume_query_quota(account_key=self._account_key, amount=1)
+        except RateLimitExceededError as exc:
+            return f"Orbit query quota reached: {exc.detail}"
+        result = self._service.retrieve(
+            RetrieveRequest(
+                query=query,
+                limit=self._recall_limit,
+                entity_id=None if self._recall_across_users else self.entity_for(user_id),
+            ),
+            account_key=self._account_key,
+            max_sensitivity=self._recall_max_sensitivity,
+        )
+        if not result.memories:
+            return f"No memories found for *{query}*."
+        lines = [f"**Orbit recall:** *{query}*"]
+        lines.extend(f"- {memory.content}" for memory in result.memories)
+        return "\n".join(lines)[:DISCORD_MESSAGE_LIMIT]
+
+
+def build_client(bridge: DiscordMemoryBridge, *, guild_ids: list[str] | None = None) -> Any:
+    """Create a ``discord.Client`` wired to ``bridge``; start it with ``client.run(token)``."""
+    if discord_module is None:
+        msg = "Discord bot requires discord.py. Install with: pip install orbit-memory[discord]"
+        raise RuntimeError(msg)
+    intents = discord_module.Intents.default()
+    intents.message_content = True
+    client = discord_module.Client(intents=intents)
+    tree = discord_module.app_commands.CommandTree(client)
+    guilds = [discord_module.Object(id=int(item)) for item in guild_ids or []]
+
+    @tree.command(name="recall", description="Search your Orbit memory.")
+    async def recall(interaction: Any, query: str) -> None:
+        await interaction.response.defer(ephemeral=True, thinking=True)
+        text = await asyncio.to_thread(bridge.recall, query, user_id=str(interaction.user.id))
+        await interaction.followup.send(text, ephemeral=True)
+
+    @client.event
+    async def on_ready() -> None:
+        if not guilds:
+            await tree.sync()
+            return
+        for guild in guilds:
+            tree.copy_global_to(guild=guild)
+            await tree.sync(guild=guild)
+
+    @client.event
+    async def on_message(message: Any) -> None:
+        await asyncio.to_thread(
+            bridge.handle_message,
+            DiscordMessage(
+                message_id=str(message.id),
+                guild_id=str(message.guild.id) if message.guild is not None else None,
+                channel_id=str(message.channel.id),
+                author_id=str(message.author.id),
+                author_name=str(message.author.display_name),
+                content=message.content or "",
+                is_bot=bool(message.author.bot),
+            ),
+        )
+
+    return client
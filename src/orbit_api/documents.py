@@ -0,0 +1,67 @@
+"""Treat a re-uploaded document as a revision of the one it largely repeats.
+
+A chunked document whose chunks mostly match an earlier chunk group of the same entity is a
+new revision of it. Its chunks are aligned with the earlier ones: unchanged chunks keep their
+memories, edited chunks update theirs in place (same id, new version), and only added or
+removed chunks are written or deleted, so the index does not churn and retrieval does not
+return both copies. Chunks compare by their words, so re-flowed whitespace is not an edit.
+"""
+
+from __future__ import annotations
+
+from collections.abc import Sequence
+from dataclasses import dataclass, field
+from difflib import SequenceMatcher
+
+# Relationships that describe a chunk's place in its document rather than its content.
+DOCUMENT_RELATIONSHIP_PREFIXES = (
+    "oversize:",
+    "original_chars:",
+    "chunk_group:",
+    "chunk_count:",
+    "chunk_index:",
+    "source_start:",
+    "source_end:",
+    "page_start:",
+    "page_end:",
+)
+
+
+@dataclass
+class RevisionPlan:
+    """Index pairs ``(old, new)`` into the earlier and the re-uploaded chunk lists."""
+
+    keep: list[tuple[int, int]] = field(default_factory=list)
+    update: list[tuple[int, int]] = field(default_factory=list)
+    add: list[int] = field(default_factory=list)
+    remove: list[int] = field(default_factory=list)
+
+
+def revision_similarity(old: Sequence[str], new: Sequence[str]) -> float:
+    """Share of chunks the two documents have in common, in order, from 0 to 1."""
+    return _matcher(old, new).ratio()
+
+
+def plan_revision(old: Sequence[str], new: Sequence[str]) -> RevisionPlan:
+    """Align ``new`` chunks with ``old`` ones; a replaced run pairs up chunks in order."""
+    plan = RevisionPlan()
+    for tag, old_start, old_end, new_start, new_end in _matcher(old, new).get_opcodes():
+        old_indexes = list(range(old_start, old_end))
+        new_indexes = list(range(new_start, new_end))
+        if tag == "equal":
+            plan.keep.extend(zip(old_indexes, new_indexes, strict=True))
+            continue
+        paired = min(len(old_indexes), len(new_indexes))
+        plan.update.extend(zip(old_indexes[:paired], new_indexes[:paired], strict=True))
+        plan.remove.extend(old_indexes[paired:])
+        plan.add.extend(new_indexes[paired:])
+    return plan
+
+
+def _matcher(old: Sequence[str], new: Sequence[str]) -> SequenceMatcher[str]:
+    return SequenceMatcher(
+        None,
+        [" ".join(chunk.split()) for chunk in old],
+        [" ".join(chunk.split()) for chunk in new],
+        autojunk=False,
+    )
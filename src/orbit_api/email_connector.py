@@ -0,0 +1,282 @@
+"""Email ingestion from IMAP mailboxes or SES/SendGrid inbound webhooks."""
+
+from __future__ import annotations
+
+import base64
+import html
+import imaplib
+import json
+import re
+import time
+from collections.abc import Callable
+from dataclasses import dataclass, field
+from datetime import datetime
+from email import message_from_bytes, policy
+from email.message import EmailMessage, Message
+from email.utils import getaddresses, parseaddr, parsedate_to_datetime
+from typing import Any
+from urllib.parse import urlparse
+
+from orbit.logger import get_logger
+from orbit.models import IngestRequest, IngestResponse
+from orbit_api.service import OrbitApiService, RateLimitExceededError
+
+EMAIL_EVENT_TYPE = "email_message"
+
+_REPLY_HEADER_PATTERNS = (
+    re.compile(r"^\s*On .+wrote:\s*$"),
+    re.compile(r"^\s*-{2,}\s*Original Message\s*-{2,}\s*$", re.IGNORECASE),
+    re.compile(r"^\s*-{2,}\s*Forwarded message\s*-{2,}\s*$", re.IGNORECASE),
+    re.compile(r"^\s*From:\s.+$"),
+    re.compile(r"^_{10,}\s*$"),
+)
+_SIGNATURE_PATTERNS = (
+    re.compile(r"^--\s?$"),
+    re.compile(r"^\s*Sent from my \w+", re.IGNORECASE),
+    re.compile(r"^\s*Get Outlook for \w+", re.IGNORECASE),
+)
+_HTML_TAG = re.compile(r"<[^>]+>")
+_HTML_BLOCK = re.compile(r"<(script|style)[^>]*>.*?</\1>", re.IGNORECASE | re.DOTALL)
+
+
+@dataclass(frozen=True)
+class ParsedEmail:
+    message_id: str | None
+    sender: str
+    sender_name: str | None
+    subject: str
+    body: str
+    sent_at: datetime | None = None
+    recipients: list[str] = field(default_factory=list)
+
+
+def parse_email(raw: bytes) -> ParsedEmail:
+    message = message_from_bytes(raw, policy=policy.default)
+    sender_name, sender = parseaddr(str(message.get("From", "")))
+    if not sender:
+        msg = "email has no From address"
+        raise ValueError(msg)
+    sent_at: datetime | None = None
+    if message.get("Date"):
+        try:
+            sent_at = parsedate_to_datetime(str(message["Date"]))
+        except (TypeError, ValueError):
+            sent_at = None
+    recipients = [
+        address.lower()
+        for _name, address in getaddresses(
+            [str(value) for value in message.get_all("To", []) + message.get_all("Cc", [])]
+        )
+        if address
+    ]
+    return ParsedEmail(
+        message_id=str(message["Message-ID"]).strip() if message.get("Message-ID") else None,
+        sender=sender.strip().lower(),
+        sender_name=sender_name.strip() or None,
+        subject=str(message.get("Subject", "")).strip(),
+        body=strip_quoted_text(_message_text(message)),
+        sent_at=sent_at,
+        recipients=recipients,
+    )
+
+
+def strip_quoted_text(body: str) -> str:
+    """Drop quoted replies, forwarded history, and trailing signatures."""
+    kept: list[str] = []
+    for line in body.replace("\r\n", "\n").split("\n"):
+        if any(pattern.match(line) for pattern in _REPLY_HEADER_PATTERNS):
+            break
+        if any(pattern.match(line) for pattern in _SIGNATURE_PATTERNS):
+            break
+        if line.lstrip().startswith(">"):
+            continue
+        kept.append(line.rstrip())
+    return "\n".join(kept).strip()
+
+
+def email_to_ingest(parsed: ParsedEmail, *, max_content_chars: int = 20_000) -> IngestRequest | None:
+    if not parsed.body and not parsed.subject:
+        return None
+    content = f"Subject: {parsed.subject}\n\n{parsed.body}" if parsed.subject else parsed.body
+    metadata: dict[str, Any] = {"source": "email", "email_from": parsed.sender}
+    if parsed.sender_name:
+        metadata["email_from_name"] = parsed.sender_name
+    if parsed.subject:
+        metadata["email_subject"] = parsed.subject
+    if parsed.message_id:
+        metadata["email_message_id"] = parsed.message_id
+    if parsed.sent_at is not None:
+        metadata["email_sent_at"] = parsed.sent_at.isoformat()
+    if parsed.recipients:
+        metadata["email_to"] = parsed.recipients
+    return IngestRequest(
+        content=content[:max_content_chars],
+        entity_id=parsed.sender,
+        event_type=EMAIL_EVENT_TYPE,
+        metadata=metadata,
+    )
+
+
+class EmailIngestor:
+    """Parses raw RFC 822 messages and stores them under the sender's entity."""
+
+    def __init__(
+        self,
+        service: OrbitApiService,
+        *,
+        account_key: str,
+        max_content_chars: int = 20_000,
+    ) -> None:
+        self._service = service
+        self._account_key = account_key
+        self._max_content_chars = max_content_chars
+
+    @property
+    def account_key(self) -> str:
+        return self._account_key
+
+    def ingest_raw(self, raw: bytes) -> IngestResponse | None:
+        parsed = parse_email(raw)
+        request = email_to_ingest(parsed, max_content_chars=self._max_content_chars)
+        if request is None:
+            return None
+        result, _snapshot, _replayed = self._service.ingest_with_quota(
+            account_key=self._account_key,
+            request=request,
+            idempotency_key=f"email:{parsed.message_id}" if parsed.message_id else None,
+        )
+        return result
+
+
+def extract_sendgrid_email(body: bytes, content_type: str) -> bytes | None:
+    """Return the raw MIME ``email`` field from a SendGrid Inbound Parse post."""
+    if "multipart/form-data" not in content_type.lower():
+        return None
+    envelope = message_from_bytes(
+        f"Content-Type: {content_type}\r\n\r\n".encode() + body,
+        policy=policy.default,
+    )
+    if not isinstance(envelope, EmailMessage) or not envelope.is_multipart():
+        return None
+    for part in envelope.iter_parts():
+        if part.get_param("name", header="content-disposition") == "email":
+            payload = part.get_payload(decode=True)
+            return payload if isinstance(payload, bytes) else None
+    return None
+
+
+def extract_ses_email(payload: dict[str, Any]) -> bytes | None:
+    """Return the raw message from an SNS-delivered SES receipt notification."""
+    if payload.get("Type") != "Notification":
+        return None
+    try:
+        notification = json.loads(str(payload.get("Message", "")))
+    except json.JSONDecodeError:
+        return None
+    content = notification.get("content") if isinstance(notification, dict) else None
+    if not isinstance(content, str):
+        return None
+    action = notification.get("receipt", {}).get("action", {})
+    if str(action.get("encoding", "")).upper() == "BASE64":
+        return base64.b64decode(content)
+    return content.encode("utf-8")
+
+
+def is_sns_subscribe_url(url: str) -> bool:
+    parsed = urlparse(url)
+    host = parsed.hostname or ""
+    return parsed.scheme == "https" and host.startswith("sns.") and host.endswith(".amazonaws.com")
+
+
+class ImapPoller:
+    """Polls a mailbox for unseen messages and marks them seen once ingested."""
+
+    def __init__(
+        self,
+        ingestor: EmailIngestor,
+        *,
+        host: str,
+        username: str,
+        password: str,
+        mailbox: str = "INBOX",
+        port: int = 993,
+        use_ssl: bool = True,
+        connect: Callable[..., imaplib.IMAP4] | None = None,
+    ) -> None:
+        self._ingestor = ingestor
+        self._host = host
+        self._username = username
+        self._password = password
+        self._mailbox = mailbox
+        self._port = port
+        self._connect = connect or (imaplib.IMAP4_SSL if use_ssl else imaplib.IMAP4)
+        self._log = get_logger("orbit.api.email")
+
+    def poll_once(self) -> int:
+        ingested = 0
+        client = self._connect(self._host, self._port)
+        try:
+            client.login(self._username, self._password)
+            client.select(self._mailbox)
+            _status, data = client.uid("SEARCH", None, "UNSEEN")
+            uids = data[0].split() if data and data[0] else []
+            for uid in uids:
+                _status, fetched = client.uid("FETCH", uid, "(BODY.PEEK[])")
+                raw = _fetched_bytes(fetched)
+                if raw is None:
+                    continue
+                try:
+                    result = self._ingestor.ingest_raw(raw)
+                except RateLimitExceededError:
+                    self._log.warning("email_ingest_rate_limited", account=self._ingestor.account_key)
+                    break
+                except ValueError as exc:
+                    self._log.warning("email_rejected", uid=uid.decode(), error=str(exc))
+                    result = None
+                client.uid("STORE", uid, "+FLAGS", "(\\Seen)")
+                if result is not None:
+                    ingested += 1
+        finally:
+            try:
+                client.logout()
+            except imaplib.IMAP4.error:
+                pass
+        return ingested
+
+    def run(
+        self,
+        *,
+        interval_seconds: float = 60.0,
+        sleep: Callable[[float], None] = time.sleep,
+    ) -> None:
+        while True:
+            count = self.poll_once()
+            self._log.info("email_poll", account=self._ingestor.account_key, ingested=count)
+            sleep(interval_seconds)
+
+
+def _fetched_bytes(fetched: list[Any]) -> bytes | None:
+    for item in fetched:
+        if isinstance(item, tuple) and len(item) >= 2 and isinstance(item[1], bytes):
+            return item[1]
+    return None
+
+
+def _message_text(message: Message) -> str:
+    if isinstance(message, EmailMessage):
+        part = message.get_body(preferencelist=("plain", "html"))
+        if part is not None:
+            text = part.get_content()
+            if part.get_content_subtype() == "html":
+                return _html_to_text(text)
+            return str(text)
+    payload = message.get_payload(decode=True)
+    if isinstance(payload, bytes):
+        return payload.decode(message.get_content_charset() or "utf-8", errors="replace")
+    return ""
+
+
+def _html_to_text(value: str) -> str:
+    without_blocks = _HTML_BLOCK.sub("", value)
+    with_breaks = re.sub(r"<br\s*/?>|</p>|</div>", "\n", without_blocks, flags=re.IGNORECASE)
+    return html.unescape(_HTML_TAG.sub("", with_breaks))
@@ -0,0 +1,238 @@
+"""Scheduled exports of the memory change log to object storage.
+
+Each export job belongs to one tenant and runs on a five-field cron schedule (UTC). A run
+writes every change recorded since the previous run as one JSONL or Parquet object, so a
+warehouse can load the files in order to rebuild or incrementally update its copy.
+"""
+
+from __future__ import annotations
+
+import io
+import json
+from dataclasses import dataclass
+from datetime import UTC, datetime, timedelta
+from importlib import import_module
+from pathlib import Path
+from types import ModuleType
+from typing import Any
+
+from orbit_api.blob_store import BlobStore, GcsBlobStore, LocalBlobStore, S3BlobStore, blob_key
+
+# (name, lowest value, highest value) for minute, hour, day of month, month, day of week.
+_CRON_FIELDS = (
+    ("minute", 0, 59),
+    ("hour", 0, 23),
+    ("day of month", 1, 31),
+    ("month", 1, 12),
+    ("day of week", 0, 6),
+)
+_CRON_ALIASES = {
+    "@hourly": "0 * * * *",
+    "@daily": "0 0 * * *",
+    "@weekly": "0 0 * * 0",
+    "@monthly": "0 0 1 * *",
+}
+# A schedule with no match in this many days (e.g. 30 February) is rejected.
+_CRON_SEARCH_DAYS = 366 * 4
+
+
+def _optional_import(module_name: str) -> ModuleType | None:
+    try:  # pragma: no cover - optional dependency
+        return import_module(module_name)
+    except ImportError:  # pragma: no cover - optional dependency
+        return None
+
+
+pyarrow_module: ModuleType | None = _optional_import("pyarrow")
+pyarrow_parquet_module: ModuleType | None = _optional_import("pyarrow.parquet")
+
+
+@dataclass(frozen=True)
+class CronSchedule:
+    """Standard ``minute hour day month weekday`` cron, evaluated in UTC.
+
+    Fields accept ``*``, numbers, ``a-b`` ranges, ``*/n`` and ``a-b/n`` steps, and comma lists.
+    As in cron, when both day of month and day of week are restricted either may match.
+    """
+
+    expression: str
+    minutes: frozenset[int]
+    hours: frozenset[int]
+    days: frozenset[int]
+    months: frozenset[int]
+    weekdays: frozenset[int]
+    any_day: bool
+    any_weekday: bool
+
+    @classmethod
+    def parse(cls, expression: str) -> CronSchedule:
+        normalized = " ".join(expression.split())
+        fields = _CRON_ALIASES.get(normalized.lower(), normalized).split(" ")
+        if len(fields) != len(_CRON_FIELDS):
+            msg = "cron expression must have five fields: minute hour day month weekday"
+            raise ValueError(msg)
+        values = [
+            _parse_cron_field(field, name=name, low=low, high=high)
+            for field, (name, low, high) in zip(fields, _CRON_FIELDS, strict=True)
+        ]
+        schedule = cls(
+            expression=normalized,
+            minutes=values[0],
+            hours=values[1],
+            days=values[2],
+            months=values[3],
+            # Both 0 and 7 mean Sunday.
+            weekdays=frozenset(day % 7 for day in values[4]),
+            any_day=fields[2] == "*",
+            any_weekday=fields[4] == "*",
+        )
+        schedule.next_after(datetime(2000, 1, 1, tzinfo=UTC))
+        return schedule
+
+    def next_after(self, moment: datetime) -> datetime:
+        """The first matching minute strictly after ``moment``."""
+        candidate = moment.astimezone(UTC).replace(second=0, microsecond=0) + timedelta(
+            minutes=1
+        )
+        limit = candidate + timedelta(days=_CRON_SEARCH_DAYS)
+        while candidate < limit:
+            if candidate.month not in self.months:
+                candidate = _start_of_next_month(candidate)
+                continue
+            if not self._matches_day(candidate):
+                candidate = candidate.replace(hour=0, minute=0) + timedelta(days=1)
+                continue
+            if candidate.hour not in self.hours:
+                candidate = candidate.replace(minute=0) + timedelta(hours=1)
+                continue
+            if candidate.minute not in self.minutes:
+                candidate += timedelta(minutes=1)
+                continue
+            return candidate
+        msg = f"cron expression never matches: {self.expression}"
+        raise ValueError(msg)
+
+    def _matches_day(self, moment: datetime) -> bool:
+        day_match = moment.day in self.days
+        weekday_match = (moment.weekday() + 1) % 7 in self.weekdays
+        if self.any_day and self.any_weekday:
+            return True
+        if self.any_day:
+            return weekday_match
+        if self.any_weekday:
+            return day_match
+        return day_match or weekday_match
+
+
+def _parse_cron_field(field: str, *, name: str, low: int, high: int) -> frozenset[int]:
+    # Day of week also accepts 7 for Sunday.
+    upper = 7 if name == "day of week" else high
+    values: set[int] = set()
+    for part in field.split(","):
+        base, _, step_raw = part.partition("/")
+        try:
+            step = int(step_raw) if step_raw else 1
+            if base == "*":
+                start, end = low, upper
+            elif "-" in base:
+                start_raw, end_raw = base.split("-", 1)
+                start, end = int(start_raw), int(end_raw)
+            else:
+                start = int(base)
+                end = upper if step_raw else start
+        except ValueError as exc:
+            msg = f"invalid cron {name} field: {field!r}"
+            raise ValueError(msg) from exc
+        if step <= 0 or start < low or end > upper or start > end:
+            msg = f"cron {name} field out of range: {field!r}"
+            raise ValueError(msg)
+        values.update(range(start, end + 1, step))
+    return frozenset(values)
+
+
+def _start_of_next_month(moment: datetime) -> datetime:
+    first = moment.replace(day=1, hour=0, minute=0)
+    return (first + timedelta(days=32)).replace(day=1)
+
+
+def validate_destination(destination: str, *, file_root: str | None = None) -> str:
+    """Accept ``s3://bucket/prefix``, ``gs://bucket/prefix``, or ``file:///path``.
+
+    ``file://`` paths are resolved and must lie under ``file_root``; without a root they are
+    refused, so an export cannot write wherever the server process can.
+    """
+    normalized = destination.strip().rstrip("/")
+    scheme, separator, rest = normalized.partition("://")
+    if not separator or scheme not in {"s3", "gs", "file"} or not rest.strip("/"):
+        msg = "destination must look like s3://bucket/prefix, gs://bucket/prefix, or file:///path"
+        raise ValueError(msg)
+    if scheme != "file":
+        return normalized
+    if not file_root:
+        msg = "file:// destinations are disabled; set ORBIT_EXPORT_FILE_ROOT to allow them"
+        raise ValueError(msg)
+    path = Path(rest).resolve()
+    if not path.is_relative_to(Path(file_root).resolve()):
+        msg = f"file:// destination must be inside ORBIT_EXPORT_FILE_ROOT ({file_root})"
+        raise ValueError(msg)
+    return f"file://{path}"
+
+
+def build_destination_store(
+    destination: str,
+    *,
+    file_root: str | None = None,
+    s3_endpoint_url: str | None = None,
+    s3_region: str | None = None,
+) -> BlobStore:
+    # Checked again per run: the job may predate the root, or the root may have moved.
+    scheme, _, rest = validate_destination(destination, file_root=file_root).partition("://")
+    if scheme == "file":
+        return LocalBlobStore(rest)
+    bucket, _, prefix = rest.partition("/")
+    if scheme == "gs":
+        return GcsBlobStore(bucket, prefix=prefix)
+    return S3BlobStore(bucket, prefix=prefix, endpoint_url=s3_endpoint_url, region=s3_region)
+
+
+def encode_export(rows: list[dict[str, Any]], export_format: str) -> tuple[bytes, str]:
+    """Serialize change rows; returns the payload and its content type."""
+    if export_format == "parquet":
+        return _encode_parquet(rows), "application/vnd.apache.parquet"
+    lines = [json.dumps(row, ensure_ascii=True, default=str) for row in rows]
+    return ("\n".join(lines) + "\n").encode("utf-8"), "application/x-ndjson"
+
+
+def _encode_parquet(rows: list[dict[str, Any]]) -> bytes:
+    if pyarrow_module is None or pyarrow_parquet_module is None:
+        msg = "Parquet exports require pyarrow. Install with: pip install orbit-memory[parquet]"
+        raise RuntimeError(msg)
+    # ``memory`` varies by operation, so it is kept as a JSON string column.
+    columns = {
+        "sequence": [int(row["sequence"]) for row in rows],
+        "memory_id": [str(row["memory_id"]) for row in rows],
+        "operation": [str(row["operation"]) for row in rows],
+        "occurred_at": [str(row["occurred_at"]) for row in rows],
+        "memory": [
+            json.dumps(row["memory"], ensure_ascii=True) if row["memory"] else None
+            for row in rows
+        ],
+    }
+    table = pyarrow_module.table(columns)
+    buffer = io.BytesIO()
+    pyarrow_parquet_module.write_table(table, buffer)
+    return buffer.getvalue()
+
+
+def export_object_key(
+    account_key: str,
+    export_id: str,
+    *,
+    first_sequence: int,
+    last_sequence: int,
+    export_format: str,
+    now: datetime,
+) -> str:
+    extension = "parquet" if export_format == "parquet" else "jsonl"
+    filename = f"{now:%Y%m%dT%H%M%SZ}-{first_sequence:012d}-{last_sequence:012d}.{extension}"
+    return blob_key(account_key, export_id, filename)
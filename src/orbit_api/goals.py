@@ -0,0 +1,161 @@
+"""Pick goals and progress updates out of conversation.
+
+Goals are first-person commitments ("I want to run a half marathon by June 1", "I'll send
+the report by Friday"); progress updates are first-person reports on one ("I started
+training", "I finished the report"). The service stores each goal against the memory that
+stated it and moves it to ``in_progress`` or ``done`` when a later memory of the same entity
+reports progress on it, matching the two by shared keywords.
+"""
+
+from __future__ import annotations
+
+import calendar
+import re
+from collections.abc import Mapping
+from dataclasses import dataclass
+from datetime import date, timedelta
+
+_GOAL_PATTERNS = (
+    re.compile(
+        r"\b(?:i|we)(?:'m| am|'re| are)? (?:really )?"
+        r"(?:want|plan|planning|intend|hope|need|going|aim|trying|promise) to (?P<title>[^.!?]+)",
+        re.IGNORECASE,
+    ),
+    re.compile(r"\b(?:my|our) goal is to (?P<title>[^.!?]+)", re.IGNORECASE),
+    re.compile(r"\b(?:i|we)(?:'ll| will) (?P<title>[^.!?]+)", re.IGNORECASE),
+)
+_PROGRESS_PATTERNS = (
+    (
+        "done",
+        re.compile(
+            r"\b(?:i|we)(?:'ve| have)? (?:finally |just )?"
+            r"(?:finished|completed|done with|wrapped up|achieved|reached|sent|submitted|booked) "
+            r"(?P<subject>[^.!?]+)",
+            re.IGNORECASE,
+        ),
+    ),
+    (
+        "done",
+        re.compile(
+            r"(?P<subject>[^.!?,]+?) is (?:finally )?(?:done|finished|complete)\b",
+            re.IGNORECASE,
+        ),
+    ),
+    (
+        "in_progress",
+        re.compile(
+            r"\b(?:i|we)(?:'ve| have|'m| am|'re| are)? (?:already )?"
+            r"(?:started|starting|began|begun|working on|making progress on|halfway through) "
+            r"(?P<subject>[^.!?]+)",
+            re.IGNORECASE,
+        ),
+    ),
+)
+_WEEKDAYS = "monday|tuesday|wednesday|thursday|friday|saturday|sunday".split("|")
+_MONTHS = (
+    "january|february|march|april|may|june|july|august|september|october|november|december"
+).split("|")
+_DUE = re.compile(
+    r"\s*\b(?:by|before|on|until|due)?\s*(?P<when>today|tonight|tomorrow|next week|this week"
+    r"|this weekend|end of (?:the )?(?:day|week|month|year)|\d{4}-\d{2}-\d{2}"
+    rf"|in \d+ (?:day|week|month)s?|(?:{'|'.join(_WEEKDAYS)})"
+    rf"|(?:{'|'.join(_MONTHS)}) \d{{1,2}}(?:st|nd|rd|th)?)\b",
+    re.IGNORECASE,
+)
+# Text after these starts a new thought, not more of the goal or the progress subject.
+_TITLE_BREAKS = re.compile(r",? (?:but|because|so that|since) ", re.IGNORECASE)
+_SUBJECT_BREAKS = re.compile(r",|;| (?:and|but|so|because) ", re.IGNORECASE)
+_STOPWORDS = frozenset(
+    "the and for with this that then than from into onto about my our your their his her its "
+    "get got make made start started finish finished done really just some more all there here "
+    "back sure".split()
+)
+
+
+@dataclass(frozen=True)
+class GoalStatement:
+    title: str
+    due_date: date | None
+
+
+def extract_goal(text: str, *, today: date) -> GoalStatement | None:
+    """Return the goal ``text`` commits to, with its due date resolved against ``today``."""
+    normalized = " ".join(text.split())
+    for pattern in _GOAL_PATTERNS:
+        match = pattern.search(normalized)
+        if match is None:
+            continue
+        title = _TITLE_BREAKS.split(match.group("title"), maxsplit=1)[0]
+        due_date = None
+        due = _DUE.search(title)
+        if due is not None:
+            due_date = _due_date(due.group("when").lower(), today)
+            title = title[: due.start()] + title[due.end() :]
+        title = title.strip(" ,;:")
+        if _keywords(title):
+            return GoalStatement(title=title, due_date=due_date)
+    return None
+
+
+def extract_progress(text: str) -> tuple[str, str] | None:
+    """Return ``(status, subject)`` for a progress report, e.g. ``("done", "the report")``."""
+    normalized = " ".join(text.split())
+    for status, pattern in _PROGRESS_PATTERNS:
+        match = pattern.search(normalized)
+        if match is None:
+            continue
+        subject = _SUBJECT_BREAKS.split(match.group("subject"), maxsplit=1)[0].strip()
+        if _keywords(subject):
+            return status, subject
+    return None
+
+
+def match_goal(subject: str, titles: Mapping[str, str]) -> str | None:
+    """Return the key of the title sharing the most keywords with ``subject``, if any."""
+    words = _keywords(subject)
+    best, best_overlap = None, 0
+    for key, title in titles.items():
+        overlap = len(words & _keywords(title))
+        if overlap > best_overlap:
+            best, best_overlap = key, overlap
+    return best
+
+
+def _keywords(text: str) -> set[str]:
+    return {
+        word
+        for word in re.findall(r"[a-z0-9]+", text.lower())
+        if len(word) > 2 and word not in _STOPWORDS
+    }
+
+
+def _due_date(when: str, today: date) -> date | None:
+    if when in {"today", "tonight", "end of day", "end of the day"}:
+        return today
+    if when == "tomorrow":
+        return today + timedelta(days=1)
+    if when == "next week":
+        return today + timedelta(days=7)
+    if when in {"this week", "this weekend", "end of week", "end of the week"}:
+        return today + timedelta(days=6 - today.weekday())
+    if when in {"end of month", "end of the month"}:
+        return today.replace(day=calendar.monthrange(today.year, today.month)[1])
+    if when in {"end of year", "end of the year"}:
+        return today.replace(month=12, day=31)
+    if when in _WEEKDAYS:
+        return today + timedelta(days=(_WEEKDAYS.index(when) - today.weekday()) % 7 or 7)
+    if when.startswith("in "):
+        count, unit = when.split()[1:3]
+        days = {"day": 1, "week": 7, "month": 30}[unit.rstrip("s")]
+        return today + timedelta(days=int(count) * days)
+    month, _, day = when.partition(" ")
+    if month in _MONTHS:
+        try:
+            due = date(today.year, _MONTHS.index(month) + 1, int(day.rstrip("stndrh")))
+            return due if due >= today else due.replace(year=today.year + 1)
+        except ValueError:
+            return None
+    try:
+        return date.fromisoformat(when)
+    except ValueError:
+        return None
@@ -0,0 +1,158 @@
+"""Candidate vector indexes for blue/green ranking changes.
+
+A ``ShadowIndex`` re-embeds every memory with another embedding provider, chunk size, or
+quantization mode and keeps itself current from the engine's mutation feed. Until it is
+promoted it only answers mirrored queries; once promoted it replaces the engine's index for
+preselection and supplies the vectors the ranker scores, because the stored embeddings came
+from the production provider and live in a different space.
+"""
+
+from __future__ import annotations
+
+import threading
+from typing import Any
+
+import numpy as np
+
+from decision_engine.math_utils import cosine_similarity, to_unit_vector
+from decision_engine.models import MemoryRecord
+from decision_engine.semantic_encoding import EmbeddingProvider
+from memory_engine.storage.vector_store import VectorHit, VectorStore
+from orbit_api.oversize import chunk_content
+
+FloatArray = np.ndarray[Any, np.dtype[np.float32]]
+
+# Chunked memories hold several vectors, so searches over-fetch before collapsing them.
+_CHUNK_SEARCH_FACTOR = 4
+
+
+class ShadowIndex:
+    """A vector index over the same memories as production, built with its own profile."""
+
+    def __init__(
+        self,
+        embedding_provider: EmbeddingProvider,
+        *,
+        index_path: str,
+        quantization: str = "none",
+        chunk_chars: int | None = None,
+    ) -> None:
+        self._provider = embedding_provider
+        self._index_path = index_path
+        self._quantization = quantization
+        self._chunk_chars = chunk_chars
+        self._lock = threading.RLock()
+        self._store: VectorStore | None = None
+        self._vectors: dict[str, list[FloatArray]] = {}
+        # Memories written or deleted since the build began; the backfill never overrides them.
+        self._touched: set[str] = set()
+
+    @property
+    def size(self) -> int:
+        with self._lock:
+            return len(self._vectors)
+
+    def encode_query(self, query: str) -> FloatArray:
+        return np.asarray(self._provider.embed(query), dtype=np.float32)
+
+    def index(self, memory: MemoryRecord, *, backfill: bool = False) -> None:
+        """Embed and store ``memory``; ``backfill`` skips memories a live write already set."""
+        if backfill and self._is_touched(memory.memory_id):
+            return
+        vectors = self._embed_chunks(memory.content or memory.summary)
+        with self._lock:
+            if backfill and memory.memory_id in self._touched:
+                return
+            if not backfill:
+                self._touched.add(memory.memory_id)
+            self._remove_locked(memory.memory_id)
+            if not vectors:
+                return
+            store = self._vector_store(vectors[0].shape[0])
+            for position, vector in enumerate(vectors):
+                store.add(
+                    f"{memory.memory_id}#{position}",
+                    vector.tolist(),
+                    namespace=memory.account_key,
+                )
+            self._vectors[memory.memory_id] = vectors
+
+    def remove(self, memory_id: str) -> None:
+        with self._lock:
+            self._touched.add(memory_id)
+            self._remove_locked(memory_id)
+
+    def search(self, query_embedding: FloatArray, top_k: int) -> list[VectorHit]:
+        """Return the best-scoring chunk of each memory, highest first."""
+        with self._lock:
+            store = self._store
+        if store is None or top_k <= 0:
+            return []
+        fetch = top_k * _CHUNK_SEARCH_FACTOR if self._chunk_chars else top_k
+        best: dict[str, float] = {}
+        for hit in store.search(query_embedding, top_k=fetch):
+            memory_id = hit.memory_id.rsplit("#", 1)[0]
+            if hit.score > best.get(memory_id, float("-inf")):
+                best[memory_id] = hit.score
+        ranked = sorted(best.items(), key=lambda item: item[1], reverse=True)[:top_k]
+        return [VectorHit(memory_id=memory_id, score=score) for memory_id, score in ranked]
+
+    def with_vectors(
+        self, records: list[MemoryRecord], query_embedding: FloatArray
+    ) -> list[MemoryRecord]:
+        """Swap each record's embeddings for this index's closest chunk to the query.
+
+        Records the index has not embedded yet get empty vectors, which the ranker scores as
+        no semantic match rather than comparing vectors from two different spaces.
+        """
+        query = to_unit_vector(np.asarray(query_embedding, dtype=np.float32))
+        with self._lock:
+            chunks = {record.memory_id: self._vectors.get(record.memory_id) for record in records}
+        updated: list[MemoryRecord] = []
+        for record in records:
+            vectors = chunks[record.memory_id]
+            vector: list[float] = []
+            if vectors:
+                vector = max(vectors, key=lambda item: cosine_similarity(query, item)).tolist()
+            updated.append(
+                record.model_copy(update={"semantic_embedding": vector, "raw_embedding": vector})
+            )
+        return updated
+
+    def _is_touched(self, memory_id: str) -> bool:
+        with self._lock:
+            return memory_id in self._touched
+
+    def _embed_chunks(self, content: str) -> list[FloatArray]:
+        texts = chunk_content(content, self._chunk_chars) if self._chunk_chars else [content]
+        texts = [text for text in texts if text.strip()]
+        if not texts:
+            return []
+        embed_many = getattr(self._provider, "embed_many", None)
+        if callable(embed_many) and len(texts) > 1:
+            embedded = embed_many(texts)
+        else:
+            embedded = [self._provider.embed(text) for text in texts]
+        return [to_unit_vector(np.asarray(vector, dtype=np.float32)) for vector in embedded]
+
+    def _vector_store(self, embedding_dim: int) -> VectorStore:
+        if self._store is None:
+            self._store = VectorStore(
+                embedding_dim=embedding_dim,
+                index_path=self._index_path,
+                quantization=self._quantization,
+            )
+        return self._store
+
+    def _remove_locked(self, memory_id: str) -> None:
+        vectors = self._vectors.pop(memory_id, None)
+        if vectors and self._store is not None:
+            self._store.remove_many([f"{memory_id}#{position}" for position in range(len(vectors))])
+
+
+def result_overlap(production_ids: list[str], candidate_ids: list[str]) -> float:
+    """Share of the longer result list that both lists return; two empty lists agree."""
+    size = max(len(production_ids), len(candidate_ids))
+    if size == 0:
+        return 1.0
+    return len(set(production_ids) & set(candidate_ids)) / size
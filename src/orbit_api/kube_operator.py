@@ -0,0 +1,810 @@
+"""Reconcile Orbit custom resources on Kubernetes.
+
+``orbit operator`` drives three resources in the ``orbitmemory.dev/v1alpha1`` group:
+
+``OrbitCluster``
+    An Orbit deployment: the API Deployment and Service, one Deployment per worker pool
+    (``orbit export``, ``orbit retention``, ...) with an optional autoscaler, and a migration
+    Job per image. Deployments only move to a new image once its migration Job has succeeded,
+    so pods never run against an older schema.
+``OrbitNamespace``
+    A tenant's settings, event type registry, and retention policy, applied through the
+    cluster's admin API and reapplied every ``resync_interval`` to undo drift.
+``OrbitAPIKey``
+    A tenant API key kept in a Secret. A new key is issued when the current one is revoked,
+    its spec changes, ``rotationDays`` pass, or the ``orbitmemory.dev/rotate`` annotation
+    changes. The previous key keeps working for ``rotationGraceSeconds`` so pods can pick up
+    the new Secret before it is revoked.
+
+Children carry an owner reference, so deleting an ``OrbitCluster`` or ``OrbitAPIKey`` removes
+its Deployments, Jobs, or Secret. ``OrbitNamespace`` and ``OrbitAPIKey`` hold a finalizer until
+the tenant settings are cleared or the keys revoked.
+"""
+
+from __future__ import annotations
+
+import base64
+import hashlib
+import json
+from collections.abc import Callable
+from datetime import UTC, datetime, timedelta
+from importlib import import_module
+from types import ModuleType
+from typing import Any, Protocol
+from urllib.parse import quote
+
+import httpx
+
+from orbit.logger import get_logger
+
+
+def _optional_import(module_name: str) -> ModuleType | None:
+    try:  # pragma: no cover - optional dependency
+        return import_module(module_name)
+    except ImportError:  # pragma: no cover - optional dependency
+        return None
+
+
+kubernetes_module: ModuleType | None = _optional_import("kubernetes")
+
+GROUP = "orbitmemory.dev"
+API_VERSION = f"{GROUP}/v1alpha1"
+FINALIZER = f"{GROUP}/finalizer"
+ROTATE_ANNOTATION = f"{GROUP}/rotate"
+FIELD_MANAGER = "orbit-operator"
+API_PORT = 8000
+DEFAULT_ROTATION_GRACE_SECONDS = 3600
+
+Resource = dict[str, Any]
+
+
+class KubeClient(Protocol):
+    """The Kubernetes calls the operator makes; objects are plain manifest dicts."""
+
+    def list_resources(self, api_version: str, kind: str) -> list[Resource]: ...
+
+    def get(self, api_version: str, kind: str, *, name: str, namespace: str) -> Resource | None: ...
+
+    def apply(self, manifest: Resource) -> None: ...
+
+    def delete(self, api_version: str, kind: str, *, name: str, namespace: str) -> None: ...
+
+    def patch_status(self, resource: Resource, status: dict[str, Any]) -> None: ...
+
+    def set_finalizers(self, resource: Resource, finalizers: list[str]) -> None: ...
+
+
+class ReconcilePending(Exception):
+    """A dependency is not ready yet; the resource is retried on the next pass."""
+
+
+class KubernetesClient:
+    """``KubeClient`` over the official client (requires ``kubernetes``).
+
+    Uses the in-cluster service account when running in a pod and ``~/.kube/config``
+    otherwise. Children are written with server-side apply under the ``orbit-operator``
+    field manager.
+    """
+
+    def __init__(self, *, namespace: str | None = None) -> None:
+        if kubernetes_module is None:
+            msg = (
+                "The Kubernetes operator requires the kubernetes client. "
+                "Install with: pip install orbit-memory[kubernetes]"
+            )
+            raise RuntimeError(msg)
+        config = import_module("kubernetes.config")
+        try:
+            config.load_incluster_config()
+        except config.ConfigException:
+            config.load_kube_config()
+        dynamic = import_module("kubernetes.dynamic")
+        self._client = dynamic.DynamicClient(import_module("kubernetes.client").ApiClient())
+        self._not_found = import_module("kubernetes.dynamic.exceptions").NotFoundError
+        self._namespace = namespace
+
+    def _resource(self, api_version: str, kind: str) -> Any:
+        return self._client.resources.get(api_version=api_version, kind=kind)
+
+    def list_resources(self, api_version: str, kind: str) -> list[Resource]:
+        listing = self._resource(api_version, kind).get(namespace=self._namespace).to_dict()
+        # List items omit apiVersion and kind, which owner references and patches need.
+        return [{**item, "apiVersion": api_version, "kind": kind} for item in listing["items"]]
+
+    def get(self, api_version: str, kind: str, *, name: str, namespace: str) -> Resource | None:
+        try:
+            found = self._resource(api_version, kind).get(name=name, namespace=namespace)
+        except self._not_found:
+            return None
+        return dict(found.to_dict())
+
+    def apply(self, manifest: Resource) -> None:
+        metadata = manifest["metadata"]
+        self._resource(manifest["apiVersion"], manifest["kind"]).server_side_apply(
+            body=manifest,
+            name=metadata["name"],
+            namespace=metadata.get("namespace"),
+            field_manager=FIELD_MANAGER,
+            force_conflicts=True,
+        )
+
+    def delete(self, api_version: str, kind: str, *, name: str, namespace: str) -> None:
+        try:
+            self._resource(api_version, kind).delete(
+                name=name,
+                namespace=namespace,
+                body={"propagationPolicy": "Background"},
+            )
+        except self._not_found:
+            return
+
+    def patch_status(self, resource: Resource, status: dict[str, Any]) -> None:
+        metadata = resource["metadata"]
+        self._resource(resource["apiVersion"], resource["kind"]).status.patch(
+            body={"status": status},
+            name=metadata["name"],
+            namespace=metadata["namespace"],
+            content_type="application/merge-patch+json",
+        )
+
+    def set_finalizers(self, resource: Resource, finalizers: list[str]) -> None:
+        metadata = resource["metadata"]
+        self._resource(resource["apiVersion"], resource["kind"]).patch(
+            body={"metadata": {"finalizers": finalizers}},
+            name=metadata["name"],
+            namespace=metadata["namespace"],
+            content_type="application/merge-patch+json",
+        )
+
+
+class OrbitAdminClient:
+    """Calls one cluster's ``/v1/admin`` endpoints with an operator token."""
+
+    def __init__(
+        self,
+        base_url: str,
+        token: str,
+        *,
+        timeout_seconds: float = 10.0,
+        transport: httpx.BaseTransport | None = None,
+    ) -> None:
+        self._client = httpx.Client(
+            base_url=base_url.rstrip("/"),
+            headers={"Authorization": f"Bearer {token}"},
+            timeout=timeout_seconds,
+            transport=transport,
+        )
+
+    def request(
+        self,
+        method: str,
+        path: str,
+        body: dict[str, Any] | None = None,
+        *,
+        missing_ok: bool = False,
+    ) -> dict[str, Any] | None:
+        response = self._client.request(method, path, json=body)
+        if missing_ok and response.status_code == 404:
+            return None
+        response.raise_for_status()
+        payload: dict[str, Any] | None = response.json() if response.content else None
+        return payload
+
+    def close(self) -> None:
+        self._client.close()
+
+
+def cluster_endpoint(cluster: Resource) -> str:
+    metadata = cluster["metadata"]
+    return f"http://{metadata['name']}.{metadata['namespace']}.svc:{API_PORT}"
+
+
+def migration_job_name(cluster: Resource) -> str:
+    digest = hashlib.sha256(cluster["spec"]["image"].encode("utf-8")).hexdigest()[:10]
+    return f"{cluster['metadata']['name']}-migrate-{digest}"
+
+
+def owner_reference(resource: Resource) -> dict[str, Any]:
+    metadata = resource["metadata"]
+    return {
+        "apiVersion": resource["apiVersion"],
+        "kind": resource["kind"],
+        "name": metadata["name"],
+        "uid": metadata["uid"],
+        "controller": True,
+        "blockOwnerDeletion": True,
+    }
+
+
+def _labels(cluster: Resource, component: str) -> dict[str, str]:
+    return {
+        "app.kubernetes.io/name": "orbit",
+        "app.kubernetes.io/instance": cluster["metadata"]["name"],
+        "app.kubernetes.io/component": component,
+        "app.kubernetes.io/managed-by": FIELD_MANAGER,
+    }
+
+
+def _metadata(cluster: Resource, name: str, component: str) -> dict[str, Any]:
+    return {
+        "name": name,
+        "namespace": cluster["metadata"]["namespace"],
+        "labels": _labels(cluster, component),
+        "ownerReferences": [owner_reference(cluster)],
+    }
+
+
+def _container(
+    cluster: Resource,
+    *,
+    name: str,
+    image: str,
+    command: list[str] | None = None,
+    resources: dict[str, Any] | None = None,
+) -> dict[str, Any]:
+    spec = cluster["spec"]
+    container: dict[str, Any] = {
+        "name": name,
+        "image": image,
+        "imagePullPolicy": spec.get("imagePullPolicy", "IfNotPresent"),
+        # Migrations run once per image in their own Job, never at pod start.
+        "env": [{"name": "ORBIT_AUTO_MIGRATE", "value": "false"}, *spec.get("env", [])],
+        "envFrom": list(spec.get("envFrom", [])),
+    }
+    if command is not None:
+        container["command"] = command
+    if resources:
+        container["resources"] = resources
+    return container
+
+
+def _pod_template(cluster: Resource, component: str, container: dict[str, Any]) -> Resource:
+    spec = cluster["spec"]
+    pod_spec: dict[str, Any] = {"containers": [container]}
+    if spec.get("serviceAccountName"):
+        pod_spec["serviceAccountName"] = spec["serviceAccountName"]
+    if spec.get("imagePullSecrets"):
+        pod_spec["imagePullSecrets"] = spec["imagePullSecrets"]
+    return {"metadata": {"labels": _labels(cluster, component)}, "spec": pod_spec}
+
+
+def migration_job(cluster: Resource) -> Resource:
+    container = _container(
+        cluster,
+        name="migrate",
+        image=cluster["spec"]["image"],
+        command=["orbit", "migrate"],
+    )
+    template = _pod_template(cluster, "migrate", container)
+    template["spec"]["restartPolicy"] = "Never"
+    return {
+        "apiVersion": "batch/v1",
+        "kind": "Job",
+        "metadata": _metadata(cluster, migration_job_name(cluster), "migrate"),
+        "spec": {"backoffLimit": 3, "ttlSecondsAfterFinished": 86400, "template": template},
+    }
+
+
+def _deployment(
+    cluster: Resource,
+    *,
+    name: str,
+    component: str,
+    container: dict[str, Any],
+    pool: dict[str, Any],
+) -> Resource:
+    spec: dict[str, Any] = {
+        "selector": {"matchLabels": _labels(cluster, component)},
+        "template": _pod_template(cluster, component, container),
+    }
+    # With an autoscaler the replica count belongs to the HPA, so it is left out here.
+    if not pool.get("autoscaling"):
+        spec["replicas"] = int(pool.get("replicas", 1))
+    return {
+        "apiVersion": "apps/v1",
+        "kind": "Deployment",
+        "metadata": _metadata(cluster, name, component),
+        "spec": spec,
+    }
+
+
+def _autoscaler(cluster: Resource, *, name: str, component: str, pool: dict[str, Any]) -> Resource:
+    autoscaling = pool["autoscaling"]
+    return {
+        "apiVersion": "autoscaling/v2",
+        "kind": "HorizontalPodAutoscaler",
+        "metadata": _metadata(cluster, name, component),
+        "spec": {
+            "scaleTargetRef": {"apiVersion": "apps/v1", "kind": "Deployment", "name": name},
+            "minReplicas": int(autoscaling.get("minReplicas", 1)),
+            "maxReplicas": int(autoscaling["maxReplicas"]),
+            "metrics": [
+                {
+                    "type": "Resource",
+                    "resource": {
+                        "name": "cpu",
+                        "target": {
+                            "type": "Utilization",
+                            "averageUtilization": int(
+                                autoscaling.get("targetCPUUtilizationPercentage", 80)
+                            ),
+                        },
+                    },
+                }
+            ],
+        },
+    }
+
+
+def workload_manifests(cluster: Resource, *, image: str) -> list[Resource]:
+    """The API Service and every Deployment and autoscaler, running ``image``."""
+    spec = cluster["spec"]
+    name = cluster["metadata"]["name"]
+    api = dict(spec.get("api") or {})
+    api_container = _container(
+        cluster,
+        name="api",
+        image=image,
+        resources=api.get("resources"),
+    )
+    api_container["ports"] = [{"name": "http", "containerPort": API_PORT}]
+    api_container["readinessProbe"] = {"httpGet": {"path": "/v1/health", "port": "http"}}
+    manifests = [
+        {
+            "apiVersion": "v1",
+            "kind": "Service",
+            "metadata": _metadata(cluster, name, "api"),
+            "spec": {
+                "selector": _labels(cluster, "api"),
+                "ports": [{"name": "http", "port": API_PORT, "targetPort": "http"}],
+            },
+        },
+        _deployment(
+            cluster,
+            name=f"{name}-api",
+            component="api",
+            container=api_container,
+            pool=api,
+        ),
+    ]
+    if api.get("autoscaling"):
+        manifests.append(_autoscaler(cluster, name=f"{name}-api", component="api", pool=api))
+    for pool in spec.get("workers", []):
+        component = f"worker-{pool['name']}"
+        container = _container(
+            cluster,
+            name=pool["name"],
+            image=image,
+            command=["orbit", *pool["args"]],
+            resources=pool.get("resources"),
+        )
+        deployment_name = f"{name}-{pool['name']}"
+        manifests.append(
+            _deployment(
+                cluster,
+                name=deployment_name,
+                component=component,
+                container=container,
+                pool=pool,
+            )
+        )
+        if pool.get("autoscaling"):
+            manifests.append(
+                _autoscaler(cluster, name=deployment_name, component=component, pool=pool)
+            )
+    return manifests
+
+
+def _spec_hash(value: Any) -> str:
+    encoded = json.dumps(value, sort_keys=True, separators=(",", ":")).encode("utf-8")
+    return hashlib.sha256(encoded).hexdigest()[:16]
+
+
+def _parse_time(value: str | None) -> datetime | None:
+    return datetime.fromisoformat(value) if value else None
+
+
+def _job_failed(job: Resource) -> bool:
+    conditions = (job.get("status") or {}).get("conditions") or []
+    return any(item.get("type") == "Failed" and item.get("status") == "True" for item in conditions)
+
+
+class OrbitOperator:
+    """Reconciles every Orbit resource once per ``reconcile_once`` call.
+
+    A failure is recorded on the resource's status (``phase: Error``) and retried on the next
+    pass; it never stops the other resources from being reconciled.
+    """
+
+    def __init__(
+        self,
+        kube: KubeClient,
+        *,
+        resync_interval: timedelta = timedelta(minutes=10),
+        admin_factory: Callable[[str, str], OrbitAdminClient] | None = None,
+    ) -> None:
+        self._kube = kube
+        self._resync_interval = resync_interval
+        self._admin_factory = admin_factory or OrbitAdminClient
+        self._log = get_logger("orbit.api.operator")
+
+    def reconcile_once(self, *, now: datetime | None = None) -> dict[str, int]:
+        """Reconcile every resource; returns how many are ready, pending, or failed."""
+        current = now or datetime.now(UTC)
+        counts = {"ready": 0, "pending": 0, "failed": 0}
+        handlers: list[tuple[str, Callable[[Resource, datetime], dict[str, Any] | None]]] = [
+            ("OrbitCluster", self._reconcile_cluster),
+            ("OrbitNamespace", self._reconcile_namespace),
+            ("OrbitAPIKey", self._reconcile_api_key),
+        ]
+        for kind, handler in handlers:
+            for resource in self._kube.list_resources(API_VERSION, kind):
+                metadata = resource["metadata"]
+                previous = resource.get("status") or {}
+                try:
+                    status = handler(resource, current)
+                except ReconcilePending as exc:
+                    status = {**previous, "phase": "Pending", "message": str(exc)}
+                except Exception as exc:  # pylint: disable=broad-exception-caught
+                    self._log.warning(
+                        "operator_reconcile_failed",
+                        kind=kind,
+                        name=metadata["name"],
+                        namespace=metadata["namespace"],
+                        error=str(exc),
+                    )
+                    status = {**previous, "phase": "Error", "message": str(exc)[:1000]}
+                if status is None:
+                    continue
+                status["observedGeneration"] = metadata.get("generation")
+                if status.get("phase") == "Ready":
+                    counts["ready"] += 1
+                elif status.get("phase") == "Error":
+                    counts["failed"] += 1
+                else:
+                    counts["pending"] += 1
+                current_status = {key: value for key, value in status.items() if value is not None}
+                if current_status != previous:
+                    # A merge patch only removes fields that are explicitly null.
+                    cleared = {key: None for key in previous if key not in current_status}
+                    self._kube.patch_status(resource, {**cleared, **current_status})
+        return counts
+
+    def _reconcile_cluster(self, cluster: Resource, now: datetime) -> dict[str, Any]:
+        spec = cluster["spec"]
+        metadata = cluster["metadata"]
+        namespace = metadata["namespace"]
+        previous = cluster.get("status") or {}
+        status: dict[str, Any] = {
+            "endpoint": cluster_endpoint(cluster),
+            "migratedImage": previous.get("migratedImage"),
+            "workerPools": [pool["name"] for pool in spec.get("workers", [])],
+        }
+        if not (spec.get("migrations") or {}).get("enabled", True):
+            status["migratedImage"] = spec["image"]
+        elif status["migratedImage"] != spec["image"]:
+            job_name = migration_job_name(cluster)
+            job = self._kube.get("batch/v1", "Job", name=job_name, namespace=namespace)
+            if job is None:
+                self._kube.apply(migration_job(cluster))
+                status.update(phase="Migrating", message=f"running migration job {job_name}")
+            elif (job.get("status") or {}).get("succeeded"):
+                status["migratedImage"] = spec["image"]
+            elif _job_failed(job):
+                status.update(phase="MigrationFailed", message=f"migration job {job_name} failed")
+            else:
+                status.update(phase="Migrating", message=f"running migration job {job_name}")
+
+        # Until the new image has migrated, scaling changes still roll out on the old one.
+        image = status["migratedImage"]
+        if image is None:
+            return status
+        for manifest in workload_manifests(cluster, image=image):
+            self._kube.apply(manifest)
+        self._prune_workers(cluster, previous.get("workerPools") or [])
+        deployment = self._kube.get(
+            "apps/v1",
+            "Deployment",
+            name=f"{metadata['name']}-api",
+            namespace=namespace,
+        )
+        ready = int(((deployment or {}).get("status") or {}).get("readyReplicas") or 0)
+        status["readyReplicas"] = ready
+        if "phase" not in status:
+            status.update(
+                phase="Ready" if ready else "Progressing",
+                message="" if ready else "waiting for API pods",
+            )
+        return status
+
+    def _prune_workers(self, cluster: Resource, previous_pools: list[str]) -> None:
+        spec = cluster["spec"]
+        metadata = cluster["metadata"]
+        pools = {pool["name"]: pool for pool in spec.get("workers", [])}
+        autoscaled = [("api", spec.get("api") or {})] + list(pools.items())
+        for pool_name in previous_pools:
+            if pool_name not in pools:
+                self._kube.delete(
+                    "apps/v1",
+                    "Deployment",
+                    name=f"{metadata['name']}-{pool_name}",
+                    namespace=metadata["namespace"],
+                )
+                autoscaled.append((pool_name, {}))
+        for pool_name, pool in autoscaled:
+            if not pool.get("autoscaling"):
+                self._kube.delete(
+                    "autoscaling/v2",
+                    "HorizontalPodAutoscaler",
+                    name=f"{metadata['name']}-{pool_name}",
+                    namespace=metadata["namespace"],
+                )
+
+    def _admin(self, resource: Resource) -> OrbitAdminClient | None:
+        """The admin client for a resource's cluster; ``None`` once the cluster is gone."""
+        namespace = resource["metadata"]["namespace"]
+        cluster_name = resource["spec"]["clusterRef"]
+        cluster = self._kube.get(
+            API_VERSION,
+            "OrbitCluster",
+            name=cluster_name,
+            namespace=namespace,
+        )
+        if cluster is None:
+            return None
+        if (cluster.get("status") or {}).get("phase") != "Ready":
+            msg = f"waiting for OrbitCluster {cluster_name} to be ready"
+            raise ReconcilePending(msg)
+        secret_ref = cluster["spec"].get("adminTokenSecret") or {}
+        secret_name = secret_ref.get("name", f"{cluster_name}-admin")
+        secret = self._kube.get("v1", "Secret", name=secret_name, namespace=namespace)
+        encoded = ((secret or {}).get("data") or {}).get(secret_ref.get("key", "token"))
+        if not encoded:
+            msg = f"admin token secret {secret_name} is missing or empty"
+            raise ReconcilePending(msg)
+        token = base64.b64decode(encoded).decode("utf-8").strip()
+        return self._admin_factory(cluster_endpoint(cluster), token)
+
+    def _ensure_finalizer(self, resource: Resource) -> None:
+        finalizers = list(resource["metadata"].get("finalizers") or [])
+        if FINALIZER not in finalizers:
+            self._kube.set_finalizers(resource, [*finalizers, FINALIZER])
+
+    def _release(self, resource: Resource) -> None:
+        finalizers = resource["metadata"].get("finalizers") or []
+        if FINALIZER in finalizers:
+            self._kube.set_finalizers(resource, [item for item in finalizers if item != FINALIZER])
+
+    def _reconcile_namespace(self, resource: Resource, now: datetime) -> dict[str, Any] | None:
+        spec = resource["spec"]
+        account_key = spec.get("accountKey") or resource["metadata"]["name"]
+        tenant = quote(account_key, safe="")
+        deleting = bool(resource["metadata"].get("deletionTimestamp"))
+        admin = self._admin(resource)
+        if admin is None:
+            if deleting:
+                self._release(resource)
+                return None
+            msg = f"OrbitCluster {spec['clusterRef']} not found"
+            raise ReconcilePending(msg)
+        try:
+            if deleting:
+                admin.request("DELETE", f"/v1/admin/tenants/{tenant}/retention", missing_ok=True)
+                admin.request("DELETE", f"/v1/admin/tenants/{tenant}/event-types", missing_ok=True)
+                admin.request("DELETE", f"/v1/admin/namespaces/{tenant}", missing_ok=True)
+                self._release(resource)
+                return None
+            self._ensure_finalizer(resource)
+            previous = resource.get("status") or {}
+            applied_hash = _spec_hash(spec)
+            applied_at = _parse_time(previous.get("appliedAt"))
+            if (
+                previous.get("phase") == "Ready"
+                and previous.get("appliedHash") == applied_hash
+                and applied_at is not None
+                and now - applied_at < self._resync_interval
+            ):
+                return dict(previous)
+            admin.request(
+                "PUT",
+                f"/v1/admin/namespaces/{tenant}",
+                {
+                    "display_name": spec.get("displayName"),
+                    "description": spec.get("description"),
+                    "pipeline": spec.get("pipeline"),
+                },
+            )
+            event_types = spec.get("eventTypes")
+            if event_types is None:
+                admin.request("DELETE", f"/v1/admin/tenants/{tenant}/event-types", missing_ok=True)
+            else:
+                admin.request(
+                    "PUT",
+                    f"/v1/admin/tenants/{tenant}/event-types",
+                    {
+                        "event_types": event_types.get("types", []),
+                        "enforce": event_types.get("enforce", True),
+                    },
+                )
+            retention = spec.get("retention")
+            if retention is None:
+                admin.request("DELETE", f"/v1/admin/tenants/{tenant}/retention", missing_ok=True)
+            else:
+                admin.request(
+                    "PUT",
+                    f"/v1/admin/tenants/{tenant}/retention",
+                    {"days": retention["days"], "event_types": retention.get("eventTypes", {})},
+                )
+        finally:
+            admin.close()
+        return {
+            "phase": "Ready",
+            "message": "",
+            "accountKey": account_key,
+            "appliedHash": applied_hash,
+            "appliedAt": now.isoformat(),
+        }
+
+    def _reconcile_api_key(self, resource: Resource, now: datetime) -> dict[str, Any] | None:
+        spec = resource["spec"]
+        metadata = resource["metadata"]
+        account_key = spec["accountKey"]
+        keys_path = f"/v1/admin/tenants/{quote(account_key, safe='')}/keys"
+        previous = resource.get("status") or {}
+        deleting = bool(metadata.get("deletionTimestamp"))
+        admin = self._admin(resource)
+        if admin is None:
+            if deleting:
+                self._release(resource)
+                return None
+            msg = f"OrbitCluster {spec['clusterRef']} not found"
+            raise ReconcilePending(msg)
+
+        def revoke(key_id: str | None) -> None:
+            if key_id:
+                path = f"{keys_path}/{quote(key_id, safe='')}/revoke"
+                admin.request("POST", path, missing_ok=True)
+
+        try:
+            if deleting:
+                revoke(previous.get("keyId"))
+                revoke(previous.get("previousKeyId"))
+                self._release(resource)
+                return None
+            self._ensure_finalizer(resource)
+            status = {key: value for key, value in previous.items() if key != "message"}
+            secret_name = spec.get("secretName") or f"{metadata['name']}-orbit-api-key"
+            key_spec = {
+                "name": spec.get("name") or metadata["name"],
+                "scopes": spec.get("scopes"),
+                "allowed_origins": spec.get("allowedOrigins"),
+            }
+            rotate_token = (metadata.get("annotations") or {}).get(ROTATE_ANNOTATION)
+            reason = self._rotation_reason(
+                admin,
+                keys_path,
+                status,
+                spec_hash=_spec_hash(key_spec),
+                rotate_token=rotate_token,
+                secret_name=secret_name,
+                namespace=metadata["namespace"],
+                rotation_days=int(spec.get("rotationDays") or 0),
+                now=now,
+            )
+            if reason is not None:
+                issued = admin.request(
+                    "POST",
+                    keys_path,
+                    {key: value for key, value in key_spec.items() if value is not None},
+                ) or {}
+                self._kube.apply(
+                    self._key_secret(resource, secret_name, issued, self._endpoint(resource))
+                )
+                # Only one previous key is kept, so an older one still in its grace period goes.
+                revoke(status.get("previousKeyId"))
+                grace = int(spec.get("rotationGraceSeconds", DEFAULT_ROTATION_GRACE_SECONDS))
+                replaced = status.get("keyId") if reason != "revoked" else None
+                status.update(
+                    keyId=issued["key_id"],
+                    keyPrefix=issued.get("key_prefix"),
+                    issuedAt=now.isoformat(),
+                    specHash=_spec_hash(key_spec),
+                    rotateToken=rotate_token,
+                    secretName=secret_name,
+                    previousKeyId=replaced,
+                    revokePreviousAt=None,
+                    lastRotationReason=reason,
+                )
+                if replaced:
+                    status["revokePreviousAt"] = (now + timedelta(seconds=grace)).isoformat()
+                self._log.info(
+                    "operator_api_key_issued",
+                    name=metadata["name"],
+                    namespace=metadata["namespace"],
+                    account=account_key,
+                    key_id=issued["key_id"],
+                    reason=reason,
+                )
+            revoke_at = _parse_time(status.get("revokePreviousAt"))
+            if status.get("previousKeyId") and revoke_at is not None and now >= revoke_at:
+                revoke(status["previousKeyId"])
+                status.update(previousKeyId=None, revokePreviousAt=None)
+        finally:
+            admin.close()
+        status.update(phase="Ready", message="")
+        return status
+
+    def _rotation_reason(
+        self,
+        admin: OrbitAdminClient,
+        keys_path: str,
+        status: dict[str, Any],
+        *,
+        spec_hash: str,
+        rotate_token: str | None,
+        secret_name: str,
+        namespace: str,
+        rotation_days: int,
+        now: datetime,
+    ) -> str | None:
+        """Why a new key must be issued, or ``None`` if the current one is still good."""
+        key_id = status.get("keyId")
+        if not key_id:
+            return "created"
+        if status.get("specHash") != spec_hash:
+            return "spec_changed"
+        if rotate_token is not None and rotate_token != status.get("rotateToken"):
+            return "requested"
+        issued_at = _parse_time(status.get("issuedAt"))
+        rotation = timedelta(days=rotation_days)
+        if rotation_days and (issued_at is None or now - issued_at >= rotation):
+            return "scheduled"
+        if status.get("secretName") != secret_name or (
+            self._kube.get("v1", "Secret", name=secret_name, namespace=namespace) is None
+        ):
+            return "secret_missing"
+        current = admin.request(
+            "GET",
+            f"{keys_path}/{quote(key_id, safe='')}",
+            missing_ok=True,
+        )
+        if current is None or current.get("status") == "revoked":
+            return "revoked"
+        return None
+
+    def _endpoint(self, resource: Resource) -> str:
+        cluster = self._kube.get(
+            API_VERSION,
+            "OrbitCluster",
+            name=resource["spec"]["clusterRef"],
+            namespace=resource["metadata"]["namespace"],
+        )
+        return cluster_endpoint(cluster) if cluster is not None else ""
+
+    @staticmethod
+    def _key_secret(
+        resource: Resource,
+        secret_name: str,
+        issued: dict[str, Any],
+        endpoint: str,
+    ) -> Resource:
+        values = {
+            "ORBIT_API_KEY": issued["key"],
+            "ORBIT_API_KEY_ID": issued["key_id"],
+            "ORBIT_API_URL": endpoint,
+        }
+        return {
+            "apiVersion": "v1",
+            "kind": "Secret",
+            "metadata": {
+                "name": secret_name,
+                "namespace": resource["metadata"]["namespace"],
+                "labels": {"app.kubernetes.io/managed-by": FIELD_MANAGER},
+                "ownerReferences": [owner_reference(resource)],
+            },
+            "type": "Opaque",
+            "data": {
+                name: base64.b64encode(value.encode("utf-8")).decode("ascii")
+                for name, value in values.items()
+            },
+        }
@@ -0,0 +1,49 @@
+"""Alembic helpers shared by the API entrypoint and the ``orbit`` CLI."""
+
+from __future__ import annotations
+
+import os
+from pathlib import Path
+
+from alembic import command
+from alembic.config import Config as AlembicConfig
+
+from decision_engine.database_url import normalize_database_url
+from orbit.secret_sources import get_secret
+
+
+def alembic_config(database_url: str | None = None) -> AlembicConfig:
+    config_path = os.getenv("ORBIT_ALEMBIC_CONFIG", "alembic.ini")
+    config = AlembicConfig(config_path)
+    script_location = config.get_main_option("script_location")
+    if script_location and not Path(script_location).is_absolute():
+        config.set_main_option(
+            "script_location",
+            str(Path(config_path).resolve().parent / script_location),
+        )
+    resolved_url = normalize_database_url(database_url or get_secret("MDE_DATABASE_URL"))
+    if resolved_url:
+        config.set_main_option("sqlalchemy.url", resolved_url)
+    return config
+
+
+def upgrade(
+    revision: str = "head",
+    *,
+    database_url: str | None = None,
+    sql: bool = False,
+) -> None:
+    command.upgrade(alembic_config(database_url), revision, sql=sql)
+
+
+def downgrade(
+    revision: str,
+    *,
+    database_url: str | None = None,
+    sql: bool = False,
+) -> None:
+    command.downgrade(alembic_config(database_url), revision, sql=sql)
+
+
+def current(*, database_url: str | None = None) -> None:
+    command.current(alembic_config(database_url), verbose=True)
@@ -0,0 +1,164 @@
+"""Content moderation for ingested memories.
+
+A provider classifies text into categories; the operator's policy maps each category to
+``flag`` (store the memory and queue it for review) or ``block`` (refuse to store it and queue
+the content so the decision can be appealed). The built-in ``keyword`` provider needs no network
+access; ``openai`` uses the OpenAI moderation endpoint (``pip install openai``).
+"""
+
+from __future__ import annotations
+
+import re
+from dataclasses import dataclass
+from importlib import import_module
+from types import ModuleType
+from typing import Any, Protocol
+
+MODERATION_ACTIONS = ("allow", "flag", "block")
+# Content in these categories is never stored, whatever the policy says: it is always blocked,
+# its review keeps only a hash of the payload, and it is reported to the operator.
+WITHHELD_CATEGORIES = frozenset({"csam"})
+
+_KEYWORD_PATTERNS: dict[str, tuple[str, ...]] = {
+    "self_harm": (
+        r"\b(kill|hurt|harm|cut)(ing)?\s+myself\b",
+        r"\bsuicid(e|al)\b",
+        r"\bself[-\s]?harm",
+        r"\bend\s+(my|it)\s+(life|all)\b",
+    ),
+    "csam": (
+        r"\bchild\s+sexual\s+abuse\s+material\b",
+        r"\b(child|minor|underage|preteen)\s+(porn|pornography|nudes?|sexual\s+content)\b",
+    ),
+}
+# OpenAI category names, normalized to snake_case, folded into Orbit's categories.
+_OPENAI_CATEGORIES = {
+    "self_harm": "self_harm",
+    "self_harm_intent": "self_harm",
+    "self_harm_instructions": "self_harm",
+    "sexual_minors": "csam",
+    "sexual": "sexual",
+    "violence": "violence",
+    "violence_graphic": "violence",
+    "hate": "hate",
+    "hate_threatening": "hate",
+    "harassment": "harassment",
+    "harassment_threatening": "harassment",
+    "illicit": "illicit",
+    "illicit_violent": "illicit",
+}
+
+
+def _optional_import(module_name: str) -> ModuleType | None:
+    try:  # pragma: no cover - optional dependency
+        return import_module(module_name)
+    except ImportError:  # pragma: no cover - optional dependency
+        return None
+
+
+openai_module: ModuleType | None = _optional_import("openai")
+
+
+@dataclass(frozen=True)
+class ModerationVerdict:
+    action: str
+    categories: tuple[str, ...]
+    provider: str
+
+    @property
+    def withheld(self) -> bool:
+        return not WITHHELD_CATEGORIES.isdisjoint(self.categories)
+
+
+class ModerationProvider(Protocol):
+    name: str
+
+    def classify(self, text: str) -> set[str]: ...
+
+
+class KeywordModerationProvider:
+    """Regex screening for the highest-risk categories plus operator-supplied terms."""
+
+    name = "keyword"
+
+    def __init__(self, blocklist: list[str] | None = None) -> None:
+        self._patterns = {
+            category: [re.compile(pattern, re.IGNORECASE) for pattern in patterns]
+            for category, patterns in _KEYWORD_PATTERNS.items()
+        }
+        terms = [term.strip() for term in blocklist or [] if term.strip()]
+        if terms:
+            self._patterns["custom"] = [
+                re.compile(rf"\b{re.escape(term)}\b", re.IGNORECASE) for term in terms
+            ]
+
+    def classify(self, text: str) -> set[str]:
+        return {
+            category
+            for category, patterns in self._patterns.items()
+            if any(pattern.search(text) for pattern in patterns)
+        }
+
+
+class OpenAIModerationProvider:  # pragma: no cover - requires external API access
+    name = "openai"
+
+    def __init__(
+        self,
+        *,
+        api_key: str | None = None,
+        model: str = "omni-moderation-latest",
+        blocklist: list[str] | None = None,
+    ) -> None:
+        if openai_module is None:
+            msg = "openai package is not installed"
+            raise RuntimeError(msg)
+        self._client: Any = openai_module.OpenAI(api_key=api_key)
+        self._model = model
+        self._keywords = KeywordModerationProvider(blocklist)
+
+    def classify(self, text: str) -> set[str]:
+        response = self._client.moderations.create(model=self._model, input=text)
+        flagged = response.results[0].categories.model_dump()
+        categories: set[str] = set()
+        for key, value in flagged.items():
+            category = _OPENAI_CATEGORIES.get(re.sub(r"[^a-z]+", "_", key.lower()))
+            if value and category:
+                categories.add(category)
+        return categories | (self._keywords.classify(text) & {"custom"})
+
+
+def build_moderation_provider(
+    name: str,
+    *,
+    blocklist: list[str] | None = None,
+    openai_api_key: str | None = None,
+) -> ModerationProvider | None:
+    normalized = name.strip().lower()
+    if normalized == "none":
+        return None
+    if normalized == "openai":
+        return OpenAIModerationProvider(api_key=openai_api_key, blocklist=blocklist)
+    return KeywordModerationProvider(blocklist)
+
+
+def moderate(
+    provider: ModerationProvider,
+    text: str,
+    *,
+    policy: dict[str, str],
+) -> ModerationVerdict:
+    """Strictest policy action across the matched categories; unlisted categories are allowed.
+
+    Withheld categories are blocked even when the policy allows or only flags them.
+    """
+    categories = tuple(sorted(provider.classify(text)))
+    actions = {
+        "block" if category in WITHHELD_CATEGORIES else policy.get(category, "allow")
+        for category in categories
+    }
+    action = next(
+        (item for item in reversed(MODERATION_ACTIONS) if item in actions),
+        "allow",
+    )
+    return ModerationVerdict(action=action, categories=categories, provider=provider.name)
@@ -0,0 +1,44 @@
+"""Split a streamed request body into NDJSON lines as it arrives.
+
+``POST /v1/ingest/stream`` reads its body incrementally, so a producer can hold one request
+open and write an event per line; each line is handled as soon as its newline arrives rather
+than when the request ends.
+"""
+
+from __future__ import annotations
+
+from collections.abc import AsyncIterable, AsyncIterator
+
+
+class LineTooLongError(ValueError):
+    def __init__(self, line: int, max_line_bytes: int) -> None:
+        super().__init__(f"line {line} exceeds ORBIT_MAX_STREAM_LINE_BYTES={max_line_bytes}")
+        self.line = line
+
+
+async def iter_lines(
+    chunks: AsyncIterable[bytes],
+    *,
+    max_line_bytes: int,
+) -> AsyncIterator[tuple[int, bytes]]:
+    """Yield ``(line_number, line)`` for each non-blank line, numbered from 1.
+
+    Blank lines are skipped but still counted, so numbers match the producer's line count.
+    A line longer than ``max_line_bytes`` raises ``LineTooLongError`` before it is buffered in
+    full.
+    """
+    buffer = b""
+    number = 0
+    async for chunk in chunks:
+        buffer += chunk
+        *lines, buffer = buffer.split(b"\n")
+        for line in lines:
+            number += 1
+            if len(line) > max_line_bytes:
+                raise LineTooLongError(number, max_line_bytes)
+            if line.strip():
+                yield number, line
+        if len(buffer) > max_line_bytes:
+            raise LineTooLongError(number + 1, max_line_bytes)
+    if buffer.strip():
+        yield number + 1, buffer
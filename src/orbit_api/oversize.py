@@ -0,0 +1,165 @@
+"""Shrink ingest content that exceeds ``ORBIT_MAX_INGEST_CONTENT_CHARS``.
+
+``summarize`` keeps the highest-scoring sentences (by the frequency of their words across the
+whole text) in their original order, always starting with the first sentence, until the
+summary budget is spent. ``chunk`` splits on paragraph, then sentence, then word boundaries so
+no piece exceeds the limit. Both are deterministic, so replaying a request yields the same
+memories.
+
+Chunks only re-space the original text, so :func:`chunk_spans` can map each back to its
+character range in the document, and :func:`page_number` to its page when the text marks page
+breaks with form feeds, as PDF text extractors do.
+"""
+
+from __future__ import annotations
+
+import re
+from collections import Counter
+
+_PARAGRAPH_BREAK = re.compile(r"\n\s*\n")
+_SENTENCE_END = re.compile(r"(?<=[.!?])\s+")
+_WORD = re.compile(r"[A-Za-z0-9][A-Za-z0-9'_-]*")
+_STOPWORDS = frozenset(
+    {
+        "a", "an", "and", "are", "as", "at", "be", "been", "but", "by", "can", "do", "for",
+        "from", "had", "has", "have", "he", "her", "his", "i", "if", "in", "into", "is", "it",
+        "its", "me", "my", "no", "not", "of", "on", "or", "our", "she", "so", "that", "the",
+        "their", "them", "then", "there", "they", "this", "to", "us", "was", "we", "were",
+        "what", "when", "which", "who", "will", "with", "would", "you", "your",
+    }
+)  # fmt: skip
+_ELLIPSIS = "..."
+
+
+def summarize_content(content: str, max_chars: int) -> str:
+    """Extractive summary of ``content`` in at most ``max_chars`` characters."""
+    text = content.strip()
+    if len(text) <= max_chars:
+        return text
+    sentences = _sentences(text)
+    words = (match.lower() for match in _WORD.findall(text))
+    frequencies = Counter(word for word in words if word not in _STOPWORDS)
+
+    def score(sentence: str) -> float:
+        words = [word.lower() for word in _WORD.findall(sentence)]
+        content_words = [word for word in words if word not in _STOPWORDS]
+        if not content_words:
+            return 0.0
+        return sum(frequencies[word] for word in content_words) / len(words)
+
+    ranked = sorted(range(1, len(sentences)), key=lambda index: (-score(sentences[index]), index))
+    chosen = [0]
+    used = len(sentences[0])
+    for index in ranked:
+        cost = len(sentences[index]) + 1
+        if used + cost <= max_chars:
+            chosen.append(index)
+            used += cost
+    summary = " ".join(sentences[index] for index in sorted(chosen))
+    if len(summary) <= max_chars:
+        return summary
+    # The first sentence alone is over budget.
+    return _clip_words(summary, max_chars - len(_ELLIPSIS)) + _ELLIPSIS
+
+
+def chunk_content(content: str, max_chars: int) -> list[str]:
+    """Split ``content`` into pieces of at most ``max_chars`` characters, in order."""
+    chunks: list[str] = []
+    current = ""
+    for paragraph in _PARAGRAPH_BREAK.split(content.strip()):
+        for piece in _pieces(paragraph.strip(), max_chars):
+            if not piece:
+                continue
+            joined = f"{current}\n\n{piece}" if current else piece
+            if len(joined) <= max_chars:
+                current = joined
+                continue
+            if current:
+                chunks.append(current)
+            current = piece
+    if current:
+        chunks.append(current)
+    return chunks
+
+
+def chunk_spans(content: str, chunks: list[str]) -> list[tuple[int, int]]:
+    """``(start, end)`` character offsets in ``content`` of each chunk from ``chunk_content``."""
+    spans: list[tuple[int, int]] = []
+    cursor = 0
+    for chunk in chunks:
+        start = end = -1
+        for char in chunk:
+            if char.isspace():
+                continue
+            while cursor < len(content) and content[cursor] != char:
+                cursor += 1
+            if start < 0:
+                start = cursor
+            cursor += 1
+            end = cursor
+        spans.append((max(start, 0), max(end, 0)))
+    return spans
+
+
+def page_number(content: str, offset: int) -> int:
+    """The 1-based page of ``offset``, counting form feeds (``\\f``) before it as page breaks."""
+    return content.count("\f", 0, offset) + 1
+
+
+def _pieces(paragraph: str, max_chars: int) -> list[str]:
+    if len(paragraph) <= max_chars:
+        return [paragraph]
+    pieces: list[str] = []
+    current = ""
+    for sentence in _sentences(paragraph, normalize=False):
+        for part in _split_long(sentence, max_chars):
+            joined = f"{current} {part}" if current else part
+            if len(joined) <= max_chars:
+                current = joined
+                continue
+            if current:
+                pieces.append(current)
+            current = part
+    if current:
+        pieces.append(current)
+    return pieces
+
+
+def _split_long(sentence: str, max_chars: int) -> list[str]:
+    """Split one sentence on whitespace, and inside words only when a word is over the limit."""
+    if len(sentence) <= max_chars:
+        return [sentence]
+    parts: list[str] = []
+    current = ""
+    for word in sentence.split():
+        while len(word) > max_chars:
+            if current:
+                parts.append(current)
+                current = ""
+            parts.append(word[:max_chars])
+            word = word[max_chars:]
+        if not word:
+            continue
+        joined = f"{current} {word}" if current else word
+        if len(joined) <= max_chars:
+            current = joined
+        else:
+            parts.append(current)
+            current = word
+    if current:
+        parts.append(current)
+    return parts
+
+
+def _sentences(text: str, *, normalize: bool = True) -> list[str]:
+    sentences = [sentence.strip() for sentence in _SENTENCE_END.split(text) if sentence.strip()]
+    if normalize:
+        return [" ".join(sentence.split()) for sentence in sentences]
+    return sentences
+
+
+def _clip_words(text: str, max_chars: int) -> str:
+    clipped = text[: max(max_chars, 0)]
+    if " " in clipped and len(text) > max_chars:
+        clipped = clipped.rsplit(" ", 1)[0]
+    return clipped.rstrip()
@@ -0,0 +1,59 @@
+"""Redact personal data from ingested content before it is extracted, embedded, or stored.
+
+Each match is replaced by a typed placeholder such as ``[EMAIL]`` so the memory keeps its
+sentence structure. Card numbers must pass the Luhn check and phone numbers need at least nine
+digits, which keeps order numbers and short codes intact.
+"""
+
+from __future__ import annotations
+
+import re
+
+PII_KINDS = ("email", "card_number", "ssn", "phone", "ip_address")
+
+_OCTET = r"(?:25[0-5]|2[0-4]\d|1?\d?\d)"
+
+_PATTERNS: dict[str, re.Pattern[str]] = {
+    "email": re.compile(r"\b[\w.+-]+@[\w-]+(?:\.[\w-]+)+\b"),
+    "card_number": re.compile(r"\b(?:\d[ -]?){12,18}\d\b"),
+    "ssn": re.compile(r"\b\d{3}-\d{2}-\d{4}\b"),
+    "phone": re.compile(r"(?<![\w+])\+?\(?\d[\d ().-]{7,}\d\b"),
+    "ip_address": re.compile(rf"\b(?:{_OCTET}\.){{3}}{_OCTET}\b"),
+}
+
+
+def redact_pii(text: str) -> tuple[str, list[str]]:
+    """Return ``text`` with personal data replaced and the kinds that were found, in order."""
+    found: list[str] = []
+    for kind in PII_KINDS:
+        pattern = _PATTERNS[kind]
+
+        def replace(match: re.Match[str], kind: str = kind) -> str:
+            if not _accept(kind, match.group(0)):
+                return match.group(0)
+            if kind not in found:
+                found.append(kind)
+            return f"[{kind.upper()}]"
+
+        text = pattern.sub(replace, text)
+    return text, found
+
+
+def _accept(kind: str, value: str) -> bool:
+    digits = [int(char) for char in value if char.isdigit()]
+    if kind == "card_number":
+        return 13 <= len(digits) <= 19 and _luhn_valid(digits)
+    if kind == "phone":
+        return 9 <= len(digits) <= 15
+    return True
+
+
+def _luhn_valid(digits: list[int]) -> bool:
+    total = 0
+    for index, digit in enumerate(reversed(digits)):
+        if index % 2 == 1:
+            digit *= 2
+            if digit > 9:
+                digit -= 9
+        total += digit
+    return total % 10 == 0
@@ -0,0 +1,251 @@
+"""Ingest pipeline declared as an ordered graph of named stages.
+
+Every ingested event runs through the stages configured for its namespace::
+
+    moderation -> pii -> webhook -> extraction -> dedup -> sentiment -> categorization
+        -> embedding -> indexing
+
+``embedding`` and ``indexing`` are required; the rest can be left out, and stages may be
+reordered as long as each one still runs after the stages it depends on (``_DEPENDS_ON``).
+``indexing`` writes the memory, so it always runs last, after the whole batch has passed the
+earlier stages. A stage can halt an event (moderation blocks it, dedup finds an existing copy),
+which skips the remaining stages for that event.
+
+Operators can add their own stages as ``wasm:<name>`` entries (see ``orbit_api.wasm_stage``);
+they may go anywhere before ``indexing``.
+"""
+
+from __future__ import annotations
+
+import threading
+from collections.abc import Callable, Iterable, Mapping, Sequence
+from dataclasses import dataclass, field
+from time import perf_counter
+from typing import TYPE_CHECKING, Any
+
+if TYPE_CHECKING:
+    # Type-only imports keep this module light enough for ``orbit_api.config`` to import.
+    from decision_engine.models import MemoryRecord
+    from memory_engine.models.processed_event import ProcessedEvent
+    from memory_engine.models.storage_decision import StorageDecision
+    from memory_engine.stage1_input.processor import ExtractedEvent
+    from orbit.models import IngestRequest
+    from orbit_api.moderation import ModerationVerdict
+
+PIPELINE_STAGES = (
+    "moderation",
+    "pii",
+    "webhook",
+    "extraction",
+    "dedup",
+    "sentiment",
+    "categorization",
+    "embedding",
+    "indexing",
+)
+# ``webhook`` does nothing for accounts without a configured transform webhook.
+DEFAULT_PIPELINE = (
+    "moderation",
+    "webhook",
+    "extraction",
+    "categorization",
+    "embedding",
+    "indexing",
+)
+REQUIRED_STAGES = ("embedding", "indexing")
+CUSTOM_STAGE_PREFIX = "wasm:"
+
+# Stages that must come earlier whenever both are enabled.
+_DEPENDS_ON: dict[str, tuple[str, ...]] = {
+    "embedding": ("extraction",),
+    "indexing": (
+        "moderation",
+        "pii",
+        "webhook",
+        "extraction",
+        "dedup",
+        "sentiment",
+        "categorization",
+        "embedding",
+    ),
+}
+
+
+def validate_pipeline(stages: Iterable[str]) -> list[str]:
+    """Normalize a stage list and check it forms a valid pipeline."""
+    normalized = [stage.strip().lower() for stage in stages if stage.strip()]
+    unknown = [
+        stage
+        for stage in normalized
+        if stage not in PIPELINE_STAGES and not is_custom_stage(stage)
+    ]
+    if unknown:
+        msg = (
+            f"unknown pipeline stage(s): {', '.join(unknown)} "
+            f"(expected: {', '.join(PIPELINE_STAGES)}, or {CUSTOM_STAGE_PREFIX}<name>)"
+        )
+        raise ValueError(msg)
+    if len(set(normalized)) != len(normalized):
+        msg = "pipeline stages must not repeat"
+        raise ValueError(msg)
+    missing = [stage for stage in REQUIRED_STAGES if stage not in normalized]
+    if missing:
+        msg = f"pipeline must include: {', '.join(missing)}"
+        raise ValueError(msg)
+    for index, stage in enumerate(normalized):
+        later = normalized[index + 1 :]
+        for dependency in _DEPENDS_ON.get(stage, ()):
+            if dependency in later:
+                msg = f"pipeline stage {dependency} must run before {stage}"
+                raise ValueError(msg)
+    if normalized[-1] != "indexing":
+        msg = "pipeline stage indexing must run last"
+        raise ValueError(msg)
+    return normalized
+
+
+def is_custom_stage(stage: str) -> bool:
+    return stage.startswith(CUSTOM_STAGE_PREFIX) and bool(stage[len(CUSTOM_STAGE_PREFIX) :])
+
+
+def parse_pipeline(value: str | Sequence[str]) -> list[str]:
+    """Parse ``pii>extraction>embedding>indexing`` (or a comma-separated list)."""
+    if isinstance(value, str):
+        value = value.replace(">", ",").split(",")
+    return validate_pipeline(value)
+
+
+def parse_namespace_pipelines(value: str) -> dict[str, list[str]]:
+    """Parse ``acme=pii>extraction>embedding>indexing,bigco=...`` into namespace -> stages."""
+    pipelines: dict[str, list[str]] = {}
+    for item in value.split(","):
+        if not item.strip():
+            continue
+        namespace, separator, stages = item.partition("=")
+        if not separator or not namespace.strip():
+            msg = f"invalid pipeline entry: {item.strip()!r}"
+            raise ValueError(msg)
+        pipelines[namespace.strip()] = parse_pipeline(stages)
+    return pipelines
+
+
+@dataclass
+class IngestContext:
+    """One event on its way through the pipeline; stages read and fill in its fields."""
+
+    request: IngestRequest
+    account_key: str
+    content: str
+    metadata: dict[str, Any]
+    # Part of a bulk backfill: embedded with its batch, and stored without consolidation.
+    backfill: bool = False
+    verdict: ModerationVerdict | None = None
+    extracted: ExtractedEvent | None = None
+    processed: ProcessedEvent | None = None
+    decision: StorageDecision | None = None
+    stored: MemoryRecord | None = None
+    duplicate_of: MemoryRecord | None = None
+    halted_by: str | None = None
+    halt_reason: str | None = None
+    stage_latency_ms: dict[str, float] = field(default_factory=dict)
+
+    def halt(self, stage: str, *, reason: str | None = None) -> None:
+        self.halted_by = stage
+        self.halt_reason = reason
+
+    def add_relationships(self, *relationships: str) -> None:
+        self.metadata["relationships"] = [
+            *[str(item) for item in self.metadata.get("relationships", [])],
+            *relationships,
+        ]
+
+    def screened_request(self) -> IngestRequest:
+        """The request as the stages have rewritten it, e.g. with PII redacted."""
+        if self.content == self.request.content:
+            return self.request
+        return self.request.model_copy(update={"content": self.content})
+
+
+Stage = Callable[[IngestContext], None]
+
+
+@dataclass
+class StageMetrics:
+    runs: int = 0
+    halts: int = 0
+    failures: int = 0
+    latency_ms_sum: float = 0.0
+
+
+class IngestPipeline:
+    """Runs configured stage orders over ``IngestContext`` and keeps per-stage counters."""
+
+    def __init__(self, stages: Mapping[str, Stage]) -> None:
+        missing = [name for name in PIPELINE_STAGES if name not in stages]
+        if missing:
+            msg = f"no implementation for pipeline stage(s): {', '.join(missing)}"
+            raise ValueError(msg)
+        self._stages = dict(stages)
+        self._metrics = {name: StageMetrics() for name in self._stages}
+        self._lock = threading.Lock()
+
+    def prepare(
+        self,
+        order: Sequence[str],
+        context: IngestContext,
+        *,
+        skip: frozenset[str] = frozenset(),
+    ) -> IngestContext:
+        """Run every stage before ``indexing``."""
+        return self._run([stage for stage in order if stage != "indexing"], context, skip)
+
+    def commit(self, context: IngestContext) -> IngestContext:
+        """Run ``indexing`` unless an earlier stage halted the event."""
+        return self._run(["indexing"], context, frozenset())
+
+    def has_stage(self, name: str) -> bool:
+        return name in self._stages
+
+    def metrics_snapshot(self) -> dict[str, StageMetrics]:
+        with self._lock:
+            return {
+                name: StageMetrics(
+                    runs=item.runs,
+                    halts=item.halts,
+                    failures=item.failures,
+                    latency_ms_sum=item.latency_ms_sum,
+                )
+                for name, item in self._metrics.items()
+            }
+
+    def _run(
+        self,
+        order: Sequence[str],
+        context: IngestContext,
+        skip: frozenset[str],
+    ) -> IngestContext:
+        for name in order:
+            if context.halted_by is not None:
+                break
+            if name in skip:
+                continue
+            if name not in self._stages:
+                msg = f"pipeline stage {name} is not registered"
+                raise ValueError(msg)
+            start = perf_counter()
+            failed = False
+            try:
+                self._stages[name](context)
+            except Exception:
+                failed = True
+                raise
+            finally:
+                elapsed_ms = (perf_counter() - start) * 1000.0
+                context.stage_latency_ms[name] = elapsed_ms
+                with self._lock:
+                    metrics = self._metrics[name]
+                    metrics.runs += 1
+                    metrics.latency_ms_sum += elapsed_ms
+                    metrics.failures += int(failed)
+                    metrics.halts += int(context.halted_by == name)
+        return context
@@ -0,0 +1,142 @@
+"""Tenant-hosted transforms for the ``webhook`` ingest pipeline stage.
+
+The stage POSTs each event to the tenant's URL as a ``pipeline_transform`` event, signed like
+Orbit's other webhooks when a secret is set, and applies the mutations in the JSON reply::
+
+    {"content": "...", "annotations": ["..."], "drop": false, "reason": "..."}
+
+Every key is optional and an empty reply (or ``204``) leaves the event unchanged. ``content``
+replaces the text, ``annotations`` become ``annotation:<text>`` relationships, and ``drop``
+stops the event before it is stored.
+
+The URL must resolve to public addresses only, checked when it is saved and again on every
+call, so a tenant cannot point Orbit at loopback, private, or cloud metadata addresses. Calls
+go through ``PinnedTransport``, which connects to the address the check approved, so the host
+cannot rebind to an internal address between the check and the connection.
+"""
+
+from __future__ import annotations
+
+import hashlib
+import hmac
+import json
+from dataclasses import dataclass, field
+from typing import Any
+
+import httpx
+
+from orbit_api.pipeline import IngestContext
+from orbit_api.tracing import outbound_headers
+from orbit_api.url_sources import OutboundPolicy, PinnedTransport, UrlFetchError
+
+MAX_ANNOTATIONS = 32
+MAX_ANNOTATION_CHARS = 512
+
+
+class PipelineWebhookError(ValueError):
+    """The tenant's webhook timed out, failed, or replied with invalid mutations."""
+
+
+@dataclass(frozen=True)
+class WebhookTarget:
+    url: str
+    secret: str | None
+    timeout_ms: int
+    failure_policy: str
+    policy: OutboundPolicy = field(default_factory=OutboundPolicy)
+
+
+def transform_payload(context: IngestContext) -> dict[str, Any]:
+    return {
+        "account_key": context.account_key,
+        "entity_id": context.request.entity_id,
+        "event_type": context.request.event_type,
+        "content": context.content,
+        "metadata": context.metadata,
+    }
+
+
+def call_transform_webhook(target: WebhookTarget, payload: dict[str, Any]) -> dict[str, Any]:
+    """POST ``payload`` and return the decoded mutations (``{}`` for an empty reply)."""
+    body = json.dumps(
+        {"type": "pipeline_transform", **payload},
+        ensure_ascii=True,
+        default=str,
+    ).encode("utf-8")
+    headers = {
+        "Content-Type": "application/json",
+        "X-Orbit-Event": "pipeline_transform",
+        **outbound_headers(),
+    }
+    if target.secret:
+        digest = hmac.new(target.secret.encode("utf-8"), body, hashlib.sha256).hexdigest()
+        headers["X-Orbit-Signature"] = f"sha256={digest}"
+    try:
+        # The transport re-checks the host per call: its DNS may have changed since it was saved.
+        with httpx.Client(
+            timeout=target.timeout_ms / 1000.0,
+            transport=PinnedTransport(target.policy),
+            trust_env=False,
+        ) as client:
+            response = client.post(target.url, content=body, headers=headers)
+    except UrlFetchError as exc:
+        msg = f"pipeline webhook URL is not allowed: {exc}"
+        raise PipelineWebhookError(msg) from exc
+    except httpx.TimeoutException as exc:
+        msg = f"pipeline webhook timed out after {target.timeout_ms} ms"
+        raise PipelineWebhookError(msg) from exc
+    except httpx.HTTPError as exc:
+        msg = f"pipeline webhook request failed: {exc}"
+        raise PipelineWebhookError(msg) from exc
+    if response.status_code >= 300:
+        msg = f"pipeline webhook returned HTTP {response.status_code}"
+        raise PipelineWebhookError(msg)
+    if not response.content.strip():
+        return {}
+    try:
+        mutations = response.json()
+    except ValueError as exc:
+        msg = "pipeline webhook reply is not valid JSON"
+        raise PipelineWebhookError(msg) from exc
+    if not isinstance(mutations, dict):
+        msg = "pipeline webhook reply must be a JSON object"
+        raise PipelineWebhookError(msg)
+    return mutations
+
+
+def apply_mutations(
+    context: IngestContext,
+    mutations: dict[str, Any],
+    *,
+    max_content_chars: int,
+) -> None:
+    """Validate every mutation first so a bad reply leaves the event untouched."""
+    content = mutations.get("content")
+    if content is not None and (
+        not isinstance(content, str) or not content.strip() or len(content) > max_content_chars
+    ):
+        msg = f"pipeline webhook content must be a string of 1-{max_content_chars} chars"
+        raise PipelineWebhookError(msg)
+    annotations = mutations.get("annotations") or []
+    if (
+        not isinstance(annotations, list)
+        or len(annotations) > MAX_ANNOTATIONS
+        or not all(
+            isinstance(item, str) and 0 < len(item.strip()) <= MAX_ANNOTATION_CHARS
+            for item in annotations
+        )
+    ):
+        msg = (
+            f"pipeline webhook annotations must be at most {MAX_ANNOTATIONS} strings "
+            f"of 1-{MAX_ANNOTATION_CHARS} chars"
+        )
+        raise PipelineWebhookError(msg)
+
+    if mutations.get("drop") is True:
+        reason = mutations.get("reason")
+        context.halt("webhook", reason=str(reason).strip() if reason else None)
+        return
+    if content is not None:
+        context.content = content.strip()
+    if annotations:
+        context.add_relationships(*[f"annotation:{item.strip()}" for item in annotations])
@@ -0,0 +1,41 @@
+"""Anonymized retrieval query log for product analytics.
+
+Queries are stored without the entity, API key, or session that issued them. Before storage the
+text is lowercased and email addresses, URLs, and numbers are replaced with placeholders, so
+``Email bob@example.com about order 5512`` and ``email ann@example.org about order 77`` are
+counted as the same query.
+"""
+
+from __future__ import annotations
+
+import hashlib
+import math
+import re
+
+MAX_QUERY_TEXT_CHARS = 255
+
+_EMAIL_PATTERN = re.compile(r"[\w.+-]+@[\w-]+(\.[\w-]+)+")
+_URL_PATTERN = re.compile(r"\bhttps?://\S+|\bwww\.\S+", re.IGNORECASE)
+_NUMBER_PATTERN = re.compile(r"\+?\d[\d\s().-]*\d|\d")
+_WHITESPACE_PATTERN = re.compile(r"\s+")
+
+
+def anonymize_query(query: str) -> str:
+    text = _URL_PATTERN.sub("<url>", query)
+    text = _EMAIL_PATTERN.sub("<email>", text)
+    text = _NUMBER_PATTERN.sub("<number>", text)
+    text = _WHITESPACE_PATTERN.sub(" ", text).strip().lower()
+    return text[:MAX_QUERY_TEXT_CHARS]
+
+
+def query_fingerprint(anonymized: str) -> str:
+    return hashlib.sha256(anonymized.encode("utf-8")).hexdigest()
+
+
+def percentile(values: list[float], fraction: float) -> float:
+    """Nearest-rank percentile of ``values``; ``fraction`` is between 0 and 1."""
+    if not values:
+        return 0.0
+    ordered = sorted(values)
+    rank = max(1, math.ceil(fraction * len(ordered)))
+    return ordered[rank - 1]
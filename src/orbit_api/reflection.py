@@ -0,0 +1,103 @@
+"""Distill lessons-learned from a completed agent trajectory.
+
+Rules are deterministic so the same trajectory always yields the same lessons: a failed step
+that a later step recovered from becomes an error-recovery lesson, an unrecovered failure
+becomes a pitfall, and an unsuccessful outcome is summarized so the next attempt can start
+from it. Successful trajectories are additionally stored as procedures by the service.
+"""
+
+from __future__ import annotations
+
+from dataclasses import dataclass
+
+from orbit.models import ReflectRequest, TrajectoryStep
+
+MAX_LESSONS = 20
+_MAX_ERROR_CHARS = 240
+
+
+@dataclass(frozen=True)
+class DistilledLesson:
+    kind: str
+    content: str
+    summary: str
+
+
+def distill_lessons(request: ReflectRequest) -> list[DistilledLesson]:
+    lessons: list[DistilledLesson] = []
+    seen_errors: set[str] = set()
+    for index, step in enumerate(request.steps):
+        if not step.error:
+            continue
+        error = _clip(step.error)
+        error_key = f"{step.tool or step.action}|{error}".lower()
+        if error_key in seen_errors:
+            continue
+        seen_errors.add(error_key)
+        recovery_index = _recovery_step(request.steps, index)
+        if recovery_index is None:
+            lessons.append(
+                DistilledLesson(
+                    kind="pitfall",
+                    content=(
+                        f"Lesson learned while trying to {request.goal}: "
+                        f"{_describe(step)} failed with \"{error}\" and was not recovered. "
+                        "Avoid this approach or check its preconditions first."
+                    ),
+                    summary=f"Pitfall: {_describe(step)} fails with \"{error}\"",
+                )
+            )
+            continue
+        recovery = request.steps[recovery_index]
+        lessons.append(
+            DistilledLesson(
+                kind="error_recovery",
+                content=(
+                    f"Lesson learned while trying to {request.goal}: when {_describe(step)} "
+                    f"fails with \"{error}\", {_describe(recovery)} resolved it."
+                ),
+                summary=f"Recovery for \"{error}\": {recovery.action}",
+            )
+        )
+
+    if request.outcome != "success":
+        detail = request.outcome_detail or _last_error(request.steps) or "no detail recorded"
+        verb = "failed" if request.outcome == "failure" else "only partly succeeded"
+        lessons.append(
+            DistilledLesson(
+                kind="outcome",
+                content=(
+                    f"Lesson learned: an attempt to {request.goal} {verb} after "
+                    f"{len(request.steps)} steps ({detail}). Try a different approach next time."
+                ),
+                summary=f"Attempt to {request.goal} {verb}",
+            )
+        )
+    return lessons[:MAX_LESSONS]
+
+
+def _recovery_step(steps: list[TrajectoryStep], failed_index: int) -> int | None:
+    """First later successful step using the same tool, or the next successful step if toolless."""
+    failed = steps[failed_index]
+    for index in range(failed_index + 1, len(steps)):
+        candidate = steps[index]
+        if candidate.error:
+            continue
+        if failed.tool is None or candidate.tool == failed.tool:
+            return index
+    return None
+
+
+def _describe(step: TrajectoryStep) -> str:
+    return f"{step.action} ({step.tool})" if step.tool else step.action
+
+
+def _last_error(steps: list[TrajectoryStep]) -> str | None:
+    for step in reversed(steps):
+        if step.error:
+            return f"last error: {_clip(step.error)}"
+    return None
+
+
+def _clip(text: str) -> str:
+    return text if len(text) <= _MAX_ERROR_CHARS else text[: _MAX_ERROR_CHARS - 3].rstrip() + "..."
@@ -0,0 +1,136 @@
+"""Tenant residency routing and request signing between Orbit regions.
+
+Each region runs its own Orbit deployment and database. A tenant has one home region that
+accepts all of its writes, so every write is ordered by a single database and replicas never
+have to merge conflicting edits. Replica regions pull the home region's change feed and
+serve reads locally. Pinned tenants are never proxied through a region outside their home and
+replica set: requests that land elsewhere are rejected with the region to retry against.
+
+Requests between regions are signed with the same canonical request as SDK calls (see
+``orbit.signing``) under a separate replication secret, so a captured request can be neither
+altered nor replayed.
+"""
+
+from __future__ import annotations
+
+import hmac
+import secrets
+from dataclasses import dataclass
+from datetime import UTC, datetime
+
+from orbit.signing import canonical_request, compute_signature
+
+REGION_HEADER = "X-Orbit-Region"
+FORWARDED_FROM_HEADER = "X-Orbit-Forwarded-From"
+REPLICATION_SIGNATURE_HEADER = "X-Orbit-Replication-Signature"
+REPLICATION_TIMESTAMP_HEADER = "X-Orbit-Replication-Timestamp"
+REPLICATION_NONCE_HEADER = "X-Orbit-Replication-Nonce"
+REPLICATION_MAX_SKEW_SECONDS = 300
+REPLICATION_MAX_NONCE_CHARS = 128
+
+READ_METHODS = frozenset({"GET", "HEAD", "OPTIONS"})
+
+
+@dataclass(frozen=True)
+class RegionRoute:
+    """Where a tenant request is served: ``local``, ``forward`` to, or ``reject`` for ``region``."""
+
+    action: str
+    region: str | None = None
+
+
+def route_request(
+    *,
+    local_region: str | None,
+    home_region: str | None,
+    replica_regions: list[str],
+    pinned: bool,
+    method: str,
+) -> RegionRoute:
+    if local_region is None or home_region is None or home_region == local_region:
+        return RegionRoute("local")
+    if method.upper() in READ_METHODS and local_region in replica_regions:
+        return RegionRoute("local")
+    if pinned and local_region not in replica_regions:
+        return RegionRoute("reject", home_region)
+    return RegionRoute("forward", home_region)
+
+
+def replication_signature(
+    secret: str,
+    *,
+    method: str,
+    path: str,
+    query: str,
+    timestamp: str,
+    nonce: str,
+    body: bytes,
+) -> str:
+    canonical = canonical_request(
+        method=method,
+        path=path,
+        query=query,
+        timestamp=timestamp,
+        nonce=nonce,
+        body=body,
+    )
+    return f"sha256={compute_signature(secret, canonical)}"
+
+
+def verify_replication_signature(
+    secret: str,
+    *,
+    signature: str,
+    method: str,
+    path: str,
+    query: str,
+    timestamp: str,
+    nonce: str,
+    body: bytes,
+    now: datetime | None = None,
+) -> bool:
+    """Check the signature and timestamp; the caller still has to refuse a reused ``nonce``."""
+    if not nonce or len(nonce) > REPLICATION_MAX_NONCE_CHARS:
+        return False
+    try:
+        issued_at = int(timestamp)
+    except ValueError:
+        return False
+    current = int((now or datetime.now(UTC)).timestamp())
+    if abs(current - issued_at) > REPLICATION_MAX_SKEW_SECONDS:
+        return False
+    expected = replication_signature(
+        secret,
+        method=method,
+        path=path,
+        query=query,
+        timestamp=timestamp,
+        nonce=nonce,
+        body=body,
+    )
+    return hmac.compare_digest(expected, signature)
+
+
+def replication_headers(
+    secret: str,
+    *,
+    method: str,
+    path: str,
+    query: str = "",
+    body: bytes,
+) -> dict[str, str]:
+    timestamp = str(int(datetime.now(UTC).timestamp()))
+    nonce = secrets.token_hex(16)
+    return {
+        REPLICATION_TIMESTAMP_HEADER: timestamp,
+        REPLICATION_NONCE_HEADER: nonce,
+        REPLICATION_SIGNATURE_HEADER: replication_signature(
+            secret,
+            method=method,
+            path=path,
+            query=query,
+            timestamp=timestamp,
+            nonce=nonce,
+            body=body,
+        ),
+    }
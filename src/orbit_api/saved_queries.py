@@ -0,0 +1,59 @@
+"""Query templates for saved retrievals.
+
+A saved query's template is retrieval text with ``{name}`` placeholders, such as
+``"open tickets about {product}"``, filled from the parameters a run passes. ``{{`` and ``}}``
+are literal braces. Placeholders are plain names only, so a template cannot reach attributes
+or indexes of the values it is given.
+"""
+
+from __future__ import annotations
+
+import re
+from collections.abc import Mapping
+from string import Formatter
+
+SAVED_QUERY_NAME = re.compile(r"^[a-z0-9][a-z0-9_.-]{0,63}$")
+_PARAMETER_NAME = re.compile(r"^[A-Za-z_][A-Za-z0-9_]{0,63}$")
+
+
+def validate_saved_query_name(name: str) -> str:
+    if not SAVED_QUERY_NAME.match(name):
+        msg = (
+            "saved query name must be 1-64 lowercase letters, digits, '_', '-' or '.', "
+            "starting with a letter or digit"
+        )
+        raise ValueError(msg)
+    return name
+
+
+def template_parameters(template: str) -> list[str]:
+    """Placeholder names in ``template``, in order of first use."""
+    names: list[str] = []
+    try:
+        fields = list(Formatter().parse(template))
+    except ValueError as exc:
+        msg = f"invalid query template: {exc}"
+        raise ValueError(msg) from exc
+    for _, name, format_spec, conversion in fields:
+        if name is None:
+            continue
+        if not _PARAMETER_NAME.match(name) or format_spec or conversion:
+            msg = f"query template placeholders must be plain names, not {{{name}}}"
+            raise ValueError(msg)
+        if name not in names:
+            names.append(name)
+    return names
+
+
+def render_query(template: str, params: Mapping[str, str]) -> str:
+    """Fill ``template`` from ``params``; every placeholder needs a value and no extras."""
+    names = template_parameters(template)
+    missing = [name for name in names if name not in params]
+    if missing:
+        msg = f"missing saved query parameter(s): {', '.join(missing)}"
+        raise ValueError(msg)
+    unknown = sorted(set(params) - set(names))
+    if unknown:
+        msg = f"unknown saved query parameter(s): {', '.join(unknown)}"
+        raise ValueError(msg)
+    return template.format_map({name: str(params[name]) for name in names})
@@ -0,0 +1,77 @@
+"""Label ingested content with a sentiment and the emotions it expresses.
+
+A lexicon classifier: each emotion has a word list, and the sentiment is the balance of
+positive and negative cue words. A negation (``not``, ``never``, ``n't``) within the two words
+before a cue flips its polarity and suppresses its emotion, so "not happy" reads as negative
+without also counting as joy. Labels are stored as ``sentiment:<label>`` and
+``emotion:<label>`` relationships, which retrieval filters on.
+"""
+
+from __future__ import annotations
+
+import re
+
+from orbit.models import MEMORY_EMOTIONS
+
+_EMOTION_WORDS: dict[str, frozenset[str]] = {
+    "frustration": frozenset(
+        "frustrated frustrating annoyed annoying fed sick tired ugh useless stuck again broken "
+        "keeps".split()
+    ),
+    "anger": frozenset("angry furious outraged livid hate unacceptable ridiculous".split()),
+    "sadness": frozenset("sad unhappy disappointed lonely miss upset depressed".split()),
+    "anxiety": frozenset("worried anxious nervous scared afraid stressed panic concerned".split()),
+    "confusion": frozenset("confused confusing unclear lost understand makes".split()),
+    "joy": frozenset("happy glad excited love great awesome amazing delighted enjoy".split()),
+    "gratitude": frozenset("thanks thank grateful appreciate appreciated".split()),
+}
+_NEGATIVE_EMOTIONS = frozenset({"frustration", "anger", "sadness", "anxiety", "confusion"})
+# Cue words that only signal an emotion in a phrase: "fed up", "sick of", "doesn't make sense".
+_PHRASE_ONLY = {
+    "fed": re.compile(r"\bfed up\b"),
+    "sick": re.compile(r"\bsick (?:of|and tired)\b"),
+    "tired": re.compile(r"\btired of\b"),
+    "again": re.compile(r"\b(?:not|failed|broke|broken|crashed)\b.*\bagain\b"),
+    "keeps": re.compile(r"\bkeeps (?:failing|crashing|breaking)\b"),
+    "lost": re.compile(r"\b(?:i'?m|i am|feel|feeling) lost\b"),
+    "understand": re.compile(r"\b(?:don'?t|do not|can'?t|cannot) understand\b"),
+    "makes": re.compile(r"\b(?:doesn'?t|does not|don'?t) make sense\b"),
+    "miss": re.compile(r"\bi miss\b"),
+}
+_NEGATIONS = frozenset({"not", "no", "never", "hardly", "without"})
+_WORD = re.compile(r"[a-z]+(?:'[a-z]+)?")
+
+
+def classify_sentiment(text: str) -> tuple[str, list[str]]:
+    """Return the sentiment of ``text`` and its emotions, in ``MEMORY_EMOTIONS`` order."""
+    lowered = text.lower()
+    words = _WORD.findall(lowered)
+    found: set[str] = set()
+    balance = 0
+    for index, word in enumerate(words):
+        for emotion, cues in _EMOTION_WORDS.items():
+            if word not in cues:
+                continue
+            phrase = _PHRASE_ONLY.get(word)
+            if phrase is not None and not phrase.search(lowered):
+                continue
+            polarity = -1 if emotion in _NEGATIVE_EMOTIONS else 1
+            if phrase is None and _negated(words, index):
+                # "not happy" is negative but not joy; "not worried" is mildly positive.
+                balance -= polarity
+                continue
+            found.add(emotion)
+            balance += polarity
+    if balance > 0:
+        sentiment = "positive"
+    elif balance < 0:
+        sentiment = "negative"
+    else:
+        sentiment = "neutral"
+    return sentiment, [emotion for emotion in MEMORY_EMOTIONS if emotion in found]
+
+
+def _negated(words: list[str], index: int) -> bool:
+    return any(
+        word in _NEGATIONS or word.endswith("n't") for word in words[max(0, index - 2) : index]
+    )
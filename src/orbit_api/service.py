@@ -2,67 +2,299 @@
 
 from __future__ import annotations
 
+import difflib
 import hashlib
 import hmac
 import json
+import random
 import re
 import secrets
-from collections import Counter
-from collections.abc import Callable
-from dataclasses import dataclass
-from datetime import UTC, datetime, timedelta
+from collections import Counter, OrderedDict, deque
+from collections.abc import Callable, Sequence
+from concurrent.futures import ThreadPoolExecutor
+from contextlib import suppress
+from dataclasses import dataclass, field, replace
+from datetime import UTC, date, datetime, timedelta
 from pathlib import Path
-from threading import RLock
+from threading import Event as ThreadEvent
+from threading import RLock, Thread
 from time import perf_counter
 from typing import Any, TypeVar
+from urllib.parse import urlparse
 from uuid import uuid4
 
 import httpx
+import jwt
 import numpy as np
-from sqlalchemy import create_engine, func, select
+from sqlalchemy import and_, create_engine, delete, func, or_, select, text, update
 from sqlalchemy.exc import IntegrityError
 from sqlalchemy.orm import Session, sessionmaker
 
+from decision_engine import clock
+from decision_engine.clock import Clock, FixedClock
+from decision_engine.math_utils import cosine_similarity, to_unit_vector
 from decision_engine.models import MemoryRecord, RetrievedMemory
+from decision_engine.semantic_encoding import EmbeddingProvider
 from memory_engine.config import EngineConfig
 from memory_engine.engine import DecisionEngine
 from memory_engine.models.event import Event
+from memory_engine.stage1_input.embedding import build_embedding_provider
 from memory_engine.storage.db import (
     ApiAccountUsageRow,
     ApiAuditLogRow,
+    ApiChatTurnRow,
     ApiDashboardUserRow,
+    ApiDigestRow,
+    ApiEntityAttributeRow,
+    ApiEntityGroupMemberRow,
+    ApiEventTypeRegistryRow,
+    ApiExportJobRow,
+    ApiGoalRow,
     ApiIdempotencyRow,
+    ApiIndexDeploymentRow,
+    ApiIngestAggregateRow,
+    ApiIngestionAnomalyRow,
     ApiKeyRow,
+    ApiMemoryChangeRow,
+    ApiMemoryCounterRow,
+    ApiMemoryLinkRow,
+    ApiMemoryReviewRow,
+    ApiMemoryShareRow,
+    ApiMemoryVersionRow,
+    ApiModerationReviewRow,
+    ApiNamespaceRow,
     ApiPilotProRequestRow,
+    ApiPipelineWebhookRow,
+    ApiQueryLogRow,
+    ApiReplicationCursorRow,
+    ApiResurfacedMemoryRow,
+    ApiRetentionPolicyRow,
+    ApiSavedQueryRow,
+    ApiTenantResidencyRow,
+    ApiUrlSourceRow,
+    ApiWritePolicyRow,
     Base,
 )
+from memory_engine.storage.quantization import normalize_quantization_mode
+from orbit.logger import get_logger
 from orbit.models import (
+    DIGEST_PERIODS,
+    GOAL_STATUSES,
+    IMPORT_MODES,
+    RETRIEVAL_PROFILES,
+    SENSITIVITY_LEVELS,
     AccountQuota,
     AccountUsage,
+    AdminAnomaly,
+    AdminAnomalyListResponse,
+    AdminEntity,
+    AdminEntityListResponse,
+    AdminMetricsResponse,
+    AdminTenant,
+    AdminTenantListResponse,
     ApiKeyIssueResponse,
     ApiKeyListResponse,
     ApiKeyRevokeResponse,
     ApiKeyRotateResponse,
     ApiKeySummary,
+    AskRequest,
+    AskResponse,
     AuthValidationResponse,
+    BatchRetrieveRequest,
+    BatchRetrieveResponse,
+    BrowserTokenRequest,
+    BrowserTokenResponse,
+    CaptureRequest,
+    ChangeFeedResponse,
+    ChatHistoryResponse,
+    ChatRequest,
+    ChatResponse,
+    ChatTurn,
+    ConflictingFact,
+    Digest,
+    DigestRequest,
+    DocumentRevision,
+    EntityAttributesPatchRequest,
+    EntityAttributesResponse,
+    EntityConflictsResponse,
+    EntityGoalsResponse,
+    EntityGroupRequest,
+    EntityGroupResponse,
+    EntityTopicsResponse,
+    EventTypeDefinition,
+    EventTypeRegistry,
+    EventTypeRegistryRequest,
+    EventTypeSampling,
+    ExportJob,
+    ExportJobListResponse,
+    ExportJobRequest,
+    FanoutRetrieveRequest,
+    FanoutRetrieveResponse,
     FeedbackRequest,
     FeedbackResponse,
+    Goal,
+    GoalProgress,
+    HookMemory,
+    ImportResponse,
+    IndexComparison,
+    IndexDeployment,
+    IndexDeploymentListResponse,
+    IndexDeploymentRequest,
     IngestRequest,
     IngestResponse,
     Memory,
+    MemoryChange,
+    MemoryConflict,
+    MemoryDiffResponse,
+    MemoryLink,
+    MemoryLinkListResponse,
+    MemoryLinkRequest,
     MemoryQualityResponse,
+    MemoryReview,
+    MemoryReviewDecision,
+    MemoryReviewListResponse,
+    MemoryShare,
+    MemoryShareListResponse,
+    MemoryShareRequest,
+    MemoryUpdateRequest,
+    MemoryVersion,
+    MemoryVersionListResponse,
     MetadataSummary,
+    ModerationAppealRequest,
+    ModerationResolveRequest,
+    ModerationReview,
+    ModerationReviewListResponse,
+    Namespace,
+    NamespaceListResponse,
+    NamespaceRequest,
+    NamespaceRetrieveSummary,
+    OptimizeJob,
+    OptimizeJobListResponse,
+    OptimizeStep,
     PaginatedMemoriesResponse,
     PilotProRequest,
     PilotProRequestResponse,
+    PinnedClock,
+    PipelineWebhook,
+    PipelineWebhookRequest,
+    Procedure,
+    ProcedureRequest,
+    ProcedureSearchResponse,
+    ProcedureStep,
+    QueryAnalyticsResponse,
+    QueryDailyStats,
+    QueryStat,
+    RecallRequest,
+    RecallResponse,
+    ReplicatedApiKey,
+    ReplicationBatch,
+    ReflectLesson,
+    ReflectRequest,
+    ReflectResponse,
+    ResurfacedMemory,
+    ResurfaceResponse,
+    RetentionPolicy,
+    RetentionPolicyRequest,
+    RetrieveBoost,
+    RetrieveDefaults,
     RetrieveRequest,
     RetrieveResponse,
+    RetrieveSummary,
+    SavedQuery,
+    SavedQueryListResponse,
+    SavedQueryRequest,
+    SavedQueryRunRequest,
+    SessionEndResponse,
+    SessionMemoryItem,
+    SessionMemoryListResponse,
+    SessionMemoryRequest,
+    SessionSummary,
+    SimilarMemoriesResponse,
     StatusResponse,
     TenantMetricsResponse,
+    TenantResidency,
+    TenantResidencyRequest,
     TenantUsageMetric,
+    TimeRange,
+    Topic,
+    UrlSource,
+    UrlSourceListResponse,
+    UrlSourceRequest,
+    VectorIndexNamespace,
+    VectorSearchRequest,
+    WritePolicy,
+    WritePolicyRequest,
 )
+from orbit.secret_sources import get_secret
+from orbit.signing import canonical_request, compute_signature
+from orbit_api.anomaly import AnomalyThresholds, IngestionAnomaly, IngestionAnomalyDetector
 from orbit_api.auth import AuthContext
+from orbit_api.blob_store import BlobStore, blob_key, blob_key_belongs_to, build_blob_store
+from orbit_api.categories import UNCATEGORIZED, categorize
 from orbit_api.config import ApiConfig
+from orbit_api.documents import (
+    DOCUMENT_RELATIONSHIP_PREFIXES,
+    plan_revision,
+    revision_similarity,
+)
+from orbit_api.exports import (
+    CronSchedule,
+    build_destination_store,
+    encode_export,
+    export_object_key,
+    validate_destination,
+)
+from orbit_api.goals import extract_goal, extract_progress, match_goal
+from orbit_api.index_deployment import ShadowIndex, result_overlap
+from orbit_api.moderation import ModerationVerdict, build_moderation_provider, moderate
+from orbit_api.oversize import chunk_content, chunk_spans, page_number, summarize_content
+from orbit_api.pii import redact_pii
+from orbit_api.pipeline import IngestContext, IngestPipeline, validate_pipeline
+from orbit_api.pipeline_webhook import (
+    PipelineWebhookError,
+    WebhookTarget,
+    apply_mutations,
+    call_transform_webhook,
+    transform_payload,
+)
+from orbit_api.query_analytics import anonymize_query, percentile, query_fingerprint
+from orbit_api.reflection import distill_lessons
+from orbit_api.saved_queries import render_query, template_parameters, validate_saved_query_name
+from orbit_api.regions import (
+    REPLICATION_MAX_SKEW_SECONDS,
+    RegionRoute,
+    replication_headers,
+    route_request,
+)
+from orbit_api.sentiment import classify_sentiment
+from orbit_api.synthesis import (
+    SummaryTarget,
+    answer_messages,
+    call_summarizer,
+    chat_messages,
+    cited_memory_ids,
+    parse_answer,
+    digest_messages,
+    summary_messages,
+)
+from orbit_api.topics import TopicCluster, cluster_memories, clustering_algorithm
+from orbit_api.tracing import outbound_headers, submit_with_context
+from orbit_api.url_sources import (
+    OutboundPolicy,
+    UrlFetchError,
+    content_hash,
+    fetch_url,
+    require_public_host,
+)
+from orbit_api.wasm_stage import build_wasm_stages
+from orbit_api.working_memory import (
+    WorkingMemoryItem,
+    WorkingMemoryStore,
+    build_working_memory_store,
+    new_working_memory_item,
+    score_working_memory,
+    should_promote_working_memory,
+)
 
 
 @dataclass
@@ -121,6 +353,39 @@ class IdempotencyConflictError(RuntimeError):
     """Raised when an idempotency key is reused with a different payload."""
 
 
+class IndexDeploymentConflictError(RuntimeError):
+    """Raised when an index deployment's status does not allow the requested change."""
+
+
+class PreconditionFailedError(RuntimeError):
+    """Raised when an ``If-Match`` version no longer matches the stored resource."""
+
+    def __init__(self, *, current_version: int) -> None:
+        super().__init__(f"resource was modified; current version is {current_version}")
+        self.current_version = current_version
+
+
+class ContentBlockedError(ValueError):
+    """Raised when moderation policy blocks ingested content; the content is queued for review."""
+
+    def __init__(self, *, review_ids: list[int], categories: list[str]) -> None:
+        reviews = ", ".join(str(item) for item in review_ids)
+        super().__init__(
+            f"content blocked by moderation policy ({', '.join(categories)}); "
+            f"appeal via moderation review {reviews}"
+        )
+        self.review_ids = review_ids
+        self.categories = categories
+
+
+class WritePolicyViolationError(ValueError):
+    """Raised when a write breaks the namespace's write policy; nothing is stored."""
+
+    def __init__(self, *, rule: str, detail: str) -> None:
+        super().__init__(f"write policy violation ({rule}): {detail}")
+        self.rule = rule
+
+
 class ApiKeyAuthenticationError(RuntimeError):
     """Raised when an API key cannot be authenticated."""
 
@@ -136,6 +401,86 @@ _API_KEY_PATTERN = re.compile(r"^orbit_pk_([a-z0-9]{12})_([A-Za-z0-9_-]{16,})$")
 _API_KEY_HASH_ALGORITHM = "sha256"
 _API_KEY_HASH_ITERATIONS = 310_000
 _DEFAULT_KEY_SCOPES = ["read", "write", "feedback"]
+# Superscopes only operator-configured JWTs carry; a key holder could otherwise mint them.
+_RESERVED_KEY_SCOPES = frozenset({"admin", "*"})
+_BROWSER_TOKEN_SCOPES = frozenset({"read", "memory:read", "feedback", "memory:feedback"})
+BROWSER_TOKEN_AUTH_TYPE = "browser_token"
+_ACCOUNT_BOUND_AUTH_TYPES = frozenset({"api_key", "signed_request", BROWSER_TOKEN_AUTH_TYPE})
+# Relationships that carry access control or provenance; only the server writes them, so
+# client metadata naming one is dropped before ingest.
+_RESERVED_RELATIONSHIP_PREFIXES = (
+    "blob_",
+    "sensitivity:",
+    "agent:",
+    "agent_scope:",
+    "category:",
+    "url_source:",
+    "crawled_at:",
+)
+PROCEDURE_EVENT_TYPE = "procedure"
+_MAX_ENTITY_ATTRIBUTES_BYTES = 65_536
+_ACCOUNT_ANOMALY_KEY_ID = "account"
+_WEBHOOK_TIMEOUT_SECONDS = 5.0
+_REPLICATION_TIMEOUT_SECONDS = 10.0
+# Idempotency rows under this operation record the nonces of signed replication requests.
+_REPLICATION_NONCE_OPERATION = "replication_nonce"
+# Idempotency rows under this operation record HMAC-signed API request nonces per key id.
+_SIGNING_NONCE_OPERATION = "signing_nonce"
+_MAX_RETRIEVE_BATCH_WORKERS = 8
+_OPTIMIZE_STEPS = (
+    "vector_compaction",
+    "vector_index_save",
+    "storage_maintenance",
+    "metadata_maintenance",
+)
+_MAX_OPTIMIZE_JOBS = 20
+# Index deployment state is shared through the database; each process re-reads it this often.
+_INDEX_DEPLOYMENT_REFRESH_SECONDS = 30.0
+# Mirrored queries are dropped rather than queued once this many are waiting.
+_MAX_INDEX_MIRROR_BACKLOG = 32
+_LIVE_INDEX_DEPLOYMENT_STATUSES = ("building", "ready", "active")
+# Expired query log rows are pruned once every this many logged queries.
+_QUERY_LOG_PRUNE_INTERVAL = 1000
+# Graph retrieval: each hop away from a vector hit scales the connected fact's score by this.
+_GRAPH_HOP_DECAY = 0.8
+# MMR re-selects the top-k from this many times k of the best-ranked candidates.
+_MMR_CANDIDATE_FACTOR = 5
+# Extracted fact families that feed entity profiles: list-valued vs. single-valued attributes.
+_FACT_LIST_ATTRIBUTES = {"allergy": "allergies", "preference_like": "likes"}
+_FACT_VALUE_ATTRIBUTES = {
+    "weight_current": "weight",
+    "weight_target": "target_weight",
+    "weight_goal_reason": "weight_goal_reason",
+}
+# /v1/chat: earlier turns sent with each message, and the event types turns are ingested as.
+_CHAT_HISTORY_TURNS = 20
+_CHAT_EVENT_TYPES = {"user": "user_question", "assistant": "assistant_response"}
+# Aggregated event types: how much of the window's latest event its summary memory quotes.
+_AGGREGATE_LATEST_CHARS = 500
+# Digests: the most important new memories the summary covers, and how many the fallback
+# summary (no summarization model configured) quotes.
+_DIGEST_MAX_MEMORIES = 50
+_DIGEST_QUOTED_MEMORIES = 5
+# fast=true retrieval: the stages it never runs, the entries kept in each of the query-embedding
+# and result caches, and the number of recent latencies the published p99 is computed over.
+_FAST_SKIPPED_STAGES = frozenset(
+    {"candidate_fallback", "keyword_search", "candidate_expansion", "rerank", "intent_caps"}
+)
+_FAST_CACHE_SIZE = 1024
+_FAST_LATENCY_WINDOW = 1000
+
+
+@dataclass
+class _SampledBatch:
+    """How the sampling policies handle one ingest batch; positions index into the batch."""
+
+    # Events that are not stored individually.
+    responses: dict[int, IngestResponse] = field(default_factory=dict)
+    # First occurrences of counter observations, by (entity_id, event_type, content hash), with
+    # the positions of their repeats in the same batch.
+    new_counters: dict[tuple[str, str, str], list[int]] = field(default_factory=dict)
+    # Aggregation windows the batch closed, stored as one memory each.
+    summaries: list[IngestRequest] = field(default_factory=list)
 
 
 @dataclass
@@ -153,8 +498,18 @@ class OrbitApiService:
         api_config: ApiConfig | None = None,
         engine: DecisionEngine | None = None,
         engine_config: EngineConfig | None = None,
+        clock_source: Clock | None = None,
     ) -> None:
         self._config = api_config or ApiConfig.from_env()
+        if clock_source is None and self._config.test_mode:
+            clock_source = FixedClock(self._config.test_clock)
+        # Installed as the process clock, which decay, TTLs, and schedules all read.
+        self._clock = clock_source
+        if clock_source is not None:
+            clock.install(clock_source)
+        self._log = get_logger("orbit.api.service")
+        # Sampling and index mirroring draw from this; test mode seeds it.
+        self._random = random.Random(self._config.test_seed if self._config.test_mode else None)
         resolved_engine_config = self._resolve_engine_config(engine_config)
         self._engine = engine or DecisionEngine(
             config=resolved_engine_config,
@@ -189,24 +544,163 @@ class OrbitApiService:
             "feedback_latency_ms_sum": 0.0,
             "dashboard_auth_failures_total": 0.0,
             "dashboard_key_rotation_failures_total": 0.0,
+            "retrieve_degraded_total": 0.0,
+            "retrieve_fast_requests_total": 0.0,
+            "retrieve_fast_cache_hits_total": 0.0,
+            "working_memory_promotions_rejected_total": 0.0,
+            "backfill_consolidation_failures_total": 0.0,
+            "moderation_withheld_total": 0.0,
         }
+        self._fast_latencies_ms: deque[float] = deque(maxlen=_FAST_LATENCY_WINDOW)
+        # (id(encoder), query) -> embedding, and result cache key -> (cached_at, response);
+        # both least recently used first.
+        self._query_embedding_cache: OrderedDict[tuple[int, str], np.ndarray] = OrderedDict()
+        self._fast_result_cache: OrderedDict[
+            tuple[str, str, str], tuple[float, RetrieveResponse]
+        ] = OrderedDict()
         self._http_status_counts: dict[int, float] = {}
         self._pilot_pro_accounts = {
             self._normalize_account_key(account_key)
             for account_key in self._config.pilot_pro_account_keys
         }
+        self._working_memory: WorkingMemoryStore = build_working_memory_store(
+            self._config.working_memory_backend,
+            redis_url=self._config.working_memory_redis_url,
+            max_items_per_session=self._config.working_memory_max_items,
+            ttl_seconds=self._config.working_memory_ttl_seconds,
+        )
+        self._last_session_sweep = perf_counter()
+        self._blob_store: BlobStore = build_blob_store(
+            self._config.blob_store_backend,
+            local_path=self._config.blob_store_path,
+            s3_bucket=self._config.blob_s3_bucket,
+            s3_prefix=self._config.blob_s3_prefix,
+            s3_endpoint_url=self._config.blob_s3_endpoint_url,
+            s3_region=self._config.blob_s3_region,
+        )
+        self._moderation_provider = build_moderation_provider(
+            self._config.moderation_provider,
+            blocklist=self._config.moderation_blocklist,
+            openai_api_key=get_secret("OPENAI_API_KEY"),
+        )
+        self._pipeline = IngestPipeline(
+            {
+                "moderation": self._stage_moderation,
+                "pii": self._stage_pii,
+                "webhook": self._stage_webhook,
+                "extraction": self._stage_extraction,
+                "dedup": self._stage_dedup,
+                "sentiment": self._stage_sentiment,
+                "categorization": self._stage_categorization,
+                "embedding": self._stage_embedding,
+                "indexing": self._stage_indexing,
+                **build_wasm_stages(
+                    self._config.wasm_stages,
+                    fuel=self._config.wasm_stage_fuel,
+                    max_memory_bytes=self._config.wasm_stage_max_memory_bytes,
+                ),
+            }
+        )
+        self._anomaly_detector = IngestionAnomalyDetector(
+            AnomalyThresholds(
+                spike_multiplier=self._config.anomaly_spike_multiplier,
+                spike_min_events_per_minute=self._config.anomaly_spike_min_events_per_minute,
+                repeat_threshold=self._config.anomaly_repeat_threshold,
+                language_warmup_events=self._config.anomaly_language_warmup_events,
+            )
+        )
+        self._webhook_executor = ThreadPoolExecutor(
+            max_workers=1,
+            thread_name_prefix="orbit-webhook",
+        )
+        self._maintenance_executor = ThreadPoolExecutor(
+            max_workers=1,
+            thread_name_prefix="orbit-maintenance",
+        )
+        # Insertion-ordered so the oldest jobs are dropped first.
+        self._optimize_jobs: dict[str, OptimizeJob] = {}
+        self._index_build_executor = ThreadPoolExecutor(
+            max_workers=1,
+            thread_name_prefix="orbit-index-build",
+        )
+        self._index_mirror_executor = ThreadPoolExecutor(
+            max_workers=1,
+            thread_name_prefix="orbit-index-mirror",
+        )
+        # Query logs, and fast=true retrieval counts, are written off the request path.
+        self._retrieval_bookkeeping_executor = ThreadPoolExecutor(
+            max_workers=1,
+            thread_name_prefix="orbit-retrieval-bookkeeping",
+        )
+        self._session_sweep_stop = ThreadEvent()
+        self._session_sweep_thread: Thread | None = None
+        # Deployment id -> this process's copy of the index; built ones are in _built_indexes.
+        self._shadow_indexes: dict[str, ShadowIndex] = {}
+        self._built_indexes: set[str] = set()
+        self._index_build_errors: dict[str, str] = {}
+        self._serving_index_id: str | None = None
+        self._index_mirror_percents: dict[str, float] = {}
+        self._index_mirror_backlog = 0
+        self._index_refreshed_at: float | None = None
+        self._query_log_writes = 0
+        # (account_key, entity_id) -> (computed_at, clusters); rebuilt lazily once stale.
+        # Keyed by account, entity, clearance and agent: each caller clusters only what it can see.
+        self._topic_cache: dict[
+            tuple[str, str, str, str], tuple[datetime, list[TopicCluster]]
+        ] = {}
+        # When memories live in the state database, change rows are written in the same
+        # transaction as the memory itself, so the feed can neither miss nor invent a write.
+        add_write_journal = getattr(self._engine.storage, "add_write_journal", None)
+        self._changes_journaled = False
+        if (
+            callable(add_write_journal)
+            and getattr(self._engine.storage, "database_url", None) == self._config.database_url
+        ):
+            add_write_journal(self._journal_memory_change)
+            self._changes_journaled = True
+        add_mutation_listener = getattr(self._engine, "add_mutation_listener", None)
+        if callable(add_mutation_listener):
+            if not self._changes_journaled:
+                add_mutation_listener(self._record_memory_change)
+                add_mutation_listener(self._record_supersessions)
+            add_mutation_listener(self._apply_fact_to_attributes)
+            add_mutation_listener(self._track_goals)
+            add_mutation_listener(self._evict_changed_from_topics)
+            add_mutation_listener(self._sync_shadow_indexes)
+            add_mutation_listener(self._evict_changed_from_fast_results)
+            add_mutation_listener(self._delete_removed_attachment)
+        self._refresh_index_deployments(force=True)
 
     @property
     def config(self) -> ApiConfig:
         return self._config
 
+    def set_clock(self, request: PinnedClock) -> PinnedClock:
+        """Move the pinned clock, e.g. a week ahead to watch recency decay; test mode only."""
+        if not isinstance(self._clock, FixedClock):
+            msg = "the engine clock can only be set in test mode (ORBIT_TEST_MODE)"
+            raise ValueError(msg)
+        self._clock.set(request.now)
+        return PinnedClock(now=self._clock.now())
+
     def close(self) -> None:
+        self.stop_session_sweeper()
+        self._webhook_executor.shutdown(wait=False)
+        self._maintenance_executor.shutdown(wait=True)
+        with self._state_lock:
+            # Builds stop at their next memory once their index is gone.
+            self._shadow_indexes.clear()
+        self._index_build_executor.shutdown(wait=False, cancel_futures=True)
+        self._index_mirror_executor.shutdown(wait=False, cancel_futures=True)
+        self._retrieval_bookkeeping_executor.shutdown(wait=True)
         self._state_engine.dispose()
         self._engine.close()
+        if self._clock is not None:
+            clock.install(None)
 
     def resolve_account_context(self, auth: AuthContext) -> AuthContext:
         claims = dict(auth.claims)
-        if str(claims.get("auth_type", "")).strip().lower() == "api_key":
+        if str(claims.get("auth_type", "")).strip().lower() in _ACCOUNT_BOUND_AUTH_TYPES:
             return auth
         issuer = self._normalize_auth_issuer(claims.get("iss"))
         subject = self._normalize_auth_subject(auth.subject)
@@ -268,19 +762,551 @@ class OrbitApiService:
         account_key: str,
         request: IngestRequest,
         idempotency_key: str | None,
+        key_id: str | None = None,
     ) -> tuple[IngestResponse, RateLimitSnapshot, bool]:
-        return self._execute_write_operation(
+        events = self.apply_oversize_policy(request)
+        result, snapshot, replayed = self._execute_write_operation(
             account_key=account_key,
             operation="ingest",
             idempotency_key=idempotency_key,
             payload=request.model_dump(mode="json"),
             quota_kind="event",
-            quota_amount=1,
-            execute=lambda: self.ingest(request, account_key=account_key),
+            quota_amount=len(events),
+            execute=lambda: self._ingest_expanded(events, account_key=account_key),
             serialize=lambda response: response.model_dump(mode="json"),
             deserialize=IngestResponse.model_validate,
             status_code=201,
         )
+        if not replayed:
+            self._observe_ingestion(
+                account_key=account_key,
+                key_id=key_id,
+                contents=[item.content for item in events],
+            )
+        return result, snapshot, replayed
+
+    def apply_oversize_policy(self, request: IngestRequest) -> list[IngestRequest]:
+        """Return the events that store ``request``: itself, its summary, or its chunks."""
+        limit = self._config.max_ingest_content_chars
+        if len(request.content) <= limit:
+            return [request]
+        action = request.on_oversize or self._config.on_oversize
+        if action == "reject":
+            msg = f"content exceeds ORBIT_MAX_INGEST_CONTENT_CHARS={limit}"
+            raise ValueError(msg)
+        metadata = dict(request.metadata or {})
+        # Recorded as relationships, like tags and sensitivity, so they read back with the
+        # memory.
+        relationships = [
+            *[str(item) for item in metadata.get("relationships", [])],
+            f"oversize:{action}",
+            f"original_chars:{len(request.content)}",
+        ]
+        if action == "summarize":
+            summary = summarize_content(
+                request.content,
+                min(self._config.oversize_summary_chars, limit),
+            )
+            return [
+                request.model_copy(
+                    update={
+                        "content": summary,
+                        "metadata": {**metadata, "relationships": relationships},
+                    }
+                )
+            ]
+        chunks = chunk_content(request.content, limit)
+        relationships += [f"chunk_group:{uuid4().hex}", f"chunk_count:{len(chunks)}"]
+        return [
+            request.model_copy(
+                update={
+                    "content": chunk,
+                    # The attachment belongs to the memory as a whole; keep it once.
+                    "attachment": request.attachment if index == 0 else None,
+                    "metadata": {
+                        **metadata,
+                        "relationships": [
+                            *relationships,
+                            f"chunk_index:{index}",
+                            *self._chunk_source_relationships(request.content, start, end),
+                        ],
+                    },
+                }
+            )
+            for index, (chunk, (start, end)) in enumerate(
+                zip(chunks, chunk_spans(request.content, chunks), strict=True)
+            )
+        ]
+
+    @staticmethod
+    def _chunk_source_relationships(content: str, start: int, end: int) -> list[str]:
+        """Where a chunk sits in its document, so answers can cite the original text."""
+        relationships = [f"source_start:{start}", f"source_end:{end}"]
+        if "\f" in content:
+            relationships += [
+                f"page_start:{page_number(content, start)}",
+                f"page_end:{page_number(content, max(end - 1, start))}",
+            ]
+        return relationships
+
+    def _ingest_expanded(
+        self,
+        events: list[IngestRequest],
+        *,
+        account_key: str,
+    ) -> IngestResponse:
+        if self._oversize_action(events) is None:
+            return self.ingest(events[0], account_key=account_key)
+        if self._oversize_action(events) == "chunk":
+            revised = self._revise_document(events, account_key=account_key)
+            if revised is not None:
+                return revised
+        results = self.ingest_batch(events, account_key=account_key)
+        return self._merge_oversize_results(results, events)
+
+    def _revise_document(
+        self,
+        events: list[IngestRequest],
+        *,
+        account_key: str,
+    ) -> IngestResponse | None:
+        """Apply a re-uploaded document's chunks as a revision of the upload it repeats.
+
+        Returns ``None`` when no earlier chunk group of the entity is similar enough, and the
+        chunks are stored as a new document.
+        """
+        threshold = self._config.document_revision_threshold
+        entity_ids_fn = getattr(self._engine, "memory_ids_for_entity", None)
+        if threshold <= 0 or not callable(entity_ids_fn):
+            return None
+        normalized_account_key = self._normalize_account_key(account_key)
+        entity_id = events[0].entity_id or self._config.default_entity_id
+        groups: dict[str, list[MemoryRecord]] = {}
+        for record in self._engine.storage.fetch_by_ids(
+            entity_ids_fn(entity_id, account_key=normalized_account_key),
+            account_key=normalized_account_key,
+        ):
+            group = self._relationship_value(record.relationships, "chunk_group:")
+            if group is not None:
+                groups.setdefault(group, []).append(record)
+        contents = [event.content for event in events]
+        best: tuple[float, str, list[MemoryRecord]] | None = None
+        for group, records in groups.items():
+            records.sort(key=self._chunk_index)
+            similarity = revision_similarity([record.content for record in records], contents)
+            if similarity >= threshold and (best is None or similarity > best[0]):
+                best = (similarity, group, records)
+        if best is None:
+            return None
+        similarity, document_id, previous = best
+
+        # The revision keeps the earlier document's id so citations and groups stay stable.
+        events = [
+            event.model_copy(
+                update={
+                    "metadata": {
+                        **(event.metadata or {}),
+                        "relationships": [
+                            f"chunk_group:{document_id}"
+                            if str(item).startswith("chunk_group:")
+                            else str(item)
+                            for item in (event.metadata or {}).get("relationships", [])
+                        ],
+                    }
+                }
+            )
+            for event in events
+        ]
+        plan = plan_revision([record.content for record in previous], contents)
+        results: dict[int, IngestResponse] = {}
+        edits = [(pair, False) for pair in plan.keep] + [(pair, True) for pair in plan.update]
+        for (old_index, new_index), edited in edits:
+            record = previous[old_index]
+            if edited:
+                self.update_memory(
+                    record.memory_id,
+                    MemoryUpdateRequest(content=events[new_index].content),
+                    account_key=normalized_account_key,
+                )
+            relationships = [
+                *[
+                    item
+                    for item in record.relationships
+                    if not item.startswith(DOCUMENT_RELATIONSHIP_PREFIXES)
+                ],
+                *[
+                    str(item)
+                    for item in (events[new_index].metadata or {}).get("relationships", [])
+                    if str(item).startswith(DOCUMENT_RELATIONSHIP_PREFIXES)
+                ],
+            ]
+            if relationships != record.relationships:
+                self._engine.update_relationships(
+                    record.memory_id,
+                    relationships,
+                    account_key=normalized_account_key,
+                )
+            results[new_index] = IngestResponse(
+                memory_id=record.memory_id,
+                stored=edited,
+                importance_score=float(record.latest_importance),
+                decision_reason=(
+                    "updated in document revision"
+                    if edited
+                    else "unchanged in document revision"
+                ),
+                encoded_at=clock.now(),
+                latency_ms=0.0,
+            )
+        if plan.add:
+            added = self.ingest_batch(
+                [events[index] for index in plan.add],
+                account_key=normalized_account_key,
+            )
+            results.update(zip(plan.add, added, strict=True))
+        if plan.remove:
+            self._engine.delete_memories(
+                [previous[index].memory_id for index in plan.remove],
+                account_key=normalized_account_key,
+            )
+        merged = self._merge_oversize_results(
+            [results[index] for index in range(len(events))],
+            events,
+        )
+        return merged.model_copy(
+            update={
+                "revision": DocumentRevision(
+                    document_id=document_id,
+                    similarity=round(similarity, 4),
+                    unchanged=len(plan.keep),
+                    updated=len(plan.update),
+                    added=len(plan.add),
+                    removed=len(plan.remove),
+                )
+            }
+        )
+
+    @classmethod
+    def _chunk_index(cls, record: MemoryRecord) -> int:
+        value = cls._relationship_value(record.relationships, "chunk_index:")
+        return int(value) if value is not None and value.isdigit() else 0
+
+    @staticmethod
+    def _merge_oversize_results(
+        results: list[IngestResponse],
+        events: list[IngestRequest],
+    ) -> IngestResponse:
+        action = OrbitApiService._oversize_action(events)
+        return results[0].model_copy(
+            update={
+                "stored": any(item.stored for item in results),
+                "importance_score": max(item.importance_score for item in results),
+                "latency_ms": sum(item.latency_ms for item in results),
+                "oversize_action": action,
+                "chunk_memory_ids": (
+                    [item.memory_id for item in results] if action == "chunk" else []
+                ),
+            }
+        )
+
+    @staticmethod
+    def hook_to_ingest(payload: dict[str, Any]) -> IngestRequest:
+        """Build an ingest event from a flat no-code payload; extra keys become metadata."""
+        fields = dict(payload)
+        content = fields.pop("content", None)
+        if not isinstance(content, str) or not content.strip():
+            msg = "content is required and must be a non-empty string"
+            raise ValueError(msg)
+        entity_id = fields.pop("entity_id", None)
+        event_type = fields.pop("event_type", None)
+        metadata = {str(key): value for key, value in fields.items() if value is not None}
+        metadata.setdefault("source", "webhook")
+        return IngestRequest(
+            content=content,
+            entity_id=str(entity_id) if entity_id not in (None, "") else None,
+            event_type=str(event_type) if event_type not in (None, "") else None,
+            metadata=metadata,
+        )
+
+    def list_hook_memories(
+        self,
+        *,
+        account_key: str,
+        limit: int = 50,
+        entity_id: str | None = None,
+        event_type: str | None = None,
+        max_sensitivity: str | None = None,
+        agent_id: str | None = None,
+    ) -> list[HookMemory]:
+        """Newest-first flat memories for polling triggers.
+
+        Storage sorts and limits the scan; the window only widens when filters drop rows.
+        """
+        normalized_account_key = self._normalize_account_key(account_key)
+        fetch = limit * 2
+        while True:
+            recent = self._engine.storage.list_recent_memories(
+                limit=fetch,
+                account_key=normalized_account_key,
+            )
+            records = self._apply_filters(
+                self._visible_to_agent(self._within_clearance(recent, max_sensitivity), agent_id),
+                entity_id,
+                event_type,
+                None,
+                None,
+            )
+            if len(records) >= limit or len(recent) < fetch:
+                break
+            fetch *= 4
+        return [self._as_hook_memory(record) for record in records[:limit]]
+
+    def search_hook_memories(
+        self,
+        *,
+        account_key: str,
+        query: str,
+        limit: int = 10,
+        entity_id: str | None = None,
+        max_sensitivity: str | None = None,
+    ) -> list[HookMemory]:
+        response = self.retrieve(
+            RetrieveRequest(query=query, limit=limit, entity_id=entity_id),
+            account_key=account_key,
+            max_sensitivity=max_sensitivity,
+        )
+        return [
+            HookMemory(
+                id=memory.memory_id,
+                content=memory.content,
+                entity_id=(memory.metadata.get("entities") or [None])[0],
+                event_type=memory.metadata.get("intent"),
+                created_at=memory.timestamp,
+                importance_score=memory.importance_score,
+            )
+            for memory in response.memories
+        ]
+
+    @staticmethod
+    def _as_hook_memory(record: MemoryRecord) -> HookMemory:
+        return HookMemory(
+            id=record.memory_id,
+            content=record.content,
+            entity_id=record.entities[0] if record.entities else None,
+            event_type=record.intent,
+            created_at=record.created_at,
+            importance_score=float(max(0.0, min(1.0, record.latest_importance))),
+        )
+
+    @staticmethod
+    def capture_to_ingest(request: CaptureRequest) -> IngestRequest:
+        """Flatten a browser "remember this page" capture into an ingest event."""
+        lines = [request.title.strip()] if request.title and request.title.strip() else []
+        lines.append(request.url)
+        if request.selection and request.selection.strip():
+            lines.extend(["", request.selection.strip()])
+        if request.note and request.note.strip():
+            lines.extend(["", f"Note: {request.note.strip()}"])
+        metadata: dict[str, Any] = {"source": "browser_capture", "url": request.url}
+        if request.title:
+            metadata["title"] = request.title.strip()
+        if request.favicon_url:
+            metadata["favicon_url"] = request.favicon_url.strip()
+        if request.tags:
+            metadata["tags"] = request.tags
+        return IngestRequest(
+            content="\n".join(lines),
+            event_type="page_capture",
+            entity_id=request.entity_id,
+            metadata=metadata,
+        )
+
+    @staticmethod
+    def procedure_to_ingest(request: ProcedureRequest) -> IngestRequest:
+        """Render a how-to procedure as searchable text, keeping the structured steps alongside."""
+        error_pattern = (request.error_pattern or "").strip()
+        outcome = (request.outcome or "").strip()
+        kind = "error_resolution" if error_pattern else "tool_sequence"
+        lines = [f"Procedure: {request.task}"]
+        if error_pattern:
+            lines.append(f"Resolves: {error_pattern}")
+        for index, step in enumerate(request.steps, start=1):
+            lines.append(f"{index}. {step.action}" + (f" (tool: {step.tool})" if step.tool else ""))
+        if outcome:
+            lines.append(f"Outcome: {outcome}")
+        steps_json = json.dumps(
+            [step.model_dump(exclude_none=True) for step in request.steps],
+            ensure_ascii=True,
+            separators=(",", ":"),
+            sort_keys=True,
+        )
+        relationships = [
+            *[str(item) for item in request.metadata.get("relationships", [])],
+            f"procedure_kind:{kind}",
+            f"procedure_task:{request.task}",
+            f"procedure_steps:{steps_json}",
+        ]
+        if error_pattern:
+            relationships.append(f"procedure_error:{error_pattern}")
+        if outcome:
+            relationships.append(f"procedure_outcome:{outcome}")
+        return IngestRequest(
+            content="\n".join(lines),
+            event_type=PROCEDURE_EVENT_TYPE,
+            entity_id=request.entity_id,
+            metadata={
+                **request.metadata,
+                "summary": request.task,
+                "relationships": relationships,
+            },
+        )
+
+    def reflect_with_quota(
+        self,
+        *,
+        account_key: str,
+        request: ReflectRequest,
+        idempotency_key: str | None,
+    ) -> tuple[ReflectResponse, RateLimitSnapshot, bool]:
+        """Distill a trajectory into lessons and store them as one quota-checked batch."""
+        trajectory_id = self._trajectory_id(request)
+        events = self.reflection_to_ingest(request, trajectory_id=trajectory_id)
+        if any(len(event.content) > self._config.max_ingest_content_chars for event in events):
+            msg = (
+                "distilled lessons exceed ORBIT_MAX_INGEST_CONTENT_CHARS="
+                f"{self._config.max_ingest_content_chars}"
+            )
+            raise ValueError(msg)
+        items, snapshot, replayed = self.ingest_batch_with_quota(
+            account_key=account_key,
+            events=events,
+            idempotency_key=idempotency_key,
+        )
+        lessons = [
+            ReflectLesson(
+                memory_id=item.memory_id,
+                kind=str((event.metadata or {}).get("lesson_kind", "lesson")),
+                content=event.content,
+                stored=item.stored,
+            )
+            for event, item in zip(events, items, strict=True)
+        ]
+        return (
+            ReflectResponse(trajectory_id=trajectory_id, lessons=lessons),
+            snapshot,
+            replayed,
+        )
+
+    @classmethod
+    def reflection_to_ingest(
+        cls,
+        request: ReflectRequest,
+        *,
+        trajectory_id: str,
+    ) -> list[IngestRequest]:
+        provenance = [
+            "inferred:true",
+            "inference_type:trajectory_reflection",
+            f"trajectory:{trajectory_id}",
+        ]
+        events = [
+            IngestRequest(
+                content=lesson.content,
+                event_type="lesson_learned",
+                entity_id=request.entity_id,
+                metadata={
+                    **request.metadata,
+                    "summary": lesson.summary,
+                    "lesson_kind": lesson.kind,
+                    "relationships": [*provenance, f"lesson_kind:{lesson.kind}"],
+                },
+            )
+            for lesson in distill_lessons(request)
+        ]
+        successful_steps = [step for step in request.steps if not step.error]
+        if request.outcome == "success" and successful_steps:
+            procedure = cls.procedure_to_ingest(
+                ProcedureRequest(
+                    task=request.goal,
+                    steps=[
+                        ProcedureStep(
+                            action=step.action,
+                            tool=step.tool,
+                            arguments=step.arguments,
+                            result=step.result,
+                        )
+                        for step in successful_steps[:50]
+                    ],
+                    outcome=request.outcome_detail,
+                    entity_id=request.entity_id,
+                    metadata=request.metadata,
+                )
+            )
+            metadata = dict(procedure.metadata or {})
+            metadata["lesson_kind"] = "procedure"
+            metadata["relationships"] = [*metadata.get("relationships", []), *provenance]
+            events.insert(0, procedure.model_copy(update={"metadata": metadata}))
+        return events
+
+    @staticmethod
+    def _trajectory_id(request: ReflectRequest) -> str:
+        # Content-derived so resubmitting the same trajectory is recognizable (and replayable).
+        digest = hashlib.sha256(request.model_dump_json().encode("utf-8")).hexdigest()
+        return f"traj_{digest[:24]}"
+
+    def search_procedures(
+        self,
+        task: str,
+        *,
+        limit: int = 5,
+        entity_id: str | None = None,
+        account_key: str | None = None,
+    ) -> ProcedureSearchResponse:
+        """Find stored procedures for tasks resembling ``task``, best match first."""
+        start = perf_counter()
+        result = self.retrieve(
+            RetrieveRequest(
+                query=task,
+                limit=limit,
+                entity_id=entity_id,
+                event_type=PROCEDURE_EVENT_TYPE,
+                fallback="empty",
+            ),
+            account_key=account_key,
+        )
+        procedures = [
+            procedure
+            for procedure in (self._as_procedure(memory) for memory in result.memories)
+            if procedure is not None
+        ]
+        return ProcedureSearchResponse(
+            data=procedures,
+            query_execution_time_ms=(perf_counter() - start) * 1000.0,
+        )
+
+    @classmethod
+    def _as_procedure(cls, memory: Memory) -> Procedure | None:
+        relationships = [str(item) for item in memory.metadata.get("relationships", [])]
+        steps_raw = cls._relationship_value(relationships, "procedure_steps:")
+        if steps_raw is None:
+            return None
+        try:
+            steps = [ProcedureStep.model_validate(item) for item in json.loads(steps_raw)]
+        except ValueError:
+            return None
+        entities = memory.metadata.get("entities") or []
+        return Procedure(
+            memory_id=memory.memory_id,
+            task=cls._relationship_value(relationships, "procedure_task:")
+            or str(memory.metadata.get("summary", "")),
+            kind=cls._relationship_value(relationships, "procedure_kind:") or "tool_sequence",
+            steps=steps,
+            error_pattern=cls._relationship_value(relationships, "procedure_error:"),
+            outcome=cls._relationship_value(relationships, "procedure_outcome:"),
+            entity_id=str(entities[0]) if entities else None,
+            score=memory.rank_score,
+            created_at=memory.timestamp,
+        )
 
     def feedback_with_quota(
         self,
@@ -308,16 +1334,19 @@ class OrbitApiService:
         account_key: str,
         events: list[IngestRequest],
         idempotency_key: str | None,
+        key_id: str | None = None,
     ) -> tuple[list[IngestResponse], RateLimitSnapshot, bool]:
         payload = [item.model_dump(mode="json") for item in events]
-        return self._execute_write_operation(
+        groups = [self.apply_oversize_policy(item) for item in events]
+        expanded = [event for group in groups for event in group]
+        items, snapshot, replayed = self._execute_write_operation(
             account_key=account_key,
             operation="ingest_batch",
             idempotency_key=idempotency_key,
             payload=payload,
             quota_kind="event",
-            quota_amount=len(events),
-            execute=lambda: self.ingest_batch(events, account_key=account_key),
+            quota_amount=len(expanded),
+            execute=lambda: self._ingest_batch_expanded(groups, account_key=account_key),
             serialize=lambda responses: {
                 "items": [item.model_dump(mode="json") for item in responses]
             },
@@ -326,6 +1355,110 @@ class OrbitApiService:
             ],
             status_code=200,
         )
+        if not replayed:
+            self._observe_ingestion(
+                account_key=account_key,
+                key_id=key_id,
+                contents=[item.content for item in expanded],
+            )
+        return items, snapshot, replayed
+
+    @staticmethod
+    def _oversize_action(events: list[IngestRequest]) -> str | None:
+        relationships = [str(item) for item in (events[0].metadata or {}).get("relationships", [])]
+        return OrbitApiService._relationship_value(relationships, "oversize:")
+
+    def import_with_quota(
+        self,
+        *,
+        account_key: str,
+        events: list[IngestRequest],
+        mode: str,
+        idempotency_key: str | None,
+        key_id: str | None = None,
+    ) -> tuple[ImportResponse, RateLimitSnapshot, bool]:
+        """Bulk-load ``events``; ``backfill`` mode batches embeddings and defers consolidation.
+
+        Imports skip the per-minute rate limit but count against the event quota like ingest.
+        """
+        if mode not in IMPORT_MODES:
+            msg = f"mode must be one of: {', '.join(IMPORT_MODES)}"
+            raise ValueError(msg)
+        payload = {"mode": mode, "events": [item.model_dump(mode="json") for item in events]}
+        groups = [self.apply_oversize_policy(item) for item in events]
+        expanded = [event for group in groups for event in group]
+        result, snapshot, replayed = self._execute_write_operation(
+            account_key=account_key,
+            operation="import",
+            idempotency_key=idempotency_key,
+            payload=payload,
+            quota_kind="event",
+            quota_amount=len(expanded),
+            execute=lambda: self._import_expanded(groups, mode=mode, account_key=account_key),
+            serialize=lambda response: response.model_dump(mode="json"),
+            deserialize=ImportResponse.model_validate,
+            status_code=200,
+        )
+        if not replayed:
+            self._observe_ingestion(
+                account_key=account_key,
+                key_id=key_id,
+                contents=[item.content for item in expanded],
+            )
+        return result, snapshot, replayed
+
+    def _import_expanded(
+        self,
+        groups: list[list[IngestRequest]],
+        *,
+        mode: str,
+        account_key: str,
+    ) -> ImportResponse:
+        started = perf_counter()
+        items = self._ingest_batch_expanded(
+            groups,
+            account_key=account_key,
+            backfill=mode == "backfill",
+        )
+        elapsed = perf_counter() - started
+        count = sum(len(group) for group in groups)
+        imported = sum(1 for item in items if item.stored)
+        return ImportResponse(
+            mode=mode,
+            items=items,
+            imported=imported,
+            skipped=len(items) - imported,
+            duration_ms=round(elapsed * 1000.0, 3),
+            events_per_second=round(count / elapsed, 1) if elapsed > 0 else float(count),
+        )
+
+    def _ingest_batch_expanded(
+        self,
+        groups: list[list[IngestRequest]],
+        *,
+        account_key: str,
+        backfill: bool = False,
+    ) -> list[IngestResponse]:
+        flattened = [event for group in groups for event in group]
+        results = (
+            self._run_pipeline(
+                flattened,
+                account_key=self._normalize_account_key(account_key),
+                backfill=True,
+            )
+            if backfill
+            else self.ingest_batch(flattened, account_key=account_key)
+        )
+        merged: list[IngestResponse] = []
+        offset = 0
+        for group in groups:
+            group_results = results[offset : offset + len(group)]
+            offset += len(group)
+            if self._oversize_action(group) is None:
+                merged.append(group_results[0])
+            else:
+                merged.append(self._merge_oversize_results(group_results, group))
+        return merged
 
     def feedback_batch_with_quota(
         self,
@@ -359,12 +1492,14 @@ class OrbitApiService:
         account_key: str,
         name: str,
         scopes: list[str] | None = None,
+        allowed_origins: list[str] | None = None,
         actor_subject: str | None = None,
         actor_type: str = "dashboard_user",
     ) -> ApiKeyIssueResponse:
         normalized_account_key = self._normalize_account_key(account_key)
         normalized_name = self._normalize_api_key_name(name)
         normalized_scopes = self._normalize_scopes(scopes)
+        normalized_origins = self._normalize_origins(allowed_origins)
         policy = self._plan_policy(normalized_account_key)
         scopes_json = json.dumps(
             normalized_scopes,
@@ -408,6 +1543,7 @@ class OrbitApiService:
                             secret_hash=secret_hash,
                             hash_iterations=_API_KEY_HASH_ITERATIONS,
                             scopes_json=scopes_json,
+                            allowed_origins_json=json.dumps(normalized_origins),
                             status="active",
                             created_at=now,
                             last_used_at=None,
@@ -427,6 +1563,7 @@ class OrbitApiService:
                             "key_prefix": key_prefix,
                             "name": normalized_name,
                             "scopes": normalized_scopes,
+                            "allowed_origins": normalized_origins,
                         },
                     )
                     session.flush()
@@ -438,6 +1575,7 @@ class OrbitApiService:
                 name=normalized_name,
                 key_prefix=key_prefix,
                 scopes=normalized_scopes,
+                allowed_origins=normalized_origins,
                 status="active",
                 created_at=now,
                 last_used_at=None,
@@ -477,6 +1615,20 @@ class OrbitApiService:
             has_more=has_more,
         )
 
+    def api_key(self, *, account_key: str, key_id: str) -> ApiKeySummary:
+        normalized_account_key = self._normalize_account_key(account_key)
+        normalized_key_id = self._normalize_key_id(key_id)
+        with self._state_session_factory() as session:
+            row = session.scalar(
+                select(ApiKeyRow)
+                .where(ApiKeyRow.account_key == normalized_account_key)
+                .where(ApiKeyRow.key_id == normalized_key_id)
+            )
+        if row is None:
+            msg = f"api key not found: {normalized_key_id}"
+            raise KeyError(msg)
+        return self._as_api_key_summary(row)
+
     def revoke_api_key(
         self,
         *,
@@ -526,6 +1678,7 @@ class OrbitApiService:
         key_id: str,
         name: str | None = None,
         scopes: list[str] | None = None,
+        allowed_origins: list[str] | None = None,
         actor_subject: str | None = None,
         actor_type: str = "dashboard_user",
     ) -> ApiKeyRotateResponse:
@@ -533,6 +1686,9 @@ class OrbitApiService:
         normalized_key_id = self._normalize_key_id(key_id)
         new_name = self._normalize_api_key_name(name) if name is not None else None
         requested_scopes = self._normalize_scopes(scopes) if scopes is not None else None
+        requested_origins = (
+            self._normalize_origins(allowed_origins) if allowed_origins is not None else None
+        )
 
         for _ in range(5):
             now = datetime.now(UTC)
@@ -546,6 +1702,7 @@ class OrbitApiService:
             )
             resolved_name = ""
             resolved_scopes: list[str] = []
+            resolved_origins: list[str] = []
             try:
                 with self._state_session_factory() as session, session.begin():
                     existing = self._select_api_key_for_update(
@@ -568,6 +1725,11 @@ class OrbitApiService:
                     )
                     if not resolved_scopes:
                         resolved_scopes = list(_DEFAULT_KEY_SCOPES)
+                    resolved_origins = (
+                        requested_origins
+                        if requested_origins is not None
+                        else self._deserialize_scopes(existing.allowed_origins_json)
+                    )
                     scopes_json = json.dumps(
                         resolved_scopes,
                         sort_keys=True,
@@ -584,6 +1746,7 @@ class OrbitApiService:
                             secret_hash=secret_hash,
                             hash_iterations=_API_KEY_HASH_ITERATIONS,
                             scopes_json=scopes_json,
+                            allowed_origins_json=json.dumps(resolved_origins),
                             status="active",
                             created_at=now,
                             last_used_at=None,
@@ -607,6 +1770,7 @@ class OrbitApiService:
                             "new_key_prefix": key_prefix,
                             "name": resolved_name,
                             "scopes": resolved_scopes,
+                            "allowed_origins": resolved_origins,
                         },
                     )
                     session.flush()
@@ -620,6 +1784,7 @@ class OrbitApiService:
                     name=resolved_name,
                     key_prefix=key_prefix,
                     scopes=resolved_scopes,
+                    allowed_origins=resolved_origins,
                     status="active",
                     created_at=now,
                     last_used_at=None,
@@ -632,7 +1797,13 @@ class OrbitApiService:
         msg = "Failed to rotate API key. Please retry."
         raise RuntimeError(msg)
 
-    def authenticate_api_key(self, token: str, *, source: str | None = None) -> AuthContext:
+    def authenticate_api_key(
+        self,
+        token: str,
+        *,
+        source: str | None = None,
+        origin: str | None = None,
+    ) -> AuthContext:
         key_prefix, key_secret = self._parse_api_key_token(token)
         with self._state_session_factory() as session, session.begin():
             stmt = (
@@ -660,6 +1831,10 @@ class OrbitApiService:
             if not hmac.compare_digest(expected_hash, row.secret_hash):
                 msg = "API key secret mismatch."
                 raise ApiKeyAuthenticationError(msg)
+            allowed_origins = self._deserialize_scopes(row.allowed_origins_json)
+            if allowed_origins and (origin or "").strip().lower() not in allowed_origins:
+                msg = "API key is not allowed from this origin."
+                raise ApiKeyAuthenticationError(msg)
             row.last_used_at = datetime.now(UTC)
             row.last_used_source = self._normalize_optional_source(source)
             scopes = self._deserialize_scopes(row.scopes_json)
@@ -681,6 +1856,7 @@ class OrbitApiService:
                 "key_id": row.key_id,
                 "key_prefix": row.key_prefix,
                 "account_key": row.account_key,
+                "allowed_origins": allowed_origins,
             }
             return AuthContext(
                 subject=row.account_key,
@@ -689,456 +1865,3779 @@ class OrbitApiService:
                 claims=claims,
             )
 
-    def ingest(
+    def authenticate_signed_request(
         self,
-        request: IngestRequest,
         *,
-        account_key: str | None = None,
-    ) -> IngestResponse:
-        start = perf_counter()
-        normalized_account_key = self._normalize_account_key(account_key)
-        event = Event(
-            entity_id=request.entity_id or self._config.default_entity_id,
-            event_type=request.event_type or self._config.default_event_type,
-            description=request.content,
-            metadata=request.metadata or {},
-        )
-        processed = self._engine.process_input(event)
-        decision = self._engine.make_storage_decision(
-            processed,
-            account_key=normalized_account_key,
+        key_id: str,
+        signature: str,
+        method: str,
+        path: str,
+        query: str,
+        timestamp: str,
+        nonce: str,
+        body: bytes,
+        now: float | None = None,
+    ) -> AuthContext:
+        """Verify an HMAC-signed request; nonces are single-use within the skew window."""
+        configured = self._config.request_signing_keys.get(key_id)
+        if configured is None:
+            msg = "Unknown signing key."
+            raise ApiKeyAuthenticationError(msg)
+        account_key, _, secret = configured.partition(":")
+        try:
+            signed_at = int(timestamp)
+        except ValueError as exc:
+            msg = "Signature timestamp is malformed."
+            raise ApiKeyAuthenticationError(msg) from exc
+        current = now if now is not None else datetime.now(UTC).timestamp()
+        max_skew = self._config.request_signing_max_skew_seconds
+        if abs(current - signed_at) > max_skew:
+            msg = "Signature timestamp is outside the allowed window."
+            raise ApiKeyAuthenticationError(msg)
+        if not nonce or len(nonce) > 128:
+            msg = "Signature nonce is missing or too long."
+            raise ApiKeyAuthenticationError(msg)
+        expected = compute_signature(
+            secret,
+            canonical_request(
+                method=method,
+                path=path,
+                query=query,
+                timestamp=timestamp,
+                nonce=nonce,
+                body=body,
+            ),
         )
-        stored = self._engine.store_memory(
-            processed,
-            decision,
-            account_key=normalized_account_key,
+        if not hmac.compare_digest(expected, signature.lower()):
+            msg = "Signature mismatch."
+            raise ApiKeyAuthenticationError(msg)
+        claimed = self._claim_nonce(
+            operation=_SIGNING_NONCE_OPERATION,
+            account_key=key_id[:128],
+            nonce=hashlib.sha256(nonce.encode("utf-8")).hexdigest(),
+            max_age_seconds=2 * max_skew,
+            now=datetime.fromtimestamp(current, UTC),
+        )
+        if not claimed:
+            msg = "Signature nonce was already used."
+            raise ApiKeyAuthenticationError(msg)
+        return AuthContext(
+            subject=self._normalize_account_key(account_key),
+            scopes=list(_DEFAULT_KEY_SCOPES),
+            token="",
+            claims={
+                "auth_type": "signed_request",
+                "key_id": key_id,
+                "account_key": account_key,
+            },
         )
-        latency_ms = (perf_counter() - start) * 1000.0
 
-        memory_id = (
-            stored.memory_id
-            if stored is not None
-            else f"mem_{processed.event_id.replace('-', '')}"
+    def issue_browser_token(
+        self,
+        *,
+        auth: AuthContext,
+        request: BrowserTokenRequest,
+    ) -> BrowserTokenResponse:
+        """Mint a short-lived JWT that a browser can use for retrieval only."""
+        if request.ttl_seconds > self._config.browser_token_max_ttl_seconds:
+            msg = (
+                "ttl_seconds cannot exceed "
+                f"{self._config.browser_token_max_ttl_seconds}"
+            )
+            raise ValueError(msg)
+        scopes = request.scopes or ["read"]
+        disallowed = [scope for scope in scopes if scope not in _BROWSER_TOKEN_SCOPES]
+        if disallowed:
+            msg = f"scopes not allowed for browser tokens: {', '.join(disallowed)}"
+            raise ValueError(msg)
+        if "admin" not in auth.scopes and "*" not in auth.scopes:
+            held = {scope.removeprefix("memory:") for scope in auth.scopes}
+            if "write" in held:
+                held.add("feedback")
+            missing = [scope for scope in scopes if scope.removeprefix("memory:") not in held]
+            if missing:
+                msg = f"cannot grant scopes the caller does not hold: {', '.join(missing)}"
+                raise ValueError(msg)
+        token_scopes = list(scopes)
+        required_scope = self._config.jwt_required_scope
+        if required_scope and required_scope not in token_scopes:
+            token_scopes.append(required_scope)
+        entity_id = request.entity_id.strip() if request.entity_id else None
+        allowed_origins = self._normalize_origins(request.allowed_origins)
+        issued_at = datetime.now(UTC)
+        expires_at = issued_at + timedelta(seconds=request.ttl_seconds)
+        claims: dict[str, Any] = {
+            "sub": auth.subject,
+            "iss": self._config.jwt_issuer,
+            "aud": self._config.jwt_audience,
+            "iat": int(issued_at.timestamp()),
+            "exp": int(expires_at.timestamp()),
+            "jti": uuid4().hex,
+            "scopes": token_scopes,
+            "auth_type": BROWSER_TOKEN_AUTH_TYPE,
+            "account_key": auth.subject,
+            "allowed_origins": allowed_origins,
+        }
+        if entity_id:
+            claims["entity_id"] = entity_id
+        token = jwt.encode(
+            claims,
+            key=self._config.jwt_secret,
+            algorithm=self._config.jwt_algorithm,
         )
-        decision_reason = (
-            decision.rationale
-            if decision.store
-            else f"Discarded by policy: {decision.rationale}"
+        return BrowserTokenResponse(
+            token=token,
+            expires_at=expires_at,
+            scopes=scopes,
+            entity_id=entity_id,
+            allowed_origins=allowed_origins,
         )
 
-        with self._state_lock:
-            self._latest_ingestion = datetime.now(UTC)
-            self._metrics["ingest_requests_total"] += 1
-            self._metrics["ingest_latency_ms_sum"] += latency_ms
+    def ingest(
+        self,
+        request: IngestRequest,
+        *,
+        account_key: str | None = None,
+    ) -> IngestResponse:
+        return self.ingest_batch([request], account_key=account_key)[0]
 
-        return IngestResponse(
-            memory_id=memory_id,
-            stored=decision.store,
-            importance_score=float(max(0.0, min(1.0, decision.confidence))),
-            decision_reason=decision_reason,
-            encoded_at=processed.timestamp,
-            latency_ms=latency_ms,
+    def pipeline_for(self, account_key: str | None = None) -> list[str]:
+        """Ingest stage order for the account.
+
+        A pipeline set through the namespace API wins, then the account's
+        ``pipeline_namespaces`` entry, then ``pipeline_stages``.
+        """
+        namespace = self._normalize_account_key(account_key)
+        with self._state_session_factory() as session:
+            pipeline_json = session.scalar(
+                select(ApiNamespaceRow.pipeline_json).where(
+                    ApiNamespaceRow.account_key == namespace
+                )
+            )
+        if pipeline_json:
+            return list(json.loads(pipeline_json))
+        return list(
+            self._config.pipeline_namespaces.get(namespace, self._config.pipeline_stages)
         )
 
-    def ingest_batch(
+    def _run_pipeline(
         self,
         events: list[IngestRequest],
         *,
-        account_key: str | None = None,
+        account_key: str,
+        skip: frozenset[str] = frozenset(),
+        sample: bool = True,
+        backfill: bool = False,
     ) -> list[IngestResponse]:
-        return [self.ingest(item, account_key=account_key) for item in events]
-
-    def retrieve(
-        self,
-        request: RetrieveRequest,
-        *,
-        account_key: str | None = None,
-    ) -> RetrieveResponse:
-        start = perf_counter()
-        normalized_account_key = self._normalize_account_key(account_key)
-        query_embedding = np.asarray(
-            self._engine.input_processor.encoder.encode_query(request.query),
-            dtype=np.float32,
+        self._check_event_types(events, account_key=account_key)
+        for item in events:
+            self._occurred_at(item)
+        self._check_write_policy(events, account_key=account_key)
+        batch = (
+            self._sample_events(events, account_key=account_key) if sample else _SampledBatch()
         )
-        now = datetime.now(UTC)
-        pool_size = max(120, request.limit * 20)
-        preselected: list[MemoryRecord]
-        if request.entity_id:
-            entity_ids_fn = getattr(self._engine, "memory_ids_for_entity", None)
-            entity_ids = (
-                entity_ids_fn(
-                    request.entity_id,
-                    account_key=normalized_account_key,
+        repeats = {
+            index for positions in batch.new_counters.values() for index in positions[1:]
+        }
+        if "review" not in skip:
+            counted = {index for positions in batch.new_counters.values() for index in positions}
+            batch.responses.update(
+                self._hold_for_review(
+                    events,
+                    account_key=account_key,
+                    exclude={*batch.responses, *counted},
                 )
-                if callable(entity_ids_fn)
-                else []
             )
-            preselected = self._engine.storage.fetch_by_ids(
-                entity_ids,
-                account_key=normalized_account_key,
+        order = self.pipeline_for(account_key)
+        contexts: list[IngestContext] = []
+        try:
+            for index, item in enumerate(events):
+                if index in batch.responses or index in repeats:
+                    continue
+                contexts.append(self._ingest_context(item, account_key=account_key))
+                contexts[-1].backfill = backfill
+                # A backfill embeds the whole batch at once, below.
+                self._pipeline.prepare(
+                    order, contexts[-1], skip=(skip | {"embedding"}) if backfill else skip
+                )
+            if backfill:
+                self._embed_backfill(contexts, account_key=account_key)
+        except Exception:
+            for context in contexts:
+                self._discard_attachment(context)
+            raise
+        blocked = [
+            (context.screened_request(), context.verdict)
+            for context in contexts
+            if context.halted_by == "moderation" and context.verdict is not None
+        ]
+        if blocked:
+            for context in contexts:
+                self._discard_attachment(context)
+            # Nothing from a batch is stored when any event in it is blocked.
+            self._raise_blocked(blocked, account_key=account_key)
+        committed: list[IngestResponse] = []
+        for position, context in enumerate(contexts):
+            try:
+                indexed = self._pipeline.commit(context)
+            except Exception:
+                # Events from here on were never stored, so their blobs would be orphaned.
+                for pending in contexts[position:]:
+                    self._discard_attachment(pending)
+                raise
+            committed.append(self._finish_ingest(indexed))
+        finished = iter(committed)
+        if backfill:
+            # Runs on the maintenance worker so a large import returns once its writes land.
+            submit_with_context(
+                self._maintenance_executor,
+                self._consolidate_backfill,
+                [
+                    (context.processed, context.stored, context.decision.should_compress)
+                    for context in contexts
+                    if context.stored is not None
+                    and context.processed is not None
+                    and context.decision is not None
+                ],
+                account_key=account_key,
             )
-            if not preselected:
-                vector_store = getattr(self._engine, "vector_store", None)
-                if vector_store is None:
-                    preselected = self._engine.storage.search_candidates(
-                        query_embedding,
-                        top_k=pool_size,
-                        account_key=normalized_account_key,
-                    )
+        responses: list[IngestResponse | None] = []
+        for index in range(len(events)):
+            if index in batch.responses:
+                responses.append(batch.responses[index])
+            else:
+                # Repeats of a new counter observation are answered once it is stored.
+                responses.append(None if index in repeats else next(finished))
+        for key, positions in batch.new_counters.items():
+            first = responses[positions[0]]
+            if first is None:
+                continue
+            for occurrence, index in enumerate(positions[1:], start=2):
+                responses[index] = self._counted_response(
+                    first.memory_id,
+                    occurrence=occurrence,
+                    now=datetime.now(UTC),
+                )
+            if first.stored:
+                self._start_counter(
+                    key,
+                    memory_id=first.memory_id,
+                    occurrences=len(positions),
+                    account_key=account_key,
+                )
+        if batch.summaries:
+            # Windows closed by this batch are stored as one memory each, without resampling.
+            self._run_pipeline(
+                batch.summaries, account_key=account_key, sample=False, backfill=backfill
+            )
+        return [response for response in responses if response is not None]
+
+    def _consolidate_backfill(self, stored: list[Any], *, account_key: str) -> None:
+        try:
+            self._engine.consolidate(stored, account_key=account_key)
+        except Exception:  # pylint: disable=broad-exception-caught
+            # The memories are stored either way; only their clustering and compression wait.
+            with self._state_lock:
+                self._metrics["backfill_consolidation_failures_total"] += 1
+
+    def _sample_events(self, events: list[IngestRequest], *, account_key: str) -> _SampledBatch:
+        """Apply the registry's sampling policies to ``events``."""
+        with self._state_session_factory() as session:
+            row = session.get(ApiEventTypeRegistryRow, account_key)
+            definitions = json.loads(row.event_types_json) if row is not None else []
+        policies = {
+            item["name"]: EventTypeSampling.model_validate(item["sampling"])
+            for item in definitions
+            if item.get("sampling")
+        }
+        batch = _SampledBatch()
+        for index, item in enumerate(events):
+            event_type = item.event_type or self._config.default_event_type
+            policy = policies.get(event_type)
+            if policy is None:
+                continue
+            now = datetime.now(UTC)
+            if policy.mode == "counter":
+                key = (
+                    item.entity_id or self._config.default_entity_id,
+                    event_type,
+                    self._content_fingerprint(item.content),
+                )
+                if key in batch.new_counters:
+                    batch.new_counters[key].append(index)
+                    continue
+                counted = self._count_occurrence(key, account_key=account_key, now=now)
+                if counted is None:
+                    batch.new_counters[key] = [index]
                 else:
-                    hits = vector_store.search(query_embedding, top_k=pool_size)
-                    preselected = self._engine.storage.fetch_by_ids(
-                        [hit.memory_id for hit in hits],
-                        account_key=normalized_account_key,
+                    memory_id, occurrence = counted
+                    batch.responses[index] = self._counted_response(
+                        memory_id,
+                        occurrence=occurrence,
+                        now=now,
                     )
-        else:
-            vector_store = getattr(self._engine, "vector_store", None)
-            if vector_store is not None:
-                hits = vector_store.search(query_embedding, top_k=pool_size)
-                preselected = self._engine.storage.fetch_by_ids(
-                    [hit.memory_id for hit in hits],
-                    account_key=normalized_account_key,
-                )
+                continue
+            if policy.mode == "sample":
+                if self._random.random() < policy.rate:
+                    continue
+                reason = f"Sampled out: {policy.rate:g} of {event_type} events are stored"
             else:
-                preselected = self._engine.storage.search_candidates(
-                    query_embedding,
-                    top_k=pool_size,
-                    account_key=normalized_account_key,
+                summary = self._aggregate_event(
+                    item,
+                    event_type=event_type,
+                    window=timedelta(seconds=policy.window_seconds),
+                    account_key=account_key,
+                    now=now,
                 )
-        if len(preselected) < request.limit:
-            fallback = self._engine.storage.search_candidates(
-                query_embedding,
-                top_k=max(pool_size, request.limit * 4),
-                account_key=normalized_account_key,
+                if summary is not None:
+                    batch.summaries.append(summary)
+                reason = (
+                    f"Aggregated: one {event_type} memory is stored per "
+                    f"{policy.window_seconds} s window"
+                )
+            batch.responses[index] = IngestResponse(
+                memory_id=f"mem_{uuid4().hex}",
+                stored=False,
+                importance_score=0.0,
+                decision_reason=reason,
+                encoded_at=now,
+                latency_ms=0.0,
             )
-            seen_ids = {item.memory_id for item in preselected}
-            preselected.extend(
-                item for item in fallback if item.memory_id not in seen_ids
+        return batch
+
+    def _count_occurrence(
+        self,
+        key: tuple[str, str, str],
+        *,
+        account_key: str,
+        now: datetime,
+    ) -> tuple[str, int] | None:
+        """Count a repeat on the memory that stored ``key``: ``(memory_id, occurrences)``.
+
+        ``None`` when this is the first occurrence, or the counted memory has been deleted.
+        """
+        entity_id, event_type, content_hash = key
+        with self._state_lock, self._state_session_factory() as session:
+            row = session.scalar(
+                select(ApiMemoryCounterRow).where(
+                    ApiMemoryCounterRow.account_key == account_key,
+                    ApiMemoryCounterRow.entity_id == entity_id,
+                    ApiMemoryCounterRow.event_type == event_type,
+                    ApiMemoryCounterRow.content_hash == content_hash,
+                )
             )
-        candidates = self._apply_filters(
-            records=preselected,
-            entity_id=request.entity_id,
-            event_type=request.event_type,
-            start_time=request.time_range.start if request.time_range else None,
-            end_time=request.time_range.end if request.time_range else None,
-        )
-        candidates = self._ensure_non_assistant_candidates(
-            candidates=candidates,
-            top_k=request.limit,
-            query=request.query,
-            pool_size=pool_size,
-            entity_id=request.entity_id,
-            event_type=request.event_type,
-            start_time=request.time_range.start if request.time_range else None,
-            end_time=request.time_range.end if request.time_range else None,
-            account_key=normalized_account_key,
+            if row is None:
+                return None
+            if not self._engine.storage.fetch_by_ids([row.memory_id], account_key=account_key):
+                session.delete(row)
+                session.commit()
+                return None
+            row.occurrences += 1
+            row.last_seen_at = now
+            session.commit()
+            return row.memory_id, row.occurrences
+
+    def _start_counter(
+        self,
+        key: tuple[str, str, str],
+        *,
+        memory_id: str,
+        occurrences: int,
+        account_key: str,
+    ) -> None:
+        entity_id, event_type, content_hash = key
+        now = datetime.now(UTC)
+        with self._state_session_factory() as session:
+            session.add(
+                ApiMemoryCounterRow(
+                    account_key=account_key,
+                    entity_id=entity_id,
+                    event_type=event_type,
+                    content_hash=content_hash,
+                    memory_id=memory_id,
+                    occurrences=occurrences,
+                    first_seen_at=now,
+                    last_seen_at=now,
+                )
+            )
+            try:
+                session.commit()
+            except IntegrityError:
+                # Another process stored the same observation first; both memories are kept.
+                session.rollback()
+
+    @staticmethod
+    def _counted_response(memory_id: str, *, occurrence: int, now: datetime) -> IngestResponse:
+        return IngestResponse(
+            memory_id=memory_id,
+            stored=False,
+            importance_score=0.0,
+            decision_reason=f"Counted: occurrence {occurrence} of memory {memory_id}",
+            encoded_at=now,
+            latency_ms=0.0,
         )
-        ranked = self._engine.ranker.rank(query_embedding, candidates, now=now)
-        ranked = self._diversity_aware_rerank(ranked)
-        ranked = self._reweight_ranked_by_query(
-            query=request.query,
-            ranked=ranked,
-            candidates=candidates,
+
+    def _memory_occurrences(self, memories: list[Memory], *, account_key: str) -> None:
+        """Add ``metadata["occurrences"]`` to memories that count repeated observations."""
+        by_id = {memory.memory_id: memory for memory in memories}
+        if not by_id:
+            return
+        with self._state_session_factory() as session:
+            rows = session.scalars(
+                select(ApiMemoryCounterRow).where(
+                    ApiMemoryCounterRow.account_key == account_key,
+                    ApiMemoryCounterRow.memory_id.in_(list(by_id)),
+                )
+            ).all()
+        for row in rows:
+            by_id[row.memory_id].metadata["occurrences"] = {
+                "count": row.occurrences,
+                "first_seen_at": _as_utc(row.first_seen_at).isoformat(),
+                "last_seen_at": _as_utc(row.last_seen_at).isoformat(),
+            }
+
+    def _aggregate_event(
+        self,
+        request: IngestRequest,
+        *,
+        event_type: str,
+        window: timedelta,
+        account_key: str,
+        now: datetime,
+    ) -> IngestRequest | None:
+        """Count ``request`` in its entity's window; returns the summary of a window it closed."""
+        entity_id = request.entity_id or self._config.default_entity_id
+        summary: IngestRequest | None = None
+        with self._state_lock, self._state_session_factory() as session:
+            row = session.scalar(
+                select(ApiIngestAggregateRow).where(
+                    ApiIngestAggregateRow.account_key == account_key,
+                    ApiIngestAggregateRow.entity_id == entity_id,
+                    ApiIngestAggregateRow.event_type == event_type,
+                )
+            )
+            if row is None:
+                row = ApiIngestAggregateRow(
+                    account_key=account_key,
+                    entity_id=entity_id,
+                    event_type=event_type,
+                    event_count=0,
+                    window_start=now,
+                    last_seen_at=now,
+                )
+                session.add(row)
+            elif now - _as_utc(row.window_start) >= window:
+                summary = self._aggregate_summary(row)
+                row.window_start = now
+                row.event_count = 0
+            row.event_count += 1
+            row.latest_content = request.content
+            row.last_seen_at = now
+            session.commit()
+        return summary
+
+    @staticmethod
+    def _aggregate_summary(row: ApiIngestAggregateRow) -> IngestRequest:
+        start, end = _as_utc(row.window_start), _as_utc(row.last_seen_at)
+        latest = row.latest_content
+        if len(latest) > _AGGREGATE_LATEST_CHARS:
+            latest = f"{latest[: _AGGREGATE_LATEST_CHARS - 3]}..."
+        return IngestRequest(
+            content=(
+                f"{row.event_count} {row.event_type} events from {start:%Y-%m-%d %H:%M} to "
+                f"{end:%Y-%m-%d %H:%M} UTC. Latest: {latest}"
+            ),
+            event_type=row.event_type,
+            entity_id=row.entity_id,
+            metadata={
+                "aggregate": {
+                    "event_count": row.event_count,
+                    "window_start": start.isoformat(),
+                    "window_end": end.isoformat(),
+                }
+            },
         )
-        ranked = self._promote_primary_candidate_for_query(
-            query=request.query,
-            ranked=ranked,
+
+    def _ingest_context(self, request: IngestRequest, *, account_key: str) -> IngestContext:
+        metadata = dict(request.metadata or {})
+        if "relationships" in metadata:
+            metadata["relationships"] = [
+                str(item)
+                for item in metadata["relationships"]
+                if not str(item).startswith(_RESERVED_RELATIONSHIP_PREFIXES)
+            ]
+        if request.attachment is not None:
+            metadata["relationships"] = [
+                *[str(item) for item in metadata.get("relationships", [])],
+                *self._store_attachment(request, account_key=account_key),
+            ]
+        tags = metadata.get("tags")
+        if isinstance(tags, list) and tags:
+            # Tags are kept as relationships so tag-based shares can match them later.
+            metadata["relationships"] = [
+                *[str(item) for item in metadata.get("relationships", [])],
+                *[f"tag:{str(tag).strip()}" for tag in tags if str(tag).strip()],
+            ]
+        sensitivity = request.sensitivity or self._config.default_sensitivity
+        if sensitivity != "public":
+            # Unlabeled memories read back as public, so only restricted labels are recorded.
+            metadata["relationships"] = [
+                *[str(item) for item in metadata.get("relationships", [])],
+                f"sensitivity:{sensitivity}",
+            ]
+        if request.agent_id:
+            agent_relationships = [f"agent:{request.agent_id}"]
+            if (request.agent_scope or self._config.default_agent_scope) == "private":
+                agent_relationships.append("agent_scope:private")
+            metadata["relationships"] = [
+                *[str(item) for item in metadata.get("relationships", [])],
+                *agent_relationships,
+            ]
+        return IngestContext(
+            request=request,
+            account_key=account_key,
+            content=request.content,
+            metadata=metadata,
         )
-        selected = self._select_with_intent_caps(
-            ranked,
-            top_k=request.limit,
-            query=request.query,
+
+    def _ingest_event(self, context: IngestContext) -> Event:
+        return Event(
+            entity_id=context.request.entity_id or self._config.default_entity_id,
+            event_type=context.request.event_type or self._config.default_event_type,
+            description=context.content,
+            metadata=context.metadata,
+            timestamp=self._occurred_at(context.request),
         )
-        memories: list[Memory] = []
-        for index, ranked_item in enumerate(selected, start=1):
-            self._engine.storage.update_retrieval(
-                ranked_item.memory.memory_id,
-                account_key=normalized_account_key,
+
+    def _occurred_at(self, request: IngestRequest) -> datetime:
+        """When ``request``'s event happened, checked against the clock and backfill window."""
+        now = clock.now()
+        if request.occurred_at is None:
+            return now
+        occurred_at = _as_utc(request.occurred_at).astimezone(UTC)
+        if occurred_at - now > timedelta(seconds=self._config.max_clock_skew_seconds):
+            msg = (
+                f"occurred_at is more than ORBIT_MAX_CLOCK_SKEW_SECONDS="
+                f"{self._config.max_clock_skew_seconds} ahead of the server clock"
             )
-            memories.append(
-                self._as_memory(
-                    ranked_item.memory,
-                    rank_position=index,
-                    rank_score=float(ranked_item.rank_score),
-                )
+            raise ValueError(msg)
+        if now - occurred_at > timedelta(days=self._config.max_backfill_days):
+            msg = (
+                "occurred_at is older than "
+                f"ORBIT_MAX_BACKFILL_DAYS={self._config.max_backfill_days}"
             )
+            raise ValueError(msg)
+        # A client clock running slightly fast is not a future event.
+        return min(occurred_at, now)
 
-        query_execution_time_ms = (perf_counter() - start) * 1000.0
-        with self._state_lock:
-            self._metrics["retrieve_requests_total"] += 1
-            self._metrics["retrieve_latency_ms_sum"] += query_execution_time_ms
+    def _stage_moderation(self, context: IngestContext) -> None:
+        context.verdict = self._moderate(context.screened_request())
+        if context.verdict is not None and context.verdict.action == "block":
+            context.halt("moderation")
 
-        applied_filters: dict[str, str] = {}
-        if request.entity_id:
-            applied_filters["entity_id"] = request.entity_id
-        if request.event_type:
-            applied_filters["event_type"] = request.event_type
-        if request.time_range:
-            applied_filters["start_time"] = request.time_range.start.isoformat()
-            applied_filters["end_time"] = request.time_range.end.isoformat()
+    def _stage_pii(self, context: IngestContext) -> None:
+        content, kinds = redact_pii(context.content)
+        if kinds:
+            context.content = content
+            context.add_relationships(*[f"pii_redacted:{kind}" for kind in kinds])
 
-        return RetrieveResponse(
-            memories=memories,
-            total_candidates=len(candidates),
-            query_execution_time_ms=query_execution_time_ms,
-            applied_filters=applied_filters,
+    def _stage_webhook(self, context: IngestContext) -> None:
+        with self._state_session_factory() as session:
+            row = session.get(ApiPipelineWebhookRow, context.account_key)
+            if row is None:
+                return
+            target = WebhookTarget(
+                url=row.url,
+                secret=row.secret,
+                timeout_ms=row.timeout_ms,
+                failure_policy=row.failure_policy,
+                policy=self._outbound_policy(),
+            )
+        try:
+            apply_mutations(
+                context,
+                call_transform_webhook(target, transform_payload(context)),
+                max_content_chars=self._config.max_ingest_content_chars,
+            )
+        except PipelineWebhookError as exc:
+            if target.failure_policy == "reject":
+                raise
+            if target.failure_policy == "drop":
+                context.halt("webhook", reason=str(exc))
+
+    def _stage_extraction(self, context: IngestContext) -> None:
+        context.extracted = self._engine.extract_input(
+            self._ingest_event(context),
+            account_key=context.account_key,
         )
 
-    def feedback(
-        self,
-        request: FeedbackRequest,
-        *,
-        account_key: str | None = None,
-    ) -> FeedbackResponse:
-        start = perf_counter()
-        normalized_account_key = self._normalize_account_key(account_key)
-        existing = self._engine.storage.fetch_by_ids(
-            [request.memory_id],
-            account_key=normalized_account_key,
+    def _stage_dedup(self, context: IngestContext) -> None:
+        """Halt when the entity already has a memory with the same content and intent."""
+        event = self._ingest_event(context)
+        intent = (
+            context.extracted.understanding.intent
+            if context.extracted is not None
+            else str(event.metadata.get("intent", event.event_type))
         )
-        if not existing:
-            msg = f"memory_id {request.memory_id} was not found"
-            raise KeyError(msg)
+        since = datetime.now(UTC) - timedelta(days=self._config.dedup_window_days)
+        fingerprint = self._content_fingerprint(context.content)
+        for record in self._engine.storage.fetch_by_entity_and_intent(
+            entity_id=event.entity_id,
+            intent=intent,
+            since_iso=since.isoformat(),
+            account_key=context.account_key,
+        ):
+            if self._content_fingerprint(record.content) == fingerprint:
+                context.duplicate_of = record
+                context.halt("dedup")
+                return
 
-        outcome_signal = (
-            request.outcome_value
-            if request.outcome_value is not None
-            else (1.0 if request.helpful else -1.0)
-        )
-        helpful_ids = [request.memory_id] if request.helpful else []
-        self._engine.record_feedback(
-            query=f"memory:{request.memory_id}",
-            ranked_memory_ids=[request.memory_id],
-            helpful_memory_ids=helpful_ids,
-            outcome_signal=outcome_signal,
-            account_key=normalized_account_key,
+    @staticmethod
+    def _stage_sentiment(context: IngestContext) -> None:
+        sentiment, emotions = classify_sentiment(context.content)
+        context.add_relationships(
+            f"sentiment:{sentiment}",
+            *[f"emotion:{emotion}" for emotion in emotions],
         )
 
-        latency_ms = (perf_counter() - start) * 1000.0
-        with self._state_lock:
-            self._metrics["feedback_requests_total"] += 1
-            self._metrics["feedback_latency_ms_sum"] += latency_ms
+    def _stage_categorization(self, context: IngestContext) -> None:
+        category = categorize(context.content, self._config.memory_categories)
+        context.add_relationships(f"category:{category}")
 
-        impact = (
-            "Positive signal recorded. This will improve ranking for similar queries."
-            if request.helpful
-            else "Negative signal recorded. This helps suppress low-value memories."
+    def _stage_embedding(self, context: IngestContext) -> None:
+        extracted = context.extracted or self._engine.extract_input(
+            self._ingest_event(context),
+            account_key=context.account_key,
+            use_provider=False,
         )
-        return FeedbackResponse(
-            recorded=True,
-            memory_id=request.memory_id,
-            learning_impact=impact,
-            updated_at=datetime.now(UTC),
+        context.processed = self._engine.embed_input(extracted, account_key=context.account_key)
+
+    def _embed_backfill(self, contexts: list[IngestContext], *, account_key: str) -> None:
+        """The embedding stage for a whole backfill batch, in one provider call."""
+        pending = [context for context in contexts if context.halted_by is None]
+        if not pending:
+            return
+        processed = self._engine.embed_inputs(
+            [
+                context.extracted
+                or self._engine.extract_input(
+                    self._ingest_event(context),
+                    account_key=account_key,
+                    use_provider=False,
+                )
+                for context in pending
+            ],
+            account_key=account_key,
         )
+        for context, item in zip(pending, processed, strict=True):
+            context.processed = item
 
-    def feedback_batch(
-        self,
-        feedback: list[FeedbackRequest],
-        *,
-        account_key: str | None = None,
-    ) -> list[FeedbackResponse]:
-        return [self.feedback(item, account_key=account_key) for item in feedback]
-
-    def status(self, account_key: str) -> StatusResponse:
-        now = datetime.now(UTC)
-        normalized_account_key = self._normalize_account_key(account_key)
-        policy = self._plan_policy(normalized_account_key)
-        with self._state_lock:
-            latest_ingestion = self._latest_ingestion
-        usage = self._read_usage_row(normalized_account_key)
-        pilot_pro_request_row = self._read_pilot_pro_request(normalized_account_key)
-        storage_mb = self._storage_usage_mb(account_key=normalized_account_key)
-        active_api_keys = self._count_active_api_keys(normalized_account_key)
-        events_month = (
-            usage.events_month
-            if usage
-            and usage.month_year == now.year
-            and usage.month_value == now.month
-            else 0
-        )
-        queries_month = (
-            usage.queries_month
-            if usage
-            and usage.month_year == now.year
-            and usage.month_value == now.month
-            else 0
+    def _stage_indexing(self, context: IngestContext) -> None:
+        if context.processed is None:
+            msg = "indexing requires the embedding stage"
+            raise RuntimeError(msg)
+        context.decision = self._engine.make_storage_decision(
+            context.processed,
+            account_key=context.account_key,
         )
-        pilot_pro_request = self._as_pilot_pro_request(
-            row=pilot_pro_request_row,
-            policy=policy,
+        context.stored = self._engine.store_memory(
+            context.processed,
+            context.decision,
+            account_key=context.account_key,
+            consolidate=not context.backfill,
         )
-        metadata_summary = self._metadata_summary(normalized_account_key)
 
-        return StatusResponse(
-            connected=True,
-            api_version=self._config.api_version,
-            account_usage=AccountUsage(
-                events_ingested_this_month=events_month,
-                queries_this_month=queries_month,
-                storage_usage_mb=storage_mb,
-                active_api_keys=active_api_keys,
-                quota=AccountQuota(
-                    events_per_day=max(policy.ingest_events_per_month // 30, 1),
-                    queries_per_day=max(policy.retrieve_queries_per_month // 30, 1),
-                    events_per_month=policy.ingest_events_per_month,
-                    queries_per_month=policy.retrieve_queries_per_month,
-                    api_keys=policy.api_keys_limit,
-                    retention_days=policy.retention_days,
-                    plan=policy.plan,
-                    reset_at=datetime.fromtimestamp(
-                        self._next_month_reset_epoch(now),
-                        tz=UTC,
-                    ),
-                    warning_threshold_percent=policy.warning_threshold_percent,
-                    critical_threshold_percent=policy.critical_threshold_percent,
-                ),
-            ),
-            pilot_pro_request=pilot_pro_request,
-            latest_ingestion=latest_ingestion,
-            uptime_percent=self._config.uptime_percent,
-            metadata_summary=metadata_summary,
-        )
+    @staticmethod
+    def _content_fingerprint(content: str) -> str:
+        return hashlib.sha256(" ".join(content.lower().split()).encode("utf-8")).hexdigest()
 
-    def tenant_metrics(self, account_key: str) -> TenantMetricsResponse:
-        now = datetime.now(UTC)
-        normalized_account_key = self._normalize_account_key(account_key)
-        policy = self._plan_policy(normalized_account_key)
-        usage = self._read_usage_row(normalized_account_key)
-        events_month = (
-            usage.events_month
-            if usage
-            and usage.month_year == now.year
-            and usage.month_value == now.month
-            else 0
-        )
-        queries_month = (
-            usage.queries_month
-            if usage
-            and usage.month_year == now.year
-            and usage.month_value == now.month
-            else 0
-        )
-        active_api_keys = self._count_active_api_keys(normalized_account_key)
-        storage_mb = self._storage_usage_mb(account_key=normalized_account_key)
-        pilot_pro_request_row = self._read_pilot_pro_request(normalized_account_key)
-        pilot_pro_requested_at = (
-            pilot_pro_request_row.requested_at if pilot_pro_request_row else None
+    def _discard_attachment(self, context: IngestContext) -> None:
+        orphaned_key = self._relationship_value(
+            [str(item) for item in context.metadata.get("relationships", [])],
+            "blob_key:",
         )
-        pilot_pro_requested = (
-            pilot_pro_request_row is not None
-            and self._normalize_pilot_pro_status(pilot_pro_request_row.status)
-            == "requested"
+        if (
+            orphaned_key
+            and context.request.attachment is not None
+            and blob_key_belongs_to(orphaned_key, context.account_key)
+        ):
+            self._blob_store.delete(orphaned_key)
+
+    def _delete_removed_attachment(self, operation: str, memory: MemoryRecord) -> None:
+        """Delete a deleted memory's attachment blob, whether a user or retention removed it."""
+        if operation != "deleted":
+            return
+        attachment = self._attachment_metadata(memory)
+        if attachment is None or not blob_key_belongs_to(
+            str(attachment["key"]), self._normalize_account_key(memory.account_key)
+        ):
+            return
+        try:
+            self._blob_store.delete(str(attachment["key"]))
+        except Exception:  # pylint: disable=broad-exception-caught
+            # The memory is already gone, and a replica may never have had the blob; a delete
+            # the store refuses leaves it behind rather than failing the deletion.
+            return
+
+    def _finish_ingest(self, context: IngestContext) -> IngestResponse:
+        latency_ms = sum(context.stage_latency_ms.values())
+        with self._state_lock:
+            self._latest_ingestion = datetime.now(UTC)
+            self._metrics["ingest_requests_total"] += 1
+            self._metrics["ingest_latency_ms_sum"] += latency_ms
+
+        duplicate = context.duplicate_of
+        if duplicate is not None:
+            self._discard_attachment(context)
+            return IngestResponse(
+                memory_id=duplicate.memory_id,
+                stored=False,
+                importance_score=float(max(0.0, min(1.0, duplicate.latest_importance))),
+                decision_reason=f"Duplicate of existing memory {duplicate.memory_id}",
+                encoded_at=duplicate.created_at,
+                latency_ms=latency_ms,
+            )
+        if context.halted_by is not None:
+            # A custom stage dropped the event before it was indexed.
+            self._discard_attachment(context)
+            reason = f": {context.halt_reason}" if context.halt_reason else ""
+            return IngestResponse(
+                memory_id=f"mem_{uuid4().hex}",
+                stored=False,
+                importance_score=0.0,
+                decision_reason=f"Dropped by pipeline stage {context.halted_by}{reason}",
+                encoded_at=datetime.now(UTC),
+                latency_ms=latency_ms,
+            )
+        processed, decision, stored = context.processed, context.decision, context.stored
+        if processed is None or decision is None:
+            msg = "ingest pipeline finished without a storage decision"
+            raise RuntimeError(msg)
+        if stored is None:
+            self._discard_attachment(context)
+
+        memory_id = (
+            stored.memory_id
+            if stored is not None
+            else f"mem_{processed.event_id.replace('-', '')}"
         )
-        return TenantMetricsResponse(
-            generated_at=now,
-            plan=policy.plan,
-            reset_at=datetime.fromtimestamp(
-                self._next_month_reset_epoch(now),
-                tz=UTC,
-            ),
-            warning_threshold_percent=policy.warning_threshold_percent,
-            critical_threshold_percent=policy.critical_threshold_percent,
-            ingest=self._usage_metric(
-                used=events_month,
-                limit=policy.ingest_events_per_month,
-                warning_threshold_percent=policy.warning_threshold_percent,
-                critical_threshold_percent=policy.critical_threshold_percent,
-            ),
-            retrieve=self._usage_metric(
-                used=queries_month,
-                limit=policy.retrieve_queries_per_month,
-                warning_threshold_percent=policy.warning_threshold_percent,
-                critical_threshold_percent=policy.critical_threshold_percent,
-            ),
-            api_keys=self._usage_metric(
-                used=active_api_keys,
-                limit=policy.api_keys_limit,
-                warning_threshold_percent=policy.warning_threshold_percent,
-                critical_threshold_percent=policy.critical_threshold_percent,
-            ),
-            storage_usage_mb=storage_mb,
-            pilot_pro_requested=pilot_pro_requested,
-            pilot_pro_requested_at=pilot_pro_requested_at,
+        decision_reason = (
+            decision.rationale
+            if decision.store
+            else f"Discarded by policy: {decision.rationale}"
         )
 
-    def memory_quality(self, account_key: str) -> MemoryQualityResponse:
-        normalized_account_key = self._normalize_account_key(account_key)
-        now = datetime.now(UTC)
-        records = self._engine.storage.list_recent_memories(
-            account_key=normalized_account_key,
+        if context.verdict is not None and context.verdict.action == "flag":
+            self._queue_moderation_review(
+                context.screened_request(),
+                context.verdict,
+                account_key=context.account_key,
+                memory_id=memory_id if stored is not None else None,
+            )
+
+        return IngestResponse(
+            memory_id=memory_id,
+            stored=decision.store,
+            importance_score=float(max(0.0, min(1.0, decision.confidence))),
+            decision_reason=decision_reason,
+            encoded_at=processed.timestamp,
+            latency_ms=latency_ms,
         )
-        records_7d = [
-            record
-            for record in records
-            if (now - record.created_at).total_seconds() <= (7 * 86400)
-        ]
-        records_30d = [
-            record
-            for record in records
-            if (now - record.created_at).total_seconds() <= (30 * 86400)
-        ]
-        daily_bins_30d, series_30d = self._memory_quality_series_30d(records, now=now)
-        return MemoryQualityResponse(
-            generated_at=now,
-            window_7d=self._metadata_summary_from_records(records_7d, now=now),
-            window_30d=self._metadata_summary_from_records(records_30d, now=now),
-            daily_bins_30d=daily_bins_30d,
-            series_30d=series_30d,
+
+    def memory_attachment(
+        self,
+        memory_id: str,
+        *,
+        account_key: str | None = None,
+        max_sensitivity: str | None = None,
+        agent_id: str | None = None,
+    ) -> tuple[bytes, str, str | None]:
+        records = self._visible_to_agent(
+            self._within_clearance(
+                self._engine.storage.fetch_by_ids(
+                    [memory_id],
+                    account_key=self._normalize_account_key(account_key),
+                ),
+                max_sensitivity,
+            ),
+            agent_id,
         )
+        if not records:
+            msg = f"memory not found: {memory_id}"
+            raise KeyError(msg)
+        attachment = self._attachment_metadata(records[0])
+        # A key outside the caller's own segment is never served, whatever the memory claims.
+        if attachment is None or not blob_key_belongs_to(
+            str(attachment["key"]), self._normalize_account_key(account_key)
+        ):
+            msg = f"memory has no attachment: {memory_id}"
+            raise KeyError(msg)
+        data = self._blob_store.get(str(attachment["key"]))
+        return data, str(attachment["content_type"]), attachment.get("filename")
 
-    def _memory_quality_series_30d(
+    def _store_attachment(
         self,
-        records: list[MemoryRecord],
+        request: IngestRequest,
         *,
-        now: datetime,
-    ) -> tuple[list[str], dict[str, list[float]]]:
-        bins: list[datetime] = []
-        start_day = now.date() - timedelta(days=29)
-        for offset in range(30):
-            day = datetime.combine(
-                start_day + timedelta(days=offset),
-                datetime.min.time(),
-                tzinfo=UTC,
+        account_key: str,
+    ) -> list[str]:
+        attachment = request.attachment
+        if attachment is None:
+            return []
+        data = attachment.decoded()
+        if len(data) > self._config.max_attachment_bytes:
+            msg = (
+                "attachment exceeds max size "
+                f"({self._config.max_attachment_bytes} bytes)"
             )
-            bins.append(day)
-        labels = [day.date().isoformat() for day in bins]
+            raise ValueError(msg)
+        key = blob_key(account_key, uuid4().hex, attachment.filename)
+        stored = self._blob_store.put(key, data, content_type=attachment.content_type)
+        relationships = [
+            f"blob_key:{stored.key}",
+            f"blob_content_type:{stored.content_type}",
+            f"blob_size_bytes:{stored.size_bytes}",
+            f"blob_sha256:{stored.sha256}",
+        ]
+        if attachment.filename:
+            relationships.append(f"blob_filename:{attachment.filename.strip()}")
+        return relationships
 
-        summary_rolling: list[MetadataSummary] = []
-        for day in bins:
-            window_records = [
-                record
-                for record in records
-                if record.created_at.date() <= day.date()
-                and (day.date() - record.created_at.date()).days <= 30
-            ]
-            summary_rolling.append(
-                self._metadata_summary_from_records(window_records, now=day)
+    @classmethod
+    def _attachment_metadata(cls, record: MemoryRecord) -> dict[str, Any] | None:
+        key = cls._relationship_value(record.relationships, "blob_key:")
+        if key is None:
+            return None
+        size_raw = cls._relationship_value(record.relationships, "blob_size_bytes:")
+        return {
+            "key": key,
+            "content_type": cls._relationship_value(
+                record.relationships, "blob_content_type:"
             )
-
-        return labels, {
-            "inferred_facts": [
-                float(item.total_inferred_facts) for item in summary_rolling
-            ],
-            "contested_ratio": [float(item.contested_ratio) for item in summary_rolling],
-            "fact_conflicts": [float(item.fact_conflict_count) for item in summary_rolling],
-            "superseded_refs": [
-                float(item.superseded_fact_references) for item in summary_rolling
-            ],
+            or "application/octet-stream",
+            "size_bytes": int(size_raw) if size_raw and size_raw.isdigit() else None,
+            "sha256": cls._relationship_value(record.relationships, "blob_sha256:"),
+            "filename": cls._relationship_value(record.relationships, "blob_filename:"),
         }
 
-    def _metadata_summary(self, account_key: str) -> MetadataSummary:
-        limit = max(1, self._config.metadata_summary_window)
-        records = self._engine.storage.list_recent_memories(
-            limit=limit,
-            account_key=account_key,
-        )
-        return self._metadata_summary_from_records(records, now=datetime.now(UTC))
-
-    def _metadata_summary_from_records(
+    def ingest_batch(
         self,
-        records: list[MemoryRecord],
+        events: list[IngestRequest],
         *,
-        now: datetime,
+        account_key: str | None = None,
+    ) -> list[IngestResponse]:
+        return self._run_pipeline(events, account_key=self._normalize_account_key(account_key))
+
+    def pipeline_webhook(self, account_key: str) -> PipelineWebhook:
+        normalized_account_key = self._normalize_account_key(account_key)
+        with self._state_session_factory() as session:
+            row = session.get(ApiPipelineWebhookRow, normalized_account_key)
+            if row is None:
+                msg = f"no pipeline webhook configured for account: {normalized_account_key}"
+                raise KeyError(msg)
+            return self._as_pipeline_webhook(row)
+
+    def set_pipeline_webhook(
+        self,
+        account_key: str,
+        request: PipelineWebhookRequest,
+    ) -> PipelineWebhook:
+        """Send this account's ingested events through its own transform service."""
+        normalized_account_key = self._normalize_account_key(account_key)
+        try:
+            require_public_host(request.url, policy=self._outbound_policy())
+        except UrlFetchError as exc:
+            msg = f"pipeline webhook URL is not allowed: {exc}"
+            raise ValueError(msg) from exc
+        with self._state_session_factory() as session:
+            row = session.get(ApiPipelineWebhookRow, normalized_account_key)
+            if row is None:
+                row = ApiPipelineWebhookRow(account_key=normalized_account_key)
+                session.add(row)
+            row.url = request.url
+            row.secret = request.secret
+            row.timeout_ms = request.timeout_ms
+            row.failure_policy = request.failure_policy
+            row.updated_at = datetime.now(UTC)
+            session.commit()
+            return self._as_pipeline_webhook(row)
+
+    def delete_pipeline_webhook(self, account_key: str) -> PipelineWebhook:
+        normalized_account_key = self._normalize_account_key(account_key)
+        with self._state_session_factory() as session:
+            row = session.get(ApiPipelineWebhookRow, normalized_account_key)
+            if row is None:
+                msg = f"no pipeline webhook configured for account: {normalized_account_key}"
+                raise KeyError(msg)
+            removed = self._as_pipeline_webhook(row)
+            session.delete(row)
+            session.commit()
+            return removed
+
+    @staticmethod
+    def _as_pipeline_webhook(row: ApiPipelineWebhookRow) -> PipelineWebhook:
+        return PipelineWebhook(
+            url=row.url,
+            has_secret=bool(row.secret),
+            timeout_ms=row.timeout_ms,
+            failure_policy=row.failure_policy,
+            updated_at=_as_utc(row.updated_at),
+        )
+
+    def list_namespaces(self) -> NamespaceListResponse:
+        with self._state_session_factory() as session:
+            rows = session.scalars(
+                select(ApiNamespaceRow).order_by(ApiNamespaceRow.account_key.asc())
+            ).all()
+            return NamespaceListResponse(data=[self._as_namespace(row) for row in rows])
+
+    def namespace(self, account_key: str) -> Namespace:
+        normalized_account_key = self._normalize_account_key(account_key)
+        with self._state_session_factory() as session:
+            row = session.get(ApiNamespaceRow, normalized_account_key)
+            if row is None:
+                msg = f"namespace not found: {normalized_account_key}"
+                raise KeyError(msg)
+            return self._as_namespace(row)
+
+    def set_namespace(self, account_key: str, request: NamespaceRequest) -> Namespace:
+        """Create or replace the namespace's settings; memories and keys are untouched."""
+        normalized_account_key = self._normalize_account_key(account_key)
+        pipeline = None
+        if request.pipeline is not None:
+            pipeline = validate_pipeline(request.pipeline)
+            unregistered = [stage for stage in pipeline if not self._pipeline.has_stage(stage)]
+            if unregistered:
+                msg = f"pipeline stage(s) not registered: {', '.join(unregistered)}"
+                raise ValueError(msg)
+        now = clock.now()
+        with self._state_session_factory() as session:
+            row = session.get(ApiNamespaceRow, normalized_account_key)
+            if row is None:
+                row = ApiNamespaceRow(account_key=normalized_account_key, created_at=now)
+                session.add(row)
+            row.display_name = request.display_name
+            row.description = request.description
+            row.pipeline_json = json.dumps(pipeline) if pipeline is not None else None
+            row.retrieve_defaults_json = (
+                request.retrieve_defaults.model_dump_json(exclude_none=True)
+                if request.retrieve_defaults is not None
+                else None
+            )
+            row.expires_at = (
+                now + timedelta(seconds=request.ttl_seconds)
+                if request.ttl_seconds is not None
+                else None
+            )
+            row.updated_at = now
+            session.commit()
+            return self._as_namespace(row)
+
+    def delete_namespace(self, account_key: str) -> Namespace:
+        normalized_account_key = self._normalize_account_key(account_key)
+        with self._state_session_factory() as session:
+            row = session.get(ApiNamespaceRow, normalized_account_key)
+            if row is None:
+                msg = f"namespace not found: {normalized_account_key}"
+                raise KeyError(msg)
+            removed = self._as_namespace(row)
+            session.delete(row)
+            session.commit()
+            return removed
+
+    @staticmethod
+    def _as_namespace(row: ApiNamespaceRow) -> Namespace:
+        return Namespace(
+            account_key=row.account_key,
+            display_name=row.display_name,
+            description=row.description,
+            pipeline=json.loads(row.pipeline_json) if row.pipeline_json else None,
+            retrieve_defaults=(
+                RetrieveDefaults.model_validate_json(row.retrieve_defaults_json)
+                if row.retrieve_defaults_json
+                else None
+            ),
+            expires_at=_as_utc(row.expires_at) if row.expires_at is not None else None,
+            created_at=_as_utc(row.created_at),
+            updated_at=_as_utc(row.updated_at),
+        )
+
+    def expire_namespaces(self, *, now: datetime | None = None) -> dict[str, int]:
+        """Wipe sandbox namespaces past their TTL; returns memories deleted per namespace.
+
+        A wiped namespace loses its memories, API keys, usage counters, and the namespace
+        itself, so the account key can be reused for the next run.
+        """
+        current = now or clock.now()
+        with self._state_session_factory() as session:
+            expired = list(
+                session.scalars(
+                    select(ApiNamespaceRow.account_key).where(
+                        ApiNamespaceRow.expires_at.is_not(None),
+                        ApiNamespaceRow.expires_at <= current,
+                    )
+                ).all()
+            )
+        deleted: dict[str, int] = {}
+        for account_key in expired:
+            memory_ids = [
+                record.memory_id
+                for record in self._engine.storage.list_memories(account_key=account_key)
+            ]
+            removed = (
+                self._engine.delete_memories(memory_ids, account_key=account_key)
+                if memory_ids
+                else []
+            )
+            with self._state_session_factory() as session, session.begin():
+                for key in session.scalars(
+                    select(ApiKeyRow).where(
+                        ApiKeyRow.account_key == account_key,
+                        ApiKeyRow.status != "revoked",
+                    )
+                ).all():
+                    key.status = "revoked"
+                    key.revoked_at = current
+                    self._insert_audit_row(
+                        session=session,
+                        account_key=account_key,
+                        actor_subject="orbit-expire",
+                        actor_type="system",
+                        action="api_key_revoked",
+                        target_type="api_key",
+                        target_id=key.key_id,
+                        metadata={"reason": "sandbox_expired"},
+                    )
+                usage = session.get(ApiAccountUsageRow, account_key)
+                if usage is not None:
+                    session.delete(usage)
+                namespace = session.get(ApiNamespaceRow, account_key)
+                if namespace is not None:
+                    session.delete(namespace)
+            deleted[account_key] = len(removed)
+        return deleted
+
+    def _is_sandbox(self, session: Session, account_key: str) -> bool:
+        expires_at = session.scalar(
+            select(ApiNamespaceRow.expires_at).where(ApiNamespaceRow.account_key == account_key)
+        )
+        return expires_at is not None
+
+    def event_type_registry(self, account_key: str) -> EventTypeRegistry:
+        normalized_account_key = self._normalize_account_key(account_key)
+        with self._state_session_factory() as session:
+            row = session.get(ApiEventTypeRegistryRow, normalized_account_key)
+            if row is None:
+                msg = f"no event type registry for account: {normalized_account_key}"
+                raise KeyError(msg)
+            return self._as_event_type_registry(row)
+
+    def set_event_type_registry(
+        self,
+        account_key: str,
+        request: EventTypeRegistryRequest,
+    ) -> EventTypeRegistry:
+        """Declare the account's event types; when enforced, ingest rejects any other type."""
+        normalized_account_key = self._normalize_account_key(account_key)
+        with self._state_session_factory() as session:
+            row = session.get(ApiEventTypeRegistryRow, normalized_account_key)
+            if row is None:
+                row = ApiEventTypeRegistryRow(account_key=normalized_account_key)
+                session.add(row)
+            row.event_types_json = json.dumps(
+                [item.model_dump() for item in request.event_types],
+                ensure_ascii=True,
+            )
+            row.enforce = request.enforce
+            row.updated_at = datetime.now(UTC)
+            session.commit()
+            return self._as_event_type_registry(row)
+
+    def delete_event_type_registry(self, account_key: str) -> EventTypeRegistry:
+        normalized_account_key = self._normalize_account_key(account_key)
+        with self._state_session_factory() as session:
+            row = session.get(ApiEventTypeRegistryRow, normalized_account_key)
+            if row is None:
+                msg = f"no event type registry for account: {normalized_account_key}"
+                raise KeyError(msg)
+            removed = self._as_event_type_registry(row)
+            session.delete(row)
+            session.commit()
+            return removed
+
+    def _check_event_types(self, events: list[IngestRequest], *, account_key: str) -> None:
+        with self._state_session_factory() as session:
+            row = session.get(ApiEventTypeRegistryRow, account_key)
+            if row is None or not row.enforce:
+                return
+            allowed = {item["name"] for item in json.loads(row.event_types_json)}
+        for item in events:
+            event_type = item.event_type or self._config.default_event_type
+            if event_type not in allowed:
+                msg = f"event type {event_type!r} is not registered for this namespace"
+                raise ValueError(msg)
+
+    @staticmethod
+    def _as_event_type_registry(row: ApiEventTypeRegistryRow) -> EventTypeRegistry:
+        return EventTypeRegistry(
+            account_key=row.account_key,
+            event_types=[
+                EventTypeDefinition.model_validate(item)
+                for item in json.loads(row.event_types_json)
+            ],
+            enforce=row.enforce,
+            updated_at=_as_utc(row.updated_at),
+        )
+
+    def write_policy(self, account_key: str) -> WritePolicy:
+        normalized_account_key = self._normalize_account_key(account_key)
+        policy = self._write_policy_for(normalized_account_key)
+        if policy is None:
+            msg = f"no write policy for account: {normalized_account_key}"
+            raise KeyError(msg)
+        return policy
+
+    def set_write_policy(self, account_key: str, request: WritePolicyRequest) -> WritePolicy:
+        """Limit what agents may store in the namespace; ingest rejects anything else."""
+        known = {*self._config.memory_categories, UNCATEGORIZED}
+        unknown = [
+            category
+            for category in dict.fromkeys([*request.banned_categories, *request.review_categories])
+            if category not in known
+        ]
+        if unknown:
+            msg = f"unknown memory category: {', '.join(unknown)}"
+            raise ValueError(msg)
+        normalized_account_key = self._normalize_account_key(account_key)
+        with self._state_session_factory() as session:
+            row = session.get(ApiWritePolicyRow, normalized_account_key)
+            if row is None:
+                row = ApiWritePolicyRow(account_key=normalized_account_key)
+                session.add(row)
+            row.allowed_event_types_json = (
+                json.dumps(request.allowed_event_types, ensure_ascii=True)
+                if request.allowed_event_types is not None
+                else None
+            )
+            row.max_importance = request.max_importance
+            row.banned_categories_json = json.dumps(request.banned_categories, ensure_ascii=True)
+            row.review_categories_json = json.dumps(request.review_categories, ensure_ascii=True)
+            row.updated_at = datetime.now(UTC)
+            session.commit()
+            return self._as_write_policy(row)
+
+    def delete_write_policy(self, account_key: str) -> WritePolicy:
+        normalized_account_key = self._normalize_account_key(account_key)
+        with self._state_session_factory() as session:
+            row = session.get(ApiWritePolicyRow, normalized_account_key)
+            if row is None:
+                msg = f"no write policy for account: {normalized_account_key}"
+                raise KeyError(msg)
+            removed = self._as_write_policy(row)
+            session.delete(row)
+            session.commit()
+            return removed
+
+    def _write_policy_for(self, account_key: str) -> WritePolicy | None:
+        with self._state_session_factory() as session:
+            row = session.get(ApiWritePolicyRow, account_key)
+            return self._as_write_policy(row) if row is not None else None
+
+    def _check_write_policy(self, events: list[IngestRequest], *, account_key: str) -> None:
+        policy = self._write_policy_for(account_key)
+        if policy is None:
+            return
+        for item in events:
+            self._enforce_write_policy(
+                policy,
+                event_type=item.event_type or self._config.default_event_type,
+                importance=(item.metadata or {}).get("importance"),
+                content=item.content,
+            )
+
+    def _enforce_write_policy(
+        self,
+        policy: WritePolicy,
+        *,
+        event_type: str,
+        importance: Any,
+        content: str,
+    ) -> None:
+        if policy.allowed_event_types is not None and event_type not in policy.allowed_event_types:
+            raise WritePolicyViolationError(
+                rule="allowed_event_types",
+                detail=f"event type {event_type!r} is not allowed in this namespace",
+            )
+        if policy.max_importance is not None and importance is not None:
+            if isinstance(importance, bool) or not isinstance(importance, int | float):
+                raise WritePolicyViolationError(
+                    rule="max_importance",
+                    detail="importance must be a number",
+                )
+            if importance > policy.max_importance:
+                raise WritePolicyViolationError(
+                    rule="max_importance",
+                    detail=f"importance {importance:g} exceeds {policy.max_importance:g}",
+                )
+        if policy.banned_categories:
+            category = categorize(content, self._config.memory_categories)
+            if category in policy.banned_categories:
+                raise WritePolicyViolationError(
+                    rule="banned_categories",
+                    detail=f"{category} memories may not be stored in this namespace",
+                )
+
+    @staticmethod
+    def _as_write_policy(row: ApiWritePolicyRow) -> WritePolicy:
+        return WritePolicy(
+            account_key=row.account_key,
+            allowed_event_types=(
+                json.loads(row.allowed_event_types_json)
+                if row.allowed_event_types_json is not None
+                else None
+            ),
+            max_importance=row.max_importance,
+            banned_categories=json.loads(row.banned_categories_json),
+            review_categories=json.loads(row.review_categories_json or "[]"),
+            updated_at=_as_utc(row.updated_at),
+        )
+
+    def _hold_for_review(
+        self,
+        events: list[IngestRequest],
+        *,
+        account_key: str,
+        exclude: set[int],
+    ) -> dict[int, IngestResponse]:
+        """Queue events in the policy's review categories instead of storing them."""
+        policy = self._write_policy_for(account_key)
+        if policy is None or not policy.review_categories:
+            return {}
+        held: dict[int, IngestResponse] = {}
+        now = datetime.now(UTC)
+        with self._state_session_factory() as session:
+            for index, item in enumerate(events):
+                if index in exclude:
+                    continue
+                category = categorize(item.content, self._config.memory_categories)
+                if category not in policy.review_categories:
+                    continue
+                if (
+                    item.attachment is not None
+                    and len(item.attachment.decoded()) > self._config.max_attachment_bytes
+                ):
+                    # Refused now rather than held for an approval that could never store it.
+                    msg = f"attachment exceeds max size ({self._config.max_attachment_bytes} bytes)"
+                    raise ValueError(msg)
+                review_id = f"rev_{uuid4().hex[:16]}"
+                session.add(
+                    ApiMemoryReviewRow(
+                        id=review_id,
+                        account_key=account_key,
+                        entity_id=item.entity_id,
+                        agent_id=item.agent_id,
+                        category=category,
+                        content=item.content,
+                        # The attachment is held with the request and stored on approval.
+                        request_json=item.model_dump_json(),
+                        status="pending",
+                        created_at=now,
+                        updated_at=now,
+                    )
+                )
+                # Nothing is stored yet: the review id stands in until approval assigns one.
+                held[index] = IngestResponse(
+                    memory_id=review_id,
+                    stored=False,
+                    importance_score=0.0,
+                    decision_reason=f"Held for review {review_id} ({category})",
+                    encoded_at=now,
+                    latency_ms=0.0,
+                    review_id=review_id,
+                )
+            session.commit()
+        return held
+
+    def memory_reviews(
+        self,
+        *,
+        account_key: str,
+        status: str | None = None,
+        limit: int = 50,
+    ) -> MemoryReviewListResponse:
+        """Writes held for approval, newest first."""
+        query = select(ApiMemoryReviewRow).where(
+            ApiMemoryReviewRow.account_key == self._normalize_account_key(account_key)
+        )
+        if status:
+            query = query.where(ApiMemoryReviewRow.status == status)
+        with self._state_session_factory() as session:
+            rows = session.scalars(
+                query.order_by(ApiMemoryReviewRow.created_at.desc()).limit(limit)
+            ).all()
+            return MemoryReviewListResponse(data=[self._as_memory_review(row) for row in rows])
+
+    def resolve_memory_review(
+        self,
+        review_id: str,
+        decision: str,
+        request: MemoryReviewDecision,
+        *,
+        account_key: str,
+        resolved_by: str,
+    ) -> MemoryReview:
+        """Approving a held write runs it through the pipeline; rejecting discards it.
+
+        The review is claimed with a conditional update first, so of two concurrent decisions
+        only one runs and the other sees the review as already decided.
+        """
+        normalized_account_key = self._normalize_account_key(account_key)
+        claim_status = "approving" if decision == "approve" else "rejecting"
+        with self._state_session_factory() as session:
+            row = self._memory_review_row(session, review_id, account_key=normalized_account_key)
+            request_json = row.request_json
+            claimed = session.execute(
+                update(ApiMemoryReviewRow)
+                .where(ApiMemoryReviewRow.id == review_id)
+                .where(ApiMemoryReviewRow.status == "pending")
+                .values(status=claim_status, updated_at=datetime.now(UTC))
+            ).rowcount
+            session.commit()
+            if claimed != 1:
+                session.refresh(row)
+                msg = f"memory review {review_id} is already {row.status}"
+                raise ValueError(msg)
+        memory_id = None
+        if decision == "approve":
+            try:
+                stored = self._run_pipeline(
+                    [IngestRequest.model_validate_json(request_json)],
+                    account_key=normalized_account_key,
+                    skip=frozenset({"review"}),
+                )[0]
+            except Exception:
+                # Nothing was stored; hand the review back so it can be decided again.
+                with self._state_session_factory() as session:
+                    session.execute(
+                        update(ApiMemoryReviewRow)
+                        .where(ApiMemoryReviewRow.id == review_id)
+                        .where(ApiMemoryReviewRow.status == claim_status)
+                        .values(status="pending", updated_at=datetime.now(UTC))
+                    )
+                    session.commit()
+                raise
+            memory_id = stored.memory_id if stored.stored else None
+        with self._state_session_factory() as session:
+            row = self._memory_review_row(session, review_id, account_key=normalized_account_key)
+            row.status = "approved" if decision == "approve" else "rejected"
+            row.memory_id = memory_id
+            row.note = request.note
+            row.resolved_by = resolved_by
+            row.updated_at = datetime.now(UTC)
+            session.commit()
+            return self._as_memory_review(row)
+
+    @staticmethod
+    def _memory_review_row(
+        session: Session,
+        review_id: str,
+        *,
+        account_key: str,
+    ) -> ApiMemoryReviewRow:
+        row = session.get(ApiMemoryReviewRow, review_id)
+        if row is None or row.account_key != account_key:
+            msg = f"memory review not found: {review_id}"
+            raise KeyError(msg)
+        return row
+
+    @staticmethod
+    def _as_memory_review(row: ApiMemoryReviewRow) -> MemoryReview:
+        return MemoryReview(
+            review_id=row.id,
+            entity_id=row.entity_id,
+            agent_id=row.agent_id,
+            category=row.category,
+            content=row.content,
+            status=row.status,
+            memory_id=row.memory_id,
+            note=row.note,
+            resolved_by=row.resolved_by,
+            created_at=_as_utc(row.created_at),
+            updated_at=_as_utc(row.updated_at),
+        )
+
+    def retention_policy(self, account_key: str) -> RetentionPolicy:
+        normalized_account_key = self._normalize_account_key(account_key)
+        with self._state_session_factory() as session:
+            row = session.get(ApiRetentionPolicyRow, normalized_account_key)
+            if row is None:
+                msg = f"no retention policy for account: {normalized_account_key}"
+                raise KeyError(msg)
+            return self._as_retention_policy(row)
+
+    def set_retention_policy(
+        self,
+        account_key: str,
+        request: RetentionPolicyRequest,
+    ) -> RetentionPolicy:
+        """Delete the account's memories once they are older than the policy allows."""
+        normalized_account_key = self._normalize_account_key(account_key)
+        with self._state_session_factory() as session:
+            row = session.get(ApiRetentionPolicyRow, normalized_account_key)
+            if row is None:
+                row = ApiRetentionPolicyRow(account_key=normalized_account_key)
+                session.add(row)
+            row.days = request.days
+            row.event_types_json = json.dumps(request.event_types, ensure_ascii=True)
+            row.updated_at = datetime.now(UTC)
+            session.commit()
+            return self._as_retention_policy(row)
+
+    def delete_retention_policy(self, account_key: str) -> RetentionPolicy:
+        normalized_account_key = self._normalize_account_key(account_key)
+        with self._state_session_factory() as session:
+            row = session.get(ApiRetentionPolicyRow, normalized_account_key)
+            if row is None:
+                msg = f"no retention policy for account: {normalized_account_key}"
+                raise KeyError(msg)
+            removed = self._as_retention_policy(row)
+            session.delete(row)
+            session.commit()
+            return removed
+
+    def apply_retention_policies(self, *, now: datetime | None = None) -> dict[str, int]:
+        """Delete memories past their account's retention; returns deletions per account."""
+        current = now or clock.now()
+        with self._state_session_factory() as session:
+            policies = [
+                self._as_retention_policy(row)
+                for row in session.scalars(select(ApiRetentionPolicyRow)).all()
+            ]
+        deleted: dict[str, int] = {}
+        for policy in policies:
+            expired = [
+                record.memory_id
+                for record in self._engine.storage.list_memories(account_key=policy.account_key)
+                if _as_utc(record.created_at)
+                < current - timedelta(days=policy.event_types.get(record.intent, policy.days))
+            ]
+            if expired:
+                removed = self._engine.delete_memories(expired, account_key=policy.account_key)
+                deleted[policy.account_key] = len(removed)
+        return deleted
+
+    @staticmethod
+    def _as_retention_policy(row: ApiRetentionPolicyRow) -> RetentionPolicy:
+        return RetentionPolicy(
+            account_key=row.account_key,
+            days=row.days,
+            event_types=json.loads(row.event_types_json),
+            updated_at=_as_utc(row.updated_at),
+        )
+
+    def resurface(
+        self,
+        *,
+        entity_id: str | None = None,
+        limit: int = 10,
+        max_sensitivity: str | None = None,
+        account_key: str | None = None,
+        now: datetime | None = None,
+    ) -> ResurfaceResponse:
+        """Important memories nobody has retrieved lately, most important first."""
+        return ResurfaceResponse(
+            data=self._resurface_candidates(
+                account_key=self._normalize_account_key(account_key),
+                entity_id=self._normalize_entity_id(entity_id) if entity_id else None,
+                max_sensitivity=max_sensitivity,
+                now=now or clock.now(),
+            )[:limit]
+        )
+
+    def run_resurfacing(self, *, now: datetime | None = None) -> dict[str, int]:
+        """Send forgotten memories to the resurface webhook; returns deliveries per account."""
+        webhook_url = self._config.resurface_webhook_url
+        if not webhook_url:
+            return {}
+        current = now or clock.now()
+        account_keys = sorted(
+            {
+                self._normalize_account_key(record.account_key)
+                for record in self._engine.storage.list_memories()
+            }
+        )
+        delivered: dict[str, int] = {}
+        for account_key in account_keys:
+            items = self._resurface_candidates(
+                account_key=account_key,
+                entity_id=None,
+                max_sensitivity=None,
+                now=current,
+            )[: self._config.resurface_limit]
+            sent = [
+                item.memory.memory_id
+                for item in items
+                if _post_webhook(
+                    webhook_url,
+                    "memory_resurfaced",
+                    {"account_key": account_key, **item.model_dump(mode="json")},
+                    secret=self._config.resurface_webhook_secret,
+                )
+            ]
+            if not sent:
+                continue
+            with self._state_session_factory() as session, session.begin():
+                rows = {
+                    row.memory_id: row
+                    for row in session.scalars(
+                        select(ApiResurfacedMemoryRow)
+                        .where(ApiResurfacedMemoryRow.account_key == account_key)
+                        .where(ApiResurfacedMemoryRow.memory_id.in_(sent))
+                    )
+                }
+                for memory_id in sent:
+                    row = rows.get(memory_id)
+                    if row is None:
+                        row = ApiResurfacedMemoryRow(account_key=account_key, memory_id=memory_id)
+                        session.add(row)
+                    row.surfaced_at = current
+            delivered[account_key] = len(sent)
+        return delivered
+
+    def _resurface_candidates(
+        self,
+        *,
+        account_key: str,
+        entity_id: str | None,
+        max_sensitivity: str | None,
+        now: datetime,
+    ) -> list[ResurfacedMemory]:
+        # Stated by the user, important, idle for ORBIT_RESURFACE_AFTER_DAYS and not already
+        # delivered by the job within that window.
+        cutoff = now - timedelta(days=self._config.resurface_after_days)
+        with self._state_session_factory() as session:
+            recently_surfaced = set(
+                session.scalars(
+                    select(ApiResurfacedMemoryRow.memory_id)
+                    .where(ApiResurfacedMemoryRow.account_key == account_key)
+                    .where(ApiResurfacedMemoryRow.surfaced_at >= cutoff)
+                )
+            )
+        candidates: list[tuple[MemoryRecord, datetime]] = []
+        for record in self._within_clearance(
+            self._engine.storage.list_memories(account_key=account_key),
+            max_sensitivity,
+        ):
+            last_accessed_at = _as_utc(record.last_retrieved_at or record.created_at)
+            if (
+                last_accessed_at > cutoff
+                or record.latest_importance < self._config.resurface_min_importance
+                or record.memory_id in recently_surfaced
+                or record.intent.startswith("assistant_")
+                or "inferred:true" in record.relationships
+                or (entity_id is not None and entity_id not in record.entities)
+            ):
+                continue
+            candidates.append((record, last_accessed_at))
+        candidates.sort(key=lambda item: (-item[0].latest_importance, item[1]))
+        return [
+            ResurfacedMemory(
+                memory=self._as_memory(record, position, record.latest_importance),
+                last_accessed_at=last_accessed_at,
+                idle_days=(now - last_accessed_at).days,
+                prompt=f"{_time_ago(_as_utc(record.event_time), now)} you mentioned: "
+                f"{record.content}",
+            )
+            for position, (record, last_accessed_at) in enumerate(candidates, start=1)
+        ]
+
+    def create_digest(
+        self,
+        request: DigestRequest,
+        *,
+        account_key: str | None = None,
+        max_sensitivity: str | None = None,
+        now: datetime | None = None,
+    ) -> Digest:
+        """Summarize the memories stored in the request's period and keep the digest."""
+        webhook_url = self._config.digest_webhook_url
+        if request.deliver and not webhook_url:
+            msg = "deliver requires ORBIT_DIGEST_WEBHOOK_URL"
+            raise ValueError(msg)
+        normalized_account_key = self._normalize_account_key(account_key)
+        entity_id = self._normalize_entity_id(request.entity_id) if request.entity_id else None
+        window_end = now or clock.now()
+        window_start = window_end - timedelta(days=DIGEST_PERIODS[request.period])
+        records = [
+            record
+            for record in self._within_clearance(
+                self._engine.storage.list_memories(account_key=normalized_account_key),
+                max_sensitivity,
+            )
+            if window_start <= _as_utc(record.created_at) <= window_end
+            and (entity_id is None or entity_id in record.entities)
+        ]
+        records.sort(key=lambda record: (-record.latest_importance, _as_utc(record.created_at)))
+        categories = Counter(
+            self._relationship_value(record.relationships, "category:") or UNCATEGORIZED
+            for record in records
+        )
+        covered = records[:_DIGEST_MAX_MEMORIES]
+        target = self._summary_target()
+        if target is not None and covered:
+            summary = call_summarizer(
+                target,
+                digest_messages(
+                    request.period,
+                    [
+                        self._as_memory(record, position, record.latest_importance)
+                        for position, record in enumerate(covered, start=1)
+                    ],
+                ),
+            )
+        else:
+            summary = _digest_text(request.period, len(records), categories, covered)
+        digest = Digest(
+            digest_id=f"dig_{uuid4().hex[:16]}",
+            period=request.period,
+            entity_id=entity_id,
+            window_start=window_start,
+            window_end=window_end,
+            memory_count=len(records),
+            categories=dict(categories.most_common()),
+            summary=summary,
+            memory_ids=[record.memory_id for record in covered],
+            model=target.model if target is not None and covered else None,
+            created_at=window_end,
+        )
+        if request.deliver and webhook_url:
+            delivered = _post_webhook(
+                webhook_url,
+                "digest_created",
+                {"account_key": normalized_account_key, **digest.model_dump(mode="json")},
+                secret=self._config.digest_webhook_secret,
+            )
+            digest = digest.model_copy(update={"delivered": delivered})
+        with self._state_session_factory() as session, session.begin():
+            session.add(
+                ApiDigestRow(
+                    id=digest.digest_id,
+                    account_key=normalized_account_key,
+                    entity_id=digest.entity_id,
+                    period=digest.period,
+                    window_start=digest.window_start,
+                    window_end=digest.window_end,
+                    memory_count=digest.memory_count,
+                    categories_json=json.dumps(digest.categories),
+                    summary=digest.summary,
+                    memory_ids_json=json.dumps(digest.memory_ids),
+                    model=digest.model,
+                    delivered=digest.delivered,
+                    created_at=digest.created_at,
+                )
+            )
+        return digest
+
+    def digest(self, digest_id: str, *, account_key: str | None = None) -> Digest:
+        with self._state_session_factory() as session:
+            row = session.get(ApiDigestRow, digest_id)
+        if row is None or row.account_key != self._normalize_account_key(account_key):
+            msg = f"digest not found: {digest_id}"
+            raise KeyError(msg)
+        return Digest(
+            digest_id=row.id,
+            period=row.period,
+            entity_id=row.entity_id,
+            window_start=_as_utc(row.window_start),
+            window_end=_as_utc(row.window_end),
+            memory_count=row.memory_count,
+            categories=json.loads(row.categories_json),
+            summary=row.summary,
+            memory_ids=json.loads(row.memory_ids_json),
+            model=row.model,
+            delivered=row.delivered,
+            created_at=_as_utc(row.created_at),
+        )
+
+    def _moderate(self, request: IngestRequest) -> ModerationVerdict | None:
+        if self._moderation_provider is None:
+            return None
+        verdict = moderate(
+            self._moderation_provider,
+            request.content,
+            policy=self._config.moderation_policy,
+        )
+        return verdict if verdict.action != "allow" else None
+
+    def _raise_blocked(
+        self,
+        blocked: list[tuple[IngestRequest, ModerationVerdict]],
+        *,
+        account_key: str,
+    ) -> None:
+        review_ids = [
+            self._queue_moderation_review(request, verdict, account_key=account_key).id
+            for request, verdict in blocked
+        ]
+        categories = sorted({item for _, verdict in blocked for item in verdict.categories})
+        raise ContentBlockedError(review_ids=review_ids, categories=categories)
+
+    def _queue_moderation_review(
+        self,
+        request: IngestRequest,
+        verdict: ModerationVerdict,
+        *,
+        account_key: str,
+        memory_id: str | None = None,
+    ) -> ModerationReview:
+        now = datetime.now(UTC)
+        withheld = verdict.withheld
+        with self._state_session_factory() as session:
+            row = ApiModerationReviewRow(
+                account_key=account_key,
+                entity_id=request.entity_id,
+                action=verdict.action,
+                categories_json=json.dumps(list(verdict.categories)),
+                provider=verdict.provider,
+                status="pending",
+                # Withheld content is never persisted, not even for review; only its hash is.
+                content="" if withheld else request.content,
+                content_sha256=(
+                    hashlib.sha256(request.content.encode("utf-8")).hexdigest()
+                    if withheld
+                    else None
+                ),
+                # Attachments are not kept for blocked events; an override stores the text only.
+                request_json=(
+                    "{}" if withheld else request.model_dump_json(exclude={"attachment"})
+                ),
+                memory_id=memory_id,
+                created_at=now,
+                updated_at=now,
+            )
+            session.add(row)
+            session.commit()
+            review = self._as_moderation_review(row)
+        if withheld:
+            self._report_withheld_content(review)
+        return review
+
+    def _report_withheld_content(self, review: ModerationReview) -> None:
+        with self._state_lock:
+            self._metrics["moderation_withheld_total"] += 1
+        self._log.error(
+            "moderation_content_withheld",
+            account=review.account_key,
+            review_id=review.id,
+            categories=review.categories,
+            content_sha256=review.content_sha256,
+        )
+        webhook_url = self._config.moderation_report_webhook_url
+        if webhook_url:
+            submit_with_context(
+                self._webhook_executor,
+                _post_webhook,
+                webhook_url,
+                "moderation_report",
+                {
+                    "account_key": review.account_key,
+                    "review_id": review.id,
+                    "entity_id": review.entity_id,
+                    "categories": review.categories,
+                    "provider": review.provider,
+                    "content_sha256": review.content_sha256,
+                    "occurred_at": review.created_at.isoformat(),
+                },
+                secret=self._config.moderation_report_webhook_secret,
+            )
+
+    def moderation_reviews(
+        self,
+        *,
+        account_key: str | None = None,
+        status: str | None = None,
+        limit: int = 50,
+    ) -> ModerationReviewListResponse:
+        """Review queue, newest first; ``account_key=None`` lists every account (operators)."""
+        query = select(ApiModerationReviewRow)
+        if account_key is not None:
+            query = query.where(
+                ApiModerationReviewRow.account_key == self._normalize_account_key(account_key)
+            )
+        if status:
+            query = query.where(ApiModerationReviewRow.status == status)
+        with self._state_session_factory() as session:
+            rows = session.scalars(
+                query.order_by(ApiModerationReviewRow.id.desc()).limit(limit)
+            ).all()
+            return ModerationReviewListResponse(
+                data=[self._as_moderation_review(row) for row in rows]
+            )
+
+    def appeal_moderation_review(
+        self,
+        review_id: int,
+        request: ModerationAppealRequest,
+        *,
+        account_key: str | None = None,
+    ) -> ModerationReview:
+        with self._state_session_factory() as session:
+            row = self._moderation_review_row(
+                session,
+                review_id,
+                account_key=self._normalize_account_key(account_key),
+            )
+            if row.status != "pending":
+                msg = f"moderation review {review_id} is {row.status} and cannot be appealed"
+                raise ValueError(msg)
+            row.status = "appealed"
+            row.appeal_reason = request.reason.strip()
+            row.updated_at = datetime.now(UTC)
+            session.commit()
+            return self._as_moderation_review(row)
+
+    def resolve_moderation_review(
+        self,
+        review_id: int,
+        request: ModerationResolveRequest,
+        *,
+        resolved_by: str,
+    ) -> ModerationReview:
+        """Operator decision: approving a block stores the content, rejecting a flag deletes it."""
+        with self._state_session_factory() as session:
+            row = self._moderation_review_row(session, review_id, account_key=None)
+            review = self._as_moderation_review(row)
+            request_json = row.request_json
+        if review.status not in {"pending", "appealed"}:
+            msg = f"moderation review {review_id} is already {review.status}"
+            raise ValueError(msg)
+        memory_id = review.memory_id
+        if request.decision == "approve" and review.content_sha256 is not None:
+            msg = f"moderation review {review_id} content was withheld and cannot be approved"
+            raise ValueError(msg)
+        if request.decision == "approve" and review.action == "block":
+            stored = self._run_pipeline(
+                [IngestRequest.model_validate_json(request_json)],
+                account_key=review.account_key,
+                skip=frozenset({"moderation"}),
+            )[0]
+            memory_id = stored.memory_id if stored.stored else None
+        if request.decision == "reject" and memory_id:
+            self._engine.delete_memories([memory_id], account_key=review.account_key)
+        with self._state_session_factory() as session:
+            row = self._moderation_review_row(session, review_id, account_key=None)
+            row.memory_id = memory_id
+            row.status = "approved" if request.decision == "approve" else "rejected"
+            row.resolution_note = request.note
+            row.resolved_by = resolved_by
+            row.updated_at = datetime.now(UTC)
+            session.commit()
+            return self._as_moderation_review(row)
+
+    @staticmethod
+    def _moderation_review_row(
+        session: Session,
+        review_id: int,
+        *,
+        account_key: str | None,
+    ) -> ApiModerationReviewRow:
+        row = session.get(ApiModerationReviewRow, review_id)
+        if row is None or (account_key is not None and row.account_key != account_key):
+            msg = f"moderation review not found: {review_id}"
+            raise KeyError(msg)
+        return row
+
+    @staticmethod
+    def _as_moderation_review(row: ApiModerationReviewRow) -> ModerationReview:
+        return ModerationReview(
+            id=row.id,
+            account_key=row.account_key,
+            entity_id=row.entity_id,
+            action=row.action,
+            categories=json.loads(row.categories_json),
+            provider=row.provider,
+            status=row.status,
+            content=row.content,
+            content_sha256=row.content_sha256,
+            memory_id=row.memory_id,
+            appeal_reason=row.appeal_reason,
+            resolution_note=row.resolution_note,
+            created_at=_as_utc(row.created_at),
+            updated_at=_as_utc(row.updated_at),
+        )
+
+    def retrieve(
+        self,
+        request: RetrieveRequest,
+        *,
+        account_key: str | None = None,
+        max_sensitivity: str | None = None,
+    ) -> RetrieveResponse:
+        """Rank memories for ``request``; ``max_sensitivity`` hides more restricted labels."""
+        request = self.apply_retrieve_defaults(request, account_key=account_key)
+        summary_target = self._summary_target() if request.summarize else None
+        if request.summarize and summary_target is None:
+            msg = (
+                "summarize requires ORBIT_SUMMARIZE_UPSTREAM_URL "
+                "(or ORBIT_CHAT_PROXY_UPSTREAM_URL) to be set"
+            )
+            raise ValueError(msg)
+        start = perf_counter()
+        normalized_account_key = self._normalize_account_key(account_key)
+        cache_key: tuple[str, str, str] | None = None
+        # Session results change with every turn's working memory, so they are never reused.
+        if (
+            request.fast
+            and not request.session_id
+            and self._config.fast_retrieval_cache_seconds > 0
+        ):
+            cache_key = (normalized_account_key, max_sensitivity or "", request.model_dump_json())
+            cached = self._cached_fast_result(cache_key, request, start=start)
+            if cached is not None:
+                return cached
+        serving, mirror = self._route_index_deployments(normalized_account_key)
+        response = self._retrieve(
+            request,
+            account_key=normalized_account_key,
+            max_sensitivity=max_sensitivity,
+            index=serving,
+        )
+        if request.fast:
+            self._record_fast_latency(response.query_execution_time_ms, cache_hit=False)
+            if cache_key is not None:
+                with self._state_lock:
+                    self._fast_result_cache[cache_key] = (perf_counter(), response)
+                    self._fast_result_cache.move_to_end(cache_key)
+                    while len(self._fast_result_cache) > _FAST_CACHE_SIZE:
+                        self._fast_result_cache.popitem(last=False)
+        if mirror is not None:
+            self._index_mirror_executor.submit(
+                self._mirror_retrieve,
+                mirror,
+                request,
+                account_key=normalized_account_key,
+                max_sensitivity=max_sensitivity,
+                production=response,
+            )
+        if summary_target is not None and response.memories:
+            if request.max_latency_ms is not None:
+                remaining = start + request.max_latency_ms / 1000.0 - perf_counter()
+                if remaining <= 0:
+                    # The ranked memories are returned as they are rather than blowing the budget.
+                    with self._state_lock:
+                        self._metrics["retrieve_degraded_total"] += int(not response.degraded)
+                    return response.model_copy(
+                        update={
+                            "degraded": True,
+                            "skipped_stages": [*response.skipped_stages, "summarize"],
+                        }
+                    )
+                summary_target = replace(
+                    summary_target,
+                    timeout_seconds=min(summary_target.timeout_seconds, remaining),
+                )
+            response = self._summarize_retrieval(request.query, response, target=summary_target)
+        return response
+
+    def apply_retrieve_defaults(
+        self,
+        request: RetrieveRequest,
+        *,
+        account_key: str | None = None,
+    ) -> RetrieveRequest:
+        """Fill the options ``request`` leaves out from its namespace, then from its profile.
+
+        Options the request sets win over the namespace's defaults, and both win over the
+        profile's preset, so a client can still override anything per request.
+        """
+        explicit = request.model_fields_set
+        update: dict[str, Any] = {}
+        with self._state_session_factory() as session:
+            defaults_json = session.scalar(
+                select(ApiNamespaceRow.retrieve_defaults_json).where(
+                    ApiNamespaceRow.account_key == self._normalize_account_key(account_key)
+                )
+            )
+        if defaults_json:
+            defaults = RetrieveDefaults.model_validate_json(defaults_json)
+            update = {
+                name: value
+                for name, value in defaults.model_dump(exclude_none=True).items()
+                if name not in explicit
+            }
+        profile = update.get("profile", request.profile)
+        if profile is not None:
+            for name, value in RETRIEVAL_PROFILES[profile].items():
+                if name not in explicit and name not in update:
+                    update[name] = value
+        if not update:
+            return request
+        # Validated again, since a preset can conflict with options the request set itself.
+        return RetrieveRequest.model_validate(
+            {**request.model_dump(exclude_unset=True), **update}
+        )
+
+    def _cached_fast_result(
+        self,
+        cache_key: tuple[str, str, str],
+        request: RetrieveRequest,
+        *,
+        start: float,
+    ) -> RetrieveResponse | None:
+        with self._state_lock:
+            entry = self._fast_result_cache.get(cache_key)
+            if entry is None:
+                return None
+            cached_at, response = entry
+            if perf_counter() - cached_at > self._config.fast_retrieval_cache_seconds:
+                del self._fast_result_cache[cache_key]
+                return None
+            self._fast_result_cache.move_to_end(cache_key)
+        query_execution_time_ms = (perf_counter() - start) * 1000.0
+        self._record_fast_latency(query_execution_time_ms, cache_hit=True)
+        self._retrieval_bookkeeping_executor.submit(
+            self._record_retrieval,
+            [] if response.fallback else [memory.memory_id for memory in response.memories],
+            request.query,
+            result_count=0 if response.fallback else len(response.memories),
+            latency_ms=query_execution_time_ms,
+            account_key=cache_key[0],
+        )
+        return response.model_copy(
+            update={"cached": True, "query_execution_time_ms": query_execution_time_ms}
+        )
+
+    def _record_fast_latency(self, latency_ms: float, *, cache_hit: bool) -> None:
+        with self._state_lock:
+            self._fast_latencies_ms.append(latency_ms)
+            self._metrics["retrieve_fast_requests_total"] += 1
+            if cache_hit:
+                self._metrics["retrieve_requests_total"] += 1
+                self._metrics["retrieve_latency_ms_sum"] += latency_ms
+                self._metrics["retrieve_fast_cache_hits_total"] += 1
+
+    def _fast_latency_p99_ms(self) -> float:
+        with self._state_lock:
+            latencies = list(self._fast_latencies_ms)
+        return percentile(latencies, 0.99)
+
+    def _evict_changed_from_fast_results(self, operation: str, memory: MemoryRecord) -> None:
+        # Any write can change an account's ranking, so its cached results all go.
+        account_key = self._normalize_account_key(memory.account_key)
+        with self._state_lock:
+            for cache_key in [key for key in self._fast_result_cache if key[0] == account_key]:
+                del self._fast_result_cache[cache_key]
+
+    def _record_retrieval(
+        self,
+        memory_ids: list[str],
+        query: str,
+        *,
+        result_count: int,
+        latency_ms: float,
+        account_key: str,
+    ) -> None:
+        for memory_id in memory_ids:
+            self._engine.storage.update_retrieval(memory_id, account_key=account_key)
+        self._log_query(
+            query,
+            result_count=result_count,
+            latency_ms=latency_ms,
+            account_key=account_key,
+        )
+
+    def _query_embedding(self, query: str, *, account_key: str, cached: bool) -> np.ndarray:
+        encoder = self._engine.processor_for(account_key).encoder
+        # Keyed by encoder so an account that switches pipeline mode re-encodes its queries.
+        cache_key = (id(encoder), query)
+        if cached:
+            with self._state_lock:
+                embedding = self._query_embedding_cache.get(cache_key)
+                if embedding is not None:
+                    self._query_embedding_cache.move_to_end(cache_key)
+                    return embedding
+        embedding = np.asarray(encoder.encode_query(query), dtype=np.float32)
+        if cached:
+            with self._state_lock:
+                self._query_embedding_cache[cache_key] = embedding
+                while len(self._query_embedding_cache) > _FAST_CACHE_SIZE:
+                    self._query_embedding_cache.popitem(last=False)
+        return embedding
+
+    def _summary_target(self) -> SummaryTarget | None:
+        base_url = self._config.summarize_upstream_url or self._config.chat_proxy_upstream_url
+        if not base_url:
+            return None
+        return SummaryTarget(
+            base_url=base_url,
+            api_key=(
+                self._config.summarize_upstream_api_key
+                if self._config.summarize_upstream_url
+                else self._config.chat_proxy_upstream_api_key
+            ),
+            model=self._config.summarize_model,
+            timeout_seconds=self._config.summarize_timeout_seconds,
+        )
+
+    @staticmethod
+    def _summarize_retrieval(
+        query: str,
+        response: RetrieveResponse,
+        *,
+        target: SummaryTarget,
+    ) -> RetrieveResponse:
+        """Replace the ranked memories with one paragraph that cites them by ID."""
+        text = call_summarizer(target, summary_messages(query, response.memories))
+        summary = RetrieveSummary(
+            text=text,
+            citations=cited_memory_ids(text, [memory.memory_id for memory in response.memories]),
+            model=target.model,
+        )
+        return response.model_copy(update={"memories": [], "summary": summary})
+
+    def _retrieve(
+        self,
+        request: RetrieveRequest,
+        *,
+        account_key: str,
+        max_sensitivity: str | None,
+        index: ShadowIndex | None,
+        shadow: bool = False,
+    ) -> RetrieveResponse:
+        """Run ``retrieve`` against ``index`` (the engine's own when ``None``).
+
+        A ``shadow`` run is a mirrored query: it has no side effects and stops after ranking.
+        A ``fast`` run skips the optional stages and records its side effects in the background.
+        """
+        start = perf_counter()
+        deadline = (
+            start + request.max_latency_ms / 1000.0
+            if request.max_latency_ms is not None
+            else None
+        )
+        skipped_stages: list[str] = []
+
+        def within_budget(stage: str) -> bool:
+            # Optional stages are skipped once the budget is spent; the core vector
+            # search and ranking always run so there is something to return.
+            if request.fast and stage in _FAST_SKIPPED_STAGES:
+                # Skipped by design, so the result is not reported as degraded.
+                return False
+            if deadline is None or perf_counter() < deadline:
+                return True
+            skipped_stages.append(stage)
+            return False
+
+        if (
+            not shadow
+            and request.consistency == "strong"
+            and not self._engine.wait_for_flash_pipeline(
+                self._config.strong_consistency_timeout_ms / 1000.0
+                if deadline is None
+                else max(
+                    0.0,
+                    min(
+                        self._config.strong_consistency_timeout_ms / 1000.0,
+                        deadline - perf_counter(),
+                    ),
+                )
+            )
+        ):
+            # Indexing of earlier writes did not finish in time; serve what is visible now.
+            skipped_stages.append("consistency")
+
+        normalized_account_key = self._normalize_account_key(account_key)
+        topic_memory_ids: set[str] | None = None
+        if request.topic_id:
+            if not request.entity_id:
+                msg = "topic_id requires entity_id"
+                raise ValueError(msg)
+            topic_memory_ids = self._topic_memory_ids(
+                request.entity_id,
+                request.topic_id,
+                account_key=normalized_account_key,
+                max_sensitivity=max_sensitivity,
+                agent_id=request.agent_id,
+            )
+        if index is None:
+            query_embedding = self._query_embedding(
+                request.query,
+                account_key=normalized_account_key,
+                cached=request.fast,
+            )
+        else:
+            query_embedding = index.encode_query(request.query)
+        vector_store = index if index is not None else getattr(self._engine, "vector_store", None)
+        now = clock.now()
+        pool_size = max(120, request.limit * 20)
+        preselected: list[MemoryRecord]
+        if request.entity_id:
+            entity_ids_fn = getattr(self._engine, "memory_ids_for_entity", None)
+            entity_ids = (
+                entity_ids_fn(
+                    request.entity_id,
+                    account_key=normalized_account_key,
+                )
+                if callable(entity_ids_fn)
+                else []
+            )
+            preselected = self._engine.storage.fetch_by_ids(
+                entity_ids,
+                account_key=normalized_account_key,
+            )
+            if not preselected:
+                if vector_store is None:
+                    preselected = self._engine.storage.search_candidates(
+                        query_embedding,
+                        top_k=pool_size,
+                        account_key=normalized_account_key,
+                    )
+                else:
+                    hits = vector_store.search(query_embedding, top_k=pool_size)
+                    preselected = self._engine.storage.fetch_by_ids(
+                        [hit.memory_id for hit in hits],
+                        account_key=normalized_account_key,
+                    )
+        else:
+            if vector_store is not None:
+                hits = vector_store.search(query_embedding, top_k=pool_size)
+                preselected = self._engine.storage.fetch_by_ids(
+                    [hit.memory_id for hit in hits],
+                    account_key=normalized_account_key,
+                )
+            else:
+                preselected = self._engine.storage.search_candidates(
+                    query_embedding,
+                    top_k=pool_size,
+                    account_key=normalized_account_key,
+                )
+        # Storage search compares stored embeddings, which a deployed index does not use.
+        if (
+            index is None
+            and len(preselected) < request.limit
+            and within_budget("candidate_fallback")
+        ):
+            fallback = self._engine.storage.search_candidates(
+                query_embedding,
+                top_k=max(pool_size, request.limit * 4),
+                account_key=normalized_account_key,
+            )
+            seen_ids = {item.memory_id for item in preselected}
+            preselected.extend(
+                item for item in fallback if item.memory_id not in seen_ids
+            )
+        keyword_search_fn = getattr(self._engine.storage, "keyword_search", None)
+        if callable(keyword_search_fn) and within_budget("keyword_search"):
+            keyword_hits = keyword_search_fn(
+                request.query,
+                top_k=max(request.limit * 4, 20),
+                account_key=normalized_account_key,
+            )
+            seen_ids = {item.memory_id for item in preselected}
+            preselected.extend(
+                item for item in keyword_hits if item.memory_id not in seen_ids
+            )
+        candidates = self._apply_filters(
+            records=preselected,
+            entity_id=request.entity_id,
+            event_type=request.event_type,
+            start_time=request.time_range.start if request.time_range else None,
+            end_time=request.time_range.end if request.time_range else None,
+        )
+        if within_budget("candidate_expansion"):
+            candidates = self._ensure_non_assistant_candidates(
+                candidates=candidates,
+                top_k=request.limit,
+                query=request.query,
+                pool_size=pool_size,
+                entity_id=request.entity_id,
+                event_type=request.event_type,
+                start_time=request.time_range.start if request.time_range else None,
+                end_time=request.time_range.end if request.time_range else None,
+                account_key=normalized_account_key,
+            )
+        shared_from: dict[str, str] = {}
+        if request.entity_id:
+            shared = self._shared_records(request.entity_id, account_key=normalized_account_key)
+            seen_ids = {item.memory_id for item in candidates}
+            for record in self._apply_filters(
+                records=[record for record, _ in shared.values()],
+                entity_id=None,
+                event_type=request.event_type,
+                start_time=request.time_range.start if request.time_range else None,
+                end_time=request.time_range.end if request.time_range else None,
+            ):
+                shared_from[record.memory_id] = shared[record.memory_id][1]
+                if record.memory_id not in seen_ids:
+                    candidates.append(record)
+        if topic_memory_ids is not None:
+            candidates = [item for item in candidates if item.memory_id in topic_memory_ids]
+        if request.sentiment or request.emotion:
+            candidates = [
+                item
+                for item in candidates
+                if (
+                    request.sentiment is None
+                    or f"sentiment:{request.sentiment}" in item.relationships
+                )
+                and (request.emotion is None or f"emotion:{request.emotion}" in item.relationships)
+            ]
+        if request.category:
+            if (
+                request.category not in self._config.memory_categories
+                and request.category != UNCATEGORIZED
+            ):
+                msg = f"unknown memory category: {request.category}"
+                raise ValueError(msg)
+            candidates = [
+                item
+                for item in candidates
+                if f"category:{request.category}" in item.relationships
+            ]
+        if request.from_agent:
+            candidates = [
+                item for item in candidates if f"agent:{request.from_agent}" in item.relationships
+            ]
+        candidates = self._visible_to_agent(candidates, request.agent_id)
+        candidates = self._within_clearance(candidates, max_sensitivity)
+        if index is not None:
+            candidates = index.with_vectors(candidates, query_embedding)
+        ranked = self._engine.ranker.rank(query_embedding, candidates, now=now)
+        if within_budget("rerank"):
+            ranked = self._diversity_aware_rerank(ranked)
+            ranked = self._reweight_ranked_by_query(
+                query=request.query,
+                ranked=ranked,
+                candidates=candidates,
+            )
+            ranked = self._promote_primary_candidate_for_query(
+                query=request.query,
+                ranked=ranked,
+            )
+        if self._config.category_weights:
+            ranked = self._weight_by_category(ranked)
+        if request.boost is not None:
+            ranked = self._apply_boost(ranked, request.boost)
+        if request.mmr:
+            selected = self._select_with_mmr(
+                ranked,
+                top_k=request.limit,
+                mmr_lambda=request.mmr_lambda,
+            )
+        elif within_budget("intent_caps"):
+            selected = self._select_with_intent_caps(
+                ranked,
+                top_k=request.limit,
+                query=request.query,
+            )
+        else:
+            selected = ranked[: request.limit]
+        graph_paths: dict[str, dict[str, Any]] = {}
+        if request.mode == "graph" and within_budget("graph_expansion"):
+            connected = self._expand_through_graph(
+                selected,
+                query_embedding=query_embedding,
+                index=index,
+                request=request,
+                account_key=normalized_account_key,
+                max_sensitivity=max_sensitivity,
+                now=now,
+                within_budget=within_budget,
+            )
+            graph_paths = {item.memory.memory_id: path for item, path in connected}
+            selected = sorted(
+                [*selected, *(item for item, _ in connected)],
+                key=lambda item: item.rank_score,
+                reverse=True,
+            )[: request.limit]
+        if request.min_score is not None:
+            selected = [item for item in selected if item.rank_score >= request.min_score]
+        memories: list[Memory] = []
+        for position, ranked_item in enumerate(selected, start=1):
+            if not shadow and not request.fast:
+                self._engine.storage.update_retrieval(
+                    ranked_item.memory.memory_id,
+                    account_key=normalized_account_key,
+                )
+            memory = self._as_memory(
+                ranked_item.memory,
+                rank_position=position,
+                rank_score=float(ranked_item.rank_score),
+            )
+            if request.mode == "graph":
+                memory.metadata["graph"] = graph_paths.get(memory.memory_id)
+            if memory.memory_id in shared_from:
+                memory.metadata["shared_from"] = shared_from[memory.memory_id]
+            if request.boost is not None:
+                boost = self._boost_multiplier(ranked_item.memory, request.boost)
+                if boost != 1.0:
+                    memory.metadata["boost"] = boost
+            memories.append(memory)
+        if shadow:
+            return RetrieveResponse(
+                memories=memories,
+                total_candidates=len(candidates),
+                query_execution_time_ms=(perf_counter() - start) * 1000.0,
+            )
+
+        self._memory_occurrences(memories, account_key=normalized_account_key)
+
+        if request.include_linked and within_budget("linked_memories"):
+            memories.extend(
+                self._linked_memories(
+                    memories,
+                    account_key=normalized_account_key,
+                    max_sensitivity=max_sensitivity,
+                    agent_id=request.agent_id,
+                    entity_id=request.entity_id,
+                    limit=request.limit,
+                )
+            )
+
+        if request.session_id:
+            memories = self._merge_working_memory(
+                request,
+                memories,
+                account_key=normalized_account_key,
+            )
+
+        fallback: str | None = None
+        fallback_attributes: dict[str, Any] | None = None
+        if not memories:
+            fallback, memories, fallback_attributes = self._zero_result_fallback(
+                request,
+                account_key=normalized_account_key,
+                max_sensitivity=max_sensitivity,
+            )
+
+        query_execution_time_ms = (perf_counter() - start) * 1000.0
+        with self._state_lock:
+            self._metrics["retrieve_requests_total"] += 1
+            self._metrics["retrieve_latency_ms_sum"] += query_execution_time_ms
+            if skipped_stages:
+                self._metrics["retrieve_degraded_total"] += 1
+
+        applied_filters: dict[str, str] = {}
+        if request.entity_id:
+            applied_filters["entity_id"] = request.entity_id
+        if request.event_type:
+            applied_filters["event_type"] = request.event_type
+        if request.time_range:
+            applied_filters["start_time"] = request.time_range.start.isoformat()
+            applied_filters["end_time"] = request.time_range.end.isoformat()
+        if request.session_id:
+            applied_filters["session_id"] = request.session_id
+        if request.mode != "vector":
+            applied_filters["mode"] = request.mode
+            applied_filters["graph_hops"] = str(request.graph_hops)
+        if request.topic_id:
+            applied_filters["topic_id"] = request.topic_id
+        if request.sentiment:
+            applied_filters["sentiment"] = request.sentiment
+        if request.emotion:
+            applied_filters["emotion"] = request.emotion
+        if request.category:
+            applied_filters["category"] = request.category
+        if request.agent_id:
+            applied_filters["agent_id"] = request.agent_id
+        if request.from_agent:
+            applied_filters["from_agent"] = request.from_agent
+        if request.profile:
+            applied_filters["profile"] = request.profile
+        if request.mmr:
+            applied_filters["mmr_lambda"] = str(request.mmr_lambda)
+        if max_sensitivity is not None:
+            applied_filters["max_sensitivity"] = max_sensitivity
+
+        if request.fast:
+            self._retrieval_bookkeeping_executor.submit(
+                self._record_retrieval,
+                [item.memory.memory_id for item in selected],
+                request.query,
+                result_count=0 if fallback else len(memories),
+                latency_ms=query_execution_time_ms,
+                account_key=normalized_account_key,
+            )
+        else:
+            self._retrieval_bookkeeping_executor.submit(
+                self._log_query,
+                request.query,
+                result_count=0 if fallback else len(memories),
+                latency_ms=query_execution_time_ms,
+                account_key=normalized_account_key,
+            )
+        return RetrieveResponse(
+            memories=memories,
+            total_candidates=len(candidates),
+            query_execution_time_ms=query_execution_time_ms,
+            applied_filters=applied_filters,
+            degraded=bool(skipped_stages),
+            skipped_stages=skipped_stages,
+            fallback=fallback,
+            attributes=fallback_attributes,
+        )
+
+    def _zero_result_fallback(
+        self,
+        request: RetrieveRequest,
+        *,
+        account_key: str,
+        max_sensitivity: str | None,
+    ) -> tuple[str | None, list[Memory], dict[str, Any] | None]:
+        """Apply the request's (or the configured) zero-result fallback.
+
+        Returns the fallback that ran, replacement memories, and the entity profile for
+        ``attributes``; ``(None, [], None)`` when the result should stay empty.
+        """
+        fallback = request.fallback or self._config.zero_result_fallback
+        if fallback == "recent":
+            records = sorted(
+                self._visible_to_agent(
+                    self._within_clearance(
+                        self._apply_filters(
+                            records=self._engine.storage.list_memories(account_key=account_key),
+                            entity_id=request.entity_id,
+                            event_type=request.event_type,
+                            start_time=request.time_range.start if request.time_range else None,
+                            end_time=request.time_range.end if request.time_range else None,
+                        ),
+                        max_sensitivity,
+                    ),
+                    request.agent_id,
+                ),
+                key=lambda item: item.event_time,
+                reverse=True,
+            )[: request.limit]
+            memories = [
+                self._as_memory(record, rank_position=index, rank_score=0.0)
+                for index, record in enumerate(records, start=1)
+            ]
+            for memory in memories:
+                memory.metadata["fallback"] = "recent"
+            return ("recent", memories, None) if memories else (None, [], None)
+        if fallback == "attributes":
+            if not request.entity_id:
+                return "attributes", [], {}
+            profile = self.entity_attributes(request.entity_id, account_key=account_key)
+            return "attributes", [], profile.attributes
+        webhook_url = self._config.zero_result_webhook_url
+        if fallback == "webhook" and webhook_url:
+            submit_with_context(
+                self._webhook_executor,
+                _post_webhook,
+                webhook_url,
+                "zero_result_query",
+                {
+                    "account_key": account_key,
+                    "query": anonymize_query(request.query),
+                    "entity_id": request.entity_id,
+                    "event_type": request.event_type,
+                    "occurred_at": datetime.now(UTC).isoformat(),
+                },
+                secret=self._config.zero_result_webhook_secret,
+            )
+            return "webhook", [], None
+        return None, [], None
+
+    def remember_in_session(
+        self,
+        session_id: str,
+        request: SessionMemoryRequest,
+        *,
+        account_key: str | None = None,
+    ) -> SessionMemoryItem:
+        normalized_account_key = self._normalize_account_key(account_key)
+        normalized_session_id = self._normalize_session_id(session_id)
+        if len(request.content) > self._config.max_ingest_content_chars:
+            msg = f"content must be at most {self._config.max_ingest_content_chars} characters"
+            raise ValueError(msg)
+        policy = self._write_policy_for(normalized_account_key)
+        if policy is not None:
+            self._enforce_write_policy(
+                policy,
+                event_type=request.event_type or self._config.default_event_type,
+                importance=request.importance,
+                content=request.content,
+            )
+        item = new_working_memory_item(
+            session_id=normalized_session_id,
+            content=request.content,
+            event_type=request.event_type or self._config.default_event_type,
+            entity_id=request.entity_id or self._config.default_entity_id,
+            importance=request.importance,
+            metadata=request.metadata,
+        )
+        self._working_memory.append(
+            normalized_account_key,
+            item,
+            ttl_seconds=self._config.working_memory_ttl_seconds,
+        )
+        self.promote_idle_sessions()
+        return self._as_session_item(item)
+
+    def session_memories(
+        self,
+        session_id: str,
+        *,
+        account_key: str | None = None,
+    ) -> SessionMemoryListResponse:
+        normalized_session_id = self._normalize_session_id(session_id)
+        items = self._working_memory.list(
+            self._normalize_account_key(account_key),
+            normalized_session_id,
+        )
+        return SessionMemoryListResponse(
+            session_id=normalized_session_id,
+            data=[self._as_session_item(item) for item in items],
+        )
+
+    def end_session(
+        self,
+        session_id: str,
+        *,
+        account_key: str | None = None,
+    ) -> SessionEndResponse:
+        """Promote important or recalled items to long-term memory and drop the rest."""
+        normalized_account_key = self._normalize_account_key(account_key)
+        normalized_session_id = self._normalize_session_id(session_id)
+        items = self._working_memory.pop_session(normalized_account_key, normalized_session_id)
+        min_importance = self._config.working_memory_promotion_min_importance
+        promoted: list[str] = []
+        discarded = 0
+        rejected = 0
+        for index, item in enumerate(items):
+            if not should_promote_working_memory(item, min_importance=min_importance):
+                discarded += 1
+                continue
+            try:
+                response, _, _ = self.ingest_with_quota(
+                    account_key=normalized_account_key,
+                    request=IngestRequest(
+                        content=item.content,
+                        event_type=item.event_type,
+                        entity_id=item.entity_id,
+                        metadata={
+                            **item.metadata,
+                            "session_id": item.session_id,
+                            "promoted_from": "working_memory",
+                        },
+                    ),
+                    idempotency_key=item.item_id,
+                )
+            except (ValueError, IdempotencyConflictError):
+                # Refused by validation, moderation or a write policy: retrying cannot help.
+                rejected += 1
+                with self._state_lock:
+                    self._metrics["working_memory_promotions_rejected_total"] += 1
+                continue
+            except Exception as exc:  # pylint: disable=broad-exception-caught
+                # Out of quota or the store failed: keep what was not promoted so the session
+                # can be ended again later.
+                self._log.warning(
+                    "session_end_requeued",
+                    account=normalized_account_key,
+                    session_id=normalized_session_id,
+                    requeued=len(items) - index,
+                    error=str(exc),
+                )
+                for remaining in items[index:]:
+                    self._working_memory.append(
+                        normalized_account_key,
+                        remaining,
+                        ttl_seconds=self._config.working_memory_ttl_seconds,
+                    )
+                raise
+            promoted.append(response.memory_id)
+        return SessionEndResponse(
+            session_id=normalized_session_id,
+            promoted_memory_ids=promoted,
+            discarded_count=discarded,
+            rejected_count=rejected,
+        )
+
+    def promote_idle_sessions(self, *, force: bool = False) -> int:
+        """End sessions idle longer than the working-memory TTL (checked at most once a minute)."""
+        with self._state_lock:
+            if not force and perf_counter() - self._last_session_sweep < 60.0:
+                return 0
+            self._last_session_sweep = perf_counter()
+        idle_before = clock.now().timestamp() - self._config.working_memory_ttl_seconds
+        ended = 0
+        for account_key, session_id in self._working_memory.idle_sessions(idle_before):
+            try:
+                self.end_session(session_id, account_key=account_key)
+            except Exception:  # pylint: disable=broad-exception-caught
+                # end_session requeued the unpromoted items; the next sweep retries them.
+                continue
+            ended += 1
+        return ended
+
+    def start_session_sweeper(self, interval_seconds: float = 60.0) -> None:
+        """End idle sessions on a timer, so they are promoted without waiting for another write."""
+        if interval_seconds <= 0 or self._session_sweep_thread is not None:
+            return
+        self._session_sweep_stop.clear()
+        self._session_sweep_thread = Thread(
+            target=self._sweep_sessions,
+            args=(interval_seconds,),
+            name="orbit-session-sweep",
+            daemon=True,
+        )
+        self._session_sweep_thread.start()
+
+    def stop_session_sweeper(self) -> None:
+        self._session_sweep_stop.set()
+        if self._session_sweep_thread is not None:
+            self._session_sweep_thread.join(timeout=5)
+            self._session_sweep_thread = None
+
+    def _sweep_sessions(self, interval_seconds: float) -> None:
+        while not self._session_sweep_stop.wait(interval_seconds):
+            self.promote_idle_sessions(force=True)
+
+    def _expand_through_graph(
+        self,
+        seeds: list[RetrievedMemory],
+        *,
+        query_embedding: np.ndarray,
+        index: ShadowIndex | None = None,
+        request: RetrieveRequest,
+        account_key: str,
+        max_sensitivity: str | None,
+        now: datetime,
+        within_budget: Callable[[str], bool] | None = None,
+    ) -> list[tuple[RetrievedMemory, dict[str, Any]]]:
+        """Walk out from vector hits via shared entities and ``a->b`` relationship edges.
+
+        Returns connected memories (not already in ``seeds``) with the path that reached them.
+        Hops after the first stop once ``within_budget`` reports the latency budget spent.
+        """
+        entity_ids_fn = getattr(self._engine, "memory_ids_for_entity", None)
+        if not callable(entity_ids_fn) or not seeds:
+            return []
+        seen_ids = {item.memory.memory_id for item in seeds}
+        # The default entity and the entity already being searched would pull in everything.
+        visited = {self._config.default_entity_id, request.entity_id or ""}
+        frontier = [
+            (entity, item.memory.memory_id, float(item.rank_score))
+            for item in seeds
+            for entity in self._graph_neighbors(item.memory)
+        ]
+        connected: list[tuple[RetrievedMemory, dict[str, Any]]] = []
+        for hop in range(1, request.graph_hops + 1):
+            if hop > 1 and within_budget is not None and not within_budget("graph_hops"):
+                break
+            reached: dict[str, tuple[str, str, float]] = {}
+            for entity, from_memory_id, parent_score in frontier:
+                if entity in visited:
+                    continue
+                visited.add(entity)
+                for memory_id in entity_ids_fn(entity, account_key=account_key):
+                    if memory_id not in seen_ids and memory_id not in reached:
+                        reached[memory_id] = (entity, from_memory_id, parent_score)
+            records = self._apply_filters(
+                records=self._engine.storage.fetch_by_ids(
+                    list(reached),
+                    account_key=account_key,
+                ),
+                entity_id=None,
+                event_type=request.event_type,
+                start_time=request.time_range.start if request.time_range else None,
+                end_time=request.time_range.end if request.time_range else None,
+            )
+            # Paths never run through a memory the caller is not cleared to see.
+            records = self._visible_to_agent(
+                self._within_clearance(records, max_sensitivity),
+                request.agent_id,
+            )
+            if not records:
+                break
+            if index is not None:
+                records = index.with_vectors(records, query_embedding)
+            frontier = []
+            ranked = self._engine.ranker.rank(query_embedding, records, now=now)
+            for item in ranked[: request.limit]:
+                entity, from_memory_id, parent_score = reached[item.memory.memory_id]
+                score = parent_score * (_GRAPH_HOP_DECAY**hop) * (0.5 + 0.5 * float(item.rank_score))
+                seen_ids.add(item.memory.memory_id)
+                connected.append(
+                    (
+                        RetrievedMemory(memory=item.memory, rank_score=score),
+                        {
+                            "hops": hop,
+                            "via_entity": entity,
+                            "from_memory_id": from_memory_id,
+                        },
+                    )
+                )
+                frontier.extend(
+                    (neighbor, item.memory.memory_id, score)
+                    for neighbor in self._graph_neighbors(item.memory)
+                )
+        return connected
+
+    @staticmethod
+    def _graph_neighbors(record: MemoryRecord) -> list[str]:
+        neighbors = list(record.entities)
+        for relation in record.relationships:
+            if "->" not in relation:
+                continue
+            parts = [part.strip() for part in relation.split("->")]
+            # Endpoints such as "fact:allergy:peanuts" are attributes, not entities.
+            neighbors.extend(part for part in (parts[0], parts[-1]) if part and ":" not in part)
+        return list(dict.fromkeys(neighbors))
+
+    def recall(
+        self,
+        request: RecallRequest,
+        *,
+        account_key: str | None = None,
+        max_sensitivity: str | None = None,
+    ) -> RecallResponse:
+        """Entity profile, relevant memories, and the current session in a single call."""
+        start = perf_counter()
+        profile = self.entity_attributes(request.entity_id, account_key=account_key)
+        retrieved = self.retrieve(
+            RetrieveRequest(
+                query=request.query,
+                limit=request.limit,
+                entity_id=request.entity_id,
+                max_latency_ms=request.max_latency_ms,
+                session_id=request.session_id,
+            ),
+            account_key=account_key,
+            max_sensitivity=max_sensitivity,
+        )
+        session = (
+            self._session_summary(
+                request.session_id,
+                limit=request.session_items,
+                account_key=account_key,
+            )
+            if request.session_id
+            else None
+        )
+        return RecallResponse(
+            entity_id=request.entity_id,
+            profile=profile,
+            memories=retrieved.memories,
+            session=session,
+            query_execution_time_ms=(perf_counter() - start) * 1000.0,
+            degraded=retrieved.degraded,
+        )
+
+    def ask(
+        self,
+        request: AskRequest,
+        *,
+        account_key: str | None = None,
+        max_sensitivity: str | None = None,
+    ) -> AskResponse:
+        """Answer ``request.question`` from retrieved memories, citing the ones it used.
+
+        The answer is ``None`` with zero confidence when nothing matched (the model is not
+        called) or when the model finds the memories do not answer the question.
+        """
+        start = perf_counter()
+        target = self._summary_target()
+        if target is None:
+            msg = (
+                "ask requires ORBIT_SUMMARIZE_UPSTREAM_URL "
+                "(or ORBIT_CHAT_PROXY_UPSTREAM_URL) to be set"
+            )
+            raise ValueError(msg)
+        retrieved = self.retrieve(
+            RetrieveRequest(
+                query=request.question,
+                limit=request.limit,
+                entity_id=request.entity_id,
+                event_type=request.event_type,
+                time_range=request.time_range,
+            ),
+            account_key=account_key,
+            max_sensitivity=max_sensitivity,
+        )
+        answer: str | None = None
+        confidence = 0.0
+        citations: list[str] = []
+        if retrieved.memories:
+            answer, confidence = parse_answer(
+                call_summarizer(target, answer_messages(request.question, retrieved.memories))
+            )
+        if answer:
+            citations = cited_memory_ids(
+                answer,
+                [memory.memory_id for memory in retrieved.memories],
+            )
+        by_id = {memory.memory_id: memory for memory in retrieved.memories}
+        return AskResponse(
+            question=request.question,
+            answer=answer,
+            citations=citations,
+            confidence=confidence,
+            memories=[by_id[memory_id] for memory_id in citations],
+            model=target.model,
+            query_execution_time_ms=(perf_counter() - start) * 1000.0,
+        )
+
+    def chat(
+        self,
+        request: ChatRequest,
+        *,
+        account_key: str | None = None,
+        max_sensitivity: str | None = None,
+    ) -> ChatResponse:
+        """Reply to ``request.message`` with the session's earlier turns and relevant memories.
+
+        Both sides of the turn are then ingested as ``user_question`` / ``assistant_response``
+        and appended to the session. A session stays with the entity it started with.
+        """
+        target = self._summary_target()
+        if target is None:
+            msg = (
+                "chat requires ORBIT_SUMMARIZE_UPSTREAM_URL "
+                "(or ORBIT_CHAT_PROXY_UPSTREAM_URL) to be set"
+            )
+            raise ValueError(msg)
+        normalized_account_key = self._normalize_account_key(account_key)
+        session_id = self._normalize_session_id(request.session_id)
+        with self._state_session_factory() as session:
+            rows = session.scalars(
+                self._chat_turns_query(normalized_account_key, session_id)
+                .order_by(ApiChatTurnRow.id.desc())
+                .limit(_CHAT_HISTORY_TURNS)
+            ).all()
+        history = list(reversed(rows))
+        entity_id = self._normalize_entity_id(
+            (history[0].entity_id if history else None)
+            or request.entity_id
+            or self._config.default_entity_id
+        )
+        if request.entity_id and self._normalize_entity_id(request.entity_id) != entity_id:
+            msg = f"session {session_id} belongs to entity {entity_id}"
+            raise ValueError(msg)
+        memories = (
+            self.retrieve(
+                RetrieveRequest(
+                    query=request.message,
+                    limit=request.memory_limit,
+                    entity_id=entity_id,
+                ),
+                account_key=normalized_account_key,
+                max_sensitivity=max_sensitivity,
+            ).memories
+            if request.memory_limit
+            else []
+        )
+        reply = call_summarizer(
+            target,
+            chat_messages(
+                [(row.role, row.content) for row in history],
+                memories,
+                request.message,
+            ),
+        )
+        turns: list[ApiChatTurnRow] = []
+        for role, content in (("user", request.message), ("assistant", reply)):
+            turns.append(
+                ApiChatTurnRow(
+                    account_key=normalized_account_key,
+                    session_id=session_id,
+                    entity_id=entity_id,
+                    role=role,
+                    content=content,
+                    memory_id=self._ingest_chat_turn(
+                        role,
+                        content,
+                        entity_id=entity_id,
+                        session_id=session_id,
+                        account_key=normalized_account_key,
+                    ),
+                    retrieved_ids_json=json.dumps(
+                        [memory.memory_id for memory in memories] if role == "assistant" else []
+                    ),
+                    created_at=datetime.now(UTC),
+                )
+            )
+        with self._state_session_factory() as session:
+            session.add_all(turns)
+            session.commit()
+            turn_count = session.scalar(
+                select(func.count()).select_from(
+                    self._chat_turns_query(normalized_account_key, session_id).subquery()
+                )
+            )
+        return ChatResponse(
+            session_id=session_id,
+            entity_id=entity_id,
+            reply=reply,
+            memories=memories,
+            ingested_memory_ids=[turn.memory_id for turn in turns if turn.memory_id],
+            model=target.model,
+            turn_count=int(turn_count or 0),
+        )
+
+    def chat_history(
+        self,
+        session_id: str,
+        *,
+        account_key: str | None = None,
+    ) -> ChatHistoryResponse:
+        """Every turn of a chat session, oldest first."""
+        normalized_session_id = self._normalize_session_id(session_id)
+        with self._state_session_factory() as session:
+            rows = session.scalars(
+                self._chat_turns_query(
+                    self._normalize_account_key(account_key),
+                    normalized_session_id,
+                ).order_by(ApiChatTurnRow.id)
+            ).all()
+            return ChatHistoryResponse(
+                session_id=normalized_session_id,
+                data=[self._as_chat_turn(row) for row in rows],
+            )
+
+    def clear_chat(
+        self,
+        session_id: str,
+        *,
+        account_key: str | None = None,
+    ) -> ChatHistoryResponse:
+        """Forget a session's turns and return them; memories ingested from them remain."""
+        history = self.chat_history(session_id, account_key=account_key)
+        if not history.data:
+            msg = f"chat session not found: {history.session_id}"
+            raise KeyError(msg)
+        with self._state_session_factory() as session:
+            session.execute(
+                delete(ApiChatTurnRow).where(
+                    ApiChatTurnRow.account_key == self._normalize_account_key(account_key),
+                    ApiChatTurnRow.session_id == history.session_id,
+                )
+            )
+            session.commit()
+        return history
+
+    def _ingest_chat_turn(
+        self,
+        role: str,
+        content: str,
+        *,
+        entity_id: str,
+        session_id: str,
+        account_key: str,
+    ) -> str | None:
+        try:
+            result, _, _ = self.ingest_with_quota(
+                account_key=account_key,
+                request=IngestRequest(
+                    content=content,
+                    event_type=_CHAT_EVENT_TYPES[role],
+                    entity_id=entity_id,
+                    metadata={"source": "chat", "session_id": session_id},
+                ),
+                idempotency_key=None,
+            )
+        except RateLimitExceededError:
+            # The conversation goes on; the turn just is not kept in long-term memory.
+            return None
+        return result.memory_id if result.stored else None
+
+    @staticmethod
+    def _chat_turns_query(account_key: str, session_id: str) -> Any:
+        return select(ApiChatTurnRow).where(
+            ApiChatTurnRow.account_key == account_key,
+            ApiChatTurnRow.session_id == session_id,
+        )
+
+    @staticmethod
+    def _as_chat_turn(row: ApiChatTurnRow) -> ChatTurn:
+        return ChatTurn(
+            role=row.role,
+            content=row.content,
+            memory_id=row.memory_id,
+            retrieved_memory_ids=json.loads(row.retrieved_ids_json),
+            created_at=_as_utc(row.created_at),
+        )
+
+    def _session_summary(
+        self,
+        session_id: str,
+        *,
+        limit: int,
+        account_key: str | None = None,
+    ) -> SessionSummary:
+        normalized_session_id = self._normalize_session_id(session_id)
+        items = self._working_memory.list(
+            self._normalize_account_key(account_key),
+            normalized_session_id,
+        )
+        recent = items[-limit:] if limit else []
+        lines = [
+            f"- {item.content if len(item.content) <= 200 else item.content[:197].rstrip() + '...'}"
+            for item in recent
+        ]
+        return SessionSummary(
+            session_id=normalized_session_id,
+            item_count=len(items),
+            started_at=items[0].created_at if items else None,
+            last_activity_at=items[-1].created_at if items else None,
+            summary="\n".join(lines),
+            recent=[self._as_session_item(item) for item in recent],
+        )
+
+    def retrieve_fanout(
+        self,
+        request: FanoutRetrieveRequest,
+        *,
+        account_key: str | None = None,
+        max_sensitivity: str | None = None,
+    ) -> FanoutRetrieveResponse:
+        """Retrieve from every namespace concurrently and merge by weighted rank score."""
+        start = perf_counter()
+        sub_requests = [
+            RetrieveRequest(
+                query=request.query,
+                limit=namespace.limit or request.limit,
+                entity_id=namespace.entity_id,
+                event_type=namespace.event_type,
+                time_range=request.time_range,
+                max_latency_ms=request.max_latency_ms,
+                fallback=namespace.fallback,
+            )
+            for namespace in request.namespaces
+        ]
+        with ThreadPoolExecutor(
+            max_workers=len(sub_requests),
+            thread_name_prefix="orbit-fanout",
+        ) as executor:
+            results = list(
+                executor.map(
+                    lambda sub_request: self.retrieve(
+                        sub_request,
+                        account_key=account_key,
+                        max_sensitivity=max_sensitivity,
+                    ),
+                    sub_requests,
+                )
+            )
+
+        merged: dict[str, Memory] = {}
+        summaries: list[NamespaceRetrieveSummary] = []
+        for namespace, result in zip(request.namespaces, results, strict=True):
+            summaries.append(
+                NamespaceRetrieveSummary(
+                    name=namespace.name,
+                    weight=namespace.weight,
+                    returned=len(result.memories),
+                    total_candidates=result.total_candidates,
+                    query_execution_time_ms=result.query_execution_time_ms,
+                    degraded=result.degraded,
+                    fallback=result.fallback,
+                    attributes=result.attributes,
+                )
+            )
+            for memory in result.memories:
+                weighted_score = memory.rank_score * namespace.weight
+                existing = merged.get(memory.memory_id)
+                if existing is not None:
+                    existing.metadata["namespaces"].append(namespace.name)
+                    if weighted_score <= existing.rank_score:
+                        continue
+                    namespaces = existing.metadata["namespaces"]
+                else:
+                    namespaces = [namespace.name]
+                merged[memory.memory_id] = memory.model_copy(
+                    update={
+                        "rank_score": weighted_score,
+                        "metadata": {
+                            **memory.metadata,
+                            "namespace": namespace.name,
+                            "namespaces": namespaces,
+                            "namespace_weight": namespace.weight,
+                            "namespace_rank_score": memory.rank_score,
+                        },
+                    }
+                )
+
+        ordered = sorted(merged.values(), key=lambda item: item.rank_score, reverse=True)
+        memories = [
+            item.model_copy(update={"rank_position": index})
+            for index, item in enumerate(ordered[: request.limit], start=1)
+        ]
+        return FanoutRetrieveResponse(
+            memories=memories,
+            namespaces=summaries,
+            query_execution_time_ms=(perf_counter() - start) * 1000.0,
+            degraded=any(result.degraded for result in results),
+        )
+
+    def _log_query(
+        self,
+        query: str,
+        *,
+        result_count: int,
+        latency_ms: float,
+        account_key: str,
+    ) -> None:
+        if not self._config.query_analytics_enabled:
+            return
+        anonymized = anonymize_query(query)
+        if not anonymized:
+            return
+        now = datetime.now(UTC)
+        with self._state_lock:
+            self._query_log_writes += 1
+            prune = self._query_log_writes % _QUERY_LOG_PRUNE_INTERVAL == 0
+        with self._state_session_factory() as session:
+            session.add(
+                ApiQueryLogRow(
+                    account_key=account_key,
+                    query_hash=query_fingerprint(anonymized),
+                    query_text=anonymized,
+                    result_count=result_count,
+                    latency_ms=latency_ms,
+                    created_at=now,
+                )
+            )
+            if prune:
+                cutoff = now - timedelta(days=self._config.query_analytics_retention_days)
+                session.execute(delete(ApiQueryLogRow).where(ApiQueryLogRow.created_at < cutoff))
+            session.commit()
+
+    def query_analytics(
+        self,
+        *,
+        account_key: str | None = None,
+        days: int = 7,
+        limit: int = 20,
+    ) -> QueryAnalyticsResponse:
+        """Most frequent and zero-result queries plus daily latency percentiles, UTC days."""
+        since = datetime.now(UTC) - timedelta(days=days)
+        with self._state_session_factory() as session:
+            rows = session.execute(
+                select(
+                    ApiQueryLogRow.query_hash,
+                    ApiQueryLogRow.query_text,
+                    ApiQueryLogRow.result_count,
+                    ApiQueryLogRow.latency_ms,
+                    ApiQueryLogRow.created_at,
+                )
+                .where(ApiQueryLogRow.account_key == self._normalize_account_key(account_key))
+                .where(ApiQueryLogRow.created_at >= since)
+            ).all()
+
+        stats: dict[str, QueryStat] = {}
+        latencies: dict[date, list[float]] = {}
+        zero_by_day: dict[date, int] = {}
+        for query_hash, query_text, result_count, latency_ms, created_at in rows:
+            created_at = _as_utc(created_at)
+            zero = 1 if result_count == 0 else 0
+            stat = stats.get(query_hash)
+            if stat is None:
+                stats[query_hash] = QueryStat(
+                    query=query_text,
+                    count=1,
+                    zero_result_count=zero,
+                    last_seen_at=created_at,
+                )
+            else:
+                stat.count += 1
+                stat.zero_result_count += zero
+                stat.last_seen_at = max(stat.last_seen_at, created_at)
+            day = created_at.date()
+            latencies.setdefault(day, []).append(float(latency_ms))
+            zero_by_day[day] = zero_by_day.get(day, 0) + zero
+
+        ordered = sorted(stats.values(), key=lambda item: (-item.count, item.query))
+        zero_results = sorted(
+            (item for item in stats.values() if item.zero_result_count),
+            key=lambda item: (-item.zero_result_count, item.query),
+        )
+        total_zero = sum(zero_by_day.values())
+        return QueryAnalyticsResponse(
+            days=days,
+            total_queries=len(rows),
+            zero_result_rate=total_zero / len(rows) if rows else 0.0,
+            top_queries=ordered[:limit],
+            zero_result_queries=zero_results[:limit],
+            daily=[
+                QueryDailyStats(
+                    day=day,
+                    queries=len(values),
+                    zero_result_queries=zero_by_day[day],
+                    latency_p50_ms=percentile(values, 0.5),
+                    latency_p95_ms=percentile(values, 0.95),
+                    latency_p99_ms=percentile(values, 0.99),
+                )
+                for day, values in sorted(latencies.items())
+            ],
+        )
+
+    def retrieve_batch(
+        self,
+        request: BatchRetrieveRequest,
+        *,
+        account_key: str | None = None,
+        max_sensitivity: str | None = None,
+    ) -> BatchRetrieveResponse:
+        """Run each query as its own retrieval, concurrently; results keep the query order."""
+        start = perf_counter()
+        # Only the options the batch sets are passed on, so the rest take namespace defaults.
+        shared = request.model_dump(exclude_unset=True, exclude={"queries"})
+        sub_requests = [RetrieveRequest(query=query, **shared) for query in request.queries]
+        with ThreadPoolExecutor(
+            max_workers=min(len(sub_requests), _MAX_RETRIEVE_BATCH_WORKERS),
+            thread_name_prefix="orbit-retrieve-batch",
+        ) as executor:
+            results = list(
+                executor.map(
+                    lambda sub_request: self.retrieve(
+                        sub_request,
+                        account_key=account_key,
+                        max_sensitivity=max_sensitivity,
+                    ),
+                    sub_requests,
+                )
+            )
+        return BatchRetrieveResponse(
+            results=results,
+            query_execution_time_ms=(perf_counter() - start) * 1000.0,
+            degraded=any(result.degraded for result in results),
+        )
+
+    def search_vector(
+        self,
+        request: VectorSearchRequest,
+        *,
+        account_key: str | None = None,
+        max_sensitivity: str | None = None,
+    ) -> RetrieveResponse:
+        """Nearest memories to ``request.vector`` by cosine similarity.
+
+        The vector must come from the model that embedded the tenant's memories. There is no
+        query text, so only the filters apply: no keyword search, reranking, or fallbacks.
+        """
+        start = perf_counter()
+        normalized_account_key = self._normalize_account_key(account_key)
+        embedding_dim = self._engine.config.embedding_dim
+        if len(request.vector) != embedding_dim:
+            msg = f"vector must have {embedding_dim} dimensions"
+            raise ValueError(msg)
+        scored, total_candidates = self._nearest_records(
+            np.asarray(request.vector, dtype=np.float32),
+            limit=request.limit,
+            account_key=normalized_account_key,
+            max_sensitivity=max_sensitivity,
+            entity_id=request.entity_id,
+            event_type=request.event_type,
+            time_range=request.time_range,
+            min_score=request.min_score,
+            agent_id=request.agent_id,
+        )
+        memories: list[Memory] = []
+        for position, (record, similarity) in enumerate(scored, start=1):
+            self._engine.storage.update_retrieval(
+                record.memory_id,
+                account_key=normalized_account_key,
+            )
+            memory = self._as_memory(record, rank_position=position, rank_score=similarity)
+            memory.relevance_explanation = "Ranked by cosine similarity to the supplied vector."
+            memories.append(memory)
+
+        applied_filters: dict[str, str] = {}
+        if request.entity_id:
+            applied_filters["entity_id"] = request.entity_id
+        if request.event_type:
+            applied_filters["event_type"] = request.event_type
+        if request.time_range:
+            applied_filters["start_time"] = request.time_range.start.isoformat()
+            applied_filters["end_time"] = request.time_range.end.isoformat()
+        if request.agent_id:
+            applied_filters["agent_id"] = request.agent_id
+        if max_sensitivity is not None:
+            applied_filters["max_sensitivity"] = max_sensitivity
+        return RetrieveResponse(
+            memories=memories,
+            total_candidates=total_candidates,
+            query_execution_time_ms=(perf_counter() - start) * 1000.0,
+            applied_filters=applied_filters,
+        )
+
+    def similar_memories(
+        self,
+        memory_id: str,
+        *,
+        limit: int = 10,
+        min_score: float | None = None,
+        account_key: str | None = None,
+        max_sensitivity: str | None = None,
+        agent_id: str | None = None,
+    ) -> SimilarMemoriesResponse:
+        """Nearest neighbors of a stored memory, most similar first, excluding itself.
+
+        A memory above the caller's sensitivity clearance, or private to another agent, is
+        reported as not found. Unlike retrieval, listing neighbors does not count as a
+        retrieval of them.
+        """
+        start = perf_counter()
+        normalized_account_key = self._normalize_account_key(account_key)
+        source = self._visible_to_agent(
+            self._within_clearance(
+                self._engine.storage.fetch_by_ids([memory_id], account_key=normalized_account_key),
+                max_sensitivity,
+            ),
+            agent_id,
+        )
+        if not source:
+            msg = f"memory_id {memory_id} was not found"
+            raise KeyError(msg)
+        scored, _ = self._nearest_records(
+            np.asarray(source[0].semantic_embedding, dtype=np.float32),
+            limit=limit,
+            account_key=normalized_account_key,
+            max_sensitivity=max_sensitivity,
+            min_score=min_score,
+            exclude_id=memory_id,
+            agent_id=agent_id,
+        )
+        memories: list[Memory] = []
+        for position, (record, similarity) in enumerate(scored, start=1):
+            memory = self._as_memory(record, rank_position=position, rank_score=similarity)
+            memory.relevance_explanation = f"Cosine similarity to memory {memory_id}."
+            memories.append(memory)
+        return SimilarMemoriesResponse(
+            memory_id=memory_id,
+            memories=memories,
+            query_execution_time_ms=(perf_counter() - start) * 1000.0,
+        )
+
+    def _nearest_records(
+        self,
+        query_embedding: np.ndarray[Any, np.dtype[np.float32]],
+        *,
+        limit: int,
+        account_key: str,
+        max_sensitivity: str | None,
+        entity_id: str | None = None,
+        event_type: str | None = None,
+        time_range: TimeRange | None = None,
+        min_score: float | None = None,
+        exclude_id: str | None = None,
+        agent_id: str | None = None,
+    ) -> tuple[list[tuple[MemoryRecord, float]], int]:
+        """Top ``limit`` records by cosine similarity, and how many candidates were scored."""
+        pool_size = max(120, limit * 20)
+        if entity_id:
+            entity_ids_fn = getattr(self._engine, "memory_ids_for_entity", None)
+            entity_memory_ids = (
+                entity_ids_fn(entity_id, account_key=account_key) if callable(entity_ids_fn) else []
+            )
+            records = self._engine.storage.fetch_by_ids(entity_memory_ids, account_key=account_key)
+        else:
+            records = []
+            vector_store = getattr(self._engine, "vector_store", None)
+            if vector_store is not None:
+                hits = vector_store.search(query_embedding, top_k=pool_size)
+                records = self._engine.storage.fetch_by_ids(
+                    [hit.memory_id for hit in hits],
+                    account_key=account_key,
+                )
+            # The vector store is shared by every tenant, so its top hits can miss this one.
+            if len(records) <= limit:
+                seen_ids = {item.memory_id for item in records}
+                records.extend(
+                    item
+                    for item in self._engine.storage.search_candidates(
+                        query_embedding,
+                        top_k=pool_size,
+                        account_key=account_key,
+                    )
+                    if item.memory_id not in seen_ids
+                )
+        candidates = self._visible_to_agent(
+            self._within_clearance(
+                self._apply_filters(
+                    records=[record for record in records if record.memory_id != exclude_id],
+                    entity_id=entity_id,
+                    event_type=event_type,
+                    start_time=time_range.start if time_range else None,
+                    end_time=time_range.end if time_range else None,
+                ),
+                max_sensitivity,
+            ),
+            agent_id,
+        )
+        scored: list[tuple[MemoryRecord, float]] = []
+        for record in candidates:
+            embedding = np.asarray(record.semantic_embedding, dtype=np.float32)
+            if embedding.shape == query_embedding.shape:
+                scored.append((record, cosine_similarity(query_embedding, embedding)))
+        scored.sort(key=lambda item: item[1], reverse=True)
+        if min_score is not None:
+            scored = [item for item in scored if item[1] >= min_score]
+        return scored[:limit], len(candidates)
+
+    def feedback(
+        self,
+        request: FeedbackRequest,
+        *,
+        account_key: str | None = None,
+    ) -> FeedbackResponse:
+        start = perf_counter()
+        normalized_account_key = self._normalize_account_key(account_key)
+        existing = self._engine.storage.fetch_by_ids(
+            [request.memory_id],
+            account_key=normalized_account_key,
+        )
+        if not existing:
+            msg = f"memory_id {request.memory_id} was not found"
+            raise KeyError(msg)
+
+        outcome_signal = (
+            request.outcome_value
+            if request.outcome_value is not None
+            else (1.0 if request.helpful else -1.0)
+        )
+        helpful_ids = [request.memory_id] if request.helpful else []
+        self._engine.record_feedback(
+            query=f"memory:{request.memory_id}",
+            ranked_memory_ids=[request.memory_id],
+            helpful_memory_ids=helpful_ids,
+            outcome_signal=outcome_signal,
+            account_key=normalized_account_key,
+        )
+        self._record_memory_change(
+            "updated",
+            existing[0],
+            extra={"feedback": {"helpful": request.helpful, "outcome_signal": outcome_signal}},
+        )
+
+        latency_ms = (perf_counter() - start) * 1000.0
+        with self._state_lock:
+            self._metrics["feedback_requests_total"] += 1
+            self._metrics["feedback_latency_ms_sum"] += latency_ms
+
+        impact = (
+            "Positive signal recorded. This will improve ranking for similar queries."
+            if request.helpful
+            else "Negative signal recorded. This helps suppress low-value memories."
+        )
+        return FeedbackResponse(
+            recorded=True,
+            memory_id=request.memory_id,
+            learning_impact=impact,
+            updated_at=datetime.now(UTC),
+        )
+
+    def feedback_batch(
+        self,
+        feedback: list[FeedbackRequest],
+        *,
+        account_key: str | None = None,
+    ) -> list[FeedbackResponse]:
+        return [self.feedback(item, account_key=account_key) for item in feedback]
+
+    def status(self, account_key: str) -> StatusResponse:
+        now = datetime.now(UTC)
+        normalized_account_key = self._normalize_account_key(account_key)
+        policy = self._plan_policy(normalized_account_key)
+        with self._state_lock:
+            latest_ingestion = self._latest_ingestion
+        usage = self._read_usage_row(normalized_account_key)
+        pilot_pro_request_row = self._read_pilot_pro_request(normalized_account_key)
+        storage_mb = self._storage_usage_mb(account_key=normalized_account_key)
+        active_api_keys = self._count_active_api_keys(normalized_account_key)
+        events_month = (
+            usage.events_month
+            if usage
+            and usage.month_year == now.year
+            and usage.month_value == now.month
+            else 0
+        )
+        queries_month = (
+            usage.queries_month
+            if usage
+            and usage.month_year == now.year
+            and usage.month_value == now.month
+            else 0
+        )
+        pilot_pro_request = self._as_pilot_pro_request(
+            row=pilot_pro_request_row,
+            policy=policy,
+        )
+        metadata_summary = self._metadata_summary(normalized_account_key)
+
+        return StatusResponse(
+            connected=True,
+            api_version=self._config.api_version,
+            account_usage=AccountUsage(
+                events_ingested_this_month=events_month,
+                queries_this_month=queries_month,
+                storage_usage_mb=storage_mb,
+                active_api_keys=active_api_keys,
+                quota=AccountQuota(
+                    events_per_day=max(policy.ingest_events_per_month // 30, 1),
+                    queries_per_day=max(policy.retrieve_queries_per_month // 30, 1),
+                    events_per_month=policy.ingest_events_per_month,
+                    queries_per_month=policy.retrieve_queries_per_month,
+                    api_keys=policy.api_keys_limit,
+                    retention_days=policy.retention_days,
+                    plan=policy.plan,
+                    reset_at=datetime.fromtimestamp(
+                        self._next_month_reset_epoch(now),
+                        tz=UTC,
+                    ),
+                    warning_threshold_percent=policy.warning_threshold_percent,
+                    critical_threshold_percent=policy.critical_threshold_percent,
+                ),
+            ),
+            pilot_pro_request=pilot_pro_request,
+            latest_ingestion=latest_ingestion,
+            uptime_percent=self._config.uptime_percent,
+            metadata_summary=metadata_summary,
+        )
+
+    def tenant_metrics(self, account_key: str) -> TenantMetricsResponse:
+        now = datetime.now(UTC)
+        normalized_account_key = self._normalize_account_key(account_key)
+        policy = self._plan_policy(normalized_account_key)
+        usage = self._read_usage_row(normalized_account_key)
+        events_month = (
+            usage.events_month
+            if usage
+            and usage.month_year == now.year
+            and usage.month_value == now.month
+            else 0
+        )
+        queries_month = (
+            usage.queries_month
+            if usage
+            and usage.month_year == now.year
+            and usage.month_value == now.month
+            else 0
+        )
+        active_api_keys = self._count_active_api_keys(normalized_account_key)
+        storage_mb = self._storage_usage_mb(account_key=normalized_account_key)
+        pilot_pro_request_row = self._read_pilot_pro_request(normalized_account_key)
+        pilot_pro_requested_at = (
+            pilot_pro_request_row.requested_at if pilot_pro_request_row else None
+        )
+        pilot_pro_requested = (
+            pilot_pro_request_row is not None
+            and self._normalize_pilot_pro_status(pilot_pro_request_row.status)
+            == "requested"
+        )
+        return TenantMetricsResponse(
+            generated_at=now,
+            plan=policy.plan,
+            reset_at=datetime.fromtimestamp(
+                self._next_month_reset_epoch(now),
+                tz=UTC,
+            ),
+            warning_threshold_percent=policy.warning_threshold_percent,
+            critical_threshold_percent=policy.critical_threshold_percent,
+            ingest=self._usage_metric(
+                used=events_month,
+                limit=policy.ingest_events_per_month,
+                warning_threshold_percent=policy.warning_threshold_percent,
+                critical_threshold_percent=policy.critical_threshold_percent,
+            ),
+            retrieve=self._usage_metric(
+                used=queries_month,
+                limit=policy.retrieve_queries_per_month,
+                warning_threshold_percent=policy.warning_threshold_percent,
+                critical_threshold_percent=policy.critical_threshold_percent,
+            ),
+            api_keys=self._usage_metric(
+                used=active_api_keys,
+                limit=policy.api_keys_limit,
+                warning_threshold_percent=policy.warning_threshold_percent,
+                critical_threshold_percent=policy.critical_threshold_percent,
+            ),
+            storage_usage_mb=storage_mb,
+            pilot_pro_requested=pilot_pro_requested,
+            pilot_pro_requested_at=pilot_pro_requested_at,
+        )
+
+    def memory_quality(self, account_key: str) -> MemoryQualityResponse:
+        normalized_account_key = self._normalize_account_key(account_key)
+        now = datetime.now(UTC)
+        records = self._engine.storage.list_recent_memories(
+            account_key=normalized_account_key,
+        )
+        records_7d = [
+            record
+            for record in records
+            if (now - record.created_at).total_seconds() <= (7 * 86400)
+        ]
+        records_30d = [
+            record
+            for record in records
+            if (now - record.created_at).total_seconds() <= (30 * 86400)
+        ]
+        daily_bins_30d, series_30d = self._memory_quality_series_30d(records, now=now)
+        return MemoryQualityResponse(
+            generated_at=now,
+            window_7d=self._metadata_summary_from_records(records_7d, now=now),
+            window_30d=self._metadata_summary_from_records(records_30d, now=now),
+            daily_bins_30d=daily_bins_30d,
+            series_30d=series_30d,
+        )
+
+    def _memory_quality_series_30d(
+        self,
+        records: list[MemoryRecord],
+        *,
+        now: datetime,
+    ) -> tuple[list[str], dict[str, list[float]]]:
+        bins: list[datetime] = []
+        start_day = now.date() - timedelta(days=29)
+        for offset in range(30):
+            day = datetime.combine(
+                start_day + timedelta(days=offset),
+                datetime.min.time(),
+                tzinfo=UTC,
+            )
+            bins.append(day)
+        labels = [day.date().isoformat() for day in bins]
+
+        summary_rolling: list[MetadataSummary] = []
+        for day in bins:
+            window_records = [
+                record
+                for record in records
+                if record.created_at.date() <= day.date()
+                and (day.date() - record.created_at.date()).days <= 30
+            ]
+            summary_rolling.append(
+                self._metadata_summary_from_records(window_records, now=day)
+            )
+
+        return labels, {
+            "inferred_facts": [
+                float(item.total_inferred_facts) for item in summary_rolling
+            ],
+            "contested_ratio": [float(item.contested_ratio) for item in summary_rolling],
+            "fact_conflicts": [float(item.fact_conflict_count) for item in summary_rolling],
+            "superseded_refs": [
+                float(item.superseded_fact_references) for item in summary_rolling
+            ],
+        }
+
+    def _metadata_summary(self, account_key: str) -> MetadataSummary:
+        limit = max(1, self._config.metadata_summary_window)
+        records = self._engine.storage.list_recent_memories(
+            limit=limit,
+            account_key=account_key,
+        )
+        return self._metadata_summary_from_records(records, now=datetime.now(UTC))
+
+    def _metadata_summary_from_records(
+        self,
+        records: list[MemoryRecord],
+        *,
+        now: datetime,
     ) -> MetadataSummary:
         total = 0
         contested = 0
@@ -1158,364 +5657,3321 @@ class OrbitApiService:
                 inference_type=inference_type,
             ):
                 continue
-            intent = record.intent.strip().lower()
-            if intent not in {"inferred_user_fact", "inferred_user_fact_conflict"}:
+            intent = record.intent.strip().lower()
+            if intent not in {"inferred_user_fact", "inferred_user_fact_conflict"}:
+                continue
+            total += 1
+            age_days = max((now - record.created_at).total_seconds() / 86400.0, 0.0)
+            total_age_days += age_days
+            if intent == "inferred_user_fact_conflict":
+                conflict_guards += 1
+                contested += 1
+                fact_conflict_count += 1
+                fact_key = self._relationship_value(relationships, prefix="fact_key:")
+                if fact_key:
+                    family = self._fact_family(fact_key)
+                    fact_family_counts[family] += 1
+                continue
+            fact_key = self._relationship_value(relationships, prefix="fact_key:")
+            if fact_key:
+                family = self._fact_family(fact_key)
+                fact_family_counts[family] += 1
+                if family in {"weight_current", "weight_target"}:
+                    mutable_numeric_facts += 1
+            fact_status = self._relationship_value(relationships, prefix="fact_status:") or ""
+            clarification_required = (
+                self._relationship_value(relationships, prefix="clarification_required:") == "true"
+            )
+            superseded_fact_references += len(
+                self._relationship_values(relationships, prefix="supersedes:")
+            )
+            if fact_status == "contested" or clarification_required:
+                contested += 1
+                fact_conflict_count += 1
+            else:
+                confirmed += 1
+        average_age = (total_age_days / total) if total else 0.0
+        contested_ratio = (contested / total) if total else 0.0
+        conflict_guard_ratio = (conflict_guards / total) if total else 0.0
+        return MetadataSummary(
+            total_inferred_facts=total,
+            confirmed_facts=confirmed,
+            contested_facts=contested,
+            conflict_guards=conflict_guards,
+            contested_ratio=contested_ratio,
+            conflict_guard_ratio=conflict_guard_ratio,
+            average_fact_age_days=average_age,
+            fact_family_coverage=len(fact_family_counts),
+            fact_family_counts=dict(sorted(fact_family_counts.items())),
+            fact_conflict_count=fact_conflict_count,
+            superseded_fact_references=superseded_fact_references,
+            mutable_numeric_facts=mutable_numeric_facts,
+        )
+
+    @staticmethod
+    def _fact_family(fact_key: str) -> str:
+        normalized = fact_key.strip().lower()
+        if ":" not in normalized:
+            return normalized
+        return normalized.split(":", 1)[0]
+
+    @staticmethod
+    def _usage_metric(
+        *,
+        used: int,
+        limit: int | None,
+        warning_threshold_percent: int,
+        critical_threshold_percent: int,
+    ) -> TenantUsageMetric:
+        if limit is None or limit <= 0:
+            return TenantUsageMetric(
+                used=max(0, int(used)),
+                limit=None,
+                remaining=None,
+                utilization_percent=0.0,
+                status="ok",
+            )
+        normalized_used = max(0, int(used))
+        normalized_limit = int(limit)
+        remaining = max(normalized_limit - normalized_used, 0)
+        utilization_percent = min(
+            100.0,
+            max(0.0, (float(normalized_used) / float(normalized_limit)) * 100.0),
+        )
+        status = "ok"
+        if normalized_used >= normalized_limit:
+            status = "limit"
+        elif utilization_percent >= float(critical_threshold_percent):
+            status = "critical"
+        elif utilization_percent >= float(warning_threshold_percent):
+            status = "warning"
+        return TenantUsageMetric(
+            used=normalized_used,
+            limit=normalized_limit,
+            remaining=remaining,
+            utilization_percent=utilization_percent,
+            status=status,
+        )
+
+    def request_pilot_pro(
+        self,
+        *,
+        account_key: str,
+        actor_subject: str | None,
+        actor_email: str | None = None,
+        actor_name: str | None = None,
+    ) -> PilotProRequestResponse:
+        normalized_account_key = self._normalize_account_key(account_key)
+        normalized_actor_subject = (
+            self._normalize_optional_actor_subject(actor_subject)
+            or normalized_account_key
+        )
+        normalized_actor_email = self._normalize_optional_email(actor_email)
+        normalized_actor_name = self._normalize_optional_display_name(actor_name)
+        created = False
+        should_send_email = False
+
+        with self._state_session_factory() as session, session.begin():
+            row = self._select_pilot_pro_request_for_update(
+                session=session,
+                account_key=normalized_account_key,
+            )
+            now = datetime.now(UTC)
+            if row is None:
+                row = ApiPilotProRequestRow(
+                    account_key=normalized_account_key,
+                    status="requested",
+                    requested_by_subject=normalized_actor_subject,
+                    requested_by_email=normalized_actor_email,
+                    requested_by_name=normalized_actor_name,
+                    requested_at=now,
+                    email_sent_at=None,
+                    email_last_attempt_at=None,
+                    email_delivery_error=None,
+                    created_at=now,
+                    updated_at=now,
+                )
+                session.add(row)
+                created = True
+                should_send_email = True
+            else:
+                prior_status = self._normalize_pilot_pro_status(row.status)
+                if prior_status != "requested":
+                    row.status = "requested"
+                    row.requested_at = now
+                    row.email_sent_at = None
+                    row.email_last_attempt_at = None
+                    row.email_delivery_error = None
+                    should_send_email = True
+                row.requested_by_subject = normalized_actor_subject
+                row.requested_by_email = normalized_actor_email or row.requested_by_email
+                row.requested_by_name = normalized_actor_name or row.requested_by_name
+                row.updated_at = now
+
+            self._insert_audit_row(
+                session=session,
+                account_key=normalized_account_key,
+                actor_subject=normalized_actor_subject,
+                actor_type="dashboard_user",
+                action="pilot_pro_requested",
+                target_type="account",
+                target_id=normalized_account_key,
+                metadata={
+                    "created": created,
+                    "requested_by_email": normalized_actor_email,
+                    "requested_by_name": normalized_actor_name,
+                },
+            )
+            session.flush()
+
+        email_sent = False
+        if should_send_email:
+            email_sent, delivery_error, attempted_at = self._send_pilot_pro_request_email(
+                account_key=normalized_account_key,
+                actor_subject=normalized_actor_subject,
+                actor_email=normalized_actor_email,
+                actor_name=normalized_actor_name,
+            )
+            with self._state_session_factory() as session, session.begin():
+                row = self._select_pilot_pro_request_for_update(
+                    session=session,
+                    account_key=normalized_account_key,
+                )
+                if row is not None:
+                    row.email_last_attempt_at = attempted_at
+                    row.email_delivery_error = delivery_error
+                    if email_sent:
+                        row.email_sent_at = attempted_at
+                    row.updated_at = datetime.now(UTC)
+                    self._insert_audit_row(
+                        session=session,
+                        account_key=normalized_account_key,
+                        actor_subject=normalized_actor_subject,
+                        actor_type="system",
+                        action=(
+                            "pilot_pro_request_email_sent"
+                            if email_sent
+                            else "pilot_pro_request_email_failed"
+                        ),
+                        target_type="account",
+                        target_id=normalized_account_key,
+                        metadata={
+                            "delivery_error": delivery_error,
+                        },
+                    )
+        else:
+            existing = self._read_pilot_pro_request(normalized_account_key)
+            email_sent = bool(existing and existing.email_sent_at is not None)
+
+        row = self._read_pilot_pro_request(normalized_account_key)
+        return PilotProRequestResponse(
+            request=self._as_pilot_pro_request(
+                row=row,
+                policy=self._plan_policy(normalized_account_key),
+            ),
+            created=created,
+            email_sent=email_sent,
+        )
+
+    def health(self) -> dict[str, str]:
+        try:
+            self._engine.memory_count()
+        except Exception as exc:  # pylint: disable=broad-exception-caught
+            return {
+                "status": "degraded",
+                "version": self._config.api_version,
+                "storage": "error",
+                "detail": str(exc),
+            }
+        health = {
+            "status": "ok",
+            "version": self._config.api_version,
+            "storage": "ok",
+        }
+        for role, provider in self._engine.provider_health().items():
+            health[f"{role}_provider"] = provider.get("status", "ok")
+            if provider.get("status", "ok") != "ok":
+                health["status"] = "degraded"
+                health.setdefault("detail", provider.get("detail", ""))
+        return health
+
+    def validate_token(self, auth: AuthContext) -> AuthValidationResponse:
+        return AuthValidationResponse(valid=True, scopes=auth.scopes)
+
+    def record_http_response(self, status_code: int) -> None:
+        with self._state_lock:
+            self._http_status_counts[status_code] = (
+                self._http_status_counts.get(status_code, 0.0) + 1.0
+            )
+
+    def record_dashboard_auth_failure(self) -> None:
+        with self._state_lock:
+            self._metrics["dashboard_auth_failures_total"] += 1
+
+    def record_dashboard_key_rotation_failure(self) -> None:
+        with self._state_lock:
+            self._metrics["dashboard_key_rotation_failures_total"] += 1
+
+    def metrics_text(self) -> str:
+        with self._state_lock:
+            ingest_total = self._metrics["ingest_requests_total"]
+            retrieve_total = self._metrics["retrieve_requests_total"]
+            retrieve_degraded = self._metrics["retrieve_degraded_total"]
+            retrieve_fast_total = self._metrics["retrieve_fast_requests_total"]
+            retrieve_fast_hits = self._metrics["retrieve_fast_cache_hits_total"]
+            feedback_total = self._metrics["feedback_requests_total"]
+            dashboard_auth_failures = self._metrics["dashboard_auth_failures_total"]
+            key_rotation_failures = self._metrics[
+                "dashboard_key_rotation_failures_total"
+            ]
+            promotions_rejected = self._metrics["working_memory_promotions_rejected_total"]
+            backfill_failures = self._metrics["backfill_consolidation_failures_total"]
+            moderation_withheld = self._metrics["moderation_withheld_total"]
+            status_counts = dict(self._http_status_counts)
+        flash_metrics = self._engine.flash_metrics_snapshot()
+        lines = [
+            "# HELP orbit_ingest_requests_total Total ingest requests.",
+            "# TYPE orbit_ingest_requests_total counter",
+            f"orbit_ingest_requests_total {ingest_total:.0f}",
+            "# HELP orbit_retrieve_requests_total Total retrieve requests.",
+            "# TYPE orbit_retrieve_requests_total counter",
+            f"orbit_retrieve_requests_total {retrieve_total:.0f}",
+            "# HELP orbit_retrieve_degraded_total Retrievals that hit max_latency_ms and skipped stages.",
+            "# TYPE orbit_retrieve_degraded_total counter",
+            f"orbit_retrieve_degraded_total {retrieve_degraded:.0f}",
+            "# HELP orbit_retrieve_fast_requests_total Total fast=true retrieve requests.",
+            "# TYPE orbit_retrieve_fast_requests_total counter",
+            f"orbit_retrieve_fast_requests_total {retrieve_fast_total:.0f}",
+            "# HELP orbit_retrieve_fast_cache_hits_total fast=true retrievals served from cache.",
+            "# TYPE orbit_retrieve_fast_cache_hits_total counter",
+            f"orbit_retrieve_fast_cache_hits_total {retrieve_fast_hits:.0f}",
+            "# HELP orbit_retrieve_fast_latency_p99_ms p99 latency of recent fast=true retrievals.",
+            "# TYPE orbit_retrieve_fast_latency_p99_ms gauge",
+            f"orbit_retrieve_fast_latency_p99_ms {self._fast_latency_p99_ms():.3f}",
+            "# HELP orbit_feedback_requests_total Total feedback requests.",
+            "# TYPE orbit_feedback_requests_total counter",
+            f"orbit_feedback_requests_total {feedback_total:.0f}",
+            "# HELP orbit_dashboard_auth_failures_total Dashboard auth failures observed by API.",
+            "# TYPE orbit_dashboard_auth_failures_total counter",
+            f"orbit_dashboard_auth_failures_total {dashboard_auth_failures:.0f}",
+            "# HELP orbit_dashboard_key_rotation_failures_total Dashboard key-rotation failures.",
+            "# TYPE orbit_dashboard_key_rotation_failures_total counter",
+            f"orbit_dashboard_key_rotation_failures_total {key_rotation_failures:.0f}",
+            "# HELP orbit_working_memory_promotions_rejected_total Session items refused.",
+            "# TYPE orbit_working_memory_promotions_rejected_total counter",
+            f"orbit_working_memory_promotions_rejected_total {promotions_rejected:.0f}",
+            "# HELP orbit_backfill_consolidation_failures_total Failed backfill consolidations.",
+            "# TYPE orbit_backfill_consolidation_failures_total counter",
+            f"orbit_backfill_consolidation_failures_total {backfill_failures:.0f}",
+            "# HELP orbit_moderation_withheld_total Blocked events whose content was withheld.",
+            "# TYPE orbit_moderation_withheld_total counter",
+            f"orbit_moderation_withheld_total {moderation_withheld:.0f}",
+            "# HELP orbit_uptime_seconds Process uptime in seconds.",
+            "# TYPE orbit_uptime_seconds gauge",
+            f"orbit_uptime_seconds {self._uptime_seconds():.3f}",
+            "# HELP orbit_flash_pipeline_mode_async Flash pipeline mode (1 async, 0 sync).",
+            "# TYPE orbit_flash_pipeline_mode_async gauge",
+            f"orbit_flash_pipeline_mode_async {flash_metrics['mode_async']:.0f}",
+            "# HELP orbit_flash_pipeline_workers Number of flash pipeline workers.",
+            "# TYPE orbit_flash_pipeline_workers gauge",
+            f"orbit_flash_pipeline_workers {flash_metrics['workers']:.0f}",
+            "# HELP orbit_flash_pipeline_queue_depth Pending flash pipeline tasks.",
+            "# TYPE orbit_flash_pipeline_queue_depth gauge",
+            f"orbit_flash_pipeline_queue_depth {flash_metrics['queue_depth']:.0f}",
+            "# HELP orbit_flash_pipeline_queue_capacity Flash pipeline queue capacity.",
+            "# TYPE orbit_flash_pipeline_queue_capacity gauge",
+            f"orbit_flash_pipeline_queue_capacity {flash_metrics['queue_capacity']:.0f}",
+            "# HELP orbit_flash_pipeline_enqueued_total Total flash tasks enqueued.",
+            "# TYPE orbit_flash_pipeline_enqueued_total counter",
+            f"orbit_flash_pipeline_enqueued_total {flash_metrics['enqueued_total']:.0f}",
+            "# HELP orbit_flash_pipeline_dropped_total Total flash tasks dropped due to queue pressure.",
+            "# TYPE orbit_flash_pipeline_dropped_total counter",
+            f"orbit_flash_pipeline_dropped_total {flash_metrics['dropped_total']:.0f}",
+            "# HELP orbit_flash_pipeline_runs_total Total flash tasks executed.",
+            "# TYPE orbit_flash_pipeline_runs_total counter",
+            f"orbit_flash_pipeline_runs_total {flash_metrics['runs_total']:.0f}",
+            "# HELP orbit_flash_pipeline_maintenance_total Total flash maintenance cycles.",
+            "# TYPE orbit_flash_pipeline_maintenance_total counter",
+            f"orbit_flash_pipeline_maintenance_total {flash_metrics['maintenance_total']:.0f}",
+            "# HELP orbit_flash_pipeline_failures_total Total flash pipeline task failures.",
+            "# TYPE orbit_flash_pipeline_failures_total counter",
+            f"orbit_flash_pipeline_failures_total {flash_metrics['failures_total']:.0f}",
+        ]
+        lines.extend(
+            [
+                "# HELP orbit_http_responses_total API responses by status code.",
+                "# TYPE orbit_http_responses_total counter",
+            ]
+        )
+        for status_code in sorted(status_counts):
+            lines.append(
+                f'orbit_http_responses_total{{status_code="{status_code}"}} '
+                f"{status_counts[status_code]:.0f}"
+            )
+        stage_metrics = self._pipeline.metrics_snapshot()
+        for name, help_text, attribute in (
+            ("runs_total", "Ingest pipeline stage executions.", "runs"),
+            ("halts_total", "Events a pipeline stage stopped from going further.", "halts"),
+            ("failures_total", "Ingest pipeline stage errors.", "failures"),
+        ):
+            lines.extend(
+                [
+                    f"# HELP orbit_pipeline_stage_{name} {help_text}",
+                    f"# TYPE orbit_pipeline_stage_{name} counter",
+                ]
+            )
+            for stage, metrics in stage_metrics.items():
+                lines.append(
+                    f'orbit_pipeline_stage_{name}{{stage="{stage}"}} '
+                    f"{getattr(metrics, attribute):.0f}"
+                )
+        lines.extend(
+            [
+                "# HELP orbit_pipeline_stage_latency_ms_sum Time spent in each pipeline stage.",
+                "# TYPE orbit_pipeline_stage_latency_ms_sum counter",
+            ]
+        )
+        for stage, metrics in stage_metrics.items():
+            lines.append(
+                f'orbit_pipeline_stage_latency_ms_sum{{stage="{stage}"}} '
+                f"{metrics.latency_ms_sum:.3f}"
+            )
+        return "\n".join(lines) + "\n"
+
+    def list_memories(
+        self,
+        limit: int,
+        cursor: str | None,
+        *,
+        account_key: str | None = None,
+        entity_id: str | None = None,
+        max_sensitivity: str | None = None,
+        agent_id: str | None = None,
+        all_agents: bool = False,
+    ) -> PaginatedMemoriesResponse:
+        """Newest-first page of memories; ``all_agents`` also lists other agents' private ones."""
+        offset = 0
+        if cursor:
+            try:
+                offset = max(0, int(cursor))
+            except ValueError:
+                offset = 0
+
+        records = self._within_clearance(
+            self._engine.storage.list_memories(
+                account_key=self._normalize_account_key(account_key)
+            ),
+            max_sensitivity,
+        )
+        if not all_agents:
+            records = self._visible_to_agent(records, agent_id)
+        records = sorted(
+            (record for record in records if not entity_id or entity_id in record.entities),
+            key=lambda item: item.created_at,
+            reverse=True,
+        )
+        selected = records[offset : offset + limit]
+        data = [
+            self._as_memory(
+                record,
+                rank_position=offset + idx + 1,
+                rank_score=float(record.latest_importance),
+            )
+            for idx, record in enumerate(selected)
+        ]
+        next_offset = offset + limit
+        has_more = next_offset < len(records)
+        return PaginatedMemoriesResponse(
+            data=data,
+            cursor=str(next_offset) if has_more else None,
+            has_more=has_more,
+        )
+
+    def attach_embeddings(self, memories: list[Memory], *, account_key: str | None = None) -> None:
+        """Set each stored memory's semantic embedding on it, for ``fields=embedding``.
+
+        Session working-memory items have no stored embedding and keep ``None``.
+        """
+        records = {
+            record.memory_id: record
+            for record in self._engine.storage.fetch_by_ids(
+                [memory.memory_id for memory in memories],
+                account_key=self._normalize_account_key(account_key),
+            )
+        }
+        for memory in memories:
+            record = records.get(memory.memory_id)
+            if record is not None:
+                memory.embedding = [float(value) for value in record.semantic_embedding]
+
+    def _merge_working_memory(
+        self,
+        request: RetrieveRequest,
+        memories: list[Memory],
+        *,
+        account_key: str,
+    ) -> list[Memory]:
+        session_id = self._normalize_session_id(request.session_id or "")
+        items = [
+            item
+            for item in self._working_memory.list(account_key, session_id)
+            if (not request.entity_id or item.entity_id == request.entity_id)
+            and (not request.event_type or item.event_type == request.event_type)
+        ]
+        scored = score_working_memory(request.query, items, limit=request.limit)
+        if not scored:
+            return memories
+        session_memories = [
+            Memory(
+                memory_id=item.item_id,
+                content=item.content,
+                rank_position=0,
+                rank_score=score,
+                importance_score=item.importance,
+                timestamp=item.created_at,
+                metadata={
+                    **item.metadata,
+                    "tier": "working",
+                    "session_id": item.session_id,
+                    "intent": item.event_type,
+                    "entities": [item.entity_id],
+                },
+                relevance_explanation="Matched session working memory by query terms and recency.",
+            )
+            for item, score in scored
+        ]
+        long_term = [
+            memory.model_copy(update={"metadata": {**memory.metadata, "tier": "long_term"}})
+            for memory in memories
+        ]
+        merged = sorted(
+            [*session_memories, *long_term],
+            key=lambda memory: memory.rank_score,
+            reverse=True,
+        )[: request.limit]
+        recalled = [memory.memory_id for memory in merged if memory.metadata["tier"] == "working"]
+        self._working_memory.mark_recalled(account_key, session_id, recalled)
+        return [
+            memory.model_copy(update={"rank_position": index})
+            for index, memory in enumerate(merged, start=1)
+        ]
+
+    @staticmethod
+    def _as_session_item(item: WorkingMemoryItem) -> SessionMemoryItem:
+        return SessionMemoryItem(
+            item_id=item.item_id,
+            session_id=item.session_id,
+            content=item.content,
+            event_type=item.event_type,
+            entity_id=item.entity_id,
+            importance=item.importance,
+            recall_count=item.recall_count,
+            created_at=item.created_at,
+            metadata=item.metadata,
+        )
+
+    @staticmethod
+    def _normalize_session_id(session_id: str) -> str:
+        normalized = session_id.strip()
+        if not normalized or len(normalized) > 128:
+            msg = "session_id must be 1-128 characters"
+            raise ValueError(msg)
+        return normalized
+
+    def entity_attributes(
+        self,
+        entity_id: str,
+        *,
+        account_key: str | None = None,
+    ) -> EntityAttributesResponse:
+        normalized_entity_id = self._normalize_entity_id(entity_id)
+        with self._state_session_factory() as session:
+            row = session.execute(
+                select(ApiEntityAttributeRow)
+                .where(
+                    ApiEntityAttributeRow.account_key
+                    == self._normalize_account_key(account_key)
+                )
+                .where(ApiEntityAttributeRow.entity_id == normalized_entity_id)
+            ).scalar_one_or_none()
+        if row is None:
+            return EntityAttributesResponse(
+                entity_id=normalized_entity_id,
+                attributes={},
+                sources={},
+            )
+        return self._as_entity_attributes(row)
+
+    def patch_entity_attributes(
+        self,
+        entity_id: str,
+        request: EntityAttributesPatchRequest,
+        *,
+        account_key: str | None = None,
+        expected_version: int | None = None,
+    ) -> EntityAttributesResponse:
+        """Apply a merge patch; manually set attributes are no longer overwritten by inference."""
+        return self._update_entity_attributes(
+            account_key=self._normalize_account_key(account_key),
+            entity_id=self._normalize_entity_id(entity_id),
+            source="manual",
+            changes=lambda _attributes, _sources: request.attributes,
+            expected_version=expected_version,
+        )
+
+    def _apply_fact_to_attributes(self, operation: str, memory: MemoryRecord) -> None:
+        if operation not in {"created", "deleted"} or not memory.entities:
+            return
+        fact = self._fact_inference_metadata(memory)
+        if fact is None or fact["status"] == "contested" or fact["subject"] not in {None, "user"}:
+            return
+        family, _, value = str(fact["fact_key"]).partition(":")
+        value = value.strip()
+        negative = fact["polarity"] == "negative"
+        list_key = _FACT_LIST_ATTRIBUTES.get(family)
+        value_key = _FACT_VALUE_ATTRIBUTES.get(family)
+        if not value or (list_key is None and value_key is None):
+            return
+        if operation == "deleted":
+            self._retract_fact_from_attributes(memory, family=family, value=value)
+            return
+
+        def changes(attributes: dict[str, Any], sources: dict[str, str]) -> dict[str, Any]:
+            if list_key is not None:
+                if sources.get(list_key) == "manual":
+                    return {}
+                current = attributes.get(list_key)
+                items = [str(item) for item in current] if isinstance(current, list) else []
+                if negative:
+                    updated = [item for item in items if item != value]
+                else:
+                    updated = items if value in items else [*items, value]
+                if updated == items:
+                    return {}
+                return {list_key: updated or None}
+            if negative or value_key is None or sources.get(value_key) == "manual":
+                return {}
+            return {} if attributes.get(value_key) == value else {value_key: value}
+
+        try:
+            self._update_entity_attributes(
+                account_key=self._normalize_account_key(memory.account_key),
+                entity_id=memory.entities[0],
+                source=f"inferred:{memory.memory_id}",
+                changes=changes,
+            )
+        except ValueError:
+            # A full profile must not fail the ingest that produced the fact.
+            return
+
+    def _retract_fact_from_attributes(
+        self,
+        memory: MemoryRecord,
+        *,
+        family: str,
+        value: str,
+    ) -> None:
+        """Recompute what a deleted fact fed into its entity's profile from the facts left.
+
+        A list value stays while the latest remaining fact about it is positive; a single
+        value the deleted fact set falls back to the latest remaining positive fact of its
+        family, or is removed. Manually set attributes are never touched.
+        """
+        account_key = self._normalize_account_key(memory.account_key)
+        entity_id = memory.entities[0]
+        entity_ids_fn = getattr(self._engine, "memory_ids_for_entity", None)
+        memory_ids = (
+            entity_ids_fn(entity_id, account_key=account_key) if callable(entity_ids_fn) else []
+        )
+        remaining: list[tuple[MemoryRecord, str, bool]] = []
+        for record in self._engine.storage.fetch_by_ids(memory_ids, account_key=account_key):
+            fact = self._fact_inference_metadata(record)
+            if (
+                record.memory_id == memory.memory_id
+                or fact is None
+                or fact["status"] == "contested"
+                or fact["subject"] not in {None, "user"}
+            ):
+                continue
+            record_family, _, record_value = str(fact["fact_key"]).partition(":")
+            if record_family == family and record_value.strip():
+                remaining.append((record, record_value.strip(), fact["polarity"] != "negative"))
+        remaining.sort(key=lambda item: _as_utc(item[0].created_at))
+        list_key = _FACT_LIST_ATTRIBUTES.get(family)
+        value_key = _FACT_VALUE_ATTRIBUTES.get(family)
+        if list_key is not None:
+            same_value = [item for item in remaining if item[1] == value]
+            survivor = same_value[-1] if same_value else None
+        else:
+            positives = [item for item in remaining if item[2]]
+            survivor = positives[-1] if positives else None
+        deleted_source = f"inferred:{memory.memory_id}"
+
+        def changes(attributes: dict[str, Any], sources: dict[str, str]) -> dict[str, Any]:
+            if list_key is not None:
+                if sources.get(list_key) == "manual":
+                    return {}
+                current = attributes.get(list_key)
+                items = [str(item) for item in current] if isinstance(current, list) else []
+                keep = survivor is not None and survivor[2]
+                if keep == (value in items):
+                    return {}
+                updated = [*items, value] if keep else [item for item in items if item != value]
+                return {list_key: updated or None}
+            if value_key is None or sources.get(value_key) != deleted_source:
+                return {}
+            return {value_key: survivor[1] if survivor is not None else None}
+
+        try:
+            self._update_entity_attributes(
+                account_key=account_key,
+                entity_id=entity_id,
+                source=f"inferred:{survivor[0].memory_id}" if survivor else deleted_source,
+                changes=changes,
+            )
+        except ValueError:
+            return
+
+    def _update_entity_attributes(
+        self,
+        *,
+        account_key: str,
+        entity_id: str,
+        source: str,
+        changes: Callable[[dict[str, Any], dict[str, str]], dict[str, Any]],
+        expected_version: int | None = None,
+    ) -> EntityAttributesResponse:
+        try:
+            return self._write_entity_attributes(
+                account_key=account_key,
+                entity_id=entity_id,
+                source=source,
+                changes=changes,
+                expected_version=expected_version,
+            )
+        except IntegrityError:
+            # A concurrent first write created the profile; the retry locks that row, so the
+            # version check and merge see it.
+            return self._write_entity_attributes(
+                account_key=account_key,
+                entity_id=entity_id,
+                source=source,
+                changes=changes,
+                expected_version=expected_version,
+            )
+
+    def _write_entity_attributes(
+        self,
+        *,
+        account_key: str,
+        entity_id: str,
+        source: str,
+        changes: Callable[[dict[str, Any], dict[str, str]], dict[str, Any]],
+        expected_version: int | None,
+    ) -> EntityAttributesResponse:
+        with self._state_session_factory() as session, session.begin():
+            row = session.execute(
+                select(ApiEntityAttributeRow)
+                .where(ApiEntityAttributeRow.account_key == account_key)
+                .where(ApiEntityAttributeRow.entity_id == entity_id)
+                .with_for_update()
+            ).scalar_one_or_none()
+            current_version = row.version if row is not None else 0
+            if expected_version is not None and expected_version != current_version:
+                raise PreconditionFailedError(current_version=current_version)
+            attributes: dict[str, Any] = json.loads(row.attributes_json) if row else {}
+            sources: dict[str, str] = json.loads(row.sources_json) if row else {}
+            patch = changes(attributes, sources)
+            if not patch:
+                if row is None:
+                    return EntityAttributesResponse(entity_id=entity_id, attributes={}, sources={})
+                return self._as_entity_attributes(row)
+            for key, value in patch.items():
+                if value is None:
+                    attributes.pop(key, None)
+                    sources.pop(key, None)
+                else:
+                    attributes[key] = value
+                    sources[key] = source
+            if len(attributes) > self._config.max_entity_attributes:
+                msg = (
+                    "entity profile exceeds ORBIT_MAX_ENTITY_ATTRIBUTES="
+                    f"{self._config.max_entity_attributes}"
+                )
+                raise ValueError(msg)
+            attributes_json = json.dumps(attributes, ensure_ascii=True, sort_keys=True)
+            if len(attributes_json) > _MAX_ENTITY_ATTRIBUTES_BYTES:
+                msg = f"entity profile must serialize to at most {_MAX_ENTITY_ATTRIBUTES_BYTES} bytes"
+                raise ValueError(msg)
+            if row is None:
+                row = ApiEntityAttributeRow(account_key=account_key, entity_id=entity_id)
+                session.add(row)
+            row.attributes_json = attributes_json
+            row.sources_json = json.dumps(sources, ensure_ascii=True, sort_keys=True)
+            row.version = current_version + 1
+            row.updated_at = datetime.now(UTC)
+            return self._as_entity_attributes(row)
+
+    @staticmethod
+    def _as_entity_attributes(row: ApiEntityAttributeRow) -> EntityAttributesResponse:
+        return EntityAttributesResponse(
+            entity_id=row.entity_id,
+            attributes=json.loads(row.attributes_json),
+            sources=json.loads(row.sources_json),
+            updated_at=row.updated_at,
+            version=row.version or 0,
+        )
+
+    @staticmethod
+    def _normalize_entity_id(entity_id: str) -> str:
+        normalized = entity_id.strip()
+        if not normalized or len(normalized) > 255:
+            msg = "entity_id must be 1-255 characters"
+            raise ValueError(msg)
+        return normalized
+
+    def entity_topics(
+        self,
+        entity_id: str,
+        *,
+        refresh: bool = False,
+        account_key: str | None = None,
+        max_sensitivity: str | None = None,
+        agent_id: str | None = None,
+    ) -> EntityTopicsResponse:
+        """Topics among the entity's memories the caller is cleared for and its agent can read."""
+        normalized_entity_id = self._normalize_entity_id(entity_id)
+        computed_at, clusters = self._topic_clusters(
+            normalized_entity_id,
+            refresh=refresh,
+            account_key=self._normalize_account_key(account_key),
+            max_sensitivity=max_sensitivity,
+            agent_id=agent_id,
+        )
+        return EntityTopicsResponse(
+            entity_id=normalized_entity_id,
+            topics=[
+                Topic(
+                    topic_id=cluster.topic_id,
+                    label=cluster.label,
+                    summary=cluster.summary,
+                    keywords=list(cluster.keywords),
+                    memory_count=len(cluster.memory_ids),
+                    memory_ids=list(cluster.memory_ids),
+                )
+                for cluster in clusters
+            ],
+            algorithm=clustering_algorithm(),
+            computed_at=computed_at,
+        )
+
+    def _topic_memory_ids(
+        self,
+        entity_id: str,
+        topic_id: str,
+        *,
+        account_key: str,
+        max_sensitivity: str | None,
+        agent_id: str | None,
+    ) -> set[str]:
+        _, clusters = self._topic_clusters(
+            self._normalize_entity_id(entity_id),
+            refresh=False,
+            account_key=account_key,
+            max_sensitivity=max_sensitivity,
+            agent_id=agent_id,
+        )
+        for cluster in clusters:
+            if cluster.topic_id == topic_id:
+                return set(cluster.memory_ids)
+        msg = f"topic {topic_id} not found for entity {entity_id}"
+        raise KeyError(msg)
+
+    def _topic_clusters(
+        self,
+        entity_id: str,
+        *,
+        refresh: bool,
+        account_key: str,
+        max_sensitivity: str | None,
+        agent_id: str | None,
+    ) -> tuple[datetime, list[TopicCluster]]:
+        """Cached clusters for an entity, recomputed after ORBIT_TOPIC_REFRESH_SECONDS."""
+        cache_key = (account_key, entity_id, max_sensitivity or "", agent_id or "")
+        now = datetime.now(UTC)
+        with self._state_lock:
+            cached = self._topic_cache.get(cache_key)
+        if (
+            cached is not None
+            and not refresh
+            and (now - cached[0]).total_seconds() < self._config.topic_refresh_seconds
+        ):
+            return cached
+        entity_ids_fn = getattr(self._engine, "memory_ids_for_entity", None)
+        memory_ids = (
+            entity_ids_fn(entity_id, account_key=account_key)
+            if callable(entity_ids_fn)
+            else []
+        )
+        # Filter before clustering: labels and summaries quote the medoid memory's content.
+        records = self._visible_to_agent(
+            self._within_clearance(
+                self._engine.storage.fetch_by_ids(memory_ids, account_key=account_key),
+                max_sensitivity,
+            ),
+            agent_id,
+        )
+        clusters = cluster_memories(
+            records,
+            min_cluster_size=self._config.topic_min_cluster_size,
+        )
+        with self._state_lock:
+            self._topic_cache[cache_key] = (now, clusters)
+        return now, clusters
+
+    def _evict_changed_from_topics(self, operation: str, memory: MemoryRecord) -> None:
+        if operation == "created":
+            return
+        account_key = self._normalize_account_key(memory.account_key)
+        entity_ids = set(memory.entities)
+        with self._state_lock:
+            for cache_key in list(self._topic_cache):
+                if cache_key[0] == account_key and cache_key[1] in entity_ids:
+                    del self._topic_cache[cache_key]
+
+    def entity_goals(
+        self,
+        entity_id: str,
+        *,
+        status: str | None = None,
+        account_key: str | None = None,
+    ) -> EntityGoalsResponse:
+        normalized_entity_id = self._normalize_entity_id(entity_id)
+        if status is not None and status not in GOAL_STATUSES:
+            msg = f"status must be one of: {', '.join(GOAL_STATUSES)}"
+            raise ValueError(msg)
+        query = (
+            select(ApiGoalRow)
+            .where(ApiGoalRow.account_key == self._normalize_account_key(account_key))
+            .where(ApiGoalRow.entity_id == normalized_entity_id)
+            .order_by(ApiGoalRow.created_at, ApiGoalRow.id)
+        )
+        if status is not None:
+            query = query.where(ApiGoalRow.status == status)
+        with self._state_session_factory() as session:
+            rows = session.execute(query).scalars().all()
+        today = datetime.now(UTC).date()
+        return EntityGoalsResponse(
+            entity_id=normalized_entity_id,
+            goals=[
+                Goal(
+                    goal_id=row.id,
+                    title=row.title,
+                    status=row.status,
+                    due_date=row.due_date,
+                    overdue=(
+                        row.due_date is not None and row.due_date < today and row.status != "done"
+                    ),
+                    memory_id=row.memory_id,
+                    progress=[GoalProgress(**item) for item in json.loads(row.progress_json)],
+                    created_at=_as_utc(row.created_at),
+                    updated_at=_as_utc(row.updated_at),
+                )
+                for row in rows
+            ],
+        )
+
+    def _track_goals(self, operation: str, memory: MemoryRecord) -> None:
+        """Open goals the user commits to and advance them on later progress reports."""
+        if not memory.entities or memory.intent.startswith("assistant_"):
+            return
+        account_key = self._normalize_account_key(memory.account_key)
+        entity_id = memory.entities[0]
+        if operation == "deleted":
+            self._forget_goal_memory(memory, account_key=account_key, entity_id=entity_id)
+            return
+        if operation != "created":
+            return
+        progress = extract_progress(memory.content)
+        goal = extract_goal(memory.content, today=_as_utc(memory.event_time).date())
+        if progress is None and goal is None:
+            return
+        now = datetime.now(UTC)
+        with self._state_session_factory() as session, session.begin():
+            if progress is not None:
+                status, note = progress
+                rows = {
+                    row.id: row
+                    for row in session.execute(
+                        select(ApiGoalRow)
+                        .where(ApiGoalRow.account_key == account_key)
+                        .where(ApiGoalRow.entity_id == entity_id)
+                        .where(ApiGoalRow.status != "done")
+                    ).scalars()
+                }
+                goal_id = match_goal(note, {key: row.title for key, row in rows.items()})
+                if goal_id is not None:
+                    row = rows[goal_id]
+                    row.status = status
+                    row.progress_json = json.dumps(
+                        [
+                            *json.loads(row.progress_json),
+                            {
+                                "memory_id": memory.memory_id,
+                                "status": status,
+                                "note": note,
+                                "recorded_at": now.isoformat(),
+                            },
+                        ]
+                    )
+                    row.updated_at = now
+            if goal is not None:
+                session.add(
+                    ApiGoalRow(
+                        id=f"goal_{uuid4().hex[:16]}",
+                        account_key=account_key,
+                        entity_id=entity_id,
+                        memory_id=memory.memory_id,
+                        title=goal.title,
+                        status="open",
+                        due_date=goal.due_date,
+                        progress_json="[]",
+                        created_at=now,
+                        updated_at=now,
+                    )
+                )
+
+    def _forget_goal_memory(
+        self,
+        memory: MemoryRecord,
+        *,
+        account_key: str,
+        entity_id: str,
+    ) -> None:
+        # A deleted memory takes the goal it stated, or its progress note, with it.
+        with self._state_session_factory() as session, session.begin():
+            for row in session.execute(
+                select(ApiGoalRow)
+                .where(ApiGoalRow.account_key == account_key)
+                .where(ApiGoalRow.entity_id == entity_id)
+            ).scalars():
+                if row.memory_id == memory.memory_id:
+                    session.delete(row)
+                    continue
+                progress = json.loads(row.progress_json)
+                kept = [item for item in progress if item["memory_id"] != memory.memory_id]
+                if len(kept) != len(progress):
+                    row.progress_json = json.dumps(kept)
+                    row.status = kept[-1]["status"] if kept else "open"
+
+    def entity_conflicts(
+        self,
+        entity_id: str,
+        *,
+        account_key: str | None = None,
+        max_sensitivity: str | None = None,
+        agent_id: str | None = None,
+    ) -> EntityConflictsResponse:
+        """Contradictory facts about an entity that no later statement or deletion resolved.
+
+        A confirmed change ("the doctor confirmed I'm no longer allergic") supersedes and
+        deletes the older fact, so every conflict whose facts all still exist is unresolved.
+        Facts and sources the caller is not cleared for, or its agent cannot read, are left out.
+        """
+        normalized_entity_id = self._normalize_entity_id(entity_id)
+        visible = self._visible_to_agent(
+            self._within_clearance(
+                self._engine.storage.list_memories(
+                    account_key=self._normalize_account_key(account_key)
+                ),
+                max_sensitivity,
+            ),
+            agent_id,
+        )
+        records = {
+            record.memory_id: record
+            for record in visible
+            if normalized_entity_id in record.entities
+        }
+        groups: dict[tuple[str, str], dict[str, MemoryRecord]] = {}
+        for record in records.values():
+            if record.intent != "inferred_user_fact":
                 continue
-            total += 1
-            age_days = max((now - record.created_at).total_seconds() / 86400.0, 0.0)
-            total_age_days += age_days
-            if intent == "inferred_user_fact_conflict":
-                conflict_guards += 1
-                contested += 1
-                fact_conflict_count += 1
-                fact_key = self._relationship_value(relationships, prefix="fact_key:")
-                if fact_key:
-                    family = self._fact_family(fact_key)
-                    fact_family_counts[family] += 1
+            rivals = [
+                records[memory_id]
+                for memory_id in self._relationship_values(record.relationships, "conflicts_with:")
+                if memory_id in records
+            ]
+            fact = self._fact_inference_metadata(record)
+            if not rivals or fact is None:
                 continue
-            fact_key = self._relationship_value(relationships, prefix="fact_key:")
-            if fact_key:
-                family = self._fact_family(fact_key)
-                fact_family_counts[family] += 1
-                if family in {"weight_current", "weight_target"}:
-                    mutable_numeric_facts += 1
-            fact_status = self._relationship_value(relationships, prefix="fact_status:") or ""
-            clarification_required = (
-                self._relationship_value(relationships, prefix="clarification_required:") == "true"
+            group = groups.setdefault((fact["subject"] or "user", fact["fact_key"]), {})
+            for item in (record, *rivals):
+                group[item.memory_id] = item
+        conflicts: list[MemoryConflict] = []
+        for (subject, fact_key), members in groups.items():
+            ordered = sorted(members.values(), key=lambda item: _as_utc(item.created_at))
+            facts: list[ConflictingFact] = []
+            clarification_required = False
+            for record in ordered:
+                fact = self._fact_inference_metadata(record) or {}
+                clarification_required |= bool(fact.get("clarification_required"))
+                source_id = self._relationship_value(record.relationships, "derived_from:")
+                source = records.get(source_id) if source_id else None
+                facts.append(
+                    ConflictingFact(
+                        memory_id=record.memory_id,
+                        statement=record.summary or record.content,
+                        polarity=fact.get("polarity"),
+                        status=fact.get("status"),
+                        created_at=_as_utc(record.created_at),
+                        source_memory_id=source.memory_id if source else None,
+                        source_content=source.content if source else None,
+                    )
+                )
+            conflicts.append(
+                MemoryConflict(
+                    subject=subject,
+                    fact_key=fact_key,
+                    fact_type=(self._fact_inference_metadata(ordered[-1]) or {}).get("fact_type"),
+                    clarification_required=clarification_required,
+                    facts=facts,
+                    detected_at=facts[-1].created_at,
+                )
+            )
+        conflicts.sort(key=lambda item: item.detected_at, reverse=True)
+        return EntityConflictsResponse(entity_id=normalized_entity_id, conflicts=conflicts)
+
+    def share_memories(
+        self,
+        entity_id: str,
+        request: MemoryShareRequest,
+        *,
+        account_key: str | None = None,
+    ) -> MemoryShare:
+        """Let another entity (or every member of a group) retrieve some of this entity's memories.
+
+        Shared memories keep their owner; retrieval for the target also returns them, marked
+        with ``shared_from``, until the share is revoked.
+        """
+        normalized_account_key = self._normalize_account_key(account_key)
+        source_entity_id = self._normalize_entity_id(entity_id)
+        target_type = "entity" if request.target_entity_id is not None else "group"
+        target_id = self._normalize_entity_id(
+            request.target_entity_id or request.target_group_id or ""
+        )
+        if target_type == "entity" and target_id == source_entity_id:
+            msg = "an entity cannot share memories with itself"
+            raise ValueError(msg)
+        if request.memory_ids:
+            records = self._engine.storage.fetch_by_ids(
+                request.memory_ids,
+                account_key=normalized_account_key,
+            )
+            owned = {
+                record.memory_id for record in records if source_entity_id in record.entities
+            }
+            missing = [item for item in request.memory_ids if item not in owned]
+            if missing:
+                msg = f"memories not found for entity {source_entity_id}: {', '.join(missing)}"
+                raise KeyError(msg)
+        with self._state_session_factory() as session:
+            row = ApiMemoryShareRow(
+                account_key=normalized_account_key,
+                source_entity_id=source_entity_id,
+                target_type=target_type,
+                target_id=target_id,
+                memory_ids_json=json.dumps(request.memory_ids),
+                tags_json=json.dumps(request.tags),
+                created_at=datetime.now(UTC),
+            )
+            session.add(row)
+            session.commit()
+            return self._as_memory_share(row)
+
+    def memory_shares(
+        self,
+        entity_id: str,
+        *,
+        direction: str = "outgoing",
+        include_revoked: bool = False,
+        account_key: str | None = None,
+    ) -> MemoryShareListResponse:
+        """Shares an entity granted (``outgoing``) or receives directly or via groups."""
+        normalized_account_key = self._normalize_account_key(account_key)
+        normalized_entity_id = self._normalize_entity_id(entity_id)
+        query = select(ApiMemoryShareRow).where(
+            ApiMemoryShareRow.account_key == normalized_account_key
+        )
+        if direction == "outgoing":
+            query = query.where(ApiMemoryShareRow.source_entity_id == normalized_entity_id)
+        else:
+            query = query.where(
+                self._share_target_clause(
+                    normalized_entity_id,
+                    account_key=normalized_account_key,
+                )
+            )
+        if not include_revoked:
+            query = query.where(ApiMemoryShareRow.revoked_at.is_(None))
+        with self._state_session_factory() as session:
+            rows = session.scalars(query.order_by(ApiMemoryShareRow.id.desc())).all()
+            return MemoryShareListResponse(data=[self._as_memory_share(row) for row in rows])
+
+    def revoke_memory_share(
+        self,
+        share_id: int,
+        *,
+        account_key: str | None = None,
+    ) -> MemoryShare:
+        """Stop sharing; revoking an already revoked share returns it unchanged."""
+        with self._state_session_factory() as session:
+            row = session.get(ApiMemoryShareRow, share_id)
+            if row is None or row.account_key != self._normalize_account_key(account_key):
+                msg = f"memory share not found: {share_id}"
+                raise KeyError(msg)
+            if row.revoked_at is None:
+                row.revoked_at = datetime.now(UTC)
+                session.commit()
+            return self._as_memory_share(row)
+
+    def set_entity_group(
+        self,
+        group_id: str,
+        request: EntityGroupRequest,
+        *,
+        account_key: str | None = None,
+    ) -> EntityGroupResponse:
+        """Replace a group's members; an empty list dissolves the group."""
+        normalized_account_key = self._normalize_account_key(account_key)
+        normalized_group_id = self._normalize_entity_id(group_id)
+        entity_ids = [self._normalize_entity_id(item) for item in request.entity_ids]
+        with self._state_session_factory() as session:
+            session.execute(
+                delete(ApiEntityGroupMemberRow).where(
+                    ApiEntityGroupMemberRow.account_key == normalized_account_key,
+                    ApiEntityGroupMemberRow.group_id == normalized_group_id,
+                )
+            )
+            now = datetime.now(UTC)
+            session.add_all(
+                ApiEntityGroupMemberRow(
+                    account_key=normalized_account_key,
+                    group_id=normalized_group_id,
+                    entity_id=item,
+                    created_at=now,
+                )
+                for item in entity_ids
+            )
+            session.commit()
+        return EntityGroupResponse(group_id=normalized_group_id, entity_ids=entity_ids)
+
+    def entity_group(
+        self,
+        group_id: str,
+        *,
+        account_key: str | None = None,
+    ) -> EntityGroupResponse:
+        normalized_group_id = self._normalize_entity_id(group_id)
+        with self._state_session_factory() as session:
+            entity_ids = session.scalars(
+                select(ApiEntityGroupMemberRow.entity_id)
+                .where(
+                    ApiEntityGroupMemberRow.account_key
+                    == self._normalize_account_key(account_key),
+                    ApiEntityGroupMemberRow.group_id == normalized_group_id,
+                )
+                .order_by(ApiEntityGroupMemberRow.id)
+            ).all()
+        if not entity_ids:
+            msg = f"group not found: {normalized_group_id}"
+            raise KeyError(msg)
+        return EntityGroupResponse(group_id=normalized_group_id, entity_ids=list(entity_ids))
+
+    def _share_target_clause(self, entity_id: str, *, account_key: str) -> Any:
+        groups = (
+            select(ApiEntityGroupMemberRow.group_id)
+            .where(
+                ApiEntityGroupMemberRow.account_key == account_key,
+                ApiEntityGroupMemberRow.entity_id == entity_id,
+            )
+            .scalar_subquery()
+        )
+        return or_(
+            and_(
+                ApiMemoryShareRow.target_type == "entity",
+                ApiMemoryShareRow.target_id == entity_id,
+            ),
+            and_(
+                ApiMemoryShareRow.target_type == "group",
+                ApiMemoryShareRow.target_id.in_(groups),
+            ),
+        )
+
+    def _shared_records(
+        self,
+        entity_id: str,
+        *,
+        account_key: str,
+    ) -> dict[str, tuple[MemoryRecord, str]]:
+        """Memories other entities currently share with ``entity_id``, keyed by memory id."""
+        shares = self.memory_shares(
+            entity_id,
+            direction="incoming",
+            account_key=account_key,
+        ).data
+        if not shares:
+            return {}
+        entity_ids_fn = getattr(self._engine, "memory_ids_for_entity", None)
+        shared: dict[str, tuple[MemoryRecord, str]] = {}
+        for share in shares:
+            candidate_ids = set(share.memory_ids)
+            if share.tags and callable(entity_ids_fn):
+                candidate_ids.update(entity_ids_fn(share.source_entity_id, account_key=account_key))
+            wanted_tags = {f"tag:{tag}" for tag in share.tags}
+            for record in self._engine.storage.fetch_by_ids(
+                sorted(candidate_ids),
+                account_key=account_key,
+            ):
+                # A memory moved off the source entity is no longer the source's to share.
+                if share.source_entity_id not in record.entities:
+                    continue
+                if record.memory_id in share.memory_ids or wanted_tags.intersection(
+                    record.relationships
+                ):
+                    shared.setdefault(record.memory_id, (record, share.source_entity_id))
+        return shared
+
+    @staticmethod
+    def _as_memory_share(row: ApiMemoryShareRow) -> MemoryShare:
+        return MemoryShare(
+            share_id=row.id,
+            source_entity_id=row.source_entity_id,
+            target_type=row.target_type,
+            target_id=row.target_id,
+            memory_ids=json.loads(row.memory_ids_json),
+            tags=json.loads(row.tags_json),
+            created_at=_as_utc(row.created_at),
+            revoked_at=_as_utc(row.revoked_at) if row.revoked_at is not None else None,
+        )
+
+    def link_memories(
+        self,
+        memory_id: str,
+        request: MemoryLinkRequest,
+        *,
+        account_key: str | None = None,
+    ) -> MemoryLink:
+        """Record that ``memory_id`` <link_type> ``request.target_memory_id``.
+
+        Linking the same pair with the same type again returns the existing link.
+        """
+        normalized_account_key = self._normalize_account_key(account_key)
+        target_memory_id = request.target_memory_id
+        if target_memory_id == memory_id:
+            msg = "a memory cannot be linked to itself"
+            raise ValueError(msg)
+        found = {
+            record.memory_id
+            for record in self._engine.storage.fetch_by_ids(
+                [memory_id, target_memory_id],
+                account_key=normalized_account_key,
+            )
+        }
+        missing = [item for item in (memory_id, target_memory_id) if item not in found]
+        if missing:
+            msg = f"memories not found: {', '.join(missing)}"
+            raise KeyError(msg)
+        existing = select(ApiMemoryLinkRow).where(
+            ApiMemoryLinkRow.account_key == normalized_account_key,
+            ApiMemoryLinkRow.source_memory_id == memory_id,
+            ApiMemoryLinkRow.target_memory_id == target_memory_id,
+            ApiMemoryLinkRow.link_type == request.link_type,
+        )
+        with self._state_session_factory() as session:
+            row = session.scalars(existing).first()
+            if row is not None:
+                return self._as_memory_link(row)
+            row = ApiMemoryLinkRow(
+                account_key=normalized_account_key,
+                source_memory_id=memory_id,
+                target_memory_id=target_memory_id,
+                link_type=request.link_type,
+                created_at=datetime.now(UTC),
+            )
+            session.add(row)
+            try:
+                session.commit()
+            except IntegrityError:
+                # A concurrent request created the same link first.
+                session.rollback()
+                return self._as_memory_link(session.scalars(existing).one())
+            return self._as_memory_link(row)
+
+    def memory_links(
+        self,
+        memory_id: str,
+        *,
+        account_key: str | None = None,
+    ) -> MemoryLinkListResponse:
+        """Links from and to ``memory_id``, newest first."""
+        normalized_account_key = self._normalize_account_key(account_key)
+        if not self._engine.storage.fetch_by_ids([memory_id], account_key=normalized_account_key):
+            msg = f"memory_id {memory_id} was not found"
+            raise KeyError(msg)
+        with self._state_session_factory() as session:
+            rows = session.scalars(
+                select(ApiMemoryLinkRow)
+                .where(
+                    ApiMemoryLinkRow.account_key == normalized_account_key,
+                    or_(
+                        ApiMemoryLinkRow.source_memory_id == memory_id,
+                        ApiMemoryLinkRow.target_memory_id == memory_id,
+                    ),
+                )
+                .order_by(ApiMemoryLinkRow.id.desc())
+            ).all()
+            return MemoryLinkListResponse(data=[self._as_memory_link(row) for row in rows])
+
+    def delete_memory_link(
+        self,
+        memory_id: str,
+        link_id: int,
+        *,
+        account_key: str | None = None,
+    ) -> MemoryLink:
+        """Remove a link; either of its memories can be used to address it."""
+        with self._state_session_factory() as session:
+            row = session.get(ApiMemoryLinkRow, link_id)
+            if (
+                row is None
+                or row.account_key != self._normalize_account_key(account_key)
+                or memory_id not in (row.source_memory_id, row.target_memory_id)
+            ):
+                msg = f"memory link not found: {link_id}"
+                raise KeyError(msg)
+            link = self._as_memory_link(row)
+            session.delete(row)
+            session.commit()
+            return link
+
+    def _linked_memories(
+        self,
+        memories: list[Memory],
+        *,
+        account_key: str,
+        max_sensitivity: str | None,
+        agent_id: str | None,
+        entity_id: str | None,
+        limit: int,
+    ) -> list[Memory]:
+        """Memories one link away from ``memories``, in the order of what they link to.
+
+        Each is marked with ``linked`` naming the retrieved memory it came through, the link
+        type, and whether the link points away from (``outgoing``) or to that memory. Links
+        never reach past the request's ``entity_id``.
+        """
+        rank = {memory.memory_id: position for position, memory in enumerate(memories)}
+        if not rank:
+            return []
+        with self._state_session_factory() as session:
+            rows = session.scalars(
+                select(ApiMemoryLinkRow)
+                .where(
+                    ApiMemoryLinkRow.account_key == account_key,
+                    or_(
+                        ApiMemoryLinkRow.source_memory_id.in_(list(rank)),
+                        ApiMemoryLinkRow.target_memory_id.in_(list(rank)),
+                    ),
+                )
+                .order_by(ApiMemoryLinkRow.id)
+            ).all()
+        hops: dict[str, tuple[int, dict[str, str]]] = {}
+        for row in rows:
+            for origin, neighbor, direction in (
+                (row.source_memory_id, row.target_memory_id, "outgoing"),
+                (row.target_memory_id, row.source_memory_id, "incoming"),
+            ):
+                if origin not in rank or neighbor in rank:
+                    continue
+                if neighbor not in hops or rank[origin] < hops[neighbor][0]:
+                    hops[neighbor] = (
+                        rank[origin],
+                        {"from": origin, "link_type": row.link_type, "direction": direction},
+                    )
+        if not hops:
+            return []
+        records = self._within_clearance(
+            self._apply_filters(
+                records=self._engine.storage.fetch_by_ids(sorted(hops), account_key=account_key),
+                entity_id=entity_id,
+                event_type=None,
+                start_time=None,
+                end_time=None,
+            ),
+            max_sensitivity,
+        )
+        records = self._visible_to_agent(records, agent_id)
+        records.sort(key=lambda record: hops[record.memory_id][0])
+        linked: list[Memory] = []
+        for position, record in enumerate(records[:limit], start=len(memories) + 1):
+            memory = self._as_memory(record, rank_position=position, rank_score=0.0)
+            memory.metadata["linked"] = hops[record.memory_id][1]
+            memory.relevance_explanation = "Linked to a retrieved memory."
+            linked.append(memory)
+        return linked
+
+    @staticmethod
+    def _as_memory_link(row: ApiMemoryLinkRow) -> MemoryLink:
+        return MemoryLink(
+            link_id=row.id,
+            source_memory_id=row.source_memory_id,
+            target_memory_id=row.target_memory_id,
+            link_type=row.link_type,
+            created_at=_as_utc(row.created_at),
+        )
+
+    def admin_tenants(self) -> AdminTenantListResponse:
+        """Every account with stored memories, usage, or API keys (operator view)."""
+        now = datetime.now(UTC)
+        memory_counts: dict[str, int] = {}
+        last_memory_at: dict[str, datetime] = {}
+        for record in self._engine.storage.list_memories():
+            account_key = self._normalize_account_key(record.account_key)
+            memory_counts[account_key] = memory_counts.get(account_key, 0) + 1
+            latest = last_memory_at.get(account_key)
+            if latest is None or record.created_at > latest:
+                last_memory_at[account_key] = record.created_at
+        with self._state_session_factory() as session:
+            usage_rows = {
+                row.account_key: row for row in session.scalars(select(ApiAccountUsageRow))
+            }
+            key_accounts = set(session.scalars(select(ApiKeyRow.account_key).distinct()))
+        tenants: list[AdminTenant] = []
+        for account_key in sorted({*memory_counts, *usage_rows, *key_accounts}):
+            usage = usage_rows.get(account_key)
+            current_month = (
+                usage is not None
+                and usage.month_year == now.year
+                and usage.month_value == now.month
             )
-            superseded_fact_references += len(
-                self._relationship_values(relationships, prefix="supersedes:")
+            activity = [
+                _as_utc(item)
+                for item in (last_memory_at.get(account_key), usage.updated_at if usage else None)
+                if item is not None
+            ]
+            tenants.append(
+                AdminTenant(
+                    account_key=account_key,
+                    plan=self._plan_policy(account_key).plan,
+                    memory_count=memory_counts.get(account_key, 0),
+                    active_api_keys=self._count_active_api_keys(account_key),
+                    events_this_month=usage.events_month if usage and current_month else 0,
+                    queries_this_month=usage.queries_month if usage and current_month else 0,
+                    last_activity_at=max(activity) if activity else None,
+                )
             )
-            if fact_status == "contested" or clarification_required:
-                contested += 1
-                fact_conflict_count += 1
-            else:
-                confirmed += 1
-        average_age = (total_age_days / total) if total else 0.0
-        contested_ratio = (contested / total) if total else 0.0
-        conflict_guard_ratio = (conflict_guards / total) if total else 0.0
-        return MetadataSummary(
-            total_inferred_facts=total,
-            confirmed_facts=confirmed,
-            contested_facts=contested,
-            conflict_guards=conflict_guards,
-            contested_ratio=contested_ratio,
-            conflict_guard_ratio=conflict_guard_ratio,
-            average_fact_age_days=average_age,
-            fact_family_coverage=len(fact_family_counts),
-            fact_family_counts=dict(sorted(fact_family_counts.items())),
-            fact_conflict_count=fact_conflict_count,
-            superseded_fact_references=superseded_fact_references,
-            mutable_numeric_facts=mutable_numeric_facts,
+        return AdminTenantListResponse(data=tenants)
+
+    def admin_entities(self, account_key: str) -> AdminEntityListResponse:
+        normalized_account_key = self._normalize_account_key(account_key)
+        counts: dict[str, int] = {}
+        latest: dict[str, datetime] = {}
+        for record in self._engine.storage.list_memories(account_key=normalized_account_key):
+            if not record.entities:
+                continue
+            entity_id = record.entities[0]
+            counts[entity_id] = counts.get(entity_id, 0) + 1
+            if entity_id not in latest or record.created_at > latest[entity_id]:
+                latest[entity_id] = record.created_at
+        entities = sorted(
+            (
+                AdminEntity(
+                    entity_id=entity_id,
+                    memory_count=count,
+                    last_memory_at=latest[entity_id],
+                )
+                for entity_id, count in counts.items()
+            ),
+            key=lambda item: (-item.memory_count, item.entity_id),
+        )
+        return AdminEntityListResponse(account_key=normalized_account_key, data=entities)
+
+    def admin_anomalies(
+        self,
+        *,
+        account_key: str | None = None,
+        kind: str | None = None,
+        limit: int = 50,
+    ) -> AdminAnomalyListResponse:
+        query = select(ApiIngestionAnomalyRow)
+        if account_key:
+            query = query.where(
+                ApiIngestionAnomalyRow.account_key == self._normalize_account_key(account_key)
+            )
+        if kind:
+            query = query.where(ApiIngestionAnomalyRow.kind == kind)
+        with self._state_session_factory() as session:
+            rows = session.scalars(
+                query.order_by(ApiIngestionAnomalyRow.id.desc()).limit(limit)
+            ).all()
+        return AdminAnomalyListResponse(data=[self._as_admin_anomaly(row) for row in rows])
+
+    def _observe_ingestion(
+        self,
+        *,
+        account_key: str,
+        key_id: str | None,
+        contents: list[str],
+    ) -> None:
+        if not self._config.anomaly_detection_enabled:
+            return
+        anomalies = self._anomaly_detector.observe(
+            account_key=self._normalize_account_key(account_key),
+            # JWT callers and connectors have no API key; they share one baseline per account.
+            key_id=key_id or _ACCOUNT_ANOMALY_KEY_ID,
+            contents=contents,
         )
+        if not anomalies:
+            return
+        webhook_url = self._config.anomaly_webhook_url
+        with self._state_session_factory() as session:
+            rows = [self._anomaly_row(item, webhook_url=webhook_url) for item in anomalies]
+            session.add_all(rows)
+            session.commit()
+            alerts = [self._as_admin_anomaly(row) for row in rows]
+        if webhook_url:
+            for alert in alerts:
+                submit_with_context(
+                    self._webhook_executor,
+                    self._deliver_anomaly_webhook,
+                    alert,
+                    webhook_url=webhook_url,
+                )
 
     @staticmethod
-    def _fact_family(fact_key: str) -> str:
-        normalized = fact_key.strip().lower()
-        if ":" not in normalized:
-            return normalized
-        return normalized.split(":", 1)[0]
+    def _anomaly_row(
+        anomaly: IngestionAnomaly,
+        *,
+        webhook_url: str | None,
+    ) -> ApiIngestionAnomalyRow:
+        return ApiIngestionAnomalyRow(
+            account_key=anomaly.account_key,
+            key_id=anomaly.key_id,
+            kind=anomaly.kind,
+            detail_json=json.dumps(anomaly.detail, ensure_ascii=True),
+            webhook_status="pending" if webhook_url else "skipped",
+            detected_at=anomaly.detected_at,
+        )
+
+    def _deliver_anomaly_webhook(self, alert: AdminAnomaly, *, webhook_url: str) -> None:
+        delivered = _post_webhook(
+            webhook_url,
+            "ingestion_anomaly",
+            alert.model_dump(mode="json", exclude={"webhook_status"}),
+            secret=self._config.anomaly_webhook_secret,
+        )
+        with self._state_session_factory() as session:
+            row = session.get(ApiIngestionAnomalyRow, alert.id)
+            if row is not None:
+                row.webhook_status = "delivered" if delivered else "failed"
+                session.commit()
 
     @staticmethod
-    def _usage_metric(
+    def _as_admin_anomaly(row: ApiIngestionAnomalyRow) -> AdminAnomaly:
+        return AdminAnomaly(
+            id=row.id,
+            account_key=row.account_key,
+            key_id=row.key_id,
+            kind=row.kind,
+            detail=json.loads(row.detail_json),
+            webhook_status=row.webhook_status,
+            detected_at=_as_utc(row.detected_at),
+        )
+
+    def admin_metrics(self) -> AdminMetricsResponse:
+        with self._state_lock:
+            requests = dict(self._metrics)
+            status_counts = dict(self._http_status_counts)
+        requests["retrieve_fast_latency_p99_ms"] = self._fast_latency_p99_ms()
+        flash_metrics = self._engine.flash_metrics_snapshot()
+        vector_store = getattr(self._engine, "vector_store", None)
+        namespace_stats = getattr(vector_store, "namespace_stats", None)
+        return AdminMetricsResponse(
+            generated_at=datetime.now(UTC),
+            uptime_seconds=self._uptime_seconds(),
+            requests=requests,
+            flash_pipeline={key: float(value) for key, value in flash_metrics.items()},
+            http_responses={
+                str(status_code): count for status_code, count in sorted(status_counts.items())
+            },
+            vector_index=[
+                VectorIndexNamespace.model_validate(item)
+                for item in (namespace_stats() if callable(namespace_stats) else [])
+            ],
+        )
+
+    def tenant_residency(self, account_key: str) -> TenantResidency:
+        normalized_account_key = self._normalize_account_key(account_key)
+        with self._state_session_factory() as session:
+            row = session.get(ApiTenantResidencyRow, normalized_account_key)
+            if row is None:
+                msg = f"no residency configured for account: {normalized_account_key}"
+                raise KeyError(msg)
+            return self._as_tenant_residency(row)
+
+    def set_tenant_residency(
+        self,
+        account_key: str,
+        request: TenantResidencyRequest,
         *,
-        used: int,
-        limit: int | None,
-        warning_threshold_percent: int,
-        critical_threshold_percent: int,
-    ) -> TenantUsageMetric:
-        if limit is None or limit <= 0:
-            return TenantUsageMetric(
-                used=max(0, int(used)),
-                limit=None,
-                remaining=None,
-                utilization_percent=0.0,
-                status="ok",
+        propagate: bool = True,
+    ) -> TenantResidency:
+        """Pin a tenant to a home region and its read replicas.
+
+        The setting is pushed to every peer so any region can route the tenant's requests;
+        ``propagated_to`` lists the peers that accepted it.
+        """
+        local_region = self._config.region
+        if local_region is None:
+            msg = "multi-region routing is not configured (set ORBIT_REGION)"
+            raise ValueError(msg)
+        known_regions = {local_region, *self._config.region_peers}
+        unknown = sorted(
+            region
+            for region in (request.home_region, *request.replica_regions)
+            if region not in known_regions
+        )
+        if unknown:
+            msg = f"unknown regions: {', '.join(unknown)}"
+            raise ValueError(msg)
+        replica_regions = [
+            region for region in request.replica_regions if region != request.home_region
+        ]
+        normalized_account_key = self._normalize_account_key(account_key)
+        with self._state_session_factory() as session:
+            row = session.get(ApiTenantResidencyRow, normalized_account_key)
+            if row is None:
+                row = ApiTenantResidencyRow(account_key=normalized_account_key)
+                session.add(row)
+            row.home_region = request.home_region
+            row.replica_regions_json = json.dumps(replica_regions)
+            row.pinned = request.pinned
+            row.updated_at = datetime.now(UTC)
+            session.commit()
+            residency = self._as_tenant_residency(row)
+        if not propagate:
+            return residency
+        body = request.model_copy(update={"replica_regions": replica_regions})
+        propagated_to = [
+            region
+            for region, base_url in sorted(self._config.region_peers.items())
+            if self._replication_request(
+                base_url,
+                "PUT",
+                f"/v1/internal/replication/residency/{normalized_account_key}",
+                body=body.model_dump_json().encode("utf-8"),
             )
-        normalized_used = max(0, int(used))
-        normalized_limit = int(limit)
-        remaining = max(normalized_limit - normalized_used, 0)
-        utilization_percent = min(
-            100.0,
-            max(0.0, (float(normalized_used) / float(normalized_limit)) * 100.0),
+            is not None
+        ]
+        return residency.model_copy(update={"propagated_to": propagated_to})
+
+    def region_route(self, account_key: str, *, method: str) -> RegionRoute:
+        if self._config.region is None:
+            return RegionRoute("local")
+        with self._state_session_factory() as session:
+            row = session.get(ApiTenantResidencyRow, self._normalize_account_key(account_key))
+            if row is None:
+                return RegionRoute("local")
+            return route_request(
+                local_region=self._config.region,
+                home_region=row.home_region,
+                replica_regions=json.loads(row.replica_regions_json),
+                pinned=row.pinned,
+                method=method,
+            )
+
+    def claim_replication_nonce(self, nonce: str) -> bool:
+        """Record a signed replication request's nonce; ``False`` when it was already used."""
+        return self._claim_nonce(
+            operation=_REPLICATION_NONCE_OPERATION,
+            account_key="",
+            nonce=nonce,
+            max_age_seconds=2 * REPLICATION_MAX_SKEW_SECONDS,
         )
-        status = "ok"
-        if normalized_used >= normalized_limit:
-            status = "limit"
-        elif utilization_percent >= float(critical_threshold_percent):
-            status = "critical"
-        elif utilization_percent >= float(warning_threshold_percent):
-            status = "warning"
-        return TenantUsageMetric(
-            used=normalized_used,
-            limit=normalized_limit,
-            remaining=remaining,
-            utilization_percent=utilization_percent,
-            status=status,
+
+    def _claim_nonce(
+        self,
+        *,
+        operation: str,
+        account_key: str,
+        nonce: str,
+        max_age_seconds: int,
+        now: datetime | None = None,
+    ) -> bool:
+        """Insert a single-use nonce into the shared state DB; ``False`` when it already exists.
+
+        The unique idempotency scope makes the claim atomic across workers and instances. A
+        nonce only needs remembering while its request's timestamp is still accepted, so rows
+        older than ``max_age_seconds`` are dropped.
+        """
+        current = now or datetime.now(UTC)
+        with self._state_session_factory() as session:
+            session.execute(
+                delete(ApiIdempotencyRow)
+                .where(ApiIdempotencyRow.operation == operation)
+                .where(ApiIdempotencyRow.created_at < current - timedelta(seconds=max_age_seconds))
+            )
+            session.add(
+                ApiIdempotencyRow(
+                    account_key=account_key,
+                    operation=operation,
+                    idempotency_key=nonce,
+                    request_hash=hashlib.sha256(nonce.encode("utf-8")).hexdigest(),
+                    created_at=current,
+                    updated_at=current,
+                )
+            )
+            try:
+                session.commit()
+            except IntegrityError:
+                session.rollback()
+                return False
+        return True
+
+    def replication_batch(
+        self,
+        account_key: str,
+        *,
+        cursor: str | None = None,
+    ) -> ReplicationBatch:
+        """Change-feed page plus the tenant's API key hashes, served to replica regions."""
+        normalized_account_key = self._normalize_account_key(account_key)
+        changes = self.list_changes(
+            account_key=normalized_account_key,
+            cursor=cursor,
+            limit=self._config.replication_batch_size,
+            all_agents=True,
+        )
+        with self._state_session_factory() as session:
+            key_rows = session.scalars(
+                select(ApiKeyRow).where(ApiKeyRow.account_key == normalized_account_key)
+            ).all()
+        api_keys = [
+            ReplicatedApiKey(
+                key_id=row.key_id,
+                name=row.name,
+                key_prefix=row.key_prefix,
+                secret_salt=row.secret_salt,
+                secret_hash=row.secret_hash,
+                hash_iterations=row.hash_iterations,
+                scopes=self._deserialize_scopes(row.scopes_json),
+                allowed_origins=json.loads(row.allowed_origins_json or "[]"),
+                status=row.status,
+                created_at=_as_utc(row.created_at),
+                revoked_at=_as_utc(row.revoked_at) if row.revoked_at else None,
+            )
+            for row in key_rows
+        ]
+        return ReplicationBatch(
+            account_key=normalized_account_key,
+            region=self._config.region or "",
+            changes=changes.data,
+            api_keys=api_keys,
+            cursor=changes.cursor,
+            has_more=changes.has_more,
+        )
+
+    def apply_replication_batch(self, batch: ReplicationBatch) -> int:
+        """Apply a home region's changes locally; returns how many changes were applied.
+
+        Replays are safe: creates of existing memories and deletes of missing ones are skipped.
+        """
+        account_key = self._normalize_account_key(batch.account_key)
+        self._upsert_replicated_api_keys(account_key, batch.api_keys)
+        applied = 0
+        for change in batch.changes:
+            existing = self._engine.storage.fetch_by_ids(
+                [change.memory_id],
+                account_key=account_key,
+            )
+            if change.operation == "deleted":
+                if existing:
+                    self._engine.delete_memories([change.memory_id], account_key=account_key)
+                    applied += 1
+                continue
+            if change.operation not in {"created", "updated"} or change.memory is None:
+                continue
+            content = str(change.memory.get("content", ""))
+            if existing:
+                if change.operation == "updated" and existing[0].content != content:
+                    self._engine.update_memory(
+                        change.memory_id,
+                        content,
+                        account_key=account_key,
+                    )
+                    applied += 1
+                continue
+            self._engine.import_memory(
+                change.memory_id,
+                content=content,
+                intent=str(change.memory.get("intent", "")),
+                entities=[str(item) for item in change.memory.get("entities", [])],
+                relationships=[str(item) for item in change.memory.get("relationships", [])],
+                storage_tier=str(change.memory.get("storage_tier", "")),
+                account_key=account_key,
+            )
+            applied += 1
+        return applied
+
+    def replicate_once(self) -> dict[str, int]:
+        """Pull pending changes for every tenant this region replicates; returns counts per tenant."""
+        local_region = self._config.region
+        if local_region is None or not self._config.region_peers:
+            return {}
+        with self._state_session_factory() as session:
+            rows = session.scalars(select(ApiTenantResidencyRow)).all()
+            tenants = [
+                (row.account_key, row.home_region)
+                for row in rows
+                if row.home_region != local_region
+                and local_region in json.loads(row.replica_regions_json)
+            ]
+        applied: dict[str, int] = {}
+        for account_key, home_region in tenants:
+            base_url = self._config.region_peers.get(home_region)
+            if base_url is None:
+                continue
+            applied[account_key] = self._replicate_tenant(
+                account_key,
+                home_region=home_region,
+                base_url=base_url,
+            )
+        return applied
+
+    def _replicate_tenant(self, account_key: str, *, home_region: str, base_url: str) -> int:
+        with self._state_session_factory() as session:
+            cursor_row = session.get(ApiReplicationCursorRow, account_key)
+            cursor = (
+                cursor_row.cursor
+                if cursor_row is not None and cursor_row.source_region == home_region
+                else None
+            )
+        applied = 0
+        while True:
+            path = f"/v1/internal/replication/{account_key}/changes"
+            if cursor:
+                path = f"{path}?cursor={cursor}"
+            payload = self._replication_request(base_url, "GET", path)
+            if payload is None:
+                break
+            batch = ReplicationBatch.model_validate(payload)
+            applied += self.apply_replication_batch(batch)
+            cursor = batch.cursor
+            with self._state_session_factory() as session:
+                cursor_row = session.get(ApiReplicationCursorRow, account_key)
+                if cursor_row is None:
+                    cursor_row = ApiReplicationCursorRow(account_key=account_key)
+                    session.add(cursor_row)
+                cursor_row.source_region = home_region
+                cursor_row.cursor = cursor
+                cursor_row.synced_at = datetime.now(UTC)
+                session.commit()
+            if not batch.has_more:
+                break
+        return applied
+
+    def _upsert_replicated_api_keys(
+        self,
+        account_key: str,
+        api_keys: list[ReplicatedApiKey],
+    ) -> None:
+        if not api_keys:
+            return
+        with self._state_session_factory() as session:
+            for key in api_keys:
+                row = session.get(ApiKeyRow, key.key_id)
+                if row is None:
+                    row = ApiKeyRow(key_id=key.key_id, account_key=account_key)
+                    session.add(row)
+                row.name = key.name
+                row.key_prefix = key.key_prefix
+                row.secret_salt = key.secret_salt
+                row.secret_hash = key.secret_hash
+                row.hash_iterations = key.hash_iterations
+                row.scopes_json = json.dumps(key.scopes)
+                row.allowed_origins_json = json.dumps(key.allowed_origins)
+                row.status = key.status
+                row.created_at = key.created_at
+                row.revoked_at = key.revoked_at
+            session.commit()
+
+    def _replication_request(
+        self,
+        base_url: str,
+        method: str,
+        path: str,
+        *,
+        body: bytes = b"",
+    ) -> dict[str, Any] | None:
+        secret = self._config.replication_secret or ""
+        target = urlparse(path)
+        headers = {
+            "Content-Type": "application/json",
+            **outbound_headers(),
+            **replication_headers(
+                secret,
+                method=method,
+                path=target.path,
+                query=target.query,
+                body=body,
+            ),
+        }
+        try:
+            with httpx.Client(timeout=_REPLICATION_TIMEOUT_SECONDS) as client:
+                response = client.request(method, f"{base_url}{path}", content=body, headers=headers)
+        except httpx.HTTPError:
+            return None
+        if response.status_code >= 300:
+            return None
+        payload = response.json()
+        return payload if isinstance(payload, dict) else None
+
+    @staticmethod
+    def _as_tenant_residency(row: ApiTenantResidencyRow) -> TenantResidency:
+        return TenantResidency(
+            account_key=row.account_key,
+            home_region=row.home_region,
+            replica_regions=json.loads(row.replica_regions_json),
+            pinned=bool(row.pinned),
+            updated_at=_as_utc(row.updated_at),
+        )
+
+    def start_optimize_job(self, *, trigger: str = "manual") -> OptimizeJob:
+        """Queue an index compaction and table maintenance run on the maintenance thread."""
+        job = self._create_optimize_job(trigger)
+        self._maintenance_executor.submit(self._run_optimize_job, job.job_id)
+        return job
+
+    def run_optimize(self, *, trigger: str = "manual") -> OptimizeJob:
+        job = self._create_optimize_job(trigger)
+        return self._run_optimize_job(job.job_id)
+
+    def optimize_job(self, job_id: str) -> OptimizeJob:
+        with self._state_lock:
+            job = self._optimize_jobs.get(job_id)
+        if job is None:
+            msg = f"optimize job not found: {job_id}"
+            raise KeyError(msg)
+        return job
+
+    def list_optimize_jobs(self) -> OptimizeJobListResponse:
+        with self._state_lock:
+            jobs = list(reversed(self._optimize_jobs.values()))
+        return OptimizeJobListResponse(jobs=jobs)
+
+    def _create_optimize_job(self, trigger: str) -> OptimizeJob:
+        with self._state_lock:
+            active = next(
+                (
+                    job
+                    for job in self._optimize_jobs.values()
+                    if job.status in {"queued", "running"}
+                ),
+                None,
+            )
+            if active is not None:
+                msg = f"optimize job {active.job_id} is already {active.status}"
+                raise ValueError(msg)
+            job = OptimizeJob(
+                job_id=f"opt_{uuid4().hex[:16]}",
+                status="queued",
+                trigger=trigger,
+                progress=0.0,
+                steps=[OptimizeStep(name=name, status="pending") for name in _OPTIMIZE_STEPS],
+                created_at=datetime.now(UTC),
+            )
+            self._optimize_jobs[job.job_id] = job
+            while len(self._optimize_jobs) > _MAX_OPTIMIZE_JOBS:
+                del self._optimize_jobs[next(iter(self._optimize_jobs))]
+        return job
+
+    def _run_optimize_job(self, job_id: str) -> OptimizeJob:
+        steps = {name: OptimizeStep(name=name, status="pending") for name in _OPTIMIZE_STEPS}
+        step_started: dict[str, float] = {}
+
+        def publish(**updates: Any) -> OptimizeJob:
+            completed = sum(1 for step in steps.values() if step.status == "succeeded")
+            with self._state_lock:
+                job = self._optimize_jobs[job_id].model_copy(
+                    update={
+                        "steps": list(steps.values()),
+                        "progress": round(completed / len(steps), 4),
+                        **updates,
+                    }
+                )
+                self._optimize_jobs[job_id] = job
+            return job
+
+        def settle(status: str) -> None:
+            for name, step in steps.items():
+                if step.status == "running":
+                    duration_ms = (perf_counter() - step_started[name]) * 1000.0
+                    steps[name] = step.model_copy(
+                        update={"status": status, "duration_ms": round(duration_ms, 3)}
+                    )
+
+        def begin(name: str) -> None:
+            settle("succeeded")
+            step_started[name] = perf_counter()
+            steps[name] = steps[name].model_copy(update={"status": "running"})
+            publish(current_step=name)
+
+        publish(status="running", started_at=datetime.now(UTC))
+        try:
+            report = self._engine.optimize(on_step=begin)
+            begin("metadata_maintenance")
+            report["metadata_maintenance"] = {"statements": self._optimize_state_tables()}
+            settle("succeeded")
+        except Exception as exc:
+            settle("failed")
+            return publish(status="failed", error=str(exc), finished_at=datetime.now(UTC))
+        for name, detail in report.items():
+            if name in steps:
+                steps[name] = steps[name].model_copy(update={"detail": detail})
+        return publish(status="succeeded", current_step=None, finished_at=datetime.now(UTC))
+
+    def _optimize_state_tables(self) -> list[str]:
+        dialect = self._state_engine.dialect.name
+        if dialect == "sqlite":
+            steps = {"vacuum": "VACUUM", "analyze": "ANALYZE"}
+        elif dialect == "postgresql":
+            steps = {"vacuum_analyze": "VACUUM (ANALYZE)"}
+        else:
+            return []
+        with self._state_engine.connect().execution_options(isolation_level="AUTOCOMMIT") as conn:
+            for statement in steps.values():
+                conn.execute(text(statement))
+        return list(steps)
+
+    def start_index_deployment(self, request: IndexDeploymentRequest) -> IndexDeployment:
+        """Build a candidate index alongside production; only one can be pending at a time."""
+        quantization = normalize_quantization_mode(request.quantization)
+        provider_name = (
+            request.embedding_provider.strip().lower() if request.embedding_provider else None
+        )
+        embedding_provider = build_embedding_provider(
+            self._engine.config.embedding_dim,
+            provider_name=provider_name,
+        )
+        with self._state_session_factory() as session:
+            pending = session.scalars(
+                select(ApiIndexDeploymentRow).where(
+                    ApiIndexDeploymentRow.status.in_(("building", "ready"))
+                )
+            ).first()
+            if pending is not None:
+                msg = f"index deployment {pending.id} is already {pending.status}"
+                raise IndexDeploymentConflictError(msg)
+            row = ApiIndexDeploymentRow(
+                id=f"idx_{uuid4().hex[:16]}",
+                status="building",
+                embedding_provider=provider_name,
+                chunk_chars=request.chunk_chars,
+                quantization=quantization,
+                mirror_percent=request.mirror_percent,
+                created_at=datetime.now(UTC),
+            )
+            session.add(row)
+            session.commit()
+            session.refresh(row)
+            self._start_shadow_build(row, embedding_provider)
+            return self._as_index_deployment(row)
+
+    def list_index_deployments(self) -> IndexDeploymentListResponse:
+        self._refresh_index_deployments(force=True)
+        with self._state_session_factory() as session:
+            rows = session.scalars(
+                select(ApiIndexDeploymentRow)
+                .order_by(ApiIndexDeploymentRow.created_at.desc())
+                .limit(_MAX_OPTIMIZE_JOBS)
+            ).all()
+            data = [self._as_index_deployment(row) for row in rows]
+        with self._state_lock:
+            serving = self._serving_index_id or "baseline"
+        return IndexDeploymentListResponse(serving=serving, data=data)
+
+    def index_deployment(self, deployment_id: str) -> IndexDeployment:
+        with self._state_session_factory() as session:
+            return self._as_index_deployment(self._index_deployment_row(session, deployment_id))
+
+    def set_index_mirror(self, deployment_id: str, mirror_percent: float) -> IndexDeployment:
+        with self._state_session_factory() as session:
+            row = self._index_deployment_row(session, deployment_id)
+            if row.status not in {"building", "ready"}:
+                msg = f"index deployment {deployment_id} is {row.status}; only candidates mirror"
+                raise IndexDeploymentConflictError(msg)
+            row.mirror_percent = mirror_percent
+            session.commit()
+            result = self._as_index_deployment(row)
+        self._refresh_index_deployments(force=True)
+        return result
+
+    def promote_index_deployment(self, deployment_id: str) -> IndexDeployment:
+        """Make a ready deployment the serving index, retiring the one it replaces.
+
+        Each process switches as one reference swap once its copy is built, so a query runs
+        entirely against one index.
+        """
+        now = datetime.now(UTC)
+        with self._state_session_factory() as session:
+            row = self._index_deployment_row(session, deployment_id)
+            if row.status != "ready":
+                msg = f"index deployment {deployment_id} is {row.status}, not ready"
+                raise IndexDeploymentConflictError(msg)
+            for active in session.scalars(
+                select(ApiIndexDeploymentRow).where(ApiIndexDeploymentRow.status == "active")
+            ):
+                active.status = "retired"
+                active.retired_at = now
+            row.status = "active"
+            row.mirror_percent = 0.0
+            row.promoted_at = now
+            session.commit()
+        self._refresh_index_deployments(force=True)
+        return self.index_deployment(deployment_id)
+
+    def retire_index_deployment(self, deployment_id: str) -> IndexDeployment:
+        """Cancel a candidate, or roll an active deployment back to the engine's own index."""
+        with self._state_session_factory() as session:
+            row = self._index_deployment_row(session, deployment_id)
+            if row.status in _LIVE_INDEX_DEPLOYMENT_STATUSES:
+                row.status = "retired" if row.status == "active" else "cancelled"
+                row.mirror_percent = 0.0
+                row.retired_at = datetime.now(UTC)
+                session.commit()
+        self._refresh_index_deployments(force=True)
+        return self.index_deployment(deployment_id)
+
+    def _index_deployment_row(self, session: Session, deployment_id: str) -> ApiIndexDeploymentRow:
+        row = session.get(ApiIndexDeploymentRow, deployment_id)
+        if row is None:
+            msg = f"index deployment not found: {deployment_id}"
+            raise KeyError(msg)
+        return row
+
+    def _as_index_deployment(self, row: ApiIndexDeploymentRow) -> IndexDeployment:
+        with self._state_lock:
+            index = self._shadow_indexes.get(row.id)
+            built = row.id in self._built_indexes
+            build_error = self._index_build_errors.get(row.id)
+        samples = row.mirrored_queries
+        comparison = IndexComparison(mirrored_queries=samples)
+        if samples:
+            comparison = IndexComparison(
+                mirrored_queries=samples,
+                mean_overlap=round(row.overlap_sum / samples, 4),
+                top_result_agreement=round(row.top_result_matches / samples, 4),
+                production_latency_ms=round(row.production_latency_ms_sum / samples, 3),
+                candidate_latency_ms=round(row.candidate_latency_ms_sum / samples, 3),
+            )
+        return IndexDeployment(
+            deployment_id=row.id,
+            status=row.status,
+            embedding_provider=row.embedding_provider,
+            chunk_chars=row.chunk_chars,
+            quantization=row.quantization,
+            mirror_percent=row.mirror_percent,
+            built=built,
+            indexed_memories=index.size if index is not None else 0,
+            comparison=comparison,
+            error=row.error or build_error,
+            created_at=_as_utc(row.created_at),
+            ready_at=_as_utc(row.ready_at) if row.ready_at else None,
+            promoted_at=_as_utc(row.promoted_at) if row.promoted_at else None,
+            retired_at=_as_utc(row.retired_at) if row.retired_at else None,
         )
 
-    def request_pilot_pro(
+    def _refresh_index_deployments(self, *, force: bool = False) -> None:
+        """Bring this process's indexes in line with the deployments in the state tables.
+
+        Deployments started or promoted elsewhere (another replica, or before a restart) are
+        built here in the background; the engine's index serves until the copy is ready.
+        """
+        now = perf_counter()
+        with self._state_lock:
+            if (
+                not force
+                and self._index_refreshed_at is not None
+                and now - self._index_refreshed_at < _INDEX_DEPLOYMENT_REFRESH_SECONDS
+            ):
+                return
+            self._index_refreshed_at = now
+        with self._state_session_factory() as session:
+            rows = session.scalars(
+                select(ApiIndexDeploymentRow).where(
+                    ApiIndexDeploymentRow.status.in_(_LIVE_INDEX_DEPLOYMENT_STATUSES)
+                )
+            ).all()
+            session.expunge_all()
+        live = {row.id: row for row in rows}
+        with self._state_lock:
+            for deployment_id in set(self._shadow_indexes) - set(live):
+                del self._shadow_indexes[deployment_id]
+                self._built_indexes.discard(deployment_id)
+            unbuilt = [row for row in rows if row.id not in self._shadow_indexes]
+        for row in unbuilt:
+            if row.id in self._index_build_errors:
+                continue
+            try:
+                embedding_provider = build_embedding_provider(
+                    self._engine.config.embedding_dim,
+                    provider_name=row.embedding_provider,
+                )
+            except Exception as exc:
+                self._index_build_failed(row.id, exc)
+                continue
+            self._start_shadow_build(row, embedding_provider)
+        with self._state_lock:
+            self._serving_index_id = next(
+                (
+                    row.id
+                    for row in rows
+                    if row.status == "active" and row.id in self._built_indexes
+                ),
+                None,
+            )
+            self._index_mirror_percents = {
+                row.id: row.mirror_percent
+                for row in rows
+                if row.status in {"building", "ready"}
+                and row.mirror_percent > 0
+                and row.id in self._built_indexes
+            }
+
+    def _start_shadow_build(
+        self, row: ApiIndexDeploymentRow, embedding_provider: EmbeddingProvider
+    ) -> None:
+        index = ShadowIndex(
+            embedding_provider,
+            index_path=f"{row.id}.idx",
+            quantization=row.quantization,
+            chunk_chars=row.chunk_chars,
+        )
+        with self._state_lock:
+            if row.id in self._shadow_indexes:
+                return
+            self._shadow_indexes[row.id] = index
+        self._index_build_executor.submit(self._build_shadow_index, row.id, index)
+
+    def _build_shadow_index(self, deployment_id: str, index: ShadowIndex) -> None:
+        # Writes made while this runs reach the index through _sync_shadow_indexes.
+        try:
+            for record in self._engine.storage.list_memories():
+                with self._state_lock:
+                    if self._shadow_indexes.get(deployment_id) is not index:
+                        return
+                index.index(record, backfill=True)
+        except Exception as exc:
+            self._index_build_failed(deployment_id, exc)
+            return
+        with self._state_lock:
+            self._built_indexes.add(deployment_id)
+        with self._state_session_factory() as session:
+            row = session.get(ApiIndexDeploymentRow, deployment_id)
+            if row is not None and row.status == "building":
+                row.status = "ready"
+                row.ready_at = datetime.now(UTC)
+                session.commit()
+        self._refresh_index_deployments(force=True)
+
+    def _index_build_failed(self, deployment_id: str, exc: Exception) -> None:
+        with self._state_lock:
+            self._shadow_indexes.pop(deployment_id, None)
+            self._index_build_errors[deployment_id] = str(exc)
+        # An active deployment keeps serving from replicas that did build it.
+        with self._state_session_factory() as session:
+            row = session.get(ApiIndexDeploymentRow, deployment_id)
+            if row is not None and row.status == "building":
+                row.status = "failed"
+                row.error = str(exc)
+                session.commit()
+
+    def _sync_shadow_indexes(self, operation: str, memory: MemoryRecord) -> None:
+        with self._state_lock:
+            indexes = list(self._shadow_indexes.items())
+        for _, index in indexes:
+            # A failure must not fail the write; the memory is just missing from the
+            # candidate, which its comparison numbers then reflect.
+            with suppress(Exception):
+                if operation == "deleted":
+                    index.remove(memory.memory_id)
+                else:
+                    index.index(memory)
+
+    def _route_index_deployments(
+        self, account_key: str
+    ) -> tuple[ShadowIndex | None, tuple[str, ShadowIndex] | None]:
+        """Return the index serving ``account_key`` and, for a sampled query, one to mirror to.
+
+        Heuristic namespaces encode queries with their own processor and stay on the engine.
+        """
+        self._refresh_index_deployments()
+        if self._engine.pipeline_mode_for(account_key) == "heuristic":
+            return None, None
+        with self._state_lock:
+            serving = (
+                self._shadow_indexes.get(self._serving_index_id)
+                if self._serving_index_id is not None
+                else None
+            )
+            mirror: tuple[str, ShadowIndex] | None = None
+            for deployment_id, percent in self._index_mirror_percents.items():
+                index = self._shadow_indexes.get(deployment_id)
+                if index is None or self._random.random() * 100.0 >= percent:
+                    continue
+                if self._index_mirror_backlog < _MAX_INDEX_MIRROR_BACKLOG:
+                    self._index_mirror_backlog += 1
+                    mirror = (deployment_id, index)
+                break
+        return serving, mirror
+
+    def _mirror_retrieve(
         self,
+        mirror: tuple[str, ShadowIndex],
+        request: RetrieveRequest,
         *,
         account_key: str,
-        actor_subject: str | None,
-        actor_email: str | None = None,
-        actor_name: str | None = None,
-    ) -> PilotProRequestResponse:
-        normalized_account_key = self._normalize_account_key(account_key)
-        normalized_actor_subject = (
-            self._normalize_optional_actor_subject(actor_subject)
-            or normalized_account_key
+        max_sensitivity: str | None,
+        production: RetrieveResponse,
+    ) -> None:
+        deployment_id, index = mirror
+        try:
+            candidate = self._retrieve(
+                request,
+                account_key=account_key,
+                max_sensitivity=max_sensitivity,
+                index=index,
+                shadow=True,
+            )
+        except Exception:
+            # Failed mirrors are not counted; production already answered.
+            return
+        finally:
+            with self._state_lock:
+                self._index_mirror_backlog -= 1
+        # Working memory and zero-result fallbacks do not come from either index.
+        production_ids = (
+            []
+            if production.fallback
+            else [
+                memory.memory_id
+                for memory in production.memories
+                if memory.metadata.get("tier") != "working"
+            ]
         )
-        normalized_actor_email = self._normalize_optional_email(actor_email)
-        normalized_actor_name = self._normalize_optional_display_name(actor_name)
-        created = False
-        should_send_email = False
+        candidate_ids = [memory.memory_id for memory in candidate.memories]
+        top_match = production_ids[:1] == candidate_ids[:1]
+        with self._state_session_factory() as session:
+            session.execute(
+                update(ApiIndexDeploymentRow)
+                .where(ApiIndexDeploymentRow.id == deployment_id)
+                .values(
+                    mirrored_queries=ApiIndexDeploymentRow.mirrored_queries + 1,
+                    overlap_sum=ApiIndexDeploymentRow.overlap_sum
+                    + result_overlap(production_ids, candidate_ids),
+                    top_result_matches=ApiIndexDeploymentRow.top_result_matches
+                    + int(top_match),
+                    production_latency_ms_sum=ApiIndexDeploymentRow.production_latency_ms_sum
+                    + production.query_execution_time_ms,
+                    candidate_latency_ms_sum=ApiIndexDeploymentRow.candidate_latency_ms_sum
+                    + candidate.query_execution_time_ms,
+                )
+            )
+            session.commit()
 
-        with self._state_session_factory() as session, session.begin():
-            row = self._select_pilot_pro_request_for_update(
-                session=session,
-                account_key=normalized_account_key,
+    def list_changes(
+        self,
+        *,
+        account_key: str | None = None,
+        cursor: str | None = None,
+        limit: int = 100,
+        max_sensitivity: str | None = None,
+        agent_id: str | None = None,
+        all_agents: bool = False,
+    ) -> ChangeFeedResponse:
+        """Changes after ``cursor``; changes to memories above ``max_sensitivity`` are left out.
+
+        So are changes to memories private to other agents, unless ``all_agents`` is set for
+        replication and warehouse sync, which mirror the whole log.
+        """
+        normalized_account_key = self._normalize_account_key(account_key)
+        after_id = self._cursor_to_offset(cursor)
+        with self._state_session_factory() as session:
+            rows = session.scalars(
+                select(ApiMemoryChangeRow)
+                .where(ApiMemoryChangeRow.account_key == normalized_account_key)
+                .where(ApiMemoryChangeRow.id > after_id)
+                .order_by(ApiMemoryChangeRow.id.asc())
+                .limit(limit + 1)
+            ).all()
+        rows = self._settled_changes(rows)
+        has_more = len(rows) > limit
+        page = rows[:limit]
+        data = [
+            change
+            for change in (self._as_memory_change(row) for row in page)
+            if self._payload_within_clearance(change.memory, max_sensitivity)
+            and (all_agents or self._payload_visible_to_agent(change.memory, agent_id))
+        ]
+        next_cursor = str(page[-1].id) if page else (cursor or None)
+        return ChangeFeedResponse(data=data, cursor=next_cursor, has_more=has_more)
+
+    def _settled_changes(self, rows: Sequence[ApiMemoryChangeRow]) -> list[ApiMemoryChangeRow]:
+        # Postgres hands out change ids before commit, so a reader can see id 12 while id 11 is
+        # still in flight; stopping at the first change younger than the settle window keeps the
+        # cursor from moving past it. SQLite serializes writers, so its ids commit in order.
+        settle = self._config.change_feed_settle_seconds
+        if settle <= 0 or self._state_engine.dialect.name == "sqlite":
+            return list(rows)
+        horizon = datetime.now(UTC) - timedelta(seconds=settle)
+        for position, row in enumerate(rows):
+            if _as_utc(row.created_at) > horizon:
+                return list(rows[:position])
+        return list(rows)
+
+    def latest_change_sequence(self, account_key: str | None = None) -> int:
+        """Sequence of the account's newest change, or 0 when nothing has changed yet."""
+        with self._state_session_factory() as session:
+            latest = session.scalar(
+                select(func.max(ApiMemoryChangeRow.id)).where(
+                    ApiMemoryChangeRow.account_key == self._normalize_account_key(account_key)
+                )
             )
-            now = datetime.now(UTC)
+        return int(latest or 0)
+
+    def memory_snapshot(self, account_key: str | None = None) -> list[tuple[str, dict[str, Any]]]:
+        """Every stored memory as ``(memory_id, payload)`` in the change feed's payload shape."""
+        records = self._engine.storage.list_memories(
+            account_key=self._normalize_account_key(account_key)
+        )
+        return [
+            (record.memory_id, self._change_payload(record))
+            for record in sorted(records, key=lambda item: item.created_at)
+        ]
+
+    @staticmethod
+    def _as_memory_change(row: ApiMemoryChangeRow) -> MemoryChange:
+        return MemoryChange(
+            sequence=int(row.id),
+            memory_id=row.memory_id,
+            operation=row.operation,
+            occurred_at=_as_utc(row.created_at),
+            memory=json.loads(row.payload_json) or None,
+        )
+
+    def create_export_job(self, account_key: str, request: ExportJobRequest) -> ExportJob:
+        """Schedule recurring exports of the account's change log to object storage."""
+        schedule = CronSchedule.parse(request.cron)
+        destination = validate_destination(
+            request.destination,
+            file_root=self._config.export_file_root,
+        )
+        now = clock.now()
+        row = ApiExportJobRow(
+            id=f"exp_{uuid4().hex[:16]}",
+            account_key=self._normalize_account_key(account_key),
+            cron=schedule.expression,
+            destination=destination,
+            format=request.format,
+            enabled=request.enabled,
+            cursor=0,
+            next_run_at=schedule.next_after(now),
+            rows_exported=0,
+            created_at=now,
+        )
+        with self._state_session_factory() as session:
+            session.add(row)
+            session.commit()
+            return self._as_export_job(row)
+
+    def list_export_jobs(self, account_key: str) -> ExportJobListResponse:
+        with self._state_session_factory() as session:
+            rows = session.scalars(
+                select(ApiExportJobRow)
+                .where(ApiExportJobRow.account_key == self._normalize_account_key(account_key))
+                .order_by(ApiExportJobRow.created_at.asc())
+            ).all()
+            return ExportJobListResponse(data=[self._as_export_job(row) for row in rows])
+
+    def delete_export_job(self, export_id: str) -> ExportJob:
+        with self._state_session_factory() as session:
+            row = session.get(ApiExportJobRow, export_id)
             if row is None:
-                row = ApiPilotProRequestRow(
+                msg = f"export job not found: {export_id}"
+                raise KeyError(msg)
+            removed = self._as_export_job(row)
+            session.delete(row)
+            session.commit()
+            return removed
+
+    def run_export_job(self, export_id: str, *, now: datetime | None = None) -> ExportJob:
+        """Run one export immediately, whether or not it is due."""
+        with self._state_session_factory() as session:
+            row = session.get(ApiExportJobRow, export_id)
+            if row is None:
+                msg = f"export job not found: {export_id}"
+                raise KeyError(msg)
+            self._export_changes(session, row, now=now or clock.now())
+            session.commit()
+            return self._as_export_job(row)
+
+    def run_due_exports(self, *, now: datetime | None = None) -> list[ExportJob]:
+        """Run every enabled export whose next scheduled time has passed."""
+        current = now or clock.now()
+        with self._state_session_factory() as session:
+            due_ids = session.scalars(
+                select(ApiExportJobRow.id)
+                .where(ApiExportJobRow.enabled.is_(True))
+                .where(ApiExportJobRow.next_run_at <= current)
+                .order_by(ApiExportJobRow.next_run_at.asc())
+            ).all()
+        return [self.run_export_job(export_id, now=current) for export_id in due_ids]
+
+    def _export_changes(self, session: Session, row: ApiExportJobRow, *, now: datetime) -> None:
+        # A failed upload leaves the cursor alone, so the next run retries the same changes.
+        changes = session.scalars(
+            select(ApiMemoryChangeRow)
+            .where(ApiMemoryChangeRow.account_key == row.account_key)
+            .where(ApiMemoryChangeRow.id > row.cursor)
+            .order_by(ApiMemoryChangeRow.id.asc())
+            .limit(self._config.export_max_rows)
+        ).all()
+        changes = self._settled_changes(changes)
+        row.rows_exported = 0
+        row.last_error = None
+        try:
+            # Exports run with no agent, so agent-private changes stay out of the files; the
+            # cursor still moves past them.
+            exported = [
+                change.model_dump(mode="json")
+                for change in (self._as_memory_change(item) for item in changes)
+                if self._payload_visible_to_agent(change.memory, None)
+            ]
+            if exported:
+                payload, content_type = encode_export(exported, row.format)
+                key = export_object_key(
+                    row.account_key,
+                    row.id,
+                    first_sequence=int(changes[0].id),
+                    last_sequence=int(changes[-1].id),
+                    export_format=row.format,
+                    now=now,
+                )
+                build_destination_store(
+                    row.destination,
+                    file_root=self._config.export_file_root,
+                    s3_endpoint_url=self._config.blob_s3_endpoint_url,
+                    s3_region=self._config.blob_s3_region,
+                ).put(key, payload, content_type=content_type)
+                row.last_object_key = key
+                row.rows_exported = len(exported)
+            if changes:
+                row.cursor = int(changes[-1].id)
+            row.last_status = "succeeded"
+        except Exception as exc:  # pylint: disable=broad-exception-caught
+            row.last_status = "failed"
+            row.last_error = str(exc)[:2000]
+        row.last_run_at = now
+        row.next_run_at = CronSchedule.parse(row.cron).next_after(now)
+
+    @staticmethod
+    def _as_export_job(row: ApiExportJobRow) -> ExportJob:
+        return ExportJob(
+            export_id=row.id,
+            account_key=row.account_key,
+            cron=row.cron,
+            destination=row.destination,
+            format=row.format,
+            enabled=row.enabled,
+            cursor=row.cursor,
+            next_run_at=_as_utc(row.next_run_at) if row.next_run_at else None,
+            last_run_at=_as_utc(row.last_run_at) if row.last_run_at else None,
+            last_status=row.last_status,
+            last_error=row.last_error,
+            last_object_key=row.last_object_key,
+            rows_exported=row.rows_exported,
+            created_at=_as_utc(row.created_at),
+        )
+
+    def create_url_source(self, request: UrlSourceRequest, *, account_key: str) -> UrlSource:
+        """Register a URL to re-crawl on a schedule and crawl it now.
+
+        Registering a URL the entity already follows updates that source instead.
+        """
+        normalized_account_key = self._normalize_account_key(account_key)
+        entity_id = request.entity_id or self._config.default_entity_id
+        now = clock.now()
+        with self._state_session_factory() as session:
+            row = session.scalars(
+                select(ApiUrlSourceRow)
+                .where(ApiUrlSourceRow.account_key == normalized_account_key)
+                .where(ApiUrlSourceRow.url == request.url)
+                .where(ApiUrlSourceRow.entity_id == entity_id)
+            ).first()
+            if row is None:
+                row = ApiUrlSourceRow(
+                    id=f"src_{uuid4().hex[:16]}",
                     account_key=normalized_account_key,
-                    status="requested",
-                    requested_by_subject=normalized_actor_subject,
-                    requested_by_email=normalized_actor_email,
-                    requested_by_name=normalized_actor_name,
-                    requested_at=now,
-                    email_sent_at=None,
-                    email_last_attempt_at=None,
-                    email_delivery_error=None,
+                    url=request.url,
+                    entity_id=entity_id,
+                    memory_ids_json="[]",
                     created_at=now,
-                    updated_at=now,
                 )
                 session.add(row)
-                created = True
-                should_send_email = True
-            else:
-                prior_status = self._normalize_pilot_pro_status(row.status)
-                if prior_status != "requested":
-                    row.status = "requested"
-                    row.requested_at = now
-                    row.email_sent_at = None
-                    row.email_last_attempt_at = None
-                    row.email_delivery_error = None
-                    should_send_email = True
-                row.requested_by_subject = normalized_actor_subject
-                row.requested_by_email = normalized_actor_email or row.requested_by_email
-                row.requested_by_name = normalized_actor_name or row.requested_by_name
-                row.updated_at = now
+            row.interval_hours = request.interval_hours or self._config.url_recrawl_hours
+            row.enabled = request.enabled
+            self._crawl_url_source(row, now=now)
+            session.commit()
+            return self._as_url_source(row)
 
-            self._insert_audit_row(
-                session=session,
-                account_key=normalized_account_key,
-                actor_subject=normalized_actor_subject,
-                actor_type="dashboard_user",
-                action="pilot_pro_requested",
-                target_type="account",
-                target_id=normalized_account_key,
-                metadata={
-                    "created": created,
-                    "requested_by_email": normalized_actor_email,
-                    "requested_by_name": normalized_actor_name,
-                },
-            )
-            session.flush()
+    def list_url_sources(self, *, account_key: str) -> UrlSourceListResponse:
+        with self._state_session_factory() as session:
+            rows = session.scalars(
+                select(ApiUrlSourceRow)
+                .where(ApiUrlSourceRow.account_key == self._normalize_account_key(account_key))
+                .order_by(ApiUrlSourceRow.created_at.asc())
+            ).all()
+            return UrlSourceListResponse(data=[self._as_url_source(row) for row in rows])
+
+    def delete_url_source(self, source_id: str, *, account_key: str) -> UrlSource:
+        """Stop crawling a URL; the memories of its last crawl are kept."""
+        with self._state_session_factory() as session:
+            row = self._url_source_row(session, source_id, account_key=account_key)
+            removed = self._as_url_source(row)
+            session.delete(row)
+            session.commit()
+            return removed
 
-        email_sent = False
-        if should_send_email:
-            email_sent, delivery_error, attempted_at = self._send_pilot_pro_request_email(
-                account_key=normalized_account_key,
-                actor_subject=normalized_actor_subject,
-                actor_email=normalized_actor_email,
-                actor_name=normalized_actor_name,
-            )
-            with self._state_session_factory() as session, session.begin():
-                row = self._select_pilot_pro_request_for_update(
-                    session=session,
-                    account_key=normalized_account_key,
-                )
-                if row is not None:
-                    row.email_last_attempt_at = attempted_at
-                    row.email_delivery_error = delivery_error
-                    if email_sent:
-                        row.email_sent_at = attempted_at
-                    row.updated_at = datetime.now(UTC)
-                    self._insert_audit_row(
-                        session=session,
-                        account_key=normalized_account_key,
-                        actor_subject=normalized_actor_subject,
-                        actor_type="system",
-                        action=(
-                            "pilot_pro_request_email_sent"
-                            if email_sent
-                            else "pilot_pro_request_email_failed"
-                        ),
-                        target_type="account",
-                        target_id=normalized_account_key,
-                        metadata={
-                            "delivery_error": delivery_error,
-                        },
-                    )
-        else:
-            existing = self._read_pilot_pro_request(normalized_account_key)
-            email_sent = bool(existing and existing.email_sent_at is not None)
+    def crawl_url_source(
+        self,
+        source_id: str,
+        *,
+        account_key: str,
+        now: datetime | None = None,
+    ) -> UrlSource:
+        """Crawl one URL source immediately, whether or not it is due."""
+        with self._state_session_factory() as session:
+            row = self._url_source_row(session, source_id, account_key=account_key)
+            self._crawl_url_source(row, now=now or clock.now())
+            session.commit()
+            return self._as_url_source(row)
+
+    def run_due_crawls(self, *, now: datetime | None = None) -> list[UrlSource]:
+        """Re-crawl every enabled URL source whose next crawl time has passed."""
+        current = now or clock.now()
+        with self._state_session_factory() as session:
+            due = session.execute(
+                select(ApiUrlSourceRow.id, ApiUrlSourceRow.account_key)
+                .where(ApiUrlSourceRow.enabled.is_(True))
+                .where(ApiUrlSourceRow.next_crawl_at <= current)
+                .order_by(ApiUrlSourceRow.next_crawl_at.asc())
+            ).all()
+        return [
+            self.crawl_url_source(source_id, account_key=account_key, now=current)
+            for source_id, account_key in due
+        ]
 
-        row = self._read_pilot_pro_request(normalized_account_key)
-        return PilotProRequestResponse(
-            request=self._as_pilot_pro_request(
-                row=row,
-                policy=self._plan_policy(normalized_account_key),
-            ),
-            created=created,
-            email_sent=email_sent,
+    def _url_source_row(
+        self,
+        session: Session,
+        source_id: str,
+        *,
+        account_key: str,
+    ) -> ApiUrlSourceRow:
+        row = session.get(ApiUrlSourceRow, source_id)
+        if row is None or row.account_key != self._normalize_account_key(account_key):
+            msg = f"url source not found: {source_id}"
+            raise KeyError(msg)
+        return row
+
+    def _outbound_policy(self) -> OutboundPolicy:
+        return OutboundPolicy(
+            allowed_hosts=tuple(self._config.outbound_allowed_hosts),
+            proxy_url=self._config.outbound_proxy_url,
         )
 
-    def health(self) -> dict[str, str]:
+    def _crawl_url_source(self, row: ApiUrlSourceRow, *, now: datetime) -> None:
+        # A failed crawl leaves the earlier memories and hash alone; they only grow stale.
+        previous = [str(item) for item in json.loads(row.memory_ids_json or "[]")]
         try:
-            self._engine.memory_count()
+            page = fetch_url(row.url, policy=self._outbound_policy())
+            digest = content_hash(page.text)
+            if digest == row.content_hash and previous:
+                memory_ids = previous
+                row.last_status = "unchanged"
+            else:
+                # Chunked pages revise the previous crawl's chunks in place (see documents.py).
+                result, _, _ = self.ingest_with_quota(
+                    account_key=row.account_key,
+                    request=IngestRequest(
+                        content=page.text,
+                        event_type="url_source",
+                        entity_id=row.entity_id,
+                        on_oversize="chunk",
+                    ),
+                    idempotency_key=None,
+                )
+                if not result.stored and result.revision is None:
+                    # Keep serving the previous crawl rather than replacing it with nothing.
+                    msg = f"page was not stored: {result.decision_reason}"
+                    raise ValueError(msg)
+                memory_ids = result.chunk_memory_ids or [result.memory_id]
+                replaced = [memory_id for memory_id in previous if memory_id not in memory_ids]
+                if replaced:
+                    self._engine.delete_memories(replaced, account_key=row.account_key)
+                row.content_hash = digest
+                row.memory_ids_json = json.dumps(memory_ids)
+                row.last_status = "updated"
+            self._mark_crawled(
+                memory_ids, account_key=row.account_key, source_id=row.id, crawled_at=now
+            )
+            row.last_crawled_at = now
+            row.last_error = None
         except Exception as exc:  # pylint: disable=broad-exception-caught
-            return {
-                "status": "degraded",
-                "version": self._config.api_version,
-                "storage": "error",
-                "detail": str(exc),
-            }
+            row.last_status = "failed"
+            row.last_error = str(exc)[:2000]
+        row.next_crawl_at = now + timedelta(hours=row.interval_hours)
+
+    def _mark_crawled(
+        self,
+        memory_ids: list[str],
+        *,
+        account_key: str,
+        source_id: str,
+        crawled_at: datetime,
+    ) -> None:
+        # The source link is stamped here rather than sent with the ingest, which drops
+        # reserved relationships from request metadata.
+        stamps = [f"url_source:{source_id}", f"crawled_at:{_as_utc(crawled_at).isoformat()}"]
+        for record in self._engine.storage.fetch_by_ids(memory_ids, account_key=account_key):
+            relationships = [
+                *[
+                    item
+                    for item in record.relationships
+                    if not item.startswith(("url_source:", "crawled_at:"))
+                ],
+                *stamps,
+            ]
+            if relationships != record.relationships:
+                self._engine.update_relationships(
+                    record.memory_id,
+                    relationships,
+                    account_key=account_key,
+                )
+
+    def _is_stale(self, crawled_at: datetime | None) -> bool:
+        if crawled_at is None:
+            return False
+        age = clock.now() - _as_utc(crawled_at)
+        return age > timedelta(hours=self._config.url_freshness_hours)
+
+    def _as_url_source(self, row: ApiUrlSourceRow) -> UrlSource:
+        last_crawled_at = _as_utc(row.last_crawled_at) if row.last_crawled_at else None
+        return UrlSource(
+            source_id=row.id,
+            url=row.url,
+            entity_id=row.entity_id,
+            interval_hours=row.interval_hours,
+            enabled=row.enabled,
+            content_hash=row.content_hash,
+            memory_ids=json.loads(row.memory_ids_json or "[]"),
+            last_crawled_at=last_crawled_at,
+            next_crawl_at=_as_utc(row.next_crawl_at) if row.next_crawl_at else None,
+            last_status=row.last_status,
+            last_error=row.last_error,
+            stale=self._is_stale(last_crawled_at),
+            created_at=_as_utc(row.created_at),
+        )
+
+    def set_saved_query(
+        self, name: str, request: SavedQueryRequest, *, account_key: str
+    ) -> SavedQuery:
+        """Create or replace a named retrieval that clients run with only its parameters."""
+        validate_saved_query_name(name)
+        template_parameters(request.query_template)
+        normalized_account_key = self._normalize_account_key(account_key)
+        now = clock.now()
+        with self._state_session_factory() as session:
+            row = self._saved_query_row(session, name, account_key=normalized_account_key)
+            if row is None:
+                row = ApiSavedQueryRow(
+                    account_key=normalized_account_key, name=name, created_at=now
+                )
+                session.add(row)
+            row.query_template = request.query_template
+            row.description = request.description
+            row.options_json = request.options.model_dump_json(exclude_none=True)
+            row.updated_at = now
+            session.commit()
+            return self._as_saved_query(row)
+
+    def saved_query(self, name: str, *, account_key: str) -> SavedQuery:
+        with self._state_session_factory() as session:
+            return self._as_saved_query(self._require_saved_query(session, name, account_key))
+
+    def list_saved_queries(self, *, account_key: str) -> SavedQueryListResponse:
+        with self._state_session_factory() as session:
+            rows = session.scalars(
+                select(ApiSavedQueryRow)
+                .where(ApiSavedQueryRow.account_key == self._normalize_account_key(account_key))
+                .order_by(ApiSavedQueryRow.name.asc())
+            ).all()
+            return SavedQueryListResponse(data=[self._as_saved_query(row) for row in rows])
+
+    def delete_saved_query(self, name: str, *, account_key: str) -> SavedQuery:
+        with self._state_session_factory() as session:
+            row = self._require_saved_query(session, name, account_key)
+            removed = self._as_saved_query(row)
+            session.delete(row)
+            session.commit()
+            return removed
+
+    def run_saved_query(
+        self,
+        name: str,
+        request: SavedQueryRunRequest,
+        *,
+        account_key: str,
+        max_sensitivity: str | None = None,
+    ) -> RetrieveResponse:
+        """Retrieve with a saved query's template filled from ``request.params``.
+
+        The saved options count as set by the caller, so they take precedence over the
+        namespace's retrieval defaults; ``request.limit`` overrides the saved limit.
+        """
+        saved = self.saved_query(name, account_key=account_key)
+        query = render_query(saved.query_template, request.params)
+        if not query.strip():
+            msg = f"saved query {name!r} rendered an empty query"
+            raise ValueError(msg)
+        if len(query) > self._config.max_query_chars:
+            msg = f"query must be at most {self._config.max_query_chars} characters"
+            raise ValueError(msg)
+        options: dict[str, Any] = saved.options.model_dump(exclude_none=True)
+        per_run = request.model_dump(
+            exclude_none=True, include={"entity_id", "session_id", "agent_id", "boost"}
+        )
+        if request.limit is not None:
+            options["limit"] = request.limit
+        retrieve_request = RetrieveRequest(query=query, **per_run, **options)
+        return self.retrieve(
+            retrieve_request, account_key=account_key, max_sensitivity=max_sensitivity
+        )
+
+    def _saved_query_row(
+        self, session: Session, name: str, *, account_key: str
+    ) -> ApiSavedQueryRow | None:
+        return session.scalars(
+            select(ApiSavedQueryRow)
+            .where(ApiSavedQueryRow.account_key == account_key)
+            .where(ApiSavedQueryRow.name == name)
+        ).first()
+
+    def _require_saved_query(
+        self, session: Session, name: str, account_key: str
+    ) -> ApiSavedQueryRow:
+        row = self._saved_query_row(
+            session, name, account_key=self._normalize_account_key(account_key)
+        )
+        if row is None:
+            msg = f"saved query not found: {name}"
+            raise KeyError(msg)
+        return row
+
+    @staticmethod
+    def _as_saved_query(row: ApiSavedQueryRow) -> SavedQuery:
+        return SavedQuery(
+            name=row.name,
+            query_template=row.query_template,
+            parameters=template_parameters(row.query_template),
+            description=row.description,
+            options=RetrieveDefaults.model_validate_json(row.options_json or "{}"),
+            created_at=_as_utc(row.created_at),
+            updated_at=_as_utc(row.updated_at),
+        )
+
+    def _record_memory_change(
+        self,
+        operation: str,
+        memory: MemoryRecord,
+        *,
+        extra: dict[str, Any] | None = None,
+    ) -> None:
+        with self._state_session_factory() as session:
+            session.add(self._memory_change_row(operation, memory, extra=extra))
+            session.commit()
+
+    def _journal_memory_change(
+        self,
+        session: Session,
+        operation: str,
+        memory: MemoryRecord,
+    ) -> None:
+        session.add(self._memory_change_row(operation, memory))
+        session.add_all(self._supersession_rows(operation, memory))
+
+    def _memory_change_row(
+        self,
+        operation: str,
+        memory: MemoryRecord,
+        *,
+        extra: dict[str, Any] | None = None,
+    ) -> ApiMemoryChangeRow:
+        payload = {} if operation == "deleted" else self._change_payload(memory)
+        if extra:
+            payload.update(extra)
+        return ApiMemoryChangeRow(
+            account_key=self._normalize_account_key(memory.account_key),
+            memory_id=memory.memory_id,
+            operation=operation,
+            payload_json=json.dumps(payload, ensure_ascii=True),
+            created_at=datetime.now(UTC),
+        )
+
+    @staticmethod
+    def _change_payload(memory: MemoryRecord) -> dict[str, Any]:
         return {
-            "status": "ok",
-            "version": self._config.api_version,
-            "storage": "ok",
+            "content": memory.content,
+            "summary": memory.summary,
+            "intent": memory.intent,
+            "entities": memory.entities,
+            "relationships": memory.relationships,
+            "storage_tier": memory.storage_tier.value,
+            "created_at": memory.created_at.isoformat(),
         }
 
-    def validate_token(self, auth: AuthContext) -> AuthValidationResponse:
-        return AuthValidationResponse(valid=True, scopes=auth.scopes)
-
-    def record_http_response(self, status_code: int) -> None:
-        with self._state_lock:
-            self._http_status_counts[status_code] = (
-                self._http_status_counts.get(status_code, 0.0) + 1.0
+    def _record_supersessions(self, operation: str, memory: MemoryRecord) -> None:
+        """Link each memory a new inferred fact replaced to its successor in the change log."""
+        rows = self._supersession_rows(operation, memory)
+        if not rows:
+            return
+        with self._state_session_factory() as session:
+            session.add_all(rows)
+            session.commit()
+
+    def _supersession_rows(
+        self,
+        operation: str,
+        memory: MemoryRecord,
+    ) -> list[ApiMemoryChangeRow]:
+        if operation != "created":
+            return []
+        now = datetime.now(UTC)
+        return [
+            ApiMemoryChangeRow(
+                account_key=self._normalize_account_key(memory.account_key),
+                memory_id=memory_id,
+                operation="superseded",
+                payload_json=json.dumps({"superseded_by": memory.memory_id}),
+                created_at=now,
             )
+            for memory_id in self._relationship_values(memory.relationships, "supersedes:")
+        ]
 
-    def record_dashboard_auth_failure(self) -> None:
-        with self._state_lock:
-            self._metrics["dashboard_auth_failures_total"] += 1
+    def update_memory(
+        self,
+        memory_id: str,
+        request: MemoryUpdateRequest,
+        *,
+        account_key: str | None = None,
+        expected_version: int | None = None,
+    ) -> Memory:
+        """Replace a memory's content; the previous content stays available as a version.
 
-    def record_dashboard_key_rotation_failure(self) -> None:
-        with self._state_lock:
-            self._metrics["dashboard_key_rotation_failures_total"] += 1
+        ``expected_version`` makes the write conditional on the current version, so concurrent
+        editors get :class:`PreconditionFailedError` instead of overwriting each other.
+        """
+        normalized_account_key = self._normalize_account_key(account_key)
+        existing = self._engine.storage.fetch_by_ids(
+            [memory_id],
+            account_key=normalized_account_key,
+        )
+        if not existing:
+            msg = f"memory not found: {memory_id}"
+            raise KeyError(msg)
+        current = existing[0]
+        current_version = self.memory_versions(
+            memory_id,
+            account_key=normalized_account_key,
+            all_agents=True,
+        ).current_version
+        screened = IngestRequest(
+            content=request.content,
+            entity_id=current.entities[0] if current.entities else None,
+        )
+        verdict = self._moderate(screened)
+        if verdict is not None and verdict.action == "block":
+            msg = f"content blocked by moderation policy ({', '.join(verdict.categories)})"
+            raise ValueError(msg)
+        if request.content == current.content:
+            if expected_version is not None and expected_version != current_version:
+                raise PreconditionFailedError(current_version=current_version)
+            return self._as_memory(
+                current,
+                rank_position=1,
+                rank_score=float(current.latest_importance),
+            ).model_copy(update={"version": current_version})
+        new_version = self._claim_memory_version(
+            normalized_account_key,
+            memory_id,
+            seed_version=current_version,
+            expected_version=expected_version,
+        )
+        try:
+            if not self._memory_change_rows(memory_id, account_key=normalized_account_key):
+                # Memories stored before the change log existed get their original content
+                # recorded first so it remains a version after the update.
+                self._record_memory_change("updated", current)
+            updated = self._engine.update_memory(
+                memory_id,
+                request.content,
+                account_key=normalized_account_key,
+            )
+        except Exception:
+            self._release_memory_version(normalized_account_key, memory_id, new_version)
+            raise
+        if verdict is not None:
+            self._queue_moderation_review(
+                screened,
+                verdict,
+                account_key=normalized_account_key,
+                memory_id=memory_id,
+            )
+        return self._as_memory(
+            updated,
+            rank_position=1,
+            rank_score=float(updated.latest_importance),
+        ).model_copy(update={"version": new_version})
 
-    def metrics_text(self) -> str:
-        with self._state_lock:
-            ingest_total = self._metrics["ingest_requests_total"]
-            retrieve_total = self._metrics["retrieve_requests_total"]
-            feedback_total = self._metrics["feedback_requests_total"]
-            dashboard_auth_failures = self._metrics["dashboard_auth_failures_total"]
-            key_rotation_failures = self._metrics[
-                "dashboard_key_rotation_failures_total"
-            ]
-            status_counts = dict(self._http_status_counts)
-        flash_metrics = self._engine.flash_metrics_snapshot()
-        lines = [
-            "# HELP orbit_ingest_requests_total Total ingest requests.",
-            "# TYPE orbit_ingest_requests_total counter",
-            f"orbit_ingest_requests_total {ingest_total:.0f}",
-            "# HELP orbit_retrieve_requests_total Total retrieve requests.",
-            "# TYPE orbit_retrieve_requests_total counter",
-            f"orbit_retrieve_requests_total {retrieve_total:.0f}",
-            "# HELP orbit_feedback_requests_total Total feedback requests.",
-            "# TYPE orbit_feedback_requests_total counter",
-            f"orbit_feedback_requests_total {feedback_total:.0f}",
-            "# HELP orbit_dashboard_auth_failures_total Dashboard auth failures observed by API.",
-            "# TYPE orbit_dashboard_auth_failures_total counter",
-            f"orbit_dashboard_auth_failures_total {dashboard_auth_failures:.0f}",
-            "# HELP orbit_dashboard_key_rotation_failures_total Dashboard key-rotation failures.",
-            "# TYPE orbit_dashboard_key_rotation_failures_total counter",
-            f"orbit_dashboard_key_rotation_failures_total {key_rotation_failures:.0f}",
-            "# HELP orbit_uptime_seconds Process uptime in seconds.",
-            "# TYPE orbit_uptime_seconds gauge",
-            f"orbit_uptime_seconds {self._uptime_seconds():.3f}",
-            "# HELP orbit_flash_pipeline_mode_async Flash pipeline mode (1 async, 0 sync).",
-            "# TYPE orbit_flash_pipeline_mode_async gauge",
-            f"orbit_flash_pipeline_mode_async {flash_metrics['mode_async']:.0f}",
-            "# HELP orbit_flash_pipeline_workers Number of flash pipeline workers.",
-            "# TYPE orbit_flash_pipeline_workers gauge",
-            f"orbit_flash_pipeline_workers {flash_metrics['workers']:.0f}",
-            "# HELP orbit_flash_pipeline_queue_depth Pending flash pipeline tasks.",
-            "# TYPE orbit_flash_pipeline_queue_depth gauge",
-            f"orbit_flash_pipeline_queue_depth {flash_metrics['queue_depth']:.0f}",
-            "# HELP orbit_flash_pipeline_queue_capacity Flash pipeline queue capacity.",
-            "# TYPE orbit_flash_pipeline_queue_capacity gauge",
-            f"orbit_flash_pipeline_queue_capacity {flash_metrics['queue_capacity']:.0f}",
-            "# HELP orbit_flash_pipeline_enqueued_total Total flash tasks enqueued.",
-            "# TYPE orbit_flash_pipeline_enqueued_total counter",
-            f"orbit_flash_pipeline_enqueued_total {flash_metrics['enqueued_total']:.0f}",
-            "# HELP orbit_flash_pipeline_dropped_total Total flash tasks dropped due to queue pressure.",
-            "# TYPE orbit_flash_pipeline_dropped_total counter",
-            f"orbit_flash_pipeline_dropped_total {flash_metrics['dropped_total']:.0f}",
-            "# HELP orbit_flash_pipeline_runs_total Total flash tasks executed.",
-            "# TYPE orbit_flash_pipeline_runs_total counter",
-            f"orbit_flash_pipeline_runs_total {flash_metrics['runs_total']:.0f}",
-            "# HELP orbit_flash_pipeline_maintenance_total Total flash maintenance cycles.",
-            "# TYPE orbit_flash_pipeline_maintenance_total counter",
-            f"orbit_flash_pipeline_maintenance_total {flash_metrics['maintenance_total']:.0f}",
-            "# HELP orbit_flash_pipeline_failures_total Total flash pipeline task failures.",
-            "# TYPE orbit_flash_pipeline_failures_total counter",
-            f"orbit_flash_pipeline_failures_total {flash_metrics['failures_total']:.0f}",
-        ]
-        lines.extend(
-            [
-                "# HELP orbit_http_responses_total API responses by status code.",
-                "# TYPE orbit_http_responses_total counter",
-            ]
+    def _claim_memory_version(
+        self,
+        account_key: str,
+        memory_id: str,
+        *,
+        seed_version: int,
+        expected_version: int | None,
+    ) -> int:
+        """Advance the memory's stored version by one and return the new version.
+
+        The advance is a conditional UPDATE on the version it read, so of two concurrent edits
+        only one wins: a conditional loser gets :class:`PreconditionFailedError` and an
+        unconditional one goes again on top of the winner. A memory's first edit creates its
+        row at ``seed_version``, the version its change-log history implies.
+        """
+        row_filter = (
+            ApiMemoryVersionRow.account_key == account_key,
+            ApiMemoryVersionRow.memory_id == memory_id,
         )
-        for status_code in sorted(status_counts):
-            lines.append(
-                f'orbit_http_responses_total{{status_code="{status_code}"}} '
-                f"{status_counts[status_code]:.0f}"
+        while True:
+            with self._state_session_factory() as session:
+                stored = session.scalar(select(ApiMemoryVersionRow.version).where(*row_filter))
+                if stored is None:
+                    session.add(
+                        ApiMemoryVersionRow(
+                            account_key=account_key,
+                            memory_id=memory_id,
+                            version=seed_version,
+                            updated_at=datetime.now(UTC),
+                        )
+                    )
+                    try:
+                        session.commit()
+                    except IntegrityError:
+                        # A concurrent first edit created the row; compete on its version.
+                        session.rollback()
+                    continue
+                if expected_version is not None and expected_version != stored:
+                    raise PreconditionFailedError(current_version=stored)
+                claimed = session.execute(
+                    update(ApiMemoryVersionRow)
+                    .where(*row_filter, ApiMemoryVersionRow.version == stored)
+                    .values(version=stored + 1, updated_at=datetime.now(UTC))
+                ).rowcount
+                session.commit()
+            if claimed == 1:
+                return stored + 1
+
+    def _release_memory_version(self, account_key: str, memory_id: str, version: int) -> None:
+        """Undo a claim whose write failed, unless another edit has advanced past it."""
+        with self._state_session_factory() as session:
+            session.execute(
+                update(ApiMemoryVersionRow)
+                .where(ApiMemoryVersionRow.account_key == account_key)
+                .where(ApiMemoryVersionRow.memory_id == memory_id)
+                .where(ApiMemoryVersionRow.version == version)
+                .values(version=version - 1, updated_at=datetime.now(UTC))
             )
-        return "\n".join(lines) + "\n"
+            session.commit()
 
-    def list_memories(
+    def memory_versions(
         self,
-        limit: int,
-        cursor: str | None,
+        memory_id: str,
         *,
         account_key: str | None = None,
-    ) -> PaginatedMemoriesResponse:
-        offset = 0
-        if cursor:
-            try:
-                offset = max(0, int(cursor))
-            except ValueError:
-                offset = 0
+        max_sensitivity: str | None = None,
+        agent_id: str | None = None,
+        all_agents: bool = False,
+    ) -> MemoryVersionListResponse:
+        """Content history from the change log; updates that leave content unchanged are skipped.
+
+        The memory's latest label decides clearance for its whole history, so a memory above
+        ``max_sensitivity``, or private to an agent other than ``agent_id``, is reported as not
+        found. Edits and reverts pass ``all_agents`` to read the history they build on.
+        """
+        normalized_account_key = self._normalize_account_key(account_key)
+        versions: list[MemoryVersion] = []
+        deleted_at: datetime | None = None
+        superseded_by: str | None = None
+        latest_payload: dict[str, Any] = {}
+        for row in self._memory_change_rows(memory_id, account_key=normalized_account_key):
+            payload = json.loads(row.payload_json) or {}
+            if row.operation == "deleted":
+                deleted_at = _as_utc(row.created_at)
+                continue
+            if row.operation == "superseded":
+                superseded_by = payload.get("superseded_by")
+                continue
+            latest_payload = payload
+            content = payload.get("content")
+            if not isinstance(content, str) or (versions and versions[-1].content == content):
+                continue
+            reverted_from = next(
+                (item.version for item in versions if item.content == content),
+                None,
+            )
+            operation = "created" if not versions else "updated"
+            versions.append(
+                MemoryVersion(
+                    version=len(versions) + 1,
+                    operation="reverted" if reverted_from is not None else operation,
+                    content=content,
+                    summary=payload.get("summary"),
+                    recorded_at=_as_utc(row.created_at),
+                    reverted_from=reverted_from,
+                )
+            )
+        if versions and (
+            not self._payload_within_clearance(latest_payload, max_sensitivity)
+            or not (all_agents or self._payload_visible_to_agent(latest_payload, agent_id))
+        ):
+            msg = f"memory not found: {memory_id}"
+            raise KeyError(msg)
+        if not versions:
+            records = self._within_clearance(
+                self._engine.storage.fetch_by_ids([memory_id], account_key=normalized_account_key),
+                max_sensitivity,
+            )
+            if not all_agents:
+                records = self._visible_to_agent(records, agent_id)
+            if not records:
+                msg = f"memory not found: {memory_id}"
+                raise KeyError(msg)
+            versions.append(
+                MemoryVersion(
+                    version=1,
+                    operation="created",
+                    content=records[0].content,
+                    summary=records[0].summary,
+                    recorded_at=records[0].created_at,
+                )
+            )
+        return MemoryVersionListResponse(
+            memory_id=memory_id,
+            current_version=versions[-1].version,
+            versions=versions,
+            deleted_at=deleted_at,
+            superseded_by=superseded_by,
+        )
 
-        records = sorted(
-            self._engine.storage.list_memories(
-                account_key=self._normalize_account_key(account_key)
-            ),
-            key=lambda item: item.created_at,
-            reverse=True,
+    def memory_diff(
+        self,
+        memory_id: str,
+        *,
+        from_version: int | None = None,
+        to_version: int | None = None,
+        account_key: str | None = None,
+        max_sensitivity: str | None = None,
+        agent_id: str | None = None,
+    ) -> MemoryDiffResponse:
+        """Unified diff between two versions; defaults to the previous and current version."""
+        history = self.memory_versions(
+            memory_id,
+            account_key=account_key,
+            max_sensitivity=max_sensitivity,
+            agent_id=agent_id,
         )
-        selected = records[offset : offset + limit]
-        data = [
-            self._as_memory(
-                record,
-                rank_position=offset + idx + 1,
-                rank_score=float(record.latest_importance),
-            )
-            for idx, record in enumerate(selected)
-        ]
-        next_offset = offset + limit
-        has_more = next_offset < len(records)
-        return PaginatedMemoriesResponse(
-            data=data,
-            cursor=str(next_offset) if has_more else None,
-            has_more=has_more,
+        target = to_version or history.current_version
+        source = from_version or max(1, target - 1)
+        by_number = {item.version: item for item in history.versions}
+        for number in (source, target):
+            if number not in by_number:
+                msg = f"version {number} not found for memory {memory_id}"
+                raise KeyError(msg)
+        diff = difflib.unified_diff(
+            by_number[source].content.splitlines(),
+            by_number[target].content.splitlines(),
+            fromfile=f"{memory_id}@v{source}",
+            tofile=f"{memory_id}@v{target}",
+            lineterm="",
+        )
+        return MemoryDiffResponse(
+            memory_id=memory_id,
+            from_version=source,
+            to_version=target,
+            diff="\n".join(diff),
+        )
+
+    def revert_memory(
+        self,
+        memory_id: str,
+        version: int,
+        *,
+        account_key: str | None = None,
+        expected_version: int | None = None,
+    ) -> Memory:
+        """Restore an earlier version's content; the revert is itself recorded as a new version."""
+        history = self.memory_versions(memory_id, account_key=account_key, all_agents=True)
+        if history.deleted_at is not None:
+            msg = f"memory not found: {memory_id}"
+            raise KeyError(msg)
+        target = next((item for item in history.versions if item.version == version), None)
+        if target is None:
+            msg = f"version {version} not found for memory {memory_id}"
+            raise KeyError(msg)
+        return self.update_memory(
+            memory_id,
+            MemoryUpdateRequest(content=target.content),
+            account_key=account_key,
+            expected_version=expected_version,
         )
 
+    def _memory_change_rows(
+        self,
+        memory_id: str,
+        *,
+        account_key: str,
+    ) -> list[ApiMemoryChangeRow]:
+        with self._state_session_factory() as session:
+            return list(
+                session.scalars(
+                    select(ApiMemoryChangeRow)
+                    .where(ApiMemoryChangeRow.account_key == account_key)
+                    .where(ApiMemoryChangeRow.memory_id == memory_id)
+                    .order_by(ApiMemoryChangeRow.id.asc())
+                ).all()
+            )
+
     def _as_memory(
         self,
         record: MemoryRecord,
@@ -1530,7 +8986,8 @@ class OrbitApiService:
             rank_position=rank_position,
             rank_score=float(max(0.0, min(1.0, rank_score))),
             importance_score=float(max(0.0, min(1.0, record.latest_importance))),
-            timestamp=record.created_at,
+            timestamp=record.event_time,
+            created_at=record.created_at,
             metadata={
                 "summary": record.summary,
                 "intent": record.intent,
@@ -1539,12 +8996,64 @@ class OrbitApiService:
                 "storage_tier": record.storage_tier.value,
                 "inference_provenance": inference_provenance,
                 "fact_inference": fact_inference,
+                "attachment": self._attachment_metadata(record),
+                "sensitivity": self._record_sensitivity(record),
+                "sentiment": self._relationship_value(record.relationships, "sentiment:"),
+                "emotions": self._relationship_values(record.relationships, "emotion:"),
+                "category": self._relationship_value(record.relationships, "category:"),
+                "agent_id": self._relationship_value(record.relationships, "agent:"),
+                "agent_scope": (
+                    "private" if "agent_scope:private" in record.relationships else "shared"
+                ),
+                "source": self._chunk_source(record.relationships),
+                **self._url_freshness(record.relationships),
+                "access": {
+                    "retrieval_count": record.retrieval_count,
+                    "last_retrieved_at": (
+                        _as_utc(record.last_retrieved_at).isoformat()
+                        if record.last_retrieved_at is not None
+                        else None
+                    ),
+                },
             },
             relevance_explanation=(
                 "Ranked by semantic similarity + learned relevance model."
             ),
         )
 
+    @classmethod
+    def _chunk_source(cls, relationships: list[str]) -> dict[str, Any] | None:
+        """Citation offsets of a chunk of a longer document; None for other memories."""
+        offsets: dict[str, Any] = {}
+        for key in ("start", "end", "chunk_index", "chunk_count", "page_start", "page_end"):
+            prefix = f"source_{key}:" if key in {"start", "end"} else f"{key}:"
+            value = cls._relationship_value(relationships, prefix)
+            offsets[key] = int(value) if value is not None and value.isdigit() else None
+        if offsets["start"] is None or offsets["end"] is None:
+            return None
+        return {
+            "document_id": cls._relationship_value(relationships, "chunk_group:"),
+            **offsets,
+        }
+
+    def _url_freshness(self, relationships: list[str]) -> dict[str, Any]:
+        """The URL source a memory was crawled from, and whether that crawl is too old."""
+        source_id = self._relationship_value(relationships, "url_source:")
+        if source_id is None:
+            return {"url_source": None, "stale": False}
+        crawled_at = None
+        with suppress(TypeError, ValueError):
+            crawled_at = datetime.fromisoformat(
+                self._relationship_value(relationships, "crawled_at:") or ""
+            )
+        return {
+            "url_source": {
+                "source_id": source_id,
+                "crawled_at": crawled_at.isoformat() if crawled_at else None,
+            },
+            "stale": self._is_stale(crawled_at),
+        }
+
     @classmethod
     def _inference_provenance(cls, record: MemoryRecord) -> dict[str, Any]:
         relationships = [str(item).strip() for item in record.relationships]
@@ -1648,9 +9157,9 @@ class OrbitApiService:
                 continue
             if event_type and record.intent != event_type:
                 continue
-            if start_time and record.created_at < start_time:
+            if start_time and record.event_time < start_time:
                 continue
-            if end_time and record.created_at > end_time:
+            if end_time and record.event_time > end_time:
                 continue
             output.append(record)
         return output
@@ -1792,6 +9301,94 @@ class OrbitApiService:
         reweighted.sort(key=lambda item: item.rank_score, reverse=True)
         return reweighted
 
+    def _weight_by_category(self, ranked: list[RetrievedMemory]) -> list[RetrievedMemory]:
+        weights = self._config.category_weights
+        weighted = [
+            item.model_copy(
+                update={
+                    "rank_score": max(
+                        0.0,
+                        min(
+                            item.rank_score
+                            * weights.get(
+                                self._relationship_value(item.memory.relationships, "category:")
+                                or UNCATEGORIZED,
+                                1.0,
+                            ),
+                            2.0,
+                        ),
+                    )
+                }
+            )
+            for item in ranked
+        ]
+        weighted.sort(key=lambda item: item.rank_score, reverse=True)
+        return weighted
+
+    @staticmethod
+    def _select_with_mmr(
+        ranked: list[RetrievedMemory],
+        *,
+        top_k: int,
+        mmr_lambda: float,
+    ) -> list[RetrievedMemory]:
+        """Pick ``top_k`` of ``ranked`` by maximal marginal relevance, in the order picked.
+
+        Each pick maximizes ``mmr_lambda * relevance - (1 - mmr_lambda) * redundancy``, where
+        relevance is the rank score over the best one and redundancy is the highest cosine
+        similarity to a memory already picked, so near-paraphrases of a pick fall behind.
+        """
+        pool = ranked[: top_k * _MMR_CANDIDATE_FACTOR]
+        if len(pool) <= 1:
+            return pool[:top_k]
+        top_score = max(item.rank_score for item in pool) or 1.0
+        relevance = np.asarray([item.rank_score / top_score for item in pool], dtype=np.float32)
+        embeddings = [item.memory.semantic_embedding for item in pool]
+        dimension = next((len(embedding) for embedding in embeddings if embedding), 0)
+        # Memories without a comparable vector count as unlike every other memory.
+        vectors = np.zeros((len(pool), dimension), dtype=np.float32)
+        for row, embedding in enumerate(embeddings):
+            if dimension and len(embedding) == dimension:
+                vectors[row] = to_unit_vector(np.asarray(embedding, dtype=np.float32))
+        similarity = vectors @ vectors.T
+        redundancy = np.zeros(len(pool), dtype=np.float32)
+        available = np.ones(len(pool), dtype=bool)
+        picked: list[int] = []
+        while len(picked) < min(top_k, len(pool)):
+            scores = mmr_lambda * relevance - (1.0 - mmr_lambda) * redundancy
+            scores[~available] = -np.inf
+            index = int(np.argmax(scores))
+            picked.append(index)
+            available[index] = False
+            redundancy = np.maximum(redundancy, similarity[index])
+        return [pool[index] for index in picked]
+
+    def _apply_boost(
+        self, ranked: list[RetrievedMemory], boost: RetrieveBoost
+    ) -> list[RetrievedMemory]:
+        boosted = [
+            item.model_copy(
+                update={
+                    "rank_score": max(
+                        0.0,
+                        min(item.rank_score * self._boost_multiplier(item.memory, boost), 2.0),
+                    )
+                }
+            )
+            for item in ranked
+        ]
+        boosted.sort(key=lambda item: item.rank_score, reverse=True)
+        return boosted
+
+    def _boost_multiplier(self, record: MemoryRecord, boost: RetrieveBoost) -> float:
+        """Product of the ``boost`` multipliers whose tag, event type, or category match."""
+        multiplier = boost.event_type.get(record.intent, 1.0)
+        category = self._relationship_value(record.relationships, "category:") or UNCATEGORIZED
+        multiplier *= boost.category.get(category, 1.0)
+        for tag in self._relationship_values(record.relationships, "tag:"):
+            multiplier *= boost.tags.get(tag, 1.0)
+        return multiplier
+
     def _diversity_aware_rerank(
         self,
         ranked: list[RetrievedMemory],
@@ -2542,6 +10139,70 @@ class OrbitApiService:
     def _is_assistant_intent(intent: str) -> bool:
         return intent.strip().lower().startswith("assistant_")
 
+    @classmethod
+    def _record_sensitivity(cls, record: MemoryRecord) -> str:
+        label = cls._relationship_value(record.relationships, "sensitivity:")
+        return label if label in SENSITIVITY_LEVELS else "public"
+
+    @classmethod
+    def _within_clearance(
+        cls,
+        records: list[MemoryRecord],
+        max_sensitivity: str | None,
+    ) -> list[MemoryRecord]:
+        if max_sensitivity is None:
+            return records
+        ceiling = SENSITIVITY_LEVELS.index(max_sensitivity)
+        return [
+            record
+            for record in records
+            if SENSITIVITY_LEVELS.index(cls._record_sensitivity(record)) <= ceiling
+        ]
+
+    @classmethod
+    def _payload_within_clearance(
+        cls,
+        payload: dict[str, Any] | None,
+        max_sensitivity: str | None,
+    ) -> bool:
+        """Clearance check for a change-log payload, which carries the memory's relationships."""
+        if max_sensitivity is None or not payload:
+            return True
+        label = cls._relationship_value(payload.get("relationships") or [], "sensitivity:")
+        if label not in SENSITIVITY_LEVELS:
+            label = "public"
+        return SENSITIVITY_LEVELS.index(label) <= SENSITIVITY_LEVELS.index(max_sensitivity)
+
+    def _visible_to_agent(
+        self,
+        records: list[MemoryRecord],
+        agent_id: str | None,
+    ) -> list[MemoryRecord]:
+        return [
+            record for record in records if self._agent_can_read(record.relationships, agent_id)
+        ]
+
+    def _payload_visible_to_agent(
+        self,
+        payload: dict[str, Any] | None,
+        agent_id: str | None,
+    ) -> bool:
+        """Agent check for a change-log payload, which carries the memory's relationships."""
+        if not payload:
+            return True
+        return self._agent_can_read(payload.get("relationships") or [], agent_id)
+
+    def _agent_can_read(self, relationships: list[str], agent_id: str | None) -> bool:
+        # Private memories are for the agent that wrote them and the agents
+        # ORBIT_AGENT_VISIBILITY grants them to; callers without an agent_id see none.
+        if "agent_scope:private" not in relationships:
+            return True
+        if agent_id is None:
+            return False
+        writer = self._relationship_value(relationships, "agent:")
+        readable = set(self._config.agent_visibility.get(agent_id, []))
+        return writer == agent_id or writer in readable or "*" in readable
+
     @staticmethod
     def _relationship_value(relationships: list[str], prefix: str) -> str | None:
         for relation in relationships:
@@ -2609,6 +10270,9 @@ class OrbitApiService:
             "fact_conflict_guard_v1": (
                 "Conflicting critical facts were detected; clarification is required before relying on them."
             ),
+            "trajectory_reflection": (
+                "Distilled from a completed agent trajectory submitted for reflection."
+            ),
         }
         if inference_type in reasons:
             return reasons[inference_type]
@@ -2651,6 +10315,19 @@ class OrbitApiService:
         if amount <= 0:
             msg = "amount must be > 0"
             raise ValueError(msg)
+        if self._is_sandbox(session, account_key):
+            # Sandbox usage is free; the namespace is wiped when its TTL runs out.
+            policy = self._plan_policy(account_key)
+            limit = (
+                policy.ingest_events_per_month
+                if kind == "event"
+                else policy.retrieve_queries_per_month
+            )
+            return RateLimitSnapshot(
+                limit=limit,
+                remaining=limit,
+                reset_epoch=self._next_month_reset_epoch(now),
+            )
         usage = self._select_usage_row_for_update(session=session, account_key=account_key)
         if usage is None:
             usage = ApiAccountUsageRow(
@@ -3311,6 +10988,7 @@ class OrbitApiService:
             name=row.name,
             key_prefix=row.key_prefix,
             scopes=OrbitApiService._deserialize_scopes(row.scopes_json),
+            allowed_origins=OrbitApiService._deserialize_scopes(row.allowed_origins_json),
             status=row.status,
             created_at=row.created_at,
             last_used_at=row.last_used_at,
@@ -3386,12 +11064,28 @@ class OrbitApiService:
             value = scope.strip()
             if not value or value in seen:
                 continue
+            if value in _RESERVED_KEY_SCOPES:
+                msg = f"scope {value!r} cannot be granted to an API key"
+                raise ValueError(msg)
             seen.add(value)
             normalized.append(value)
         if not normalized:
             return list(_DEFAULT_KEY_SCOPES)
         return normalized
 
+    @staticmethod
+    def _normalize_origins(origins: list[str] | None) -> list[str]:
+        normalized: list[str] = []
+        for origin in origins or []:
+            parsed = urlparse(origin.strip())
+            if not parsed.scheme or not parsed.netloc or parsed.path not in ("", "/"):
+                msg = f"allowed origin must look like scheme://host[:port]: {origin}"
+                raise ValueError(msg)
+            value = f"{parsed.scheme}://{parsed.netloc}".lower()
+            if value not in normalized:
+                normalized.append(value)
+        return normalized
+
     @staticmethod
     def _normalize_key_id(value: str) -> str:
         normalized = value.strip()
@@ -3622,16 +11316,30 @@ class OrbitApiService:
         self,
         provided: EngineConfig | None,
     ) -> EngineConfig:
-        base = provided or EngineConfig.from_env()
+        base = provided or self._engine_config_from_overrides(EngineConfig.from_env())
         updates: dict[str, Any] = {}
         if base.database_url is None:
             updates["database_url"] = self._config.database_url
         if not base.sqlite_path:
             updates["sqlite_path"] = self._config.sqlite_fallback_path
+        if self._config.test_mode:
+            updates["pipeline_mode"] = "heuristic"
+            updates["pipeline_mode_namespaces"] = {}
+            updates["embedding_seed"] = self._config.test_seed
         if not updates:
             return base
         return base.model_copy(update=updates)
 
+    def _engine_config_from_overrides(self, base: EngineConfig) -> EngineConfig:
+        overrides = self._config.engine_overrides
+        if not overrides:
+            return base
+        unknown = sorted(key for key in overrides if key not in type(base).model_fields)
+        if unknown:
+            msg = f"unknown [engine] config keys: {', '.join(unknown)}"
+            raise ValueError(msg)
+        return type(base).model_validate({**base.model_dump(), **overrides})
+
     @staticmethod
     def _normalize_account_key(account_key: str | None) -> str:
         if account_key is None:
@@ -3640,5 +11348,69 @@ class OrbitApiService:
         return normalized or "default"
 
 
+def _as_utc(value: datetime) -> datetime:
+    # SQLite drops tzinfo on round-trip; treat naive timestamps as UTC.
+    return value if value.tzinfo is not None else value.replace(tzinfo=UTC)
+
+
+def _time_ago(moment: datetime, now: datetime) -> str:
+    days = (now - moment).days
+    if days < 7:
+        return "A few days ago"
+    if days < 14:
+        return "Last week"
+    if days < 30:
+        return f"{days // 7} weeks ago"
+    if days < 60:
+        return "Last month"
+    if days < 365:
+        return f"{days // 30} months ago"
+    if days < 730:
+        return "Last year"
+    return f"{days // 365} years ago"
+
+
+def _digest_text(
+    period: str,
+    count: int,
+    categories: Counter[str],
+    records: list[MemoryRecord],
+) -> str:
+    # Used when no summarization model is configured: counts, then the top memories verbatim.
+    window = "day" if period == "daily" else "week"
+    if count == 0:
+        return f"No new memories in the past {window}."
+    noun = "memory" if count == 1 else "memories"
+    breakdown = ", ".join(f"{total} {category}" for category, total in categories.most_common())
+    lines = [f"{count} new {noun} in the past {window}: {breakdown}."]
+    lines.extend(f"- {record.content}" for record in records[:_DIGEST_QUOTED_MEMORIES])
+    return "\n".join(lines)
+
+
+def _post_webhook(
+    url: str,
+    event_type: str,
+    payload: dict[str, Any],
+    *,
+    secret: str | None,
+) -> bool:
+    """POST a JSON event, HMAC-signed when ``secret`` is set; returns whether it was accepted."""
+    body = json.dumps({"type": event_type, **payload}, ensure_ascii=True).encode("utf-8")
+    headers = {
+        "Content-Type": "application/json",
+        "X-Orbit-Event": event_type,
+        **outbound_headers(),
+    }
+    if secret:
+        digest = hmac.new(secret.encode("utf-8"), body, hashlib.sha256).hexdigest()
+        headers["X-Orbit-Signature"] = f"sha256={digest}"
+    try:
+        with httpx.Client(timeout=_WEBHOOK_TIMEOUT_SECONDS) as client:
+            response = client.post(url, content=body, headers=headers)
+    except httpx.HTTPError:
+        return False
+    return response.status_code < 300
+
+
 def _tokenize_query(query: str) -> set[str]:
     return set(re.findall(r"[a-z0-9]+", query.lower()))
@@ -0,0 +1,180 @@
+"""Slack Events API ingestion and `/orbit recall` slash command."""
+
+from __future__ import annotations
+
+import hashlib
+import hmac
+import time
+from typing import Any
+
+from orbit.models import IngestRequest, RetrieveRequest
+from orbit_api.config import ApiConfig
+from orbit_api.service import OrbitApiService, RateLimitExceededError
+
+SIGNATURE_VERSION = "v0"
+MAX_REQUEST_AGE_SECONDS = 300
+SLACK_EVENT_TYPE = "slack_message"
+
+
+def verify_signature(
+    signing_secret: str,
+    *,
+    timestamp: str | None,
+    body: bytes,
+    signature: str | None,
+    now: float | None = None,
+) -> bool:
+    """Check Slack's ``X-Slack-Signature`` and reject replays older than five minutes."""
+    if not timestamp or not signature:
+        return False
+    try:
+        sent_at = int(timestamp)
+    except ValueError:
+        return False
+    current = time.time() if now is None else now
+    if abs(current - sent_at) > MAX_REQUEST_AGE_SECONDS:
+        return False
+    base = f"{SIGNATURE_VERSION}:{timestamp}:".encode() + body
+    digest = hmac.new(signing_secret.encode(), base, hashlib.sha256).hexdigest()
+    return hmac.compare_digest(f"{SIGNATURE_VERSION}={digest}", signature)
+
+
+class SlackIntegration:
+    """Maps consenting Slack users onto Orbit entities for one Orbit account.
+
+    Only messages from users listed in ``user_entities`` are ingested, and only
+    from allow-listed channels (plus DMs when enabled). ``/orbit recall`` searches
+    the caller's own entity only.
+    """
+
+    def __init__(
+        self,
+        service: OrbitApiService,
+        *,
+        account_key: str,
+        user_entities: dict[str, str],
+        channel_ids: list[str] | None = None,
+        include_direct_messages: bool = False,
+        max_content_chars: int = 20_000,
+        recall_limit: int = 5,
+        recall_max_sensitivity: str = "public",
+    ) -> None:
+        self._service = service
+        self._account_key = account_key
+        self._user_entities = dict(user_entities)
+        self._channel_ids = set(channel_ids or [])
+        self._include_direct_messages = include_direct_messages
+        self._max_content_chars = max_content_chars
+        self._recall_limit = recall_limit
+        self._recall_max_sensitivity = recall_max_sensitivity
+
+    @classmethod
+    def from_config(
+        cls,
+        service: OrbitApiService,
+        config: ApiConfig,
+    ) -> SlackIntegration | None:
+        if not config.slack_signing_secret or not config.slack_account_key:
+            return None
+        return cls(
+            service,
+            account_key=config.slack_account_key,
+            user_entities=config.slack_user_entities,
+            channel_ids=config.slack_channel_ids,
+            include_direct_messages=config.slack_include_direct_messages,
+            max_content_chars=config.max_ingest_content_chars,
+            recall_max_sensitivity=config.slack_recall_max_sensitivity,
+        )
+
+    @property
+    def account_key(self) -> str:
+        return self._account_key
+
+    def handle_event(self, payload: dict[str, Any]) -> dict[str, Any]:
+        if payload.get("type") == "url_verification":
+            return {"challenge": payload.get("challenge")}
+        if payload.get("type") != "event_callback":
+            return {"ok": True, "ingested": False}
+        event = payload.get("event")
+        request = (
+            self.event_to_ingest(event, team_id=payload.get("team_id"))
+            if isinstance(event, dict)
+            else None
+        )
+        if request is None:
+            return {"ok": True, "ingested": False}
+        event_id = payload.get("event_id")
+        result, _snapshot, _replayed = self._service.ingest_with_quota(
+            account_key=self._account_key,
+            request=request,
+            idempotency_key=f"slack:{event_id}" if event_id else None,
+        )
+        return {"ok": True, "ingested": result.stored, "memory_id": result.memory_id}
+
+    def event_to_ingest(
+        self,
+        event: dict[str, Any],
+        *,
+        team_id: str | None = None,
+    ) -> IngestRequest | None:
+        if event.get("type") != "message" or event.get("subtype") or event.get("bot_id"):
+            return None
+        user_id = str(event.get("user") or "")
+        entity_id = self._user_entities.get(user_id)
+        if entity_id is None:
+            return None
+        channel = str(event.get("channel") or "")
+        if event.get("channel_type") == "im":
+            if not self._include_direct_messages:
+                return None
+        elif channel not in self._channel_ids:
+            return None
+        text = str(event.get("text") or "").strip()
+        if not text:
+            return None
+        metadata: dict[str, Any] = {
+            "source": "slack",
+            "slack_channel": channel,
+            "slack_user": user_id,
+            "slack_ts": event.get("ts"),
+        }
+        if team_id:
+            metadata["slack_team_id"] = team_id
+        if event.get("thread_ts"):
+            metadata["slack_thread_ts"] = event.get("thread_ts")
+        return IngestRequest(
+            content=text[: self._max_content_chars],
+            entity_id=entity_id,
+            event_type=SLACK_EVENT_TYPE,
+            metadata=metadata,
+        )
+
+    def handle_command(self, form: dict[str, str]) -> dict[str, Any]:
+        subcommand, _, query = form.get("text", "").strip().partition(" ")
+        query = query.strip()
+        if subcommand.lower() != "recall" or not query:
+            return _ephemeral("Usage: `/orbit recall <question>`")
+        entity_id = self._user_entities.get(form.get("user_id", ""))
+        if entity_id is None:
+            return _ephemeral(
+                "Your Slack account is not linked to Orbit. Ask a workspace admin to add you."
+            )
+        try:
+            self._service.consume_query_quota(account_key=self._account_key, amount=1)
+        except RateLimitExceededError as exc:
+            return _ephemeral(f"Orbit query quota reached: {exc.detail}")
+        result = self._service.retrieve(
+            # Each linked user recalls only their own entity's memories.
+            RetrieveRequest(query=query, limit=self._recall_limit, entity_id=entity_id),
+            account_key=self._account_key,
+            max_sensitivity=self._recall_max_sensitivity,
+        )
+        if not result.memories:
+            return _ephemeral(f"No memories found for _{query}_.")
+        lines = [f"*Orbit recall:* _{query}_"]
+        lines.extend(f"• {memory.content}" for memory in result.memories)
+        return _ephemeral("\n".join(lines))
+
+
+def _ephemeral(text: str) -> dict[str, Any]:
+    return {"response_type": "ephemeral", "text": text}
@@ -0,0 +1,339 @@
+"""Consume ingest events from Kafka topics or NATS subjects without an HTTP shim."""
+
+from __future__ import annotations
+
+import asyncio
+import json
+import time
+from collections.abc import Callable, Iterator
+from dataclasses import dataclass, field
+from importlib import import_module
+from types import ModuleType
+from typing import Any, Protocol
+
+from pydantic import ValidationError
+
+from orbit.logger import get_logger
+from orbit.models import IngestRequest
+from orbit_api.service import (
+    IdempotencyConflictError,
+    OrbitApiService,
+    RateLimitExceededError,
+)
+
+
+def _optional_import(module_name: str) -> ModuleType | None:
+    try:  # pragma: no cover - optional dependency
+        return import_module(module_name)
+    except ImportError:  # pragma: no cover - optional dependency
+        return None
+
+
+confluent_kafka_module: ModuleType | None = _optional_import("confluent_kafka")
+nats_module: ModuleType | None = _optional_import("nats")
+
+
+@dataclass(frozen=True)
+class SchemaMapping:
+    """Dotted-path mapping from a JSON message onto an ingest request."""
+
+    content_field: str = "content"
+    entity_id_field: str | None = "entity_id"
+    event_type_field: str | None = "event_type"
+    metadata_field: str | None = "metadata"
+    account_key_field: str | None = None
+    default_account_key: str = "default"
+    default_event_type: str | None = None
+    static_metadata: dict[str, Any] = field(default_factory=dict)
+
+    @classmethod
+    def from_json(cls, raw: str) -> SchemaMapping:
+        payload = json.loads(raw)
+        if not isinstance(payload, dict):
+            msg = "schema mapping must be a JSON object"
+            raise ValueError(msg)
+        allowed = set(cls.__dataclass_fields__)
+        unknown = sorted(set(payload) - allowed)
+        if unknown:
+            msg = f"unknown schema mapping keys: {', '.join(unknown)}"
+            raise ValueError(msg)
+        return cls(**payload)
+
+    def map(self, value: bytes | str) -> tuple[str, IngestRequest]:
+        """Return ``(account_key, request)`` for one message body."""
+        try:
+            document = json.loads(value)
+        except json.JSONDecodeError as exc:
+            msg = "message body is not valid JSON"
+            raise ValueError(msg) from exc
+        if not isinstance(document, dict):
+            msg = "message body must be a JSON object"
+            raise ValueError(msg)
+        content = _lookup(document, self.content_field)
+        if content is None:
+            msg = f"message is missing content field '{self.content_field}'"
+            raise ValueError(msg)
+        metadata_value = _lookup(document, self.metadata_field)
+        metadata = dict(self.static_metadata)
+        if isinstance(metadata_value, dict):
+            metadata.update(metadata_value)
+        entity_id = _lookup(document, self.entity_id_field)
+        event_type = _lookup(document, self.event_type_field) or self.default_event_type
+        account_key = _lookup(document, self.account_key_field) or self.default_account_key
+        request = IngestRequest(
+            content=content if isinstance(content, str) else json.dumps(content),
+            entity_id=str(entity_id) if entity_id is not None else None,
+            event_type=str(event_type) if event_type is not None else None,
+            metadata=metadata or None,
+        )
+        return str(account_key), request
+
+
+@dataclass
+class StreamMessage:
+    value: bytes
+    idempotency_key: str | None
+    ack: Callable[[], None] = lambda: None
+
+
+class MessageSource(Protocol):
+    def messages(self) -> Iterator[StreamMessage]: ...
+
+    def close(self) -> None: ...
+
+
+class StreamSourceError(RuntimeError):
+    """The source reported an error it cannot recover from; the connector stops."""
+
+
+@dataclass
+class ConnectorStats:
+    ingested: int = 0
+    replayed: int = 0
+    rejected: int = 0
+    source_errors: int = 0
+
+
+class StreamConnector:
+    """Pull messages from a source and ingest them with idempotent at-least-once delivery."""
+
+    def __init__(
+        self,
+        service: OrbitApiService,
+        source: MessageSource,
+        mapping: SchemaMapping,
+        *,
+        max_backoff_seconds: float = 60.0,
+        sleep: Callable[[float], None] = time.sleep,
+    ) -> None:
+        self._service = service
+        self._source = source
+        self._mapping = mapping
+        self._max_backoff_seconds = max_backoff_seconds
+        self._sleep = sleep
+        self._log = get_logger("orbit.api.stream_connector")
+        self.stats = ConnectorStats()
+
+    def run(self, max_messages: int | None = None) -> ConnectorStats:
+        processed = 0
+        try:
+            for message in self._source.messages():
+                self._handle(message)
+                processed += 1
+                if max_messages is not None and processed >= max_messages:
+                    break
+        finally:
+            self.stats.source_errors = int(getattr(self._source, "errors", 0))
+            self._source.close()
+        return self.stats
+
+    def _handle(self, message: StreamMessage) -> None:
+        try:
+            account_key, request = self._mapping.map(message.value)
+        except (ValueError, ValidationError) as exc:
+            self.stats.rejected += 1
+            self._log.warning(
+                "stream_message_rejected",
+                idempotency_key=message.idempotency_key,
+                error=str(exc),
+            )
+            message.ack()
+            return
+        while True:
+            try:
+                _result, _snapshot, replayed = self._service.ingest_with_quota(
+                    account_key=account_key,
+                    request=request,
+                    idempotency_key=message.idempotency_key,
+                )
+            except RateLimitExceededError as exc:
+                delay = min(float(exc.retry_after_seconds), self._max_backoff_seconds)
+                self._log.warning(
+                    "stream_quota_backoff",
+                    account=account_key,
+                    retry_after_seconds=delay,
+                )
+                self._sleep(max(delay, 1.0))
+                continue
+            except (ValueError, IdempotencyConflictError) as exc:
+                self.stats.rejected += 1
+                self._log.warning(
+                    "stream_message_rejected",
+                    idempotency_key=message.idempotency_key,
+                    error=str(exc),
+                )
+                break
+            if replayed:
+                self.stats.replayed += 1
+            else:
+                self.stats.ingested += 1
+            break
+        message.ack()
+
+
+class KafkaSource:
+    """Kafka consumer with manual offset commits after each ingested message.
+
+    Consumer errors are logged and counted in ``errors``; a fatal one raises
+    ``StreamSourceError``, since the consumer cannot make progress after it.
+    """
+
+    def __init__(
+        self,
+        *,
+        brokers: str,
+        topic: str,
+        group_id: str,
+        poll_timeout_seconds: float = 1.0,
+        extra_config: dict[str, Any] | None = None,
+    ) -> None:
+        if confluent_kafka_module is None:
+            msg = (
+                "Kafka connector requires confluent-kafka. "
+                "Install with: pip install orbit-memory[kafka]"
+            )
+            raise RuntimeError(msg)
+        self._topic = topic
+        self._poll_timeout_seconds = poll_timeout_seconds
+        self._consumer = confluent_kafka_module.Consumer(
+            {
+                "bootstrap.servers": brokers,
+                "group.id": group_id,
+                "enable.auto.commit": False,
+                "auto.offset.reset": "earliest",
+                **(extra_config or {}),
+            }
+        )
+        self._consumer.subscribe([topic])
+        self._closed = False
+        self._log = get_logger("orbit.api.stream_connector")
+        self.errors = 0
+
+    def messages(self) -> Iterator[StreamMessage]:
+        while not self._closed:
+            record = self._consumer.poll(self._poll_timeout_seconds)
+            if record is None:
+                continue
+            error = record.error()
+            if error:
+                self.errors += 1
+                self._log.warning(
+                    "kafka_consumer_error",
+                    topic=self._topic,
+                    code=error.code(),
+                    error=str(error),
+                    fatal=error.fatal(),
+                )
+                if error.fatal():
+                    msg = f"Kafka consumer failed: {error}"
+                    raise StreamSourceError(msg)
+                continue
+            yield StreamMessage(
+                value=record.value() or b"",
+                idempotency_key=(
+                    f"kafka:{record.topic()}:{record.partition()}:{record.offset()}"
+                ),
+                ack=lambda record=record: self._consumer.commit(
+                    message=record,
+                    asynchronous=False,
+                ),
+            )
+
+    def close(self) -> None:
+        if not self._closed:
+            self._closed = True
+            self._consumer.close()
+
+
+class NatsSource:
+    """Core NATS subscriber (optionally a queue group) bridged onto a blocking iterator.
+
+    Publishers that set ``Nats-Msg-Id`` get idempotent redelivery handling.
+    """
+
+    def __init__(
+        self,
+        *,
+        servers: str,
+        subject: str,
+        queue_group: str = "",
+        poll_timeout_seconds: float = 1.0,
+    ) -> None:
+        if nats_module is None:
+            msg = (
+                "NATS connector requires nats-py. "
+                "Install with: pip install orbit-memory[nats]"
+            )
+            raise RuntimeError(msg)
+        self._servers = [item.strip() for item in servers.split(",") if item.strip()]
+        self._subject = subject
+        self._queue_group = queue_group
+        self._poll_timeout_seconds = poll_timeout_seconds
+        self._loop = asyncio.new_event_loop()
+        self._client: Any = None
+        self._subscription: Any = None
+        self._closed = False
+
+    def messages(self) -> Iterator[StreamMessage]:
+        self._loop.run_until_complete(self._connect())
+        while not self._closed:
+            try:
+                message = self._loop.run_until_complete(
+                    self._subscription.next_msg(timeout=self._poll_timeout_seconds)
+                )
+            except TimeoutError:
+                continue
+            headers = message.headers or {}
+            message_id = headers.get("Nats-Msg-Id")
+            yield StreamMessage(
+                value=message.data,
+                idempotency_key=(
+                    f"nats:{message.subject}:{message_id}" if message_id else None
+                ),
+            )
+
+    def close(self) -> None:
+        if self._closed:
+            return
+        self._closed = True
+        if self._client is not None:
+            self._loop.run_until_complete(self._client.drain())
+        self._loop.close()
+
+    async def _connect(self) -> None:
+        self._client = await nats_module.connect(servers=self._servers)  # type: ignore[union-attr]
+        self._subscription = await self._client.subscribe(
+            self._subject,
+            queue=self._queue_group,
+        )
+
+
+def _lookup(document: dict[str, Any], path: str | None) -> Any:
+    if not path:
+        return None
+    current: Any = document
+    for segment in path.split("."):
+        if not isinstance(current, dict) or segment not in current:
+            return None
+        current = current[segment]
+    return current
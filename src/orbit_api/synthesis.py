@@ -0,0 +1,169 @@
+"""LLM calls over memories: ``summarize=true``, ``/v1/ask``, ``/v1/chat`` and digests.
+
+The memories are sent to an OpenAI-compatible ``/chat/completions`` endpoint, each prefixed
+with its ID in brackets, and the model is asked for one paragraph (or, for ``/v1/ask``, a JSON
+answer with a confidence) that cites the memories it uses as ``[memory_id]``. Citations are
+read back from the text; IDs the model invents are dropped. ``/v1/chat`` instead puts the
+memories in the system prompt ahead of the session's earlier turns. Digests ask for a recap
+of one period's new memories, cited the same way.
+"""
+
+from __future__ import annotations
+
+import json
+import re
+from dataclasses import dataclass
+from typing import Any
+
+import httpx
+
+from orbit.models import Memory
+from orbit_api.tracing import outbound_headers
+
+SYSTEM_PROMPT = (
+    "You consolidate memories retrieved for a query into one short paragraph of context for "
+    "an AI agent. Use only facts stated in the memories. After each statement, cite the "
+    "memories it comes from by their IDs in square brackets, for example [mem_1]. When "
+    "memories disagree, say so and cite both. Do not add a preamble."
+)
+ANSWER_PROMPT = (
+    "You answer a question using only the memories provided. Cite the memories each "
+    "statement relies on by their IDs in square brackets, for example [mem_1]. Reply with a "
+    'JSON object and nothing else: {"answer": "...", "confidence": 0.0}. confidence is '
+    "between 0 and 1 and reflects how well the memories support the answer. If the memories "
+    'do not answer the question, reply {"answer": null, "confidence": 0.0}.'
+)
+CHAT_PROMPT = (
+    "You are a helpful assistant that remembers earlier conversations with this user. Use the "
+    "memories below when they are relevant to the user's message and ignore them otherwise. "
+    "Do not mention that you have a memory unless the user asks."
+)
+DIGEST_PROMPT = (
+    "You write a short recap of what an AI agent stored in its memory over a period, for the "
+    "people who oversee it. Group related memories, lead with the most important, and use "
+    "only facts stated in the memories. Cite the memories each statement comes from by their "
+    "IDs in square brackets, for example [mem_1]. Do not add a preamble."
+)
+_JSON_FENCE = re.compile(r"^```(?:json)?\s*|\s*```$")
+_CITATION = re.compile(r"\[([^\[\]]+)\]")
+
+
+class SummarizationError(RuntimeError):
+    """The completion endpoint timed out, failed, or returned no usable text."""
+
+
+@dataclass(frozen=True)
+class SummaryTarget:
+    base_url: str
+    api_key: str | None
+    model: str
+    timeout_seconds: float
+
+
+def summary_messages(query: str, memories: list[Memory]) -> list[dict[str, str]]:
+    listed = "\n".join(f"[{memory.memory_id}] {memory.content}" for memory in memories)
+    return [
+        {"role": "system", "content": SYSTEM_PROMPT},
+        {"role": "user", "content": f"Query: {query}\n\nMemories:\n{listed}"},
+    ]
+
+
+def answer_messages(question: str, memories: list[Memory]) -> list[dict[str, str]]:
+    listed = "\n".join(f"[{memory.memory_id}] {memory.content}" for memory in memories)
+    return [
+        {"role": "system", "content": ANSWER_PROMPT},
+        {"role": "user", "content": f"Question: {question}\n\nMemories:\n{listed}"},
+    ]
+
+
+def digest_messages(period: str, memories: list[Memory]) -> list[dict[str, str]]:
+    listed = "\n".join(f"[{memory.memory_id}] {memory.content}" for memory in memories)
+    return [
+        {"role": "system", "content": DIGEST_PROMPT},
+        {"role": "user", "content": f"Period: {period}\n\nNew memories:\n{listed}"},
+    ]
+
+
+def chat_messages(
+    history: list[tuple[str, str]],
+    memories: list[Memory],
+    message: str,
+) -> list[dict[str, str]]:
+    """System prompt with ``memories``, then the ``(role, content)`` history, then ``message``."""
+    system = CHAT_PROMPT
+    if memories:
+        listed = "\n".join(f"- {memory.content}" for memory in memories)
+        system = f"{system}\n\nRelevant memories about this user:\n{listed}"
+    return [
+        {"role": "system", "content": system},
+        *({"role": role, "content": content} for role, content in history),
+        {"role": "user", "content": message},
+    ]
+
+
+def parse_answer(text: str) -> tuple[str | None, float]:
+    """``(answer, confidence)`` from a reply to ``answer_messages``."""
+    try:
+        payload = json.loads(_JSON_FENCE.sub("", text.strip()))
+    except ValueError as exc:
+        msg = "answer is not valid JSON"
+        raise SummarizationError(msg) from exc
+    if not isinstance(payload, dict):
+        msg = "answer must be a JSON object"
+        raise SummarizationError(msg)
+    answer = payload.get("answer")
+    confidence = payload.get("confidence", 0.0)
+    if answer is not None and not isinstance(answer, str):
+        msg = "answer must be a string or null"
+        raise SummarizationError(msg)
+    if isinstance(confidence, bool) or not isinstance(confidence, int | float):
+        msg = "confidence must be a number"
+        raise SummarizationError(msg)
+    answer = answer.strip() if answer else None
+    return answer or None, float(min(1.0, max(0.0, confidence))) if answer else 0.0
+
+
+def call_summarizer(target: SummaryTarget, messages: list[dict[str, str]]) -> str:
+    """Return the completion text for ``messages``."""
+    headers = {"Content-Type": "application/json", **outbound_headers()}
+    if target.api_key:
+        headers["Authorization"] = f"Bearer {target.api_key}"
+    body: dict[str, Any] = {"model": target.model, "messages": messages, "temperature": 0}
+    try:
+        with httpx.Client(timeout=target.timeout_seconds) as client:
+            response = client.post(
+                f"{target.base_url.rstrip('/')}/chat/completions",
+                json=body,
+                headers=headers,
+            )
+    except httpx.TimeoutException as exc:
+        msg = f"completion timed out after {target.timeout_seconds:g} s"
+        raise SummarizationError(msg) from exc
+    except httpx.HTTPError as exc:
+        msg = f"completion request failed: {exc}"
+        raise SummarizationError(msg) from exc
+    if response.status_code >= 300:
+        msg = f"completion endpoint returned HTTP {response.status_code}"
+        raise SummarizationError(msg)
+    try:
+        text = response.json()["choices"][0]["message"]["content"]
+    except (ValueError, KeyError, IndexError, TypeError) as exc:
+        msg = "completion endpoint returned an unexpected reply"
+        raise SummarizationError(msg) from exc
+    if not isinstance(text, str) or not text.strip():
+        msg = "completion endpoint returned an empty reply"
+        raise SummarizationError(msg)
+    return text.strip()
+
+
+def cited_memory_ids(text: str, memory_ids: list[str]) -> list[str]:
+    """Memory IDs cited in ``text``, in order of first citation, limited to ``memory_ids``."""
+    known = set(memory_ids)
+    cited: list[str] = []
+    for match in _CITATION.finditer(text):
+        # A single bracket may cite several memories: [mem_1, mem_2].
+        for item in match.group(1).split(","):
+            memory_id = item.strip()
+            if memory_id in known and memory_id not in cited:
+                cited.append(memory_id)
+    return cited
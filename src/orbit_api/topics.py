@@ -0,0 +1,169 @@
+"""Group an entity's memories into topics by embedding similarity.
+
+HDBSCAN is used when installed (``pip install orbit-memory[topics]``); otherwise memories are
+linked when their cosine similarity clears a threshold and communities are found with label
+propagation over that graph. Both are deterministic for a given set of memories.
+"""
+
+from __future__ import annotations
+
+import hashlib
+import math
+import re
+from collections import Counter
+from dataclasses import dataclass
+from importlib import import_module
+from types import ModuleType
+
+import numpy as np
+
+from decision_engine.models import MemoryRecord
+
+MAX_TOPIC_MEMORIES = 2000
+_SIMILARITY_THRESHOLD = 0.6
+_LABEL_PROPAGATION_ROUNDS = 20
+_KEYWORD_PATTERN = re.compile(r"[a-z][a-z0-9]{2,}")
+_STOPWORDS = frozenset(
+    (
+        "the and for with that this from are was were has have had not but you your they "
+        "their them she her his him its our about into what when which who will would can "
+        "could should also just than then there these those been being does did how why all "
+        "any more most some such very user assistant inferred"
+    ).split()
+)
+
+
+def _optional_import(module_name: str) -> ModuleType | None:
+    try:  # pragma: no cover - optional dependency
+        return import_module(module_name)
+    except ImportError:  # pragma: no cover - optional dependency
+        return None
+
+
+hdbscan_module: ModuleType | None = _optional_import("hdbscan")
+
+
+@dataclass(frozen=True)
+class TopicCluster:
+    topic_id: str
+    label: str
+    summary: str
+    keywords: tuple[str, ...]
+    memory_ids: tuple[str, ...]
+
+
+def clustering_algorithm() -> str:
+    return "hdbscan" if hdbscan_module is not None else "label_propagation"
+
+
+def cluster_memories(
+    records: list[MemoryRecord],
+    *,
+    min_cluster_size: int = 3,
+) -> list[TopicCluster]:
+    """Cluster records into topics, largest first; records that fit no topic are left out."""
+    records = sorted(records, key=lambda item: item.created_at, reverse=True)[:MAX_TOPIC_MEMORIES]
+    if len(records) < min_cluster_size:
+        return []
+    vectors = np.asarray([record.semantic_embedding for record in records], dtype=np.float32)
+    norms = np.linalg.norm(vectors, axis=1, keepdims=True)
+    vectors = vectors / np.where(norms == 0.0, 1.0, norms)
+    similarity = vectors @ vectors.T
+
+    if hdbscan_module is not None:
+        labels = [
+            int(label)
+            for label in hdbscan_module.HDBSCAN(
+                min_cluster_size=min_cluster_size,
+                metric="euclidean",
+            ).fit_predict(vectors)
+        ]
+    else:
+        labels = _label_propagation(similarity)
+
+    members: dict[int, list[int]] = {}
+    for index, label in enumerate(labels):
+        if label >= 0:
+            members.setdefault(label, []).append(index)
+    groups = [indexes for indexes in members.values() if len(indexes) >= min_cluster_size]
+
+    document_frequency: Counter[str] = Counter()
+    for record in records:
+        document_frequency.update(set(_keywords(record)))
+
+    clusters: list[TopicCluster] = []
+    for indexes in sorted(groups, key=lambda group: (-len(group), group[0])):
+        medoid = _medoid(similarity, indexes)
+        keywords = _cluster_keywords(
+            [records[i] for i in indexes],
+            document_frequency=document_frequency,
+            total_documents=len(records),
+        )
+        medoid_record = records[medoid]
+        clusters.append(
+            TopicCluster(
+                topic_id="topic_"
+                + hashlib.sha256(medoid_record.memory_id.encode("utf-8")).hexdigest()[:12],
+                label=" / ".join(keywords[:3]) or medoid_record.summary[:60],
+                summary=medoid_record.summary or medoid_record.content[:200],
+                keywords=tuple(keywords),
+                memory_ids=tuple(records[i].memory_id for i in indexes),
+            )
+        )
+    return clusters
+
+
+def _medoid(similarity: np.ndarray, indexes: list[int]) -> int:
+    """Member with the highest mean similarity to the rest of its cluster."""
+    means = similarity[np.ix_(indexes, indexes)].mean(axis=1)
+    return indexes[int(np.argmax(means))]
+
+
+def _label_propagation(similarity: np.ndarray) -> list[int]:
+    """Community detection on the thresholded similarity graph; isolated nodes get -1."""
+    size = similarity.shape[0]
+    neighbors = [
+        [j for j in range(size) if j != i and similarity[i, j] >= _SIMILARITY_THRESHOLD]
+        for i in range(size)
+    ]
+    labels = list(range(size))
+    for _ in range(_LABEL_PROPAGATION_ROUNDS):
+        changed = False
+        for i in range(size):
+            if not neighbors[i]:
+                continue
+            weights: dict[int, float] = {}
+            for j in neighbors[i]:
+                weights[labels[j]] = weights.get(labels[j], 0.0) + float(similarity[i, j])
+            best = min(weights, key=lambda label: (-weights[label], label))
+            if best != labels[i]:
+                labels[i] = best
+                changed = True
+        if not changed:
+            break
+    return [label if neighbors[i] else -1 for i, label in enumerate(labels)]
+
+
+def _keywords(record: MemoryRecord) -> list[str]:
+    return [
+        token
+        for token in _KEYWORD_PATTERN.findall(f"{record.summary} {record.content}".lower())
+        if token not in _STOPWORDS
+    ]
+
+
+def _cluster_keywords(
+    records: list[MemoryRecord],
+    *,
+    document_frequency: Counter[str],
+    total_documents: int,
+) -> list[str]:
+    counts: Counter[str] = Counter()
+    for record in records:
+        counts.update(set(_keywords(record)))
+    scored = {
+        token: count * math.log((1 + total_documents) / (1 + document_frequency[token]))
+        for token, count in counts.items()
+        if count > 1 or len(records) == 1
+    }
+    return sorted(scored, key=lambda token: (-scored[token], token))[:5]
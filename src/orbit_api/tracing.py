@@ -0,0 +1,125 @@
+"""Request-id and W3C trace-context passthrough.
+
+Every API request is bound to a ``TraceContext`` for its lifetime: the caller's
+``X-Request-ID`` (or a generated one) and, when valid, its ``traceparent``/``tracestate``.
+Outbound calls the API makes on the request's behalf (webhooks, region forwarding,
+replication, the chat proxy upstream) copy those headers, and responses echo them, so a
+caller's distributed trace continues through Orbit without OpenTelemetry being installed.
+"""
+
+from __future__ import annotations
+
+import re
+import uuid
+from collections.abc import Callable, Iterator, Mapping
+from concurrent.futures import Executor, Future
+from contextlib import contextmanager
+from contextvars import ContextVar, copy_context
+from dataclasses import dataclass
+from typing import Any
+
+import structlog
+
+REQUEST_ID_HEADER = "X-Request-ID"
+TRACEPARENT_HEADER = "traceparent"
+TRACESTATE_HEADER = "tracestate"
+
+_MAX_REQUEST_ID_CHARS = 128
+_MAX_TRACESTATE_CHARS = 512
+_REQUEST_ID_PATTERN = re.compile(r"^[A-Za-z0-9._:/+=@-]+$")
+_TRACEPARENT_PATTERN = re.compile(r"^([0-9a-f]{2})-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$")
+
+
+@dataclass(frozen=True, slots=True)
+class TraceContext:
+    request_id: str
+    traceparent: str | None = None
+    tracestate: str | None = None
+
+    @property
+    def trace_id(self) -> str | None:
+        return self.traceparent.split("-")[1] if self.traceparent else None
+
+    @classmethod
+    def from_headers(cls, headers: Mapping[str, str]) -> TraceContext:
+        """Context for an inbound request; a request id is generated when none is usable."""
+        lowered = {key.lower(): value for key, value in headers.items()}
+        traceparent = normalize_traceparent(lowered.get(TRACEPARENT_HEADER))
+        tracestate = lowered.get(TRACESTATE_HEADER, "").strip() or None
+        if tracestate is not None and len(tracestate) > _MAX_TRACESTATE_CHARS:
+            tracestate = None
+        return cls(
+            request_id=normalize_request_id(lowered.get(REQUEST_ID_HEADER.lower()))
+            or new_request_id(),
+            traceparent=traceparent,
+            tracestate=tracestate if traceparent else None,
+        )
+
+    def as_headers(self) -> dict[str, str]:
+        headers = {REQUEST_ID_HEADER: self.request_id}
+        if self.traceparent:
+            headers[TRACEPARENT_HEADER] = self.traceparent
+            if self.tracestate:
+                headers[TRACESTATE_HEADER] = self.tracestate
+        return headers
+
+
+_current: ContextVar[TraceContext | None] = ContextVar("orbit_trace_context", default=None)
+
+
+def new_request_id() -> str:
+    return uuid.uuid4().hex
+
+
+def normalize_request_id(value: str | None) -> str | None:
+    """Return ``value`` when it is a safe, bounded header token, otherwise ``None``."""
+    if value is None:
+        return None
+    stripped = value.strip()
+    if not stripped or len(stripped) > _MAX_REQUEST_ID_CHARS:
+        return None
+    return stripped if _REQUEST_ID_PATTERN.match(stripped) else None
+
+
+def normalize_traceparent(value: str | None) -> str | None:
+    """Validate a W3C ``traceparent``; all-zero ids and version ``ff`` are rejected."""
+    if value is None:
+        return None
+    match = _TRACEPARENT_PATTERN.match(value.strip().lower())
+    if match is None:
+        return None
+    version, trace_id, span_id, _ = match.groups()
+    if version == "ff" or set(trace_id) == {"0"} or set(span_id) == {"0"}:
+        return None
+    return match.group(0)
+
+
+def current() -> TraceContext | None:
+    return _current.get()
+
+
+@contextmanager
+def bind_trace_context(context: TraceContext) -> Iterator[TraceContext]:
+    """Make ``context`` current, and visible to structlog as ``request_id``/``trace_id``."""
+    token = _current.set(context)
+    log_fields: dict[str, Any] = {"request_id": context.request_id}
+    if context.trace_id:
+        log_fields["trace_id"] = context.trace_id
+    with structlog.contextvars.bound_contextvars(**log_fields):
+        try:
+            yield context
+        finally:
+            _current.reset(token)
+
+
+def outbound_headers() -> dict[str, str]:
+    """Headers that carry the current request's trace onto an outbound call."""
+    context = _current.get()
+    return context.as_headers() if context is not None else {}
+
+
+def submit_with_context(
+    executor: Executor, fn: Callable[..., Any], /, *args: Any, **kwargs: Any
+) -> Future[Any]:
+    """``executor.submit`` that keeps the caller's trace context inside the worker thread."""
+    return executor.submit(copy_context().run, fn, *args, **kwargs)
@@ -0,0 +1,206 @@
+"""Fetch URL sources and reduce them to the text Orbit stores.
+
+A URL source is a page Orbit re-fetches on a schedule. Each crawl hashes the extracted text,
+so an unchanged page only refreshes its crawl time, and a changed one is re-ingested as a
+revision of the earlier crawl. Fetches refuse hosts that resolve to private, loopback, or
+link-local addresses, including after redirects, so a source cannot reach internal services.
+
+Outbound requests go through ``PinnedTransport``, which connects to the very address the check
+approved instead of letting the client resolve the host a second time: otherwise a host could
+answer the check with a public address and the connection with an internal one (DNS
+rebinding). Operators can narrow outbound traffic further with ``OutboundPolicy``: a host
+allowlist and an egress proxy every request is sent through.
+"""
+
+from __future__ import annotations
+
+import hashlib
+import ipaddress
+import socket
+from dataclasses import dataclass
+from html.parser import HTMLParser
+from urllib.parse import urljoin, urlparse
+
+import httpx
+
+FETCH_TIMEOUT_SECONDS = 10.0
+MAX_FETCH_BYTES = 5_000_000
+MAX_REDIRECTS = 5
+TEXT_CONTENT_TYPES = ("text/html", "application/xhtml+xml", "text/plain", "text/markdown")
+
+_SKIPPED_TAGS = {"script", "style", "noscript", "template", "svg", "head"}
+_BLOCK_TAGS = {
+    "address", "article", "aside", "blockquote", "br", "dd", "div", "dl", "dt", "figcaption",
+    "footer", "form", "h1", "h2", "h3", "h4", "h5", "h6", "header", "hr", "li", "main", "nav",
+    "ol", "p", "pre", "section", "table", "td", "th", "tr", "ul",
+}  # fmt: skip
+
+
+class UrlFetchError(RuntimeError):
+    """Raised when a URL source cannot be fetched or holds no text."""
+
+
+@dataclass(frozen=True)
+class OutboundPolicy:
+    """Where outbound fetches may go (ORBIT_OUTBOUND_ALLOWED_HOSTS) and the proxy they use.
+
+    An ``allowed_hosts`` entry matches that hostname, or any subdomain when written
+    ``*.example.com``. No entries allows every public host.
+    """
+
+    allowed_hosts: tuple[str, ...] = ()
+    proxy_url: str | None = None
+
+    def allows(self, hostname: str) -> bool:
+        if not self.allowed_hosts:
+            return True
+        name = hostname.lower().rstrip(".")
+        for entry in self.allowed_hosts:
+            pattern = entry.strip().lower().rstrip(".")
+            if name == pattern or (pattern.startswith("*.") and name.endswith(pattern[1:])):
+                return True
+        return False
+
+
+class PinnedTransport(httpx.HTTPTransport):
+    """Send each request to the public address its host was checked at.
+
+    Every request, including each redirect hop, is checked with ``require_public_host`` and
+    its URL rewritten to the approved address. The ``Host`` header and the TLS server name
+    keep the original hostname, so virtual hosting and certificate checks are unaffected.
+    """
+
+    def __init__(self, policy: OutboundPolicy | None = None) -> None:
+        self._policy = policy or OutboundPolicy()
+        super().__init__(proxy=self._policy.proxy_url)
+
+    def handle_request(self, request: httpx.Request) -> httpx.Response:
+        hostname = request.url.host
+        address = require_public_host(str(request.url), policy=self._policy)
+        request.url = request.url.copy_with(host=f"[{address}]" if ":" in address else address)
+        request.extensions = {**request.extensions, "sni_hostname": hostname}
+        return super().handle_request(request)
+
+
+@dataclass(frozen=True)
+class FetchedPage:
+    url: str
+    status_code: int
+    content_type: str
+    text: str
+
+
+def fetch_url(url: str, *, policy: OutboundPolicy | None = None) -> FetchedPage:
+    """GET ``url``, following public redirects, and return its extracted text."""
+    current = url
+    # trust_env=False: proxies from the environment would bypass the pinned transport.
+    with httpx.Client(
+        timeout=FETCH_TIMEOUT_SECONDS,
+        follow_redirects=False,
+        transport=PinnedTransport(policy),
+        trust_env=False,
+    ) as client:
+        for _ in range(MAX_REDIRECTS + 1):
+            headers = {"Accept": ", ".join(TEXT_CONTENT_TYPES)}
+            with client.stream("GET", current, headers=headers) as resp:
+                if resp.is_redirect and "location" in resp.headers:
+                    current = urljoin(current, resp.headers["location"])
+                    continue
+                if resp.status_code >= 400:
+                    msg = f"{current} returned HTTP {resp.status_code}"
+                    raise UrlFetchError(msg)
+                content_type = resp.headers.get("content-type", "text/html")
+                body = bytearray()
+                for part in resp.iter_bytes():
+                    body.extend(part)
+                    if len(body) > MAX_FETCH_BYTES:
+                        msg = f"{current} is larger than {MAX_FETCH_BYTES} bytes"
+                        raise UrlFetchError(msg)
+                text = extract_text(bytes(body), content_type, encoding=resp.encoding)
+                return FetchedPage(
+                    url=current,
+                    status_code=resp.status_code,
+                    content_type=content_type,
+                    text=text,
+                )
+    msg = f"{url} redirected more than {MAX_REDIRECTS} times"
+    raise UrlFetchError(msg)
+
+
+def extract_text(body: bytes, content_type: str, *, encoding: str | None = None) -> str:
+    """Readable text of an HTML or plain-text response; blank lines separate blocks."""
+    media_type = content_type.split(";", 1)[0].strip().lower()
+    if media_type not in TEXT_CONTENT_TYPES:
+        msg = f"unsupported content type {media_type!r}"
+        raise UrlFetchError(msg)
+    decoded = body.decode(encoding or "utf-8", errors="replace")
+    if media_type in {"text/plain", "text/markdown"}:
+        text = decoded
+    else:
+        parser = _TextExtractor()
+        parser.feed(decoded)
+        parser.close()
+        text = "".join(parser.parts)
+    blocks = [" ".join(block.split()) for block in text.split("\n")]
+    cleaned = "\n\n".join(block for block in blocks if block)
+    if not cleaned:
+        msg = "page has no text content"
+        raise UrlFetchError(msg)
+    return cleaned
+
+
+def content_hash(text: str) -> str:
+    """Hash of the page text with whitespace normalized, so re-flowed markup is not a change."""
+    return hashlib.sha256(" ".join(text.split()).encode("utf-8")).hexdigest()
+
+
+def require_public_host(url: str, *, policy: OutboundPolicy | None = None) -> str:
+    """Return the address to connect to for ``url``.
+
+    Raises ``UrlFetchError`` unless ``url`` is http(s) on a host ``policy`` allows whose
+    addresses are all public.
+    """
+    parsed = urlparse(url)
+    if parsed.scheme not in {"http", "https"} or not parsed.hostname:
+        msg = f"not an http(s) URL: {url}"
+        raise UrlFetchError(msg)
+    if policy is not None and not policy.allows(parsed.hostname):
+        msg = f"{parsed.hostname} is not in ORBIT_OUTBOUND_ALLOWED_HOSTS"
+        raise UrlFetchError(msg)
+    try:
+        infos = socket.getaddrinfo(parsed.hostname, parsed.port or None, type=socket.SOCK_STREAM)
+    except OSError as exc:
+        msg = f"cannot resolve {parsed.hostname}: {exc}"
+        raise UrlFetchError(msg) from exc
+    addresses = [ipaddress.ip_address(info[4][0]) for info in infos]
+    if not addresses:
+        msg = f"cannot resolve {parsed.hostname}: no addresses"
+        raise UrlFetchError(msg)
+    for address in addresses:
+        if not address.is_global:
+            msg = f"{parsed.hostname} resolves to a non-public address"
+            raise UrlFetchError(msg)
+    return str(addresses[0])
+
+
+class _TextExtractor(HTMLParser):
+    def __init__(self) -> None:
+        super().__init__(convert_charrefs=True)
+        self.parts: list[str] = []
+        self._skipping = 0
+
+    def handle_starttag(self, tag: str, attrs: list[tuple[str, str | None]]) -> None:
+        if tag in _SKIPPED_TAGS:
+            self._skipping += 1
+        elif tag in _BLOCK_TAGS:
+            self.parts.append("\n")
+
+    def handle_endtag(self, tag: str) -> None:
+        if tag in _SKIPPED_TAGS:
+            self._skipping = max(0, self._skipping - 1)
+        elif tag in _BLOCK_TAGS:
+            self.parts.append("\n")
+
+    def handle_data(self, data: str) -> None:
+        if not self._skipping:
+            self.parts.append(data)
@@ -0,0 +1,129 @@
+"""Structured 422 bodies listing every invalid request field.
+
+Every validation failure, whether FastAPI rejected the request before the endpoint ran, a model
+built inside an endpoint failed, or an endpoint check such as the ingest content limit tripped,
+is answered with the same shape::
+
+    {"detail": {"message": "...", "error_code": "validation_error",
+                "errors": [{"field": "events.2.content", "location": "body",
+                            "constraint": "max_length", "message": "...", "limit": 20000}]}}
+
+``field`` is the dotted path inside ``location`` (``body``, ``query``, ``path`` or ``header``).
+``constraint`` names the rule that failed, and ``limit`` carries its bound when there is one.
+"""
+
+from __future__ import annotations
+
+from collections.abc import Iterable, Mapping, Sequence
+from dataclasses import dataclass
+from typing import Any
+
+VALIDATION_ERROR_CODE = "validation_error"
+
+_LOCATIONS = frozenset({"body", "query", "path", "header", "cookie"})
+
+# Pydantic error types mapped to the constraint names clients see.
+_CONSTRAINTS = {
+    "missing": "required",
+    "extra_forbidden": "unknown_field",
+    "string_too_short": "min_length",
+    "string_too_long": "max_length",
+    "too_short": "min_items",
+    "too_long": "max_items",
+    "greater_than": "gt",
+    "greater_than_equal": "ge",
+    "less_than": "lt",
+    "less_than_equal": "le",
+    "string_pattern_mismatch": "pattern",
+    "literal_error": "enum",
+    "enum": "enum",
+    "json_invalid": "json",
+    "value_error": "invalid",
+    "assertion_error": "invalid",
+}
+
+# ``ctx`` keys that hold the bound of the failed rule, in lookup order.
+_LIMIT_KEYS = ("max_length", "min_length", "le", "ge", "lt", "gt", "pattern", "expected")
+
+
+@dataclass(frozen=True)
+class FieldError:
+    field: str
+    constraint: str
+    message: str
+    location: str = "body"
+    limit: Any = None
+
+    def as_dict(self) -> dict[str, Any]:
+        payload: dict[str, Any] = {
+            "field": self.field,
+            "location": self.location,
+            "constraint": self.constraint,
+            "message": self.message,
+        }
+        if self.limit is not None:
+            payload["limit"] = self.limit
+        return payload
+
+
+class FieldValidationError(Exception):
+    """Raised by endpoints to answer 422 with one entry per invalid field."""
+
+    def __init__(self, errors: Sequence[FieldError]) -> None:
+        self.errors = list(errors)
+        super().__init__(validation_message(self.errors))
+
+
+def field_errors_from_pydantic(
+    errors: Iterable[Mapping[str, Any]],
+    *,
+    location: str = "body",
+) -> list[FieldError]:
+    """Convert ``ValidationError.errors()`` entries; ``location`` applies when ``loc`` has none."""
+    converted: list[FieldError] = []
+    for error in errors:
+        loc = [str(part) for part in error.get("loc", ())]
+        item_location = location
+        if loc and loc[0] in _LOCATIONS:
+            item_location = loc.pop(0)
+        error_type = str(error.get("type", ""))
+        context = error.get("ctx") or {}
+        limit = next((context[key] for key in _LIMIT_KEYS if key in context), None)
+        message = str(error.get("msg", "invalid value"))
+        # Pydantic prefixes messages from our own validators with "Value error, ".
+        message = message.removeprefix("Value error, ")
+        converted.append(
+            FieldError(
+                field=".".join(loc) or item_location,
+                constraint=_constraint(error_type),
+                message=message,
+                location=item_location,
+                limit=limit if isinstance(limit, str | int | float) else None,
+            )
+        )
+    return converted
+
+
+def validation_error_content(errors: Sequence[FieldError]) -> dict[str, Any]:
+    return {
+        "detail": {
+            "message": validation_message(errors),
+            "error_code": VALIDATION_ERROR_CODE,
+            "errors": [error.as_dict() for error in errors],
+        }
+    }
+
+
+def validation_message(errors: Sequence[FieldError]) -> str:
+    if len(errors) == 1:
+        return f"{errors[0].field}: {errors[0].message}"
+    fields = ", ".join(dict.fromkeys(error.field for error in errors))
+    return f"{len(errors)} invalid fields: {fields}"
+
+
+def _constraint(error_type: str) -> str:
+    if error_type in _CONSTRAINTS:
+        return _CONSTRAINTS[error_type]
+    if error_type.endswith(("_type", "_parsing")):
+        return "type"
+    return error_type or "invalid"
@@ -0,0 +1,167 @@
+"""API version negotiation and deprecation headers.
+
+Routes are written once under ``/v1``. ``/v2`` serves every ``/v1`` route unchanged unless a
+``/v2`` route of its own replaces it, so v2 can evolve one endpoint at a time while v1
+integrations keep working. A client picks the version with the path, or keeps its ``/v1``
+paths and sends ``Orbit-Version: 2``.
+
+Every versioned response names the version that served it in ``Orbit-Version``. Responses
+from a deprecated version or endpoint also carry ``Deprecation`` (RFC 9745), ``Sunset``
+(RFC 8594) when removal is scheduled, and a ``Link`` to the successor, so clients notice long
+before anything breaks.
+"""
+
+from __future__ import annotations
+
+import re
+from collections.abc import Sequence
+from dataclasses import dataclass
+from datetime import datetime
+from email.utils import format_datetime
+
+from starlette.datastructures import Headers, MutableHeaders
+from starlette.requests import Request
+from starlette.responses import JSONResponse
+from starlette.routing import BaseRoute, Match, compile_path
+from starlette.types import ASGIApp, Message, Receive, Scope, Send
+
+VERSION_HEADER = "Orbit-Version"
+SUPPORTED_VERSIONS = ("v1", "v2")
+BASE_VERSION = "v1"
+UNSUPPORTED_VERSION_CODE = "unsupported_api_version"
+VERSION_CONFLICT_CODE = "api_version_conflict"
+# Set on the scope when a request is routed to another version's path, so HMAC signatures
+# are checked against the path the client actually signed.
+REQUEST_PATH_KEY = "orbit.request_path"
+
+_VERSIONED_PATH = re.compile(r"^/(v\d+)(/.*)?$")
+
+
+@dataclass(frozen=True)
+class Deprecation:
+    """A deprecated version (``path="/v1"``) or endpoint (``path="/v1/memories/{memory_id}"``)."""
+
+    path: str
+    deprecated_at: datetime
+    sunset: datetime | None = None
+    successor: str | None = None
+    # None deprecates every method on the path.
+    method: str | None = None
+
+    def matches(self, method: str, path: str) -> bool:
+        if self.method is not None and self.method.upper() != method:
+            return False
+        if "{" in self.path:
+            return compile_path(self.path)[0].match(path) is not None
+        return path == self.path or path.startswith(self.path.rstrip("/") + "/")
+
+    def headers(self, path: str) -> dict[str, str]:
+        headers = {"Deprecation": f"@{int(self.deprecated_at.timestamp())}"}
+        if self.sunset is not None:
+            headers["Sunset"] = format_datetime(self.sunset, usegmt=True)
+        if self.successor is not None:
+            # A deprecated prefix links each path to the same path under its successor.
+            successor = self.successor
+            if "{" not in self.path and path.startswith(self.path):
+                successor += path[len(self.path) :]
+            headers["Link"] = f'<{successor}>; rel="successor-version"'
+        return headers
+
+
+def parse_version(value: str) -> str | None:
+    """Normalize ``2``, ``v2``, or ``V2`` to ``v2``; ``None`` for unsupported versions."""
+    normalized = value.strip().lower()
+    if not normalized.startswith("v"):
+        normalized = f"v{normalized}"
+    return normalized if normalized in SUPPORTED_VERSIONS else None
+
+
+def api_version(request: Request) -> str:
+    """The version a request was negotiated to, for endpoints whose v2 behaviour differs."""
+    return str(getattr(request.state, "api_version", BASE_VERSION))
+
+
+def request_path(request: Request) -> str:
+    """The path the client sent, before version routing rewrote it."""
+    return str(request.scope.get(REQUEST_PATH_KEY, request.url.path))
+
+
+class ApiVersionMiddleware:
+    """Route each versioned request to its version's handler and stamp version headers.
+
+    ``routes`` is the app's live route list, so routes registered after the middleware are
+    still found.
+    """
+
+    def __init__(
+        self,
+        app: ASGIApp,
+        *,
+        routes: Sequence[BaseRoute],
+        deprecations: Sequence[Deprecation] = (),
+    ) -> None:
+        self.app = app
+        self.routes = routes
+        self.deprecations = tuple(deprecations)
+
+    async def __call__(self, scope: Scope, receive: Receive, send: Send) -> None:
+        if scope["type"] != "http":
+            await self.app(scope, receive, send)
+            return
+        path = scope["path"]
+        matched = _VERSIONED_PATH.match(path)
+        if matched is None or matched.group(1) not in SUPPORTED_VERSIONS:
+            await self.app(scope, receive, send)
+            return
+        path_version, rest = matched.group(1), matched.group(2) or ""
+
+        version = path_version
+        requested = Headers(scope=scope).get(VERSION_HEADER)
+        if requested is not None:
+            negotiated = parse_version(requested)
+            if negotiated is None:
+                supported = ", ".join(SUPPORTED_VERSIONS)
+                await _error(
+                    f"Unsupported {VERSION_HEADER} {requested!r}; supported: {supported}.",
+                    UNSUPPORTED_VERSION_CODE,
+                )(scope, receive, send)
+                return
+            if path_version != BASE_VERSION and negotiated != path_version:
+                await _error(
+                    f"{VERSION_HEADER} {negotiated} conflicts with the {path_version} path.",
+                    VERSION_CONFLICT_CODE,
+                )(scope, receive, send)
+                return
+            version = negotiated
+
+        served = f"/{version}{rest}"
+        target = served if self._has_route(served, scope["method"]) else f"/{BASE_VERSION}{rest}"
+        scope = dict(scope)
+        scope.setdefault("state", {})["api_version"] = version
+        if target != path:
+            scope["path"] = target
+            scope[REQUEST_PATH_KEY] = path
+        headers = {VERSION_HEADER: version}
+        for deprecation in self.deprecations:
+            if deprecation.matches(scope["method"], served):
+                headers.update(deprecation.headers(served))
+                break
+
+        async def send_with_headers(message: Message) -> None:
+            if message["type"] == "http.response.start":
+                MutableHeaders(scope=message).update(headers)
+            await send(message)
+
+        await self.app(scope, receive, send_with_headers)
+
+    def _has_route(self, path: str, method: str) -> bool:
+        probe = {"type": "http", "path": path, "root_path": "", "method": method}
+        return any(route.matches(probe)[0] is Match.FULL for route in self.routes)
+
+
+def _error(message: str, error_code: str) -> JSONResponse:
+    return JSONResponse(
+        status_code=400,
+        content={"detail": {"message": message, "error_code": error_code}},
+        headers={"X-Orbit-Error-Code": error_code},
+    )
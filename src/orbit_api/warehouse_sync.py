@@ -0,0 +1,473 @@
+"""Stream an account's memory changes into BigQuery or Snowflake tables for SQL analytics.
+
+Two tables are kept in the target dataset or schema:
+
+``orbit_memory_changes``
+    Append-only copy of the change feed, one row per change. Its highest ``sequence`` for an
+    account is the sync cursor, so the warehouse itself records how far the sync has got.
+``orbit_memories``
+    Current state of every memory, merged from the changes. Deleted memories stay as rows
+    with ``deleted = true`` so historical joins keep working.
+
+``ensure_schema`` creates both tables and adds any columns missing from an older deployment;
+columns are never dropped or retyped. A backfill copies every stored memory first, so memories
+written before the change log existed are included, and records a ``backfill`` change row at
+the newest change it covers so incremental syncs pick up from there.
+"""
+
+from __future__ import annotations
+
+import json
+from collections.abc import Sequence
+from dataclasses import dataclass
+from datetime import UTC, datetime
+from importlib import import_module
+from types import ModuleType
+from typing import Any, Protocol
+from uuid import uuid4
+
+from orbit.models import MemoryChange
+from orbit_api.service import OrbitApiService
+
+
+def _optional_import(module_name: str) -> ModuleType | None:
+    try:  # pragma: no cover - optional dependency
+        return import_module(module_name)
+    except ImportError:  # pragma: no cover - optional dependency
+        return None
+
+
+bigquery_module: ModuleType | None = _optional_import("google.cloud.bigquery")
+snowflake_connector_module: ModuleType | None = _optional_import("snowflake.connector")
+
+MEMORIES_TABLE = "orbit_memories"
+CHANGES_TABLE = "orbit_memory_changes"
+
+# Column name and portable type; new columns are appended here and added by ``ensure_schema``.
+MEMORY_COLUMNS: tuple[tuple[str, str], ...] = (
+    ("account_key", "string"),
+    ("memory_id", "string"),
+    ("content", "string"),
+    ("summary", "string"),
+    ("intent", "string"),
+    ("entities", "json"),
+    ("relationships", "json"),
+    ("storage_tier", "string"),
+    ("superseded_by", "string"),
+    ("created_at", "timestamp"),
+    ("updated_at", "timestamp"),
+    ("deleted", "bool"),
+    ("sequence", "int"),
+)
+CHANGE_COLUMNS: tuple[tuple[str, str], ...] = (
+    ("account_key", "string"),
+    ("sequence", "int"),
+    ("memory_id", "string"),
+    ("operation", "string"),
+    ("occurred_at", "timestamp"),
+    ("payload", "json"),
+    ("synced_at", "timestamp"),
+)
+TABLE_COLUMNS = {MEMORIES_TABLE: MEMORY_COLUMNS, CHANGES_TABLE: CHANGE_COLUMNS}
+
+_BIGQUERY_TYPES = {
+    "string": "STRING",
+    "json": "STRING",
+    "timestamp": "TIMESTAMP",
+    "bool": "BOOL",
+    "int": "INT64",
+}
+_SNOWFLAKE_TYPES = {
+    "string": "VARCHAR",
+    "json": "VARCHAR",
+    "timestamp": "TIMESTAMP_TZ",
+    "bool": "BOOLEAN",
+    "int": "NUMBER(38, 0)",
+}
+_MERGE_KEYS = ("account_key", "memory_id")
+# Later changes always win for these; other columns keep their value when a change omits them.
+_MERGE_OVERWRITE = ("updated_at", "sequence")
+
+
+class WarehouseSink(Protocol):
+    def ensure_schema(self) -> None: ...
+
+    def last_sequence(self, account_key: str) -> int: ...
+
+    def upsert_memories(self, rows: Sequence[dict[str, Any]]) -> None: ...
+
+    def append_changes(self, rows: Sequence[dict[str, Any]]) -> None: ...
+
+
+@dataclass
+class SyncStats:
+    memories: int = 0
+    changes: int = 0
+    cursor: int = 0
+
+
+class WarehouseSync:
+    """Copies one account's memories and change feed into a ``WarehouseSink``."""
+
+    def __init__(
+        self,
+        service: OrbitApiService,
+        sink: WarehouseSink,
+        *,
+        account_key: str,
+        batch_size: int = 500,
+    ) -> None:
+        if batch_size <= 0:
+            msg = "batch_size must be positive"
+            raise ValueError(msg)
+        self._service = service
+        self._sink = sink
+        self._account_key = account_key
+        self._batch_size = batch_size
+        self._schema_ready = False
+
+    def backfill(self) -> SyncStats:
+        """Copy every stored memory, then catch up on changes made while copying."""
+        self._ensure_schema()
+        head = self._service.latest_change_sequence(self._account_key)
+        snapshot = self._service.memory_snapshot(self._account_key)
+        now = datetime.now(UTC)
+        stats = SyncStats(cursor=head)
+        for start in range(0, len(snapshot), self._batch_size):
+            rows = [
+                memory_row(
+                    self._account_key,
+                    memory_id,
+                    payload,
+                    sequence=head,
+                    updated_at=now,
+                )
+                for memory_id, payload in snapshot[start : start + self._batch_size]
+            ]
+            self._sink.upsert_memories(rows)
+            stats.memories += len(rows)
+        # Changes up to ``head`` are already reflected in the snapshot.
+        if head > self._sink.last_sequence(self._account_key):
+            self._sink.append_changes(
+                [
+                    {
+                        "account_key": self._account_key,
+                        "sequence": head,
+                        "memory_id": "",
+                        "operation": "backfill",
+                        "occurred_at": now.isoformat(),
+                        "payload": None,
+                        "synced_at": now.isoformat(),
+                    }
+                ]
+            )
+        incremental = self.sync_once()
+        stats.memories += incremental.memories
+        stats.changes = incremental.changes
+        stats.cursor = max(stats.cursor, incremental.cursor)
+        return stats
+
+    def sync_once(self) -> SyncStats:
+        """Apply every change after the warehouse's cursor, one batch at a time."""
+        self._ensure_schema()
+        cursor = self._sink.last_sequence(self._account_key)
+        stats = SyncStats(cursor=cursor)
+        while True:
+            page = self._service.list_changes(
+                account_key=self._account_key,
+                cursor=str(cursor) if cursor else None,
+                limit=self._batch_size,
+                all_agents=True,
+            )
+            if not page.data:
+                return stats
+            now = datetime.now(UTC)
+            memories = fold_changes(self._account_key, page.data)
+            # Memories first: if appending the changes fails, the cursor stays put and the
+            # idempotent merge is simply repeated on the next run.
+            self._sink.upsert_memories(memories)
+            self._sink.append_changes(
+                [change_row(self._account_key, change, synced_at=now) for change in page.data]
+            )
+            cursor = page.data[-1].sequence
+            stats.memories += len(memories)
+            stats.changes += len(page.data)
+            stats.cursor = cursor
+            if not page.has_more:
+                return stats
+
+    def _ensure_schema(self) -> None:
+        if not self._schema_ready:
+            self._sink.ensure_schema()
+            self._schema_ready = True
+
+
+def memory_row(
+    account_key: str,
+    memory_id: str,
+    payload: dict[str, Any] | None,
+    *,
+    sequence: int,
+    updated_at: datetime,
+    deleted: bool | None = False,
+) -> dict[str, Any]:
+    """An ``orbit_memories`` row; ``None`` columns leave the stored value unchanged."""
+    payload = payload or {}
+    return {
+        "account_key": account_key,
+        "memory_id": memory_id,
+        "content": payload.get("content"),
+        "summary": payload.get("summary"),
+        "intent": payload.get("intent"),
+        "entities": _json_or_none(payload.get("entities")),
+        "relationships": _json_or_none(payload.get("relationships")),
+        "storage_tier": payload.get("storage_tier"),
+        "superseded_by": payload.get("superseded_by"),
+        "created_at": payload.get("created_at"),
+        "updated_at": updated_at.isoformat(),
+        "deleted": deleted,
+        "sequence": sequence,
+    }
+
+
+def change_row(account_key: str, change: MemoryChange, *, synced_at: datetime) -> dict[str, Any]:
+    return {
+        "account_key": account_key,
+        "sequence": change.sequence,
+        "memory_id": change.memory_id,
+        "operation": change.operation,
+        "occurred_at": change.occurred_at.isoformat(),
+        "payload": _json_or_none(change.memory),
+        "synced_at": synced_at.isoformat(),
+    }
+
+
+def fold_changes(account_key: str, changes: Sequence[MemoryChange]) -> list[dict[str, Any]]:
+    """Collapse a batch of changes into one ``orbit_memories`` row per memory."""
+    rows: dict[str, dict[str, Any]] = {}
+    for change in changes:
+        # ``superseded`` only links to the replacement; ``deleted`` carries no payload.
+        deleted = {"deleted": True, "superseded": None}.get(change.operation, False)
+        row = memory_row(
+            account_key,
+            change.memory_id,
+            change.memory,
+            sequence=change.sequence,
+            updated_at=change.occurred_at,
+            deleted=deleted,
+        )
+        previous = rows.get(change.memory_id)
+        if previous is not None:
+            row = {
+                key: value if value is not None or key in _MERGE_OVERWRITE else previous[key]
+                for key, value in row.items()
+            }
+        rows[change.memory_id] = row
+    return list(rows.values())
+
+
+def merge_sql(target: str, staging: str) -> str:
+    """``MERGE`` shared by BigQuery and Snowflake; older changes never overwrite newer ones."""
+    columns = [name for name, _ in MEMORY_COLUMNS]
+    updates = ", ".join(
+        f"{name} = S.{name}"
+        if name in _MERGE_OVERWRITE
+        else f"{name} = COALESCE(S.{name}, T.{name})"
+        for name in columns
+        if name not in _MERGE_KEYS
+    )
+    on = " AND ".join(f"T.{name} = S.{name}" for name in _MERGE_KEYS)
+    return (
+        f"MERGE INTO {target} T USING {staging} S ON {on} "
+        f"WHEN MATCHED AND S.sequence > T.sequence THEN UPDATE SET {updates} "
+        f"WHEN NOT MATCHED THEN INSERT ({', '.join(columns)}) "
+        f"VALUES ({', '.join(f'S.{name}' for name in columns)})"
+    )
+
+
+def _json_or_none(value: Any) -> str | None:
+    if value is None:
+        return None
+    return json.dumps(value, ensure_ascii=True, default=str)
+
+
+class BigQuerySink:
+    """Writes to a ``project.dataset`` (requires ``google-cloud-bigquery``).
+
+    Rows go in through load jobs rather than streaming inserts, which BigQuery does not let
+    ``MERGE`` touch until its streaming buffer flushes.
+    """
+
+    def __init__(self, dataset: str, *, location: str | None = None, client: Any = None) -> None:
+        if bigquery_module is None:
+            msg = (
+                "BigQuery sync requires google-cloud-bigquery. "
+                "Install with: pip install orbit-memory[bigquery]"
+            )
+            raise RuntimeError(msg)
+        project, separator, dataset_name = dataset.partition(".")
+        if not separator or not project or not dataset_name:
+            msg = "BigQuery dataset must look like project.dataset"
+            raise ValueError(msg)
+        self._bigquery = bigquery_module
+        self._dataset = dataset
+        self._location = location
+        self._client = client or bigquery_module.Client(project=project, location=location)
+
+    def ensure_schema(self) -> None:
+        dataset = self._bigquery.Dataset(self._dataset)
+        if self._location:
+            dataset.location = self._location
+        self._client.create_dataset(dataset, exists_ok=True)
+        for table_name, columns in TABLE_COLUMNS.items():
+            fields = self._schema(columns)
+            table = self._client.create_table(
+                self._bigquery.Table(self._table_id(table_name), schema=fields),
+                exists_ok=True,
+            )
+            existing = {field.name for field in table.schema}
+            missing = [field for field in fields if field.name not in existing]
+            if missing:
+                table.schema = [*table.schema, *missing]
+                self._client.update_table(table, ["schema"])
+
+    def last_sequence(self, account_key: str) -> int:
+        job = self._client.query(
+            f"SELECT MAX(sequence) AS sequence FROM `{self._table_id(CHANGES_TABLE)}` "
+            "WHERE account_key = @account_key",
+            job_config=self._bigquery.QueryJobConfig(
+                query_parameters=[
+                    self._bigquery.ScalarQueryParameter("account_key", "STRING", account_key)
+                ]
+            ),
+        )
+        row = next(iter(job.result()), None)
+        return int(row["sequence"] or 0) if row is not None else 0
+
+    def upsert_memories(self, rows: Sequence[dict[str, Any]]) -> None:
+        if not rows:
+            return
+        staging = self._table_id(f"_{MEMORIES_TABLE}_staging_{uuid4().hex[:12]}")
+        try:
+            self._load(staging, rows, MEMORY_COLUMNS, write_disposition="WRITE_TRUNCATE")
+            self._client.query(
+                merge_sql(f"`{self._table_id(MEMORIES_TABLE)}`", f"`{staging}`")
+            ).result()
+        finally:
+            self._client.delete_table(staging, not_found_ok=True)
+
+    def append_changes(self, rows: Sequence[dict[str, Any]]) -> None:
+        if rows:
+            self._load(
+                self._table_id(CHANGES_TABLE),
+                rows,
+                CHANGE_COLUMNS,
+                write_disposition="WRITE_APPEND",
+            )
+
+    def _load(
+        self,
+        table_id: str,
+        rows: Sequence[dict[str, Any]],
+        columns: tuple[tuple[str, str], ...],
+        *,
+        write_disposition: str,
+    ) -> None:
+        job_config = self._bigquery.LoadJobConfig(
+            schema=self._schema(columns),
+            write_disposition=write_disposition,
+        )
+        self._client.load_table_from_json(list(rows), table_id, job_config=job_config).result()
+
+    def _schema(self, columns: tuple[tuple[str, str], ...]) -> list[Any]:
+        return [self._bigquery.SchemaField(name, _BIGQUERY_TYPES[kind]) for name, kind in columns]
+
+    def _table_id(self, table_name: str) -> str:
+        return f"{self._dataset}.{table_name}"
+
+
+class SnowflakeSink:
+    """Writes to the connection's current schema (requires ``snowflake-connector-python``)."""
+
+    def __init__(self, connection: Any) -> None:
+        self._connection = connection
+
+    @classmethod
+    def connect(cls, **connect_kwargs: Any) -> SnowflakeSink:
+        if snowflake_connector_module is None:
+            msg = (
+                "Snowflake sync requires snowflake-connector-python. "
+                "Install with: pip install orbit-memory[snowflake]"
+            )
+            raise RuntimeError(msg)
+        return cls(snowflake_connector_module.connect(**connect_kwargs))
+
+    def ensure_schema(self) -> None:
+        cursor = self._connection.cursor()
+        try:
+            for table_name, columns in TABLE_COLUMNS.items():
+                definition = ", ".join(
+                    f"{name} {_SNOWFLAKE_TYPES[kind]}" for name, kind in columns
+                )
+                cursor.execute(f"CREATE TABLE IF NOT EXISTS {table_name} ({definition})")
+                cursor.execute(
+                    "SELECT column_name FROM information_schema.columns "
+                    "WHERE table_schema = CURRENT_SCHEMA() AND table_name = %s",
+                    (table_name.upper(),),
+                )
+                existing = {str(row[0]).lower() for row in cursor.fetchall()}
+                for name, kind in columns:
+                    if name not in existing:
+                        cursor.execute(
+                            f"ALTER TABLE {table_name} ADD COLUMN {name} {_SNOWFLAKE_TYPES[kind]}"
+                        )
+        finally:
+            cursor.close()
+
+    def last_sequence(self, account_key: str) -> int:
+        cursor = self._connection.cursor()
+        try:
+            cursor.execute(
+                f"SELECT MAX(sequence) FROM {CHANGES_TABLE} WHERE account_key = %s",
+                (account_key,),
+            )
+            row = cursor.fetchone()
+        finally:
+            cursor.close()
+        return int(row[0] or 0) if row else 0
+
+    def upsert_memories(self, rows: Sequence[dict[str, Any]]) -> None:
+        if not rows:
+            return
+        staging = f"{MEMORIES_TABLE}_staging_{uuid4().hex[:12]}"
+        cursor = self._connection.cursor()
+        try:
+            cursor.execute(f"CREATE TEMPORARY TABLE {staging} LIKE {MEMORIES_TABLE}")
+            self._insert(cursor, staging, rows, MEMORY_COLUMNS)
+            cursor.execute(merge_sql(MEMORIES_TABLE, staging))
+            cursor.execute(f"DROP TABLE IF EXISTS {staging}")
+        finally:
+            cursor.close()
+
+    def append_changes(self, rows: Sequence[dict[str, Any]]) -> None:
+        if not rows:
+            return
+        cursor = self._connection.cursor()
+        try:
+            self._insert(cursor, CHANGES_TABLE, rows, CHANGE_COLUMNS)
+        finally:
+            cursor.close()
+
+    @staticmethod
+    def _insert(
+        cursor: Any,
+        table_name: str,
+        rows: Sequence[dict[str, Any]],
+        columns: tuple[tuple[str, str], ...],
+    ) -> None:
+        names = [name for name, _ in columns]
+        cursor.executemany(
+            f"INSERT INTO {table_name} ({', '.join(names)}) "
+            f"VALUES ({', '.join(['%s'] * len(names))})",
+            [tuple(row.get(name) for name in names) for row in rows],
+        )
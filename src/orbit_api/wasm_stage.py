@@ -0,0 +1,221 @@
+"""Custom ingest pipeline stages compiled to WebAssembly.
+
+Operators register modules with ``ORBIT_WASM_STAGES=name=/path/module.wasm,...`` and enable
+them per namespace as ``wasm:<name>`` entries in a pipeline order. Each event gets a fresh
+instance, so modules cannot keep state between events, and runs under a fuel and memory budget.
+
+A module exports ``memory`` and ``run() -> i32`` (``0`` on success) and may import these
+functions from the ``orbit`` namespace. Pointers and lengths are ``i32`` offsets into the
+module's own memory; strings are UTF-8.
+
+``content_len() -> i32`` / ``read_content(ptr)``
+    Size of the event content, then copy it to ``ptr``.
+``metadata_len() -> i32`` / ``read_metadata(ptr)``
+    The same for a JSON object with ``account_key``, ``entity_id``, ``event_type``, and
+    ``metadata`` (including ``relationships``).
+``set_content(ptr, len)``
+    Replace the content that later stages see and store.
+``annotate(ptr, len)``
+    Attach a note to the memory, stored as relationship ``annotation:<text>``.
+``halt(ptr, len)``
+    Drop the event with a reason; later stages do not run and nothing is stored.
+"""
+
+from __future__ import annotations
+
+import json
+from importlib import import_module
+from pathlib import Path
+from types import ModuleType
+from typing import Any
+
+from orbit_api.pipeline import IngestContext
+
+MAX_ANNOTATION_CHARS = 512
+
+
+def _optional_import(module_name: str) -> ModuleType | None:
+    try:  # pragma: no cover - optional dependency
+        return import_module(module_name)
+    except ImportError:  # pragma: no cover - optional dependency
+        return None
+
+
+wasmtime_module: ModuleType | None = _optional_import("wasmtime")
+
+
+class WasmStageError(RuntimeError):
+    """A stage module trapped, ran out of fuel, or called the host API incorrectly."""
+
+
+class WasmHostApi:
+    """Host side of the ``orbit`` imports for one event."""
+
+    def __init__(self, stage: str, context: IngestContext) -> None:
+        self._stage = stage
+        self._context = context
+
+    def content(self) -> bytes:
+        return self._context.content.encode("utf-8")
+
+    def metadata(self) -> bytes:
+        view = {
+            "account_key": self._context.account_key,
+            "entity_id": self._context.request.entity_id,
+            "event_type": self._context.request.event_type,
+            "metadata": self._context.metadata,
+        }
+        return json.dumps(view, default=str).encode("utf-8")
+
+    def set_content(self, data: bytes) -> None:
+        content = self._decode(data).strip()
+        if not content:
+            msg = f"{self._stage} set empty content"
+            raise WasmStageError(msg)
+        self._context.content = content
+
+    def annotate(self, data: bytes) -> None:
+        note = self._decode(data).strip()
+        if not note or len(note) > MAX_ANNOTATION_CHARS:
+            msg = f"{self._stage} annotations must be 1-{MAX_ANNOTATION_CHARS} chars"
+            raise WasmStageError(msg)
+        # Prefixed so a module cannot forge the relationships Orbit itself reads.
+        self._context.add_relationships(f"annotation:{note}")
+
+    def halt(self, data: bytes) -> None:
+        self._context.halt(self._stage, reason=self._decode(data).strip() or None)
+
+    def _decode(self, data: bytes) -> str:
+        try:
+            return data.decode("utf-8")
+        except UnicodeDecodeError as exc:
+            msg = f"{self._stage} passed invalid UTF-8"
+            raise WasmStageError(msg) from exc
+
+
+class WasmStage:
+    """A pipeline stage backed by a compiled WebAssembly module (requires ``wasmtime``)."""
+
+    def __init__(
+        self,
+        name: str,
+        path: str | Path,
+        *,
+        fuel: int,
+        max_memory_bytes: int,
+    ) -> None:
+        if wasmtime_module is None:
+            msg = (
+                "WASM pipeline stages require wasmtime. "
+                "Install with: pip install orbit-memory[wasm]"
+            )
+            raise RuntimeError(msg)
+        self.stage = f"wasm:{name}"
+        self._fuel = fuel
+        self._max_memory_bytes = max_memory_bytes
+        config = wasmtime_module.Config()
+        config.consume_fuel = True
+        self._engine = wasmtime_module.Engine(config)
+        try:
+            self._module = wasmtime_module.Module.from_file(self._engine, str(path))
+        except (OSError, wasmtime_module.WasmtimeError) as exc:
+            msg = f"could not load wasm:{name} from {path}: {exc}"
+            raise ValueError(msg) from exc
+        exports = {export.name: export.type for export in self._module.exports}
+        run = exports.get("run")
+        if not (
+            isinstance(run, wasmtime_module.FuncType)
+            and not run.params
+            and run.results == [wasmtime_module.ValType.i32()]
+        ):
+            msg = f"wasm:{name} must export run() -> i32"
+            raise ValueError(msg)
+        if not isinstance(exports.get("memory"), wasmtime_module.MemoryType):
+            msg = f"wasm:{name} must export its memory as memory"
+            raise ValueError(msg)
+
+    def __call__(self, context: IngestContext) -> None:
+        wasmtime = wasmtime_module
+        if wasmtime is None:  # pragma: no cover - checked in __init__
+            return
+        host = WasmHostApi(self.stage, context)
+        store = wasmtime.Store(self._engine)
+        store.set_fuel(self._fuel)
+        store.set_limits(memory_size=self._max_memory_bytes)
+        linker = wasmtime.Linker(self._engine)
+        i32 = wasmtime.ValType.i32()
+
+        def define(name: str, params: int, results: int, func: Any) -> None:
+            linker.define_func(
+                "orbit",
+                name,
+                wasmtime.FuncType([i32] * params, [i32] * results),
+                func,
+                access_caller=True,
+            )
+
+        def memory(caller: Any) -> Any:
+            exported = caller.get("memory")
+            if not isinstance(exported, wasmtime.Memory):
+                msg = f"{self.stage} does not export memory"
+                raise WasmStageError(msg)
+            return exported
+
+        def read(caller: Any, ptr: int, length: int) -> bytes:
+            return bytes(memory(caller).read(caller, ptr, ptr + length))
+
+        define("content_len", 0, 1, lambda caller: len(host.content()))
+        define(
+            "read_content",
+            1,
+            0,
+            lambda caller, ptr: memory(caller).write(caller, host.content(), ptr),
+        )
+        define("metadata_len", 0, 1, lambda caller: len(host.metadata()))
+        define(
+            "read_metadata",
+            1,
+            0,
+            lambda caller, ptr: memory(caller).write(caller, host.metadata(), ptr),
+        )
+        define(
+            "set_content",
+            2,
+            0,
+            lambda caller, ptr, length: host.set_content(read(caller, ptr, length)),
+        )
+        define(
+            "annotate",
+            2,
+            0,
+            lambda caller, ptr, length: host.annotate(read(caller, ptr, length)),
+        )
+        define("halt", 2, 0, lambda caller, ptr, length: host.halt(read(caller, ptr, length)))
+
+        try:
+            instance = linker.instantiate(store, self._module)
+            run = instance.exports(store).get("run")
+            if not isinstance(run, wasmtime.Func):
+                msg = f"{self.stage} does not export run"
+                raise WasmStageError(msg)
+            status = run(store)
+        except (wasmtime.Trap, wasmtime.WasmtimeError) as exc:
+            msg = f"{self.stage} failed: {exc}"
+            raise WasmStageError(msg) from exc
+        if status != 0:
+            msg = f"{self.stage} returned status {status}"
+            raise WasmStageError(msg)
+
+
+def build_wasm_stages(
+    modules: dict[str, str],
+    *,
+    fuel: int,
+    max_memory_bytes: int,
+) -> dict[str, WasmStage]:
+    """Load every registered module, keyed by its pipeline entry (``wasm:<name>``)."""
+    stages = [
+        WasmStage(name, path, fuel=fuel, max_memory_bytes=max_memory_bytes)
+        for name, path in modules.items()
+    ]
+    return {stage.stage: stage for stage in stages}
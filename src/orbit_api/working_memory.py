@@ -0,0 +1,310 @@
+"""Session-scoped working memory: a fast short-term tier in front of long-term storage.
+
+Items are kept in process memory (or Redis when several API replicas share sessions), scored
+lexically at retrieval time so writes never wait on embeddings, and promoted to long-term
+memory when the session ends or goes idle.
+"""
+
+from __future__ import annotations
+
+import json
+import re
+from dataclasses import asdict, dataclass, field, replace
+from datetime import datetime
+from importlib import import_module
+from threading import RLock
+from types import ModuleType
+from typing import Any, Protocol
+from uuid import uuid4
+
+from decision_engine import clock
+
+_TOKEN_PATTERN = re.compile(r"[a-z0-9]+")
+_SESSION_KEY_SEPARATOR = "\x1f"
+
+
+def _optional_import(module_name: str) -> ModuleType | None:
+    try:  # pragma: no cover - optional dependency
+        return import_module(module_name)
+    except ImportError:  # pragma: no cover - optional dependency
+        return None
+
+
+redis_module: ModuleType | None = _optional_import("redis")
+
+
+@dataclass(frozen=True)
+class WorkingMemoryItem:
+    item_id: str
+    session_id: str
+    content: str
+    event_type: str
+    entity_id: str
+    created_at: datetime
+    importance: float = 0.0
+    recall_count: int = 0
+    metadata: dict[str, Any] = field(default_factory=dict)
+
+
+class WorkingMemoryStore(Protocol):
+    backend: str
+
+    def append(
+        self,
+        account_key: str,
+        item: WorkingMemoryItem,
+        *,
+        ttl_seconds: int | None = None,
+    ) -> None: ...
+
+    def list(self, account_key: str, session_id: str) -> list[WorkingMemoryItem]: ...
+
+    def mark_recalled(self, account_key: str, session_id: str, item_ids: list[str]) -> None: ...
+
+    def pop_session(self, account_key: str, session_id: str) -> list[WorkingMemoryItem]: ...
+
+    def idle_sessions(self, idle_before: float) -> list[tuple[str, str]]: ...
+
+
+class InMemoryWorkingMemoryStore:
+    """Per-process store; sessions must stick to one API replica."""
+
+    backend = "memory"
+
+    def __init__(self, *, max_items_per_session: int = 500) -> None:
+        self._max_items = max_items_per_session
+        self._lock = RLock()
+        self._sessions: dict[tuple[str, str], dict[str, WorkingMemoryItem]] = {}
+        self._last_activity: dict[tuple[str, str], float] = {}
+
+    def append(
+        self,
+        account_key: str,
+        item: WorkingMemoryItem,
+        *,
+        ttl_seconds: int | None = None,
+    ) -> None:
+        key = (account_key, item.session_id)
+        with self._lock:
+            items = self._sessions.setdefault(key, {})
+            items[item.item_id] = item
+            while len(items) > self._max_items:
+                items.pop(next(iter(items)))
+            self._last_activity[key] = _now_epoch()
+
+    def list(self, account_key: str, session_id: str) -> list[WorkingMemoryItem]:
+        with self._lock:
+            return list(self._sessions.get((account_key, session_id), {}).values())
+
+    def mark_recalled(self, account_key: str, session_id: str, item_ids: list[str]) -> None:
+        key = (account_key, session_id)
+        with self._lock:
+            items = self._sessions.get(key)
+            if not items:
+                return
+            for item_id in item_ids:
+                item = items.get(item_id)
+                if item is not None:
+                    items[item_id] = replace(item, recall_count=item.recall_count + 1)
+            self._last_activity[key] = _now_epoch()
+
+    def pop_session(self, account_key: str, session_id: str) -> list[WorkingMemoryItem]:
+        key = (account_key, session_id)
+        with self._lock:
+            self._last_activity.pop(key, None)
+            return list(self._sessions.pop(key, {}).values())
+
+    def idle_sessions(self, idle_before: float) -> list[tuple[str, str]]:
+        with self._lock:
+            return [key for key, seen in self._last_activity.items() if seen < idle_before]
+
+
+class RedisWorkingMemoryStore:
+    """Shares sessions across API replicas via Redis hashes plus an activity sorted set."""
+
+    backend = "redis"
+
+    def __init__(
+        self,
+        url: str,
+        *,
+        prefix: str = "orbit:wm",
+        max_items_per_session: int = 500,
+        ttl_seconds: int = 3600,
+        client: Any | None = None,
+    ) -> None:
+        if client is None:
+            if redis_module is None:
+                msg = (
+                    "Redis working memory requires redis. "
+                    "Install with: pip install orbit-memory[redis]"
+                )
+                raise RuntimeError(msg)
+            client = redis_module.Redis.from_url(url)
+        self._client = client
+        self._prefix = prefix
+        self._max_items = max_items_per_session
+        self._ttl_seconds = ttl_seconds
+
+    def append(
+        self,
+        account_key: str,
+        item: WorkingMemoryItem,
+        *,
+        ttl_seconds: int | None = None,
+    ) -> None:
+        """Store ``item``; ``ttl_seconds`` is the current idle timeout, which may be reloaded."""
+        key = self._session_key(account_key, item.session_id)
+        # Keys outlive the idle timeout so the sweeper can still promote them.
+        key_ttl_seconds = max((ttl_seconds or self._ttl_seconds) * 2, 60)
+        pipeline = self._client.pipeline()
+        pipeline.hset(key, item.item_id, _dump_item(item))
+        pipeline.expire(key, key_ttl_seconds)
+        pipeline.zadd(
+            self._activity_key,
+            {self._member(account_key, item.session_id): _now_epoch()},
+        )
+        pipeline.execute()
+        if self._client.hlen(key) > self._max_items:
+            items = self.list(account_key, item.session_id)
+            overflow = [entry.item_id for entry in items[: len(items) - self._max_items]]
+            if overflow:
+                self._client.hdel(key, *overflow)
+
+    def list(self, account_key: str, session_id: str) -> list[WorkingMemoryItem]:
+        raw = self._client.hgetall(self._session_key(account_key, session_id))
+        items = [_load_item(value) for value in raw.values()]
+        return sorted(items, key=lambda entry: entry.created_at)
+
+    def mark_recalled(self, account_key: str, session_id: str, item_ids: list[str]) -> None:
+        if not item_ids:
+            return
+        key = self._session_key(account_key, session_id)
+        for item_id, value in zip(item_ids, self._client.hmget(key, item_ids), strict=True):
+            if value is None:
+                continue
+            item = _load_item(value)
+            recalled = replace(item, recall_count=item.recall_count + 1)
+            self._client.hset(key, item_id, _dump_item(recalled))
+        self._client.zadd(self._activity_key, {self._member(account_key, session_id): _now_epoch()})
+
+    def pop_session(self, account_key: str, session_id: str) -> list[WorkingMemoryItem]:
+        key = self._session_key(account_key, session_id)
+        pipeline = self._client.pipeline()
+        pipeline.hgetall(key)
+        pipeline.delete(key)
+        pipeline.zrem(self._activity_key, self._member(account_key, session_id))
+        raw, _, _ = pipeline.execute()
+        items = [_load_item(value) for value in raw.values()]
+        return sorted(items, key=lambda entry: entry.created_at)
+
+    def idle_sessions(self, idle_before: float) -> list[tuple[str, str]]:
+        members = self._client.zrangebyscore(self._activity_key, "-inf", idle_before)
+        sessions: list[tuple[str, str]] = []
+        for member in members:
+            decoded = member.decode("utf-8") if isinstance(member, bytes) else str(member)
+            account_key, _, session_id = decoded.partition(_SESSION_KEY_SEPARATOR)
+            sessions.append((account_key, session_id))
+        return sessions
+
+    @property
+    def _activity_key(self) -> str:
+        return f"{self._prefix}:sessions"
+
+    def _session_key(self, account_key: str, session_id: str) -> str:
+        return f"{self._prefix}:{account_key}:{session_id}"
+
+    @staticmethod
+    def _member(account_key: str, session_id: str) -> str:
+        return f"{account_key}{_SESSION_KEY_SEPARATOR}{session_id}"
+
+
+def build_working_memory_store(
+    backend: str,
+    *,
+    redis_url: str | None = None,
+    max_items_per_session: int = 500,
+    ttl_seconds: int = 3600,
+) -> WorkingMemoryStore:
+    normalized = backend.strip().lower()
+    if normalized == "redis":
+        if not redis_url:
+            msg = (
+                "ORBIT_WORKING_MEMORY_REDIS_URL is required when "
+                "ORBIT_WORKING_MEMORY_BACKEND=redis"
+            )
+            raise ValueError(msg)
+        return RedisWorkingMemoryStore(
+            redis_url,
+            max_items_per_session=max_items_per_session,
+            ttl_seconds=ttl_seconds,
+        )
+    return InMemoryWorkingMemoryStore(max_items_per_session=max_items_per_session)
+
+
+def new_working_memory_item(
+    *,
+    session_id: str,
+    content: str,
+    event_type: str,
+    entity_id: str,
+    importance: float = 0.0,
+    metadata: dict[str, Any] | None = None,
+) -> WorkingMemoryItem:
+    return WorkingMemoryItem(
+        item_id=f"wm_{uuid4().hex}",
+        session_id=session_id,
+        content=content,
+        event_type=event_type,
+        entity_id=entity_id,
+        created_at=clock.now(),
+        importance=importance,
+        metadata=dict(metadata or {}),
+    )
+
+
+def score_working_memory(
+    query: str,
+    items: list[WorkingMemoryItem],
+    *,
+    limit: int,
+) -> list[tuple[WorkingMemoryItem, float]]:
+    """Rank items by query-term overlap, nudged toward recent and important items."""
+    query_tokens = set(_TOKEN_PATTERN.findall(query.lower()))
+    if not query_tokens or not items:
+        return []
+    newest = max(item.created_at for item in items)
+    oldest = min(item.created_at for item in items)
+    span = max((newest - oldest).total_seconds(), 1.0)
+    scored: list[tuple[WorkingMemoryItem, float]] = []
+    for item in items:
+        tokens = set(_TOKEN_PATTERN.findall(item.content.lower()))
+        overlap = len(query_tokens & tokens) / len(query_tokens)
+        if overlap <= 0.0:
+            continue
+        recency = 1.0 - ((newest - item.created_at).total_seconds() / span)
+        score = (0.75 * overlap) + (0.15 * recency) + (0.10 * item.importance)
+        scored.append((item, min(1.0, score)))
+    scored.sort(key=lambda entry: entry[1], reverse=True)
+    return scored[:limit]
+
+
+def should_promote_working_memory(item: WorkingMemoryItem, *, min_importance: float) -> bool:
+    """Promote items flagged as important or that were recalled during the session."""
+    return item.importance >= min_importance or item.recall_count > 0
+
+
+def _dump_item(item: WorkingMemoryItem) -> str:
+    payload = asdict(item)
+    payload["created_at"] = item.created_at.isoformat()
+    return json.dumps(payload, ensure_ascii=True)
+
+
+def _load_item(raw: bytes | str) -> WorkingMemoryItem:
+    payload = json.loads(raw)
+    payload["created_at"] = datetime.fromisoformat(payload["created_at"])
+    return WorkingMemoryItem(**payload)
+
+
+def _now_epoch() -> float:
+    return clock.now().timestamp()
@@ -1,8 +1,10 @@
 from __future__ import annotations
 
 import asyncio
+import json
 from datetime import UTC, datetime, timedelta
 from pathlib import Path
+from typing import Any
 
 import httpx
 import jwt
@@ -17,7 +19,12 @@ JWT_ISSUER = "orbit-tests"
 JWT_AUDIENCE = "orbit-tests-api"
 
 
-def _build_app(tmp_path: Path, *, cors_allow_origins: list[str] | None = None):
+def _build_app(
+    tmp_path: Path,
+    *,
+    cors_allow_origins: list[str] | None = None,
+    **api_overrides: Any,
+):
     db_path = tmp_path / "errors.db"
     api_config = ApiConfig(
         database_url=f"sqlite:///{db_path}",
@@ -30,6 +37,7 @@ def _build_app(tmp_path: Path, *, cors_allow_origins: list[str] | None = None):
         jwt_issuer=JWT_ISSUER,
         jwt_audience=JWT_AUDIENCE,
         cors_allow_origins=cors_allow_origins or [],
+        **api_overrides,
     )
     engine_config = EngineConfig(
         sqlite_path=str(db_path),
@@ -179,6 +187,76 @@ def test_api_scope_enforcement_errors(tmp_path: Path) -> None:
     asyncio.run(_run())
 
 
+def test_api_validation_errors_list_each_invalid_field(tmp_path: Path) -> None:
+    async def _run() -> None:
+        app = _build_app(
+            tmp_path,
+            max_ingest_content_chars=50,
+            allowed_event_types=["user_question", "assistant_response"],
+        )
+        transport = httpx.ASGITransport(app=app)
+        headers = {"Authorization": f"Bearer {_jwt_token('validation-user')}"}
+        async with httpx.AsyncClient(
+            transport=transport,
+            base_url="http://testserver",
+        ) as client:
+            batch = await client.post(
+                "/v1/ingest/batch",
+                headers=headers,
+                json={
+                    "events": [
+                        {"content": "fine", "event_type": "user_question"},
+                        {"content": "x" * 51, "event_type": "user_preferrence"},
+                    ]
+                },
+            )
+            assert batch.status_code == 422
+            assert batch.headers["X-Orbit-Error-Code"] == "validation_error"
+            detail = batch.json()["detail"]
+            assert detail["error_code"] == "validation_error"
+            assert [(item["field"], item["constraint"]) for item in detail["errors"]] == [
+                ("events.1.content", "max_length"),
+                ("events.1.event_type", "enum"),
+            ]
+            assert detail["errors"][0]["limit"] == 50
+
+            schema = await client.post(
+                "/v1/ingest",
+                headers=headers,
+                json={"event_type": "user_question", "colour": "blue"},
+            )
+            assert schema.status_code == 422
+            errors = {item["field"]: item for item in schema.json()["detail"]["errors"]}
+            assert errors["content"]["constraint"] == "required"
+            assert errors["colour"]["constraint"] == "unknown_field"
+
+            long_query = await client.get(
+                "/v1/retrieve",
+                headers=headers,
+                params={"query": "x" * 2001},
+            )
+            [query_error] = long_query.json()["detail"]["errors"]
+            assert query_error["location"] == "query"
+            assert query_error["constraint"] == "max_length"
+            assert query_error["limit"] == 2000
+
+            bad_range = await client.get(
+                "/v1/retrieve",
+                headers=headers,
+                params={
+                    "query": "hello",
+                    "start_time": "2026-02-01T00:00:00Z",
+                    "end_time": "2026-01-01T00:00:00Z",
+                },
+            )
+            assert bad_range.status_code == 422
+            [range_error] = bad_range.json()["detail"]["errors"]
+            assert range_error["field"] == "end_time"
+            assert range_error["location"] == "query"
+
+    asyncio.run(_run())
+
+
 def test_api_idempotency_replay_and_conflict(tmp_path: Path) -> None:
     async def _run() -> None:
         app = _build_app(tmp_path)
@@ -317,3 +395,125 @@ def test_api_cors_preflight_for_vercel_origin(tmp_path: Path) -> None:
             )
 
     asyncio.run(_run())
+
+
+def test_api_import_rate_limits_standard_mode_but_not_backfills(tmp_path: Path) -> None:
+    async def _run() -> None:
+        app = _build_app(tmp_path, per_minute_limit="1/minute")
+        transport = httpx.ASGITransport(app=app)
+        body = {"events": [{"content": "Alice likes tea", "event_type": "user_question"}]}
+
+        def headers(subject: str) -> dict[str, str]:
+            return {"Authorization": f"Bearer {_jwt_token(subject, scopes=['import'])}"}
+
+        async with httpx.AsyncClient(
+            transport=transport,
+            base_url="http://testserver",
+        ) as client:
+            first = await client.post("/v1/import", headers=headers("import-a"), json=body)
+            assert first.status_code == 200
+            # The per-minute limit counts per client, so another account is refused too.
+            second = await client.post("/v1/import", headers=headers("import-b"), json=body)
+            assert second.status_code == 429
+            for subject in ("import-c", "import-d"):
+                backfill = await client.post(
+                    "/v1/import?mode=backfill",
+                    headers=headers(subject),
+                    json=body,
+                )
+                assert backfill.status_code == 200
+                assert backfill.json()["mode"] == "backfill"
+
+    asyncio.run(_run())
+
+
+def test_api_ingest_stream_acks_each_line(tmp_path: Path) -> None:
+    async def _run() -> None:
+        app = _build_app(tmp_path, allowed_event_types=["user_question"])
+        transport = httpx.ASGITransport(app=app)
+        headers = {
+            "Authorization": f"Bearer {_jwt_token('stream-user')}",
+            "Content-Type": "application/x-ndjson",
+        }
+        body = "\n".join(
+            [
+                json.dumps({"content": "I prefer tea", "event_type": "user_question"}),
+                "",
+                "{not json",
+                json.dumps({"content": "no such type", "event_type": "user_preferrence"}),
+                json.dumps({"content": "over quota", "event_type": "user_question"}),
+                json.dumps({"content": "never read", "event_type": "user_question"}),
+            ]
+        )
+        async with httpx.AsyncClient(
+            transport=transport,
+            base_url="http://testserver",
+        ) as client:
+            wrong_type = await client.post(
+                "/v1/ingest/stream",
+                headers={**headers, "Content-Type": "application/json"},
+                content=body,
+            )
+            assert wrong_type.status_code == 415
+
+            response = await client.post("/v1/ingest/stream", headers=headers, content=body)
+            assert response.status_code == 200
+            assert response.headers["content-type"].startswith("application/x-ndjson")
+            acks = [json.loads(line) for line in response.text.splitlines()]
+            assert [(ack["line"], ack["status_code"]) for ack in acks] == [
+                (1, 201),
+                (3, 422),
+                (4, 422),
+                (5, 429),
+            ]
+            assert acks[0]["ok"] is True
+            assert acks[0]["result"]["memory_id"]
+            assert acks[2]["error"].startswith("event_type")
+            assert all(ack["ok"] is False for ack in acks[1:])
+
+    asyncio.run(_run())
+
+
+def test_api_versions_route_v2_to_v1_and_flag_deprecations(tmp_path: Path) -> None:
+    async def _run() -> None:
+        app = _build_app(
+            tmp_path,
+            api_v1_deprecated_at=datetime(2027, 1, 1, tzinfo=UTC),
+            api_v1_sunset=datetime(2027, 7, 1, tzinfo=UTC),
+        )
+        transport = httpx.ASGITransport(app=app)
+        headers = {"Authorization": f"Bearer {_jwt_token('version-user')}"}
+        async with httpx.AsyncClient(
+            transport=transport,
+            base_url="http://testserver",
+        ) as client:
+            v1 = await client.get("/v1/status", headers=headers)
+            assert v1.status_code == 200
+            assert v1.headers["Orbit-Version"] == "v1"
+            assert v1.headers["Deprecation"] == "@1798761600"
+            assert v1.headers["Sunset"] == "Thu, 01 Jul 2027 00:00:00 GMT"
+            assert v1.headers["Link"] == '</v2/status>; rel="successor-version"'
+
+            v2 = await client.get("/v2/status", headers=headers)
+            assert v2.status_code == 200
+            assert v2.headers["Orbit-Version"] == "v2"
+            assert "Deprecation" not in v2.headers
+            assert v2.json() == v1.json()
+
+            negotiated = await client.get("/v1/status", headers={**headers, "Orbit-Version": "2"})
+            assert negotiated.status_code == 200
+            assert negotiated.headers["Orbit-Version"] == "v2"
+            assert "Deprecation" not in negotiated.headers
+
+            conflict = await client.get("/v2/status", headers={**headers, "Orbit-Version": "v1"})
+            assert conflict.status_code == 400
+            assert conflict.headers["X-Orbit-Error-Code"] == "api_version_conflict"
+
+            unsupported = await client.get("/v1/status", headers={**headers, "Orbit-Version": "v9"})
+            assert unsupported.status_code == 400
+            assert unsupported.headers["X-Orbit-Error-Code"] == "unsupported_api_version"
+
+            missing = await client.get("/v2/no-such-endpoint", headers=headers)
+            assert missing.status_code == 404
+
+    asyncio.run(_run())
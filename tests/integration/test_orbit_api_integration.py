@@ -6,20 +6,37 @@ from pathlib import Path
 
 import httpx
 import jwt
+import pytest
 
 from memory_engine.config import EngineConfig
-from orbit import AsyncMemoryEngine, Config
+from orbit import (
+    AsyncMemoryEngine,
+    Config,
+    OrbitAuthError,
+    OrbitNotFoundError,
+    OrbitValidationError,
+    trace_context,
+)
+from orbit.signing import HmacAuth, canonical_request, compute_signature
 from orbit_api.app import create_app
 from orbit_api.config import ApiConfig
+from orbit_api.regions import (
+    REPLICATION_NONCE_HEADER,
+    REPLICATION_SIGNATURE_HEADER,
+    REPLICATION_TIMESTAMP_HEADER,
+    replication_headers,
+)
 
 JWT_SECRET = "integration-secret"
 JWT_ISSUER = "orbit-tests"
 JWT_AUDIENCE = "orbit-tests-api"
+OPERATOR_TOKEN = "integration-operator-token-0123456789"
 
 
-def _build_app(tmp_path: Path):
+def _build_app(tmp_path: Path, **api_overrides: object):
     db_path = tmp_path / "orbit_api.db"
     api_config = ApiConfig(
+        **api_overrides,
         database_url=f"sqlite:///{db_path}",
         sqlite_fallback_path=str(db_path),
         free_events_per_day=100,
@@ -806,3 +823,593 @@ def test_api_dashboard_account_claim_mapping_shares_account(tmp_path: Path) -> N
             assert key_id in ids
 
     asyncio.run(_run())
+
+
+def test_api_no_code_hooks_accept_query_api_key_and_flat_payloads(tmp_path: Path) -> None:
+    async def _run() -> None:
+        app = _build_app(tmp_path, allow_query_api_key=True)
+        transport = httpx.ASGITransport(app=app)
+        jwt_headers = {"Authorization": f"Bearer {_jwt_token(subject='zapier-user')}"}
+
+        async with httpx.AsyncClient(
+            transport=transport,
+            base_url="http://testserver",
+        ) as client:
+            issue = await client.post(
+                "/v1/dashboard/keys",
+                headers=jwt_headers,
+                json={"name": "zapier", "scopes": ["read", "write"]},
+            )
+            assert issue.status_code == 201
+            query_key = {"api_key": str(issue.json()["key"])}
+
+            created = await client.post(
+                "/v1/hooks/ingest",
+                params=query_key,
+                json={
+                    "content": "Lead Acme Corp asked for a SOC 2 report",
+                    "entity_id": "acme",
+                    "event_type": "crm_note",
+                    "deal_stage": "negotiation",
+                },
+            )
+            assert created.status_code == 201
+            created_body = created.json()
+            assert created_body["entity_id"] == "acme"
+            assert created_body["event_type"] == "crm_note"
+
+            polled = await client.get(
+                "/v1/hooks/memories",
+                params={**query_key, "entity_id": "acme"},
+            )
+            assert polled.status_code == 200
+            items = polled.json()
+            assert isinstance(items, list)
+            assert items[0]["id"] == created_body["id"]
+            assert set(items[0]) == {
+                "id",
+                "content",
+                "entity_id",
+                "event_type",
+                "created_at",
+                "importance_score",
+            }
+
+            searched = await client.get(
+                "/v1/hooks/search",
+                params={**query_key, "query": "SOC 2 report"},
+            )
+            assert searched.status_code == 200
+            assert searched.json()[0]["id"] == created_body["id"]
+
+            missing = await client.post("/v1/hooks/ingest", params=query_key, json={"x": 1})
+            assert missing.status_code == 422
+
+            rejected = await client.get(
+                "/v1/hooks/memories",
+                params={"api_key": "orbit_pk_invalid"},
+            )
+            assert rejected.status_code == 401
+
+    asyncio.run(_run())
+
+
+def test_api_browser_tokens_are_scoped_to_retrieval_entity_and_origin(tmp_path: Path) -> None:
+    async def _run() -> None:
+        app = _build_app(tmp_path)
+        transport = httpx.ASGITransport(app=app)
+        backend_headers = {"Authorization": f"Bearer {_jwt_token()}"}
+
+        async with httpx.AsyncClient(
+            transport=transport,
+            base_url="http://testserver",
+        ) as client:
+            for entity_id, content in (
+                ("alice", "Alice prefers dark mode"),
+                ("bob", "Bob prefers light mode"),
+            ):
+                ingest = await client.post(
+                    "/v1/ingest",
+                    headers=backend_headers,
+                    json={"content": content, "event_type": "preference", "entity_id": entity_id},
+                )
+                assert ingest.status_code == 201
+
+            minted = await client.post(
+                "/v1/tokens/browser",
+                headers=backend_headers,
+                json={
+                    "entity_id": "alice",
+                    "ttl_seconds": 300,
+                    "allowed_origins": ["https://app.example.com"],
+                },
+            )
+            assert minted.status_code == 201
+            minted_body = minted.json()
+            assert minted_body["scopes"] == ["read"]
+            browser_headers = {
+                "Authorization": f"Bearer {minted_body['token']}",
+                "Origin": "https://app.example.com",
+            }
+
+            retrieved = await client.get(
+                "/v1/retrieve",
+                headers=browser_headers,
+                params={"query": "display preference"},
+            )
+            assert retrieved.status_code == 200
+            contents = [item["content"] for item in retrieved.json()["memories"]]
+            assert contents
+            assert all("Alice" in content for content in contents)
+
+            other_entity = await client.get(
+                "/v1/retrieve",
+                headers=browser_headers,
+                params={"query": "display preference", "entity_id": "bob"},
+            )
+            assert other_entity.status_code == 403
+
+            wrong_origin = await client.get(
+                "/v1/retrieve",
+                headers={**browser_headers, "Origin": "https://evil.example.com"},
+                params={"query": "display preference"},
+            )
+            assert wrong_origin.status_code == 403
+
+            write_attempt = await client.post(
+                "/v1/ingest",
+                headers=browser_headers,
+                json={"content": "injected", "entity_id": "alice"},
+            )
+            assert write_attempt.status_code == 403
+
+            too_broad = await client.post(
+                "/v1/tokens/browser",
+                headers=backend_headers,
+                json={"scopes": ["write"]},
+            )
+            assert too_broad.status_code == 422
+
+            too_long = await client.post(
+                "/v1/tokens/browser",
+                headers=backend_headers,
+                json={"ttl_seconds": 86_400},
+            )
+            assert too_long.status_code == 422
+
+    asyncio.run(_run())
+
+
+def test_api_propagates_trace_headers_from_sdk_context(tmp_path: Path) -> None:
+    traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+    async def _run() -> None:
+        app = _build_app(tmp_path)
+        transport = httpx.ASGITransport(app=app)
+        engine = AsyncMemoryEngine(
+            config=Config(api_key=_jwt_token(), base_url="http://testserver", max_retries=0),
+            transport=transport,
+        )
+        try:
+            with trace_context(request_id="checkout-42", traceparent=traceparent):
+                await engine.status()
+            assert engine.last_request_id == "checkout-42"
+            await engine.status()
+            generated = engine.last_request_id
+            assert generated and generated != "checkout-42"
+        finally:
+            await engine.aclose()
+
+        async with httpx.AsyncClient(transport=transport, base_url="http://testserver") as client:
+            response = await client.get(
+                "/v1/status",
+                headers={
+                    "Authorization": f"Bearer {_jwt_token()}",
+                    "X-Request-ID": "bad id\twith spaces",
+                    "traceparent": "00-" + "0" * 32 + "-00f067aa0ba902b7-01",
+                },
+            )
+            assert response.headers["X-Request-ID"] != "bad id\twith spaces"
+            assert "traceparent" not in response.headers
+            response = await client.get(
+                "/v1/status",
+                headers={"Authorization": f"Bearer {_jwt_token()}", "traceparent": traceparent},
+            )
+            assert response.headers["traceparent"] == traceparent
+
+    asyncio.run(_run())
+
+
+def test_api_returns_embeddings_only_to_keys_with_the_embeddings_scope(tmp_path: Path) -> None:
+    async def _run() -> None:
+        app = _build_app(tmp_path)
+        transport = httpx.ASGITransport(app=app)
+        scoped = AsyncMemoryEngine(
+            config=Config(
+                api_key=_jwt_token(scopes=["read", "write", "memory:embeddings"]),
+                base_url="http://testserver",
+                max_retries=0,
+            ),
+            transport=transport,
+        )
+        plain = AsyncMemoryEngine(
+            config=Config(api_key=_jwt_token(), base_url="http://testserver", max_retries=0),
+            transport=transport,
+        )
+        try:
+            await scoped.ingest("Alice prefers aisle seats", entity_id="alice")
+            await scoped.ingest("Bob flies to Lisbon in May", entity_id="bob")
+
+            without = await scoped.retrieve("aisle seat", entity_id="alice")
+            assert without.memories[0].embedding is None
+            retrieved = await scoped.retrieve(
+                "aisle seat", entity_id="alice", include_embeddings=True
+            )
+            embedding = retrieved.memories[0].embedding
+            assert embedding is not None and len(embedding) == 32
+
+            nearest = await scoped.search_vector(embedding, limit=1, include_embeddings=True)
+            assert nearest.memories[0].memory_id == retrieved.memories[0].memory_id
+            assert nearest.memories[0].rank_score == pytest.approx(1.0, abs=1e-4)
+            assert nearest.memories[0].embedding == pytest.approx(embedding)
+
+            with pytest.raises(OrbitAuthError, match="memory:embeddings"):
+                await plain.retrieve("aisle seat", include_embeddings=True)
+        finally:
+            await scoped.aclose()
+            await plain.aclose()
+
+    asyncio.run(_run())
+
+
+def test_chat_keeps_session_state_retrieves_and_ingests_turns(
+    tmp_path: Path,
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    def fake_llm(target: object, messages: list[dict[str, str]]) -> str:
+        system, *history, latest = messages
+        if history:
+            return f"You asked: {history[0]['content']}"
+        if "aisle seats" in system["content"]:
+            return "Booked an aisle seat to Lisbon."
+        return "Booked a seat to Lisbon."
+
+    monkeypatch.setattr("orbit_api.service.call_summarizer", fake_llm)
+
+    async def _run() -> None:
+        app = _build_app(tmp_path, summarize_upstream_url="http://llm.test/v1")
+        engine = AsyncMemoryEngine(
+            config=Config(api_key=_jwt_token(), base_url="http://testserver", max_retries=0),
+            transport=httpx.ASGITransport(app=app),
+        )
+        try:
+            await engine.ingest("Alice prefers aisle seats", entity_id="alice")
+
+            first = await engine.chat("trip-1", "Book me a seat to Lisbon", entity_id="alice")
+            assert first.reply == "Booked an aisle seat to Lisbon."
+            assert "Alice prefers aisle seats" in [memory.content for memory in first.memories]
+            assert len(first.ingested_memory_ids) == 2
+            assert first.turn_count == 2
+
+            second = await engine.chat("trip-1", "What did I just ask?")
+            assert second.entity_id == "alice"
+            assert second.reply == "You asked: Book me a seat to Lisbon"
+            assert second.turn_count == 4
+
+            history = await engine.chat_history("trip-1")
+            assert [turn.role for turn in history.data] == [
+                "user",
+                "assistant",
+                "user",
+                "assistant",
+            ]
+            assert history.data[1].retrieved_memory_ids == [
+                memory.memory_id for memory in first.memories
+            ]
+            remembered = await engine.retrieve("Lisbon seat", entity_id="alice", limit=10)
+            assert set(first.ingested_memory_ids) <= {
+                memory.memory_id for memory in remembered.memories
+            }
+
+            with pytest.raises(OrbitValidationError, match="belongs to entity alice"):
+                await engine.chat("trip-1", "Hi", entity_id="bob")
+
+            cleared = await engine.clear_chat("trip-1")
+            assert len(cleared.data) == 4
+            assert (await engine.chat_history("trip-1")).data == []
+            with pytest.raises(OrbitNotFoundError):
+                await engine.clear_chat("trip-1")
+        finally:
+            await engine.aclose()
+
+    asyncio.run(_run())
+
+
+def test_api_accepts_hmac_signed_requests_from_sdk(tmp_path: Path) -> None:
+    signing_secret = "signing-secret-0123456789"
+
+    async def _run() -> None:
+        app = _build_app(
+            tmp_path,
+            request_signing_keys={"backend-1": f"signed-account:{signing_secret}"},
+        )
+        transport = httpx.ASGITransport(app=app)
+        sdk = AsyncMemoryEngine(
+            config=Config(
+                signing_key_id="backend-1",
+                signing_secret=signing_secret,
+                base_url="http://testserver",
+                max_retries=0,
+                enable_telemetry=False,
+            ),
+            transport=transport,
+        )
+        try:
+            ingest = await sdk.ingest(
+                content="Signed requests never carry a bearer token",
+                event_type="security_note",
+                entity_id="ops",
+            )
+            assert ingest.stored
+            retrieved = await sdk.retrieve("bearer token", entity_id="ops")
+            assert retrieved.memories
+        finally:
+            await sdk.aclose()
+
+        captured: list[httpx.Request] = []
+
+        async def _capture(request: httpx.Request) -> httpx.Response:
+            captured.append(request)
+            return httpx.Response(200, json={})
+
+        auth = HmacAuth("backend-1", signing_secret)
+        async with httpx.AsyncClient(
+            transport=httpx.MockTransport(_capture),
+            base_url="http://testserver",
+        ) as recorder:
+            await recorder.get("/v1/retrieve", params={"query": "bearer"}, auth=auth)
+            await recorder.get("/v1/retrieve", params={"query": "bearer"}, auth=auth)
+
+        async with httpx.AsyncClient(
+            transport=transport,
+            base_url="http://testserver",
+        ) as client:
+            first = await client.get(
+                "/v1/retrieve",
+                params={"query": "bearer"},
+                headers=dict(captured[0].headers),
+            )
+            assert first.status_code == 200
+            replayed = await client.get(
+                "/v1/retrieve",
+                params={"query": "bearer"},
+                headers=dict(captured[0].headers),
+            )
+            assert replayed.status_code == 401
+            tampered = await client.get(
+                "/v1/retrieve",
+                params={"query": "something else"},
+                headers=dict(captured[1].headers),
+            )
+            assert tampered.status_code == 401
+
+    asyncio.run(_run())
+
+
+def test_api_admin_dashboard_browses_tenants_and_manages_keys(tmp_path: Path) -> None:
+    async def _run() -> None:
+        app = _build_app(tmp_path, operator_tokens={"ops": OPERATOR_TOKEN})
+        transport = httpx.ASGITransport(app=app)
+        tenant_headers = {"Authorization": f"Bearer {_jwt_token('tenant-user')}"}
+        tenant_admin_headers = {
+            "Authorization": f"Bearer {_jwt_token('tenant-user', scopes=['admin'])}"
+        }
+        admin_headers = {"Authorization": f"Bearer {OPERATOR_TOKEN}"}
+
+        async with httpx.AsyncClient(
+            transport=transport,
+            base_url="http://testserver",
+        ) as client:
+            for entity_id, content in (
+                ("alice", "Alice prefers dark mode"),
+                ("alice", "Alice ships on Fridays"),
+                ("bob", "Bob prefers light mode"),
+            ):
+                ingest = await client.post(
+                    "/v1/ingest",
+                    headers=tenant_headers,
+                    json={"content": content, "event_type": "preference", "entity_id": entity_id},
+                )
+                assert ingest.status_code == 201
+
+            page = await client.get("/admin")
+            assert page.status_code == 200
+            assert "Orbit Admin" in page.text
+
+            forbidden = await client.get("/v1/admin/tenants", headers=tenant_headers)
+            assert forbidden.status_code == 403
+            # A tenant credential never reaches operator routes, even with the admin scope.
+            tenant_admin = await client.get("/v1/admin/tenants", headers=tenant_admin_headers)
+            assert tenant_admin.status_code == 403
+            self_granted = await client.post(
+                "/v1/dashboard/keys",
+                headers=tenant_headers,
+                json={"name": "escalate", "scopes": ["read", "admin"]},
+            )
+            assert self_granted.status_code == 422
+
+            tenants = await client.get("/v1/admin/tenants", headers=admin_headers)
+            assert tenants.status_code == 200
+            tenant = next(item for item in tenants.json()["data"] if item["memory_count"] == 3)
+            account_key = tenant["account_key"]
+
+            entities = await client.get(
+                f"/v1/admin/tenants/{account_key}/entities",
+                headers=admin_headers,
+            )
+            assert entities.status_code == 200
+            assert [(item["entity_id"], item["memory_count"]) for item in entities.json()["data"]] == [
+                ("alice", 2),
+                ("bob", 1),
+            ]
+
+            memories = await client.get(
+                f"/v1/admin/tenants/{account_key}/memories",
+                headers=admin_headers,
+                params={"entity_id": "bob"},
+            )
+            assert memories.status_code == 200
+            assert [item["content"] for item in memories.json()["data"]] == [
+                "Bob prefers light mode"
+            ]
+
+            retrieved = await client.get(
+                f"/v1/admin/tenants/{account_key}/retrieve",
+                headers=admin_headers,
+                params={"query": "display preference", "entity_id": "alice"},
+            )
+            assert retrieved.status_code == 200
+            assert all("Alice" in item["content"] for item in retrieved.json()["memories"])
+
+            issued = await client.post(
+                f"/v1/admin/tenants/{account_key}/keys",
+                headers=admin_headers,
+                json={"name": "ops-issued", "scopes": ["read"]},
+            )
+            assert issued.status_code == 201
+            key_id = issued.json()["key_id"]
+            revoked = await client.post(
+                f"/v1/admin/tenants/{account_key}/keys/{key_id}/revoke",
+                headers=admin_headers,
+            )
+            assert revoked.status_code == 200
+            assert revoked.json()["revoked"] is True
+
+            metrics = await client.get("/v1/admin/metrics", headers=admin_headers)
+            assert metrics.status_code == 200
+            assert metrics.json()["requests"]["ingest_requests_total"] == 3
+
+    asyncio.run(_run())
+
+
+def test_api_admin_dashboard_can_be_disabled(tmp_path: Path) -> None:
+    async def _run() -> None:
+        app = _build_app(
+            tmp_path,
+            admin_dashboard_enabled=False,
+            operator_tokens={"ops": OPERATOR_TOKEN},
+        )
+        transport = httpx.ASGITransport(app=app)
+        admin_headers = {"Authorization": f"Bearer {OPERATOR_TOKEN}"}
+
+        async with httpx.AsyncClient(
+            transport=transport,
+            base_url="http://testserver",
+        ) as client:
+            assert (await client.get("/admin")).status_code == 404
+            disabled = await client.get("/v1/admin/tenants", headers=admin_headers)
+            assert disabled.status_code == 404
+
+    asyncio.run(_run())
+
+
+def test_api_binds_agent_id_to_agent_scoped_credentials(tmp_path: Path) -> None:
+    async def _run() -> None:
+        app = _build_app(tmp_path)
+        transport = httpx.ASGITransport(app=app)
+        writer = {"Authorization": f"Bearer {_jwt_token()}"}
+        researcher = {
+            "Authorization": f"Bearer {_jwt_token(scopes=['read', 'agent:researcher'])}"
+        }
+        planner = {"Authorization": f"Bearer {_jwt_token(scopes=['read', 'agent:planner'])}"}
+
+        async with httpx.AsyncClient(
+            transport=transport,
+            base_url="http://testserver",
+        ) as client:
+            stored = await client.post(
+                "/v1/ingest",
+                headers=writer,
+                json={
+                    "content": "Alice booked a hotel in Lisbon for May",
+                    "entity_id": "alice",
+                    "agent_id": "researcher",
+                    "agent_scope": "private",
+                },
+            )
+            assert stored.status_code == 201
+
+            own = await client.get("/v1/memories", headers=researcher)
+            assert [item["content"] for item in own.json()["data"]] == [
+                "Alice booked a hotel in Lisbon for May"
+            ]
+            other = await client.get("/v1/memories", headers=planner)
+            assert other.json()["data"] == []
+            spoofed = await client.get(
+                "/v1/memories",
+                headers=planner,
+                params={"agent_id": "researcher"},
+            )
+            assert spoofed.status_code == 403
+            feed = await client.get("/v1/changes", headers=planner)
+            assert feed.json()["data"] == []
+
+    asyncio.run(_run())
+
+
+def test_replication_requests_use_the_sdk_canonical_request() -> None:
+    secret = "replication-secret-for-integration-tests"
+    body = b'{"cursor": 5}'
+    headers = replication_headers(
+        secret,
+        method="POST",
+        path="/v1/internal/replication/acme/changes",
+        query="b=2&a=1",
+        body=body,
+    )
+    canonical = canonical_request(
+        method="POST",
+        path="/v1/internal/replication/acme/changes",
+        query="a=1&b=2",
+        timestamp=headers[REPLICATION_TIMESTAMP_HEADER],
+        nonce=headers[REPLICATION_NONCE_HEADER],
+        body=body,
+    )
+    assert headers[REPLICATION_SIGNATURE_HEADER] == f"sha256={compute_signature(secret, canonical)}"
+
+
+def test_api_refuses_replayed_or_altered_replication_requests(tmp_path: Path) -> None:
+    secret = "replication-secret-for-integration-tests"
+
+    async def _run() -> None:
+        app = _build_app(tmp_path, replication_secret=secret)
+        transport = httpx.ASGITransport(app=app)
+        path = "/v1/internal/replication/acme/changes"
+
+        async with httpx.AsyncClient(
+            transport=transport,
+            base_url="http://testserver",
+        ) as client:
+            headers = replication_headers(
+                secret,
+                method="GET",
+                path=path,
+                query="cursor=5",
+                body=b"",
+            )
+            first = await client.get(path, params={"cursor": "5"}, headers=headers)
+            assert first.status_code == 200
+            replayed = await client.get(path, params={"cursor": "5"}, headers=headers)
+            assert replayed.status_code == 401
+
+            headers = replication_headers(
+                secret,
+                method="GET",
+                path=path,
+                query="cursor=5",
+                body=b"",
+            )
+            rewound = await client.get(path, params={"cursor": "0"}, headers=headers)
+            assert rewound.status_code == 401
+
+    asyncio.run(_run())
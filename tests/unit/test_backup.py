@@ -0,0 +1,167 @@
+from __future__ import annotations
+
+from datetime import UTC, datetime, timedelta
+from pathlib import Path
+
+import pytest
+
+from decision_engine.models import (
+    EncodedEvent,
+    RawEvent,
+    SemanticUnderstanding,
+    StorageDecision,
+    StorageTier,
+)
+from decision_engine.storage_sqlalchemy import SQLAlchemyStorageManager
+from orbit_api import cli
+from orbit_api.backup import create_backup, list_backups, restore_backup
+from orbit_api.blob_store import BlobObject, LocalBlobStore
+
+
+class _DictBlobStore:
+    """Stands in for an object store: nothing lands on local disk."""
+
+    backend = "s3"
+
+    def __init__(self) -> None:
+        self.objects: dict[str, tuple[bytes, str]] = {}
+
+    def put(self, key: str, data: bytes, *, content_type: str) -> BlobObject:
+        self.objects[key] = (data, content_type)
+        return BlobObject(key=key, content_type=content_type, size_bytes=len(data), sha256="")
+
+    def get(self, key: str) -> bytes:
+        if key not in self.objects:
+            raise KeyError(key)
+        return self.objects[key][0]
+
+    def delete(self, key: str) -> None:
+        self.objects.pop(key, None)
+
+
+def _store(
+    manager: SQLAlchemyStorageManager,
+    content: str,
+    relationships: list[str] | None = None,
+) -> str:
+    encoded = EncodedEvent(
+        event=RawEvent(content=content, context={"intent": "interaction"}),
+        raw_embedding=[1.0, 0.0],
+        semantic_embedding=[1.0, 0.0],
+        understanding=SemanticUnderstanding(
+            summary=content,
+            intent="interaction",
+            entities=["user_1"],
+            relationships=relationships or [],
+        ),
+        semantic_key=f"key-{content}",
+    )
+    decision = StorageDecision(
+        should_store=True,
+        tier=StorageTier.PERSISTENT,
+        confidence=0.9,
+        rationale="test",
+        trace={},
+    )
+    return manager.store(encoded, decision, account_key="acct").memory_id
+
+
+def test_full_and_incremental_backup_restore(tmp_path: Path) -> None:
+    database_url = f"sqlite:///{tmp_path / 'orbit.db'}"
+    blob_store = LocalBlobStore(tmp_path / "blobs")
+    blob_store.put("acct/one.bin", b"one", content_type="application/octet-stream")
+    backups = tmp_path / "backups"
+    base_time = datetime.now(UTC)
+
+    manager = SQLAlchemyStorageManager(database_url)
+    try:
+        first = _store(manager, "first memory", ["blob_key:acct/one.bin"])
+        full = create_backup(
+            backups, database_url=database_url, blob_store=blob_store, now=base_time
+        )
+        second = _store(manager, "second memory")
+        manager.delete_memories([first], account_key="acct")
+        incremental = create_backup(
+            backups,
+            database_url=database_url,
+            blob_store=blob_store,
+            incremental=True,
+            now=base_time + timedelta(minutes=5),
+        )
+        _store(manager, "bad bulk import")
+    finally:
+        manager.close()
+
+    assert full.kind == "full"
+    assert full.tables["memories"] == 1
+    assert full.blobs == 1
+    assert incremental.kind == "incremental"
+    assert incremental.parent_id == full.backup_id
+    assert [item.backup_id for item in list_backups(backups)] == [
+        full.backup_id,
+        incremental.backup_id,
+    ]
+
+    restored_blobs = tmp_path / "restored-blobs"
+    restore_backup(
+        backups, database_url=database_url, blob_store=LocalBlobStore(restored_blobs)
+    )
+    manager = SQLAlchemyStorageManager(database_url)
+    try:
+        ids = {item.memory_id for item in manager.list_memories(account_key="acct")}
+        assert ids == {second}
+    finally:
+        manager.close()
+    assert (restored_blobs / "acct" / "one.bin").read_bytes() == b"one"
+
+    restore_backup(
+        backups,
+        database_url=database_url,
+        to_timestamp=base_time + timedelta(minutes=1),
+    )
+    manager = SQLAlchemyStorageManager(database_url)
+    try:
+        ids = {item.memory_id for item in manager.list_memories(account_key="acct")}
+        assert ids == {first}
+    finally:
+        manager.close()
+
+
+def test_backup_reads_and_restores_attachments_through_the_blob_store(tmp_path: Path) -> None:
+    database_url = f"sqlite:///{tmp_path / 'orbit.db'}"
+    remote = _DictBlobStore()
+    remote.put("acct/report.pdf", b"%PDF", content_type="application/pdf")
+    remote.put("acct/unreferenced.bin", b"stray", content_type="application/octet-stream")
+    backups = tmp_path / "backups"
+
+    manager = SQLAlchemyStorageManager(database_url)
+    try:
+        _store(
+            manager,
+            "quarterly report",
+            ["blob_key:acct/report.pdf", "blob_content_type:application/pdf"],
+        )
+        manifest = create_backup(backups, database_url=database_url, blob_store=remote)
+    finally:
+        manager.close()
+
+    assert manifest.blobs == 1
+    assert not (tmp_path / "blobs").exists()
+
+    restored = _DictBlobStore()
+    restore_backup(backups, database_url=database_url, blob_store=restored)
+    assert restored.objects == {"acct/report.pdf": (b"%PDF", "application/pdf")}
+
+
+def test_incremental_backup_requires_full_backup(tmp_path: Path) -> None:
+    with pytest.raises(ValueError, match="full backup"):
+        create_backup(
+            tmp_path / "backups",
+            database_url=f"sqlite:///{tmp_path / 'orbit.db'}",
+            incremental=True,
+        )
+
+
+def test_backup_restore_cli_requires_confirmation(tmp_path: Path) -> None:
+    with pytest.raises(SystemExit):
+        cli.main(["backup", "restore", "--dir", str(tmp_path)])
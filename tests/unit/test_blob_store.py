@@ -0,0 +1,278 @@
+from __future__ import annotations
+
+import base64
+from pathlib import Path
+from typing import Any
+
+import pytest
+
+from memory_engine.config import EngineConfig
+from orbit.models import IngestAttachment, IngestRequest
+from orbit_api.blob_store import LocalBlobStore, S3BlobStore, blob_key
+from orbit_api.config import ApiConfig
+from orbit_api.service import OrbitApiService
+
+
+def test_clients_cannot_claim_another_accounts_blob(tmp_path: Path) -> None:
+    db_path = tmp_path / "blob-forgery.db"
+    blob_root = tmp_path / "blobs"
+    service = OrbitApiService(
+        api_config=ApiConfig(
+            database_url=f"sqlite:///{db_path}",
+            sqlite_fallback_path=str(db_path),
+            blob_store_path=str(blob_root),
+        ),
+        engine_config=EngineConfig(
+            sqlite_path=str(db_path),
+            database_url=f"sqlite:///{db_path}",
+            embedding_dim=16,
+            persistent_confidence_prior=0.0,
+            ephemeral_confidence_prior=0.0,
+        ),
+    )
+    try:
+        victim = service.ingest(
+            IngestRequest(
+                content="Signed contract uploaded",
+                entity_id="alice",
+                attachment=IngestAttachment(
+                    content_base64=base64.b64encode(b"%PDF-1.4 secret").decode("ascii"),
+                    filename="contract.pdf",
+                ),
+            ),
+            account_key="victim",
+        )
+        victim_record = service._engine.storage.fetch_by_ids(
+            [victim.memory_id], account_key="victim"
+        )[0]
+        victim_key = next(
+            item.removeprefix("blob_key:")
+            for item in victim_record.relationships
+            if item.startswith("blob_key:")
+        )
+
+        forged = service.ingest(
+            IngestRequest(
+                content="Totally my file",
+                entity_id="mallory",
+                metadata={
+                    "relationships": [
+                        f"blob_key:{victim_key}",
+                        "sensitivity:public",
+                        "agent:other",
+                        "agent_scope:private",
+                        "category:billing",
+                        "url_source:src_1",
+                        "crawled_at:2026-01-01T00:00:00+00:00",
+                        "topic:contracts",
+                    ]
+                },
+            ),
+            account_key="mallory",
+        )
+        record = service._engine.storage.fetch_by_ids(
+            [forged.memory_id], account_key="mallory"
+        )[0]
+        assert "topic:contracts" in record.relationships
+        assert not any(
+            item.startswith(("blob_", "sensitivity:", "agent", "url_source:", "crawled_at:"))
+            for item in record.relationships
+        )
+        with pytest.raises(KeyError):
+            service.memory_attachment(forged.memory_id, account_key="mallory")
+
+        # Even a stored relationship naming a foreign key is neither served nor deleted.
+        service._engine.update_relationships(
+            forged.memory_id, [f"blob_key:{victim_key}"], account_key="mallory"
+        )
+        with pytest.raises(KeyError):
+            service.memory_attachment(forged.memory_id, account_key="mallory")
+        service._engine.delete_memories([forged.memory_id], account_key="mallory")
+        data, _, _ = service.memory_attachment(victim.memory_id, account_key="victim")
+        assert data == b"%PDF-1.4 secret"
+    finally:
+        service.close()
+
+
+class _FakeS3Client:
+    def __init__(self) -> None:
+        self.objects: dict[tuple[str, str], bytes] = {}
+
+    def put_object(self, *, Bucket: str, Key: str, Body: bytes, ContentType: str) -> None:
+        self.objects[(Bucket, Key)] = Body
+
+    def get_object(self, *, Bucket: str, Key: str) -> dict[str, Any]:
+        body = self.objects[(Bucket, Key)]
+
+        class _Body:
+            def read(self) -> bytes:
+                return body
+
+        return {"Body": _Body()}
+
+    def delete_object(self, *, Bucket: str, Key: str) -> None:
+        self.objects.pop((Bucket, Key), None)
+
+
+def test_local_blob_store_round_trip(tmp_path: Path) -> None:
+    store = LocalBlobStore(tmp_path / "blobs")
+    stored = store.put("acct/ref/file.txt", b"hello", content_type="text/plain")
+    assert stored.size_bytes == 5
+    assert store.get("acct/ref/file.txt") == b"hello"
+    store.delete("acct/ref/file.txt")
+    with pytest.raises(KeyError):
+        store.get("acct/ref/file.txt")
+
+
+def test_local_blob_store_rejects_path_escape(tmp_path: Path) -> None:
+    store = LocalBlobStore(tmp_path / "blobs")
+    with pytest.raises(ValueError):
+        store.put("../outside.bin", b"x", content_type="application/octet-stream")
+
+
+def test_blob_key_sanitizes_segments() -> None:
+    assert blob_key("acct one", "abc", "../../etc/passwd") == "acct_one/abc/etc_passwd"
+    assert blob_key("acct", "abc") == "acct/abc/blob"
+
+
+def test_s3_blob_store_uses_prefix() -> None:
+    client = _FakeS3Client()
+    store = S3BlobStore("bucket", prefix="/orbit/", client=client)
+    store.put("acct/ref/blob", b"payload", content_type="image/png")
+    assert ("bucket", "orbit/acct/ref/blob") in client.objects
+    assert store.get("acct/ref/blob") == b"payload"
+
+
+def test_ingest_attachment_is_offloaded_to_blob_store(tmp_path: Path) -> None:
+    db_path = tmp_path / "blob-service.db"
+    service = OrbitApiService(
+        api_config=ApiConfig(
+            database_url=f"sqlite:///{db_path}",
+            sqlite_fallback_path=str(db_path),
+            blob_store_path=str(tmp_path / "blobs"),
+            max_attachment_bytes=64,
+        ),
+        engine_config=EngineConfig(
+            sqlite_path=str(db_path),
+            database_url=f"sqlite:///{db_path}",
+            embedding_dim=16,
+            persistent_confidence_prior=0.0,
+            ephemeral_confidence_prior=0.0,
+        ),
+    )
+    try:
+        payload = b"%PDF-1.4 tiny"
+        response = service.ingest(
+            IngestRequest(
+                content="Quarterly planning doc uploaded",
+                entity_id="alice",
+                attachment=IngestAttachment(
+                    content_base64=base64.b64encode(payload).decode("ascii"),
+                    content_type="application/pdf",
+                    filename="plan.pdf",
+                ),
+            ),
+            account_key="acct",
+        )
+        data, content_type, filename = service.memory_attachment(
+            response.memory_id,
+            account_key="acct",
+        )
+        assert data == payload
+        assert content_type == "application/pdf"
+        assert filename == "plan.pdf"
+
+        listed = service.list_memories(limit=10, cursor=None, account_key="acct")
+        attachment = listed.data[0].metadata["attachment"]
+        assert attachment["size_bytes"] == len(payload)
+        assert payload not in listed.data[0].content.encode()
+
+        with pytest.raises(KeyError):
+            service.memory_attachment(response.memory_id, account_key="other")
+
+        restricted = service.ingest(
+            IngestRequest(
+                content="Signed offer letter uploaded",
+                entity_id="alice",
+                sensitivity="confidential",
+                attachment=IngestAttachment(
+                    content_base64=base64.b64encode(b"offer").decode("ascii"),
+                ),
+            ),
+            account_key="acct",
+        )
+        with pytest.raises(KeyError):
+            service.memory_attachment(
+                restricted.memory_id,
+                account_key="acct",
+                max_sensitivity="internal",
+            )
+        cleared, _, _ = service.memory_attachment(
+            restricted.memory_id,
+            account_key="acct",
+            max_sensitivity="confidential",
+        )
+        assert cleared == b"offer"
+        with pytest.raises(ValueError):
+            service.ingest(
+                IngestRequest(
+                    content="too big",
+                    attachment=IngestAttachment(
+                        content_base64=base64.b64encode(b"x" * 65).decode("ascii"),
+                    ),
+                ),
+                account_key="acct",
+            )
+    finally:
+        service.close()
+
+
+def test_attachment_blobs_do_not_outlive_their_memory(
+    tmp_path: Path,
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    db_path = tmp_path / "blob-cleanup.db"
+    blob_root = tmp_path / "blobs"
+    service = OrbitApiService(
+        api_config=ApiConfig(
+            database_url=f"sqlite:///{db_path}",
+            sqlite_fallback_path=str(db_path),
+            blob_store_path=str(blob_root),
+        ),
+        engine_config=EngineConfig(
+            sqlite_path=str(db_path),
+            database_url=f"sqlite:///{db_path}",
+            embedding_dim=16,
+            persistent_confidence_prior=0.0,
+            ephemeral_confidence_prior=0.0,
+        ),
+    )
+
+    def stored_blobs() -> list[Path]:
+        return [path for path in blob_root.rglob("*") if path.is_file()]
+
+    def upload(content: str) -> IngestRequest:
+        return IngestRequest(
+            content=content,
+            entity_id="alice",
+            attachment=IngestAttachment(
+                content_base64=base64.b64encode(b"%PDF-1.4 tiny").decode("ascii"),
+                filename="plan.pdf",
+            ),
+        )
+
+    try:
+        response = service.ingest(upload("Quarterly planning doc uploaded"), account_key="acct")
+        assert len(stored_blobs()) == 1
+        service._engine.delete_memories([response.memory_id], account_key="acct")
+        assert stored_blobs() == []
+
+        def failing_store(*_args: Any, **_kwargs: Any) -> Any:
+            raise RuntimeError("storage unavailable")
+
+        monkeypatch.setattr(service._engine, "store_memory", failing_store)
+        with pytest.raises(RuntimeError, match="storage unavailable"):
+            service.ingest(upload("Budget spreadsheet uploaded"), account_key="acct")
+        assert stored_blobs() == []
+    finally:
+        service.close()
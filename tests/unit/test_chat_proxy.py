@@ -0,0 +1,155 @@
+from __future__ import annotations
+
+import asyncio
+import json
+from pathlib import Path
+
+import httpx
+
+from memory_engine.config import EngineConfig
+from orbit.models import IngestRequest
+from orbit_api.chat_proxy import MEMORY_PREAMBLE, ChatMemoryProxy, last_user_message
+from orbit_api.config import ApiConfig
+from orbit_api.service import OrbitApiService
+
+
+def _service(tmp_path: Path) -> OrbitApiService:
+    db_path = tmp_path / "chat_proxy.db"
+    api_config = ApiConfig(
+        database_url=f"sqlite:///{db_path}",
+        sqlite_fallback_path=str(db_path),
+    )
+    engine_config = EngineConfig(
+        sqlite_path=str(db_path),
+        database_url=f"sqlite:///{db_path}",
+        embedding_dim=16,
+        persistent_confidence_prior=0.0,
+        ephemeral_confidence_prior=0.0,
+    )
+    return OrbitApiService(api_config=api_config, engine_config=engine_config)
+
+
+def test_last_user_message_reads_text_parts() -> None:
+    messages = [
+        {"role": "user", "content": "first"},
+        {"role": "assistant", "content": "reply"},
+        {
+            "role": "user",
+            "content": [
+                {"type": "text", "text": "what plan"},
+                {"type": "image_url", "image_url": {"url": "https://example.com/a.png"}},
+                {"type": "text", "text": "am I on?"},
+            ],
+        },
+    ]
+    assert last_user_message(messages) == "what plan am I on?"
+    assert last_user_message([{"role": "system", "content": "hi"}]) is None
+
+
+def test_complete_injects_memories_and_records_turn(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    upstream_bodies: list[dict[str, object]] = []
+
+    def handler(request: httpx.Request) -> httpx.Response:
+        upstream_bodies.append(json.loads(request.content))
+        assert request.headers["Authorization"] == "Bearer sk-upstream"
+        return httpx.Response(
+            200,
+            json={"choices": [{"message": {"role": "assistant", "content": "You are on Pro."}}]},
+        )
+
+    try:
+        service.ingest_with_quota(
+            account_key="acct",
+            request=IngestRequest(
+                content="Alice upgraded to the Pro plan last week",
+                event_type="user_fact",
+                entity_id="alice",
+            ),
+            idempotency_key=None,
+        )
+        proxy = ChatMemoryProxy(
+            service,
+            upstream_base_url="https://llm.example.com/v1/",
+            upstream_api_key="sk-upstream",
+            transport=httpx.MockTransport(handler),
+        )
+        body = {
+            "model": "gpt-4o-mini",
+            "messages": [
+                {"role": "system", "content": "You are a support agent."},
+                {"role": "user", "content": "Which plan am I on?"},
+            ],
+        }
+        augmented, user_message = proxy.augment(body, account_key="acct", entity_id="alice")
+        payload = asyncio.run(
+            proxy.complete(
+                augmented,
+                user_message=user_message,
+                account_key="acct",
+                entity_id="alice",
+            )
+        )
+
+        assert payload["choices"][0]["message"]["content"] == "You are on Pro."
+        assert body["messages"][0]["content"] == "You are a support agent."
+        system_prompt = upstream_bodies[0]["messages"][0]["content"]  # type: ignore[index]
+        assert system_prompt.startswith("You are a support agent.")
+        assert MEMORY_PREAMBLE in system_prompt
+        assert "Pro plan" in system_prompt
+
+        memories = service.list_memories(limit=10, cursor=None, account_key="acct")
+        contents = {memory.content for memory in memories.data}
+        assert "Which plan am I on?" in contents
+        assert "You are on Pro." in contents
+        assert len(memories.data) == 3
+    finally:
+        service.close()
+
+
+def test_stream_relays_chunks_and_reports_upstream_errors(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    chunks = [
+        'data: {"choices":[{"delta":{"content":"Hello"}}]}',
+        "",
+        'data: {"choices":[{"delta":{"content":" there"}}]}',
+        "",
+        "data: [DONE]",
+        "",
+    ]
+
+    def handler(request: httpx.Request) -> httpx.Response:
+        if json.loads(request.content)["model"] == "broken":
+            return httpx.Response(500, text="boom")
+        return httpx.Response(200, text="\n".join(chunks))
+
+    async def collect(proxy: ChatMemoryProxy, model: str) -> bytes:
+        parts = []
+        async for part in proxy.stream(
+            {"model": model, "messages": [{"role": "user", "content": "hi"}]},
+            user_message="hi",
+            account_key="acct",
+            entity_id="bob",
+        ):
+            parts.append(part)
+        return b"".join(parts)
+
+    try:
+        proxy = ChatMemoryProxy(
+            service,
+            upstream_base_url="https://llm.example.com/v1",
+            transport=httpx.MockTransport(handler),
+        )
+        relayed = asyncio.run(collect(proxy, "gpt-4o-mini"))
+        assert b"[DONE]" in relayed
+        contents = {
+            memory.content
+            for memory in service.list_memories(limit=10, cursor=None, account_key="acct").data
+        }
+        assert contents == {"hi", "Hello there"}
+
+        failed = asyncio.run(collect(proxy, "broken"))
+        assert b'"error"' in failed
+        assert b"500" in failed
+    finally:
+        service.close()
@@ -0,0 +1,108 @@
+from __future__ import annotations
+
+from pathlib import Path
+
+import pytest
+
+from orbit_api.config import ApiConfig, load_config_file
+from orbit_api.config_reload import ConfigReloader
+
+
+def _write(path: Path, text: str) -> Path:
+    path.write_text(text, encoding="utf-8")
+    return path
+
+
+def test_load_config_file_flattens_sections_and_collects_engine(tmp_path: Path) -> None:
+    path = _write(
+        tmp_path / "orbit.toml",
+        """
+default_entity_id = "team"
+
+[limits]
+max_batch_items = 250
+
+[integrations]
+slack_user_entities = { U1 = "alice" }
+
+[engine]
+flash_pipeline_mode = "async"
+""",
+    )
+
+    config = ApiConfig.from_file(path)
+
+    assert config.default_entity_id == "team"
+    assert config.max_batch_items == 250
+    assert config.slack_user_entities == {"U1": "alice"}
+    assert config.engine_overrides == {"flash_pipeline_mode": "async"}
+    assert config.config_file == str(path)
+
+
+def test_load_config_file_rejects_unknown_keys_and_formats(tmp_path: Path) -> None:
+    unknown = _write(tmp_path / "orbit.toml", "[limits]\nmax_widgets = 3\n")
+    with pytest.raises(ValueError, match="max_widgets"):
+        load_config_file(unknown)
+
+    ini = _write(tmp_path / "orbit.ini", "x=1\n")
+    with pytest.raises(ValueError, match="must be"):
+        load_config_file(ini)
+
+
+def test_from_env_overlays_config_file(tmp_path: Path, monkeypatch: pytest.MonkeyPatch) -> None:
+    path = _write(tmp_path / "orbit.json", '{"free_retention_days": 45}')
+    monkeypatch.setenv("ORBIT_DEFAULT_EVENT_TYPE", "from_env")
+    monkeypatch.setenv("ORBIT_CONFIG_FILE", str(path))
+
+    config = ApiConfig.from_env()
+
+    assert config.free_retention_days == 45
+    assert config.default_event_type == "from_env"
+
+
+def test_reload_applies_reloadable_fields_only(tmp_path: Path) -> None:
+    path = _write(tmp_path / "orbit.toml", "max_batch_items = 100\n")
+    config = ApiConfig.from_file(path)
+    reloader = ConfigReloader(config, path)
+
+    _write(
+        path,
+        'max_batch_items = 10\nblob_store_path = "elsewhere"\n',
+    )
+    result = reloader.reload()
+
+    assert result == {"applied": ["max_batch_items"], "restart_required": ["blob_store_path"]}
+    assert config.max_batch_items == 10
+    assert config.blob_store_path == "blobs"
+
+
+def test_reload_keeps_running_config_when_file_is_invalid(tmp_path: Path) -> None:
+    path = _write(tmp_path / "orbit.toml", "max_batch_items = 100\n")
+    config = ApiConfig.from_file(path)
+    reloader = ConfigReloader(config, path)
+
+    _write(path, "max_batch_items = -1\n")
+    result = reloader.reload()
+
+    assert result == {"applied": [], "restart_required": []}
+    assert config.max_batch_items == 100
+
+
+def test_reload_rereads_rotated_secrets_from_their_source(
+    tmp_path: Path, monkeypatch: pytest.MonkeyPatch
+) -> None:
+    path = _write(tmp_path / "orbit.toml", "max_batch_items = 100\n")
+    secret = _write(tmp_path / "jwt_secret", "first-secret")
+    monkeypatch.delenv("ORBIT_JWT_SECRET", raising=False)
+    monkeypatch.setenv("ORBIT_JWT_SECRET_FILE", str(secret))
+    monkeypatch.setenv("ORBIT_CONFIG_FILE", str(path))
+    config = ApiConfig.from_env()
+    reloader = ConfigReloader(config, path, source=ApiConfig.from_environment)
+    assert config.jwt_secret == "first-secret"
+
+    _write(secret, "rotated-secret")
+    result = reloader.reload()
+
+    assert "jwt_secret" in result["applied"]
+    assert config.jwt_secret == "rotated-secret"
+    assert config.max_batch_items == 100
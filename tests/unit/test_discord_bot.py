@@ -0,0 +1,96 @@
+from __future__ import annotations
+
+from pathlib import Path
+
+from memory_engine.config import EngineConfig
+from orbit.models import IngestRequest
+from orbit_api.config import ApiConfig
+from orbit_api.discord_bot import DiscordMemoryBridge, DiscordMessage
+from orbit_api.service import OrbitApiService
+
+
+def _service(tmp_path: Path) -> OrbitApiService:
+    db_path = tmp_path / "discord.db"
+    api_config = ApiConfig(
+        database_url=f"sqlite:///{db_path}",
+        sqlite_fallback_path=str(db_path),
+    )
+    engine_config = EngineConfig(
+        sqlite_path=str(db_path),
+        database_url=f"sqlite:///{db_path}",
+        embedding_dim=16,
+        persistent_confidence_prior=0.0,
+        ephemeral_confidence_prior=0.0,
+    )
+    return OrbitApiService(api_config=api_config, engine_config=engine_config)
+
+
+def _message(**overrides: object) -> DiscordMessage:
+    values: dict[str, object] = {
+        "message_id": "m1",
+        "guild_id": "g1",
+        "channel_id": "c-general",
+        "author_id": "42",
+        "author_name": "Ada",
+        "content": "My main character is a level 60 paladin",
+    }
+    values.update(overrides)
+    return DiscordMessage(**values)  # type: ignore[arg-type]
+
+
+def test_message_to_ingest_filters_channels_and_bots(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        bridge = DiscordMemoryBridge(service, account_key="acct", channel_ids=["c-general"])
+        request = bridge.message_to_ingest(_message())
+
+        assert request is not None
+        assert request.entity_id == "discord:42"
+        assert request.event_type == "discord_message"
+        assert bridge.message_to_ingest(_message(channel_id="c-other")) is None
+        assert bridge.message_to_ingest(_message(is_bot=True)) is None
+        assert bridge.message_to_ingest(_message(content="   ")) is None
+    finally:
+        service.close()
+
+
+def test_recall_is_scoped_to_the_calling_user(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        bridge = DiscordMemoryBridge(service, account_key="acct", channel_ids=["c-general"])
+        bridge.handle_message(_message())
+        bridge.handle_message(
+            _message(message_id="m2", author_id="7", content="My main character is a rogue")
+        )
+
+        own = bridge.recall("what is my main character", user_id="42")
+        assert "paladin" in own
+        assert "rogue" not in own
+        assert bridge.recall("   ", user_id="42").startswith("Usage")
+    finally:
+        service.close()
+
+
+def test_recall_leaves_out_memories_above_the_bot_clearance(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        service.ingest(
+            IngestRequest(
+                content="My main character's account password is hunter2",
+                entity_id=DiscordMemoryBridge.entity_for("42"),
+                sensitivity="confidential",
+            ),
+            account_key="acct",
+        )
+        public = DiscordMemoryBridge(service, account_key="acct", channel_ids=["c-general"])
+        assert "hunter2" not in public.recall("main character password", user_id="42")
+
+        cleared = DiscordMemoryBridge(
+            service,
+            account_key="acct",
+            channel_ids=["c-general"],
+            recall_max_sensitivity="confidential",
+        )
+        assert "hunter2" in cleared.recall("main character password", user_id="42")
+    finally:
+        service.close()
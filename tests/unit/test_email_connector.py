@@ -0,0 +1,144 @@
+from __future__ import annotations
+
+import base64
+import json
+from pathlib import Path
+from typing import Any
+
+from memory_engine.config import EngineConfig
+from orbit_api.config import ApiConfig
+from orbit_api.email_connector import (
+    EmailIngestor,
+    ImapPoller,
+    extract_sendgrid_email,
+    extract_ses_email,
+    is_sns_subscribe_url,
+    parse_email,
+    strip_quoted_text,
+)
+from orbit_api.service import OrbitApiService
+
+RAW_EMAIL = b"""From: Alice Smith <Alice@Example.com>
+To: sales@orbit.dev
+Subject: Renewal
+Message-ID: <renewal-1@example.com>
+Date: Tue, 13 Oct 2026 10:00:00 +0000
+Content-Type: text/plain
+
+We want to renew for 50 seats.
+> quoted line from an earlier message
+Thanks
+
+--
+Alice Smith
+CTO, Example
+
+On Mon, Oct 12, 2026 at 9:00 AM Bob <bob@orbit.dev> wrote:
+> Are you renewing?
+"""
+
+
+def _service(tmp_path: Path) -> OrbitApiService:
+    db_path = tmp_path / "email.db"
+    api_config = ApiConfig(
+        database_url=f"sqlite:///{db_path}",
+        sqlite_fallback_path=str(db_path),
+    )
+    engine_config = EngineConfig(
+        sqlite_path=str(db_path),
+        database_url=f"sqlite:///{db_path}",
+        embedding_dim=16,
+        persistent_confidence_prior=0.0,
+        ephemeral_confidence_prior=0.0,
+    )
+    return OrbitApiService(api_config=api_config, engine_config=engine_config)
+
+
+class _FakeImap:
+    def __init__(self, messages: dict[bytes, bytes]) -> None:
+        self.messages = messages
+        self.seen: list[bytes] = []
+
+    def login(self, username: str, password: str) -> None:
+        return None
+
+    def select(self, mailbox: str) -> None:
+        return None
+
+    def uid(self, command: str, *args: Any) -> tuple[str, list[Any]]:
+        if command == "SEARCH":
+            return "OK", [b" ".join(self.messages)]
+        if command == "FETCH":
+            uid = args[0]
+            return "OK", [(b"1 (BODY[] {1}", self.messages[uid]), b")"]
+        if command == "STORE":
+            self.seen.append(args[0])
+        return "OK", []
+
+    def logout(self) -> None:
+        return None
+
+
+def test_parse_email_strips_quotes_and_signature() -> None:
+    parsed = parse_email(RAW_EMAIL)
+
+    assert parsed.sender == "alice@example.com"
+    assert parsed.sender_name == "Alice Smith"
+    assert parsed.subject == "Renewal"
+    assert parsed.body == "We want to renew for 50 seats.\nThanks"
+    assert parsed.recipients == ["sales@orbit.dev"]
+    assert strip_quoted_text("Hi\n\nSent from my iPhone") == "Hi"
+
+
+def test_extracts_raw_message_from_sendgrid_and_ses_payloads() -> None:
+    boundary = "orbit-boundary"
+    body = (
+        f'--{boundary}\r\nContent-Disposition: form-data; name="to"\r\n\r\nsales@orbit.dev\r\n'
+        f'--{boundary}\r\nContent-Disposition: form-data; name="email"\r\n\r\n'
+    ).encode() + RAW_EMAIL + f"\r\n--{boundary}--\r\n".encode()
+    sendgrid = extract_sendgrid_email(body, f"multipart/form-data; boundary={boundary}")
+    assert sendgrid is not None
+    assert parse_email(sendgrid).sender == "alice@example.com"
+
+    notification = {
+        "content": base64.b64encode(RAW_EMAIL).decode(),
+        "receipt": {"action": {"type": "SNS", "encoding": "BASE64"}},
+    }
+    ses = extract_ses_email({"Type": "Notification", "Message": json.dumps(notification)})
+    assert ses == RAW_EMAIL
+    assert extract_ses_email({"Type": "SubscriptionConfirmation"}) is None
+    assert is_sns_subscribe_url("https://sns.us-east-1.amazonaws.com/?Action=ConfirmSubscription")
+    assert not is_sns_subscribe_url("https://example.com/sns.us-east-1.amazonaws.com")
+
+
+def test_ingestor_and_imap_poller_store_under_sender_entity(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        ingestor = EmailIngestor(service, account_key="acct-crm")
+        first = ingestor.ingest_raw(RAW_EMAIL)
+        replay = ingestor.ingest_raw(RAW_EMAIL)
+        assert first is not None and replay is not None
+        assert replay.memory_id == first.memory_id
+
+        imap = _FakeImap(
+            {
+                b"7": RAW_EMAIL.replace(b"renewal-1@", b"renewal-2@").replace(
+                    b"50 seats", b"a security review first"
+                )
+            },
+        )
+        poller = ImapPoller(
+            ingestor,
+            host="imap.example.com",
+            username="crm",
+            password="secret",
+            connect=lambda host, port: imap,
+        )
+        assert poller.poll_once() == 1
+        assert imap.seen == [b"7"]
+
+        memories = service.list_memories(limit=10, cursor=None, account_key="acct-crm")
+        assert len(memories.data) == 2
+        assert all("alice@example.com" in memory.metadata["entities"] for memory in memories.data)
+    finally:
+        service.close()
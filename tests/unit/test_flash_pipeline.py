@@ -57,3 +57,29 @@ def test_async_flash_pipeline_enqueues_and_runs(tmp_path) -> None:
         assert engine._metrics.get("flash_maintenance_runs", 0.0) >= 1.0
     finally:
         engine.close()
+
+
+def test_wait_for_flash_pipeline_makes_inferred_memories_visible(tmp_path) -> None:
+    config = EngineConfig(
+        sqlite_path=str(tmp_path / "flash.db"),
+        metrics_path=str(tmp_path / "metrics.json"),
+        embedding_dim=32,
+        persistent_confidence_prior=0.0,
+        ephemeral_confidence_prior=0.0,
+        flash_pipeline_mode="async",
+        flash_pipeline_workers=2,
+        flash_pipeline_queue_size=32,
+        personalization_repeat_threshold=2,
+    )
+    engine = DecisionEngine(config=config)
+    try:
+        _ingest(engine, content="hello loop help", entity_id="alice")
+        _ingest(engine, content="hello loop help", entity_id="alice")
+        assert engine.wait_for_flash_pipeline(5.0) is True
+        assert any(
+            item.intent == "inferred_learning_pattern"
+            for item in engine.get_memory(entity_id="alice")
+        )
+        assert engine._flash_pending == set()
+    finally:
+        engine.close()
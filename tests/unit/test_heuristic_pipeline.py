@@ -0,0 +1,58 @@
+from __future__ import annotations
+
+from pathlib import Path
+
+import pytest
+
+from memory_engine.config import EngineConfig
+from memory_engine.engine import DecisionEngine
+from memory_engine.models.event import Event
+from memory_engine.pipeline_mode import parse_namespace_pipeline_modes
+
+
+def test_heuristic_pipeline_mode_is_selected_per_namespace(tmp_path: Path) -> None:
+    engine = DecisionEngine(
+        config=EngineConfig(
+            sqlite_path=str(tmp_path / "memory.db"),
+            metrics_path=str(tmp_path / "metrics.json"),
+            embedding_dim=64,
+            pipeline_mode_namespaces=parse_namespace_pipeline_modes("acme=heuristic"),
+        )
+    )
+    try:
+        assert engine.pipeline_mode_for("acme") == "heuristic"
+        assert engine.pipeline_mode_for("bigco") == "standard"
+        event = Event(
+            entity_id="user_1",
+            event_type="user_fact",
+            description="Dana moved the Orbit API deploy to Berlin on 2026-03-01, ping @ops.",
+        )
+        processed = engine.process_input(event, account_key="acme")
+        assert {"Orbit", "API", "Berlin", "2026-03-01", "@ops"} <= set(
+            processed.entity_references
+        )
+        again = engine.process_input(event, account_key="acme")
+        assert again.semantic_embedding == processed.semantic_embedding
+
+        first = engine.make_storage_decision(processed, account_key="acme")
+        assert first.trace["documents_seen"] == 0.0
+        for _ in range(5):
+            repeated = engine.make_storage_decision(processed, account_key="acme")
+        assert repeated.trace["distinctiveness"] < first.trace["distinctiveness"]
+        assert repeated.confidence < first.confidence
+        standard = engine.make_storage_decision(
+            engine.process_input(event, account_key="bigco"), account_key="bigco"
+        )
+        assert "model_confidence" in standard.trace
+
+        engine.store_memory(processed, first, account_key="acme")
+        results = engine.retrieve("when did the deploy move to Berlin", account_key="acme")
+        assert results and results[0].memory.content == event.description
+    finally:
+        engine.close()
+
+
+def test_parse_namespace_pipeline_modes_rejects_unknown_modes() -> None:
+    assert parse_namespace_pipeline_modes(" acme = Heuristic ,") == {"acme": "heuristic"}
+    with pytest.raises(ValueError, match="pipeline mode"):
+        parse_namespace_pipeline_modes("acme=llm")
@@ -0,0 +1,255 @@
+from __future__ import annotations
+
+import base64
+import json
+from datetime import UTC, datetime, timedelta
+from typing import Any
+
+import httpx
+
+from orbit_api.kube_operator import (
+    API_VERSION,
+    FINALIZER,
+    ROTATE_ANNOTATION,
+    OrbitAdminClient,
+    OrbitOperator,
+    migration_job_name,
+)
+
+
+class _FakeKube:
+    def __init__(self) -> None:
+        self.objects: dict[tuple[str, str, str, str], dict[str, Any]] = {}
+        self.deleted: list[str] = []
+
+    def add(self, kind: str, name: str, spec: dict[str, Any], **metadata: Any) -> dict[str, Any]:
+        resource = {
+            "apiVersion": API_VERSION,
+            "kind": kind,
+            "metadata": {"name": name, "namespace": "orbit", "uid": f"uid-{name}", **metadata},
+            "spec": spec,
+        }
+        self.apply(resource)
+        return resource
+
+    def find(self, api_version: str, kind: str, name: str) -> dict[str, Any]:
+        return self.objects[(api_version, kind, "orbit", name)]
+
+    def list_resources(self, api_version: str, kind: str) -> list[dict[str, Any]]:
+        return [
+            json.loads(json.dumps(item))
+            for (version, item_kind, _, _), item in self.objects.items()
+            if (version, item_kind) == (api_version, kind)
+        ]
+
+    def get(
+        self, api_version: str, kind: str, *, name: str, namespace: str
+    ) -> dict[str, Any] | None:
+        return self.objects.get((api_version, kind, namespace, name))
+
+    def apply(self, manifest: dict[str, Any]) -> None:
+        metadata = manifest["metadata"]
+        key = (manifest["apiVersion"], manifest["kind"], metadata["namespace"], metadata["name"])
+        current = self.objects.get(key, {})
+        self.objects[key] = {**manifest, "status": current.get("status", manifest.get("status"))}
+
+    def delete(self, api_version: str, kind: str, *, name: str, namespace: str) -> None:
+        if self.objects.pop((api_version, kind, namespace, name), None) is not None:
+            self.deleted.append(f"{kind}/{name}")
+
+    def patch_status(self, resource: dict[str, Any], status: dict[str, Any]) -> None:
+        stored = self.find(resource["apiVersion"], resource["kind"], resource["metadata"]["name"])
+        merged = {**(stored.get("status") or {}), **status}
+        stored["status"] = {key: value for key, value in merged.items() if value is not None}
+
+    def set_finalizers(self, resource: dict[str, Any], finalizers: list[str]) -> None:
+        stored = self.find(resource["apiVersion"], resource["kind"], resource["metadata"]["name"])
+        stored["metadata"]["finalizers"] = finalizers
+
+
+class _FakeOrbit:
+    def __init__(self) -> None:
+        self.calls: list[str] = []
+        self.keys: dict[str, str] = {}
+
+    def handle(self, request: httpx.Request) -> httpx.Response:
+        assert request.headers["Authorization"] == "Bearer admin-token"
+        path = request.url.raw_path.decode("ascii")
+        self.calls.append(f"{request.method} {path}")
+        if request.method == "POST" and path.endswith("/keys"):
+            key_id = f"key_{len(self.keys) + 1}"
+            self.keys[key_id] = "active"
+            return httpx.Response(
+                201,
+                json={"key_id": key_id, "key_prefix": "orbit_pk_", "key": f"secret-{key_id}"},
+            )
+        if request.method == "POST" and path.endswith("/revoke"):
+            self.keys[path.split("/")[-2]] = "revoked"
+            return httpx.Response(200, json={})
+        if request.method == "GET" and "/keys/" in path:
+            key_id = path.rsplit("/", 1)[-1]
+            if key_id not in self.keys:
+                return httpx.Response(404, json={"detail": "not found"})
+            return httpx.Response(200, json={"key_id": key_id, "status": self.keys[key_id]})
+        return httpx.Response(200, json={})
+
+
+def _operator(kube: _FakeKube, orbit: _FakeOrbit) -> OrbitOperator:
+    transport = httpx.MockTransport(orbit.handle)
+    return OrbitOperator(
+        kube,
+        admin_factory=lambda url, token: OrbitAdminClient(url, token, transport=transport),
+    )
+
+
+def _ready_cluster(kube: _FakeKube) -> None:
+    kube.add("OrbitCluster", "orbit", {"image": "orbit:1"})
+    kube.find(API_VERSION, "OrbitCluster", "orbit")["status"] = {
+        "phase": "Ready",
+        "migratedImage": "orbit:1",
+    }
+    kube.apply(
+        {
+            "apiVersion": "apps/v1",
+            "kind": "Deployment",
+            "metadata": {"name": "orbit-api", "namespace": "orbit"},
+            "status": {"readyReplicas": 1},
+        }
+    )
+    kube.apply(
+        {
+            "apiVersion": "v1",
+            "kind": "Secret",
+            "metadata": {"name": "orbit-admin", "namespace": "orbit"},
+            "data": {"token": base64.b64encode(b"admin-token").decode("ascii")},
+        }
+    )
+
+
+def test_operator_migrates_before_rolling_out_and_scales_worker_pools() -> None:
+    kube = _FakeKube()
+    cluster = kube.add(
+        "OrbitCluster",
+        "orbit",
+        {
+            "image": "orbit:1",
+            "api": {"replicas": 2},
+            "workers": [
+                {"name": "export", "args": ["export"], "replicas": 1},
+                {
+                    "name": "retention",
+                    "args": ["retention"],
+                    "autoscaling": {"minReplicas": 1, "maxReplicas": 4},
+                },
+            ],
+        },
+        generation=1,
+    )
+    operator = _operator(kube, _FakeOrbit())
+
+    assert operator.reconcile_once() == {"ready": 0, "pending": 1, "failed": 0}
+    job = kube.find("batch/v1", "Job", migration_job_name(cluster))
+    assert job["spec"]["template"]["spec"]["containers"][0]["command"] == ["orbit", "migrate"]
+    assert kube.find(API_VERSION, "OrbitCluster", "orbit")["status"]["phase"] == "Migrating"
+    assert not any(kind == "Deployment" for _, kind, _, _ in kube.objects)
+
+    job["status"] = {"succeeded": 1}
+    operator.reconcile_once()
+    api = kube.find("apps/v1", "Deployment", "orbit-api")
+    container = api["spec"]["template"]["spec"]["containers"][0]
+    assert (api["spec"]["replicas"], container["image"]) == (2, "orbit:1")
+    assert {"name": "ORBIT_AUTO_MIGRATE", "value": "false"} in container["env"]
+    export = kube.find("apps/v1", "Deployment", "orbit-export")["spec"]["template"]["spec"]
+    assert export["containers"][0]["command"] == ["orbit", "export"]
+    assert "replicas" not in kube.find("apps/v1", "Deployment", "orbit-retention")["spec"]
+    hpa = kube.find("autoscaling/v2", "HorizontalPodAutoscaler", "orbit-retention")
+    assert hpa["spec"]["maxReplicas"] == 4
+
+    api["status"] = {"readyReplicas": 2}
+    assert operator.reconcile_once()["ready"] == 1
+    status = kube.find(API_VERSION, "OrbitCluster", "orbit")["status"]
+    assert status["endpoint"] == "http://orbit.orbit.svc:8000" and status["observedGeneration"] == 1
+
+    # A new image keeps the old one running until its migration has succeeded.
+    stored = kube.find(API_VERSION, "OrbitCluster", "orbit")
+    stored["spec"] = {
+        "image": "orbit:2",
+        "api": {"replicas": 3},
+        "workers": [{"name": "export", "args": ["export"], "replicas": 2}],
+    }
+    operator.reconcile_once()
+    container = kube.find("apps/v1", "Deployment", "orbit-api")["spec"]["template"]["spec"]
+    assert container["containers"][0]["image"] == "orbit:1"
+    assert kube.find("apps/v1", "Deployment", "orbit-api")["spec"]["replicas"] == 3
+    assert kube.find(API_VERSION, "OrbitCluster", "orbit")["status"]["phase"] == "Migrating"
+    assert {"Deployment/orbit-retention", "HorizontalPodAutoscaler/orbit-retention"} <= set(
+        kube.deleted
+    )
+
+    kube.find("batch/v1", "Job", migration_job_name(stored))["status"] = {
+        "conditions": [{"type": "Failed", "status": "True"}]
+    }
+    operator.reconcile_once()
+    assert kube.find(API_VERSION, "OrbitCluster", "orbit")["status"]["phase"] == "MigrationFailed"
+
+
+def test_operator_applies_namespaces_and_rotates_api_keys() -> None:
+    kube = _FakeKube()
+    orbit = _FakeOrbit()
+    _ready_cluster(kube)
+    kube.add(
+        "OrbitNamespace",
+        "acme",
+        {
+            "clusterRef": "orbit",
+            "accountKey": "acme/eu",
+            "retention": {"days": 365, "eventTypes": {"user_question": 30}},
+        },
+    )
+    kube.add(
+        "OrbitAPIKey",
+        "backend",
+        {"clusterRef": "orbit", "accountKey": "acme/eu", "scopes": ["read"]},
+    )
+    operator = _operator(kube, orbit)
+    now = datetime(2026, 10, 15, tzinfo=UTC)
+
+    operator.reconcile_once(now=now)
+    assert "PUT /v1/admin/namespaces/acme%2Feu" in orbit.calls
+    assert "PUT /v1/admin/tenants/acme%2Feu/retention" in orbit.calls
+    assert "DELETE /v1/admin/tenants/acme%2Feu/event-types" in orbit.calls
+    namespace = kube.find(API_VERSION, "OrbitNamespace", "acme")
+    assert namespace["metadata"]["finalizers"] == [FINALIZER]
+    assert namespace["status"]["phase"] == "Ready"
+    secret = kube.find("v1", "Secret", "backend-orbit-api-key")
+    assert base64.b64decode(secret["data"]["ORBIT_API_KEY"]) == b"secret-key_1"
+    assert kube.find(API_VERSION, "OrbitAPIKey", "backend")["status"]["keyId"] == "key_1"
+
+    # Unchanged specs inside the resync window make no writes.
+    orbit.calls.clear()
+    operator.reconcile_once(now=now + timedelta(minutes=1))
+    assert orbit.calls == ["GET /v1/admin/tenants/acme%2Feu/keys/key_1"]
+
+    api_key = kube.find(API_VERSION, "OrbitAPIKey", "backend")
+    api_key["metadata"]["annotations"] = {ROTATE_ANNOTATION: "2026-10-15"}
+    operator.reconcile_once(now=now + timedelta(minutes=2))
+    status = kube.find(API_VERSION, "OrbitAPIKey", "backend")["status"]
+    assert (status["keyId"], status["previousKeyId"]) == ("key_2", "key_1")
+    assert status["lastRotationReason"] == "requested"
+    assert orbit.keys == {"key_1": "active", "key_2": "active"}
+
+    operator.reconcile_once(now=now + timedelta(hours=2))
+    status = kube.find(API_VERSION, "OrbitAPIKey", "backend")["status"]
+    assert orbit.keys["key_1"] == "revoked" and "previousKeyId" not in status
+
+    # A key revoked outside the operator is replaced.
+    orbit.keys["key_2"] = "revoked"
+    operator.reconcile_once(now=now + timedelta(hours=3))
+    status = kube.find(API_VERSION, "OrbitAPIKey", "backend")["status"]
+    assert (status["keyId"], status["lastRotationReason"]) == ("key_3", "revoked")
+
+    api_key = kube.find(API_VERSION, "OrbitAPIKey", "backend")
+    api_key["metadata"]["deletionTimestamp"] = (now + timedelta(hours=4)).isoformat()
+    operator.reconcile_once(now=now + timedelta(hours=4))
+    assert orbit.keys["key_3"] == "revoked"
+    assert api_key["metadata"]["finalizers"] == []
@@ -1,37 +1,134 @@
 from __future__ import annotations
 
-from datetime import UTC, datetime, timedelta
+import base64
+import hashlib
+import json
+import socket
+import time
+from collections.abc import Callable
+from datetime import UTC, date, datetime, timedelta
 from pathlib import Path
+from typing import Any
 
+import httpx
 import pytest
 from sqlalchemy import create_engine, select
 from sqlalchemy.orm import Session
 
+from decision_engine import clock
+from decision_engine.clock import Clock, FixedClock
 from decision_engine.models import MemoryRecord, RetrievedMemory, StorageTier
 from memory_engine.config import EngineConfig
-from memory_engine.storage.db import ApiDashboardUserRow, ApiPilotProRequestRow
-from orbit.models import FeedbackRequest, IngestRequest, RetrieveRequest
+from memory_engine.storage.db import (
+    ApiDashboardUserRow,
+    ApiIngestAggregateRow,
+    ApiMemoryChangeRow,
+    ApiMemoryReviewRow,
+    ApiPilotProRequestRow,
+)
+from orbit.models import (
+    AskRequest,
+    BatchRetrieveRequest,
+    CaptureRequest,
+    DigestRequest,
+    EntityAttributesPatchRequest,
+    EntityGroupRequest,
+    EventTypeDefinition,
+    EventTypeRegistryRequest,
+    EventTypeSampling,
+    ExportJobRequest,
+    FanoutRetrieveRequest,
+    FeedbackRequest,
+    IndexDeploymentRequest,
+    IngestAttachment,
+    IngestRequest,
+    IngestResponse,
+    MemoryLinkRequest,
+    MemoryReviewDecision,
+    MemoryShareRequest,
+    MemoryUpdateRequest,
+    ModerationAppealRequest,
+    ModerationResolveRequest,
+    NamespaceRequest,
+    PinnedClock,
+    PipelineWebhookRequest,
+    ProcedureRequest,
+    ProcedureStep,
+    RecallRequest,
+    ReflectRequest,
+    RetentionPolicyRequest,
+    RetrieveBoost,
+    RetrieveDefaults,
+    RetrieveNamespace,
+    RetrieveRequest,
+    RetrieveResponse,
+    SavedQueryRequest,
+    SavedQueryRunRequest,
+    SessionMemoryRequest,
+    TenantResidencyRequest,
+    TimeRange,
+    TrajectoryStep,
+    UrlSourceRequest,
+    VectorSearchRequest,
+    WritePolicyRequest,
+)
+from orbit.signing import canonical_request, compute_signature
+from orbit_api.anomaly import AnomalyThresholds, IngestionAnomalyDetector
 from orbit_api.auth import AuthContext
 from orbit_api.config import ApiConfig
+from orbit_api.exports import CronSchedule, build_destination_store, validate_destination
+from orbit_api.moderation import KeywordModerationProvider, moderate
+from orbit_api.pipeline import (
+    PIPELINE_STAGES,
+    IngestContext,
+    IngestPipeline,
+    parse_namespace_pipelines,
+    parse_pipeline,
+)
+from orbit_api.pipeline_webhook import (
+    PipelineWebhookError,
+    WebhookTarget,
+    call_transform_webhook,
+)
+from orbit_api.url_sources import (
+    FetchedPage,
+    OutboundPolicy,
+    UrlFetchError,
+    extract_text,
+    fetch_url,
+)
 from orbit_api.service import (
     AccountMappingError,
     ApiKeyAuthenticationError,
+    ContentBlockedError,
     IdempotencyConflictError,
+    IndexDeploymentConflictError,
     OrbitApiService,
     PlanQuotaExceededError,
+    PreconditionFailedError,
     RateLimitExceededError,
+    WritePolicyViolationError,
 )
+from orbit_api.query_analytics import anonymize_query, percentile
+from orbit_api.synthesis import SummarizationError
+from orbit_api.topics import TopicCluster, cluster_memories
+from orbit_api.wasm_stage import WasmHostApi, WasmStage, WasmStageError
 
 
-def _service(tmp_path: Path) -> OrbitApiService:
+def _service(
+    tmp_path: Path, clock_source: Clock | None = None, **api_overrides: Any
+) -> OrbitApiService:
     db_path = tmp_path / "service.db"
     api_config = ApiConfig(
-        database_url=f"sqlite:///{db_path}",
-        sqlite_fallback_path=str(db_path),
-        free_events_per_day=2,
-        free_queries_per_day=2,
-        free_events_per_month=2,
-        free_queries_per_month=2,
+        **{
+            "database_url": f"sqlite:///{db_path}",
+            "sqlite_fallback_path": str(db_path),
+            "free_events_per_day": 2,
+            "free_queries_per_day": 2,
+            "free_events_per_month": 2,
+            "free_queries_per_month": 2,
+            **api_overrides,
+        }
     )
     engine_config = EngineConfig(
         sqlite_path=str(db_path),
@@ -42,7 +139,9 @@ def _service(tmp_path: Path) -> OrbitApiService:
         ranker_min_training_samples=2,
         ranker_training_batch_size=2,
     )
-    return OrbitApiService(api_config=api_config, engine_config=engine_config)
+    return OrbitApiService(
+        api_config=api_config, engine_config=engine_config, clock_source=clock_source
+    )
 
 
 def _retrieved(
@@ -245,6 +344,32 @@ def test_diversity_rerank_prefers_short_non_assistant(tmp_path: Path) -> None:
         service.close()
 
 
+def test_mmr_selection_skips_paraphrases_of_earlier_picks(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        ranked = []
+        for memory_id, score, vector in (
+            ("vegan", 0.95, [1.0, 0.0, 0.0]),
+            ("vegan_again", 0.94, [0.99, 0.1, 0.0]),
+            ("plant_based", 0.93, [0.98, 0.15, 0.0]),
+            ("travel", 0.6, [0.0, 1.0, 0.0]),
+        ):
+            item = _retrieved(memory_id, intent="user_fact", content=memory_id, score=score)
+            memory = item.memory.model_copy(update={"semantic_embedding": vector})
+            ranked.append(item.model_copy(update={"memory": memory}))
+
+        diverse = service._select_with_mmr(ranked, top_k=2, mmr_lambda=0.5)
+        assert [item.memory.memory_id for item in diverse] == ["vegan", "travel"]
+        assert diverse[1].rank_score == 0.6
+        relevant = service._select_with_mmr(ranked, top_k=2, mmr_lambda=1.0)
+        assert [item.memory.memory_id for item in relevant] == ["vegan", "vegan_again"]
+
+        with pytest.raises(ValueError, match="mmr_lambda must be between 0 and 1"):
+            RetrieveRequest(query="diet", mmr=True, mmr_lambda=1.5)
+    finally:
+        service.close()
+
+
 def test_service_request_pilot_pro_persists_and_is_idempotent(tmp_path: Path) -> None:
     service = _service(tmp_path)
     try:
@@ -529,6 +654,92 @@ def test_service_issue_list_and_authenticate_api_key(tmp_path: Path) -> None:
         service.close()
 
 
+def test_service_api_key_allowed_origins_restrict_authentication(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        issued = service.issue_api_key(
+            account_key="acct_browser",
+            name="extension",
+            scopes=["write"],
+            allowed_origins=["chrome-extension://abcdefgh/", "https://Notes.example.com"],
+        )
+        assert issued.allowed_origins == [
+            "chrome-extension://abcdefgh",
+            "https://notes.example.com",
+        ]
+
+        context = service.authenticate_api_key(
+            issued.key,
+            origin="chrome-extension://abcdefgh",
+        )
+        assert context.subject == "acct_browser"
+        with pytest.raises(ApiKeyAuthenticationError):
+            service.authenticate_api_key(issued.key, origin="https://evil.example.com")
+        with pytest.raises(ApiKeyAuthenticationError):
+            service.authenticate_api_key(issued.key)
+        with pytest.raises(ValueError):
+            service.issue_api_key(
+                account_key="acct_browser",
+                name="bad-origin",
+                allowed_origins=["https://notes.example.com/path"],
+            )
+
+        rotated = service.rotate_api_key(account_key="acct_browser", key_id=issued.key_id)
+        assert rotated.new_key.allowed_origins == issued.allowed_origins
+    finally:
+        service.close()
+
+
+def test_service_refuses_superscopes_on_api_keys(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        for scope in ("admin", "*"):
+            with pytest.raises(ValueError, match="cannot be granted"):
+                service.issue_api_key(account_key="acct_keys", name="escalate", scopes=[scope])
+        issued = service.issue_api_key(account_key="acct_keys", name="reader", scopes=["read"])
+        with pytest.raises(ValueError, match="cannot be granted"):
+            service.rotate_api_key(
+                account_key="acct_keys",
+                key_id=issued.key_id,
+                scopes=["read", "admin"],
+            )
+    finally:
+        service.close()
+
+
+def test_service_capture_flattens_page_into_ingest(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        request = service.capture_to_ingest(
+            CaptureRequest(
+                url="https://docs.example.com/pgvector",
+                title="pgvector HNSW tuning",
+                selection="Raise ef_search for better recall.",
+                favicon_url="https://docs.example.com/favicon.ico",
+                tags=["postgres", "postgres", "search"],
+            )
+        )
+        assert request.event_type == "page_capture"
+        assert request.content.splitlines()[0] == "pgvector HNSW tuning"
+        assert "Raise ef_search" in request.content
+        assert request.metadata == {
+            "source": "browser_capture",
+            "url": "https://docs.example.com/pgvector",
+            "title": "pgvector HNSW tuning",
+            "favicon_url": "https://docs.example.com/favicon.ico",
+            "tags": ["postgres", "search"],
+        }
+
+        result, _snapshot, _replayed = service.ingest_with_quota(
+            account_key="acct_browser",
+            request=request,
+            idempotency_key=None,
+        )
+        assert result.memory_id
+    finally:
+        service.close()
+
+
 def test_service_api_key_limit_enforced_by_plan(tmp_path: Path) -> None:
     db_path = tmp_path / "key_limit.db"
     api_config = ApiConfig(
@@ -625,6 +836,37 @@ def test_service_rotate_api_key_replaces_old_key(tmp_path: Path) -> None:
         service.close()
 
 
+def test_service_signed_request_nonces_are_single_use_across_instances(
+    tmp_path: Path,
+) -> None:
+    secret = "signing-secret-0123456789"
+    overrides = {"request_signing_keys": {"backend-1": f"acct_signed:{secret}"}}
+    first = _service(tmp_path, **overrides)
+    second = _service(tmp_path, **overrides)
+    timestamp = str(int(time.time()))
+    signed = {
+        "key_id": "backend-1",
+        "method": "GET",
+        "path": "/v1/retrieve",
+        "query": "query=bearer",
+        "timestamp": timestamp,
+        "nonce": "nonce-1",
+        "body": b"",
+    }
+    signature = compute_signature(
+        secret,
+        canonical_request(**{key: value for key, value in signed.items() if key != "key_id"}),
+    )
+    try:
+        auth = first.authenticate_signed_request(signature=signature, **signed)
+        assert auth.subject == "acct_signed"
+        with pytest.raises(ApiKeyAuthenticationError, match="already used"):
+            second.authenticate_signed_request(signature=signature, **signed)
+    finally:
+        first.close()
+        second.close()
+
+
 def test_service_list_api_keys_paginates(tmp_path: Path) -> None:
     service = _service(tmp_path)
     try:
@@ -1380,3 +1622,3557 @@ def test_service_health_degraded_on_storage_failure(tmp_path: Path) -> None:
         assert health["storage"] == "error"
     finally:
         service.close()
+
+
+def test_change_feed_is_ordered_resumable_and_tenant_scoped(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        first = service.ingest(
+            IngestRequest(content="Alice prefers dark mode", entity_id="alice"),
+            account_key="acct_a",
+        )
+        service.ingest(
+            IngestRequest(content="Bob prefers light mode", entity_id="bob"),
+            account_key="acct_b",
+        )
+        service.feedback(
+            FeedbackRequest(memory_id=first.memory_id, helpful=True),
+            account_key="acct_a",
+        )
+        service._engine._delete_memories([first.memory_id], account_key="acct_a")
+
+        page = service.list_changes(account_key="acct_a", limit=2)
+        assert [item.operation for item in page.data] == ["created", "updated"]
+        assert page.has_more is True
+        assert page.data[0].memory_id == first.memory_id
+        assert page.data[0].memory is not None
+        assert page.data[0].memory["content"] == "Alice prefers dark mode"
+        assert page.data[0].sequence < page.data[1].sequence
+
+        resumed = service.list_changes(account_key="acct_a", cursor=page.cursor)
+        assert [item.operation for item in resumed.data] == ["deleted"]
+        assert resumed.has_more is False
+
+        idle = service.list_changes(account_key="acct_a", cursor=resumed.cursor)
+        assert idle.data == []
+        assert idle.cursor == resumed.cursor
+
+        other = service.list_changes(account_key="acct_b")
+        assert all(item.memory_id != first.memory_id for item in other.data)
+    finally:
+        service.close()
+
+
+def test_change_feed_rows_commit_with_the_memory_write(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        stored = service.ingest(
+            IngestRequest(content="Alice prefers dark mode", entity_id="alice"),
+            account_key="acct",
+        )
+
+        def _fail_after_journal(*_: Any) -> None:
+            raise RuntimeError("disk full")
+
+        service._engine.storage.add_write_journal(_fail_after_journal)
+        with pytest.raises(RuntimeError, match="disk full"):
+            service._engine.update_relationships(
+                stored.memory_id,
+                ["category:display"],
+                account_key="acct",
+            )
+        # The update and its change row roll back together.
+        (record,) = service._engine.storage.fetch_by_ids([stored.memory_id], account_key="acct")
+        assert "category:display" not in record.relationships
+        assert [item.operation for item in service.list_changes(account_key="acct").data] == [
+            "created"
+        ]
+    finally:
+        service.close()
+
+
+def test_change_feed_holds_back_changes_younger_than_the_settle_window(
+    tmp_path: Path,
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    service = _service(tmp_path, change_feed_settle_seconds=60.0)
+    try:
+        service.ingest(
+            IngestRequest(content="Alice prefers dark mode", entity_id="alice"),
+            account_key="acct",
+        )
+        # The window only applies where ids can commit out of order.
+        assert [item.operation for item in service.list_changes(account_key="acct").data] == [
+            "created"
+        ]
+
+        with monkeypatch.context() as patch:
+            patch.setattr(service._state_engine.dialect, "name", "postgresql")
+            held = service.list_changes(account_key="acct")
+            assert held.data == []
+            assert held.cursor is None
+            assert held.has_more is False
+
+            engine = create_engine(service.config.database_url, future=True)
+            try:
+                with Session(engine) as session:
+                    for row in session.scalars(select(ApiMemoryChangeRow)):
+                        row.created_at = datetime.now(UTC) - timedelta(minutes=5)
+                    session.commit()
+            finally:
+                engine.dispose()
+
+            settled = service.list_changes(account_key="acct")
+            assert [item.operation for item in settled.data] == ["created"]
+            assert settled.cursor == str(settled.data[0].sequence)
+    finally:
+        service.close()
+
+
+def test_service_retrieve_returns_partial_results_when_latency_budget_is_spent(
+    tmp_path: Path,
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    service = _service(tmp_path)
+    try:
+        service.ingest(IngestRequest(content="Alice prefers dark mode", entity_id="alice"))
+        unbounded = service.retrieve(RetrieveRequest(query="display preference", limit=5))
+        assert unbounded.degraded is False
+        assert unbounded.skipped_stages == []
+
+        encoder = service._engine.input_processor.encoder
+        encode_query = encoder.encode_query
+
+        def _slow_encode(query: str):  # type: ignore[no-untyped-def]
+            time.sleep(0.01)
+            return encode_query(query)
+
+        monkeypatch.setattr(encoder, "encode_query", _slow_encode)
+        budgeted = service.retrieve(
+            RetrieveRequest(query="display preference", limit=5, max_latency_ms=1)
+        )
+        assert budgeted.degraded is True
+        assert "rerank" in budgeted.skipped_stages
+        assert [item.content for item in budgeted.memories] == ["Alice prefers dark mode"]
+        assert "orbit_retrieve_degraded_total 1" in service.metrics_text()
+    finally:
+        service.close()
+
+
+def test_service_fast_retrieve_skips_optional_stages_and_caches_results(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        service.ingest(
+            IngestRequest(content="Alice prefers dark mode", entity_id="alice"),
+            account_key="acct",
+        )
+        request = RetrieveRequest(query="display preference", limit=5, fast=True)
+
+        first = service.retrieve(request, account_key="acct")
+        assert [item.content for item in first.memories] == ["Alice prefers dark mode"]
+        assert first.degraded is False
+        assert first.skipped_stages == []
+        assert first.cached is False
+
+        second = service.retrieve(request, account_key="acct")
+        assert second.cached is True
+        assert [item.memory_id for item in second.memories] == [
+            item.memory_id for item in first.memories
+        ]
+        assert service.retrieve(request, account_key="other").cached is False
+
+        service.ingest(
+            IngestRequest(content="Alice switched to light mode", entity_id="alice"),
+            account_key="acct",
+        )
+        assert service.retrieve(request, account_key="acct").cached is False
+
+        metrics = service.metrics_text()
+        assert "orbit_retrieve_fast_requests_total 4" in metrics
+        assert "orbit_retrieve_fast_cache_hits_total 1" in metrics
+        assert service.admin_metrics().requests["retrieve_fast_latency_p99_ms"] > 0
+
+        with pytest.raises(ValueError, match="fast cannot be combined"):
+            RetrieveRequest(query="display preference", fast=True, mode="graph")
+    finally:
+        service.close()
+
+
+def test_service_retrieve_fanout_merges_namespaces_with_weights(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        service.ingest(IngestRequest(content="Alice prefers dark mode", entity_id="alice"))
+        service.ingest(
+            IngestRequest(content="Company style guide mandates dark mode", entity_id="org-kb")
+        )
+
+        result = service.retrieve_fanout(
+            FanoutRetrieveRequest(
+                query="dark mode preference",
+                limit=5,
+                namespaces=[
+                    RetrieveNamespace(name="personal", entity_id="alice", weight=1.0),
+                    RetrieveNamespace(name="org", entity_id="org-kb", weight=0.01),
+                ],
+            )
+        )
+
+        assert [item.name for item in result.namespaces] == ["personal", "org"]
+        assert all(item.returned >= 1 for item in result.namespaces)
+        assert [item.metadata["namespace"] for item in result.memories][:2] == [
+            "personal",
+            "org",
+        ]
+        assert [item.rank_position for item in result.memories] == list(
+            range(1, len(result.memories) + 1)
+        )
+        org_memory = next(item for item in result.memories if item.metadata["namespace"] == "org")
+        assert org_memory.rank_score == pytest.approx(
+            org_memory.metadata["namespace_rank_score"] * 0.01
+        )
+    finally:
+        service.close()
+
+
+def test_fanout_request_rejects_duplicate_namespace_names() -> None:
+    with pytest.raises(ValueError, match="unique"):
+        FanoutRetrieveRequest(
+            query="anything",
+            namespaces=[RetrieveNamespace(name="a"), RetrieveNamespace(name="a")],
+        )
+
+
+def test_service_stores_procedures_and_finds_them_by_task(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        service.ingest(IngestRequest(content="Alice restarted the staging database yesterday"))
+        stored = service.ingest(
+            service.procedure_to_ingest(
+                ProcedureRequest(
+                    task="Restart the staging database",
+                    error_pattern="connection pool exhausted",
+                    steps=[
+                        ProcedureStep(action="Drain traffic", tool="kubectl", arguments={"replicas": 0}),
+                        ProcedureStep(action="Restart the primary", tool="pg_ctl", result="ok"),
+                    ],
+                    outcome="Connections recovered",
+                    entity_id="ops-agent",
+                )
+            )
+        )
+
+        result = service.search_procedures("how do I restart the staging database?")
+
+        assert [item.memory_id for item in result.data] == [stored.memory_id]
+        procedure = result.data[0]
+        assert procedure.task == "Restart the staging database"
+        assert procedure.kind == "error_resolution"
+        assert procedure.error_pattern == "connection pool exhausted"
+        assert procedure.outcome == "Connections recovered"
+        assert procedure.entity_id == "ops-agent"
+        assert [step.tool for step in procedure.steps] == ["kubectl", "pg_ctl"]
+        assert procedure.steps[0].arguments == {"replicas": 0}
+    finally:
+        service.close()
+
+
+def test_service_reflect_distills_lessons_from_trajectory(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    request = ReflectRequest(
+        goal="publish the release notes",
+        outcome="success",
+        entity_id="release-agent",
+        steps=[
+            TrajectoryStep(action="Push the tag", tool="git", error="permission denied"),
+            TrajectoryStep(action="Push the tag with the deploy key", tool="git"),
+            TrajectoryStep(action="Upload notes", tool="s3", error="bucket not found"),
+            TrajectoryStep(action="Post notes to the wiki", tool="wiki"),
+        ],
+    )
+    service.config.free_events_per_day = 10
+    service.config.free_events_per_month = 10
+    try:
+        result, _, replayed = service.reflect_with_quota(
+            account_key="acct",
+            request=request,
+            idempotency_key=None,
+        )
+
+        assert replayed is False
+        assert [lesson.kind for lesson in result.lessons] == [
+            "procedure",
+            "error_recovery",
+            "pitfall",
+        ]
+        assert "Push the tag with the deploy key (git) resolved it" in result.lessons[1].content
+        assert result.trajectory_id == service._trajectory_id(request)  # type: ignore[attr-defined]
+
+        procedures = service.search_procedures("publish release notes", account_key="acct")
+        assert [step.tool for step in procedures.data[0].steps] == ["git", "wiki"]
+
+        memories = service.list_memories(limit=10, cursor=None, account_key="acct").data
+        lesson = next(item for item in memories if item.metadata["intent"] == "lesson_learned")
+        provenance = lesson.metadata["inference_provenance"]
+        assert provenance["is_inferred"] is True
+        assert provenance["inference_type"] == "trajectory_reflection"
+    finally:
+        service.close()
+
+
+def test_reflect_request_rejects_unknown_outcome() -> None:
+    with pytest.raises(ValueError, match="outcome must be one of"):
+        ReflectRequest(goal="g", outcome="done", steps=[TrajectoryStep(action="a")])
+
+
+def test_entity_attributes_merge_patches_and_follow_extracted_facts(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+
+    def fact(memory_id: str, fact_key: str, polarity: str = "positive") -> MemoryRecord:
+        return _retrieved(
+            memory_id,
+            intent="inferred_user_fact",
+            content="fact",
+            score=1.0,
+            relationships=[
+                "fact_subject:user",
+                f"fact_key:{fact_key}",
+                f"fact_polarity:{polarity}",
+                "fact_status:active",
+            ],
+        ).memory
+
+    try:
+        assert service.entity_attributes("alice").attributes == {}
+
+        service.patch_entity_attributes(
+            "alice",
+            EntityAttributesPatchRequest(attributes={"timezone": "UTC", "plan": "free"}),
+        )
+        service._apply_fact_to_attributes("created", fact("m1", "allergy:peanuts"))  # type: ignore[attr-defined]
+        service._apply_fact_to_attributes("created", fact("m2", "allergy:shellfish"))  # type: ignore[attr-defined]
+        service._apply_fact_to_attributes("created", fact("m3", "allergy:peanuts", "negative"))  # type: ignore[attr-defined]
+        service._apply_fact_to_attributes("created", fact("m4", "weight_current:180"))  # type: ignore[attr-defined]
+
+        service.patch_entity_attributes(
+            "alice",
+            EntityAttributesPatchRequest(attributes={"plan": None, "weight": "175"}),
+        )
+        service._apply_fact_to_attributes("created", fact("m5", "weight_current:190"))  # type: ignore[attr-defined]
+
+        profile = service.entity_attributes("alice")
+        assert profile.attributes == {
+            "allergies": ["shellfish"],
+            "timezone": "UTC",
+            "weight": "175",
+        }
+        assert profile.sources == {
+            "allergies": "inferred:m2",
+            "timezone": "manual",
+            "weight": "manual",
+        }
+        assert profile.updated_at is not None
+        assert service.entity_attributes("alice", account_key="other").attributes == {}
+    finally:
+        service.close()
+
+
+def test_entity_attributes_drop_values_of_deleted_facts(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        service.ingest(
+            IngestRequest(
+                content="I am allergic to pineapple.",
+                event_type="user_question",
+                entity_id="alice",
+            ),
+            account_key="acct",
+        )
+        profile = service.entity_attributes("alice", account_key="acct")
+        assert profile.attributes == {"allergies": ["pineapple"]}
+        fact_id = profile.sources["allergies"].removeprefix("inferred:")
+
+        service._engine.delete_memories([fact_id], account_key="acct")
+        assert service.entity_attributes("alice", account_key="acct").attributes == {}
+
+        weight = _retrieved(
+            "m1",
+            intent="inferred_user_fact",
+            content="fact",
+            score=1.0,
+            relationships=["fact_subject:user", "fact_key:weight_current:180"],
+        ).memory.model_copy(update={"account_key": "acct"})
+        service._apply_fact_to_attributes("created", weight)  # type: ignore[attr-defined]
+        assert service.entity_attributes("alice", account_key="acct").attributes == {
+            "weight": "180"
+        }
+        service._apply_fact_to_attributes("deleted", weight)  # type: ignore[attr-defined]
+        assert service.entity_attributes("alice", account_key="acct").attributes == {}
+
+        service.patch_entity_attributes(
+            "alice",
+            EntityAttributesPatchRequest(attributes={"weight": "175"}),
+            account_key="acct",
+        )
+        service._apply_fact_to_attributes("deleted", weight)  # type: ignore[attr-defined]
+        assert service.entity_attributes("alice", account_key="acct").sources == {
+            "weight": "manual"
+        }
+    finally:
+        service.close()
+
+
+def test_entity_attributes_enforce_profile_size(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    service.config.max_entity_attributes = 2
+    try:
+        with pytest.raises(ValueError, match="ORBIT_MAX_ENTITY_ATTRIBUTES"):
+            service.patch_entity_attributes(
+                "alice",
+                EntityAttributesPatchRequest(attributes={"a": 1, "b": 2, "c": 3}),
+            )
+        assert service.entity_attributes("alice").attributes == {}
+    finally:
+        service.close()
+
+
+def test_service_graph_mode_follows_shared_entities(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        service.ingest(
+            IngestRequest(
+                content="Alice's manager is Bob",
+                entity_id="alice",
+                metadata={"entities": ["bob"]},
+            )
+        )
+        apollo = service.ingest(
+            IngestRequest(content="Bob leads project Apollo", entity_id="bob")
+        )
+        service.ingest(IngestRequest(content="Carol leads project Zephyr", entity_id="carol"))
+        query = "what projects is Alice's manager involved in"
+
+        vector = service.retrieve(RetrieveRequest(query=query, limit=5, entity_id="alice"))
+        assert apollo.memory_id not in [item.memory_id for item in vector.memories]
+        assert "graph" not in vector.memories[0].metadata
+
+        graph = service.retrieve(
+            RetrieveRequest(query=query, limit=5, entity_id="alice", mode="graph")
+        )
+        by_id = {item.memory_id: item for item in graph.memories}
+        assert set(by_id) == {graph.memories[0].memory_id, apollo.memory_id}
+        assert graph.memories[0].metadata["graph"] is None
+        assert by_id[apollo.memory_id].metadata["graph"] == {
+            "hops": 1,
+            "via_entity": "bob",
+            "from_memory_id": graph.memories[0].memory_id,
+        }
+        assert by_id[apollo.memory_id].rank_score < graph.memories[0].rank_score
+        assert graph.applied_filters["mode"] == "graph"
+    finally:
+        service.close()
+
+
+def test_cluster_memories_groups_similar_embeddings(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setattr("orbit_api.topics.hdbscan_module", None)
+
+    def record(memory_id: str, content: str, embedding: list[float], age: float) -> MemoryRecord:
+        memory = _retrieved(
+            memory_id,
+            intent="preference",
+            content=content,
+            score=1.0,
+            age_days=age,
+        ).memory
+        return memory.model_copy(update={"semantic_embedding": embedding})
+
+    records = [
+        record("a1", "peanut allergy confirmed", [1.0, 0.0, 0.0], 0),
+        record("a2", "peanut allergy flagged at dinner", [1.0, 0.0, 0.0], 1),
+        record("a3", "peanut allergy in medical record", [1.0, 0.0, 0.0], 2),
+        record("h1", "hiking trails near Dublin", [0.0, 1.0, 0.0], 3),
+        record("h2", "weekend hiking trails", [0.0, 1.0, 0.0], 4),
+        record("h3", "hiking trails with dog", [0.0, 1.0, 0.0], 5),
+        record("c1", "favourite colour is blue", [0.0, 0.0, 1.0], 6),
+    ]
+
+    clusters = cluster_memories(records, min_cluster_size=3)
+
+    assert [cluster.memory_ids for cluster in clusters] == [("a1", "a2", "a3"), ("h1", "h2", "h3")]
+    assert clusters[0].label == "allergy / peanut"
+    assert clusters[0].summary == "peanut allergy confirmed"
+    assert clusters[1].label == "hiking / trails"
+    assert clusters[0].topic_id != clusters[1].topic_id
+    assert cluster_memories(records, min_cluster_size=4) == []
+
+
+def test_service_topics_scope_retrieval(
+    tmp_path: Path,
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    service = _service(tmp_path)
+    try:
+        kept = service.ingest(IngestRequest(content="Alice hikes on weekends", entity_id="alice"))
+        service.ingest(IngestRequest(content="Alice hikes with her dog", entity_id="alice"))
+        calls: list[int] = []
+
+        def fake_cluster(
+            records: list[MemoryRecord],
+            *,
+            min_cluster_size: int,
+        ) -> list[TopicCluster]:
+            calls.append(len(records))
+            return [
+                TopicCluster(
+                    topic_id="topic_hiking",
+                    label="hiking",
+                    summary="Alice hikes on weekends",
+                    keywords=("hiking",),
+                    memory_ids=(kept.memory_id,),
+                )
+            ]
+
+        monkeypatch.setattr("orbit_api.service.cluster_memories", fake_cluster)
+
+        topics = service.entity_topics("alice")
+        assert [topic.topic_id for topic in topics.topics] == ["topic_hiking"]
+        assert topics.topics[0].memory_count == 1
+        service.entity_topics("alice")
+        assert calls == [2]
+        service.entity_topics("alice", refresh=True)
+        assert calls == [2, 2]
+
+        scoped = service.retrieve(
+            RetrieveRequest(query="hikes", limit=5, entity_id="alice", topic_id="topic_hiking")
+        )
+        assert [item.memory_id for item in scoped.memories] == [kept.memory_id]
+        assert scoped.applied_filters["topic_id"] == "topic_hiking"
+
+        with pytest.raises(KeyError):
+            service.retrieve(
+                RetrieveRequest(query="hikes", entity_id="alice", topic_id="topic_missing")
+            )
+        with pytest.raises(ValueError, match="requires entity_id"):
+            service.retrieve(RetrieveRequest(query="hikes", topic_id="topic_hiking"))
+    finally:
+        service.close()
+
+
+def test_service_topics_cluster_only_memories_the_caller_can_read(
+    tmp_path: Path,
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    service = _service(tmp_path)
+    try:
+        shared = service.ingest(IngestRequest(content="Alice hikes on weekends", entity_id="alice"))
+        service.ingest(
+            IngestRequest(
+                content="Alice hikes to her therapist",
+                entity_id="alice",
+                sensitivity="confidential",
+            )
+        )
+        service.ingest(
+            IngestRequest(
+                content="Alice hikes with her dog",
+                entity_id="alice",
+                agent_id="researcher",
+                agent_scope="private",
+            )
+        )
+        clustered: list[set[str]] = []
+
+        def fake_cluster(
+            records: list[MemoryRecord],
+            *,
+            min_cluster_size: int,
+        ) -> list[TopicCluster]:
+            clustered.append({record.content for record in records})
+            return []
+
+        monkeypatch.setattr("orbit_api.service.cluster_memories", fake_cluster)
+
+        service.entity_topics("alice", max_sensitivity="internal", agent_id="planner")
+        service.entity_topics("alice", agent_id="researcher")
+        service.entity_topics("alice", max_sensitivity="internal", agent_id="planner")
+        assert clustered == [
+            {shared.content},
+            {
+                "Alice hikes on weekends",
+                "Alice hikes to her therapist",
+                "Alice hikes with her dog",
+            },
+        ]
+    finally:
+        service.close()
+
+
+def test_anomaly_detector_flags_spikes_languages_and_repeats() -> None:
+    detector = IngestionAnomalyDetector(
+        AnomalyThresholds(
+            spike_min_events_per_minute=10,
+            repeat_threshold=3,
+            language_warmup_events=5,
+        )
+    )
+    start = datetime(2026, 10, 15, tzinfo=UTC)
+
+    def observe(contents: list[str], minutes: float) -> list[str]:
+        anomalies = detector.observe(
+            account_key="acct",
+            key_id="key",
+            contents=contents,
+            now=start + timedelta(minutes=minutes),
+        )
+        return [item.kind for item in anomalies]
+
+    for minute in range(12):
+        assert observe([f"note {minute}"], minute) == []
+    assert observe(["Привет, как дела?"], 12) == ["new_language"]
+    assert observe(["Привет снова"], 12.5) == []
+    assert observe(["same payload"] * 3, 13) == ["repeated_payload"]
+    assert observe(["same payload"] * 3, 13.5) == []
+    assert observe([f"burst {index}" for index in range(12)], 14) == ["volume_spike"]
+    other_key = detector.observe(account_key="acct", key_id="other", contents=["Привет"])
+    assert other_key == []
+
+
+def test_anomaly_detector_forgets_idle_keys_and_expired_alerts(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    monkeypatch.setattr("orbit_api.anomaly._MAX_TRACKED_KEYS", 2)
+    detector = IngestionAnomalyDetector(AnomalyThresholds(repeat_threshold=2, cooldown_seconds=60))
+    start = datetime(2026, 10, 15, tzinfo=UTC)
+    for index in range(3):
+        detector.observe(account_key="acct", key_id=f"key-{index}", contents=["x"], now=start)
+    assert list(detector._states) == [("acct", "key-1"), ("acct", "key-2")]
+
+    detector.observe(account_key="acct", key_id="key-2", contents=["dup", "dup"], now=start)
+    state = detector._states[("acct", "key-2")]
+    assert len(state.last_alert) == 1
+    detector.observe(
+        account_key="acct",
+        key_id="key-2",
+        contents=["fresh"],
+        now=start + timedelta(minutes=5),
+    )
+    assert state.last_alert == {}
+
+
+def test_service_records_ingestion_anomalies_for_admins(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    service.config.free_events_per_day = 10
+    service.config.free_events_per_month = 10
+    service._anomaly_detector = IngestionAnomalyDetector(  # type: ignore[attr-defined]
+        AnomalyThresholds(repeat_threshold=3)
+    )
+    try:
+        events = [IngestRequest(content="sync loop payload", entity_id="alice")] * 3
+        service.ingest_batch_with_quota(
+            account_key="acct",
+            events=events,
+            idempotency_key="batch-1",
+            key_id="key_1",
+        )
+        service.ingest_batch_with_quota(
+            account_key="acct",
+            events=events,
+            idempotency_key="batch-1",
+            key_id="key_1",
+        )
+
+        alerts = service.admin_anomalies(account_key="acct").data
+        assert [(alert.kind, alert.key_id) for alert in alerts] == [
+            ("repeated_payload", "key_1")
+        ]
+        assert alerts[0].detail["occurrences"] == 3
+        assert alerts[0].webhook_status == "skipped"
+        assert service.admin_anomalies(kind="volume_spike").data == []
+    finally:
+        service.close()
+
+
+def test_keyword_moderation_applies_strictest_policy_action() -> None:
+    provider = KeywordModerationProvider(blocklist=["acme leak"])
+    policy = {"csam": "block", "self_harm": "flag", "custom": "flag"}
+
+    assert moderate(provider, "Prefers dark mode", policy=policy).action == "allow"
+    flagged = moderate(provider, "Said they want to hurt myself tonight", policy=policy)
+    assert (flagged.action, flagged.categories) == ("flag", ("self_harm",))
+    assert moderate(provider, "Shared the ACME leak doc", policy=policy).categories == (
+        "custom",
+    )
+    blocked = moderate(
+        provider,
+        "Asked for underage porn and mentioned suicide",
+        policy=policy,
+    )
+    assert blocked.action == "block"
+    assert blocked.categories == ("csam", "self_harm")
+
+
+def test_service_moderation_blocks_flags_and_resolves_reviews(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    service._moderation_provider = KeywordModerationProvider(  # type: ignore[attr-defined]
+        blocklist=["project nightjar"]
+    )
+    service.config.moderation_policy = {"custom": "block", "self_harm": "flag"}
+    try:
+        with pytest.raises(ContentBlockedError, match="appeal via moderation review") as blocked:
+            service.ingest(
+                IngestRequest(content="Project Nightjar ships in May", entity_id="alice"),
+                account_key="acct",
+            )
+        assert service.list_memories(limit=10, cursor=None, account_key="acct").data == []
+        review_id = blocked.value.review_ids[0]
+
+        appealed = service.appeal_moderation_review(
+            review_id,
+            ModerationAppealRequest(reason="Internal codename, not sensitive"),
+            account_key="acct",
+        )
+        assert appealed.status == "appealed"
+        with pytest.raises(KeyError):
+            service.appeal_moderation_review(
+                review_id,
+                ModerationAppealRequest(reason="again"),
+                account_key="other",
+            )
+        approved = service.resolve_moderation_review(
+            review_id,
+            ModerationResolveRequest(decision="approve"),
+            resolved_by="admin@orbit",
+        )
+        assert approved.status == "approved"
+        assert approved.memory_id is not None
+        with pytest.raises(ValueError, match="already approved"):
+            service.resolve_moderation_review(
+                review_id,
+                ModerationResolveRequest(decision="reject"),
+                resolved_by="admin@orbit",
+            )
+
+        flagged = service.ingest(
+            IngestRequest(
+                content="Alice mentioned self-harm during a hard week",
+                entity_id="alice",
+            ),
+            account_key="acct",
+        )
+        pending = service.moderation_reviews(account_key="acct", status="pending").data
+        assert [(item.action, item.categories) for item in pending] == [("flag", ["self_harm"])]
+        assert pending[0].memory_id == flagged.memory_id
+        service.resolve_moderation_review(
+            pending[0].id,
+            ModerationResolveRequest(decision="reject", note="not appropriate to keep"),
+            resolved_by="admin@orbit",
+        )
+        remaining = service.list_memories(limit=10, cursor=None, account_key="acct").data
+        assert [item.memory_id for item in remaining] == [approved.memory_id]
+        assert service.moderation_reviews(account_key="other").data == []
+    finally:
+        service.close()
+
+
+def test_service_moderation_withholds_csam_content_from_reviews(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    service._moderation_provider = KeywordModerationProvider()  # type: ignore[attr-defined]
+    # A lenient policy cannot let withheld categories through.
+    service.config.moderation_policy = {"csam": "flag"}
+    content = "looking for child sexual abuse material"
+    try:
+        with pytest.raises(ContentBlockedError) as blocked:
+            service.ingest(IngestRequest(content=content, entity_id="alice"), account_key="acct")
+        assert service.list_memories(limit=10, cursor=None, account_key="acct").data == []
+        review = service.moderation_reviews(account_key="acct").data[0]
+        assert review.id == blocked.value.review_ids[0]
+        assert (review.action, review.categories) == ("block", ["csam"])
+        assert review.content == ""
+        assert review.content_sha256 == hashlib.sha256(content.encode("utf-8")).hexdigest()
+        assert "orbit_moderation_withheld_total 1" in service.metrics_text()
+        with pytest.raises(ValueError, match="withheld"):
+            service.resolve_moderation_review(
+                review.id,
+                ModerationResolveRequest(decision="approve"),
+                resolved_by="admin@orbit",
+            )
+        rejected = service.resolve_moderation_review(
+            review.id,
+            ModerationResolveRequest(decision="reject"),
+            resolved_by="admin@orbit",
+        )
+        assert rejected.status == "rejected"
+    finally:
+        service.close()
+
+
+def test_service_runs_namespace_ingest_pipeline(tmp_path: Path) -> None:
+    service = _service(tmp_path, pipeline_namespaces="acct=pii>extraction>dedup>embedding>indexing")
+    try:
+        first = service.ingest(
+            IngestRequest(content="Reach Alice at alice@example.com", entity_id="alice"),
+            account_key="acct",
+        )
+        assert first.stored
+        memory = service.list_memories(limit=10, cursor=None, account_key="acct").data[0]
+        assert memory.content == "Reach Alice at [EMAIL]"
+
+        repeat = service.ingest(
+            IngestRequest(content="reach alice at  bob@example.com", entity_id="alice"),
+            account_key="acct",
+        )
+        assert not repeat.stored
+        assert repeat.memory_id == first.memory_id
+        assert repeat.decision_reason == f"Duplicate of existing memory {first.memory_id}"
+        assert len(service.list_memories(limit=10, cursor=None, account_key="acct").data) == 1
+
+        other = service.ingest(
+            IngestRequest(content="Reach Alice at alice@example.com", entity_id="alice"),
+            account_key="other",
+        )
+        assert other.stored
+        assert service.list_memories(limit=10, cursor=None, account_key="other").data[
+            0
+        ].content == "Reach Alice at alice@example.com"
+
+        metrics = service.metrics_text()
+        assert 'orbit_pipeline_stage_runs_total{stage="pii"} 2' in metrics
+        assert 'orbit_pipeline_stage_halts_total{stage="dedup"} 1' in metrics
+        assert 'orbit_pipeline_stage_runs_total{stage="indexing"} 2' in metrics
+    finally:
+        service.close()
+
+
+def test_pipeline_config_rejects_invalid_stage_orders() -> None:
+    assert parse_pipeline("PII > extraction > embedding > indexing") == [
+        "pii",
+        "extraction",
+        "embedding",
+        "indexing",
+    ]
+    with pytest.raises(ValueError, match="must include: embedding"):
+        parse_pipeline("moderation>indexing")
+    with pytest.raises(ValueError, match="extraction must run before embedding"):
+        parse_pipeline("embedding>extraction>indexing")
+    with pytest.raises(ValueError, match="unknown pipeline stage"):
+        parse_namespace_pipelines("acme=translate>embedding>indexing")
+
+
+def test_service_applies_tenant_pipeline_webhook_mutations(
+    tmp_path: Path,
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    replies: list[Any] = []
+    sent: list[dict[str, Any]] = []
+
+    def fake_call(target: Any, payload: dict[str, Any]) -> dict[str, Any]:
+        sent.append({"url": target.url, **payload})
+        reply = replies.pop(0)
+        if isinstance(reply, Exception):
+            raise reply
+        return reply
+
+    monkeypatch.setattr("orbit_api.service.call_transform_webhook", fake_call)
+    # hooks.acme.test does not resolve; the public-host check has its own test below.
+    monkeypatch.setattr("orbit_api.service.require_public_host", lambda url, **_: None)
+    service = _service(tmp_path)
+    try:
+        with pytest.raises(KeyError):
+            service.pipeline_webhook("acct")
+        configured = service.set_pipeline_webhook(
+            "acct",
+            PipelineWebhookRequest(url="https://hooks.acme.test/orbit", secret="s3cret"),
+        )
+        assert (configured.has_secret, configured.failure_policy) == (True, "continue")
+
+        replies.append({"content": "Alice renewed the ACME-42 contract", "annotations": ["crm"]})
+        renewed = service.ingest(
+            IngestRequest(content="Alice renewed the contract", entity_id="alice"),
+            account_key="acct",
+        )
+        assert sent[0]["url"] == "https://hooks.acme.test/orbit"
+        assert sent[0]["content"] == "Alice renewed the contract"
+        stored = service.list_memories(limit=10, cursor=None, account_key="acct").data
+        assert [item.memory_id for item in stored] == [renewed.memory_id]
+        assert stored[0].content == "Alice renewed the ACME-42 contract"
+
+        replies.append({"drop": True, "reason": "internal test traffic"})
+        dropped = service.ingest(
+            IngestRequest(content="ping", entity_id="alice"),
+            account_key="acct",
+        )
+        assert not dropped.stored
+        assert dropped.decision_reason == "Dropped by pipeline stage webhook: internal test traffic"
+
+        replies.append(PipelineWebhookError("pipeline webhook timed out after 2000 ms"))
+        assert service.ingest(
+            IngestRequest(content="Alice prefers email", entity_id="alice"),
+            account_key="acct",
+        ).stored
+        service.set_pipeline_webhook(
+            "acct",
+            PipelineWebhookRequest(url="https://hooks.acme.test/orbit", failure_policy="reject"),
+        )
+        replies.append({"content": ""})
+        with pytest.raises(PipelineWebhookError, match="content must be a string"):
+            service.ingest(
+                IngestRequest(content="Alice is in Lisbon", entity_id="alice"),
+                account_key="acct",
+            )
+        assert len(service.list_memories(limit=10, cursor=None, account_key="acct").data) == 2
+
+        service.delete_pipeline_webhook("acct")
+        service.ingest(
+            IngestRequest(content="Alice is in Porto", entity_id="alice"),
+            account_key="acct",
+        )
+        assert replies == [] and len(sent) == 4
+    finally:
+        service.close()
+
+
+def test_pipeline_webhook_refuses_private_addresses(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        for url in (
+            "http://127.0.0.1:8080/transform",
+            "http://10.0.0.5/transform",
+            "http://169.254.169.254/latest/meta-data/",
+        ):
+            with pytest.raises(ValueError, match="not allowed"):
+                service.set_pipeline_webhook("acct", PipelineWebhookRequest(url=url))
+        with pytest.raises(KeyError):
+            service.pipeline_webhook("acct")
+
+        # A URL saved before the check existed is refused again at call time.
+        target = WebhookTarget(
+            url="http://[::1]:8080/transform",
+            secret=None,
+            timeout_ms=1000,
+            failure_policy="reject",
+        )
+        with pytest.raises(PipelineWebhookError, match="not allowed"):
+            call_transform_webhook(target, {"content": "Alice renewed the contract"})
+    finally:
+        service.close()
+
+
+def test_pipeline_webhook_connects_to_the_address_it_checked(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    answers = ["93.184.216.34", "169.254.169.254"]
+    sent: list[httpx.Request] = []
+
+    def fake_getaddrinfo(host: str, port: Any, **_: Any) -> list[Any]:
+        return [(socket.AF_INET, socket.SOCK_STREAM, 6, "", (answers.pop(0), 443))]
+
+    def fake_send(self: httpx.HTTPTransport, request: httpx.Request) -> httpx.Response:
+        sent.append(request)
+        return httpx.Response(204)
+
+    monkeypatch.setattr("orbit_api.url_sources.socket.getaddrinfo", fake_getaddrinfo)
+    monkeypatch.setattr(httpx.HTTPTransport, "handle_request", fake_send)
+    target = WebhookTarget(
+        url="https://hooks.acme.test/orbit",
+        secret=None,
+        timeout_ms=1000,
+        failure_policy="reject",
+    )
+
+    assert call_transform_webhook(target, {"content": "Alice renewed the contract"}) == {}
+    [request] = sent
+    assert request.url.host == "93.184.216.34"
+    assert request.headers["host"] == "hooks.acme.test"
+    assert answers == ["169.254.169.254"]
+    with pytest.raises(PipelineWebhookError, match="not allowed"):
+        call_transform_webhook(target, {"content": "Alice renewed the contract"})
+
+
+def test_service_manages_namespace_config_for_admins(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        with pytest.raises(ValueError, match="must include"):
+            service.set_namespace("acct", NamespaceRequest(pipeline=["pii", "indexing"]))
+        namespace = service.set_namespace(
+            "acct",
+            NamespaceRequest(
+                display_name="Acme",
+                pipeline=["pii", "extraction", "embedding", "indexing"],
+            ),
+        )
+        assert namespace.pipeline == service.pipeline_for("acct")
+        assert [item.account_key for item in service.list_namespaces().data] == ["acct"]
+
+        service.set_event_type_registry(
+            "acct",
+            EventTypeRegistryRequest(event_types=[EventTypeDefinition(name="preference")]),
+        )
+        with pytest.raises(ValueError, match="not registered"):
+            service.ingest(
+                IngestRequest(content="Alice likes tea", entity_id="alice", event_type="chat"),
+                account_key="acct",
+            )
+        stored = service.ingest(
+            IngestRequest(content="Alice likes tea", entity_id="alice", event_type="preference"),
+            account_key="acct",
+        )
+        assert stored.stored
+
+        issued = service.issue_api_key(account_key="acct", name="terraform", scopes=["read"])
+        assert service.api_key(account_key="acct", key_id=issued.key_id).name == "terraform"
+        with pytest.raises(KeyError):
+            service.api_key(account_key="other", key_id=issued.key_id)
+
+        service.set_retention_policy("acct", RetentionPolicyRequest(days=30))
+        now = datetime.now(UTC)
+        assert service.apply_retention_policies(now=now + timedelta(days=1)) == {}
+        assert service.apply_retention_policies(now=now + timedelta(days=31)) == {"acct": 1}
+        assert service.list_memories(limit=10, cursor=None, account_key="acct").data == []
+
+        service.delete_namespace("acct")
+        service.delete_event_type_registry("acct")
+        assert service.pipeline_for("acct") == list(service.config.pipeline_stages)
+        with pytest.raises(KeyError):
+            service.namespace("acct")
+    finally:
+        service.close()
+
+
+def test_service_test_mode_ranks_reproducibly(tmp_path: Path) -> None:
+    pinned_at = datetime(2026, 3, 1, 12, 0, tzinfo=UTC)
+
+    def run(directory: Path) -> list[tuple[str, float, datetime]]:
+        directory.mkdir()
+        service = _service(directory, test_mode=True, test_seed=7, test_clock=pinned_at)
+        try:
+            for content in (
+                "Alice prefers green tea in the morning",
+                "Alice is allergic to peanuts",
+                "Bob prefers black coffee",
+            ):
+                service.ingest(
+                    IngestRequest(content=content, entity_id="alice"),
+                    account_key="acct",
+                )
+            result = service.retrieve(
+                RetrieveRequest(query="What tea does Alice prefer?", entity_id="alice"),
+                account_key="acct",
+            )
+            return [(item.content, item.rank_score, item.timestamp) for item in result.memories]
+        finally:
+            service.close()
+
+    first = run(tmp_path / "first")
+    assert first == run(tmp_path / "second")
+    assert {timestamp for _, _, timestamp in first} == {pinned_at}
+    assert not clock.pinned()
+
+    (tmp_path / "third").mkdir()
+    service = _service(tmp_path / "third", test_mode=True)
+    try:
+        later = service.set_clock(PinnedClock(now=pinned_at + timedelta(days=7)))
+        assert later.now == clock.now() == pinned_at + timedelta(days=7)
+    finally:
+        service.close()
+    service = _service(tmp_path)
+    try:
+        with pytest.raises(ValueError, match="test mode"):
+            service.set_clock(PinnedClock(now=pinned_at))
+    finally:
+        service.close()
+
+
+def test_service_backfills_event_timestamps_on_an_injected_clock(tmp_path: Path) -> None:
+    fixed = FixedClock(datetime(2026, 6, 1, tzinfo=UTC))
+    service = _service(tmp_path, clock_source=fixed)
+    try:
+        backfilled = datetime(2025, 6, 1, 9, 30)
+        service.ingest(
+            IngestRequest(
+                content="Alice moved to Lisbon", entity_id="alice", occurred_at=backfilled
+            ),
+            account_key="acct",
+        )
+        # A client clock a minute fast is stored as now; a day ahead is rejected.
+        service.ingest(
+            IngestRequest(
+                content="Alice likes tea",
+                entity_id="alice",
+                occurred_at=fixed.now() + timedelta(seconds=60),
+            ),
+            account_key="acct",
+        )
+        with pytest.raises(ValueError, match="ORBIT_MAX_CLOCK_SKEW_SECONDS"):
+            service.ingest(
+                IngestRequest(
+                    content="Alice flew home", occurred_at=fixed.now() + timedelta(days=1)
+                ),
+                account_key="acct",
+            )
+        with pytest.raises(ValueError, match="ORBIT_MAX_BACKFILL_DAYS"):
+            service.ingest(
+                IngestRequest(
+                    content="Alice was born", occurred_at=datetime(1990, 1, 1, tzinfo=UTC)
+                ),
+                account_key="acct",
+            )
+        stored = {
+            item.content: item.timestamp
+            for item in service.list_memories(limit=10, cursor=None, account_key="acct").data
+        }
+        assert stored == {
+            "Alice moved to Lisbon": backfilled.replace(tzinfo=UTC),
+            "Alice likes tea": fixed.now(),
+        }
+
+        service.set_namespace("ci-run", NamespaceRequest(ttl_seconds=600))
+        fixed.advance(timedelta(seconds=601))
+        assert service.expire_namespaces() == {"ci-run": 0}
+    finally:
+        service.close()
+    assert clock.now() != fixed.now()
+
+
+def test_service_ranks_backdated_memories_by_occurred_at(tmp_path: Path) -> None:
+    fixed = FixedClock(datetime(2026, 6, 1, tzinfo=UTC))
+    service = _service(tmp_path, clock_source=fixed)
+    try:
+        last_summer = datetime(2025, 7, 4, tzinfo=UTC)
+        service.ingest(
+            IngestRequest(
+                content="Alice booked a trip to Lisbon", entity_id="alice", occurred_at=last_summer
+            ),
+            account_key="acct",
+        )
+        service.ingest(
+            IngestRequest(content="Alice booked a trip to Rome", entity_id="alice"),
+            account_key="acct",
+        )
+        result = service.retrieve(
+            RetrieveRequest(
+                query="Where did Alice book a trip?",
+                entity_id="alice",
+                time_range=TimeRange(
+                    start=datetime(2025, 7, 1, tzinfo=UTC), end=datetime(2025, 8, 1, tzinfo=UTC)
+                ),
+            ),
+            account_key="acct",
+        )
+        assert [item.content for item in result.memories] == ["Alice booked a trip to Lisbon"]
+        assert result.memories[0].timestamp == last_summer
+        assert result.memories[0].created_at == fixed.now()
+    finally:
+        service.close()
+
+
+def test_service_backfill_import_batches_embeddings_and_defers_consolidation(
+    tmp_path: Path,
+) -> None:
+    service = _service(tmp_path)
+    embed_calls: list[int] = []
+    consolidated: list[list[str]] = []
+    embed_inputs = service._engine.embed_inputs
+    consolidate = service._engine.consolidate
+
+    def counting_embed_inputs(extracted: Any, account_key: Any = None) -> Any:
+        embed_calls.append(len(extracted))
+        return embed_inputs(extracted, account_key=account_key)
+
+    def recording_consolidate(stored: Any, account_key: Any = None) -> None:
+        consolidated.append([memory.content for _, memory, _ in stored])
+        consolidate(stored, account_key=account_key)
+
+    service._engine.embed_inputs = counting_embed_inputs  # type: ignore[method-assign]
+    service._engine.consolidate = recording_consolidate  # type: ignore[method-assign]
+    try:
+        events = [
+            IngestRequest(
+                content="Alice asked about refunds",
+                entity_id="alice",
+                occurred_at=datetime(2025, 3, 1, tzinfo=UTC),
+            ),
+            IngestRequest(
+                content="Alice renewed her plan",
+                entity_id="alice",
+                occurred_at=datetime(2025, 4, 1, tzinfo=UTC),
+            ),
+        ]
+        with pytest.raises(ValueError, match="mode must be one of"):
+            service.import_with_quota(
+                account_key="acct", events=events, mode="turbo", idempotency_key=None
+            )
+        result, snapshot, replayed = service.import_with_quota(
+            account_key="acct", events=events, mode="backfill", idempotency_key=None
+        )
+        assert (result.mode, result.imported, result.skipped) == ("backfill", 2, 0)
+        assert result.events_per_second > 0
+        assert snapshot.remaining == 0
+        assert not replayed
+        assert embed_calls == [2]
+        # Consolidation is queued on the single maintenance worker; wait for it to run.
+        service._maintenance_executor.submit(lambda: None).result()
+        assert consolidated == [["Alice asked about refunds", "Alice renewed her plan"]]
+    finally:
+        service.close()
+
+
+def test_service_wipes_expired_sandbox_namespaces(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        sandbox = service.set_namespace("ci-run", NamespaceRequest(ttl_seconds=600))
+        assert sandbox.expires_at is not None
+        issued = service.issue_api_key(account_key="ci-run", name="ci", scopes=["write"])
+        # Sandboxes never use up the plan's quota (two events in these tests).
+        for _ in range(3):
+            assert service.consume_event_quota("ci-run").remaining == 2
+        for account_key in ("ci-run", "acct"):
+            service.ingest(
+                IngestRequest(content="Alice likes tea", entity_id="alice"),
+                account_key=account_key,
+            )
+
+        now = datetime.now(UTC)
+        assert service.expire_namespaces(now=now) == {}
+        assert service.expire_namespaces(now=now + timedelta(seconds=601)) == {"ci-run": 1}
+        assert service.list_memories(limit=10, cursor=None, account_key="ci-run").data == []
+        assert service.api_key(account_key="ci-run", key_id=issued.key_id).status == "revoked"
+        with pytest.raises(KeyError):
+            service.namespace("ci-run")
+        assert len(service.list_memories(limit=10, cursor=None, account_key="acct").data) == 1
+    finally:
+        service.close()
+
+
+def test_service_samples_and_aggregates_registered_event_types(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        service.set_event_type_registry(
+            "acct",
+            EventTypeRegistryRequest(
+                enforce=False,
+                event_types=[
+                    EventTypeDefinition(
+                        name="page_view",
+                        sampling=EventTypeSampling(mode="sample", rate=0.0),
+                    ),
+                    EventTypeDefinition(
+                        name="heartbeat",
+                        sampling=EventTypeSampling(mode="aggregate", window_seconds=60),
+                    ),
+                ],
+            ),
+        )
+        results = service.ingest_batch(
+            [
+                IngestRequest(content=content, entity_id="alice", event_type=event_type)
+                for content, event_type in (
+                    ("Alice opened pricing", "page_view"),
+                    ("Alice likes tea", "preference"),
+                    ("Alice device online", "heartbeat"),
+                    ("Alice device idle", "heartbeat"),
+                )
+            ],
+            account_key="acct",
+        )
+        assert [item.stored for item in results] == [False, True, False, False]
+        assert results[0].decision_reason.startswith("Sampled out")
+        assert results[2].decision_reason.startswith("Aggregated")
+        assert len(service.list_memories(limit=10, cursor=None, account_key="acct").data) == 1
+
+        engine = create_engine(service.config.database_url, future=True)
+        try:
+            with Session(engine) as session:
+                row = session.execute(select(ApiIngestAggregateRow)).scalar_one()
+                assert row.event_count == 2
+                row.window_start = datetime.now(UTC) - timedelta(minutes=2)
+                session.commit()
+        finally:
+            engine.dispose()
+
+        service.ingest(
+            IngestRequest(
+                content="Alice device offline",
+                entity_id="alice",
+                event_type="heartbeat",
+            ),
+            account_key="acct",
+        )
+        memories = service.list_memories(limit=10, cursor=None, account_key="acct").data
+        summaries = [item for item in memories if item.content.startswith("2 heartbeat events")]
+        assert len(summaries) == 1
+        assert "Latest: Alice device idle" in summaries[0].content
+    finally:
+        service.close()
+
+
+def test_service_counts_repeated_counter_observations(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        service.set_event_type_registry(
+            "acct",
+            EventTypeRegistryRequest(
+                enforce=False,
+                event_types=[
+                    EventTypeDefinition(
+                        name="ui_action",
+                        sampling=EventTypeSampling(mode="counter"),
+                    )
+                ],
+            ),
+        )
+        opened = IngestRequest(
+            content="User opened the settings page",
+            entity_id="alice",
+            event_type="ui_action",
+        )
+        first = service.ingest(opened, account_key="acct")
+        assert first.stored
+        repeats = service.ingest_batch(
+            [opened, opened.model_copy(update={"content": "user opened the  settings page"})],
+            account_key="acct",
+        )
+        assert [item.memory_id for item in repeats] == [first.memory_id, first.memory_id]
+        assert not any(item.stored for item in repeats)
+        assert repeats[1].decision_reason == f"Counted: occurrence 3 of memory {first.memory_id}"
+        assert len(service.list_memories(limit=10, cursor=None, account_key="acct").data) == 1
+
+        result = service.retrieve(
+            RetrieveRequest(query="settings page", entity_id="alice"),
+            account_key="acct",
+        )
+        assert result.memories[0].metadata["occurrences"]["count"] == 3
+
+        service._engine.delete_memories([first.memory_id], account_key="acct")
+        assert service.ingest(opened, account_key="acct").stored
+    finally:
+        service.close()
+
+
+def test_service_sentiment_stage_labels_and_filters_memories(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        service.set_namespace(
+            "acct",
+            NamespaceRequest(pipeline=["extraction", "sentiment", "embedding", "indexing"]),
+        )
+        for content in (
+            "Alice is frustrated that the export keeps failing",
+            "Alice said thanks, the new dashboard is great",
+            "Alice moved the export to Tuesdays",
+        ):
+            service.ingest(IngestRequest(content=content, entity_id="alice"), account_key="acct")
+
+        frustrated = service.retrieve(
+            RetrieveRequest(query="export", entity_id="alice", emotion="frustration"),
+            account_key="acct",
+        )
+        assert [item.content for item in frustrated.memories] == [
+            "Alice is frustrated that the export keeps failing"
+        ]
+        assert frustrated.memories[0].metadata["sentiment"] == "negative"
+        assert frustrated.memories[0].metadata["emotions"] == ["frustration"]
+        assert frustrated.applied_filters["emotion"] == "frustration"
+
+        positive = service.retrieve(
+            RetrieveRequest(query="dashboard", entity_id="alice", sentiment="positive"),
+            account_key="acct",
+        )
+        assert [item.metadata["emotions"] for item in positive.memories] == [["joy", "gratitude"]]
+        with pytest.raises(ValueError, match="emotion must be one of"):
+            RetrieveRequest(query="export", emotion="bored")
+    finally:
+        service.close()
+
+
+def test_service_categorizes_memories_and_weights_by_category(tmp_path: Path) -> None:
+    service = _service(tmp_path, category_weights="constraint=0")
+    try:
+        for content in (
+            "Alice is allergic to peanuts",
+            "Alice prefers dark mode in the editor",
+            "Alice wants to learn Rust this year",
+        ):
+            service.ingest(IngestRequest(content=content, entity_id="alice"), account_key="acct")
+
+        result = service.retrieve(
+            RetrieveRequest(query="Alice", entity_id="alice", limit=3),
+            account_key="acct",
+        )
+        categories = {item.content: item.metadata["category"] for item in result.memories}
+        assert categories == {
+            "Alice is allergic to peanuts": "constraint",
+            "Alice prefers dark mode in the editor": "preference",
+            "Alice wants to learn Rust this year": "goal",
+        }
+        assert result.memories[-1].metadata["category"] == "constraint"
+        assert result.memories[-1].rank_score == 0.0
+
+        goals = service.retrieve(
+            RetrieveRequest(query="Alice", entity_id="alice", category="Goal"),
+            account_key="acct",
+        )
+        assert [item.content for item in goals.memories] == ["Alice wants to learn Rust this year"]
+        assert goals.applied_filters["category"] == "goal"
+        with pytest.raises(ValueError, match="unknown memory category"):
+            service.retrieve(
+                RetrieveRequest(query="Alice", category="hobby"),
+                account_key="acct",
+            )
+    finally:
+        service.close()
+
+
+def test_service_multiplies_request_boosts_into_rank_scores(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        for content, tags in (
+            ("Alice is migrating the billing service to Postgres", ["project-x"]),
+            ("Alice is redesigning the onboarding emails", ["project-y"]),
+            ("Alice reviewed the quarterly roadmap", []),
+        ):
+            service.ingest(
+                IngestRequest(content=content, entity_id="alice", metadata={"tags": tags}),
+                account_key="acct",
+            )
+
+        result = service.retrieve(
+            RetrieveRequest(
+                query="Alice",
+                entity_id="alice",
+                limit=3,
+                boost=RetrieveBoost(tags={"project-x": 10.0, " project-y ": 0.0}),
+            ),
+            account_key="acct",
+        )
+        assert result.memories[0].content.endswith("billing service to Postgres")
+        assert result.memories[0].metadata["boost"] == 10.0
+        assert result.memories[-1].content.endswith("onboarding emails")
+        assert result.memories[-1].rank_score == 0.0
+        assert "boost" not in result.memories[1].metadata
+
+        with pytest.raises(ValueError, match="must be between 0 and 10"):
+            RetrieveBoost(event_type={"user_preference": 11.0})
+        with pytest.raises(ValueError, match="keys cannot be empty"):
+            RetrieveBoost(tags={" ": 2.0})
+    finally:
+        service.close()
+
+
+def test_service_scopes_memories_by_writing_agent(tmp_path: Path) -> None:
+    service = _service(tmp_path, agent_visibility="auditor=*")
+    try:
+        for content, agent_id, agent_scope in (
+            ("Alice prefers aisle seats on flights", "planner", None),
+            ("Alice booked a hotel in Lisbon for May", "researcher", "private"),
+            ("Alice asked for a vegetarian menu", None, None),
+        ):
+            service.ingest(
+                IngestRequest(
+                    content=content,
+                    entity_id="alice",
+                    agent_id=agent_id,
+                    agent_scope=agent_scope,
+                ),
+                account_key="acct",
+            )
+
+        def contents(**filters: str) -> set[str]:
+            result = service.retrieve(
+                RetrieveRequest(query="Alice", entity_id="alice", **filters),
+                account_key="acct",
+            )
+            return {item.content for item in result.memories}
+
+        shared = {"Alice prefers aisle seats on flights", "Alice asked for a vegetarian menu"}
+        assert contents() == shared
+        assert contents(agent_id="planner") == shared
+        assert contents(agent_id="researcher") == {
+            *shared,
+            "Alice booked a hotel in Lisbon for May",
+        }
+        assert contents(agent_id="auditor", from_agent="researcher") == {
+            "Alice booked a hotel in Lisbon for May"
+        }
+
+        result = service.retrieve(
+            RetrieveRequest(query="Alice", entity_id="alice", from_agent="planner"),
+            account_key="acct",
+        )
+        assert result.memories[0].metadata["agent_id"] == "planner"
+        assert result.memories[0].metadata["agent_scope"] == "shared"
+        assert result.applied_filters["from_agent"] == "planner"
+        with pytest.raises(ValueError, match="requires agent_id"):
+            IngestRequest(content="Alice likes tea", agent_scope="private")
+    finally:
+        service.close()
+
+
+
+def test_service_hook_memories_are_newest_first_and_widen_past_filtered_rows(
+    tmp_path: Path,
+) -> None:
+    service = _service(tmp_path)
+    try:
+        oldest = service.ingest(
+            IngestRequest(content="Bob renewed the annual plan", entity_id="bob"),
+            account_key="acct",
+        )
+        alice_ids = [
+            service.ingest(
+                IngestRequest(content=f"Alice ticket {topic} was resolved", entity_id="alice"),
+                account_key="acct",
+            ).memory_id
+            for topic in ("billing", "login", "export", "search", "mobile")
+        ]
+
+        newest = service.list_hook_memories(account_key="acct", limit=2)
+        assert [memory.id for memory in newest] == alice_ids[::-1][:2]
+        bob = service.list_hook_memories(account_key="acct", limit=1, entity_id="bob")
+        assert [memory.id for memory in bob] == [oldest.memory_id]
+    finally:
+        service.close()
+
+
+def test_service_hides_private_memories_from_other_agents_on_every_read(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        private = service.ingest(
+            IngestRequest(
+                content="Alice booked a hotel in Lisbon for May",
+                entity_id="alice",
+                agent_id="researcher",
+                agent_scope="private",
+            ),
+            account_key="acct",
+        )
+        shared = service.ingest(
+            IngestRequest(content="Alice prefers a hotel near the river", entity_id="alice"),
+            account_key="acct",
+        )
+        encoder = service._engine.input_processor.encoder
+        vector = [float(item) for item in encoder.encode_query("Alice hotel Lisbon")]
+
+        def listed(**kwargs: Any) -> set[str]:
+            page = service.list_memories(limit=10, cursor=None, account_key="acct", **kwargs)
+            return {item.memory_id for item in page.data}
+
+        def changed(**kwargs: Any) -> set[str]:
+            feed = service.list_changes(account_key="acct", **kwargs)
+            return {item.memory_id for item in feed.data}
+
+        def searched(agent_id: str | None) -> set[str]:
+            result = service.search_vector(
+                VectorSearchRequest(vector=vector, agent_id=agent_id),
+                account_key="acct",
+            )
+            return {item.memory_id for item in result.memories}
+
+        both = {private.memory_id, shared.memory_id}
+        assert listed() == {shared.memory_id}
+        assert listed(agent_id="researcher") == both
+        assert listed(all_agents=True) == both
+        assert changed() == {shared.memory_id}
+        assert changed(agent_id="researcher") == both
+        assert changed(all_agents=True) == both
+        assert searched(None) == {shared.memory_id}
+        assert searched("researcher") == both
+
+        neighbors = service.similar_memories(shared.memory_id, account_key="acct")
+        assert [item.memory_id for item in neighbors.memories] == []
+        with pytest.raises(KeyError):
+            service.similar_memories(private.memory_id, account_key="acct", agent_id="planner")
+        with pytest.raises(KeyError):
+            service.memory_versions(private.memory_id, account_key="acct")
+        history = service.memory_versions(
+            private.memory_id,
+            account_key="acct",
+            agent_id="researcher",
+        )
+        assert history.current_version == 1
+    finally:
+        service.close()
+
+
+def test_service_graph_expansion_skips_other_agents_private_memories(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        service.ingest(
+            IngestRequest(
+                content="Alice's manager is Bob",
+                entity_id="alice",
+                metadata={"entities": ["bob"]},
+            )
+        )
+        apollo = service.ingest(
+            IngestRequest(
+                content="Bob leads project Apollo",
+                entity_id="bob",
+                agent_id="researcher",
+                agent_scope="private",
+            )
+        )
+        query = "what projects is Alice's manager involved in"
+
+        def expanded(agent_id: str) -> set[str]:
+            result = service.retrieve(
+                RetrieveRequest(
+                    query=query,
+                    limit=5,
+                    entity_id="alice",
+                    mode="graph",
+                    agent_id=agent_id,
+                )
+            )
+            return {item.memory_id for item in result.memories}
+
+        assert apollo.memory_id in expanded("researcher")
+        assert apollo.memory_id not in expanded("planner")
+    finally:
+        service.close()
+
+
+def test_service_recent_fallback_skips_other_agents_private_memories(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        shared = service.ingest(IngestRequest(content="Alice adopted a cat", entity_id="alice"))
+        private = service.ingest(
+            IngestRequest(
+                content="Alice moved to Porto",
+                entity_id="alice",
+                agent_id="researcher",
+                agent_scope="private",
+            )
+        )
+
+        def recent(agent_id: str) -> list[str]:
+            result = service.retrieve(
+                RetrieveRequest(
+                    query="cat",
+                    entity_id="alice",
+                    min_score=1000.0,
+                    fallback="recent",
+                    agent_id=agent_id,
+                )
+            )
+            return [item.memory_id for item in result.memories]
+
+        assert recent("researcher") == [private.memory_id, shared.memory_id]
+        assert recent("planner") == [shared.memory_id]
+    finally:
+        service.close()
+
+
+def test_service_linked_memories_skip_other_agents_private_memories(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        claim = service.ingest(
+            IngestRequest(content="Alice prefers aisle seats", entity_id="alice")
+        )
+        rebuttal = service.ingest(
+            IngestRequest(
+                content="Alice switched to window seats",
+                entity_id="alice",
+                agent_id="researcher",
+                agent_scope="private",
+            )
+        )
+        service.link_memories(
+            claim.memory_id,
+            MemoryLinkRequest(target_memory_id=rebuttal.memory_id, link_type="contradicts"),
+        )
+
+        planner = service.retrieve(
+            RetrieveRequest(
+                query="aisle seats",
+                entity_id="alice",
+                limit=1,
+                include_linked=True,
+                agent_id="planner",
+            )
+        )
+        assert [item.memory_id for item in planner.memories] == [claim.memory_id]
+        researcher = service.retrieve(
+            RetrieveRequest(
+                query="aisle seats",
+                entity_id="alice",
+                limit=1,
+                include_linked=True,
+                agent_id="researcher",
+            )
+        )
+        assert {item.memory_id for item in researcher.memories} == {
+            claim.memory_id,
+            rebuttal.memory_id,
+        }
+    finally:
+        service.close()
+
+
+def test_service_tracks_goals_and_progress_from_memories(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        stated = service.ingest(
+            IngestRequest(
+                content="I want to send the quarterly report by 2030-01-15",
+                entity_id="alice",
+            ),
+            account_key="acct",
+        )
+        service.ingest(
+            IngestRequest(content="I need to renew my passport by 2020-01-01", entity_id="alice"),
+            account_key="acct",
+        )
+        goals = service.entity_goals("alice", account_key="acct").goals
+        assert [(goal.title, goal.status) for goal in goals] == [
+            ("send the quarterly report", "open"),
+            ("renew my passport", "open"),
+        ]
+        assert goals[0].due_date == date(2030, 1, 15)
+        assert goals[0].memory_id == stated.memory_id
+        assert [goal.overdue for goal in goals] == [False, True]
+
+        started = service.ingest(
+            IngestRequest(content="I started drafting the quarterly report", entity_id="alice"),
+            account_key="acct",
+        )
+        service.ingest(
+            IngestRequest(content="I finally finished the quarterly report", entity_id="alice"),
+            account_key="acct",
+        )
+        done = service.entity_goals("alice", status="done", account_key="acct").goals
+        assert [goal.title for goal in done] == ["send the quarterly report"]
+        assert [item.status for item in done[0].progress] == ["in_progress", "done"]
+        assert done[0].progress[0].memory_id == started.memory_id
+        with pytest.raises(ValueError, match="status must be one of"):
+            service.entity_goals("alice", status="blocked", account_key="acct")
+
+        service._engine.delete_memories([stated.memory_id], account_key="acct")
+        remaining = service.entity_goals("alice", account_key="acct").goals
+        assert [goal.title for goal in remaining] == ["renew my passport"]
+    finally:
+        service.close()
+
+
+def test_service_reports_unresolved_fact_conflicts(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        for content in ("I am allergic to pineapple.", "I am not allergic to pineapple anymore."):
+            service.ingest(
+                IngestRequest(content=content, event_type="user_question", entity_id="alice"),
+                account_key="acct",
+            )
+
+        conflicts = service.entity_conflicts("alice", account_key="acct").conflicts
+        assert [(item.fact_key, item.clarification_required) for item in conflicts] == [
+            ("allergy:pineapple", True)
+        ]
+        facts = conflicts[0].facts
+        assert [fact.polarity for fact in facts] == ["positive", "negative"]
+        assert facts[0].source_content == "I am allergic to pineapple."
+        assert conflicts[0].detected_at == facts[-1].created_at
+        assert service.entity_conflicts("bob", account_key="acct").conflicts == []
+
+        service._engine.delete_memories([facts[0].memory_id], account_key="acct")
+        assert service.entity_conflicts("alice", account_key="acct").conflicts == []
+    finally:
+        service.close()
+
+
+def test_service_conflicts_hide_sources_the_caller_cannot_read(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        service.ingest(
+            IngestRequest(
+                content="I am allergic to pineapple.",
+                event_type="user_question",
+                entity_id="alice",
+                sensitivity="confidential",
+            ),
+            account_key="acct",
+        )
+        service.ingest(
+            IngestRequest(
+                content="I am not allergic to pineapple anymore.",
+                event_type="user_question",
+                entity_id="alice",
+                agent_id="researcher",
+                agent_scope="private",
+            ),
+            account_key="acct",
+        )
+
+        def sources(**kwargs: Any) -> list[str | None]:
+            conflicts = service.entity_conflicts("alice", account_key="acct", **kwargs).conflicts
+            return [fact.source_content for fact in conflicts[0].facts]
+
+        assert sources(agent_id="researcher") == [
+            "I am allergic to pineapple.",
+            "I am not allergic to pineapple anymore.",
+        ]
+        assert sources(max_sensitivity="internal", agent_id="planner") == [None, None]
+    finally:
+        service.close()
+
+
+def test_service_resurfaces_idle_important_memories(
+    tmp_path: Path,
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    sent: list[dict[str, Any]] = []
+
+    def fake_post(
+        url: str,
+        event_type: str,
+        payload: dict[str, Any],
+        *,
+        secret: str | None,
+    ) -> bool:
+        sent.append({"url": url, "type": event_type, **payload})
+        return True
+
+    monkeypatch.setattr("orbit_api.service._post_webhook", fake_post)
+    service = _service(
+        tmp_path,
+        resurface_min_importance=0.0,
+        resurface_webhook_url="https://hooks.example/resurface",
+    )
+    try:
+        stored = service.ingest(
+            IngestRequest(content="I want to learn Rust", entity_id="alice"),
+            account_key="acct",
+        )
+        assert service.resurface(account_key="acct").data == []
+
+        later = datetime.now(UTC) + timedelta(days=45)
+        items = service.resurface(account_key="acct", entity_id="alice", now=later).data
+        assert [item.memory.memory_id for item in items] == [stored.memory_id]
+        assert items[0].prompt == "Last month you mentioned: I want to learn Rust"
+        assert items[0].idle_days >= 44
+        assert service.resurface(account_key="acct", entity_id="bob", now=later).data == []
+
+        assert service.run_resurfacing(now=later) == {"acct": 1}
+        assert sent[0]["type"] == "memory_resurfaced"
+        assert sent[0]["memory"]["memory_id"] == stored.memory_id
+        # Delivered memories wait another ORBIT_RESURFACE_AFTER_DAYS before resurfacing.
+        assert service.run_resurfacing(now=later + timedelta(days=1)) == {}
+        assert service.resurface(account_key="acct", now=later).data == []
+    finally:
+        service.close()
+
+
+def test_service_generates_and_delivers_digests(
+    tmp_path: Path,
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    sent: list[dict[str, Any]] = []
+
+    def fake_post(
+        url: str,
+        event_type: str,
+        payload: dict[str, Any],
+        *,
+        secret: str | None,
+    ) -> bool:
+        sent.append({"url": url, "type": event_type, **payload})
+        return True
+
+    monkeypatch.setattr("orbit_api.service._post_webhook", fake_post)
+    service = _service(tmp_path, digest_webhook_url="https://hooks.example/digests")
+    try:
+        for content, entity_id in (
+            ("I love sushi", "alice"),
+            ("I moved to Lisbon", "alice"),
+            ("I prefer window seats", "bob"),
+        ):
+            service.ingest(IngestRequest(content=content, entity_id=entity_id), account_key="acct")
+
+        digest = service.create_digest(
+            DigestRequest(period="weekly", entity_id="alice", deliver=True),
+            account_key="acct",
+        )
+        assert digest.memory_count == 2
+        assert digest.model is None
+        assert digest.summary.startswith("2 new memories in the past week")
+        assert "- I love sushi" in digest.summary
+        assert sum(digest.categories.values()) == 2
+        assert digest.delivered is True
+        assert sent[0]["type"] == "digest_created"
+        assert sent[0]["digest_id"] == digest.digest_id
+
+        assert service.digest(digest.digest_id, account_key="acct") == digest
+        with pytest.raises(KeyError):
+            service.digest(digest.digest_id, account_key="other")
+
+        namespace = service.create_digest(DigestRequest(), account_key="acct")
+        assert namespace.memory_count == 3
+        assert namespace.delivered is False
+        later = service.create_digest(
+            DigestRequest(period="daily"),
+            account_key="acct",
+            now=datetime.now(UTC) + timedelta(days=2),
+        )
+        assert later.summary == "No new memories in the past day."
+    finally:
+        service.close()
+
+
+def test_service_enforces_namespace_write_policy(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        with pytest.raises(ValueError, match="unknown memory category"):
+            service.set_write_policy("acct", WritePolicyRequest(banned_categories=["hobby"]))
+        policy = service.set_write_policy(
+            "acct",
+            WritePolicyRequest(
+                allowed_event_types=["user_fact", "user_fact"],
+                max_importance=0.5,
+                banned_categories=["Constraint"],
+            ),
+        )
+        assert policy.allowed_event_types == ["user_fact"]
+        assert policy.banned_categories == ["constraint"]
+
+        stored = service.ingest(
+            IngestRequest(
+                content="Alice prefers dark mode",
+                event_type="user_fact",
+                metadata={"importance": 0.4},
+            ),
+            account_key="acct",
+        )
+        assert stored.stored
+        violations = {
+            "allowed_event_types": IngestRequest(
+                content="Alice prefers tea",
+                event_type="agent_scratchpad",
+            ),
+            "max_importance": IngestRequest(
+                content="Alice prefers coffee",
+                event_type="user_fact",
+                metadata={"importance": 0.9},
+            ),
+            "banned_categories": IngestRequest(
+                content="Alice is allergic to peanuts",
+                event_type="user_fact",
+            ),
+        }
+        for rule, request in violations.items():
+            with pytest.raises(WritePolicyViolationError, match=rule) as excinfo:
+                service.ingest(request, account_key="acct")
+            assert excinfo.value.rule == rule
+        with pytest.raises(WritePolicyViolationError, match="max_importance"):
+            service.remember_in_session(
+                "sess-1",
+                SessionMemoryRequest(
+                    content="Alice likes jazz",
+                    event_type="user_fact",
+                    importance=0.9,
+                ),
+                account_key="acct",
+            )
+        # Other namespaces are unaffected, and deleting the policy lifts it.
+        service.ingest(violations["banned_categories"], account_key="other")
+        assert service.delete_write_policy("acct").max_importance == 0.5
+        service.ingest(violations["allowed_event_types"], account_key="acct")
+        with pytest.raises(KeyError):
+            service.write_policy("acct")
+    finally:
+        service.close()
+
+
+def test_service_holds_review_categories_for_approval(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        service.set_write_policy("acct", WritePolicyRequest(review_categories=["constraint"]))
+        responses = service.ingest_batch(
+            [
+                IngestRequest(content="Alice is allergic to peanuts", entity_id="alice"),
+                IngestRequest(content="Alice prefers dark mode", entity_id="alice"),
+            ],
+            account_key="acct",
+        )
+        held, stored = responses
+        assert not held.stored and held.review_id is not None
+        assert held.decision_reason == f"Held for review {held.review_id} (constraint)"
+        # Nothing is stored yet, so no memory id is made up for it.
+        assert held.memory_id == held.review_id
+        assert stored.stored and stored.review_id is None
+
+        def contents() -> list[str]:
+            result = service.retrieve(
+                RetrieveRequest(query="What is Alice allergic to?", entity_id="alice"),
+                account_key="acct",
+            )
+            return [item.content for item in result.memories]
+
+        assert "Alice is allergic to peanuts" not in contents()
+        [pending] = service.memory_reviews(account_key="acct", status="pending").data
+        assert pending.review_id == held.review_id
+        assert (pending.category, pending.entity_id) == ("constraint", "alice")
+        with pytest.raises(KeyError):
+            service.resolve_memory_review(
+                held.review_id,
+                "approve",
+                MemoryReviewDecision(),
+                account_key="other",
+                resolved_by="other",
+            )
+
+        approved = service.resolve_memory_review(
+            held.review_id,
+            "approve",
+            MemoryReviewDecision(note="confirmed with Alice"),
+            account_key="acct",
+            resolved_by="reviewer",
+        )
+        assert approved.status == "approved" and approved.memory_id is not None
+        assert approved.resolved_by == "reviewer"
+        assert "Alice is allergic to peanuts" in contents()
+        with pytest.raises(ValueError, match="already approved"):
+            service.resolve_memory_review(
+                held.review_id,
+                "reject",
+                MemoryReviewDecision(),
+                account_key="acct",
+                resolved_by="reviewer",
+            )
+
+        second = service.ingest(
+            IngestRequest(content="Alice must not eat shellfish", entity_id="alice"),
+            account_key="acct",
+        )
+        rejected = service.resolve_memory_review(
+            second.review_id,
+            "reject",
+            MemoryReviewDecision(),
+            account_key="acct",
+            resolved_by="reviewer",
+        )
+        assert rejected.status == "rejected" and rejected.memory_id is None
+        assert "Alice must not eat shellfish" not in contents()
+        assert [item.status for item in service.memory_reviews(account_key="acct").data] == [
+            "rejected",
+            "approved",
+        ]
+    finally:
+        service.close()
+
+
+def test_service_review_keeps_attachments_and_claims_each_decision_once(
+    tmp_path: Path,
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    service = _service(tmp_path)
+    try:
+        service.set_write_policy("acct", WritePolicyRequest(review_categories=["constraint"]))
+        held = service.ingest(
+            IngestRequest(
+                content="Alice is allergic to peanuts",
+                entity_id="alice",
+                attachment=IngestAttachment(
+                    content_base64=base64.b64encode(b"allergy letter").decode("ascii"),
+                    content_type="text/plain",
+                    filename="letter.txt",
+                ),
+            ),
+            account_key="acct",
+        )
+        assert held.review_id is not None
+
+        def decide(decision: str) -> Any:
+            return service.resolve_memory_review(
+                held.review_id,
+                decision,
+                MemoryReviewDecision(),
+                account_key="acct",
+                resolved_by="reviewer",
+            )
+
+        # A failed approval hands the review back instead of leaving it claimed.
+        def failing_pipeline(*args: Any, **kwargs: Any) -> Any:
+            raise RuntimeError("embedding provider down")
+
+        run_pipeline = service._run_pipeline
+        monkeypatch.setattr(service, "_run_pipeline", failing_pipeline)
+        with pytest.raises(RuntimeError, match="embedding provider down"):
+            decide("approve")
+        [pending] = service.memory_reviews(account_key="acct").data
+        assert pending.status == "pending"
+        monkeypatch.setattr(service, "_run_pipeline", run_pipeline)
+
+        # A decision already in flight elsewhere wins; this one is refused.
+        with Session(service._state_engine) as session:
+            session.get(ApiMemoryReviewRow, held.review_id).status = "approving"
+            session.commit()
+        with pytest.raises(ValueError, match="already approving"):
+            decide("reject")
+        with Session(service._state_engine) as session:
+            session.get(ApiMemoryReviewRow, held.review_id).status = "pending"
+            session.commit()
+
+        approved = decide("approve")
+        assert approved.status == "approved" and approved.memory_id is not None
+        data, content_type, filename = service.memory_attachment(
+            approved.memory_id, account_key="acct"
+        )
+        assert (data, content_type, filename) == (b"allergy letter", "text/plain", "letter.txt")
+    finally:
+        service.close()
+
+
+def test_service_runs_scheduled_exports_incrementally(tmp_path: Path) -> None:
+    service = _service(tmp_path, export_file_root=str(tmp_path))
+    try:
+        with pytest.raises(ValueError, match="destination must look like"):
+            service.create_export_job(
+                "acct",
+                ExportJobRequest(cron="@hourly", destination="ftp://lake/orbit"),
+            )
+        for outside in ("file:///etc/cron.d", f"file://{tmp_path}/../escape"):
+            with pytest.raises(ValueError, match="must be inside ORBIT_EXPORT_FILE_ROOT"):
+                service.create_export_job(
+                    "acct",
+                    ExportJobRequest(cron="@hourly", destination=outside),
+                )
+        job = service.create_export_job(
+            "acct",
+            ExportJobRequest(cron="*/15 * * * *", destination=f"file://{tmp_path}/exports"),
+        )
+        assert [item.export_id for item in service.list_export_jobs("acct").data] == [
+            job.export_id
+        ]
+        for content in ("Alice prefers email", "Alice works at ACME"):
+            service.ingest(IngestRequest(content=content, entity_id="alice"), account_key="acct")
+
+        now = datetime(2026, 10, 15, 9, 7, tzinfo=UTC)
+        first = service.run_export_job(job.export_id, now=now)
+        assert first.last_status == "succeeded" and first.rows_exported >= 2
+        assert first.next_run_at == datetime(2026, 10, 15, 9, 15, tzinfo=UTC)
+        exported = tmp_path / "exports" / str(first.last_object_key)
+        rows = [json.loads(line) for line in exported.read_text().splitlines()]
+        assert len(rows) == first.rows_exported
+        assert rows[0]["operation"] == "created" and rows[-1]["sequence"] == first.cursor
+
+        assert service.run_due_exports(now=now + timedelta(minutes=5)) == []
+        again = service.run_due_exports(now=now + timedelta(minutes=8))
+        assert [(item.rows_exported, item.cursor) for item in again] == [(0, first.cursor)]
+
+        removed = service.delete_export_job(job.export_id)
+        assert removed.export_id == job.export_id
+        assert service.list_export_jobs("acct").data == []
+    finally:
+        service.close()
+
+
+def test_file_export_destinations_need_a_configured_root(tmp_path: Path) -> None:
+    with pytest.raises(ValueError, match="file:// destinations are disabled"):
+        validate_destination(f"file://{tmp_path}/exports")
+    assert validate_destination("s3://lake/orbit/") == "s3://lake/orbit"
+    assert validate_destination(
+        f"file://{tmp_path}/a/../exports",
+        file_root=str(tmp_path),
+    ) == f"file://{tmp_path.resolve()}/exports"
+    with pytest.raises(ValueError, match="must be inside"):
+        build_destination_store(f"file://{tmp_path}/../other", file_root=str(tmp_path))
+
+
+def test_cron_schedule_matches_standard_fields() -> None:
+    start = datetime(2026, 10, 15, 9, 7, tzinfo=UTC)  # a Thursday
+    assert CronSchedule.parse("@daily").next_after(start) == datetime(2026, 10, 16, tzinfo=UTC)
+    assert CronSchedule.parse("30 2 * * 7").next_after(start) == datetime(
+        2026, 10, 18, 2, 30, tzinfo=UTC
+    )
+    # Day of month and day of week combine with OR, as in cron.
+    assert CronSchedule.parse("0 0 1 * 5").next_after(start) == datetime(
+        2026, 10, 16, tzinfo=UTC
+    )
+    for expression in ("* * *", "61 * * * *", "0 0 30 2 *", "*/0 * * * *"):
+        with pytest.raises(ValueError):
+            CronSchedule.parse(expression)
+
+
+def test_wasm_host_api_rewrites_annotates_and_halts() -> None:
+    context = IngestContext(
+        request=IngestRequest(content="Ticket ORB-12 is blocked", entity_id="alice"),
+        account_key="acct",
+        content="Ticket ORB-12 is blocked",
+        metadata={"relationships": ["sensitivity:internal"]},
+    )
+    host = WasmHostApi("wasm:tickets", context)
+    view = json.loads(host.metadata())
+    assert view["entity_id"] == "alice"
+    assert view["metadata"]["relationships"] == ["sensitivity:internal"]
+
+    host.set_content("Ticket [ORB-12] is blocked".encode())
+    host.annotate(b"sensitivity:public")
+    assert context.screened_request().content == "Ticket [ORB-12] is blocked"
+    assert context.metadata["relationships"] == [
+        "sensitivity:internal",
+        "annotation:sensitivity:public",
+    ]
+    with pytest.raises(WasmStageError, match="invalid UTF-8"):
+        host.annotate(b"\xff")
+
+    pipeline = IngestPipeline(
+        {
+            **{name: (lambda ctx: None) for name in PIPELINE_STAGES},
+            "wasm:tickets": lambda ctx: WasmHostApi("wasm:tickets", ctx).halt(b"closed"),
+        }
+    )
+    pipeline.prepare(["wasm:tickets", "embedding", "indexing"], context)
+    assert (context.halted_by, context.halt_reason) == ("wasm:tickets", "closed")
+    assert pipeline.metrics_snapshot()["wasm:tickets"].halts == 1
+    assert pipeline.metrics_snapshot()["embedding"].runs == 0
+
+
+def test_pipeline_config_requires_registered_wasm_stages() -> None:
+    config = ApiConfig(
+        wasm_stages="Tickets=/opt/orbit/tickets.wasm",
+        pipeline_namespaces="acct=wasm:tickets>extraction>embedding>indexing",
+    )
+    assert config.wasm_stages == {"tickets": "/opt/orbit/tickets.wasm"}
+    with pytest.raises(ValueError, match="unregistered stage"):
+        ApiConfig(pipeline_stages="wasm:tickets>embedding>indexing")
+    with pytest.raises(ValueError, match="indexing must run last"):
+        parse_pipeline("embedding>indexing>wasm:tickets")
+
+
+def test_wasm_stage_registration_requires_run_and_memory_exports(tmp_path: Path) -> None:
+    pytest.importorskip("wasmtime")
+    no_memory = tmp_path / "no_memory.wat"
+    no_memory.write_text('(module (func (export "run") (result i32) i32.const 0))')
+    with pytest.raises(ValueError, match="must export its memory"):
+        WasmStage("tickets", no_memory, fuel=10_000, max_memory_bytes=65_536)
+    no_run = tmp_path / "no_run.wat"
+    no_run.write_text('(module (memory (export "memory") 1))')
+    with pytest.raises(ValueError, match=r"must export run\(\) -> i32"):
+        WasmStage("tickets", no_run, fuel=10_000, max_memory_bytes=65_536)
+
+
+def test_service_hides_memories_above_caller_sensitivity(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        public = service.ingest(
+            IngestRequest(content="Alice likes trail running", entity_id="alice")
+        )
+        internal = service.ingest(
+            IngestRequest(
+                content="Alice runs the trail running club budget",
+                entity_id="alice",
+                sensitivity="internal",
+            )
+        )
+        confidential = service.ingest(
+            IngestRequest(
+                content="Alice filed an HR complaint about the trail running club",
+                entity_id="alice",
+                sensitivity="Confidential",
+            )
+        )
+        request = RetrieveRequest(query="trail running", limit=10, entity_id="alice")
+
+        def visible(max_sensitivity: str | None) -> set[str]:
+            result = service.retrieve(request, max_sensitivity=max_sensitivity)
+            return {item.memory_id for item in result.memories}
+
+        assert visible("public") == {public.memory_id}
+        assert visible("internal") == {public.memory_id, internal.memory_id}
+        everything = {public.memory_id, internal.memory_id, confidential.memory_id}
+        assert visible("confidential") == everything
+        assert visible(None) == everything
+
+        listed = service.list_memories(limit=10, cursor=None, max_sensitivity="internal").data
+        assert {item.metadata["sensitivity"] for item in listed} == {"public", "internal"}
+        restricted = service.retrieve(request, max_sensitivity="public")
+        assert restricted.applied_filters["max_sensitivity"] == "public"
+    finally:
+        service.close()
+
+
+def test_service_hides_restricted_memories_from_changes_and_history(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        public = service.ingest(
+            IngestRequest(content="Alice likes trail running", entity_id="alice"),
+            account_key="acct",
+        )
+        secret = service.ingest(
+            IngestRequest(
+                content="Alice filed an HR complaint",
+                entity_id="alice",
+                sensitivity="confidential",
+            ),
+            account_key="acct",
+        )
+
+        changes = service.list_changes(account_key="acct", max_sensitivity="public")
+        assert [item.memory_id for item in changes.data] == [public.memory_id]
+        assert changes.cursor == str(service.latest_change_sequence("acct"))
+        cleared = service.list_changes(account_key="acct", max_sensitivity="confidential")
+        assert {item.memory_id for item in cleared.data} == {public.memory_id, secret.memory_id}
+
+        with pytest.raises(KeyError):
+            service.memory_versions(secret.memory_id, account_key="acct", max_sensitivity="public")
+        with pytest.raises(KeyError):
+            service.memory_diff(secret.memory_id, account_key="acct", max_sensitivity="internal")
+        history = service.memory_versions(
+            secret.memory_id,
+            account_key="acct",
+            max_sensitivity="confidential",
+        )
+        assert history.versions[-1].content == "Alice filed an HR complaint"
+        assert service.memory_versions(
+            public.memory_id,
+            account_key="acct",
+            max_sensitivity="public",
+        ).current_version == 1
+    finally:
+        service.close()
+
+
+def test_ingest_request_rejects_unknown_sensitivity() -> None:
+    with pytest.raises(ValueError, match="sensitivity must be one of"):
+        IngestRequest(content="Alice", sensitivity="secret")
+
+
+def test_service_shares_memories_with_entities_and_groups(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        shopping = service.ingest(
+            IngestRequest(
+                content="Alice buys oat milk every week",
+                entity_id="alice",
+                metadata={"tags": ["groceries"]},
+            )
+        )
+        private = service.ingest(
+            IngestRequest(content="Alice buys oat milk for her diet plan", entity_id="alice")
+        )
+        request = RetrieveRequest(query="oat milk", limit=10, entity_id="bob")
+
+        def visible() -> set[str]:
+            return {item.memory_id for item in service.retrieve(request).memories}
+
+        assert visible() == set()
+        service.set_entity_group("family", EntityGroupRequest(entity_ids=["bob", "carol"]))
+        share = service.share_memories(
+            "alice",
+            MemoryShareRequest(target_group_id="family", tags=["groceries"]),
+        )
+        result = service.retrieve(request)
+        assert [item.memory_id for item in result.memories] == [shopping.memory_id]
+        assert result.memories[0].metadata["shared_from"] == "alice"
+        assert private.memory_id not in visible()
+
+        incoming = service.memory_shares("carol", direction="incoming").data
+        assert [item.share_id for item in incoming] == [share.share_id]
+        with pytest.raises(KeyError):
+            service.share_memories(
+                "bob",
+                MemoryShareRequest(target_entity_id="carol", memory_ids=[private.memory_id]),
+            )
+
+        revoked = service.revoke_memory_share(share.share_id)
+        assert revoked.revoked_at is not None
+        assert visible() == set()
+        assert service.memory_shares("alice").data == []
+    finally:
+        service.close()
+
+
+def test_memory_share_request_requires_one_target() -> None:
+    with pytest.raises(ValueError, match="exactly one of"):
+        MemoryShareRequest(target_entity_id="bob", target_group_id="family", tags=["x"])
+    with pytest.raises(ValueError, match="memory_ids or tags"):
+        MemoryShareRequest(target_entity_id="bob")
+
+
+def test_service_updates_memory_and_reverts_to_earlier_version(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        stored = service.ingest(
+            IngestRequest(content="Alice prefers window seats on flights", entity_id="alice")
+        )
+        memory_id = stored.memory_id
+        updated = service.update_memory(
+            memory_id,
+            MemoryUpdateRequest(content="Alice prefers aisle seats on flights"),
+        )
+        assert updated.content == "Alice prefers aisle seats on flights"
+        assert updated.metadata["entities"] == ["alice"]
+
+        diff = service.memory_diff(memory_id)
+        assert (diff.from_version, diff.to_version) == (1, 2)
+        assert "-Alice prefers window seats on flights" in diff.diff
+        assert "+Alice prefers aisle seats on flights" in diff.diff
+
+        reverted = service.revert_memory(memory_id, 1)
+        assert reverted.content == "Alice prefers window seats on flights"
+        history = service.memory_versions(memory_id)
+        assert [item.operation for item in history.versions] == ["created", "updated", "reverted"]
+        assert history.versions[-1].reverted_from == 1
+        assert history.current_version == 3
+        assert history.deleted_at is None
+
+        with pytest.raises(KeyError):
+            service.memory_diff(memory_id, from_version=9)
+        with pytest.raises(KeyError):
+            service.update_memory("missing", MemoryUpdateRequest(content="anything"))
+    finally:
+        service.close()
+
+
+def test_service_conditional_writes_reject_stale_versions(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        profile = service.patch_entity_attributes(
+            "alice",
+            EntityAttributesPatchRequest(attributes={"timezone": "UTC"}),
+            expected_version=0,
+        )
+        assert profile.version == 1
+        with pytest.raises(PreconditionFailedError) as stale_profile:
+            service.patch_entity_attributes(
+                "alice",
+                EntityAttributesPatchRequest(attributes={"timezone": "Europe/Dublin"}),
+                expected_version=0,
+            )
+        assert stale_profile.value.current_version == 1
+        assert service.entity_attributes("alice").attributes == {"timezone": "UTC"}
+
+        memory_id = service.ingest(
+            IngestRequest(content="Alice commutes by bike", entity_id="alice")
+        ).memory_id
+        edited = service.update_memory(
+            memory_id,
+            MemoryUpdateRequest(content="Alice commutes by train"),
+            expected_version=1,
+        )
+        assert edited.version == 2
+        with pytest.raises(PreconditionFailedError):
+            service.update_memory(
+                memory_id,
+                MemoryUpdateRequest(content="Alice commutes by car"),
+                expected_version=1,
+            )
+        assert service.memory_versions(memory_id).versions[-1].content == "Alice commutes by train"
+    finally:
+        service.close()
+
+
+def test_service_conditional_writes_hold_under_concurrent_editors(
+    tmp_path: Path,
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    service = _service(tmp_path)
+    try:
+        memory_id = service.ingest(
+            IngestRequest(content="Alice commutes by bike", entity_id="alice")
+        ).memory_id
+        engine_update = service._engine.update_memory
+        rejected: list[PreconditionFailedError] = []
+
+        def racing_update(*args: Any, **kwargs: Any) -> Any:
+            # A second editor holding the same ETag arrives while the first is still writing.
+            monkeypatch.setattr(service._engine, "update_memory", engine_update)
+            try:
+                service.update_memory(
+                    memory_id,
+                    MemoryUpdateRequest(content="Alice commutes by car"),
+                    expected_version=1,
+                )
+            except PreconditionFailedError as exc:
+                rejected.append(exc)
+            return engine_update(*args, **kwargs)
+
+        monkeypatch.setattr(service._engine, "update_memory", racing_update)
+        edited = service.update_memory(
+            memory_id,
+            MemoryUpdateRequest(content="Alice commutes by train"),
+            expected_version=1,
+        )
+        assert edited.version == 2
+        assert [exc.current_version for exc in rejected] == [2]
+        assert service.memory_versions(memory_id).versions[-1].content == "Alice commutes by train"
+
+        account_key = service._normalize_account_key(None)
+
+        def racing_patch(entity_id: str) -> Callable[[dict[str, Any], dict[str, str]], Any]:
+            pending = [entity_id]
+
+            def changes(_attributes: dict[str, Any], _sources: dict[str, str]) -> Any:
+                # Another request creates the profile between this one's read and its insert.
+                if pending:
+                    service.patch_entity_attributes(
+                        pending.pop(),
+                        EntityAttributesPatchRequest(attributes={"timezone": "UTC"}),
+                    )
+                return {"language": "en"}
+
+            return changes
+
+        with pytest.raises(PreconditionFailedError) as stale_profile:
+            service._update_entity_attributes(
+                account_key=account_key,
+                entity_id="bob",
+                source="manual",
+                changes=racing_patch("bob"),
+                expected_version=0,
+            )
+        assert stale_profile.value.current_version == 1
+        merged = service._update_entity_attributes(
+            account_key=account_key,
+            entity_id="carol",
+            source="manual",
+            changes=racing_patch("carol"),
+        )
+        assert merged.attributes == {"language": "en", "timezone": "UTC"}
+        assert merged.version == 2
+    finally:
+        service.close()
+
+
+def test_service_retrieve_batch_returns_one_result_per_query_in_order(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        service.ingest(IngestRequest(content="Alice flies to Lisbon in May", entity_id="alice"))
+        service.ingest(IngestRequest(content="Alice prefers aisle seats", entity_id="alice"))
+        service.ingest(IngestRequest(content="Bob prefers window seats", entity_id="bob"))
+
+        result = service.retrieve_batch(
+            BatchRetrieveRequest(
+                queries=["travel to Lisbon", "seat preference"],
+                entity_id="alice",
+                limit=5,
+            )
+        )
+        assert len(result.results) == 2
+        for item in result.results:
+            assert item.memories
+            assert all("alice" in memory.metadata["entities"] for memory in item.memories)
+        assert result.degraded is False
+    finally:
+        service.close()
+
+
+def test_service_search_vector_returns_nearest_memories(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        service.ingest(IngestRequest(content="Alice prefers aisle seats", entity_id="alice"))
+        service.ingest(IngestRequest(content="Bob flies to Lisbon in May", entity_id="bob"))
+        encoder = service._engine.input_processor.encoder
+        vector = [float(item) for item in encoder.encode_query("aisle seat preference")]
+
+        result = service.search_vector(VectorSearchRequest(vector=vector, limit=5))
+        assert result.memories[0].content == "Alice prefers aisle seats"
+        scores = [memory.rank_score for memory in result.memories]
+        assert scores == sorted(scores, reverse=True)
+
+        filtered = service.search_vector(VectorSearchRequest(vector=vector, entity_id="bob"))
+        assert [memory.content for memory in filtered.memories] == ["Bob flies to Lisbon in May"]
+        assert filtered.applied_filters == {"entity_id": "bob"}
+
+        with pytest.raises(ValueError, match="16 dimensions"):
+            service.search_vector(VectorSearchRequest(vector=[1.0, 0.0]))
+    finally:
+        service.close()
+
+
+def test_service_similar_memories_excludes_the_source_and_respects_clearance(
+    tmp_path: Path,
+) -> None:
+    service = _service(tmp_path)
+    try:
+        source = service.ingest(
+            IngestRequest(content="Alice prefers aisle seats", entity_id="alice")
+        )
+        service.ingest(IngestRequest(content="Alice always books aisle seats", entity_id="alice"))
+        service.ingest(IngestRequest(content="Bob flies to Lisbon in May", entity_id="bob"))
+        secret = service.ingest(
+            IngestRequest(
+                content="Alice's seat upgrade budget", entity_id="alice", sensitivity="confidential"
+            )
+        )
+
+        result = service.similar_memories(source.memory_id, limit=5, max_sensitivity="public")
+        contents = [memory.content for memory in result.memories]
+        assert contents[0] == "Alice always books aisle seats"
+        assert "Alice prefers aisle seats" not in contents
+        assert "Alice's seat upgrade budget" not in contents
+        scores = [memory.rank_score for memory in result.memories]
+        assert scores == sorted(scores, reverse=True)
+
+        with pytest.raises(KeyError):
+            service.similar_memories(secret.memory_id, max_sensitivity="public")
+        with pytest.raises(KeyError):
+            service.similar_memories("missing")
+    finally:
+        service.close()
+
+
+def test_service_memory_links_are_traversed_with_include_linked(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        claim = service.ingest(
+            IngestRequest(content="Alice prefers aisle seats", entity_id="alice")
+        )
+        rebuttal = service.ingest(
+            IngestRequest(content="Alice picked a window seat on every trip", entity_id="alice")
+        )
+        service.ingest(IngestRequest(content="Bob flies to Lisbon in May", entity_id="bob"))
+
+        link = service.link_memories(
+            claim.memory_id,
+            MemoryLinkRequest(target_memory_id=rebuttal.memory_id, link_type="Contradicts"),
+        )
+        assert link.link_type == "contradicts"
+        again = service.link_memories(
+            claim.memory_id,
+            MemoryLinkRequest(target_memory_id=rebuttal.memory_id, link_type="contradicts"),
+        )
+        assert again.link_id == link.link_id
+        assert [item.link_id for item in service.memory_links(rebuttal.memory_id).data] == [
+            link.link_id
+        ]
+        with pytest.raises(ValueError):
+            service.link_memories(
+                claim.memory_id,
+                MemoryLinkRequest(target_memory_id=claim.memory_id, link_type="supports"),
+            )
+        with pytest.raises(KeyError):
+            service.link_memories(
+                claim.memory_id,
+                MemoryLinkRequest(target_memory_id="missing", link_type="supports"),
+            )
+
+        plain = service.retrieve(RetrieveRequest(query="aisle seats", entity_id="alice", limit=1))
+        assert len(plain.memories) == 1
+        linked = service.retrieve(
+            RetrieveRequest(query="aisle seats", entity_id="alice", limit=1, include_linked=True)
+        )
+        assert len(linked.memories) == 2
+        top, neighbor = linked.memories
+        assert top.memory_id == plain.memories[0].memory_id
+        assert {top.memory_id, neighbor.memory_id} == {claim.memory_id, rebuttal.memory_id}
+        assert neighbor.metadata["linked"]["from"] == top.memory_id
+        assert neighbor.metadata["linked"]["link_type"] == "contradicts"
+        assert neighbor.metadata["linked"]["direction"] == (
+            "outgoing" if top.memory_id == claim.memory_id else "incoming"
+        )
+
+        service.delete_memory_link(rebuttal.memory_id, link.link_id)
+        assert service.memory_links(claim.memory_id).data == []
+        with pytest.raises(KeyError):
+            service.delete_memory_link(claim.memory_id, link.link_id)
+    finally:
+        service.close()
+
+
+def test_service_summarize_replaces_memories_with_a_cited_synthesis(
+    tmp_path: Path,
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    sent: list[Any] = []
+
+    def fake_call(target: Any, messages: list[dict[str, str]]) -> str:
+        sent.append((target, messages))
+        first_id = messages[1]["content"].split("Memories:\n[", 1)[1].split("]", 1)[0]
+        return f"Alice prefers aisle seats [{first_id}] and was upgraded [mem_invented]."
+
+    monkeypatch.setattr("orbit_api.service.call_summarizer", fake_call)
+    (tmp_path / "unconfigured").mkdir()
+    unconfigured = _service(tmp_path / "unconfigured")
+    try:
+        with pytest.raises(ValueError, match="ORBIT_SUMMARIZE_UPSTREAM_URL"):
+            unconfigured.retrieve(RetrieveRequest(query="seats", summarize=True))
+    finally:
+        unconfigured.close()
+
+    service = _service(
+        tmp_path,
+        chat_proxy_upstream_url="https://llm.acme.test/v1",
+        chat_proxy_upstream_api_key="upstream-key",
+        summarize_model="summary-model",
+    )
+    try:
+        service.ingest(IngestRequest(content="Alice prefers aisle seats", entity_id="alice"))
+        service.ingest(IngestRequest(content="Alice flies to Lisbon in May", entity_id="alice"))
+
+        result = service.retrieve(
+            RetrieveRequest(query="aisle seats", entity_id="alice", summarize=True)
+        )
+        target, messages = sent[0]
+        assert (target.base_url, target.api_key) == ("https://llm.acme.test/v1", "upstream-key")
+        assert "Query: aisle seats" in messages[1]["content"]
+        assert result.memories == []
+        assert result.summary is not None
+        assert result.summary.model == "summary-model"
+        assert len(result.summary.citations) == 1
+        assert f"[{result.summary.citations[0]}] Alice" in messages[1]["content"]
+        assert "[mem_invented]" in result.summary.text
+
+        assert service.retrieve(
+            RetrieveRequest(query="aisle seats", entity_id="nobody", summarize=True)
+        ).summary is None
+        assert len(sent) == 1
+    finally:
+        service.close()
+
+
+def test_service_summarize_stays_within_the_latency_budget(
+    tmp_path: Path,
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    sent: list[Any] = []
+
+    def fake_call(target: Any, messages: list[dict[str, str]]) -> str:
+        sent.append(target)
+        return "Alice prefers aisle seats."
+
+    monkeypatch.setattr("orbit_api.service.call_summarizer", fake_call)
+    service = _service(
+        tmp_path,
+        summarize_upstream_url="https://llm.acme.test/v1",
+        summarize_timeout_seconds=30.0,
+    )
+    try:
+        service.ingest(IngestRequest(content="Alice prefers aisle seats", entity_id="alice"))
+        budgeted = service.retrieve(
+            RetrieveRequest(
+                query="aisle seats",
+                entity_id="alice",
+                summarize=True,
+                max_latency_ms=5_000,
+            )
+        )
+        assert budgeted.summary is not None
+        assert sent[0].timeout_seconds <= 5.0
+
+        search = service._retrieve
+
+        def slow_search(*args: Any, **kwargs: Any) -> Any:
+            time.sleep(0.01)
+            return search(*args, **kwargs)
+
+        monkeypatch.setattr(service, "_retrieve", slow_search)
+        spent = service.retrieve(
+            RetrieveRequest(
+                query="aisle seats",
+                entity_id="alice",
+                summarize=True,
+                max_latency_ms=1,
+            )
+        )
+        assert spent.summary is None
+        assert spent.memories
+        assert spent.degraded
+        assert "summarize" in spent.skipped_stages
+        assert len(sent) == 1
+    finally:
+        service.close()
+
+
+def test_service_recall_returns_profile_memories_and_session(tmp_path: Path) -> None:
+    service = _service(tmp_path, working_memory_ttl_seconds=60)
+    try:
+        service.patch_entity_attributes(
+            "alice",
+            EntityAttributesPatchRequest(attributes={"timezone": "Europe/Dublin"}),
+            account_key="acct",
+        )
+        service.ingest(
+            IngestRequest(content="Alice prefers short answers", entity_id="alice"),
+            account_key="acct",
+        )
+        for content in ("Asked about invoices", "Wants a refund for March"):
+            service.remember_in_session(
+                "chat-1",
+                SessionMemoryRequest(content=content, entity_id="alice"),
+                account_key="acct",
+            )
+
+        result = service.recall(
+            RecallRequest(
+                query="how should I answer alice?",
+                entity_id="alice",
+                session_id="chat-1",
+                session_items=1,
+            ),
+            account_key="acct",
+        )
+
+        assert result.profile.attributes == {"timezone": "Europe/Dublin"}
+        assert any("short answers" in memory.content for memory in result.memories)
+        assert result.session is not None
+        assert result.session.item_count == 2
+        assert [item.content for item in result.session.recent] == ["Wants a refund for March"]
+        assert result.session.summary == "- Wants a refund for March"
+
+        no_session = service.recall(
+            RecallRequest(query="anything", entity_id="bob"),
+            account_key="acct",
+        )
+        assert no_session.session is None
+        assert no_session.profile.attributes == {}
+    finally:
+        service.close()
+
+
+def test_service_ask_answers_with_cited_memories_and_confidence(
+    tmp_path: Path,
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    replies: list[str] = []
+    sent: list[list[dict[str, str]]] = []
+
+    def fake_call(target: Any, messages: list[dict[str, str]]) -> str:
+        sent.append(messages)
+        return replies.pop(0)
+
+    monkeypatch.setattr("orbit_api.service.call_summarizer", fake_call)
+    service = _service(tmp_path, summarize_upstream_url="https://llm.acme.test/v1")
+    try:
+        seat = service.ingest(IngestRequest(content="Alice prefers aisle seats", entity_id="alice"))
+        service.ingest(IngestRequest(content="Alice flies to Lisbon in May", entity_id="alice"))
+
+        replies.append(
+            "```json\n"
+            + json.dumps({"answer": f"An aisle seat [{seat.memory_id}].", "confidence": 1.4})
+            + "\n```"
+        )
+        result = service.ask(AskRequest(question="Which seat does Alice like?", entity_id="alice"))
+        assert "Question: Which seat does Alice like?" in sent[0][1]["content"]
+        assert result.answer == f"An aisle seat [{seat.memory_id}]."
+        assert result.citations == [seat.memory_id]
+        assert [memory.memory_id for memory in result.memories] == [seat.memory_id]
+        assert result.confidence == 1.0
+
+        replies.append('{"answer": null, "confidence": 0.7}')
+        unknown = service.ask(AskRequest(question="What is Alice's budget?", entity_id="alice"))
+        assert (unknown.answer, unknown.citations, unknown.confidence) == (None, [], 0.0)
+
+        replies.append("Alice likes aisle seats.")
+        with pytest.raises(SummarizationError, match="not valid JSON"):
+            service.ask(AskRequest(question="Which seat?", entity_id="alice"))
+
+        empty = service.ask(AskRequest(question="Which seat?", entity_id="nobody"))
+        assert (empty.answer, empty.memories) == (None, [])
+        assert len(sent) == 3
+    finally:
+        service.close()
+
+
+def test_anonymize_query_redacts_identifiers() -> None:
+    assert anonymize_query("Email  bob@example.com about order 5512") == (
+        "email <email> about order <number>"
+    )
+    assert anonymize_query("See https://example.com/a?b=1 or call +1 (555) 010-9999") == (
+        "see <url> or call <number>"
+    )
+    assert percentile([40.0, 10.0, 30.0, 20.0], 0.5) == 20.0
+    assert percentile([], 0.95) == 0.0
+
+
+def test_service_query_analytics_reports_top_and_zero_result_queries(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        service.ingest(IngestRequest(content="Alice prefers aisle seats", entity_id="alice"))
+        for query in ("Seat for order 12", "seat for order 99"):
+            service.retrieve(RetrieveRequest(query=query, entity_id="alice"))
+        service.retrieve(RetrieveRequest(query="favourite seat", entity_id="alice"))
+        service.retrieve(RetrieveRequest(query="passport number", entity_id="nobody"))
+        # Query logs are written off the request path; wait for the single bookkeeping worker.
+        service._retrieval_bookkeeping_executor.submit(lambda: None).result()
+
+        report = service.query_analytics()
+        assert report.total_queries == 4
+        assert report.top_queries[0].query == "seat for order <number>"
+        assert report.top_queries[0].count == 2
+        assert [item.query for item in report.zero_result_queries] == ["passport number"]
+        assert report.zero_result_rate == 0.25
+        assert len(report.daily) == 1
+        assert report.daily[0].queries == 4
+        assert service.query_analytics(account_key="other").total_queries == 0
+    finally:
+        service.close()
+
+
+def test_service_zero_result_fallbacks(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        older = service.ingest(IngestRequest(content="Alice adopted a cat", entity_id="alice"))
+        newer = service.ingest(IngestRequest(content="Alice moved to Porto", entity_id="alice"))
+        service.patch_entity_attributes(
+            "alice",
+            EntityAttributesPatchRequest(attributes={"timezone": "Europe/Lisbon"}),
+        )
+
+        def retrieve(fallback: str | None = None) -> RetrieveResponse:
+            return service.retrieve(
+                RetrieveRequest(query="cat", entity_id="alice", min_score=1000.0, fallback=fallback)
+            )
+
+        empty = retrieve()
+        assert empty.memories == []
+        assert empty.fallback is None
+
+        recent = retrieve(fallback="recent")
+        assert recent.fallback == "recent"
+        assert [item.memory_id for item in recent.memories] == [
+            newer.memory_id,
+            older.memory_id,
+        ]
+        assert recent.memories[0].metadata["fallback"] == "recent"
+
+        profile = retrieve(fallback="attributes")
+        assert profile.memories == []
+        assert profile.attributes == {"timezone": "Europe/Lisbon"}
+
+        service.config.zero_result_fallback = "attributes"
+        assert retrieve().fallback == "attributes"
+        assert retrieve(fallback="webhook").fallback is None
+    finally:
+        service.close()
+
+
+def test_service_replicates_tenant_to_replica_region_and_routes_by_residency(
+    tmp_path: Path,
+) -> None:
+    (tmp_path / "eu").mkdir()
+    (tmp_path / "us").mkdir()
+    home = _service(tmp_path / "eu")
+    replica = _service(tmp_path / "us")
+    residency = TenantResidencyRequest(home_region="eu", replica_regions=["us"])
+    try:
+        for service, region, peer in ((home, "eu", "us"), (replica, "us", "eu")):
+            service.config.region = region
+            service.config.region_peers = {peer: f"https://{peer}.orbit.invalid"}
+            service.set_tenant_residency("acme", residency, propagate=False)
+        key = home.issue_api_key(account_key="acme", name="server")
+        kept = home.ingest(
+            IngestRequest(content="Alice prefers aisle seats", entity_id="alice"),
+            account_key="acme",
+        )
+        edited = home.ingest(
+            IngestRequest(content="Alice lives in Lisbon", entity_id="alice"),
+            account_key="acme",
+        )
+        home.update_memory(
+            edited.memory_id,
+            MemoryUpdateRequest(content="Alice lives in Porto"),
+            account_key="acme",
+        )
+
+        batch = home.replication_batch("acme")
+        assert replica.apply_replication_batch(batch) >= 3
+        assert replica.apply_replication_batch(batch) == 0
+        replicated = {
+            record.memory_id: record.content
+            for record in replica.list_memories(50, None, account_key="acme").data
+        }
+        assert replicated[kept.memory_id] == "Alice prefers aisle seats"
+        assert replicated[edited.memory_id] == "Alice lives in Porto"
+        assert replica.authenticate_api_key(key.key).subject == "acme"
+
+        assert replica.region_route("acme", method="GET").action == "local"
+        write_route = replica.region_route("acme", method="POST")
+        assert (write_route.action, write_route.region) == ("forward", "eu")
+        assert home.region_route("acme", method="POST").action == "local"
+        assert replica.region_route("other", method="POST").action == "local"
+
+        pinned = TenantResidencyRequest(home_region="eu", pinned=True)
+        replica.set_tenant_residency("acme", pinned, propagate=False)
+        read_route = replica.region_route("acme", method="GET")
+        assert (read_route.action, read_route.region) == ("reject", "eu")
+        with pytest.raises(ValueError, match="unknown regions"):
+            home.set_tenant_residency(
+                "acme",
+                TenantResidencyRequest(home_region="ap"),
+                propagate=False,
+            )
+    finally:
+        home.close()
+        replica.close()
+
+
+def test_service_optimize_compacts_tombstoned_vectors(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        kept = service.ingest(
+            IngestRequest(
+                content="Alice prefers aisle seats",
+                event_type="user_question",
+                entity_id="alice",
+            )
+        )
+        removed = service.ingest(
+            IngestRequest(
+                content="Alice is flying to Lisbon on Friday",
+                event_type="user_question",
+                entity_id="alice",
+            )
+        )
+        # Another instance deleted this memory, leaving its vector behind in this one.
+        service._engine.storage.delete_memories([removed.memory_id])
+        assert removed.memory_id in service._engine.vector_store.memory_ids()
+
+        job = service.run_optimize()
+
+        assert job.status == "succeeded"
+        assert job.progress == 1.0
+        assert [step.status for step in job.steps] == ["succeeded"] * 4
+        compaction = job.steps[0]
+        assert compaction.name == "vector_compaction"
+        assert compaction.detail["tombstones_removed"] == 1
+        assert job.steps[-1].detail["statements"] == ["vacuum", "analyze"]
+        vector_ids = service._engine.vector_store.memory_ids()
+        assert kept.memory_id in vector_ids
+        assert removed.memory_id not in vector_ids
+        assert service.optimize_job(job.job_id).finished_at is not None
+        assert service.list_optimize_jobs().jobs[0].job_id == job.job_id
+        with pytest.raises(KeyError):
+            service.optimize_job("opt_missing")
+    finally:
+        service.close()
+
+
+def test_service_index_deployment_mirrors_promotes_and_rolls_back(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+
+    def wait_for(check: Any) -> None:
+        deadline = time.monotonic() + 5.0
+        while not check():
+            assert time.monotonic() < deadline
+            time.sleep(0.01)
+
+    try:
+        for content in ("Alice prefers aisle seats", "Alice is flying to Lisbon on Friday"):
+            service.ingest(
+                IngestRequest(content=content, event_type="user_question", entity_id="alice")
+            )
+        with pytest.raises(ValueError, match="quantization"):
+            service.start_index_deployment(IndexDeploymentRequest(quantization="fp4"))
+
+        started = service.start_index_deployment(
+            IndexDeploymentRequest(
+                embedding_provider="deterministic",
+                chunk_chars=200,
+                mirror_percent=100.0,
+            )
+        )
+        deployment_id = started.deployment_id
+        with pytest.raises(IndexDeploymentConflictError, match="already"):
+            service.start_index_deployment(IndexDeploymentRequest())
+        wait_for(lambda: service.index_deployment(deployment_id).status == "ready")
+        late = service.ingest(
+            IngestRequest(content="Alice rents a car in Porto", event_type="user_question")
+        )
+        deployment = service.index_deployment(deployment_id)
+        assert deployment.built and deployment.indexed_memories == 3
+        assert service.list_index_deployments().serving == "baseline"
+
+        request = RetrieveRequest(query="Where is Alice flying?", limit=2)
+        production = service.retrieve(request)
+        wait_for(
+            lambda: service.index_deployment(deployment_id).comparison.mirrored_queries == 1
+        )
+        comparison = service.index_deployment(deployment_id).comparison
+        assert comparison.mean_overlap is not None and 0.0 <= comparison.mean_overlap <= 1.0
+        assert comparison.production_latency_ms == pytest.approx(
+            production.query_execution_time_ms, abs=0.001
+        )
+
+        promoted = service.promote_index_deployment(deployment_id)
+        assert (promoted.status, promoted.mirror_percent) == ("active", 0.0)
+        assert service.list_index_deployments().serving == deployment_id
+        served = service.retrieve(RetrieveRequest(query="car rental in Porto", limit=3))
+        assert late.memory_id in {memory.memory_id for memory in served.memories}
+
+        retired = service.retire_index_deployment(deployment_id)
+        assert retired.status == "retired" and retired.retired_at is not None
+        assert service.list_index_deployments().serving == "baseline"
+        with pytest.raises(KeyError):
+            service.index_deployment("idx_missing")
+    finally:
+        service.close()
+
+
+def test_service_ingest_summarizes_or_chunks_oversized_content(tmp_path: Path) -> None:
+    service = _service(
+        tmp_path,
+        max_ingest_content_chars=120,
+        oversize_summary_chars=80,
+        free_events_per_day=20,
+        free_events_per_month=20,
+    )
+    paste = " ".join(
+        [
+            "Alice said the billing export to Postgres timed out during the nightly run.",
+            "Her team moved the Kubernetes cluster from Austin to Berlin last quarter.",
+            "She prefers short answers with code examples written in Python or Go.",
+            "The mobile app crashes whenever SSO tokens expire while offline for days.",
+            "Support escalated three tickets about Terraform drift in staging accounts.",
+            "Onboarding new designers took two weeks because the Figma library moved.",
+        ]
+    )
+    try:
+        with pytest.raises(ValueError, match="ORBIT_MAX_INGEST_CONTENT_CHARS=120"):
+            service.ingest_with_quota(
+                account_key="acct",
+                request=IngestRequest(content=paste, event_type="user_question", entity_id="alice"),
+                idempotency_key=None,
+            )
+
+        summarized, _, _ = service.ingest_with_quota(
+            account_key="acct",
+            request=IngestRequest(
+                content=paste,
+                event_type="user_question",
+                entity_id="alice",
+                on_oversize="summarize",
+            ),
+            idempotency_key=None,
+        )
+        assert summarized.oversize_action == "summarize"
+        assert summarized.chunk_memory_ids == []
+        [record] = service._engine.storage.fetch_by_ids(
+            [summarized.memory_id], account_key="acct"
+        )
+        assert len(record.content) <= 80
+        assert "oversize:summarize" in record.relationships
+        assert record.content.startswith("Alice said the billing export")
+
+        chunked, snapshot, _ = service.ingest_with_quota(
+            account_key="acct",
+            request=IngestRequest(
+                content=paste,
+                event_type="user_question",
+                entity_id="alice",
+                on_oversize="chunk",
+            ),
+            idempotency_key=None,
+        )
+        assert chunked.oversize_action == "chunk"
+        assert len(chunked.chunk_memory_ids) == 6
+        assert chunked.memory_id == chunked.chunk_memory_ids[0]
+        # Every stored chunk counts against the event quota.
+        assert snapshot.remaining == 20 - 1 - 6
+        chunks = service._engine.storage.fetch_by_ids(chunked.chunk_memory_ids, account_key="acct")
+        assert all(len(chunk.content) <= 120 for chunk in chunks)
+        assert all("oversize:chunk" in chunk.relationships for chunk in chunks)
+        assert sorted(
+            relation
+            for chunk in chunks
+            for relation in chunk.relationships
+            if relation.startswith("chunk_index:")
+        ) == [f"chunk_index:{index}" for index in range(6)]
+    finally:
+        service.close()
+
+
+def test_service_chunked_documents_carry_citation_offsets_and_pages(tmp_path: Path) -> None:
+    service = _service(
+        tmp_path,
+        max_ingest_content_chars=120,
+        free_events_per_day=20,
+        free_events_per_month=20,
+    )
+    document = (
+        "Refunds are issued within 14 days of a return request.\n\n"
+        "Items must be unused and in their original packaging.\f"
+        "Shipping costs are refunded only when the item arrived damaged.\n\n"
+        "Gift cards and final-sale items cannot be returned for any reason."
+    )
+    try:
+        result, _, _ = service.ingest_with_quota(
+            account_key="acct",
+            request=IngestRequest(
+                content=document,
+                event_type="user_question",
+                entity_id="alice",
+                on_oversize="chunk",
+            ),
+            idempotency_key=None,
+        )
+        records = service._engine.storage.fetch_by_ids(result.chunk_memory_ids, account_key="acct")
+        by_id = {record.memory_id: record for record in records}
+        sources = [
+            service._as_memory(by_id[memory_id], 1, 1.0).metadata["source"]
+            for memory_id in result.chunk_memory_ids
+        ]
+        assert [source["chunk_index"] for source in sources] == list(range(len(sources)))
+        assert len({source["document_id"] for source in sources}) == 1
+        for memory_id, source in zip(result.chunk_memory_ids, sources, strict=True):
+            cited = document[source["start"] : source["end"]]
+            assert cited.split() == by_id[memory_id].content.split()
+        assert (sources[0]["page_start"], sources[0]["page_end"]) == (1, 1)
+        assert (sources[-1]["page_start"], sources[-1]["page_end"]) == (2, 2)
+
+        plain, _, _ = service.ingest_with_quota(
+            account_key="acct",
+            request=IngestRequest(content="I prefer aisle seats", entity_id="alice"),
+            idempotency_key=None,
+        )
+        [record] = service._engine.storage.fetch_by_ids([plain.memory_id], account_key="acct")
+        assert service._as_memory(record, 1, 1.0).metadata["source"] is None
+    finally:
+        service.close()
+
+
+def test_service_reuploaded_document_revises_changed_chunks_only(tmp_path: Path) -> None:
+    service = _service(
+        tmp_path,
+        max_ingest_content_chars=120,
+        free_events_per_day=20,
+        free_events_per_month=20,
+    )
+    paragraphs = [
+        "Refunds are issued within 14 days of a return request reaching our warehouse team.",
+        "Items must be unused and in their original packaging with every tag still attached.",
+        "Shipping costs are refunded only when the item arrived damaged or was not as described.",
+        "Gift cards and final-sale items cannot be returned for any reason, including defects.",
+    ]
+
+    def upload(parts: list[str]) -> IngestResponse:
+        result, _, _ = service.ingest_with_quota(
+            account_key="acct",
+            request=IngestRequest(
+                content="\n\n".join(parts),
+                event_type="user_question",
+                entity_id="alice",
+                on_oversize="chunk",
+            ),
+            idempotency_key=None,
+        )
+        return result
+
+    try:
+        first = upload(paragraphs)
+        assert first.revision is None
+        assert len(first.chunk_memory_ids) == 4
+
+        edited = "Shipping costs are refunded only when the item arrived damaged or broken."
+        added = "Store credit issued for a return never expires and works online too."
+        second = upload([*paragraphs[:2], edited, paragraphs[3], added])
+        assert second.revision is not None
+        assert (
+            second.revision.unchanged,
+            second.revision.updated,
+            second.revision.added,
+            second.revision.removed,
+        ) == (3, 1, 1, 0)
+        # Unchanged and edited chunks keep their memory ids.
+        assert second.chunk_memory_ids[:4] == first.chunk_memory_ids
+        records = {
+            record.memory_id: record
+            for record in service._engine.storage.fetch_by_ids(
+                second.chunk_memory_ids, account_key="acct"
+            )
+        }
+        assert records[second.chunk_memory_ids[2]].content == edited
+        versions = service.memory_versions(second.chunk_memory_ids[2], account_key="acct")
+        assert versions.current_version == 2
+        groups = {
+            relation
+            for record in records.values()
+            for relation in record.relationships
+            if relation.startswith("chunk_group:")
+        }
+        assert groups == {f"chunk_group:{second.revision.document_id}"}
+        assert all("chunk_count:5" in record.relationships for record in records.values())
+
+        third = upload(paragraphs[:2])
+        assert third.revision is not None
+        assert third.revision.removed == 3
+        remaining = service._engine.storage.fetch_by_ids(
+            second.chunk_memory_ids, account_key="acct"
+        )
+        assert sorted(record.memory_id for record in remaining) == sorted(
+            first.chunk_memory_ids[:2]
+        )
+    finally:
+        service.close()
+
+
+def test_service_recrawls_url_sources_and_flags_stale_memories(
+    tmp_path: Path, monkeypatch: pytest.MonkeyPatch
+) -> None:
+    fixed = FixedClock(datetime(2026, 6, 1, tzinfo=UTC))
+    service = _service(
+        tmp_path,
+        clock_source=fixed,
+        max_ingest_content_chars=120,
+        free_events_per_day=20,
+        free_events_per_month=20,
+        free_queries_per_day=5,
+        free_queries_per_month=5,
+    )
+    paragraphs = [
+        "Refunds are issued within 14 days of a return request reaching our warehouse team.",
+        "Items must be unused and in their original packaging with every tag still attached.",
+        "Gift cards and final-sale items cannot be returned for any reason, including defects.",
+    ]
+    page = {"html": "<html><head><title>Returns</title><script>track()</script></head><body>"}
+    page["html"] += "".join(f"<p>{item}</p>" for item in paragraphs) + "</body></html>"
+
+    def fake_fetch(url: str, **_: Any) -> FetchedPage:
+        if page["html"] == "":
+            raise UrlFetchError(f"{url} returned HTTP 503")
+        text = extract_text(page["html"].encode("utf-8"), "text/html; charset=utf-8")
+        return FetchedPage(url=url, status_code=200, content_type="text/html", text=text)
+
+    monkeypatch.setattr("orbit_api.service.fetch_url", fake_fetch)
+    try:
+        source = service.create_url_source(
+            UrlSourceRequest(url="https://shop.example.com/returns", entity_id="support"),
+            account_key="acct",
+        )
+        assert source.last_status == "updated"
+        assert source.interval_hours == 24.0
+        assert source.next_crawl_at == fixed.now() + timedelta(hours=24)
+        assert len(source.memory_ids) == 3
+        records = service._engine.storage.fetch_by_ids(source.memory_ids, account_key="acct")
+        assert sorted(record.content for record in records) == sorted(paragraphs)
+        assert all(f"url_source:{source.source_id}" in item.relationships for item in records)
+
+        # Nothing is due yet; once it is, an unchanged page only refreshes the crawl time.
+        assert service.run_due_crawls() == []
+        fixed.advance(timedelta(hours=25))
+        [unchanged] = service.run_due_crawls()
+        assert unchanged.last_status == "unchanged"
+        assert unchanged.memory_ids == source.memory_ids
+        assert unchanged.last_crawled_at == fixed.now()
+
+        edited = "Items must be unused, in their original packaging, and returned with the receipt."
+        page["html"] = page["html"].replace(paragraphs[1], edited)
+        updated = service.crawl_url_source(source.source_id, account_key="acct")
+        assert updated.last_status == "updated"
+        assert updated.content_hash != source.content_hash
+        # The edited chunk is revised in place, so every memory id survives.
+        assert updated.memory_ids == source.memory_ids
+        contents = {
+            record.memory_id: record.content
+            for record in service._engine.storage.fetch_by_ids(
+                updated.memory_ids, account_key="acct"
+            )
+        }
+        assert contents[updated.memory_ids[1]] == edited
+
+        page["html"] = ""
+        fixed.advance(timedelta(hours=73))
+        failed = service.crawl_url_source(source.source_id, account_key="acct")
+        assert failed.last_status == "failed"
+        assert failed.last_error == "https://shop.example.com/returns returned HTTP 503"
+        assert failed.stale is True
+        assert failed.memory_ids == updated.memory_ids
+
+        retrieved = service.retrieve(
+            RetrieveRequest(query="refund gift cards", entity_id="support", limit=5),
+            account_key="acct",
+        )
+        assert retrieved.memories
+        for memory in retrieved.memories:
+            assert memory.metadata["stale"] is True
+            assert memory.metadata["url_source"]["source_id"] == source.source_id
+
+        with pytest.raises(KeyError):
+            service.crawl_url_source(source.source_id, account_key="other")
+        service.delete_url_source(source.source_id, account_key="acct")
+        assert service.list_url_sources(account_key="acct").data == []
+    finally:
+        service.close()
+
+
+def test_service_crawl_keeps_previous_memories_when_nothing_is_stored(
+    tmp_path: Path,
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    service = _service(tmp_path)
+    page = {"text": "Refunds are issued within 14 days of a return request."}
+
+    def fake_fetch(url: str, **_: Any) -> FetchedPage:
+        return FetchedPage(url=url, status_code=200, content_type="text/plain", text=page["text"])
+
+    monkeypatch.setattr("orbit_api.service.fetch_url", fake_fetch)
+    try:
+        source = service.create_url_source(
+            UrlSourceRequest(url="https://shop.example.com/returns", entity_id="support"),
+            account_key="acct",
+        )
+        assert source.last_status == "updated"
+
+        def rejected(**_: Any) -> tuple[IngestResponse, Any, Any]:
+            response = IngestResponse(
+                memory_id="mem_rejected",
+                stored=False,
+                importance_score=0.0,
+                decision_reason="below the storage threshold",
+                encoded_at=datetime.now(UTC),
+                latency_ms=0.0,
+            )
+            return response, None, None
+
+        monkeypatch.setattr(service, "ingest_with_quota", rejected)
+        page["text"] = "Refunds are no longer offered."
+        failed = service.crawl_url_source(source.source_id, account_key="acct")
+        assert failed.last_status == "failed"
+        assert failed.last_error == "page was not stored: below the storage threshold"
+        assert failed.memory_ids == source.memory_ids
+        assert failed.content_hash == source.content_hash
+        kept = service._engine.storage.fetch_by_ids(source.memory_ids, account_key="acct")
+        assert [record.content for record in kept] == [
+            "Refunds are issued within 14 days of a return request."
+        ]
+    finally:
+        service.close()
+
+
+def test_fetch_url_connects_to_the_address_it_checked(monkeypatch: pytest.MonkeyPatch) -> None:
+    # The first answer passes the check; a rebinding resolver would answer loopback next.
+    answers = ["93.184.216.34", "127.0.0.1"]
+    sent: list[httpx.Request] = []
+
+    def fake_getaddrinfo(host: str, port: Any, **_: Any) -> list[Any]:
+        return [(socket.AF_INET, socket.SOCK_STREAM, 6, "", (answers.pop(0), 443))]
+
+    def fake_send(self: httpx.HTTPTransport, request: httpx.Request) -> httpx.Response:
+        sent.append(request)
+        return httpx.Response(200, headers={"content-type": "text/plain"}, text="Refunds")
+
+    monkeypatch.setattr("orbit_api.url_sources.socket.getaddrinfo", fake_getaddrinfo)
+    monkeypatch.setattr(httpx.HTTPTransport, "handle_request", fake_send)
+
+    page = fetch_url("https://shop.example.com/returns")
+    assert page.text == "Refunds"
+    [request] = sent
+    assert request.url.host == "93.184.216.34"
+    assert request.headers["host"] == "shop.example.com"
+    assert request.extensions["sni_hostname"] == "shop.example.com"
+    assert answers == ["127.0.0.1"]
+
+    policy = OutboundPolicy(allowed_hosts=("*.example.org",))
+    with pytest.raises(UrlFetchError, match="ORBIT_OUTBOUND_ALLOWED_HOSTS"):
+        fetch_url("https://shop.example.com/returns", policy=policy)
+    assert policy.allows("docs.example.org")
+    assert not policy.allows("example.org.evil.test")
+
+
+def test_service_applies_namespace_retrieve_defaults_under_request_options(
+    tmp_path: Path,
+) -> None:
+    service = _service(tmp_path)
+    try:
+        with pytest.raises(ValueError, match="profile must be one of"):
+            RetrieveDefaults(profile="exhaustive")
+        namespace = service.set_namespace(
+            "acct",
+            NamespaceRequest(
+                retrieve_defaults=RetrieveDefaults(
+                    limit=5, min_score=0.2, profile="Thorough", event_type="support_ticket"
+                )
+            ),
+        )
+        assert namespace.retrieve_defaults is not None
+        assert namespace.retrieve_defaults.profile == "thorough"
+        assert service.namespace("acct").retrieve_defaults == namespace.retrieve_defaults
+
+        defaulted = service.apply_retrieve_defaults(RetrieveRequest(query="x"), account_key="acct")
+        assert (defaulted.limit, defaulted.min_score, defaulted.event_type) == (
+            5,
+            0.2,
+            "support_ticket",
+        )
+        assert (defaulted.mode, defaulted.graph_hops, defaulted.include_linked) == (
+            "graph",
+            2,
+            True,
+        )
+
+        # The request's own options, and its profile, win over the namespace's.
+        overridden = service.apply_retrieve_defaults(
+            RetrieveRequest(query="x", limit=3, profile="fast", event_type="user_fact"),
+            account_key="acct",
+        )
+        assert (overridden.limit, overridden.event_type, overridden.fast) == (3, "user_fact", True)
+        assert (overridden.mode, overridden.include_linked) == ("vector", False)
+        with pytest.raises(ValueError, match="fast cannot be combined"):
+            service.apply_retrieve_defaults(
+                RetrieveRequest(query="x", profile="fast", summarize=True), account_key="acct"
+            )
+
+        untouched = RetrieveRequest(query="x")
+        assert service.apply_retrieve_defaults(untouched, account_key="other") is untouched
+
+        service.ingest(
+            IngestRequest(content="Printer jams on tray 2", event_type="support_ticket"),
+            account_key="acct",
+        )
+        result = service.retrieve(
+            RetrieveRequest(query="printer tray", profile="balanced"), account_key="acct"
+        )
+        assert result.applied_filters["event_type"] == "support_ticket"
+        assert result.applied_filters["profile"] == "balanced"
+        assert len(result.memories) <= 5
+    finally:
+        service.close()
+
+
+def test_service_runs_saved_queries_by_name_with_template_params(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        with pytest.raises(ValueError, match="plain names"):
+            service.set_saved_query(
+                "bad", SavedQueryRequest(query_template="{user.__class__}"), account_key="acct"
+            )
+        with pytest.raises(ValueError, match="saved query name"):
+            service.set_saved_query(
+                "Bad Name", SavedQueryRequest(query_template="x"), account_key="acct"
+            )
+        saved = service.set_saved_query(
+            "open-tickets",
+            SavedQueryRequest(
+                query_template="{product} issues {{urgent}} about {product} {area}",
+                options=RetrieveDefaults(limit=4, profile="balanced", event_type="support_ticket"),
+            ),
+            account_key="acct",
+        )
+        assert saved.parameters == ["product", "area"]
+        assert [item.name for item in service.list_saved_queries(account_key="acct").data] == [
+            "open-tickets"
+        ]
+        assert service.list_saved_queries(account_key="other").data == []
+
+        service.ingest(
+            IngestRequest(content="Printer jams on tray 2", event_type="support_ticket"),
+            account_key="acct",
+        )
+        # Saved options win over the namespace's defaults.
+        service.set_namespace(
+            "acct", NamespaceRequest(retrieve_defaults=RetrieveDefaults(event_type="user_fact"))
+        )
+        result = service.run_saved_query(
+            "open-tickets",
+            SavedQueryRunRequest(params={"product": "printer", "area": "tray"}, limit=2),
+            account_key="acct",
+        )
+        assert result.applied_filters["event_type"] == "support_ticket"
+        assert result.applied_filters["profile"] == "balanced"
+        assert len(result.memories) <= 2
+
+        with pytest.raises(ValueError, match="missing saved query parameter"):
+            service.run_saved_query(
+                "open-tickets", SavedQueryRunRequest(params={"product": "x"}), account_key="acct"
+            )
+        with pytest.raises(ValueError, match="unknown saved query parameter"):
+            service.run_saved_query(
+                "open-tickets",
+                SavedQueryRunRequest(params={"product": "x", "area": "y", "extra": "z"}),
+                account_key="acct",
+            )
+        with pytest.raises(KeyError):
+            service.run_saved_query("open-tickets", SavedQueryRunRequest(), account_key="other")
+
+        assert service.delete_saved_query("open-tickets", account_key="acct").name == "open-tickets"
+        with pytest.raises(KeyError):
+            service.saved_query("open-tickets", account_key="acct")
+    finally:
+        service.close()
@@ -0,0 +1,64 @@
+from __future__ import annotations
+
+import threading
+
+import pytest
+
+from orbit_api.bench import BenchConfig, run_bench
+
+
+class _RecordingTarget:
+    def __init__(self) -> None:
+        self._lock = threading.Lock()
+        self.ingested: list[tuple[str, str, str]] = []
+        self.queries: list[tuple[str, str, int]] = []
+
+    def ingest(self, *, entity_id: str, content: str, event_type: str) -> None:
+        with self._lock:
+            self.ingested.append((entity_id, content, event_type))
+
+    def retrieve(self, *, entity_id: str, query: str, limit: int) -> None:
+        if query.startswith("Where"):
+            msg = "simulated outage"
+            raise RuntimeError(msg)
+        with self._lock:
+            self.queries.append((entity_id, query, limit))
+
+
+def test_bench_seeds_entities_and_reports_mixed_workload_percentiles() -> None:
+    target = _RecordingTarget()
+    config = BenchConfig(
+        entities=5,
+        memories_per_entity=3,
+        operations=200,
+        concurrency=4,
+        read_ratio=0.75,
+        limit=7,
+        seed=11,
+    )
+
+    report = run_bench(target, config)
+
+    seed_entities = [entity_id for entity_id, _, _ in target.ingested[:15]]
+    assert sorted(set(seed_entities)) == [f"bench-user-{index:05d}" for index in range(5)]
+    assert all(seed_entities.count(entity_id) == 3 for entity_id in set(seed_entities))
+    assert all(limit == 7 for _, _, limit in target.queries)
+
+    by_key = {(item["phase"], item["operation"]): item for item in report.operations}
+    assert by_key[("seed", "ingest")]["count"] == 15
+    retrieve = by_key[("mixed", "retrieve")]
+    mixed_ingest = by_key[("mixed", "ingest")]
+    assert retrieve["count"] + retrieve["errors"] + mixed_ingest["count"] == 200
+    assert retrieve["error_types"] == {"RuntimeError": retrieve["errors"]}
+    assert 0 <= retrieve["p50_ms"] <= retrieve["p99_ms"] <= retrieve["max_ms"]
+    assert "p95 ms" in report.format_table()
+
+    replay = _RecordingTarget()
+    run_bench(replay, config)
+    assert sorted(replay.ingested) == sorted(target.ingested)
+    assert sorted(replay.queries) == sorted(target.queries)
+
+
+def test_bench_config_rejects_invalid_read_ratio() -> None:
+    with pytest.raises(ValueError, match="read ratio"):
+        BenchConfig(read_ratio=1.5)
@@ -0,0 +1,70 @@
+from __future__ import annotations
+
+from typing import Any
+
+import pytest
+
+from orbit_api import cli, migrations
+
+
+def test_orbit_migrate_upgrades_to_head_by_default(monkeypatch: pytest.MonkeyPatch) -> None:
+    calls: list[tuple[str, Any, Any]] = []
+    monkeypatch.setattr(
+        migrations.command,
+        "upgrade",
+        lambda config, revision, sql=False: calls.append(
+            ("upgrade", revision, config.get_main_option("sqlalchemy.url"))
+        ),
+    )
+    assert cli.main(["migrate", "--database-url", "sqlite:///cli.db"]) == 0
+    assert calls == [("upgrade", "head", "sqlite:///cli.db")]
+
+
+def test_orbit_migrate_downgrade_requires_revision(monkeypatch: pytest.MonkeyPatch) -> None:
+    calls: list[str] = []
+    monkeypatch.setattr(
+        migrations.command,
+        "downgrade",
+        lambda config, revision, sql=False: calls.append(revision),
+    )
+    with pytest.raises(SystemExit):
+        cli.main(["migrate", "--downgrade"])
+    assert cli.main(["migrate", "--downgrade", "--revision", "20260225_0007"]) == 0
+    assert calls == ["20260225_0007"]
+
+
+def test_orbit_migrate_current(monkeypatch: pytest.MonkeyPatch) -> None:
+    calls: list[bool] = []
+    monkeypatch.setattr(
+        migrations.command,
+        "current",
+        lambda config, verbose=False: calls.append(verbose),
+    )
+    assert cli.main(["migrate", "--current"]) == 0
+    assert calls == [True]
+
+
+def test_orbit_cli_resolves_secret_defaults_only_after_parsing(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    looked_up: list[str] = []
+    handled: list[Any] = []
+
+    def fake_secret(name: str, default: str | None = None) -> str:
+        looked_up.append(name)
+        return "resolved-token"
+
+    monkeypatch.setattr(cli, "get_secret", fake_secret)
+    monkeypatch.setattr(cli, "_run_connect_discord", handled.append)
+
+    with pytest.raises(SystemExit):
+        cli.main(["connect", "discord", "--help"])
+    assert looked_up == []
+
+    assert cli.main(["connect", "discord"]) == 0
+    assert looked_up == ["ORBIT_DISCORD_BOT_TOKEN"]
+    assert handled[0].token == "resolved-token"
+
+    assert cli.main(["connect", "discord", "--token", "explicit"]) == 0
+    assert looked_up == ["ORBIT_DISCORD_BOT_TOKEN"]
+    assert handled[1].token == "explicit"
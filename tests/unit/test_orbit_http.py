@@ -1,13 +1,25 @@
 from __future__ import annotations
 
 import asyncio
+import threading
+import time
 
 import httpx
 import pytest
 
-from orbit.config import Config
-from orbit.exceptions import OrbitAuthError, OrbitRateLimitError
+from orbit.config import Config, Endpoint
+from orbit.endpoints import EndpointPool
+from orbit.hedging import MIN_LATENCY_SAMPLES, LatencyTracker
+from orbit.exceptions import OrbitAuthError, OrbitNotFoundError, OrbitRateLimitError
 from orbit.http import AsyncOrbitHttpClient, OrbitHttpClient
+from orbit.tracing import trace_context
+from orbit.signing import (
+    NONCE_HEADER,
+    TIMESTAMP_HEADER,
+    canonical_request,
+    compute_signature,
+    parse_authorization,
+)
 
 
 def test_sync_http_maps_auth_error() -> None:
@@ -75,3 +87,199 @@ def test_async_http_maps_rate_limit_error() -> None:
             await client.aclose()
 
     asyncio.run(_run())
+
+
+def test_sync_http_signs_requests_instead_of_bearer_token() -> None:
+    seen: list[httpx.Request] = []
+
+    def handler(request: httpx.Request) -> httpx.Response:
+        seen.append(request)
+        return httpx.Response(status_code=200, json={"ok": True})
+
+    client = OrbitHttpClient(
+        config=Config(signing_key_id="backend-1", signing_secret="s" * 24),
+        transport=httpx.MockTransport(handler),
+    )
+    try:
+        client.post("/v1/ingest", json_body={"content": "hello"})
+    finally:
+        client.close()
+
+    request = seen[0]
+    parsed = parse_authorization(request.headers["Authorization"])
+    assert parsed is not None
+    key_id, signature = parsed
+    assert key_id == "backend-1"
+    expected = compute_signature(
+        "s" * 24,
+        canonical_request(
+            method="POST",
+            path="/v1/ingest",
+            query="",
+            timestamp=request.headers[TIMESTAMP_HEADER],
+            nonce=request.headers[NONCE_HEADER],
+            body=request.content,
+        ),
+    )
+    assert signature == expected
+
+
+def test_sync_http_sends_trace_headers_and_reports_request_id() -> None:
+    traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+    seen: list[httpx.Headers] = []
+
+    def handler(request: httpx.Request) -> httpx.Response:
+        seen.append(request.headers)
+        request_id = request.headers.get("X-Request-ID", "generated")
+        status_code = 404 if request.url.path == "/v1/missing" else 200
+        return httpx.Response(status_code, json={}, headers={"X-Request-ID": request_id})
+
+    client = OrbitHttpClient(
+        config=Config(api_key="orbit_pk_aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"),
+        transport=httpx.MockTransport(handler),
+        trace_context_provider=lambda: {"traceparent": traceparent, "tracestate": "vendor=1"},
+    )
+    try:
+        with trace_context(request_id="req-1"):
+            client.get("/v1/status")
+            with trace_context(request_id="req-2"), pytest.raises(OrbitNotFoundError) as error:
+                client.get("/v1/missing")
+        assert error.value.request_id == "req-2"
+        assert client.last_request_id == "req-2"
+        client.get("/v1/status")
+        assert client.last_request_id == "generated"
+    finally:
+        client.close()
+    assert seen[0]["X-Request-ID"] == "req-1"
+    assert (seen[0]["traceparent"], seen[0]["tracestate"]) == (traceparent, "vendor=1")
+    assert "X-Request-ID" not in seen[2] and seen[2]["traceparent"] == traceparent
+
+
+def test_sync_http_fails_over_between_endpoints_and_probes_recovery() -> None:
+    primary_up = {"value": False}
+    seen: list[tuple[str, str, str]] = []
+
+    def handler(request: httpx.Request) -> httpx.Response:
+        host = request.url.host
+        seen.append((host, request.url.path, request.headers["Authorization"]))
+        if host == "us.orbit.test" and not primary_up["value"]:
+            return httpx.Response(status_code=503, json={"detail": "unavailable"})
+        return httpx.Response(status_code=200, json={"host": host})
+
+    client = OrbitHttpClient(
+        config=Config(
+            api_key="orbit_pk_aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+            endpoints=[
+                Endpoint(base_url="https://us.orbit.test", region="us"),
+                Endpoint(base_url="https://eu.orbit.test", api_key="orbit_pk_eu", region="eu"),
+            ],
+            max_retries=0,
+            endpoint_cooldown_seconds=0.05,
+        ),
+        transport=httpx.MockTransport(handler),
+    )
+    try:
+        assert client.get("/v1/status") == {"host": "eu.orbit.test"}
+        assert client.get("/v1/status") == {"host": "eu.orbit.test"}
+        assert [entry.healthy for entry in client.endpoint_health()] == [False, True]
+
+        primary_up["value"] = True
+        time.sleep(0.06)
+        assert client.get("/v1/status") == {"host": "us.orbit.test"}
+        assert all(entry.healthy for entry in client.endpoint_health())
+    finally:
+        client.close()
+    assert [(host, path) for host, path, _ in seen] == [
+        ("us.orbit.test", "/v1/status"),
+        ("eu.orbit.test", "/v1/status"),
+        ("eu.orbit.test", "/v1/status"),
+        ("us.orbit.test", "/v1/health"),
+        ("us.orbit.test", "/v1/status"),
+    ]
+    assert seen[0][2] == "Bearer orbit_pk_aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+    assert seen[1][2] == "Bearer orbit_pk_eu"
+
+
+def test_endpoint_pool_latency_routing_prefers_fastest_healthy_endpoint() -> None:
+    now = {"value": 0.0}
+    pool = EndpointPool(
+        Config(
+            endpoints=[Endpoint(base_url=f"https://{region}.orbit.test") for region in "abc"],
+            routing="latency",
+        ),
+        clock=lambda: now["value"],
+    )
+    assert [pool.select(), pool.select(exclude={0})] == [0, 1]
+    pool.record_success(0, 0.120)
+    pool.record_success(1, 0.030)
+    assert pool.select() == 2
+    pool.record_success(2, 0.080)
+    assert pool.select() == 1
+
+    pool.record_failure(1)
+    assert pool.select() == 2
+    assert pool.claim_probes() == []
+    now["value"] = 31.0
+    assert pool.claim_probes() == [1]
+    assert pool.claim_probes() == []
+
+
+def test_sync_hedged_get_returns_the_faster_duplicate() -> None:
+    lock = threading.Lock()
+    calls = {"count": 0}
+
+    def handler(_request: httpx.Request) -> httpx.Response:
+        with lock:
+            calls["count"] += 1
+            copy = calls["count"]
+        if copy == 1:
+            time.sleep(0.5)
+        return httpx.Response(status_code=200, json={"copy": copy})
+
+    client = OrbitHttpClient(
+        config=Config(api_key="orbit_pk_aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", hedge_delay_ms=20),
+        transport=httpx.MockTransport(handler),
+    )
+    try:
+        started = time.monotonic()
+        assert client.hedged_get("/v1/retrieve", {"query": "tea"}, hedge=True) == {"copy": 2}
+        assert time.monotonic() - started < 0.4
+        assert client.hedged_get("/v1/retrieve", {"query": "tea"}, hedge=False) == {"copy": 3}
+        assert calls["count"] == 3
+    finally:
+        client.close()
+
+
+def test_async_hedged_get_waits_for_p95_before_hedging() -> None:
+    calls = {"count": 0}
+
+    async def handler(_request: httpx.Request) -> httpx.Response:
+        calls["count"] += 1
+        copy = calls["count"]
+        if copy == MIN_LATENCY_SAMPLES + 1:
+            await asyncio.sleep(0.5)
+        return httpx.Response(status_code=200, json={"copy": copy})
+
+    async def run() -> None:
+        client = AsyncOrbitHttpClient(
+            config=Config(api_key="orbit_pk_aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"),
+            transport=httpx.MockTransport(handler),
+        )
+        try:
+            # No hedging until enough latency samples exist to estimate p95.
+            for _ in range(MIN_LATENCY_SAMPLES):
+                await client.hedged_get("/v1/retrieve", hedge=True)
+            assert calls["count"] == MIN_LATENCY_SAMPLES
+            payload = await client.hedged_get("/v1/retrieve", hedge=True)
+            assert payload == {"copy": MIN_LATENCY_SAMPLES + 2}
+        finally:
+            await client.aclose()
+
+    asyncio.run(run())
+
+    latencies = LatencyTracker()
+    for millis in range(1, MIN_LATENCY_SAMPLES):
+        latencies.record(millis / 1000.0)
+    assert latencies.percentile(0.95) is None
+    latencies.record(0.020)
+    assert latencies.percentile(0.95) == 0.019
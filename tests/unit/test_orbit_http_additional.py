@@ -9,6 +9,7 @@ from orbit.config import Config
 from orbit.exceptions import (
     OrbitAuthError,
     OrbitNotFoundError,
+    OrbitPreconditionFailedError,
     OrbitServerError,
     OrbitTimeoutError,
     OrbitValidationError,
@@ -36,6 +37,40 @@ def test_http_client_maps_validation_error() -> None:
         client.close()
 
 
+def test_http_client_exposes_field_errors_on_422() -> None:
+    field_error = {
+        "field": "content",
+        "location": "body",
+        "constraint": "max_length",
+        "message": "content exceeds ORBIT_MAX_INGEST_CONTENT_CHARS=10",
+        "limit": 10,
+    }
+
+    def handler(_request: httpx.Request) -> httpx.Response:
+        return httpx.Response(
+            status_code=422,
+            json={
+                "detail": {
+                    "message": "content: content exceeds ORBIT_MAX_INGEST_CONTENT_CHARS=10",
+                    "error_code": "validation_error",
+                    "errors": [field_error],
+                }
+            },
+        )
+
+    client = OrbitHttpClient(
+        config=Config(api_key="orbit_pk_aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"),
+        transport=httpx.MockTransport(handler),
+    )
+    try:
+        with pytest.raises(OrbitValidationError) as exc_info:
+            client.post("/v1/ingest", json_body={"content": "x" * 11})
+    finally:
+        client.close()
+    assert exc_info.value.errors == [field_error]
+    assert "ORBIT_MAX_INGEST_CONTENT_CHARS" in str(exc_info.value)
+
+
 def test_http_client_maps_not_found_error() -> None:
     def handler(_request: httpx.Request) -> httpx.Response:
         return httpx.Response(status_code=404, json={"detail": "missing"})
@@ -51,6 +86,27 @@ def test_http_client_maps_not_found_error() -> None:
         client.close()
 
 
+def test_http_client_sends_if_match_and_maps_precondition_failed() -> None:
+    def handler(request: httpx.Request) -> httpx.Response:
+        assert request.headers["if-match"] == '"2"'
+        return httpx.Response(status_code=412, json={"detail": "resource was modified"})
+
+    client = OrbitHttpClient(
+        config=Config(api_key="orbit_pk_aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"),
+        transport=httpx.MockTransport(handler),
+    )
+    try:
+        with pytest.raises(OrbitPreconditionFailedError, match="resource was modified"):
+            client.request(
+                "PATCH",
+                "/v1/memories/mem_1",
+                json_body={"content": "x"},
+                headers={"If-Match": '"2"'},
+            )
+    finally:
+        client.close()
+
+
 def test_http_client_timeout_error() -> None:
     def handler(_request: httpx.Request) -> httpx.Response:
         raise httpx.ReadTimeout("timeout")
@@ -0,0 +1,124 @@
+from __future__ import annotations
+
+import asyncio
+from datetime import UTC, datetime
+from typing import Any
+
+import httpx
+
+from orbit import (
+    AsyncMemoryEngine,
+    AsyncShadowReadEngine,
+    Config,
+    MemoryEngine,
+    ShadowDiff,
+    ShadowReadEngine,
+)
+
+_KEY = "orbit_pk_aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+
+
+def _handler(contents: list[str], seen: list[str]) -> Any:
+    now = datetime.now(UTC).isoformat()
+
+    def handler(request: httpx.Request) -> httpx.Response:
+        seen.append(str(request.url.params))
+        if request.url.path == "/v1/ingest":
+            return httpx.Response(
+                status_code=201,
+                json={
+                    "memory_id": "mem_1",
+                    "stored": True,
+                    "importance_score": 0.9,
+                    "decision_reason": "high relevance",
+                    "encoded_at": now,
+                    "latency_ms": 1.0,
+                },
+            )
+        if not contents:
+            return httpx.Response(status_code=500, json={"detail": "shadow down"})
+        memories = [
+            {
+                "memory_id": f"mem_{len(seen)}_{position}",
+                "content": content,
+                "rank_position": position,
+                "rank_score": 1.0 / position,
+                "importance_score": 0.5,
+                "timestamp": now,
+                "metadata": {},
+                "relevance_explanation": "test",
+            }
+            for position, content in enumerate(contents, start=1)
+        ]
+        return httpx.Response(
+            status_code=200,
+            json={"memories": memories, "total_candidates": 3, "query_execution_time_ms": 1.0},
+        )
+
+    return handler
+
+
+def test_shadow_read_engine_returns_primary_and_diffs_shadow_in_background() -> None:
+    primary_calls: list[str] = []
+    shadow_calls: list[str] = []
+    diffs: list[ShadowDiff] = []
+    primary = MemoryEngine(
+        config=Config(api_key=_KEY, max_retries=0),
+        transport=httpx.MockTransport(
+            _handler(["Alice likes tea", "Alice lives in Porto", "Bob"], primary_calls)
+        ),
+    )
+    shadow = MemoryEngine(
+        config=Config(api_key=_KEY, base_url="https://shadow.local", max_retries=0),
+        transport=httpx.MockTransport(
+            _handler(["alice  lives in porto", "Alice likes tea", "Carol"], shadow_calls)
+        ),
+    )
+    engine = ShadowReadEngine(
+        primary, shadow, shadow_params={"mode": "graph"}, on_diff=diffs.append
+    )
+    try:
+        response = engine.retrieve("where does alice live", limit=3)
+        assert [memory.content for memory in response.memories][0] == "Alice likes tea"
+        assert engine.ingest("Alice moved").memory_id == "mem_1"
+        assert engine.flush(timeout=5.0)
+
+        (diff,) = diffs
+        assert diff.overlap == 2 / 3 and not diff.top_result_match
+        assert (diff.only_in_primary, diff.only_in_shadow) == (["bob"], ["carol"])
+        assert [(item.primary_position, item.shadow_position) for item in diff.rank_changes] == [
+            (1, 2),
+            (2, 1),
+        ]
+        assert "mode=graph" in shadow_calls[0] and "mode" not in primary_calls[0]
+        # Writes go to the primary only.
+        assert len(shadow_calls) == 1
+        report = engine.report()
+        assert (report.compared, report.identical, report.failed) == (1, 0, 0)
+        assert report.mean_overlap == 0.6667
+    finally:
+        engine.close()
+        primary.close()
+        shadow.close()
+
+
+def test_async_shadow_read_engine_counts_shadow_failures() -> None:
+    async def run() -> None:
+        primary = AsyncMemoryEngine(
+            config=Config(api_key=_KEY, max_retries=0),
+            transport=httpx.MockTransport(_handler(["Alice likes tea"], [])),
+        )
+        shadow = AsyncMemoryEngine(
+            config=Config(api_key=_KEY, max_retries=0),
+            transport=httpx.MockTransport(_handler([], [])),
+        )
+        async with AsyncShadowReadEngine(primary, shadow, match_on="memory_id") as engine:
+            response = await engine.retrieve("tea")
+            assert response.memories[0].content == "Alice likes tea"
+            await engine.flush()
+            report = engine.report()
+            assert (report.compared, report.failed, report.mean_overlap) == (0, 1, None)
+        await primary.aclose()
+        await shadow.aclose()
+
+    asyncio.run(run())
@@ -1,5 +1,7 @@
 from __future__ import annotations
 
+from concurrent.futures import ThreadPoolExecutor
+
 import numpy as np
 import pytest
 
@@ -88,3 +90,46 @@ def test_optional_semantic_embedding_adapters_raise_when_modules_absent(
         adapters.OllamaSemanticProvider()
     with pytest.raises(RuntimeError):
         adapters.OllamaEmbeddingProvider()
+
+
+def test_ollama_embedding_provider_checks_model_and_batches_concurrent_calls(
+    monkeypatch,
+) -> None:
+    batches: list[list[str]] = []
+
+    class _FakeOllamaClient:
+        def __init__(self, host: str | None = None, **kwargs) -> None:
+            _ = (host, kwargs)
+
+        @staticmethod
+        def list():
+            return {"models": [{"model": "nomic-embed-text:latest"}]}
+
+        @staticmethod
+        def embed(model: str, input: list[str]):
+            _ = model
+            batches.append(list(input))
+            return {"embeddings": [[float(len(text)), 1.0, 0.0] for text in input]}
+
+    class _FakeOllamaModule:
+        Client = _FakeOllamaClient
+
+    monkeypatch.setattr(adapters, "ollama_module", _FakeOllamaModule())
+    with pytest.raises(RuntimeError, match="ollama pull llama3.1"):
+        adapters.OllamaSemanticProvider(model="llama3.1", host="http://ollama.test")
+
+    provider = adapters.OllamaEmbeddingProvider(
+        host="http://ollama.test", dimensions=4, batch_window_ms=200
+    )
+    assert provider.check_health()["status"] == "ok"
+    texts = [f"memory {'x' * index}" for index in range(6)]
+    with ThreadPoolExecutor(max_workers=6) as pool:
+        vectors = list(pool.map(provider.embed, texts))
+
+    assert len(batches) < len(texts)
+    assert sorted(text for batch in batches for text in batch) == sorted(texts)
+    for text, vector in zip(texts, vectors, strict=True):
+        assert vector.shape[0] == 4
+        expected = np.asarray([len(text), 1.0, 0.0, 0.0], dtype=np.float32)
+        assert np.allclose(vector, expected / np.linalg.norm(expected))
+    assert len(provider.embed_many(texts)) == len(texts)
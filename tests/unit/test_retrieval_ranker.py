@@ -96,3 +96,32 @@ def test_ranker_downweights_long_assistant_responses_pre_warmup() -> None:
     )
 
     assert ranked[0].memory.memory_id == "profile"
+
+
+def test_ranker_strengthens_recalled_memories_and_fades_unused_ones() -> None:
+    query = np.array([1.0, 0.0, 0.0], dtype=np.float32)
+    now = datetime.now(UTC)
+    base = _memory("base", [0.9, 0.1, 0.0], outcome=0.5)
+    recalled = base.model_copy(
+        update={
+            "memory_id": "recalled",
+            "created_at": now - timedelta(days=30),
+            "retrieval_count": 6,
+            "last_retrieved_at": now - timedelta(days=1),
+        }
+    )
+    unused = base.model_copy(
+        update={
+            "memory_id": "unused",
+            "created_at": now - timedelta(days=30),
+            "retrieval_count": 0,
+        }
+    )
+    ranker = RetrievalRanker(min_training_samples=100, training_batch_size=64)
+
+    ranked = ranker.rank(query_embedding=query, candidates=[unused, recalled], now=now)
+
+    assert [item.memory.memory_id for item in ranked] == ["recalled", "unused"]
+    assert ranker._memory_strength(recalled, now) > 0.98
+    # Past the 7-day grace period, unused memories decay 1.5x faster than the base rate.
+    assert ranker._memory_strength(unused, now) < np.exp(-0.03 * 30)
@@ -0,0 +1,90 @@
+from __future__ import annotations
+
+from pathlib import Path
+from typing import Any
+
+import httpx
+import pytest
+
+from orbit import secret_sources
+from orbit.secret_sources import SecretResolutionError, clear_secret_cache, get_secret
+
+
+@pytest.fixture(autouse=True)
+def _clean_secret_env(monkeypatch: pytest.MonkeyPatch) -> None:
+    for name in (
+        "ORBIT_TEST_SECRET",
+        "ORBIT_TEST_SECRET_FILE",
+        "ORBIT_SECRETS_DIR",
+        "VAULT_ADDR",
+        "VAULT_TOKEN",
+        "VAULT_TOKEN_FILE",
+        "VAULT_K8S_ROLE",
+    ):
+        monkeypatch.delenv(name, raising=False)
+    clear_secret_cache()
+
+
+def test_get_secret_prefers_env_then_file_then_secrets_dir(
+    tmp_path: Path,
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    assert get_secret("ORBIT_TEST_SECRET", "fallback") == "fallback"
+
+    mounted = tmp_path / "mounted"
+    mounted.mkdir()
+    (mounted / "orbit-test-secret").write_text("from-k8s\n", encoding="utf-8")
+    monkeypatch.setenv("ORBIT_SECRETS_DIR", str(mounted))
+    assert get_secret("ORBIT_TEST_SECRET") == "from-k8s"
+
+    secret_file = tmp_path / "secret.txt"
+    secret_file.write_text("from-file\n", encoding="utf-8")
+    monkeypatch.setenv("ORBIT_TEST_SECRET_FILE", str(secret_file))
+    assert get_secret("ORBIT_TEST_SECRET") == "from-file"
+
+    monkeypatch.setenv("ORBIT_TEST_SECRET", "from-env")
+    assert get_secret("ORBIT_TEST_SECRET") == "from-env"
+
+    monkeypatch.delenv("ORBIT_TEST_SECRET")
+    monkeypatch.setenv("ORBIT_TEST_SECRET_FILE", str(tmp_path / "missing.txt"))
+    with pytest.raises(SecretResolutionError):
+        get_secret("ORBIT_TEST_SECRET")
+
+
+def test_get_secret_resolves_vault_references_with_kubernetes_auth(
+    tmp_path: Path,
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    service_account = tmp_path / "token"
+    service_account.write_text("sa-jwt", encoding="utf-8")
+    monkeypatch.setenv("VAULT_ADDR", "https://vault.example.com/")
+    monkeypatch.setenv("VAULT_K8S_ROLE", "orbit-api")
+    monkeypatch.setenv("VAULT_K8S_TOKEN_PATH", str(service_account))
+    monkeypatch.setenv("ORBIT_TEST_SECRET", "vault:secret/data/orbit#jwt_secret")
+    calls: list[tuple[str, str, dict[str, Any]]] = []
+
+    def fake_request(method: str, url: str, **kwargs: Any) -> httpx.Response:
+        calls.append((method, url, kwargs))
+        request = httpx.Request(method, url)
+        if url.endswith("/v1/auth/kubernetes/login"):
+            assert kwargs["json"] == {"role": "orbit-api", "jwt": "sa-jwt"}
+            return httpx.Response(200, json={"auth": {"client_token": "s.vault"}}, request=request)
+        assert kwargs["headers"]["X-Vault-Token"] == "s.vault"
+        return httpx.Response(
+            200,
+            json={"data": {"data": {"jwt_secret": "kv2-value"}}},
+            request=request,
+        )
+
+    monkeypatch.setattr(secret_sources.httpx, "request", fake_request)
+
+    assert get_secret("ORBIT_TEST_SECRET") == "kv2-value"
+    assert get_secret("ORBIT_TEST_SECRET") == "kv2-value"
+    assert [call[1] for call in calls] == [
+        "https://vault.example.com/v1/auth/kubernetes/login",
+        "https://vault.example.com/v1/secret/data/orbit",
+    ]
+
+    monkeypatch.setenv("ORBIT_TEST_SECRET", "vault:secret/data/orbit")
+    with pytest.raises(SecretResolutionError):
+        get_secret("ORBIT_TEST_SECRET")
@@ -0,0 +1,180 @@
+from __future__ import annotations
+
+import hashlib
+import hmac
+from pathlib import Path
+
+from memory_engine.config import EngineConfig
+from orbit.models import IngestRequest
+from orbit_api.config import ApiConfig
+from orbit_api.service import OrbitApiService
+from orbit_api.slack import SlackIntegration, verify_signature
+
+
+def _service(tmp_path: Path) -> OrbitApiService:
+    db_path = tmp_path / "slack.db"
+    api_config = ApiConfig(
+        database_url=f"sqlite:///{db_path}",
+        sqlite_fallback_path=str(db_path),
+    )
+    engine_config = EngineConfig(
+        sqlite_path=str(db_path),
+        database_url=f"sqlite:///{db_path}",
+        embedding_dim=16,
+        persistent_confidence_prior=0.0,
+        ephemeral_confidence_prior=0.0,
+    )
+    return OrbitApiService(api_config=api_config, engine_config=engine_config)
+
+
+def _integration(service: OrbitApiService) -> SlackIntegration:
+    return SlackIntegration(
+        service,
+        account_key="acct-slack",
+        user_entities={"U1": "alice"},
+        channel_ids=["C-eng"],
+    )
+
+
+def _sign(secret: str, timestamp: str, body: bytes) -> str:
+    base = f"v0:{timestamp}:".encode() + body
+    return "v0=" + hmac.new(secret.encode(), base, hashlib.sha256).hexdigest()
+
+
+def test_verify_signature_accepts_fresh_signed_requests_only() -> None:
+    body = b'{"type":"event_callback"}'
+    signature = _sign("secret", "1000", body)
+
+    assert verify_signature("secret", timestamp="1000", body=body, signature=signature, now=1010)
+    assert not verify_signature("other", timestamp="1000", body=body, signature=signature, now=1010)
+    assert not verify_signature("secret", timestamp="1000", body=body, signature=signature, now=2000)
+    assert not verify_signature("secret", timestamp=None, body=body, signature=signature)
+
+
+def test_config_parses_slack_user_entities() -> None:
+    config = ApiConfig(slack_user_entities="U1=alice, U2=bob", slack_channel_ids="C1,C2")
+
+    assert config.slack_user_entities == {"U1": "alice", "U2": "bob"}
+    assert config.slack_channel_ids == ["C1", "C2"]
+
+
+def test_event_to_ingest_requires_consent_and_allowed_channel(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        integration = _integration(service)
+        message = {"type": "message", "user": "U1", "channel": "C-eng", "text": "Deploys run on Fridays"}
+
+        request = integration.event_to_ingest(message, team_id="T1")
+        assert request is not None
+        assert request.entity_id == "alice"
+        assert request.event_type == "slack_message"
+        assert request.metadata is not None
+        assert request.metadata["slack_team_id"] == "T1"
+
+        assert integration.event_to_ingest({**message, "user": "U-unlinked"}) is None
+        assert integration.event_to_ingest({**message, "channel": "C-random"}) is None
+        assert integration.event_to_ingest({**message, "bot_id": "B1"}) is None
+        assert integration.event_to_ingest({**message, "subtype": "channel_join"}) is None
+        assert integration.event_to_ingest({**message, "channel_type": "im"}) is None
+    finally:
+        service.close()
+
+
+def test_events_ingest_and_recall_command_answers(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        integration = _integration(service)
+        assert integration.handle_event({"type": "url_verification", "challenge": "abc"}) == {
+            "challenge": "abc"
+        }
+        result = integration.handle_event(
+            {
+                "type": "event_callback",
+                "event_id": "Ev1",
+                "team_id": "T1",
+                "event": {
+                    "type": "message",
+                    "user": "U1",
+                    "channel": "C-eng",
+                    "text": "The staging database password rotates every Monday",
+                },
+            }
+        )
+        assert result["ok"] is True
+        assert "memory_id" in result
+
+        usage = integration.handle_command({"user_id": "U1", "text": "help"})
+        assert usage["response_type"] == "ephemeral"
+        assert "Usage" in usage["text"]
+
+        denied = integration.handle_command({"user_id": "U9", "text": "recall password"})
+        assert "not linked" in denied["text"]
+
+        answer = integration.handle_command(
+            {"user_id": "U1", "text": "recall when does the staging password rotate"}
+        )
+        assert answer["response_type"] == "ephemeral"
+        assert "Monday" in answer["text"]
+    finally:
+        service.close()
+
+
+def test_recall_command_leaves_out_memories_above_the_configured_clearance(
+    tmp_path: Path,
+) -> None:
+    service = _service(tmp_path)
+    try:
+        service.ingest(
+            IngestRequest(
+                content="The staging database password is hunter2",
+                entity_id="alice",
+                sensitivity="confidential",
+            ),
+            account_key="acct-slack",
+        )
+        command = {"user_id": "U1", "text": "recall staging database password"}
+
+        hidden = _integration(service).handle_command(command)
+        assert "hunter2" not in hidden["text"]
+
+        cleared = SlackIntegration(
+            service,
+            account_key="acct-slack",
+            user_entities={"U1": "alice"},
+            recall_max_sensitivity="confidential",
+        )
+        assert "hunter2" in cleared.handle_command(command)["text"]
+        assert ApiConfig().slack_recall_max_sensitivity == "public"
+    finally:
+        service.close()
+
+
+def test_recall_command_is_scoped_to_the_calling_users_entity(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        integration = SlackIntegration(
+            service,
+            account_key="acct-slack",
+            user_entities={"U1": "alice", "U2": "bob"},
+            channel_ids=["C-eng"],
+        )
+        for user, text in (
+            ("U1", "My salary review is on Monday"),
+            ("U2", "My salary review is on Thursday"),
+        ):
+            integration.handle_event(
+                {
+                    "type": "event_callback",
+                    "event_id": f"Ev-{user}",
+                    "event": {"type": "message", "user": user, "channel": "C-eng", "text": text},
+                }
+            )
+
+        alice = integration.handle_command({"user_id": "U1", "text": "recall salary review"})
+        assert "Monday" in alice["text"]
+        assert "Thursday" not in alice["text"]
+        bob = integration.handle_command({"user_id": "U2", "text": "recall salary review"})
+        assert "Thursday" in bob["text"]
+        assert "Monday" not in bob["text"]
+    finally:
+        service.close()
@@ -0,0 +1,53 @@
+from __future__ import annotations
+
+import json
+
+import pytest
+
+from decision_engine.config import EngineConfig
+from decision_engine.storage_postgres import (
+    PostgresStorageManager,
+    build_metadata_document,
+    normalize_text_search_config,
+    to_vector_literal,
+)
+
+
+def test_postgres_storage_manager_rejects_non_postgres_url() -> None:
+    with pytest.raises(ValueError, match="postgresql"):
+        PostgresStorageManager("sqlite:///memory.db", embedding_dim=4)
+
+
+def test_vector_literal_matches_pgvector_text_format() -> None:
+    assert to_vector_literal([1.0, 0.5, -0.25]) == "[1,0.5,-0.25]"
+    assert to_vector_literal([]) == "[]"
+
+
+def test_metadata_document_groups_prefixed_relationship_tags() -> None:
+    document = json.loads(
+        build_metadata_document(
+            entities=["alice"],
+            relationships=["fact_key:diet", "alice->topic_python", "derived_from:m-1"],
+            intent="preference_stated",
+        )
+    )
+    assert document["intent"] == "preference_stated"
+    assert document["entities"] == ["alice"]
+    assert document["tags"] == {"fact_key": ["diet"], "derived_from": ["m-1"]}
+    assert "alice->topic_python" in document["relationships"]
+
+
+def test_text_search_config_is_validated() -> None:
+    assert normalize_text_search_config(" English ") == "english"
+    with pytest.raises(ValueError):
+        normalize_text_search_config("english'); DROP TABLE memories; --")
+
+
+def test_engine_config_storage_backend_from_env(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("MDE_STORAGE_BACKEND", "Postgres")
+    monkeypatch.setenv("MDE_POSTGRES_TEXT_SEARCH_CONFIG", "simple")
+    config = EngineConfig.from_env()
+    assert config.storage_backend == "postgres"
+    assert config.postgres_text_search_config == "simple"
+    with pytest.raises(ValueError):
+        EngineConfig(storage_backend="mongo")
@@ -1,19 +1,43 @@
 from __future__ import annotations
 
+import json
 from pathlib import Path
+from typing import Any
 
 import numpy as np
+from sqlalchemy.orm import Session
 
 from decision_engine.models import (
     EncodedEvent,
+    MemoryRecord,
     RawEvent,
     SemanticUnderstanding,
     StorageDecision,
     StorageTier,
 )
+from decision_engine.storage_postgres import build_metadata_document, to_vector_literal
 from decision_engine.storage_sqlalchemy import SQLAlchemyStorageManager
 
 
+class _MetadataIndexingManager(SQLAlchemyStorageManager):
+    """Keeps what Postgres would write to ``metadata_jsonb`` and ``embedding_vector``."""
+
+    def __init__(self, *args: Any, **kwargs: Any) -> None:
+        super().__init__(*args, **kwargs)
+        self.documents: dict[str, dict[str, Any]] = {}
+        self.vectors: dict[str, str] = {}
+
+    def _write_derived_columns(self, session: Session, record: MemoryRecord) -> None:
+        self.vectors[record.memory_id] = to_vector_literal(record.semantic_embedding)
+        self.documents[record.memory_id] = json.loads(
+            build_metadata_document(
+                entities=record.entities,
+                relationships=record.relationships,
+                intent=record.intent,
+            )
+        )
+
+
 def test_sqlalchemy_storage_manager_with_sqlite_url(tmp_path: Path) -> None:
     manager = SQLAlchemyStorageManager(f"sqlite:///{tmp_path / 'sa-manager.db'}")
     try:
@@ -49,6 +73,9 @@ def test_sqlalchemy_storage_manager_with_sqlite_url(tmp_path: Path) -> None:
         assert candidates
 
         manager.update_retrieval(stored.memory_id)
+        retrieved = manager.fetch_by_ids([stored.memory_id])[0]
+        assert retrieved.retrieval_count == 1
+        assert retrieved.last_retrieved_at is not None
         manager.update_outcome(stored.memory_id, 1.0)
         entity_intent = manager.fetch_by_entity_and_intent("user_1", "interaction")
         assert entity_intent
@@ -60,3 +87,80 @@ def test_sqlalchemy_storage_manager_with_sqlite_url(tmp_path: Path) -> None:
         assert manager.count_memories() == 0
     finally:
         manager.close()
+
+
+def test_relationship_updates_refresh_the_metadata_document(tmp_path: Path) -> None:
+    manager = _MetadataIndexingManager(f"sqlite:///{tmp_path / 'sa-metadata.db'}")
+    try:
+        encoded = EncodedEvent(
+            event=RawEvent(content="Alice is vegetarian", context={"intent": "preference"}),
+            raw_embedding=[1.0, 0.0],
+            semantic_embedding=[1.0, 0.0],
+            understanding=SemanticUnderstanding(
+                summary="Alice is vegetarian",
+                intent="preference",
+                entities=["alice"],
+                relationships=[],
+            ),
+            semantic_key="semantic-key-diet",
+        )
+        decision = StorageDecision(
+            should_store=True,
+            tier=StorageTier.PERSISTENT,
+            confidence=0.9,
+            rationale="test store",
+            trace={},
+        )
+        stored = manager.store(encoded, decision)
+        manager.update_relationships(stored.memory_id, ["fact_key:diet"])
+
+        def tagged(key: str, value: str) -> list[str]:
+            return [
+                memory_id
+                for memory_id, document in manager.documents.items()
+                if value in document["tags"].get(key, [])
+            ]
+
+        assert tagged("fact_key", "diet") == [stored.memory_id]
+        manager.update_relationships(stored.memory_id, [])
+        assert tagged("fact_key", "diet") == []
+    finally:
+        manager.close()
+
+
+def test_content_updates_refresh_the_embedding_vector(tmp_path: Path) -> None:
+    manager = _MetadataIndexingManager(f"sqlite:///{tmp_path / 'sa-vector.db'}")
+    try:
+        encoded = EncodedEvent(
+            event=RawEvent(content="Alice is vegetarian", context={"intent": "preference"}),
+            raw_embedding=[1.0, 0.0],
+            semantic_embedding=[1.0, 0.0],
+            understanding=SemanticUnderstanding(
+                summary="Alice is vegetarian",
+                intent="preference",
+                entities=["alice"],
+                relationships=[],
+            ),
+            semantic_key="semantic-key-diet",
+        )
+        decision = StorageDecision(
+            should_store=True,
+            tier=StorageTier.PERSISTENT,
+            confidence=0.9,
+            rationale="test store",
+            trace={},
+        )
+        stored = manager.store(encoded, decision)
+        assert manager.vectors[stored.memory_id] == "[1,0]"
+
+        manager.update_content(
+            stored.memory_id,
+            content="Alice is vegan",
+            summary="Alice is vegan",
+            raw_embedding=[0.0, 1.0],
+            semantic_embedding=[0.0, 1.0],
+            semantic_key="semantic-key-vegan",
+        )
+        assert manager.vectors[stored.memory_id] == "[0,1]"
+    finally:
+        manager.close()
@@ -0,0 +1,184 @@
+from __future__ import annotations
+
+import json
+from collections.abc import Iterator
+from pathlib import Path
+from types import SimpleNamespace
+from typing import Any
+
+import pytest
+
+from memory_engine.config import EngineConfig
+from orbit_api.config import ApiConfig
+from orbit_api.service import OrbitApiService
+from orbit_api import stream_connector
+from orbit_api.stream_connector import (
+    KafkaSource,
+    SchemaMapping,
+    StreamConnector,
+    StreamMessage,
+    StreamSourceError,
+)
+
+
+class _ListSource:
+    def __init__(self, messages: list[StreamMessage]) -> None:
+        self._messages = messages
+        self.closed = False
+
+    def messages(self) -> Iterator[StreamMessage]:
+        yield from self._messages
+
+    def close(self) -> None:
+        self.closed = True
+
+
+def _service(tmp_path: Path) -> OrbitApiService:
+    db_path = tmp_path / "connector.db"
+    api_config = ApiConfig(
+        database_url=f"sqlite:///{db_path}",
+        sqlite_fallback_path=str(db_path),
+    )
+    engine_config = EngineConfig(
+        sqlite_path=str(db_path),
+        database_url=f"sqlite:///{db_path}",
+        embedding_dim=16,
+        persistent_confidence_prior=0.0,
+        ephemeral_confidence_prior=0.0,
+    )
+    return OrbitApiService(api_config=api_config, engine_config=engine_config)
+
+
+def test_schema_mapping_reads_dotted_paths() -> None:
+    mapping = SchemaMapping(
+        content_field="payload.text",
+        entity_id_field="user.id",
+        account_key_field="tenant",
+        static_metadata={"source": "kafka"},
+    )
+    account_key, request = mapping.map(
+        json.dumps(
+            {
+                "tenant": "acct-1",
+                "user": {"id": 42},
+                "payload": {"text": "Alice prefers dark mode"},
+                "event_type": "preference",
+                "metadata": {"channel": "web"},
+            }
+        ).encode()
+    )
+
+    assert account_key == "acct-1"
+    assert request.content == "Alice prefers dark mode"
+    assert request.entity_id == "42"
+    assert request.event_type == "preference"
+    assert request.metadata == {"source": "kafka", "channel": "web"}
+
+
+def test_schema_mapping_rejects_unknown_keys_and_bad_messages() -> None:
+    with pytest.raises(ValueError, match="unknown schema mapping keys"):
+        SchemaMapping.from_json('{"content": "text"}')
+    mapping = SchemaMapping.from_json('{"content_field": "text"}')
+    with pytest.raises(ValueError, match="missing content field"):
+        mapping.map(b'{"content": "wrong field"}')
+    with pytest.raises(ValueError, match="not valid JSON"):
+        mapping.map(b"not-json")
+
+
+def test_connector_ingests_replays_and_rejects(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    acked: list[str] = []
+
+    def message(body: bytes, key: str) -> StreamMessage:
+        return StreamMessage(value=body, idempotency_key=key, ack=lambda: acked.append(key))
+
+    payload = json.dumps({"content": "Bob is learning Rust", "entity_id": "bob"}).encode()
+    source = _ListSource(
+        [
+            message(payload, "kafka:events:0:1"),
+            message(payload, "kafka:events:0:1"),
+            message(b"{}", "kafka:events:0:2"),
+        ]
+    )
+    try:
+        stats = StreamConnector(service, source, SchemaMapping()).run()
+        memories = service.list_memories(limit=10, cursor=None, account_key="default")
+    finally:
+        service.close()
+
+    assert (stats.ingested, stats.replayed, stats.rejected) == (1, 1, 1)
+    assert acked == ["kafka:events:0:1", "kafka:events:0:1", "kafka:events:0:2"]
+    assert source.closed
+    assert len(memories.data) == 1
+
+
+class _KafkaError:
+    def __init__(self, reason: str, *, fatal: bool) -> None:
+        self._reason = reason
+        self._fatal = fatal
+
+    def code(self) -> int:
+        return -1
+
+    def fatal(self) -> bool:
+        return self._fatal
+
+    def __str__(self) -> str:
+        return self._reason
+
+
+class _KafkaRecord:
+    def __init__(self, value: bytes = b"", error: _KafkaError | None = None) -> None:
+        self._value = value
+        self._error = error
+
+    def error(self) -> _KafkaError | None:
+        return self._error
+
+    def value(self) -> bytes:
+        return self._value
+
+    def topic(self) -> str:
+        return "events"
+
+    def partition(self) -> int:
+        return 0
+
+    def offset(self) -> int:
+        return 7
+
+
+def test_kafka_source_counts_errors_and_stops_on_fatal_ones(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    records = [
+        _KafkaRecord(error=_KafkaError("broker transport failure", fatal=False)),
+        _KafkaRecord(b'{"content": "Bob is learning Rust"}'),
+        _KafkaRecord(error=_KafkaError("fenced by a newer producer", fatal=True)),
+    ]
+
+    class _Consumer:
+        def __init__(self, config: dict[str, Any]) -> None:
+            self.config = config
+
+        def subscribe(self, topics: list[str]) -> None:
+            pass
+
+        def poll(self, timeout: float) -> _KafkaRecord:
+            return records.pop(0)
+
+        def close(self) -> None:
+            pass
+
+    monkeypatch.setattr(
+        stream_connector, "confluent_kafka_module", SimpleNamespace(Consumer=_Consumer)
+    )
+    source = KafkaSource(brokers="localhost:9092", topic="events", group_id="orbit")
+    messages = source.messages()
+
+    first = next(messages)
+    assert first.idempotency_key == "kafka:events:0:7"
+    assert source.errors == 1
+    with pytest.raises(StreamSourceError, match="fenced"):
+        next(messages)
+    assert source.errors == 2
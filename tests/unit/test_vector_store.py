@@ -31,3 +31,67 @@ def test_vector_store_save_and_load_numpy_backend(tmp_path: Path) -> None:
     second.load()
     hits = second.search([0.4, 0.6, 0.0], top_k=2)
     assert len(hits) >= 1
+
+
+def test_vector_store_quantizes_configured_namespaces(tmp_path: Path) -> None:
+    rng = np.random.default_rng(7)
+    vectors = rng.normal(size=(300, 16)).astype(np.float32)
+    store = VectorStore(
+        embedding_dim=16,
+        index_path=str(tmp_path / "quantized.idx"),
+        namespace_quantization={"int8": "int8", "pq": "pq", "binary": "binary"},
+        pq_train_size=256,
+    )
+    for namespace in ("default", "int8", "pq", "binary"):
+        for index, vector in enumerate(vectors):
+            store.add(f"{namespace}-{index}", vector.tolist(), namespace=namespace)
+
+    stats = {item["namespace"]: item for item in store.namespace_stats()}
+    assert stats["default"]["quantization"] == "none"
+    assert stats["pq"]["quantization"] == "pq"
+    for namespace in ("int8", "pq", "binary"):
+        assert stats[namespace]["vectors"] == 300
+        assert stats[namespace]["bytes"] < stats[namespace]["float32_bytes"]
+
+    hits = {hit.memory_id for hit in store.search(vectors[42], top_k=40)}
+    for namespace in ("default", "int8", "pq", "binary"):
+        assert f"{namespace}-42" in hits
+
+    store.remove_many(["int8-42"])
+    assert "int8-42" not in store.memory_ids()
+    store.save()
+    reloaded = VectorStore(
+        embedding_dim=16,
+        index_path=str(tmp_path / "quantized.idx"),
+        namespace_quantization={"int8": "int8", "pq": "pq", "binary": "binary"},
+    )
+    reloaded.load()
+    assert {hit.memory_id for hit in reloaded.search(vectors[7], top_k=40)} >= {
+        "int8-7",
+        "pq-7",
+        "binary-7",
+    }
+
+
+def test_vector_store_files_load_without_pickle(tmp_path: Path) -> None:
+    path = tmp_path / "plain.idx"
+    store = VectorStore(
+        embedding_dim=4,
+        index_path=str(path),
+        namespace_quantization={"compact": "int8"},
+    )
+    store.add("plain-1", [1.0, 0.0, 0.0, 0.0])
+    store.add("compact-1", [0.0, 1.0, 0.0, 0.0], namespace="compact")
+    store.save()
+
+    for saved in (path.with_suffix(".npz"), path.with_suffix(".quantized.npz")):
+        with np.load(str(saved), allow_pickle=False) as data:
+            assert all(data[name].dtype.kind != "O" for name in data.files)
+
+    reloaded = VectorStore(
+        embedding_dim=4,
+        index_path=str(path),
+        namespace_quantization={"compact": "int8"},
+    )
+    reloaded.load()
+    assert reloaded.memory_ids() >= {"plain-1", "compact-1"}
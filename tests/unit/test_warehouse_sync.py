@@ -0,0 +1,176 @@
+from __future__ import annotations
+
+from collections.abc import Sequence
+from datetime import UTC, datetime
+from pathlib import Path
+from typing import Any
+
+from memory_engine.config import EngineConfig
+from orbit.models import IngestRequest, MemoryChange
+from orbit_api.config import ApiConfig
+from orbit_api.service import OrbitApiService
+from orbit_api.warehouse_sync import (
+    CHANGES_TABLE,
+    MEMORIES_TABLE,
+    SnowflakeSink,
+    WarehouseSync,
+    fold_changes,
+    merge_sql,
+)
+
+
+class _MemorySink:
+    """Applies rows the way the warehouse ``MERGE`` does."""
+
+    def __init__(self) -> None:
+        self.memories: dict[str, dict[str, Any]] = {}
+        self.changes: list[dict[str, Any]] = []
+        self.schema_calls = 0
+
+    def ensure_schema(self) -> None:
+        self.schema_calls += 1
+
+    def last_sequence(self, account_key: str) -> int:
+        sequences = [row["sequence"] for row in self.changes if row["account_key"] == account_key]
+        return max(sequences, default=0)
+
+    def upsert_memories(self, rows: Sequence[dict[str, Any]]) -> None:
+        for row in rows:
+            current = self.memories.get(row["memory_id"])
+            if current is None:
+                self.memories[row["memory_id"]] = dict(row)
+            elif row["sequence"] > current["sequence"]:
+                for key, value in row.items():
+                    if value is not None or key in {"updated_at", "sequence"}:
+                        current[key] = value
+
+    def append_changes(self, rows: Sequence[dict[str, Any]]) -> None:
+        self.changes.extend(rows)
+
+
+class _SnowflakeCursor:
+    def __init__(self, statements: list[str], columns: dict[str, list[str]]) -> None:
+        self._statements = statements
+        self._columns = columns
+        self._result: list[tuple[Any, ...]] = []
+
+    def execute(self, sql: str, params: tuple[Any, ...] = ()) -> None:
+        self._statements.append(sql)
+        if "information_schema.columns" in sql:
+            self._result = [(name,) for name in self._columns.get(params[0], [])]
+
+    def executemany(self, sql: str, rows: list[tuple[Any, ...]]) -> None:
+        self._statements.append(sql)
+
+    def fetchall(self) -> list[tuple[Any, ...]]:
+        return self._result
+
+    def close(self) -> None:
+        return None
+
+
+class _SnowflakeConnection:
+    def __init__(self, columns: dict[str, list[str]]) -> None:
+        self.statements: list[str] = []
+        self._columns = columns
+
+    def cursor(self) -> _SnowflakeCursor:
+        return _SnowflakeCursor(self.statements, self._columns)
+
+
+def _service(tmp_path: Path) -> OrbitApiService:
+    db_path = tmp_path / "warehouse.db"
+    api_config = ApiConfig(
+        database_url=f"sqlite:///{db_path}",
+        sqlite_fallback_path=str(db_path),
+    )
+    engine_config = EngineConfig(
+        sqlite_path=str(db_path),
+        database_url=f"sqlite:///{db_path}",
+        embedding_dim=16,
+        persistent_confidence_prior=0.0,
+        ephemeral_confidence_prior=0.0,
+    )
+    return OrbitApiService(api_config=api_config, engine_config=engine_config)
+
+
+def test_warehouse_sync_backfills_then_streams_changes(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    sink = _MemorySink()
+    try:
+        first = service.ingest(
+            IngestRequest(content="Alice prefers email", entity_id="alice"),
+            account_key="acct",
+        )
+        sync = WarehouseSync(service, sink, account_key="acct", batch_size=1)
+        backfilled = sync.backfill()
+        assert backfilled.memories >= 1 and first.memory_id in sink.memories
+        assert sink.memories[first.memory_id]["content"] == "Alice prefers email"
+        assert sink.changes[-1]["operation"] == "backfill"
+        assert backfilled.cursor == service.latest_change_sequence("acct")
+
+        assert sync.sync_once().changes == 0
+        second = service.ingest(
+            IngestRequest(content="Alice works at ACME", entity_id="alice"),
+            account_key="acct",
+        )
+        service._engine.delete_memories([first.memory_id], account_key="acct")
+        streamed = sync.sync_once()
+        assert streamed.changes >= 2
+        assert streamed.cursor == service.latest_change_sequence("acct")
+        assert sink.memories[second.memory_id]["deleted"] is False
+        deleted = sink.memories[first.memory_id]
+        assert deleted["deleted"] is True and deleted["content"] == "Alice prefers email"
+        assert sink.schema_calls == 1
+    finally:
+        service.close()
+
+
+def test_fold_changes_keeps_last_value_per_memory() -> None:
+    at = datetime(2026, 10, 15, tzinfo=UTC)
+    rows = fold_changes(
+        "acct",
+        [
+            MemoryChange(
+                sequence=1,
+                memory_id="m1",
+                operation="created",
+                occurred_at=at,
+                memory={"content": "v1", "entities": ["alice"]},
+            ),
+            MemoryChange(
+                sequence=2,
+                memory_id="m1",
+                operation="superseded",
+                occurred_at=at,
+                memory={"superseded_by": "m2"},
+            ),
+            MemoryChange(sequence=3, memory_id="m1", operation="deleted", occurred_at=at),
+        ],
+    )
+    assert len(rows) == 1
+    assert (rows[0]["content"], rows[0]["entities"]) == ("v1", '["alice"]')
+    assert (rows[0]["superseded_by"], rows[0]["deleted"], rows[0]["sequence"]) == ("m2", True, 3)
+    assert "WHEN MATCHED AND S.sequence > T.sequence" in merge_sql("t", "s")
+
+
+def test_snowflake_sink_adds_missing_columns() -> None:
+    connection = _SnowflakeConnection(
+        {
+            MEMORIES_TABLE.upper(): ["ACCOUNT_KEY", "MEMORY_ID", "CONTENT", "SEQUENCE"],
+            CHANGES_TABLE.upper(): [
+                "ACCOUNT_KEY",
+                "SEQUENCE",
+                "MEMORY_ID",
+                "OPERATION",
+                "OCCURRED_AT",
+                "PAYLOAD",
+                "SYNCED_AT",
+            ],
+        }
+    )
+    SnowflakeSink(connection).ensure_schema()
+    altered = [sql for sql in connection.statements if sql.startswith("ALTER TABLE")]
+    assert f"ALTER TABLE {MEMORIES_TABLE} ADD COLUMN superseded_by VARCHAR" in altered
+    assert not any(CHANGES_TABLE in sql for sql in altered)
+    assert f"CREATE TABLE IF NOT EXISTS {CHANGES_TABLE} (" in connection.statements[-2]
@@ -0,0 +1,244 @@
+from __future__ import annotations
+
+import time
+from pathlib import Path
+from typing import Any
+
+import pytest
+
+from memory_engine.config import EngineConfig
+from orbit.models import (
+    IngestRequest,
+    RetrieveRequest,
+    SessionMemoryRequest,
+)
+from orbit_api.config import ApiConfig
+from orbit_api.service import OrbitApiService
+from orbit_api.working_memory import (
+    InMemoryWorkingMemoryStore,
+    RedisWorkingMemoryStore,
+    WorkingMemoryItem,
+    new_working_memory_item,
+    score_working_memory,
+)
+
+
+def _service(tmp_path: Path) -> OrbitApiService:
+    db_path = tmp_path / "service.db"
+    api_config = ApiConfig(
+        database_url=f"sqlite:///{db_path}",
+        sqlite_fallback_path=str(db_path),
+        working_memory_ttl_seconds=60,
+    )
+    engine_config = EngineConfig(
+        sqlite_path=str(db_path),
+        database_url=f"sqlite:///{db_path}",
+        embedding_dim=16,
+        persistent_confidence_prior=0.0,
+        ephemeral_confidence_prior=0.0,
+    )
+    return OrbitApiService(api_config=api_config, engine_config=engine_config)
+
+
+def _item(content: str, *, session_id: str = "s1", importance: float = 0.0) -> WorkingMemoryItem:
+    return new_working_memory_item(
+        session_id=session_id,
+        content=content,
+        event_type="user_question",
+        entity_id="alice",
+        importance=importance,
+    )
+
+
+def test_in_memory_store_trims_sessions_and_tracks_recalls() -> None:
+    store = InMemoryWorkingMemoryStore(max_items_per_session=2)
+    first = _item("first")
+    store.append("acct", first)
+    store.append("acct", _item("second"))
+    store.append("acct", _item("third"))
+    store.append("other", _item("isolated"))
+
+    assert [item.content for item in store.list("acct", "s1")] == ["second", "third"]
+
+    third = store.list("acct", "s1")[1]
+    store.mark_recalled("acct", "s1", [third.item_id, first.item_id])
+    assert [item.recall_count for item in store.list("acct", "s1")] == [0, 1]
+
+    assert set(store.idle_sessions(float("inf"))) == {("acct", "s1"), ("other", "s1")}
+    assert len(store.pop_session("acct", "s1")) == 2
+    assert store.list("acct", "s1") == []
+    assert store.idle_sessions(float("inf")) == [("other", "s1")]
+
+
+class _RecordingRedis:
+    """Just enough of a Redis client for appends, recording each key's expiry."""
+
+    def __init__(self) -> None:
+        self.expiries: list[int] = []
+
+    def pipeline(self) -> _RecordingRedis:
+        return self
+
+    def hset(self, key: str, field: str, value: str) -> None:
+        return None
+
+    def expire(self, key: str, seconds: int) -> None:
+        self.expiries.append(seconds)
+
+    def zadd(self, key: str, mapping: dict[str, float]) -> None:
+        return None
+
+    def execute(self) -> list[Any]:
+        return []
+
+    def hlen(self, key: str) -> int:
+        return 1
+
+
+def test_redis_store_derives_key_ttl_from_the_current_timeout() -> None:
+    client = _RecordingRedis()
+    store = RedisWorkingMemoryStore("redis://unused", ttl_seconds=600, client=client)
+
+    store.append("acct", _item("first"))
+    # A reloaded ORBIT_WORKING_MEMORY_TTL_SECONDS reaches keys written after the reload.
+    store.append("acct", _item("second"), ttl_seconds=3600)
+    store.append("acct", _item("third"), ttl_seconds=10)
+
+    assert client.expiries == [1200, 7200, 60]
+
+
+def test_score_working_memory_requires_term_overlap() -> None:
+    items = [_item("Alice is debugging the billing export"), _item("Lunch at noon")]
+
+    scored = score_working_memory("billing export bug", items, limit=5)
+
+    assert [item.content for item, _ in scored] == ["Alice is debugging the billing export"]
+    assert 0.0 < scored[0][1] <= 1.0
+
+
+def test_service_session_retrieval_spans_tiers_and_promotes_on_end(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        remembered = service.remember_in_session(
+            "call-42",
+            SessionMemoryRequest(
+                content="Caller says the billing export times out",
+                entity_id="alice",
+            ),
+            account_key="acct",
+        )
+        service.remember_in_session(
+            "call-42",
+            SessionMemoryRequest(
+                content="Caller prefers email follow ups",
+                entity_id="alice",
+                importance=0.9,
+            ),
+            account_key="acct",
+        )
+        service.remember_in_session(
+            "call-42",
+            SessionMemoryRequest(content="Weather small talk", entity_id="alice"),
+            account_key="acct",
+        )
+
+        result = service.retrieve(
+            RetrieveRequest(query="billing export", limit=5, session_id="call-42"),
+            account_key="acct",
+        )
+        assert result.memories[0].memory_id == remembered.item_id
+        assert result.memories[0].metadata["tier"] == "working"
+        assert result.applied_filters["session_id"] == "call-42"
+
+        ended = service.end_session("call-42", account_key="acct")
+        # Recalled during the session, or flagged important: promoted. Small talk: dropped.
+        assert len(ended.promoted_memory_ids) == 2
+        assert ended.discarded_count == 1
+        assert service.session_memories("call-42", account_key="acct").data == []
+
+        long_term = service.retrieve(
+            RetrieveRequest(query="billing export", limit=5),
+            account_key="acct",
+        )
+        assert any("billing export" in memory.content for memory in long_term.memories)
+    finally:
+        service.close()
+
+
+def test_service_promotes_idle_sessions(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        service.remember_in_session(
+            "idle",
+            SessionMemoryRequest(content="Remember the VPN fix", importance=1.0),
+            account_key="acct",
+        )
+        assert service.promote_idle_sessions(force=True) == 0
+
+        service.config.working_memory_ttl_seconds = 1
+        service._working_memory._last_activity[("acct", "idle")] -= 5  # type: ignore[attr-defined]
+        assert service.promote_idle_sessions(force=True) == 1
+        assert service.session_memories("idle", account_key="acct").data == []
+    finally:
+        service.close()
+
+
+def test_service_keeps_or_rejects_session_items_when_promotion_fails(
+    tmp_path: Path, monkeypatch: pytest.MonkeyPatch
+) -> None:
+    service = _service(tmp_path)
+    try:
+        for content in ("Renewal is due in May", "Prefers phone calls"):
+            service.remember_in_session(
+                "call-7",
+                SessionMemoryRequest(content=content, importance=1.0),
+                account_key="acct",
+            )
+
+        def store_down(**_: Any) -> Any:
+            msg = "database unavailable"
+            raise RuntimeError(msg)
+
+        with monkeypatch.context() as patch:
+            patch.setattr(service, "ingest_with_quota", store_down)
+            with pytest.raises(RuntimeError, match="unavailable"):
+                service.end_session("call-7", account_key="acct")
+        assert len(service.session_memories("call-7", account_key="acct").data) == 2
+
+        real_ingest = service.ingest_with_quota
+
+        def refuse_renewals(**kwargs: Any) -> Any:
+            if "Renewal" in kwargs["request"].content:
+                msg = "content blocked by write policy"
+                raise ValueError(msg)
+            return real_ingest(**kwargs)
+
+        with monkeypatch.context() as patch:
+            patch.setattr(service, "ingest_with_quota", refuse_renewals)
+            ended = service.end_session("call-7", account_key="acct")
+        assert len(ended.promoted_memory_ids) == 1
+        assert ended.rejected_count == 1
+        assert service.session_memories("call-7", account_key="acct").data == []
+        assert "orbit_working_memory_promotions_rejected_total 1" in service.metrics_text()
+    finally:
+        service.close()
+
+
+def test_service_sweeper_promotes_idle_sessions_without_another_write(tmp_path: Path) -> None:
+    service = _service(tmp_path)
+    try:
+        service.remember_in_session(
+            "quiet",
+            SessionMemoryRequest(content="Remember the VPN fix", importance=1.0),
+            account_key="acct",
+        )
+        service.config.working_memory_ttl_seconds = 1
+        service._working_memory._last_activity[("acct", "quiet")] -= 5  # type: ignore[attr-defined]
+
+        service.start_session_sweeper(0.05)
+        deadline = time.monotonic() + 5
+        while service.session_memories("quiet", account_key="acct").data:
+            assert time.monotonic() < deadline
+            time.sleep(0.05)
+    finally:
+        service.close()